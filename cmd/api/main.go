@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embedded IANA time zone database, so user time zones validate without system zoneinfo
 
 	app "finflow-wallet/internal"
 )
@@ -23,6 +24,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Start periodic background jobs (archival, etc.)
+	application.StartBackgroundJobs(ctx)
+
 	// Start HTTP server
 	server := &http.Server{
 		Addr:         ":" + application.Config.ServerPort,
@@ -30,12 +34,20 @@ func main() {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
+		TLSConfig:    application.TLSConfig,
 	}
 
 	// Run server in a goroutine
 	go func() {
-		application.Logger.Info("Starting HTTP server", "port", application.Config.ServerPort)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsCfg := application.Config.TLS; tlsCfg.CertFile != "" {
+			application.Logger.Info("Starting HTTPS server", "port", application.Config.ServerPort)
+			err = server.ListenAndServeTLS(tlsCfg.CertFile, tlsCfg.KeyFile)
+		} else {
+			application.Logger.Info("Starting HTTP server", "port", application.Config.ServerPort)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			application.Logger.Error("HTTP server failed to start", "error", err)
 			os.Exit(1)
 		}
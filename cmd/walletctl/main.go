@@ -0,0 +1,144 @@
+// cmd/walletctl/main.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"finflow-wallet/internal/config"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/migration"
+	"finflow-wallet/internal/repository/postgres"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/migrations"
+	"finflow-wallet/pkg/db"
+)
+
+const usage = `Usage: walletctl <command> [flags]
+
+Commands:
+  verify-backup [-out file]  Run the integrity checks against the database and write the signed report
+  check-report -in file      Check the signature of a verification report
+
+The database and the signing key are configured as for the API, e.g. DB_HOST, DB_NAME and
+BACKUP_VERIFICATION_SIGNING_KEY. verify-backup exits with 1 when a check fails, check-report when the
+signature does not match.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	var err error
+	switch os.Args[1] {
+	case "verify-backup":
+		err = verifyBackup(ctx, os.Args[2:], logger)
+	case "check-report":
+		err = checkReport(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "walletctl:", err)
+		os.Exit(1)
+	}
+}
+
+// verifyBackup runs the integrity checks against the configured database and writes the signed report to
+// the -out file, or standard output.
+func verifyBackup(ctx context.Context, args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("verify-backup", flag.ExitOnError)
+	out := flags.String("out", "", "file to write the report to; standard output if empty")
+	_ = flags.Parse(args)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	schemaMigrations, err := migration.Load(migrations.FS)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	database, err := db.NewPostgresDB(cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	verification := service.NewBackupVerificationService(
+		database,
+		postgres.NewIntegrityRepository(database),
+		postgres.NewSchemaRepository(database),
+		schemaMigrations[len(schemaMigrations)-1].Version,
+		cfg.DB.Host+"/"+cfg.DB.DBName,
+		[]byte(cfg.BackupSigningKey),
+		logger,
+	)
+	report, err := verification.Verify(ctx)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(encoded)
+	} else {
+		err = os.WriteFile(*out, encoded, 0o600)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the report: %w", err)
+	}
+	if !report.Passed {
+		return errors.New("the backup failed verification")
+	}
+	return nil
+}
+
+// checkReport checks that the report in the -in file was signed with the configured key and not changed since.
+func checkReport(args []string) error {
+	flags := flag.NewFlagSet("check-report", flag.ExitOnError)
+	in := flags.String("in", "", "file holding the report")
+	_ = flags.Parse(args)
+	if *in == "" {
+		return errors.New("check-report needs -in")
+	}
+	key := os.Getenv("BACKUP_VERIFICATION_SIGNING_KEY")
+	if key == "" {
+		return errors.New("BACKUP_VERIFICATION_SIGNING_KEY is not set")
+	}
+
+	raw, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+	var report domain.VerificationReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return fmt.Errorf("failed to decode the report: %w", err)
+	}
+	if err := service.VerifyReportSignature(&report, []byte(key)); err != nil {
+		return err
+	}
+	outcome := "passed"
+	if !report.Passed {
+		outcome = "failed"
+	}
+	fmt.Printf("Report %s of %s is authentic; the backup %s verification.\n", report.ID, report.Database, outcome)
+	return nil
+}
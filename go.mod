@@ -10,6 +10,10 @@ require (
 
 require github.com/go-chi/chi/v5 v5.2.2
 
+require github.com/google/uuid v1.6.0
+
+require github.com/DATA-DOG/go-sqlmock v1.5.2
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
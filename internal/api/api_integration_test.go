@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,6 +21,7 @@ import (
 	app "finflow-wallet/internal" // Corrected import path and alias
 	"finflow-wallet/internal/api/types"
 	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
 	// Import util for error checking
 )
 
@@ -97,8 +99,8 @@ func clearDatabase(t *testing.T) {
 }
 
 // createTestUserAndWallet helper function: quickly creates a user and wallet for testing.
-// It now only returns the walletID as userID is not directly used by the API tests.
-func createTestUserAndWallet(t *testing.T, username, currency string, initialBalance decimal.Decimal) int64 {
+// It returns the wallet's public ID, the identifier the API uses.
+func createTestUserAndWallet(t *testing.T, username, currency string, initialBalance decimal.Decimal) uuid.UUID {
 	user := domain.NewUser(username)
 	// Pass testApp.DB as the DBExecutor
 	err := testApp.UserRepository.CreateUser(context.Background(), testApp.DB, user)
@@ -116,7 +118,7 @@ func createTestUserAndWallet(t *testing.T, username, currency string, initialBal
 	_, err = testApp.DB.ExecContext(context.Background(), "UPDATE wallets SET balance = $1 WHERE id = $2", initialBalance, wallet.ID)
 	require.NoError(t, err)
 
-	return wallet.ID
+	return wallet.PublicID
 }
 
 // makeRequest helper function: sends an HTTP request to the test server.
@@ -145,37 +147,37 @@ func TestDepositIntegration(t *testing.T) {
 	t.Run("SuccessfulDeposit", func(t *testing.T) {
 		depositAmount := decimal.NewFromFloat(100.00)
 		requestBody := fmt.Sprintf(`{"amount": "%s", "currency": "USD"}`, depositAmount.String())
-		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/deposit", walletID), strings.NewReader(requestBody))
+		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/deposit", walletID), strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-		var responseMap map[string]any
-		err := json.Unmarshal([]byte(body), &responseMap)
+		var response types.Response[map[string]any]
+		err := json.Unmarshal([]byte(body), &response)
 		require.NoError(t, err)
 
-		assert.Equal(t, "Deposit successful", responseMap["message"])
-		assert.Equal(t, float64(walletID), responseMap["wallet_id"])
+		assert.Equal(t, "Deposit successful", response.Meta["message"])
+		assert.Equal(t, walletID.String(), response.Data["wallet_id"])
 		// Verify new balance
-		newBalance, err := decimal.NewFromString(responseMap["new_balance"].(string))
+		newBalance, err := decimal.NewFromString(response.Data["new_balance"].(string))
 		require.NoError(t, err)
 		assert.True(t, depositAmount.Equal(newBalance), "New balance should match deposit amount") // <-- 修改这里
 
 		// Additional verification: confirm balance again via GET /balance endpoint.
-		respGet, bodyGet := makeRequest(t, "GET", fmt.Sprintf("/wallets/%d/balance", walletID), nil)
+		respGet, bodyGet := makeRequest(t, "GET", fmt.Sprintf("/wallets/%s/balance", walletID), nil)
 		defer respGet.Body.Close()
 		assert.Equal(t, http.StatusOK, respGet.StatusCode)
-		var balanceMap map[string]any
-		err = json.Unmarshal([]byte(bodyGet), &balanceMap)
+		var balanceResponse types.Response[map[string]any]
+		err = json.Unmarshal([]byte(bodyGet), &balanceResponse)
 		require.NoError(t, err)
-		retrievedBalance, err := decimal.NewFromString(balanceMap["balance"].(string))
+		retrievedBalance, err := decimal.NewFromString(balanceResponse.Data["balance"].(string))
 		require.NoError(t, err)
 		assert.True(t, depositAmount.Equal(retrievedBalance), "Retrieved balance should match deposit amount") // <-- 修改这里
 	})
 
 	t.Run("InvalidAmount", func(t *testing.T) {
 		requestBody := `{"amount": "-10.00", "currency": "USD"}`
-		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/deposit", walletID), strings.NewReader(requestBody))
+		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/deposit", walletID), strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
@@ -183,9 +185,9 @@ func TestDepositIntegration(t *testing.T) {
 	})
 
 	t.Run("WalletNotFound", func(t *testing.T) {
-		nonExistentWalletID := int64(9999)
+		nonExistentWalletID := uuid.New()
 		requestBody := `{"amount": "50.00", "currency": "USD"}`
-		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/deposit", nonExistentWalletID), strings.NewReader(requestBody))
+		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/deposit", nonExistentWalletID), strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
@@ -194,29 +196,46 @@ func TestDepositIntegration(t *testing.T) {
 
 	t.Run("CurrencyMismatch", func(t *testing.T) {
 		requestBody := `{"amount": "50.00", "currency": "HKD"}`
-		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/deposit", walletID), strings.NewReader(requestBody))
+		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/deposit", walletID), strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode) // <-- 期望 400
 		assert.Contains(t, body, "wallet currency mismatch")    // <-- 期望特定消息
 	})
 
+	t.Run("UnsupportedCurrency", func(t *testing.T) {
+		requestBody := `{"amount": "50.00", "currency": "XYZ"}`
+		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/deposit", walletID), strings.NewReader(requestBody))
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, body, `"code":"currency_unsupported"`)
+	})
+
+	t.Run("NormalizedCurrency", func(t *testing.T) {
+		requestBody := `{"amount": "1.00", "currency": " usd "}`
+		resp, _ := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/deposit", walletID), strings.NewReader(requestBody))
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
 	t.Run("SuccessfulDeposit_EUR", func(t *testing.T) {
 		eurWalletID := createTestUserAndWallet(t, "deposit_user_eur", "EUR", decimal.NewFromInt(0))
 		depositAmount := decimal.NewFromFloat(200.00)
 		requestBody := fmt.Sprintf(`{"amount": "%s", "currency": "EUR"}`, depositAmount.String())
-		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/deposit", eurWalletID), strings.NewReader(requestBody))
+		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/deposit", eurWalletID), strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-		var responseMap map[string]any
-		err := json.Unmarshal([]byte(body), &responseMap)
+		var response types.Response[map[string]any]
+		err := json.Unmarshal([]byte(body), &response)
 		require.NoError(t, err)
 
-		assert.Equal(t, "Deposit successful", responseMap["message"])
-		assert.Equal(t, float64(eurWalletID), responseMap["wallet_id"])
-		newBalance, err := decimal.NewFromString(responseMap["new_balance"].(string))
+		assert.Equal(t, "Deposit successful", response.Meta["message"])
+		assert.Equal(t, eurWalletID.String(), response.Data["wallet_id"])
+		newBalance, err := decimal.NewFromString(response.Data["new_balance"].(string))
 		require.NoError(t, err)
 		assert.True(t, depositAmount.Equal(newBalance), "New balance for EUR wallet should match deposit amount") // <-- 修改这里
 	})
@@ -230,16 +249,16 @@ func TestWithdrawIntegration(t *testing.T) {
 	t.Run("SuccessfulWithdrawal", func(t *testing.T) {
 		withdrawAmount := decimal.NewFromFloat(100.00)
 		requestBody := fmt.Sprintf(`{"amount": "%s", "currency": "USD"}`, withdrawAmount.String())
-		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/withdraw", walletID), strings.NewReader(requestBody))
+		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/withdraw", walletID), strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
-		var responseMap map[string]any
-		err := json.Unmarshal([]byte(body), &responseMap)
+		var response types.Response[map[string]any]
+		err := json.Unmarshal([]byte(body), &response)
 		require.NoError(t, err)
 
-		assert.Equal(t, "Withdrawal successful", responseMap["message"])
-		newBalance, err := decimal.NewFromString(responseMap["new_balance"].(string))
+		assert.Equal(t, "Withdrawal successful", response.Meta["message"])
+		newBalance, err := decimal.NewFromString(response.Data["new_balance"].(string))
 		require.NoError(t, err)
 		expectedBalance := decimal.NewFromFloat(400.00)
 		assert.True(t, expectedBalance.Equal(newBalance), "New balance should be 400.00") // <-- 修改这里
@@ -248,7 +267,7 @@ func TestWithdrawIntegration(t *testing.T) {
 	t.Run("InsufficientFunds", func(t *testing.T) {
 		withdrawAmount := decimal.NewFromFloat(1000.00)
 		requestBody := fmt.Sprintf(`{"amount": "%s", "currency": "USD"}`, withdrawAmount.String())
-		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/withdraw", walletID), strings.NewReader(requestBody))
+		resp, body := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/withdraw", walletID), strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
@@ -264,17 +283,17 @@ func TestTransferIntegration(t *testing.T) {
 
 	t.Run("SuccessfulTransfer", func(t *testing.T) {
 		transferAmount := decimal.NewFromFloat(50.00)
-		requestBody := fmt.Sprintf(`{"from_wallet_id": %d, "to_wallet_id": %d, "amount": "%s", "currency": "USD"}`, walletID1, walletID2, transferAmount.String())
+		requestBody := fmt.Sprintf(`{"from_wallet_id": "%s", "to_wallet_id": "%s", "amount": "%s", "currency": "USD"}`, walletID1, walletID2, transferAmount.String())
 		resp, body := makeRequest(t, "POST", "/transfers", strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
-		var responseMap map[string]any
-		err := json.Unmarshal([]byte(body), &responseMap)
+		var response types.Response[map[string]any]
+		err := json.Unmarshal([]byte(body), &response)
 		require.NoError(t, err)
 
-		assert.Equal(t, "Transfer successful", responseMap["message"])
-		fromWalletNewBalance, err := decimal.NewFromString(responseMap["from_wallet_new_balance"].(string))
+		assert.Equal(t, "Transfer successful", response.Meta["message"])
+		fromWalletNewBalance, err := decimal.NewFromString(response.Data["from_wallet_new_balance"].(string))
 		require.NoError(t, err)
 
 		expectedFromBalance := decimal.NewFromFloat(450.00)
@@ -282,9 +301,52 @@ func TestTransferIntegration(t *testing.T) {
 		assert.True(t, expectedFromBalance.Equal(fromWalletNewBalance), "From wallet new balance should be 450.00")
 	})
 
+	t.Run("DuplicateTransfer", func(t *testing.T) {
+		// Repeats SuccessfulTransfer within the duplicate window
+		requestBody := fmt.Sprintf(`{"from_wallet_id": "%s", "to_wallet_id": "%s", "amount": "50.00", "currency": "USD"}`, walletID1, walletID2)
+		resp, body := makeRequest(t, "POST", "/transfers", strings.NewReader(requestBody))
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		var conflict map[string]string
+		require.NoError(t, json.Unmarshal([]byte(body), &conflict))
+		assert.Equal(t, "duplicate_transfer", conflict["code"])
+		assert.NotEmpty(t, conflict["existing_transaction_id"])
+		assert.Equal(t, "/transactions/"+conflict["existing_transaction_id"], resp.Header.Get("Location"))
+
+		requestBody = fmt.Sprintf(`{"from_wallet_id": "%s", "to_wallet_id": "%s", "amount": "50.00", "currency": "USD", "force": true}`, walletID1, walletID2)
+		resp, body = makeRequest(t, "POST", "/transfers", strings.NewReader(requestBody))
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, body)
+	})
+
+	t.Run("QuotedTransfer", func(t *testing.T) {
+		requestBody := fmt.Sprintf(`{"from_wallet_id": "%s", "to_wallet_id": "%s", "amount": "20.00", "currency": "USD"}`, walletID1, walletID2)
+		resp, body := makeRequest(t, "POST", "/transfers/quote", strings.NewReader(requestBody))
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusCreated, resp.StatusCode, body)
+		var quote types.Response[map[string]any]
+		require.NoError(t, json.Unmarshal([]byte(body), &quote))
+		assert.Equal(t, "20.00", quote.Data["debit_amount"])
+		assert.Equal(t, "20.00", quote.Data["credit_amount"])
+		assert.Nil(t, quote.Data["rate"])
+
+		requestBody = fmt.Sprintf(`{"from_wallet_id": "%s", "to_wallet_id": "%s", "amount": "20.00", "currency": "USD", "quote_id": "%s"}`, walletID1, walletID2, quote.Data["id"])
+		resp, body = makeRequest(t, "POST", "/transfers", strings.NewReader(requestBody))
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, body)
+
+		// A quote is executed once
+		resp, body = makeRequest(t, "POST", "/transfers", strings.NewReader(requestBody))
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Contains(t, body, `"code":"quote_not_usable"`)
+	})
+
 	t.Run("SameWalletTransfer", func(t *testing.T) {
 		transferAmount := decimal.NewFromFloat(10.00)
-		requestBody := fmt.Sprintf(`{"from_wallet_id": %d, "to_wallet_id": %d, "amount": "%s", "currency": "USD"}`, walletID1, walletID1, transferAmount.String())
+		requestBody := fmt.Sprintf(`{"from_wallet_id": "%s", "to_wallet_id": "%s", "amount": "%s", "currency": "USD"}`, walletID1, walletID1, transferAmount.String())
 		resp, body := makeRequest(t, "POST", "/transfers", strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
@@ -294,7 +356,7 @@ func TestTransferIntegration(t *testing.T) {
 
 	t.Run("InsufficientFundsInSourceWallet", func(t *testing.T) {
 		transferAmount := decimal.NewFromFloat(200.00)
-		requestBody := fmt.Sprintf(`{"from_wallet_id": %d, "to_wallet_id": %d, "amount": "%s", "currency": "USD"}`, walletID2, walletID1, transferAmount.String())
+		requestBody := fmt.Sprintf(`{"from_wallet_id": "%s", "to_wallet_id": "%s", "amount": "%s", "currency": "USD"}`, walletID2, walletID1, transferAmount.String())
 		resp, body := makeRequest(t, "POST", "/transfers", strings.NewReader(requestBody))
 		defer resp.Body.Close()
 
@@ -310,17 +372,17 @@ func TestTransactionHistoryAndBalanceConsistency(t *testing.T) {
 
 	// Perform a series of operations
 	depositAmount1 := decimal.NewFromFloat(500.00)
-	respDeposit1, _ := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/deposit", walletID), strings.NewReader(fmt.Sprintf(`{"amount": "%s", "currency": "USD"}`, depositAmount1.String())))
+	respDeposit1, _ := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/deposit", walletID), strings.NewReader(fmt.Sprintf(`{"amount": "%s", "currency": "USD"}`, depositAmount1.String())))
 	defer respDeposit1.Body.Close()
 	time.Sleep(10 * time.Millisecond) // Ensure distinct transaction times
 
 	withdrawAmount := decimal.NewFromFloat(150.00)
-	respWithdraw, _ := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/withdraw", walletID), strings.NewReader(fmt.Sprintf(`{"amount": "%s", "currency": "USD"}`, withdrawAmount.String())))
+	respWithdraw, _ := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/withdraw", walletID), strings.NewReader(fmt.Sprintf(`{"amount": "%s", "currency": "USD"}`, withdrawAmount.String())))
 	defer respWithdraw.Body.Close()
 	time.Sleep(10 * time.Millisecond)
 
 	depositAmount2 := decimal.NewFromFloat(200.00)
-	respDeposit2, _ := makeRequest(t, "POST", fmt.Sprintf("/wallets/%d/deposit", walletID), strings.NewReader(fmt.Sprintf(`{"amount": "%s", "currency": "USD"}`, depositAmount2.String())))
+	respDeposit2, _ := makeRequest(t, "POST", fmt.Sprintf("/wallets/%s/deposit", walletID), strings.NewReader(fmt.Sprintf(`{"amount": "%s", "currency": "USD"}`, depositAmount2.String())))
 	defer respDeposit2.Body.Close()
 	time.Sleep(10 * time.Millisecond)
 
@@ -330,18 +392,18 @@ func TestTransactionHistoryAndBalanceConsistency(t *testing.T) {
 	require.NoError(t, errFmt, "Failed to parse expected final balance string")
 
 	// 1. Get current balance
-	respBalance, bodyBalance := makeRequest(t, "GET", fmt.Sprintf("/wallets/%d/balance", walletID), nil)
+	respBalance, bodyBalance := makeRequest(t, "GET", fmt.Sprintf("/wallets/%s/balance", walletID), nil)
 	defer respBalance.Body.Close()
 	assert.Equal(t, http.StatusOK, respBalance.StatusCode)
-	var balanceMap map[string]interface{}
-	err := json.Unmarshal([]byte(bodyBalance), &balanceMap)
+	var balanceResponse types.Response[map[string]any]
+	err := json.Unmarshal([]byte(bodyBalance), &balanceResponse)
 	require.NoError(t, err)
-	currentBalance, err := decimal.NewFromString(balanceMap["balance"].(string))
+	currentBalance, err := decimal.NewFromString(balanceResponse.Data["balance"].(string))
 	require.NoError(t, err)
 	assert.Equal(t, expectedFinalBalance, currentBalance, "Current balance should match expected final balance")
 
 	// 2. Get transaction history
-	respHistory, bodyHistory := makeRequest(t, "GET", fmt.Sprintf("/wallets/%d/transactions?limit=10&offset=0", walletID), nil)
+	respHistory, bodyHistory := makeRequest(t, "GET", fmt.Sprintf("/wallets/%s/transactions?limit=10&offset=0", walletID), nil)
 	defer respHistory.Body.Close()
 	assert.Equal(t, http.StatusOK, respHistory.StatusCode)
 
@@ -352,9 +414,9 @@ func TestTransactionHistoryAndBalanceConsistency(t *testing.T) {
 
 	transactionsData := historyResponse.Data
 	assert.Len(t, transactionsData, 3, "Should have 3 transactions")
-	assert.Equal(t, 10, historyResponse.Limit)
-	assert.Equal(t, 0, historyResponse.Offset)
-	assert.Equal(t, int64(3), historyResponse.TotalCount, "Total count should be 3") // Assert TotalCount
+	assert.Equal(t, 10, historyResponse.Meta.Limit)
+	assert.Equal(t, 0, historyResponse.Meta.Offset)
+	assert.Equal(t, int64(3), historyResponse.Meta.TotalCount, "Total count should be 3") // Assert TotalCount
 
 	// 3. Calculate balance from transaction history
 	calculatedBalanceFromHistory := decimal.NewFromInt(0) // Start calculation from 0
@@ -375,9 +437,9 @@ func TestTransactionHistoryAndBalanceConsistency(t *testing.T) {
 		case domain.TransactionTypeTransfer:
 			// For transfers, determine if it's an outgoing or incoming transfer
 			// Note: This assumes all transactions are related to the current walletID
-			if txMap["from_wallet_id"] != nil && int64(txMap["from_wallet_id"].(float64)) == walletID {
+			if txMap["from_wallet_id"] != nil && txMap["from_wallet_id"] == walletID.String() {
 				calculatedBalanceFromHistory = calculatedBalanceFromHistory.Sub(amount)
-			} else if txMap["to_wallet_id"] != nil && int64(txMap["to_wallet_id"].(float64)) == walletID {
+			} else if txMap["to_wallet_id"] != nil && txMap["to_wallet_id"] == walletID.String() {
 				calculatedBalanceFromHistory = calculatedBalanceFromHistory.Add(amount)
 			}
 		}
@@ -386,3 +448,40 @@ func TestTransactionHistoryAndBalanceConsistency(t *testing.T) {
 	// 4. Compare the two balances
 	assert.Equal(t, currentBalance, calculatedBalanceFromHistory, "Balance derived from history should match current balance")
 }
+
+// TestChangeFeedSettlementIntegration tests that the settlement of a PENDING transaction is held back from the
+// change feed by the time it settled, not by the time the transaction was created, even when a newer
+// transaction was inserted before it settled.
+func TestChangeFeedSettlementIntegration(t *testing.T) {
+	clearDatabase(t)
+	ctx := context.Background()
+	walletPublicID := createTestUserAndWallet(t, "feeduser", "USD", decimal.NewFromInt(100))
+	wallet, err := testApp.WalletRepository.GetWalletByPublicID(ctx, testApp.DB, walletPublicID)
+	require.NoError(t, err)
+
+	pending := domain.NewTransaction(&wallet.ID, nil, decimal.NewFromInt(10), "USD", domain.TransactionTypeWithdrawal, nil)
+	pending.Status = domain.TransactionStatusPending
+	pending.CreatedAt = pending.CreatedAt.Add(-time.Hour)
+	require.NoError(t, testApp.TransactionRepository.CreateTransaction(ctx, testApp.DB, pending))
+	newer := domain.NewTransaction(nil, &wallet.ID, decimal.NewFromInt(5), "USD", domain.TransactionTypeDeposit, nil)
+	newer.CreatedAt = newer.CreatedAt.Add(-time.Minute)
+	require.NoError(t, testApp.TransactionRepository.CreateTransaction(ctx, testApp.DB, newer))
+
+	time.Sleep(500 * time.Millisecond)
+	require.NoError(t, testApp.AsyncTransferRepository.CompleteAsyncTransfer(ctx, testApp.DB, pending))
+
+	// A call whose settle delay reaches back before the settlement sees the newer insert, but not the settlement
+	changes, err := testApp.ChangeRepository.ListTransactionChanges(ctx, testApp.DB, 0, repository.ChangeFilter{SettleDelay: 250 * time.Millisecond}, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, newer.PublicID, changes[0].PublicID)
+	cursor := changes[0].Seq
+
+	// The settlement follows the cursor once it has settled
+	changes, err = testApp.ChangeRepository.ListTransactionChanges(ctx, testApp.DB, cursor, repository.ChangeFilter{}, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, pending.PublicID, changes[0].PublicID)
+	assert.Equal(t, domain.TransactionStatusCompleted, changes[0].Status)
+	assert.Greater(t, changes[0].Seq, cursor)
+}
@@ -0,0 +1,354 @@
+// internal/api/contract_test.go
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// specPath is the OpenAPI contract of the API, relative to this package.
+const specPath = "../../api/openapi.json"
+
+// openAPISpec is the subset of an OpenAPI 3.0 document the contract tests read.
+type openAPISpec struct {
+	Paths      map[string]map[string]specOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]map[string]any `json:"schemas"`
+	} `json:"components"`
+}
+
+type specOperation struct {
+	OperationID string `json:"operationId"`
+	Parameters  []struct {
+		Name   string         `json:"name"`
+		In     string         `json:"in"`
+		Schema map[string]any `json:"schema"`
+	} `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Example any `json:"example"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema map[string]any `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+// contractCase is one request derived from an operation of the spec, with the status it must answer.
+type contractCase struct {
+	name      string
+	operation specOperation
+	method    string
+	path      string
+	body      string
+	status    int
+}
+
+// TestAPIContract replays requests derived from the OpenAPI spec against the test server and checks that each
+// gets a documented status code with a body matching the documented schema. Every operation is replayed with
+// its example request against the fixtures, expecting its success status; with unknown IDs when it documents
+// 404; and with a malformed body when it documents 400 and takes one.
+func TestAPIContract(t *testing.T) {
+	spec := loadSpec(t)
+	clearDatabase(t)
+	fixtures := contractFixtures(t)
+
+	t.Run("OperationsAreRouted", func(t *testing.T) {
+		routed := map[string]bool{}
+		err := chi.Walk(testApp.HTTPHandler.(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			routed[method+" "+strings.TrimSuffix(route, "/")] = true
+			return nil
+		})
+		require.NoError(t, err)
+
+		documented := 0
+		for path, operations := range spec.Paths {
+			for method := range operations {
+				key := strings.ToUpper(method) + " " + path
+				assert.True(t, routed[key], "%s is in the spec but not routed", key)
+				delete(routed, key)
+				documented++
+			}
+		}
+		t.Logf("%d operations documented, %d routes not in the spec yet", documented, len(routed))
+	})
+
+	for _, c := range contractCases(t, spec, fixtures) {
+		t.Run(c.name, func(t *testing.T) {
+			var body io.Reader
+			if c.body != "" {
+				body = strings.NewReader(c.body)
+			}
+			resp, respBody := makeRequest(t, c.method, c.path, body)
+			defer resp.Body.Close()
+
+			require.Equal(t, c.status, resp.StatusCode, "%s %s: %s", c.method, c.path, respBody)
+			content, ok := c.operation.Responses[strconv.Itoa(c.status)].Content["application/json"]
+			if !ok {
+				return
+			}
+			var decoded any
+			require.NoError(t, json.Unmarshal([]byte(respBody), &decoded), "the response is not JSON")
+			assert.Empty(t, spec.validate(content.Schema, decoded, "$"), "the response does not match the schema: %s", respBody)
+		})
+	}
+}
+
+func loadSpec(t *testing.T) *openAPISpec {
+	raw, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	var spec openAPISpec
+	require.NoError(t, json.Unmarshal(raw, &spec))
+	return &spec
+}
+
+// contractFixtures creates the records the requests of the spec refer to: a user with a funded wallet, a
+// second wallet to transfer to, and a transaction.
+func contractFixtures(t *testing.T) map[string]string {
+	walletID := createTestUserAndWallet(t, "contract_payer", "USD", decimal.NewFromInt(1000))
+	toWalletID := createTestUserAndWallet(t, "contract_payee", "USD", decimal.Zero)
+
+	resp, body := makeRequest(t, http.MethodGet, fmt.Sprintf("/wallets/%s", walletID), nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	var wallet struct {
+		Data struct {
+			UserID int64 `json:"user_id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &wallet))
+
+	resp, body = makeRequest(t, http.MethodPost, fmt.Sprintf("/wallets/%s/deposit", toWalletID), strings.NewReader(`{"amount": "5.00", "currency": "USD"}`))
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	var deposit struct {
+		Data struct {
+			TransactionID string `json:"transaction_id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &deposit))
+
+	return map[string]string{
+		"userID":        strconv.FormatInt(wallet.Data.UserID, 10),
+		"walletID":      walletID.String(),
+		"toWalletID":    toWalletID.String(),
+		"transactionID": deposit.Data.TransactionID,
+	}
+}
+
+// contractCases derives the requests to replay from the spec, in a stable order.
+func contractCases(t *testing.T, spec *openAPISpec, fixtures map[string]string) []contractCase {
+	var cases []contractCase
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		methods := make([]string, 0, len(spec.Paths[path]))
+		for method := range spec.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			operation := spec.Paths[path][method]
+			method = strings.ToUpper(method)
+
+			body := ""
+			if operation.RequestBody != nil {
+				example := substituteFixtures(operation.RequestBody.Content["application/json"].Example, fixtures)
+				raw, err := json.Marshal(example)
+				require.NoError(t, err)
+				body = string(raw)
+			}
+			success := 0
+			for code := range operation.Responses {
+				if status, _ := strconv.Atoi(code); status >= 200 && status < 300 && (success == 0 || status < success) {
+					success = status
+				}
+			}
+			require.NotZero(t, success, "%s %s documents no success status", method, path)
+			cases = append(cases, contractCase{
+				name:      operation.OperationID + "/Example",
+				operation: operation,
+				method:    method,
+				path:      expandPath(path, func(name string) string { return fixtures[name] }),
+				body:      body,
+				status:    success,
+			})
+
+			if _, ok := operation.Responses["404"]; ok && strings.Contains(path, "{") {
+				cases = append(cases, contractCase{
+					name:      operation.OperationID + "/UnknownID",
+					operation: operation,
+					method:    method,
+					path:      expandPath(path, func(name string) string { return unknownID(operation, name) }),
+					body:      body,
+					status:    http.StatusNotFound,
+				})
+			}
+			if _, ok := operation.Responses["400"]; ok && operation.RequestBody != nil {
+				cases = append(cases, contractCase{
+					name:      operation.OperationID + "/MalformedBody",
+					operation: operation,
+					method:    method,
+					path:      expandPath(path, func(name string) string { return fixtures[name] }),
+					body:      "{",
+					status:    http.StatusBadRequest,
+				})
+			}
+		}
+	}
+	return cases
+}
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// expandPath replaces the parameters of a spec path with their values.
+func expandPath(path string, value func(name string) string) string {
+	return pathParam.ReplaceAllStringFunc(path, func(param string) string {
+		return value(strings.Trim(param, "{}"))
+	})
+}
+
+// unknownID returns a well-formed ID of the parameter's type that matches no record.
+func unknownID(operation specOperation, name string) string {
+	for _, param := range operation.Parameters {
+		if param.Name == name && param.Schema["type"] == "integer" {
+			return "999999999"
+		}
+	}
+	return uuid.New().String()
+}
+
+// substituteFixtures replaces the strings of an example that name a fixture, e.g. "{walletID}", with its value.
+func substituteFixtures(example any, fixtures map[string]string) any {
+	switch v := example.(type) {
+	case string:
+		if name, ok := strings.CutPrefix(v, "{"); ok {
+			if value, ok := fixtures[strings.TrimSuffix(name, "}")]; ok {
+				return value
+			}
+		}
+		return v
+	case map[string]any:
+		substituted := make(map[string]any, len(v))
+		for key, value := range v {
+			substituted[key] = substituteFixtures(value, fixtures)
+		}
+		return substituted
+	case []any:
+		substituted := make([]any, len(v))
+		for i, value := range v {
+			substituted[i] = substituteFixtures(value, fixtures)
+		}
+		return substituted
+	}
+	return example
+}
+
+// validate checks a decoded JSON value against a schema of the spec and returns the mismatches. It supports
+// the keywords the spec uses: $ref, type, nullable, required, properties, additionalProperties, items, enum,
+// pattern and the uuid and date-time formats.
+func (spec *openAPISpec) validate(schema map[string]any, value any, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		resolved, ok := spec.Components.Schemas[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema %s", at, ref)}
+		}
+		return spec.validate(resolved, value, at)
+	}
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return nil
+		}
+		return []string{at + ": is null"}
+	}
+
+	var mismatches []string
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []string{at + ": is not an object"}
+		}
+		required, _ := schema["required"].([]any)
+		for _, field := range required {
+			if _, ok := object[field.(string)]; !ok {
+				mismatches = append(mismatches, fmt.Sprintf("%s: misses %s", at, field))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		for field, fieldValue := range object {
+			if property, ok := properties[field].(map[string]any); ok {
+				mismatches = append(mismatches, spec.validate(property, fieldValue, at+"."+field)...)
+			} else if additional != nil {
+				mismatches = append(mismatches, spec.validate(additional, fieldValue, at+"."+field)...)
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return []string{at + ": is not an array"}
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range array {
+			mismatches = append(mismatches, spec.validate(items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return []string{at + ": is not a string"}
+		}
+		if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, any(s)) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %q is not one of %v", at, s, enum))
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(s) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %q does not match %s", at, s, pattern))
+		}
+		switch schema["format"] {
+		case "uuid":
+			if _, err := uuid.Parse(s); err != nil {
+				mismatches = append(mismatches, fmt.Sprintf("%s: %q is not a UUID", at, s))
+			}
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				mismatches = append(mismatches, fmt.Sprintf("%s: %q is not an RFC 3339 date-time", at, s))
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return []string{at + ": is not an integer"}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{at + ": is not a number"}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{at + ": is not a boolean"}
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,218 @@
+// internal/api/handler/adjustment.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// AdjustmentHandler handles the admin requests for manual wallet adjustments. Every request needs a personal
+// admin token, as the proposer and the approver of an adjustment must be different admins.
+type AdjustmentHandler struct {
+	responder
+	adjustments service.AdjustmentService
+	wallets     service.WalletService
+	logger      *slog.Logger
+}
+
+// NewAdjustmentHandler creates a new AdjustmentHandler.
+func NewAdjustmentHandler(adjustments service.AdjustmentService, wallets service.WalletService, logger *slog.Logger) *AdjustmentHandler {
+	return &AdjustmentHandler{
+		responder:   responder{logger: logger},
+		adjustments: adjustments,
+		wallets:     wallets,
+		logger:      logger,
+	}
+}
+
+// ProposeAdjustmentRequest represents the request body for proposing a manual adjustment.
+type ProposeAdjustmentRequest struct {
+	WalletID   uuid.UUID                   `json:"wallet_id"`
+	Direction  domain.AdjustmentDirection  `json:"direction"` // CREDIT or DEBIT
+	Amount     decimal.Decimal             `json:"amount"`
+	ReasonCode domain.AdjustmentReasonCode `json:"reason_code"`
+	Note       *string                     `json:"note"` // Optional; becomes the posted transaction's support note
+}
+
+func (req *ProposeAdjustmentRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Amount}
+}
+
+// AdjustmentDecisionRequest represents the request body for approving or rejecting an adjustment; {} for no note.
+type AdjustmentDecisionRequest struct {
+	Note *string `json:"note"`
+}
+
+// ProposeAdjustment handles the propose adjustment request. Nothing is booked until another admin approves
+// it, so it yields 202 Accepted.
+// POST /admin/adjustments
+func (h *AdjustmentHandler) ProposeAdjustment(w http.ResponseWriter, r *http.Request) {
+	var req ProposeAdjustmentRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.WalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	wallet, err := h.wallets.GetWalletByPublicID(r.Context(), req.WalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	direction := domain.AdjustmentDirection(strings.ToUpper(strings.TrimSpace(string(req.Direction))))
+	reasonCode := domain.AdjustmentReasonCode(strings.ToUpper(strings.TrimSpace(string(req.ReasonCode))))
+	adjustment, err := h.adjustments.Propose(r.Context(), wallet, direction, req.Amount, reasonCode, req.Note, middleware.AdminFromContext(r.Context()))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusAccepted, formatAdjustment(adjustment), nil, adjustmentLinks(adjustment))
+}
+
+// ListAdjustments handles the list adjustments request, optionally for one status, e.g. ?status=PENDING for
+// the approval queue.
+// GET /admin/adjustments
+func (h *AdjustmentHandler) ListAdjustments(w http.ResponseWriter, r *http.Request) {
+	status := domain.AdjustmentStatus(strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("status"))))
+	adjustments, err := h.adjustments.ListAdjustments(r.Context(), status)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	data := make([]adjustmentResponse, len(adjustments))
+	for i := range adjustments {
+		data[i] = formatAdjustment(&adjustments[i])
+	}
+	h.respondWithData(w, http.StatusOK, data, nil, types.Links{"self": r.URL.String()})
+}
+
+// GetAdjustment handles the get adjustment request.
+// GET /admin/adjustments/{adjustmentID}
+func (h *AdjustmentHandler) GetAdjustment(w http.ResponseWriter, r *http.Request) {
+	adjustmentID, ok := h.adjustmentIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	adjustment, err := h.adjustments.GetAdjustment(r.Context(), adjustmentID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatAdjustment(adjustment), nil, adjustmentLinks(adjustment))
+}
+
+// ApproveAdjustment handles the approve adjustment request, which posts it as an ADJUSTMENT transaction. Its
+// proposer yields 403 Forbidden, and an adjustment that is no longer pending 409 Conflict.
+// POST /admin/adjustments/{adjustmentID}/approve
+func (h *AdjustmentHandler) ApproveAdjustment(w http.ResponseWriter, r *http.Request) {
+	adjustmentID, ok := h.adjustmentIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req AdjustmentDecisionRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	adjustment, _, err := h.adjustments.Approve(r.Context(), adjustmentID, middleware.AdminFromContext(r.Context()), req.Note)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatAdjustment(adjustment), nil, adjustmentLinks(adjustment))
+}
+
+// RejectAdjustment handles the reject adjustment request. Its proposer may reject it to withdraw it.
+// POST /admin/adjustments/{adjustmentID}/reject
+func (h *AdjustmentHandler) RejectAdjustment(w http.ResponseWriter, r *http.Request) {
+	adjustmentID, ok := h.adjustmentIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req AdjustmentDecisionRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	adjustment, err := h.adjustments.Reject(r.Context(), adjustmentID, middleware.AdminFromContext(r.Context()), req.Note)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatAdjustment(adjustment), nil, adjustmentLinks(adjustment))
+}
+
+func (h *AdjustmentHandler) adjustmentIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	adjustmentID, err := uuid.Parse(chi.URLParam(r, "adjustmentID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return uuid.Nil, false
+	}
+	return adjustmentID, true
+}
+
+func adjustmentLinks(adjustment *domain.Adjustment) types.Links {
+	links := types.Links{
+		"self":   fmt.Sprintf("/admin/adjustments/%s", adjustment.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", adjustment.WalletPublicID),
+	}
+	if adjustment.Status == domain.AdjustmentStatusPending {
+		links["approve"] = fmt.Sprintf("/admin/adjustments/%s/approve", adjustment.PublicID)
+		links["reject"] = fmt.Sprintf("/admin/adjustments/%s/reject", adjustment.PublicID)
+	}
+	if adjustment.TransactionID != nil {
+		links["transaction"] = fmt.Sprintf("/admin/transactions/%s", adjustment.TransactionID)
+	}
+	return links
+}
+
+// adjustmentResponse is a manual adjustment as rendered in API responses.
+type adjustmentResponse struct {
+	ID            uuid.UUID                   `json:"id"`
+	WalletID      uuid.UUID                   `json:"wallet_id"`
+	Direction     domain.AdjustmentDirection  `json:"direction"`
+	Amount        money                       `json:"amount"`
+	ReasonCode    domain.AdjustmentReasonCode `json:"reason_code"`
+	Note          *string                     `json:"note"`
+	Status        domain.AdjustmentStatus     `json:"status"`
+	ProposedBy    string                      `json:"proposed_by"`
+	DecidedBy     *string                     `json:"decided_by"`
+	DecisionNote  *string                     `json:"decision_note"`
+	TransactionID *uuid.UUID                  `json:"transaction_id"`
+	CreatedAt     time.Time                   `json:"created_at"`
+	DecidedAt     *time.Time                  `json:"decided_at"`
+}
+
+func formatAdjustment(adjustment *domain.Adjustment) adjustmentResponse {
+	return adjustmentResponse{
+		ID:            adjustment.PublicID,
+		WalletID:      adjustment.WalletPublicID,
+		Direction:     adjustment.Direction,
+		Amount:        newMoney(adjustment.Amount, adjustment.Currency),
+		ReasonCode:    adjustment.ReasonCode,
+		Note:          adjustment.Note,
+		Status:        adjustment.Status,
+		ProposedBy:    adjustment.ProposedBy,
+		DecidedBy:     adjustment.DecidedBy,
+		DecisionNote:  adjustment.DecisionNote,
+		TransactionID: adjustment.TransactionID,
+		CreatedAt:     adjustment.CreatedAt,
+		DecidedAt:     adjustment.DecidedAt,
+	}
+}
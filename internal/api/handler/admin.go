@@ -0,0 +1,348 @@
+// internal/api/handler/admin.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository/shadow"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// AdminHandler handles operator-only requests under /admin.
+type AdminHandler struct {
+	responder
+	maintenance *middleware.MaintenanceSwitch
+	flags       service.FeatureFlagService
+	queryTimer  *db.QueryTimer
+	poolMonitor *db.PoolMonitor
+	loadShedder *middleware.LoadShedder
+	shadow      *shadow.Harness // nil when shadow reads are disabled
+	rollouts    *service.Rollouts
+	logger      *slog.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler.
+func NewAdminHandler(maintenance *middleware.MaintenanceSwitch, flags service.FeatureFlagService, queryTimer *db.QueryTimer, poolMonitor *db.PoolMonitor, loadShedder *middleware.LoadShedder, shadowReads *shadow.Harness, rollouts *service.Rollouts, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		responder:   responder{logger: logger},
+		maintenance: maintenance,
+		flags:       flags,
+		queryTimer:  queryTimer,
+		poolMonitor: poolMonitor,
+		loadShedder: loadShedder,
+		shadow:      shadowReads,
+		rollouts:    rollouts,
+		logger:      logger,
+	}
+}
+
+// MaintenanceRequest represents the request body for switching maintenance mode.
+type MaintenanceRequest struct {
+	ReadOnly          bool   `json:"read_only"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+	Reason            string `json:"reason"`
+}
+
+// GetMaintenance handles the get maintenance mode request.
+// GET /admin/maintenance
+func (h *AdminHandler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	h.respondWithData(w, http.StatusOK, formatMaintenance(h.maintenance.State()), nil, types.Links{"self": r.URL.Path})
+}
+
+// SetMaintenance handles the switch maintenance mode request.
+// PUT /admin/maintenance
+func (h *AdminHandler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.RetryAfterSeconds < 0 {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	state := h.maintenance.Set(req.ReadOnly, time.Duration(req.RetryAfterSeconds)*time.Second, req.Reason)
+	h.logger.Warn("Maintenance mode changed", "read_only", state.ReadOnly, "reason", state.Reason)
+
+	h.respondWithData(w, http.StatusOK, formatMaintenance(state), nil, types.Links{"self": r.URL.Path})
+}
+
+// QueryTimingRequest represents the request body for switching query timing.
+type QueryTimingRequest struct {
+	Enabled         bool `json:"enabled"`
+	SlowThresholdMs *int `json:"slow_threshold_ms"` // Omitted keeps the current threshold; 0 stops slow query logging
+}
+
+// GetQueryTiming handles the get query timing request, returning the settings and the per-query latency histograms.
+// GET /admin/query-timing
+func (h *AdminHandler) GetQueryTiming(w http.ResponseWriter, r *http.Request) {
+	h.respondWithData(w, http.StatusOK, h.formatQueryTiming(), nil, types.Links{"self": r.URL.Path})
+}
+
+// SetQueryTiming handles the switch query timing request.
+// PUT /admin/query-timing
+func (h *AdminHandler) SetQueryTiming(w http.ResponseWriter, r *http.Request) {
+	var req QueryTimingRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.SlowThresholdMs != nil && *req.SlowThresholdMs < 0 {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	_, threshold := h.queryTimer.Settings()
+	if req.SlowThresholdMs != nil {
+		threshold = time.Duration(*req.SlowThresholdMs) * time.Millisecond
+	}
+	h.queryTimer.Set(req.Enabled, threshold)
+	h.logger.Warn("Query timing changed", "enabled", req.Enabled, "slow_threshold", threshold)
+
+	h.respondWithData(w, http.StatusOK, h.formatQueryTiming(), nil, types.Links{"self": r.URL.Path})
+}
+
+// ResetQueryTiming handles the reset query latency histograms request.
+// DELETE /admin/query-timing/stats
+func (h *AdminHandler) ResetQueryTiming(w http.ResponseWriter, r *http.Request) {
+	h.queryTimer.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDBPool handles the get connection pool request, returning the last sample of the pool statistics.
+// GET /admin/db-pool
+func (h *AdminHandler) GetDBPool(w http.ResponseWriter, r *http.Request) {
+	sample := h.poolMonitor.Last()
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"sampled_at":           sample.At,
+		"max_open_connections": sample.Stats.MaxOpenConnections,
+		"open_connections":     sample.Stats.OpenConnections,
+		"in_use":               sample.Stats.InUse,
+		"idle":                 sample.Stats.Idle,
+		"wait_count":           sample.Stats.WaitCount,
+		"wait_duration_ms":     sample.Stats.WaitDuration.Milliseconds(),
+		"interval_waits":       sample.Waits,
+		"average_wait_ms":      float64(sample.AverageWait()) / float64(time.Millisecond),
+	}, nil, types.Links{"self": r.URL.Path, "metrics": r.URL.Path + "/metrics"})
+}
+
+// GetDBPoolMetrics handles the connection pool metrics request, returning the last sample in the Prometheus text
+// exposition format for scraping.
+// GET /admin/db-pool/metrics
+func (h *AdminHandler) GetDBPoolMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := h.poolMonitor.WritePrometheus(w); err != nil {
+		h.logger.Warn("Failed to write connection pool metrics", "error", err)
+	}
+}
+
+// GetLoadShedding handles the get load shedding request, returning the limits and counters of each route class.
+// GET /admin/load-shedding
+func (h *AdminHandler) GetLoadShedding(w http.ResponseWriter, r *http.Request) {
+	classes := []map[string]any{}
+	for _, stats := range h.loadShedder.Stats() {
+		classes = append(classes, map[string]any{
+			"class":          stats.Class,
+			"max_concurrent": stats.Limit.MaxConcurrent,
+			"max_queue":      stats.Limit.MaxQueue,
+			"max_wait_ms":    stats.Limit.MaxWait.Milliseconds(),
+			"in_flight":      stats.InFlight,
+			"queued":         stats.Queued,
+			"max_queued":     stats.MaxQueued,
+			"admitted":       stats.Admitted,
+			"shed": map[string]any{
+				"queue_full":     stats.ShedQueueFull,
+				"timeout":        stats.ShedTimeout,
+				"pool_saturated": stats.ShedPoolSaturated,
+			},
+		})
+	}
+	h.respondWithData(w, http.StatusOK, map[string]any{"classes": classes}, nil, types.Links{"self": r.URL.Path})
+}
+
+// GetShadowReads handles the get shadow reads request, returning per repository operation how many reads were
+// compared with the candidate implementation, how many diverged, and the results of the last divergence.
+// GET /admin/shadow-reads
+func (h *AdminHandler) GetShadowReads(w http.ResponseWriter, r *http.Request) {
+	operations := []shadow.OperationStats{}
+	if h.shadow != nil {
+		operations = h.shadow.Stats()
+	}
+	h.respondWithData(w, http.StatusOK, map[string]any{"enabled": h.shadow != nil, "operations": operations}, nil, types.Links{"self": r.URL.Path})
+}
+
+// FeatureFlagRequest represents the request body for creating or replacing a feature flag.
+type FeatureFlagRequest struct {
+	Description    string  `json:"description"`
+	Enabled        bool    `json:"enabled"`
+	UserIDs        []int64 `json:"user_ids"`
+	RolloutPercent int     `json:"rollout_percent"`
+	RolloutBy      string  `json:"rollout_by"` // user (default) or wallet
+	Value          string  `json:"value"`
+}
+
+// ListFeatureFlags handles the list feature flags request.
+// GET /admin/feature-flags
+func (h *AdminHandler) ListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := h.flags.ListFlags(r.Context())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, flags, nil, types.Links{"self": r.URL.Path})
+}
+
+// GetFeatureFlag handles the get feature flag request.
+// GET /admin/feature-flags/{key}
+func (h *AdminHandler) GetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	flag, err := h.flags.GetFlag(r.Context(), chi.URLParam(r, "key"))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, flag, nil, featureFlagLinks(flag.Key))
+}
+
+// PutFeatureFlag handles the create or replace feature flag request.
+// PUT /admin/feature-flags/{key}
+func (h *AdminHandler) PutFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	var req FeatureFlagRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	flag := &domain.FeatureFlag{
+		Key:            chi.URLParam(r, "key"),
+		Description:    req.Description,
+		Enabled:        req.Enabled,
+		UserIDs:        req.UserIDs,
+		RolloutPercent: req.RolloutPercent,
+		RolloutBy:      domain.RolloutBy(req.RolloutBy),
+		Value:          req.Value,
+	}
+	if flag.UserIDs == nil {
+		flag.UserIDs = []int64{}
+	}
+	if err := h.flags.SaveFlag(r.Context(), flag); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, flag, nil, featureFlagLinks(flag.Key))
+}
+
+// DeleteFeatureFlag handles the delete feature flag request.
+// DELETE /admin/feature-flags/{key}
+func (h *AdminHandler) DeleteFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	if err := h.flags.DeleteFlag(r.Context(), chi.URLParam(r, "key")); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EvaluateFeatureFlag handles the evaluate feature flag request, showing how a flag resolves for one user,
+// and for one of their wallets if wallet_id is given.
+// GET /admin/feature-flags/{key}/evaluation?user_id={userID}&wallet_id={walletID}
+func (h *AdminHandler) EvaluateFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.URL.Query().Get("user_id"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	var walletID int64
+	if raw := r.URL.Query().Get("wallet_id"); raw != "" {
+		if walletID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			h.respondWithError(w, r, util.ErrInvalidInput)
+			return
+		}
+	}
+
+	decision := h.flags.EvaluateFor(r.Context(), chi.URLParam(r, "key"), domain.FlagSubject{UserID: userID, WalletID: walletID})
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"key":       decision.Key,
+		"user_id":   userID,
+		"wallet_id": walletID,
+		"enabled":   decision.Enabled,
+		"value":     decision.Value,
+		"reason":    decision.Reason,
+	}, nil, featureFlagLinks(decision.Key))
+}
+
+// GetRollouts handles the get rollouts request, comparing the control and canary code paths of each flagged
+// change called since startup.
+// GET /admin/rollouts
+func (h *AdminHandler) GetRollouts(w http.ResponseWriter, r *http.Request) {
+	rollouts := []map[string]any{}
+	for _, stats := range h.rollouts.Stats() {
+		rollouts = append(rollouts, map[string]any{
+			"flag":       stats.Flag,
+			"variant":    stats.Variant,
+			"calls":      stats.Calls,
+			"errors":     stats.Errors,
+			"conflicts":  stats.Conflicts,
+			"average_ms": float64(stats.AverageLatency()) / float64(time.Millisecond),
+			"max_ms":     float64(stats.Max) / float64(time.Millisecond),
+		})
+	}
+	h.respondWithData(w, http.StatusOK, rollouts, nil, types.Links{"self": r.URL.Path, "metrics": "/admin/rollouts/metrics"})
+}
+
+// GetRolloutMetrics handles the rollout metrics request, returning the measures of GetRollouts in the Prometheus
+// text exposition format for scraping.
+// GET /admin/rollouts/metrics
+func (h *AdminHandler) GetRolloutMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := h.rollouts.WritePrometheus(w); err != nil {
+		h.logger.Warn("Failed to write rollout metrics", "error", err)
+	}
+}
+
+func featureFlagLinks(key string) types.Links {
+	return types.Links{"self": "/admin/feature-flags/" + url.PathEscape(key)}
+}
+
+func formatMaintenance(state middleware.MaintenanceState) map[string]any {
+	return map[string]any{
+		"read_only":           state.ReadOnly,
+		"retry_after_seconds": int(state.RetryAfter.Seconds()),
+		"reason":              state.Reason,
+		"since":               state.Since,
+	}
+}
+
+func (h *AdminHandler) formatQueryTiming() map[string]any {
+	enabled, threshold := h.queryTimer.Settings()
+	queries := []map[string]any{}
+	for _, stats := range h.queryTimer.Stats() {
+		buckets := make([]map[string]any, 0, len(stats.Buckets))
+		for i, count := range stats.Buckets {
+			bucket := map[string]any{"le_ms": nil, "count": count} // The last bucket is unbounded
+			if i < len(db.LatencyBuckets) {
+				bucket["le_ms"] = float64(db.LatencyBuckets[i]) / float64(time.Millisecond)
+			}
+			buckets = append(buckets, bucket)
+		}
+		queries = append(queries, map[string]any{
+			"name":     stats.Name,
+			"count":    stats.Count,
+			"total_ms": float64(stats.Total) / float64(time.Millisecond),
+			"max_ms":   float64(stats.Max) / float64(time.Millisecond),
+			"buckets":  buckets,
+		})
+	}
+	return map[string]any{
+		"enabled":           enabled,
+		"slow_threshold_ms": threshold.Milliseconds(),
+		"queries":           queries,
+	}
+}
@@ -0,0 +1,100 @@
+// internal/api/handler/annotation.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// AnnotationHandler handles the support-only HTTP requests for transaction annotations. Its routes
+// require the admin token.
+type AnnotationHandler struct {
+	responder
+	annotations service.AnnotationService
+	wallets     service.WalletService
+	logger      *slog.Logger
+}
+
+// NewAnnotationHandler creates a new AnnotationHandler.
+func NewAnnotationHandler(annotations service.AnnotationService, wallets service.WalletService, logger *slog.Logger) *AnnotationHandler {
+	return &AnnotationHandler{
+		responder:   responder{logger: logger},
+		annotations: annotations,
+		wallets:     wallets,
+		logger:      logger,
+	}
+}
+
+// AnnotationRequest represents the request body for annotating a transaction. Omitted fields are left unchanged.
+type AnnotationRequest struct {
+	Note      *string   `json:"note"`     // An empty note clears it
+	CaseIDs   *[]string `json:"case_ids"` // Replaces the case references
+	UpdatedBy *string   `json:"updated_by"`
+}
+
+// AnnotateTransaction handles the annotate transaction request.
+// PATCH /transactions/{transactionID}/annotations
+func (h *AnnotationHandler) AnnotateTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := uuid.Parse(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	var req AnnotationRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	annotation, err := h.annotations.AnnotateTransaction(r.Context(), transactionID, domain.TransactionAnnotationPatch{
+		Note:      req.Note,
+		CaseIDs:   req.CaseIDs,
+		UpdatedBy: req.UpdatedBy,
+	})
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, annotation, nil, annotationLinks(transactionID))
+}
+
+// GetAdminTransaction handles the admin view of a transaction, which includes its annotation.
+// GET /admin/transactions/{transactionID}
+func (h *AnnotationHandler) GetAdminTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := uuid.Parse(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	transaction, err := h.wallets.GetTransaction(r.Context(), transactionID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	annotation, err := h.annotations.GetAnnotation(r.Context(), transactionID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	formatted := formatTransaction(transaction)
+	formatted["annotation"] = annotation // null when the transaction has none
+	h.respondWithData(w, http.StatusOK, formatted, nil, annotationLinks(transactionID))
+}
+
+func annotationLinks(transactionID uuid.UUID) types.Links {
+	return types.Links{
+		"self":        fmt.Sprintf("/admin/transactions/%s", transactionID),
+		"annotations": fmt.Sprintf("/transactions/%s/annotations", transactionID),
+		"transaction": fmt.Sprintf("/transactions/%s", transactionID),
+	}
+}
@@ -0,0 +1,99 @@
+// internal/api/handler/audit.go
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// AuditHandler handles the audit trail of wallets. Its routes require the admin token.
+type AuditHandler struct {
+	responder
+	audit   service.AuditService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewAuditHandler creates a new AuditHandler.
+func NewAuditHandler(audit service.AuditService, wallets service.WalletService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		responder: responder{logger: logger},
+		audit:     audit,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// auditEventResponse shows an audit event with its payload as JSON rather than as the string it is hashed as.
+type auditEventResponse struct {
+	*domain.AuditEvent
+	Payload json.RawMessage `json:"payload"`
+}
+
+// ListAuditEvents handles the list audit events request. Events are returned in chain order from the sequence
+// number after the after parameter; meta carries the cursor of the next page when there may be one.
+// GET /admin/wallets/{walletID}/audit
+func (h *AuditHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	var after int64
+	if raw := r.URL.Query().Get("after"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			h.respondWithError(w, r, fmt.Errorf("%w: after must be a sequence number", util.ErrInvalidInput))
+			return
+		}
+		after = parsed
+	}
+	limit := listLimit(r)
+
+	events, err := h.audit.ListEvents(r.Context(), wallet.ID, after, limit)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	items := make([]auditEventResponse, len(events))
+	for i := range events {
+		items[i] = auditEventResponse{AuditEvent: &events[i], Payload: json.RawMessage(events[i].Payload)}
+	}
+	meta := map[string]any{"count": len(items)}
+	if len(items) == limit {
+		meta["next_after"] = items[len(items)-1].Sequence
+	}
+	h.respondWithData(w, http.StatusOK, items, meta, types.Links{
+		"self":   r.URL.Path,
+		"verify": fmt.Sprintf("/admin/wallets/%s/audit/verify", wallet.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	})
+}
+
+// VerifyAuditChain handles the verify audit chain request. The verification is returned with 200 whether the
+// chain is intact or not; broken_at and reason say where and why it breaks.
+// GET /admin/wallets/{walletID}/audit/verify
+func (h *AuditHandler) VerifyAuditChain(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	verification, err := h.audit.VerifyChain(r.Context(), wallet)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, verification, nil, types.Links{
+		"self":   r.URL.Path,
+		"events": fmt.Sprintf("/admin/wallets/%s/audit", wallet.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	})
+}
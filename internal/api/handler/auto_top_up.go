@@ -0,0 +1,218 @@
+// internal/api/handler/auto_top_up.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// AutoTopUpHandler handles HTTP requests for a wallet's funding sources and auto top-up rule.
+type AutoTopUpHandler struct {
+	responder
+	topUps  service.AutoTopUpService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewAutoTopUpHandler creates a new AutoTopUpHandler.
+func NewAutoTopUpHandler(topUps service.AutoTopUpService, wallets service.WalletService, logger *slog.Logger) *AutoTopUpHandler {
+	return &AutoTopUpHandler{
+		responder: responder{logger: logger},
+		topUps:    topUps,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// FundingSourceRequest represents the request body for linking a funding source.
+type FundingSourceRequest struct {
+	Provider  string  `json:"provider"`  // The gateway's payment method type, e.g. "card"
+	Reference string  `json:"reference"` // The gateway's token for the payment method
+	Label     *string `json:"label"`     // Optional display name
+}
+
+// AutoTopUpRequest represents the request body for setting a wallet's auto top-up rule.
+type AutoTopUpRequest struct {
+	FundingSourceID int64           `json:"funding_source_id"`
+	Threshold       decimal.Decimal `json:"threshold"`
+	Amount          decimal.Decimal `json:"amount"`
+}
+
+func (req *AutoTopUpRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Threshold, req.Amount}
+}
+
+// ListFundingSources handles the list funding sources request.
+// GET /wallets/{walletID}/funding-sources
+func (h *AutoTopUpHandler) ListFundingSources(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	sources, err := h.topUps.ListFundingSources(r.Context(), wallet.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, sources, nil, fundingSourceLinks(wallet))
+}
+
+// AddFundingSource handles the link funding source request. The payment method must already be tokenized
+// by the payments gateway; only its reference is sent and stored.
+// POST /wallets/{walletID}/funding-sources
+func (h *AutoTopUpHandler) AddFundingSource(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req FundingSourceRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	source := &domain.FundingSource{
+		WalletID:       wallet.ID,
+		WalletPublicID: wallet.PublicID,
+		Provider:       req.Provider,
+		Reference:      req.Reference,
+		Label:          req.Label,
+	}
+	if err := h.topUps.AddFundingSource(r.Context(), source); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, source, nil, fundingSourceLinks(wallet))
+}
+
+// RemoveFundingSource handles the unlink funding source request. A source used by the auto top-up rule
+// yields 409 Conflict until the rule is changed or deleted.
+// DELETE /wallets/{walletID}/funding-sources/{sourceID}
+func (h *AutoTopUpHandler) RemoveFundingSource(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	sourceID, err := strconv.ParseInt(chi.URLParam(r, "sourceID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	if err := h.topUps.RemoveFundingSource(r.Context(), wallet.ID, sourceID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAutoTopUp handles the get auto top-up rule request.
+// GET /wallets/{walletID}/auto-top-up
+func (h *AutoTopUpHandler) GetAutoTopUp(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	rule, err := h.topUps.GetRule(r.Context(), wallet)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatAutoTopUpRule(rule), nil, autoTopUpLinks(wallet))
+}
+
+// SetAutoTopUp handles the set auto top-up rule request. Setting the rule again re-enables one that was
+// disabled by repeated failures.
+// PUT /wallets/{walletID}/auto-top-up
+func (h *AutoTopUpHandler) SetAutoTopUp(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req AutoTopUpRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	rule, err := h.topUps.SetRule(r.Context(), wallet, req.FundingSourceID, req.Threshold, req.Amount)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatAutoTopUpRule(rule), nil, autoTopUpLinks(wallet))
+}
+
+// DeleteAutoTopUp handles the delete auto top-up rule request.
+// DELETE /wallets/{walletID}/auto-top-up
+func (h *AutoTopUpHandler) DeleteAutoTopUp(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.topUps.DeleteRule(r.Context(), wallet); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func fundingSourceLinks(wallet *domain.Wallet) types.Links {
+	return types.Links{
+		"self":        fmt.Sprintf("/wallets/%s/funding-sources", wallet.PublicID),
+		"auto_top_up": fmt.Sprintf("/wallets/%s/auto-top-up", wallet.PublicID),
+		"wallet":      fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	}
+}
+
+func autoTopUpLinks(wallet *domain.Wallet) types.Links {
+	return types.Links{
+		"self":            fmt.Sprintf("/wallets/%s/auto-top-up", wallet.PublicID),
+		"funding_sources": fmt.Sprintf("/wallets/%s/funding-sources", wallet.PublicID),
+		"wallet":          fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	}
+}
+
+// autoTopUpRuleResponse is an auto top-up rule as rendered in API responses, with amounts in the wallet's currency.
+type autoTopUpRuleResponse struct {
+	WalletID            uuid.UUID  `json:"wallet_id"`
+	FundingSourceID     int64      `json:"funding_source_id"`
+	Threshold           money      `json:"threshold"`
+	Amount              money      `json:"amount"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastAttemptAt       *time.Time `json:"last_attempt_at"`
+	LastError           *string    `json:"last_error"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func formatAutoTopUpRule(rule *domain.AutoTopUpRule) autoTopUpRuleResponse {
+	return autoTopUpRuleResponse{
+		WalletID:            rule.WalletPublicID,
+		FundingSourceID:     rule.FundingSourceID,
+		Threshold:           newMoney(rule.Threshold, rule.Currency),
+		Amount:              newMoney(rule.Amount, rule.Currency),
+		Enabled:             rule.Enabled,
+		ConsecutiveFailures: rule.ConsecutiveFailures,
+		LastAttemptAt:       rule.LastAttemptAt,
+		LastError:           rule.LastError,
+		CreatedAt:           rule.CreatedAt,
+		UpdatedAt:           rule.UpdatedAt,
+	}
+}
@@ -0,0 +1,120 @@
+// internal/api/handler/balance_shard.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+)
+
+// BalanceShardHandler handles the admin requests for the balance shards of high-throughput wallets.
+type BalanceShardHandler struct {
+	responder
+	shards  service.BalanceShardService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewBalanceShardHandler creates a new BalanceShardHandler.
+func NewBalanceShardHandler(shards service.BalanceShardService, wallets service.WalletService, logger *slog.Logger) *BalanceShardHandler {
+	return &BalanceShardHandler{
+		responder: responder{logger: logger},
+		shards:    shards,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// BalanceShardsRequest represents the request body for sharding the balance of a wallet.
+type BalanceShardsRequest struct {
+	Shards int `json:"shards"` // From 2 to 64; 0 merges the shards back
+}
+
+// ListBalanceShards handles the list balance shards request: every sharded wallet with the balance of each shard.
+// GET /admin/balance-shards
+func (h *BalanceShardHandler) ListBalanceShards(w http.ResponseWriter, r *http.Request) {
+	wallets, err := h.shards.ListShards(r.Context())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	formatted := make([]map[string]any, len(wallets))
+	for i := range wallets {
+		formatted[i] = formatWalletShards(&wallets[i])
+	}
+	h.respondWithData(w, http.StatusOK, formatted, nil, types.Links{"self": "/admin/balance-shards"})
+}
+
+// SetBalanceShards handles the set balance shards request, splitting the wallet's balance into the given number
+// of shards and returning the wallet with its new shards.
+// PUT /admin/wallets/{walletID}/balance-shards
+func (h *BalanceShardHandler) SetBalanceShards(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	var req BalanceShardsRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	if err := h.shards.SetShards(r.Context(), wallet.ID, req.Shards, middleware.AdminFromContext(r.Context())); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	wallets, err := h.shards.ListShards(r.Context())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	sharded := domain.WalletShards{WalletID: wallet.ID, WalletPublicID: wallet.PublicID}
+	for _, candidate := range wallets {
+		if candidate.WalletID == wallet.ID {
+			sharded = candidate
+		}
+	}
+	h.respondWithData(w, http.StatusOK, formatWalletShards(&sharded), nil, types.Links{
+		"self":   fmt.Sprintf("/admin/wallets/%s/balance-shards", wallet.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	})
+}
+
+// DeleteBalanceShards handles the delete balance shards request, merging the shards back into the wallet's balance.
+// DELETE /admin/wallets/{walletID}/balance-shards
+func (h *BalanceShardHandler) DeleteBalanceShards(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	if err := h.shards.SetShards(r.Context(), wallet.ID, 0, middleware.AdminFromContext(r.Context())); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// formatWalletShards renders a sharded wallet for API responses. Its total is that of the shards alone; the
+// wallet's balance also counts what movements put on the wallet itself since the last rebalance.
+func formatWalletShards(wallet *domain.WalletShards) map[string]any {
+	total := decimal.Zero
+	for _, shard := range wallet.Shards {
+		total = total.Add(shard.Balance)
+	}
+	shards := wallet.Shards
+	if shards == nil {
+		shards = []domain.BalanceShard{}
+	}
+	return map[string]any{
+		"wallet_id":    wallet.WalletPublicID,
+		"shard_count":  len(wallet.Shards),
+		"shards_total": total,
+		"shards":       shards,
+	}
+}
@@ -0,0 +1,292 @@
+// internal/api/handler/bill.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// BillHandler handles HTTP requests for shared bills.
+type BillHandler struct {
+	responder
+	bills   service.BillService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewBillHandler creates a new BillHandler.
+func NewBillHandler(bills service.BillService, wallets service.WalletService, logger *slog.Logger) *BillHandler {
+	return &BillHandler{
+		responder: responder{logger: logger},
+		bills:     bills,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// BillRequest represents the request body for creating a bill. Participants are split like the
+// destinations of a split transfer: all by fixed amount, or all by percentage share of Amount.
+type BillRequest struct {
+	Amount       decimal.Decimal    `json:"amount"` // Total; required when splitting by share
+	Description  *string            `json:"description"`
+	Participants []SplitDestination `json:"participants"`
+}
+
+func (req *BillRequest) amountFields() []decimal.Decimal {
+	amounts := []decimal.Decimal{req.Amount}
+	for _, destination := range req.Participants {
+		amounts = append(amounts, destination.Amount)
+	}
+	return amounts
+}
+
+// BillParticipantRequest represents the request body for approving or paying a share of a bill.
+type BillParticipantRequest struct {
+	WalletID uuid.UUID       `json:"wallet_id"`
+	Amount   decimal.Decimal `json:"amount"`   // Pay only; defaults to the whole outstanding share
+	Category string          `json:"category"` // Pay only; optional spending category
+}
+
+func (req *BillParticipantRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Amount}
+}
+
+// CreateBill handles the create bill request. The wallet in the path is the one the participants pay.
+// POST /wallets/{walletID}/bills
+func (h *BillHandler) CreateBill(w http.ResponseWriter, r *http.Request) {
+	owner, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req BillRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if len(req.Participants) > domain.MaxSplitLegs {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	split := domain.Split{Currency: owner.Currency, Total: req.Amount, Legs: make([]domain.SplitLeg, 0, len(req.Participants))}
+	for _, participant := range req.Participants {
+		wallet, err := h.wallets.GetWalletByPublicID(r.Context(), participant.WalletID)
+		if err != nil {
+			h.respondWithError(w, r, err)
+			return
+		}
+		split.Legs = append(split.Legs, domain.SplitLeg{ToWalletID: wallet.ID, Amount: participant.Amount, Share: participant.Share})
+	}
+
+	bill, err := h.bills.CreateBill(r.Context(), owner, split, req.Description)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	links := billLinks(bill)
+	w.Header().Set("Location", links["self"])
+	h.respondWithData(w, http.StatusCreated, formatBill(bill), nil, links)
+}
+
+// ListBills handles the list bills request: the bills the wallet owns or takes part in.
+// GET /wallets/{walletID}/bills
+func (h *BillHandler) ListBills(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	bills, err := h.bills.ListBills(r.Context(), wallet.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	data := make([]billResponse, len(bills))
+	for i := range bills {
+		data[i] = formatBill(&bills[i])
+	}
+	h.respondWithData(w, http.StatusOK, data, nil, types.Links{
+		"self":   fmt.Sprintf("/wallets/%s/bills", wallet.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	})
+}
+
+// GetBill handles the get bill request.
+// GET /bills/{billID}
+func (h *BillHandler) GetBill(w http.ResponseWriter, r *http.Request) {
+	billID, ok := h.billIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	bill, err := h.bills.GetBill(r.Context(), billID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatBill(bill), billMeta(bill), billLinks(bill))
+}
+
+// ApproveShare handles a participant's approval of their share.
+// POST /bills/{billID}/approve
+func (h *BillHandler) ApproveShare(w http.ResponseWriter, r *http.Request) {
+	billID, participant, _, ok := h.decodeParticipantRequest(w, r)
+	if !ok {
+		return
+	}
+
+	bill, err := h.bills.ApproveShare(r.Context(), billID, participant.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatBill(bill), billMeta(bill), billLinks(bill))
+}
+
+// PayShare handles a participant's payment towards their share. Partial payments are accepted.
+// POST /bills/{billID}/pay
+func (h *BillHandler) PayShare(w http.ResponseWriter, r *http.Request) {
+	billID, participant, req, ok := h.decodeParticipantRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := service.WithTransactionCategory(r.Context(), req.Category)
+	bill, transaction, err := h.bills.PayShare(ctx, billID, participant.ID, req.Amount)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	links := billLinks(bill)
+	links["transaction"] = fmt.Sprintf("/transactions/%s", transaction.PublicID)
+	meta := billMeta(bill)
+	meta["message"] = "Payment successful"
+	h.respondWithData(w, http.StatusOK, formatBill(bill), meta, links)
+}
+
+// CancelBill handles the owner's cancellation of an open bill.
+// POST /bills/{billID}/cancel
+func (h *BillHandler) CancelBill(w http.ResponseWriter, r *http.Request) {
+	billID, ok := h.billIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	bill, err := h.bills.CancelBill(r.Context(), billID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatBill(bill), billMeta(bill), billLinks(bill))
+}
+
+// billIDFromPath parses the {billID} path parameter. On failure it writes the error response and reports false.
+func (h *BillHandler) billIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	billID, err := uuid.Parse(chi.URLParam(r, "billID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return uuid.Nil, false
+	}
+	return billID, true
+}
+
+// decodeParticipantRequest parses the bill ID and the request body and resolves the participant wallet.
+// On failure it writes the error response and reports false.
+func (h *BillHandler) decodeParticipantRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, *domain.Wallet, BillParticipantRequest, bool) {
+	var req BillParticipantRequest
+	billID, ok := h.billIDFromPath(w, r)
+	if !ok {
+		return uuid.Nil, nil, req, false
+	}
+	if !h.decodeRequest(w, r, &req) {
+		return uuid.Nil, nil, req, false
+	}
+	if req.WalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return uuid.Nil, nil, req, false
+	}
+	participant, err := h.wallets.GetWalletByPublicID(r.Context(), req.WalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return uuid.Nil, nil, req, false
+	}
+	return billID, participant, req, true
+}
+
+// billMeta reports the payment progress of a bill.
+func billMeta(bill *domain.Bill) map[string]any {
+	paid := bill.PaidAmount()
+	return map[string]any{
+		"paid_amount":        newMoney(paid, bill.Currency),
+		"outstanding_amount": newMoney(bill.Amount.Sub(paid), bill.Currency),
+	}
+}
+
+// billResponse is a bill as rendered in API responses.
+type billResponse struct {
+	ID            uuid.UUID                 `json:"id"`
+	OwnerWalletID uuid.UUID                 `json:"owner_wallet_id"`
+	Amount        money                     `json:"amount"`
+	Currency      string                    `json:"currency"`
+	Description   *string                   `json:"description"`
+	Status        domain.BillStatus         `json:"status"`
+	Participants  []billParticipantResponse `json:"participants"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	UpdatedAt     time.Time                 `json:"updated_at"`
+}
+
+// billParticipantResponse is a participant's share of a bill, in the bill's currency.
+type billParticipantResponse struct {
+	WalletID       uuid.UUID                `json:"wallet_id"`
+	ShareAmount    money                    `json:"share_amount"`
+	PaidAmount     money                    `json:"paid_amount"`
+	Status         domain.ParticipantStatus `json:"status"`
+	ApprovedAt     *time.Time               `json:"approved_at"`
+	LastRemindedAt *time.Time               `json:"last_reminded_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+func formatBill(bill *domain.Bill) billResponse {
+	participants := make([]billParticipantResponse, len(bill.Participants))
+	for i, participant := range bill.Participants {
+		participants[i] = billParticipantResponse{
+			WalletID:       participant.WalletPublicID,
+			ShareAmount:    newMoney(participant.ShareAmount, bill.Currency),
+			PaidAmount:     newMoney(participant.PaidAmount, bill.Currency),
+			Status:         participant.Status,
+			ApprovedAt:     participant.ApprovedAt,
+			LastRemindedAt: participant.LastRemindedAt,
+			UpdatedAt:      participant.UpdatedAt,
+		}
+	}
+	return billResponse{
+		ID:            bill.PublicID,
+		OwnerWalletID: bill.OwnerWalletPublicID,
+		Amount:        newMoney(bill.Amount, bill.Currency),
+		Currency:      bill.Currency,
+		Description:   bill.Description,
+		Status:        bill.Status,
+		Participants:  participants,
+		CreatedAt:     bill.CreatedAt,
+		UpdatedAt:     bill.UpdatedAt,
+	}
+}
+
+func billLinks(bill *domain.Bill) types.Links {
+	return types.Links{
+		"self":    fmt.Sprintf("/bills/%s", bill.PublicID),
+		"approve": fmt.Sprintf("/bills/%s/approve", bill.PublicID),
+		"pay":     fmt.Sprintf("/bills/%s/pay", bill.PublicID),
+		"owner":   fmt.Sprintf("/wallets/%s", bill.OwnerWalletPublicID),
+	}
+}
@@ -0,0 +1,178 @@
+// internal/api/handler/budget.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// BudgetHandler handles HTTP requests for a wallet's spending budgets and their consumption.
+type BudgetHandler struct {
+	responder
+	budgets service.BudgetService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewBudgetHandler creates a new BudgetHandler.
+func NewBudgetHandler(budgets service.BudgetService, wallets service.WalletService, logger *slog.Logger) *BudgetHandler {
+	return &BudgetHandler{
+		responder: responder{logger: logger},
+		budgets:   budgets,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// BudgetRequest represents the request body for creating a budget.
+type BudgetRequest struct {
+	Category    *string                  `json:"category"` // Optional; omit to budget all spending
+	Period      domain.BudgetPeriod      `json:"period"`
+	Limit       decimal.Decimal          `json:"limit"`
+	Enforcement domain.BudgetEnforcement `json:"enforcement"` // Optional; defaults to NONE
+}
+
+func (req *BudgetRequest) amountFields() []decimal.Decimal { return []decimal.Decimal{req.Limit} }
+
+// ListBudgets handles the list budgets request.
+// GET /wallets/{walletID}/budgets
+func (h *BudgetHandler) ListBudgets(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	budgets, err := h.budgets.ListBudgets(r.Context(), wallet.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	data := make([]budgetResponse, len(budgets))
+	for i := range budgets {
+		data[i] = formatBudget(&budgets[i], wallet.Currency)
+	}
+	h.respondWithData(w, http.StatusOK, data, nil, budgetLinks(wallet))
+}
+
+// CreateBudget handles the create budget request.
+// POST /wallets/{walletID}/budgets
+func (h *BudgetHandler) CreateBudget(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req BudgetRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	budget := &domain.Budget{
+		WalletID:       wallet.ID,
+		WalletPublicID: wallet.PublicID,
+		Category:       req.Category,
+		Period:         req.Period,
+		Limit:          req.Limit,
+		Enforcement:    req.Enforcement,
+	}
+	if err := h.budgets.CreateBudget(r.Context(), budget); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, formatBudget(budget, wallet.Currency), nil, budgetLinks(wallet))
+}
+
+// DeleteBudget handles the delete budget request.
+// DELETE /wallets/{walletID}/budgets/{budgetID}
+func (h *BudgetHandler) DeleteBudget(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	budgetID, err := strconv.ParseInt(chi.URLParam(r, "budgetID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	if err := h.budgets.DeleteBudget(r.Context(), wallet.ID, budgetID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBudgetAnalytics reports the spending and remaining amount of each budget in its current period.
+// GET /wallets/{walletID}/analytics/budgets
+func (h *BudgetHandler) GetBudgetAnalytics(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	statuses, err := h.budgets.BudgetStatuses(r.Context(), wallet.ID, now)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	data := make([]map[string]any, 0, len(statuses))
+	for _, status := range statuses {
+		data = append(data, map[string]any{
+			"budget":       formatBudget(&status.Budget, wallet.Currency),
+			"period_start": status.PeriodStart,
+			"period_end":   status.PeriodEnd,
+			"spent":        newMoney(status.Spent, wallet.Currency),
+			"remaining":    newMoney(status.Remaining, wallet.Currency),
+			"exceeded":     status.Exceeded,
+		})
+	}
+	links := budgetLinks(wallet)
+	links["self"] = fmt.Sprintf("/wallets/%s/analytics/budgets", wallet.PublicID)
+	h.respondWithData(w, http.StatusOK, data, map[string]any{"as_of": now, "currency": wallet.Currency}, links)
+}
+
+func budgetLinks(wallet *domain.Wallet) types.Links {
+	return types.Links{
+		"self":   fmt.Sprintf("/wallets/%s/budgets", wallet.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	}
+}
+
+// budgetResponse is a budget as rendered in API responses, with the limit in the wallet's currency.
+type budgetResponse struct {
+	ID          int64                    `json:"id"`
+	WalletID    uuid.UUID                `json:"wallet_id"`
+	Category    *string                  `json:"category"`
+	Period      domain.BudgetPeriod      `json:"period"`
+	Limit       money                    `json:"limit"`
+	Enforcement domain.BudgetEnforcement `json:"enforcement"`
+	CreatedAt   time.Time                `json:"created_at"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+}
+
+func formatBudget(budget *domain.Budget, currency string) budgetResponse {
+	return budgetResponse{
+		ID:          budget.ID,
+		WalletID:    budget.WalletPublicID,
+		Category:    budget.Category,
+		Period:      budget.Period,
+		Limit:       newMoney(budget.Limit, currency),
+		Enforcement: budget.Enforcement,
+		CreatedAt:   budget.CreatedAt,
+		UpdatedAt:   budget.UpdatedAt,
+	}
+}
@@ -0,0 +1,54 @@
+// internal/api/handler/cache.go
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// CachePolicy holds how long clients may reuse the responses of read endpoints polled by dashboards.
+// Zero disables caching of an endpoint.
+type CachePolicy struct {
+	Transactions time.Duration // Transaction history and search of a wallet
+	Reports      time.Duration // Treasury reports
+}
+
+var cachePolicy atomic.Pointer[CachePolicy]
+
+// SetCachePolicy sets the cache lifetimes of read endpoints. It is set once at startup.
+func SetCachePolicy(policy CachePolicy) {
+	cachePolicy.Store(&policy)
+}
+
+// currentCachePolicy returns the policy set at startup; without one, nothing is cached.
+func currentCachePolicy() CachePolicy {
+	if policy := cachePolicy.Load(); policy != nil {
+		return *policy
+	}
+	return CachePolicy{}
+}
+
+// writeCacheHeaders lets the client reuse the response for maxAge, and reports whether the client's
+// If-Modified-Since shows it already has the resource as of lastModified, in which case the caller should
+// answer 304 Not Modified. Responses are private, as they are only for the caller.
+// Last-Modified has a resolution of one second, so it is only sent once the resource has been unchanged for
+// a full second; otherwise a second change within the same second could be missed on revalidation.
+func writeCacheHeaders(w http.ResponseWriter, r *http.Request, maxAge time.Duration, lastModified time.Time) bool {
+	if maxAge <= 0 {
+		return false
+	}
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	if lastModified.IsZero() || time.Since(lastModified) < time.Second {
+		return false
+	}
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified.Truncate(time.Second).After(since) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
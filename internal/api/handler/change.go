@@ -0,0 +1,90 @@
+// internal/api/handler/change.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+const defaultChangeLimit = 100
+
+// ChangeHandler handles HTTP requests for the change feed.
+type ChangeHandler struct {
+	responder
+	changes service.ChangeService
+	logger  *slog.Logger
+}
+
+// NewChangeHandler creates a new ChangeHandler.
+func NewChangeHandler(changes service.ChangeService, logger *slog.Logger) *ChangeHandler {
+	return &ChangeHandler{
+		responder: responder{logger: logger},
+		changes:   changes,
+		logger:    logger,
+	}
+}
+
+// ListChanges handles the change feed request: the wallets and transactions changed after the since cursor.
+// Omit since for a full sync, then pass meta.next_cursor to receive only what changed in between.
+// GET /changes
+func (h *ChangeHandler) ListChanges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var since int64
+	if sinceStr := query.Get("since"); sinceStr != "" {
+		parsed, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			h.respondWithError(w, r, fmt.Errorf("%w: invalid cursor", util.ErrInvalidInput))
+			return
+		}
+		since = parsed
+	}
+	limit := defaultChangeLimit
+	if parsed, err := strconv.Atoi(query.Get("limit")); err == nil && parsed > 0 {
+		limit = min(parsed, domain.MaxChangesPerPage)
+	}
+	var userID *int64
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		parsed, err := strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil {
+			h.respondWithError(w, r, util.ErrInvalidInput)
+			return
+		}
+		userID = &parsed
+	}
+
+	changes, err := h.changes.ListChanges(r.Context(), since, userID, limit)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	wallets := make([]map[string]any, len(changes.Wallets))
+	for i := range changes.Wallets {
+		wallets[i] = formatWallet(&changes.Wallets[i].Wallet)
+	}
+	transactions := make([]map[string]any, len(changes.Transactions))
+	for i := range changes.Transactions {
+		transactions[i] = formatTransaction(&changes.Transactions[i].Transaction)
+	}
+
+	cursor := strconv.FormatInt(changes.Cursor, 10)
+	next := "/changes?since=" + cursor
+	if userID != nil {
+		next += "&user_id=" + strconv.FormatInt(*userID, 10)
+	}
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"wallets":      wallets,
+		"transactions": transactions,
+	}, map[string]any{
+		"next_cursor": cursor,
+		"has_more":    changes.HasMore,
+	}, types.Links{"self": r.URL.RequestURI(), "next": next})
+}
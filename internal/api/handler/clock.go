@@ -0,0 +1,93 @@
+// internal/api/handler/clock.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/clock"
+	"finflow-wallet/internal/jobs"
+	"finflow-wallet/internal/util"
+)
+
+// ClockHandler handles the test clock of sandbox mode. It only exists in sandbox mode.
+type ClockHandler struct {
+	responder
+	clock     *clock.Test
+	scheduler *jobs.Scheduler
+	logger    *slog.Logger
+}
+
+// NewClockHandler creates a new ClockHandler.
+func NewClockHandler(testClock *clock.Test, scheduler *jobs.Scheduler, logger *slog.Logger) *ClockHandler {
+	return &ClockHandler{
+		responder: responder{logger: logger},
+		clock:     testClock,
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+// AdvanceClockRequest represents the request body for advancing the test clock.
+type AdvanceClockRequest struct {
+	Duration string `json:"duration"` // Go duration, e.g. "720h"
+	RunJobs  bool   `json:"run_jobs"` // Runs every background job once at the new time before responding
+}
+
+// GetClock handles the get test clock request.
+// GET /admin/clock
+func (h *ClockHandler) GetClock(w http.ResponseWriter, r *http.Request) {
+	h.respondWithData(w, http.StatusOK, h.formatClock(h.clock.Now(), nil), nil, types.Links{"self": r.URL.Path})
+}
+
+// AdvanceClock handles the advance test clock request, optionally catching the background jobs up at once
+// instead of on their next tick. A failed job is reported in the response, not as an error.
+// POST /admin/clock/advance
+func (h *ClockHandler) AdvanceClock(w http.ResponseWriter, r *http.Request) {
+	var req AdvanceClockRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	d, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	now, err := h.clock.Advance(d)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	h.logger.Warn("Test clock advanced", "by", d, "now", now, "offset", h.clock.Offset())
+
+	var results []jobs.JobResult
+	if req.RunJobs {
+		results = h.scheduler.RunAll(r.Context())
+	}
+	h.respondWithData(w, http.StatusOK, h.formatClock(now, results), nil, types.Links{"self": "/admin/clock"})
+}
+
+func (h *ClockHandler) formatClock(now time.Time, results []jobs.JobResult) map[string]any {
+	formatted := map[string]any{
+		"now":            now.UTC(),
+		"offset_seconds": int64(h.clock.Offset().Seconds()),
+	}
+	if results != nil {
+		ran := make([]map[string]any, 0, len(results))
+		for _, result := range results {
+			job := map[string]any{
+				"name":        result.Name,
+				"duration_ms": result.Duration.Milliseconds(),
+			}
+			if result.Err != nil {
+				job["error"] = result.Err.Error()
+			}
+			ran = append(ran, job)
+		}
+		formatted["jobs"] = ran
+	}
+	return formatted
+}
@@ -0,0 +1,100 @@
+// internal/api/handler/contact_verification.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// ContactVerificationHandler handles HTTP requests verifying a user's email address and phone number.
+type ContactVerificationHandler struct {
+	responder
+	verifications service.ContactVerificationService
+	logger        *slog.Logger
+}
+
+// NewContactVerificationHandler creates a new ContactVerificationHandler.
+func NewContactVerificationHandler(verifications service.ContactVerificationService, logger *slog.Logger) *ContactVerificationHandler {
+	return &ContactVerificationHandler{
+		responder:     responder{logger: logger},
+		verifications: verifications,
+		logger:        logger,
+	}
+}
+
+// ConfirmVerificationRequest represents the request body for confirming a verification code.
+type ConfirmVerificationRequest struct {
+	Code string `json:"code"`
+}
+
+// IssueVerification handles the request for a verification code, which is sent to the user's email or phone.
+// The code itself is never returned.
+// POST /users/{userID}/contact/{channel}/verification
+func (h *ContactVerificationHandler) IssueVerification(w http.ResponseWriter, r *http.Request) {
+	userID, channel, ok := h.pathParams(w, r)
+	if !ok {
+		return
+	}
+
+	verification, err := h.verifications.Issue(r.Context(), userID, channel)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusAccepted, verification, nil, verificationLinks(userID, channel))
+}
+
+// ConfirmVerification handles the confirm verification code request.
+// POST /users/{userID}/contact/{channel}/verification/confirm
+func (h *ContactVerificationHandler) ConfirmVerification(w http.ResponseWriter, r *http.Request) {
+	userID, channel, ok := h.pathParams(w, r)
+	if !ok {
+		return
+	}
+	var req ConfirmVerificationRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	user, err := h.verifications.Confirm(r.Context(), userID, channel, strings.TrimSpace(req.Code))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatUser(user), nil, verificationLinks(userID, channel))
+}
+
+// pathParams parses the userID and channel path parameters; the channel is "email" or "phone". On failure it
+// writes the error response and reports false.
+func (h *ContactVerificationHandler) pathParams(w http.ResponseWriter, r *http.Request) (int64, domain.ContactChannel, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return 0, "", false
+	}
+	channel := domain.ContactChannel(strings.ToUpper(chi.URLParam(r, "channel")))
+	if !channel.Valid() {
+		h.respondWithError(w, r, fmt.Errorf("%w: channel must be email or phone", util.ErrInvalidInput))
+		return 0, "", false
+	}
+	return userID, channel, true
+}
+
+func verificationLinks(userID int64, channel domain.ContactChannel) types.Links {
+	path := fmt.Sprintf("/users/%d/contact/%s/verification", userID, strings.ToLower(string(channel)))
+	return types.Links{
+		"self":    path,
+		"confirm": path + "/confirm",
+		"user":    fmt.Sprintf("/users/%d", userID),
+	}
+}
@@ -0,0 +1,285 @@
+// internal/api/handler/crypto.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// CryptoHandler handles HTTP requests for the deposit addresses, deposits and withdrawals of crypto wallets.
+type CryptoHandler struct {
+	responder
+	crypto  service.CryptoService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewCryptoHandler creates a new CryptoHandler.
+func NewCryptoHandler(crypto service.CryptoService, wallets service.WalletService, logger *slog.Logger) *CryptoHandler {
+	return &CryptoHandler{
+		responder: responder{logger: logger},
+		crypto:    crypto,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// CryptoWithdrawalRequest represents the request body for withdrawing from a crypto wallet to an address.
+type CryptoWithdrawalRequest struct {
+	Address string          `json:"address"`
+	Amount  decimal.Decimal `json:"amount"` // Received by the address; the network fee is debited on top
+}
+
+func (req *CryptoWithdrawalRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Amount}
+}
+
+// ListCryptoAddresses handles the list deposit addresses request.
+// GET /wallets/{walletID}/crypto/addresses
+func (h *CryptoHandler) ListCryptoAddresses(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.crypto.ListAddresses(r.Context(), wallet)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, addresses, nil, cryptoLinks(wallet, "addresses"))
+}
+
+// CreateCryptoAddress handles the new deposit address request. Earlier addresses stay assigned to the wallet,
+// and deposits to any of them are credited.
+// POST /wallets/{walletID}/crypto/addresses
+func (h *CryptoHandler) CreateCryptoAddress(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	address, err := h.crypto.NewAddress(r.Context(), wallet)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, address, nil, cryptoLinks(wallet, "addresses"))
+}
+
+// ListCryptoDeposits handles the list deposits request. Pending deposits show their confirmations so far.
+// GET /wallets/{walletID}/crypto/deposits
+func (h *CryptoHandler) ListCryptoDeposits(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	deposits, err := h.crypto.ListDeposits(r.Context(), wallet, listLimit(r))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	items := make([]cryptoDepositResponse, len(deposits))
+	for i := range deposits {
+		items[i] = formatCryptoDeposit(&deposits[i])
+	}
+	h.respondWithData(w, http.StatusOK, items, map[string]any{"count": len(items)}, cryptoLinks(wallet, "deposits"))
+}
+
+// EstimateCryptoFee handles the network fee estimate request for a withdrawal of amount to address.
+// GET /wallets/{walletID}/crypto/fee-estimate?address=...&amount=...
+func (h *CryptoHandler) EstimateCryptoFee(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	amount, err := decimal.NewFromString(r.URL.Query().Get("amount"))
+	if err != nil {
+		h.respondWithError(w, r, fmt.Errorf("%w: amount must be a decimal number", util.ErrInvalidInput))
+		return
+	}
+
+	address := r.URL.Query().Get("address")
+	fee, err := h.crypto.EstimateFee(r.Context(), wallet, address, amount)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"asset":   wallet.Currency,
+		"address": address,
+		"amount":  newMoney(amount, wallet.Currency),
+		"fee":     newMoney(fee, wallet.Currency),
+		"total":   newMoney(amount.Add(fee), wallet.Currency),
+	}, nil, cryptoLinks(wallet, "fee-estimate"))
+}
+
+// RequestCryptoWithdrawal handles the crypto withdrawal request. The amount and the estimated network fee are
+// debited at once and the payout is queued; 202 Accepted answers, and the payout's status tells when it is sent.
+// POST /wallets/{walletID}/crypto/withdrawals
+func (h *CryptoHandler) RequestCryptoWithdrawal(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req CryptoWithdrawalRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	payout, err := h.crypto.RequestWithdrawal(r.Context(), wallet, req.Address, req.Amount)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusAccepted, formatCryptoPayout(payout), nil, cryptoPayoutLinks(wallet, payout))
+}
+
+// ListCryptoWithdrawals handles the list crypto withdrawals request.
+// GET /wallets/{walletID}/crypto/withdrawals
+func (h *CryptoHandler) ListCryptoWithdrawals(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	payouts, err := h.crypto.ListPayouts(r.Context(), wallet, listLimit(r))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	items := make([]cryptoPayoutResponse, len(payouts))
+	for i := range payouts {
+		items[i] = formatCryptoPayout(&payouts[i])
+	}
+	h.respondWithData(w, http.StatusOK, items, map[string]any{"count": len(items)}, cryptoLinks(wallet, "withdrawals"))
+}
+
+// GetCryptoWithdrawal handles the get crypto withdrawal request.
+// GET /wallets/{walletID}/crypto/withdrawals/{payoutID}
+func (h *CryptoHandler) GetCryptoWithdrawal(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	payoutID, err := uuid.Parse(chi.URLParam(r, "payoutID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	payout, err := h.crypto.GetPayout(r.Context(), wallet, payoutID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatCryptoPayout(payout), nil, cryptoPayoutLinks(wallet, payout))
+}
+
+func cryptoLinks(wallet *domain.Wallet, self string) types.Links {
+	return types.Links{
+		"self":        fmt.Sprintf("/wallets/%s/crypto/%s", wallet.PublicID, self),
+		"addresses":   fmt.Sprintf("/wallets/%s/crypto/addresses", wallet.PublicID),
+		"deposits":    fmt.Sprintf("/wallets/%s/crypto/deposits", wallet.PublicID),
+		"withdrawals": fmt.Sprintf("/wallets/%s/crypto/withdrawals", wallet.PublicID),
+		"wallet":      fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	}
+}
+
+func cryptoPayoutLinks(wallet *domain.Wallet, payout *domain.CryptoPayout) types.Links {
+	links := types.Links{
+		"self":        fmt.Sprintf("/wallets/%s/crypto/withdrawals/%s", wallet.PublicID, payout.PublicID),
+		"withdrawals": fmt.Sprintf("/wallets/%s/crypto/withdrawals", wallet.PublicID),
+		"transaction": fmt.Sprintf("/transactions/%s", payout.TransactionID),
+		"wallet":      fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	}
+	if payout.RefundTransactionID != nil {
+		links["refund"] = fmt.Sprintf("/transactions/%s", *payout.RefundTransactionID)
+	}
+	return links
+}
+
+// cryptoDepositResponse is a crypto deposit as rendered in API responses, with its amount in the asset's places.
+type cryptoDepositResponse struct {
+	WalletID      uuid.UUID                  `json:"wallet_id"`
+	Asset         string                     `json:"asset"`
+	Network       string                     `json:"network"`
+	Address       string                     `json:"address"`
+	TxHash        string                     `json:"tx_hash"`
+	OutputIndex   int                        `json:"output_index"`
+	Amount        money                      `json:"amount"`
+	Confirmations int                        `json:"confirmations"`
+	Status        domain.CryptoDepositStatus `json:"status"`
+	TransactionID *uuid.UUID                 `json:"transaction_id"`
+	DetectedAt    time.Time                  `json:"detected_at"`
+	CreditedAt    *time.Time                 `json:"credited_at"`
+}
+
+func formatCryptoDeposit(deposit *domain.CryptoDeposit) cryptoDepositResponse {
+	return cryptoDepositResponse{
+		WalletID:      deposit.WalletPublicID,
+		Asset:         deposit.Asset,
+		Network:       deposit.Network,
+		Address:       deposit.Address,
+		TxHash:        deposit.TxHash,
+		OutputIndex:   deposit.OutputIndex,
+		Amount:        newMoney(deposit.Amount, deposit.Asset),
+		Confirmations: deposit.Confirmations,
+		Status:        deposit.Status,
+		TransactionID: deposit.TransactionID,
+		DetectedAt:    deposit.DetectedAt,
+		CreditedAt:    deposit.CreditedAt,
+	}
+}
+
+// cryptoPayoutResponse is a crypto payout as rendered in API responses, with its amounts in the asset's places.
+type cryptoPayoutResponse struct {
+	ID                  uuid.UUID                 `json:"id"`
+	WalletID            uuid.UUID                 `json:"wallet_id"`
+	Asset               string                    `json:"asset"`
+	Network             string                    `json:"network"`
+	Address             string                    `json:"address"`
+	Amount              money                     `json:"amount"`
+	Fee                 money                     `json:"fee"`
+	Status              domain.CryptoPayoutStatus `json:"status"`
+	TransactionID       uuid.UUID                 `json:"transaction_id"`
+	RefundTransactionID *uuid.UUID                `json:"refund_transaction_id"`
+	ChainTxHash         *string                   `json:"chain_tx_hash"`
+	Attempts            int                       `json:"attempts"`
+	LastError           *string                   `json:"last_error"`
+	CreatedAt           time.Time                 `json:"created_at"`
+	UpdatedAt           time.Time                 `json:"updated_at"`
+}
+
+func formatCryptoPayout(payout *domain.CryptoPayout) cryptoPayoutResponse {
+	return cryptoPayoutResponse{
+		ID:                  payout.PublicID,
+		WalletID:            payout.WalletPublicID,
+		Asset:               payout.Asset,
+		Network:             payout.Network,
+		Address:             payout.Address,
+		Amount:              newMoney(payout.Amount, payout.Asset),
+		Fee:                 newMoney(payout.Fee, payout.Asset),
+		Status:              payout.Status,
+		TransactionID:       payout.TransactionID,
+		RefundTransactionID: payout.RefundTransactionID,
+		ChainTxHash:         payout.ChainTxHash,
+		Attempts:            payout.Attempts,
+		LastError:           payout.LastError,
+		CreatedAt:           payout.CreatedAt,
+		UpdatedAt:           payout.UpdatedAt,
+	}
+}
@@ -0,0 +1,117 @@
+// internal/api/handler/currency_restriction.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// CurrencyRestrictionHandler handles the admin requests for the residency restrictions of currencies.
+type CurrencyRestrictionHandler struct {
+	responder
+	restrictions service.CurrencyRestrictionService
+	logger       *slog.Logger
+}
+
+// NewCurrencyRestrictionHandler creates a new CurrencyRestrictionHandler.
+func NewCurrencyRestrictionHandler(restrictions service.CurrencyRestrictionService, logger *slog.Logger) *CurrencyRestrictionHandler {
+	return &CurrencyRestrictionHandler{
+		responder:    responder{logger: logger},
+		restrictions: restrictions,
+		logger:       logger,
+	}
+}
+
+// CurrencyRestrictionRequest represents the request body for setting the restriction of a currency.
+type CurrencyRestrictionRequest struct {
+	Countries []string `json:"countries"` // ISO 3166-1 alpha-2 codes, e.g. ["US", "CA"]
+}
+
+// ListCurrencyRestrictions handles the list currency restrictions request.
+// GET /admin/currency-restrictions
+func (h *CurrencyRestrictionHandler) ListCurrencyRestrictions(w http.ResponseWriter, r *http.Request) {
+	restrictions, err := h.restrictions.ListRestrictions(r.Context())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, restrictions, nil, types.Links{"self": "/admin/currency-restrictions"})
+}
+
+// GetCurrencyRestriction handles the get currency restriction request. An unrestricted currency yields 404.
+// GET /admin/currency-restrictions/{currency}
+func (h *CurrencyRestrictionHandler) GetCurrencyRestriction(w http.ResponseWriter, r *http.Request) {
+	currency, ok := h.currencyFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	restriction, err := h.restrictions.GetRestriction(r.Context(), currency)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, restriction, nil, currencyRestrictionLinks(currency))
+}
+
+// SetCurrencyRestriction handles the set currency restriction request, replacing the currency's countries.
+// PUT /admin/currency-restrictions/{currency}
+func (h *CurrencyRestrictionHandler) SetCurrencyRestriction(w http.ResponseWriter, r *http.Request) {
+	currency, ok := h.currencyFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req CurrencyRestrictionRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	restriction, err := h.restrictions.SetRestriction(r.Context(), currency, req.Countries, middleware.AdminFromContext(r.Context()))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, restriction, nil, currencyRestrictionLinks(currency))
+}
+
+// DeleteCurrencyRestriction handles the delete currency restriction request, lifting the restriction.
+// DELETE /admin/currency-restrictions/{currency}
+func (h *CurrencyRestrictionHandler) DeleteCurrencyRestriction(w http.ResponseWriter, r *http.Request) {
+	currency, ok := h.currencyFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.restrictions.DeleteRestriction(r.Context(), currency, middleware.AdminFromContext(r.Context())); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currencyFromPath parses the currency path parameter. On failure it writes the error response and reports false.
+func (h *CurrencyRestrictionHandler) currencyFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	currency := strings.ToUpper(chi.URLParam(r, "currency"))
+	if !domain.IsSupportedCurrency(currency) {
+		h.respondWithError(w, r, util.ErrCurrencyUnsupported)
+		return "", false
+	}
+	return currency, true
+}
+
+func currencyRestrictionLinks(currency string) types.Links {
+	return types.Links{
+		"self":         fmt.Sprintf("/admin/currency-restrictions/%s", currency),
+		"restrictions": "/admin/currency-restrictions",
+	}
+}
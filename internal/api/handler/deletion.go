@@ -0,0 +1,108 @@
+// internal/api/handler/deletion.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// DeletionHandler handles HTTP requests for soft-deleting users and wallets, and restoring them under /admin.
+type DeletionHandler struct {
+	responder
+	deletions service.DeletionService
+	wallets   service.WalletService
+	logger    *slog.Logger
+}
+
+// NewDeletionHandler creates a new DeletionHandler.
+func NewDeletionHandler(deletions service.DeletionService, wallets service.WalletService, logger *slog.Logger) *DeletionHandler {
+	return &DeletionHandler{
+		responder: responder{logger: logger},
+		deletions: deletions,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// DeleteUser handles the delete user request. The user's wallets are deleted with them.
+// DELETE /users/{userID}
+func (h *DeletionHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	if err := h.deletions.DeleteUser(r.Context(), userID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteWallet handles the delete wallet request.
+// DELETE /wallets/{walletID}
+func (h *DeletionHandler) DeleteWallet(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.deletions.DeleteWallet(r.Context(), wallet.ID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreUser handles the restore user request.
+// POST /admin/users/{userID}/restore
+func (h *DeletionHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	user, err := h.deletions.RestoreUser(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.logger.Warn("User restored by operator", "user_id", userID)
+	h.respondWithData(w, http.StatusOK, formatUser(user), nil, types.Links{
+		"self":    fmt.Sprintf("/users/%d", user.ID),
+		"wallets": fmt.Sprintf("/wallets?user_id=%d", user.ID),
+	})
+}
+
+// RestoreWallet handles the restore wallet request. Wallets deleted with their user are restored with the user.
+// POST /admin/wallets/{walletID}/restore
+func (h *DeletionHandler) RestoreWallet(w http.ResponseWriter, r *http.Request) {
+	// The wallet is deleted, so it cannot be resolved through the wallet service.
+	publicID, err := uuid.Parse(chi.URLParam(r, "walletID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	wallet, err := h.deletions.RestoreWallet(r.Context(), publicID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.logger.Warn("Wallet restored by operator", "wallet_id", wallet.PublicID)
+	h.respondWithData(w, http.StatusOK, formatWallet(wallet), nil, types.Links{
+		"self": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+		"user": fmt.Sprintf("/users/%d", wallet.UserID),
+	})
+}
@@ -0,0 +1,71 @@
+// internal/api/handler/dormancy.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/service"
+)
+
+// DormancyHandler handles HTTP requests for dormant wallets: listing them under /admin and reactivating them.
+type DormancyHandler struct {
+	responder
+	dormancy service.DormancyService
+	wallets  service.WalletService
+	logger   *slog.Logger
+}
+
+// NewDormancyHandler creates a new DormancyHandler.
+func NewDormancyHandler(dormancy service.DormancyService, wallets service.WalletService, logger *slog.Logger) *DormancyHandler {
+	return &DormancyHandler{
+		responder: responder{logger: logger},
+		dormancy:  dormancy,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// ListDormantWallets handles the list dormant wallets request.
+// GET /admin/wallets/dormant
+func (h *DormancyHandler) ListDormantWallets(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	wallets, totalCount, err := h.dormancy.ListDormant(r.Context(), opts)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	formattedWallets := make([]map[string]any, len(wallets))
+	for i, wallet := range wallets {
+		formattedWallets[i] = project(formatWallet(&wallet), opts.Fields)
+	}
+
+	h.respondWithJSON(w, http.StatusOK, types.NewPaginatedResponse(formattedWallets, r.URL, opts.Limit, opts.Offset, totalCount))
+}
+
+// ReactivateWallet handles the reactivate wallet request. The owner must first verify their email or phone again.
+// POST /wallets/{walletID}/reactivate
+func (h *DormancyHandler) ReactivateWallet(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	reactivated, err := h.dormancy.Reactivate(r.Context(), wallet.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatWallet(reactivated), nil, types.Links{
+		"self": fmt.Sprintf("/wallets/%s", reactivated.PublicID),
+		"user": fmt.Sprintf("/users/%d", reactivated.UserID),
+	})
+}
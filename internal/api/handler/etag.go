@@ -0,0 +1,37 @@
+// internal/api/handler/etag.go
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"finflow-wallet/internal/domain"
+)
+
+// parseETagList splits an If-Match / If-None-Match header into its entity tags.
+// Weak tags (W/"...") are compared by their opaque value.
+func parseETagList(header string) []string {
+	var etags []string
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag != "" {
+			etags = append(etags, tag)
+		}
+	}
+	return etags
+}
+
+// writeWalletETag sets the ETag header for the wallet and reports whether the client's
+// If-None-Match already holds it, in which case the caller should answer 304 Not Modified.
+func writeWalletETag(w http.ResponseWriter, r *http.Request, wallet *domain.Wallet) bool {
+	etag := wallet.ETag()
+	w.Header().Set("ETag", etag)
+
+	cached := parseETagList(r.Header.Get("If-None-Match"))
+	if slices.Contains(cached, "*") || slices.Contains(cached, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
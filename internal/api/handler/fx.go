@@ -0,0 +1,55 @@
+// internal/api/handler/fx.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/service"
+)
+
+// FXHandler handles exchange rate requests.
+type FXHandler struct {
+	responder
+	fx     service.FXService
+	logger *slog.Logger
+}
+
+// NewFXHandler creates a new FXHandler.
+func NewFXHandler(fx service.FXService, logger *slog.Logger) *FXHandler {
+	return &FXHandler{
+		responder: responder{logger: logger},
+		fx:        fx,
+		logger:    logger,
+	}
+}
+
+// GetRates handles the get exchange rates request.
+// GET /fx/rates
+func (h *FXHandler) GetRates(w http.ResponseWriter, r *http.Request) {
+	table, err := h.fx.Rates(r.Context())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	rates := make([]map[string]any, 0, len(table.Rates))
+	for _, rate := range table.Rates {
+		rates = append(rates, map[string]any{
+			"base":        rate.Base,
+			"quote":       rate.Quote,
+			"rate":        rate.Rate.String(),
+			"as_of":       rate.AsOf,
+			"age_seconds": int64(now.Sub(rate.AsOf).Seconds()),
+			"stale":       rate.IsStale(now, table.MaxAge),
+		})
+	}
+	meta := map[string]any{
+		"refreshed_at":    table.RefreshedAt,
+		"max_age_seconds": int64(table.MaxAge.Seconds()),
+	}
+	h.respondWithData(w, http.StatusOK, rates, meta, types.Links{"self": r.URL.Path})
+}
@@ -0,0 +1,407 @@
+// internal/api/handler/golden_test.go
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// update rewrites the golden files from the current responses instead of comparing against them:
+//
+//	go test ./internal/api/handler -run Golden -update
+var update = flag.Bool("update", false, "rewrite the golden files of the handler tests")
+
+// goldenDir holds the recorded responses, one file per case.
+const goldenDir = "testdata/golden"
+
+// Fixtures of the golden tests. Everything a response shows is fixed, so the files are stable.
+var (
+	goldenTime         = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	goldenUSDWalletID  = uuid.MustParse("11111111-1111-4111-8111-111111111111")
+	goldenEURWalletID  = uuid.MustParse("22222222-2222-4222-8222-222222222222")
+	goldenJPYWalletID  = uuid.MustParse("33333333-3333-4333-8333-333333333333")
+	goldenKWDWalletID  = uuid.MustParse("44444444-4444-4444-8444-444444444444")
+	goldenTransferToID = uuid.MustParse("55555555-5555-4555-8555-555555555555")
+	goldenTransaction  = uuid.MustParse("66666666-6666-4666-8666-666666666666")
+)
+
+// goldenWalletService serves the fixtures. Its methods the handlers under test do not call are left to the
+// embedded nil interface and panic.
+type goldenWalletService struct {
+	service.WalletService
+	wallets map[uuid.UUID]*domain.Wallet
+	user    *domain.User
+	status  domain.TransactionStatus // Of the transactions it makes
+	fail    error                    // Returned by the operation under test instead of its result
+}
+
+func newGoldenWalletService() *goldenWalletService {
+	svc := &goldenWalletService{wallets: map[uuid.UUID]*domain.Wallet{}, status: domain.TransactionStatusCompleted}
+	for i, fixture := range []struct {
+		publicID uuid.UUID
+		currency string
+		balance  string
+	}{
+		{goldenUSDWalletID, "USD", "150.5"},
+		{goldenEURWalletID, "EUR", "0"},
+		{goldenJPYWalletID, "JPY", "1200"},
+		{goldenKWDWalletID, "KWD", "12.3456"},
+		{goldenTransferToID, "USD", "20"},
+	} {
+		svc.wallets[fixture.publicID] = &domain.Wallet{
+			ID:             int64(i + 1),
+			PublicID:       fixture.publicID,
+			UserID:         7,
+			Currency:       fixture.currency,
+			Balance:        decimal.RequireFromString(fixture.balance),
+			Kind:           domain.WalletKindPersonal,
+			Version:        3,
+			CreatedAt:      goldenTime,
+			UpdatedAt:      goldenTime.Add(time.Hour),
+			LastActivityAt: goldenTime.Add(time.Hour),
+		}
+	}
+	residency := "DE"
+	svc.user = &domain.User{ID: 7, Username: "alice", Timezone: "Europe/Berlin", Residency: &residency, CreatedAt: goldenTime, UpdatedAt: goldenTime}
+	return svc
+}
+
+func (s *goldenWalletService) walletByID(id int64) *domain.Wallet {
+	for _, wallet := range s.wallets {
+		if wallet.ID == id {
+			return wallet
+		}
+	}
+	return nil
+}
+
+// moved returns the wallet after a balance change of delta.
+func (s *goldenWalletService) moved(walletID int64, delta decimal.Decimal) *domain.Wallet {
+	wallet := *s.walletByID(walletID)
+	wallet.Balance = wallet.Balance.Add(delta)
+	wallet.Version++
+	wallet.UpdatedAt = goldenTime.Add(2 * time.Hour)
+	return &wallet
+}
+
+func (s *goldenWalletService) transaction(from, to *domain.Wallet, amount decimal.Decimal, currency string, txType domain.TransactionType) *domain.Transaction {
+	transaction := &domain.Transaction{
+		PublicID:        goldenTransaction,
+		Amount:          amount,
+		Currency:        currency,
+		Type:            txType,
+		Status:          s.status,
+		TransactionTime: goldenTime.Add(2 * time.Hour),
+		PromoAmount:     decimal.Zero,
+		CreatedAt:       goldenTime.Add(2 * time.Hour),
+	}
+	if from != nil {
+		transaction.FromWalletID, transaction.FromWalletPublicID = &from.ID, &from.PublicID
+	}
+	if to != nil {
+		transaction.ToWalletID, transaction.ToWalletPublicID = &to.ID, &to.PublicID
+	}
+	return transaction
+}
+
+func (s *goldenWalletService) GetWalletByPublicID(ctx context.Context, publicID uuid.UUID) (*domain.Wallet, error) {
+	wallet, ok := s.wallets[publicID]
+	if !ok {
+		return nil, util.ErrWalletNotFound
+	}
+	return wallet, nil
+}
+
+func (s *goldenWalletService) GetBalance(ctx context.Context, walletID int64) (*domain.Wallet, error) {
+	return s.walletByID(walletID), nil
+}
+
+func (s *goldenWalletService) Deposit(ctx context.Context, walletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Transaction, error) {
+	if s.fail != nil {
+		return nil, nil, s.fail
+	}
+	wallet := s.moved(walletID, amount)
+	return wallet, s.transaction(nil, wallet, amount, currency, domain.TransactionTypeDeposit), nil
+}
+
+func (s *goldenWalletService) Withdraw(ctx context.Context, walletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Transaction, error) {
+	if s.fail != nil {
+		return nil, nil, s.fail
+	}
+	wallet := s.moved(walletID, amount.Neg())
+	return wallet, s.transaction(wallet, nil, amount, currency, domain.TransactionTypeWithdrawal), nil
+}
+
+func (s *goldenWalletService) Transfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Wallet, *domain.Transaction, error) {
+	if s.fail != nil {
+		return nil, nil, nil, s.fail
+	}
+	from, to := s.moved(fromWalletID, amount.Neg()), s.moved(toWalletID, amount)
+	return from, to, s.transaction(from, to, amount, currency, domain.TransactionTypeTransfer), nil
+}
+
+func (s *goldenWalletService) GetUser(ctx context.Context, userID int64) (*domain.User, error) {
+	if userID != s.user.ID {
+		return nil, util.ErrUserNotFound
+	}
+	return s.user, nil
+}
+
+func (s *goldenWalletService) CreateUserAndWallet(ctx context.Context, username, currency string, residency *string) (*domain.User, *domain.Wallet, error) {
+	if s.fail != nil {
+		return nil, nil, s.fail
+	}
+	user := *s.user
+	user.Username, user.Residency = username, residency
+	wallet := *s.wallets[goldenUSDWalletID]
+	wallet.Currency, wallet.Balance, wallet.Version, wallet.UpdatedAt = currency, decimal.Zero, 1, goldenTime
+	return &user, &wallet, nil
+}
+
+func (s *goldenWalletService) GetTransaction(ctx context.Context, publicID uuid.UUID) (*domain.Transaction, error) {
+	if publicID != goldenTransaction {
+		return nil, util.ErrNotFound
+	}
+	return s.transaction(s.wallets[goldenUSDWalletID], s.wallets[goldenTransferToID], decimal.RequireFromString("12.5"), "USD", domain.TransactionTypeTransfer), nil
+}
+
+func (s *goldenWalletService) ListTransactionHistory(ctx context.Context, walletID int64, filter repository.TransactionFilter, opts repository.ListOptions) ([]domain.Transaction, int64, error) {
+	deposit := s.transaction(nil, s.walletByID(walletID), decimal.RequireFromString("100"), "USD", domain.TransactionTypeDeposit)
+	return []domain.Transaction{*deposit}, 1, nil
+}
+
+// goldenCase is a request to the core wallet endpoints whose response is recorded in goldenDir/<name>.golden.
+type goldenCase struct {
+	name   string
+	method string
+	path   string
+	body   string
+	setup  func(svc *goldenWalletService) // Optional; e.g. makes the operation fail
+}
+
+// TestHandlerResponsesGolden records the responses of the core wallet endpoints, successes and error paths,
+// in golden files, so a change to a field name, a status code or the formatting of an amount shows up in
+// review as a diff of testdata/golden rather than in a client after release. After an intended change,
+// rerun with -update and commit the rewritten files.
+func TestHandlerResponsesGolden(t *testing.T) {
+	transfer := func(from, to uuid.UUID, amount string) string {
+		return fmt.Sprintf(`{"from_wallet_id": "%s", "to_wallet_id": "%s", "amount": "%s", "currency": "USD"}`, from, to, amount)
+	}
+	fail := func(err error) func(*goldenWalletService) {
+		return func(svc *goldenWalletService) { svc.fail = err }
+	}
+	cases := []goldenCase{
+		{name: "create_user", method: http.MethodPost, path: "/users", body: `{"username": "bob", "currency": " usd ", "residency": "FR"}`},
+		{name: "create_user_missing_username", method: http.MethodPost, path: "/users", body: `{"currency": "USD"}`},
+		{name: "create_user_unsupported_currency", method: http.MethodPost, path: "/users", body: `{"username": "bob", "currency": "XXX1"}`},
+		{name: "create_user_duplicate", method: http.MethodPost, path: "/users", body: `{"username": "alice", "currency": "USD"}`,
+			setup: fail(&util.DuplicateEntryError{Resource: "user", ExistingID: 7})},
+		{name: "get_user", method: http.MethodGet, path: "/users/7"},
+		{name: "get_user_invalid_id", method: http.MethodGet, path: "/users/seven"},
+		{name: "get_user_unknown", method: http.MethodGet, path: "/users/8"},
+
+		{name: "get_wallet", method: http.MethodGet, path: "/wallets/" + goldenUSDWalletID.String()},
+		{name: "get_wallet_zero_decimal_currency", method: http.MethodGet, path: "/wallets/" + goldenJPYWalletID.String()},
+		{name: "get_wallet_invalid_id", method: http.MethodGet, path: "/wallets/not-a-uuid"},
+		{name: "get_wallet_unknown", method: http.MethodGet, path: "/wallets/" + uuid.Nil.String()},
+		{name: "get_wallet_balance", method: http.MethodGet, path: "/wallets/" + goldenUSDWalletID.String() + "/balance"},
+		{name: "get_wallet_balance_three_decimal_currency", method: http.MethodGet, path: "/wallets/" + goldenKWDWalletID.String() + "/balance"},
+		{name: "get_wallet_balance_zero", method: http.MethodGet, path: "/wallets/" + goldenEURWalletID.String() + "/balance"},
+		{name: "get_transaction_history", method: http.MethodGet, path: "/wallets/" + goldenUSDWalletID.String() + "/transactions?limit=10"},
+		{name: "get_transaction_history_invalid_range", method: http.MethodGet, path: "/wallets/" + goldenUSDWalletID.String() + "/transactions?from=yesterday"},
+
+		{name: "deposit", method: http.MethodPost, path: "/wallets/" + goldenUSDWalletID.String() + "/deposit", body: `{"amount": "49.5", "currency": "USD"}`},
+		{name: "deposit_malformed_body", method: http.MethodPost, path: "/wallets/" + goldenUSDWalletID.String() + "/deposit", body: `{`},
+		{name: "deposit_amount_precision_exceeded", method: http.MethodPost, path: "/wallets/" + goldenUSDWalletID.String() + "/deposit", body: `{"amount": "1.000001", "currency": "USD"}`},
+		{name: "deposit_non_positive_amount", method: http.MethodPost, path: "/wallets/" + goldenUSDWalletID.String() + "/deposit", body: `{"amount": "0", "currency": "USD"}`},
+		{name: "deposit_currency_mismatch", method: http.MethodPost, path: "/wallets/" + goldenUSDWalletID.String() + "/deposit", body: `{"amount": "10", "currency": "EUR"}`,
+			setup: fail(util.ErrCurrencyMismatch)},
+		{name: "deposit_dry_run", method: http.MethodPost, path: "/wallets/" + goldenUSDWalletID.String() + "/deposit", body: `{"amount": "10", "currency": "USD", "dry_run": true}`},
+		{name: "withdraw", method: http.MethodPost, path: "/wallets/" + goldenKWDWalletID.String() + "/withdraw", body: `{"amount": "0.1", "currency": "KWD"}`},
+		{name: "withdraw_insufficient_funds", method: http.MethodPost, path: "/wallets/" + goldenUSDWalletID.String() + "/withdraw", body: `{"amount": "1000", "currency": "USD"}`,
+			setup: fail(util.ErrInsufficientFunds)},
+		{name: "withdraw_frozen_wallet", method: http.MethodPost, path: "/wallets/" + goldenUSDWalletID.String() + "/withdraw", body: `{"amount": "1", "currency": "USD"}`,
+			setup: fail(util.ErrWalletFrozen)},
+
+		{name: "transfer", method: http.MethodPost, path: "/transfers", body: transfer(goldenUSDWalletID, goldenTransferToID, "12.5")},
+		{name: "transfer_pending", method: http.MethodPost, path: "/transfers", body: transfer(goldenUSDWalletID, goldenTransferToID, "12.5"),
+			setup: func(svc *goldenWalletService) { svc.status = domain.TransactionStatusPending }},
+		{name: "transfer_missing_wallet_id", method: http.MethodPost, path: "/transfers", body: `{"to_wallet_id": "` + goldenTransferToID.String() + `", "amount": "1", "currency": "USD"}`},
+		{name: "transfer_unknown_destination", method: http.MethodPost, path: "/transfers", body: transfer(goldenUSDWalletID, uuid.Nil, "1")},
+		{name: "transfer_same_wallet", method: http.MethodPost, path: "/transfers", body: transfer(goldenUSDWalletID, goldenUSDWalletID, "1"),
+			setup: fail(util.ErrSameWalletTransfer)},
+		{name: "transfer_concurrent_update", method: http.MethodPost, path: "/transfers", body: transfer(goldenUSDWalletID, goldenTransferToID, "1"),
+			setup: fail(fmt.Errorf("failed to update wallet balance: %w", util.ErrConcurrentUpdate))},
+		{name: "transfer_duplicate", method: http.MethodPost, path: "/transfers", body: transfer(goldenUSDWalletID, goldenTransferToID, "12.5"),
+			setup: fail(&util.DuplicateTransferError{ExistingID: goldenTransaction, CreatedAt: goldenTime})},
+		{name: "transfer_internal_error", method: http.MethodPost, path: "/transfers", body: transfer(goldenUSDWalletID, goldenTransferToID, "1"),
+			setup: fail(errors.New("connection reset by peer"))},
+
+		{name: "get_transaction", method: http.MethodGet, path: "/transactions/" + goldenTransaction.String()},
+		{name: "get_transaction_invalid_id", method: http.MethodGet, path: "/transactions/42"},
+		{name: "get_transaction_unknown", method: http.MethodGet, path: "/transactions/" + uuid.Nil.String()},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := newGoldenWalletService()
+			if c.setup != nil {
+				c.setup(svc)
+			}
+			var body io.Reader
+			if c.body != "" {
+				body = strings.NewReader(c.body)
+			}
+			rec := httptest.NewRecorder()
+			goldenRouter(svc).ServeHTTP(rec, httptest.NewRequest(c.method, c.path, body))
+
+			assertGolden(t, c.name, recordResponse(t, rec))
+		})
+	}
+}
+
+// TestErrorResponsesGolden records the status and body every error the handlers map gets, so renaming an
+// error code or changing its status is caught for all endpoints at once.
+func TestErrorResponsesGolden(t *testing.T) {
+	rs := responder{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	errs := []struct {
+		name string
+		err  error
+	}{
+		{"ErrInvalidInput", util.ErrInvalidInput},
+		{"ErrNotFound", util.ErrNotFound},
+		{"ErrWalletNotFound", util.ErrWalletNotFound},
+		{"ErrUserNotFound", util.ErrUserNotFound},
+		{"ErrInsufficientFunds", util.ErrInsufficientFunds},
+		{"ErrSameWalletTransfer", util.ErrSameWalletTransfer},
+		{"ErrCurrencyMismatch", util.ErrCurrencyMismatch},
+		{"ErrCurrencyUnsupported", util.ErrCurrencyUnsupported},
+		{"ErrAmountPrecision", util.ErrAmountPrecision},
+		{"AmountPrecisionError", &util.AmountPrecisionError{Places: 6, MaxPlaces: 4}},
+		{"ErrDuplicateEntry", util.ErrDuplicateEntry},
+		{"DuplicateEntryError", &util.DuplicateEntryError{Resource: "user", ExistingID: 7}},
+		{"ErrReferenceViolation", util.ErrReferenceViolation},
+		{"ErrConcurrentUpdate", util.ErrConcurrentUpdate},
+		{"ErrConstraintViolation", util.ErrConstraintViolation},
+		{"ErrPreconditionFailed", util.ErrPreconditionFailed},
+		{"ErrFXRateUnavailable", util.ErrFXRateUnavailable},
+		{"ErrFXRateStale", util.ErrFXRateStale},
+		{"ErrForbidden", util.ErrForbidden},
+		{"ErrApprovalRequired", util.ErrApprovalRequired},
+		{"ErrApprovalNotPending", util.ErrApprovalNotPending},
+		{"ErrBudgetExceeded", util.ErrBudgetExceeded},
+		{"ErrNotMerchantWallet", util.ErrNotMerchantWallet},
+		{"ErrChargeNotPending", util.ErrChargeNotPending},
+		{"ErrBillNotOpen", util.ErrBillNotOpen},
+		{"ErrBillNotApproved", util.ErrBillNotApproved},
+		{"ErrVoucherNotRedeemable", util.ErrVoucherNotRedeemable},
+		{"ErrWalletNotEmpty", util.ErrWalletNotEmpty},
+		{"ErrQuoteNotUsable", util.ErrQuoteNotUsable},
+		{"ErrDuplicateTransfer", util.ErrDuplicateTransfer},
+		{"DuplicateTransferError", &util.DuplicateTransferError{ExistingID: goldenTransaction, CreatedAt: goldenTime}},
+		{"ErrFundsNotSuspended", util.ErrFundsNotSuspended},
+		{"ErrAdjustmentNotPending", util.ErrAdjustmentNotPending},
+		{"ErrScreeningHit", util.ErrScreeningHit},
+		{"ErrScreeningCaseClosed", util.ErrScreeningCaseClosed},
+		{"ErrCurrencyRestricted", util.ErrCurrencyRestricted},
+		{"ErrPINRequired", util.ErrPINRequired},
+		{"ErrInvalidPIN", util.ErrInvalidPIN},
+		{"ErrPINLocked", util.ErrPINLocked},
+		{"ErrInvalidVerificationCode", util.ErrInvalidVerificationCode},
+		{"ErrVerificationThrottled", util.ErrVerificationThrottled},
+		{"ErrContactInUse", util.ErrContactInUse},
+		{"ErrWalletFrozen", util.ErrWalletFrozen},
+		{"ErrVerificationRequired", util.ErrVerificationRequired},
+		{"ErrVolumeQuotaExceeded", util.ErrVolumeQuotaExceeded},
+		{"ErrRampLiquidity", util.ErrRampLiquidity},
+		{"ErrConsentInvalid", util.ErrConsentInvalid},
+		{"ErrConsentNotPending", util.ErrConsentNotPending},
+		{"ErrInvalidIDToken", util.ErrInvalidIDToken},
+		{"ErrIdentityLinked", util.ErrIdentityLinked},
+		{"ErrUserDeactivated", util.ErrUserDeactivated},
+		{"ErrOwnershipTransferFrozen", util.ErrOwnershipTransferFrozen},
+		{"ErrOwnershipNotPending", util.ErrOwnershipNotPending},
+		{"Unmapped", errors.New("unexpected")},
+	}
+
+	var recorded bytes.Buffer
+	for _, e := range errs {
+		rec := httptest.NewRecorder()
+		rs.respondWithError(rec, httptest.NewRequest(http.MethodGet, "/", nil), e.err)
+		fmt.Fprintf(&recorded, "== %s\n%s\n", e.name, recordResponse(t, rec))
+	}
+	assertGolden(t, "errors", recorded.String())
+}
+
+// goldenRouter routes the core wallet endpoints to a WalletHandler over svc.
+func goldenRouter(svc service.WalletService) http.Handler {
+	h := NewWalletHandler(svc, nil, service.NewDescriptionRenderer(nil), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r := chi.NewRouter()
+	r.Post("/users", h.CreateUser)
+	r.Get("/users/{userID}", h.GetUser)
+	r.Get("/wallets/{walletID}", h.GetWallet)
+	r.Get("/wallets/{walletID}/balance", h.GetWalletBalance)
+	r.Get("/wallets/{walletID}/transactions", h.GetTransactionHistory)
+	r.Post("/wallets/{walletID}/deposit", h.Deposit)
+	r.Post("/wallets/{walletID}/withdraw", h.Withdraw)
+	r.Post("/transfers", h.Transfer)
+	r.Get("/transactions/{transactionID}", h.GetTransaction)
+	return r
+}
+
+// recordResponse renders a response for a golden file: the status line, the headers clients rely on and the
+// indented JSON body.
+func recordResponse(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var out strings.Builder
+	fmt.Fprintf(&out, "HTTP %d\n", rec.Code)
+	for _, header := range []string{"Content-Type", "ETag", "Location"} {
+		if value := rec.Header().Get(header); value != "" {
+			fmt.Fprintf(&out, "%s: %s\n", header, value)
+		}
+	}
+	out.WriteString("\n")
+	var body bytes.Buffer
+	require.NoError(t, json.Indent(&body, rec.Body.Bytes(), "", "  "), "the body is not JSON: %s", rec.Body.String())
+	out.Write(body.Bytes())
+	out.WriteString("\n")
+	return out.String()
+}
+
+// assertGolden compares got with goldenDir/<name>.golden, or rewrites the file with -update.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join(goldenDir, name+".golden")
+	if *update {
+		require.NoError(t, os.MkdirAll(goldenDir, 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "no golden file; run the test with -update to record it")
+	assert.Equal(t, string(want), got, "the response differs from %s; if the change is intended, rerun with -update", path)
+}
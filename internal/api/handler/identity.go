@@ -0,0 +1,153 @@
+// internal/api/handler/identity.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// IdentityHandler handles HTTP requests for delegated login with OpenID Connect providers and for the
+// identities linked to users.
+type IdentityHandler struct {
+	responder
+	identities service.IdentityService
+	logger     *slog.Logger
+}
+
+// NewIdentityHandler creates a new IdentityHandler.
+func NewIdentityHandler(identities service.IdentityService, logger *slog.Logger) *IdentityHandler {
+	return &IdentityHandler{
+		responder:  responder{logger: logger},
+		identities: identities,
+		logger:     logger,
+	}
+}
+
+// OIDCLoginRequest represents the request body for logging in with an ID token.
+type OIDCLoginRequest struct {
+	IDToken string `json:"id_token"`
+}
+
+// LinkIdentityRequest represents the request body for linking a provider account to a user.
+type LinkIdentityRequest struct {
+	Provider string `json:"provider"`
+	IDToken  string `json:"id_token"`
+}
+
+// Login handles the login request with an ID token of the provider. It returns 201 Created with the new
+// user and wallet when the provider account logs in for the first time, and 200 OK with the user otherwise.
+// POST /auth/oidc/{provider}/login
+func (h *IdentityHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req OIDCLoginRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.IDToken == "" {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	login, err := h.identities.Login(r.Context(), chi.URLParam(r, "provider"), req.IDToken)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	location := fmt.Sprintf("/users/%d", login.User.ID)
+	data := map[string]any{
+		"user":     formatUser(login.User),
+		"identity": login.Identity,
+	}
+	links := types.Links{
+		"user":       location,
+		"identities": location + "/identities",
+	}
+	if !login.Provisioned {
+		h.respondWithData(w, http.StatusOK, data, nil, links)
+		return
+	}
+	data["wallet"] = formatWallet(login.Wallet)
+	links["wallet"] = fmt.Sprintf("/wallets/%s", login.Wallet.PublicID)
+	w.Header().Set("Location", location)
+	h.respondWithData(w, http.StatusCreated, data, map[string]any{"provisioned": true}, links)
+}
+
+// ListIdentities handles the list identities request.
+// GET /users/{userID}/identities
+func (h *IdentityHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	identities, err := h.identities.ListIdentities(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, identities, map[string]any{"total": len(identities)}, identityLinks(userID))
+}
+
+// LinkIdentity handles the link identity request, proven by an ID token of the provider account. An account
+// linked to another user, or a second account at the same provider, returns 409 identity_linked.
+// POST /users/{userID}/identities
+func (h *IdentityHandler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req LinkIdentityRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Provider == "" || req.IDToken == "" {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	identity, err := h.identities.LinkIdentity(r.Context(), userID, req.Provider, req.IDToken)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, identity, nil, identityLinks(userID))
+}
+
+// UnlinkIdentity handles the unlink identity request.
+// DELETE /users/{userID}/identities/{provider}
+func (h *IdentityHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.identities.UnlinkIdentity(r.Context(), userID, chi.URLParam(r, "provider")); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userIDFromPath parses the userID path parameter. On failure it writes the error response and reports false.
+func (h *IdentityHandler) userIDFromPath(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return 0, false
+	}
+	return userID, true
+}
+
+func identityLinks(userID int64) types.Links {
+	return types.Links{
+		"self": fmt.Sprintf("/users/%d/identities", userID),
+		"user": fmt.Sprintf("/users/%d", userID),
+	}
+}
@@ -0,0 +1,127 @@
+// internal/api/handler/impersonation.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// ImpersonationHandler handles the admin HTTP requests that start, inspect and end impersonation sessions.
+type ImpersonationHandler struct {
+	responder
+	sessions service.ImpersonationService
+	logger   *slog.Logger
+}
+
+// NewImpersonationHandler creates a new ImpersonationHandler.
+func NewImpersonationHandler(sessions service.ImpersonationService, logger *slog.Logger) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		responder: responder{logger: logger},
+		sessions:  sessions,
+		logger:    logger,
+	}
+}
+
+// ImpersonationRequest represents the request body for starting an impersonation session.
+type ImpersonationRequest struct {
+	UserID        int64  `json:"user_id"`
+	Reason        string `json:"reason"` // e.g. a support case reference
+	AllowTransfer bool   `json:"allow_transfer"`
+}
+
+// StartImpersonation handles the start impersonation request. The token is only returned here. The admin
+// authenticated by their personal token is the operator kept with the audit trail.
+// POST /admin/impersonations
+func (h *ImpersonationHandler) StartImpersonation(w http.ResponseWriter, r *http.Request) {
+	var req ImpersonationRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.UserID <= 0 {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	session, err := h.sessions.StartSession(r.Context(), req.UserID, middleware.AdminFromContext(r.Context()), req.Reason, req.AllowTransfer)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	links := impersonationLinks(session)
+	w.Header().Set("Location", links["self"])
+	h.respondWithData(w, http.StatusCreated, session, nil, links)
+}
+
+// GetImpersonation handles the get impersonation session request.
+// GET /admin/impersonations/{sessionID}
+func (h *ImpersonationHandler) GetImpersonation(w http.ResponseWriter, r *http.Request) {
+	publicID, err := uuid.Parse(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	session, err := h.sessions.GetSession(r.Context(), publicID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, session, nil, impersonationLinks(session))
+}
+
+// EndImpersonation handles the end impersonation request; the token stops working immediately.
+// DELETE /admin/impersonations/{sessionID}
+func (h *ImpersonationHandler) EndImpersonation(w http.ResponseWriter, r *http.Request) {
+	publicID, err := uuid.Parse(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	if err := h.sessions.EndSession(r.Context(), publicID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListImpersonationAudit handles the list impersonation audit entries request.
+// GET /admin/impersonations/{sessionID}/audit
+func (h *ImpersonationHandler) ListImpersonationAudit(w http.ResponseWriter, r *http.Request) {
+	publicID, err := uuid.Parse(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	entries, err := h.sessions.ListAuditEntries(r.Context(), publicID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	if entries == nil {
+		entries = []domain.ImpersonationAuditEntry{}
+	}
+	h.respondWithData(w, http.StatusOK, entries, nil, types.Links{
+		"self":    r.URL.Path,
+		"session": fmt.Sprintf("/admin/impersonations/%s", publicID),
+	})
+}
+
+func impersonationLinks(session *domain.ImpersonationSession) types.Links {
+	return types.Links{
+		"self":  fmt.Sprintf("/admin/impersonations/%s", session.PublicID),
+		"audit": fmt.Sprintf("/admin/impersonations/%s/audit", session.PublicID),
+		"user":  fmt.Sprintf("/users/%d", session.UserID),
+	}
+}
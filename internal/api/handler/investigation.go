@@ -0,0 +1,87 @@
+// internal/api/handler/investigation.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// InvestigationHandler handles the admin view of a transaction with its linked entities.
+type InvestigationHandler struct {
+	responder
+	investigations service.InvestigationService
+	logger         *slog.Logger
+}
+
+// NewInvestigationHandler creates a new InvestigationHandler.
+func NewInvestigationHandler(investigations service.InvestigationService, logger *slog.Logger) *InvestigationHandler {
+	return &InvestigationHandler{
+		responder:      responder{logger: logger},
+		investigations: investigations,
+		logger:         logger,
+	}
+}
+
+// GetTransactionInvestigation handles the full admin view of a transaction: the transaction with both
+// wallets and their owners, the parent and child transactions, the records referencing it, its annotation,
+// the notifications about it and the impersonated requests that touched it. A missing side is null.
+// GET /admin/transactions/{transactionID}/full
+func (h *InvestigationHandler) GetTransactionInvestigation(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := uuid.Parse(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	investigation, err := h.investigations.Investigate(r.Context(), transactionID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	children := make([]map[string]any, len(investigation.Children))
+	for i := range investigation.Children {
+		children[i] = formatTransaction(&investigation.Children[i])
+	}
+	var parent map[string]any
+	if investigation.Parent != nil {
+		parent = formatTransaction(investigation.Parent)
+	}
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"transaction":   formatTransaction(investigation.Transaction),
+		"from":          formatInvestigationSide(investigation.FromWallet, investigation.FromUser),
+		"to":            formatInvestigationSide(investigation.ToWallet, investigation.ToUser),
+		"parent":        parent,
+		"children":      children,
+		"references":    investigation.References,
+		"annotation":    investigation.Annotation,
+		"notifications": investigation.Notifications,
+		"deliveries":    investigation.Deliveries,
+		"audit":         investigation.Audit,
+	}, nil, types.Links{
+		"self":        fmt.Sprintf("/admin/transactions/%s/full", transactionID),
+		"admin":       fmt.Sprintf("/admin/transactions/%s", transactionID),
+		"transaction": fmt.Sprintf("/transactions/%s", transactionID),
+	})
+}
+
+// formatInvestigationSide renders a side of a transaction, nil for a side without a wallet.
+func formatInvestigationSide(wallet *domain.Wallet, user *domain.User) map[string]any {
+	if wallet == nil {
+		return nil
+	}
+	side := map[string]any{"wallet": formatWallet(wallet), "user": nil}
+	if user != nil {
+		side["user"] = formatUser(user)
+	}
+	return side
+}
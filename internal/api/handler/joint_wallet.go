@@ -0,0 +1,295 @@
+// internal/api/handler/joint_wallet.go
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// JointWalletHandler handles HTTP requests for wallet members, approval policies and transfer approvals.
+type JointWalletHandler struct {
+	responder
+	joint   service.JointWalletService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewJointWalletHandler creates a new JointWalletHandler.
+func NewJointWalletHandler(joint service.JointWalletService, wallets service.WalletService, logger *slog.Logger) *JointWalletHandler {
+	return &JointWalletHandler{
+		responder: responder{logger: logger},
+		joint:     joint,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// WalletMemberRequest represents the request body for adding a wallet member.
+type WalletMemberRequest struct {
+	UserID int64             `json:"user_id"`
+	Role   domain.WalletRole `json:"role"`
+}
+
+// ApprovalPolicyRequest represents the request body for setting a wallet's approval policy.
+type ApprovalPolicyRequest struct {
+	Threshold         decimal.Decimal `json:"threshold"`
+	RequiredApprovals int             `json:"required_approvals"`
+}
+
+func (req *ApprovalPolicyRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Threshold}
+}
+
+// ApprovalVoteRequest represents the request body for approving or rejecting a transfer.
+type ApprovalVoteRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+// ListMembers handles the list wallet members request.
+// GET /wallets/{walletID}/members
+func (h *JointWalletHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.joint.ListMembers(r.Context(), wallet)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, members, nil, memberLinks(wallet))
+}
+
+// AddMember handles the add wallet member request.
+// POST /wallets/{walletID}/members
+func (h *JointWalletHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req WalletMemberRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.UserID <= 0 {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	member, err := h.joint.AddMember(r.Context(), wallet, req.UserID, req.Role)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, member, nil, memberLinks(wallet))
+}
+
+// RemoveMember handles the remove wallet member request.
+// DELETE /wallets/{walletID}/members/{userID}
+func (h *JointWalletHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	if err := h.joint.RemoveMember(r.Context(), wallet, userID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetApprovalPolicy handles the get approval policy request.
+// GET /wallets/{walletID}/approval-policy
+func (h *JointWalletHandler) GetApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	policy, err := h.joint.GetApprovalPolicy(r.Context(), wallet)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatApprovalPolicy(policy, wallet.Currency), nil, types.Links{"self": r.URL.Path})
+}
+
+// SetApprovalPolicy handles the set approval policy request.
+// PUT /wallets/{walletID}/approval-policy
+func (h *JointWalletHandler) SetApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req ApprovalPolicyRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	policy, err := h.joint.SetApprovalPolicy(r.Context(), wallet, req.Threshold, req.RequiredApprovals)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatApprovalPolicy(policy, wallet.Currency), nil, types.Links{"self": r.URL.Path})
+}
+
+// DeleteApprovalPolicy handles the delete approval policy request.
+// DELETE /wallets/{walletID}/approval-policy
+func (h *JointWalletHandler) DeleteApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.joint.DeleteApprovalPolicy(r.Context(), wallet); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTransferApproval handles the get transfer approval request.
+// GET /transfer-approvals/{approvalID}
+func (h *JointWalletHandler) GetTransferApproval(w http.ResponseWriter, r *http.Request) {
+	publicID, err := uuid.Parse(chi.URLParam(r, "approvalID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	approval, err := h.joint.GetTransferApproval(r.Context(), publicID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatTransferApproval(approval), nil, transferApprovalLinks(approval))
+}
+
+// ApproveTransfer handles the approve transfer request. The approval that completes the quorum executes the transfer.
+// POST /transfer-approvals/{approvalID}/approve
+func (h *JointWalletHandler) ApproveTransfer(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, h.joint.ApproveTransfer)
+}
+
+// RejectTransfer handles the reject transfer request.
+// POST /transfer-approvals/{approvalID}/reject
+func (h *JointWalletHandler) RejectTransfer(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, h.joint.RejectTransfer)
+}
+
+// vote decodes an approve or reject request and applies it with cast.
+func (h *JointWalletHandler) vote(w http.ResponseWriter, r *http.Request, cast func(ctx context.Context, publicID uuid.UUID, userID int64) (*domain.TransferApproval, error)) {
+	publicID, err := uuid.Parse(chi.URLParam(r, "approvalID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	var req ApprovalVoteRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.UserID <= 0 {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	approval, err := cast(r.Context(), publicID, req.UserID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatTransferApproval(approval), nil, transferApprovalLinks(approval))
+}
+
+// memberLinks returns the links of a wallet's member list.
+func memberLinks(wallet *domain.Wallet) types.Links {
+	return types.Links{
+		"self":   fmt.Sprintf("/wallets/%s/members", wallet.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	}
+}
+
+// transferApprovalLinks returns the links of a transfer approval request and, once executed, its transaction.
+func transferApprovalLinks(approval *domain.TransferApproval) types.Links {
+	links := types.Links{
+		"self":   fmt.Sprintf("/transfer-approvals/%s", approval.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", approval.FromWalletPublicID),
+	}
+	if approval.TransactionID != nil {
+		links["transaction"] = fmt.Sprintf("/transactions/%s", *approval.TransactionID)
+	}
+	return links
+}
+
+// approvalPolicyResponse is a wallet's approval policy as rendered in API responses.
+type approvalPolicyResponse struct {
+	Threshold         money     `json:"threshold"`
+	RequiredApprovals int       `json:"required_approvals"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func formatApprovalPolicy(policy *domain.ApprovalPolicy, currency string) approvalPolicyResponse {
+	return approvalPolicyResponse{
+		Threshold:         newMoney(policy.Threshold, currency),
+		RequiredApprovals: policy.RequiredApprovals,
+		UpdatedAt:         policy.UpdatedAt,
+	}
+}
+
+// transferApprovalResponse is a transfer awaiting approval as rendered in API responses.
+type transferApprovalResponse struct {
+	ID                uuid.UUID                     `json:"id"`
+	FromWalletID      uuid.UUID                     `json:"from_wallet_id"`
+	ToWalletID        uuid.UUID                     `json:"to_wallet_id"`
+	Amount            money                         `json:"amount"`
+	Currency          string                        `json:"currency"`
+	RequestedBy       *int64                        `json:"requested_by"`
+	RequiredApprovals int                           `json:"required_approvals"`
+	ApprovedBy        []int64                       `json:"approved_by"`
+	Status            domain.TransferApprovalStatus `json:"status"`
+	TransactionID     *uuid.UUID                    `json:"transaction_id"`
+	FailureReason     *string                       `json:"failure_reason"`
+	CreatedAt         time.Time                     `json:"created_at"`
+	UpdatedAt         time.Time                     `json:"updated_at"`
+}
+
+func formatTransferApproval(approval *domain.TransferApproval) transferApprovalResponse {
+	return transferApprovalResponse{
+		ID:                approval.PublicID,
+		FromWalletID:      approval.FromWalletPublicID,
+		ToWalletID:        approval.ToWalletPublicID,
+		Amount:            newMoney(approval.Amount, approval.Currency),
+		Currency:          approval.Currency,
+		RequestedBy:       approval.RequestedBy,
+		RequiredApprovals: approval.RequiredApprovals,
+		ApprovedBy:        approval.ApprovedBy,
+		Status:            approval.Status,
+		TransactionID:     approval.TransactionID,
+		FailureReason:     approval.FailureReason,
+		CreatedAt:         approval.CreatedAt,
+		UpdatedAt:         approval.UpdatedAt,
+	}
+}
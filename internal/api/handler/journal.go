@@ -0,0 +1,87 @@
+// internal/api/handler/journal.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// JournalHandler handles HTTP requests for the accounting journal export.
+type JournalHandler struct {
+	responder
+	journal service.JournalService
+	logger  *slog.Logger
+}
+
+// NewJournalHandler creates a new JournalHandler.
+func NewJournalHandler(journal service.JournalService, logger *slog.Logger) *JournalHandler {
+	return &JournalHandler{
+		responder: responder{logger: logger},
+		journal:   journal,
+		logger:    logger,
+	}
+}
+
+// ExportJournal handles the journal export request: the journal entries of the transactions created in
+// [from, to), both RFC 3339 timestamps, as a CSV download.
+// GET /admin/journal
+func (h *JournalHandler) ExportJournal(w http.ResponseWriter, r *http.Request) {
+	from, err := parseTimeParam(r, "from")
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	to, err := parseTimeParam(r, "to")
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	if from == nil || to == nil {
+		h.respondWithError(w, r, fmt.Errorf("%w: from and to are required", util.ErrInvalidInput))
+		return
+	}
+
+	// The body is streamed, so the headers must be set before the first entry is written; a range that is
+	// rejected fails before anything is written and still gets an error response
+	stream := &lazyHeaderWriter{ResponseWriter: w, header: func() {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="journal-%s-%s.csv"`,
+			from.UTC().Format(time.DateOnly), to.UTC().Format(time.DateOnly)))
+		w.WriteHeader(http.StatusOK)
+	}}
+	entries, err := h.journal.WriteJournal(r.Context(), *from, *to, stream)
+	if err != nil {
+		if !stream.started {
+			h.respondWithError(w, r, err)
+			return
+		}
+		// The status is already sent; the client sees a truncated body
+		h.logger.Error("Failed to stream journal export", "from", from, "to", to, "error", err)
+		return
+	}
+	if !stream.started {
+		stream.header()
+	}
+	h.logger.Info("Journal exported", "from", from, "to", to, "entries", entries)
+}
+
+// lazyHeaderWriter sends the response headers on the first write, so an error found before any output can
+// still be answered with an error response.
+type lazyHeaderWriter struct {
+	http.ResponseWriter
+	header  func()
+	started bool
+}
+
+func (w *lazyHeaderWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.header()
+	}
+	return w.ResponseWriter.Write(p)
+}
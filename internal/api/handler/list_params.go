@@ -0,0 +1,97 @@
+// internal/api/handler/list_params.go
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+const (
+	defaultListLimit = 10
+	maxListLimit     = 100
+)
+
+// listLimit returns the page size of list endpoints taking no other list options: the limit query parameter,
+// defaultListLimit if it is missing or invalid, and at most maxListLimit.
+func listLimit(r *http.Request) int {
+	if parsed, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsed > 0 {
+		return min(parsed, maxListLimit)
+	}
+	return defaultListLimit
+}
+
+// parseListOptions reads the query-parameter contract shared by all list endpoints:
+//
+//	limit=10&offset=0&sort=created_at:desc,id:asc&fields=id,amount
+//
+// Invalid limit/offset values fall back to defaults; malformed sort terms are rejected.
+// Unknown sort or projection fields are rejected by the repository's query builder.
+func parseListOptions(r *http.Request) (repository.ListOptions, error) {
+	query := r.URL.Query()
+	opts := repository.ListOptions{Limit: defaultListLimit}
+
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		opts.Limit = min(limit, maxListLimit)
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset >= 0 {
+		opts.Offset = offset
+	}
+
+	if sortParam := query.Get("sort"); sortParam != "" {
+		for _, term := range strings.Split(sortParam, ",") {
+			field, direction, _ := strings.Cut(strings.TrimSpace(term), ":")
+			sortField := repository.SortField{Field: field}
+			switch strings.ToLower(direction) {
+			case "", "asc":
+			case "desc":
+				sortField.Desc = true
+			default:
+				return opts, fmt.Errorf("%w: invalid sort direction %q", util.ErrInvalidInput, direction)
+			}
+			opts.Sort = append(opts.Sort, sortField)
+		}
+	}
+
+	if fieldsParam := query.Get("fields"); fieldsParam != "" {
+		for _, field := range strings.Split(fieldsParam, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				opts.Fields = append(opts.Fields, field)
+			}
+		}
+	}
+
+	return opts, nil
+}
+
+// project keeps only the requested keys of a formatted item; no fields means everything.
+func project(item map[string]any, fields []string) map[string]any {
+	if len(fields) == 0 {
+		return item
+	}
+	projected := make(map[string]any, len(fields))
+	for _, field := range fields {
+		if value, ok := item[field]; ok {
+			projected[field] = value
+		}
+	}
+	return projected
+}
+
+// parseTimeParam reads an optional RFC 3339 timestamp query parameter.
+func parseTimeParam(r *http.Request, name string) (*time.Time, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC 3339 timestamp", util.ErrInvalidInput, name)
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,331 @@
+// internal/api/handler/merchant.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// MerchantHandler handles HTTP requests for merchant wallets, charges and settlements.
+type MerchantHandler struct {
+	responder
+	merchants service.MerchantService
+	wallets   service.WalletService
+	logger    *slog.Logger
+}
+
+// NewMerchantHandler creates a new MerchantHandler.
+func NewMerchantHandler(merchants service.MerchantService, wallets service.WalletService, logger *slog.Logger) *MerchantHandler {
+	return &MerchantHandler{
+		responder: responder{logger: logger},
+		merchants: merchants,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// MerchantSettingsRequest represents the request body for registering a merchant wallet.
+type MerchantSettingsRequest struct {
+	PayoutWalletID  uuid.UUID       `json:"payout_wallet_id"`
+	MinPayoutAmount decimal.Decimal `json:"min_payout_amount"` // Optional; smaller daily batches roll over
+}
+
+func (req *MerchantSettingsRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.MinPayoutAmount}
+}
+
+// ChargeRequest represents the request body for creating a charge.
+type ChargeRequest struct {
+	Amount      decimal.Decimal `json:"amount"`
+	Currency    string          `json:"currency"`
+	Description *string         `json:"description"`
+	Reference   *string         `json:"reference"` // Optional; the merchant's own order reference
+}
+
+func (req *ChargeRequest) currencyFields() []*string       { return []*string{&req.Currency} }
+func (req *ChargeRequest) amountFields() []decimal.Decimal { return []decimal.Decimal{req.Amount} }
+
+// PayChargeRequest represents the request body for paying a charge.
+type PayChargeRequest struct {
+	PayerWalletID uuid.UUID `json:"payer_wallet_id"`
+	Category      string    `json:"category"` // Optional spending category
+}
+
+// RegisterMerchant handles the register merchant wallet request. Calling it again updates the preferences.
+// PUT /wallets/{walletID}/merchant
+func (h *MerchantHandler) RegisterMerchant(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req MerchantSettingsRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.PayoutWalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	payout, err := h.wallets.GetWalletByPublicID(r.Context(), req.PayoutWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	settings, err := h.merchants.RegisterMerchant(r.Context(), wallet, payout.ID, req.MinPayoutAmount)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatMerchantSettings(settings, wallet.Currency), nil, merchantLinks(wallet))
+}
+
+// GetMerchant handles the get merchant settings request.
+// GET /wallets/{walletID}/merchant
+func (h *MerchantHandler) GetMerchant(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.merchants.GetSettings(r.Context(), wallet.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatMerchantSettings(settings, wallet.Currency), nil, merchantLinks(wallet))
+}
+
+// CreateCharge handles the create charge request. The charge is returned with the ID the customer pays.
+// POST /wallets/{walletID}/charges
+func (h *MerchantHandler) CreateCharge(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req ChargeRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	charge := &domain.Charge{
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: req.Description,
+		Reference:   req.Reference,
+	}
+	if err := h.merchants.CreateCharge(r.Context(), wallet, charge); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	links := chargeLinks(charge)
+	w.Header().Set("Location", links["self"])
+	h.respondWithData(w, http.StatusCreated, formatCharge(charge), nil, links)
+}
+
+// ListSettlements handles the list settlements request.
+// GET /wallets/{walletID}/settlements
+func (h *MerchantHandler) ListSettlements(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	settlements, err := h.merchants.ListSettlements(r.Context(), wallet.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	data := make([]settlementResponse, len(settlements))
+	for i := range settlements {
+		data[i] = formatSettlement(&settlements[i])
+	}
+	links := merchantLinks(wallet)
+	links["self"] = fmt.Sprintf("/wallets/%s/settlements", wallet.PublicID)
+	h.respondWithData(w, http.StatusOK, data, nil, links)
+}
+
+// GetCharge handles the get charge request.
+// GET /charges/{chargeID}
+func (h *MerchantHandler) GetCharge(w http.ResponseWriter, r *http.Request) {
+	chargeID, ok := h.chargeIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	charge, err := h.merchants.GetCharge(r.Context(), chargeID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatCharge(charge), nil, chargeLinks(charge))
+}
+
+// PayCharge handles the customer's confirmation of a charge.
+// POST /charges/{chargeID}/pay
+func (h *MerchantHandler) PayCharge(w http.ResponseWriter, r *http.Request) {
+	chargeID, ok := h.chargeIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req PayChargeRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.PayerWalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	payer, err := h.wallets.GetWalletByPublicID(r.Context(), req.PayerWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	ctx := service.WithTransactionCategory(r.Context(), req.Category)
+	charge, transaction, err := h.merchants.PayCharge(ctx, chargeID, payer.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	links := chargeLinks(charge)
+	links["transaction"] = fmt.Sprintf("/transactions/%s", transaction.PublicID)
+	h.respondWithData(w, http.StatusOK, formatCharge(charge), map[string]any{"message": "Payment successful"}, links)
+}
+
+// CancelCharge handles the merchant's cancellation of a pending charge.
+// POST /charges/{chargeID}/cancel
+func (h *MerchantHandler) CancelCharge(w http.ResponseWriter, r *http.Request) {
+	chargeID, ok := h.chargeIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	charge, err := h.merchants.CancelCharge(r.Context(), chargeID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatCharge(charge), nil, chargeLinks(charge))
+}
+
+// chargeIDFromPath parses the {chargeID} path parameter. On failure it writes the error response and reports false.
+func (h *MerchantHandler) chargeIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	chargeID, err := uuid.Parse(chi.URLParam(r, "chargeID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return uuid.Nil, false
+	}
+	return chargeID, true
+}
+
+func merchantLinks(wallet *domain.Wallet) types.Links {
+	return types.Links{
+		"self":        fmt.Sprintf("/wallets/%s/merchant", wallet.PublicID),
+		"wallet":      fmt.Sprintf("/wallets/%s", wallet.PublicID),
+		"settlements": fmt.Sprintf("/wallets/%s/settlements", wallet.PublicID),
+	}
+}
+
+func chargeLinks(charge *domain.Charge) types.Links {
+	return types.Links{
+		"self":     fmt.Sprintf("/charges/%s", charge.PublicID),
+		"pay":      fmt.Sprintf("/charges/%s/pay", charge.PublicID),
+		"merchant": fmt.Sprintf("/wallets/%s", charge.MerchantWalletPublicID),
+	}
+}
+
+// merchantSettingsResponse is a merchant registration as rendered in API responses.
+type merchantSettingsResponse struct {
+	WalletID        uuid.UUID `json:"wallet_id"`
+	PayoutWalletID  uuid.UUID `json:"payout_wallet_id"`
+	MinPayoutAmount money     `json:"min_payout_amount"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func formatMerchantSettings(settings *domain.MerchantSettings, currency string) merchantSettingsResponse {
+	return merchantSettingsResponse{
+		WalletID:        settings.WalletPublicID,
+		PayoutWalletID:  settings.PayoutWalletPublicID,
+		MinPayoutAmount: newMoney(settings.MinPayoutAmount, currency),
+		CreatedAt:       settings.CreatedAt,
+		UpdatedAt:       settings.UpdatedAt,
+	}
+}
+
+// chargeResponse is a charge as rendered in API responses.
+type chargeResponse struct {
+	ID               uuid.UUID           `json:"id"`
+	MerchantWalletID uuid.UUID           `json:"merchant_wallet_id"`
+	Amount           money               `json:"amount"`
+	Currency         string              `json:"currency"`
+	Description      *string             `json:"description"`
+	Reference        *string             `json:"reference"`
+	Status           domain.ChargeStatus `json:"status"`
+	PayerWalletID    *uuid.UUID          `json:"payer_wallet_id"`
+	TransactionID    *uuid.UUID          `json:"transaction_id"`
+	ExpiresAt        time.Time           `json:"expires_at"`
+	PaidAt           *time.Time          `json:"paid_at"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+}
+
+func formatCharge(charge *domain.Charge) chargeResponse {
+	return chargeResponse{
+		ID:               charge.PublicID,
+		MerchantWalletID: charge.MerchantWalletPublicID,
+		Amount:           newMoney(charge.Amount, charge.Currency),
+		Currency:         charge.Currency,
+		Description:      charge.Description,
+		Reference:        charge.Reference,
+		Status:           charge.Status,
+		PayerWalletID:    charge.PayerWalletPublicID,
+		TransactionID:    charge.TransactionID,
+		ExpiresAt:        charge.ExpiresAt,
+		PaidAt:           charge.PaidAt,
+		CreatedAt:        charge.CreatedAt,
+		UpdatedAt:        charge.UpdatedAt,
+	}
+}
+
+// settlementResponse is a daily merchant payout as rendered in API responses.
+type settlementResponse struct {
+	ID               uuid.UUID `json:"id"`
+	MerchantWalletID uuid.UUID `json:"merchant_wallet_id"`
+	PayoutWalletID   uuid.UUID `json:"payout_wallet_id"`
+	Amount           money     `json:"amount"`
+	Currency         string    `json:"currency"`
+	ChargeCount      int       `json:"charge_count"`
+	SettlementDate   time.Time `json:"settlement_date"`
+	TransactionID    uuid.UUID `json:"transaction_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func formatSettlement(settlement *domain.Settlement) settlementResponse {
+	return settlementResponse{
+		ID:               settlement.PublicID,
+		MerchantWalletID: settlement.MerchantWalletPublicID,
+		PayoutWalletID:   settlement.PayoutWalletPublicID,
+		Amount:           newMoney(settlement.Amount, settlement.Currency),
+		Currency:         settlement.Currency,
+		ChargeCount:      settlement.ChargeCount,
+		SettlementDate:   settlement.SettlementDate,
+		TransactionID:    settlement.TransactionID,
+		CreatedAt:        settlement.CreatedAt,
+	}
+}
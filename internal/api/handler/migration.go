@@ -0,0 +1,38 @@
+// internal/api/handler/migration.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/service"
+)
+
+// MigrationHandler handles the admin requests for the migration state of the database.
+type MigrationHandler struct {
+	responder
+	migrations service.MigrationService
+	logger     *slog.Logger
+}
+
+// NewMigrationHandler creates a new MigrationHandler.
+func NewMigrationHandler(migrations service.MigrationService, logger *slog.Logger) *MigrationHandler {
+	return &MigrationHandler{
+		responder:  responder{logger: logger},
+		migrations: migrations,
+		logger:     logger,
+	}
+}
+
+// GetMigrationStatus handles the get migration status request: the version of the database against that of
+// the code, the pending migrations with their expand or contract phase, and the progress of the backfills.
+// GET /admin/migrations
+func (h *MigrationHandler) GetMigrationStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.migrations.Status(r.Context())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, status, nil, types.Links{"self": "/admin/migrations"})
+}
@@ -0,0 +1,51 @@
+// internal/api/handler/money.go
+package handler
+
+import (
+	"sync/atomic"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+)
+
+// MoneyFormat selects how monetary amounts are written in JSON responses.
+type MoneyFormat int32
+
+const (
+	// MoneyAsString writes amounts as strings, e.g. "12.50". Clients never parse them as floats.
+	MoneyAsString MoneyFormat = iota
+	// MoneyAsNumber writes amounts as JSON numbers, e.g. 12.50, for clients of the legacy format.
+	MoneyAsNumber
+)
+
+var moneyFormat atomic.Int32
+
+// SetMoneyFormat sets the format of the monetary amounts of every response. It is set once at startup.
+func SetMoneyFormat(format MoneyFormat) {
+	moneyFormat.Store(int32(format))
+}
+
+// money is a monetary amount in a response, written at the scale of its currency's minor unit.
+type money struct {
+	amount decimal.Decimal
+	places int32
+}
+
+// newMoney returns amount as a response field in the given currency.
+func newMoney(amount decimal.Decimal, currency string) money {
+	return money{amount: amount, places: domain.CurrencyPrecision(currency)}
+}
+
+// String returns the amount at its currency's scale, e.g. "12.50" for USD and "12.500" for KWD.
+func (m money) String() string {
+	return m.amount.StringFixed(m.places)
+}
+
+// MarshalJSON writes the amount in the configured MoneyFormat.
+func (m money) MarshalJSON() ([]byte, error) {
+	if MoneyFormat(moneyFormat.Load()) == MoneyAsNumber {
+		return []byte(m.String()), nil
+	}
+	return []byte(`"` + m.String() + `"`), nil
+}
@@ -0,0 +1,207 @@
+// internal/api/handler/netting.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// NettingHandler handles HTTP requests for netting instructions and their net settlements.
+type NettingHandler struct {
+	responder
+	netting service.NettingService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewNettingHandler creates a new NettingHandler.
+func NewNettingHandler(netting service.NettingService, wallets service.WalletService, logger *slog.Logger) *NettingHandler {
+	return &NettingHandler{
+		responder: responder{logger: logger},
+		netting:   netting,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// NettingInstructionRequest represents the request body for submitting a netting instruction.
+type NettingInstructionRequest struct {
+	FromWalletID uuid.UUID       `json:"from_wallet_id"`
+	ToWalletID   uuid.UUID       `json:"to_wallet_id"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     string          `json:"currency"`
+	Reference    *string         `json:"reference"` // Optional; the submitter's own reference
+}
+
+func (req *NettingInstructionRequest) currencyFields() []*string { return []*string{&req.Currency} }
+func (req *NettingInstructionRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Amount}
+}
+
+// SubmitInstruction handles the submit netting instruction request. The instruction is accepted, not executed:
+// it is settled with the pair's other instructions when the netting window closes.
+// POST /netting/instructions
+func (h *NettingHandler) SubmitInstruction(w http.ResponseWriter, r *http.Request) {
+	var req NettingInstructionRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.FromWalletID == uuid.Nil || req.ToWalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	from, err := h.wallets.GetWalletByPublicID(r.Context(), req.FromWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	to, err := h.wallets.GetWalletByPublicID(r.Context(), req.ToWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	instruction, err := h.netting.SubmitInstruction(r.Context(), from, to, req.Amount, req.Currency, req.Reference)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	links := nettingInstructionLinks(instruction)
+	w.Header().Set("Location", links["self"])
+	h.respondWithData(w, http.StatusAccepted, formatNettingInstruction(instruction), nil, links)
+}
+
+// GetInstruction handles the get netting instruction request. A settled instruction links to its settlement
+// and the net transaction.
+// GET /netting/instructions/{instructionID}
+func (h *NettingHandler) GetInstruction(w http.ResponseWriter, r *http.Request) {
+	instructionID, err := uuid.Parse(chi.URLParam(r, "instructionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	instruction, err := h.netting.GetInstruction(r.Context(), instructionID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatNettingInstruction(instruction), nil, nettingInstructionLinks(instruction))
+}
+
+// GetSettlement handles the get netting settlement request. The settlement lists every instruction it settled.
+// GET /netting/settlements/{settlementID}
+func (h *NettingHandler) GetSettlement(w http.ResponseWriter, r *http.Request) {
+	settlementID, err := uuid.Parse(chi.URLParam(r, "settlementID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	settlement, instructions, err := h.netting.GetSettlement(r.Context(), settlementID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	links := types.Links{
+		"self":        fmt.Sprintf("/netting/settlements/%s", settlement.PublicID),
+		"from_wallet": fmt.Sprintf("/wallets/%s", settlement.FromWalletPublicID),
+		"to_wallet":   fmt.Sprintf("/wallets/%s", settlement.ToWalletPublicID),
+	}
+	if settlement.TransactionID != nil {
+		links["transaction"] = fmt.Sprintf("/transactions/%s", settlement.TransactionID)
+	}
+	h.respondWithData(w, http.StatusOK, formatNettingSettlement(settlement, instructions), nil, links)
+}
+
+func nettingInstructionLinks(instruction *domain.NettingInstruction) types.Links {
+	links := types.Links{
+		"self":        fmt.Sprintf("/netting/instructions/%s", instruction.PublicID),
+		"from_wallet": fmt.Sprintf("/wallets/%s", instruction.FromWalletPublicID),
+		"to_wallet":   fmt.Sprintf("/wallets/%s", instruction.ToWalletPublicID),
+	}
+	if instruction.SettlementPublicID != nil {
+		links["settlement"] = fmt.Sprintf("/netting/settlements/%s", instruction.SettlementPublicID)
+	}
+	if instruction.TransactionID != nil {
+		links["transaction"] = fmt.Sprintf("/transactions/%s", instruction.TransactionID)
+	}
+	return links
+}
+
+// nettingInstructionResponse is a netting instruction as rendered in API responses.
+type nettingInstructionResponse struct {
+	ID            uuid.UUID                       `json:"id"`
+	FromWalletID  uuid.UUID                       `json:"from_wallet_id"`
+	ToWalletID    uuid.UUID                       `json:"to_wallet_id"`
+	Amount        money                           `json:"amount"`
+	Currency      string                          `json:"currency"`
+	Reference     *string                         `json:"reference"`
+	Status        domain.NettingInstructionStatus `json:"status"`
+	SettlementID  *uuid.UUID                      `json:"settlement_id"`
+	TransactionID *uuid.UUID                      `json:"transaction_id"`
+	SettledAt     *time.Time                      `json:"settled_at"`
+	CreatedAt     time.Time                       `json:"created_at"`
+}
+
+func formatNettingInstruction(instruction *domain.NettingInstruction) nettingInstructionResponse {
+	return nettingInstructionResponse{
+		ID:            instruction.PublicID,
+		FromWalletID:  instruction.FromWalletPublicID,
+		ToWalletID:    instruction.ToWalletPublicID,
+		Amount:        newMoney(instruction.Amount, instruction.Currency),
+		Currency:      instruction.Currency,
+		Reference:     instruction.Reference,
+		Status:        instruction.Status,
+		SettlementID:  instruction.SettlementPublicID,
+		TransactionID: instruction.TransactionID,
+		SettledAt:     instruction.SettledAt,
+		CreatedAt:     instruction.CreatedAt,
+	}
+}
+
+// nettingSettlementResponse is a net settlement with its instructions as rendered in API responses.
+type nettingSettlementResponse struct {
+	ID               uuid.UUID                    `json:"id"`
+	FromWalletID     uuid.UUID                    `json:"from_wallet_id"`
+	ToWalletID       uuid.UUID                    `json:"to_wallet_id"`
+	NetAmount        money                        `json:"net_amount"`
+	GrossAmount      money                        `json:"gross_amount"`
+	Currency         string                       `json:"currency"`
+	InstructionCount int                          `json:"instruction_count"`
+	TransactionID    *uuid.UUID                   `json:"transaction_id"`
+	WindowEnd        time.Time                    `json:"window_end"`
+	CreatedAt        time.Time                    `json:"created_at"`
+	Instructions     []nettingInstructionResponse `json:"instructions"`
+}
+
+func formatNettingSettlement(settlement *domain.NettingSettlement, instructions []domain.NettingInstruction) nettingSettlementResponse {
+	data := make([]nettingInstructionResponse, len(instructions))
+	for i := range instructions {
+		data[i] = formatNettingInstruction(&instructions[i])
+	}
+	return nettingSettlementResponse{
+		ID:               settlement.PublicID,
+		FromWalletID:     settlement.FromWalletPublicID,
+		ToWalletID:       settlement.ToWalletPublicID,
+		NetAmount:        newMoney(settlement.NetAmount, settlement.Currency),
+		GrossAmount:      newMoney(settlement.GrossAmount, settlement.Currency),
+		Currency:         settlement.Currency,
+		InstructionCount: settlement.InstructionCount,
+		TransactionID:    settlement.TransactionID,
+		WindowEnd:        settlement.WindowEnd,
+		CreatedAt:        settlement.CreatedAt,
+		Instructions:     data,
+	}
+}
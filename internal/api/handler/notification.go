@@ -0,0 +1,300 @@
+// internal/api/handler/notification.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// NotificationHandler handles HTTP requests for a user's notification inbox, push devices, notification
+// preferences and the delivery status of their notifications.
+type NotificationHandler struct {
+	responder
+	notifications service.NotificationService
+	logger        *slog.Logger
+}
+
+// NewNotificationHandler creates a new NotificationHandler.
+func NewNotificationHandler(notifications service.NotificationService, logger *slog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		responder:     responder{logger: logger},
+		notifications: notifications,
+		logger:        logger,
+	}
+}
+
+// RegisterDeviceRequest represents the request body for registering a device for push notifications.
+type RegisterDeviceRequest struct {
+	Platform domain.PushPlatform `json:"platform"` // FCM or APNS
+	Token    string              `json:"token"`    // The registration token the app got from the push service
+	Label    *string             `json:"label"`    // Optional name shown to the user, e.g. "Pixel 8"
+}
+
+// NotificationPreferenceRequest sets one preference; the channel defaults to PUSH.
+type NotificationPreferenceRequest struct {
+	Category domain.NotificationCategory `json:"category"`
+	Channel  domain.NotificationChannel  `json:"channel"`
+	Enabled  *bool                       `json:"enabled"`
+}
+
+// UpdateNotificationPreferencesRequest represents the request body for updating notification preferences.
+// Preferences not listed keep their value.
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences"`
+}
+
+// NotificationInboxMeta is the pagination window of an inbox page plus the user's unread count, whatever
+// the filter.
+type NotificationInboxMeta struct {
+	types.PageMeta
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// NotificationInboxResponse is a page of a user's notification inbox.
+type NotificationInboxResponse struct {
+	Data  []domain.Notification `json:"data"`
+	Meta  NotificationInboxMeta `json:"meta"`
+	Links types.Links           `json:"links"`
+}
+
+// ListNotifications handles the list notifications request: the user's inbox, newest first. unread=true
+// lists only the unread notifications.
+// GET /users/{userID}/notifications
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	opts, err := parseListOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	unreadOnly := false
+	if raw := r.URL.Query().Get("unread"); raw != "" {
+		if unreadOnly, err = strconv.ParseBool(raw); err != nil {
+			h.respondWithError(w, r, fmt.Errorf("%w: unread must be true or false", util.ErrInvalidInput))
+			return
+		}
+	}
+
+	notifications, totalCount, err := h.notifications.ListNotifications(r.Context(), userID, unreadOnly, opts.Limit, opts.Offset)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	unread, err := h.notifications.CountUnread(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	page := types.NewPaginatedResponse(notifications, r.URL, opts.Limit, opts.Offset, totalCount)
+	h.respondWithJSON(w, http.StatusOK, NotificationInboxResponse{
+		Data:  page.Data,
+		Meta:  NotificationInboxMeta{PageMeta: page.Meta, UnreadCount: unread},
+		Links: page.Links,
+	})
+}
+
+// MarkNotificationRead handles the mark notification read request.
+// POST /users/{userID}/notifications/{notificationID}/read
+func (h *NotificationHandler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	notificationID, err := strconv.ParseInt(chi.URLParam(r, "notificationID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	notification, err := h.notifications.MarkRead(r.Context(), userID, notificationID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, notification, nil, notificationLinks(userID))
+}
+
+// MarkAllNotificationsRead handles the mark all notifications read request.
+// POST /users/{userID}/notifications/read
+func (h *NotificationHandler) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	marked, err := h.notifications.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, map[string]int64{"marked_read": marked}, nil, notificationLinks(userID))
+}
+
+// RegisterDevice handles the register device request. Registering a token again refreshes it.
+// POST /users/{userID}/devices
+func (h *NotificationHandler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req RegisterDeviceRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	device := &domain.DeviceToken{
+		UserID:   userID,
+		Platform: domain.PushPlatform(strings.ToUpper(string(req.Platform))),
+		Token:    strings.TrimSpace(req.Token),
+		Label:    req.Label,
+	}
+	if err := h.notifications.RegisterDevice(r.Context(), device); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, device, nil, deviceLinks(userID, device.ID))
+}
+
+// ListDevices handles the list devices request.
+// GET /users/{userID}/devices
+func (h *NotificationHandler) ListDevices(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	devices, err := h.notifications.ListDevices(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, devices, nil, notificationLinks(userID))
+}
+
+// DeleteDevice handles the delete device request, e.g. when the user logs out of the app.
+// DELETE /users/{userID}/devices/{deviceID}
+func (h *NotificationHandler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	deviceID, err := strconv.ParseInt(chi.URLParam(r, "deviceID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	if err := h.notifications.DeleteDevice(r.Context(), userID, deviceID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPreferences handles the get notification preferences request.
+// GET /users/{userID}/notification-preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.notifications.ListPreferences(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, preferences, nil, notificationLinks(userID))
+}
+
+// UpdatePreferences handles the update notification preferences request.
+// PUT /users/{userID}/notification-preferences
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req UpdateNotificationPreferencesRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	preferences := make([]domain.NotificationPreference, len(req.Preferences))
+	for i, p := range req.Preferences {
+		if p.Enabled == nil {
+			h.respondWithError(w, r, fmt.Errorf("%w: enabled is required", util.ErrInvalidInput))
+			return
+		}
+		preferences[i] = domain.NotificationPreference{
+			Category: domain.NotificationCategory(strings.ToUpper(string(p.Category))),
+			Channel:  domain.NotificationChannelPush,
+			Enabled:  *p.Enabled,
+		}
+		if p.Channel != "" {
+			preferences[i].Channel = domain.NotificationChannel(strings.ToUpper(string(p.Channel)))
+		}
+	}
+
+	updated, err := h.notifications.SetPreferences(r.Context(), userID, preferences)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, updated, nil, notificationLinks(userID))
+}
+
+// ListDeliveries handles the list notification deliveries request: the user's most recent push deliveries
+// with their status.
+// GET /users/{userID}/notification-deliveries
+func (h *NotificationHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.notifications.ListDeliveries(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, deliveries, nil, notificationLinks(userID))
+}
+
+func (h *NotificationHandler) userIDFromPath(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return 0, false
+	}
+	return userID, true
+}
+
+func notificationLinks(userID int64) types.Links {
+	return types.Links{
+		"devices":       fmt.Sprintf("/users/%d/devices", userID),
+		"preferences":   fmt.Sprintf("/users/%d/notification-preferences", userID),
+		"notifications": fmt.Sprintf("/users/%d/notifications", userID),
+		"deliveries":    fmt.Sprintf("/users/%d/notification-deliveries", userID),
+		"user":          fmt.Sprintf("/users/%d", userID),
+	}
+}
+
+func deviceLinks(userID, deviceID int64) types.Links {
+	links := notificationLinks(userID)
+	links["self"] = fmt.Sprintf("/users/%d/devices/%d", userID, deviceID)
+	return links
+}
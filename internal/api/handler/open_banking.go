@@ -0,0 +1,516 @@
+// internal/api/handler/open_banking.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// obBasePath is the prefix of the Open Banking account information routes.
+const obBasePath = "/open-banking/v3.1/aisp"
+
+// OpenBankingHandler handles HTTP requests of the Open Banking account information API, made by third parties
+// with an access token, and of the users answering the consents those third parties ask for.
+//
+// Third-party responses follow the UK Open Banking Read/Write API: a {Data, Links, Meta} envelope with
+// PascalCase fields and amounts as {Amount, Currency} objects. Errors use the API's standard error body.
+type OpenBankingHandler struct {
+	responder
+	openBanking service.OpenBankingService
+	logger      *slog.Logger
+}
+
+// NewOpenBankingHandler creates a new OpenBankingHandler.
+func NewOpenBankingHandler(openBanking service.OpenBankingService, logger *slog.Logger) *OpenBankingHandler {
+	return &OpenBankingHandler{
+		responder:   responder{logger: logger},
+		openBanking: openBanking,
+		logger:      logger,
+	}
+}
+
+// OBConsentRequest represents the request body for creating an account access consent.
+type OBConsentRequest struct {
+	Data struct {
+		Permissions             []domain.OBPermission `json:"Permissions"`
+		ExpirationDateTime      *time.Time            `json:"ExpirationDateTime"`
+		TransactionFromDateTime *time.Time            `json:"TransactionFromDateTime"`
+		TransactionToDateTime   *time.Time            `json:"TransactionToDateTime"`
+	} `json:"Data"`
+	Risk map[string]any `json:"Risk"` // Accepted as the standard requires; not evaluated
+}
+
+// AuthoriseConsentRequest represents the request body for authorising a consent for some of the user's wallets.
+type AuthoriseConsentRequest struct {
+	WalletIDs []uuid.UUID `json:"wallet_ids"`
+}
+
+// obResponse is the envelope of every third-party response.
+type obResponse struct {
+	Data  any            `json:"Data"`
+	Risk  map[string]any `json:"Risk,omitempty"`
+	Links obLinks        `json:"Links"`
+	Meta  obMeta         `json:"Meta"`
+}
+
+type obLinks struct {
+	Self  string `json:"Self"`
+	First string `json:"First,omitempty"`
+	Prev  string `json:"Prev,omitempty"`
+	Next  string `json:"Next,omitempty"`
+	Last  string `json:"Last,omitempty"`
+}
+
+type obMeta struct {
+	TotalPages int `json:"TotalPages"`
+}
+
+// respondOB writes a third-party response of a single page.
+func (h *OpenBankingHandler) respondOB(w http.ResponseWriter, code int, data any, self string) {
+	h.respondWithJSON(w, code, obResponse{Data: data, Links: obLinks{Self: self}, Meta: obMeta{TotalPages: 1}})
+}
+
+// obAccess returns who reads account information, from the verified access token.
+func obAccess(r *http.Request) service.OBAccess {
+	token := middleware.OpenBankingTokenFromContext(r.Context())
+	return service.OBAccess{ClientID: token.ClientID, ConsentID: token.ConsentID}
+}
+
+// CreateConsent handles the third party's request for an account access consent, which the user then
+// authorises or rejects.
+// POST /open-banking/v3.1/aisp/account-access-consents
+func (h *OpenBankingHandler) CreateConsent(w http.ResponseWriter, r *http.Request) {
+	var req OBConsentRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	token := middleware.OpenBankingTokenFromContext(r.Context())
+
+	consent, err := h.openBanking.CreateConsent(r.Context(), token.ClientID, req.Data.Permissions,
+		req.Data.ExpirationDateTime, req.Data.TransactionFromDateTime, req.Data.TransactionToDateTime)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, obResponse{
+		Data:  formatOBConsent(consent),
+		Risk:  map[string]any{},
+		Links: obLinks{Self: obConsentPath(consent.ConsentID)},
+		Meta:  obMeta{TotalPages: 1},
+	})
+}
+
+// GetConsent handles the third party's get consent request.
+// GET /open-banking/v3.1/aisp/account-access-consents/{consentID}
+func (h *OpenBankingHandler) GetConsent(w http.ResponseWriter, r *http.Request) {
+	consentID, err := uuid.Parse(chi.URLParam(r, "consentID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	token := middleware.OpenBankingTokenFromContext(r.Context())
+
+	consent, err := h.openBanking.GetConsent(r.Context(), token.ClientID, consentID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, obResponse{
+		Data:  formatOBConsent(consent),
+		Risk:  map[string]any{},
+		Links: obLinks{Self: obConsentPath(consentID)},
+		Meta:  obMeta{TotalPages: 1},
+	})
+}
+
+// DeleteConsent handles the third party's delete consent request, which revokes the consent.
+// DELETE /open-banking/v3.1/aisp/account-access-consents/{consentID}
+func (h *OpenBankingHandler) DeleteConsent(w http.ResponseWriter, r *http.Request) {
+	consentID, err := uuid.Parse(chi.URLParam(r, "consentID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	token := middleware.OpenBankingTokenFromContext(r.Context())
+
+	if err := h.openBanking.DeleteConsent(r.Context(), token.ClientID, consentID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAccounts handles the list accounts request: the wallets the consent of the access token covers.
+// GET /open-banking/v3.1/aisp/accounts
+func (h *OpenBankingHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	wallets, err := h.openBanking.ListAccounts(r.Context(), obAccess(r))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	accounts := make([]obAccount, 0, len(wallets))
+	for i := range wallets {
+		accounts = append(accounts, formatOBAccount(&wallets[i]))
+	}
+	h.respondOB(w, http.StatusOK, map[string]any{"Account": accounts}, obBasePath+"/accounts")
+}
+
+// GetAccount handles the get account request.
+// GET /open-banking/v3.1/aisp/accounts/{accountID}
+func (h *OpenBankingHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.openBanking.GetAccount(r.Context(), obAccess(r), accountID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondOB(w, http.StatusOK, map[string]any{"Account": []obAccount{formatOBAccount(wallet)}}, obAccountPath(accountID))
+}
+
+// GetBalances handles the get balances request. A wallet has a single balance, available as soon as a
+// transaction completes.
+// GET /open-banking/v3.1/aisp/accounts/{accountID}/balances
+func (h *OpenBankingHandler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	wallet, err := h.openBanking.GetBalance(r.Context(), obAccess(r), accountID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	amount, indicator := obAmount(wallet.Balance, wallet.Currency)
+	balance := obBalance{
+		AccountID:            wallet.PublicID,
+		Amount:               amount,
+		CreditDebitIndicator: indicator,
+		Type:                 "InterimAvailable",
+		DateTime:             wallet.UpdatedAt,
+	}
+	h.respondOB(w, http.StatusOK, map[string]any{"Balance": []obBalance{balance}}, obAccountPath(accountID)+"/balances")
+}
+
+// ListTransactions handles the list transactions request, newest first. fromBookingDateTime and
+// toBookingDateTime narrow the range within the consent's transaction window; limit and offset page it.
+// GET /open-banking/v3.1/aisp/accounts/{accountID}/transactions
+func (h *OpenBankingHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := h.accountIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	from, err := parseOBDateTime(r, "fromBookingDateTime")
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	to, err := parseOBDateTime(r, "toBookingDateTime")
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	opts := repository.ListOptions{Limit: listLimit(r)}
+	if offset, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && offset >= 0 {
+		opts.Offset = offset
+	}
+
+	wallet, transactions, total, err := h.openBanking.ListTransactions(r.Context(), obAccess(r), accountID, from, to, opts)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	items := make([]obTransaction, 0, len(transactions))
+	for i := range transactions {
+		items = append(items, formatOBTransaction(wallet, &transactions[i]))
+	}
+	self := obAccountPath(accountID) + "/transactions"
+	totalPages := int((total + int64(opts.Limit) - 1) / int64(opts.Limit))
+	links := obLinks{Self: r.URL.RequestURI()}
+	page := func(offset int) string {
+		query := r.URL.Query()
+		query.Set("limit", strconv.Itoa(opts.Limit))
+		query.Set("offset", strconv.Itoa(offset))
+		return self + "?" + query.Encode()
+	}
+	if opts.Offset > 0 {
+		links.First = page(0)
+		links.Prev = page(max(opts.Offset-opts.Limit, 0))
+	}
+	if int64(opts.Offset+opts.Limit) < total {
+		links.Next = page(opts.Offset + opts.Limit)
+		links.Last = page((totalPages - 1) * opts.Limit)
+	}
+	h.respondWithJSON(w, http.StatusOK, obResponse{
+		Data:  map[string]any{"Transaction": items},
+		Links: links,
+		Meta:  obMeta{TotalPages: max(totalPages, 1)},
+	})
+}
+
+// ListUserConsents handles the user's list consents request.
+// GET /users/{userID}/open-banking/consents
+func (h *OpenBankingHandler) ListUserConsents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	consents, err := h.openBanking.ListUserConsents(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, consents, map[string]any{"total": len(consents)}, types.Links{
+		"self": fmt.Sprintf("/users/%d/open-banking/consents", userID),
+		"user": fmt.Sprintf("/users/%d", userID),
+	})
+}
+
+// AuthoriseConsent handles the user's authorisation of a consent for some of their wallets.
+// POST /users/{userID}/open-banking/consents/{consentID}/authorise
+func (h *OpenBankingHandler) AuthoriseConsent(w http.ResponseWriter, r *http.Request) {
+	userID, consentID, ok := h.userConsentFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req AuthoriseConsentRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	consent, err := h.openBanking.AuthoriseConsent(r.Context(), userID, consentID, req.WalletIDs)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, consent, nil, userConsentLinks(userID, consent))
+}
+
+// RejectConsent handles the user's rejection of a consent.
+// POST /users/{userID}/open-banking/consents/{consentID}/reject
+func (h *OpenBankingHandler) RejectConsent(w http.ResponseWriter, r *http.Request) {
+	userID, consentID, ok := h.userConsentFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	consent, err := h.openBanking.RejectConsent(r.Context(), userID, consentID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, consent, nil, userConsentLinks(userID, consent))
+}
+
+// RevokeConsent handles the user's revocation of a consent they authorised. The third party loses access
+// immediately.
+// DELETE /users/{userID}/open-banking/consents/{consentID}
+func (h *OpenBankingHandler) RevokeConsent(w http.ResponseWriter, r *http.Request) {
+	userID, consentID, ok := h.userConsentFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	consent, err := h.openBanking.RevokeConsent(r.Context(), userID, consentID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, consent, nil, userConsentLinks(userID, consent))
+}
+
+// accountIDFromPath parses the accountID path parameter. On failure it writes the error response and reports false.
+func (h *OpenBankingHandler) accountIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	accountID, err := uuid.Parse(chi.URLParam(r, "accountID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return uuid.Nil, false
+	}
+	return accountID, true
+}
+
+// userIDFromPath parses the userID path parameter. On failure it writes the error response and reports false.
+func (h *OpenBankingHandler) userIDFromPath(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return 0, false
+	}
+	return userID, true
+}
+
+// userConsentFromPath parses the userID and consentID path parameters. On failure it writes the error
+// response and reports false.
+func (h *OpenBankingHandler) userConsentFromPath(w http.ResponseWriter, r *http.Request) (int64, uuid.UUID, bool) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return 0, uuid.Nil, false
+	}
+	consentID, err := uuid.Parse(chi.URLParam(r, "consentID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return 0, uuid.Nil, false
+	}
+	return userID, consentID, true
+}
+
+// parseOBDateTime parses an optional ISO 8601 date-time query parameter.
+func parseOBDateTime(r *http.Request, name string) (*time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC 3339 date-time", util.ErrInvalidInput, name)
+	}
+	return &parsed, nil
+}
+
+func obConsentPath(consentID uuid.UUID) string {
+	return fmt.Sprintf("%s/account-access-consents/%s", obBasePath, consentID)
+}
+
+func obAccountPath(accountID uuid.UUID) string {
+	return fmt.Sprintf("%s/accounts/%s", obBasePath, accountID)
+}
+
+func userConsentLinks(userID int64, consent *domain.OBConsent) types.Links {
+	links := types.Links{
+		"self":     fmt.Sprintf("/users/%d/open-banking/consents/%s", userID, consent.ConsentID),
+		"consents": fmt.Sprintf("/users/%d/open-banking/consents", userID),
+	}
+	for _, walletID := range consent.WalletPublicIDs {
+		links["wallet_"+walletID.String()] = fmt.Sprintf("/wallets/%s", walletID)
+	}
+	return links
+}
+
+// obConsent is an account access consent as rendered to third parties.
+type obConsent struct {
+	ConsentID               uuid.UUID              `json:"ConsentId"`
+	Status                  domain.OBConsentStatus `json:"Status"`
+	StatusUpdateDateTime    time.Time              `json:"StatusUpdateDateTime"`
+	CreationDateTime        time.Time              `json:"CreationDateTime"`
+	Permissions             []domain.OBPermission  `json:"Permissions"`
+	ExpirationDateTime      time.Time              `json:"ExpirationDateTime"`
+	TransactionFromDateTime *time.Time             `json:"TransactionFromDateTime,omitempty"`
+	TransactionToDateTime   *time.Time             `json:"TransactionToDateTime,omitempty"`
+}
+
+func formatOBConsent(consent *domain.OBConsent) obConsent {
+	return obConsent{
+		ConsentID:               consent.ConsentID,
+		Status:                  consent.Status,
+		StatusUpdateDateTime:    consent.StatusUpdateDateTime,
+		CreationDateTime:        consent.CreationDateTime,
+		Permissions:             consent.Permissions,
+		ExpirationDateTime:      consent.ExpirationDateTime,
+		TransactionFromDateTime: consent.TransactionFromDateTime,
+		TransactionToDateTime:   consent.TransactionToDateTime,
+	}
+}
+
+// obAccount is a wallet as rendered to third parties. Wallets are e-money accounts without a scheme
+// identifier such as an IBAN.
+type obAccount struct {
+	AccountID      uuid.UUID `json:"AccountId"`
+	Status         string    `json:"Status"`
+	Currency       string    `json:"Currency"`
+	AccountType    string    `json:"AccountType"`
+	AccountSubType string    `json:"AccountSubType"`
+	Nickname       string    `json:"Nickname"`
+	OpeningDate    time.Time `json:"OpeningDate"`
+}
+
+func formatOBAccount(wallet *domain.Wallet) obAccount {
+	status := "Enabled"
+	if wallet.FrozenAt != nil || wallet.OwnershipFrozenAt != nil {
+		status = "Disabled"
+	}
+	return obAccount{
+		AccountID:      wallet.PublicID,
+		Status:         status,
+		Currency:       wallet.Currency,
+		AccountType:    "Personal",
+		AccountSubType: "EMoney",
+		Nickname:       fmt.Sprintf("%s wallet", wallet.Currency),
+		OpeningDate:    wallet.CreatedAt,
+	}
+}
+
+// obAmountValue is an amount as rendered to third parties: unsigned, with the sign in a CreditDebitIndicator.
+type obAmountValue struct {
+	Amount   string `json:"Amount"`
+	Currency string `json:"Currency"`
+}
+
+// obAmount splits a signed amount into its absolute value and its CreditDebitIndicator.
+func obAmount(amount decimal.Decimal, currency string) (obAmountValue, string) {
+	indicator := "Credit"
+	if amount.IsNegative() {
+		indicator = "Debit"
+	}
+	return obAmountValue{Amount: newMoney(amount.Abs(), currency).String(), Currency: currency}, indicator
+}
+
+type obBalance struct {
+	AccountID            uuid.UUID     `json:"AccountId"`
+	Amount               obAmountValue `json:"Amount"`
+	CreditDebitIndicator string        `json:"CreditDebitIndicator"`
+	Type                 string        `json:"Type"`
+	DateTime             time.Time     `json:"DateTime"`
+}
+
+// obTransaction is a transaction of a wallet as rendered to third parties, from the wallet's side.
+type obTransaction struct {
+	AccountID              uuid.UUID     `json:"AccountId"`
+	TransactionID          uuid.UUID     `json:"TransactionId"`
+	Amount                 obAmountValue `json:"Amount"`
+	CreditDebitIndicator   string        `json:"CreditDebitIndicator"`
+	Status                 string        `json:"Status"`
+	BookingDateTime        time.Time     `json:"BookingDateTime"`
+	TransactionInformation *string       `json:"TransactionInformation,omitempty"`
+	TransactionCode        string        `json:"ProprietaryBankTransactionCode"`
+}
+
+func formatOBTransaction(wallet *domain.Wallet, transaction *domain.Transaction) obTransaction {
+	amount := transaction.Amount
+	if transaction.FromWalletID != nil && *transaction.FromWalletID == wallet.ID {
+		amount = amount.Neg()
+	}
+	value, indicator := obAmount(amount, transaction.Currency)
+	status := "Booked"
+	if transaction.Status == domain.TransactionStatusPending {
+		status = "Pending"
+	}
+	return obTransaction{
+		AccountID:              wallet.PublicID,
+		TransactionID:          transaction.PublicID,
+		Amount:                 value,
+		CreditDebitIndicator:   indicator,
+		Status:                 status,
+		BookingDateTime:        transaction.TransactionTime,
+		TransactionInformation: transaction.Description,
+		TransactionCode:        string(transaction.Type),
+	}
+}
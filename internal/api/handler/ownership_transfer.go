@@ -0,0 +1,227 @@
+// internal/api/handler/ownership_transfer.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// OwnershipTransferHandler handles the transfers of wallets to other users: requested and cancelled by named
+// admins under /admin, and accepted or declined by the target user.
+type OwnershipTransferHandler struct {
+	responder
+	transfers service.OwnershipTransferService
+	wallets   service.WalletService
+	logger    *slog.Logger
+}
+
+// NewOwnershipTransferHandler creates a new OwnershipTransferHandler.
+func NewOwnershipTransferHandler(transfers service.OwnershipTransferService, wallets service.WalletService, logger *slog.Logger) *OwnershipTransferHandler {
+	return &OwnershipTransferHandler{
+		responder: responder{logger: logger},
+		transfers: transfers,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// RequestOwnershipTransferRequest represents the request body for transferring a wallet to another user.
+type RequestOwnershipTransferRequest struct {
+	ToUserID int64  `json:"to_user_id"`
+	Reason   string `json:"reason"` // Required, e.g. the ticket of the account merge
+}
+
+// RequestOwnershipTransfer handles the request to transfer a wallet to another user. The wallet is frozen
+// at once but changes owner only when the target user accepts, so it yields 202 Accepted.
+// POST /admin/wallets/{walletID}/ownership-transfers
+func (h *OwnershipTransferHandler) RequestOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	var req RequestOwnershipTransferRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	transfer, err := h.transfers.Request(r.Context(), wallet, req.ToUserID, req.Reason, middleware.AdminFromContext(r.Context()))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusAccepted, transfer, nil, adminOwnershipTransferLinks(transfer))
+}
+
+// ListWalletOwnershipTransfers handles the list request for the transfers of a wallet, newest first.
+// GET /admin/wallets/{walletID}/ownership-transfers
+func (h *OwnershipTransferHandler) ListWalletOwnershipTransfers(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	transfers, err := h.transfers.ListWalletTransfers(r.Context(), wallet.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, transfers, map[string]any{"total": len(transfers)}, types.Links{
+		"self":   r.URL.Path,
+		"wallet": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	})
+}
+
+// GetOwnershipTransfer handles the get ownership transfer request.
+// GET /admin/ownership-transfers/{transferID}
+func (h *OwnershipTransferHandler) GetOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	transferID, ok := h.transferIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.transfers.GetTransfer(r.Context(), transferID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, transfer, nil, adminOwnershipTransferLinks(transfer))
+}
+
+// CancelOwnershipTransfer handles the admin's cancellation of a pending transfer, which unfreezes the wallet.
+// A transfer that is no longer pending yields 409 Conflict.
+// POST /admin/ownership-transfers/{transferID}/cancel
+func (h *OwnershipTransferHandler) CancelOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	transferID, ok := h.transferIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.transfers.Cancel(r.Context(), transferID, middleware.AdminFromContext(r.Context()))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, transfer, nil, adminOwnershipTransferLinks(transfer))
+}
+
+// ListIncomingOwnershipTransfers handles the list request for the transfers of wallets to the user,
+// optionally for one status, e.g. ?status=PENDING for those awaiting their answer.
+// GET /users/{userID}/wallet-transfers
+func (h *OwnershipTransferHandler) ListIncomingOwnershipTransfers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	status := domain.OwnershipTransferStatus(strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("status"))))
+
+	transfers, err := h.transfers.ListIncomingTransfers(r.Context(), userID, status)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, transfers, map[string]any{"total": len(transfers)}, types.Links{
+		"self": r.URL.String(),
+		"user": fmt.Sprintf("/users/%d", userID),
+	})
+}
+
+// AcceptOwnershipTransfer handles the target user's acceptance of a transfer, which makes them the owner of
+// the unfrozen wallet. A transfer that is no longer pending or has expired yields 409 Conflict.
+// POST /users/{userID}/wallet-transfers/{transferID}/accept
+func (h *OwnershipTransferHandler) AcceptOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, transferID, ok := h.userTransferFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	transfer, _, err := h.transfers.Accept(r.Context(), userID, transferID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, transfer, nil, userOwnershipTransferLinks(userID, transfer))
+}
+
+// DeclineOwnershipTransfer handles the target user's refusal of a transfer; the wallet keeps its owner.
+// POST /users/{userID}/wallet-transfers/{transferID}/decline
+func (h *OwnershipTransferHandler) DeclineOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, transferID, ok := h.userTransferFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.transfers.Decline(r.Context(), userID, transferID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, transfer, nil, userOwnershipTransferLinks(userID, transfer))
+}
+
+func (h *OwnershipTransferHandler) transferIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	transferID, err := uuid.Parse(chi.URLParam(r, "transferID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return uuid.Nil, false
+	}
+	return transferID, true
+}
+
+func (h *OwnershipTransferHandler) userIDFromPath(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return 0, false
+	}
+	return userID, true
+}
+
+// userTransferFromPath parses the userID and transferID path parameters. On failure it writes the error
+// response and reports false.
+func (h *OwnershipTransferHandler) userTransferFromPath(w http.ResponseWriter, r *http.Request) (int64, uuid.UUID, bool) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return 0, uuid.Nil, false
+	}
+	transferID, ok := h.transferIDFromPath(w, r)
+	if !ok {
+		return 0, uuid.Nil, false
+	}
+	return userID, transferID, true
+}
+
+func adminOwnershipTransferLinks(transfer *domain.OwnershipTransfer) types.Links {
+	links := types.Links{
+		"self":   fmt.Sprintf("/admin/ownership-transfers/%s", transfer.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", transfer.WalletPublicID),
+		"audit":  fmt.Sprintf("/admin/wallets/%s/audit", transfer.WalletPublicID),
+	}
+	if transfer.Status == domain.OwnershipTransferPending {
+		links["cancel"] = fmt.Sprintf("/admin/ownership-transfers/%s/cancel", transfer.PublicID)
+	}
+	return links
+}
+
+func userOwnershipTransferLinks(userID int64, transfer *domain.OwnershipTransfer) types.Links {
+	links := types.Links{
+		"transfers": fmt.Sprintf("/users/%d/wallet-transfers", userID),
+		"wallet":    fmt.Sprintf("/wallets/%s", transfer.WalletPublicID),
+	}
+	if transfer.Status == domain.OwnershipTransferPending {
+		links["accept"] = fmt.Sprintf("/users/%d/wallet-transfers/%s/accept", userID, transfer.PublicID)
+		links["decline"] = fmt.Sprintf("/users/%d/wallet-transfers/%s/decline", userID, transfer.PublicID)
+	}
+	return links
+}
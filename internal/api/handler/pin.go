@@ -0,0 +1,153 @@
+// internal/api/handler/pin.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// PINHandler handles HTTP requests for a user's transaction PIN.
+type PINHandler struct {
+	responder
+	pins   service.PINService
+	logger *slog.Logger
+}
+
+// NewPINHandler creates a new PINHandler.
+func NewPINHandler(pins service.PINService, logger *slog.Logger) *PINHandler {
+	return &PINHandler{
+		responder: responder{logger: logger},
+		pins:      pins,
+		logger:    logger,
+	}
+}
+
+// SetPINRequest represents the request body for setting or changing a transaction PIN.
+type SetPINRequest struct {
+	PIN        string `json:"pin"`
+	CurrentPIN string `json:"current_pin"` // Required to change an existing PIN
+}
+
+// VerifyPINRequest represents the request body for a step-up verification of a transaction PIN.
+type VerifyPINRequest struct {
+	PIN string `json:"pin"`
+}
+
+// GetPIN handles the get PIN status request. The PIN itself is never returned.
+// GET /users/{userID}/pin
+func (h *PINHandler) GetPIN(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	pin, err := h.pins.GetPIN(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatPIN(pin), nil, pinLinks(userID))
+}
+
+// SetPIN handles the set PIN request.
+// PUT /users/{userID}/pin
+func (h *PINHandler) SetPIN(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req SetPINRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	pin, err := h.pins.SetPIN(r.Context(), userID, req.PIN, req.CurrentPIN)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatPIN(pin), nil, pinLinks(userID))
+}
+
+// DeletePIN handles the delete PIN request, confirmed with the current PIN in the X-Transaction-PIN header.
+// DELETE /users/{userID}/pin
+func (h *PINHandler) DeletePIN(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.pins.DeletePIN(r.Context(), userID, r.Header.Get(transactionPINHeader)); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyPIN handles the step-up request: after a successful verification, the user's withdrawals and
+// transfers are confirmed without the PIN until step_up_until.
+// POST /users/{userID}/pin/verify
+func (h *PINHandler) VerifyPIN(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req VerifyPINRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	pin, err := h.pins.StepUp(r.Context(), userID, req.PIN)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatPIN(pin), nil, pinLinks(userID))
+}
+
+// userIDFromPath parses the userID path parameter. On failure it writes the error response and reports false.
+func (h *PINHandler) userIDFromPath(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return 0, false
+	}
+	return userID, true
+}
+
+func pinLinks(userID int64) types.Links {
+	return types.Links{
+		"self": fmt.Sprintf("/users/%d/pin", userID),
+		"user": fmt.Sprintf("/users/%d", userID),
+	}
+}
+
+// pinResponse is the status of a user's transaction PIN as rendered in API responses.
+type pinResponse struct {
+	Enabled     bool       `json:"enabled"`
+	LockedUntil *time.Time `json:"locked_until"`
+	StepUpUntil *time.Time `json:"step_up_until"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+func formatPIN(pin *domain.UserPIN) pinResponse {
+	if pin == nil {
+		return pinResponse{}
+	}
+	return pinResponse{
+		Enabled:     true,
+		LockedUntil: pin.LockedUntil,
+		StepUpUntil: pin.StepUpUntil,
+		UpdatedAt:   &pin.UpdatedAt,
+	}
+}
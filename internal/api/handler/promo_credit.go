@@ -0,0 +1,143 @@
+// internal/api/handler/promo_credit.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// PromoCreditHandler handles HTTP requests for promotional credits: granting under /admin, and listing per wallet.
+type PromoCreditHandler struct {
+	responder
+	credits service.PromoCreditService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewPromoCreditHandler creates a new PromoCreditHandler.
+func NewPromoCreditHandler(credits service.PromoCreditService, wallets service.WalletService, logger *slog.Logger) *PromoCreditHandler {
+	return &PromoCreditHandler{
+		responder: responder{logger: logger},
+		credits:   credits,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// PromoCreditRequest represents the request body for granting a promotional credit.
+type PromoCreditRequest struct {
+	Amount          decimal.Decimal `json:"amount"`
+	ExpiresAt       time.Time       `json:"expires_at"`
+	Reason          *string         `json:"reason"`           // Optional
+	AllowWithdrawal bool            `json:"allow_withdrawal"` // Optional; by default the credit can only be transferred
+}
+
+func (req *PromoCreditRequest) amountFields() []decimal.Decimal { return []decimal.Decimal{req.Amount} }
+
+// ListPromoCredits handles the list promo credits request. The credit spendable now is reported in meta.
+// GET /wallets/{walletID}/promo-credits
+func (h *PromoCreditHandler) ListPromoCredits(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	credits, err := h.credits.ListCredits(r.Context(), wallet.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	now := time.Now().UTC()
+	available := decimal.Zero
+	data := make([]promoCreditResponse, len(credits))
+	for i := range credits {
+		if credits[i].Spendable(now) {
+			available = available.Add(credits[i].Remaining)
+		}
+		data[i] = formatPromoCredit(&credits[i], wallet.Currency)
+	}
+	h.respondWithData(w, http.StatusOK, data, map[string]any{
+		"available": newMoney(available, wallet.Currency),
+		"currency":  wallet.Currency,
+		"as_of":     now,
+	}, promoCreditLinks(wallet))
+}
+
+// GrantPromoCredit handles the grant promo credit request.
+// POST /admin/wallets/{walletID}/promo-credits
+func (h *PromoCreditHandler) GrantPromoCredit(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req PromoCreditRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if !req.Amount.Equal(req.Amount.Truncate(domain.CurrencyPrecision(wallet.Currency))) {
+		h.respondWithError(w, r, fmt.Errorf("%w: amount must fit the currency's minor unit", util.ErrInvalidInput))
+		return
+	}
+
+	credit := &domain.PromoCredit{
+		WalletID:        wallet.ID,
+		WalletPublicID:  wallet.PublicID,
+		Amount:          req.Amount,
+		AllowWithdrawal: req.AllowWithdrawal,
+		Reason:          req.Reason,
+		ExpiresAt:       req.ExpiresAt,
+	}
+	if err := h.credits.GrantCredit(r.Context(), credit); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.logger.Warn("Promo credit granted by operator", "wallet_id", wallet.PublicID, "amount", credit.Amount.String())
+	h.respondWithData(w, http.StatusCreated, formatPromoCredit(credit, wallet.Currency), nil, promoCreditLinks(wallet))
+}
+
+func promoCreditLinks(wallet *domain.Wallet) types.Links {
+	return types.Links{
+		"self":   fmt.Sprintf("/wallets/%s/promo-credits", wallet.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	}
+}
+
+// promoCreditResponse is a promotional credit as rendered in API responses, in the wallet's currency.
+type promoCreditResponse struct {
+	ID              uuid.UUID                `json:"id"`
+	WalletID        uuid.UUID                `json:"wallet_id"`
+	Amount          money                    `json:"amount"`
+	Remaining       money                    `json:"remaining"`
+	AllowWithdrawal bool                     `json:"allow_withdrawal"`
+	Reason          *string                  `json:"reason"`
+	Status          domain.PromoCreditStatus `json:"status"`
+	ExpiresAt       time.Time                `json:"expires_at"`
+	CreatedAt       time.Time                `json:"created_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
+}
+
+func formatPromoCredit(credit *domain.PromoCredit, currency string) promoCreditResponse {
+	return promoCreditResponse{
+		ID:              credit.PublicID,
+		WalletID:        credit.WalletPublicID,
+		Amount:          newMoney(credit.Amount, currency),
+		Remaining:       newMoney(credit.Remaining, currency),
+		AllowWithdrawal: credit.AllowWithdrawal,
+		Reason:          credit.Reason,
+		Status:          credit.Status,
+		ExpiresAt:       credit.ExpiresAt,
+		CreatedAt:       credit.CreatedAt,
+		UpdatedAt:       credit.UpdatedAt,
+	}
+}
@@ -0,0 +1,101 @@
+// internal/api/handler/protobuf.go
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"finflow-wallet/internal/api/types"
+)
+
+// protobufContentType is the media type of protobuf responses.
+const protobufContentType = "application/x-protobuf"
+
+// protoMessage is a response that can also be written in the protobuf encoding of api/proto/wallet.proto.
+// The few messages are encoded by hand; they only have string fields.
+type protoMessage interface {
+	appendProto(b []byte) []byte
+}
+
+// appendProtoString appends a string field in the protobuf wire format: the tag, with the length-delimited
+// wire type, the length and the bytes. Empty strings are left out, as proto3 does.
+func appendProtoString(b []byte, field int, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = appendProtoVarint(b, uint64(field)<<3|2)
+	b = appendProtoVarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+func appendProtoVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// walletBalanceProto is finflow.wallet.v1.WalletBalance.
+type walletBalanceProto struct {
+	walletID string
+	balance  money
+	currency string
+}
+
+func (m walletBalanceProto) appendProto(b []byte) []byte {
+	b = appendProtoString(b, 1, m.walletID)
+	b = appendProtoString(b, 2, m.balance.String())
+	return appendProtoString(b, 3, m.currency)
+}
+
+// transferResultProto is finflow.wallet.v1.TransferResult.
+type transferResultProto struct {
+	transactionID        string
+	fromWalletNewBalance money
+}
+
+func (m transferResultProto) appendProto(b []byte) []byte {
+	b = appendProtoString(b, 1, m.transactionID)
+	return appendProtoString(b, 2, m.fromWalletNewBalance.String())
+}
+
+// prefersProtobuf reports whether the Accept header ranks protobuf at least as high as JSON. Without an
+// Accept header, or with */* alone, the answer is JSON.
+func prefersProtobuf(r *http.Request) bool {
+	var protobufQ, jsonQ float64
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case protobufContentType, "application/protobuf":
+			protobufQ = max(protobufQ, q)
+		case "application/json", "application/*", "*/*":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return protobufQ > 0 && protobufQ >= jsonQ
+}
+
+// respondWithDataOrProto writes msg in protobuf when the request prefers it, and data in the standard JSON
+// envelope otherwise. Either way the response varies by Accept.
+func (rs responder) respondWithDataOrProto(w http.ResponseWriter, r *http.Request, code int, data any, meta map[string]any, links types.Links, msg protoMessage) {
+	w.Header().Add("Vary", "Accept")
+	if !prefersProtobuf(r) {
+		rs.respondWithData(w, code, data, meta, links)
+		return
+	}
+	w.Header().Set("Content-Type", protobufContentType)
+	w.WriteHeader(code)
+	_, _ = w.Write(msg.appendProto(nil))
+}
@@ -0,0 +1,188 @@
+// internal/api/handler/ramp.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// RampHandler handles HTTP requests for ramps between fiat and stablecoin wallets.
+type RampHandler struct {
+	responder
+	ramps   service.RampService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewRampHandler creates a new RampHandler.
+func NewRampHandler(ramps service.RampService, wallets service.WalletService, logger *slog.Logger) *RampHandler {
+	return &RampHandler{
+		responder: responder{logger: logger},
+		ramps:     ramps,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// RampQuoteRequest represents the request body for quoting a ramp from the wallet in the path.
+type RampQuoteRequest struct {
+	ToWalletID uuid.UUID       `json:"to_wallet_id"`
+	Amount     decimal.Decimal `json:"amount"` // In the source wallet's currency
+}
+
+func (req *RampQuoteRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Amount}
+}
+
+// QuoteRamp handles the ramp quote request. No money moves; the order can be executed until it expires.
+// POST /wallets/{walletID}/ramps
+func (h *RampHandler) QuoteRamp(w http.ResponseWriter, r *http.Request) {
+	source, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	var req RampQuoteRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.ToWalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	destination, err := h.wallets.GetWalletByPublicID(r.Context(), req.ToWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	order, err := h.ramps.QuoteRamp(r.Context(), source, destination, req.Amount)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, formatRampOrder(order), map[string]any{
+		"message": "Execute the ramp before it expires",
+	}, rampLinks(source, order))
+}
+
+// GetRamp handles the get ramp request, from either wallet of the ramp.
+// GET /wallets/{walletID}/ramps/{rampID}
+func (h *RampHandler) GetRamp(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	rampID, err := uuid.Parse(chi.URLParam(r, "rampID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	order, err := h.ramps.GetRamp(r.Context(), wallet, rampID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatRampOrder(order), nil, rampLinks(wallet, order))
+}
+
+// ExecuteRamp handles the ramp execution request from the ramp's source wallet. An expired or already
+// used quote returns 409 quote_not_usable; executing an executed ramp again returns it unchanged.
+// POST /wallets/{walletID}/ramps/{rampID}/execute
+func (h *RampHandler) ExecuteRamp(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	rampID, err := uuid.Parse(chi.URLParam(r, "rampID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	order, err := h.ramps.ExecuteRamp(r.Context(), wallet, rampID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatRampOrder(order), nil, rampLinks(wallet, order))
+}
+
+func rampLinks(wallet *domain.Wallet, order *domain.RampOrder) types.Links {
+	links := types.Links{
+		"self":        fmt.Sprintf("/wallets/%s/ramps/%s", wallet.PublicID, order.PublicID),
+		"from_wallet": fmt.Sprintf("/wallets/%s", order.FromWalletPublicID),
+		"to_wallet":   fmt.Sprintf("/wallets/%s", order.ToWalletPublicID),
+	}
+	if order.Status == domain.RampQuoted {
+		links["execute"] = fmt.Sprintf("/wallets/%s/ramps/%s/execute", order.FromWalletPublicID, order.PublicID)
+	}
+	if order.DebitTransactionID != nil {
+		links["debit_transaction"] = fmt.Sprintf("/transactions/%s", *order.DebitTransactionID)
+	}
+	if order.CreditTransactionID != nil {
+		links["credit_transaction"] = fmt.Sprintf("/transactions/%s", *order.CreditTransactionID)
+	}
+	return links
+}
+
+// rampOrderResponse is a ramp order as rendered in API responses.
+type rampOrderResponse struct {
+	ID                  uuid.UUID            `json:"id"`
+	Direction           domain.RampDirection `json:"direction"`
+	Status              domain.RampStatus    `json:"status"`
+	QuoteID             uuid.UUID            `json:"quote_id"`
+	FromWalletID        uuid.UUID            `json:"from_wallet_id"`
+	ToWalletID          uuid.UUID            `json:"to_wallet_id"`
+	Amount              money                `json:"amount"`
+	Currency            string               `json:"currency"`
+	Fee                 money                `json:"fee"`
+	DebitAmount         money                `json:"debit_amount"`
+	CreditAmount        money                `json:"credit_amount"`
+	CreditCurrency      string               `json:"credit_currency"`
+	Rate                *string              `json:"rate"`
+	ExpiresAt           time.Time            `json:"expires_at"`
+	DebitTransactionID  *uuid.UUID           `json:"debit_transaction_id"`
+	CreditTransactionID *uuid.UUID           `json:"credit_transaction_id"`
+	ExecutedAt          *time.Time           `json:"executed_at"`
+	CreatedAt           time.Time            `json:"created_at"`
+}
+
+func formatRampOrder(order *domain.RampOrder) rampOrderResponse {
+	response := rampOrderResponse{
+		ID:                  order.PublicID,
+		Direction:           order.Direction,
+		Status:              order.Status,
+		QuoteID:             order.QuotePublicID,
+		FromWalletID:        order.FromWalletPublicID,
+		ToWalletID:          order.ToWalletPublicID,
+		Amount:              newMoney(order.Amount, order.Currency),
+		Currency:            order.Currency,
+		Fee:                 newMoney(order.Fee, order.Currency),
+		DebitAmount:         newMoney(order.DebitAmount(), order.Currency),
+		CreditAmount:        newMoney(order.CreditAmount, order.CreditCurrency),
+		CreditCurrency:      order.CreditCurrency,
+		ExpiresAt:           order.ExpiresAt,
+		DebitTransactionID:  order.DebitTransactionID,
+		CreditTransactionID: order.CreditTransactionID,
+		ExecutedAt:          order.ExecutedAt,
+		CreatedAt:           order.CreatedAt,
+	}
+	if order.Rate != nil {
+		rate := order.Rate.String()
+		response.Rate = &rate
+	}
+	return response
+}
@@ -0,0 +1,136 @@
+// internal/api/handler/report.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// defaultReportTop is how many of the largest balances per currency a report lists when top is not given.
+const defaultReportTop = 10
+
+// ReportHandler handles HTTP requests for treasury reports.
+type ReportHandler struct {
+	responder
+	reports service.ReportService
+	logger  *slog.Logger
+}
+
+// NewReportHandler creates a new ReportHandler.
+func NewReportHandler(reports service.ReportService, logger *slog.Logger) *ReportHandler {
+	return &ReportHandler{
+		responder: responder{logger: logger},
+		reports:   reports,
+		logger:    logger,
+	}
+}
+
+// GetLiabilityReport handles the liability report request: total customer balances per currency, the float
+// movement over [from, to), both RFC 3339 timestamps, and the top largest balances per currency. The period
+// defaults to the current UTC day, so that repeated requests share a cached report.
+// GET /admin/reports/liability
+func (h *ReportHandler) GetLiabilityReport(w http.ResponseWriter, r *http.Request) {
+	from, err := parseTimeParam(r, "from")
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	to, err := parseTimeParam(r, "to")
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	if from == nil {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		from = &today
+	}
+	if to == nil {
+		end := from.Add(24 * time.Hour)
+		to = &end
+	}
+	top := defaultReportTop
+	if raw := r.URL.Query().Get("top"); raw != "" {
+		if top, err = strconv.Atoi(raw); err != nil {
+			h.respondWithError(w, r, fmt.Errorf("%w: top must be a number", util.ErrInvalidInput))
+			return
+		}
+	}
+
+	report, err := h.reports.LiabilityReport(r.Context(), *from, *to, top)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	if writeCacheHeaders(w, r, currentCachePolicy().Reports, report.GeneratedAt) {
+		return
+	}
+	meta := map[string]any{"from": report.From, "to": report.To, "generated_at": report.GeneratedAt}
+	h.respondWithData(w, http.StatusOK, formatLiabilityReport(report), meta, types.Links{"self": r.URL.Path})
+}
+
+// liabilityReportResponse is a liability report as rendered in API responses.
+type liabilityReportResponse struct {
+	Liabilities     []currencyLiabilityResponse `json:"liabilities"`
+	Float           []floatMovementResponse     `json:"float"`
+	LargestBalances []rankedBalanceResponse     `json:"largest_balances"`
+}
+
+type currencyLiabilityResponse struct {
+	Currency     string `json:"currency"`
+	TotalBalance money  `json:"total_balance"`
+	WalletCount  int64  `json:"wallet_count"`
+}
+
+type floatMovementResponse struct {
+	Currency string `json:"currency"`
+	Inflow   money  `json:"inflow"`
+	Outflow  money  `json:"outflow"`
+	Net      money  `json:"net"`
+}
+
+type rankedBalanceResponse struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	UserID   int64     `json:"user_id"`
+	Balance  money     `json:"balance"`
+}
+
+func formatLiabilityReport(report *domain.LiabilityReport) liabilityReportResponse {
+	resp := liabilityReportResponse{
+		Liabilities:     make([]currencyLiabilityResponse, len(report.Liabilities)),
+		Float:           make([]floatMovementResponse, len(report.Float)),
+		LargestBalances: make([]rankedBalanceResponse, len(report.LargestBalances)),
+	}
+	for i, liability := range report.Liabilities {
+		resp.Liabilities[i] = currencyLiabilityResponse{
+			Currency:     liability.Currency,
+			TotalBalance: newMoney(liability.TotalBalance, liability.Currency),
+			WalletCount:  liability.WalletCount,
+		}
+	}
+	for i, movement := range report.Float {
+		resp.Float[i] = floatMovementResponse{
+			Currency: movement.Currency,
+			Inflow:   newMoney(movement.Inflow, movement.Currency),
+			Outflow:  newMoney(movement.Outflow, movement.Currency),
+			Net:      newMoney(movement.Net(), movement.Currency),
+		}
+	}
+	for i, balance := range report.LargestBalances {
+		resp.LargestBalances[i] = rankedBalanceResponse{
+			WalletID: balance.WalletPublicID,
+			UserID:   balance.UserID,
+			Balance:  newMoney(balance.Balance, balance.Currency),
+		}
+	}
+	return resp
+}
@@ -0,0 +1,53 @@
+// internal/api/handler/request.go
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// currencyRequest is a request body with currency fields, which decodeRequest normalizes.
+type currencyRequest interface {
+	currencyFields() []*string
+}
+
+// amountRequest is a request body with amount fields, which decodeRequest checks against the
+// deployment's amount scale.
+type amountRequest interface {
+	amountFields() []decimal.Decimal
+}
+
+// decodeRequest decodes the JSON request body into req. The currency fields of a currencyRequest
+// are trimmed and upper-cased, and must then be in the currency registry; every currency field is
+// required. The amount fields of an amountRequest must not have more decimal places than
+// domain.AmountScale. On failure it writes the error response and reports false.
+func (rs responder) decodeRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		rs.respondWithError(w, r, util.ErrInvalidInput)
+		return false
+	}
+	if cr, ok := req.(currencyRequest); ok {
+		for _, currency := range cr.currencyFields() {
+			*currency = strings.ToUpper(strings.TrimSpace(*currency))
+			if !domain.IsSupportedCurrency(*currency) {
+				rs.respondWithError(w, r, util.ErrCurrencyUnsupported)
+				return false
+			}
+		}
+	}
+	if ar, ok := req.(amountRequest); ok {
+		for _, amount := range ar.amountFields() {
+			if places := domain.AmountPlaces(amount); places > domain.AmountScale() {
+				rs.respondWithError(w, r, &util.AmountPrecisionError{Places: places, MaxPlaces: domain.AmountScale()})
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,282 @@
+// internal/api/handler/response.go
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/i18n"
+	"finflow-wallet/internal/util" // For custom errors
+)
+
+// responder holds the response-writing helpers shared by all handlers.
+// Handlers embed it so every endpoint produces the same envelope and error shapes.
+type responder struct {
+	logger *slog.Logger
+}
+
+// Helper function to send JSON responses.
+func (rs responder) respondWithJSON(w http.ResponseWriter, code int, payload any) {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		rs.logger.Error("Failed to marshal JSON response", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(response)
+}
+
+// respondWithData wraps a single resource in the standard {data, meta, links} envelope.
+func (rs responder) respondWithData(w http.ResponseWriter, code int, data any, meta map[string]any, links types.Links) {
+	rs.respondWithJSON(w, code, types.Response[any]{
+		Data:  data,
+		Meta:  meta,
+		Links: links,
+	})
+}
+
+// respondWithError maps a service error to its HTTP status and writes the API's standard
+// {"error": message, "code": code} body. The code is stable; the message is in the request's locale.
+func (rs responder) respondWithError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := rs.describeError(err)
+	if apiErr.location != "" {
+		w.Header().Set("Location", apiErr.location)
+	}
+	body := map[string]string{"error": apiErr.message(r), "code": apiErr.code}
+	for field, value := range apiErr.extra {
+		body[field] = value
+	}
+	rs.respondWithJSON(w, apiErr.status, body)
+}
+
+// respondWithMultiStatus writes the results of a bulk request in the standard multi-status envelope.
+func (rs responder) respondWithMultiStatus(w http.ResponseWriter, items []types.ItemResult[any]) {
+	status, response := types.NewMultiStatusResponse(items)
+	rs.respondWithJSON(w, status, response)
+}
+
+// itemSucceeded reports a processed item of a bulk request.
+func itemSucceeded(index, status int, data any) types.ItemResult[any] {
+	return types.ItemResult[any]{Index: index, Status: status, Data: data}
+}
+
+// itemFailed reports a failed item of a bulk request with the status, code and message the error would
+// get as the error of a single request.
+func (rs responder) itemFailed(r *http.Request, index int, err error) types.ItemResult[any] {
+	apiErr := rs.describeError(err)
+	return types.ItemResult[any]{Index: index, Status: apiErr.status, Error: apiErr.message(r), Code: apiErr.code, Details: apiErr.extra}
+}
+
+// apiError is how an error is reported to clients.
+type apiError struct {
+	status   int
+	code     string
+	key      string            // Message key when it differs from the code
+	params   map[string]string // Message placeholders
+	extra    map[string]string // Extra fields of the error body
+	location string            // Sent as the Location header, e.g. of the resource a create collided with
+}
+
+// message returns the error's message in the request's locale.
+func (e apiError) message(r *http.Request) string {
+	key := e.key
+	if key == "" {
+		key = e.code
+	}
+	return i18n.Message(r.Context(), key, e.params)
+}
+
+// describeError maps a service error to its HTTP status, code and message.
+func (rs responder) describeError(err error) apiError {
+	statusCode := http.StatusInternalServerError
+	code := "internal_error"
+	key := ""                    // Message key when it differs from the code
+	var params map[string]string // Message placeholders
+	var extra map[string]string  // Extra fields of the error body
+	location := ""               // The resource the error points at, if any
+
+	switch {
+	case util.IsError(err, util.ErrInvalidInput):
+		statusCode = http.StatusBadRequest
+		code = "invalid_input"
+		params = map[string]string{"detail": err.Error()} // The detail itself is not translated
+	case util.IsError(err, util.ErrNotFound), util.IsError(err, util.ErrWalletNotFound), util.IsError(err, util.ErrUserNotFound):
+		statusCode = http.StatusNotFound
+		code = "not_found"
+	case util.IsError(err, util.ErrInsufficientFunds):
+		statusCode = http.StatusPaymentRequired // 402 Payment Required
+		code = "insufficient_funds"
+	case util.IsError(err, util.ErrSameWalletTransfer):
+		statusCode = http.StatusBadRequest
+		code = "same_wallet_transfer"
+	case util.IsError(err, util.ErrCurrencyMismatch):
+		statusCode = http.StatusBadRequest
+		code = "currency_mismatch"
+	case util.IsError(err, util.ErrCurrencyUnsupported):
+		statusCode = http.StatusBadRequest
+		code = "currency_unsupported"
+	case util.IsError(err, util.ErrAmountPrecision):
+		statusCode = http.StatusBadRequest
+		code = "amount_precision_exceeded"
+		// Say how many places are accepted, so the client can round rather than guess.
+		var precision *util.AmountPrecisionError
+		if errors.As(err, &precision) {
+			key = "amount_precision_exceeded.places"
+			maxPlaces := strconv.Itoa(int(precision.MaxPlaces))
+			params = map[string]string{"places": strconv.Itoa(int(precision.Places)), "max_places": maxPlaces}
+			extra = map[string]string{"max_decimal_places": maxPlaces}
+		}
+	case util.IsError(err, util.ErrDuplicateEntry):
+		statusCode = http.StatusConflict
+		code = "already_exists"
+		// Point the client at the resource it collided with so a retried create can recover it.
+		var duplicate *util.DuplicateEntryError
+		if errors.As(err, &duplicate) && duplicate.ExistingID != 0 {
+			key = "already_exists.resource"
+			params = map[string]string{"resource": duplicate.Resource}
+			location = fmt.Sprintf("/%ss/%d", duplicate.Resource, duplicate.ExistingID)
+		}
+	case util.IsError(err, util.ErrReferenceViolation):
+		statusCode = http.StatusConflict
+		code = "reference_violation"
+	case util.IsError(err, util.ErrConcurrentUpdate):
+		statusCode = http.StatusConflict
+		code = "concurrent_update"
+	case util.IsError(err, util.ErrConstraintViolation):
+		statusCode = http.StatusUnprocessableEntity
+		code = "constraint_violation"
+	case util.IsError(err, util.ErrPreconditionFailed):
+		statusCode = http.StatusPreconditionFailed
+		code = "precondition_failed"
+	case util.IsError(err, util.ErrFXRateUnavailable):
+		statusCode = http.StatusUnprocessableEntity
+		code = "fx_rate_unavailable"
+	case util.IsError(err, util.ErrFXRateStale):
+		statusCode = http.StatusServiceUnavailable
+		code = "fx_rate_stale"
+	case util.IsError(err, util.ErrForbidden):
+		statusCode = http.StatusForbidden
+		code = "forbidden"
+	case util.IsError(err, util.ErrApprovalRequired):
+		statusCode = http.StatusForbidden
+		code = "approval_required"
+	case util.IsError(err, util.ErrApprovalNotPending):
+		statusCode = http.StatusConflict
+		code = "approval_not_pending"
+	case util.IsError(err, util.ErrBudgetExceeded):
+		statusCode = http.StatusUnprocessableEntity
+		code = "budget_exceeded"
+	case util.IsError(err, util.ErrNotMerchantWallet):
+		statusCode = http.StatusUnprocessableEntity
+		code = "not_merchant_wallet"
+	case util.IsError(err, util.ErrChargeNotPending):
+		statusCode = http.StatusConflict
+		code = "charge_not_pending"
+	case util.IsError(err, util.ErrBillNotOpen):
+		statusCode = http.StatusConflict
+		code = "bill_not_open"
+	case util.IsError(err, util.ErrBillNotApproved):
+		statusCode = http.StatusConflict
+		code = "bill_not_approved"
+	case util.IsError(err, util.ErrVoucherNotRedeemable):
+		statusCode = http.StatusConflict
+		code = "voucher_not_redeemable"
+	case util.IsError(err, util.ErrWalletNotEmpty):
+		statusCode = http.StatusConflict
+		code = "wallet_not_empty"
+	case util.IsError(err, util.ErrQuoteNotUsable):
+		statusCode = http.StatusConflict
+		code = "quote_not_usable"
+	case util.IsError(err, util.ErrDuplicateTransfer):
+		statusCode = http.StatusConflict
+		code = "duplicate_transfer"
+		// Show the earlier transfer, so the user can tell whether the first attempt went through.
+		var duplicate *util.DuplicateTransferError
+		if errors.As(err, &duplicate) {
+			key = "duplicate_transfer.existing"
+			createdAt := duplicate.CreatedAt.UTC().Format(time.RFC3339)
+			params = map[string]string{"created_at": createdAt}
+			extra = map[string]string{"existing_transaction_id": duplicate.ExistingID.String(), "existing_created_at": createdAt}
+			location = fmt.Sprintf("/transactions/%s", duplicate.ExistingID)
+		}
+	case util.IsError(err, util.ErrFundsNotSuspended):
+		statusCode = http.StatusConflict
+		code = "funds_not_suspended"
+	case util.IsError(err, util.ErrAdjustmentNotPending):
+		statusCode = http.StatusConflict
+		code = "adjustment_not_pending"
+	case util.IsError(err, util.ErrScreeningHit):
+		statusCode = http.StatusForbidden
+		code = "screening_hit"
+	case util.IsError(err, util.ErrScreeningCaseClosed):
+		statusCode = http.StatusConflict
+		code = "screening_case_closed"
+	case util.IsError(err, util.ErrCurrencyRestricted):
+		statusCode = http.StatusForbidden
+		code = "currency_restricted"
+	case util.IsError(err, util.ErrPINRequired):
+		statusCode = http.StatusForbidden
+		code = "pin_required"
+	case util.IsError(err, util.ErrInvalidPIN):
+		statusCode = http.StatusForbidden
+		code = "invalid_pin"
+	case util.IsError(err, util.ErrPINLocked):
+		statusCode = http.StatusLocked
+		code = "pin_locked"
+	case util.IsError(err, util.ErrInvalidVerificationCode):
+		statusCode = http.StatusBadRequest
+		code = "invalid_verification_code"
+	case util.IsError(err, util.ErrVerificationThrottled):
+		statusCode = http.StatusTooManyRequests
+		code = "verification_throttled"
+	case util.IsError(err, util.ErrContactInUse):
+		statusCode = http.StatusConflict
+		code = "contact_in_use"
+	case util.IsError(err, util.ErrWalletFrozen):
+		statusCode = http.StatusForbidden
+		code = "wallet_frozen"
+	case util.IsError(err, util.ErrVerificationRequired):
+		statusCode = http.StatusForbidden
+		code = "verification_required"
+	case util.IsError(err, util.ErrVolumeQuotaExceeded):
+		statusCode = http.StatusForbidden
+		code = "volume_quota_exceeded"
+	case util.IsError(err, util.ErrRampLiquidity):
+		statusCode = http.StatusServiceUnavailable
+		code = "ramp_liquidity_unavailable"
+	case util.IsError(err, util.ErrConsentInvalid):
+		statusCode = http.StatusForbidden
+		code = "consent_invalid"
+	case util.IsError(err, util.ErrConsentNotPending):
+		statusCode = http.StatusConflict
+		code = "consent_not_pending"
+	case util.IsError(err, util.ErrInvalidIDToken):
+		statusCode = http.StatusUnauthorized
+		code = "invalid_id_token"
+	case util.IsError(err, util.ErrIdentityLinked):
+		statusCode = http.StatusConflict
+		code = "identity_linked"
+	case util.IsError(err, util.ErrUserDeactivated):
+		statusCode = http.StatusForbidden
+		code = "user_deactivated"
+	case util.IsError(err, util.ErrOwnershipTransferFrozen):
+		statusCode = http.StatusForbidden
+		code = "wallet_ownership_frozen"
+	case util.IsError(err, util.ErrOwnershipNotPending):
+		statusCode = http.StatusConflict
+		code = "ownership_transfer_not_pending"
+	// Add more specific error mappings as needed
+	default:
+		rs.logger.Error("Unhandled service error", "error", err)
+	}
+
+	return apiError{status: statusCode, code: code, key: key, params: params, extra: extra, location: location}
+}
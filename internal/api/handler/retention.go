@@ -0,0 +1,72 @@
+// internal/api/handler/retention.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// RetentionHandler handles the admin requests for the retention of audit and notification records.
+type RetentionHandler struct {
+	responder
+	retention service.RetentionService
+	logger    *slog.Logger
+}
+
+// NewRetentionHandler creates a new RetentionHandler.
+func NewRetentionHandler(retention service.RetentionService, logger *slog.Logger) *RetentionHandler {
+	return &RetentionHandler{
+		responder: responder{logger: logger},
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// GetRetention handles the get retention request: the retention period of each record type with its purge
+// metrics since the process started.
+// GET /admin/retention
+func (h *RetentionHandler) GetRetention(w http.ResponseWriter, r *http.Request) {
+	stats := h.retention.Stats()
+	formatted := make([]map[string]any, len(stats))
+	for i, s := range stats {
+		formatted[i] = map[string]any{
+			"type":           s.Type,
+			"retention_days": int(s.Retention / (24 * time.Hour)), // 0 keeps the records forever
+			"purged":         s.Purged,
+			"runs":           s.Runs,
+			"last_run":       s.LastRun,
+			"last_run_at":    s.LastRunAt,
+			"last_failure":   s.LastFailure,
+		}
+	}
+	h.respondWithData(w, http.StatusOK, formatted, nil, types.Links{"self": "/admin/retention", "purge": "/admin/retention/purge"})
+}
+
+// PurgeRecords handles the purge request, purging the records past their retention period now rather than
+// at night. With dry_run=true it only counts them.
+// POST /admin/retention/purge
+func (h *RetentionHandler) PurgeRecords(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			h.respondWithError(w, r, fmt.Errorf("%w: dry_run must be true or false", util.ErrInvalidInput))
+			return
+		}
+	}
+
+	results, err := h.retention.Purge(r.Context(), time.Now().UTC(), dryRun)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.logger.Warn("Records purged on demand", "dry_run", dryRun, "types", len(results))
+	h.respondWithData(w, http.StatusOK, results, nil, types.Links{"self": "/admin/retention/purge", "retention": "/admin/retention"})
+}
@@ -0,0 +1,479 @@
+// internal/api/handler/scim.go
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// SCIM 2.0 schema URNs (RFC 7643, RFC 7644).
+const (
+	scimUserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// scimMaxCount bounds the page size of a user list.
+const scimMaxCount = 100
+
+// SCIMHandler handles the SCIM 2.0 requests of enterprise identity systems provisioning users. Requests and
+// responses follow the SCIM protocol rather than the API's envelope: resources are returned bare as
+// application/scim+json, and errors use the SCIM error schema with the API's localized message as detail.
+type SCIMHandler struct {
+	responder
+	scim   service.SCIMService
+	logger *slog.Logger
+}
+
+// NewSCIMHandler creates a new SCIMHandler.
+func NewSCIMHandler(scim service.SCIMService, logger *slog.Logger) *SCIMHandler {
+	return &SCIMHandler{
+		responder: responder{logger: logger},
+		scim:      scim,
+		logger:    logger,
+	}
+}
+
+// scimMultiValue is an entry of the emails or phoneNumbers of a SCIM user.
+type scimMultiValue struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMUserRequest represents the body of a SCIM user create or replace request. Attributes the service does
+// not manage, such as name, are ignored.
+type SCIMUserRequest struct {
+	UserName     string           `json:"userName"`
+	ExternalID   *string          `json:"externalId"`
+	Active       *bool            `json:"active"` // Defaults to true
+	Emails       []scimMultiValue `json:"emails"`
+	PhoneNumbers []scimMultiValue `json:"phoneNumbers"`
+}
+
+func (req *SCIMUserRequest) attributes() service.SCIMUserAttributes {
+	return service.SCIMUserAttributes{
+		UserName:   req.UserName,
+		ExternalID: req.ExternalID,
+		Email:      primaryValue(req.Emails),
+		Phone:      primaryValue(req.PhoneNumbers),
+		Active:     req.Active == nil || *req.Active,
+	}
+}
+
+// primaryValue returns the primary value of a multi-valued attribute, else its first, or nil if it is empty.
+func primaryValue(values []scimMultiValue) *string {
+	if len(values) == 0 {
+		return nil
+	}
+	chosen := values[0]
+	for _, value := range values {
+		if value.Primary {
+			chosen = value
+			break
+		}
+	}
+	return &chosen.Value
+}
+
+// SCIMPatchRequest represents the body of a SCIM PATCH request.
+type SCIMPatchRequest struct {
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation is one operation of a SCIM PATCH request.
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"` // add, replace or remove, in any case
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// scimUser is the SCIM representation of a provisioned user.
+type scimUser struct {
+	Schemas      []string         `json:"schemas"`
+	ID           uuid.UUID        `json:"id"`
+	ExternalID   *string          `json:"externalId,omitempty"`
+	UserName     string           `json:"userName"`
+	Active       bool             `json:"active"`
+	Emails       []scimMultiValue `json:"emails,omitempty"`
+	PhoneNumbers []scimMultiValue `json:"phoneNumbers,omitempty"`
+	Meta         scimMeta         `json:"meta"`
+}
+
+type scimMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+func formatSCIMUser(result *service.SCIMUserResult) scimUser {
+	user := result.User
+	formatted := scimUser{
+		Schemas:    []string{scimUserSchema},
+		ID:         result.Resource.PublicID,
+		ExternalID: result.Resource.ExternalID,
+		UserName:   user.Username,
+		Active:     user.DeactivatedAt == nil,
+		Meta: scimMeta{
+			ResourceType: "User",
+			Created:      result.Resource.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     scimUserPath(result.Resource.PublicID),
+		},
+	}
+	if result.Resource.UpdatedAt.After(formatted.Meta.LastModified) {
+		formatted.Meta.LastModified = result.Resource.UpdatedAt
+	}
+	if user.Email != nil {
+		formatted.Emails = []scimMultiValue{{Value: *user.Email, Type: "work", Primary: true}}
+	}
+	if user.Phone != nil {
+		formatted.PhoneNumbers = []scimMultiValue{{Value: *user.Phone, Type: "work", Primary: true}}
+	}
+	return formatted
+}
+
+func scimUserPath(id uuid.UUID) string {
+	return fmt.Sprintf("/scim/v2/Users/%s", id)
+}
+
+// respondSCIM writes a SCIM resource or message.
+func (h *SCIMHandler) respondSCIM(w http.ResponseWriter, code int, payload any) {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		h.logger.Error("Failed to marshal SCIM response", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(code)
+	_, _ = w.Write(response)
+}
+
+// respondSCIMError maps a service error like respondWithError does, and writes it in the SCIM error schema.
+func (h *SCIMHandler) respondSCIMError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := h.describeError(err)
+	body := map[string]any{
+		"schemas": []string{scimErrorSchema},
+		"status":  strconv.Itoa(apiErr.status),
+		"detail":  apiErr.message(r),
+	}
+	switch apiErr.status {
+	case http.StatusConflict:
+		body["scimType"] = "uniqueness"
+	case http.StatusBadRequest:
+		body["scimType"] = "invalidValue"
+	}
+	h.respondSCIM(w, apiErr.status, body)
+}
+
+// client returns the tenant the request is authenticated as, and the request's ID.
+func (h *SCIMHandler) client(r *http.Request) service.SCIMClient {
+	return service.SCIMClient{
+		Tenant:    middleware.SCIMTenantFromContext(r.Context()),
+		RequestID: chimiddleware.GetReqID(r.Context()),
+	}
+}
+
+// decodeSCIM decodes a request body; on failure it writes the SCIM error and reports false.
+func (h *SCIMHandler) decodeSCIM(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		h.respondSCIMError(w, r, util.ErrInvalidInput)
+		return false
+	}
+	return true
+}
+
+// userIDFromPath parses the SCIM id path parameter. On failure it writes the error response and reports false.
+func (h *SCIMHandler) userIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.respondSCIMError(w, r, util.ErrUserNotFound)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// CreateUser handles the SCIM create user request; the user gets a wallet in the tenant's default currency.
+// POST /scim/v2/Users
+func (h *SCIMHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req SCIMUserRequest
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+
+	result, err := h.scim.CreateUser(r.Context(), h.client(r), req.attributes())
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", scimUserPath(result.Resource.PublicID))
+	h.respondSCIM(w, http.StatusCreated, formatSCIMUser(result))
+}
+
+// GetUser handles the SCIM get user request.
+// GET /scim/v2/Users/{id}
+func (h *SCIMHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.scim.GetUser(r.Context(), h.client(r).Tenant, id)
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+	h.respondSCIM(w, http.StatusOK, formatSCIMUser(result))
+}
+
+// ListUsers handles the SCIM list users request. The only filters supported are the exact matches identity
+// systems look users up with: userName eq "..." and externalId eq "...".
+// GET /scim/v2/Users?filter=userName eq "alice"&startIndex=1&count=100
+func (h *SCIMHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseSCIMFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+	startIndex, count := 1, scimMaxCount
+	if raw := r.URL.Query().Get("startIndex"); raw != "" {
+		if startIndex, err = strconv.Atoi(raw); err != nil {
+			h.respondSCIMError(w, r, util.ErrInvalidInput)
+			return
+		}
+		startIndex = max(startIndex, 1)
+	}
+	if raw := r.URL.Query().Get("count"); raw != "" {
+		if count, err = strconv.Atoi(raw); err != nil {
+			h.respondSCIMError(w, r, util.ErrInvalidInput)
+			return
+		}
+		count = min(max(count, 0), scimMaxCount)
+	}
+
+	results, total, err := h.scim.ListUsers(r.Context(), h.client(r).Tenant, filter, count, startIndex-1)
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+	resources := make([]scimUser, len(results))
+	for i := range results {
+		resources[i] = formatSCIMUser(&results[i])
+	}
+	h.respondSCIM(w, http.StatusOK, map[string]any{
+		"schemas":      []string{scimListResponseSchema},
+		"totalResults": total,
+		"startIndex":   startIndex,
+		"itemsPerPage": len(resources),
+		"Resources":    resources,
+	})
+}
+
+// parseSCIMFilter parses a filter of the form `attribute eq "value"`.
+func parseSCIMFilter(raw string) (service.SCIMFilter, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return service.SCIMFilter{}, nil
+	}
+	attribute, rest, ok := strings.Cut(raw, " ")
+	operator, value, ok2 := strings.Cut(strings.TrimSpace(rest), " ")
+	value, err := strconv.Unquote(strings.TrimSpace(value))
+	if !ok || !ok2 || !strings.EqualFold(operator, "eq") || err != nil {
+		return service.SCIMFilter{}, fmt.Errorf("%w: unsupported filter %q", util.ErrInvalidInput, raw)
+	}
+	return service.SCIMFilter{Attribute: attribute, Value: value}, nil
+}
+
+// ReplaceUser handles the SCIM replace user request. Setting active to false deactivates the user.
+// PUT /scim/v2/Users/{id}
+func (h *SCIMHandler) ReplaceUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req SCIMUserRequest
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+
+	result, err := h.scim.ReplaceUser(r.Context(), h.client(r), id, req.attributes())
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+	h.respondSCIM(w, http.StatusOK, formatSCIMUser(result))
+}
+
+// PatchUser handles the SCIM patch user request. Operations add, replace or remove userName, externalId,
+// active, emails and phoneNumbers; an operation without a path sets the attributes of its value.
+// PATCH /scim/v2/Users/{id}
+func (h *SCIMHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req SCIMPatchRequest
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+	patch, err := parseSCIMPatch(req.Operations)
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+
+	result, err := h.scim.PatchUser(r.Context(), h.client(r), id, patch)
+	if err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+	h.respondSCIM(w, http.StatusOK, formatSCIMUser(result))
+}
+
+// parseSCIMPatch turns PATCH operations into a patch of the managed attributes. A path with a value filter
+// such as emails[type eq "work"].value addresses the user's single email.
+func parseSCIMPatch(operations []SCIMPatchOperation) (service.SCIMUserPatch, error) {
+	var patch service.SCIMUserPatch
+	invalid := func(format string, args ...any) (service.SCIMUserPatch, error) {
+		return service.SCIMUserPatch{}, fmt.Errorf("%w: %s", util.ErrInvalidInput, fmt.Sprintf(format, args...))
+	}
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return invalid("unsupported operation %q", operation.Op)
+		}
+		values := map[string]json.RawMessage{}
+		if operation.Path == "" {
+			if op == "remove" || json.Unmarshal(operation.Value, &values) != nil {
+				return invalid("an operation without a path needs an object value")
+			}
+		} else {
+			attribute, _, _ := strings.Cut(operation.Path, "[")
+			attribute, _, _ = strings.Cut(attribute, ".")
+			values[attribute] = operation.Value
+			if op == "remove" {
+				values[attribute] = json.RawMessage(`""`)
+			}
+		}
+		for attribute, value := range values {
+			if err := applySCIMPatchValue(&patch, attribute, value); err != nil {
+				return invalid("%s: %v", attribute, err)
+			}
+		}
+	}
+	return patch, nil
+}
+
+// applySCIMPatchValue sets one attribute of the patch. Values are accepted in the shapes identity systems
+// send them: active as a boolean or "True"/"False", emails and phone numbers as a list or a single value.
+func applySCIMPatchValue(patch *service.SCIMUserPatch, attribute string, value json.RawMessage) error {
+	switch attribute {
+	case "userName":
+		return json.Unmarshal(value, &patch.UserName)
+	case "externalId":
+		return json.Unmarshal(value, &patch.ExternalID)
+	case "active":
+		var active bool
+		if err := json.Unmarshal(value, &active); err != nil {
+			var text string
+			if json.Unmarshal(value, &text) != nil {
+				return err
+			}
+			if active, err = strconv.ParseBool(text); err != nil {
+				return err
+			}
+		}
+		patch.Active = &active
+		return nil
+	case "emails", "phoneNumbers":
+		single, err := scimSingleValue(value)
+		if err != nil {
+			return err
+		}
+		if attribute == "emails" {
+			patch.Email = single
+		} else {
+			patch.Phone = single
+		}
+		return nil
+	}
+	return fmt.Errorf("not a managed attribute")
+}
+
+// scimSingleValue reads a multi-valued attribute given as a list of values, a single value object or a bare
+// string; an empty list or string removes it.
+func scimSingleValue(value json.RawMessage) (*string, error) {
+	var text string
+	if json.Unmarshal(value, &text) == nil {
+		return &text, nil
+	}
+	var single scimMultiValue
+	if json.Unmarshal(value, &single) == nil {
+		return &single.Value, nil
+	}
+	var list []scimMultiValue
+	if err := json.Unmarshal(value, &list); err != nil {
+		return nil, err
+	}
+	empty := ""
+	if chosen := primaryValue(list); chosen != nil {
+		return chosen, nil
+	}
+	return &empty, nil
+}
+
+// DeactivateUser handles the SCIM delete user request by deactivating the user: they keep their wallets and
+// money, and the resource stays readable with active false.
+// DELETE /scim/v2/Users/{id}
+func (h *SCIMHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.scim.DeactivateUser(r.Context(), h.client(r), id); err != nil {
+		h.respondSCIMError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListProvisioningEvents handles the admin request for the provisioning audit records of a user.
+// GET /admin/users/{userID}/provisioning-events
+func (h *SCIMHandler) ListProvisioningEvents(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	events, err := h.scim.ListProvisioningEvents(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	if events == nil {
+		events = []domain.SCIMProvisioningEvent{}
+	}
+	h.respondWithData(w, http.StatusOK, events, map[string]any{"total": len(events)}, types.Links{
+		"self": fmt.Sprintf("/admin/users/%d/provisioning-events", userID),
+		"user": fmt.Sprintf("/users/%d", userID),
+	})
+}
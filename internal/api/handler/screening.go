@@ -0,0 +1,158 @@
+// internal/api/handler/screening.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// ScreeningHandler handles the admin requests for the screening denylist and its review cases.
+type ScreeningHandler struct {
+	responder
+	screening service.ScreeningService
+	logger    *slog.Logger
+}
+
+// NewScreeningHandler creates a new ScreeningHandler.
+func NewScreeningHandler(screening service.ScreeningService, logger *slog.Logger) *ScreeningHandler {
+	return &ScreeningHandler{
+		responder: responder{logger: logger},
+		screening: screening,
+		logger:    logger,
+	}
+}
+
+// DenylistEntryRequest represents the request body for adding a denylist entry.
+type DenylistEntryRequest struct {
+	Name   string  `json:"name"`
+	Reason *string `json:"reason"` // Optional, e.g. the sanctions list the name comes from
+}
+
+// ResolveScreeningCaseRequest represents the request body for resolving a screening case.
+type ResolveScreeningCaseRequest struct {
+	Status domain.ScreeningCaseStatus `json:"status"` // CLEARED or CONFIRMED
+	Note   *string                    `json:"note"`
+}
+
+// ListDenylistEntries handles the list denylist request.
+// GET /admin/denylist
+func (h *ScreeningHandler) ListDenylistEntries(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.screening.ListDenylistEntries(r.Context())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, entries, nil, types.Links{"self": "/admin/denylist"})
+}
+
+// AddDenylistEntry handles the add denylist entry request. A name matching an existing entry once normalized
+// yields 409 Conflict.
+// POST /admin/denylist
+func (h *ScreeningHandler) AddDenylistEntry(w http.ResponseWriter, r *http.Request) {
+	var req DenylistEntryRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	entry, err := h.screening.AddDenylistEntry(r.Context(), strings.TrimSpace(req.Name), req.Reason, middleware.AdminFromContext(r.Context()))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, entry, nil, types.Links{
+		"self":     fmt.Sprintf("/admin/denylist/%d", entry.ID),
+		"denylist": "/admin/denylist",
+	})
+}
+
+// DeleteDenylistEntry handles the delete denylist entry request. An entry with review cases yields 409 Conflict.
+// DELETE /admin/denylist/{entryID}
+func (h *ScreeningHandler) DeleteDenylistEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := strconv.ParseInt(chi.URLParam(r, "entryID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	if err := h.screening.DeleteDenylistEntry(r.Context(), entryID, middleware.AdminFromContext(r.Context())); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListScreeningCases handles the list screening cases request, optionally for one status, e.g. ?status=OPEN
+// for the review queue.
+// GET /admin/screening/cases
+func (h *ScreeningHandler) ListScreeningCases(w http.ResponseWriter, r *http.Request) {
+	status := domain.ScreeningCaseStatus(strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("status"))))
+	cases, err := h.screening.ListCases(r.Context(), status)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, cases, nil, types.Links{"self": r.URL.String()})
+}
+
+// GetScreeningCase handles the get screening case request.
+// GET /admin/screening/cases/{caseID}
+func (h *ScreeningHandler) GetScreeningCase(w http.ResponseWriter, r *http.Request) {
+	caseID, err := uuid.Parse(chi.URLParam(r, "caseID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	screeningCase, err := h.screening.GetCase(r.Context(), caseID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, screeningCase, nil, screeningCaseLinks(screeningCase))
+}
+
+// ResolveScreeningCase handles the resolve screening case request. CLEARED lifts the block for the party and
+// entry; CONFIRMED keeps it. A case that is already resolved yields 409 Conflict.
+// POST /admin/screening/cases/{caseID}/resolve
+func (h *ScreeningHandler) ResolveScreeningCase(w http.ResponseWriter, r *http.Request) {
+	caseID, err := uuid.Parse(chi.URLParam(r, "caseID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	var req ResolveScreeningCaseRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	status := domain.ScreeningCaseStatus(strings.ToUpper(strings.TrimSpace(string(req.Status))))
+	screeningCase, err := h.screening.ResolveCase(r.Context(), caseID, status, middleware.AdminFromContext(r.Context()), req.Note)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, screeningCase, nil, screeningCaseLinks(screeningCase))
+}
+
+func screeningCaseLinks(screeningCase *domain.ScreeningCase) types.Links {
+	links := types.Links{
+		"self":  fmt.Sprintf("/admin/screening/cases/%s", screeningCase.PublicID),
+		"cases": "/admin/screening/cases",
+	}
+	if screeningCase.Status == domain.ScreeningCaseOpen {
+		links["resolve"] = fmt.Sprintf("/admin/screening/cases/%s/resolve", screeningCase.PublicID)
+	}
+	return links
+}
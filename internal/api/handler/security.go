@@ -0,0 +1,120 @@
+// internal/api/handler/security.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// SecurityHandler handles HTTP requests for the authentication events of a user.
+type SecurityHandler struct {
+	responder
+	security service.SecurityService
+	logger   *slog.Logger
+}
+
+// NewSecurityHandler creates a new SecurityHandler.
+func NewSecurityHandler(security service.SecurityService, logger *slog.Logger) *SecurityHandler {
+	return &SecurityHandler{
+		responder: responder{logger: logger},
+		security:  security,
+		logger:    logger,
+	}
+}
+
+// AuthEventRequest represents the request body for recording an authentication event. Omitted client
+// attributes are taken from the request itself, for a client reporting its own login.
+type AuthEventRequest struct {
+	Type      domain.AuthEventType `json:"type"`       // LOGIN, LOGIN_FAILED or LOGOUT
+	DeviceID  *string              `json:"device_id"`  // Optional stable device identifier; without it the user agent identifies the device
+	IPAddress *string              `json:"ip_address"` // Defaults to the request's client address
+	UserAgent *string              `json:"user_agent"` // Defaults to the request's User-Agent header
+	GeoHint   *string              `json:"geo_hint"`   // Optional coarse location, e.g. "Berlin, DE"
+}
+
+// RecordAuthEvent handles the record authentication event request.
+// POST /users/{userID}/security/events
+func (h *SecurityHandler) RecordAuthEvent(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	var req AuthEventRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	event := &domain.AuthEvent{
+		UserID:    userID,
+		Type:      req.Type,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+		GeoHint:   req.GeoHint,
+	}
+	if event.IPAddress == nil {
+		event.IPAddress = clientIP(r)
+	}
+	if event.UserAgent == nil && r.UserAgent() != "" {
+		userAgent := r.UserAgent()
+		event.UserAgent = &userAgent
+	}
+	var deviceID string
+	if req.DeviceID != nil {
+		deviceID = *req.DeviceID
+	}
+
+	if err := h.security.RecordEvent(r.Context(), event, deviceID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, event, nil, securityLinks(userID))
+}
+
+// GetSecurityActivity handles the get security activity request, listing the user's most recent
+// authentication events.
+// GET /users/{userID}/security/activity
+func (h *SecurityHandler) GetSecurityActivity(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	events, err := h.security.ListActivity(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, events, nil, securityLinks(userID))
+}
+
+// clientIP returns the host of the request's remote address, as set from X-Real-IP or X-Forwarded-For
+// by the RealIP middleware, or nil if it is not an IP address.
+func clientIP(r *http.Request) *string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if net.ParseIP(host) == nil {
+		return nil
+	}
+	return &host
+}
+
+func securityLinks(userID int64) types.Links {
+	return types.Links{
+		"self": fmt.Sprintf("/users/%d/security/activity", userID),
+		"user": fmt.Sprintf("/users/%d", userID),
+	}
+}
@@ -0,0 +1,197 @@
+// internal/api/handler/suspense.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// SuspenseHandler handles provider webhooks for inbound funds and the admin requests for funds held in suspense.
+type SuspenseHandler struct {
+	responder
+	suspense service.SuspenseService
+	wallets  service.WalletService
+	logger   *slog.Logger
+}
+
+// NewSuspenseHandler creates a new SuspenseHandler.
+func NewSuspenseHandler(suspense service.SuspenseService, wallets service.WalletService, logger *slog.Logger) *SuspenseHandler {
+	return &SuspenseHandler{
+		responder: responder{logger: logger},
+		suspense:  suspense,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// InboundFundsRequest represents the webhook body a provider sends when it has received funds.
+type InboundFundsRequest struct {
+	Reference        string          `json:"reference"`         // The provider's ID of the payment; redeliveries repeat it
+	AccountReference *string         `json:"account_reference"` // What the payer quoted, matched against wallet IDs
+	Amount           decimal.Decimal `json:"amount"`
+	Currency         string          `json:"currency"`
+}
+
+func (req *InboundFundsRequest) currencyFields() []*string { return []*string{&req.Currency} }
+func (req *InboundFundsRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Amount}
+}
+
+// ReassignFundsRequest represents the request body for reassigning suspended funds to a wallet.
+type ReassignFundsRequest struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	Reason   string    `json:"reason"`
+}
+
+// ReceiveInboundFunds handles the inbound funds webhook of a payment provider, identified by its partner
+// signature. New funds yield 201 Created; a redelivery yields 200 OK with the funds as first recorded.
+// POST /webhooks/inbound-funds
+func (h *SuspenseHandler) ReceiveInboundFunds(w http.ResponseWriter, r *http.Request) {
+	var req InboundFundsRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	provider := middleware.PartnerFromContext(r.Context())
+	funds, created, err := h.suspense.ReceiveFunds(r.Context(), provider, req.Reference, req.AccountReference, req.Amount, req.Currency)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	h.respondWithData(w, status, formatInboundFunds(funds), nil, types.Links{"self": r.URL.Path})
+}
+
+// ListSuspendedFunds handles the list suspended funds request, optionally for one currency.
+// GET /admin/suspense/funds
+func (h *SuspenseHandler) ListSuspendedFunds(w http.ResponseWriter, r *http.Request) {
+	currency := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("currency")))
+	if currency != "" && !domain.IsSupportedCurrency(currency) {
+		h.respondWithError(w, r, util.ErrCurrencyUnsupported)
+		return
+	}
+
+	funds, err := h.suspense.ListSuspendedFunds(r.Context(), currency)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	data := make([]inboundFundsResponse, len(funds))
+	for i := range funds {
+		data[i] = formatInboundFunds(&funds[i])
+	}
+	h.respondWithData(w, http.StatusOK, data, nil, types.Links{"self": r.URL.Path})
+}
+
+// GetFunds handles the get inbound funds request.
+// GET /admin/suspense/funds/{fundsID}
+func (h *SuspenseHandler) GetFunds(w http.ResponseWriter, r *http.Request) {
+	fundsID, err := uuid.Parse(chi.URLParam(r, "fundsID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	funds, err := h.suspense.GetFunds(r.Context(), fundsID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatInboundFunds(funds), nil, inboundFundsLinks(funds))
+}
+
+// ReassignFunds handles the reassign suspended funds request: the funds move to the wallet as an ADJUSTMENT
+// transaction, annotated with the reassigning admin and the reason. Funds that are not suspended yield 409 Conflict.
+// POST /admin/suspense/funds/{fundsID}/reassign
+func (h *SuspenseHandler) ReassignFunds(w http.ResponseWriter, r *http.Request) {
+	fundsID, err := uuid.Parse(chi.URLParam(r, "fundsID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	var req ReassignFundsRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.WalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	target, err := h.wallets.GetWalletByPublicID(r.Context(), req.WalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	funds, _, err := h.suspense.Reassign(r.Context(), fundsID, target, middleware.AdminFromContext(r.Context()), strings.TrimSpace(req.Reason))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatInboundFunds(funds), nil, inboundFundsLinks(funds))
+}
+
+func inboundFundsLinks(funds *domain.InboundFunds) types.Links {
+	links := types.Links{
+		"self":        fmt.Sprintf("/admin/suspense/funds/%s", funds.PublicID),
+		"transaction": fmt.Sprintf("/admin/transactions/%s", funds.TransactionID),
+	}
+	if funds.AdjustmentTransactionID != nil {
+		links["adjustment"] = fmt.Sprintf("/admin/transactions/%s", funds.AdjustmentTransactionID)
+		links["assigned_wallet"] = fmt.Sprintf("/wallets/%s", funds.AssignedWalletPublicID)
+	}
+	return links
+}
+
+// inboundFundsResponse is a record of inbound funds as rendered in API responses.
+type inboundFundsResponse struct {
+	ID                      uuid.UUID                 `json:"id"`
+	Provider                string                    `json:"provider"`
+	Reference               string                    `json:"reference"`
+	AccountReference        *string                   `json:"account_reference"`
+	Amount                  money                     `json:"amount"`
+	Status                  domain.InboundFundsStatus `json:"status"`
+	WalletID                uuid.UUID                 `json:"wallet_id"`
+	TransactionID           uuid.UUID                 `json:"transaction_id"`
+	AssignedWalletID        *uuid.UUID                `json:"assigned_wallet_id"`
+	AdjustmentTransactionID *uuid.UUID                `json:"adjustment_transaction_id"`
+	ResolvedBy              *string                   `json:"resolved_by"`
+	ResolutionReason        *string                   `json:"resolution_reason"`
+	ResolvedAt              *time.Time                `json:"resolved_at"`
+	CreatedAt               time.Time                 `json:"created_at"`
+}
+
+func formatInboundFunds(funds *domain.InboundFunds) inboundFundsResponse {
+	return inboundFundsResponse{
+		ID:                      funds.PublicID,
+		Provider:                funds.Provider,
+		Reference:               funds.Reference,
+		AccountReference:        funds.AccountReference,
+		Amount:                  newMoney(funds.Amount, funds.Currency),
+		Status:                  funds.Status,
+		WalletID:                funds.WalletPublicID,
+		TransactionID:           funds.TransactionID,
+		AssignedWalletID:        funds.AssignedWalletPublicID,
+		AdjustmentTransactionID: funds.AdjustmentTransactionID,
+		ResolvedBy:              funds.ResolvedBy,
+		ResolutionReason:        funds.ResolutionReason,
+		ResolvedAt:              funds.ResolvedAt,
+		CreatedAt:               funds.CreatedAt,
+	}
+}
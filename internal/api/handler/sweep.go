@@ -0,0 +1,166 @@
+// internal/api/handler/sweep.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// SweepRuleHandler handles HTTP requests for a user's sweep rules.
+type SweepRuleHandler struct {
+	responder
+	rules   service.SweepRuleService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewSweepRuleHandler creates a new SweepRuleHandler.
+func NewSweepRuleHandler(rules service.SweepRuleService, wallets service.WalletService, logger *slog.Logger) *SweepRuleHandler {
+	return &SweepRuleHandler{
+		responder: responder{logger: logger},
+		rules:     rules,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// SweepRuleRequest represents the request body for creating a sweep rule.
+type SweepRuleRequest struct {
+	Kind           domain.SweepRuleKind `json:"kind"`
+	SourceWalletID uuid.UUID            `json:"source_wallet_id"`
+	TargetWalletID uuid.UUID            `json:"target_wallet_id"`
+	Threshold      decimal.Decimal      `json:"threshold"`
+}
+
+func (req *SweepRuleRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Threshold}
+}
+
+// ListSweepRules handles the list sweep rules request.
+// GET /users/{userID}/sweep-rules
+func (h *SweepRuleHandler) ListSweepRules(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	rules, err := h.rules.ListRules(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	data := make([]sweepRuleResponse, len(rules))
+	for i := range rules {
+		data[i] = formatSweepRule(&rules[i])
+	}
+	h.respondWithData(w, http.StatusOK, data, nil, sweepRuleLinks(userID))
+}
+
+// CreateSweepRule handles the create sweep rule request.
+// POST /users/{userID}/sweep-rules
+func (h *SweepRuleHandler) CreateSweepRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	var req SweepRuleRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	source, err := h.wallets.GetWalletByPublicID(r.Context(), req.SourceWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	target, err := h.wallets.GetWalletByPublicID(r.Context(), req.TargetWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	rule := &domain.SweepRule{
+		UserID:         userID,
+		Kind:           req.Kind,
+		SourceWalletID: source.ID,
+		TargetWalletID: target.ID,
+		Threshold:      req.Threshold,
+	}
+	if err := h.rules.CreateRule(r.Context(), rule); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, formatSweepRule(rule), nil, sweepRuleLinks(userID))
+}
+
+// DeleteSweepRule handles the delete sweep rule request.
+// DELETE /users/{userID}/sweep-rules/{ruleID}
+func (h *SweepRuleHandler) DeleteSweepRule(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	ruleID, err := strconv.ParseInt(chi.URLParam(r, "ruleID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	if err := h.rules.DeleteRule(r.Context(), userID, ruleID); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func sweepRuleLinks(userID int64) types.Links {
+	return types.Links{
+		"self": fmt.Sprintf("/users/%d/sweep-rules", userID),
+		"user": fmt.Sprintf("/users/%d", userID),
+	}
+}
+
+// sweepRuleResponse is a sweep rule as rendered in API responses, with the threshold in the wallets' currency.
+type sweepRuleResponse struct {
+	ID             int64                `json:"id"`
+	UserID         int64                `json:"user_id"`
+	Kind           domain.SweepRuleKind `json:"kind"`
+	SourceWalletID uuid.UUID            `json:"source_wallet_id"`
+	TargetWalletID uuid.UUID            `json:"target_wallet_id"`
+	Currency       string               `json:"currency"`
+	Threshold      money                `json:"threshold"`
+	Enabled        bool                 `json:"enabled"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+func formatSweepRule(rule *domain.SweepRule) sweepRuleResponse {
+	return sweepRuleResponse{
+		ID:             rule.ID,
+		UserID:         rule.UserID,
+		Kind:           rule.Kind,
+		SourceWalletID: rule.SourceWalletPublicID,
+		TargetWalletID: rule.TargetWalletPublicID,
+		Currency:       rule.Currency,
+		Threshold:      newMoney(rule.Threshold, rule.Currency),
+		Enabled:        rule.Enabled,
+		CreatedAt:      rule.CreatedAt,
+		UpdatedAt:      rule.UpdatedAt,
+	}
+}
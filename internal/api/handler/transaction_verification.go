@@ -0,0 +1,54 @@
+// internal/api/handler/transaction_verification.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// TransactionVerificationHandler handles the checks of transactions against the ledger. Its routes require
+// the admin token, as they show the balances of both wallets.
+type TransactionVerificationHandler struct {
+	responder
+	verification service.TransactionVerificationService
+	logger       *slog.Logger
+}
+
+// NewTransactionVerificationHandler creates a new TransactionVerificationHandler.
+func NewTransactionVerificationHandler(verification service.TransactionVerificationService, logger *slog.Logger) *TransactionVerificationHandler {
+	return &TransactionVerificationHandler{
+		responder:    responder{logger: logger},
+		verification: verification,
+		logger:       logger,
+	}
+}
+
+// VerifyTransaction handles the verify transaction request. The verification is returned with 200 whether the
+// transaction is consistent or not; its mismatches say what is wrong.
+// GET /transactions/{transactionID}/verify
+func (h *TransactionVerificationHandler) VerifyTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := uuid.Parse(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	verification, err := h.verification.VerifyTransaction(r.Context(), transactionID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, verification, nil, types.Links{
+		"self":        fmt.Sprintf("/transactions/%s/verify", transactionID),
+		"transaction": fmt.Sprintf("/transactions/%s", transactionID),
+		"full":        fmt.Sprintf("/admin/transactions/%s/full", transactionID),
+	})
+}
@@ -0,0 +1,118 @@
+// internal/api/handler/transfer_quote.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// TransferQuoteHandler handles HTTP requests for transfer quotes.
+type TransferQuoteHandler struct {
+	responder
+	quotes  service.TransferQuoteService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewTransferQuoteHandler creates a new TransferQuoteHandler.
+func NewTransferQuoteHandler(quotes service.TransferQuoteService, wallets service.WalletService, logger *slog.Logger) *TransferQuoteHandler {
+	return &TransferQuoteHandler{
+		responder: responder{logger: logger},
+		quotes:    quotes,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// TransferQuoteRequest represents the request body for quoting a transfer.
+type TransferQuoteRequest struct {
+	FromWalletID uuid.UUID       `json:"from_wallet_id"`
+	ToWalletID   uuid.UUID       `json:"to_wallet_id"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     string          `json:"currency"` // The source wallet's currency
+}
+
+func (req *TransferQuoteRequest) currencyFields() []*string { return []*string{&req.Currency} }
+func (req *TransferQuoteRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Amount}
+}
+
+// QuoteTransfer handles the transfer quote request. No money moves; the returned quote ID can be passed
+// as quote_id to POST /transfers until the quote expires, to execute the transfer at the quoted pricing.
+// POST /transfers/quote
+func (h *TransferQuoteHandler) QuoteTransfer(w http.ResponseWriter, r *http.Request) {
+	var req TransferQuoteRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.FromWalletID == uuid.Nil || req.ToWalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	source, err := h.wallets.GetWalletByPublicID(r.Context(), req.FromWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	destination, err := h.wallets.GetWalletByPublicID(r.Context(), req.ToWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	quote, err := h.quotes.QuoteTransfer(r.Context(), source, destination, req.Amount, req.Currency)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusCreated, formatTransferQuote(quote), map[string]any{
+		"message": "Pass the quote ID as quote_id to POST /transfers before it expires",
+	}, types.Links{"transfer": "/transfers"})
+}
+
+// transferQuoteResponse is a transfer quote as rendered in API responses.
+type transferQuoteResponse struct {
+	ID             uuid.UUID `json:"id"`
+	FromWalletID   uuid.UUID `json:"from_wallet_id"`
+	ToWalletID     uuid.UUID `json:"to_wallet_id"`
+	Amount         money     `json:"amount"`
+	Currency       string    `json:"currency"`
+	Fee            money     `json:"fee"`
+	DebitAmount    money     `json:"debit_amount"`
+	CreditAmount   money     `json:"credit_amount"`
+	CreditCurrency string    `json:"credit_currency"`
+	Rate           *string   `json:"rate"` // Null for a same-currency transfer
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func formatTransferQuote(quote *domain.TransferQuote) transferQuoteResponse {
+	response := transferQuoteResponse{
+		ID:             quote.PublicID,
+		FromWalletID:   quote.FromWalletPublicID,
+		ToWalletID:     quote.ToWalletPublicID,
+		Amount:         newMoney(quote.Amount, quote.Currency),
+		Currency:       quote.Currency,
+		Fee:            newMoney(quote.Fee, quote.Currency),
+		DebitAmount:    newMoney(quote.DebitAmount(), quote.Currency),
+		CreditAmount:   newMoney(quote.CreditAmount, quote.CreditCurrency),
+		CreditCurrency: quote.CreditCurrency,
+		ExpiresAt:      quote.ExpiresAt,
+		CreatedAt:      quote.CreatedAt,
+	}
+	if quote.Rate != nil {
+		rate := quote.Rate.String()
+		response.Rate = &rate
+	}
+	return response
+}
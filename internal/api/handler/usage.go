@@ -0,0 +1,99 @@
+// internal/api/handler/usage.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// UsageHandler handles HTTP requests for the usage and quotas of partner API keys.
+type UsageHandler struct {
+	responder
+	usage  service.UsageService
+	logger *slog.Logger
+}
+
+// NewUsageHandler creates a new UsageHandler.
+func NewUsageHandler(usage service.UsageService, logger *slog.Logger) *UsageHandler {
+	return &UsageHandler{
+		responder: responder{logger: logger},
+		usage:     usage,
+		logger:    logger,
+	}
+}
+
+// GetUsage handles the get API key usage request: the requests and money movement of the current calendar
+// month against the key's quotas. A partner may only read the usage of its own key.
+// GET /api-keys/{keyID}/usage
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	keyID := chi.URLParam(r, "keyID")
+	if keyID != middleware.PartnerFromContext(r.Context()) {
+		h.respondWithError(w, r, fmt.Errorf("%w: usage of another API key", util.ErrForbidden))
+		return
+	}
+
+	usage, quota, err := h.usage.GetUsage(r.Context(), keyID, time.Now())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	currencies := make([]string, 0, len(usage.Volume)+len(quota.Volume))
+	for currency := range usage.Volume {
+		currencies = append(currencies, currency)
+	}
+	for currency := range quota.Volume {
+		if _, ok := usage.Volume[currency]; !ok {
+			currencies = append(currencies, currency)
+		}
+	}
+	sort.Strings(currencies)
+	volume := make([]map[string]any, len(currencies))
+	for i, currency := range currencies {
+		volume[i] = map[string]any{
+			"currency": currency,
+			"used":     newMoney(usage.Volume[currency], currency),
+			"quota":    quotaMoney(quota.Volume[currency], currency),
+		}
+	}
+
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"api_key_id":   keyID,
+		"period_start": usage.Month,
+		"period_end":   usage.Month.AddDate(0, 1, 0),
+		"requests": map[string]any{
+			"used":  usage.Requests,
+			"quota": quotaCount(quota.Requests),
+		},
+		"volume": volume,
+	}, nil, types.Links{
+		"self": fmt.Sprintf("/api-keys/%s/usage", keyID),
+	})
+}
+
+// quotaCount renders a request quota, null when unlimited.
+func quotaCount(limit int64) any {
+	if limit <= 0 {
+		return nil
+	}
+	return limit
+}
+
+// quotaMoney renders a volume quota, null when unlimited.
+func quotaMoney(limit decimal.Decimal, currency string) any {
+	if !limit.IsPositive() {
+		return nil
+	}
+	return newMoney(limit, currency)
+}
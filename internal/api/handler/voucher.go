@@ -0,0 +1,185 @@
+// internal/api/handler/voucher.go
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// VoucherHandler handles HTTP requests for vouchers: issuing and reporting under /admin, and redemption.
+type VoucherHandler struct {
+	responder
+	vouchers service.VoucherService
+	wallets  service.WalletService
+	logger   *slog.Logger
+}
+
+// NewVoucherHandler creates a new VoucherHandler.
+func NewVoucherHandler(vouchers service.VoucherService, wallets service.WalletService, logger *slog.Logger) *VoucherHandler {
+	return &VoucherHandler{
+		responder: responder{logger: logger},
+		vouchers:  vouchers,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// IssueVouchersRequest represents the request body for issuing vouchers.
+type IssueVouchersRequest struct {
+	Amount    decimal.Decimal `json:"amount"`
+	Currency  string          `json:"currency"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Count     int             `json:"count"` // Optional; defaults to 1
+}
+
+func (req *IssueVouchersRequest) currencyFields() []*string { return []*string{&req.Currency} }
+func (req *IssueVouchersRequest) amountFields() []decimal.Decimal {
+	return []decimal.Decimal{req.Amount}
+}
+
+// RedeemVoucherRequest represents the request body for redeeming a voucher.
+type RedeemVoucherRequest struct {
+	Code     string    `json:"code"`
+	WalletID uuid.UUID `json:"wallet_id"`
+}
+
+// IssueVouchers handles the issue vouchers request. The codes are only ever returned in this response.
+// POST /admin/vouchers
+func (h *VoucherHandler) IssueVouchers(w http.ResponseWriter, r *http.Request) {
+	var req IssueVouchersRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Count == 0 {
+		req.Count = 1
+	}
+
+	vouchers, err := h.vouchers.IssueVouchers(r.Context(), req.Amount, req.Currency, req.ExpiresAt, req.Count)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.logger.Warn("Vouchers issued by operator", "count", len(vouchers), "amount", req.Amount.String(), "currency", req.Currency)
+	data := make([]voucherResponse, len(vouchers))
+	for i := range vouchers {
+		data[i] = formatVoucher(&vouchers[i])
+	}
+	h.respondWithData(w, http.StatusCreated, data, map[string]any{
+		"message": "Store the codes now; they cannot be retrieved again",
+	}, types.Links{"liability": "/admin/vouchers/liability"})
+}
+
+// GetVoucherLiability handles the voucher liability report request.
+// GET /admin/vouchers/liability
+func (h *VoucherHandler) GetVoucherLiability(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	liabilities, err := h.vouchers.GetLiability(r.Context(), now)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	data := make([]voucherLiabilityResponse, len(liabilities))
+	for i := range liabilities {
+		data[i] = formatVoucherLiability(&liabilities[i])
+	}
+	h.respondWithData(w, http.StatusOK, data, map[string]any{"as_of": now}, types.Links{"self": r.URL.Path})
+}
+
+// RedeemVoucher handles the redeem voucher request, crediting the voucher's value to the wallet.
+// POST /vouchers/redeem
+func (h *VoucherHandler) RedeemVoucher(w http.ResponseWriter, r *http.Request) {
+	var req RedeemVoucherRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Code == "" || req.WalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	target, err := h.wallets.GetWalletByPublicID(r.Context(), req.WalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	voucher, wallet, transaction, err := h.vouchers.RedeemVoucher(r.Context(), req.Code, target.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", wallet.ETag())
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"voucher_id":     voucher.PublicID,
+		"wallet_id":      wallet.PublicID,
+		"amount":         newMoney(voucher.Amount, voucher.Currency),
+		"currency":       voucher.Currency,
+		"new_balance":    newMoney(wallet.Balance, wallet.Currency),
+		"transaction_id": transaction.PublicID,
+	}, map[string]any{"message": "Voucher redeemed"}, transactionLinks(transaction.PublicID, wallet.PublicID))
+}
+
+// voucherResponse is a voucher as rendered in API responses. The code is only present when issued.
+type voucherResponse struct {
+	ID               uuid.UUID            `json:"id"`
+	Code             string               `json:"code,omitempty"`
+	CodeHint         string               `json:"code_hint"`
+	Amount           money                `json:"amount"`
+	Currency         string               `json:"currency"`
+	Status           domain.VoucherStatus `json:"status"`
+	ExpiresAt        time.Time            `json:"expires_at"`
+	RedeemedWalletID *uuid.UUID           `json:"redeemed_wallet_id"`
+	TransactionID    *uuid.UUID           `json:"transaction_id"`
+	RedeemedAt       *time.Time           `json:"redeemed_at"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
+func formatVoucher(voucher *domain.Voucher) voucherResponse {
+	return voucherResponse{
+		ID:               voucher.PublicID,
+		Code:             voucher.Code,
+		CodeHint:         voucher.CodeHint,
+		Amount:           newMoney(voucher.Amount, voucher.Currency),
+		Currency:         voucher.Currency,
+		Status:           voucher.Status,
+		ExpiresAt:        voucher.ExpiresAt,
+		RedeemedWalletID: voucher.RedeemedWalletPublicID,
+		TransactionID:    voucher.TransactionID,
+		RedeemedAt:       voucher.RedeemedAt,
+		CreatedAt:        voucher.CreatedAt,
+		UpdatedAt:        voucher.UpdatedAt,
+	}
+}
+
+// voucherLiabilityResponse is the voucher liability of one currency as rendered in API responses.
+type voucherLiabilityResponse struct {
+	Currency          string `json:"currency"`
+	OutstandingCount  int    `json:"outstanding_count"`
+	OutstandingAmount money  `json:"outstanding_amount"`
+	ExpiredCount      int    `json:"expired_count"`
+	ExpiredAmount     money  `json:"expired_amount"`
+	RedeemedCount     int    `json:"redeemed_count"`
+	RedeemedAmount    money  `json:"redeemed_amount"`
+}
+
+func formatVoucherLiability(liability *domain.VoucherLiability) voucherLiabilityResponse {
+	return voucherLiabilityResponse{
+		Currency:          liability.Currency,
+		OutstandingCount:  liability.OutstandingCount,
+		OutstandingAmount: newMoney(liability.OutstandingAmount, liability.Currency),
+		ExpiredCount:      liability.ExpiredCount,
+		ExpiredAmount:     newMoney(liability.ExpiredAmount, liability.Currency),
+		RedeemedCount:     liability.RedeemedCount,
+		RedeemedAmount:    newMoney(liability.RedeemedAmount, liability.Currency),
+	}
+}
@@ -2,293 +2,896 @@
 package handler
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
 	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
 	"finflow-wallet/internal/service"
 	"finflow-wallet/internal/util" // For custom errors
 )
 
+// DefaultTimeout bounds the handling of a request; X-Request-Timeout can shorten but not extend it.
 const DefaultTimeout = 5 * time.Second
 
+// Bounds of the wait of GET /transactions/{transactionID}/wait.
+const (
+	defaultTransactionWait = 30 * time.Second
+	maxTransactionWait     = 60 * time.Second
+)
+
 // WalletHandler handles HTTP requests related to wallet operations.
 type WalletHandler struct {
-	service service.WalletService
-	logger  *slog.Logger
+	responder
+	service      service.WalletService
+	approvals    service.JointWalletService
+	descriptions service.DescriptionRenderer
+	pins         service.PINService // Optional; confirms withdrawals and transfers with the owner's PIN
+	logger       *slog.Logger
 }
 
 // NewWalletHandler creates a new WalletHandler.
-func NewWalletHandler(svc service.WalletService, logger *slog.Logger) *WalletHandler {
+func NewWalletHandler(svc service.WalletService, approvals service.JointWalletService, descriptions service.DescriptionRenderer, pins service.PINService, logger *slog.Logger) *WalletHandler {
 	return &WalletHandler{
-		service: svc,
-		logger:  logger,
-	}
-}
-
-// Helper function to send JSON responses.
-func (h *WalletHandler) respondWithJSON(w http.ResponseWriter, code int, payload any) {
-	response, err := json.Marshal(payload)
-	if err != nil {
-		h.logger.Error("Failed to marshal JSON response", "error", err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		responder:    responder{logger: logger},
+		service:      svc,
+		approvals:    approvals,
+		descriptions: descriptions,
+		pins:         pins,
+		logger:       logger,
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	_, _ = w.Write(response)
 }
 
-// Helper function to send error responses.
-func (h *WalletHandler) respondWithError(w http.ResponseWriter, err error) {
-	statusCode := http.StatusInternalServerError
-	message := "Internal server error"
+// transactionPINHeader carries the transaction PIN confirming a withdrawal or transfer.
+const transactionPINHeader = "X-Transaction-PIN"
 
-	switch {
-	case util.IsError(err, util.ErrInvalidInput):
-		statusCode = http.StatusBadRequest
-		message = err.Error() // Use the error message directly for invalid input
-	case util.IsError(err, util.ErrNotFound), util.IsError(err, util.ErrWalletNotFound), util.IsError(err, util.ErrUserNotFound):
-		statusCode = http.StatusNotFound
-		message = "Resource not found"
-	case util.IsError(err, util.ErrInsufficientFunds):
-		statusCode = http.StatusPaymentRequired // 402 Payment Required
-		message = "Insufficient funds"
-	case util.IsError(err, util.ErrSameWalletTransfer):
-		statusCode = http.StatusBadRequest
-		message = "Cannot transfer to the same wallet"
-	case util.IsError(err, util.ErrCurrencyMismatch):
-		statusCode = http.StatusBadRequest
-		message = "wallet currency mismatch"
-	// Add more specific error mappings as needed
-	default:
-		h.logger.Error("Unhandled service error", "error", err)
+// confirmPIN confirms a withdrawal or transfer out of the user's wallet with the PIN in transactionPINHeader,
+// unless the request is made in an impersonation session. On failure it writes the error response and
+// reports false.
+func (h *WalletHandler) confirmPIN(w http.ResponseWriter, r *http.Request, userID int64) bool {
+	if h.pins == nil || service.ImpersonationFromContext(r.Context()) != nil {
+		return true
 	}
-
-	h.respondWithJSON(w, statusCode, map[string]string{"error": message})
+	if err := h.pins.Confirm(r.Context(), userID, r.Header.Get(transactionPINHeader)); err != nil {
+		h.respondWithError(w, r, err)
+		return false
+	}
+	return true
 }
 
 // DepositRequest represents the request body for deposit.
 type DepositRequest struct {
 	Amount   decimal.Decimal `json:"amount"`
 	Currency string          `json:"currency"`
+	Category string          `json:"category"` // Optional spending category
+	DryRun   bool            `json:"dry_run"`  // Validate and answer the would-be result without moving money
 }
 
+func (req *DepositRequest) currencyFields() []*string       { return []*string{&req.Currency} }
+func (req *DepositRequest) amountFields() []decimal.Decimal { return []decimal.Decimal{req.Amount} }
+
 // Deposit handles the deposit money request.
 // POST /wallets/{walletID}/deposit
 func (h *WalletHandler) Deposit(w http.ResponseWriter, r *http.Request) {
-	walletIDStr := chi.URLParam(r, "walletID")
-	walletID, err := strconv.ParseInt(walletIDStr, 10, 64)
-	if err != nil {
-		h.respondWithError(w, util.ErrInvalidInput)
+	target, ok := h.walletFromPath(w, r)
+	if !ok {
 		return
 	}
 
 	var req DepositRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, util.ErrInvalidInput)
+	if !h.decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Basic validation
 	if req.Amount.IsNegative() || req.Amount.IsZero() {
-		h.respondWithError(w, util.ErrInvalidInput)
-		return
-	}
-	if req.Currency == "" {
-		h.respondWithError(w, util.ErrInvalidInput)
+		h.respondWithError(w, r, util.ErrInvalidInput)
 		return
 	}
 
-	wallet, transaction, err := h.service.Deposit(r.Context(), walletID, req.Amount, req.Currency)
+	ctx := service.WithIfMatch(r.Context(), parseETagList(r.Header.Get("If-Match")))
+	ctx = service.WithTransactionCategory(ctx, req.Category)
+	if req.DryRun {
+		ctx = service.WithDryRun(ctx)
+	}
+	wallet, transaction, err := h.service.Deposit(ctx, target.ID, req.Amount, req.Currency)
 	if err != nil {
-		h.respondWithError(w, err)
+		h.respondWithError(w, r, err)
+		return
+	}
+	if req.DryRun {
+		h.respondWithDryRun(w, wallet, transaction)
 		return
 	}
 
-	h.respondWithJSON(w, http.StatusOK, map[string]any{
-		"message":        "Deposit successful",
-		"wallet_id":      wallet.ID,
-		"new_balance":    wallet.Balance.StringFixed(2),
-		"transaction_id": transaction.ID,
-	})
+	w.Header().Set("ETag", wallet.ETag())
+
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"wallet_id":      wallet.PublicID,
+		"new_balance":    newMoney(wallet.Balance, wallet.Currency),
+		"transaction_id": transaction.PublicID,
+	}, map[string]any{"message": "Deposit successful"}, transactionLinks(transaction.PublicID, wallet.PublicID))
 }
 
 // WithdrawRequest represents the request body for withdraw.
 type WithdrawRequest struct {
-	Amount   decimal.Decimal `json:"amount"`
-	Currency string          `json:"currency"`
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	Category       string          `json:"category"`        // Optional spending category
+	OverrideBudget bool            `json:"override_budget"` // Proceed even if a SOFT_BLOCK budget would be exceeded
+	DryRun         bool            `json:"dry_run"`         // Validate and answer the would-be result without moving money
 }
 
+func (req *WithdrawRequest) currencyFields() []*string       { return []*string{&req.Currency} }
+func (req *WithdrawRequest) amountFields() []decimal.Decimal { return []decimal.Decimal{req.Amount} }
+
 // Withdraw handles the withdraw money request.
 // POST /wallets/{walletID}/withdraw
 func (h *WalletHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
-	walletIDStr := chi.URLParam(r, "walletID")
-	walletID, err := strconv.ParseInt(walletIDStr, 10, 64)
-	if err != nil {
-		h.respondWithError(w, util.ErrInvalidInput)
+	target, ok := h.walletFromPath(w, r)
+	if !ok {
 		return
 	}
 
 	var req WithdrawRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, util.ErrInvalidInput)
+	if !h.decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Basic validation
 	if req.Amount.IsNegative() || req.Amount.IsZero() {
-		h.respondWithError(w, util.ErrInvalidInput)
+		h.respondWithError(w, r, util.ErrInvalidInput)
 		return
 	}
-	if req.Currency == "" {
-		h.respondWithError(w, util.ErrInvalidInput)
+
+	ctx := service.WithIfMatch(r.Context(), parseETagList(r.Header.Get("If-Match")))
+	ctx = withSpendingOptions(ctx, req.Category, req.OverrideBudget)
+	if req.DryRun {
+		ctx = service.WithDryRun(ctx)
+	} else if !h.confirmPIN(w, r, target.UserID) {
 		return
 	}
-
-	wallet, transaction, err := h.service.Withdraw(r.Context(), walletID, req.Amount, req.Currency)
+	wallet, transaction, err := h.service.Withdraw(ctx, target.ID, req.Amount, req.Currency)
 	if err != nil {
-		h.respondWithError(w, err)
+		h.respondWithError(w, r, err)
+		return
+	}
+	if req.DryRun {
+		h.respondWithDryRun(w, wallet, transaction)
 		return
 	}
 
-	h.respondWithJSON(w, http.StatusOK, map[string]any{
-		"message":        "Withdrawal successful",
-		"wallet_id":      wallet.ID,
-		"new_balance":    wallet.Balance.StringFixed(2),
-		"transaction_id": transaction.ID,
-	})
+	w.Header().Set("ETag", wallet.ETag())
+
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"wallet_id":      wallet.PublicID,
+		"new_balance":    newMoney(wallet.Balance, wallet.Currency),
+		"transaction_id": transaction.PublicID,
+	}, map[string]any{"message": "Withdrawal successful"}, transactionLinks(transaction.PublicID, wallet.PublicID))
 }
 
 // TransferRequest represents the request body for transfer.
 type TransferRequest struct {
-	FromWalletID int64           `json:"from_wallet_id"`
-	ToWalletID   int64           `json:"to_wallet_id"`
-	Amount       decimal.Decimal `json:"amount"`
-	Currency     string          `json:"currency"`
+	FromWalletID   uuid.UUID       `json:"from_wallet_id"`
+	ToWalletID     uuid.UUID       `json:"to_wallet_id"`
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	RequestedBy    *int64          `json:"requested_by"`    // Optional; the member initiating a transfer from a joint wallet
+	Category       string          `json:"category"`        // Optional spending category
+	OverrideBudget bool            `json:"override_budget"` // Proceed even if a SOFT_BLOCK budget would be exceeded
+	QuoteID        uuid.UUID       `json:"quote_id"`        // Optional; executes the transfer at the pricing of a POST /transfers/quote
+	Force          bool            `json:"force"`           // Send even if it repeats a recent transfer
+	Async          bool            `json:"async"`           // Accept the transfer now and make it in the background
+	DryRun         bool            `json:"dry_run"`         // Validate and answer the would-be result without moving money
 }
 
-// Transfer handles the transfer money request.
+func (req *TransferRequest) currencyFields() []*string       { return []*string{&req.Currency} }
+func (req *TransferRequest) amountFields() []decimal.Decimal { return []decimal.Decimal{req.Amount} }
+
+// Transfer handles the transfer money request. Transfers above the source wallet's approval threshold
+// are not executed; they yield 202 Accepted with the approval request to be approved by the owners.
+// A quoted transfer cannot wait for approval, as its quote would expire, and fails instead.
+// A transfer repeating a recent one yields 409 Conflict with the earlier transaction unless force is set.
+// An executed transfer is answered in protobuf when the request prefers it.
+// With async set, the transfer is checked and accepted as a PENDING transaction, answered with 202 Accepted,
+// and made by a background worker; clients wait for it on /transactions/{id}/wait. A synchronous transfer the
+// service accepts as PENDING, as sandbox mode does for its magic values, is answered the same way.
 // POST /transfers
 func (h *WalletHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 	var req TransferRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, util.ErrInvalidInput)
+	if !h.decodeRequest(w, r, &req) {
 		return
 	}
 
 	// Basic validation
-	if req.FromWalletID == 0 || req.ToWalletID == 0 {
-		h.respondWithError(w, util.ErrInvalidInput)
+	if req.FromWalletID == uuid.Nil || req.ToWalletID == uuid.Nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
 		return
 	}
 	if req.Amount.IsNegative() || req.Amount.IsZero() {
-		h.respondWithError(w, util.ErrInvalidInput)
+		h.respondWithError(w, r, util.ErrInvalidInput)
 		return
 	}
-	if req.Currency == "" {
-		h.respondWithError(w, util.ErrInvalidInput)
+
+	// If-Match applies to the source wallet, the one whose balance the caller can see
+	ctx := service.WithIfMatch(r.Context(), parseETagList(r.Header.Get("If-Match")))
+	ctx = withSpendingOptions(ctx, req.Category, req.OverrideBudget)
+	ctx = service.WithTransferQuote(ctx, req.QuoteID)
+	if req.Force {
+		ctx = service.WithForcedTransfer(ctx)
+	}
+	source, err := h.service.GetWalletByPublicID(ctx, req.FromWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	destination, err := h.service.GetWalletByPublicID(ctx, req.ToWalletID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	if req.DryRun {
+		h.dryRunTransfer(service.WithDryRun(ctx), w, r, &req, source, destination)
+		return
+	}
+	if !h.confirmPIN(w, r, source.UserID) {
 		return
 	}
 
-	fromWallet, _, transaction, err := h.service.Transfer(r.Context(), req.FromWalletID, req.ToWalletID, req.Amount, req.Currency)
+	if req.Async {
+		h.submitTransfer(ctx, w, r, &req, source, destination)
+		return
+	}
+	fromWallet, _, transaction, err := h.service.Transfer(ctx, source.ID, destination.ID, req.Amount, req.Currency)
+	if errors.Is(err, util.ErrApprovalRequired) && h.approvals != nil && req.QuoteID == uuid.Nil {
+		h.requestTransferApproval(ctx, w, r, &req, source, destination)
+		return
+	}
 	if err != nil {
-		h.respondWithError(w, err)
+		h.respondWithError(w, r, err)
+		return
+	}
+	if transaction.Status == domain.TransactionStatusPending {
+		h.respondTransferAccepted(w, transaction, source)
 		return
 	}
 
-	h.respondWithJSON(w, http.StatusOK, map[string]any{
-		"message":                 "Transfer successful",
-		"transaction_id":          transaction.ID,
-		"from_wallet_new_balance": fromWallet.Balance.StringFixed(2),
+	w.Header().Set("ETag", fromWallet.ETag())
+
+	h.respondWithDataOrProto(w, r, http.StatusOK, map[string]any{
+		"transaction_id":          transaction.PublicID,
+		"from_wallet_new_balance": newMoney(fromWallet.Balance, fromWallet.Currency),
 		//ignore to_wallet_new_balance for security reasons, you don't want to expose the balance passively
-		//"to_wallet_new_balance":   toWallet.Balance.StringFixed(2),
+		//"to_wallet_new_balance":   newMoney(toWallet.Balance, toWallet.Currency),
+	}, map[string]any{"message": "Transfer successful"}, transactionLinks(transaction.PublicID, fromWallet.PublicID), transferResultProto{
+		transactionID:        transaction.PublicID.String(),
+		fromWalletNewBalance: newMoney(fromWallet.Balance, fromWallet.Currency),
 	})
 }
 
-// GetWalletBalance handles the get wallet balance request.
-// GET /wallets/{walletID}/balance
-func (h *WalletHandler) GetWalletBalance(w http.ResponseWriter, r *http.Request) {
-	walletIDStr := chi.URLParam(r, "walletID")
-	walletID, err := strconv.ParseInt(walletIDStr, 10, 64)
+// dryRunTransfer answers what the transfer would do, asynchronous or not, without making it. A transfer that
+// would await approval fails with the approval error rather than requesting it.
+func (h *WalletHandler) dryRunTransfer(ctx context.Context, w http.ResponseWriter, r *http.Request, req *TransferRequest, source, destination *domain.Wallet) {
+	fromWallet, _, transaction, err := h.service.Transfer(ctx, source.ID, destination.ID, req.Amount, req.Currency)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithDryRun(w, fromWallet, transaction)
+}
+
+// respondWithDryRun answers a dry run with the balance the wallet would have and the transaction that would be
+// recorded, which has no ID as it is not stored.
+func (h *WalletHandler) respondWithDryRun(w http.ResponseWriter, wallet *domain.Wallet, transaction *domain.Transaction) {
+	formatted := formatTransaction(transaction)
+	delete(formatted, "id")
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"dry_run":     true,
+		"wallet_id":   wallet.PublicID,
+		"new_balance": newMoney(wallet.Balance, wallet.Currency),
+		"transaction": formatted,
+	}, map[string]any{"message": "Dry run successful; no money was moved"}, types.Links{
+		"wallet":       fmt.Sprintf("/wallets/%s/balance", wallet.PublicID),
+		"transactions": fmt.Sprintf("/wallets/%s/transactions", wallet.PublicID),
+	})
+}
+
+// submitTransfer accepts an async transfer. One needing approval awaits it instead, like a synchronous one.
+func (h *WalletHandler) submitTransfer(ctx context.Context, w http.ResponseWriter, r *http.Request, req *TransferRequest, source, destination *domain.Wallet) {
+	transaction, err := h.service.SubmitTransfer(ctx, source.ID, destination.ID, req.Amount, req.Currency)
+	if errors.Is(err, util.ErrApprovalRequired) && h.approvals != nil {
+		h.requestTransferApproval(ctx, w, r, req, source, destination)
+		return
+	}
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	h.respondTransferAccepted(w, transaction, source)
+}
+
+// respondTransferAccepted answers a transfer accepted as PENDING, to be made in the background.
+func (h *WalletHandler) respondTransferAccepted(w http.ResponseWriter, transaction *domain.Transaction, source *domain.Wallet) {
+	links := transactionLinks(transaction.PublicID, source.PublicID)
+	links["wait"] = fmt.Sprintf("/transactions/%s/wait", transaction.PublicID)
+	w.Header().Set("Location", links["self"])
+	h.respondWithData(w, http.StatusAccepted, map[string]any{
+		"transaction_id": transaction.PublicID,
+		"status":         transaction.Status,
+	}, map[string]any{"message": "Transfer accepted"}, links)
+}
+
+// requestTransferApproval holds a transfer above the source wallet's approval threshold back for the owners.
+func (h *WalletHandler) requestTransferApproval(ctx context.Context, w http.ResponseWriter, r *http.Request, req *TransferRequest, source, destination *domain.Wallet) {
+	approval, err := h.approvals.RequestTransferApproval(ctx, source, destination, req.Amount, req.Currency, req.RequestedBy)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	links := transferApprovalLinks(approval)
+	w.Header().Set("Location", links["self"])
+	h.respondWithData(w, http.StatusAccepted, formatTransferApproval(approval), map[string]any{"message": "Transfer awaiting approval"}, links)
+}
+
+// SplitDestination is one destination of a split transfer: a fixed amount or a percentage share.
+type SplitDestination struct {
+	WalletID uuid.UUID       `json:"wallet_id"`
+	Amount   decimal.Decimal `json:"amount"`
+	Share    decimal.Decimal `json:"share"`
+}
+
+// SplitTransferRequest represents the request body for a split transfer.
+type SplitTransferRequest struct {
+	FromWalletID   uuid.UUID          `json:"from_wallet_id"`
+	Currency       string             `json:"currency"`
+	Amount         decimal.Decimal    `json:"amount"` // Total; required when splitting by share
+	Destinations   []SplitDestination `json:"destinations"`
+	Category       string             `json:"category"`        // Optional spending category
+	OverrideBudget bool               `json:"override_budget"` // Proceed even if a SOFT_BLOCK budget would be exceeded
+}
+
+func (req *SplitTransferRequest) currencyFields() []*string { return []*string{&req.Currency} }
+func (req *SplitTransferRequest) amountFields() []decimal.Decimal {
+	amounts := []decimal.Decimal{req.Amount}
+	for _, destination := range req.Destinations {
+		amounts = append(amounts, destination.Amount)
+	}
+	return amounts
+}
+
+// SplitTransfer handles the split transfer request: one source paying several destinations atomically.
+// POST /transfers/split
+func (h *WalletHandler) SplitTransfer(w http.ResponseWriter, r *http.Request) {
+	var req SplitTransferRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.FromWalletID == uuid.Nil || len(req.Destinations) > domain.MaxSplitLegs {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	// If-Match applies to the source wallet, as for a single transfer
+	ctx := service.WithIfMatch(r.Context(), parseETagList(r.Header.Get("If-Match")))
+	ctx = withSpendingOptions(ctx, req.Category, req.OverrideBudget)
+	source, err := h.service.GetWalletByPublicID(ctx, req.FromWalletID)
 	if err != nil {
-		h.respondWithError(w, util.ErrInvalidInput)
+		h.respondWithError(w, r, err)
 		return
 	}
+	split := domain.Split{Currency: req.Currency, Total: req.Amount, Legs: make([]domain.SplitLeg, 0, len(req.Destinations))}
+	for _, destination := range req.Destinations {
+		wallet, err := h.service.GetWalletByPublicID(ctx, destination.WalletID)
+		if err != nil {
+			h.respondWithError(w, r, err)
+			return
+		}
+		split.Legs = append(split.Legs, domain.SplitLeg{ToWalletID: wallet.ID, Amount: destination.Amount, Share: destination.Share})
+	}
 
-	wallet, err := h.service.GetBalance(r.Context(), walletID)
+	if !h.confirmPIN(w, r, source.UserID) {
+		return
+	}
+	fromWallet, parent, legs, err := h.service.SplitTransfer(ctx, source.ID, split)
 	if err != nil {
-		h.respondWithError(w, err)
+		h.respondWithError(w, r, err)
 		return
 	}
 
-	h.respondWithJSON(w, http.StatusOK, map[string]any{
-		"wallet_id": wallet.ID,
-		"balance":   wallet.Balance.StringFixed(2),
+	w.Header().Set("ETag", fromWallet.ETag())
+
+	legData := make([]map[string]any, 0, len(legs))
+	for _, leg := range legs {
+		legData = append(legData, map[string]any{
+			"transaction_id": leg.PublicID,
+			"to_wallet_id":   leg.ToWalletPublicID,
+			"amount":         newMoney(leg.Amount, leg.Currency),
+		})
+	}
+	h.respondWithData(w, http.StatusOK, map[string]any{
+		"transaction_id":          parent.PublicID,
+		"amount":                  newMoney(parent.Amount, parent.Currency),
+		"from_wallet_new_balance": newMoney(fromWallet.Balance, fromWallet.Currency),
+		"legs":                    legData,
+	}, map[string]any{"message": "Split transfer successful"}, transactionLinks(parent.PublicID, fromWallet.PublicID))
+}
+
+// GetWalletBalance handles the get wallet balance request, in JSON or, on request, protobuf.
+// GET /wallets/{walletID}/balance
+func (h *WalletHandler) GetWalletBalance(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := h.readableWalletFromPath(w, r)
+	if !ok {
+		return
+	}
+	if writeWalletETag(w, r, wallet) {
+		return
+	}
+
+	h.respondWithDataOrProto(w, r, http.StatusOK, map[string]any{
+		"wallet_id": wallet.PublicID,
+		"balance":   newMoney(wallet.Balance, wallet.Currency),
 		"currency":  wallet.Currency,
+	}, nil, types.Links{"self": r.URL.Path}, walletBalanceProto{
+		walletID: wallet.PublicID.String(),
+		balance:  newMoney(wallet.Balance, wallet.Currency),
+		currency: wallet.Currency,
+	})
+}
+
+// GetWallet handles the get wallet request.
+// GET /wallets/{walletID}
+func (h *WalletHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := h.readableWalletFromPath(w, r)
+	if !ok {
+		return
+	}
+	if writeWalletETag(w, r, wallet) {
+		return
+	}
+
+	h.respondWithData(w, http.StatusOK, formatWallet(wallet), nil, types.Links{
+		"self":         r.URL.Path,
+		"balance":      fmt.Sprintf("/wallets/%s/balance", wallet.PublicID),
+		"transactions": fmt.Sprintf("/wallets/%s/transactions", wallet.PublicID),
 	})
 }
 
 // GetTransactionHistory handles the get transaction history request.
 // GET /wallets/{walletID}/transactions
 func (h *WalletHandler) GetTransactionHistory(w http.ResponseWriter, r *http.Request) {
-	walletIDStr := chi.URLParam(r, "walletID")
-	walletID, err := strconv.ParseInt(walletIDStr, 10, 64)
-	if err != nil {
-		h.respondWithError(w, util.ErrInvalidInput)
+	h.listTransactionHistory(w, r, repository.TransactionFilter{})
+}
+
+// SearchTransactionHistory handles the transaction search request: q is matched case-insensitively
+// against descriptions and categories, or, when it is a transaction ID, finds that transaction and its legs.
+// The type and category filters and the date range, sorting and paging of the history apply as well.
+// Without a date range the archive is searched too, so old payments can be found.
+// GET /wallets/{walletID}/transactions/search
+func (h *WalletHandler) SearchTransactionHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repository.TransactionFilter{Query: strings.TrimSpace(query.Get("q"))}
+	if filter.Query == "" {
+		h.respondWithError(w, r, fmt.Errorf("%w: q is required", util.ErrInvalidInput))
 		return
 	}
+	if raw := query.Get("type"); raw != "" {
+		txType := domain.TransactionType(strings.ToUpper(raw))
+		filter.Type = &txType
+	}
+	if category := query.Get("category"); category != "" {
+		filter.Category = &category
+	}
+	h.listTransactionHistory(w, r, filter)
+}
 
-	// Parse query parameters for pagination
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+// listTransactionHistory writes a page of the path wallet's transactions matching the filter, narrowed
+// further by the date range parameters.
+func (h *WalletHandler) listTransactionHistory(w http.ResponseWriter, r *http.Request, filter repository.TransactionFilter) {
+	target, ok := h.walletFromPath(w, r)
+	if !ok {
+		return
+	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 10 // Default limit
+	opts, err := parseListOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
 	}
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0 // Default offset
+
+	// Optional created_at range (RFC 3339); ranges older than the retention horizon also search the archive
+	if filter.From, err = parseTimeParam(r, "from"); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	if filter.To, err = parseTimeParam(r, "to"); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	// display_description is rendered rather than stored, so the query selects the columns it is rendered from
+	fields := opts.Fields
+	if slices.Contains(fields, "display_description") {
+		opts.Fields = slices.DeleteFunc(slices.Clone(fields), func(field string) bool { return field == "display_description" })
+		opts.Fields = append(opts.Fields, descriptionColumns...)
+		slices.Sort(opts.Fields)
+		opts.Fields = slices.Compact(opts.Fields)
 	}
 
-	// Modified: GetTransactionHistory now returns total count
-	transactions, totalCount, err := h.service.GetTransactionHistory(r.Context(), walletID, limit, offset)
+	// Every transaction changes the wallet's balance, so the history is as new as the wallet
+	if writeCacheHeaders(w, r, currentCachePolicy().Transactions, target.UpdatedAt) {
+		return
+	}
+	transactions, totalCount, err := h.service.ListTransactionHistory(r.Context(), target.ID, filter, opts)
 	if err != nil {
-		h.respondWithError(w, err)
+		h.respondWithError(w, r, err)
 		return
 	}
 
 	// Prepare the data for the generic PaginatedResponse
-	formattedTransactions := make([]map[string]interface{}, len(transactions))
+	formattedTransactions := make([]map[string]any, len(transactions))
 	for i, tx := range transactions {
-		formattedTransactions[i] = map[string]interface{}{
-			"id":               tx.ID,
-			"from_wallet_id":   tx.FromWalletID,
-			"to_wallet_id":     tx.ToWalletID,
-			"amount":           tx.Amount.StringFixed(2),
-			"currency":         tx.Currency,
-			"type":             tx.Type,
-			"status":           tx.Status,
-			"transaction_time": tx.TransactionTime,
-			"description":      tx.Description,
-			"created_at":       tx.CreatedAt,
+		formatted := formatTransaction(&tx)
+		formatted["display_description"] = h.descriptions.Describe(r.Context(), &tx, target.ID)
+		formattedTransactions[i] = project(formatted, fields)
+	}
+
+	// Use the generic PaginatedResponse envelope, which also carries next/prev links
+	h.respondWithJSON(w, http.StatusOK, types.NewPaginatedResponse(formattedTransactions, r.URL, opts.Limit, opts.Offset, totalCount))
+}
+
+// CreateUserRequest represents the request body for creating a user with their first wallet.
+type CreateUserRequest struct {
+	Username  string  `json:"username"`
+	Currency  string  `json:"currency"`
+	Residency *string `json:"residency"` // Optional ISO 3166-1 alpha-2 country, e.g. "DE"
+}
+
+func (req *CreateUserRequest) currencyFields() []*string { return []*string{&req.Currency} }
+
+// CreateUser handles the create user request. The user and their wallet are created atomically;
+// an existing username yields 409 Conflict with a Location header pointing at that user.
+// POST /users
+func (h *WalletHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req CreateUserRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Username == "" {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	user, wallet, err := h.service.CreateUserAndWallet(r.Context(), req.Username, req.Currency, req.Residency)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	location := fmt.Sprintf("/users/%d", user.ID)
+	w.Header().Set("Location", location)
+	h.respondWithData(w, http.StatusCreated, map[string]any{
+		"user":   formatUser(user),
+		"wallet": formatWallet(wallet),
+	}, nil, types.Links{
+		"self":   location,
+		"wallet": fmt.Sprintf("/wallets/%s", wallet.PublicID),
+	})
+}
+
+// GetUser handles the get user request.
+// GET /users/{userID}
+func (h *WalletHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	user, err := h.service.GetUser(r.Context(), userID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	h.respondWithData(w, http.StatusOK, formatUser(user), nil, types.Links{
+		"self":    r.URL.Path,
+		"wallets": fmt.Sprintf("/wallets?user_id=%d", user.ID),
+	})
+}
+
+// UpdateUserRequest represents the request body for updating a user's preferences. Omitted fields are left unchanged.
+type UpdateUserRequest struct {
+	Timezone  *string `json:"timezone"`  // IANA name, e.g. "Europe/Berlin"
+	Residency *string `json:"residency"` // ISO 3166-1 alpha-2 country, e.g. "DE"; an empty string clears it
+	Email     *string `json:"email"`     // An empty string clears it
+	Phone     *string `json:"phone"`     // E.164, e.g. "+4915112345678"; an empty string clears it
+}
+
+// UpdateUser handles the update user request.
+// PATCH /users/{userID}
+func (h *WalletHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	var req UpdateUserRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if req.Timezone == nil && req.Residency == nil && req.Email == nil && req.Phone == nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	var user *domain.User
+	if req.Timezone != nil {
+		if user, err = h.service.SetUserTimezone(r.Context(), userID, *req.Timezone); err != nil {
+			h.respondWithError(w, r, err)
+			return
+		}
+	}
+	if req.Residency != nil {
+		residency := req.Residency
+		if *residency == "" {
+			residency = nil
+		}
+		if user, err = h.service.SetUserResidency(r.Context(), userID, residency); err != nil {
+			h.respondWithError(w, r, err)
+			return
+		}
+	}
+	if req.Email != nil || req.Phone != nil {
+		if user, err = h.service.SetUserContact(r.Context(), userID, req.Email, req.Phone); err != nil {
+			h.respondWithError(w, r, err)
+			return
 		}
 	}
 
-	// Use the generic PaginatedResponse struct and include totalCount
-	responsePayload := types.PaginatedResponse[map[string]interface{}]{
-		Data:       formattedTransactions,
-		Limit:      limit,
-		Offset:     offset,
-		TotalCount: totalCount, // <-- Pass totalCount here
+	h.respondWithData(w, http.StatusOK, formatUser(user), nil, types.Links{
+		"self":    r.URL.Path,
+		"wallets": fmt.Sprintf("/wallets?user_id=%d", user.ID),
+	})
+}
+
+// ListUsers handles the list users request.
+// GET /users
+func (h *WalletHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	users, totalCount, err := h.service.ListUsers(r.Context(), opts)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	formattedUsers := make([]map[string]any, len(users))
+	for i, user := range users {
+		formattedUsers[i] = project(formatUser(&user), opts.Fields)
+	}
+
+	h.respondWithJSON(w, http.StatusOK, types.NewPaginatedResponse(formattedUsers, r.URL, opts.Limit, opts.Offset, totalCount))
+}
+
+// ListWallets handles the list wallets request, optionally filtered by owner.
+// GET /wallets?user_id={userID}
+func (h *WalletHandler) ListWallets(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	var filter repository.WalletFilter
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil {
+			h.respondWithError(w, r, util.ErrInvalidInput)
+			return
+		}
+		filter.UserID = &userID
 	}
 
-	h.respondWithJSON(w, http.StatusOK, responsePayload)
+	wallets, totalCount, err := h.service.ListWallets(r.Context(), filter, opts)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	formattedWallets := make([]map[string]any, len(wallets))
+	for i, wallet := range wallets {
+		formattedWallets[i] = project(formatWallet(&wallet), opts.Fields)
+	}
+
+	h.respondWithJSON(w, http.StatusOK, types.NewPaginatedResponse(formattedWallets, r.URL, opts.Limit, opts.Offset, totalCount))
+}
+
+// GetTransaction handles the get single transaction request.
+// GET /transactions/{transactionID}
+func (h *WalletHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := uuid.Parse(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	transaction, err := h.service.GetTransaction(r.Context(), transactionID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	formatted := formatTransaction(transaction)
+	formatted["display_description"] = h.descriptions.Describe(r.Context(), transaction, 0)
+	h.respondWithData(w, http.StatusOK, formatted, nil, types.Links{"self": r.URL.Path})
+}
+
+// WaitForTransaction handles the wait for transaction request: a long poll that answers once the transaction
+// has left PENDING, or with the transaction still PENDING when timeout (default 30s, at most 60s) elapses.
+// GET /transactions/{transactionID}/wait?timeout=30s
+func (h *WalletHandler) WaitForTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := uuid.Parse(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	timeout := defaultTransactionWait
+	if raw := r.URL.Query().Get("timeout"); raw != "" {
+		timeout, err = time.ParseDuration(raw)
+		if err != nil || timeout < 0 || timeout > maxTransactionWait {
+			h.respondWithError(w, r, fmt.Errorf("%w: timeout must be a duration of at most %s, e.g. 30s", util.ErrInvalidInput, maxTransactionWait))
+			return
+		}
+	}
+	// Outlast the server's write timeout, which is shorter than a long poll
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + DefaultTimeout))
+
+	transaction, err := h.service.WaitForTransaction(r.Context(), transactionID, timeout)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+
+	formatted := formatTransaction(transaction)
+	formatted["display_description"] = h.descriptions.Describe(r.Context(), transaction, 0)
+	h.respondWithData(w, http.StatusOK, formatted, nil, types.Links{"self": fmt.Sprintf("/transactions/%s", transaction.PublicID)})
+}
+
+// formatUser renders a user for API responses.
+func formatUser(user *domain.User) map[string]any {
+	return map[string]any{
+		"id":                user.ID,
+		"username":          user.Username,
+		"timezone":          user.Timezone,
+		"residency":         user.Residency,
+		"email":             user.Email,
+		"phone":             user.Phone,
+		"email_verified_at": user.EmailVerifiedAt,
+		"phone_verified_at": user.PhoneVerifiedAt,
+		"created_at":        user.CreatedAt,
+		"updated_at":        user.UpdatedAt,
+	}
+}
+
+// formatWallet renders a wallet for API responses, with the balance at its currency's scale.
+func formatWallet(wallet *domain.Wallet) map[string]any {
+	formatted := map[string]any{
+		"id":         wallet.PublicID,
+		"user_id":    wallet.UserID,
+		"currency":   wallet.Currency,
+		"balance":    newMoney(wallet.Balance, wallet.Currency),
+		"version":    wallet.Version,
+		"created_at": wallet.CreatedAt,
+		"updated_at": wallet.UpdatedAt,
+	}
+	if !wallet.LastActivityAt.IsZero() {
+		formatted["last_activity_at"] = wallet.LastActivityAt
+	}
+	if wallet.DormantSince != nil {
+		formatted["dormant_since"] = wallet.DormantSince
+	}
+	if wallet.FrozenAt != nil {
+		formatted["frozen_at"] = wallet.FrozenAt
+	}
+	if wallet.OwnershipFrozenAt != nil {
+		formatted["ownership_frozen_at"] = wallet.OwnershipFrozenAt
+	}
+	// Only the change feed returns deleted wallets
+	if wallet.DeletedAt != nil {
+		formatted["deleted_at"] = wallet.DeletedAt
+	}
+	return formatted
+}
+
+// formatTransaction renders a transaction for API responses, with amounts at its currency's scale.
+func formatTransaction(tx *domain.Transaction) map[string]any {
+	return map[string]any{
+		"id":                    tx.PublicID,
+		"from_wallet_id":        tx.FromWalletPublicID,
+		"to_wallet_id":          tx.ToWalletPublicID,
+		"amount":                newMoney(tx.Amount, tx.Currency),
+		"currency":              tx.Currency,
+		"type":                  tx.Type,
+		"status":                tx.Status,
+		"transaction_time":      tx.TransactionTime,
+		"description":           tx.Description,
+		"category":              tx.Category,
+		"parent_transaction_id": tx.ParentID,
+		"promo_amount":          newMoney(tx.PromoAmount, tx.Currency),
+		"created_at":            tx.CreatedAt,
+	}
+}
+
+// descriptionColumns are the columns a transaction's display_description is rendered from.
+var descriptionColumns = []string{"type", "description", "category", "from_wallet_id", "to_wallet_id", "from_username", "to_username"}
+
+// withSpendingOptions attaches the category and budget override of a withdraw or transfer request to ctx.
+func withSpendingOptions(ctx context.Context, category string, overrideBudget bool) context.Context {
+	ctx = service.WithTransactionCategory(ctx, category)
+	if overrideBudget {
+		ctx = service.WithBudgetOverride(ctx)
+	}
+	return ctx
+}
+
+// transactionLinks returns the links of a freshly created transaction and the wallet it was reported against.
+func transactionLinks(transactionID, walletID uuid.UUID) types.Links {
+	return types.Links{
+		"self":         fmt.Sprintf("/transactions/%s", transactionID),
+		"wallet":       fmt.Sprintf("/wallets/%s/balance", walletID),
+		"transactions": fmt.Sprintf("/wallets/%s/transactions", walletID),
+	}
+}
+
+// walletFromPath resolves the {walletID} path parameter, a wallet's public UUID, to the wallet.
+// On failure it writes the error response and reports false.
+func (h *WalletHandler) walletFromPath(w http.ResponseWriter, r *http.Request) (*domain.Wallet, bool) {
+	return resolveWalletFromPath(h.responder, h.service, w, r)
+}
+
+// readableWalletFromPath is walletFromPath for reads of the wallet and its balance, which the service's
+// authorization policy must permit (service.ActionReadBalance).
+func (h *WalletHandler) readableWalletFromPath(w http.ResponseWriter, r *http.Request) (*domain.Wallet, bool) {
+	wallet, ok := h.walletFromPath(w, r)
+	if !ok {
+		return nil, false
+	}
+	wallet, err := h.service.GetBalance(r.Context(), wallet.ID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return nil, false
+	}
+	return wallet, true
+}
+
+// resolveWalletFromPath implements walletFromPath for any handler with access to the wallet service.
+func resolveWalletFromPath(rs responder, svc service.WalletService, w http.ResponseWriter, r *http.Request) (*domain.Wallet, bool) {
+	publicID, err := uuid.Parse(chi.URLParam(r, "walletID"))
+	if err != nil {
+		rs.respondWithError(w, r, util.ErrInvalidInput)
+		return nil, false
+	}
+	wallet, err := svc.GetWalletByPublicID(r.Context(), publicID)
+	if err != nil {
+		rs.respondWithError(w, r, err)
+		return nil, false
+	}
+	return wallet, true
 }
@@ -0,0 +1,127 @@
+// internal/api/handler/wallet_export.go
+package handler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// WalletExportHandler handles HTTP requests for asynchronous full-history wallet exports.
+type WalletExportHandler struct {
+	responder
+	exports service.WalletExportService
+	wallets service.WalletService
+	logger  *slog.Logger
+}
+
+// NewWalletExportHandler creates a new WalletExportHandler.
+func NewWalletExportHandler(exports service.WalletExportService, wallets service.WalletService, logger *slog.Logger) *WalletExportHandler {
+	return &WalletExportHandler{
+		responder: responder{logger: logger},
+		exports:   exports,
+		wallets:   wallets,
+		logger:    logger,
+	}
+}
+
+// RequestExport handles the request export request. The export is queued and answered with 202 Accepted;
+// its progress is polled at the Location returned.
+// POST /wallets/{walletID}/exports
+func (h *WalletExportHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.exports.RequestExport(r.Context(), wallet)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	links := walletExportLinks(export)
+	w.Header().Set("Location", links["self"])
+	h.respondWithData(w, http.StatusAccepted, export, walletExportMeta(h.exports, export), links)
+}
+
+// GetExport handles the get export request. Once the export is COMPLETED, meta carries a signed download URL.
+// GET /exports/{exportID}
+func (h *WalletExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	exportID, err := uuid.Parse(chi.URLParam(r, "exportID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+
+	export, err := h.exports.GetExport(r.Context(), exportID)
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, export, walletExportMeta(h.exports, export), walletExportLinks(export))
+}
+
+// DownloadExport handles the download export request, streaming the CSV file. The URL must carry the
+// expiry and signature handed out by GetExport.
+// GET /exports/{exportID}/download
+func (h *WalletExportHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	exportID, err := uuid.Parse(chi.URLParam(r, "exportID"))
+	if err != nil {
+		h.respondWithError(w, r, util.ErrInvalidInput)
+		return
+	}
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil {
+		h.respondWithError(w, r, fmt.Errorf("%w: expires must be a Unix timestamp", util.ErrInvalidInput))
+		return
+	}
+
+	export, body, err := h.exports.OpenDownload(r.Context(), exportID, expires, r.URL.Query().Get("signature"), time.Now().UTC())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="wallet-%s-transactions.csv"`, export.WalletPublicID))
+	if export.AuditSequence != nil && export.AuditHash != nil {
+		// The digest of the wallet's audit chain when the export was taken, to check the file against
+		w.Header().Set("X-Audit-Chain-Sequence", strconv.FormatInt(*export.AuditSequence, 10))
+		w.Header().Set("X-Audit-Chain-Hash", *export.AuditHash)
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, body); err != nil {
+		// The status is already sent; the client sees a truncated body
+		h.logger.Error("Failed to stream wallet export", "export_id", export.PublicID, "error", err)
+	}
+}
+
+// walletExportMeta reports the export's progress and, once it is complete, a freshly signed download URL.
+func walletExportMeta(exports service.WalletExportService, export *domain.WalletExport) map[string]any {
+	meta := map[string]any{"progress": export.Progress()}
+	if export.Status == domain.WalletExportStatusCompleted {
+		url, expiresAt := exports.DownloadURL(export, time.Now().UTC())
+		meta["download_url"] = url
+		meta["download_url_expires_at"] = expiresAt
+	}
+	return meta
+}
+
+func walletExportLinks(export *domain.WalletExport) types.Links {
+	return types.Links{
+		"self":   fmt.Sprintf("/exports/%s", export.PublicID),
+		"wallet": fmt.Sprintf("/wallets/%s", export.WalletPublicID),
+	}
+}
@@ -0,0 +1,107 @@
+// internal/api/handler/wallet_serialization.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"finflow-wallet/internal/api/middleware"
+	"finflow-wallet/internal/api/types"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+)
+
+// WalletSerializationHandler handles the admin requests for the serialization modes of contended wallets.
+type WalletSerializationHandler struct {
+	responder
+	serialization service.WalletSerializationService
+	wallets       service.WalletService
+	logger        *slog.Logger
+}
+
+// NewWalletSerializationHandler creates a new WalletSerializationHandler.
+func NewWalletSerializationHandler(serialization service.WalletSerializationService, wallets service.WalletService, logger *slog.Logger) *WalletSerializationHandler {
+	return &WalletSerializationHandler{
+		responder:     responder{logger: logger},
+		serialization: serialization,
+		wallets:       wallets,
+		logger:        logger,
+	}
+}
+
+// WalletSerializationRequest represents the request body for choosing the serialization mode of a wallet.
+type WalletSerializationRequest struct {
+	Mode domain.SerializationMode `json:"mode"` // QUEUE or ADVISORY_LOCK
+}
+
+// ListWalletSerializations handles the list wallet serializations request: every wallet with a mode, and
+// the depth and waits of its queue in the replica answering.
+// GET /admin/wallet-serialization
+func (h *WalletSerializationHandler) ListWalletSerializations(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.serialization.ListSerializations(r.Context())
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	formatted := make([]map[string]any, len(statuses))
+	for i, status := range statuses {
+		formatted[i] = formatWalletSerialization(&status.WalletSerialization)
+		if queue := status.Queue; queue != nil {
+			formatted[i]["queue"] = map[string]any{
+				"depth":       queue.Depth,
+				"max_depth":   queue.MaxDepth,
+				"entered":     queue.Entered,
+				"avg_wait_ms": float64(queue.AvgWait.Microseconds()) / 1000,
+			}
+		}
+	}
+	h.respondWithData(w, http.StatusOK, formatted, nil, types.Links{"self": "/admin/wallet-serialization"})
+}
+
+// SetWalletSerialization handles the set wallet serialization request.
+// PUT /admin/wallets/{walletID}/serialization
+func (h *WalletSerializationHandler) SetWalletSerialization(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	var req WalletSerializationRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+
+	serialization, err := h.serialization.SetSerialization(r.Context(), wallet, req.Mode, middleware.AdminFromContext(r.Context()))
+	if err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	h.respondWithData(w, http.StatusOK, formatWalletSerialization(serialization), nil, types.Links{
+		"self":          fmt.Sprintf("/admin/wallets/%s/serialization", wallet.PublicID),
+		"serialization": "/admin/wallet-serialization",
+	})
+}
+
+// DeleteWalletSerialization handles the delete wallet serialization request, returning the wallet to row locking.
+// DELETE /admin/wallets/{walletID}/serialization
+func (h *WalletSerializationHandler) DeleteWalletSerialization(w http.ResponseWriter, r *http.Request) {
+	wallet, ok := resolveWalletFromPath(h.responder, h.wallets, w, r)
+	if !ok {
+		return
+	}
+	if err := h.serialization.DeleteSerialization(r.Context(), wallet.ID, middleware.AdminFromContext(r.Context())); err != nil {
+		h.respondWithError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// formatWalletSerialization renders the serialization mode of a wallet for API responses.
+func formatWalletSerialization(serialization *domain.WalletSerialization) map[string]any {
+	return map[string]any{
+		"wallet_id":  serialization.WalletPublicID,
+		"mode":       serialization.Mode,
+		"updated_by": serialization.UpdatedBy,
+		"updated_at": serialization.UpdatedAt,
+	}
+}
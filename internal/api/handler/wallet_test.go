@@ -0,0 +1,62 @@
+// internal/api/handler/wallet_test.go
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// fixedWalletRepository finds the one wallet it holds. Its other methods are left to the embedded nil
+// interface and panic.
+type fixedWalletRepository struct {
+	repository.WalletRepository
+	wallet *domain.Wallet
+}
+
+func (r *fixedWalletRepository) GetWalletByID(ctx context.Context, q repository.DBExecutor, id int64) (*domain.Wallet, error) {
+	if id != r.wallet.ID {
+		return nil, util.ErrNotFound
+	}
+	return r.wallet, nil
+}
+
+func (r *fixedWalletRepository) GetWalletByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Wallet, error) {
+	if publicID != r.wallet.PublicID {
+		return nil, util.ErrNotFound
+	}
+	return r.wallet, nil
+}
+
+// denyAllPolicy denies every operation.
+type denyAllPolicy struct{}
+
+func (denyAllPolicy) Authorize(ctx context.Context, req service.PolicyRequest) error {
+	return util.ErrForbidden
+}
+
+// TestWalletReadsEvaluatePolicy tests that the wallet and balance endpoints are subject to the wallet
+// service's authorization policy.
+func TestWalletReadsEvaluatePolicy(t *testing.T) {
+	wallet := newGoldenWalletService().wallets[goldenUSDWalletID]
+	svc := service.NewWalletService(nil, nil, nil, &fixedWalletRepository{wallet: wallet}, nil, nil, nil, nil,
+		service.WithPolicy(denyAllPolicy{}))
+	router := goldenRouter(svc)
+
+	for _, path := range []string{"/wallets/" + wallet.PublicID.String() + "/balance", "/wallets/" + wallet.PublicID.String()} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code, path)
+		assert.NotContains(t, rec.Body.String(), wallet.Balance.String(), path)
+	}
+}
@@ -0,0 +1,61 @@
+// internal/api/middleware/admin.go
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// adminKey is the context key of the authenticated admin's name.
+type adminKey struct{}
+
+// RequireAdminToken guards admin routes with static bearer tokens: the shared token, or the personal token
+// of one of the named admins in users (name -> token), whose name is then available through AdminFromContext.
+// With neither configured, the admin API is disabled altogether.
+func RequireAdminToken(token string, users map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" && len(users) == 0 {
+				writeError(w, r, http.StatusForbidden, "admin_disabled")
+				return
+			}
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				writeError(w, r, http.StatusUnauthorized, "invalid_admin_token")
+				return
+			}
+			if token != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			for name, userToken := range users {
+				if subtle.ConstantTimeCompare([]byte(provided), []byte(userToken)) == 1 {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminKey{}, name)))
+					return
+				}
+			}
+			writeError(w, r, http.StatusUnauthorized, "invalid_admin_token")
+		})
+	}
+}
+
+// AdminFromContext returns the name of the admin whose personal token authenticated the request, or "" for
+// the shared admin token.
+func AdminFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(adminKey{}).(string)
+	return name
+}
+
+// RequireNamedAdmin guards admin routes whose actions are attributed to an admin, such as the steps of a
+// maker-checker approval: the shared admin token is not accepted there.
+func RequireNamedAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if AdminFromContext(r.Context()) == "" {
+			writeError(w, r, http.StatusForbidden, "admin_identity_required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,55 @@
+// internal/api/middleware/admin_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireAdminToken(t *testing.T) {
+	var admin string
+	guarded := RequireAdminToken("shared", map[string]string{"alice": "alice-token"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin = AdminFromContext(r.Context())
+	}))
+	serve := func(handler http.Handler, token string) int {
+		admin = ""
+		req := httptest.NewRequest(http.MethodGet, "/admin/adjustments", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("SharedTokenIsAnonymous", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(guarded, "shared"))
+		assert.Empty(t, admin)
+	})
+
+	t.Run("PersonalTokenNamesTheAdmin", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(guarded, "alice-token"))
+		assert.Equal(t, "alice", admin)
+	})
+
+	t.Run("UnknownTokenRejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(guarded, "guess"))
+		assert.Equal(t, http.StatusUnauthorized, serve(guarded, ""))
+	})
+
+	t.Run("NamedAdminRequired", func(t *testing.T) {
+		named := RequireAdminToken("shared", map[string]string{"alice": "alice-token"})(RequireNamedAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+		assert.Equal(t, http.StatusForbidden, serve(named, "shared"))
+		assert.Equal(t, http.StatusOK, serve(named, "alice-token"))
+	})
+
+	t.Run("DisabledWithoutTokens", func(t *testing.T) {
+		disabled := RequireAdminToken("", nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		assert.Equal(t, http.StatusForbidden, serve(disabled, "anything"))
+	})
+}
@@ -0,0 +1,17 @@
+// internal/api/middleware/cache.go
+package middleware
+
+import "net/http"
+
+// NoStoreMutations marks the responses to unsafe requests, which move money or change settings, as not to be
+// stored by any cache. Read endpoints set their own Cache-Control.
+func NoStoreMutations(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
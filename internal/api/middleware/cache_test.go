@@ -0,0 +1,28 @@
+// internal/api/middleware/cache_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoStoreMutations(t *testing.T) {
+	handler := NoStoreMutations(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for method, cacheControl := range map[string]string{
+		http.MethodGet:    "",
+		http.MethodHead:   "",
+		http.MethodPost:   "no-store",
+		http.MethodPatch:  "no-store",
+		http.MethodDelete: "no-store",
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/wallets/abc", nil))
+		assert.Equal(t, cacheControl, rec.Header().Get("Cache-Control"), method)
+	}
+}
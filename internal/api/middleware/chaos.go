@@ -0,0 +1,96 @@
+// internal/api/middleware/chaos.go
+package middleware
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"finflow-wallet/pkg/db"
+)
+
+// HeaderChaosFault lists the faults injected into a response, e.g. "latency,db-drop", so a tester can tell
+// them apart from real failures.
+const HeaderChaosFault = "X-Chaos-Fault"
+
+// ChaosRule injects faults into the requests it matches. Each fault is injected independently, with its
+// own probability from 0 to 1.
+type ChaosRule struct {
+	Method      string // Empty matches any method
+	Path        string // A path.Match pattern, e.g. "/wallets/*/withdraw"
+	Latency     time.Duration
+	LatencyRate float64 // Probability of delaying the request by Latency
+	ErrorRate   float64 // Probability of answering 500 without running the handler
+	DBDropRate  float64 // Probability of failing the request's database transactions as if the connection dropped
+}
+
+func (r *ChaosRule) matches(req *http.Request) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, req.Method) {
+		return false
+	}
+	ok, _ := path.Match(r.Path, req.URL.Path)
+	return ok
+}
+
+// ChaosInjector is a fault-injection middleware for staging, used to check how clients handle latency,
+// server errors and lost database connections: whether they retry, and whether retries stay idempotent.
+// Dropped connections take effect only where transactions are begun through db.DropMarkedConnections.
+// It must never be enabled in production.
+type ChaosInjector struct {
+	rules  []ChaosRule
+	random func() float64
+	logger *slog.Logger
+}
+
+// NewChaosInjector creates a ChaosInjector; a request is subject to the first rule matching it.
+func NewChaosInjector(rules []ChaosRule, logger *slog.Logger) *ChaosInjector {
+	return &ChaosInjector{rules: rules, random: rand.Float64, logger: logger}
+}
+
+// Middleware injects the faults of the rule matching the request, if any.
+func (c *ChaosInjector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rule := c.match(r)
+		if rule == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var faults []string
+		inject := func(fault string) {
+			faults = append(faults, fault)
+			w.Header().Set(HeaderChaosFault, strings.Join(faults, ","))
+			c.logger.Warn("Chaos fault injected", "fault", fault, "method", r.Method, "path", r.URL.Path)
+		}
+		if rule.Latency > 0 && c.random() < rule.LatencyRate {
+			inject("latency")
+			select {
+			case <-time.After(rule.Latency):
+			case <-r.Context().Done():
+				return // The client or the request timeout gave up first
+			}
+		}
+		if c.random() < rule.ErrorRate {
+			inject("error")
+			writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		if c.random() < rule.DBDropRate {
+			inject("db-drop")
+			r = r.WithContext(db.WithDroppedConnection(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *ChaosInjector) match(r *http.Request) *ChaosRule {
+	for i := range c.rules {
+		if c.rules[i].matches(r) {
+			return &c.rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// internal/api/middleware/chaos_test.go
+package middleware
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/pkg/db"
+)
+
+func TestChaosInjector(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	// begin stands in for a service beginning a transaction with the request's context
+	begin := db.DropMarkedConnections(func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+		return nil, nil
+	})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := begin(r.Context(), nil); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(c *ChaosInjector, method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c.Middleware(handler).ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+	always := func() float64 { return 0 } // Every fault with a non-zero rate fires
+
+	t.Run("UnmatchedRequestsPassThrough", func(t *testing.T) {
+		c := NewChaosInjector([]ChaosRule{{Method: "POST", Path: "/wallets/*/withdraw", ErrorRate: 1}}, logger)
+		c.random = always
+
+		assert.Equal(t, http.StatusOK, serve(c, http.MethodGet, "/wallets/abc/withdraw").Code)
+		assert.Equal(t, http.StatusOK, serve(c, http.MethodPost, "/wallets/abc/deposit").Code)
+		assert.Equal(t, http.StatusInternalServerError, serve(c, http.MethodPost, "/wallets/abc/withdraw").Code)
+	})
+
+	t.Run("ErrorSkipsHandler", func(t *testing.T) {
+		c := NewChaosInjector([]ChaosRule{{Path: "/transfers", ErrorRate: 0.5}}, logger)
+		c.random = always
+
+		rec := serve(c, http.MethodPost, "/transfers")
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "error", rec.Header().Get(HeaderChaosFault))
+		assert.JSONEq(t, `{"error": "Internal server error", "code": "internal_error"}`, rec.Body.String())
+	})
+
+	t.Run("DroppedConnectionFailsTransactions", func(t *testing.T) {
+		c := NewChaosInjector([]ChaosRule{{Path: "/transfers", DBDropRate: 0.5}}, logger)
+		c.random = always
+
+		rec := serve(c, http.MethodPost, "/transfers")
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "db-drop", rec.Header().Get(HeaderChaosFault))
+		_, err := begin(db.WithDroppedConnection(context.Background()), nil)
+		assert.ErrorIs(t, err, driver.ErrBadConn)
+	})
+
+	t.Run("LatencyDelaysRequest", func(t *testing.T) {
+		c := NewChaosInjector([]ChaosRule{{Path: "/transfers", Latency: 20 * time.Millisecond, LatencyRate: 0.5}}, logger)
+		c.random = always
+
+		start := time.Now()
+		rec := serve(c, http.MethodPost, "/transfers")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "latency", rec.Header().Get(HeaderChaosFault))
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("FaultsFollowTheirRates", func(t *testing.T) {
+		c := NewChaosInjector([]ChaosRule{{Path: "/transfers", ErrorRate: 0.1}}, logger)
+		c.random = func() float64 { return 0.1 } // Not below the rate
+
+		rec := serve(c, http.MethodPost, "/transfers")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderChaosFault))
+	})
+}
@@ -0,0 +1,221 @@
+// internal/api/middleware/compress.go
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Compressor compresses responses with gzip or deflate, whichever the client prefers in Accept-Encoding.
+// Only responses of the configured media types are compressed, and only once they reach the minimum size:
+// the first bytes are held back until then, so small responses are sent as they are. Responses that are
+// flushed, such as streamed files, are compressed from the first flush.
+type Compressor struct {
+	minSize      int
+	contentTypes []string
+	gzipWriters  sync.Pool
+	zlibWriters  sync.Pool
+}
+
+// NewCompressor creates a Compressor. level is the gzip and deflate level, from 1 (fastest) to 9 (smallest).
+func NewCompressor(level, minSize int, contentTypes []string) *Compressor {
+	c := &Compressor{minSize: minSize, contentTypes: contentTypes}
+	c.gzipWriters.New = func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, level) // The level is validated by the config
+		return w
+	}
+	c.zlibWriters.New = func() any {
+		w, _ := zlib.NewWriterLevel(io.Discard, level)
+		return w
+	}
+	return c
+}
+
+// Middleware compresses the responses of requests accepting gzip or deflate.
+func (c *Compressor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, compressor: c, encoding: encoding, status: http.StatusOK}
+		next.ServeHTTP(cw, r)
+		cw.close() // Not deferred: after a panic, the held back bytes are dropped for the recoverer's response
+	})
+}
+
+// negotiateEncoding returns the encoding of the response, "gzip", "deflate" or "" for none. gzip wins ties.
+func negotiateEncoding(acceptEncoding string) string {
+	var gzipQ, deflateQ, anyQ float64 = -1, -1, -1 // -1: not listed
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(coding), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gzip", "x-gzip":
+			gzipQ = q
+		case "deflate":
+			deflateQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	// A wildcard covers the encodings not listed
+	if gzipQ < 0 {
+		gzipQ = anyQ
+	}
+	if deflateQ < 0 {
+		deflateQ = anyQ
+	}
+	switch {
+	case gzipQ > 0 && gzipQ >= deflateQ:
+		return "gzip"
+	case deflateQ > 0:
+		return "deflate"
+	}
+	return ""
+}
+
+// compressWriter decides whether to compress a response at its first write, and buffers it until it reaches
+// the minimum size.
+type compressWriter struct {
+	http.ResponseWriter
+	compressor  *Compressor
+	encoding    string
+	status      int
+	wroteHeader bool
+	buf         []byte
+	decided     bool      // Whether the response is compressed has been decided
+	encoder     io.Writer // The compressor once compressing; nil when sent as it is
+}
+
+// WriteHeader holds back the status until the response's encoding is known.
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	if code < http.StatusOK { // Informational responses go out right away
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	cw.status, cw.wroteHeader = code, true
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		if !cw.compressible() {
+			cw.start(false)
+		} else if len(cw.buf)+len(p) < cw.compressor.minSize {
+			cw.buf = append(cw.buf, p...)
+			return len(p), nil
+		} else {
+			cw.start(true)
+		}
+	}
+	if cw.encoder != nil {
+		return cw.encoder.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush sends what was written so far; a response flushed before reaching the minimum size is compressed
+// anyway, as it is being streamed.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			cw.WriteHeader(http.StatusOK)
+		}
+		cw.start(cw.compressible())
+	}
+	if flusher, ok := cw.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// compressible reports whether the response can be compressed: it has a body of a configured media type
+// that is not encoded already.
+func (cw *compressWriter) compressible() bool {
+	if cw.status == http.StatusNoContent || cw.status == http.StatusNotModified {
+		return false
+	}
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && slices.Contains(cw.compressor.contentTypes, mediaType)
+}
+
+// start sends the status and the held back bytes, through a compressor if compress is set.
+func (cw *compressWriter) start(compress bool) {
+	cw.decided = true
+	if compress {
+		header := cw.Header()
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		if cw.encoding == "gzip" {
+			w := cw.compressor.gzipWriters.Get().(*gzip.Writer)
+			w.Reset(cw.ResponseWriter)
+			cw.encoder = w
+		} else {
+			w := cw.compressor.zlibWriters.Get().(*zlib.Writer)
+			w.Reset(cw.ResponseWriter)
+			cw.encoder = w
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) > 0 {
+		if cw.encoder != nil {
+			_, _ = cw.encoder.Write(cw.buf)
+		} else {
+			_, _ = cw.ResponseWriter.Write(cw.buf)
+		}
+		cw.buf = nil
+	}
+}
+
+// close sends a response that never reached the minimum size as it is, or finishes the compressed stream.
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			return // Nothing was written; let the server answer as usual
+		}
+		cw.start(false)
+		return
+	}
+	switch w := cw.encoder.(type) {
+	case *gzip.Writer:
+		_ = w.Close()
+		cw.compressor.gzipWriters.Put(w)
+	case *zlib.Writer:
+		_ = w.Close()
+		cw.compressor.zlibWriters.Put(w)
+	}
+}
@@ -0,0 +1,102 @@
+// internal/api/middleware/compress_test.go
+package middleware
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressor(t *testing.T) {
+	large := strings.Repeat(`{"id": 1, "amount": "12.50"},`, 100)
+	c := NewCompressor(5, 1024, []string{"application/json", "text/csv"})
+	serve := func(acceptEncoding, contentType, body string, flush bool) *httptest.ResponseRecorder {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(http.StatusCreated)
+			for _, chunk := range strings.SplitAfter(body, ",") {
+				_, _ = io.WriteString(w, chunk)
+				if flush {
+					w.(http.Flusher).Flush()
+				}
+			}
+		})
+		req := httptest.NewRequest(http.MethodGet, "/wallets/abc/transactions", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		c.Middleware(handler).ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("LargeResponseIsGzipped", func(t *testing.T) {
+		rec := serve("deflate, gzip", "application/json; charset=utf-8", large, false)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		assert.Less(t, rec.Body.Len(), len(large))
+		reader, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, large, string(decoded))
+	})
+
+	t.Run("DeflateWhenPreferred", func(t *testing.T) {
+		rec := serve("gzip;q=0.5, deflate", "text/csv", large, false)
+
+		assert.Equal(t, "deflate", rec.Header().Get("Content-Encoding"))
+		reader, err := zlib.NewReader(rec.Body)
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, large, string(decoded))
+	})
+
+	t.Run("SmallResponseIsNotCompressed", func(t *testing.T) {
+		rec := serve("gzip", "application/json", `{"id": 1}`, false)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"id": 1}`, rec.Body.String())
+	})
+
+	t.Run("StreamedResponseIsCompressed", func(t *testing.T) {
+		rec := serve("gzip", "text/csv", "a,b,c", true)
+
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		reader, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "a,b,c", string(decoded))
+	})
+
+	t.Run("OtherContentTypesAndEncodingsPassThrough", func(t *testing.T) {
+		for _, tc := range []struct{ acceptEncoding, contentType string }{
+			{"gzip", "application/x-protobuf"},
+			{"", "application/json"},
+			{"br", "application/json"},
+			{"gzip;q=0, deflate;q=0", "application/json"},
+		} {
+			rec := serve(tc.acceptEncoding, tc.contentType, large, false)
+			assert.Empty(t, rec.Header().Get("Content-Encoding"), tc)
+			assert.Equal(t, large, rec.Body.String(), tc)
+		}
+	})
+
+	t.Run("WildcardCoversUnlistedEncodings", func(t *testing.T) {
+		assert.Equal(t, "gzip", negotiateEncoding("*"))
+		assert.Equal(t, "deflate", negotiateEncoding("gzip;q=0, *"))
+		assert.Equal(t, "", negotiateEncoding("identity"))
+	})
+}
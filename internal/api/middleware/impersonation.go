@@ -0,0 +1,109 @@
+// internal/api/middleware/impersonation.go
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// HeaderImpersonationToken carries the token of an impersonation session.
+const HeaderImpersonationToken = "X-Impersonation-Token"
+
+// ImpersonationGuard scopes and audits requests made with an impersonation token.
+//
+// An impersonated request may read anything outside /admin. The only write it may make is POST /transfers,
+// once per session and only for sessions created with allow_transfer; the wallet service checks that the
+// money leaves a wallet of the impersonated user and uses up the transfer when it makes it. Every impersonated request, allowed or not, is logged
+// and recorded in the session's audit trail. Requests without the header are passed through.
+type ImpersonationGuard struct {
+	sessions service.ImpersonationService
+	logger   *slog.Logger
+}
+
+// NewImpersonationGuard creates an ImpersonationGuard.
+func NewImpersonationGuard(sessions service.ImpersonationService, logger *slog.Logger) *ImpersonationGuard {
+	return &ImpersonationGuard{sessions: sessions, logger: logger}
+}
+
+// Middleware enforces the scope of impersonation sessions and audits their requests.
+func (g *ImpersonationGuard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(HeaderImpersonationToken)
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		session, err := g.sessions.Authenticate(r.Context(), token)
+		if errors.Is(err, util.ErrNotFound) {
+			writeError(w, r, http.StatusUnauthorized, "invalid_impersonation_token")
+			return
+		}
+		if err != nil {
+			g.logger.Error("Failed to authenticate impersonation token", "error", err)
+			writeError(w, r, http.StatusInternalServerError, "unexpected_error")
+			return
+		}
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		action, denial := g.authorize(r, session)
+		if denial != "" {
+			writeError(ww, r, http.StatusForbidden, denial)
+		} else {
+			ctx := service.WithImpersonation(r.Context(), session)
+			ctx = service.WithSubject(ctx, service.Subject{Kind: service.SubjectUser, UserID: session.UserID})
+			next.ServeHTTP(ww, r.WithContext(ctx))
+		}
+		g.audit(r, session, action, ww.Status())
+	})
+}
+
+// authorize classifies the request and returns the error code of its denial, if it is denied.
+func (g *ImpersonationGuard) authorize(r *http.Request, session *domain.ImpersonationSession) (domain.ImpersonationAction, string) {
+	if strings.HasPrefix(r.URL.Path, "/admin") {
+		return domain.ImpersonationActionDenied, "impersonation_admin_denied"
+	}
+	switch {
+	case r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions:
+		return domain.ImpersonationActionRead, ""
+	case r.Method == http.MethodPost && r.URL.Path == "/transfers":
+		// The transfer is claimed with the transfer itself; a session that has used it up is turned away here
+		if !session.AllowTransfer || session.TransferClaimedAt != nil {
+			return domain.ImpersonationActionDenied, "impersonation_transfer_denied"
+		}
+		return domain.ImpersonationActionTransfer, ""
+	default:
+		return domain.ImpersonationActionDenied, "impersonation_read_only"
+	}
+}
+
+// audit logs the request and records it in the session's audit trail. The entry is written even if the
+// client went away, and a failure to write it is logged rather than failing the finished request.
+func (g *ImpersonationGuard) audit(r *http.Request, session *domain.ImpersonationSession, action domain.ImpersonationAction, status int) {
+	if status == 0 {
+		status = http.StatusOK // Nothing written
+	}
+	entry := &domain.ImpersonationAuditEntry{
+		SessionID: session.ID,
+		Action:    action,
+		Method:    r.Method,
+		Path:      r.URL.RequestURI(),
+		Status:    status,
+	}
+	if requestID := chimiddleware.GetReqID(r.Context()); requestID != "" {
+		entry.RequestID = &requestID
+	}
+	g.logger.Warn("Impersonated request", "impersonation_id", session.PublicID, "operator", session.Operator,
+		"user_id", session.UserID, "action", action, "method", r.Method, "path", entry.Path, "status", status)
+	if err := g.sessions.Record(context.WithoutCancel(r.Context()), entry); err != nil {
+		g.logger.Error("Failed to record impersonation audit entry", "impersonation_id", session.PublicID, "error", err)
+	}
+}
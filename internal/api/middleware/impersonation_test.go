@@ -0,0 +1,120 @@
+// internal/api/middleware/impersonation_test.go
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// fakeImpersonationSessions keeps one session in memory and collects the audit entries.
+type fakeImpersonationSessions struct {
+	service.ImpersonationService
+	token   string
+	session *domain.ImpersonationSession
+	entries []domain.ImpersonationAuditEntry
+}
+
+func (f *fakeImpersonationSessions) Authenticate(ctx context.Context, token string) (*domain.ImpersonationSession, error) {
+	if token != f.token {
+		return nil, util.ErrNotFound
+	}
+	return f.session, nil
+}
+
+func (f *fakeImpersonationSessions) Record(ctx context.Context, entry *domain.ImpersonationAuditEntry) error {
+	f.entries = append(f.entries, *entry)
+	return nil
+}
+
+func TestImpersonationGuard(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var seen *domain.ImpersonationSession
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = service.ImpersonationFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	newGuard := func(allowTransfer bool) (*ImpersonationGuard, *fakeImpersonationSessions) {
+		sessions := &fakeImpersonationSessions{
+			token:   "imp_test",
+			session: &domain.ImpersonationSession{ID: 1, PublicID: uuid.New(), UserID: 10, Operator: "alice", AllowTransfer: allowTransfer},
+		}
+		return NewImpersonationGuard(sessions, logger), sessions
+	}
+	serve := func(g *ImpersonationGuard, method, path, token string) *httptest.ResponseRecorder {
+		seen = nil
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set(HeaderImpersonationToken, token)
+		}
+		rec := httptest.NewRecorder()
+		g.Middleware(ok).ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("WithoutTokenPassesThrough", func(t *testing.T) {
+		g, sessions := newGuard(false)
+		assert.Equal(t, http.StatusOK, serve(g, http.MethodPost, "/transfers", "").Code)
+		assert.Nil(t, seen)
+		assert.Empty(t, sessions.entries)
+	})
+
+	t.Run("UnknownTokenIsRejected", func(t *testing.T) {
+		g, _ := newGuard(false)
+		assert.Equal(t, http.StatusUnauthorized, serve(g, http.MethodGet, "/users/10", "imp_other").Code)
+	})
+
+	t.Run("ReadsAreAllowedAndAudited", func(t *testing.T) {
+		g, sessions := newGuard(false)
+
+		assert.Equal(t, http.StatusOK, serve(g, http.MethodGet, "/wallets?user_id=10", "imp_test").Code)
+		assert.Equal(t, sessions.session, seen)
+		if assert.Len(t, sessions.entries, 1) {
+			assert.Equal(t, domain.ImpersonationActionRead, sessions.entries[0].Action)
+			assert.Equal(t, "/wallets?user_id=10", sessions.entries[0].Path)
+			assert.Equal(t, http.StatusOK, sessions.entries[0].Status)
+		}
+	})
+
+	t.Run("WritesAndAdminRoutesAreDeniedAndAudited", func(t *testing.T) {
+		g, sessions := newGuard(true)
+
+		assert.Equal(t, http.StatusForbidden, serve(g, http.MethodPost, "/wallets/x/withdraw", "imp_test").Code)
+		assert.Equal(t, http.StatusForbidden, serve(g, http.MethodGet, "/admin/maintenance", "imp_test").Code)
+		assert.Nil(t, seen)
+		if assert.Len(t, sessions.entries, 2) {
+			assert.Equal(t, domain.ImpersonationActionDenied, sessions.entries[0].Action)
+			assert.Equal(t, http.StatusForbidden, sessions.entries[1].Status)
+		}
+	})
+
+	t.Run("OneTransferWhenAllowed", func(t *testing.T) {
+		g, sessions := newGuard(true)
+
+		assert.Equal(t, http.StatusOK, serve(g, http.MethodPost, "/transfers", "imp_test").Code)
+		// The wallet service claimed the transfer when it made it
+		claimedAt := time.Now()
+		sessions.session.TransferClaimedAt = &claimedAt
+		assert.Equal(t, http.StatusForbidden, serve(g, http.MethodPost, "/transfers", "imp_test").Code)
+		if assert.Len(t, sessions.entries, 2) {
+			assert.Equal(t, domain.ImpersonationActionTransfer, sessions.entries[0].Action)
+			assert.Equal(t, domain.ImpersonationActionDenied, sessions.entries[1].Action)
+		}
+	})
+
+	t.Run("NoTransferWithoutPermission", func(t *testing.T) {
+		g, _ := newGuard(false)
+		assert.Equal(t, http.StatusForbidden, serve(g, http.MethodPost, "/transfers", "imp_test").Code)
+	})
+}
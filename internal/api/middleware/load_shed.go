@@ -0,0 +1,206 @@
+// internal/api/middleware/load_shed.go
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RouteClass groups the routes that share a concurrency limit.
+type RouteClass string
+
+const (
+	RouteClassRead     RouteClass = "read"     // Every request that moves no money, reads and other writes alike
+	RouteClassMovement RouteClass = "movement" // Deposits, withdrawals, transfers and payments
+)
+
+// ClassLimit bounds the concurrent requests of a route class.
+type ClassLimit struct {
+	MaxConcurrent int           // Requests served at once; 0 leaves the class unlimited
+	MaxQueue      int           // Requests waiting for a slot; further ones are shed at once
+	MaxWait       time.Duration // Longest a request waits for a slot before it is shed
+}
+
+// LoadShedConfig configures a LoadShedder.
+type LoadShedConfig struct {
+	Read           ClassLimit
+	Movement       ClassLimit
+	MovementRoutes []string      // path.Match patterns of the routes whose unsafe requests move money
+	ExemptRoutes   []string      // path.Match patterns of routes never limited, e.g. long polls holding no connection
+	ShedPoolUsage  int           // Percentage of the DB pool in use from which reads are shed without queueing; 0 disables
+	RetryAfter     time.Duration // Advertised in the Retry-After header of shed requests
+}
+
+// LoadShedStats describes the requests of a route class since the process started.
+type LoadShedStats struct {
+	Class             RouteClass
+	Limit             ClassLimit
+	InFlight          int   // Requests being served now
+	Queued            int   // Requests waiting for a slot now
+	MaxQueued         int   // Most requests that waited at once
+	Admitted          int64 // Requests served, at once or after waiting
+	ShedQueueFull     int64 // Requests shed because the queue was full
+	ShedTimeout       int64 // Requests shed after waiting MaxWait
+	ShedPoolSaturated int64 // Reads shed because the DB pool was saturated
+}
+
+// classLimiter is the semaphore and queue of one route class.
+type classLimiter struct {
+	limit ClassLimit
+	slots chan struct{} // Holds a token per request in flight
+
+	mu    sync.Mutex
+	stats LoadShedStats
+}
+
+// LoadShedder limits the concurrent requests of each route class, so a spike of reads cannot take the
+// database connections money movements need. Requests beyond the limit queue for a slot; those finding the
+// queue full, or not getting a slot within MaxWait, are answered with 503 Service Unavailable and code
+// overloaded. While the DB pool is saturated, reads are shed without queueing. Admin routes are exempt so
+// operators can still look at the stats.
+type LoadShedder struct {
+	cfg       LoadShedConfig
+	poolStats func() sql.DBStats // Optional; without it reads are never shed for the pool
+	classes   map[RouteClass]*classLimiter
+}
+
+// NewLoadShedder creates a shedder; poolStats reports the DB pool, e.g. (*sql.DB).Stats.
+func NewLoadShedder(cfg LoadShedConfig, poolStats func() sql.DBStats) *LoadShedder {
+	s := &LoadShedder{cfg: cfg, poolStats: poolStats, classes: map[RouteClass]*classLimiter{}}
+	for class, limit := range map[RouteClass]ClassLimit{RouteClassRead: cfg.Read, RouteClassMovement: cfg.Movement} {
+		limiter := &classLimiter{limit: limit, stats: LoadShedStats{Class: class, Limit: limit}}
+		if limit.MaxConcurrent > 0 {
+			limiter.slots = make(chan struct{}, limit.MaxConcurrent)
+		}
+		s.classes[class] = limiter
+	}
+	return s
+}
+
+// Stats returns the stats of every route class, reads first.
+func (s *LoadShedder) Stats() []LoadShedStats {
+	stats := make([]LoadShedStats, 0, len(s.classes))
+	for _, class := range []RouteClass{RouteClassRead, RouteClassMovement} {
+		limiter := s.classes[class]
+		limiter.mu.Lock()
+		stats = append(stats, limiter.stats)
+		limiter.mu.Unlock()
+	}
+	return stats
+}
+
+// Middleware admits, queues or sheds each request by its route class.
+func (s *LoadShedder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.exempt(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		limiter := s.classes[s.classify(r)]
+		release, ok := limiter.acquire(r, s.poolSaturated())
+		if !ok {
+			if r.Context().Err() != nil {
+				return // Cancelled or timed out while queued; the timeout middleware answers
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int(s.cfg.RetryAfter.Seconds())))
+			writeError(w, r, http.StatusServiceUnavailable, "overloaded")
+			return
+		}
+		defer release()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *LoadShedder) exempt(urlPath string) bool {
+	if urlPath == "/health" || strings.HasPrefix(urlPath, "/admin/") {
+		return true
+	}
+	return matchesAny(s.cfg.ExemptRoutes, urlPath)
+}
+
+func (s *LoadShedder) classify(r *http.Request) RouteClass {
+	if !isSafeMethod(r.Method) && matchesAny(s.cfg.MovementRoutes, r.URL.Path) {
+		return RouteClassMovement
+	}
+	return RouteClassRead
+}
+
+// poolSaturated reports whether the share of the DB pool in use has reached the shed threshold.
+func (s *LoadShedder) poolSaturated() bool {
+	if s.poolStats == nil || s.cfg.ShedPoolUsage <= 0 {
+		return false
+	}
+	stats := s.poolStats()
+	return stats.MaxOpenConnections > 0 && stats.InUse*100 >= stats.MaxOpenConnections*s.cfg.ShedPoolUsage
+}
+
+func matchesAny(patterns []string, urlPath string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, urlPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// acquire takes a slot of the class, waiting in its queue if none is free, and reports whether it got one.
+// Reads arriving while the pool is saturated only take a free slot; so do requests of a class without queue.
+func (l *classLimiter) acquire(r *http.Request, poolSaturated bool) (func(), bool) {
+	if poolSaturated && l.stats.Class == RouteClassRead {
+		l.count(func(stats *LoadShedStats) { stats.ShedPoolSaturated++ })
+		return nil, false
+	}
+	if l.slots == nil {
+		l.count(func(stats *LoadShedStats) { stats.Admitted++; stats.InFlight++ })
+		return l.release, true
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		l.count(func(stats *LoadShedStats) { stats.Admitted++; stats.InFlight++ })
+		return l.release, true
+	default:
+	}
+
+	l.mu.Lock()
+	if l.stats.Queued >= l.limit.MaxQueue {
+		l.stats.ShedQueueFull++
+		l.mu.Unlock()
+		return nil, false
+	}
+	l.stats.Queued++
+	l.stats.MaxQueued = max(l.stats.MaxQueued, l.stats.Queued)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.limit.MaxWait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		l.count(func(stats *LoadShedStats) { stats.Queued--; stats.Admitted++; stats.InFlight++ })
+		return l.release, true
+	case <-timer.C:
+		l.count(func(stats *LoadShedStats) { stats.Queued--; stats.ShedTimeout++ })
+		return nil, false
+	case <-r.Context().Done():
+		l.count(func(stats *LoadShedStats) { stats.Queued-- })
+		return nil, false
+	}
+}
+
+func (l *classLimiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+	l.count(func(stats *LoadShedStats) { stats.InFlight-- })
+}
+
+func (l *classLimiter) count(update func(stats *LoadShedStats)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	update(&l.stats)
+}
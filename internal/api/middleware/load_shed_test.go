@@ -0,0 +1,129 @@
+// internal/api/middleware/load_shed_test.go
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadShedder(t *testing.T) {
+	cfg := LoadShedConfig{
+		Read:           ClassLimit{MaxConcurrent: 1, MaxQueue: 1, MaxWait: 50 * time.Millisecond},
+		Movement:       ClassLimit{MaxConcurrent: 1, MaxQueue: 0},
+		MovementRoutes: []string{"/transfers", "/wallets/*/deposit"},
+		ShedPoolUsage:  90,
+		RetryAfter:     2 * time.Second,
+	}
+	// blocking serves requests until release is closed, signalling each one it starts
+	blocking := func() (http.Handler, chan struct{}, chan struct{}) {
+		started, release := make(chan struct{}, 8), make(chan struct{})
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}), started, release
+	}
+	serve := func(h http.Handler, method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	t.Run("QueueFullIsShed", func(t *testing.T) {
+		s := NewLoadShedder(cfg, nil)
+		next, started, release := blocking()
+		h := s.Middleware(next)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() { defer wg.Done(); serve(h, http.MethodPost, "/wallets/w1/deposit") }()
+		<-started
+
+		rec := serve(h, http.MethodPost, "/transfers")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+		assert.Contains(t, rec.Body.String(), "overloaded")
+
+		// Reads have their own slots, so they are not held up by the movement in flight
+		wg.Add(1)
+		go func() { defer wg.Done(); serve(h, http.MethodGet, "/wallets/w1/balance") }()
+		<-started
+		close(release)
+		wg.Wait()
+
+		stats := s.Stats()
+		assert.Equal(t, RouteClassMovement, stats[1].Class)
+		assert.Equal(t, int64(1), stats[1].Admitted)
+		assert.Equal(t, int64(1), stats[1].ShedQueueFull)
+		assert.Equal(t, int64(1), stats[0].Admitted)
+		assert.Zero(t, stats[0].InFlight+stats[1].InFlight)
+	})
+
+	t.Run("QueuedRequestIsShedAfterMaxWait", func(t *testing.T) {
+		s := NewLoadShedder(cfg, nil)
+		next, started, release := blocking()
+		h := s.Middleware(next)
+		defer close(release)
+
+		go serve(h, http.MethodGet, "/wallets/w1/balance")
+		<-started
+
+		rec := serve(h, http.MethodGet, "/wallets/w2/balance")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, int64(1), s.Stats()[0].ShedTimeout)
+		assert.Equal(t, 1, s.Stats()[0].MaxQueued)
+	})
+
+	t.Run("QueuedRequestGetsFreedSlot", func(t *testing.T) {
+		s := NewLoadShedder(LoadShedConfig{Read: ClassLimit{MaxConcurrent: 1, MaxQueue: 1, MaxWait: time.Second}}, nil)
+		next, started, release := blocking()
+		h := s.Middleware(next)
+
+		done := make(chan int)
+		go func() { done <- serve(h, http.MethodGet, "/wallets/w1/balance").Code }()
+		<-started
+		go func() { done <- serve(h, http.MethodGet, "/wallets/w2/balance").Code }()
+		assert.Eventually(t, func() bool { return s.Stats()[0].Queued == 1 }, time.Second, time.Millisecond)
+
+		close(release)
+		assert.Equal(t, http.StatusOK, <-done)
+		assert.Equal(t, http.StatusOK, <-done)
+		assert.Equal(t, int64(2), s.Stats()[0].Admitted)
+	})
+
+	t.Run("SaturatedPoolShedsReadsOnly", func(t *testing.T) {
+		pool := func() sql.DBStats { return sql.DBStats{MaxOpenConnections: 10, InUse: 9} }
+		s := NewLoadShedder(cfg, pool)
+		ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+		h := s.Middleware(ok)
+
+		assert.Equal(t, http.StatusServiceUnavailable, serve(h, http.MethodGet, "/wallets/w1/balance").Code)
+		assert.Equal(t, http.StatusOK, serve(h, http.MethodPost, "/transfers").Code)
+		assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/admin/load-shedding").Code)
+		assert.Equal(t, int64(1), s.Stats()[0].ShedPoolSaturated)
+	})
+
+	t.Run("CancelledWhileQueuedWritesNothing", func(t *testing.T) {
+		s := NewLoadShedder(LoadShedConfig{Read: ClassLimit{MaxConcurrent: 1, MaxQueue: 1, MaxWait: time.Minute}}, nil)
+		next, started, release := blocking()
+		h := s.Middleware(next)
+		defer close(release)
+
+		go serve(h, http.MethodGet, "/wallets/w1/balance")
+		<-started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wallets/w2/balance", nil).WithContext(ctx))
+		assert.Empty(t, rec.Body.String(), "the timeout middleware answers")
+		assert.Zero(t, s.Stats()[0].Queued)
+	})
+}
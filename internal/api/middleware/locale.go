@@ -0,0 +1,19 @@
+// internal/api/middleware/locale.go
+package middleware
+
+import (
+	"net/http"
+
+	"finflow-wallet/internal/i18n"
+)
+
+// Localize negotiates the locale of error messages from the Accept-Language header and announces it in
+// Content-Language. Error codes are not translated, so clients should match on them, not on messages.
+func Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", locale)
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), locale)))
+	})
+}
@@ -0,0 +1,48 @@
+// internal/api/middleware/locale_test.go
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalize(t *testing.T) {
+	// The admin guard stands in for any middleware or handler writing an error
+	guarded := Localize(RequireAdminToken("", nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	serve := func(acceptLanguage string) (*httptest.ResponseRecorder, map[string]string) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/maintenance", nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		rec := httptest.NewRecorder()
+		guarded.ServeHTTP(rec, req)
+		var body map[string]string
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec, body
+	}
+
+	t.Run("DefaultsToEnglish", func(t *testing.T) {
+		rec, body := serve("")
+		assert.Equal(t, "en", rec.Header().Get("Content-Language"))
+		assert.Equal(t, "Admin API is disabled", body["error"])
+		assert.Equal(t, "admin_disabled", body["code"])
+	})
+
+	t.Run("MessageIsTranslatedAndCodeIsNot", func(t *testing.T) {
+		rec, body := serve("de-AT,de;q=0.9,en;q=0.5")
+		assert.Equal(t, "de", rec.Header().Get("Content-Language"))
+		assert.Equal(t, "Accept-Language", rec.Header().Get("Vary"))
+		assert.Equal(t, "Die Admin-API ist deaktiviert", body["error"])
+		assert.Equal(t, "admin_disabled", body["code"])
+	})
+
+	t.Run("UnsupportedLocaleFallsBack", func(t *testing.T) {
+		rec, body := serve("ja-JP")
+		assert.Equal(t, "en", rec.Header().Get("Content-Language"))
+		assert.Equal(t, "Admin API is disabled", body["error"])
+	})
+}
@@ -0,0 +1,81 @@
+// internal/api/middleware/maintenance.go
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaintenanceState describes the current maintenance mode.
+type MaintenanceState struct {
+	ReadOnly   bool
+	RetryAfter time.Duration // Advertised in the Retry-After header of rejected requests
+	Reason     string
+	Since      *time.Time // When read-only mode was turned on
+}
+
+// MaintenanceSwitch is a runtime read-only switch. While it is on, safe requests (GET, HEAD, OPTIONS)
+// keep working and everything else, i.e. every money-moving endpoint, is answered with
+// 503 Service Unavailable and a Retry-After header. Admin routes are exempt so the switch can be turned off.
+type MaintenanceSwitch struct {
+	mu    sync.RWMutex
+	state MaintenanceState
+}
+
+// NewMaintenanceSwitch creates a switch in the given initial state.
+func NewMaintenanceSwitch(readOnly bool, retryAfter time.Duration) *MaintenanceSwitch {
+	s := &MaintenanceSwitch{state: MaintenanceState{RetryAfter: retryAfter}}
+	if readOnly {
+		s.Set(true, retryAfter, "enabled at startup")
+	}
+	return s
+}
+
+// State returns a snapshot of the current state.
+func (s *MaintenanceSwitch) State() MaintenanceState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+// Set turns read-only mode on or off. A zero retryAfter keeps the previous value.
+func (s *MaintenanceSwitch) Set(readOnly bool, retryAfter time.Duration, reason string) MaintenanceState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if retryAfter > 0 {
+		s.state.RetryAfter = retryAfter
+	}
+	if !readOnly {
+		s.state = MaintenanceState{RetryAfter: s.state.RetryAfter}
+		return s.state
+	}
+	if !s.state.ReadOnly {
+		since := time.Now().UTC()
+		s.state.Since = &since
+	}
+	s.state.ReadOnly = true
+	s.state.Reason = reason
+	return s.state
+}
+
+// Middleware rejects unsafe requests while read-only mode is on.
+func (s *MaintenanceSwitch) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := s.State()
+		if !state.ReadOnly || isSafeMethod(r.Method) || strings.HasPrefix(r.URL.Path, "/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(int(state.RetryAfter.Seconds())))
+		writeError(w, r, http.StatusServiceUnavailable, "read_only_mode")
+	})
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,47 @@
+// internal/api/middleware/maintenance_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceSwitch(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	serve := func(s *MaintenanceSwitch, method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.Middleware(ok).ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	t.Run("OffPassesEverything", func(t *testing.T) {
+		s := NewMaintenanceSwitch(false, time.Minute)
+		assert.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/transfers").Code)
+	})
+
+	t.Run("ReadOnlyBlocksWrites", func(t *testing.T) {
+		s := NewMaintenanceSwitch(false, time.Minute)
+		s.Set(true, 2*time.Minute, "schema migration")
+
+		rec := serve(s, http.MethodPost, "/transfers")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "120", rec.Header().Get("Retry-After"))
+
+		assert.Equal(t, http.StatusOK, serve(s, http.MethodGet, "/wallets/x/balance").Code)
+		assert.Equal(t, http.StatusOK, serve(s, http.MethodPut, "/admin/maintenance").Code)
+	})
+
+	t.Run("TurningOffRestoresWrites", func(t *testing.T) {
+		s := NewMaintenanceSwitch(true, time.Minute)
+		assert.True(t, s.State().ReadOnly)
+		assert.NotNil(t, s.State().Since)
+
+		s.Set(false, 0, "")
+		assert.Equal(t, http.StatusOK, serve(s, http.MethodPost, "/transfers").Code)
+		assert.Equal(t, time.Minute, s.State().RetryAfter)
+	})
+}
@@ -0,0 +1,87 @@
+// internal/api/middleware/mtls.go
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"finflow-wallet/internal/service"
+)
+
+// ClientCertTLSConfig returns the server TLS settings verifying client certificates against the PEM CA
+// certificates. With require, connections without a client certificate are refused during the handshake;
+// otherwise they are served as external callers.
+func ClientCertTLSConfig(caPEM []byte, require bool) (*tls.Config, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("no CA certificates found")
+	}
+	clientAuth := tls.VerifyClientCertIfGiven
+	if require {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientCAs:  pool,
+		ClientAuth: clientAuth,
+	}, nil
+}
+
+// ServiceIdentifier maps the verified client certificates of internal callers to service identities.
+// A certificate names its service by a SPIFFE ID in a URI SAN or, for callers without SPIFFE, a DNS SAN.
+type ServiceIdentifier struct {
+	identities map[string]string
+	logger     *slog.Logger
+}
+
+// NewServiceIdentifier creates a ServiceIdentifier for the given SPIFFE ID or DNS name -> service name map.
+func NewServiceIdentifier(identities map[string]string, logger *slog.Logger) *ServiceIdentifier {
+	return &ServiceIdentifier{identities: identities, logger: logger}
+}
+
+// Identify returns the service named by the leaf of the verified client certificate chain, SPIFFE IDs
+// first. It returns "" without a verified certificate, and false with one naming no known service.
+func (s *ServiceIdentifier) Identify(state *tls.ConnectionState) (string, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", true
+	}
+	leaf := state.VerifiedChains[0][0]
+	for _, uri := range leaf.URIs {
+		if uri.Scheme != "spiffe" {
+			continue
+		}
+		if name, ok := s.identities[uri.String()]; ok {
+			return name, true
+		}
+	}
+	for _, dnsName := range leaf.DNSNames {
+		if name, ok := s.identities[dnsName]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Middleware attaches the service identity of requests with a verified client certificate to their
+// context. A certificate the CA vouches for but that names no known service is refused, so a new service
+// cannot act before it is given a name.
+func (s *ServiceIdentifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := s.Identify(r.TLS)
+		if !ok {
+			leaf := r.TLS.VerifiedChains[0][0]
+			s.logger.Warn("Client certificate names no known service", "subject", leaf.Subject.String(),
+				"uris", leaf.URIs, "dns_names", leaf.DNSNames)
+			writeError(w, r, http.StatusForbidden, "unknown_service_identity")
+			return
+		}
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(service.WithServiceIdentity(r.Context(), name)))
+	})
+}
@@ -0,0 +1,168 @@
+// internal/api/middleware/mtls_test.go
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/service"
+)
+
+// testCA issues certificates for the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "finflow internal CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a client certificate with the SANs.
+func (ca *testCA) issue(t *testing.T, uris []string, dnsNames []string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		DNSNames:     dnsNames,
+	}
+	for _, raw := range uris {
+		uri, err := url.Parse(raw)
+		require.NoError(t, err)
+		template.URIs = append(template.URIs, uri)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestServiceIdentifier(t *testing.T) {
+	ca := newTestCA(t)
+	identifier := NewServiceIdentifier(map[string]string{
+		"spiffe://finflow.internal/ns/payments/sa/ledger": "ledger",
+		"reports.finflow.internal":                        "reports",
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var identity string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity = service.ServiceIdentityFromContext(r.Context())
+	})
+	serve := func(t *testing.T, requireCert bool) *httptest.Server {
+		tlsConfig, err := ClientCertTLSConfig(ca.pem, requireCert)
+		require.NoError(t, err)
+		server := httptest.NewUnstartedServer(identifier.Middleware(next))
+		server.TLS = tlsConfig
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		return server
+	}
+	call := func(server *httptest.Server, certs ...tls.Certificate) (*http.Response, error) {
+		identity = ""
+		client := server.Client()
+		client.Transport.(*http.Transport).TLSClientConfig.Certificates = certs
+		return client.Get(server.URL)
+	}
+
+	t.Run("SPIFFEIDNamesService", func(t *testing.T) {
+		server := serve(t, false)
+		cert := ca.issue(t, []string{"spiffe://finflow.internal/ns/payments/sa/ledger"}, []string{"reports.finflow.internal"})
+
+		resp, err := call(server, cert)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "ledger", identity)
+	})
+
+	t.Run("DNSNameNamesService", func(t *testing.T) {
+		server := serve(t, false)
+
+		resp, err := call(server, ca.issue(t, nil, []string{"reports.finflow.internal"}))
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, "reports", identity)
+	})
+
+	t.Run("UnknownServiceIsForbidden", func(t *testing.T) {
+		server := serve(t, false)
+
+		resp, err := call(server, ca.issue(t, []string{"spiffe://finflow.internal/ns/payments/sa/other"}, nil))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Contains(t, string(body), "unknown_service_identity")
+	})
+
+	t.Run("ExternalCallerWithoutCertificate", func(t *testing.T) {
+		server := serve(t, false)
+
+		resp, err := call(server)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, identity)
+	})
+
+	t.Run("RequiredCertificateRefusesConnection", func(t *testing.T) {
+		server := serve(t, true)
+
+		_, err := call(server)
+		assert.Error(t, err)
+	})
+
+	t.Run("CertificateOfAnotherCARefusesConnection", func(t *testing.T) {
+		server := serve(t, false)
+
+		_, err := call(server, newTestCA(t).issue(t, []string{"spiffe://finflow.internal/ns/payments/sa/ledger"}, nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidCAPEM", func(t *testing.T) {
+		_, err := ClientCertTLSConfig([]byte("not a certificate"), false)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,102 @@
+// internal/api/middleware/open_banking_test.go
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signOpenBankingToken signs claims the way the authorization server does.
+func signOpenBankingToken(t *testing.T, key []byte, alg string, claims map[string]any) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestRequireOpenBankingToken(t *testing.T) {
+	key := []byte("shared-secret")
+	consentID := uuid.New()
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss":        "https://auth.example.com",
+			"sub":        "tpp-1",
+			"scope":      "openid accounts",
+			"consent_id": consentID.String(),
+			"exp":        time.Now().Add(time.Minute).Unix(),
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+	var token *OpenBankingToken
+	guarded := RequireOpenBankingToken(key, "https://auth.example.com")(RequireScope("accounts")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = OpenBankingTokenFromContext(r.Context())
+	})))
+	serve := func(handler http.Handler, bearer string) *httptest.ResponseRecorder {
+		token = nil
+		req := httptest.NewRequest(http.MethodGet, "/open-banking/v3.1/aisp/accounts", nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("ValidTokenCarriesClaims", func(t *testing.T) {
+		rec := serve(guarded, signOpenBankingToken(t, key, "HS256", claims(nil)))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, token)
+		assert.Equal(t, "tpp-1", token.ClientID)
+		assert.Equal(t, consentID, token.ConsentID)
+	})
+
+	t.Run("InvalidTokensRejected", func(t *testing.T) {
+		for name, bearer := range map[string]string{
+			"Missing":        "",
+			"Malformed":      "not-a-jwt",
+			"WrongKey":       signOpenBankingToken(t, []byte("other"), "HS256", claims(nil)),
+			"WrongIssuer":    signOpenBankingToken(t, key, "HS256", claims(map[string]any{"iss": "https://evil.example.com"})),
+			"Expired":        signOpenBankingToken(t, key, "HS256", claims(map[string]any{"exp": time.Now().Add(-time.Second).Unix()})),
+			"NoConsent":      signOpenBankingToken(t, key, "HS256", claims(map[string]any{"consent_id": ""})),
+			"OtherAlgorithm": signOpenBankingToken(t, key, "HS512", claims(nil)),
+		} {
+			rec := serve(guarded, bearer)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+			assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "invalid_token", name)
+		}
+		assert.Nil(t, token)
+	})
+
+	t.Run("ScopeRequired", func(t *testing.T) {
+		rec := serve(guarded, signOpenBankingToken(t, key, "HS256", claims(map[string]any{"scope": "openid payments"})))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "insufficient_scope")
+		assert.Nil(t, token)
+	})
+
+	t.Run("DisabledWithoutKey", func(t *testing.T) {
+		disabled := RequireOpenBankingToken(nil, "")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		assert.Equal(t, http.StatusForbidden, serve(disabled, signOpenBankingToken(t, key, "HS256", claims(nil))).Code)
+	})
+}
@@ -0,0 +1,76 @@
+// internal/api/middleware/replay.go
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"finflow-wallet/pkg/redis"
+)
+
+// ReplayCache remembers the nonces of signed requests for as long as their timestamps are acceptable, so
+// that a replayed request is refused.
+type ReplayCache interface {
+	// Claim records the key for ttl and reports false if it is recorded already.
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// MemoryReplayCache is a ReplayCache of one process. Behind several replicas, a request replayed to
+// another replica than the original is not detected; use a RedisReplayCache there.
+type MemoryReplayCache struct {
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	pruned time.Time
+	now    func() time.Time
+}
+
+// NewMemoryReplayCache creates an empty MemoryReplayCache.
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{seen: make(map[string]time.Time), now: time.Now}
+}
+
+// Claim implements ReplayCache. Expired keys are pruned at most once a minute.
+func (c *MemoryReplayCache) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.Sub(c.pruned) > time.Minute {
+		for k, exp := range c.seen {
+			if now.After(exp) {
+				delete(c.seen, k)
+			}
+		}
+		c.pruned = now
+	}
+
+	if exp, ok := c.seen[key]; ok && now.Before(exp) {
+		return false, nil
+	}
+	c.seen[key] = now.Add(ttl)
+	return true, nil
+}
+
+// RedisReplayCache is a ReplayCache shared by the replicas of a deployment through Redis: a key is claimed
+// with SET NX, so exactly one replica claims it, and expires with the key's TTL.
+type RedisReplayCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisReplayCache creates a RedisReplayCache whose keys start with prefix, so that deployments can
+// share a Redis.
+func NewRedisReplayCache(client *redis.Client, prefix string) *RedisReplayCache {
+	return &RedisReplayCache{client: client, prefix: prefix}
+}
+
+// Claim implements ReplayCache.
+func (c *RedisReplayCache) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	claimed, err := c.client.SetNX(ctx, c.prefix+"replay:"+key, "1", ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim nonce: %w", err)
+	}
+	return claimed, nil
+}
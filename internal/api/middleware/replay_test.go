@@ -0,0 +1,125 @@
+// internal/api/middleware/replay_test.go
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/pkg/redis"
+)
+
+func TestMemoryReplayCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	cache := NewMemoryReplayCache()
+	cache.now = func() time.Time { return now }
+
+	first, err := cache.Claim(ctx, "acme:n-1", time.Minute)
+	require.NoError(t, err)
+	again, err := cache.Claim(ctx, "acme:n-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, first)
+	assert.False(t, again)
+
+	now = now.Add(2 * time.Minute)
+	expired, err := cache.Claim(ctx, "acme:n-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, expired, "a key can be claimed again once it expired")
+}
+
+// serveSetNX answers SET NX commands over RESP, remembering the keys set and the commands received.
+func serveSetNX(t *testing.T) (addr string, commands func() []string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	var mu sync.Mutex
+	keys := map[string]bool{}
+	var received []string
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					var n int
+					if _, err := fmt.Fscanf(reader, "*%d\r\n", &n); err != nil {
+						return
+					}
+					args := make([]string, n)
+					for i := range args {
+						var size int
+						if _, err := fmt.Fscanf(reader, "$%d\r\n", &size); err != nil {
+							return
+						}
+						buf := make([]byte, size+2)
+						if _, err := io.ReadFull(reader, buf); err != nil {
+							return
+						}
+						args[i] = string(buf[:size])
+					}
+					mu.Lock()
+					received = append(received, strings.Join(args, " "))
+					answer := "+OK\r\n"
+					if keys[args[1]] {
+						answer = "$-1\r\n"
+					}
+					keys[args[1]] = true
+					mu.Unlock()
+					_, _ = conn.Write([]byte(answer))
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), received...)
+	}
+}
+
+func TestRedisReplayCache(t *testing.T) {
+	ctx := context.Background()
+	addr, commands := serveSetNX(t)
+	client, err := redis.Open("redis://"+addr, time.Second)
+	require.NoError(t, err)
+	defer client.Close()
+	// Two replicas sharing the Redis
+	replicaA := NewRedisReplayCache(client, "finflow:")
+	replicaB := NewRedisReplayCache(client, "finflow:")
+
+	first, err := replicaA.Claim(ctx, "acme:n-1", 10*time.Minute)
+	require.NoError(t, err)
+	replay, err := replicaB.Claim(ctx, "acme:n-1", 10*time.Minute)
+	require.NoError(t, err)
+
+	assert.True(t, first)
+	assert.False(t, replay)
+	assert.Equal(t, "SET finflow:replay:acme:n-1 1 NX PX 600000", commands()[0])
+}
+
+func TestRedisReplayCacheUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	client, err := redis.Open("redis://"+addr, time.Second)
+	require.NoError(t, err)
+
+	_, err = NewRedisReplayCache(client, "finflow:").Claim(context.Background(), "acme:n-1", time.Minute)
+	assert.ErrorContains(t, err, "failed to claim nonce")
+}
@@ -0,0 +1,44 @@
+// internal/api/middleware/scim.go
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// scimTenantKey is the context key of the authenticated SCIM tenant's name.
+type scimTenantKey struct{}
+
+// RequireSCIMToken guards the SCIM routes with the bearer tokens of enterprise tenants (name -> token). The
+// tenant whose token authenticated the request is available through SCIMTenantFromContext. With no tenants
+// configured, the SCIM API is disabled.
+func RequireSCIMToken(tenants map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(tenants) == 0 {
+				writeError(w, r, http.StatusForbidden, "scim_disabled")
+				return
+			}
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if ok {
+				for name, token := range tenants {
+					if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1 {
+						next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), scimTenantKey{}, name)))
+						return
+					}
+				}
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+			writeError(w, r, http.StatusUnauthorized, "invalid_scim_token")
+		})
+	}
+}
+
+// SCIMTenantFromContext returns the name of the tenant whose token authenticated the request, or "" outside
+// the SCIM routes.
+func SCIMTenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(scimTenantKey{}).(string)
+	return tenant
+}
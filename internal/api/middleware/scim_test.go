@@ -0,0 +1,49 @@
+// internal/api/middleware/scim_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireSCIMToken(t *testing.T) {
+	var tenant string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = SCIMTenantFromContext(r.Context())
+	})
+	serve := func(tenants map[string]string, authorization string) *httptest.ResponseRecorder {
+		tenant = ""
+		req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		RequireSCIMToken(tenants)(next).ServeHTTP(rec, req)
+		return rec
+	}
+	tenants := map[string]string{"acme": "acme-token", "globex": "globex-token"}
+
+	t.Run("TenantOfToken", func(t *testing.T) {
+		rec := serve(tenants, "Bearer globex-token")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "globex", tenant)
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer other", "Basic YWNtZTphY21lLXRva2Vu", "acme-token"} {
+			rec := serve(tenants, authorization)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, authorization)
+			assert.Contains(t, rec.Body.String(), "invalid_scim_token")
+			assert.Empty(t, tenant)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		rec := serve(nil, "Bearer acme-token")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "scim_disabled")
+	})
+}
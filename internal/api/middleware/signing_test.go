@@ -0,0 +1,173 @@
+// internal/api/middleware/signing_test.go
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingReplayCache is a ReplayCache whose store is unreachable.
+type failingReplayCache struct{}
+
+func (failingReplayCache) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestRequestSigner(t *testing.T) {
+	secret := "s3cret"
+	now := time.Unix(1_700_000_000, 0)
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	})
+
+	newReplica := func(replays ReplayCache) http.Handler {
+		signer := NewRequestSigner(map[string]string{"acme": secret}, 5*time.Minute, replays, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		signer.now = func() time.Time { return now }
+		return signer.Middleware(echo)
+	}
+	newHandler := func() http.Handler { return newReplica(nil) }
+	signedRequest := func(body, nonce string, at time.Time) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/transfers?dry_run=1", strings.NewReader(body))
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		req.Header.Set(HeaderPartnerID, "acme")
+		req.Header.Set(HeaderSignatureTimestamp, timestamp)
+		req.Header.Set(HeaderSignatureNonce, nonce)
+		req.Header.Set(HeaderSignature, SignRequest([]byte(secret), req.Method, req.URL.RequestURI(), timestamp, nonce, []byte(body)))
+		return req
+	}
+
+	t.Run("ValidRequestIsSignedBack", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler().ServeHTTP(rec, signedRequest(`{"amount":"1"}`, "n-1", now))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `{"amount":"1"}`, rec.Body.String())
+		timestamp := rec.Header().Get(HeaderSignatureTimestamp)
+		assert.Equal(t, SignResponse([]byte(secret), http.StatusCreated, timestamp, "n-1", rec.Body.Bytes()), rec.Header().Get(HeaderSignature))
+	})
+
+	t.Run("TamperedBodyRejected", func(t *testing.T) {
+		req := signedRequest(`{"amount":"1"}`, "n-2", now)
+		req.Body = io.NopCloser(strings.NewReader(`{"amount":"1000"}`))
+		rec := httptest.NewRecorder()
+		newHandler().ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Invalid request signature")
+	})
+
+	t.Run("ReplayedNonceRejected", func(t *testing.T) {
+		handler := newHandler()
+		first := httptest.NewRecorder()
+		handler.ServeHTTP(first, signedRequest(`{}`, "n-3", now))
+		replay := httptest.NewRecorder()
+		handler.ServeHTTP(replay, signedRequest(`{}`, "n-3", now))
+
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusUnauthorized, replay.Code)
+		assert.Contains(t, replay.Body.String(), "Request already processed")
+	})
+
+	t.Run("NonceReplayedToAnotherReplicaRejected", func(t *testing.T) {
+		shared := NewMemoryReplayCache()
+		first := httptest.NewRecorder()
+		newReplica(shared).ServeHTTP(first, signedRequest(`{}`, "n-7", now))
+		replay := httptest.NewRecorder()
+		newReplica(shared).ServeHTTP(replay, signedRequest(`{}`, "n-7", now))
+
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Equal(t, http.StatusUnauthorized, replay.Code)
+		assert.Contains(t, replay.Body.String(), "replayed_request")
+	})
+
+	t.Run("UnavailableReplayCacheRefusesRequest", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newReplica(failingReplayCache{}).ServeHTTP(rec, signedRequest(`{}`, "n-8", now))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Contains(t, rec.Body.String(), "replay_check_unavailable")
+	})
+
+	t.Run("StaleTimestampRejected", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler().ServeHTTP(rec, signedRequest(`{}`, "n-4", now.Add(-10*time.Minute)))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Request signature expired")
+	})
+
+	t.Run("UnknownPartnerRejected", func(t *testing.T) {
+		req := signedRequest(`{}`, "n-5", now)
+		req.Header.Set(HeaderPartnerID, "globex")
+		rec := httptest.NewRecorder()
+		newHandler().ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("UnsignedRequestPassesThrough", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderSignature))
+	})
+
+	t.Run("RequirePartnerAcceptsOnlySignedRequests", func(t *testing.T) {
+		signer := NewRequestSigner(map[string]string{"acme": secret}, 5*time.Minute, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		signer.now = func() time.Time { return now }
+		var partnerID string
+		handler := signer.Middleware(RequirePartner(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			partnerID = PartnerFromContext(r.Context())
+		})))
+
+		signed := httptest.NewRecorder()
+		handler.ServeHTTP(signed, signedRequest(`{}`, "n-6", now))
+		unsigned := httptest.NewRecorder()
+		handler.ServeHTTP(unsigned, httptest.NewRequest(http.MethodPost, "/webhooks/inbound-funds", nil))
+
+		assert.Equal(t, http.StatusOK, signed.Code)
+		assert.Equal(t, "acme", partnerID)
+		assert.Equal(t, http.StatusUnauthorized, unsigned.Code)
+		assert.Contains(t, unsigned.Body.String(), "partner_signature_required")
+	})
+
+	t.Run("RequirePaymentProviderRejectsOtherPartners", func(t *testing.T) {
+		signer := NewRequestSigner(map[string]string{"acme": secret}, 5*time.Minute, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		signer.now = func() time.Time { return now }
+		called := false
+		handler := func(providers []string) http.Handler {
+			return signer.Middleware(RequirePaymentProvider(providers)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})))
+		}
+
+		provider := httptest.NewRecorder()
+		handler([]string{"acme"}).ServeHTTP(provider, signedRequest(`{}`, "n-9", now))
+		assert.Equal(t, http.StatusOK, provider.Code)
+		assert.True(t, called)
+
+		called = false
+		other := httptest.NewRecorder()
+		handler([]string{"globex"}).ServeHTTP(other, signedRequest(`{}`, "n-10", now))
+		assert.Equal(t, http.StatusForbidden, other.Code)
+		assert.Contains(t, other.Body.String(), "payment_provider_required")
+		assert.False(t, called)
+
+		unsigned := httptest.NewRecorder()
+		handler([]string{"acme"}).ServeHTTP(unsigned, httptest.NewRequest(http.MethodPost, "/webhooks/inbound-funds", nil))
+		assert.Equal(t, http.StatusUnauthorized, unsigned.Code)
+	})
+}
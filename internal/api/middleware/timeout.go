@@ -0,0 +1,113 @@
+// internal/api/middleware/timeout.go
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"time"
+
+	"finflow-wallet/internal/i18n"
+)
+
+// RequestTimeoutHeader lets a caller bound the time the server spends on its request, e.g. "800ms".
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// Timeout cancels the context of requests running longer than maxTimeout, and so the service and database
+// work done under it. A request may ask for less with X-Request-Timeout, but not for more. The long-polling
+// routes matching one of the path.Match patterns in longPolls bound their own wait, and are only bounded by
+// the header. A request that fails once its timeout has passed is answered with 504 Gateway Timeout and code
+// request_timeout instead of the error it failed with.
+func Timeout(maxTimeout time.Duration, longPolls ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := maxTimeout
+			for _, pattern := range longPolls {
+				if matched, _ := path.Match(pattern, r.URL.Path); matched {
+					timeout = 0
+					break
+				}
+			}
+			if raw := r.Header.Get(RequestTimeoutHeader); raw != "" {
+				requested, err := time.ParseDuration(raw)
+				if err != nil || requested <= 0 {
+					writeJSON(w, http.StatusBadRequest, map[string]string{
+						"error": i18n.Message(r.Context(), "invalid_input", map[string]string{"detail": RequestTimeoutHeader + " must be a positive duration, e.g. 800ms"}),
+						"code":  "invalid_input",
+					})
+					return
+				}
+				if timeout == 0 || requested < timeout {
+					timeout = requested
+				}
+			}
+			if timeout == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			tw := &timeoutWriter{ResponseWriter: w, r: r.WithContext(ctx), timeout: timeout}
+			next.ServeHTTP(tw, tw.r)
+			if !tw.wroteHeader && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				tw.writeTimeout()
+			}
+		})
+	}
+}
+
+// timeoutWriter replaces the server error of a request that ran out of time with a 504.
+type timeoutWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	timeout     time.Duration
+	wroteHeader bool
+	timedOut    bool // The 504 was sent; the handler's response is dropped
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	// Errors of work cancelled by the deadline surface as whatever the failing layer made of them
+	if status >= http.StatusInternalServerError && errors.Is(tw.r.Context().Err(), context.DeadlineExceeded) {
+		tw.writeTimeout()
+		return
+	}
+	tw.ResponseWriter.WriteHeader(status)
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.timedOut {
+		return len(p), nil
+	}
+	return tw.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter { return tw.ResponseWriter }
+
+// writeTimeout sends the 504 response.
+func (tw *timeoutWriter) writeTimeout() {
+	tw.wroteHeader, tw.timedOut = true, true
+	timeout := tw.timeout.String()
+	writeJSON(tw.ResponseWriter, http.StatusGatewayTimeout, map[string]string{
+		"error":   i18n.Message(tw.r.Context(), "request_timeout", map[string]string{"timeout": timeout}),
+		"code":    "request_timeout",
+		"timeout": timeout,
+	})
+}
+
+// writeJSON writes an error body the way writeError does, for errors with message parameters.
+func writeJSON(w http.ResponseWriter, status int, body map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeout(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+	handler := Timeout(time.Second, "/transactions/*/wait")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, hasDeadline = r.Context().Deadline()
+	}))
+	serve := func(path, requestTimeout string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if requestTimeout != "" {
+			req.Header.Set(RequestTimeoutHeader, requestTimeout)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("DefaultTimeout", func(t *testing.T) {
+		serve("/transactions/abc", "")
+		assert.True(t, hasDeadline)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+	})
+
+	t.Run("HeaderShortensTheTimeout", func(t *testing.T) {
+		serve("/transactions/abc", "200ms")
+		assert.True(t, hasDeadline)
+		assert.WithinDuration(t, time.Now().Add(200*time.Millisecond), deadline, 100*time.Millisecond)
+	})
+
+	t.Run("HeaderCannotExtendTheTimeout", func(t *testing.T) {
+		serve("/transactions/abc", "1h")
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+	})
+
+	t.Run("LongPollsAreOnlyBoundedByTheHeader", func(t *testing.T) {
+		serve("/transactions/abc/wait?timeout=30s", "")
+		assert.False(t, hasDeadline)
+
+		serve("/transactions/abc/wait?timeout=30s", "10s")
+		assert.True(t, hasDeadline)
+		assert.WithinDuration(t, time.Now().Add(10*time.Second), deadline, 100*time.Millisecond)
+	})
+
+	t.Run("InvalidHeaderIsRejected", func(t *testing.T) {
+		for _, value := range []string{"soon", "0s", "-1s"} {
+			rec := serve("/transactions/abc", value)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, value)
+		}
+	})
+}
+
+func TestTimeoutExceeded(t *testing.T) {
+	failing := Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":"Internal server error","code":"internal_error"}`))
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/transfers", nil)
+	req.Header.Set(RequestTimeoutHeader, "20ms")
+	rec := httptest.NewRecorder()
+
+	failing.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "request_timeout", body["code"])
+	assert.Equal(t, "20ms", body["timeout"])
+	assert.Contains(t, body["error"], "20ms")
+
+	t.Run("SilentHandlerGetsTheTimeout", func(t *testing.T) {
+		silent := Timeout(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		rec := httptest.NewRecorder()
+		silent.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wallets", nil))
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	})
+
+	t.Run("ClientErrorsAreKept", func(t *testing.T) {
+		rejecting := Timeout(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			w.WriteHeader(http.StatusPaymentRequired)
+		}))
+		rec := httptest.NewRecorder()
+		rejecting.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wallets", nil).WithContext(context.Background()))
+		assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+	})
+}
@@ -0,0 +1,55 @@
+// internal/api/middleware/usage.go
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// UsageMeter counts the requests of partners against their monthly quotas. It must run after the
+// RequestSigner, as it meters the partner whose signature the request carried; unsigned requests are passed
+// through. Once a partner is over its request quota, its requests are refused with 429 Too Many Requests
+// until the month ends. The partner is attached to the request's context, so the money it moves counts
+// against its volume quota.
+type UsageMeter struct {
+	usage  service.UsageService
+	now    func() time.Time
+	logger *slog.Logger
+}
+
+// NewUsageMeter creates a UsageMeter.
+func NewUsageMeter(usage service.UsageService, logger *slog.Logger) *UsageMeter {
+	return &UsageMeter{usage: usage, now: time.Now, logger: logger}
+}
+
+// Middleware meters partner requests and refuses those over the quota.
+func (m *UsageMeter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		partnerID := PartnerFromContext(r.Context())
+		if partnerID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		now := m.now()
+		err := m.usage.CountRequest(r.Context(), partnerID, now)
+		if errors.Is(err, util.ErrRequestQuotaExceeded) {
+			m.logger.Warn("Refused partner request over quota", "partner_id", partnerID, "path", r.URL.Path)
+			resetAt := domain.UsageMonth(now).AddDate(0, 1, 0)
+			w.Header().Set("Retry-After", strconv.Itoa(int(resetAt.Sub(now).Seconds())+1))
+			writeError(w, r, http.StatusTooManyRequests, "request_quota_exceeded")
+			return
+		}
+		if err != nil {
+			// Metering must not take the API down with it; the request is served uncounted
+			m.logger.Error("Failed to count partner request", "partner_id", partnerID, "error", err)
+		}
+		next.ServeHTTP(w, r.WithContext(service.WithPartner(r.Context(), partnerID)))
+	})
+}
@@ -0,0 +1,96 @@
+// internal/api/middleware/usage_test.go
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/internal/service"
+	"finflow-wallet/internal/util"
+)
+
+// fakeUsage counts requests in memory against a fixed quota.
+type fakeUsage struct {
+	service.UsageService
+	quota    int64
+	requests map[string]int64
+	err      error
+}
+
+func (f *fakeUsage) CountRequest(ctx context.Context, partnerID string, now time.Time) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.requests[partnerID]++
+	if f.requests[partnerID] > f.quota {
+		return util.ErrRequestQuotaExceeded
+	}
+	return nil
+}
+
+func TestUsageMeter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var seen string
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = service.PartnerFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	newMeter := func(usage *fakeUsage) *UsageMeter {
+		meter := NewUsageMeter(usage, logger)
+		meter.now = func() time.Time { return time.Date(2026, 10, 31, 23, 59, 0, 0, time.UTC) }
+		return meter
+	}
+	serve := func(meter *UsageMeter, partnerID string) *httptest.ResponseRecorder {
+		seen = ""
+		req := httptest.NewRequest(http.MethodGet, "/wallets/abc/balance", nil)
+		if partnerID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), partnerKey{}, partnerID))
+		}
+		rec := httptest.NewRecorder()
+		meter.Middleware(ok).ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("RefusedOverQuotaUntilMonthEnds", func(t *testing.T) {
+		usage := &fakeUsage{quota: 2, requests: map[string]int64{}}
+		meter := newMeter(usage)
+
+		for i := 0; i < 2; i++ {
+			rec := serve(meter, "acme")
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "acme", seen)
+		}
+		rec := serve(meter, "acme")
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"request_quota_exceeded"`)
+		assert.Equal(t, "61", rec.Header().Get("Retry-After"))
+		assert.Empty(t, seen)
+	})
+
+	t.Run("UnsignedRequestsAreNotMetered", func(t *testing.T) {
+		usage := &fakeUsage{quota: 0, requests: map[string]int64{}}
+
+		rec := serve(newMeter(usage), "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, usage.requests)
+	})
+
+	t.Run("MeteringFailureServesRequest", func(t *testing.T) {
+		usage := &fakeUsage{err: errors.New("database is down")}
+
+		rec := serve(newMeter(usage), "acme")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "acme", seen)
+	})
+}
@@ -9,18 +9,88 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 
 	"finflow-wallet/internal/api/handler"
+	apimiddleware "finflow-wallet/internal/api/middleware"
 )
 
+// Handlers groups the HTTP handlers mounted by the router.
+type Handlers struct {
+	Wallet        *handler.WalletHandler
+	Admin         *handler.AdminHandler
+	FX            *handler.FXHandler
+	Sweep         *handler.SweepRuleHandler
+	Joint         *handler.JointWalletHandler
+	Budget        *handler.BudgetHandler
+	Merchant      *handler.MerchantHandler
+	Bill          *handler.BillHandler
+	Voucher       *handler.VoucherHandler
+	Promo         *handler.PromoCreditHandler
+	Change        *handler.ChangeHandler
+	Deletion      *handler.DeletionHandler
+	Dormancy      *handler.DormancyHandler
+	Impersonation *handler.ImpersonationHandler
+	Annotation    *handler.AnnotationHandler
+	WalletExport  *handler.WalletExportHandler
+	TransferQuote *handler.TransferQuoteHandler
+	AutoTopUp     *handler.AutoTopUpHandler
+	Crypto        *handler.CryptoHandler
+	Ramp          *handler.RampHandler
+	OpenBanking   *handler.OpenBankingHandler
+	Identity      *handler.IdentityHandler
+	SCIM          *handler.SCIMHandler
+	Netting       *handler.NettingHandler
+	Journal       *handler.JournalHandler
+	Report        *handler.ReportHandler
+	Suspense      *handler.SuspenseHandler
+	Adjustment    *handler.AdjustmentHandler
+	Ownership     *handler.OwnershipTransferHandler
+	Screening     *handler.ScreeningHandler
+	Restriction   *handler.CurrencyRestrictionHandler
+	Security      *handler.SecurityHandler
+	PIN           *handler.PINHandler
+	Verification  *handler.ContactVerificationHandler
+	Notification  *handler.NotificationHandler
+	Usage         *handler.UsageHandler
+	Serialization *handler.WalletSerializationHandler
+	BalanceShards *handler.BalanceShardHandler
+	Migration     *handler.MigrationHandler
+	Retention     *handler.RetentionHandler
+	Backup        *handler.BackupVerificationHandler
+	Investigation *handler.InvestigationHandler
+	TxVerify      *handler.TransactionVerificationHandler
+	Audit         *handler.AuditHandler
+	Clock         *handler.ClockHandler // Only in sandbox mode; its routes are not registered otherwise
+}
+
+// Options holds router-level settings.
+type Options struct {
+	AdminToken  string                            // Shared bearer token for /admin routes
+	AdminUsers  map[string]string                 // Admin name -> personal bearer token; with no token either, /admin is disabled
+	OpenBanking OpenBankingOptions                // Access tokens of /open-banking routes
+	SCIMTokens  map[string]string                 // Tenant name -> bearer token of /scim routes; with none, /scim is disabled
+	Providers   []string                          // Partner IDs of payment providers; with none, the inbound funds webhook rejects every request
+	Middlewares []func(http.Handler) http.Handler // Applied after the global middlewares, in order
+}
+
+// OpenBankingOptions holds the verification settings of Open Banking access tokens.
+type OpenBankingOptions struct {
+	TokenKey    []byte // HMAC key of the HS256 access tokens; with none, the Open Banking API is disabled
+	TokenIssuer string // Required "iss" of the access tokens
+}
+
 // NewRouter sets up and returns a new HTTP router.
-func NewRouter(walletHandler *handler.WalletHandler, logger *slog.Logger) http.Handler {
+func NewRouter(handlers Handlers, opts Options, logger *slog.Logger) http.Handler {
+	walletHandler := handlers.Wallet
 	r := chi.NewRouter()
 
 	// Global middlewares
-	r.Use(middleware.RequestID)                       // Add a request ID to the context
-	r.Use(middleware.RealIP)                          // Use the real IP address
-	r.Use(middleware.Logger)                          // Log HTTP requests
-	r.Use(middleware.Recoverer)                       // Recover from panics and return 500
-	r.Use(middleware.Timeout(handler.DefaultTimeout)) // Set a default timeout for requests (define DefaultTimeout in handler)
+	r.Use(middleware.RequestID)                                                  // Add a request ID to the context
+	r.Use(middleware.RealIP)                                                     // Use the real IP address
+	r.Use(middleware.Logger)                                                     // Log HTTP requests
+	r.Use(middleware.Recoverer)                                                  // Recover from panics and return 500
+	r.Use(apimiddleware.Localize)                                                // Negotiate the locale of error messages
+	r.Use(apimiddleware.Timeout(handler.DefaultTimeout, "/transactions/*/wait")) // Bound requests by the default or X-Request-Timeout
+	r.Use(apimiddleware.NoStoreMutations)                                        // Never cache what a mutation returns
+	r.Use(opts.Middlewares...)
 
 	// Health check endpoint
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -29,15 +99,314 @@ func NewRouter(walletHandler *handler.WalletHandler, logger *slog.Logger) http.H
 	})
 
 	// Wallet API routes
+	// User API routes
+	r.Get("/users", walletHandler.ListUsers)
+	r.Post("/users", walletHandler.CreateUser)
+	r.Get("/users/{userID}", walletHandler.GetUser)
+	r.Patch("/users/{userID}", walletHandler.UpdateUser)
+	r.Delete("/users/{userID}", handlers.Deletion.DeleteUser)
+	r.Get("/users/{userID}/sweep-rules", handlers.Sweep.ListSweepRules)
+	r.Post("/users/{userID}/sweep-rules", handlers.Sweep.CreateSweepRule)
+	r.Delete("/users/{userID}/sweep-rules/{ruleID}", handlers.Sweep.DeleteSweepRule)
+	r.Post("/users/{userID}/security/events", handlers.Security.RecordAuthEvent)
+	r.Get("/users/{userID}/security/activity", handlers.Security.GetSecurityActivity)
+	r.Get("/users/{userID}/pin", handlers.PIN.GetPIN)
+	r.Put("/users/{userID}/pin", handlers.PIN.SetPIN)
+	r.Delete("/users/{userID}/pin", handlers.PIN.DeletePIN)
+	r.Post("/users/{userID}/pin/verify", handlers.PIN.VerifyPIN)
+	r.Post("/users/{userID}/contact/{channel}/verification", handlers.Verification.IssueVerification)
+	r.Post("/users/{userID}/contact/{channel}/verification/confirm", handlers.Verification.ConfirmVerification)
+	r.Post("/users/{userID}/devices", handlers.Notification.RegisterDevice)
+	r.Get("/users/{userID}/devices", handlers.Notification.ListDevices)
+	r.Delete("/users/{userID}/devices/{deviceID}", handlers.Notification.DeleteDevice)
+	r.Get("/users/{userID}/notification-preferences", handlers.Notification.GetPreferences)
+	r.Put("/users/{userID}/notification-preferences", handlers.Notification.UpdatePreferences)
+	r.Get("/users/{userID}/notifications", handlers.Notification.ListNotifications)
+	r.Post("/users/{userID}/notifications/read", handlers.Notification.MarkAllNotificationsRead)
+	r.Post("/users/{userID}/notifications/{notificationID}/read", handlers.Notification.MarkNotificationRead)
+	r.Get("/users/{userID}/notification-deliveries", handlers.Notification.ListDeliveries)
+	r.Get("/users/{userID}/identities", handlers.Identity.ListIdentities)
+	r.Post("/users/{userID}/identities", handlers.Identity.LinkIdentity)
+	r.Delete("/users/{userID}/identities/{provider}", handlers.Identity.UnlinkIdentity)
+	// Account access consents third parties asked the user for, answered by the user
+	r.Get("/users/{userID}/open-banking/consents", handlers.OpenBanking.ListUserConsents)
+	r.Post("/users/{userID}/open-banking/consents/{consentID}/authorise", handlers.OpenBanking.AuthoriseConsent)
+	r.Post("/users/{userID}/open-banking/consents/{consentID}/reject", handlers.OpenBanking.RejectConsent)
+	r.Delete("/users/{userID}/open-banking/consents/{consentID}", handlers.OpenBanking.RevokeConsent)
+	// Transfers of wallets to the user requested under /admin, answered by the user
+	r.Get("/users/{userID}/wallet-transfers", handlers.Ownership.ListIncomingOwnershipTransfers)
+	r.Post("/users/{userID}/wallet-transfers/{transferID}/accept", handlers.Ownership.AcceptOwnershipTransfer)
+	r.Post("/users/{userID}/wallet-transfers/{transferID}/decline", handlers.Ownership.DeclineOwnershipTransfer)
+
 	r.Route("/wallets", func(r chi.Router) {
+		r.Get("/", walletHandler.ListWallets)
+		r.Get("/{walletID}", walletHandler.GetWallet)
+		r.Delete("/{walletID}", handlers.Deletion.DeleteWallet)
+		r.Post("/{walletID}/reactivate", handlers.Dormancy.ReactivateWallet)
 		r.Post("/{walletID}/deposit", walletHandler.Deposit)
 		r.Post("/{walletID}/withdraw", walletHandler.Withdraw)
 		r.Get("/{walletID}/balance", walletHandler.GetWalletBalance)
 		r.Get("/{walletID}/transactions", walletHandler.GetTransactionHistory)
+		r.Get("/{walletID}/transactions/search", walletHandler.SearchTransactionHistory)
+
+		// Joint wallet membership and approval policy
+		r.Get("/{walletID}/members", handlers.Joint.ListMembers)
+		r.Post("/{walletID}/members", handlers.Joint.AddMember)
+		r.Delete("/{walletID}/members/{userID}", handlers.Joint.RemoveMember)
+		r.Get("/{walletID}/approval-policy", handlers.Joint.GetApprovalPolicy)
+		r.Put("/{walletID}/approval-policy", handlers.Joint.SetApprovalPolicy)
+		r.Delete("/{walletID}/approval-policy", handlers.Joint.DeleteApprovalPolicy)
+
+		// Spending budgets
+		r.Get("/{walletID}/budgets", handlers.Budget.ListBudgets)
+		r.Post("/{walletID}/budgets", handlers.Budget.CreateBudget)
+		r.Delete("/{walletID}/budgets/{budgetID}", handlers.Budget.DeleteBudget)
+		r.Get("/{walletID}/analytics/budgets", handlers.Budget.GetBudgetAnalytics)
+
+		// Merchant wallets
+		r.Get("/{walletID}/merchant", handlers.Merchant.GetMerchant)
+		r.Put("/{walletID}/merchant", handlers.Merchant.RegisterMerchant)
+		r.Post("/{walletID}/charges", handlers.Merchant.CreateCharge)
+		r.Get("/{walletID}/settlements", handlers.Merchant.ListSettlements)
+
+		// Shared bills
+		r.Get("/{walletID}/bills", handlers.Bill.ListBills)
+		r.Post("/{walletID}/bills", handlers.Bill.CreateBill)
+
+		// Promotional credits, granted under /admin
+		r.Get("/{walletID}/promo-credits", handlers.Promo.ListPromoCredits)
+
+		// Full-history exports, processed in the background
+		r.Post("/{walletID}/exports", handlers.WalletExport.RequestExport)
+
+		// Linked funding sources and auto top-up from them
+		r.Get("/{walletID}/funding-sources", handlers.AutoTopUp.ListFundingSources)
+		r.Post("/{walletID}/funding-sources", handlers.AutoTopUp.AddFundingSource)
+		r.Delete("/{walletID}/funding-sources/{sourceID}", handlers.AutoTopUp.RemoveFundingSource)
+		r.Get("/{walletID}/auto-top-up", handlers.AutoTopUp.GetAutoTopUp)
+		r.Put("/{walletID}/auto-top-up", handlers.AutoTopUp.SetAutoTopUp)
+		r.Delete("/{walletID}/auto-top-up", handlers.AutoTopUp.DeleteAutoTopUp)
+
+		// Crypto wallets: deposit addresses, on-chain deposits and withdrawals paid out on chain
+		r.Get("/{walletID}/crypto/addresses", handlers.Crypto.ListCryptoAddresses)
+		r.Post("/{walletID}/crypto/addresses", handlers.Crypto.CreateCryptoAddress)
+		r.Get("/{walletID}/crypto/deposits", handlers.Crypto.ListCryptoDeposits)
+		r.Get("/{walletID}/crypto/fee-estimate", handlers.Crypto.EstimateCryptoFee)
+		r.Get("/{walletID}/crypto/withdrawals", handlers.Crypto.ListCryptoWithdrawals)
+		r.Post("/{walletID}/crypto/withdrawals", handlers.Crypto.RequestCryptoWithdrawal)
+		r.Get("/{walletID}/crypto/withdrawals/{payoutID}", handlers.Crypto.GetCryptoWithdrawal)
+		// Ramps between fiat and stablecoin wallets: quoted first, then executed at the quoted pricing
+		r.Post("/{walletID}/ramps", handlers.Ramp.QuoteRamp)
+		r.Get("/{walletID}/ramps/{rampID}", handlers.Ramp.GetRamp)
+		r.Post("/{walletID}/ramps/{rampID}/execute", handlers.Ramp.ExecuteRamp)
 	})
 
 	// Transfer is a separate top-level endpoint as it involves two wallets
 	r.Post("/transfers", walletHandler.Transfer)
+	r.Post("/transfers/split", walletHandler.SplitTransfer)
+	r.Post("/transfers/quote", handlers.TransferQuote.QuoteTransfer)
+
+	// Transfer instructions settled as one net transfer per wallet pair when the netting window closes
+	r.Post("/netting/instructions", handlers.Netting.SubmitInstruction)
+	r.Get("/netting/instructions/{instructionID}", handlers.Netting.GetInstruction)
+	r.Get("/netting/settlements/{settlementID}", handlers.Netting.GetSettlement)
+
+	// Transfers held back by a joint wallet's approval policy
+	r.Get("/transfer-approvals/{approvalID}", handlers.Joint.GetTransferApproval)
+	r.Post("/transfer-approvals/{approvalID}/approve", handlers.Joint.ApproveTransfer)
+	r.Post("/transfer-approvals/{approvalID}/reject", handlers.Joint.RejectTransfer)
+
+	// Charges created by merchants and paid by customers
+	r.Get("/charges/{chargeID}", handlers.Merchant.GetCharge)
+	r.Post("/charges/{chargeID}/pay", handlers.Merchant.PayCharge)
+	r.Post("/charges/{chargeID}/cancel", handlers.Merchant.CancelCharge)
+
+	// Delegated login with the ID token of an OpenID Connect provider; provisions the user at first login
+	r.Post("/auth/oidc/{provider}/login", handlers.Identity.Login)
+
+	// Shared bills approved and paid by each participant
+	r.Get("/bills/{billID}", handlers.Bill.GetBill)
+	r.Post("/bills/{billID}/approve", handlers.Bill.ApproveShare)
+	r.Post("/bills/{billID}/pay", handlers.Bill.PayShare)
+	r.Post("/bills/{billID}/cancel", handlers.Bill.CancelBill)
+
+	// Vouchers issued under /admin and redeemed into a wallet
+	r.Post("/vouchers/redeem", handlers.Voucher.RedeemVoucher)
+
+	// Transaction API routes
+	r.Get("/transactions/{transactionID}", walletHandler.GetTransaction)
+	r.Get("/transactions/{transactionID}/wait", walletHandler.WaitForTransaction)
+	// Support annotations, invisible to users; readable through GET /admin/transactions/{transactionID}
+	r.With(apimiddleware.RequireAdminToken(opts.AdminToken, opts.AdminUsers)).
+		Patch("/transactions/{transactionID}/annotations", handlers.Annotation.AnnotateTransaction)
+	// Recomputes the transaction's effect on both wallets from the ledger, after incidents
+	r.With(apimiddleware.RequireAdminToken(opts.AdminToken, opts.AdminUsers)).
+		Get("/transactions/{transactionID}/verify", handlers.TxVerify.VerifyTransaction)
+
+	// Payment provider webhooks, signed by the provider as a partner
+	r.With(apimiddleware.RequirePaymentProvider(opts.Providers)).Post("/webhooks/inbound-funds", handlers.Suspense.ReceiveInboundFunds)
+
+	// Usage and quotas of a partner's own API key
+	r.With(apimiddleware.RequirePartner).Get("/api-keys/{keyID}/usage", handlers.Usage.GetUsage)
+
+	// Open Banking account information, read by third parties with an access token of the accounts scope
+	r.Route("/open-banking/v3.1/aisp", func(r chi.Router) {
+		r.Use(apimiddleware.RequireOpenBankingToken(opts.OpenBanking.TokenKey, opts.OpenBanking.TokenIssuer))
+		r.Use(apimiddleware.RequireScope("accounts"))
+		r.Post("/account-access-consents", handlers.OpenBanking.CreateConsent)
+		r.Get("/account-access-consents/{consentID}", handlers.OpenBanking.GetConsent)
+		r.Delete("/account-access-consents/{consentID}", handlers.OpenBanking.DeleteConsent)
+		r.Get("/accounts", handlers.OpenBanking.ListAccounts)
+		r.Get("/accounts/{accountID}", handlers.OpenBanking.GetAccount)
+		r.Get("/accounts/{accountID}/balances", handlers.OpenBanking.GetBalances)
+		r.Get("/accounts/{accountID}/transactions", handlers.OpenBanking.ListTransactions)
+	})
+
+	// SCIM 2.0 user provisioning by the identity systems of enterprise tenants
+	r.Route("/scim/v2", func(r chi.Router) {
+		r.Use(apimiddleware.RequireSCIMToken(opts.SCIMTokens))
+		r.Get("/Users", handlers.SCIM.ListUsers)
+		r.Post("/Users", handlers.SCIM.CreateUser)
+		r.Get("/Users/{id}", handlers.SCIM.GetUser)
+		r.Put("/Users/{id}", handlers.SCIM.ReplaceUser)
+		r.Patch("/Users/{id}", handlers.SCIM.PatchUser)
+		r.Delete("/Users/{id}", handlers.SCIM.DeactivateUser)
+	})
+
+	// Wallet export progress and signed downloads
+	r.Get("/exports/{exportID}", handlers.WalletExport.GetExport)
+	r.Get("/exports/{exportID}/download", handlers.WalletExport.DownloadExport)
+
+	// Delta sync of wallets and transactions
+	r.Get("/changes", handlers.Change.ListChanges)
+
+	// Exchange rate routes
+	r.Get("/fx/rates", handlers.FX.GetRates)
+
+	// Operator-only routes
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(apimiddleware.RequireAdminToken(opts.AdminToken, opts.AdminUsers))
+		r.Get("/maintenance", handlers.Admin.GetMaintenance)
+		r.Put("/maintenance", handlers.Admin.SetMaintenance)
+
+		// Query latency histograms and slow query logging
+		r.Get("/query-timing", handlers.Admin.GetQueryTiming)
+		r.Put("/query-timing", handlers.Admin.SetQueryTiming)
+		r.Delete("/query-timing/stats", handlers.Admin.ResetQueryTiming)
+
+		// Connection pool statistics, also for Prometheus
+		r.Get("/db-pool", handlers.Admin.GetDBPool)
+		r.Get("/db-pool/metrics", handlers.Admin.GetDBPoolMetrics)
+
+		// Concurrency limits and shed counters per route class
+		r.Get("/load-shedding", handlers.Admin.GetLoadShedding)
+
+		// Comparison of the reads repeated against a candidate repository implementation
+		r.Get("/shadow-reads", handlers.Admin.GetShadowReads)
+
+		r.Get("/feature-flags", handlers.Admin.ListFeatureFlags)
+		r.Get("/feature-flags/{key}", handlers.Admin.GetFeatureFlag)
+		r.Put("/feature-flags/{key}", handlers.Admin.PutFeatureFlag)
+		r.Delete("/feature-flags/{key}", handlers.Admin.DeleteFeatureFlag)
+		r.Get("/feature-flags/{key}/evaluation", handlers.Admin.EvaluateFeatureFlag)
+		// Control and canary code paths of the flagged changes, also for Prometheus
+		r.Get("/rollouts", handlers.Admin.GetRollouts)
+		r.Get("/rollouts/metrics", handlers.Admin.GetRolloutMetrics)
+
+		r.Post("/vouchers", handlers.Voucher.IssueVouchers)
+		r.Get("/vouchers/liability", handlers.Voucher.GetVoucherLiability)
+
+		r.Post("/wallets/{walletID}/promo-credits", handlers.Promo.GrantPromoCredit)
+
+		// Soft-deleted users and wallets can be restored until they are purged
+		r.Post("/users/{userID}/restore", handlers.Deletion.RestoreUser)
+		r.Post("/wallets/{walletID}/restore", handlers.Deletion.RestoreWallet)
+
+		// Audit records of the changes SCIM tenants made to a user
+		r.Get("/users/{userID}/provisioning-events", handlers.SCIM.ListProvisioningEvents)
+
+		// Wallets flagged dormant after a period without activity
+		r.Get("/wallets/dormant", handlers.Dormancy.ListDormantWallets)
+
+		// Serialization of the money movements of contended wallets, with the depth of their queues
+		r.Get("/wallet-serialization", handlers.Serialization.ListWalletSerializations)
+		r.With(apimiddleware.RequireNamedAdmin).Put("/wallets/{walletID}/serialization", handlers.Serialization.SetWalletSerialization)
+		r.With(apimiddleware.RequireNamedAdmin).Delete("/wallets/{walletID}/serialization", handlers.Serialization.DeleteWalletSerialization)
+		// Balance shards of high-throughput wallets
+		r.Get("/balance-shards", handlers.BalanceShards.ListBalanceShards)
+		r.With(apimiddleware.RequireNamedAdmin).Put("/wallets/{walletID}/balance-shards", handlers.BalanceShards.SetBalanceShards)
+		r.With(apimiddleware.RequireNamedAdmin).Delete("/wallets/{walletID}/balance-shards", handlers.BalanceShards.DeleteBalanceShards)
+
+		// Schema version, pending migrations and backfill progress
+		r.Get("/migrations", handlers.Migration.GetMigrationStatus)
+
+		// Retention periods and purges of audit and notification records
+		r.Get("/retention", handlers.Retention.GetRetention)
+		r.Post("/retention/purge", handlers.Retention.PurgeRecords)
+
+		// Invariant checks of the database, run against restored backups in disaster recovery drills
+		r.Post("/backup-verification", handlers.Backup.VerifyBackup)
+
+		// Support impersonation sessions and their audit trail
+		r.With(apimiddleware.RequireNamedAdmin).Post("/impersonations", handlers.Impersonation.StartImpersonation)
+		r.Get("/impersonations/{sessionID}", handlers.Impersonation.GetImpersonation)
+		r.Delete("/impersonations/{sessionID}", handlers.Impersonation.EndImpersonation)
+		r.Get("/impersonations/{sessionID}/audit", handlers.Impersonation.ListImpersonationAudit)
+
+		// Hash-chained audit trail of each wallet and the check of its links
+		r.Get("/wallets/{walletID}/audit", handlers.Audit.ListAuditEvents)
+		r.Get("/wallets/{walletID}/audit/verify", handlers.Audit.VerifyAuditChain)
+
+		r.Get("/transactions/{transactionID}", handlers.Annotation.GetAdminTransaction)
+		// Everything linked to a transaction in one response, for investigations
+		r.Get("/transactions/{transactionID}/full", handlers.Investigation.GetTransactionInvestigation)
+
+		// Double-entry journal of wallet activity for the finance team's ERP
+		r.Get("/journal", handlers.Journal.ExportJournal)
+
+		// Customer fund liabilities and float movement for treasury
+		r.Get("/reports/liability", handlers.Report.GetLiabilityReport)
+
+		// Unmatched inbound funds held in the suspense wallets
+		r.Get("/suspense/funds", handlers.Suspense.ListSuspendedFunds)
+		r.Get("/suspense/funds/{fundsID}", handlers.Suspense.GetFunds)
+		r.With(apimiddleware.RequireNamedAdmin).Post("/suspense/funds/{fundsID}/reassign", handlers.Suspense.ReassignFunds)
+
+		// Manual adjustments: proposed by one named admin, approved by another
+		r.Group(func(r chi.Router) {
+			r.Use(apimiddleware.RequireNamedAdmin)
+			r.Post("/adjustments", handlers.Adjustment.ProposeAdjustment)
+			r.Get("/adjustments", handlers.Adjustment.ListAdjustments)
+			r.Get("/adjustments/{adjustmentID}", handlers.Adjustment.GetAdjustment)
+			r.Post("/adjustments/{adjustmentID}/approve", handlers.Adjustment.ApproveAdjustment)
+			r.Post("/adjustments/{adjustmentID}/reject", handlers.Adjustment.RejectAdjustment)
+		})
+
+		// Transfers of wallets to other users, e.g. account merges; requested and cancelled by a named admin
+		r.With(apimiddleware.RequireNamedAdmin).Post("/wallets/{walletID}/ownership-transfers", handlers.Ownership.RequestOwnershipTransfer)
+		r.Get("/wallets/{walletID}/ownership-transfers", handlers.Ownership.ListWalletOwnershipTransfers)
+		r.Get("/ownership-transfers/{transferID}", handlers.Ownership.GetOwnershipTransfer)
+		r.With(apimiddleware.RequireNamedAdmin).Post("/ownership-transfers/{transferID}/cancel", handlers.Ownership.CancelOwnershipTransfer)
+
+		// Screening denylist and the review cases of its hits; changes are recorded under a named admin
+		r.Get("/denylist", handlers.Screening.ListDenylistEntries)
+		r.With(apimiddleware.RequireNamedAdmin).Post("/denylist", handlers.Screening.AddDenylistEntry)
+		r.With(apimiddleware.RequireNamedAdmin).Delete("/denylist/{entryID}", handlers.Screening.DeleteDenylistEntry)
+		r.Get("/screening/cases", handlers.Screening.ListScreeningCases)
+		r.Get("/screening/cases/{caseID}", handlers.Screening.GetScreeningCase)
+		r.With(apimiddleware.RequireNamedAdmin).Post("/screening/cases/{caseID}/resolve", handlers.Screening.ResolveScreeningCase)
+		// Residency restrictions of currencies, enforced at wallet creation and whenever money enters a wallet
+		r.Get("/currency-restrictions", handlers.Restriction.ListCurrencyRestrictions)
+		r.Get("/currency-restrictions/{currency}", handlers.Restriction.GetCurrencyRestriction)
+		r.With(apimiddleware.RequireNamedAdmin).Put("/currency-restrictions/{currency}", handlers.Restriction.SetCurrencyRestriction)
+		r.With(apimiddleware.RequireNamedAdmin).Delete("/currency-restrictions/{currency}", handlers.Restriction.DeleteCurrencyRestriction)
+
+		// Test clock of sandbox mode, advanced to exercise windows, expirations and background jobs
+		if handlers.Clock != nil {
+			r.Get("/clock", handlers.Clock.GetClock)
+			r.Post("/clock/advance", handlers.Clock.AdvanceClock)
+		}
+	})
 
 	return r
 }
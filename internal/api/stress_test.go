@@ -0,0 +1,129 @@
+// internal/api/stress_test.go
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/api/types"
+)
+
+// stressTransfers is the number of transfers TestConcurrentTransfersIntegration fires at once.
+const stressTransfers = 400
+
+// TestConcurrentTransfersIntegration fires hundreds of concurrent transfers in both directions between a few
+// wallets and checks that no update is lost: each wallet ends with its initial balance plus what the accepted
+// transfers credited and less what they debited, and exactly one transaction exists per accepted transfer.
+// Refusals are allowed, as long as they are the documented ones and move no money.
+//
+//	make stress-test
+func TestConcurrentTransfersIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("stress test skipped in short mode")
+	}
+	clearDatabase(t)
+	initial := decimal.NewFromInt(1000)
+	wallets := make([]uuid.UUID, 4)
+	for i := range wallets {
+		wallets[i] = createTestUserAndWallet(t, fmt.Sprintf("stress_user%d", i), "USD", initial)
+	}
+
+	type outcome struct {
+		from, to int
+		amount   decimal.Decimal
+		status   int
+		code     string
+	}
+	random := rand.New(rand.NewSource(42)) // Fixed, so a failure can be replayed
+	outcomes := make([]outcome, stressTransfers)
+	for i := range outcomes {
+		from := random.Intn(len(wallets))
+		to := (from + 1 + random.Intn(len(wallets)-1)) % len(wallets)
+		outcomes[i] = outcome{from: from, to: to, amount: decimal.NewFromInt(int64(1 + random.Intn(50)))}
+	}
+
+	// Every goroutine waits for start, so the transfers hit the server together
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range outcomes {
+		wg.Add(1)
+		go func(o *outcome) {
+			defer wg.Done()
+			<-start
+			body := fmt.Sprintf(`{"from_wallet_id": "%s", "to_wallet_id": "%s", "amount": "%s", "currency": "USD", "force": true}`,
+				wallets[o.from], wallets[o.to], o.amount)
+			req, err := http.NewRequest(http.MethodPost, testServer.URL+"/transfers", strings.NewReader(body))
+			if err != nil {
+				o.code = err.Error()
+				return
+			}
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				o.code = err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			o.status = resp.StatusCode
+			if resp.StatusCode != http.StatusOK {
+				var failure map[string]string
+				_ = json.NewDecoder(resp.Body).Decode(&failure)
+				o.code = failure["code"]
+			}
+		}(&outcomes[i])
+	}
+	close(start)
+	wg.Wait()
+
+	expected := make([]decimal.Decimal, len(wallets))
+	for i := range expected {
+		expected[i] = initial
+	}
+	accepted := 0
+	for _, o := range outcomes {
+		switch {
+		case o.status == http.StatusOK:
+			expected[o.from] = expected[o.from].Sub(o.amount)
+			expected[o.to] = expected[o.to].Add(o.amount)
+			accepted++
+		case o.status == http.StatusConflict && o.code == "concurrent_update",
+			o.status == http.StatusPaymentRequired && o.code == "insufficient_funds",
+			o.status == http.StatusServiceUnavailable, o.status == http.StatusTooManyRequests:
+			// Refused before moving money
+		default:
+			t.Errorf("transfer of %s from wallet %d to wallet %d: unexpected outcome %d %s", o.amount, o.from, o.to, o.status, o.code)
+		}
+	}
+	t.Logf("%d of %d concurrent transfers accepted", accepted, stressTransfers)
+	require.NotZero(t, accepted, "no transfer was accepted")
+
+	total := decimal.Zero
+	for i, walletID := range wallets {
+		resp, body := makeRequest(t, http.MethodGet, fmt.Sprintf("/wallets/%s/balance", walletID), nil)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode, body)
+		var balance types.Response[map[string]any]
+		require.NoError(t, json.Unmarshal([]byte(body), &balance))
+		actual, err := decimal.NewFromString(balance.Data["balance"].(string))
+		require.NoError(t, err)
+		assert.True(t, expected[i].Equal(actual), "wallet %d: balance %s, accepted transfers leave %s", i, actual, expected[i])
+		total = total.Add(actual)
+	}
+	assert.True(t, initial.Mul(decimal.NewFromInt(int64(len(wallets)))).Equal(total), "money was created or destroyed: total %s", total)
+
+	var transfers int
+	err := testApp.DB.GetContext(context.Background(), &transfers, "SELECT count(*) FROM transactions WHERE type = 'TRANSFER'")
+	require.NoError(t, err)
+	assert.Equal(t, accepted, transfers, "each accepted transfer must be recorded once")
+}
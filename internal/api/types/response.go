@@ -1,11 +1,109 @@
 // internal/api/types/response.go
 package types
 
-// PaginatedResponse defines a generic structure for paginated API responses.
-// T represents the type of data contained in the 'Data' slice.
-type PaginatedResponse[T any] struct {
-	Data       []T   `json:"data"`
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// Links holds the hypermedia links of a response, keyed by relation (self, next, prev, ...).
+type Links map[string]string
+
+// Response is the standard envelope of every successful single-resource API response.
+type Response[T any] struct {
+	Data  T              `json:"data"`
+	Meta  map[string]any `json:"meta,omitempty"`
+	Links Links          `json:"links,omitempty"`
+}
+
+// PageMeta describes the pagination window of a list response.
+type PageMeta struct {
 	Limit      int   `json:"limit"`
 	Offset     int   `json:"offset"`
 	TotalCount int64 `json:"total_count"`
 }
+
+// PaginatedResponse defines a generic structure for paginated API responses.
+// T represents the type of data contained in the 'Data' slice.
+type PaginatedResponse[T any] struct {
+	Data  []T      `json:"data"`
+	Meta  PageMeta `json:"meta"`
+	Links Links    `json:"links"`
+}
+
+// NewPaginatedResponse builds a paginated envelope with self/next/prev links derived from the request URL.
+// Links are relative (path and query only) and keep every other query parameter untouched.
+func NewPaginatedResponse[T any](data []T, requestURL *url.URL, limit, offset int, totalCount int64) PaginatedResponse[T] {
+	if data == nil {
+		data = []T{}
+	}
+	links := Links{"self": pageLink(requestURL, limit, offset)}
+	if int64(offset+limit) < totalCount {
+		links["next"] = pageLink(requestURL, limit, offset+limit)
+	}
+	if offset > 0 {
+		links["prev"] = pageLink(requestURL, limit, max(offset-limit, 0))
+	}
+
+	return PaginatedResponse[T]{
+		Data:  data,
+		Meta:  PageMeta{Limit: limit, Offset: offset, TotalCount: totalCount},
+		Links: links,
+	}
+}
+
+func pageLink(requestURL *url.URL, limit, offset int) string {
+	query := requestURL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	return (&url.URL{Path: requestURL.Path, RawQuery: query.Encode()}).String()
+}
+
+// ItemResult is the outcome of one item of a bulk request: its position in the request, its own HTTP
+// status and either its data or its error, with the message and code a failed single request would get.
+type ItemResult[T any] struct {
+	Index   int               `json:"index"`
+	Status  int               `json:"status"`
+	Data    T                 `json:"data,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Code    string            `json:"code,omitempty"`
+	Details map[string]string `json:"details,omitempty"` // Extra fields of the error, e.g. existing_transaction_id
+}
+
+// MultiStatusMeta counts the items of a bulk request by outcome.
+type MultiStatusMeta struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// MultiStatusResponse is the standard envelope of bulk and batch endpoints, which process every item and
+// report each one's outcome rather than failing the request at the first bad item.
+type MultiStatusResponse[T any] struct {
+	Data []ItemResult[T] `json:"data"`
+	Meta MultiStatusMeta `json:"meta"`
+}
+
+// NewMultiStatusResponse builds the envelope of a bulk request's results, ordered by index, and returns
+// the status of the response: 200 OK when every item succeeded, else 207 Multi-Status.
+func NewMultiStatusResponse[T any](items []ItemResult[T]) (int, MultiStatusResponse[T]) {
+	if items == nil {
+		items = []ItemResult[T]{}
+	}
+	slices.SortStableFunc(items, func(a, b ItemResult[T]) int { return a.Index - b.Index })
+	meta := MultiStatusMeta{Total: len(items)}
+	for _, item := range items {
+		if item.Status < http.StatusBadRequest {
+			meta.Succeeded++
+		} else {
+			meta.Failed++
+		}
+	}
+	status := http.StatusOK
+	if meta.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	return status, MultiStatusResponse[T]{Data: items, Meta: meta}
+}
@@ -3,20 +3,33 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	router "finflow-wallet/internal/api"
 	"finflow-wallet/internal/api/handler"
+	apimiddleware "finflow-wallet/internal/api/middleware"
 	"finflow-wallet/internal/repository"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
+	"finflow-wallet/internal/clock"
 	"finflow-wallet/internal/config"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/jobs"
+	"finflow-wallet/internal/migration"
 	"finflow-wallet/internal/repository/postgres"
+	"finflow-wallet/internal/repository/shadow"
 	"finflow-wallet/internal/service"
 	"finflow-wallet/internal/util"
+	"finflow-wallet/migrations"
 	"finflow-wallet/pkg/db"
+	"finflow-wallet/pkg/keyring"
+	"finflow-wallet/pkg/redis"
 )
 
 // Application holds all the initialized components of the application.
@@ -25,16 +38,126 @@ type Application struct {
 	Logger *slog.Logger
 	DB     *sqlx.DB
 
+	// QueryTimer times the queries of the repositories; switchable under /admin
+	QueryTimer *db.QueryTimer
+	// PoolMonitor samples the connection pool statistics and raises alarms on long waits
+	PoolMonitor *db.PoolMonitor
+	// StmtCache holds the prepared statements of the hot queries; nil when disabled
+	StmtCache *db.StmtCache
+	// ShadowDB is the candidate database the shadow reads are repeated against; nil when disabled
+	ShadowDB *sqlx.DB
+	// ShadowReads compares the reads of the candidate database with the current one; nil when disabled
+	ShadowReads *shadow.Harness
+	// Redis holds the state shared by the replicas of a deployment, such as seen nonces; nil when not configured
+	Redis *redis.Client
+
 	// Repositories
-	UserRepository        repository.UserRepository
-	WalletRepository      repository.WalletRepository
-	TransactionRepository repository.TransactionRepository
+	UserRepository             repository.UserRepository
+	WalletRepository           repository.WalletRepository
+	TransactionRepository      repository.TransactionRepository
+	ArchiveRepository          repository.TransactionArchiveRepository
+	FeatureFlagRepository      repository.FeatureFlagRepository
+	FXRateRepository           repository.FXRateRepository
+	SweepRuleRepository        repository.SweepRuleRepository
+	WalletMemberRepository     repository.WalletMemberRepository
+	TransferApprovalRepository repository.TransferApprovalRepository
+	BudgetRepository           repository.BudgetRepository
+	MerchantRepository         repository.MerchantRepository
+	ChargeRepository           repository.ChargeRepository
+	BillRepository             repository.BillRepository
+	VoucherRepository          repository.VoucherRepository
+	PromoCreditRepository      repository.PromoCreditRepository
+	ExportRepository           repository.ExportRepository
+	ChangeRepository           repository.ChangeRepository
+	ImpersonationRepository    repository.ImpersonationRepository
+	AnnotationRepository       repository.AnnotationRepository
+	WalletExportRepository     repository.WalletExportRepository
+	AuditRepository            repository.AuditRepository
+	TransferQuoteRepository    repository.TransferQuoteRepository
+	AutoTopUpRepository        repository.AutoTopUpRepository
+	CryptoRepository           repository.CryptoRepository
+	RampRepository             repository.RampRepository
+	OpenBankingRepository      repository.OpenBankingRepository
+	IdentityRepository         repository.IdentityRepository
+	SCIMRepository             repository.SCIMRepository
+	NettingRepository          repository.NettingRepository
+	ReportRepository           repository.ReportRepository
+	SuspenseRepository         repository.SuspenseRepository
+	AdjustmentRepository       repository.AdjustmentRepository
+	OwnershipRepository        repository.OwnershipTransferRepository
+	ScreeningRepository        repository.ScreeningRepository
+	RestrictionRepository      repository.CurrencyRestrictionRepository
+	AuthEventRepository        repository.AuthEventRepository
+	PINRepository              repository.PINRepository
+	VerificationRepository     repository.ContactVerificationRepository
+	NotificationRepository     repository.NotificationRepository
+	UsageRepository            repository.UsageRepository
+	SerializationRepository    repository.WalletSerializationRepository
+	BalanceShardRepository     repository.BalanceShardRepository
+	SchemaRepository           repository.SchemaRepository
+	RetentionRepository        repository.RetentionRepository
+	IntegrityRepository        repository.IntegrityRepository
+	InvestigationRepository    repository.InvestigationRepository
+	AsyncTransferRepository    repository.AsyncTransferRepository
 
 	// Services
-	WalletService service.WalletService
+	WalletService        service.WalletService
+	ArchiveService       service.ArchiveService
+	FeatureFlagService   service.FeatureFlagService
+	FXService            service.FXService
+	SweepRuleService     service.SweepRuleService
+	JointWalletService   service.JointWalletService
+	BudgetService        service.BudgetService
+	MerchantService      service.MerchantService
+	BillService          service.BillService
+	VoucherService       service.VoucherService
+	PromoCreditService   service.PromoCreditService
+	ExportService        service.ExportService // Nil when the export is disabled
+	ChangeService        service.ChangeService
+	DeletionService      service.DeletionService
+	DormancyService      service.DormancyService
+	ImpersonationService service.ImpersonationService
+	AnnotationService    service.AnnotationService
+	WalletExportService  service.WalletExportService
+	AuditService         service.AuditService
+	TransferQuoteService service.TransferQuoteService
+	AutoTopUpService     service.AutoTopUpService
+	CryptoService        service.CryptoService
+	RampService          service.RampService
+	OpenBankingService   service.OpenBankingService
+	IdentityService      service.IdentityService
+	SCIMService          service.SCIMService
+	NettingService       service.NettingService
+	JournalService       service.JournalService
+	ReportService        service.ReportService
+	SuspenseService      service.SuspenseService
+	AdjustmentService    service.AdjustmentService
+	OwnershipService     service.OwnershipTransferService
+	ScreeningService     service.ScreeningService
+	RestrictionService   service.CurrencyRestrictionService
+	SecurityService      service.SecurityService
+	PINService           service.PINService
+	VerificationService  service.ContactVerificationService
+	PIIService           service.PIIReencryptionService // nil unless encryption keys are configured
+	NotificationService  service.NotificationService
+	UsageService         service.UsageService
+	SerializationService service.WalletSerializationService
+	BalanceShardService  service.BalanceShardService
+	MigrationService     service.MigrationService
+	RetentionService     service.RetentionService
+	Rollouts             *service.Rollouts
+	BackupVerification   service.BackupVerificationService
+	InvestigationService service.InvestigationService
+	TxVerification       service.TransactionVerificationService
+
+	// Background jobs
+	Scheduler *jobs.Scheduler
+	Clock     clock.Clock // The system clock, or in sandbox mode a test clock that can be advanced
 
 	// HTTP API
+	Maintenance *apimiddleware.MaintenanceSwitch
 	HTTPHandler http.Handler
+	TLSConfig   *tls.Config // Client certificate verification of the HTTP server; nil without mTLS
 }
 
 // NewApplication creates a new Application instance.
@@ -62,39 +185,965 @@ func (app *Application) Initialize(ctx context.Context) error {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	app.DB = database
+	app.QueryTimer = db.NewQueryTimer(app.Config.QueryTiming.Enabled, app.Config.QueryTiming.SlowThreshold, app.Logger)
+	app.PoolMonitor = db.NewPoolMonitor(app.DB.Stats, app.Config.DBPool.WaitWarn, app.Config.DBPool.WaitCritical, app.Logger)
 	app.Logger.Info("Database connection established.")
+	if app.Config.Redis.URL != "" {
+		if app.Redis, err = redis.Open(app.Config.Redis.URL, app.Config.Redis.Timeout); err != nil {
+			return err
+		}
+		if err := app.Redis.Ping(ctx); err != nil {
+			return fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		app.Logger.Info("Redis connection established.")
+	}
+	app.Clock = clock.System{}
+	if app.Config.Sandbox {
+		app.Clock = clock.NewTest()
+	}
 
 	// 4. Initialize Repositories
-	app.UserRepository = postgres.NewUserRepository(app.DB)
+	piiKeyring, err := keyring.LoadKeyring(ctx, keyring.EnvSecretProvider{})
+	if err != nil {
+		return fmt.Errorf("failed to load encryption keys: %w", err)
+	}
+	var userKeyring keyring.Keyring // Left nil rather than holding a nil *AESKeyring
+	if piiKeyring != nil {
+		userKeyring = piiKeyring
+		app.Logger.Info("Personal data encryption keys loaded.", "primary_key", piiKeyring.PrimaryKeyID())
+	} else {
+		app.Logger.Warn(keyring.SecretKeys + " is not set; user email and phone cannot be stored")
+	}
+	app.UserRepository = postgres.NewUserRepository(app.DB, userKeyring)
 	app.WalletRepository = postgres.NewWalletRepository(app.DB)
 	app.TransactionRepository = postgres.NewTransactionRepository(app.DB)
+	app.ArchiveRepository = postgres.NewTransactionArchiveRepository(app.DB)
+	app.FeatureFlagRepository = postgres.NewFeatureFlagRepository(app.DB)
+	app.FXRateRepository = postgres.NewFXRateRepository(app.DB)
+	app.SweepRuleRepository = postgres.NewSweepRuleRepository(app.DB)
+	app.WalletMemberRepository = postgres.NewWalletMemberRepository(app.DB)
+	app.TransferApprovalRepository = postgres.NewTransferApprovalRepository(app.DB)
+	app.BudgetRepository = postgres.NewBudgetRepository(app.DB)
+	app.MerchantRepository = postgres.NewMerchantRepository(app.DB)
+	app.ChargeRepository = postgres.NewChargeRepository(app.DB)
+	app.BillRepository = postgres.NewBillRepository(app.DB)
+	app.VoucherRepository = postgres.NewVoucherRepository(app.DB)
+	app.PromoCreditRepository = postgres.NewPromoCreditRepository(app.DB)
+	app.ExportRepository = postgres.NewExportRepository(app.DB)
+	app.ChangeRepository = postgres.NewChangeRepository(app.DB)
+	app.ImpersonationRepository = postgres.NewImpersonationRepository(app.DB)
+	app.AnnotationRepository = postgres.NewAnnotationRepository(app.DB)
+	app.WalletExportRepository = postgres.NewWalletExportRepository(app.DB)
+	app.AuditRepository = postgres.NewAuditRepository(app.DB)
+	app.TransferQuoteRepository = postgres.NewTransferQuoteRepository(app.DB)
+	app.AutoTopUpRepository = postgres.NewAutoTopUpRepository(app.DB)
+	app.CryptoRepository = postgres.NewCryptoRepository(app.DB)
+	app.RampRepository = postgres.NewRampRepository(app.DB)
+	app.OpenBankingRepository = postgres.NewOpenBankingRepository(app.DB)
+	app.IdentityRepository = postgres.NewIdentityRepository(app.DB)
+	app.SCIMRepository = postgres.NewSCIMRepository(app.DB)
+	app.NettingRepository = postgres.NewNettingRepository(app.DB)
+	app.ReportRepository = postgres.NewReportRepository(app.DB)
+	app.SuspenseRepository = postgres.NewSuspenseRepository(app.DB)
+	app.AdjustmentRepository = postgres.NewAdjustmentRepository(app.DB)
+	app.OwnershipRepository = postgres.NewOwnershipTransferRepository(app.DB)
+	app.ScreeningRepository = postgres.NewScreeningRepository(app.DB)
+	app.RestrictionRepository = postgres.NewCurrencyRestrictionRepository(app.DB)
+	app.AuthEventRepository = postgres.NewAuthEventRepository(app.DB)
+	app.PINRepository = postgres.NewPINRepository(app.DB)
+	app.VerificationRepository = postgres.NewContactVerificationRepository(app.DB)
+	app.NotificationRepository = postgres.NewNotificationRepository(app.DB)
+	app.UsageRepository = postgres.NewUsageRepository(app.DB)
+	app.SerializationRepository = postgres.NewWalletSerializationRepository(app.DB)
+	app.BalanceShardRepository = postgres.NewBalanceShardRepository(app.DB)
+	app.SchemaRepository = postgres.NewSchemaRepository(app.DB)
+	app.RetentionRepository = postgres.NewRetentionRepository(app.DB)
+	app.IntegrityRepository = postgres.NewIntegrityRepository(app.DB)
+	app.InvestigationRepository = postgres.NewInvestigationRepository(app.DB)
+	app.AsyncTransferRepository = postgres.NewAsyncTransferRepository(app.DB)
+	if err := app.initShadowReads(); err != nil {
+		return err
+	}
 	app.Logger.Info("Repositories initialized.")
 
+	// Amounts are accepted with AMOUNT_SCALE places, so the database must store at least as many; a deployment
+	// raising it before applying the migrations would otherwise round them away
+	storedScale, err := app.SchemaRepository.GetColumnScale(ctx, app.DB, "transactions", "amount")
+	if err != nil {
+		return fmt.Errorf("failed to check the scale amounts are stored with: %w", err)
+	}
+	if app.Config.AmountScale > storedScale {
+		return fmt.Errorf("AMOUNT_SCALE %d exceeds the %d decimal places amounts are stored with; apply the migrations first", app.Config.AmountScale, storedScale)
+	}
+	domain.SetAmountScale(app.Config.AmountScale)
+	domain.SetCryptoAssetsEnabled(app.Config.Crypto.GatewayURL != "")
+
 	// 5. Initialize Services
+	// Repositories run their queries through the timed executor, inside and outside transactions
+	var untimedExecutor db.Queryer = app.DB
+	if app.Config.DB.PrepareStatements {
+		app.StmtCache = db.NewStmtCache(app.DB, postgres.HotQueries()...)
+		untimedExecutor = app.StmtCache.Executor()
+	}
+	dbExecutor := app.QueryTimer.Wrap(untimedExecutor)
+	if app.Config.DB.ReadRetries > 0 {
+		// Outside transactions, reads dropped by a failover are run again rather than answered with a 500
+		dbExecutor = db.RetryReads(dbExecutor, app.Config.DB.ReadRetries, app.Config.DB.ReadRetryBackoff, app.Logger)
+	}
+	app.FeatureFlagService = service.NewFeatureFlagService(dbExecutor, app.FeatureFlagRepository, app.Config.FeatureFlagCacheTTL, app.Logger)
+	app.Rollouts = service.NewRollouts(app.FeatureFlagService)
+	transactionEvents := service.NewTransactionEvents()
 	// Pass the concrete db.BeginTx, db.CommitTx, db.RollbackTx functions from pkg/db
+	beginTx := db.BeginTx
+	if len(app.Config.Chaos) > 0 {
+		beginTx = db.DropMarkedConnections(db.BeginTx) // Lets fault injection drop the connections of chosen requests
+	}
+	if app.StmtCache != nil {
+		beginTx = app.StmtCache.WrapBeginTx(beginTx)
+	}
+	beginTx = app.QueryTimer.WrapBeginTx(beginTx)
+	// Every committed transaction is recorded in the audit chains of its wallets
+	app.AuditService = service.NewAuditService(app.DB, dbExecutor, app.AuditRepository, app.WalletRepository, app.Logger, beginTx, db.CommitTx, db.RollbackTx)
+	transactionEvents.Subscribe(app.AuditService)
+	var policy service.Policy = service.AllowOwnerPolicy{}
+	if app.Config.Authz.Policy == config.AuthzPolicyOPA {
+		policy = service.NewOPAPolicy(app.Config.Authz.OPAURL, app.Config.Authz.OPATimeout)
+	}
+	partnerQuotas := make(map[string]domain.PartnerQuota, len(app.Config.Signing.Quotas))
+	for partnerID, quota := range app.Config.Signing.Quotas {
+		partnerQuotas[partnerID] = domain.PartnerQuota{Requests: quota.Requests, Volume: quota.Volume}
+	}
+	app.UsageService = service.NewUsageService(dbExecutor, app.UsageRepository, partnerQuotas, app.Logger)
+	transactionEvents.Subscribe(app.UsageService)
+	app.SerializationService = service.NewWalletSerializationService(dbExecutor, app.SerializationRepository, app.Config.SerializationTTL, app.Logger)
+	app.BalanceShardService = service.NewBalanceShardService(
+		app.DB,
+		dbExecutor,
+		app.BalanceShardRepository,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Config.BalanceShards.CacheTTL,
+		app.Logger,
+	)
+	// Every service moving money updates balances through the same writer, which knows the sharded wallets,
+	// checks funding against the currency restrictions and dates movements by the service clock
+	balances := service.NewBalanceWriter(app.WalletRepository, app.BalanceShardService, app.RestrictionRepository, app.Clock)
+	schemaMigrations, err := migration.Load(migrations.FS)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	app.MigrationService = service.NewMigrationService(
+		app.DB,
+		dbExecutor,
+		app.SchemaRepository,
+		schemaMigrations,
+		migrations.Backfills,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	retention := app.Config.Retention
+	days := func(n int) time.Duration { return time.Duration(n) * 24 * time.Hour }
+	app.RetentionService = service.NewRetentionService(dbExecutor, app.RetentionRepository, service.RetentionPolicy{
+		Periods: map[domain.RetentionRecordType]time.Duration{
+			domain.RetentionAuthEvents:             days(retention.AuthEventsDays),
+			domain.RetentionImpersonationAudit:     days(retention.ImpersonationAuditDays),
+			domain.RetentionNotificationDeliveries: days(retention.NotificationDeliveriesDays),
+			domain.RetentionNotifications:          days(retention.NotificationsDays),
+		},
+		BatchSize: retention.BatchSize,
+		Hour:      retention.PurgeHour,
+		DryRun:    retention.DryRun,
+	}, app.Logger)
+	app.BackupVerification = service.NewBackupVerificationService(
+		dbExecutor,
+		app.IntegrityRepository,
+		app.SchemaRepository,
+		schemaMigrations[len(schemaMigrations)-1].Version,
+		app.Config.DB.Host+"/"+app.Config.DB.DBName,
+		[]byte(app.Config.BackupSigningKey),
+		app.Logger,
+	)
+	transactionWatcher := service.NewTransactionWatcher()
+	transactionEvents.Subscribe(transactionWatcher)
 	app.WalletService = service.NewWalletService(
-		app.DB, // This is the DBTxBeginner
-		app.DB, // This is the DBExecutor
+		app.DB,     // This is the DBTxBeginner
+		dbExecutor, // This is the DBExecutor
+		app.UserRepository,
+		app.WalletRepository,
+		app.TransactionRepository,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		service.WithArchiveHorizon(app.Config.Archive.HorizonMonths),
+		service.WithFeatureFlags(app.FeatureFlagService),
+		service.WithRollouts(app.Rollouts),
+		service.WithTransactionEvents(transactionEvents),
+		service.WithApprovalPolicies(app.WalletMemberRepository),
+		service.WithBudgets(app.BudgetRepository),
+		service.WithPromoCredits(app.PromoCreditRepository),
+		service.WithTransferQuotes(app.TransferQuoteRepository),
+		service.WithDuplicateTransferWindow(app.Config.DuplicateWindow),
+		service.WithPolicy(policy),
+		service.WithScreener(service.NewDenylistScreener(dbExecutor, app.ScreeningRepository, app.Logger)),
+		service.WithCurrencyRestrictions(app.RestrictionRepository),
+		service.WithVolumeQuotas(app.UsageService),
+		service.WithTransactionWatcher(transactionWatcher),
+		service.WithWalletSerializer(app.SerializationService),
+		service.WithBalanceSharder(app.BalanceShardService),
+		service.WithAsyncTransfers(app.AsyncTransferRepository, app.Config.AsyncTransfer.RetryBackoff, app.Config.AsyncTransfer.MaxRetryBackoff),
+		service.WithImpersonations(app.ImpersonationRepository),
+		service.WithCryptoPayouts(app.CryptoRepository),
+		service.WithRamps(app.RampRepository),
+		service.WithIdentities(app.IdentityRepository),
+		service.WithSCIM(app.SCIMRepository),
+		service.WithClock(app.Clock),
+	)
+	descriptionTemplates, err := service.LoadDescriptionTemplates(app.Config.DescriptionsFile)
+	if err != nil {
+		return err
+	}
+	descriptions := service.NewDescriptionRenderer(descriptionTemplates)
+	// Push notifications are sent on the platforms with credentials; devices can be registered either way
+	pushSenders := map[domain.PushPlatform]service.PushSender{}
+	if app.Config.Push.FCMCredentialsFile != "" {
+		credentials, err := os.ReadFile(app.Config.Push.FCMCredentialsFile)
+		if err != nil {
+			return fmt.Errorf("failed to read FCM credentials: %w", err)
+		}
+		if pushSenders[domain.PushPlatformFCM], err = service.NewFCMSender(credentials, app.Config.Push.Timeout); err != nil {
+			return err
+		}
+	}
+	if app.Config.Push.APNsKeyFile != "" {
+		key, err := os.ReadFile(app.Config.Push.APNsKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read APNs signing key: %w", err)
+		}
+		push := app.Config.Push
+		if pushSenders[domain.PushPlatformAPNs], err = service.NewAPNsSender(key, push.APNsKeyID, push.APNsTeamID, push.APNsTopic, push.APNsSandbox, push.Timeout); err != nil {
+			return err
+		}
+	}
+	app.NotificationService = service.NewNotificationService(
+		app.DB,
+		dbExecutor,
+		app.NotificationRepository,
+		app.UserRepository,
+		app.WalletRepository,
+		descriptions,
+		pushSenders,
+		app.Config.Push.BatchSize,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	// Sweep rules execute through the wallet service and react to the transactions it publishes
+	app.SweepRuleService = service.NewSweepRuleService(dbExecutor, app.SweepRuleRepository, app.WalletRepository, app.WalletService, app.NotificationService, app.Logger)
+	transactionEvents.Subscribe(app.SweepRuleService)
+	app.BudgetService = service.NewBudgetService(dbExecutor, app.BudgetRepository, app.TransactionRepository, app.NotificationService, app.Logger)
+	transactionEvents.Subscribe(app.BudgetService)
+	transactionEvents.Subscribe(app.NotificationService)
+	app.JointWalletService = service.NewJointWalletService(
+		app.DB,
+		dbExecutor,
+		app.WalletMemberRepository,
+		app.TransferApprovalRepository,
+		app.WalletRepository,
+		app.WalletService,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	app.MerchantService = service.NewMerchantService(
+		app.DB,
+		dbExecutor,
+		app.MerchantRepository,
+		app.ChargeRepository,
+		app.WalletRepository,
+		balances,
+		app.TransactionRepository,
+		app.Config.Merchant.ChargeTTL,
+		transactionEvents,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	app.BillService = service.NewBillService(
+		app.DB,
+		dbExecutor,
+		app.BillRepository,
+		app.WalletRepository,
+		balances,
+		app.TransactionRepository,
+		app.Config.Bill.ReminderInterval,
+		transactionEvents,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	app.VoucherService = service.NewVoucherService(
+		app.DB,
+		dbExecutor,
+		app.VoucherRepository,
+		app.WalletRepository,
+		balances,
+		app.TransactionRepository,
+		transactionEvents,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	app.PromoCreditService = service.NewPromoCreditService(dbExecutor, app.PromoCreditRepository, app.Logger)
+	app.ChangeService = service.NewChangeService(dbExecutor, app.ChangeRepository, app.Config.ChangeSettleDelay, app.Logger)
+	app.DeletionService = service.NewDeletionService(
+		app.DB,
+		dbExecutor,
+		app.UserRepository,
+		app.WalletRepository,
+		app.Config.Deletion.Retention,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	app.DormancyService = service.NewDormancyService(
+		app.DB,
+		dbExecutor,
+		app.UserRepository,
+		app.WalletRepository,
+		service.DormancyPolicy{InactiveMonths: app.Config.Dormancy.InactiveMonths, Freeze: app.Config.Dormancy.Freeze},
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	if app.Config.Export.Dir != "" {
+		app.ExportService = service.NewExportService(
+			dbExecutor,
+			app.ExportRepository,
+			service.NewDirObjectStore(app.Config.Export.Dir),
+			app.Config.Export.BatchSize,
+			app.Config.Export.SettleDelay,
+			app.Logger,
+		)
+	}
+	app.ImpersonationService = service.NewImpersonationService(
+		dbExecutor,
+		app.ImpersonationRepository,
+		app.UserRepository,
+		app.Config.Admin.ImpersonationTTL,
+		app.Logger,
+	)
+	chartOfAccounts, err := service.LoadChartOfAccounts(app.Config.AccountsFile)
+	if err != nil {
+		return err
+	}
+	app.JournalService = service.NewJournalService(dbExecutor, app.ExportRepository, chartOfAccounts, app.Config.Export.BatchSize, app.Logger)
+	app.ReportService = service.NewReportService(dbExecutor, app.ReportRepository, app.Config.ReportCacheTTL, app.Logger)
+	app.AnnotationService = service.NewAnnotationService(dbExecutor, app.AnnotationRepository, app.TransactionRepository, app.Logger)
+	app.InvestigationService = service.NewInvestigationService(
+		dbExecutor,
+		app.InvestigationRepository,
+		app.TransactionRepository,
+		app.WalletRepository,
+		app.UserRepository,
+		app.AnnotationRepository,
+		app.Logger,
+	)
+	app.TxVerification = service.NewTransactionVerificationService(dbExecutor, app.TransactionRepository, app.IntegrityRepository, app.InvestigationRepository, app.Logger)
+	exportSigningKey := []byte(app.Config.WalletExport.SigningKey)
+	if len(exportSigningKey) == 0 {
+		exportSigningKey = make([]byte, 32)
+		if _, err := rand.Read(exportSigningKey); err != nil {
+			return fmt.Errorf("failed to generate export signing key: %w", err)
+		}
+		app.Logger.Warn("WALLET_EXPORT_SIGNING_KEY is not set; export download URLs are only valid on this instance until it restarts")
+	}
+	app.WalletExportService = service.NewWalletExportService(
+		dbExecutor,
+		app.WalletExportRepository,
+		service.NewDirObjectStore(app.Config.WalletExport.Dir),
+		descriptions,
+		policy,
+		app.AuditService,
+		exportSigningKey,
+		app.Config.WalletExport.URLTTL,
+		app.Config.WalletExport.StaleAfter,
+		app.Config.WalletExport.PageSize,
+		app.Logger,
+	)
+	app.ArchiveService = service.NewArchiveService(
+		app.DB,
+		dbExecutor,
+		app.ArchiveRepository,
+		app.Config.Archive.HorizonMonths,
+		app.Logger,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+	)
+	app.FXService = service.NewFXService(dbExecutor, app.FXRateRepository, app.Config.FX.CacheTTL, app.Config.FX.MaxRateAge, app.Logger)
+	app.TransferQuoteService = service.NewTransferQuoteService(dbExecutor, app.TransferQuoteRepository, app.FXService, app.Config.TransferQuoteTTL)
+	var gateway service.PaymentGateway // Auto top-ups are disabled without a gateway
+	if app.Config.AutoTopUp.GatewayURL != "" {
+		gateway = service.NewHTTPPaymentGateway(app.Config.AutoTopUp.GatewayURL, app.Config.AutoTopUp.GatewayAPIKey, app.Config.AutoTopUp.GatewayTimeout)
+	}
+	app.AutoTopUpService = service.NewAutoTopUpService(
+		app.DB,
+		dbExecutor,
+		app.AutoTopUpRepository,
+		app.WalletRepository,
+		balances,
+		app.TransactionRepository,
+		gateway,
+		transactionEvents,
+		app.Config.AutoTopUp.Cooldown,
+		app.Config.AutoTopUp.MaxFailures,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	var chainGateway service.ChainGateway // Crypto wallets are disabled without a gateway
+	if app.Config.Crypto.GatewayURL != "" {
+		chainGateway = service.NewHTTPChainGateway(app.Config.Crypto.GatewayURL, app.Config.Crypto.GatewayAPIKey, app.Config.Crypto.GatewayTimeout)
+	}
+	app.CryptoService = service.NewCryptoService(
+		app.DB,
+		dbExecutor,
+		app.CryptoRepository,
+		app.WalletRepository,
+		balances,
+		app.TransactionRepository,
+		app.WalletService,
+		chainGateway,
+		transactionEvents,
+		app.Config.Crypto.PayoutStaleAfter,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	app.RampService = service.NewRampService(dbExecutor, app.RampRepository, app.TransferQuoteService, app.WalletService, chainGateway, app.Logger)
+	app.OpenBankingService = service.NewOpenBankingService(
+		dbExecutor,
+		app.OpenBankingRepository,
+		app.WalletRepository,
+		app.WalletService,
+		app.Config.OpenBanking.MaxConsentAge,
+		app.Logger,
+	)
+	oidcProviders := make(map[string]service.OIDCProvider, len(app.Config.OIDC.Providers))
+	for name, provider := range app.Config.OIDC.Providers {
+		oidcProviders[name] = service.OIDCProvider(provider)
+	}
+	app.IdentityService = service.NewIdentityService(
+		dbExecutor,
+		app.IdentityRepository,
+		app.WalletService,
+		service.NewIDTokenVerifier(oidcProviders, app.Config.OIDC.JWKSCacheTTL, app.Config.OIDC.Timeout),
+		app.Logger,
+	)
+	scimCurrencies := make(map[string]string, len(app.Config.SCIM.Tenants))
+	scimTokens := make(map[string]string, len(app.Config.SCIM.Tenants))
+	for name, tenant := range app.Config.SCIM.Tenants {
+		scimCurrencies[name] = tenant.DefaultCurrency
+		scimTokens[name] = tenant.Token
+	}
+	app.SCIMService = service.NewSCIMService(
+		app.DB,
+		dbExecutor,
+		app.SCIMRepository,
 		app.UserRepository,
+		app.WalletService,
+		scimCurrencies,
+		app.Logger,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+	)
+	app.NettingService = service.NewNettingService(
+		app.DB,
+		dbExecutor,
+		app.NettingRepository,
+		app.WalletRepository,
+		balances,
+		app.TransactionRepository,
+		transactionEvents,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	app.SuspenseService = service.NewSuspenseService(
+		app.DB,
+		dbExecutor,
+		app.SuspenseRepository,
+		app.WalletRepository,
+		balances,
+		app.UserRepository,
+		app.TransactionRepository,
+		app.AnnotationRepository,
+		transactionEvents,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	app.AdjustmentService = service.NewAdjustmentService(
+		app.DB,
+		dbExecutor,
+		app.AdjustmentRepository,
 		app.WalletRepository,
+		balances,
 		app.TransactionRepository,
-		db.BeginTx,
+		app.AnnotationRepository,
+		transactionEvents,
+		beginTx,
 		db.CommitTx,
 		db.RollbackTx,
+		app.Logger,
 	)
+	app.OwnershipService = service.NewOwnershipTransferService(
+		app.DB,
+		dbExecutor,
+		app.OwnershipRepository,
+		app.WalletRepository,
+		app.UserRepository,
+		app.AuditRepository,
+		app.RestrictionRepository,
+		app.Config.OwnershipTransfer.TTL,
+		app.Clock,
+		app.Logger,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+	)
+	app.ScreeningService = service.NewScreeningService(dbExecutor, app.ScreeningRepository, app.Logger)
+	app.RestrictionService = service.NewCurrencyRestrictionService(dbExecutor, app.RestrictionRepository, app.Logger)
+	// New-device alerts land in the user's inbox, and are pushed when a push service is configured
+	app.SecurityService = service.NewSecurityService(dbExecutor, app.AuthEventRepository, app.UserRepository, app.NotificationService, app.Logger)
+	app.PINService = service.NewPINService(
+		app.DB,
+		dbExecutor,
+		app.PINRepository,
+		app.UserRepository,
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	app.VerificationService = service.NewContactVerificationService(
+		app.DB,
+		dbExecutor,
+		app.VerificationRepository,
+		app.UserRepository,
+		service.NewLogContactVerificationSender(app.Logger),
+		beginTx,
+		db.CommitTx,
+		db.RollbackTx,
+		app.Logger,
+	)
+	if userKeyring != nil {
+		app.PIIService = service.NewPIIReencryptionService(
+			app.DB,
+			app.UserRepository,
+			app.Config.PII.ReencryptBatchSize,
+			beginTx,
+			db.CommitTx,
+			db.RollbackTx,
+			app.Logger,
+		)
+	}
 	app.Logger.Info("Services initialized.")
 
 	// 6. Initialize HTTP Handlers and Router
-	walletHandler := handler.NewWalletHandler(app.WalletService, app.Logger)
-	app.HTTPHandler = router.NewRouter(walletHandler, app.Logger)
+	if app.Config.MoneyFormat == config.MoneyFormatNumber {
+		handler.SetMoneyFormat(handler.MoneyAsNumber)
+	}
+	handler.SetCachePolicy(handler.CachePolicy{
+		Transactions: app.Config.HTTPCache.TransactionsMaxAge,
+		Reports:      app.Config.HTTPCache.ReportsMaxAge,
+	})
+	app.Maintenance = apimiddleware.NewMaintenanceSwitch(app.Config.Admin.ReadOnly, app.Config.Admin.ReadOnlyRetryAfter)
+	shed := app.Config.LoadShed
+	loadShedder := apimiddleware.NewLoadShedder(apimiddleware.LoadShedConfig{
+		Read:     apimiddleware.ClassLimit{MaxConcurrent: shed.Read.Concurrency, MaxQueue: shed.Read.Queue, MaxWait: shed.Read.MaxWait},
+		Movement: apimiddleware.ClassLimit{MaxConcurrent: shed.Movement.Concurrency, MaxQueue: shed.Movement.Queue, MaxWait: shed.Movement.MaxWait},
+		MovementRoutes: []string{
+			"/wallets/*/deposit", "/wallets/*/withdraw", "/transfers", "/transfers/split",
+			"/charges/*/pay", "/bills/*/pay", "/vouchers/redeem", "/netting/instructions", "/transfer-approvals/*/approve",
+		},
+		ExemptRoutes:  []string{"/transactions/*/wait"}, // Long polls hold no connection while waiting
+		ShedPoolUsage: shed.PoolUsagePct,
+		RetryAfter:    shed.RetryAfter,
+	}, app.DB.Stats)
+	// In sandbox mode the wallet API forces magic deposits, withdrawals and transfers to their outcomes;
+	// background jobs and other services keep the real ones
+	walletAPI := app.WalletService
+	if app.Config.Sandbox {
+		walletAPI = service.NewSandboxWalletService(app.WalletService, dbExecutor, app.WalletRepository, app.UserRepository)
+		app.Logger.Warn("Sandbox mode enabled: magic amounts and usernames force transaction outcomes", "database", app.Config.DB.DBName)
+	}
+	// Jobs are registered below; the sandbox's test clock can run them all when it is advanced
+	app.Scheduler = jobs.NewScheduler(app.Logger)
+	handlers := router.Handlers{
+		Wallet:        handler.NewWalletHandler(walletAPI, app.JointWalletService, descriptions, app.PINService, app.Logger),
+		Admin:         handler.NewAdminHandler(app.Maintenance, app.FeatureFlagService, app.QueryTimer, app.PoolMonitor, loadShedder, app.ShadowReads, app.Rollouts, app.Logger),
+		FX:            handler.NewFXHandler(app.FXService, app.Logger),
+		Sweep:         handler.NewSweepRuleHandler(app.SweepRuleService, app.WalletService, app.Logger),
+		Joint:         handler.NewJointWalletHandler(app.JointWalletService, app.WalletService, app.Logger),
+		Budget:        handler.NewBudgetHandler(app.BudgetService, app.WalletService, app.Logger),
+		Merchant:      handler.NewMerchantHandler(app.MerchantService, app.WalletService, app.Logger),
+		Bill:          handler.NewBillHandler(app.BillService, app.WalletService, app.Logger),
+		Voucher:       handler.NewVoucherHandler(app.VoucherService, app.WalletService, app.Logger),
+		Promo:         handler.NewPromoCreditHandler(app.PromoCreditService, app.WalletService, app.Logger),
+		Change:        handler.NewChangeHandler(app.ChangeService, app.Logger),
+		Deletion:      handler.NewDeletionHandler(app.DeletionService, app.WalletService, app.Logger),
+		Dormancy:      handler.NewDormancyHandler(app.DormancyService, app.WalletService, app.Logger),
+		Impersonation: handler.NewImpersonationHandler(app.ImpersonationService, app.Logger),
+		Annotation:    handler.NewAnnotationHandler(app.AnnotationService, app.WalletService, app.Logger),
+		WalletExport:  handler.NewWalletExportHandler(app.WalletExportService, app.WalletService, app.Logger),
+		TransferQuote: handler.NewTransferQuoteHandler(app.TransferQuoteService, app.WalletService, app.Logger),
+		AutoTopUp:     handler.NewAutoTopUpHandler(app.AutoTopUpService, app.WalletService, app.Logger),
+		Crypto:        handler.NewCryptoHandler(app.CryptoService, app.WalletService, app.Logger),
+		Ramp:          handler.NewRampHandler(app.RampService, app.WalletService, app.Logger),
+		OpenBanking:   handler.NewOpenBankingHandler(app.OpenBankingService, app.Logger),
+		Identity:      handler.NewIdentityHandler(app.IdentityService, app.Logger),
+		SCIM:          handler.NewSCIMHandler(app.SCIMService, app.Logger),
+		Netting:       handler.NewNettingHandler(app.NettingService, app.WalletService, app.Logger),
+		Journal:       handler.NewJournalHandler(app.JournalService, app.Logger),
+		Report:        handler.NewReportHandler(app.ReportService, app.Logger),
+		Suspense:      handler.NewSuspenseHandler(app.SuspenseService, app.WalletService, app.Logger),
+		Adjustment:    handler.NewAdjustmentHandler(app.AdjustmentService, app.WalletService, app.Logger),
+		Ownership:     handler.NewOwnershipTransferHandler(app.OwnershipService, app.WalletService, app.Logger),
+		Screening:     handler.NewScreeningHandler(app.ScreeningService, app.Logger),
+		Restriction:   handler.NewCurrencyRestrictionHandler(app.RestrictionService, app.Logger),
+		Security:      handler.NewSecurityHandler(app.SecurityService, app.Logger),
+		PIN:           handler.NewPINHandler(app.PINService, app.Logger),
+		Verification:  handler.NewContactVerificationHandler(app.VerificationService, app.Logger),
+		Notification:  handler.NewNotificationHandler(app.NotificationService, app.Logger),
+		Usage:         handler.NewUsageHandler(app.UsageService, app.Logger),
+		Serialization: handler.NewWalletSerializationHandler(app.SerializationService, app.WalletService, app.Logger),
+		BalanceShards: handler.NewBalanceShardHandler(app.BalanceShardService, app.WalletService, app.Logger),
+		Migration:     handler.NewMigrationHandler(app.MigrationService, app.Logger),
+		Retention:     handler.NewRetentionHandler(app.RetentionService, app.Logger),
+		Backup:        handler.NewBackupVerificationHandler(app.BackupVerification, app.Logger),
+		Investigation: handler.NewInvestigationHandler(app.InvestigationService, app.Logger),
+		TxVerify:      handler.NewTransactionVerificationHandler(app.TxVerification, app.Logger),
+		Audit:         handler.NewAuditHandler(app.AuditService, app.WalletService, app.Logger),
+	}
+	if testClock, ok := app.Clock.(*clock.Test); ok {
+		handlers.Clock = handler.NewClockHandler(testClock, app.Scheduler, app.Logger)
+	}
+	opts := router.Options{
+		AdminToken: app.Config.Admin.Token,
+		AdminUsers: app.Config.Admin.Users,
+		OpenBanking: router.OpenBankingOptions{
+			TokenKey:    []byte(app.Config.OpenBanking.TokenKey),
+			TokenIssuer: app.Config.OpenBanking.TokenIssuer,
+		},
+		SCIMTokens: scimTokens,
+		Providers:  app.Config.Signing.PaymentProviders,
+		Middlewares: []func(http.Handler) http.Handler{
+			loadShedder.Middleware, // Before the others, so shed requests cost no query
+			app.Maintenance.Middleware,
+			apimiddleware.NewImpersonationGuard(app.ImpersonationService, app.Logger).Middleware,
+		},
+	}
+	if len(app.Config.Chaos) > 0 {
+		rules := make([]apimiddleware.ChaosRule, 0, len(app.Config.Chaos))
+		for _, rule := range app.Config.Chaos {
+			rules = append(rules, apimiddleware.ChaosRule{
+				Method:      rule.Method,
+				Path:        rule.Path,
+				Latency:     rule.Latency,
+				LatencyRate: rule.LatencyRate,
+				ErrorRate:   rule.ErrorRate,
+				DBDropRate:  rule.DBDropRate,
+			})
+		}
+		// First, so faults hit requests before any other middleware has handled them
+		opts.Middlewares = append([]func(http.Handler) http.Handler{apimiddleware.NewChaosInjector(rules, app.Logger).Middleware}, opts.Middlewares...)
+		app.Logger.Warn("Fault injection is enabled", "environment", app.Config.Environment, "rules", len(rules))
+	}
+	if tlsCfg := app.Config.TLS; tlsCfg.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(tlsCfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA certificates: %w", err)
+		}
+		if app.TLSConfig, err = apimiddleware.ClientCertTLSConfig(caPEM, tlsCfg.RequireClientCert); err != nil {
+			return fmt.Errorf("invalid MTLS_CLIENT_CA_FILE: %w", err)
+		}
+		identifier := apimiddleware.NewServiceIdentifier(tlsCfg.ServiceIdentities, app.Logger)
+		opts.Middlewares = append(opts.Middlewares, identifier.Middleware)
+		app.Logger.Info("Client certificate authentication enabled", "required", tlsCfg.RequireClientCert, "services", len(tlsCfg.ServiceIdentities))
+	}
+	if len(app.Config.Signing.Partners) > 0 {
+		var replays apimiddleware.ReplayCache // In memory without Redis, which is enough for a single node
+		if app.Redis != nil {
+			replays = apimiddleware.NewRedisReplayCache(app.Redis, app.Config.Redis.KeyPrefix)
+		}
+		signer := apimiddleware.NewRequestSigner(app.Config.Signing.Partners, app.Config.Signing.MaxSkew, replays, app.Logger)
+		opts.Middlewares = append(opts.Middlewares, signer.Middleware, apimiddleware.NewUsageMeter(app.UsageService, app.Logger).Middleware)
+	}
+	if compression := app.Config.Compression; compression.Enabled {
+		// First, so partners' responses are signed before they are compressed
+		compressor := apimiddleware.NewCompressor(compression.Level, compression.MinSize, compression.ContentTypes)
+		opts.Middlewares = append([]func(http.Handler) http.Handler{compressor.Middleware}, opts.Middlewares...)
+	}
+	app.HTTPHandler = router.NewRouter(handlers, opts, app.Logger)
 	app.Logger.Info("HTTP router and handlers initialized.")
 
+	// 7. Register Background Jobs (started separately via StartBackgroundJobs)
+	if app.Config.Archive.HorizonMonths > 0 {
+		app.Scheduler.Register(jobs.Job{
+			Name:     "transaction-archival",
+			Interval: app.Config.Archive.Interval,
+			Run: func(ctx context.Context) error {
+				now := app.Clock.Now().UTC()
+				if err := app.ArchiveService.EnsurePartitions(ctx, now); err != nil {
+					return err
+				}
+				_, err := app.ArchiveService.ArchiveExpiredPartitions(ctx, now)
+				return err
+			},
+		})
+	}
+	app.Scheduler.Register(jobs.Job{
+		Name:     "fx-rate-refresh",
+		Interval: app.Config.FX.RefreshInterval,
+		Run:      app.FXService.Refresh,
+	})
+	app.Scheduler.Register(jobs.Job{
+		Name:     "merchant-settlement",
+		Interval: app.Config.Merchant.SettlementInterval,
+		Run: func(ctx context.Context) error {
+			_, err := app.MerchantService.SettleMerchants(ctx, app.Clock.Now().UTC())
+			return err
+		},
+	})
+	app.Scheduler.Register(jobs.Job{
+		Name:     "bill-reminders",
+		Interval: app.Config.Bill.CheckInterval,
+		Run: func(ctx context.Context) error {
+			_, err := app.BillService.SendReminders(ctx, app.Clock.Now().UTC())
+			return err
+		},
+	})
+	app.Scheduler.Register(jobs.Job{
+		Name:     "promo-credit-expiry",
+		Interval: app.Config.PromoExpiryInterval,
+		Run: func(ctx context.Context) error {
+			_, err := app.PromoCreditService.ExpireCredits(ctx, app.Clock.Now().UTC())
+			return err
+		},
+	})
+	if app.ExportService != nil {
+		app.Scheduler.Register(jobs.Job{
+			Name:     "warehouse-export",
+			Interval: app.Config.Export.Interval,
+			Run: func(ctx context.Context) error {
+				_, err := app.ExportService.Export(ctx, app.Clock.Now().UTC())
+				return err
+			},
+		})
+	}
+	app.Scheduler.Register(jobs.Job{
+		Name:     "wallet-export-worker",
+		Interval: app.Config.WalletExport.PollInterval,
+		Run: func(ctx context.Context) error {
+			_, err := app.WalletExportService.Work(ctx, app.Clock.Now().UTC())
+			return err
+		},
+	})
+	app.Scheduler.Register(jobs.Job{
+		Name:     "deleted-record-purge",
+		Interval: app.Config.Deletion.PurgeInterval,
+		Run: func(ctx context.Context) error {
+			_, err := app.DeletionService.Purge(ctx, app.Clock.Now().UTC())
+			return err
+		},
+	})
+	if app.Config.Dormancy.InactiveMonths > 0 {
+		app.Scheduler.Register(jobs.Job{
+			Name:     "wallet-dormancy",
+			Interval: app.Config.Dormancy.CheckInterval,
+			Run: func(ctx context.Context) error {
+				_, err := app.DormancyService.FlagDormant(ctx, app.Clock.Now().UTC())
+				return err
+			},
+		})
+	}
+	app.Scheduler.Register(jobs.Job{
+		Name:     "ownership-transfer-expiry",
+		Interval: app.Config.OwnershipTransfer.ExpiryInterval,
+		Run: func(ctx context.Context) error {
+			_, err := app.OwnershipService.ExpireTransfers(ctx, app.Clock.Now().UTC())
+			return err
+		},
+	})
+	if app.Config.AutoTopUp.GatewayURL != "" {
+		app.Scheduler.Register(jobs.Job{
+			Name:     "auto-top-up",
+			Interval: app.Config.AutoTopUp.Interval,
+			Run: func(ctx context.Context) error {
+				_, err := app.AutoTopUpService.RunTopUps(ctx, app.Clock.Now().UTC())
+				return err
+			},
+		})
+	}
+	if app.Config.Crypto.GatewayURL != "" {
+		app.Scheduler.Register(jobs.Job{
+			Name:     "crypto-deposits",
+			Interval: app.Config.Crypto.DepositPollInterval,
+			Run: func(ctx context.Context) error {
+				_, err := app.CryptoService.PollDeposits(ctx)
+				return err
+			},
+		})
+		app.Scheduler.Register(jobs.Job{
+			Name:     "crypto-payouts",
+			Interval: app.Config.Crypto.PayoutInterval,
+			Run: func(ctx context.Context) error {
+				_, err := app.CryptoService.RunPayouts(ctx, app.Clock.Now().UTC())
+				return err
+			},
+		})
+	}
+	app.Scheduler.Register(jobs.Job{
+		Name:     "balance-shard-rebalance",
+		Interval: app.Config.BalanceShards.RebalanceInterval,
+		Run: func(ctx context.Context) error {
+			_, err := app.BalanceShardService.Rebalance(ctx)
+			return err
+		},
+	})
+	app.Scheduler.Register(jobs.Job{
+		Name:     "db-pool-monitor",
+		Interval: app.Config.DBPool.SampleInterval,
+		Run: func(ctx context.Context) error {
+			app.PoolMonitor.Sample()
+			return nil
+		},
+	})
+	app.Scheduler.Register(jobs.Job{
+		Name:     "schema-backfills",
+		Interval: app.Config.Backfill.Interval,
+		Run: func(ctx context.Context) error {
+			_, err := app.MigrationService.RunBackfills(ctx, app.Config.Backfill.BatchesPerRun)
+			return err
+		},
+	})
+	app.Scheduler.Register(jobs.Job{
+		Name:     "retention-purge",
+		Interval: 10 * time.Minute, // Checks whether the nightly purge is due
+		Run: func(ctx context.Context) error {
+			_, err := app.RetentionService.PurgeNightly(ctx, app.Clock.Now())
+			return err
+		},
+	})
+	app.Scheduler.Register(jobs.Job{
+		Name:     "async-transfer-worker",
+		Interval: app.Config.AsyncTransfer.PollInterval,
+		Run: func(ctx context.Context) error {
+			_, err := app.WalletService.ProcessAsyncTransfers(ctx, app.Config.AsyncTransfer.BatchSize)
+			return err
+		},
+	})
+	app.Scheduler.Register(jobs.Job{
+		Name:     "netting-settlement",
+		Interval: app.Config.NettingWindow,
+		Run: func(ctx context.Context) error {
+			_, err := app.NettingService.SettleWindow(ctx, app.Clock.Now().UTC())
+			return err
+		},
+	})
+	if app.PIIService != nil {
+		app.Scheduler.Register(jobs.Job{
+			Name:     "pii-reencryption",
+			Interval: app.Config.PII.ReencryptInterval,
+			Run: func(ctx context.Context) error {
+				_, err := app.PIIService.Reencrypt(ctx)
+				return err
+			},
+		})
+	}
+	if len(pushSenders) > 0 {
+		app.Scheduler.Register(jobs.Job{
+			Name:     "push-delivery",
+			Interval: app.Config.Push.DeliveryInterval,
+			Run: func(ctx context.Context) error {
+				_, err := app.NotificationService.Deliver(ctx)
+				return err
+			},
+		})
+	}
+	app.Logger.Info("Background jobs registered.")
+
+	return nil
+}
+
+// StartBackgroundJobs starts the periodic background jobs.
+// It is kept separate from Initialize so tests can build the application without running jobs.
+func (app *Application) StartBackgroundJobs(ctx context.Context) {
+	app.Scheduler.Start(ctx)
+}
+
+// initShadowReads connects to the candidate database, when configured, and wraps the wallet and transaction
+// repositories so that a sample of their reads is repeated against it and compared. A refactored repository
+// implementation is tried the same way, by passing it as the candidate.
+func (app *Application) initShadowReads() error {
+	cfg := app.Config.ShadowReads
+	if cfg.DBName == "" {
+		return nil
+	}
+	candidateConfig := app.Config.DB
+	candidateConfig.DBName = cfg.DBName
+	if cfg.DBHost != "" {
+		candidateConfig.Host = cfg.DBHost
+	}
+	candidateDB, err := db.NewPostgresDB(candidateConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the shadow database: %w", err)
+	}
+	app.ShadowDB = candidateDB
+	app.ShadowReads = shadow.NewHarness(shadow.Config{SamplePct: cfg.SamplePct, Timeout: cfg.Timeout, MaxInFlight: cfg.MaxInFlight}, app.Logger)
+	app.WalletRepository = shadow.NewWalletRepository(app.WalletRepository, postgres.NewWalletRepository(candidateDB), candidateDB, app.ShadowReads)
+	app.TransactionRepository = shadow.NewTransactionRepository(app.TransactionRepository, postgres.NewTransactionRepository(candidateDB), candidateDB, app.ShadowReads)
+	app.Logger.Warn("Shadow reads enabled", "candidate", candidateConfig.Host+"/"+candidateConfig.DBName, "sample_pct", cfg.SamplePct)
 	return nil
 }
 
 // Shutdown gracefully shuts down application resources.
 func (app *Application) Shutdown(ctx context.Context) error {
 	app.Logger.Info("Shutting down application...")
+	if app.Scheduler != nil {
+		app.Scheduler.Stop()
+	}
+	if app.StmtCache != nil {
+		if err := app.StmtCache.Close(); err != nil {
+			app.Logger.Error("Failed to close prepared statements", "error", err)
+		}
+	}
+	if app.ShadowDB != nil {
+		if err := app.ShadowDB.Close(); err != nil {
+			app.Logger.Error("Failed to close shadow database connection", "error", err)
+		}
+	}
+	if app.Redis != nil {
+		if err := app.Redis.Close(); err != nil {
+			app.Logger.Error("Failed to close Redis connections", "error", err)
+		}
+	}
 	if app.DB != nil {
 		if err := app.DB.Close(); err != nil {
 			app.Logger.Error("Failed to close database connection", "error", err)
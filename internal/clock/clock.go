@@ -0,0 +1,59 @@
+// internal/clock/clock.go
+package clock
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock tells the current time to the services and background jobs that depend on it.
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the wall clock.
+type System struct{}
+
+// Now returns time.Now().
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// Test is a clock that runs with the wall clock but can be advanced, so that scheduled work, windows and
+// expirations can be exercised without waiting. It must only be used in sandbox mode.
+type Test struct {
+	mu     sync.RWMutex
+	offset time.Duration
+}
+
+// NewTest creates a test clock that starts at the wall clock's time.
+func NewTest() *Test {
+	return &Test{}
+}
+
+// Now returns the wall clock's time plus the advanced offset.
+func (c *Test) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Now().Add(c.offset)
+}
+
+// Advance moves the clock forward by d and returns the new time. Time never goes backwards, as the stored
+// records would then look like they come from the future.
+func (c *Test) Advance(d time.Duration) (time.Time, error) {
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("clock can only be advanced by a positive duration, got %s", d)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.offset += d
+	return time.Now().Add(c.offset), nil
+}
+
+// Offset returns how far the clock has been advanced past the wall clock.
+func (c *Test) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.offset
+}
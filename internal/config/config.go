@@ -2,17 +2,369 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
 	"finflow-wallet/pkg/db" // Import db package for its Config struct
 )
 
 // AppConfig holds all application-wide configurations.
 type AppConfig struct {
-	ServerPort string
-	DB         db.Config
+	Environment         string // EnvironmentProduction disables test-only features such as fault injection
+	ServerPort          string
+	TLS                 TLSConfig
+	MoneyFormat         string // MoneyFormatString or MoneyFormatNumber, for monetary amounts in JSON responses
+	AmountScale         int32  // Decimal places amounts are accepted with, at most domain.MaxAmountScale
+	DB                  db.Config
+	Redis               RedisConfig
+	QueryTiming         QueryTimingConfig
+	DBPool              DBPoolConfig
+	Archive             ArchiveConfig
+	Signing             SigningConfig
+	Admin               AdminConfig
+	FeatureFlagCacheTTL time.Duration
+	SerializationTTL    time.Duration // How long the serialization modes of wallets are cached
+	ReportCacheTTL      time.Duration // How long a generated treasury report is served from cache
+	FX                  FXConfig
+	TransferQuoteTTL    time.Duration // How long a transfer quote can be executed
+	DuplicateWindow     time.Duration // A transfer repeating one within this window is rejected as a duplicate; 0 disables the check
+	NettingWindow       time.Duration // Netting instructions are settled as net transfers each time a window of this length closes
+	DescriptionsFile    string        // JSON file overriding the built-in transaction description templates; optional
+	AccountsFile        string        // JSON chart of accounts mapping transactions to GL accounts for the journal export; optional
+	Merchant            MerchantConfig
+	Bill                BillConfig
+	PromoExpiryInterval time.Duration // How often expired promotional credits are forfeited
+	Export              ExportConfig
+	ChangeSettleDelay   time.Duration // Changes younger than this are held back from the change feed
+	Deletion            DeletionConfig
+	Authz               AuthzConfig
+	WalletExport        WalletExportConfig
+	AutoTopUp           AutoTopUpConfig
+	Crypto              CryptoConfig
+	OpenBanking         OpenBankingConfig
+	OIDC                OIDCConfig
+	SCIM                SCIMConfig
+	PII                 PIIConfig
+	Push                PushConfig
+	Compression         CompressionConfig
+	HTTPCache           HTTPCacheConfig
+	Dormancy            DormancyConfig
+	OwnershipTransfer   OwnershipTransferConfig
+	BalanceShards       BalanceShardConfig
+	Backfill            BackfillConfig
+	Retention           RetentionConfig
+	AsyncTransfer       AsyncTransferConfig
+	LoadShed            LoadShedConfig
+	ShadowReads         ShadowReadsConfig
+	BackupSigningKey    string      // Signs the verification reports of restored backups; verification is refused without it
+	Sandbox             bool        // Magic amounts and usernames force deterministic outcomes, for integrators; never in production
+	Chaos               []ChaosRule // Fault injection rules; empty disables fault injection
+}
+
+// EnvironmentProduction is the APP_ENV value of production deployments.
+const EnvironmentProduction = "production"
+
+// sandboxDBSuffix ends the name of every sandbox database.
+const sandboxDBSuffix = "_sandbox"
+
+// QueryTimingConfig holds the initial settings of query timing, which can be changed at runtime under /admin.
+type QueryTimingConfig struct {
+	Enabled       bool          // Record per-query latency histograms and log slow queries
+	SlowThreshold time.Duration // Queries at least this slow are logged; 0 disables slow query logging
+}
+
+// DBPoolConfig holds settings for the sampling of the connection pool statistics.
+type DBPoolConfig struct {
+	SampleInterval time.Duration // How often the pool statistics are sampled
+	WaitWarn       time.Duration // Average wait for a connection from which a warning is logged; 0 disables the alarms
+	WaitCritical   time.Duration // Average wait for a connection from which an error is logged; 0 only warns
+}
+
+// ArchiveConfig holds settings for the transaction archival job.
+type ArchiveConfig struct {
+	HorizonMonths int           // Months of history kept in the hot table; 0 disables archival
+	Interval      time.Duration // How often partitions are maintained and archived
+}
+
+// RedisConfig holds the connection to the Redis shared by the replicas of a deployment.
+type RedisConfig struct {
+	URL       string        // redis:// or rediss:// URL; empty keeps shared state in memory, for single-node setups
+	Timeout   time.Duration // Bound of connecting and of each command
+	KeyPrefix string        // Start of every key, so that deployments can share a Redis
+}
+
+// SigningConfig holds the shared secrets of partners that sign their requests.
+type SigningConfig struct {
+	Partners         map[string]string       // Partner ID -> shared secret; empty disables signing
+	MaxSkew          time.Duration           // Maximum accepted clock skew of a signed request
+	Quotas           map[string]PartnerQuota // Partner ID -> monthly quota; partners without one are unlimited
+	PaymentProviders []string                // Partner IDs of the payment providers, the only partners that may report inbound funds
+}
+
+// PartnerQuota is the monthly allowance of a partner. Zero or missing limits are unlimited.
+type PartnerQuota struct {
+	Requests int64                      `json:"requests"` // Requests per month
+	Volume   map[string]decimal.Decimal `json:"volume"`   // Currency -> money moved per month, e.g. {"USD": "50000"}
+}
+
+// TLSConfig holds the server certificate of the HTTP server and the mutual TLS of internal callers, which
+// present client certificates naming their service.
+type TLSConfig struct {
+	CertFile          string            // PEM certificate chain of the server; empty serves plain HTTP
+	KeyFile           string            // PEM private key of the server certificate
+	ClientCAFile      string            // PEM CA certificates client certificates are verified against; empty disables mTLS
+	RequireClientCert bool              // Refuse connections without a client certificate instead of serving them as external callers
+	ServiceIdentities map[string]string // SPIFFE ID or DNS name of a client certificate -> name of the internal service
+}
+
+// AdminConfig holds settings for operator-only endpoints.
+type AdminConfig struct {
+	Token              string            // Shared bearer token for /admin routes
+	Users              map[string]string // Admin name -> personal bearer token, for actions attributed to an admin
+	ReadOnly           bool              // Start in read-only maintenance mode
+	ReadOnlyRetryAfter time.Duration     // Retry-After advertised while read-only
+	ImpersonationTTL   time.Duration     // Lifetime of a support impersonation session
+}
+
+// FXConfig holds settings for the exchange rate cache.
+type FXConfig struct {
+	CacheTTL        time.Duration // Age after which the rate table is reloaded on demand
+	RefreshInterval time.Duration // How often the background refresher reloads the rate table
+	MaxRateAge      time.Duration // Rates published longer ago than this are rejected for conversions
+}
+
+// MerchantConfig holds settings for merchant charges and settlements.
+type MerchantConfig struct {
+	ChargeTTL          time.Duration // How long a charge stays payable
+	SettlementInterval time.Duration // How often the settlement job runs; each merchant day is settled once
+}
+
+// BillConfig holds settings for shared bill reminders.
+type BillConfig struct {
+	ReminderInterval time.Duration // Minimum time between two reminders to a participant who still owes money
+	CheckInterval    time.Duration // How often the reminder job runs
+}
+
+// Formats of monetary amounts in JSON responses, selectable with MONEY_JSON_FORMAT.
+const (
+	MoneyFormatString = "string" // "12.50"; the default
+	MoneyFormatNumber = "number" // 12.50; the legacy format, for clients not yet reading strings
+)
+
+// Authorization policies selectable with AUTHZ_POLICY.
+const (
+	AuthzPolicyAllowOwner = "allow-owner"
+	AuthzPolicyOPA        = "opa"
+)
+
+// AuthzConfig selects the authorization policy evaluated by the wallet service.
+type AuthzConfig struct {
+	Policy     string        // AuthzPolicyAllowOwner or AuthzPolicyOPA
+	OPAURL     string        // Decision URL, required for AuthzPolicyOPA
+	OPATimeout time.Duration // Per-decision timeout
+}
+
+// DeletionConfig holds settings for purging soft-deleted users and wallets.
+type DeletionConfig struct {
+	Retention     time.Duration // How long soft-deleted rows can be restored before they are purged
+	PurgeInterval time.Duration // How often the purge job runs
+}
+
+// ExportConfig holds settings for the data warehouse export.
+type ExportConfig struct {
+	Dir         string        // Directory (e.g. a mounted bucket) the export is written to; empty disables the export
+	Interval    time.Duration // How often new transactions are exported
+	BatchSize   int           // Transactions per exported file
+	SettleDelay time.Duration // Transactions younger than this wait for the next run
+}
+
+// WalletExportConfig holds settings for the asynchronous full-history wallet exports.
+type WalletExportConfig struct {
+	Dir          string        // Directory the export files are written to
+	SigningKey   string        // HMAC key of download URLs; empty uses a random key, so URLs die with the process
+	URLTTL       time.Duration // Lifetime of a signed download URL
+	PollInterval time.Duration // How often the worker looks for queued exports
+	StaleAfter   time.Duration // A running export not finished after this is requeued
+	PageSize     int           // Transactions read per query while writing an export
+}
+
+// AutoTopUpConfig holds settings for auto top-ups from linked funding sources and the payments gateway charging them.
+type AutoTopUpConfig struct {
+	GatewayURL     string        // Base URL of the payments gateway API; empty disables auto top-ups
+	GatewayAPIKey  string        // Bearer token for the gateway API
+	GatewayTimeout time.Duration // Per-charge timeout
+	Interval       time.Duration // How often the job looks for wallets below their threshold
+	Cooldown       time.Duration // Minimum time between two attempts of a rule, successful or not
+	MaxFailures    int           // Consecutive failures after which a rule is disabled; 0 never disables
+}
+
+// CryptoConfig holds settings for crypto wallets and the chain gateway issuing their addresses, reporting their
+// deposits and sending their payouts.
+type CryptoConfig struct {
+	GatewayURL          string        // Base URL of the chain gateway API; empty disables crypto wallets
+	GatewayAPIKey       string        // Bearer token for the gateway API
+	GatewayTimeout      time.Duration // Per-request timeout
+	DepositPollInterval time.Duration // How often the gateway is polled for deposits
+	PayoutInterval      time.Duration // How often queued payouts are sent
+	PayoutStaleAfter    time.Duration // A payout still being sent after this is requeued
+}
+
+// OpenBankingConfig holds settings for the Open Banking account information API. Third parties call it with
+// access tokens issued by the authorization server, which shares the token key.
+type OpenBankingConfig struct {
+	TokenKey      string        // HMAC key of the HS256 access tokens; empty disables the API
+	TokenIssuer   string        // Required "iss" of the access tokens
+	MaxConsentAge time.Duration // Longest a consent may last before the user must authorise it again
+}
+
+// OIDCConfig holds settings for delegated login with the ID tokens of OpenID Connect providers.
+type OIDCConfig struct {
+	Providers    map[string]OIDCProvider // Provider name -> settings; with none, delegated login is disabled
+	JWKSCacheTTL time.Duration           // How long the signing keys of a provider are cached
+	Timeout      time.Duration           // Per-request timeout for discovery and key requests
+}
+
+// OIDCProvider is an OpenID Connect provider whose ID tokens are accepted.
+type OIDCProvider struct {
+	Issuer          string   `json:"issuer"`           // Required "iss" of the ID tokens
+	Audiences       []string `json:"audiences"`        // Client IDs, one of which the "aud" of an ID token must contain
+	JWKSURL         string   `json:"jwks_url"`         // Optional; discovered from the issuer's openid-configuration if empty
+	DefaultCurrency string   `json:"default_currency"` // Currency of the wallet of users provisioned at first login
+}
+
+// SCIMConfig holds the enterprise tenants whose identity systems provision users over SCIM 2.0.
+type SCIMConfig struct {
+	Tenants map[string]SCIMTenant // Tenant name -> settings; with none, the SCIM API is disabled
+}
+
+// SCIMTenant is an enterprise tenant allowed to provision users.
+type SCIMTenant struct {
+	Token           string `json:"token"`            // Bearer token of the tenant's identity system
+	DefaultCurrency string `json:"default_currency"` // Currency of the wallet of users the tenant provisions
+}
+
+// PIIConfig holds settings for re-encrypting personal data after a key rotation. The keys themselves are
+// read from the secret provider, not from the config.
+type PIIConfig struct {
+	ReencryptInterval  time.Duration // How often data encrypted with a non-primary key is re-encrypted
+	ReencryptBatchSize int           // Users re-encrypted per transaction
+}
+
+// PushConfig holds settings for push notifications. A platform is enabled by its credentials; without any,
+// devices can still be registered but nothing is sent.
+type PushConfig struct {
+	FCMCredentialsFile string        // Service account key file (JSON) of the Firebase project
+	APNsKeyFile        string        // Token signing key (.p8) of the Apple developer team
+	APNsKeyID          string        // ID of the APNs signing key
+	APNsTeamID         string        // Apple developer team ID
+	APNsTopic          string        // Bundle ID of the iOS app
+	APNsSandbox        bool          // Send to the APNs development environment
+	Timeout            time.Duration // Per-request timeout of the push services
+	DeliveryInterval   time.Duration // How often pending deliveries are sent
+	BatchSize          int           // Deliveries sent per run
+}
+
+// CompressionConfig holds settings for the compression of responses.
+type CompressionConfig struct {
+	Enabled      bool
+	Level        int      // gzip and deflate level, 1 (fastest) to 9 (smallest)
+	MinSize      int      // Responses smaller than this many bytes are sent uncompressed
+	ContentTypes []string // Media types that are compressed, e.g. "application/json"
+}
+
+// HTTPCacheConfig holds how long clients may reuse the responses of read endpoints; zero disables caching.
+type HTTPCacheConfig struct {
+	TransactionsMaxAge time.Duration // Transaction history and search
+	ReportsMaxAge      time.Duration // Treasury reports
+}
+
+// DormancyConfig holds settings for flagging wallets without activity as dormant.
+type DormancyConfig struct {
+	InactiveMonths int           // Months without a balance change after which a wallet is dormant; 0 disables the check
+	Freeze         bool          // Freeze dormant wallets, so no money leaves them until they are reactivated
+	CheckInterval  time.Duration // How often wallets are checked for dormancy
+}
+
+// OwnershipTransferConfig holds settings for admin-mediated transfers of wallets to other users.
+type OwnershipTransferConfig struct {
+	TTL            time.Duration // How long the target user has to accept a transfer; the wallet is frozen meanwhile
+	ExpiryInterval time.Duration // How often unanswered transfers are expired and their wallets unfrozen
+}
+
+// BackfillConfig holds settings for the out-of-band backfills of expand migrations.
+type BackfillConfig struct {
+	Interval      time.Duration // How often the backfill job runs
+	BatchesPerRun int           // Batches run per job run at most, keeping the load on the database low
+}
+
+// RetentionConfig holds the retention periods of the records purged nightly, in days; 0 keeps them forever.
+type RetentionConfig struct {
+	AuthEventsDays             int  // Login audit
+	ImpersonationAuditDays     int  // Requests made by support impersonating users
+	NotificationDeliveriesDays int  // Push deliveries no longer pending
+	NotificationsDays          int  // In-app inbox
+	BatchSize                  int  // Records deleted per statement
+	PurgeHour                  int  // Hour of the day, in UTC, from which the nightly purge runs
+	DryRun                     bool // Only count the records the nightly purge would delete
+}
+
+// BalanceShardConfig holds settings for the balance shards of high-throughput wallets.
+type BalanceShardConfig struct {
+	CacheTTL          time.Duration // How long the shard counts of wallets are cached
+	RebalanceInterval time.Duration // How often the balances of sharded wallets are spread evenly over their shards
+}
+
+// AsyncTransferConfig holds settings for the worker making the transfers accepted with async=true.
+type AsyncTransferConfig struct {
+	PollInterval    time.Duration // How often the worker looks for accepted transfers
+	BatchSize       int           // Transfers made per run at most
+	RetryBackoff    time.Duration // Delay before retrying a transfer that failed for a passing reason, doubled per attempt
+	MaxRetryBackoff time.Duration // Longest delay between retries
+}
+
+// LoadShedConfig holds settings for the concurrency limits shedding load in traffic spikes.
+type LoadShedConfig struct {
+	Read         ClassLimitConfig // Requests moving no money
+	Movement     ClassLimitConfig // Deposits, withdrawals, transfers and payments
+	PoolUsagePct int              // Share of the DB pool in use from which reads are shed at once; 0 disables
+	RetryAfter   time.Duration    // Retry-After advertised on shed requests
+}
+
+// ClassLimitConfig bounds the concurrent requests of a route class.
+type ClassLimitConfig struct {
+	Concurrency int           // Requests served at once; 0 disables the limit
+	Queue       int           // Requests waiting for a slot beyond Concurrency
+	MaxWait     time.Duration // Longest a request waits for a slot
+}
+
+// ShadowReadsConfig holds settings for repeating a sample of the repository reads against a candidate
+// database, to compare it with the current one before a persistence migration.
+type ShadowReadsConfig struct {
+	DBHost      string        // Host of the candidate database; the current host if empty
+	DBName      string        // Name of the candidate database; empty disables shadow reads
+	SamplePct   int           // Share of the reads repeated against the candidate, 0 to 100
+	Timeout     time.Duration // Longest a candidate read may take
+	MaxInFlight int           // Candidate reads running at once; sampled reads beyond it are skipped
+}
+
+// ChaosRule injects faults into the requests matching Method and Path, for testing clients in staging.
+type ChaosRule struct {
+	Method      string        `json:"method"` // Empty matches any method
+	Path        string        `json:"path"`   // A path.Match pattern, e.g. "/wallets/*/withdraw"
+	Latency     time.Duration `json:"-"`
+	RawLatency  string        `json:"latency"` // e.g. "500ms"
+	LatencyRate float64       `json:"latency_rate"`
+	ErrorRate   float64       `json:"error_rate"`
+	DBDropRate  float64       `json:"db_drop_rate"`
 }
 
 // LoadConfig loads configuration from environment variables.
@@ -51,9 +403,564 @@ func LoadConfig() (*AppConfig, error) {
 	if dbSSLMode == "" {
 		dbSSLMode = "disable" // Default to disable for local development
 	}
+	dbPrepareStatements, err := getEnvBool("DB_PREPARE_STATEMENTS", true)
+	if err != nil {
+		return nil, err
+	}
+	dbReadRetries, err := getEnvInt("DB_READ_RETRIES", 2)
+	if err != nil {
+		return nil, err
+	}
+	if dbReadRetries < 0 {
+		return nil, fmt.Errorf("DB_READ_RETRIES must not be negative")
+	}
+	dbReadRetryBackoff, err := getEnvDuration("DB_READ_RETRY_BACKOFF", 50*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveHorizon, err := getEnvInt("TX_ARCHIVE_HORIZON_MONTHS", 12)
+	if err != nil {
+		return nil, err
+	}
+	archiveInterval, err := getEnvDuration("TX_ARCHIVE_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	redisTimeout, err := getEnvDuration("REDIS_TIMEOUT", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	redisKeyPrefix := os.Getenv("REDIS_KEY_PREFIX")
+	if redisKeyPrefix == "" {
+		redisKeyPrefix = "finflow:"
+	}
+	partners, err := getEnvPairs("PARTNER_SIGNING_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	adminUsers, err := getEnvPairs("ADMIN_USERS")
+	if err != nil {
+		return nil, err
+	}
+	signatureMaxSkew, err := getEnvDuration("SIGNATURE_MAX_SKEW", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	partnerQuotas, err := getEnvPartnerQuotas("PARTNER_QUOTAS", partners)
+	if err != nil {
+		return nil, err
+	}
+	var paymentProviders []string
+	if raw := os.Getenv("PAYMENT_PROVIDERS"); raw != "" {
+		for _, partnerID := range strings.Split(raw, ",") {
+			partnerID = strings.TrimSpace(partnerID)
+			if _, ok := partners[partnerID]; !ok {
+				return nil, fmt.Errorf("invalid PAYMENT_PROVIDERS: partner %q has no key in PARTNER_SIGNING_KEYS", partnerID)
+			}
+			paymentProviders = append(paymentProviders, partnerID)
+		}
+	}
+
+	readOnly, err := getEnvBool("MAINTENANCE_READ_ONLY", false)
+	if err != nil {
+		return nil, err
+	}
+	readOnlyRetryAfter, err := getEnvDuration("MAINTENANCE_RETRY_AFTER", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	impersonationTTL, err := getEnvDuration("IMPERSONATION_TTL", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	featureFlagCacheTTL, err := getEnvDuration("FEATURE_FLAG_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	serializationTTL, err := getEnvDuration("WALLET_SERIALIZATION_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	reportCacheTTL, err := getEnvDuration("REPORT_CACHE_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	fxCacheTTL, err := getEnvDuration("FX_RATE_CACHE_TTL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	fxRefreshInterval, err := getEnvDuration("FX_REFRESH_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	fxMaxRateAge, err := getEnvDuration("FX_MAX_RATE_AGE", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	transferQuoteTTL, err := getEnvDuration("TRANSFER_QUOTE_TTL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	duplicateWindow, err := getEnvDuration("DUPLICATE_TRANSFER_WINDOW", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	chargeTTL, err := getEnvDuration("MERCHANT_CHARGE_TTL", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	settlementInterval, err := getEnvDuration("MERCHANT_SETTLEMENT_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	billReminderInterval, err := getEnvDuration("BILL_REMINDER_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	billCheckInterval, err := getEnvDuration("BILL_REMINDER_CHECK_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	promoExpiryInterval, err := getEnvDuration("PROMO_EXPIRY_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	exportInterval, err := getEnvDuration("EXPORT_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	exportBatchSize, err := getEnvInt("EXPORT_BATCH_SIZE", 10000)
+	if err != nil {
+		return nil, err
+	}
+	if exportBatchSize < 1 {
+		return nil, fmt.Errorf("EXPORT_BATCH_SIZE must be positive")
+	}
+	exportSettleDelay, err := getEnvDuration("EXPORT_SETTLE_DELAY", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	changeSettleDelay, err := getEnvDuration("CHANGES_SETTLE_DELAY", 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedRetention, err := getEnvDuration("DELETED_RETENTION", 90*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	purgeInterval, err := getEnvDuration("PURGE_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	moneyFormat := os.Getenv("MONEY_JSON_FORMAT")
+	if moneyFormat == "" {
+		moneyFormat = MoneyFormatString
+	}
+	if moneyFormat != MoneyFormatString && moneyFormat != MoneyFormatNumber {
+		return nil, fmt.Errorf("invalid MONEY_JSON_FORMAT %q: must be %s or %s", moneyFormat, MoneyFormatString, MoneyFormatNumber)
+	}
+
+	// 8 places are needed for crypto assets; the default keeps fiat amounts to 4
+	amountScale, err := getEnvInt("AMOUNT_SCALE", int(domain.DefaultAmountScale))
+	if err != nil {
+		return nil, err
+	}
+	if amountScale < 0 || amountScale > int(domain.MaxAmountScale) {
+		return nil, fmt.Errorf("invalid AMOUNT_SCALE %d: must be between 0 and %d", amountScale, domain.MaxAmountScale)
+	}
+
+	authzPolicy := os.Getenv("AUTHZ_POLICY")
+	if authzPolicy == "" {
+		authzPolicy = AuthzPolicyAllowOwner
+	}
+	opaURL := os.Getenv("AUTHZ_OPA_URL")
+	switch authzPolicy {
+	case AuthzPolicyAllowOwner:
+	case AuthzPolicyOPA:
+		if opaURL == "" {
+			return nil, fmt.Errorf("AUTHZ_OPA_URL is required with AUTHZ_POLICY=%s", AuthzPolicyOPA)
+		}
+	default:
+		return nil, fmt.Errorf("invalid AUTHZ_POLICY %q: must be %s or %s", authzPolicy, AuthzPolicyAllowOwner, AuthzPolicyOPA)
+	}
+	opaTimeout, err := getEnvDuration("AUTHZ_OPA_TIMEOUT", 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	walletExportDir := os.Getenv("WALLET_EXPORT_DIR")
+	if walletExportDir == "" {
+		walletExportDir = filepath.Join(os.TempDir(), "finflow-wallet-exports")
+	}
+	walletExportURLTTL, err := getEnvDuration("WALLET_EXPORT_URL_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	walletExportPollInterval, err := getEnvDuration("WALLET_EXPORT_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	walletExportStaleAfter, err := getEnvDuration("WALLET_EXPORT_STALE_AFTER", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	walletExportPageSize, err := getEnvInt("WALLET_EXPORT_PAGE_SIZE", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if walletExportPageSize < 1 {
+		return nil, fmt.Errorf("WALLET_EXPORT_PAGE_SIZE must be positive")
+	}
+
+	gatewayTimeout, err := getEnvDuration("PAYMENT_GATEWAY_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	autoTopUpInterval, err := getEnvDuration("AUTO_TOP_UP_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	autoTopUpCooldown, err := getEnvDuration("AUTO_TOP_UP_COOLDOWN", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	autoTopUpMaxFailures, err := getEnvInt("AUTO_TOP_UP_MAX_FAILURES", 3)
+	if err != nil {
+		return nil, err
+	}
+	chainGatewayURL := os.Getenv("CHAIN_GATEWAY_URL")
+	if chainGatewayURL != "" && amountScale < int(domain.MaxCryptoPlaces()) {
+		return nil, fmt.Errorf("crypto wallets need AMOUNT_SCALE of at least %d, got %d", domain.MaxCryptoPlaces(), amountScale)
+	}
+	chainGatewayTimeout, err := getEnvDuration("CHAIN_GATEWAY_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	cryptoDepositPollInterval, err := getEnvDuration("CRYPTO_DEPOSIT_POLL_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	cryptoPayoutInterval, err := getEnvDuration("CRYPTO_PAYOUT_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	cryptoPayoutStaleAfter, err := getEnvDuration("CRYPTO_PAYOUT_STALE_AFTER", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	openBankingTokenKey := os.Getenv("OPEN_BANKING_TOKEN_KEY")
+	openBankingTokenIssuer := os.Getenv("OPEN_BANKING_TOKEN_ISSUER")
+	if openBankingTokenKey != "" && openBankingTokenIssuer == "" {
+		return nil, fmt.Errorf("OPEN_BANKING_TOKEN_ISSUER is required with OPEN_BANKING_TOKEN_KEY")
+	}
+	openBankingMaxConsentAge, err := getEnvDuration("OPEN_BANKING_MAX_CONSENT_AGE", 90*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	oidcProviders, err := getEnvOIDCProviders("OIDC_PROVIDERS")
+	if err != nil {
+		return nil, err
+	}
+	oidcJWKSCacheTTL, err := getEnvDuration("OIDC_JWKS_CACHE_TTL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	oidcTimeout, err := getEnvDuration("OIDC_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	scimTenants, err := getEnvSCIMTenants("SCIM_TENANTS")
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := getEnvTLS()
+	if err != nil {
+		return nil, err
+	}
+	piiReencryptInterval, err := getEnvDuration("PII_REENCRYPT_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	piiReencryptBatchSize, err := getEnvInt("PII_REENCRYPT_BATCH_SIZE", 500)
+	if err != nil {
+		return nil, err
+	}
+	if piiReencryptBatchSize < 1 {
+		return nil, fmt.Errorf("PII_REENCRYPT_BATCH_SIZE must be positive")
+	}
+	apnsSandbox, err := getEnvBool("APNS_SANDBOX", false)
+	if err != nil {
+		return nil, err
+	}
+	pushTimeout, err := getEnvDuration("PUSH_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	pushDeliveryInterval, err := getEnvDuration("PUSH_DELIVERY_INTERVAL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	pushBatchSize, err := getEnvInt("PUSH_BATCH_SIZE", 100)
+	if err != nil {
+		return nil, err
+	}
+	if pushBatchSize < 1 {
+		return nil, fmt.Errorf("PUSH_BATCH_SIZE must be positive")
+	}
+
+	compressionEnabled, err := getEnvBool("COMPRESSION_ENABLED", true)
+	if err != nil {
+		return nil, err
+	}
+	compressionLevel, err := getEnvInt("COMPRESSION_LEVEL", 5)
+	if err != nil {
+		return nil, err
+	}
+	if compressionLevel < 1 || compressionLevel > 9 {
+		return nil, fmt.Errorf("COMPRESSION_LEVEL must be between 1 and 9")
+	}
+	compressionMinSize, err := getEnvInt("COMPRESSION_MIN_SIZE", 1024)
+	if err != nil {
+		return nil, err
+	}
+	if compressionMinSize < 0 {
+		return nil, fmt.Errorf("COMPRESSION_MIN_SIZE must not be negative")
+	}
+	compressionContentTypes := []string{"application/json", "text/csv", "text/plain"}
+	if raw := os.Getenv("COMPRESSION_CONTENT_TYPES"); raw != "" {
+		compressionContentTypes = nil
+		for _, contentType := range strings.Split(raw, ",") {
+			if contentType = strings.ToLower(strings.TrimSpace(contentType)); contentType != "" {
+				compressionContentTypes = append(compressionContentTypes, contentType)
+			}
+		}
+	}
+
+	transactionsMaxAge, err := getEnvDuration("CACHE_TRANSACTIONS_MAX_AGE", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	reportsMaxAge, err := getEnvDuration("CACHE_REPORTS_MAX_AGE", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	dormancyMonths, err := getEnvInt("DORMANCY_INACTIVE_MONTHS", 0)
+	if err != nil {
+		return nil, err
+	}
+	if dormancyMonths < 0 {
+		return nil, fmt.Errorf("DORMANCY_INACTIVE_MONTHS must not be negative")
+	}
+	dormancyFreeze, err := getEnvBool("DORMANCY_FREEZE", false)
+	if err != nil {
+		return nil, err
+	}
+	dormancyInterval, err := getEnvDuration("DORMANCY_CHECK_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	ownershipTransferTTL, err := getEnvDuration("OWNERSHIP_TRANSFER_TTL", 72*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if ownershipTransferTTL <= 0 {
+		return nil, fmt.Errorf("OWNERSHIP_TRANSFER_TTL must be positive")
+	}
+	ownershipExpiryInterval, err := getEnvDuration("OWNERSHIP_TRANSFER_EXPIRY_INTERVAL", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	shardCacheTTL, err := getEnvDuration("BALANCE_SHARD_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	rebalanceInterval, err := getEnvDuration("BALANCE_REBALANCE_INTERVAL", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	backfillInterval, err := getEnvDuration("BACKFILL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	backfillBatches, err := getEnvInt("BACKFILL_BATCHES_PER_RUN", 10)
+	if err != nil {
+		return nil, err
+	}
+	if backfillBatches <= 0 {
+		return nil, fmt.Errorf("BACKFILL_BATCHES_PER_RUN must be positive")
+	}
+
+	var retention RetentionConfig
+	for _, setting := range []struct {
+		key  string
+		def  int
+		dest *int
+	}{
+		{"RETENTION_AUTH_EVENTS_DAYS", 365, &retention.AuthEventsDays},
+		{"RETENTION_IMPERSONATION_AUDIT_DAYS", 2555, &retention.ImpersonationAuditDays},
+		{"RETENTION_NOTIFICATION_DELIVERIES_DAYS", 90, &retention.NotificationDeliveriesDays},
+		{"RETENTION_NOTIFICATIONS_DAYS", 365, &retention.NotificationsDays},
+		{"RETENTION_BATCH_SIZE", 1000, &retention.BatchSize},
+		{"RETENTION_PURGE_HOUR", 3, &retention.PurgeHour},
+	} {
+		if *setting.dest, err = getEnvInt(setting.key, setting.def); err != nil {
+			return nil, err
+		}
+		if *setting.dest < 0 {
+			return nil, fmt.Errorf("%s must not be negative", setting.key)
+		}
+	}
+	if retention.BatchSize == 0 {
+		return nil, fmt.Errorf("RETENTION_BATCH_SIZE must be positive")
+	}
+	if retention.PurgeHour > 23 {
+		return nil, fmt.Errorf("RETENTION_PURGE_HOUR must be between 0 and 23")
+	}
+	if retention.DryRun, err = getEnvBool("RETENTION_DRY_RUN", false); err != nil {
+		return nil, err
+	}
+
+	asyncPollInterval, err := getEnvDuration("ASYNC_TRANSFER_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	asyncBatchSize, err := getEnvInt("ASYNC_TRANSFER_BATCH_SIZE", 100)
+	if err != nil {
+		return nil, err
+	}
+	if asyncBatchSize <= 0 {
+		return nil, fmt.Errorf("ASYNC_TRANSFER_BATCH_SIZE must be positive")
+	}
+	asyncRetryBackoff, err := getEnvDuration("ASYNC_TRANSFER_RETRY_BACKOFF", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	asyncMaxRetryBackoff, err := getEnvDuration("ASYNC_TRANSFER_MAX_RETRY_BACKOFF", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if asyncRetryBackoff <= 0 || asyncMaxRetryBackoff < asyncRetryBackoff {
+		return nil, fmt.Errorf("ASYNC_TRANSFER_RETRY_BACKOFF must be positive and at most ASYNC_TRANSFER_MAX_RETRY_BACKOFF")
+	}
+
+	nettingWindow, err := getEnvDuration("NETTING_WINDOW", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if nettingWindow <= 0 {
+		return nil, fmt.Errorf("NETTING_WINDOW must be positive")
+	}
+
+	readLimit, err := getEnvClassLimit("READ", 64, 128, 500*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	movementLimit, err := getEnvClassLimit("MOVEMENT", 32, 256, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	shedPoolUsage, err := getEnvInt("LOAD_SHED_POOL_USAGE_PCT", 90)
+	if err != nil {
+		return nil, err
+	}
+	if shedPoolUsage < 0 || shedPoolUsage > 100 {
+		return nil, fmt.Errorf("LOAD_SHED_POOL_USAGE_PCT must be between 0 and 100")
+	}
+	shedRetryAfter, err := getEnvDuration("LOAD_SHED_RETRY_AFTER", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	shadowSamplePct, err := getEnvInt("SHADOW_READS_SAMPLE_PCT", 10)
+	if err != nil {
+		return nil, err
+	}
+	if shadowSamplePct < 0 || shadowSamplePct > 100 {
+		return nil, fmt.Errorf("SHADOW_READS_SAMPLE_PCT must be between 0 and 100")
+	}
+	shadowTimeout, err := getEnvDuration("SHADOW_READS_TIMEOUT", 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	shadowMaxInFlight, err := getEnvInt("SHADOW_READS_MAX_IN_FLIGHT", 8)
+	if err != nil {
+		return nil, err
+	}
+	if shadowMaxInFlight <= 0 {
+		return nil, fmt.Errorf("SHADOW_READS_MAX_IN_FLIGHT must be positive")
+	}
+
+	poolSampleInterval, err := getEnvDuration("DB_POOL_SAMPLE_INTERVAL", 15*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if poolSampleInterval <= 0 {
+		return nil, fmt.Errorf("DB_POOL_SAMPLE_INTERVAL must be positive")
+	}
+	poolWaitWarn, err := getEnvDuration("DB_POOL_WAIT_WARN", 50*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	poolWaitCritical, err := getEnvDuration("DB_POOL_WAIT_CRITICAL", 250*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	queryTiming, err := getEnvBool("QUERY_TIMING_ENABLED", true)
+	if err != nil {
+		return nil, err
+	}
+	slowQueryThreshold, err := getEnvDuration("SLOW_QUERY_THRESHOLD", 200*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	environment := os.Getenv("APP_ENV")
+	if environment == "" {
+		environment = "development"
+	}
+	chaos, err := getEnvChaosRules("CHAOS_RULES")
+	if err != nil {
+		return nil, err
+	}
+	if len(chaos) > 0 && environment == EnvironmentProduction {
+		return nil, fmt.Errorf("CHAOS_RULES must not be set with APP_ENV=%s", EnvironmentProduction)
+	}
+	sandbox, err := getEnvBool("SANDBOX_MODE", false)
+	if err != nil {
+		return nil, err
+	}
+	// Sandbox data is kept in databases of its own, so magic outcomes never apply to real money
+	if sandbox && environment == EnvironmentProduction {
+		return nil, fmt.Errorf("SANDBOX_MODE must not be set with APP_ENV=%s", EnvironmentProduction)
+	}
+	if sandbox && !strings.HasSuffix(dbName, sandboxDBSuffix) {
+		return nil, fmt.Errorf("SANDBOX_MODE requires a database whose name ends in %q, got %q", sandboxDBSuffix, dbName)
+	}
 
 	return &AppConfig{
-		ServerPort: serverPort,
+		Environment: environment,
+		ServerPort:  serverPort,
+		TLS:         tlsConfig,
+		MoneyFormat: moneyFormat,
+		AmountScale: int32(amountScale),
 		DB: db.Config{
 			Host:     dbHost,
 			Port:     dbPort,
@@ -61,6 +968,428 @@ func LoadConfig() (*AppConfig, error) {
 			Password: dbPassword,
 			DBName:   dbName,
 			SSLMode:  dbSSLMode,
+
+			PrepareStatements: dbPrepareStatements,
+			ReadRetries:       dbReadRetries,
+			ReadRetryBackoff:  dbReadRetryBackoff,
+		},
+		Redis: RedisConfig{
+			URL:       os.Getenv("REDIS_URL"),
+			Timeout:   redisTimeout,
+			KeyPrefix: redisKeyPrefix,
+		},
+		QueryTiming: QueryTimingConfig{
+			Enabled:       queryTiming,
+			SlowThreshold: slowQueryThreshold,
+		},
+		DBPool: DBPoolConfig{
+			SampleInterval: poolSampleInterval,
+			WaitWarn:       poolWaitWarn,
+			WaitCritical:   poolWaitCritical,
+		},
+		Archive: ArchiveConfig{
+			HorizonMonths: archiveHorizon,
+			Interval:      archiveInterval,
+		},
+		Signing: SigningConfig{
+			Partners:         partners,
+			MaxSkew:          signatureMaxSkew,
+			Quotas:           partnerQuotas,
+			PaymentProviders: paymentProviders,
+		},
+		Admin: AdminConfig{
+			Token:              os.Getenv("ADMIN_TOKEN"),
+			Users:              adminUsers,
+			ReadOnly:           readOnly,
+			ReadOnlyRetryAfter: readOnlyRetryAfter,
+			ImpersonationTTL:   impersonationTTL,
+		},
+		FeatureFlagCacheTTL: featureFlagCacheTTL,
+		SerializationTTL:    serializationTTL,
+		ReportCacheTTL:      reportCacheTTL,
+		FX: FXConfig{
+			CacheTTL:        fxCacheTTL,
+			RefreshInterval: fxRefreshInterval,
+			MaxRateAge:      fxMaxRateAge,
+		},
+		TransferQuoteTTL: transferQuoteTTL,
+		DuplicateWindow:  duplicateWindow,
+		NettingWindow:    nettingWindow,
+		DescriptionsFile: os.Getenv("TRANSACTION_DESCRIPTION_TEMPLATES"),
+		AccountsFile:     os.Getenv("JOURNAL_CHART_OF_ACCOUNTS"),
+		Merchant: MerchantConfig{
+			ChargeTTL:          chargeTTL,
+			SettlementInterval: settlementInterval,
 		},
+		Bill: BillConfig{
+			ReminderInterval: billReminderInterval,
+			CheckInterval:    billCheckInterval,
+		},
+		PromoExpiryInterval: promoExpiryInterval,
+		Export: ExportConfig{
+			Dir:         os.Getenv("EXPORT_DIR"),
+			Interval:    exportInterval,
+			BatchSize:   exportBatchSize,
+			SettleDelay: exportSettleDelay,
+		},
+		ChangeSettleDelay: changeSettleDelay,
+		Deletion: DeletionConfig{
+			Retention:     deletedRetention,
+			PurgeInterval: purgeInterval,
+		},
+		Authz: AuthzConfig{
+			Policy:     authzPolicy,
+			OPAURL:     opaURL,
+			OPATimeout: opaTimeout,
+		},
+		WalletExport: WalletExportConfig{
+			Dir:          walletExportDir,
+			SigningKey:   os.Getenv("WALLET_EXPORT_SIGNING_KEY"),
+			URLTTL:       walletExportURLTTL,
+			PollInterval: walletExportPollInterval,
+			StaleAfter:   walletExportStaleAfter,
+			PageSize:     walletExportPageSize,
+		},
+		AutoTopUp: AutoTopUpConfig{
+			GatewayURL:     os.Getenv("PAYMENT_GATEWAY_URL"),
+			GatewayAPIKey:  os.Getenv("PAYMENT_GATEWAY_API_KEY"),
+			GatewayTimeout: gatewayTimeout,
+			Interval:       autoTopUpInterval,
+			Cooldown:       autoTopUpCooldown,
+			MaxFailures:    autoTopUpMaxFailures,
+		},
+		Crypto: CryptoConfig{
+			GatewayURL:          chainGatewayURL,
+			GatewayAPIKey:       os.Getenv("CHAIN_GATEWAY_API_KEY"),
+			GatewayTimeout:      chainGatewayTimeout,
+			DepositPollInterval: cryptoDepositPollInterval,
+			PayoutInterval:      cryptoPayoutInterval,
+			PayoutStaleAfter:    cryptoPayoutStaleAfter,
+		},
+		OpenBanking: OpenBankingConfig{
+			TokenKey:      openBankingTokenKey,
+			TokenIssuer:   openBankingTokenIssuer,
+			MaxConsentAge: openBankingMaxConsentAge,
+		},
+		OIDC: OIDCConfig{
+			Providers:    oidcProviders,
+			JWKSCacheTTL: oidcJWKSCacheTTL,
+			Timeout:      oidcTimeout,
+		},
+		SCIM: SCIMConfig{
+			Tenants: scimTenants,
+		},
+		PII: PIIConfig{
+			ReencryptInterval:  piiReencryptInterval,
+			ReencryptBatchSize: piiReencryptBatchSize,
+		},
+		Push: PushConfig{
+			FCMCredentialsFile: os.Getenv("FCM_CREDENTIALS_FILE"),
+			APNsKeyFile:        os.Getenv("APNS_KEY_FILE"),
+			APNsKeyID:          os.Getenv("APNS_KEY_ID"),
+			APNsTeamID:         os.Getenv("APNS_TEAM_ID"),
+			APNsTopic:          os.Getenv("APNS_TOPIC"),
+			APNsSandbox:        apnsSandbox,
+			Timeout:            pushTimeout,
+			DeliveryInterval:   pushDeliveryInterval,
+			BatchSize:          pushBatchSize,
+		},
+		Compression: CompressionConfig{
+			Enabled:      compressionEnabled,
+			Level:        compressionLevel,
+			MinSize:      compressionMinSize,
+			ContentTypes: compressionContentTypes,
+		},
+		HTTPCache: HTTPCacheConfig{
+			TransactionsMaxAge: transactionsMaxAge,
+			ReportsMaxAge:      reportsMaxAge,
+		},
+		Dormancy: DormancyConfig{
+			InactiveMonths: dormancyMonths,
+			Freeze:         dormancyFreeze,
+			CheckInterval:  dormancyInterval,
+		},
+		OwnershipTransfer: OwnershipTransferConfig{
+			TTL:            ownershipTransferTTL,
+			ExpiryInterval: ownershipExpiryInterval,
+		},
+		BalanceShards: BalanceShardConfig{
+			CacheTTL:          shardCacheTTL,
+			RebalanceInterval: rebalanceInterval,
+		},
+		Backfill: BackfillConfig{
+			Interval:      backfillInterval,
+			BatchesPerRun: backfillBatches,
+		},
+		Retention: retention,
+		AsyncTransfer: AsyncTransferConfig{
+			PollInterval:    asyncPollInterval,
+			BatchSize:       asyncBatchSize,
+			RetryBackoff:    asyncRetryBackoff,
+			MaxRetryBackoff: asyncMaxRetryBackoff,
+		},
+		LoadShed: LoadShedConfig{
+			Read:         readLimit,
+			Movement:     movementLimit,
+			PoolUsagePct: shedPoolUsage,
+			RetryAfter:   shedRetryAfter,
+		},
+		ShadowReads: ShadowReadsConfig{
+			DBHost:      os.Getenv("SHADOW_READS_DB_HOST"),
+			DBName:      os.Getenv("SHADOW_READS_DB_NAME"),
+			SamplePct:   shadowSamplePct,
+			Timeout:     shadowTimeout,
+			MaxInFlight: shadowMaxInFlight,
+		},
+		BackupSigningKey: os.Getenv("BACKUP_VERIFICATION_SIGNING_KEY"),
+		Sandbox:          sandbox,
+		Chaos:            chaos,
 	}, nil
 }
+
+// getEnvClassLimit reads the LOAD_SHED_<class>_CONCURRENCY, _QUEUE and _MAX_WAIT environment variables.
+func getEnvClassLimit(class string, concurrency, queue int, maxWait time.Duration) (ClassLimitConfig, error) {
+	prefix := "LOAD_SHED_" + class
+	var limit ClassLimitConfig
+	var err error
+	if limit.Concurrency, err = getEnvInt(prefix+"_CONCURRENCY", concurrency); err != nil {
+		return limit, err
+	}
+	if limit.Queue, err = getEnvInt(prefix+"_QUEUE", queue); err != nil {
+		return limit, err
+	}
+	if limit.MaxWait, err = getEnvDuration(prefix+"_MAX_WAIT", maxWait); err != nil {
+		return limit, err
+	}
+	if limit.Concurrency < 0 || limit.Queue < 0 || limit.MaxWait < 0 {
+		return limit, fmt.Errorf("%s_* must not be negative", prefix)
+	}
+	return limit, nil
+}
+
+// getEnvInt reads an integer environment variable, falling back to def when unset.
+func getEnvInt(key string, def int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// getEnvDuration reads a time.Duration environment variable (e.g. "30s", "24h"), falling back to def when unset.
+func getEnvDuration(key string, def time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// getEnvBool reads a boolean environment variable (true/false/1/0), falling back to def when unset.
+func getEnvBool(key string, def bool) (bool, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// getEnvPairs reads a comma-separated list of key:value pairs (e.g. "acme:s3cret,globex:t0ps3cret").
+func getEnvPairs(key string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	raw := os.Getenv(key)
+	if raw == "" {
+		return pairs, nil
+	}
+	for _, entry := range strings.Split(raw, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid %s: entries must be key:value", key)
+		}
+		pairs[name] = value
+	}
+	return pairs, nil
+}
+
+// getEnvPartnerQuotas reads a JSON object of monthly quotas by partner ID, e.g.
+// {"acme": {"requests": 100000, "volume": {"USD": "50000"}}}. Every partner must have a signing key.
+func getEnvPartnerQuotas(key string, partners map[string]string) (map[string]PartnerQuota, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil, nil
+	}
+	var quotas map[string]PartnerQuota
+	if err := json.Unmarshal([]byte(raw), &quotas); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	for partnerID, quota := range quotas {
+		if _, ok := partners[partnerID]; !ok {
+			return nil, fmt.Errorf("invalid %s: partner %q has no key in PARTNER_SIGNING_KEYS", key, partnerID)
+		}
+		if quota.Requests < 0 {
+			return nil, fmt.Errorf("invalid %s: partner %q has a negative request quota", key, partnerID)
+		}
+		for currency, limit := range quota.Volume {
+			if !limit.IsPositive() {
+				return nil, fmt.Errorf("invalid %s: partner %q has a non-positive %s volume quota", key, partnerID, currency)
+			}
+		}
+	}
+	return quotas, nil
+}
+
+// getEnvOIDCProviders reads a JSON object of OpenID Connect providers by name, e.g.
+// {"google": {"issuer": "https://accounts.google.com", "audiences": ["123.apps.googleusercontent.com"], "default_currency": "USD"}}.
+func getEnvOIDCProviders(key string) (map[string]OIDCProvider, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil, nil
+	}
+	var providers map[string]OIDCProvider
+	if err := json.Unmarshal([]byte(raw), &providers); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	for name, provider := range providers {
+		if !strings.HasPrefix(provider.Issuer, "https://") {
+			return nil, fmt.Errorf("invalid %s: provider %q needs an https issuer", key, name)
+		}
+		if len(provider.Audiences) == 0 {
+			return nil, fmt.Errorf("invalid %s: provider %q has no audiences", key, name)
+		}
+		currency := strings.ToUpper(provider.DefaultCurrency)
+		if !domain.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("invalid %s: provider %q has an unsupported default currency %q", key, name, provider.DefaultCurrency)
+		}
+		provider.DefaultCurrency = currency
+		providers[name] = provider
+	}
+	return providers, nil
+}
+
+// getEnvChaosRules reads a JSON array of fault injection rules, e.g.
+// [{"method": "POST", "path": "/transfers", "latency": "2s", "latency_rate": 0.2, "error_rate": 0.05}].
+func getEnvChaosRules(key string) ([]ChaosRule, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil, nil
+	}
+	var rules []ChaosRule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	for i := range rules {
+		rule := &rules[i]
+		if _, err := path.Match(rule.Path, "/"); err != nil || rule.Path == "" {
+			return nil, fmt.Errorf("invalid %s: rule %d has an invalid path pattern %q", key, i, rule.Path)
+		}
+		if rule.RawLatency != "" {
+			latency, err := time.ParseDuration(rule.RawLatency)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: rule %d: %w", key, i, err)
+			}
+			rule.Latency = latency
+		}
+		for _, rate := range []float64{rule.LatencyRate, rule.ErrorRate, rule.DBDropRate} {
+			if rate < 0 || rate > 1 {
+				return nil, fmt.Errorf("invalid %s: rule %d has a rate outside 0 to 1", key, i)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// getEnvSCIMTenants reads a JSON object of SCIM tenants by name, e.g.
+// {"acme": {"token": "s3cret", "default_currency": "EUR"}}. Tokens must be distinct, as they identify the tenant.
+func getEnvSCIMTenants(key string) (map[string]SCIMTenant, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil, nil
+	}
+	var tenants map[string]SCIMTenant
+	if err := json.Unmarshal([]byte(raw), &tenants); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	tokens := make(map[string]string, len(tenants))
+	for name, tenant := range tenants {
+		if tenant.Token == "" {
+			return nil, fmt.Errorf("invalid %s: tenant %q has no token", key, name)
+		}
+		if other, ok := tokens[tenant.Token]; ok {
+			return nil, fmt.Errorf("invalid %s: tenants %q and %q share a token", key, other, name)
+		}
+		tokens[tenant.Token] = name
+		currency := strings.ToUpper(tenant.DefaultCurrency)
+		if !domain.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("invalid %s: tenant %q has an unsupported default currency %q", key, name, tenant.DefaultCurrency)
+		}
+		tenant.DefaultCurrency = currency
+		tenants[name] = tenant
+	}
+	return tenants, nil
+}
+
+// getEnvTLS reads the server certificate and the mutual TLS settings. A client CA needs a server certificate,
+// and requiring client certificates or naming services needs a client CA.
+func getEnvTLS() (TLSConfig, error) {
+	cfg := TLSConfig{
+		CertFile:     os.Getenv("TLS_CERT_FILE"),
+		KeyFile:      os.Getenv("TLS_KEY_FILE"),
+		ClientCAFile: os.Getenv("MTLS_CLIENT_CA_FILE"),
+	}
+	var err error
+	if cfg.RequireClientCert, err = getEnvBool("MTLS_REQUIRE_CLIENT_CERT", false); err != nil {
+		return cfg, err
+	}
+	if cfg.ServiceIdentities, err = getEnvServiceIdentities("MTLS_SERVICE_IDENTITIES"); err != nil {
+		return cfg, err
+	}
+	switch {
+	case (cfg.CertFile == "") != (cfg.KeyFile == ""):
+		return cfg, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	case cfg.ClientCAFile != "" && cfg.CertFile == "":
+		return cfg, fmt.Errorf("MTLS_CLIENT_CA_FILE requires TLS_CERT_FILE")
+	case cfg.ClientCAFile == "" && (cfg.RequireClientCert || len(cfg.ServiceIdentities) > 0):
+		return cfg, fmt.Errorf("MTLS_REQUIRE_CLIENT_CERT and MTLS_SERVICE_IDENTITIES require MTLS_CLIENT_CA_FILE")
+	}
+	return cfg, nil
+}
+
+// getEnvServiceIdentities reads a JSON object of internal service names by the SPIFFE ID or DNS name their
+// client certificates carry, e.g. {"spiffe://finflow.internal/ns/payments/sa/ledger": "ledger"}.
+func getEnvServiceIdentities(key string) (map[string]string, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil, nil
+	}
+	var identities map[string]string
+	if err := json.Unmarshal([]byte(raw), &identities); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	for id, name := range identities {
+		if name == "" {
+			return nil, fmt.Errorf("invalid %s: %q has no service name", key, id)
+		}
+		if trustDomain, ok := strings.CutPrefix(id, "spiffe://"); ok {
+			if domainName, _, _ := strings.Cut(trustDomain, "/"); domainName == "" {
+				return nil, fmt.Errorf("invalid %s: SPIFFE ID %q has no trust domain", key, id)
+			}
+			continue
+		}
+		if id == "" || strings.ContainsAny(id, ":/ ") {
+			return nil, fmt.Errorf("invalid %s: %q is neither a SPIFFE ID nor a DNS name", key, id)
+		}
+	}
+	return identities, nil
+}
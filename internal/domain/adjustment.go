@@ -0,0 +1,72 @@
+// internal/domain/adjustment.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// AdjustmentDirection defines whether a manual adjustment adds money to a wallet or takes it away.
+type AdjustmentDirection string
+
+const (
+	AdjustmentCredit AdjustmentDirection = "CREDIT"
+	AdjustmentDebit  AdjustmentDirection = "DEBIT"
+)
+
+// Valid reports whether the direction is one of the known directions.
+func (d AdjustmentDirection) Valid() bool {
+	return d == AdjustmentCredit || d == AdjustmentDebit
+}
+
+// AdjustmentReasonCode classifies why a manual adjustment was made, for audit and reporting.
+type AdjustmentReasonCode string
+
+const (
+	AdjustmentReasonErrorCorrection AdjustmentReasonCode = "ERROR_CORRECTION" // Reverses a booking made in error
+	AdjustmentReasonGoodwill        AdjustmentReasonCode = "GOODWILL"         // Compensation granted to a customer
+	AdjustmentReasonChargeback      AdjustmentReasonCode = "CHARGEBACK"       // Funds reclaimed through a card or bank dispute
+	AdjustmentReasonFeeRefund       AdjustmentReasonCode = "FEE_REFUND"
+	AdjustmentReasonRegulatory      AdjustmentReasonCode = "REGULATORY" // Ordered by a regulator or court
+)
+
+// Valid reports whether the code is one of the known reason codes.
+func (c AdjustmentReasonCode) Valid() bool {
+	switch c {
+	case AdjustmentReasonErrorCorrection, AdjustmentReasonGoodwill, AdjustmentReasonChargeback, AdjustmentReasonFeeRefund, AdjustmentReasonRegulatory:
+		return true
+	}
+	return false
+}
+
+// AdjustmentStatus defines the lifecycle of a manual adjustment.
+type AdjustmentStatus string
+
+const (
+	AdjustmentStatusPending  AdjustmentStatus = "PENDING"  // Proposed; waiting for a second admin
+	AdjustmentStatusPosted   AdjustmentStatus = "POSTED"   // Approved and booked as an ADJUSTMENT transaction
+	AdjustmentStatusRejected AdjustmentStatus = "REJECTED" // Rejected by an admin, or withdrawn by its proposer
+)
+
+// Adjustment is a manual credit or debit of a wallet under maker-checker control: one admin proposes it
+// and a different admin approves it before any money moves.
+type Adjustment struct {
+	ID             int64                `db:"id" json:"-"`
+	PublicID       uuid.UUID            `db:"public_id" json:"id"`
+	WalletID       int64                `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID            `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	Direction      AdjustmentDirection  `db:"direction" json:"direction"`
+	Amount         decimal.Decimal      `db:"amount" json:"amount"`
+	Currency       string               `db:"currency" json:"currency"`
+	ReasonCode     AdjustmentReasonCode `db:"reason_code" json:"reason_code"`
+	Note           *string              `db:"note" json:"note"`
+	Status         AdjustmentStatus     `db:"status" json:"status"`
+	ProposedBy     string               `db:"proposed_by" json:"proposed_by"`
+	DecidedBy      *string              `db:"decided_by" json:"decided_by"` // The approving or rejecting admin
+	DecisionNote   *string              `db:"decision_note" json:"decision_note"`
+	TransactionID  *uuid.UUID           `db:"transaction_id" json:"transaction_id"` // Set once posted
+	CreatedAt      time.Time            `db:"created_at" json:"created_at"`
+	DecidedAt      *time.Time           `db:"decided_at" json:"decided_at"`
+}
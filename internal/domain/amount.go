@@ -0,0 +1,43 @@
+// internal/domain/amount.go
+package domain
+
+import (
+	"sync/atomic"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DefaultAmountScale is the number of decimal places amounts are accepted with unless configured otherwise.
+	DefaultAmountScale int32 = 4
+	// MaxAmountScale is the number of decimal places the NUMERIC columns store amounts with. No deployment can
+	// accept more without losing them to rounding.
+	MaxAmountScale int32 = 8
+)
+
+var amountScale atomic.Int32
+
+func init() {
+	amountScale.Store(DefaultAmountScale)
+}
+
+// SetAmountScale sets the number of decimal places amounts are accepted and computed with, at most
+// MaxAmountScale. It is set once at startup.
+func SetAmountScale(scale int32) {
+	amountScale.Store(min(scale, MaxAmountScale))
+}
+
+// AmountScale returns the number of decimal places amounts are accepted and computed with.
+func AmountScale() int32 {
+	return amountScale.Load()
+}
+
+// AmountPlaces returns the number of decimal places of amount, ignoring trailing zeros: 2 for 12.05, 1 for
+// 12.50 and 0 for 1e3.
+func AmountPlaces(amount decimal.Decimal) int32 {
+	places := max(-amount.Exponent(), 0)
+	for places > 0 && amount.Equal(amount.Truncate(places-1)) {
+		places--
+	}
+	return places
+}
@@ -0,0 +1,33 @@
+// internal/domain/annotation.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Limits on transaction annotations.
+const (
+	MaxAnnotationNoteLength = 4000
+	MaxAnnotationCaseIDs    = 20
+	MaxCaseIDLength         = 64
+)
+
+// TransactionAnnotation holds support's internal note and case references for a transaction.
+// Annotations are only returned by admin endpoints.
+type TransactionAnnotation struct {
+	TransactionID uuid.UUID `db:"transaction_id" json:"transaction_id"`
+	Note          *string   `db:"note" json:"note"`
+	CaseIDs       []string  `db:"case_ids" json:"case_ids"`
+	UpdatedBy     *string   `db:"updated_by" json:"updated_by"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// TransactionAnnotationPatch changes an annotation. Nil fields are left as they are; an empty Note clears it.
+type TransactionAnnotationPatch struct {
+	Note      *string
+	CaseIDs   *[]string // Replaces the case references
+	UpdatedBy *string
+}
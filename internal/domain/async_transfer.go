@@ -0,0 +1,29 @@
+// internal/domain/async_transfer.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// AsyncTransfer is a transfer accepted for later execution. Its PENDING TRANSFER transaction is created on
+// acceptance and completed, or failed, by the worker that makes the transfer.
+type AsyncTransfer struct {
+	ID                  int64           `db:"id"`
+	TransactionID       int64           `db:"transaction_id"`
+	TransactionPublicID uuid.UUID       `db:"transaction_public_id"` // Read-only, joined from transactions
+	FromWalletID        int64           `db:"from_wallet_id"`        // Read-only, joined from transactions
+	ToWalletID          int64           `db:"to_wallet_id"`          // Read-only, joined from transactions
+	Amount              decimal.Decimal `db:"amount"`                // Read-only, joined from transactions
+	Currency            string          `db:"currency"`              // Read-only, joined from transactions
+	Category            *string         `db:"category"`              // Read-only, joined from transactions
+	OverrideBudget      bool            `db:"override_budget"`       // The request proceeds past SOFT_BLOCK budgets
+	PartnerID           *string         `db:"partner_id"`            // The partner that requested the transfer, if any
+	FailureReason       *string         `db:"failure_reason"`        // Error code of why the transfer failed; set with the FAILED status
+	Attempts            int             `db:"attempts"`              // Attempts that left the transfer PENDING for a retry
+	NextAttemptAt       *time.Time      `db:"next_attempt_at"`       // When the transfer is tried again; nil if due at once
+	CreatedAt           time.Time       `db:"created_at"`
+	UpdatedAt           time.Time       `db:"updated_at"`
+}
@@ -0,0 +1,135 @@
+// internal/domain/audit.go
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// AuditEventType names what an audit event records.
+type AuditEventType string
+
+const (
+	// AuditEventTransaction records a committed money movement into or out of the wallet, and each later
+	// change of its status.
+	AuditEventTransaction AuditEventType = "TRANSACTION"
+	// AuditEventOwnershipTransfer records each step of a transfer of the wallet to another user: its request,
+	// which freezes the wallet, and its completion, decline, cancellation or expiry.
+	AuditEventOwnershipTransfer AuditEventType = "OWNERSHIP_TRANSFER"
+)
+
+// AuditGenesisHash is the previous hash of the first event of every chain.
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// AuditEvent is an entry of a wallet's audit trail. Each wallet's events form a hash chain: an event's hash
+// covers its content and the hash of the wallet's previous event, so altering, inserting or deleting an event
+// breaks the links after it.
+type AuditEvent struct {
+	ID        int64          `db:"id" json:"-"`
+	WalletID  int64          `db:"wallet_id" json:"-"`
+	Sequence  int64          `db:"sequence" json:"sequence"` // 1 for the wallet's first event, then without gaps
+	Type      AuditEventType `db:"event_type" json:"type"`
+	Payload   string         `db:"payload" json:"-"` // JSON, hashed as stored
+	PrevHash  string         `db:"prev_hash" json:"prev_hash"`
+	Hash      string         `db:"hash" json:"hash"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+}
+
+// NewAuditEvent returns the event following previous in the wallet's chain, or its first event if previous is
+// nil, with its hash computed. The time is truncated to the microseconds the database stores.
+func NewAuditEvent(walletID int64, eventType AuditEventType, payload string, at time.Time, previous *AuditChainHead) *AuditEvent {
+	event := &AuditEvent{
+		WalletID:  walletID,
+		Sequence:  1,
+		Type:      eventType,
+		Payload:   payload,
+		PrevHash:  AuditGenesisHash,
+		CreatedAt: at.UTC().Truncate(time.Microsecond),
+	}
+	if previous != nil {
+		event.Sequence = previous.Sequence + 1
+		event.PrevHash = previous.Hash
+	}
+	event.Hash = event.ComputeHash()
+	return event
+}
+
+// ComputeHash returns the hex SHA-256 of the event's previous hash and content, one field per line.
+func (e *AuditEvent) ComputeHash() string {
+	content := strings.Join([]string{
+		e.PrevHash,
+		strconv.FormatInt(e.WalletID, 10),
+		strconv.FormatInt(e.Sequence, 10),
+		string(e.Type),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.Payload,
+	}, "\n")
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Head returns the chain head the event makes.
+func (e *AuditEvent) Head() *AuditChainHead {
+	return &AuditChainHead{Sequence: e.Sequence, Hash: e.Hash}
+}
+
+// AuditChainHead is the digest of a wallet's audit chain up to an event: the event's sequence number and hash.
+// It commits to every earlier event of the chain.
+type AuditChainHead struct {
+	Sequence int64  `db:"sequence" json:"sequence"`
+	Hash     string `db:"hash" json:"hash"`
+}
+
+// AuditChainVerification is the outcome of walking a wallet's audit chain.
+type AuditChainVerification struct {
+	WalletPublicID uuid.UUID       `json:"wallet_id"`
+	Events         int64           `json:"events"` // Events checked
+	Head           *AuditChainHead `json:"head"`   // The last intact event; nil for an empty chain or a broken first event
+	Valid          bool            `json:"valid"`
+	BrokenAt       *int64          `json:"broken_at"` // Sequence number of the first event failing the check
+	Reason         string          `json:"reason,omitempty"`
+}
+
+// AuditTransactionPayload is the payload of an AuditEventTransaction event, as seen from the event's wallet.
+type AuditTransactionPayload struct {
+	TransactionID        uuid.UUID         `json:"transaction_id"`
+	Type                 TransactionType   `json:"type"`
+	Status               TransactionStatus `json:"status"`
+	Direction            string            `json:"direction"` // DEBIT or CREDIT
+	Amount               decimal.Decimal   `json:"amount"`
+	Currency             string            `json:"currency"`
+	CounterpartyWalletID *uuid.UUID        `json:"counterparty_wallet_id,omitempty"`
+	TransactionTime      time.Time         `json:"transaction_time"`
+	Service              string            `json:"service,omitempty"` // The internal service that requested it, identified by its client certificate
+}
+
+// Directions of an AuditTransactionPayload.
+const (
+	AuditDirectionDebit  = "DEBIT"
+	AuditDirectionCredit = "CREDIT"
+)
+
+// AuditOwnershipTransferPayload is the payload of an AuditEventOwnershipTransfer event: the step of the
+// transfer and the wallet's ownership before and after it.
+type AuditOwnershipTransferPayload struct {
+	TransferID  uuid.UUID               `json:"transfer_id"`
+	Status      OwnershipTransferStatus `json:"status"` // The status the step left the transfer in
+	Before      AuditWalletOwnership    `json:"before"`
+	After       AuditWalletOwnership    `json:"after"`
+	ToUserID    int64                   `json:"to_user_id"`
+	Reason      string                  `json:"reason"`
+	RequestedBy string                  `json:"requested_by"`
+	CancelledBy string                  `json:"cancelled_by,omitempty"`
+}
+
+// AuditWalletOwnership is the ownership of a wallet as recorded in the audit trail.
+type AuditWalletOwnership struct {
+	UserID int64 `json:"user_id"`
+	Frozen bool  `json:"frozen"` // Frozen for a pending ownership transfer
+}
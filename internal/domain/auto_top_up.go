@@ -0,0 +1,40 @@
+// internal/domain/auto_top_up.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FundingSource is an external payment method linked to a wallet, such as a card or a bank mandate
+// tokenized by the payments gateway. Only the gateway's reference is stored, never card or account numbers.
+type FundingSource struct {
+	ID             int64     `db:"id" json:"id"`
+	WalletID       int64     `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	Provider       string    `db:"provider" json:"provider"`          // The gateway's payment method type, e.g. "card"
+	Reference      string    `db:"reference" json:"reference"`        // The gateway's token for the payment method
+	Label          *string   `db:"label" json:"label"`                // Optional display name, e.g. "Visa ending 4242"
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// AutoTopUpRule refills a wallet from one of its funding sources: whenever the balance is below Threshold,
+// Amount is charged to the funding source and deposited into the wallet. A wallet has at most one rule.
+type AutoTopUpRule struct {
+	WalletID            int64           `db:"wallet_id" json:"-"`
+	WalletPublicID      uuid.UUID       `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	Currency            string          `db:"currency" json:"currency"`          // Read-only, joined from wallets
+	FundingSourceID     int64           `db:"funding_source_id" json:"funding_source_id"`
+	Provider            string          `db:"provider" json:"-"`  // Read-only, joined from the funding source
+	Reference           string          `db:"reference" json:"-"` // Read-only, joined from the funding source
+	Threshold           decimal.Decimal `db:"threshold" json:"threshold"`
+	Amount              decimal.Decimal `db:"amount" json:"amount"`
+	Enabled             bool            `db:"enabled" json:"enabled"`                           // Cleared after too many consecutive failures
+	ConsecutiveFailures int             `db:"consecutive_failures" json:"consecutive_failures"` // Reset by a successful top-up
+	LastAttemptAt       *time.Time      `db:"last_attempt_at" json:"last_attempt_at"`
+	LastError           *string         `db:"last_error" json:"last_error"` // Why the last attempt failed; nil after a success
+	CreatedAt           time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time       `db:"updated_at" json:"updated_at"`
+}
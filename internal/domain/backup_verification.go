@@ -0,0 +1,69 @@
+// internal/domain/backup_verification.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Integrity check names, in the order they run.
+const (
+	CheckSchemaVersion  = "schema_version"              // The database is migrated to the code's latest migration and not dirty
+	CheckLedgerBalanced = "ledger_balanced"             // Per currency, the wallet balances add up to the money paid in less the money paid out
+	CheckWalletBalances = "balances_match_transactions" // Each wallet's balance is the sum of its completed transactions
+	CheckForeignKeys    = "foreign_keys_consistent"     // No row references a missing user, wallet or transaction
+)
+
+// IntegrityCheck is the outcome of one invariant check of a database.
+type IntegrityCheck struct {
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Failures int64    `json:"failures"`          // Rows, wallets or currencies breaking the invariant
+	Samples  []string `json:"samples,omitempty"` // Some of them, for the report
+	Error    string   `json:"error,omitempty"`   // The check could not run
+}
+
+// VerificationReport is the signed outcome of the integrity checks of a restored backup.
+type VerificationReport struct {
+	ID          uuid.UUID        `json:"id"`
+	Database    string           `json:"database"` // Host and name of the verified database
+	StartedAt   time.Time        `json:"started_at"`
+	CompletedAt time.Time        `json:"completed_at"`
+	Passed      bool             `json:"passed"`
+	Checks      []IntegrityCheck `json:"checks"`
+	// Signature is the HMAC-SHA256 of the report without it, hex encoded, with the verification signing key.
+	Signature string `json:"signature,omitempty"`
+}
+
+// BalanceMismatch is a wallet whose balance differs from the sum of its completed transactions.
+type BalanceMismatch struct {
+	WalletID       int64           `db:"wallet_id"`
+	WalletPublicID uuid.UUID       `db:"wallet_public_id"`
+	Currency       string          `db:"currency"`
+	Balance        decimal.Decimal `db:"balance"` // Own balance plus the balance shards
+	Expected       decimal.Decimal `db:"expected"`
+}
+
+// CurrencyImbalance is a currency whose wallet balances do not add up to its net external flows.
+type CurrencyImbalance struct {
+	Currency string          `db:"currency"`
+	Balances decimal.Decimal `db:"balances"`
+	NetFlows decimal.Decimal `db:"net_flows"` // Paid in by deposits, vouchers and conversion credits, less paid out
+}
+
+// OrphanCount is the number of rows of a relation referencing a missing row.
+type OrphanCount struct {
+	Relation string `db:"relation"` // E.g. "transactions.from_wallet_id -> wallets"
+	Rows     int64  `db:"rows"`
+}
+
+// LedgerBalance is a wallet's balance next to the balance recomputed from its completed transactions.
+type LedgerBalance struct {
+	WalletID       int64           `db:"wallet_id"`
+	WalletPublicID uuid.UUID       `db:"wallet_public_id"`
+	Currency       string          `db:"currency"`
+	Balance        decimal.Decimal `db:"balance"` // Own balance plus the balance shards
+	Expected       decimal.Decimal `db:"expected"`
+}
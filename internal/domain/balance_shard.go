@@ -0,0 +1,30 @@
+// internal/domain/balance_shard.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// MaxBalanceShards is the most shards a wallet's balance can be split into.
+const MaxBalanceShards = 64
+
+// BalanceShard is one of the sub-balances of a sharded wallet. Money movements update one shard each, so
+// that concurrent movements of the wallet do not all wait for the lock of a single row.
+type BalanceShard struct {
+	WalletID       int64           `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID       `db:"wallet_public_id" json:"-"`
+	Shard          int             `db:"shard" json:"shard"`
+	Balance        decimal.Decimal `db:"balance" json:"balance"`
+	Version        int64           `db:"version" json:"version"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// WalletShards is a sharded wallet with its shards, in shard order.
+type WalletShards struct {
+	WalletID       int64
+	WalletPublicID uuid.UUID
+	Shards         []BalanceShard
+}
@@ -0,0 +1,80 @@
+// internal/domain/bill.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BillStatus defines the lifecycle of a shared bill.
+type BillStatus string
+
+const (
+	BillStatusOpen      BillStatus = "OPEN"      // Participants still owe part of the bill
+	BillStatusSettled   BillStatus = "SETTLED"   // Every participant has paid their share
+	BillStatusCancelled BillStatus = "CANCELLED" // Withdrawn by the owner; payments already made are kept
+)
+
+// ParticipantStatus defines where a participant stands on their share of a bill.
+type ParticipantStatus string
+
+const (
+	ParticipantStatusPending  ParticipantStatus = "PENDING"  // Share not yet approved
+	ParticipantStatusApproved ParticipantStatus = "APPROVED" // Share approved; payments accepted
+	ParticipantStatusPaid     ParticipantStatus = "PAID"     // Share paid in full
+)
+
+// Bill is an amount owed to the owner wallet, split between participant wallets.
+type Bill struct {
+	ID                  int64             `db:"id" json:"-"`
+	PublicID            uuid.UUID         `db:"public_id" json:"id"`
+	OwnerWalletID       int64             `db:"owner_wallet_id" json:"-"`
+	OwnerWalletPublicID uuid.UUID         `db:"owner_wallet_public_id" json:"owner_wallet_id"` // Read-only, joined from wallets
+	Amount              decimal.Decimal   `db:"amount" json:"amount"`                          // The sum of the participants' shares
+	Currency            string            `db:"currency" json:"currency"`
+	Description         *string           `db:"description" json:"description"`
+	Status              BillStatus        `db:"status" json:"status"`
+	Participants        []BillParticipant `db:"-" json:"participants"`
+	CreatedAt           time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// Participant returns the participant paying from walletID, or nil if the wallet is not part of the bill.
+func (b *Bill) Participant(walletID int64) *BillParticipant {
+	for i := range b.Participants {
+		if b.Participants[i].WalletID == walletID {
+			return &b.Participants[i]
+		}
+	}
+	return nil
+}
+
+// PaidAmount returns how much of the bill the participants have paid so far.
+func (b *Bill) PaidAmount() decimal.Decimal {
+	paid := decimal.Zero
+	for _, participant := range b.Participants {
+		paid = paid.Add(participant.PaidAmount)
+	}
+	return paid
+}
+
+// BillParticipant is one wallet's share of a bill and the progress of paying it.
+type BillParticipant struct {
+	BillID         int64             `db:"bill_id" json:"-"`
+	BillPublicID   uuid.UUID         `db:"bill_public_id" json:"-"` // Read-only, joined from bills
+	WalletID       int64             `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID         `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	ShareAmount    decimal.Decimal   `db:"share_amount" json:"share_amount"`
+	PaidAmount     decimal.Decimal   `db:"paid_amount" json:"paid_amount"`
+	Status         ParticipantStatus `db:"status" json:"status"`
+	ApprovedAt     *time.Time        `db:"approved_at" json:"approved_at"`
+	LastRemindedAt *time.Time        `db:"last_reminded_at" json:"last_reminded_at"`
+	UpdatedAt      time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// Outstanding returns the part of the share not paid yet.
+func (p *BillParticipant) Outstanding() decimal.Decimal {
+	return p.ShareAmount.Sub(p.PaidAmount)
+}
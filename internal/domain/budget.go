@@ -0,0 +1,91 @@
+// internal/domain/budget.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BudgetPeriod defines how often a budget rolls over.
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDaily   BudgetPeriod = "DAILY"   // Midnight to midnight
+	BudgetPeriodWeekly  BudgetPeriod = "WEEKLY"  // Monday to Monday
+	BudgetPeriodMonthly BudgetPeriod = "MONTHLY" // Calendar month
+)
+
+// Window returns the period containing t as a half-open range [start, end), with days starting at
+// midnight in loc. A day is not always 24 hours long: across a daylight saving change it is 23 or 25.
+func (p BudgetPeriod) Window(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	switch p {
+	case BudgetPeriodDaily:
+		return day, day.AddDate(0, 0, 1)
+	case BudgetPeriodWeekly:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)) // Back to Monday
+		return start, start.AddDate(0, 0, 7)
+	}
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// BudgetEnforcement defines what happens when spending exceeds a budget.
+type BudgetEnforcement string
+
+const (
+	BudgetEnforcementNone      BudgetEnforcement = "NONE"       // Tracked only
+	BudgetEnforcementAlert     BudgetEnforcement = "ALERT"      // An alert is raised by the transaction that crosses the limit
+	BudgetEnforcementSoftBlock BudgetEnforcement = "SOFT_BLOCK" // Spending past the limit is refused unless explicitly overridden
+)
+
+// Budget limits the outgoing spending (withdrawals and transfers) of a wallet per period,
+// optionally only for one transaction category.
+type Budget struct {
+	ID             int64             `db:"id" json:"id"`
+	WalletID       int64             `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID         `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	Category       *string           `db:"category" json:"category"`          // Nil budgets all spending
+	Period         BudgetPeriod      `db:"period" json:"period"`
+	Limit          decimal.Decimal   `db:"amount_limit" json:"limit"`
+	Enforcement    BudgetEnforcement `db:"enforcement" json:"enforcement"`
+	OwnerID        int64             `db:"owner_id" json:"-"`       // Read-only, the wallet's user
+	OwnerTimezone  string            `db:"owner_timezone" json:"-"` // Read-only, joined from the wallet's user
+	CreatedAt      time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// Location returns the time zone the budget's periods are measured in: that of the wallet's owner.
+func (b *Budget) Location() *time.Location {
+	return TimezoneLocation(b.OwnerTimezone)
+}
+
+// Covers reports whether spending in the given category counts against the budget.
+func (b *Budget) Covers(category *string) bool {
+	return b.Category == nil || (category != nil && *category == *b.Category)
+}
+
+// BudgetStatus is a budget's consumption in its current period.
+type BudgetStatus struct {
+	Budget      Budget          `json:"budget"`
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+	Spent       decimal.Decimal `json:"spent"`
+	Remaining   decimal.Decimal `json:"remaining"` // Zero once exceeded
+	Exceeded    bool            `json:"exceeded"`
+}
+
+// NewBudgetStatus computes the status of a budget given the amount spent in the period.
+func NewBudgetStatus(budget Budget, start, end time.Time, spent decimal.Decimal) BudgetStatus {
+	return BudgetStatus{
+		Budget:      budget,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Spent:       spent,
+		Remaining:   decimal.Max(budget.Limit.Sub(spent), decimal.Zero),
+		Exceeded:    spent.GreaterThan(budget.Limit),
+	}
+}
@@ -0,0 +1,26 @@
+// internal/domain/change.go
+package domain
+
+// MaxChangesPerPage bounds the number of changes returned by one request to the change feed.
+const MaxChangesPerPage = 500
+
+// WalletChange is a wallet as of its latest change, numbered by the change sequence.
+type WalletChange struct {
+	Wallet
+	Seq int64 `db:"change_seq"`
+}
+
+// TransactionChange is a transaction as of its latest change, numbered by the change sequence.
+type TransactionChange struct {
+	Transaction
+	Seq int64 `db:"change_seq"`
+}
+
+// ChangeSet is one page of the change feed: everything that changed after the requested cursor and up to
+// Cursor, in change order. A row changed several times appears once, as of its latest change.
+type ChangeSet struct {
+	Wallets      []WalletChange
+	Transactions []TransactionChange
+	Cursor       int64 // Pass as since to continue; unchanged when nothing new was found
+	HasMore      bool  // More changes are available right away
+}
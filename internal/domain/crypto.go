@@ -0,0 +1,174 @@
+// internal/domain/crypto.go
+package domain
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CryptoAsset is a crypto asset wallets can hold: its currency code, the chain it moves on and how its
+// amounts and deposits are counted.
+type CryptoAsset struct {
+	Code          string
+	Network       string // The chain deposits and payouts are made on, e.g. "ethereum"
+	Places        int32  // Decimal places amounts are kept with, at most MaxAmountScale
+	Confirmations int    // Blocks mined on top of a deposit before it is credited
+	PeggedTo      string // The fiat currency a stablecoin tracks; empty for other assets
+}
+
+// Stablecoin reports whether the asset is a stablecoin, which fiat wallets can ramp to and from.
+func (a CryptoAsset) Stablecoin() bool {
+	return a.PeggedTo != ""
+}
+
+// cryptoAssets is the registry of supported crypto assets. ETH has 18 decimal places on chain but is kept
+// with the 8 the amount columns store; the dust of a deposit below that is not credited.
+var cryptoAssets = map[string]CryptoAsset{
+	"BTC":  {Code: "BTC", Network: "bitcoin", Places: 8, Confirmations: 3},
+	"ETH":  {Code: "ETH", Network: "ethereum", Places: 8, Confirmations: 12},
+	"USDC": {Code: "USDC", Network: "ethereum", Places: 6, Confirmations: 12, PeggedTo: "USD"},
+}
+
+var cryptoEnabled atomic.Bool
+
+// SetCryptoAssetsEnabled makes the crypto assets supported currencies or not. They are enabled at startup
+// when a chain gateway is configured, as nothing could be deposited into or paid out of their wallets without one.
+func SetCryptoAssetsEnabled(enabled bool) {
+	cryptoEnabled.Store(enabled)
+}
+
+// CryptoAssetsEnabled reports whether wallets of crypto assets can be created and funded.
+func CryptoAssetsEnabled() bool {
+	return cryptoEnabled.Load()
+}
+
+// LookupCryptoAsset returns the crypto asset with the currency code, enabled or not.
+func LookupCryptoAsset(code string) (CryptoAsset, bool) {
+	asset, ok := cryptoAssets[code]
+	return asset, ok
+}
+
+// IsCryptoAsset reports whether code is the currency code of a crypto asset in the registry.
+func IsCryptoAsset(code string) bool {
+	_, ok := cryptoAssets[code]
+	return ok
+}
+
+// CryptoAssets returns the registered crypto assets ordered by code.
+func CryptoAssets() []CryptoAsset {
+	assets := make([]CryptoAsset, 0, len(cryptoAssets))
+	for _, asset := range cryptoAssets {
+		assets = append(assets, asset)
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].Code < assets[j].Code })
+	return assets
+}
+
+// MaxCryptoPlaces returns the most decimal places any crypto asset is kept with; amounts must be accepted with
+// at least as many for crypto assets to be enabled.
+func MaxCryptoPlaces() int32 {
+	var places int32
+	for _, asset := range cryptoAssets {
+		places = max(places, asset.Places)
+	}
+	return places
+}
+
+// CryptoAddress is a deposit address issued to a crypto wallet by the chain gateway. Anything sent to it on
+// chain is credited to the wallet once confirmed. A wallet may have several; none is ever reassigned.
+type CryptoAddress struct {
+	ID             int64     `db:"id" json:"-"`
+	WalletID       int64     `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	Asset          string    `db:"asset" json:"asset"`
+	Network        string    `db:"network" json:"network"`
+	Address        string    `db:"address" json:"address"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// CryptoDepositStatus defines the lifecycle of an inbound crypto deposit.
+type CryptoDepositStatus string
+
+const (
+	CryptoDepositPending  CryptoDepositStatus = "PENDING"  // Seen on chain; waiting for confirmations
+	CryptoDepositCredited CryptoDepositStatus = "CREDITED" // Confirmed and booked as a DEPOSIT transaction
+)
+
+// CryptoDeposit is an on-chain transfer to a deposit address, identified by its transaction hash and output
+// index. It is recorded when the chain gateway first reports it and credited once it has enough confirmations.
+type CryptoDeposit struct {
+	ID             int64               `db:"id" json:"-"`
+	WalletID       int64               `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID           `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	Asset          string              `db:"asset" json:"asset"`
+	Network        string              `db:"network" json:"network"`
+	Address        string              `db:"address" json:"address"`
+	TxHash         string              `db:"tx_hash" json:"tx_hash"`
+	OutputIndex    int                 `db:"output_index" json:"output_index"`
+	Amount         decimal.Decimal     `db:"amount" json:"amount"` // Truncated to the asset's places
+	Confirmations  int                 `db:"confirmations" json:"confirmations"`
+	Status         CryptoDepositStatus `db:"status" json:"status"`
+	TransactionID  *uuid.UUID          `db:"transaction_id" json:"transaction_id"` // Public ID of the DEPOSIT once credited
+	DetectedAt     time.Time           `db:"detected_at" json:"detected_at"`
+	CreditedAt     *time.Time          `db:"credited_at" json:"credited_at"`
+}
+
+// CryptoPayoutStatus defines the lifecycle of a crypto withdrawal in the payout pipeline.
+type CryptoPayoutStatus string
+
+const (
+	CryptoPayoutQueued  CryptoPayoutStatus = "QUEUED"  // Debited from the wallet; waiting to be sent
+	CryptoPayoutSending CryptoPayoutStatus = "SENDING" // Claimed by the payout worker; requeued if it stalls
+	CryptoPayoutSent    CryptoPayoutStatus = "SENT"    // Broadcast by the chain gateway
+	CryptoPayoutFailed  CryptoPayoutStatus = "FAILED"  // Rejected by the chain gateway; the debit was refunded
+)
+
+// CryptoPayout is a withdrawal of a crypto wallet to an external address. The amount and the estimated network
+// fee are debited from the wallet as one WITHDRAWAL when it is requested; the payout worker then sends the
+// amount, and a rejected payout is refunded in full by a DEPOSIT pointing at the withdrawal.
+type CryptoPayout struct {
+	ID                  int64              `db:"id" json:"-"`
+	PublicID            uuid.UUID          `db:"public_id" json:"id"`
+	WalletID            int64              `db:"wallet_id" json:"-"`
+	WalletPublicID      uuid.UUID          `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	Asset               string             `db:"asset" json:"asset"`
+	Network             string             `db:"network" json:"network"`
+	Address             string             `db:"address" json:"address"`
+	Amount              decimal.Decimal    `db:"amount" json:"amount"` // Received by the address
+	Fee                 decimal.Decimal    `db:"fee" json:"fee"`       // Network fee estimated when requested
+	Status              CryptoPayoutStatus `db:"status" json:"status"`
+	TransactionID       uuid.UUID          `db:"transaction_id" json:"transaction_id"`               // Public ID of the WITHDRAWAL
+	RefundTransactionID *uuid.UUID         `db:"refund_transaction_id" json:"refund_transaction_id"` // Public ID of the refund of a failed payout
+	ChainTxHash         *string            `db:"chain_tx_hash" json:"chain_tx_hash"`                 // Set once sent
+	Attempts            int                `db:"attempts" json:"attempts"`
+	LastError           *string            `db:"last_error" json:"last_error"`
+	CreatedAt           time.Time          `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time          `db:"updated_at" json:"updated_at"`
+}
+
+// NewCryptoPayout creates a queued payout of amount from the wallet to address, at the estimated fee.
+func NewCryptoPayout(wallet *Wallet, asset CryptoAsset, address string, amount, fee decimal.Decimal) *CryptoPayout {
+	now := time.Now().UTC()
+	return &CryptoPayout{
+		PublicID:       uuid.New(),
+		WalletID:       wallet.ID,
+		WalletPublicID: wallet.PublicID,
+		Asset:          asset.Code,
+		Network:        asset.Network,
+		Address:        address,
+		Amount:         amount,
+		Fee:            fee,
+		Status:         CryptoPayoutQueued,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Total returns what the payout debits from the wallet: the amount and the network fee.
+func (p *CryptoPayout) Total() decimal.Decimal {
+	return p.Amount.Add(p.Fee)
+}
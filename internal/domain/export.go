@@ -0,0 +1,18 @@
+// internal/domain/export.go
+package domain
+
+import "time"
+
+// Streams exported to the data warehouse.
+const (
+	ExportStreamTransactions = "transactions" // Incremental; every transaction is exported once, in ID order
+	ExportStreamWallets      = "wallets"      // Snapshot of every wallet, taken once per UTC day
+)
+
+// ExportWatermark records how far an export stream has got, so each run only exports what is new.
+type ExportWatermark struct {
+	Stream     string    `db:"stream"`
+	LastID     int64     `db:"last_id"`
+	ExportedAt time.Time `db:"exported_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
@@ -0,0 +1,31 @@
+// internal/domain/fx_rate.go
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FXRate is the exchange rate of one currency pair: one unit of Base buys Rate units of Quote.
+type FXRate struct {
+	Base  string          `db:"base_currency" json:"base"`
+	Quote string          `db:"quote_currency" json:"quote"`
+	Rate  decimal.Decimal `db:"rate" json:"rate"`
+	AsOf  time.Time       `db:"as_of" json:"as_of"` // When the provider published the rate
+}
+
+// Pair returns the pair in BASE/QUOTE notation, e.g. "USD/HKD".
+func (r FXRate) Pair() string {
+	return r.Base + "/" + r.Quote
+}
+
+// Inverse returns the rate of the reverse pair, published at the same time.
+func (r FXRate) Inverse() FXRate {
+	return FXRate{Base: r.Quote, Quote: r.Base, Rate: decimal.NewFromInt(1).DivRound(r.Rate, 10), AsOf: r.AsOf}
+}
+
+// IsStale reports whether the rate is older than maxAge at the given time.
+func (r FXRate) IsStale(now time.Time, maxAge time.Duration) bool {
+	return now.Sub(r.AsOf) > maxAge
+}
@@ -0,0 +1,42 @@
+// internal/domain/identity.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to their account at an OpenID Connect provider, so they log in with the
+// provider's ID tokens. A user has at most one identity per provider.
+type UserIdentity struct {
+	ID          int64      `db:"id" json:"-"`
+	PublicID    uuid.UUID  `db:"public_id" json:"id"`
+	UserID      int64      `db:"user_id" json:"user_id"`
+	Provider    string     `db:"provider" json:"provider"` // Name of the provider in OIDC_PROVIDERS
+	Subject     string     `db:"subject" json:"subject"`   // The provider's "sub", stable per user
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	LastLoginAt *time.Time `db:"last_login_at" json:"last_login_at"`
+}
+
+// NewUserIdentity creates a new UserIdentity instance.
+func NewUserIdentity(userID int64, provider, subject string) *UserIdentity {
+	return &UserIdentity{
+		PublicID:  uuid.New(),
+		UserID:    userID,
+		Provider:  provider,
+		Subject:   subject,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// IDTokenClaims are the verified claims of an OpenID Connect ID token that login uses.
+type IDTokenClaims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+	ExpiresAt         time.Time
+}
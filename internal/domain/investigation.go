@@ -0,0 +1,58 @@
+// internal/domain/investigation.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TransactionReferenceKind names the kind of record that references a transaction.
+type TransactionReferenceKind string
+
+const (
+	ReferenceTransferApproval  TransactionReferenceKind = "transfer_approval"
+	ReferenceSettlement        TransactionReferenceKind = "settlement"
+	ReferenceCharge            TransactionReferenceKind = "charge"
+	ReferenceVoucher           TransactionReferenceKind = "voucher"
+	ReferenceNettingSettlement TransactionReferenceKind = "netting_settlement"
+	ReferenceInboundFunds      TransactionReferenceKind = "inbound_funds"
+	ReferenceAdjustment        TransactionReferenceKind = "adjustment"
+)
+
+// TransactionReference is a record, such as the charge a payment paid or the adjustment posted by a
+// transaction, that references a transaction by its public ID.
+type TransactionReference struct {
+	Kind      TransactionReferenceKind `db:"kind" json:"kind"`
+	PublicID  uuid.UUID                `db:"public_id" json:"id"`
+	Status    *string                  `db:"status" json:"status"` // Nil for records without a status
+	Amount    decimal.Decimal          `db:"amount" json:"amount"`
+	CreatedAt time.Time                `db:"created_at" json:"created_at"`
+}
+
+// TransactionAuditEntry is a request made with an impersonation session that touched a transaction.
+type TransactionAuditEntry struct {
+	ImpersonationAuditEntry
+	SessionPublicID uuid.UUID `db:"session_public_id" json:"session_id"`
+	UserID          int64     `db:"user_id" json:"-"`
+	Operator        string    `db:"operator" json:"operator"`
+}
+
+// TransactionInvestigation gathers a transaction with the entities linked to it, for support investigating it
+// in one view. The wallets and users of a side the transaction does not have, e.g. the sender of a deposit,
+// are nil.
+type TransactionInvestigation struct {
+	Transaction   *Transaction
+	FromWallet    *Wallet
+	ToWallet      *Wallet
+	FromUser      *User
+	ToUser        *User
+	Parent        *Transaction  // The SPLIT or conversion parent, if any
+	Children      []Transaction // The legs of a SPLIT, or the credit leg of a conversion
+	References    []TransactionReference
+	Annotation    *TransactionAnnotation
+	Notifications []Notification
+	Deliveries    []NotificationDelivery
+	Audit         []TransactionAuditEntry
+}
@@ -0,0 +1,61 @@
+// internal/domain/journal.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ChartOfAccounts maps wallet activity to the general ledger account codes of the journal export. Every
+// transaction moves money from one side to the other: a side with a wallet books to WalletAccount, and the
+// side without one, e.g. the bank of a deposit, to the counter account of the transaction's type.
+type ChartOfAccounts struct {
+	WalletAccount   string                     `json:"wallet_account"`   // Customer wallet balances, a liability
+	PromoAccount    string                     `json:"promo_account"`    // Funds the part of a transaction paid with promotional credits
+	SuspenseAccount string                     `json:"suspense_account"` // Takes any side no other account is mapped to
+	CounterAccounts map[TransactionType]string `json:"counter_accounts"` // Per type, the account of the side without a wallet
+}
+
+// DefaultChartOfAccounts returns the built-in mapping, used for every account a configured chart leaves out.
+func DefaultChartOfAccounts() ChartOfAccounts {
+	return ChartOfAccounts{
+		WalletAccount:   "2000",
+		PromoAccount:    "6100",
+		SuspenseAccount: "9999",
+		CounterAccounts: map[TransactionType]string{
+			TransactionTypeDeposit:    "1100", // Cash clearing
+			TransactionTypeWithdrawal: "1100",
+			TransactionTypeVoucher:    "2100", // Voucher liability
+			TransactionTypeConversion: "1900", // FX clearing
+			TransactionTypeAdjustment: "6900", // Manual adjustments
+		},
+	}
+}
+
+// CounterAccount returns the account of the side without a wallet of a transaction of the given type.
+func (c *ChartOfAccounts) CounterAccount(txType TransactionType) string {
+	if account, ok := c.CounterAccounts[txType]; ok && account != "" {
+		return account
+	}
+	return c.SuspenseAccount
+}
+
+// JournalLine is one debit or credit of a journal entry. Exactly one of Debit and Credit is non-zero.
+type JournalLine struct {
+	Account  string
+	WalletID *uuid.UUID // The wallet booked, if the line books to WalletAccount
+	Debit    decimal.Decimal
+	Credit   decimal.Decimal
+}
+
+// JournalEntry is the balanced double-entry booking of one transaction: its debits equal its credits.
+type JournalEntry struct {
+	TransactionID   uuid.UUID
+	TransactionTime time.Time
+	Type            TransactionType
+	Currency        string
+	Description     *string
+	Lines           []JournalLine
+}
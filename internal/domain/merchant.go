@@ -0,0 +1,73 @@
+// internal/domain/merchant.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// MerchantSettings are the settlement preferences of a merchant wallet.
+type MerchantSettings struct {
+	WalletID             int64           `db:"wallet_id" json:"-"`
+	PayoutWalletID       int64           `db:"payout_wallet_id" json:"-"`
+	WalletPublicID       uuid.UUID       `db:"wallet_public_id" json:"wallet_id"`               // Read-only, joined from wallets
+	PayoutWalletPublicID uuid.UUID       `db:"payout_wallet_public_id" json:"payout_wallet_id"` // Read-only, joined from wallets
+	MinPayoutAmount      decimal.Decimal `db:"min_payout_amount" json:"min_payout_amount"`      // Smaller daily batches roll over
+	OwnerTimezone        string          `db:"owner_timezone" json:"-"`                         // Read-only, joined from the merchant wallet's user; sets the business day
+	CreatedAt            time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// ChargeStatus defines the lifecycle of a charge.
+type ChargeStatus string
+
+const (
+	ChargeStatusPending   ChargeStatus = "PENDING"   // Awaiting payment by a customer
+	ChargeStatusPaid      ChargeStatus = "PAID"      // Paid into the merchant wallet
+	ChargeStatusCancelled ChargeStatus = "CANCELLED" // Withdrawn by the merchant
+	ChargeStatusExpired   ChargeStatus = "EXPIRED"   // Not paid before ExpiresAt
+)
+
+// Charge is a payment request created by a merchant and confirmed by a customer.
+type Charge struct {
+	ID                     int64           `db:"id" json:"-"`
+	PublicID               uuid.UUID       `db:"public_id" json:"id"`
+	MerchantWalletID       int64           `db:"merchant_wallet_id" json:"-"`
+	MerchantWalletPublicID uuid.UUID       `db:"merchant_wallet_public_id" json:"merchant_wallet_id"` // Read-only, joined from wallets
+	Amount                 decimal.Decimal `db:"amount" json:"amount"`
+	Currency               string          `db:"currency" json:"currency"`
+	Description            *string         `db:"description" json:"description"`
+	Reference              *string         `db:"reference" json:"reference"` // The merchant's own order reference
+	Status                 ChargeStatus    `db:"status" json:"status"`
+	PayerWalletID          *int64          `db:"payer_wallet_id" json:"-"`
+	PayerWalletPublicID    *uuid.UUID      `db:"payer_wallet_public_id" json:"payer_wallet_id"` // Read-only, joined from wallets
+	TransactionID          *uuid.UUID      `db:"transaction_id" json:"transaction_id"`          // Set once paid
+	SettlementID           *int64          `db:"settlement_id" json:"-"`                        // Set once paid out to the merchant
+	ExpiresAt              time.Time       `db:"expires_at" json:"expires_at"`
+	PaidAt                 *time.Time      `db:"paid_at" json:"paid_at"`
+	CreatedAt              time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt              time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// Expired reports whether a pending charge can no longer be paid at t.
+func (c *Charge) Expired(t time.Time) bool {
+	return c.Status == ChargeStatusPending && !t.Before(c.ExpiresAt)
+}
+
+// Settlement is one daily payout of a merchant's paid charges to its payout wallet.
+type Settlement struct {
+	ID                     int64           `db:"id" json:"-"`
+	PublicID               uuid.UUID       `db:"public_id" json:"id"`
+	MerchantWalletID       int64           `db:"merchant_wallet_id" json:"-"`
+	PayoutWalletID         int64           `db:"payout_wallet_id" json:"-"`
+	MerchantWalletPublicID uuid.UUID       `db:"merchant_wallet_public_id" json:"merchant_wallet_id"` // Read-only, joined from wallets
+	PayoutWalletPublicID   uuid.UUID       `db:"payout_wallet_public_id" json:"payout_wallet_id"`     // Read-only, joined from wallets
+	Amount                 decimal.Decimal `db:"amount" json:"amount"`
+	Currency               string          `db:"currency" json:"currency"`
+	ChargeCount            int             `db:"charge_count" json:"charge_count"`
+	SettlementDate         time.Time       `db:"settlement_date" json:"settlement_date"` // The business day (UTC) closed
+	TransactionID          uuid.UUID       `db:"transaction_id" json:"transaction_id"`   // The payout transaction
+	CreatedAt              time.Time       `db:"created_at" json:"created_at"`
+}
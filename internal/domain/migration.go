@@ -0,0 +1,32 @@
+// internal/domain/migration.go
+package domain
+
+import "time"
+
+// BackfillProgress is the progress of an out-of-band backfill.
+type BackfillProgress struct {
+	Name        string     `db:"name" json:"name"`
+	LastKey     int64      `db:"last_key" json:"last_key"` // Key of the last row covered; the next batch starts after it
+	RowsDone    int64      `db:"rows_done" json:"rows_done"`
+	Batches     int64      `db:"batches" json:"batches"`
+	LastError   *string    `db:"last_error" json:"last_error,omitempty"` // Of the last failed batch, cleared by the next success
+	StartedAt   *time.Time `db:"started_at" json:"started_at"`           // Nil before the first batch
+	UpdatedAt   *time.Time `db:"updated_at" json:"updated_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at"`
+}
+
+// MigrationInfo describes a migration of the code.
+type MigrationInfo struct {
+	Version uint64 `json:"version"`
+	Name    string `json:"name"`
+	Phase   string `json:"phase"` // expand or contract
+}
+
+// MigrationStatus compares the schema of the database with the migrations of the code.
+type MigrationStatus struct {
+	Version   uint64             `json:"version"` // Last migration applied to the database
+	Dirty     bool               `json:"dirty"`   // The last migration failed halfway and needs fixing by hand
+	Latest    uint64             `json:"latest"`  // Last migration of the code
+	Pending   []MigrationInfo    `json:"pending"` // Migrations of the code not applied yet, in order
+	Backfills []BackfillProgress `json:"backfills"`
+}
@@ -0,0 +1,80 @@
+// internal/domain/netting.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// NettingInstructionStatus defines the lifecycle of a netting instruction.
+type NettingInstructionStatus string
+
+const (
+	NettingInstructionStatusPending NettingInstructionStatus = "PENDING" // Accumulating until the window closes
+	NettingInstructionStatusSettled NettingInstructionStatus = "SETTLED" // Part of a net settlement
+)
+
+// NettingInstruction is a transfer instruction that moves no money on its own: the instructions between two
+// wallets are accumulated during a netting window and settled as a single net transfer when it closes.
+type NettingInstruction struct {
+	ID                 int64                    `db:"id" json:"-"`
+	PublicID           uuid.UUID                `db:"public_id" json:"id"`
+	FromWalletID       int64                    `db:"from_wallet_id" json:"-"`
+	ToWalletID         int64                    `db:"to_wallet_id" json:"-"`
+	FromWalletPublicID uuid.UUID                `db:"from_wallet_public_id" json:"from_wallet_id"` // Read-only, joined from wallets
+	ToWalletPublicID   uuid.UUID                `db:"to_wallet_public_id" json:"to_wallet_id"`     // Read-only, joined from wallets
+	Amount             decimal.Decimal          `db:"amount" json:"amount"`
+	Currency           string                   `db:"currency" json:"currency"`
+	Reference          *string                  `db:"reference" json:"reference"` // The submitter's own reference
+	Status             NettingInstructionStatus `db:"status" json:"status"`
+	SettlementID       *int64                   `db:"settlement_id" json:"-"`
+	SettlementPublicID *uuid.UUID               `db:"settlement_public_id" json:"settlement_id"` // Read-only, joined from netting_settlements
+	TransactionID      *uuid.UUID               `db:"transaction_id" json:"transaction_id"`      // Read-only, the net transfer; nil until settled or if the flows cancelled out
+	SettledAt          *time.Time               `db:"settled_at" json:"settled_at"`
+	CreatedAt          time.Time                `db:"created_at" json:"created_at"`
+}
+
+// NettingPair identifies the pending instructions of one netting window: those between two wallets, in
+// either direction, in one currency. WalletA is the lower wallet ID.
+type NettingPair struct {
+	WalletA  int64  `db:"wallet_a"`
+	WalletB  int64  `db:"wallet_b"`
+	Currency string `db:"currency"`
+}
+
+// NettingSettlement is the single net transfer that settled a window's instructions between two wallets.
+type NettingSettlement struct {
+	ID                 int64           `db:"id" json:"-"`
+	PublicID           uuid.UUID       `db:"public_id" json:"id"`
+	FromWalletID       int64           `db:"from_wallet_id" json:"-"`                     // The net payer
+	ToWalletID         int64           `db:"to_wallet_id" json:"-"`                       // The net payee
+	FromWalletPublicID uuid.UUID       `db:"from_wallet_public_id" json:"from_wallet_id"` // Read-only, joined from wallets
+	ToWalletPublicID   uuid.UUID       `db:"to_wallet_public_id" json:"to_wallet_id"`     // Read-only, joined from wallets
+	NetAmount          decimal.Decimal `db:"net_amount" json:"net_amount"`                // Zero if the flows cancelled out
+	GrossAmount        decimal.Decimal `db:"gross_amount" json:"gross_amount"`            // Sum of all instructions, both directions
+	Currency           string          `db:"currency" json:"currency"`
+	InstructionCount   int             `db:"instruction_count" json:"instruction_count"`
+	TransactionID      *uuid.UUID      `db:"transaction_id" json:"transaction_id"` // The NETTING transaction; nil if NetAmount is zero
+	WindowEnd          time.Time       `db:"window_end" json:"window_end"`         // Instructions created before it were settled
+	CreatedAt          time.Time       `db:"created_at" json:"created_at"`
+}
+
+// NetFlows nets instructions between walletA and walletB: it returns the net payer, the net payee, the net
+// amount and the gross amount. When the flows cancel out, walletA is reported as the payer of zero.
+func NetFlows(walletA, walletB int64, instructions []NettingInstruction) (from, to int64, net, gross decimal.Decimal) {
+	balance := decimal.Zero // What walletA owes walletB
+	for _, instruction := range instructions {
+		gross = gross.Add(instruction.Amount)
+		if instruction.FromWalletID == walletA {
+			balance = balance.Add(instruction.Amount)
+		} else {
+			balance = balance.Sub(instruction.Amount)
+		}
+	}
+	if balance.IsNegative() {
+		return walletB, walletA, balance.Neg(), gross
+	}
+	return walletA, walletB, balance, gross
+}
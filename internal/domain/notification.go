@@ -0,0 +1,122 @@
+// internal/domain/notification.go
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Limits of registered push devices.
+const (
+	MaxDevicesPerUser   = 20
+	MaxDeviceTokenBytes = 4096
+)
+
+// PushPlatform is the push service a device token belongs to.
+type PushPlatform string
+
+const (
+	PushPlatformFCM  PushPlatform = "FCM"  // Firebase Cloud Messaging: Android and web
+	PushPlatformAPNs PushPlatform = "APNS" // Apple Push Notification service: iOS
+)
+
+// Valid reports whether p is a known platform.
+func (p PushPlatform) Valid() bool {
+	return p == PushPlatformFCM || p == PushPlatformAPNs
+}
+
+// DeviceToken is a registration of one of a user's devices with a push service.
+type DeviceToken struct {
+	ID        int64        `db:"id" json:"id"`
+	UserID    int64        `db:"user_id" json:"-"`
+	Platform  PushPlatform `db:"platform" json:"platform"`
+	Token     string       `db:"token" json:"-"` // Issued by the push service; not echoed back
+	Label     *string      `db:"label" json:"label"`
+	CreatedAt time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at" json:"updated_at"` // Last registration
+}
+
+// NotificationCategory groups notifications whose delivery a user can switch on or off together.
+type NotificationCategory string
+
+const (
+	NotificationCategoryTransaction NotificationCategory = "TRANSACTION" // Money moved into or out of a wallet
+	NotificationCategorySecurity    NotificationCategory = "SECURITY"    // E.g. a login from a new device
+	NotificationCategoryBudget      NotificationCategory = "BUDGET"      // A budget alert
+	NotificationCategoryRule        NotificationCategory = "RULE"        // A sweep rule that could not run
+)
+
+// NotificationCategories lists every category, in display order.
+var NotificationCategories = []NotificationCategory{NotificationCategoryTransaction, NotificationCategorySecurity, NotificationCategoryBudget, NotificationCategoryRule}
+
+// Valid reports whether c is a known category.
+func (c NotificationCategory) Valid() bool {
+	return slices.Contains(NotificationCategories, c)
+}
+
+// NotificationChannel is a way of delivering notifications.
+type NotificationChannel string
+
+const (
+	NotificationChannelPush NotificationChannel = "PUSH"
+)
+
+// Valid reports whether c is a known channel.
+func (c NotificationChannel) Valid() bool {
+	return c == NotificationChannelPush
+}
+
+// NotificationPreference switches the delivery of a category of notifications over a channel on or off for a
+// user. Without a stored preference, delivery is on.
+type NotificationPreference struct {
+	UserID    int64                `db:"user_id" json:"-"`
+	Category  NotificationCategory `db:"category" json:"category"`
+	Channel   NotificationChannel  `db:"channel" json:"channel"`
+	Enabled   bool                 `db:"enabled" json:"enabled"`
+	UpdatedAt *time.Time           `db:"updated_at" json:"updated_at"` // Nil for the default
+}
+
+// Notification is a message to a user. It is kept in their inbox, and delivered over each channel they
+// enabled for its category.
+type Notification struct {
+	ID        int64                `db:"id" json:"id"`
+	UserID    int64                `db:"user_id" json:"-"`
+	Category  NotificationCategory `db:"category" json:"category"`
+	Title     string               `db:"title" json:"title"`
+	Body      string               `db:"body" json:"body"`
+	Data      map[string]string    `db:"-" json:"data"`          // Passed to the app, e.g. the ID of the transaction to open; stored as JSONB
+	ReadAt    *time.Time           `db:"read_at" json:"read_at"` // Nil while unread
+	CreatedAt time.Time            `db:"created_at" json:"created_at"`
+}
+
+// DeliveryStatus is the state of a notification's delivery to one device.
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending DeliveryStatus = "PENDING" // Waiting to be sent, or retried after a transient failure
+	DeliveryStatusSent    DeliveryStatus = "SENT"    // Accepted by the push service
+	DeliveryStatusFailed  DeliveryStatus = "FAILED"  // Rejected, or still failing after the last attempt
+)
+
+// NotificationDelivery is the delivery of a notification to one of the user's devices.
+type NotificationDelivery struct {
+	ID                int64                `db:"id" json:"id"`
+	UserID            int64                `db:"user_id" json:"-"`
+	NotificationID    *int64               `db:"notification_id" json:"notification_id"` // Nil for deliveries queued before the inbox
+	DeviceID          *int64               `db:"device_token_id" json:"device_id"`       // Nil once the device is removed
+	Category          NotificationCategory `db:"category" json:"category"`
+	Channel           NotificationChannel  `db:"channel" json:"channel"`
+	Title             string               `db:"title" json:"title"`
+	Body              string               `db:"body" json:"body"`
+	Data              map[string]string    `db:"-" json:"data"` // Stored as JSONB
+	Status            DeliveryStatus       `db:"status" json:"status"`
+	Attempts          int                  `db:"attempts" json:"attempts"`
+	ProviderMessageID *string              `db:"provider_message_id" json:"provider_message_id"`
+	LastError         *string              `db:"last_error" json:"last_error"`
+	CreatedAt         time.Time            `db:"created_at" json:"created_at"`
+	SentAt            *time.Time           `db:"sent_at" json:"sent_at"`
+
+	// Read-only, joined from the device for sending
+	Platform    *PushPlatform `db:"platform" json:"-"`
+	DeviceToken *string       `db:"token" json:"-"`
+}
@@ -0,0 +1,71 @@
+// internal/domain/open_banking.go
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OBPermission is a data cluster an account access consent grants, named as in the UK Open Banking standard.
+type OBPermission string
+
+const (
+	OBReadAccountsDetail     OBPermission = "ReadAccountsDetail"
+	OBReadBalances           OBPermission = "ReadBalances"
+	OBReadTransactionsDetail OBPermission = "ReadTransactionsDetail"
+)
+
+// IsValid reports whether p is a permission the API grants.
+func (p OBPermission) IsValid() bool {
+	switch p {
+	case OBReadAccountsDetail, OBReadBalances, OBReadTransactionsDetail:
+		return true
+	}
+	return false
+}
+
+// OBConsentStatus is the state of an account access consent, named as in the UK Open Banking standard.
+type OBConsentStatus string
+
+const (
+	OBConsentAwaitingAuthorisation OBConsentStatus = "AwaitingAuthorisation" // Created by the third party
+	OBConsentAuthorised            OBConsentStatus = "Authorised"            // Granted by the user for some of their wallets
+	OBConsentRejected              OBConsentStatus = "Rejected"              // Refused by the user
+	OBConsentRevoked               OBConsentStatus = "Revoked"               // Withdrawn by the user or deleted by the third party
+)
+
+// OBConsent is an account access consent: a third party asks for permissions, and the user grants them for
+// the wallets they pick. The third party reads those wallets through the Open Banking API while the consent
+// is authorised and unexpired.
+type OBConsent struct {
+	ID                      int64           `db:"id" json:"-"`
+	ConsentID               uuid.UUID       `db:"consent_id" json:"consent_id"`
+	ClientID                string          `db:"client_id" json:"client_id"` // The third party, as identified by its access tokens
+	UserID                  *int64          `db:"user_id" json:"user_id"`     // Set when the user authorises or rejects it
+	Permissions             []OBPermission  `db:"-" json:"permissions"`       // Stored as permission_list
+	WalletIDs               []int64         `db:"-" json:"-"`                 // The wallets the user granted access to; stored as wallet_ids
+	WalletPublicIDs         []uuid.UUID     `db:"-" json:"wallet_ids"`        // Read-only, joined from wallets
+	Status                  OBConsentStatus `db:"status" json:"status"`
+	ExpirationDateTime      time.Time       `db:"expires_at" json:"expiration_date_time"`
+	TransactionFromDateTime *time.Time      `db:"transaction_from" json:"transaction_from_date_time"` // Earliest transaction readable
+	TransactionToDateTime   *time.Time      `db:"transaction_to" json:"transaction_to_date_time"`     // Latest transaction readable
+	CreationDateTime        time.Time       `db:"created_at" json:"creation_date_time"`
+	StatusUpdateDateTime    time.Time       `db:"status_updated_at" json:"status_update_date_time"`
+}
+
+// Grants reports whether the consent includes the permission.
+func (c *OBConsent) Grants(permission OBPermission) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+// CoversWallet reports whether the user granted access to the wallet.
+func (c *OBConsent) CoversWallet(walletID int64) bool {
+	return slices.Contains(c.WalletIDs, walletID)
+}
+
+// Active reports whether the consent lets the third party read data at now.
+func (c *OBConsent) Active(now time.Time) bool {
+	return c.Status == OBConsentAuthorised && now.Before(c.ExpirationDateTime)
+}
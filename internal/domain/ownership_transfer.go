@@ -0,0 +1,38 @@
+// internal/domain/ownership_transfer.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OwnershipTransferStatus defines the lifecycle of a wallet ownership transfer.
+type OwnershipTransferStatus string
+
+const (
+	OwnershipTransferPending   OwnershipTransferStatus = "PENDING"   // Requested by an admin; waiting for the target user
+	OwnershipTransferCompleted OwnershipTransferStatus = "COMPLETED" // Accepted by the target user, who now owns the wallet
+	OwnershipTransferDeclined  OwnershipTransferStatus = "DECLINED"  // Declined by the target user
+	OwnershipTransferCancelled OwnershipTransferStatus = "CANCELLED" // Cancelled by an admin
+	OwnershipTransferExpired   OwnershipTransferStatus = "EXPIRED"   // Not answered in time
+)
+
+// OwnershipTransfer is the admin-mediated reassignment of a wallet to another user, e.g. after a duplicate
+// registration. The wallet is frozen while the transfer is pending, and changes owner only once the target
+// user accepts it.
+type OwnershipTransfer struct {
+	ID             int64                   `db:"id" json:"-"`
+	PublicID       uuid.UUID               `db:"public_id" json:"id"`
+	WalletID       int64                   `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID               `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	FromUserID     int64                   `db:"from_user_id" json:"from_user_id"`  // The owner when the transfer was requested
+	ToUserID       int64                   `db:"to_user_id" json:"to_user_id"`
+	Status         OwnershipTransferStatus `db:"status" json:"status"`
+	Reason         string                  `db:"reason" json:"reason"`
+	RequestedBy    string                  `db:"requested_by" json:"requested_by"`
+	CancelledBy    *string                 `db:"cancelled_by" json:"cancelled_by"`
+	ExpiresAt      time.Time               `db:"expires_at" json:"expires_at"`
+	CreatedAt      time.Time               `db:"created_at" json:"created_at"`
+	ResolvedAt     *time.Time              `db:"resolved_at" json:"resolved_at"`
+}
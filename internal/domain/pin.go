@@ -0,0 +1,96 @@
+// internal/domain/pin.go
+package domain
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lengths of a transaction PIN, in digits.
+const (
+	MinPINLength = 4
+	MaxPINLength = 8
+)
+
+// pinHashIterations is the PBKDF2 iteration count of new PIN hashes. Stored hashes carry their own count.
+const pinHashIterations = 100_000
+
+// UserPIN is a user's transaction PIN, which confirms their withdrawals and transfers once set.
+type UserPIN struct {
+	UserID         int64      `db:"user_id"`
+	PINHash        string     `db:"pin_hash"` // See HashPIN
+	FailedAttempts int        `db:"failed_attempts"`
+	LockedUntil    *time.Time `db:"locked_until"`  // Set after too many failed attempts in a row
+	StepUpUntil    *time.Time `db:"step_up_until"` // Until then, operations are confirmed without the PIN
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+// Locked reports whether the PIN is locked out at t.
+func (p *UserPIN) Locked(t time.Time) bool {
+	return p.LockedUntil != nil && t.Before(*p.LockedUntil)
+}
+
+// SteppedUp reports whether a recent verification of the PIN still confirms operations at t.
+func (p *UserPIN) SteppedUp(t time.Time) bool {
+	return p.StepUpUntil != nil && t.Before(*p.StepUpUntil)
+}
+
+// ValidatePIN checks that a PIN consists of MinPINLength to MaxPINLength digits.
+func ValidatePIN(pin string) error {
+	if len(pin) < MinPINLength || len(pin) > MaxPINLength {
+		return fmt.Errorf("PIN must have %d to %d digits", MinPINLength, MaxPINLength)
+	}
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("PIN must have %d to %d digits", MinPINLength, MaxPINLength)
+		}
+	}
+	return nil
+}
+
+// HashPIN returns the stored form of a PIN: "pbkdf2-sha256$<iterations>$<salt>$<key>", with the random salt
+// and the derived key in hex.
+func HashPIN(pin string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate PIN salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, pin, salt, pinHashIterations, sha256.Size)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash PIN: %w", err)
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", pinHashIterations, hex.EncodeToString(salt), hex.EncodeToString(key)), nil
+}
+
+// VerifyPIN reports whether pin matches a hash made by HashPIN. A malformed hash matches no PIN.
+func VerifyPIN(hash, pin string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	key, err := pbkdf2.Key(sha256.New, pin, salt, iterations, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(key, want) == 1
+}
@@ -0,0 +1,39 @@
+// internal/domain/promo_credit.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PromoCreditStatus defines the lifecycle of a promotional credit grant.
+type PromoCreditStatus string
+
+const (
+	PromoCreditStatusActive  PromoCreditStatus = "ACTIVE"
+	PromoCreditStatusExpired PromoCreditStatus = "EXPIRED" // Past ExpiresAt; the remaining amount is forfeited
+)
+
+// PromoCredit is one grant of promotional credit to a wallet. Credits are spent before the wallet's real
+// balance, the grant expiring soonest first, and whatever remains at ExpiresAt is forfeited.
+type PromoCredit struct {
+	ID              int64             `db:"id" json:"-"`
+	PublicID        uuid.UUID         `db:"public_id" json:"id"`
+	WalletID        int64             `db:"wallet_id" json:"-"`
+	WalletPublicID  uuid.UUID         `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	Amount          decimal.Decimal   `db:"amount" json:"amount"`              // As granted
+	Remaining       decimal.Decimal   `db:"remaining" json:"remaining"`
+	AllowWithdrawal bool              `db:"allow_withdrawal" json:"allow_withdrawal"` // Transfers may always spend the credit
+	Reason          *string           `db:"reason" json:"reason"`
+	Status          PromoCreditStatus `db:"status" json:"status"`
+	ExpiresAt       time.Time         `db:"expires_at" json:"expires_at"`
+	CreatedAt       time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// Spendable reports whether the credit can still pay for something at t.
+func (c *PromoCredit) Spendable(t time.Time) bool {
+	return c.Status == PromoCreditStatusActive && c.Remaining.IsPositive() && t.Before(c.ExpiresAt)
+}
@@ -0,0 +1,82 @@
+// internal/domain/ramp.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// RampDirection tells which way a ramp converts.
+type RampDirection string
+
+const (
+	RampOn  RampDirection = "ON_RAMP"  // From a fiat wallet to a stablecoin wallet
+	RampOff RampDirection = "OFF_RAMP" // From a stablecoin wallet to a fiat wallet
+)
+
+// RampStatus is the state of a ramp order.
+type RampStatus string
+
+const (
+	RampQuoted   RampStatus = "QUOTED"   // Priced; it can be executed until the quote expires
+	RampExecuted RampStatus = "EXECUTED" // Converted; the transaction IDs are set
+)
+
+// RampOrder converts between a user's fiat wallet and their stablecoin wallet in two phases: it is quoted
+// with a transfer quote, which fixes the pricing, and then executed as the quoted transfer. The order links
+// the quote with the CONVERSION legs it was executed as, for audit.
+type RampOrder struct {
+	ID                  int64         `db:"id" json:"-"`
+	PublicID            uuid.UUID     `db:"public_id" json:"id"`
+	Direction           RampDirection `db:"direction" json:"direction"`
+	QuoteID             int64         `db:"quote_id" json:"-"`
+	Status              RampStatus    `db:"status" json:"status"`
+	DebitTransactionID  *uuid.UUID    `db:"debit_transaction_id" json:"debit_transaction_id"`   // The CONVERSION debit of the source wallet
+	CreditTransactionID *uuid.UUID    `db:"credit_transaction_id" json:"credit_transaction_id"` // The CONVERSION credit of the destination wallet
+	ExecutedAt          *time.Time    `db:"executed_at" json:"executed_at"`
+	CreatedAt           time.Time     `db:"created_at" json:"created_at"`
+
+	// The pricing, read-only and joined from the quote
+	QuotePublicID      uuid.UUID        `db:"quote_public_id" json:"quote_id"`
+	FromWalletID       int64            `db:"from_wallet_id" json:"-"`
+	ToWalletID         int64            `db:"to_wallet_id" json:"-"`
+	FromWalletPublicID uuid.UUID        `db:"from_wallet_public_id" json:"from_wallet_id"`
+	ToWalletPublicID   uuid.UUID        `db:"to_wallet_public_id" json:"to_wallet_id"`
+	Amount             decimal.Decimal  `db:"amount" json:"amount"`
+	Currency           string           `db:"currency" json:"currency"`
+	Fee                decimal.Decimal  `db:"fee" json:"fee"`
+	CreditAmount       decimal.Decimal  `db:"credit_amount" json:"credit_amount"`
+	CreditCurrency     string           `db:"credit_currency" json:"credit_currency"`
+	Rate               *decimal.Decimal `db:"rate" json:"rate"`
+	ExpiresAt          time.Time        `db:"expires_at" json:"expires_at"`
+}
+
+// NewRampOrder creates a quoted ramp order priced by quote.
+func NewRampOrder(direction RampDirection, quote *TransferQuote) *RampOrder {
+	return &RampOrder{
+		PublicID:           uuid.New(),
+		Direction:          direction,
+		QuoteID:            quote.ID,
+		Status:             RampQuoted,
+		CreatedAt:          quote.CreatedAt,
+		QuotePublicID:      quote.PublicID,
+		FromWalletID:       quote.FromWalletID,
+		ToWalletID:         quote.ToWalletID,
+		FromWalletPublicID: quote.FromWalletPublicID,
+		ToWalletPublicID:   quote.ToWalletPublicID,
+		Amount:             quote.Amount,
+		Currency:           quote.Currency,
+		Fee:                quote.Fee,
+		CreditAmount:       quote.CreditAmount,
+		CreditCurrency:     quote.CreditCurrency,
+		Rate:               quote.Rate,
+		ExpiresAt:          quote.ExpiresAt,
+	}
+}
+
+// DebitAmount returns what the source wallet pays: the amount plus the fee.
+func (o *RampOrder) DebitAmount() decimal.Decimal {
+	return o.Amount.Add(o.Fee)
+}
@@ -0,0 +1,50 @@
+// internal/domain/report.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CurrencyLiability is the total of customer balances held in one currency: what the operator owes its customers.
+type CurrencyLiability struct {
+	Currency     string          `db:"currency" json:"currency"`
+	TotalBalance decimal.Decimal `db:"total_balance" json:"total_balance"`
+	WalletCount  int64           `db:"wallet_count" json:"wallet_count"`
+}
+
+// FloatMovement is how much money entered and left customer balances in one currency over a period.
+// Inflows are deposits, voucher redemptions, incoming conversion legs and promotional credits spent into
+// another wallet; outflows are withdrawals and outgoing conversion legs, less any promotional credits spent.
+type FloatMovement struct {
+	Currency string          `db:"currency" json:"currency"`
+	Inflow   decimal.Decimal `db:"inflow" json:"inflow"`
+	Outflow  decimal.Decimal `db:"outflow" json:"outflow"`
+}
+
+// Net returns the change of customer balances over the period.
+func (m FloatMovement) Net() decimal.Decimal {
+	return m.Inflow.Sub(m.Outflow)
+}
+
+// RankedBalance is one of the largest wallet balances of a currency.
+type RankedBalance struct {
+	WalletID       int64           `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID       `db:"wallet_public_id" json:"wallet_id"`
+	UserID         int64           `db:"user_id" json:"user_id"`
+	Currency       string          `db:"currency" json:"currency"`
+	Balance        decimal.Decimal `db:"balance" json:"balance"`
+}
+
+// LiabilityReport is the treasury view of customer funds: current liabilities, float movement over the
+// period [From, To), and the largest balances per currency.
+type LiabilityReport struct {
+	From            time.Time
+	To              time.Time
+	Liabilities     []CurrencyLiability
+	Float           []FloatMovement
+	LargestBalances []RankedBalance
+	GeneratedAt     time.Time // Reports are cached, so they may be up to the cache TTL old
+}
@@ -0,0 +1,27 @@
+// internal/domain/residency.go
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CurrencyRestriction lists the countries whose residents may neither open nor fund wallets in a currency.
+// Wallets opened before a restriction can still be spent from.
+type CurrencyRestriction struct {
+	Currency  string    `json:"currency"`
+	Countries []string  `json:"countries"` // ISO 3166-1 alpha-2 codes, sorted
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NormalizeCountryCode upper-cases a country code and checks that it has the form of an ISO 3166-1 alpha-2
+// code, e.g. "DE". Whether the code is assigned is not checked.
+func NormalizeCountryCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return "", fmt.Errorf("country code %q is not two letters", code)
+	}
+	return code, nil
+}
@@ -0,0 +1,41 @@
+// internal/domain/retention.go
+package domain
+
+import "time"
+
+// RetentionRecordType is a kind of record purged once older than its retention period.
+type RetentionRecordType string
+
+const (
+	RetentionAuthEvents             RetentionRecordType = "auth_events"             // Login audit
+	RetentionImpersonationAudit     RetentionRecordType = "impersonation_audit"     // Requests made by support impersonating users
+	RetentionNotificationDeliveries RetentionRecordType = "notification_deliveries" // Push deliveries no longer pending
+	RetentionNotifications          RetentionRecordType = "notifications"           // In-app inbox
+)
+
+// RetentionRecordTypes lists every record type with a retention policy, in purge order.
+var RetentionRecordTypes = []RetentionRecordType{
+	RetentionAuthEvents,
+	RetentionImpersonationAudit,
+	RetentionNotificationDeliveries,
+	RetentionNotifications,
+}
+
+// PurgeResult is the outcome of purging one record type.
+type PurgeResult struct {
+	Type   RetentionRecordType `json:"type"`
+	Cutoff time.Time           `json:"cutoff"` // Records created before it were purged
+	Rows   int64               `json:"rows"`   // Purged, or that would have been on a dry run
+	DryRun bool                `json:"dry_run"`
+}
+
+// RetentionStats are the purge metrics of a record type since the process started.
+type RetentionStats struct {
+	Type        RetentionRecordType `json:"type"`
+	Retention   time.Duration       `json:"-"` // 0 keeps the records forever
+	Purged      int64               `json:"purged"`
+	Runs        int64               `json:"runs"`
+	LastRun     *PurgeResult        `json:"last_run"`
+	LastRunAt   *time.Time          `json:"last_run_at"`
+	LastFailure *string             `json:"last_failure,omitempty"`
+}
@@ -0,0 +1,60 @@
+// internal/domain/scim.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SCIMUser is the SCIM 2.0 User resource through which an enterprise tenant's identity system manages a user
+// it provisioned. A user belongs to at most one tenant.
+type SCIMUser struct {
+	ID         int64     `db:"id" json:"-"`
+	PublicID   uuid.UUID `db:"public_id" json:"id"` // The resource's SCIM id
+	Tenant     string    `db:"tenant" json:"tenant"`
+	ExternalID *string   `db:"external_id" json:"external_id"` // The identity system's own ID, unique per tenant
+	UserID     int64     `db:"user_id" json:"user_id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// NewSCIMUser creates a new SCIMUser instance for a user not created yet.
+func NewSCIMUser(tenant string, externalID *string) *SCIMUser {
+	now := time.Now().UTC()
+	return &SCIMUser{
+		PublicID:   uuid.New(),
+		Tenant:     tenant,
+		ExternalID: externalID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// SCIMAction names the provisioning change a SCIMProvisioningEvent records.
+type SCIMAction string
+
+const (
+	SCIMActionCreate     SCIMAction = "CREATE"
+	SCIMActionUpdate     SCIMAction = "UPDATE"     // Attributes changed; the user stayed active or inactive
+	SCIMActionDeactivate SCIMAction = "DEACTIVATE" // The user became inactive, possibly with other changes
+	SCIMActionReactivate SCIMAction = "REACTIVATE" // The user became active again, possibly with other changes
+)
+
+// SCIMChange is the change of one attribute. Contact details are personal data kept encrypted, so their
+// changes name the attribute without its values.
+type SCIMChange struct {
+	From any `json:"from,omitempty"`
+	To   any `json:"to,omitempty"`
+}
+
+// SCIMProvisioningEvent is the audit record of a provisioning change a tenant made to a user.
+type SCIMProvisioningEvent struct {
+	ID        int64                 `db:"id" json:"-"`
+	Tenant    string                `db:"tenant" json:"tenant"`
+	UserID    int64                 `db:"user_id" json:"user_id"`
+	Action    SCIMAction            `db:"action" json:"action"`
+	Changes   map[string]SCIMChange `db:"-" json:"changes"`
+	RequestID *string               `db:"request_id" json:"request_id"`
+	CreatedAt time.Time             `db:"created_at" json:"created_at"`
+}
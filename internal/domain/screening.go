@@ -0,0 +1,70 @@
+// internal/domain/screening.go
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// ScreeningEvent names the moment a party is screened against the denylist.
+type ScreeningEvent string
+
+const (
+	ScreeningEventUserCreation    ScreeningEvent = "USER_CREATION"    // A user is about to be created
+	ScreeningEventNewCounterparty ScreeningEvent = "NEW_COUNTERPARTY" // A wallet is about to pay another wallet for the first time
+)
+
+// ScreeningCaseStatus defines the lifecycle of a screening review case.
+type ScreeningCaseStatus string
+
+const (
+	ScreeningCaseOpen      ScreeningCaseStatus = "OPEN"      // Waiting for a compliance review; the party stays blocked
+	ScreeningCaseCleared   ScreeningCaseStatus = "CLEARED"   // A false positive; the party is no longer blocked by the entry
+	ScreeningCaseConfirmed ScreeningCaseStatus = "CONFIRMED" // A true match; the party stays blocked
+)
+
+// DenylistEntry is a name that parties are screened against, e.g. from a sanctions list.
+type DenylistEntry struct {
+	ID             int64     `db:"id" json:"id"`
+	Name           string    `db:"name" json:"name"`
+	NormalizedName string    `db:"normalized_name" json:"-"` // See NormalizeScreeningName
+	Reason         *string   `db:"reason" json:"reason"`     // E.g. the list and listing the entry comes from
+	CreatedBy      string    `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// ScreeningCase records a screening hit for compliance review. Repeated hits of the same party on the same
+// entry share one case.
+type ScreeningCase struct {
+	ID                 int64               `db:"id" json:"-"`
+	PublicID           uuid.UUID           `db:"public_id" json:"id"`
+	EntryID            int64               `db:"entry_id" json:"entry_id"`
+	Subject            string              `db:"subject" json:"subject"` // The screened name
+	NormalizedName     string              `db:"normalized_name" json:"-"`
+	Event              ScreeningEvent      `db:"event" json:"event"`     // What the first hit blocked
+	UserID             *int64              `db:"user_id" json:"user_id"` // The screened user, if it exists
+	FromWalletID       *int64              `db:"from_wallet_id" json:"-"`
+	ToWalletID         *int64              `db:"to_wallet_id" json:"-"`
+	FromWalletPublicID *uuid.UUID          `db:"from_wallet_public_id" json:"from_wallet_id"` // The paying wallet of a blocked transfer; read-only, joined from wallets
+	ToWalletPublicID   *uuid.UUID          `db:"to_wallet_public_id" json:"to_wallet_id"`
+	Status             ScreeningCaseStatus `db:"status" json:"status"`
+	ResolvedBy         *string             `db:"resolved_by" json:"resolved_by"`
+	ResolutionNote     *string             `db:"resolution_note" json:"resolution_note"`
+	CreatedAt          time.Time           `db:"created_at" json:"created_at"`
+	ResolvedAt         *time.Time          `db:"resolved_at" json:"resolved_at"`
+}
+
+// NormalizeScreeningName reduces a name to its lower-case letters and digits, so that "John O'Neil",
+// "john.oneil" and "JOHN_ONEIL" all match the same denylist entry.
+func NormalizeScreeningName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,55 @@
+// internal/domain/security.go
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// AuthEventType classifies an authentication event of a user.
+type AuthEventType string
+
+const (
+	AuthEventLogin       AuthEventType = "LOGIN"
+	AuthEventLoginFailed AuthEventType = "LOGIN_FAILED"
+	AuthEventLogout      AuthEventType = "LOGOUT"
+)
+
+// Valid reports whether t is a known event type.
+func (t AuthEventType) Valid() bool {
+	switch t {
+	case AuthEventLogin, AuthEventLoginFailed, AuthEventLogout:
+		return true
+	}
+	return false
+}
+
+// AuthEvent records an authentication event of a user, as reported by the service that authenticates them.
+type AuthEvent struct {
+	ID         int64         `db:"id" json:"id"`
+	UserID     int64         `db:"user_id" json:"user_id"`
+	Type       AuthEventType `db:"type" json:"type"`
+	IPAddress  *string       `db:"ip_address" json:"ip_address"`
+	UserAgent  *string       `db:"user_agent" json:"user_agent"`
+	GeoHint    *string       `db:"geo_hint" json:"geo_hint"`     // Coarse location, e.g. "Berlin, DE"
+	DeviceHash *string       `db:"device_hash" json:"-"`         // See DeviceFingerprint
+	NewDevice  bool          `db:"new_device" json:"new_device"` // A login from a device the user had not logged in from before
+	CreatedAt  time.Time     `db:"created_at" json:"created_at"`
+}
+
+// DeviceFingerprint identifies the device of an authentication event: the hex SHA-256 of the device ID
+// reported by the client, or of the user agent without one. It is empty when neither is known.
+func DeviceFingerprint(deviceID, userAgent string) string {
+	var source string
+	switch {
+	case deviceID != "":
+		source = "device:" + deviceID
+	case userAgent != "":
+		source = "user-agent:" + userAgent
+	default:
+		return ""
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
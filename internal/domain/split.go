@@ -0,0 +1,101 @@
+// internal/domain/split.go
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxSplitLegs caps the number of destinations of a split payment.
+const MaxSplitLegs = 20
+
+var hundred = decimal.NewFromInt(100)
+
+// SplitLeg is one destination of a split payment: either a fixed Amount or a percentage Share of the total.
+type SplitLeg struct {
+	ToWalletID int64
+	Amount     decimal.Decimal
+	Share      decimal.Decimal // Percentage, e.g. 12.5
+}
+
+// Split describes a payment from one wallet to several. Either every leg has a fixed amount, in which case
+// Total is optional and must match their sum, or every leg has a share, the shares sum to 100 and Total is required.
+type Split struct {
+	Currency string
+	Total    decimal.Decimal
+	Legs     []SplitLeg
+}
+
+// Resolve validates the split and returns the total and the amount of each leg, in leg order.
+// Amounts must be expressible in the currency's minor unit. Share-based amounts are rounded down to it,
+// and the units left over are handed out one at a time to the legs in order, so the legs always add up to Total.
+func (s Split) Resolve() (decimal.Decimal, []decimal.Decimal, error) {
+	if len(s.Legs) < 2 || len(s.Legs) > MaxSplitLegs {
+		return decimal.Zero, nil, fmt.Errorf("a split needs between 2 and %d destinations", MaxSplitLegs)
+	}
+	places := CurrencyPrecision(s.Currency)
+	if !fitsPrecision(s.Total, places) {
+		return decimal.Zero, nil, errors.New("amount has more decimals than the currency allows")
+	}
+
+	byShare := s.Legs[0].Share.IsPositive()
+	amounts := make([]decimal.Decimal, len(s.Legs))
+	sum := decimal.Zero
+	for i, leg := range s.Legs {
+		if byShare != leg.Share.IsPositive() || leg.Share.IsNegative() || leg.Amount.IsNegative() {
+			return decimal.Zero, nil, errors.New("every destination needs either an amount or a share, not both kinds")
+		}
+		if byShare {
+			if !leg.Amount.IsZero() {
+				return decimal.Zero, nil, errors.New("every destination needs either an amount or a share, not both kinds")
+			}
+			sum = sum.Add(leg.Share)
+			continue
+		}
+		if !leg.Amount.IsPositive() {
+			return decimal.Zero, nil, errors.New("every destination amount must be positive")
+		}
+		if !fitsPrecision(leg.Amount, places) {
+			return decimal.Zero, nil, errors.New("amount has more decimals than the currency allows")
+		}
+		amounts[i] = leg.Amount
+		sum = sum.Add(leg.Amount)
+	}
+
+	if !byShare {
+		if !s.Total.IsZero() && !s.Total.Equal(sum) {
+			return decimal.Zero, nil, errors.New("destination amounts do not add up to the total")
+		}
+		return sum, amounts, nil
+	}
+
+	if !sum.Equal(hundred) {
+		return decimal.Zero, nil, errors.New("shares must add up to 100")
+	}
+	if !s.Total.IsPositive() {
+		return decimal.Zero, nil, errors.New("a split by share needs a positive total amount")
+	}
+	allocated := decimal.Zero
+	for i, leg := range s.Legs {
+		amounts[i] = s.Total.Mul(leg.Share).Div(hundred).RoundFloor(places)
+		allocated = allocated.Add(amounts[i])
+	}
+	unit := decimal.New(1, -places)
+	for i := 0; allocated.LessThan(s.Total); i = (i + 1) % len(amounts) {
+		amounts[i] = amounts[i].Add(unit)
+		allocated = allocated.Add(unit)
+	}
+	for _, amount := range amounts {
+		if !amount.IsPositive() {
+			return decimal.Zero, nil, errors.New("a share is too small to receive any amount")
+		}
+	}
+	return s.Total, amounts, nil
+}
+
+// fitsPrecision reports whether amount has no more than places decimals.
+func fitsPrecision(amount decimal.Decimal, places int32) bool {
+	return amount.Equal(amount.Truncate(places))
+}
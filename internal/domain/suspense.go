@@ -0,0 +1,44 @@
+// internal/domain/suspense.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PlatformUsername is the user that owns the platform's system wallets, such as the suspense wallets.
+const PlatformUsername = "finflow-platform"
+
+// InboundFundsStatus defines the lifecycle of funds received from a payment provider.
+type InboundFundsStatus string
+
+const (
+	InboundFundsStatusCredited   InboundFundsStatus = "CREDITED"   // Matched to a wallet and credited to it
+	InboundFundsStatusSuspended  InboundFundsStatus = "SUSPENDED"  // Unmatched; held in the currency's suspense wallet
+	InboundFundsStatusReassigned InboundFundsStatus = "REASSIGNED" // Moved out of suspense to a wallet by an operator
+)
+
+// InboundFunds records money a payment provider reported as received, e.g. a bank transfer, and where it
+// was credited. A provider's reference is recorded once, so a redelivered webhook credits nothing twice.
+type InboundFunds struct {
+	ID                      int64              `db:"id" json:"-"`
+	PublicID                uuid.UUID          `db:"public_id" json:"id"`
+	Provider                string             `db:"provider" json:"provider"`                   // The partner ID of the reporting provider
+	Reference               string             `db:"reference" json:"reference"`                 // The provider's own ID of the payment
+	AccountReference        *string            `db:"account_reference" json:"account_reference"` // What the payer quoted to identify the wallet
+	Amount                  decimal.Decimal    `db:"amount" json:"amount"`
+	Currency                string             `db:"currency" json:"currency"`
+	Status                  InboundFundsStatus `db:"status" json:"status"`
+	WalletID                int64              `db:"wallet_id" json:"-"`                                  // The wallet credited on receipt
+	WalletPublicID          uuid.UUID          `db:"wallet_public_id" json:"wallet_id"`                   // Read-only, joined from wallets
+	TransactionID           uuid.UUID          `db:"transaction_id" json:"transaction_id"`                // The DEPOSIT that credited the funds
+	AssignedWalletID        *int64             `db:"assigned_wallet_id" json:"-"`                         // The wallet suspended funds were reassigned to
+	AssignedWalletPublicID  *uuid.UUID         `db:"assigned_wallet_public_id" json:"assigned_wallet_id"` // Read-only, joined from wallets
+	AdjustmentTransactionID *uuid.UUID         `db:"adjustment_transaction_id" json:"adjustment_transaction_id"`
+	ResolvedBy              *string            `db:"resolved_by" json:"resolved_by"` // The operator who reassigned the funds
+	ResolutionReason        *string            `db:"resolution_reason" json:"resolution_reason"`
+	ResolvedAt              *time.Time         `db:"resolved_at" json:"resolved_at"`
+	CreatedAt               time.Time          `db:"created_at" json:"created_at"`
+}
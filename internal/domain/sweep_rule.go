@@ -0,0 +1,59 @@
+// internal/domain/sweep_rule.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// SweepRuleKind defines how a sweep rule balances two of a user's wallets.
+type SweepRuleKind string
+
+const (
+	// SweepRuleKindSweep moves everything above the threshold from the source wallet to the target wallet.
+	SweepRuleKindSweep SweepRuleKind = "SWEEP"
+	// SweepRuleKindTopUp refills the target wallet up to the threshold from the source wallet.
+	SweepRuleKindTopUp SweepRuleKind = "TOP_UP"
+)
+
+// SweepRule is a standing instruction to move money between two wallets of the same user.
+type SweepRule struct {
+	ID                   int64           `db:"id" json:"id"`
+	UserID               int64           `db:"user_id" json:"user_id"`
+	Kind                 SweepRuleKind   `db:"kind" json:"kind"`
+	SourceWalletID       int64           `db:"source_wallet_id" json:"-"`
+	TargetWalletID       int64           `db:"target_wallet_id" json:"-"`
+	SourceWalletPublicID uuid.UUID       `db:"source_wallet_public_id" json:"source_wallet_id"` // Read-only, joined from wallets
+	TargetWalletPublicID uuid.UUID       `db:"target_wallet_public_id" json:"target_wallet_id"` // Read-only, joined from wallets
+	Currency             string          `db:"currency" json:"currency"`                        // Read-only, joined from the source wallet
+	Threshold            decimal.Decimal `db:"threshold" json:"threshold"`
+	Enabled              bool            `db:"enabled" json:"enabled"`
+	CreatedAt            time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// WatchedWalletID returns the wallet whose balance triggers the rule.
+func (r *SweepRule) WatchedWalletID() int64 {
+	if r.Kind == SweepRuleKindTopUp {
+		return r.TargetWalletID
+	}
+	return r.SourceWalletID
+}
+
+// Amount returns how much the rule moves given the current balances, or zero if it does not fire.
+// A top-up never takes the source wallet below zero.
+func (r *SweepRule) Amount(source, target *Wallet) decimal.Decimal {
+	var amount decimal.Decimal
+	switch r.Kind {
+	case SweepRuleKindSweep:
+		amount = source.Balance.Sub(r.Threshold)
+	case SweepRuleKindTopUp:
+		amount = decimal.Min(r.Threshold.Sub(target.Balance), source.Balance)
+	}
+	if !amount.IsPositive() {
+		return decimal.Zero
+	}
+	return amount
+}
@@ -4,6 +4,7 @@ package domain
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal" // For precise monetary calculations
 )
 
@@ -14,6 +15,14 @@ const (
 	TransactionTypeDeposit    TransactionType = "DEPOSIT"
 	TransactionTypeWithdrawal TransactionType = "WITHDRAWAL"
 	TransactionTypeTransfer   TransactionType = "TRANSFER"
+	TransactionTypeAutoSweep  TransactionType = "AUTO_SWEEP" // Internal transfer executed by a sweep rule
+	TransactionTypePayment    TransactionType = "PAYMENT"    // Customer paying a merchant charge
+	TransactionTypeSettlement TransactionType = "SETTLEMENT" // Daily payout of a merchant's receipts
+	TransactionTypeSplit      TransactionType = "SPLIT"      // Parent of the TRANSFER legs of a split payment; moves no money itself
+	TransactionTypeVoucher    TransactionType = "VOUCHER"    // Redemption of a voucher into a wallet
+	TransactionTypeConversion TransactionType = "CONVERSION" // One leg of a cross-currency transfer: the debit, or the credit pointing at it
+	TransactionTypeNetting    TransactionType = "NETTING"    // Net settlement of the netting instructions between two wallets
+	TransactionTypeAdjustment TransactionType = "ADJUSTMENT" // Operator correction, e.g. reassigning funds out of a suspense wallet
 )
 
 // TransactionStatus defines the status of a financial transaction.
@@ -27,16 +36,24 @@ const (
 
 // Transaction represents a financial transaction record.
 type Transaction struct {
-	ID              int64             `db:"id" json:"id"`                             // Primary key, BIGSERIAL in DB
-	FromWalletID    *int64            `db:"from_wallet_id" json:"from_wallet_id"`     // Source wallet ID (nullable for deposits)
-	ToWalletID      *int64            `db:"to_wallet_id" json:"to_wallet_id"`         // Destination wallet ID (nullable for withdrawals)
-	Amount          decimal.Decimal   `db:"amount" json:"amount"`                     // Transaction amount, NUMERIC(20, 4) in DB
-	Currency        string            `db:"currency" json:"currency"`                 // Currency of the transaction
-	Type            TransactionType   `db:"type" json:"type"`                         // Type of transaction (DEPOSIT, WITHDRAWAL, TRANSFER)
-	Status          TransactionStatus `db:"status" json:"status"`                     // Status of the transaction (COMPLETED, PENDING, FAILED)
-	TransactionTime time.Time         `db:"transaction_time" json:"transaction_time"` // Actual time of the transaction
-	Description     *string           `db:"description" json:"description"`           // Optional description
-	CreatedAt       time.Time         `db:"created_at" json:"created_at"`             // Timestamp of record creation
+	ID                 int64             `db:"id" json:"-"`                                        // Primary key, BIGSERIAL in DB; internal only
+	PublicID           uuid.UUID         `db:"public_id" json:"id"`                                // Identifier exposed by the API
+	FromWalletID       *int64            `db:"from_wallet_id" json:"-"`                            // Source wallet ID (nullable for deposits)
+	ToWalletID         *int64            `db:"to_wallet_id" json:"-"`                              // Destination wallet ID (nullable for withdrawals)
+	FromWalletPublicID *uuid.UUID        `db:"from_wallet_public_id" json:"from_wallet_id"`        // Read-only, joined from wallets
+	ToWalletPublicID   *uuid.UUID        `db:"to_wallet_public_id" json:"to_wallet_id"`            // Read-only, joined from wallets
+	FromUsername       *string           `db:"from_username" json:"-"`                             // Read-only, the source wallet's owner; for descriptions
+	ToUsername         *string           `db:"to_username" json:"-"`                               // Read-only, the destination wallet's owner; for descriptions
+	Amount             decimal.Decimal   `db:"amount" json:"amount"`                               // Transaction amount, NUMERIC(24, 8) in DB
+	Currency           string            `db:"currency" json:"currency"`                           // Currency of the transaction
+	Type               TransactionType   `db:"type" json:"type"`                                   // Type of transaction (DEPOSIT, WITHDRAWAL, TRANSFER)
+	Status             TransactionStatus `db:"status" json:"status"`                               // Status of the transaction (COMPLETED, PENDING, FAILED)
+	TransactionTime    time.Time         `db:"transaction_time" json:"transaction_time"`           // Actual time of the transaction
+	Description        *string           `db:"description" json:"description"`                     // Optional description
+	Category           *string           `db:"category" json:"category"`                           // Optional spending category, e.g. "groceries"
+	ParentID           *uuid.UUID        `db:"parent_transaction_id" json:"parent_transaction_id"` // The SPLIT parent of a split payment leg, or the withdrawal a payout refund reverses
+	PromoAmount        decimal.Decimal   `db:"promo_amount" json:"promo_amount"`                   // Part of Amount paid from promotional credits
+	CreatedAt          time.Time         `db:"created_at" json:"created_at"`                       // Timestamp of record creation
 }
 
 // NewTransaction creates a new Transaction instance.
@@ -50,6 +67,7 @@ func NewTransaction(
 ) *Transaction {
 	now := time.Now().UTC()
 	return &Transaction{
+		PublicID:        uuid.New(),
 		FromWalletID:    fromWalletID,
 		ToWalletID:      toWalletID,
 		Amount:          amount,
@@ -61,3 +79,17 @@ func NewTransaction(
 		CreatedAt:       now,
 	}
 }
+
+// TransactionPartition describes one monthly partition of the transactions table.
+type TransactionPartition struct {
+	Name       string    // Partition table name, e.g. transactions_p202508
+	RangeStart time.Time // Inclusive lower bound on created_at
+	RangeEnd   time.Time // Exclusive upper bound on created_at
+}
+
+// ArchiveCutoff returns the start of the oldest month still kept in the hot transactions table.
+// Partitions ending on or before the cutoff are eligible for archival.
+func ArchiveCutoff(now time.Time, horizonMonths int) time.Time {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return monthStart.AddDate(0, -horizonMonths, 0)
+}
@@ -0,0 +1,59 @@
+// internal/domain/transaction_verification.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Sides of a transaction.
+const (
+	SideFrom = "from"
+	SideTo   = "to"
+)
+
+// WalletVerification is the check of one wallet of a verified transaction.
+type WalletVerification struct {
+	Side          string          `json:"side"` // SideFrom or SideTo
+	WalletID      uuid.UUID       `json:"wallet_id"`
+	Currency      string          `json:"currency"`
+	Effect        decimal.Decimal `json:"effect"`         // What the transaction adds to the balance, negative for a debit
+	Balance       decimal.Decimal `json:"balance"`        // Own balance plus the balance shards
+	LedgerBalance decimal.Decimal `json:"ledger_balance"` // Sum of the effects of the wallet's completed transactions
+	Consistent    bool            `json:"consistent"`
+}
+
+// TransactionVerification is the outcome of checking a transaction against the ledger.
+type TransactionVerification struct {
+	TransactionID uuid.UUID            `json:"transaction_id"`
+	Status        TransactionStatus    `json:"status"`
+	Consistent    bool                 `json:"consistent"`
+	Wallets       []WalletVerification `json:"wallets"`
+	Mismatches    []string             `json:"mismatches"` // Why the transaction is not consistent
+	VerifiedAt    time.Time            `json:"verified_at"`
+}
+
+// LedgerEffect returns what the transaction adds to the balance of the wallet on the given side: the amount
+// for the destination and its negation for the source, less the promotional part the source did not pay.
+// A SPLIT parent only accounts the promotional part, its legs move the money; a transaction that is not
+// COMPLETED has no effect.
+func (t *Transaction) LedgerEffect(side string) decimal.Decimal {
+	if t.Status != TransactionStatusCompleted {
+		return decimal.Zero
+	}
+	effect := decimal.Zero
+	switch side {
+	case SideTo:
+		if t.Type != TransactionTypeSplit {
+			effect = t.Amount
+		}
+	case SideFrom:
+		if t.Type != TransactionTypeSplit {
+			effect = t.Amount.Neg()
+		}
+		effect = effect.Add(t.PromoAmount)
+	}
+	return effect
+}
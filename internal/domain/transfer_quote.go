@@ -0,0 +1,40 @@
+// internal/domain/transfer_quote.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TransferQuote prices a transfer before it is made. A transfer that carries the quote's ID is executed
+// at the quoted pricing, as long as it matches the quoted wallets and amount and the quote has neither
+// expired nor been used.
+type TransferQuote struct {
+	ID                 int64            `db:"id" json:"-"`
+	PublicID           uuid.UUID        `db:"public_id" json:"id"`
+	FromWalletID       int64            `db:"from_wallet_id" json:"-"`
+	ToWalletID         int64            `db:"to_wallet_id" json:"-"`
+	FromWalletPublicID uuid.UUID        `db:"from_wallet_public_id" json:"from_wallet_id"` // Read-only, joined from wallets
+	ToWalletPublicID   uuid.UUID        `db:"to_wallet_public_id" json:"to_wallet_id"`     // Read-only, joined from wallets
+	Amount             decimal.Decimal  `db:"amount" json:"amount"`                        // As requested, in Currency
+	Currency           string           `db:"currency" json:"currency"`                    // The source wallet's currency
+	Fee                decimal.Decimal  `db:"fee" json:"fee"`                              // In Currency, debited on top of Amount
+	CreditAmount       decimal.Decimal  `db:"credit_amount" json:"credit_amount"`          // What the destination receives, in CreditCurrency
+	CreditCurrency     string           `db:"credit_currency" json:"credit_currency"`      // The destination wallet's currency
+	Rate               *decimal.Decimal `db:"rate" json:"rate"`                            // Currency to CreditCurrency; nil for a same-currency transfer
+	ExpiresAt          time.Time        `db:"expires_at" json:"expires_at"`
+	UsedAt             *time.Time       `db:"used_at" json:"used_at"` // Set by the transfer that executed the quote
+	CreatedAt          time.Time        `db:"created_at" json:"created_at"`
+}
+
+// DebitAmount returns what the source wallet pays: the amount plus the fee.
+func (q *TransferQuote) DebitAmount() decimal.Decimal {
+	return q.Amount.Add(q.Fee)
+}
+
+// CrossCurrency reports whether the quoted transfer converts between currencies.
+func (q *TransferQuote) CrossCurrency() bool {
+	return q.Currency != q.CreditCurrency
+}
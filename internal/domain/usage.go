@@ -0,0 +1,30 @@
+// internal/domain/usage.go
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PartnerQuota is the monthly allowance of a partner, i.e. an integrator signing its requests with a partner
+// key. Zero or missing limits are unlimited.
+type PartnerQuota struct {
+	Requests int64                      // Requests per month
+	Volume   map[string]decimal.Decimal // Currency -> money moved per month
+}
+
+// PartnerUsage is what a partner used in a calendar month.
+type PartnerUsage struct {
+	PartnerID string
+	Month     time.Time                  // First day of the month, UTC
+	Requests  int64                      // Every request counted, including those refused for the quota
+	Volume    map[string]decimal.Decimal // Currency -> money moved
+}
+
+// UsageMonth returns the first instant of the calendar month (UTC) that t falls in; usage is counted per
+// such month.
+func UsageMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -1,14 +1,31 @@
 // internal/domain/user.go
 package domain
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTimezone is the time zone of users who have not chosen one.
+const DefaultTimezone = "UTC"
 
 // User represents a user in the wallet system.
 type User struct {
-	ID        int64     `db:"id" json:"id"`                 // Primary key, BIGSERIAL in DB
-	Username  string    `db:"username" json:"username"`     // Unique username
-	CreatedAt time.Time `db:"created_at" json:"created_at"` // Timestamp of creation
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"` // Timestamp of last update
+	ID              int64      `db:"id" json:"id"`                                   // Primary key, BIGSERIAL in DB
+	Username        string     `db:"username" json:"username"`                       // Unique username
+	Timezone        string     `db:"timezone" json:"timezone"`                       // IANA name; sets the user's day boundaries
+	Residency       *string    `db:"residency" json:"residency"`                     // ISO 3166-1 alpha-2 country of residence, if known
+	Email           *string    `db:"-" json:"email"`                                 // Stored encrypted
+	Phone           *string    `db:"-" json:"phone"`                                 // E.164, stored encrypted
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at"`     // Cleared when the email changes
+	PhoneVerifiedAt *time.Time `db:"phone_verified_at" json:"phone_verified_at"`     // Cleared when the phone changes
+	EmailHash       *string    `db:"email_hash" json:"-"`                            // Blind index of Email, see keyring.Keyring
+	PhoneHash       *string    `db:"phone_hash" json:"-"`                            // Blind index of Phone
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`                   // Timestamp of creation
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`                   // Timestamp of last update
+	DeletedAt       *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`         // Set while soft-deleted
+	DeactivatedAt   *time.Time `db:"deactivated_at" json:"deactivated_at,omitempty"` // Set while deactivated by the tenant that provisioned the user
 }
 
 // NewUser creates a new User instance.
@@ -16,7 +33,67 @@ func NewUser(username string) *User {
 	now := time.Now().UTC()
 	return &User{
 		Username:  username,
+		Timezone:  DefaultTimezone,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 }
+
+// LoadTimezone resolves an IANA time zone name such as "Europe/Berlin". The process-dependent "Local"
+// and the empty name are rejected.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// TimezoneLocation resolves a stored time zone name, falling back to UTC for an empty or unknown one.
+func TimezoneLocation(name string) *time.Location {
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// HasVerifiedContact reports whether the user has verified their email address or phone number.
+func (u *User) HasVerifiedContact() bool {
+	return u.EmailVerifiedAt != nil || u.PhoneVerifiedAt != nil
+}
+
+// maxEmailLength is the longest email address accepted.
+const maxEmailLength = 254
+
+// NormalizeEmail trims an email address and lower-cases its domain. Only its rough shape is checked, one "@"
+// between a local part and a domain containing a dot; whether it exists is not.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" || !strings.Contains(domain, ".") || strings.ContainsAny(domain, "@ ") || len(email) > maxEmailLength {
+		return "", fmt.Errorf("invalid email address %q", email)
+	}
+	return local + "@" + strings.ToLower(domain), nil
+}
+
+// NormalizePhone removes spaces, dashes, dots and parentheses from a phone number and checks that the rest
+// is in E.164 form: "+" and 8 to 15 digits, e.g. "+4915112345678".
+func NormalizePhone(phone string) (string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+	digits := strings.TrimPrefix(normalized, "+")
+	if len(digits) == len(normalized) || len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", fmt.Errorf("phone number %q is not in E.164 form", phone)
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("phone number %q is not in E.164 form", phone)
+		}
+	}
+	return normalized, nil
+}
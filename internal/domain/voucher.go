@@ -0,0 +1,105 @@
+// internal/domain/voucher.go
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// MaxVouchersPerIssue caps the number of codes issued by one request.
+const MaxVouchersPerIssue = 100
+
+// voucherAlphabet leaves out characters that are easily confused (0/O, 1/I). Its 32 characters divide 256,
+// so every character is equally likely.
+const voucherAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// voucherCodeLength is the number of random characters in a code, printed in groups of four.
+const voucherCodeLength = 16
+
+// VoucherStatus defines the lifecycle of a voucher. An ACTIVE voucher past ExpiresAt can no longer be redeemed.
+type VoucherStatus string
+
+const (
+	VoucherStatusActive   VoucherStatus = "ACTIVE"
+	VoucherStatusRedeemed VoucherStatus = "REDEEMED"
+)
+
+// Voucher is a prepaid code worth a fixed amount, redeemable once into a wallet of the same currency.
+type Voucher struct {
+	ID                     int64           `db:"id" json:"-"`
+	PublicID               uuid.UUID       `db:"public_id" json:"id"`
+	Code                   string          `db:"-" json:"code,omitempty"` // Only known when issued; stored as CodeHash
+	CodeHash               string          `db:"code_hash" json:"-"`
+	CodeHint               string          `db:"code_hint" json:"code_hint"` // Last characters of the code
+	Amount                 decimal.Decimal `db:"amount" json:"amount"`
+	Currency               string          `db:"currency" json:"currency"`
+	Status                 VoucherStatus   `db:"status" json:"status"`
+	ExpiresAt              time.Time       `db:"expires_at" json:"expires_at"`
+	RedeemedWalletID       *int64          `db:"redeemed_wallet_id" json:"-"`
+	RedeemedWalletPublicID *uuid.UUID      `db:"redeemed_wallet_public_id" json:"redeemed_wallet_id"` // Read-only, joined from wallets
+	TransactionID          *uuid.UUID      `db:"transaction_id" json:"transaction_id"`                // Set once redeemed
+	RedeemedAt             *time.Time      `db:"redeemed_at" json:"redeemed_at"`
+	CreatedAt              time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt              time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// NewVoucherCode generates a random code such as "7KQM-X2ZD-9HTR-4WPA".
+func NewVoucherCode() (string, error) {
+	random := make([]byte, voucherCodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate voucher code: %w", err)
+	}
+	var code strings.Builder
+	for i, b := range random {
+		if i > 0 && i%4 == 0 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(voucherAlphabet[int(b)%len(voucherAlphabet)])
+	}
+	return code.String(), nil
+}
+
+// NormalizeVoucherCode strips separators and whitespace and upper-cases the code, so it matches however it was typed.
+func NormalizeVoucherCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ', '\t':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// HashVoucherCode returns the stored form of a code: the hex SHA-256 of its normalized form.
+func HashVoucherCode(code string) string {
+	sum := sha256.Sum256([]byte(NormalizeVoucherCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// VoucherCodeHint returns the last four characters of the normalized code.
+func VoucherCodeHint(code string) string {
+	normalized := NormalizeVoucherCode(code)
+	if len(normalized) <= 4 {
+		return normalized
+	}
+	return normalized[len(normalized)-4:]
+}
+
+// VoucherLiability sums up, for one currency, the value of vouchers that can still be redeemed,
+// the value of those that expired unredeemed, and the value already redeemed.
+type VoucherLiability struct {
+	Currency          string          `db:"currency" json:"currency"`
+	OutstandingCount  int             `db:"outstanding_count" json:"outstanding_count"`
+	OutstandingAmount decimal.Decimal `db:"outstanding_amount" json:"outstanding_amount"`
+	ExpiredCount      int             `db:"expired_count" json:"expired_count"`
+	ExpiredAmount     decimal.Decimal `db:"expired_amount" json:"expired_amount"`
+	RedeemedCount     int             `db:"redeemed_count" json:"redeemed_count"`
+	RedeemedAmount    decimal.Decimal `db:"redeemed_amount" json:"redeemed_amount"`
+}
@@ -2,29 +2,84 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal" // For precise monetary calculations
 )
 
+// WalletKind distinguishes merchant wallets, which accept charge payments, crypto wallets, which hold a
+// crypto asset, and the platform's suspense wallets from personal wallets.
+type WalletKind string
+
+const (
+	WalletKindPersonal WalletKind = "PERSONAL"
+	WalletKindMerchant WalletKind = "MERCHANT"
+	WalletKindSuspense WalletKind = "SUSPENSE" // Platform-owned, one per currency; holds unmatched inbound funds
+	WalletKindCrypto   WalletKind = "CRYPTO"   // Holds a crypto asset; funded from deposit addresses and paid out on chain
+)
+
 // Wallet represents a user's wallet.
 type Wallet struct {
-	ID        int64           `db:"id" json:"id"`                 // Primary key, BIGSERIAL in DB
-	UserID    int64           `db:"user_id" json:"user_id"`       // Foreign key to User
-	Currency  string          `db:"currency" json:"currency"`     // e.g., "USD", "FIAT"
-	Balance   decimal.Decimal `db:"balance" json:"balance"`       // Current balance, NUMERIC(20, 4) in DB
-	CreatedAt time.Time       `db:"created_at" json:"created_at"` // Timestamp of creation
-	UpdatedAt time.Time       `db:"updated_at" json:"updated_at"` // Timestamp of last update
+	ID                int64           `db:"id" json:"-"`                                              // Primary key, BIGSERIAL in DB; internal only
+	PublicID          uuid.UUID       `db:"public_id" json:"id"`                                      // Identifier exposed by the API
+	UserID            int64           `db:"user_id" json:"user_id"`                                   // Foreign key to User
+	Currency          string          `db:"currency" json:"currency"`                                 // e.g., "USD", "FIAT"
+	Balance           decimal.Decimal `db:"balance" json:"balance"`                                   // Current balance, NUMERIC(24, 8) in DB
+	Kind              WalletKind      `db:"kind" json:"kind"`                                         // PERSONAL unless registered as a merchant, or CRYPTO
+	Version           int64           `db:"version" json:"version"`                                   // Incremented on every balance change
+	CreatedAt         time.Time       `db:"created_at" json:"created_at"`                             // Timestamp of creation
+	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`                             // Timestamp of last update
+	LastActivityAt    time.Time       `db:"last_activity_at" json:"last_activity_at"`                 // Last balance change, or creation
+	DormantSince      *time.Time      `db:"dormant_since" json:"dormant_since,omitempty"`             // Set while flagged dormant
+	FrozenAt          *time.Time      `db:"frozen_at" json:"frozen_at,omitempty"`                     // Set while frozen for dormancy; no money can leave the wallet
+	OwnershipFrozenAt *time.Time      `db:"ownership_frozen_at" json:"ownership_frozen_at,omitempty"` // Set while frozen for a pending ownership transfer
+	DeletedAt         *time.Time      `db:"deleted_at" json:"deleted_at,omitempty"`                   // Set while soft-deleted
+}
+
+// WalletBalance is the state of a wallet right after a balance update, as returned by the UPDATE itself.
+type WalletBalance struct {
+	ID        int64           `db:"id"`
+	Balance   decimal.Decimal `db:"balance"`
+	Version   int64           `db:"version"`
+	UpdatedAt time.Time       `db:"updated_at"`
 }
 
-// NewWallet creates a new Wallet instance.
+// NewWallet creates a new Wallet instance. A wallet of a crypto asset is a CRYPTO wallet.
 func NewWallet(userID int64, currency string) *Wallet {
 	now := time.Now().UTC()
+	kind := WalletKindPersonal
+	if IsCryptoAsset(currency) {
+		kind = WalletKindCrypto
+	}
 	return &Wallet{
-		UserID:    userID,
-		Currency:  currency,
-		Balance:   decimal.Zero, // Initialize balance to 0
-		CreatedAt: now,
-		UpdatedAt: now,
+		PublicID:       uuid.New(),
+		UserID:         userID,
+		Currency:       currency,
+		Balance:        decimal.Zero, // Initialize balance to 0
+		Kind:           kind,
+		Version:        1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		LastActivityAt: now,
 	}
 }
+
+// ETag returns an opaque, quoted entity tag that changes whenever the wallet is modified.
+// It is derived from the version counter and the last update time.
+func (w *Wallet) ETag() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%d", w.ID, w.Version, w.UpdatedAt.UnixNano())))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// WithBalance returns a copy of the wallet carrying the state of a balance update.
+func (w Wallet) WithBalance(balance *WalletBalance) *Wallet {
+	w.Balance = balance.Balance
+	w.Version = balance.Version
+	w.UpdatedAt = balance.UpdatedAt
+	w.LastActivityAt = balance.UpdatedAt // Every balance change is activity
+	return &w
+}
@@ -0,0 +1,50 @@
+// internal/domain/wallet_export.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalletExportStatus defines the lifecycle of a wallet export job.
+type WalletExportStatus string
+
+const (
+	WalletExportStatusPending   WalletExportStatus = "PENDING" // Queued for the worker
+	WalletExportStatusRunning   WalletExportStatus = "RUNNING"
+	WalletExportStatusCompleted WalletExportStatus = "COMPLETED" // The file can be downloaded
+	WalletExportStatusFailed    WalletExportStatus = "FAILED"
+)
+
+// WalletExport is a request to export a wallet's full transaction history, processed in the background.
+type WalletExport struct {
+	ID             int64              `db:"id" json:"-"`
+	PublicID       uuid.UUID          `db:"public_id" json:"id"`
+	WalletID       int64              `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID          `db:"wallet_public_id" json:"wallet_id"` // Read-only, joined from wallets
+	Locale         string             `db:"locale" json:"locale"`              // Locale of the file's readable descriptions
+	Status         WalletExportStatus `db:"status" json:"status"`
+	TotalRows      *int64             `db:"total_rows" json:"total_rows"`
+	ProcessedRows  int64              `db:"processed_rows" json:"processed_rows"`
+	ObjectKey      *string            `db:"object_key" json:"-"`
+	Error          *string            `db:"error" json:"error"`
+	StartedAt      *time.Time         `db:"started_at" json:"started_at"`
+	CompletedAt    *time.Time         `db:"completed_at" json:"completed_at"`
+	AuditSequence  *int64             `db:"audit_sequence" json:"audit_sequence"` // Head of the wallet's audit chain when the file was written
+	AuditHash      *string            `db:"audit_hash" json:"audit_hash"`
+	CreatedAt      time.Time          `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `db:"updated_at" json:"updated_at"`
+}
+
+// Progress returns the share of rows written, from 0 to 1.
+func (e *WalletExport) Progress() float64 {
+	switch {
+	case e.Status == WalletExportStatusCompleted:
+		return 1
+	case e.TotalRows == nil || *e.TotalRows == 0:
+		return 0
+	default:
+		return min(float64(e.ProcessedRows)/float64(*e.TotalRows), 1)
+	}
+}
@@ -0,0 +1,81 @@
+// internal/domain/wallet_member.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// WalletRole defines what a member may do with a shared wallet.
+type WalletRole string
+
+const (
+	WalletRoleOwner   WalletRole = "OWNER"   // Spends, manages members and approves transfers
+	WalletRoleSpender WalletRole = "SPENDER" // Spends, subject to the approval policy
+	WalletRoleViewer  WalletRole = "VIEWER"  // Reads balances and history only
+)
+
+// Valid reports whether the role is one of the known roles.
+func (r WalletRole) Valid() bool {
+	return r == WalletRoleOwner || r == WalletRoleSpender || r == WalletRoleViewer
+}
+
+// CanSpend reports whether the role may initiate transfers from the wallet.
+func (r WalletRole) CanSpend() bool {
+	return r == WalletRoleOwner || r == WalletRoleSpender
+}
+
+// WalletMember grants a user a role on a wallet. The wallet's creator (Wallet.UserID) is always an owner
+// and is not stored as a member.
+type WalletMember struct {
+	WalletID  int64      `db:"wallet_id" json:"-"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	Role      WalletRole `db:"role" json:"role"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// ApprovalPolicy requires transfers above Threshold from a wallet to be approved by RequiredApprovals owners.
+type ApprovalPolicy struct {
+	WalletID          int64           `db:"wallet_id" json:"-"`
+	Threshold         decimal.Decimal `db:"threshold" json:"threshold"`
+	RequiredApprovals int             `db:"required_approvals" json:"required_approvals"`
+	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// Requires reports whether a transfer of amount needs approval under the policy.
+func (p *ApprovalPolicy) Requires(amount decimal.Decimal) bool {
+	return amount.GreaterThan(p.Threshold)
+}
+
+// TransferApprovalStatus defines the lifecycle of a transfer awaiting approval.
+type TransferApprovalStatus string
+
+const (
+	TransferApprovalPending  TransferApprovalStatus = "PENDING"  // Collecting approvals
+	TransferApprovalApproved TransferApprovalStatus = "APPROVED" // Enough approvals; the transfer is being executed
+	TransferApprovalExecuted TransferApprovalStatus = "EXECUTED" // The transfer was made
+	TransferApprovalRejected TransferApprovalStatus = "REJECTED" // An owner rejected it
+	TransferApprovalFailed   TransferApprovalStatus = "FAILED"   // Approved, but the transfer itself failed
+)
+
+// TransferApproval is a transfer held back until enough owners of the source wallet approve it.
+type TransferApproval struct {
+	ID                 int64                  `db:"id" json:"-"`
+	PublicID           uuid.UUID              `db:"public_id" json:"id"`
+	FromWalletID       int64                  `db:"from_wallet_id" json:"-"`
+	ToWalletID         int64                  `db:"to_wallet_id" json:"-"`
+	FromWalletPublicID uuid.UUID              `db:"from_wallet_public_id" json:"from_wallet_id"` // Read-only, joined from wallets
+	ToWalletPublicID   uuid.UUID              `db:"to_wallet_public_id" json:"to_wallet_id"`     // Read-only, joined from wallets
+	Amount             decimal.Decimal        `db:"amount" json:"amount"`
+	Currency           string                 `db:"currency" json:"currency"`
+	RequestedBy        *int64                 `db:"requested_by" json:"requested_by"`
+	RequiredApprovals  int                    `db:"required_approvals" json:"required_approvals"`
+	ApprovedBy         []int64                `db:"-" json:"approved_by"` // Loaded separately from the votes
+	Status             TransferApprovalStatus `db:"status" json:"status"`
+	TransactionID      *uuid.UUID             `db:"transaction_id" json:"transaction_id"` // Set once executed
+	FailureReason      *string                `db:"failure_reason" json:"failure_reason"`
+	CreatedAt          time.Time              `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time              `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+// internal/domain/wallet_serialization.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SerializationMode selects how the concurrent money movements of a contended wallet are ordered. Without
+// one, movements race for the row lock of the wallet's balance update.
+type SerializationMode string
+
+const (
+	// SerializationQueue queues the movements of the wallet in process, first come first served, before they
+	// open a database transaction, so waiting movements hold no connection.
+	SerializationQueue SerializationMode = "QUEUE"
+	// SerializationAdvisoryLock takes a Postgres advisory lock of the wallet first thing in the transaction,
+	// which orders the movements of all replicas.
+	SerializationAdvisoryLock SerializationMode = "ADVISORY_LOCK"
+)
+
+// Valid reports whether the mode is known.
+func (m SerializationMode) Valid() bool {
+	return m == SerializationQueue || m == SerializationAdvisoryLock
+}
+
+// WalletSerialization is the serialization mode chosen for a wallet.
+type WalletSerialization struct {
+	WalletID       int64             `db:"wallet_id" json:"-"`
+	WalletPublicID uuid.UUID         `db:"wallet_public_id" json:"wallet_id"`
+	Mode           SerializationMode `db:"mode" json:"mode"`
+	UpdatedBy      string            `db:"updated_by" json:"updated_by"`
+	UpdatedAt      time.Time         `db:"updated_at" json:"updated_at"`
+}
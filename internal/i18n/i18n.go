@@ -0,0 +1,148 @@
+// internal/i18n/i18n.go
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is used when a request accepts none of the available locales. Its bundle holds every
+// message; other bundles fall back to it for messages they lack.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var bundleFS embed.FS
+
+// bundles maps a locale (e.g. "de") to its messages, keyed by message key. A key is the machine-readable
+// error code returned next to the message, or the code plus a ".variant" suffix. Transaction description
+// templates are keyed the same way, by "transaction_" and the lower-case transaction type.
+var bundles = mustLoadBundles()
+
+func mustLoadBundles() map[string]map[string]string {
+	loaded, err := loadBundles()
+	if err != nil {
+		panic(err) // The bundles are compiled in; a malformed one is a build defect
+	}
+	return loaded
+}
+
+func loadBundles() (map[string]map[string]string, error) {
+	files, err := bundleFS.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := bundleFS.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("invalid translation bundle %s: %w", file.Name(), err)
+		}
+		loaded[strings.ToLower(strings.TrimSuffix(file.Name(), ".json"))] = messages
+	}
+	if _, ok := loaded[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("missing translation bundle for default locale %s", DefaultLocale)
+	}
+	return loaded, nil
+}
+
+// Locales returns the available locales, sorted.
+func Locales() []string {
+	locales := make([]string, 0, len(bundles))
+	for locale := range bundles {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Negotiate picks the available locale best matching an Accept-Language header (RFC 9110), e.g.
+// "de-AT,de;q=0.9,en;q=0.5". A regional tag matches its exact bundle, or else its language's bundle.
+// It returns DefaultLocale if nothing matches.
+func Negotiate(acceptLanguage string) string {
+	best, bestQ := DefaultLocale, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if raw, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= bestQ {
+			continue // Earlier entries win ties
+		}
+		if locale, ok := match(strings.ToLower(strings.TrimSpace(tag))); ok {
+			best, bestQ = locale, q
+		}
+	}
+	return best
+}
+
+func match(tag string) (string, bool) {
+	if tag == "*" {
+		return DefaultLocale, true
+	}
+	if _, ok := bundles[tag]; ok {
+		return tag, true
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	if _, ok := bundles[language]; ok {
+		return language, true
+	}
+	return "", false
+}
+
+type localeKey struct{}
+
+// WithLocale returns a context carrying the locale responses are written in.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the context's locale, or DefaultLocale if none is set.
+func LocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Message returns the message for key in the context's locale, falling back to the default locale and
+// then to the key itself. Placeholders such as {resource} are replaced from params.
+func Message(ctx context.Context, key string, params map[string]string) string {
+	message, ok := Lookup(LocaleFromContext(ctx), key)
+	if !ok {
+		message = key
+	}
+	return Format(message, params)
+}
+
+// Lookup returns the message for key in the locale, falling back to the default locale. It reports
+// whether either bundle holds the key.
+func Lookup(locale, key string) (string, bool) {
+	if message, ok := bundles[locale][key]; ok {
+		return message, true
+	}
+	message, ok := bundles[DefaultLocale][key]
+	return message, ok
+}
+
+// Format replaces the placeholders of a message, such as {resource}, from params.
+func Format(message string, params map[string]string) string {
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message
+}
@@ -0,0 +1,47 @@
+// internal/i18n/i18n_test.go
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBundlesAreComplete guards against a message added to the default bundle but not translated, and
+// against stray keys that no code uses.
+func TestBundlesAreComplete(t *testing.T) {
+	for locale, messages := range bundles {
+		for key := range bundles[DefaultLocale] {
+			assert.Contains(t, messages, key, "%s lacks %s", locale, key)
+		}
+		for key := range messages {
+			assert.Contains(t, bundles[DefaultLocale], key, "%s has unknown key %s", locale, key)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                         "en",
+		"de":                       "de",
+		"DE-ch":                    "de",
+		"fr-CA,es;q=0.8":           "es",
+		"en;q=0.5,es;q=0.9":        "es",
+		"es;q=0.5,de;q=0.5":        "es", // Ties go to the earlier entry
+		"de;q=0":                   "en", // Explicitly not acceptable
+		"*":                        "en",
+		"xx,de;q=invalid,es;q=0.1": "es",
+	} {
+		assert.Equal(t, want, Negotiate(header), header)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	de := WithLocale(context.Background(), "de")
+
+	assert.Equal(t, "Unzureichendes Guthaben", Message(de, "insufficient_funds", nil))
+	assert.Equal(t, "user existiert bereits", Message(de, "already_exists.resource", map[string]string{"resource": "user"}))
+	assert.Equal(t, "Insufficient funds", Message(context.Background(), "insufficient_funds", nil))
+	assert.Equal(t, "no_such_key", Message(de, "no_such_key", nil))
+}
@@ -0,0 +1,109 @@
+// internal/jobs/scheduler.go
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a named unit of background work that the Scheduler runs periodically.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered jobs on their own interval until stopped.
+// Each job runs once immediately on Start, then on every tick; runs of the same job never overlap.
+type Scheduler struct {
+	logger *slog.Logger
+	jobs   []Job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a new Scheduler.
+func NewScheduler(logger *slog.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// JobResult is the outcome of one run of a job.
+type JobResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Register adds a job to the scheduler. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) {
+	// Runs from RunAll queue behind the running tick of the same job
+	var running sync.Mutex
+	run := job.Run
+	job.Run = func(ctx context.Context) error {
+		running.Lock()
+		defer running.Unlock()
+		return run(ctx)
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// RunAll runs every registered job once, one after the other in the order they were registered, and returns
+// their results. It lets the sandbox's test clock catch the jobs up after it is advanced, without waiting for
+// their next tick.
+func (s *Scheduler) RunAll(ctx context.Context) []JobResult {
+	results := make([]JobResult, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		results = append(results, s.runOnce(ctx, job))
+	}
+	return results
+}
+
+// Start launches one goroutine per registered job.
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+	s.logger.Info("Background job scheduler started", "jobs", len(s.jobs))
+}
+
+// Stop cancels all running jobs and waits for them to return.
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info("Background job scheduler stopped")
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) JobResult {
+	start := time.Now()
+	err := job.Run(ctx)
+	result := JobResult{Name: job.Name, Duration: time.Since(start), Err: err}
+	if err != nil {
+		s.logger.Error("Background job failed", "job", job.Name, "error", err, "duration", result.Duration)
+		return result
+	}
+	s.logger.Info("Background job completed", "job", job.Name, "duration", result.Duration)
+	return result
+}
@@ -0,0 +1,16 @@
+// internal/migration/backfill.go
+package migration
+
+// Backfill fills in the data of an expand migration out of band, in small batches run by the background
+// scheduler, so that no migration holds locks on a large table for long. The contract migration relying on
+// the data, e.g. one making the new column NOT NULL, is only applied once the backfill has completed.
+type Backfill struct {
+	Name string // Unique; its progress is tracked under it
+	// After is the version of the migration the backfill needs; it does not start before that is applied.
+	After uint64
+	// Query fills in the batch of at most $2 rows whose key is greater than $1, and returns the keys of the
+	// rows it covered, which must increase with each batch. The backfill completes with the first empty batch.
+	// E.g. UPDATE t SET c = ... WHERE id IN (SELECT id FROM t WHERE id > $1 ORDER BY id LIMIT $2) RETURNING id
+	Query     string
+	BatchSize int
+}
@@ -0,0 +1,156 @@
+// internal/migration/migration.go
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Phase is the step of an expand/contract schema change a migration belongs to.
+type Phase string
+
+const (
+	// PhaseExpand migrations only add to the schema, so the code already running keeps working while and after
+	// they are applied. Every migration is an expand step unless marked otherwise.
+	PhaseExpand Phase = "expand"
+	// PhaseContract migrations remove or change what running code may still use. They are marked with a
+	// "-- migrate:contract" line and are only applied once no release depending on the old shape runs.
+	PhaseContract Phase = "contract"
+)
+
+// ContractMarker marks a migration as a contract step.
+const ContractMarker = "-- migrate:contract"
+
+// LegacyVersion is the last migration written before the expand/contract check. The earlier ones were applied
+// with downtime and are not checked.
+const LegacyVersion = 43
+
+// Migration is an up migration file.
+type Migration struct {
+	Version uint64
+	Name    string // File name without version and suffix, e.g. "create_async_transfers"
+	Phase   Phase
+	SQL     string
+}
+
+// Violation is a statement of an expand migration that running code may not survive.
+type Violation struct {
+	Version uint64
+	Name    string
+	Line    int
+	Rule    string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%06d_%s.up.sql:%d: %s; split it into expand and contract steps or mark the file %q",
+		v.Version, v.Name, v.Line, v.Rule, ContractMarker)
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(.+)\.up\.sql$`)
+
+// Load reads the up migrations of fsys, in version order.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	var migrations []Migration
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+		migration := Migration{Version: version, Name: match[2], Phase: PhaseExpand, SQL: string(content)}
+		for _, line := range strings.Split(migration.SQL, "\n") {
+			if strings.TrimSpace(line) == ContractMarker {
+				migration.Phase = PhaseContract
+			}
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// rule flags the statements of expand migrations matching pattern, unless allowed says otherwise.
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+	allowed func(match []string, sql string) bool // Optional
+}
+
+var defaultPattern = regexp.MustCompile(`(?i)\bDEFAULT\b`)
+
+var rules = []rule{
+	{name: "drops a table", pattern: regexp.MustCompile(`(?i)\bDROP\s+TABLE\b`)},
+	{name: "drops a column", pattern: regexp.MustCompile(`(?i)\bDROP\s+COLUMN\b`)},
+	{name: "renames a table or column", pattern: regexp.MustCompile(`(?i)\bRENAME\s+(TO|COLUMN)\b`)},
+	{name: "changes the type of a column", pattern: regexp.MustCompile(`(?i)\bALTER\s+COLUMN\s+\w+\s+(SET\s+DATA\s+)?TYPE\b`)},
+	{
+		// Old code inserting rows without the column fails, unless the column has a default
+		name:    "adds a NOT NULL column without a default",
+		pattern: regexp.MustCompile(`(?i)\bADD\s+COLUMN\s+[^,;]*\bNOT\s+NULL\b[^,;]*`),
+		allowed: func(match []string, sql string) bool {
+			return defaultPattern.MatchString(match[0])
+		},
+	},
+	{
+		name:    "makes a column NOT NULL without a default",
+		pattern: regexp.MustCompile(`(?i)\bALTER\s+COLUMN\s+(\w+)\s+SET\s+NOT\s+NULL\b`),
+		allowed: func(match []string, sql string) bool {
+			return regexp.MustCompile(`(?i)\bALTER\s+COLUMN\s+` + regexp.QuoteMeta(match[1]) + `\s+SET\s+DEFAULT\b`).MatchString(sql)
+		},
+	},
+}
+
+// Check returns the violations of the expand/contract pattern in the expand migrations after LegacyVersion:
+// statements dropping, renaming or retyping what running code may use, or adding constraints its writes may
+// break. Contract migrations may do all of that.
+func Check(migrations []Migration) []Violation {
+	var violations []Violation
+	for _, migration := range migrations {
+		if migration.Version <= LegacyVersion || migration.Phase == PhaseContract {
+			continue
+		}
+		sql := stripComments(migration.SQL)
+		for _, rule := range rules {
+			for _, loc := range rule.pattern.FindAllStringSubmatchIndex(sql, -1) {
+				match := make([]string, len(loc)/2)
+				for i := range match {
+					if loc[2*i] >= 0 {
+						match[i] = sql[loc[2*i]:loc[2*i+1]]
+					}
+				}
+				if rule.allowed != nil && rule.allowed(match, sql) {
+					continue
+				}
+				violations = append(violations, Violation{
+					Version: migration.Version,
+					Name:    migration.Name,
+					Line:    strings.Count(sql[:loc[0]], "\n") + 1,
+					Rule:    rule.name,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+var lineComment = regexp.MustCompile(`--[^\n]*`)
+
+// stripComments blanks out line comments, keeping the line numbers.
+func stripComments(sql string) string {
+	return lineComment.ReplaceAllString(sql, "")
+}
@@ -0,0 +1,77 @@
+// internal/migration/migration_test.go
+package migration_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/migration"
+	"finflow-wallet/migrations"
+)
+
+// TestMigrations checks the repository's migrations against the expand/contract rules.
+func TestMigrations(t *testing.T) {
+	loaded, err := migration.Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+
+	for _, violation := range migration.Check(loaded) {
+		t.Error(violation)
+	}
+	for i := 1; i < len(loaded); i++ {
+		assert.Equal(t, loaded[i-1].Version+1, loaded[i].Version, "migration versions follow each other")
+	}
+	names := map[string]bool{}
+	for _, backfill := range migrations.Backfills {
+		assert.False(t, names[backfill.Name], "backfill %s is registered twice", backfill.Name)
+		assert.Positive(t, backfill.BatchSize, "backfill %s", backfill.Name)
+		names[backfill.Name] = true
+	}
+}
+
+// TestCheck tests which statements of expand migrations are reported.
+func TestCheck(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000050_add_nickname.up.sql": {Data: []byte(`-- 000050_add_nickname.up.sql
+ALTER TABLE wallets ADD COLUMN nickname VARCHAR(50);
+ALTER TABLE wallets ADD COLUMN tier INT NOT NULL DEFAULT 0;
+CREATE INDEX idx_wallets_nickname ON wallets (nickname);`)},
+		"000050_add_nickname.down.sql": {Data: []byte(`ALTER TABLE wallets DROP COLUMN nickname;`)},
+		"000051_require_nickname.up.sql": {Data: []byte(`-- 000051_require_nickname.up.sql
+ALTER TABLE wallets ALTER COLUMN nickname SET NOT NULL;
+ALTER TABLE wallets ADD COLUMN region CHAR(2) NOT NULL;
+-- DROP TABLE wallets is only mentioned
+ALTER TABLE users RENAME COLUMN username TO login;
+ALTER TABLE wallets ALTER COLUMN tier TYPE BIGINT;
+DROP TABLE legacy_limits;`)},
+		"000052_drop_legacy.up.sql": {Data: []byte(`-- 000052_drop_legacy.up.sql
+-- migrate:contract
+ALTER TABLE wallets DROP COLUMN legacy_limit;`)},
+		"000053_default_then_not_null.up.sql": {Data: []byte(`ALTER TABLE wallets ALTER COLUMN tier SET DEFAULT 0;
+ALTER TABLE wallets ALTER COLUMN tier SET NOT NULL;`)},
+		"README.md": {Data: []byte("not a migration")},
+	}
+
+	loaded, err := migration.Load(fsys)
+	require.NoError(t, err)
+	require.Len(t, loaded, 4)
+	assert.Equal(t, migration.PhaseContract, loaded[2].Phase)
+
+	violations := migration.Check(loaded)
+	rules := make([]string, len(violations))
+	for i, violation := range violations {
+		assert.Equal(t, uint64(51), violation.Version)
+		rules[i] = violation.Rule
+	}
+	assert.ElementsMatch(t, []string{
+		"makes a column NOT NULL without a default",
+		"adds a NOT NULL column without a default",
+		"renames a table or column",
+		"changes the type of a column",
+		"drops a table",
+	}, rules)
+	assert.Contains(t, violations[0].String(), "000051_require_nickname.up.sql:")
+}
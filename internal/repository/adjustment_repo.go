@@ -0,0 +1,24 @@
+// internal/repository/adjustment_repo.go
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// AdjustmentRepository defines the interface for manual wallet adjustments.
+type AdjustmentRepository interface {
+	// CreateAdjustment stores a new proposed adjustment.
+	CreateAdjustment(ctx context.Context, q DBExecutor, adjustment *domain.Adjustment) error
+	GetAdjustmentByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.Adjustment, error)
+	// GetAdjustmentForUpdate retrieves an adjustment by public ID and row-locks it for the rest of the transaction.
+	GetAdjustmentForUpdate(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.Adjustment, error)
+	// ListAdjustments retrieves adjustments in one status or, if empty, all, newest first.
+	ListAdjustments(ctx context.Context, q DBExecutor, status domain.AdjustmentStatus, limit int) ([]domain.Adjustment, error)
+	// UpdateDecision records the approval or rejection of a pending adjustment: its new status, the deciding
+	// admin and note, the decision time and, once posted, the transaction.
+	UpdateDecision(ctx context.Context, q DBExecutor, adjustment *domain.Adjustment) error
+}
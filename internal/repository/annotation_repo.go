@@ -0,0 +1,19 @@
+// internal/repository/annotation_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// AnnotationRepository defines the interface for transaction annotation data operations.
+type AnnotationRepository interface {
+	// GetAnnotation retrieves the annotation of a transaction, or util.ErrNotFound if it has none.
+	GetAnnotation(ctx context.Context, q DBExecutor, transactionID uuid.UUID) (*domain.TransactionAnnotation, error)
+	// ApplyAnnotation creates the transaction's annotation or applies the patch to it, and returns the result.
+	ApplyAnnotation(ctx context.Context, q DBExecutor, transactionID uuid.UUID, patch domain.TransactionAnnotationPatch, now time.Time) (*domain.TransactionAnnotation, error)
+}
@@ -0,0 +1,26 @@
+// internal/repository/async_transfer_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+)
+
+// AsyncTransferRepository defines the interface for transfers accepted for later execution.
+type AsyncTransferRepository interface {
+	// CreateAsyncTransfer records the options of the transfer whose PENDING transaction is transfer.TransactionID.
+	CreateAsyncTransfer(ctx context.Context, q DBExecutor, transfer *domain.AsyncTransfer) error
+	// ListPendingAsyncTransfers returns up to limit transfers whose transaction is still PENDING and whose next
+	// attempt is due by now, oldest first.
+	ListPendingAsyncTransfers(ctx context.Context, q DBExecutor, now time.Time, limit int) ([]domain.AsyncTransfer, error)
+	// CompleteAsyncTransfer marks the PENDING transaction as COMPLETED with the time, category and promotional
+	// amount of the transfer made for it. It returns util.ErrNotFound if the transaction is no longer PENDING.
+	CompleteAsyncTransfer(ctx context.Context, q DBExecutor, transaction *domain.Transaction) error
+	// RetryAsyncTransfer counts a failed attempt of the PENDING transfer and defers its next one to nextAttemptAt.
+	RetryAsyncTransfer(ctx context.Context, q DBExecutor, transactionID int64, nextAttemptAt, at time.Time) error
+	// FailAsyncTransfer marks the PENDING transaction as FAILED and records the reason. It returns
+	// util.ErrNotFound if the transaction is no longer PENDING.
+	FailAsyncTransfer(ctx context.Context, q DBExecutor, transactionID int64, reason string, at time.Time) error
+}
@@ -0,0 +1,24 @@
+// internal/repository/audit_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// AuditRepository defines the interface for audit trail data operations.
+type AuditRepository interface {
+	// LockChain takes the lock of the wallet's chain for the rest of the transaction q, so appends to one chain
+	// happen one at a time.
+	LockChain(ctx context.Context, q DBExecutor, walletID int64) error
+	// GetChainHead returns the sequence number and hash of the wallet's latest event, or util.ErrNotFound for an
+	// empty chain.
+	GetChainHead(ctx context.Context, q DBExecutor, walletID int64) (*domain.AuditChainHead, error)
+	// AppendEvent stores the event. It fails with util.ErrDuplicateEntry if the wallet already has an event of
+	// its sequence number.
+	AppendEvent(ctx context.Context, q DBExecutor, event *domain.AuditEvent) error
+	// ListEventsAfter retrieves up to limit events of the wallet with a sequence number above afterSequence,
+	// in chain order.
+	ListEventsAfter(ctx context.Context, q DBExecutor, walletID, afterSequence int64, limit int) ([]domain.AuditEvent, error)
+}
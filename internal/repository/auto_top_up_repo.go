@@ -0,0 +1,36 @@
+// internal/repository/auto_top_up_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+)
+
+// AutoTopUpRepository defines the interface for funding source and auto top-up rule data operations.
+type AutoTopUpRepository interface {
+	// CreateFundingSource links a new funding source to a wallet using the provided DBExecutor.
+	CreateFundingSource(ctx context.Context, q DBExecutor, source *domain.FundingSource) error
+	// ListFundingSources retrieves all funding sources of a wallet.
+	ListFundingSources(ctx context.Context, q DBExecutor, walletID int64) ([]domain.FundingSource, error)
+	// GetFundingSource retrieves a wallet's funding source; it returns util.ErrNotFound if the wallet has no such source.
+	GetFundingSource(ctx context.Context, q DBExecutor, walletID, sourceID int64) (*domain.FundingSource, error)
+	// DeleteFundingSource unlinks a wallet's funding source; it returns util.ErrNotFound if the wallet has no such
+	// source and util.ErrReferenceViolation if the wallet's auto top-up rule still uses it.
+	DeleteFundingSource(ctx context.Context, q DBExecutor, walletID, sourceID int64) error
+	// GetRule retrieves a wallet's auto top-up rule; it returns util.ErrNotFound if the wallet has none.
+	GetRule(ctx context.Context, q DBExecutor, walletID int64) (*domain.AutoTopUpRule, error)
+	// SaveRule creates or replaces a wallet's auto top-up rule, enabled and without failures.
+	SaveRule(ctx context.Context, q DBExecutor, rule *domain.AutoTopUpRule) error
+	// DeleteRule removes a wallet's auto top-up rule; it returns util.ErrNotFound if the wallet has none.
+	DeleteRule(ctx context.Context, q DBExecutor, walletID int64) error
+	// ListDueRules retrieves the enabled rules of live wallets whose balance is below the threshold and that
+	// were not attempted after attemptedBefore.
+	ListDueRules(ctx context.Context, q DBExecutor, attemptedBefore time.Time) ([]domain.AutoTopUpRule, error)
+	// ClaimRule records an attempt of a due rule at now. It is a conditional update, so of two concurrent
+	// claims only one succeeds; the other returns util.ErrNotFound.
+	ClaimRule(ctx context.Context, q DBExecutor, walletID int64, now, attemptedBefore time.Time) error
+	// RecordOutcome stores the outcome of the last attempt: its failure count, error and whether the rule stays enabled.
+	RecordOutcome(ctx context.Context, q DBExecutor, walletID int64, consecutiveFailures int, lastError *string, enabled bool) error
+}
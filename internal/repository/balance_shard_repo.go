@@ -0,0 +1,31 @@
+// internal/repository/balance_shard_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceShardRepository defines the interface for the balance shards of high-throughput wallets.
+type BalanceShardRepository interface {
+	// ListShards retrieves the shards of every sharded wallet, by wallet and shard.
+	ListShards(ctx context.Context, q DBExecutor) ([]domain.BalanceShard, error)
+	// UpdateShardBalance adds amount to the first shard of the wallet, counting from start of shards, that no
+	// other transaction has locked and that amount leaves non-negative, dates the shard at, and returns the
+	// wallet's new balance. It fails with util.ErrNotFound if no shard qualifies.
+	UpdateShardBalance(ctx context.Context, q DBExecutor, walletID int64, start, shards int, amount decimal.Decimal, at time.Time) (*domain.WalletBalance, error)
+	// LockShards locks the wallet and its shards for the rest of the transaction q, and returns the wallet's
+	// own balance and its shards in shard order.
+	LockShards(ctx context.Context, q DBExecutor, walletID int64) (decimal.Decimal, []domain.BalanceShard, error)
+	// ResizeShards gives a wallet locked by LockShards shards 0 to shards-1, with a zero balance for new ones.
+	// The balance and version of removed shards are added to the wallet's own.
+	ResizeShards(ctx context.Context, q DBExecutor, walletID int64, shards int) error
+	// SetShardBalances sets the wallet's own balance and those of its shards, in shard order, leaving the
+	// versions alone, as the wallet's balance is unchanged. lastActivity is carried into the wallet's own
+	// last activity, for the dormancy check.
+	SetShardBalances(ctx context.Context, q DBExecutor, walletID int64, own decimal.Decimal, shards []decimal.Decimal, lastActivity time.Time) error
+}
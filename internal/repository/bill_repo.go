@@ -0,0 +1,32 @@
+// internal/repository/bill_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// BillRepository defines the interface for shared bill data operations.
+type BillRepository interface {
+	// CreateBill stores a new bill together with its participants.
+	CreateBill(ctx context.Context, q DBExecutor, bill *domain.Bill) error
+	// GetBillByPublicID retrieves a bill and its participants by the bill's public UUID.
+	GetBillByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.Bill, error)
+	// GetBillByPublicIDForUpdate retrieves a bill and its participants and row-locks the bill for the rest of the transaction.
+	GetBillByPublicIDForUpdate(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.Bill, error)
+	// ListBillsByWallet retrieves the bills a wallet owns or takes part in, newest first.
+	ListBillsByWallet(ctx context.Context, q DBExecutor, walletID int64) ([]domain.Bill, error)
+	// UpdateBillStatus stores the status of a bill.
+	UpdateBillStatus(ctx context.Context, q DBExecutor, bill *domain.Bill) error
+	// UpdateParticipant stores the paid amount, status and approval time of a participant.
+	UpdateParticipant(ctx context.Context, q DBExecutor, participant *domain.BillParticipant) error
+	// ListDueReminders retrieves the participants of open bills who still owe money and were not reminded
+	// (or, if never reminded, added to the bill) since the given time.
+	ListDueReminders(ctx context.Context, q DBExecutor, since time.Time) ([]domain.BillParticipant, error)
+	// MarkReminded records that a participant was reminded at the given time.
+	MarkReminded(ctx context.Context, q DBExecutor, billID, walletID int64, at time.Time) error
+}
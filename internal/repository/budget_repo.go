@@ -0,0 +1,18 @@
+// internal/repository/budget_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// BudgetRepository defines the interface for budget data operations.
+type BudgetRepository interface {
+	// CreateBudget adds a new budget using the provided DBExecutor.
+	CreateBudget(ctx context.Context, q DBExecutor, budget *domain.Budget) error
+	// ListBudgetsByWalletID retrieves all budgets of a wallet.
+	ListBudgetsByWalletID(ctx context.Context, q DBExecutor, walletID int64) ([]domain.Budget, error)
+	// DeleteBudget removes a wallet's budget; it returns util.ErrNotFound if the wallet has no such budget.
+	DeleteBudget(ctx context.Context, q DBExecutor, walletID, budgetID int64) error
+}
@@ -0,0 +1,23 @@
+// internal/repository/change_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+)
+
+// ChangeFilter narrows the change feed.
+type ChangeFilter struct {
+	UserID      *int64        // Only wallets of this user and the transactions touching them
+	SettleDelay time.Duration // Only changes at least this old by the database clock
+}
+
+// ChangeRepository defines the interface for reading rows by change sequence.
+type ChangeRepository interface {
+	// ListWalletChanges retrieves up to limit wallets changed after since, in change order.
+	ListWalletChanges(ctx context.Context, q DBExecutor, since int64, filter ChangeFilter, limit int) ([]domain.WalletChange, error)
+	// ListTransactionChanges retrieves up to limit transactions changed after since, in change order.
+	ListTransactionChanges(ctx context.Context, q DBExecutor, since int64, filter ChangeFilter, limit int) ([]domain.TransactionChange, error)
+}
@@ -0,0 +1,22 @@
+// internal/repository/contact_verification_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// ContactVerificationRepository defines the interface for the pending verifications of users' email
+// addresses and phone numbers, one per user and channel.
+type ContactVerificationRepository interface {
+	GetVerification(ctx context.Context, q DBExecutor, userID int64, channel domain.ContactChannel) (*domain.ContactVerification, error)
+	// GetVerificationForUpdate retrieves a pending verification and locks it until the end of the transaction,
+	// so concurrent attempts are counted one after another.
+	GetVerificationForUpdate(ctx context.Context, q DBExecutor, userID int64, channel domain.ContactChannel) (*domain.ContactVerification, error)
+	// SaveVerification creates or replaces the pending verification of a user and channel.
+	SaveVerification(ctx context.Context, q DBExecutor, verification *domain.ContactVerification) error
+	// UpdateAttempts stores the failed attempts of a pending verification.
+	UpdateAttempts(ctx context.Context, q DBExecutor, verification *domain.ContactVerification) error
+	DeleteVerification(ctx context.Context, q DBExecutor, userID int64, channel domain.ContactChannel) error
+}
@@ -0,0 +1,50 @@
+// internal/repository/crypto_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// CryptoRepository defines the interface for the deposit addresses, deposits and payouts of crypto wallets.
+type CryptoRepository interface {
+	// CreateAddress stores a deposit address issued to a wallet; it returns util.ErrDuplicateEntry if the
+	// address is already assigned.
+	CreateAddress(ctx context.Context, q DBExecutor, address *domain.CryptoAddress) error
+	// ListAddresses retrieves the deposit addresses of a wallet, oldest first.
+	ListAddresses(ctx context.Context, q DBExecutor, walletID int64) ([]domain.CryptoAddress, error)
+	// GetAddress retrieves the deposit address on a network; it returns util.ErrNotFound if it was not issued here.
+	GetAddress(ctx context.Context, q DBExecutor, network, address string) (*domain.CryptoAddress, error)
+
+	// RecordDeposit stores a deposit reported by the chain gateway, or raises the confirmations of the one
+	// already stored for its output, and fills in deposit from the stored row.
+	RecordDeposit(ctx context.Context, q DBExecutor, deposit *domain.CryptoDeposit) error
+	// GetDepositForUpdate retrieves a deposit and locks its row.
+	GetDepositForUpdate(ctx context.Context, q DBExecutor, depositID int64) (*domain.CryptoDeposit, error)
+	// MarkDepositCredited records the DEPOSIT transaction crediting a pending deposit.
+	MarkDepositCredited(ctx context.Context, q DBExecutor, depositID int64, transactionID uuid.UUID, creditedAt time.Time) error
+	// ListDeposits retrieves the latest deposits of a wallet, newest first.
+	ListDeposits(ctx context.Context, q DBExecutor, walletID int64, limit int) ([]domain.CryptoDeposit, error)
+
+	// CreatePayout queues a payout.
+	CreatePayout(ctx context.Context, q DBExecutor, payout *domain.CryptoPayout) error
+	// GetPayoutByPublicID retrieves a payout; it returns util.ErrNotFound if there is none.
+	GetPayoutByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.CryptoPayout, error)
+	// ListPayouts retrieves the latest payouts of a wallet, newest first.
+	ListPayouts(ctx context.Context, q DBExecutor, walletID int64, limit int) ([]domain.CryptoPayout, error)
+	// ClaimNextPayout marks the oldest QUEUED payout SENDING, counts the attempt and returns it, or
+	// util.ErrNotFound if none is queued. Concurrent workers never claim the same payout.
+	ClaimNextPayout(ctx context.Context, q DBExecutor, now time.Time) (*domain.CryptoPayout, error)
+	// RequeueStalePayouts returns payouts SENDING since before claimedBefore, e.g. after a crash, to the queue.
+	RequeueStalePayouts(ctx context.Context, q DBExecutor, claimedBefore time.Time) (int64, error)
+	// RequeuePayout returns a SENDING payout to the queue after a failed attempt that may be retried.
+	RequeuePayout(ctx context.Context, q DBExecutor, payoutID int64, lastError string) error
+	// MarkPayoutSent records the chain transaction of a SENDING payout.
+	MarkPayoutSent(ctx context.Context, q DBExecutor, payoutID int64, chainTxHash string) error
+	// MarkPayoutFailed records why a SENDING payout was rejected and the DEPOSIT refunding it.
+	MarkPayoutFailed(ctx context.Context, q DBExecutor, payoutID int64, lastError string, refundTransactionID uuid.UUID) error
+}
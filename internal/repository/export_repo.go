@@ -0,0 +1,25 @@
+// internal/repository/export_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+)
+
+// ExportRepository defines the interface for reading data for the warehouse export and tracking its progress.
+type ExportRepository interface {
+	// GetWatermark retrieves the progress of a stream; util.ErrNotFound means it was never exported.
+	GetWatermark(ctx context.Context, q DBExecutor, stream string) (*domain.ExportWatermark, error)
+	// SaveWatermark creates or replaces the progress of a stream.
+	SaveWatermark(ctx context.Context, q DBExecutor, watermark *domain.ExportWatermark) error
+	// ListTransactionsAfter retrieves up to limit transactions with an ID above afterID created before
+	// the given time, in ID order.
+	ListTransactionsAfter(ctx context.Context, q DBExecutor, afterID int64, before time.Time, limit int) ([]domain.Transaction, error)
+	// ListTransactionsInRange retrieves up to limit completed transactions with an ID above afterID created
+	// in [from, to), in ID order. The archive is included, as journals are often exported for past periods.
+	ListTransactionsInRange(ctx context.Context, q DBExecutor, from, to time.Time, afterID int64, limit int) ([]domain.Transaction, error)
+	// ListWalletsAfter retrieves up to limit wallets with an ID above afterID, in ID order.
+	ListWalletsAfter(ctx context.Context, q DBExecutor, afterID int64, limit int) ([]domain.Wallet, error)
+}
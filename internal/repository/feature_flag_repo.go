@@ -0,0 +1,18 @@
+// internal/repository/feature_flag_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// FeatureFlagRepository defines the interface for feature flag data operations.
+type FeatureFlagRepository interface {
+	// ListFlags retrieves every feature flag using the provided DBExecutor.
+	ListFlags(ctx context.Context, q DBExecutor) ([]domain.FeatureFlag, error)
+	// UpsertFlag creates the flag or replaces the existing flag with the same key.
+	UpsertFlag(ctx context.Context, q DBExecutor, flag *domain.FeatureFlag) error
+	// DeleteFlag removes a flag; it returns util.ErrNotFound if no such flag exists.
+	DeleteFlag(ctx context.Context, q DBExecutor, key string) error
+}
@@ -0,0 +1,14 @@
+// internal/repository/fx_rate_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// FXRateRepository defines the interface for exchange rate data operations.
+type FXRateRepository interface {
+	// ListRates retrieves the latest rate of every currency pair using the provided DBExecutor.
+	ListRates(ctx context.Context, q DBExecutor) ([]domain.FXRate, error)
+}
@@ -0,0 +1,24 @@
+// internal/repository/identity_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+)
+
+// IdentityRepository defines the interface for the OpenID Connect identities of users.
+type IdentityRepository interface {
+	// CreateIdentity links an identity to its user. It returns util.ErrDuplicateEntry if the provider
+	// account or the user's identity at the provider is already linked.
+	CreateIdentity(ctx context.Context, q DBExecutor, identity *domain.UserIdentity) error
+	// GetIdentity retrieves the identity of a provider account.
+	GetIdentity(ctx context.Context, q DBExecutor, provider, subject string) (*domain.UserIdentity, error)
+	// ListIdentitiesByUser retrieves the identities of a user, by provider.
+	ListIdentitiesByUser(ctx context.Context, q DBExecutor, userID int64) ([]domain.UserIdentity, error)
+	// DeleteIdentity unlinks the user's identity at the provider. It returns util.ErrNotFound if there is none.
+	DeleteIdentity(ctx context.Context, q DBExecutor, userID int64, provider string) error
+	// TouchIdentity records a login with the identity.
+	TouchIdentity(ctx context.Context, q DBExecutor, id int64, at time.Time) error
+}
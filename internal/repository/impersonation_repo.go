@@ -0,0 +1,30 @@
+// internal/repository/impersonation_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// ImpersonationRepository defines the interface for impersonation session and audit data operations.
+type ImpersonationRepository interface {
+	// CreateSession stores a new session.
+	CreateSession(ctx context.Context, q DBExecutor, session *domain.ImpersonationSession) error
+	// GetSessionByTokenHash retrieves a session by the hash of its token.
+	GetSessionByTokenHash(ctx context.Context, q DBExecutor, tokenHash string) (*domain.ImpersonationSession, error)
+	// GetSessionByPublicID retrieves a session by its public ID.
+	GetSessionByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.ImpersonationSession, error)
+	// ClaimTransfer uses up the session's one transfer. It returns util.ErrNotFound when the session
+	// does not allow a transfer, has already made one, or is no longer active at now.
+	ClaimTransfer(ctx context.Context, q DBExecutor, sessionID int64, now time.Time) error
+	// RevokeSession ends an active session.
+	RevokeSession(ctx context.Context, q DBExecutor, sessionID int64, now time.Time) error
+	// CreateAuditEntry records a request made with a session.
+	CreateAuditEntry(ctx context.Context, q DBExecutor, entry *domain.ImpersonationAuditEntry) error
+	// ListAuditEntries retrieves a session's audit entries, oldest first.
+	ListAuditEntries(ctx context.Context, q DBExecutor, sessionID int64) ([]domain.ImpersonationAuditEntry, error)
+}
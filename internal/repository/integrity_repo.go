@@ -0,0 +1,23 @@
+// internal/repository/integrity_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// IntegrityRepository defines the interface for the invariant checks of a database, e.g. a restored backup.
+type IntegrityRepository interface {
+	// ListBalanceMismatches retrieves up to limit wallets whose balance, shards included, differs from the sum
+	// of their completed transactions, archived ones included, and how many there are in all.
+	ListBalanceMismatches(ctx context.Context, q DBExecutor, limit int) ([]domain.BalanceMismatch, int64, error)
+	// ListLedgerBalances retrieves the balances of the wallets, deleted ones included, next to the sums of
+	// their completed transactions, in ascending ID order; missing wallets are left out.
+	ListLedgerBalances(ctx context.Context, q DBExecutor, walletIDs []int64) ([]domain.LedgerBalance, error)
+	// ListCurrencyImbalances retrieves the currencies whose wallet balances differ from their net external flows.
+	ListCurrencyImbalances(ctx context.Context, q DBExecutor) ([]domain.CurrencyImbalance, error)
+	// CountOrphans counts, per checked relation, the rows referencing a missing row. Relations without orphans
+	// are included with 0 rows.
+	CountOrphans(ctx context.Context, q DBExecutor) ([]domain.OrphanCount, error)
+}
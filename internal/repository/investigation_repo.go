@@ -0,0 +1,25 @@
+// internal/repository/investigation_repo.go
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// InvestigationRepository defines the interface for finding the entities linked to a transaction.
+type InvestigationRepository interface {
+	// ListChildTransactions retrieves the transactions whose parent is the transaction, archived ones included.
+	ListChildTransactions(ctx context.Context, q DBExecutor, transactionID uuid.UUID) ([]domain.Transaction, error)
+	// ListTransactionReferences retrieves the records that reference the transaction, oldest first.
+	ListTransactionReferences(ctx context.Context, q DBExecutor, transactionID uuid.UUID) ([]domain.TransactionReference, error)
+	// ListTransactionNotifications retrieves the inbox notifications of the users about the transaction.
+	ListTransactionNotifications(ctx context.Context, q DBExecutor, transactionID uuid.UUID, userIDs []int64) ([]domain.Notification, error)
+	// ListTransactionDeliveries retrieves the push deliveries to the users about the transaction.
+	ListTransactionDeliveries(ctx context.Context, q DBExecutor, transactionID uuid.UUID, userIDs []int64) ([]domain.NotificationDelivery, error)
+	// ListTransactionAudit retrieves the requests made while impersonating the users whose path names the
+	// transaction, oldest first.
+	ListTransactionAudit(ctx context.Context, q DBExecutor, transactionID uuid.UUID, userIDs []int64) ([]domain.TransactionAuditEntry, error)
+}
@@ -0,0 +1,41 @@
+// internal/repository/merchant_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// MerchantRepository defines the interface for merchant settlement preferences and settlements.
+type MerchantRepository interface {
+	// SaveSettings creates or replaces the settlement preferences of a merchant wallet.
+	SaveSettings(ctx context.Context, q DBExecutor, settings *domain.MerchantSettings) error
+	// GetSettings retrieves a merchant wallet's preferences; it returns util.ErrNotFound if the wallet is not a merchant.
+	GetSettings(ctx context.Context, q DBExecutor, walletID int64) (*domain.MerchantSettings, error)
+	// ListSettings retrieves the preferences of all merchant wallets.
+	ListSettings(ctx context.Context, q DBExecutor) ([]domain.MerchantSettings, error)
+	// CreateSettlement stores a settlement; it returns util.ErrDuplicateEntry if the merchant's day is already settled.
+	CreateSettlement(ctx context.Context, q DBExecutor, settlement *domain.Settlement) error
+	// ListSettlements retrieves the settlements of a merchant wallet, newest first.
+	ListSettlements(ctx context.Context, q DBExecutor, merchantWalletID int64) ([]domain.Settlement, error)
+}
+
+// ChargeRepository defines the interface for merchant charge data operations.
+type ChargeRepository interface {
+	// CreateCharge stores a new charge.
+	CreateCharge(ctx context.Context, q DBExecutor, charge *domain.Charge) error
+	// GetChargeByPublicID retrieves a charge by its public UUID.
+	GetChargeByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.Charge, error)
+	// GetChargeByPublicIDForUpdate retrieves a charge and row-locks it for the rest of the transaction.
+	GetChargeByPublicIDForUpdate(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.Charge, error)
+	// UpdateCharge stores the status, payer, transaction and payment time of a charge.
+	UpdateCharge(ctx context.Context, q DBExecutor, charge *domain.Charge) error
+	// ListUnsettledChargesForUpdate retrieves and row-locks a merchant's paid charges not yet settled, paid before the cutoff.
+	ListUnsettledChargesForUpdate(ctx context.Context, q DBExecutor, merchantWalletID int64, paidBefore time.Time) ([]domain.Charge, error)
+	// MarkChargesSettled links the given charges to a settlement.
+	MarkChargesSettled(ctx context.Context, q DBExecutor, settlementID int64, chargeIDs []int64) error
+}
@@ -0,0 +1,32 @@
+// internal/repository/netting_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// NettingRepository defines the interface for netting instructions and the net settlements that settle them.
+type NettingRepository interface {
+	// CreateInstruction stores a new pending netting instruction.
+	CreateInstruction(ctx context.Context, q DBExecutor, instruction *domain.NettingInstruction) error
+	// GetInstructionByPublicID retrieves a netting instruction, with its settlement once settled.
+	GetInstructionByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.NettingInstruction, error)
+	// ListPendingPairs retrieves the wallet pairs and currencies with pending instructions created before the window end.
+	ListPendingPairs(ctx context.Context, q DBExecutor, windowEnd time.Time) ([]domain.NettingPair, error)
+	// ListPendingInstructionsForUpdate retrieves and row-locks a pair's pending instructions, in either
+	// direction, created before the window end.
+	ListPendingInstructionsForUpdate(ctx context.Context, q DBExecutor, pair domain.NettingPair, windowEnd time.Time) ([]domain.NettingInstruction, error)
+	// CreateSettlement stores a net settlement.
+	CreateSettlement(ctx context.Context, q DBExecutor, settlement *domain.NettingSettlement) error
+	// GetSettlementByPublicID retrieves a net settlement.
+	GetSettlementByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.NettingSettlement, error)
+	// MarkInstructionsSettled links the given instructions to a settlement.
+	MarkInstructionsSettled(ctx context.Context, q DBExecutor, settlementID int64, instructionIDs []int64, at time.Time) error
+	// ListSettlementInstructions retrieves the instructions settled by a settlement, oldest first.
+	ListSettlementInstructions(ctx context.Context, q DBExecutor, settlementID int64) ([]domain.NettingInstruction, error)
+}
@@ -0,0 +1,40 @@
+// internal/repository/notification_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+)
+
+// NotificationRepository defines the interface for users' notification inboxes, push devices, notification
+// preferences and the deliveries of their notifications.
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, q DBExecutor, notification *domain.Notification) error
+	// ListNotifications retrieves a page of the user's notifications, newest first, optionally only the unread
+	// ones, and the total count matching.
+	ListNotifications(ctx context.Context, q DBExecutor, userID int64, unreadOnly bool, limit, offset int) ([]domain.Notification, int64, error)
+	CountUnreadNotifications(ctx context.Context, q DBExecutor, userID int64) (int64, error)
+	// MarkNotificationRead marks a notification of the user read at the given time, unless it already was, and
+	// returns it.
+	MarkNotificationRead(ctx context.Context, q DBExecutor, userID, notificationID int64, at time.Time) (*domain.Notification, error)
+	// MarkAllNotificationsRead marks every unread notification of the user read and returns how many there were.
+	MarkAllNotificationsRead(ctx context.Context, q DBExecutor, userID int64, at time.Time) (int64, error)
+	// RegisterDevice creates a device, or moves an already registered token to the user and refreshes it.
+	RegisterDevice(ctx context.Context, q DBExecutor, device *domain.DeviceToken) error
+	ListDevices(ctx context.Context, q DBExecutor, userID int64) ([]domain.DeviceToken, error)
+	DeleteDevice(ctx context.Context, q DBExecutor, userID, deviceID int64) error
+	// ListPreferences retrieves the user's stored preferences; categories without one are enabled.
+	ListPreferences(ctx context.Context, q DBExecutor, userID int64) ([]domain.NotificationPreference, error)
+	// SavePreference creates or replaces a preference of a user.
+	SavePreference(ctx context.Context, q DBExecutor, preference *domain.NotificationPreference) error
+	CreateDelivery(ctx context.Context, q DBExecutor, delivery *domain.NotificationDelivery) error
+	// ClaimPendingDeliveries locks up to limit pending deliveries, oldest first, with the platform and token of
+	// their device. Deliveries locked by another worker are skipped, so q should be a transaction.
+	ClaimPendingDeliveries(ctx context.Context, q DBExecutor, limit int) ([]domain.NotificationDelivery, error)
+	// UpdateDelivery stores the status, attempts and outcome of a delivery.
+	UpdateDelivery(ctx context.Context, q DBExecutor, delivery *domain.NotificationDelivery) error
+	// ListDeliveries retrieves the user's most recent deliveries, newest first.
+	ListDeliveries(ctx context.Context, q DBExecutor, userID int64, limit int) ([]domain.NotificationDelivery, error)
+}
@@ -0,0 +1,27 @@
+// internal/repository/open_banking_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// OpenBankingRepository defines the interface for account access consent data operations.
+type OpenBankingRepository interface {
+	// CreateConsent stores a new consent awaiting authorisation.
+	CreateConsent(ctx context.Context, q DBExecutor, consent *domain.OBConsent) error
+	// GetConsent retrieves a consent by its consent ID.
+	GetConsent(ctx context.Context, q DBExecutor, consentID uuid.UUID) (*domain.OBConsent, error)
+	// ListConsentsByUser retrieves the consents a user authorised or rejected, newest first.
+	ListConsentsByUser(ctx context.Context, q DBExecutor, userID int64) ([]domain.OBConsent, error)
+	// ResolveConsent records the user's answer to a consent awaiting authorisation: Authorised for the
+	// wallets, or Rejected. It returns util.ErrNotFound if the consent is not awaiting authorisation anymore.
+	ResolveConsent(ctx context.Context, q DBExecutor, id int64, status domain.OBConsentStatus, userID int64, walletIDs []int64, at time.Time) error
+	// RevokeConsent revokes a consent awaiting authorisation or authorised. It returns util.ErrNotFound if the
+	// consent is neither.
+	RevokeConsent(ctx context.Context, q DBExecutor, id int64, at time.Time) error
+}
@@ -0,0 +1,32 @@
+// internal/repository/ownership_transfer_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// OwnershipTransferRepository defines the interface for transfers of wallets to other users.
+type OwnershipTransferRepository interface {
+	// CreateOwnershipTransfer stores a new pending transfer. A second pending transfer of the same wallet fails
+	// with util.ErrDuplicateEntry.
+	CreateOwnershipTransfer(ctx context.Context, q DBExecutor, transfer *domain.OwnershipTransfer) error
+	GetOwnershipTransfer(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.OwnershipTransfer, error)
+	// GetOwnershipTransferForUpdate retrieves a transfer by public ID and row-locks it for the rest of the transaction.
+	GetOwnershipTransferForUpdate(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.OwnershipTransfer, error)
+	// ListWalletOwnershipTransfers retrieves up to limit transfers of a wallet, newest first.
+	ListWalletOwnershipTransfers(ctx context.Context, q DBExecutor, walletID int64, limit int) ([]domain.OwnershipTransfer, error)
+	// ListIncomingOwnershipTransfers retrieves up to limit transfers to a user in one status or, if empty, all,
+	// newest first.
+	ListIncomingOwnershipTransfers(ctx context.Context, q DBExecutor, toUserID int64, status domain.OwnershipTransferStatus, limit int) ([]domain.OwnershipTransfer, error)
+	// ListExpiredOwnershipTransfers retrieves the public IDs of up to limit pending transfers that expired before
+	// the given time, oldest first.
+	ListExpiredOwnershipTransfers(ctx context.Context, q DBExecutor, before time.Time, limit int) ([]uuid.UUID, error)
+	// ResolveOwnershipTransfer records the outcome of a pending transfer: its new status, the cancelling admin, if
+	// any, and the resolution time. A transfer that is no longer pending is not found.
+	ResolveOwnershipTransfer(ctx context.Context, q DBExecutor, transfer *domain.OwnershipTransfer) error
+}
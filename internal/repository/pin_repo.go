@@ -0,0 +1,21 @@
+// internal/repository/pin_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// PINRepository defines the interface for the transaction PINs of users.
+type PINRepository interface {
+	GetPIN(ctx context.Context, q DBExecutor, userID int64) (*domain.UserPIN, error)
+	// GetPINForUpdate retrieves a user's PIN and locks it until the end of the transaction, so concurrent
+	// attempts are counted one after another.
+	GetPINForUpdate(ctx context.Context, q DBExecutor, userID int64) (*domain.UserPIN, error)
+	// SavePIN creates or replaces a user's PIN.
+	SavePIN(ctx context.Context, q DBExecutor, pin *domain.UserPIN) error
+	// UpdateAttempts stores the failed attempts, lockout and step-up state of a user's PIN.
+	UpdateAttempts(ctx context.Context, q DBExecutor, pin *domain.UserPIN) error
+	DeletePIN(ctx context.Context, q DBExecutor, userID int64) error
+}
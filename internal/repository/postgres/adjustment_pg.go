@@ -0,0 +1,103 @@
+// internal/repository/postgres/adjustment_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// AdjustmentRepository implements repository.AdjustmentRepository for PostgreSQL.
+type AdjustmentRepository struct{}
+
+// NewAdjustmentRepository creates a new AdjustmentRepository.
+func NewAdjustmentRepository(db *sqlx.DB) repository.AdjustmentRepository {
+	return &AdjustmentRepository{}
+}
+
+// adjustmentSelect projects adjustments together with the public ID of their wallet.
+const adjustmentSelect = `SELECT a.id, a.public_id, a.wallet_id, w.public_id AS wallet_public_id, a.direction, a.amount, a.currency,
+                                 a.reason_code, a.note, a.status, a.proposed_by, a.decided_by, a.decision_note,
+                                 a.transaction_id, a.created_at, a.decided_at
+                          FROM adjustments a
+                          JOIN wallets w ON w.id = a.wallet_id`
+
+// CreateAdjustment stores a new proposed adjustment.
+func (r *AdjustmentRepository) CreateAdjustment(ctx context.Context, q repository.DBExecutor, adjustment *domain.Adjustment) error {
+	query := `INSERT INTO adjustments (public_id, wallet_id, direction, amount, currency, reason_code, note, status, proposed_by, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		adjustment.PublicID,
+		adjustment.WalletID,
+		adjustment.Direction,
+		adjustment.Amount,
+		adjustment.Currency,
+		adjustment.ReasonCode,
+		adjustment.Note,
+		adjustment.Status,
+		adjustment.ProposedBy,
+		adjustment.CreatedAt,
+	).Scan(&adjustment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create adjustment: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetAdjustmentByPublicID retrieves an adjustment by its public UUID.
+func (r *AdjustmentRepository) GetAdjustmentByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Adjustment, error) {
+	return r.getAdjustment(ctx, q, adjustmentSelect+` WHERE a.public_id = $1`, publicID)
+}
+
+// GetAdjustmentForUpdate retrieves an adjustment by its public UUID and locks its row.
+func (r *AdjustmentRepository) GetAdjustmentForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Adjustment, error) {
+	return r.getAdjustment(ctx, q, adjustmentSelect+` WHERE a.public_id = $1 FOR UPDATE OF a`, publicID)
+}
+
+func (r *AdjustmentRepository) getAdjustment(ctx context.Context, q repository.DBExecutor, query string, publicID uuid.UUID) (*domain.Adjustment, error) {
+	var adjustment domain.Adjustment
+	if err := q.GetContext(ctx, &adjustment, query, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get adjustment %s: %w", publicID, translateError(err))
+	}
+	return &adjustment, nil
+}
+
+// ListAdjustments retrieves adjustments, newest first.
+func (r *AdjustmentRepository) ListAdjustments(ctx context.Context, q repository.DBExecutor, status domain.AdjustmentStatus, limit int) ([]domain.Adjustment, error) {
+	var adjustments []domain.Adjustment
+	query := adjustmentSelect + ` WHERE ($1 = '' OR a.status = $1) ORDER BY a.created_at DESC, a.id DESC LIMIT $2`
+	if err := q.SelectContext(ctx, &adjustments, query, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list adjustments: %w", translateError(err))
+	}
+	return adjustments, nil
+}
+
+// UpdateDecision records the decision on a pending adjustment.
+func (r *AdjustmentRepository) UpdateDecision(ctx context.Context, q repository.DBExecutor, adjustment *domain.Adjustment) error {
+	query := `UPDATE adjustments
+              SET status = $1, decided_by = $2, decision_note = $3, transaction_id = $4, decided_at = $5
+              WHERE id = $6 AND status = $7`
+	result, err := q.ExecContext(ctx, query, adjustment.Status, adjustment.DecidedBy, adjustment.DecisionNote,
+		adjustment.TransactionID, adjustment.DecidedAt, adjustment.ID, domain.AdjustmentStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to update adjustment %d: %w", adjustment.ID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after updating adjustment %d: %w", adjustment.ID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// internal/repository/postgres/annotation_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// AnnotationRepository implements repository.AnnotationRepository for PostgreSQL.
+type AnnotationRepository struct{}
+
+// NewAnnotationRepository creates a new AnnotationRepository.
+func NewAnnotationRepository(db *sqlx.DB) repository.AnnotationRepository {
+	return &AnnotationRepository{}
+}
+
+// annotationRow maps a transaction_annotations row; the case references are a Postgres array.
+type annotationRow struct {
+	TransactionID uuid.UUID      `db:"transaction_id"`
+	Note          *string        `db:"note"`
+	CaseIDs       pq.StringArray `db:"case_ids"`
+	UpdatedBy     *string        `db:"updated_by"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+func (row annotationRow) toDomain() *domain.TransactionAnnotation {
+	return &domain.TransactionAnnotation{
+		TransactionID: row.TransactionID,
+		Note:          row.Note,
+		CaseIDs:       []string(row.CaseIDs),
+		UpdatedBy:     row.UpdatedBy,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+	}
+}
+
+const annotationColumns = `transaction_id, note, case_ids, updated_by, created_at, updated_at`
+
+// GetAnnotation retrieves the annotation of a transaction.
+func (r *AnnotationRepository) GetAnnotation(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID) (*domain.TransactionAnnotation, error) {
+	var row annotationRow
+	query := `SELECT ` + annotationColumns + ` FROM transaction_annotations WHERE transaction_id = $1`
+	if err := q.GetContext(ctx, &row, query, transactionID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get annotation of transaction %s: %w", transactionID, translateError(err))
+	}
+	return row.toDomain(), nil
+}
+
+// ApplyAnnotation upserts the annotation in one statement, so concurrent patches touching different fields
+// do not overwrite each other. An empty note is stored as NULL.
+func (r *AnnotationRepository) ApplyAnnotation(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID, patch domain.TransactionAnnotationPatch, now time.Time) (*domain.TransactionAnnotation, error) {
+	var caseIDs pq.StringArray
+	if patch.CaseIDs != nil {
+		caseIDs = pq.StringArray(*patch.CaseIDs)
+	}
+	var row annotationRow
+	query := `INSERT INTO transaction_annotations AS a (transaction_id, note, case_ids, updated_by, created_at, updated_at)
+              VALUES ($1, NULLIF($2, ''), COALESCE($3, '{}'::TEXT[]), $4, $5, $5)
+              ON CONFLICT (transaction_id) DO UPDATE SET
+                  note = CASE WHEN $6 THEN EXCLUDED.note ELSE a.note END,
+                  case_ids = CASE WHEN $7 THEN EXCLUDED.case_ids ELSE a.case_ids END,
+                  updated_by = COALESCE(EXCLUDED.updated_by, a.updated_by),
+                  updated_at = EXCLUDED.updated_at
+              RETURNING ` + annotationColumns
+	err := q.GetContext(ctx, &row, query,
+		transactionID,
+		patch.Note,
+		caseIDs,
+		patch.UpdatedBy,
+		now,
+		patch.Note != nil,
+		patch.CaseIDs != nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save annotation of transaction %s: %w", transactionID, translateError(err))
+	}
+	return row.toDomain(), nil
+}
@@ -0,0 +1,69 @@
+// internal/repository/postgres/append_only_test.go
+package postgres
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// transactionMutation matches SQL changing or removing rows of the transaction tables.
+var transactionMutation = regexp.MustCompile(`(?i)\b(UPDATE|DELETE\s+FROM)\s+transactions(_archive)?\b`)
+
+// pendingSettlement matches the one change allowed: settling a transaction that is still PENDING.
+var pendingSettlement = regexp.MustCompile(`(?i)^UPDATE\s+transactions\s+SET\s+status\s*=\s*'(COMPLETED|FAILED)'[^;]*\bWHERE\s+id\s*=\s*\$1\s+AND\s+status\s*=\s*'PENDING'`)
+
+// TestTransactionsAreAppendOnly keeps the repositories from growing a statement that changes or deletes a
+// transaction, which the database would reject at runtime anyway.
+func TestTransactionsAreAppendOnly(t *testing.T) {
+	t.Run("NoRepositoryMethodMutatesTransactions", func(t *testing.T) {
+		files, err := filepath.Glob("*.go")
+		require.NoError(t, err)
+		fset := token.NewFileSet()
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			parsed, err := parser.ParseFile(fset, file, nil, 0)
+			require.NoError(t, err)
+			ast.Inspect(parsed, func(node ast.Node) bool {
+				literal, ok := node.(*ast.BasicLit)
+				if !ok || literal.Kind != token.STRING {
+					return true
+				}
+				for _, match := range transactionMutation.FindAllStringIndex(literal.Value, -1) {
+					statement := literal.Value[match[0]:]
+					assert.Regexp(t, pendingSettlement, statement, "%s mutates a transaction", fset.Position(literal.Pos()))
+				}
+				return true
+			})
+		}
+	})
+
+	t.Run("TransactionRepositoryHasNoMutators", func(t *testing.T) {
+		methods := reflect.TypeOf((*repository.TransactionRepository)(nil)).Elem()
+		for i := 0; i < methods.NumMethod(); i++ {
+			name := methods.Method(i).Name
+			for _, prefix := range []string{"Update", "Delete", "Set", "Purge", "Remove"} {
+				assert.False(t, strings.HasPrefix(name, prefix), "TransactionRepository.%s looks like a mutation", name)
+			}
+		}
+	})
+
+	t.Run("TriggerRejectionTranslatesToConstraintViolation", func(t *testing.T) {
+		err := translateError(&pq.Error{Code: pgRestrictViolation})
+		assert.ErrorIs(t, err, util.ErrConstraintViolation)
+	})
+}
@@ -0,0 +1,102 @@
+// internal/repository/postgres/async_transfer_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// AsyncTransferRepository implements repository.AsyncTransferRepository for PostgreSQL.
+type AsyncTransferRepository struct{}
+
+// NewAsyncTransferRepository creates a new AsyncTransferRepository.
+func NewAsyncTransferRepository(db *sqlx.DB) repository.AsyncTransferRepository {
+	return &AsyncTransferRepository{}
+}
+
+// CreateAsyncTransfer inserts the options of an accepted transfer.
+func (r *AsyncTransferRepository) CreateAsyncTransfer(ctx context.Context, q repository.DBExecutor, transfer *domain.AsyncTransfer) error {
+	query := `INSERT INTO async_transfers (transaction_id, override_budget, partner_id, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $4) RETURNING id`
+	err := q.QueryRowContext(ctx, query, transfer.TransactionID, transfer.OverrideBudget, transfer.PartnerID, transfer.CreatedAt).Scan(&transfer.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create async transfer of transaction %d: %w", transfer.TransactionID, translateError(err))
+	}
+	transfer.UpdatedAt = transfer.CreatedAt
+	return nil
+}
+
+// ListPendingAsyncTransfers retrieves the oldest accepted transfers whose transaction is still PENDING and that
+// are due, with the transfer's wallets, amount and category joined from the transaction.
+func (r *AsyncTransferRepository) ListPendingAsyncTransfers(ctx context.Context, q repository.DBExecutor, now time.Time, limit int) ([]domain.AsyncTransfer, error) {
+	var transfers []domain.AsyncTransfer
+	query := `SELECT a.id, a.transaction_id, t.public_id AS transaction_public_id, t.from_wallet_id, t.to_wallet_id,
+                     t.amount, t.currency, t.category, a.override_budget, a.partner_id, a.failure_reason, a.attempts,
+                     a.next_attempt_at, a.created_at, a.updated_at
+              FROM async_transfers a JOIN transactions t ON t.id = a.transaction_id
+              WHERE t.status = 'PENDING' AND (a.next_attempt_at IS NULL OR a.next_attempt_at <= $1)
+              ORDER BY a.id
+              LIMIT $2`
+	if err := q.SelectContext(ctx, &transfers, query, now, limit); err != nil {
+		return nil, fmt.Errorf("failed to list pending async transfers: %w", translateError(err))
+	}
+	return transfers, nil
+}
+
+// CompleteAsyncTransfer completes the PENDING transaction. The status condition makes the completion happen
+// once: a second worker making the same transfer waits for the first one's row lock and then finds nothing
+// to complete, which rolls its transfer back.
+func (r *AsyncTransferRepository) CompleteAsyncTransfer(ctx context.Context, q repository.DBExecutor, transaction *domain.Transaction) error {
+	query := `UPDATE transactions SET status = 'COMPLETED', transaction_time = $2, category = $3, promo_amount = $4
+              WHERE id = $1 AND status = 'PENDING'`
+	result, err := q.ExecContext(ctx, query, transaction.ID, transaction.TransactionTime, transaction.Category, transaction.PromoAmount)
+	if err != nil {
+		return fmt.Errorf("failed to complete async transfer of transaction %d: %w", transaction.ID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after completing async transfer of transaction %d: %w", transaction.ID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	transaction.Status = domain.TransactionStatusCompleted
+	return nil
+}
+
+// RetryAsyncTransfer counts the failed attempt and schedules the next one.
+func (r *AsyncTransferRepository) RetryAsyncTransfer(ctx context.Context, q repository.DBExecutor, transactionID int64, nextAttemptAt, at time.Time) error {
+	query := `UPDATE async_transfers SET attempts = attempts + 1, next_attempt_at = $2, updated_at = $3 WHERE transaction_id = $1`
+	if _, err := q.ExecContext(ctx, query, transactionID, nextAttemptAt, at); err != nil {
+		return fmt.Errorf("failed to schedule retry of async transfer of transaction %d: %w", transactionID, translateError(err))
+	}
+	return nil
+}
+
+// FailAsyncTransfer fails the PENDING transaction and records the reason in one statement.
+func (r *AsyncTransferRepository) FailAsyncTransfer(ctx context.Context, q repository.DBExecutor, transactionID int64, reason string, at time.Time) error {
+	query := `WITH failed AS (
+                  UPDATE transactions SET status = 'FAILED' WHERE id = $1 AND status = 'PENDING' RETURNING id
+              )
+              UPDATE async_transfers SET failure_reason = $2, updated_at = $3
+              WHERE transaction_id IN (SELECT id FROM failed)`
+	result, err := q.ExecContext(ctx, query, transactionID, reason, at)
+	if err != nil {
+		return fmt.Errorf("failed to fail async transfer of transaction %d: %w", transactionID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after failing async transfer of transaction %d: %w", transactionID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// internal/repository/postgres/audit_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// auditChainLockClass namespaces the advisory locks of audit chains, apart from walletLockClass.
+const auditChainLockClass = 2
+
+// AuditRepository implements repository.AuditRepository for PostgreSQL.
+type AuditRepository struct{}
+
+// NewAuditRepository creates a new AuditRepository.
+func NewAuditRepository(db *sqlx.DB) repository.AuditRepository {
+	return &AuditRepository{}
+}
+
+// LockChain takes a transaction-scoped advisory lock rather than a row lock, because an empty chain has no
+// row to lock.
+func (r *AuditRepository) LockChain(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	if _, err := q.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2::bigint::bit(32)::int)`, auditChainLockClass, walletID); err != nil {
+		return fmt.Errorf("failed to lock audit chain of wallet %d: %w", walletID, translateError(err))
+	}
+	return nil
+}
+
+// GetChainHead retrieves the wallet's latest event.
+func (r *AuditRepository) GetChainHead(ctx context.Context, q repository.DBExecutor, walletID int64) (*domain.AuditChainHead, error) {
+	var head domain.AuditChainHead
+	query := `SELECT sequence, hash FROM audit_events WHERE wallet_id = $1 ORDER BY sequence DESC LIMIT 1`
+	if err := q.GetContext(ctx, &head, query, walletID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get audit chain head of wallet %d: %w", walletID, translateError(err))
+	}
+	return &head, nil
+}
+
+// AppendEvent inserts the event.
+func (r *AuditRepository) AppendEvent(ctx context.Context, q repository.DBExecutor, event *domain.AuditEvent) error {
+	query := `INSERT INTO audit_events (wallet_id, sequence, event_type, payload, prev_hash, hash, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		event.WalletID,
+		event.Sequence,
+		event.Type,
+		event.Payload,
+		event.PrevHash,
+		event.Hash,
+		event.CreatedAt,
+	).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to append audit event %d of wallet %d: %w", event.Sequence, event.WalletID, translateError(err))
+	}
+	return nil
+}
+
+// ListEventsAfter retrieves the next page of the wallet's events.
+func (r *AuditRepository) ListEventsAfter(ctx context.Context, q repository.DBExecutor, walletID, afterSequence int64, limit int) ([]domain.AuditEvent, error) {
+	events := []domain.AuditEvent{}
+	query := `SELECT id, wallet_id, sequence, event_type, payload, prev_hash, hash, created_at
+              FROM audit_events
+              WHERE wallet_id = $1 AND sequence > $2
+              ORDER BY sequence
+              LIMIT $3`
+	if err := q.SelectContext(ctx, &events, query, walletID, afterSequence, limit); err != nil {
+		return nil, fmt.Errorf("failed to list audit events of wallet %d: %w", walletID, translateError(err))
+	}
+	return events, nil
+}
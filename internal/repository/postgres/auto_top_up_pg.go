@@ -0,0 +1,194 @@
+// internal/repository/postgres/auto_top_up_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// AutoTopUpRepository implements repository.AutoTopUpRepository for PostgreSQL.
+type AutoTopUpRepository struct{}
+
+// NewAutoTopUpRepository creates a new AutoTopUpRepository.
+func NewAutoTopUpRepository(db *sqlx.DB) repository.AutoTopUpRepository {
+	return &AutoTopUpRepository{}
+}
+
+// fundingSourceSelect projects funding sources together with the public ID of their wallet.
+const fundingSourceSelect = `SELECT s.id, s.wallet_id, w.public_id AS wallet_public_id, s.provider, s.reference, s.label, s.created_at
+                             FROM funding_sources s
+                             JOIN wallets w ON w.id = s.wallet_id`
+
+// autoTopUpRuleSelect projects auto top-up rules together with their wallet and the gateway reference of their
+// funding source, everything needed to execute them.
+const autoTopUpRuleSelect = `SELECT r.wallet_id, w.public_id AS wallet_public_id, w.currency, r.funding_source_id,
+                                    s.provider, s.reference, r.threshold, r.amount, r.enabled, r.consecutive_failures,
+                                    r.last_attempt_at, r.last_error, r.created_at, r.updated_at
+                             FROM auto_top_up_rules r
+                             JOIN wallets w ON w.id = r.wallet_id
+                             JOIN funding_sources s ON s.id = r.funding_source_id`
+
+// CreateFundingSource links a new funding source to a wallet using the provided DBExecutor.
+func (r *AutoTopUpRepository) CreateFundingSource(ctx context.Context, q repository.DBExecutor, source *domain.FundingSource) error {
+	query := `INSERT INTO funding_sources (wallet_id, provider, reference, label, created_at)
+              VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		source.WalletID,
+		source.Provider,
+		source.Reference,
+		source.Label,
+		source.CreatedAt,
+	).Scan(&source.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create funding source: %w", translateError(err))
+	}
+	return nil
+}
+
+// ListFundingSources retrieves all funding sources of a wallet.
+func (r *AutoTopUpRepository) ListFundingSources(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.FundingSource, error) {
+	var sources []domain.FundingSource
+	query := fundingSourceSelect + ` WHERE s.wallet_id = $1 ORDER BY s.id`
+	if err := q.SelectContext(ctx, &sources, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to list funding sources for wallet %d: %w", walletID, translateError(err))
+	}
+	return sources, nil
+}
+
+// GetFundingSource retrieves a wallet's funding source; it returns util.ErrNotFound if the wallet has no such source.
+func (r *AutoTopUpRepository) GetFundingSource(ctx context.Context, q repository.DBExecutor, walletID, sourceID int64) (*domain.FundingSource, error) {
+	var source domain.FundingSource
+	query := fundingSourceSelect + ` WHERE s.id = $1 AND s.wallet_id = $2`
+	if err := q.GetContext(ctx, &source, query, sourceID, walletID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get funding source %d: %w", sourceID, translateError(err))
+	}
+	return &source, nil
+}
+
+// DeleteFundingSource unlinks a wallet's funding source. A source still used by the wallet's auto top-up rule
+// fails the foreign key, which translateError reports as util.ErrReferenceViolation.
+func (r *AutoTopUpRepository) DeleteFundingSource(ctx context.Context, q repository.DBExecutor, walletID, sourceID int64) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM funding_sources WHERE id = $1 AND wallet_id = $2`, sourceID, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to delete funding source %d: %w", sourceID, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting funding source %d: %w", sourceID, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// GetRule retrieves a wallet's auto top-up rule; it returns util.ErrNotFound if the wallet has none.
+func (r *AutoTopUpRepository) GetRule(ctx context.Context, q repository.DBExecutor, walletID int64) (*domain.AutoTopUpRule, error) {
+	var rule domain.AutoTopUpRule
+	if err := q.GetContext(ctx, &rule, autoTopUpRuleSelect+` WHERE r.wallet_id = $1`, walletID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get auto top-up rule of wallet %d: %w", walletID, translateError(err))
+	}
+	return &rule, nil
+}
+
+// SaveRule creates or replaces a wallet's auto top-up rule. Replacing a rule re-enables it and clears its
+// failures, but keeps the last attempt so the cooldown still applies.
+func (r *AutoTopUpRepository) SaveRule(ctx context.Context, q repository.DBExecutor, rule *domain.AutoTopUpRule) error {
+	query := `INSERT INTO auto_top_up_rules (wallet_id, funding_source_id, threshold, amount, enabled, consecutive_failures, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, TRUE, 0, $5, $6)
+              ON CONFLICT (wallet_id) DO UPDATE SET
+                  funding_source_id = EXCLUDED.funding_source_id,
+                  threshold = EXCLUDED.threshold,
+                  amount = EXCLUDED.amount,
+                  enabled = TRUE,
+                  consecutive_failures = 0,
+                  last_error = NULL,
+                  updated_at = EXCLUDED.updated_at
+              RETURNING created_at, last_attempt_at`
+	err := q.QueryRowContext(ctx, query,
+		rule.WalletID,
+		rule.FundingSourceID,
+		rule.Threshold,
+		rule.Amount,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	).Scan(&rule.CreatedAt, &rule.LastAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to save auto top-up rule of wallet %d: %w", rule.WalletID, translateError(err))
+	}
+	rule.Enabled = true
+	rule.ConsecutiveFailures = 0
+	rule.LastError = nil
+	return nil
+}
+
+// DeleteRule removes a wallet's auto top-up rule; it returns util.ErrNotFound if the wallet has none.
+func (r *AutoTopUpRepository) DeleteRule(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM auto_top_up_rules WHERE wallet_id = $1`, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to delete auto top-up rule of wallet %d: %w", walletID, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting auto top-up rule of wallet %d: %w", walletID, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// ListDueRules retrieves the enabled rules of live wallets below their threshold that are out of their cooldown.
+func (r *AutoTopUpRepository) ListDueRules(ctx context.Context, q repository.DBExecutor, attemptedBefore time.Time) ([]domain.AutoTopUpRule, error) {
+	var rules []domain.AutoTopUpRule
+	query := autoTopUpRuleSelect + `
+              WHERE r.enabled AND w.deleted_at IS NULL AND ` + walletBalanceOf("w") + ` < r.threshold
+                AND (r.last_attempt_at IS NULL OR r.last_attempt_at < $1)
+              ORDER BY r.wallet_id`
+	if err := q.SelectContext(ctx, &rules, query, attemptedBefore); err != nil {
+		return nil, fmt.Errorf("failed to list due auto top-up rules: %w", translateError(err))
+	}
+	return rules, nil
+}
+
+// ClaimRule records an attempt of the rule at now if it is still enabled and out of its cooldown.
+func (r *AutoTopUpRepository) ClaimRule(ctx context.Context, q repository.DBExecutor, walletID int64, now, attemptedBefore time.Time) error {
+	query := `UPDATE auto_top_up_rules SET last_attempt_at = $2, updated_at = $2
+              WHERE wallet_id = $1 AND enabled AND (last_attempt_at IS NULL OR last_attempt_at < $3)`
+	result, err := q.ExecContext(ctx, query, walletID, now, attemptedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to claim auto top-up rule of wallet %d: %w", walletID, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after claiming auto top-up rule of wallet %d: %w", walletID, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// RecordOutcome stores the outcome of the rule's last attempt.
+func (r *AutoTopUpRepository) RecordOutcome(ctx context.Context, q repository.DBExecutor, walletID int64, consecutiveFailures int, lastError *string, enabled bool) error {
+	query := `UPDATE auto_top_up_rules SET consecutive_failures = $2, last_error = $3, enabled = $4, updated_at = NOW()
+              WHERE wallet_id = $1`
+	if _, err := q.ExecContext(ctx, query, walletID, consecutiveFailures, lastError, enabled); err != nil {
+		return fmt.Errorf("failed to record auto top-up outcome of wallet %d: %w", walletID, translateError(err))
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// internal/repository/postgres/balance_shard_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// walletBalanceOf is the balance of the wallets row named, or aliased, table: its own balance plus those of
+// its shards, if it is sharded.
+func walletBalanceOf(table string) string {
+	return fmt.Sprintf(`(%[1]s.balance + COALESCE((SELECT SUM(bs.balance) FROM wallet_balance_shards bs WHERE bs.wallet_id = %[1]s.id), 0))`, table)
+}
+
+// walletVersionOf is the version of the wallets row named table, which every balance update of the row or of
+// one of its shards increments.
+func walletVersionOf(table string) string {
+	return fmt.Sprintf(`(%[1]s.version + COALESCE((SELECT SUM(bs.version) FROM wallet_balance_shards bs WHERE bs.wallet_id = %[1]s.id), 0))`, table)
+}
+
+// walletLatestOf is the latest of column of the wallets row named table and updated_at of its shards.
+func walletLatestOf(table, column string) string {
+	return fmt.Sprintf(`GREATEST(%[1]s.%[2]s, (SELECT MAX(bs.updated_at) FROM wallet_balance_shards bs WHERE bs.wallet_id = %[1]s.id))`, table, column)
+}
+
+// walletColumns is the select list of a domain.Wallet read from the wallets row named table, with the balance,
+// version, update time and last activity of a sharded wallet taken over its shards.
+func walletColumns(table string) string {
+	columns := []string{
+		table + ".id", table + ".public_id", table + ".user_id", table + ".currency",
+		walletBalanceOf(table) + " AS balance",
+		table + ".kind",
+		walletVersionOf(table) + " AS version",
+		table + ".created_at",
+		walletLatestOf(table, "updated_at") + " AS updated_at",
+		walletLatestOf(table, "last_activity_at") + " AS last_activity_at",
+		table + ".dormant_since", table + ".frozen_at", table + ".ownership_frozen_at",
+	}
+	return strings.Join(columns, ", ")
+}
+
+// BalanceShardRepository implements repository.BalanceShardRepository for PostgreSQL.
+type BalanceShardRepository struct{}
+
+// NewBalanceShardRepository creates a new BalanceShardRepository.
+func NewBalanceShardRepository(db *sqlx.DB) repository.BalanceShardRepository {
+	return &BalanceShardRepository{}
+}
+
+// ListShards retrieves the shards of every sharded live wallet, by wallet and shard.
+func (r *BalanceShardRepository) ListShards(ctx context.Context, q repository.DBExecutor) ([]domain.BalanceShard, error) {
+	var shards []domain.BalanceShard
+	query := `SELECT s.wallet_id, w.public_id AS wallet_public_id, s.shard, s.balance, s.version, s.updated_at
+              FROM wallet_balance_shards s JOIN wallets w ON w.id = s.wallet_id
+              WHERE w.deleted_at IS NULL
+              ORDER BY s.wallet_id, s.shard`
+	if err := q.SelectContext(ctx, &shards, query); err != nil {
+		return nil, fmt.Errorf("failed to list balance shards: %w", translateError(err))
+	}
+	return shards, nil
+}
+
+// updateShardBalanceQuery picks the shard with SKIP LOCKED, so concurrent movements of the wallet spread over
+// its shards instead of queueing on one. Joining the wallet's own row reads it without locking it. The
+// subqueries of RETURNING see the other shards as of the start of the statement.
+const updateShardBalanceQuery = `UPDATE wallet_balance_shards AS s SET balance = s.balance + $4, version = s.version + 1, updated_at = $5
+              FROM wallets w
+              WHERE w.id = s.wallet_id AND s.wallet_id = $1 AND s.shard = (
+                  SELECT shard FROM wallet_balance_shards
+                  WHERE wallet_id = $1 AND balance + $4 >= 0
+                  ORDER BY (shard + $3 - $2) % $3, shard
+                  LIMIT 1 FOR UPDATE SKIP LOCKED)
+              RETURNING s.wallet_id AS id,
+                        s.balance + w.balance + COALESCE((SELECT SUM(o.balance) FROM wallet_balance_shards o WHERE o.wallet_id = s.wallet_id AND o.shard <> s.shard), 0) AS balance,
+                        s.version + w.version + COALESCE((SELECT SUM(o.version) FROM wallet_balance_shards o WHERE o.wallet_id = s.wallet_id AND o.shard <> s.shard), 0) AS version,
+                        s.updated_at`
+
+// UpdateShardBalance adds amount to a shard of the wallet, dated at, and returns the wallet's new balance.
+func (r *BalanceShardRepository) UpdateShardBalance(ctx context.Context, q repository.DBExecutor, walletID int64, start, shards int, amount decimal.Decimal, at time.Time) (*domain.WalletBalance, error) {
+	var balance domain.WalletBalance
+	err := q.GetContext(ctx, &balance, updateShardBalanceQuery, walletID, start, shards, amount, at)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update a balance shard of wallet %d: %w", walletID, translateError(err))
+	}
+	return &balance, nil
+}
+
+// LockShards locks the wallet's row, then its shards in shard order.
+func (r *BalanceShardRepository) LockShards(ctx context.Context, q repository.DBExecutor, walletID int64) (decimal.Decimal, []domain.BalanceShard, error) {
+	var own decimal.Decimal
+	if err := q.GetContext(ctx, &own, `SELECT balance FROM wallets WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, walletID); err != nil {
+		if err == sql.ErrNoRows {
+			return decimal.Zero, nil, util.ErrNotFound
+		}
+		return decimal.Zero, nil, fmt.Errorf("failed to lock wallet %d: %w", walletID, translateError(err))
+	}
+	var shards []domain.BalanceShard
+	query := `SELECT wallet_id, shard, balance, version, updated_at FROM wallet_balance_shards
+              WHERE wallet_id = $1 ORDER BY shard FOR UPDATE`
+	if err := q.SelectContext(ctx, &shards, query, walletID); err != nil {
+		return decimal.Zero, nil, fmt.Errorf("failed to lock balance shards of wallet %d: %w", walletID, translateError(err))
+	}
+	return own, shards, nil
+}
+
+// ResizeShards adds and removes shards of the wallet.
+func (r *BalanceShardRepository) ResizeShards(ctx context.Context, q repository.DBExecutor, walletID int64, shards int) error {
+	fold := `WITH removed AS (DELETE FROM wallet_balance_shards WHERE wallet_id = $1 AND shard >= $2 RETURNING balance, version)
+             UPDATE wallets SET balance = balance + (SELECT COALESCE(SUM(balance), 0) FROM removed),
+                                version = version + (SELECT COALESCE(SUM(version), 0) FROM removed)
+             WHERE id = $1 AND EXISTS (SELECT 1 FROM removed)`
+	if _, err := q.ExecContext(ctx, fold, walletID, shards); err != nil {
+		return fmt.Errorf("failed to remove balance shards of wallet %d: %w", walletID, translateError(err))
+	}
+	add := `INSERT INTO wallet_balance_shards (wallet_id, shard, updated_at)
+            SELECT $1, shard, $3 FROM generate_series(0, $2 - 1) AS shard
+            ON CONFLICT (wallet_id, shard) DO NOTHING`
+	if _, err := q.ExecContext(ctx, add, walletID, shards, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to add balance shards of wallet %d: %w", walletID, translateError(err))
+	}
+	return nil
+}
+
+// SetShardBalances sets the balances of the wallet and its shards.
+func (r *BalanceShardRepository) SetShardBalances(ctx context.Context, q repository.DBExecutor, walletID int64, own decimal.Decimal, shards []decimal.Decimal, lastActivity time.Time) error {
+	balances := make([]string, len(shards))
+	for i, balance := range shards {
+		balances[i] = balance.String()
+	}
+	query := `UPDATE wallet_balance_shards AS s SET balance = c.balance
+              FROM unnest($2::numeric[]) WITH ORDINALITY AS c(balance, n)
+              WHERE s.wallet_id = $1 AND s.shard = c.n - 1`
+	if _, err := q.ExecContext(ctx, query, walletID, pq.Array(balances)); err != nil {
+		return fmt.Errorf("failed to set balance shards of wallet %d: %w", walletID, translateError(err))
+	}
+	query = `UPDATE wallets SET balance = $2, last_activity_at = GREATEST(last_activity_at, $3) WHERE id = $1`
+	if _, err := q.ExecContext(ctx, query, walletID, own, lastActivity); err != nil {
+		return fmt.Errorf("failed to set own balance of sharded wallet %d: %w", walletID, translateError(err))
+	}
+	return nil
+}
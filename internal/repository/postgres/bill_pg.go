@@ -0,0 +1,185 @@
+// internal/repository/postgres/bill_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// BillRepository implements repository.BillRepository for PostgreSQL.
+type BillRepository struct{}
+
+// NewBillRepository creates a new BillRepository.
+func NewBillRepository(db *sqlx.DB) repository.BillRepository {
+	return &BillRepository{}
+}
+
+// billSelect projects bills together with the public ID of the owner wallet.
+const billSelect = `SELECT b.id, b.public_id, b.owner_wallet_id, w.public_id AS owner_wallet_public_id, b.amount,
+                           b.currency, b.description, b.status, b.created_at, b.updated_at
+                    FROM bills b
+                    JOIN wallets w ON w.id = b.owner_wallet_id`
+
+// participantSelect projects bill participants together with the public IDs of the bill and the wallet.
+const participantSelect = `SELECT p.bill_id, b.public_id AS bill_public_id, p.wallet_id, w.public_id AS wallet_public_id,
+                                  p.share_amount, p.paid_amount, p.status, p.approved_at, p.last_reminded_at, p.updated_at
+                           FROM bill_participants p
+                           JOIN bills b ON b.id = p.bill_id
+                           JOIN wallets w ON w.id = p.wallet_id`
+
+// CreateBill stores a new bill together with its participants. It must be called with a transactional DBExecutor.
+func (r *BillRepository) CreateBill(ctx context.Context, q repository.DBExecutor, bill *domain.Bill) error {
+	query := `INSERT INTO bills (public_id, owner_wallet_id, amount, currency, description, status, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		bill.PublicID,
+		bill.OwnerWalletID,
+		bill.Amount,
+		bill.Currency,
+		bill.Description,
+		bill.Status,
+		bill.CreatedAt,
+		bill.UpdatedAt,
+	).Scan(&bill.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create bill: %w", translateError(err))
+	}
+
+	participantQuery := `INSERT INTO bill_participants (bill_id, wallet_id, share_amount, paid_amount, status, updated_at)
+                         VALUES ($1, $2, $3, $4, $5, $6)`
+	for i := range bill.Participants {
+		participant := &bill.Participants[i]
+		participant.BillID = bill.ID
+		_, err := q.ExecContext(ctx, participantQuery,
+			participant.BillID,
+			participant.WalletID,
+			participant.ShareAmount,
+			participant.PaidAmount,
+			participant.Status,
+			participant.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add wallet %d to bill: %w", participant.WalletID, translateError(err))
+		}
+	}
+	return nil
+}
+
+// GetBillByPublicID retrieves a bill and its participants by the bill's public UUID.
+func (r *BillRepository) GetBillByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Bill, error) {
+	return r.getBill(ctx, q, billSelect+` WHERE b.public_id = $1`, publicID)
+}
+
+// GetBillByPublicIDForUpdate retrieves a bill and its participants and locks the bill row until the surrounding
+// transaction ends. Participants are only changed with their bill locked, so the bill lock covers them.
+// It must be called with a transactional DBExecutor.
+func (r *BillRepository) GetBillByPublicIDForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Bill, error) {
+	return r.getBill(ctx, q, billSelect+` WHERE b.public_id = $1 FOR UPDATE OF b`, publicID)
+}
+
+func (r *BillRepository) getBill(ctx context.Context, q repository.DBExecutor, query string, publicID uuid.UUID) (*domain.Bill, error) {
+	var bill domain.Bill
+	if err := q.GetContext(ctx, &bill, query, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get bill %s: %w", publicID, translateError(err))
+	}
+	participantQuery := participantSelect + ` WHERE p.bill_id = $1 ORDER BY p.wallet_id`
+	if err := q.SelectContext(ctx, &bill.Participants, participantQuery, bill.ID); err != nil {
+		return nil, fmt.Errorf("failed to get participants of bill %s: %w", publicID, translateError(err))
+	}
+	return &bill, nil
+}
+
+// ListBillsByWallet retrieves the bills a wallet owns or takes part in, newest first.
+func (r *BillRepository) ListBillsByWallet(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.Bill, error) {
+	var bills []domain.Bill
+	query := billSelect + `
+              WHERE b.owner_wallet_id = $1
+                 OR EXISTS (SELECT 1 FROM bill_participants p WHERE p.bill_id = b.id AND p.wallet_id = $1)
+              ORDER BY b.created_at DESC, b.id DESC`
+	if err := q.SelectContext(ctx, &bills, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to list bills of wallet %d: %w", walletID, translateError(err))
+	}
+	if len(bills) == 0 {
+		return bills, nil
+	}
+
+	billIDs := make([]int64, len(bills))
+	index := make(map[int64]int, len(bills))
+	for i, bill := range bills {
+		billIDs[i] = bill.ID
+		index[bill.ID] = i
+	}
+	var participants []domain.BillParticipant
+	participantQuery := participantSelect + ` WHERE p.bill_id = ANY($1) ORDER BY p.bill_id, p.wallet_id`
+	if err := q.SelectContext(ctx, &participants, participantQuery, pq.Int64Array(billIDs)); err != nil {
+		return nil, fmt.Errorf("failed to list bill participants of wallet %d: %w", walletID, translateError(err))
+	}
+	for _, participant := range participants {
+		i := index[participant.BillID]
+		bills[i].Participants = append(bills[i].Participants, participant)
+	}
+	return bills, nil
+}
+
+// UpdateBillStatus stores the status of a bill.
+func (r *BillRepository) UpdateBillStatus(ctx context.Context, q repository.DBExecutor, bill *domain.Bill) error {
+	query := `UPDATE bills SET status = $1, updated_at = $2 WHERE id = $3`
+	if _, err := q.ExecContext(ctx, query, bill.Status, bill.UpdatedAt, bill.ID); err != nil {
+		return fmt.Errorf("failed to update bill %s: %w", bill.PublicID, translateError(err))
+	}
+	return nil
+}
+
+// UpdateParticipant stores the paid amount, status and approval time of a participant.
+func (r *BillRepository) UpdateParticipant(ctx context.Context, q repository.DBExecutor, participant *domain.BillParticipant) error {
+	query := `UPDATE bill_participants SET paid_amount = $1, status = $2, approved_at = $3, updated_at = $4
+              WHERE bill_id = $5 AND wallet_id = $6`
+	_, err := q.ExecContext(ctx, query,
+		participant.PaidAmount,
+		participant.Status,
+		participant.ApprovedAt,
+		participant.UpdatedAt,
+		participant.BillID,
+		participant.WalletID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update participant %d of bill %d: %w", participant.WalletID, participant.BillID, translateError(err))
+	}
+	return nil
+}
+
+// ListDueReminders retrieves the participants of open bills who still owe money and were not reminded
+// (or, if never reminded, added to the bill) since the given time.
+func (r *BillRepository) ListDueReminders(ctx context.Context, q repository.DBExecutor, since time.Time) ([]domain.BillParticipant, error) {
+	var participants []domain.BillParticipant
+	query := participantSelect + `
+              WHERE b.status = 'OPEN' AND p.status <> 'PAID'
+                AND COALESCE(p.last_reminded_at, b.created_at) < $1
+              ORDER BY p.bill_id, p.wallet_id`
+	if err := q.SelectContext(ctx, &participants, query, since); err != nil {
+		return nil, fmt.Errorf("failed to list due bill reminders: %w", translateError(err))
+	}
+	return participants, nil
+}
+
+// MarkReminded records that a participant was reminded at the given time.
+func (r *BillRepository) MarkReminded(ctx context.Context, q repository.DBExecutor, billID, walletID int64, at time.Time) error {
+	query := `UPDATE bill_participants SET last_reminded_at = $1 WHERE bill_id = $2 AND wallet_id = $3`
+	if _, err := q.ExecContext(ctx, query, at, billID, walletID); err != nil {
+		return fmt.Errorf("failed to mark participant %d of bill %d reminded: %w", walletID, billID, translateError(err))
+	}
+	return nil
+}
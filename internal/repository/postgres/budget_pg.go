@@ -0,0 +1,72 @@
+// internal/repository/postgres/budget_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// BudgetRepository implements repository.BudgetRepository for PostgreSQL.
+type BudgetRepository struct{}
+
+// NewBudgetRepository creates a new BudgetRepository.
+func NewBudgetRepository(db *sqlx.DB) repository.BudgetRepository {
+	return &BudgetRepository{}
+}
+
+// CreateBudget adds a new budget using the provided DBExecutor.
+func (r *BudgetRepository) CreateBudget(ctx context.Context, q repository.DBExecutor, budget *domain.Budget) error {
+	query := `INSERT INTO budgets (wallet_id, category, period, amount_limit, enforcement, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		budget.WalletID,
+		budget.Category,
+		budget.Period,
+		budget.Limit,
+		budget.Enforcement,
+		budget.CreatedAt,
+		budget.UpdatedAt,
+	).Scan(&budget.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create budget: %w", translateError(err))
+	}
+	return nil
+}
+
+// ListBudgetsByWalletID retrieves all budgets of a wallet, with the time zone of its owner.
+func (r *BudgetRepository) ListBudgetsByWalletID(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.Budget, error) {
+	var budgets []domain.Budget
+	query := `SELECT b.id, b.wallet_id, w.public_id AS wallet_public_id, b.category, b.period, b.amount_limit,
+                     b.enforcement, w.user_id AS owner_id, u.timezone AS owner_timezone, b.created_at, b.updated_at
+              FROM budgets b
+              JOIN wallets w ON w.id = b.wallet_id
+              JOIN users u ON u.id = w.user_id
+              WHERE b.wallet_id = $1
+              ORDER BY b.id`
+	if err := q.SelectContext(ctx, &budgets, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to list budgets for wallet %d: %w", walletID, translateError(err))
+	}
+	return budgets, nil
+}
+
+// DeleteBudget removes a wallet's budget; it returns util.ErrNotFound if the wallet has no such budget.
+func (r *BudgetRepository) DeleteBudget(ctx context.Context, q repository.DBExecutor, walletID, budgetID int64) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM budgets WHERE id = $1 AND wallet_id = $2`, budgetID, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget %d: %w", budgetID, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting budget %d: %w", budgetID, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
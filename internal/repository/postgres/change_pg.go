@@ -0,0 +1,61 @@
+// internal/repository/postgres/change_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// ChangeRepository implements repository.ChangeRepository for PostgreSQL.
+type ChangeRepository struct{}
+
+// NewChangeRepository creates a new ChangeRepository.
+func NewChangeRepository(db *sqlx.DB) repository.ChangeRepository {
+	return &ChangeRepository{}
+}
+
+// ListWalletChanges retrieves up to limit wallets changed after since, in change order. Soft-deleted wallets
+// are included with their deleted_at, so clients learn about the deletion. Filter.SettleDelay is
+// subtracted from clock_timestamp(), the clock that stamps changed_at with every change sequence value.
+func (r *ChangeRepository) ListWalletChanges(ctx context.Context, q repository.DBExecutor, since int64, filter repository.ChangeFilter, limit int) ([]domain.WalletChange, error) {
+	var changes []domain.WalletChange
+	args := []any{since, filter.SettleDelay.Seconds(), limit}
+	query := `SELECT id, public_id, user_id, currency, ` + walletBalanceOf("wallets") + ` AS balance, kind, ` + walletVersionOf("wallets") + ` AS version, created_at, updated_at, deleted_at, change_seq
+              FROM wallets
+              WHERE change_seq > $1 AND changed_at < clock_timestamp() - make_interval(secs => $2)`
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		query += ` AND user_id = $4`
+	}
+	query += ` ORDER BY change_seq LIMIT $3`
+	if err := q.SelectContext(ctx, &changes, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list wallet changes: %w", translateError(err))
+	}
+	return changes, nil
+}
+
+// ListTransactionChanges retrieves up to limit transactions changed after since, in change order.
+// Filter.SettleDelay applies to changed_at rather than created_at, which the settlement of a PENDING
+// transaction keeps while it takes a new change sequence value.
+func (r *ChangeRepository) ListTransactionChanges(ctx context.Context, q repository.DBExecutor, since int64, filter repository.ChangeFilter, limit int) ([]domain.TransactionChange, error) {
+	var changes []domain.TransactionChange
+	args := []any{since, filter.SettleDelay.Seconds(), limit}
+	query := `SELECT t.*, fw.public_id AS from_wallet_public_id, tw.public_id AS to_wallet_public_id
+              FROM (SELECT ` + transactionColumns + `, change_seq FROM transactions WHERE change_seq > $1 AND changed_at < clock_timestamp() - make_interval(secs => $2)) t
+              LEFT JOIN wallets fw ON fw.id = t.from_wallet_id
+              LEFT JOIN wallets tw ON tw.id = t.to_wallet_id`
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		query += ` WHERE fw.user_id = $4 OR tw.user_id = $4`
+	}
+	query += ` ORDER BY t.change_seq LIMIT $3`
+	if err := q.SelectContext(ctx, &changes, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list transaction changes: %w", translateError(err))
+	}
+	return changes, nil
+}
@@ -0,0 +1,59 @@
+// internal/repository/postgres/change_pg_test.go
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+func TestChangeRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := &ChangeRepository{}
+	before := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	userID := int64(7)
+
+	t.Run("ListTransactionChangesHoldsBackByChangeTime", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		publicID, walletPublicID := uuid.New(), uuid.New()
+		mock.ExpectQuery(`SELECT t.*, fw.public_id AS from_wallet_public_id, tw.public_id AS to_wallet_public_id
+              FROM (SELECT `+transactionColumns+`, change_seq FROM transactions WHERE change_seq > $1 AND changed_at < clock_timestamp() - make_interval(secs => $2)) t
+              LEFT JOIN wallets fw ON fw.id = t.from_wallet_id
+              LEFT JOIN wallets tw ON tw.id = t.to_wallet_id WHERE fw.user_id = $4 OR tw.user_id = $4 ORDER BY t.change_seq LIMIT $3`).
+			WithArgs(int64(40), 2.0, 10, userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "public_id", "from_wallet_id", "to_wallet_id", "amount", "currency", "type", "status",
+				"transaction_time", "description", "category", "parent_transaction_id", "promo_amount", "created_at", "change_seq",
+				"from_wallet_public_id", "to_wallet_public_id"}).
+				AddRow(5, publicID.String(), 3, nil, "10.0000", "USD", "WITHDRAWAL", "COMPLETED", before, nil, nil, nil, "0",
+					before.Add(-time.Hour), 42, walletPublicID.String(), nil))
+
+		changes, err := repo.ListTransactionChanges(ctx, database, 40, repository.ChangeFilter{UserID: &userID, SettleDelay: 2 * time.Second}, 10)
+
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, int64(42), changes[0].Seq)
+		assert.Equal(t, domain.TransactionStatusCompleted, changes[0].Status)
+	})
+
+	t.Run("ListWalletChangesHoldsBackByChangeTime", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(`SELECT id, public_id, user_id, currency, `+walletBalanceOf("wallets")+` AS balance, kind, `+walletVersionOf("wallets")+` AS version, created_at, updated_at, deleted_at, change_seq
+              FROM wallets
+              WHERE change_seq > $1 AND changed_at < clock_timestamp() - make_interval(secs => $2) ORDER BY change_seq LIMIT $3`).
+			WithArgs(int64(0), 2.0, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"change_seq"}))
+
+		changes, err := repo.ListWalletChanges(ctx, database, 0, repository.ChangeFilter{SettleDelay: 2 * time.Second}, 10)
+
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
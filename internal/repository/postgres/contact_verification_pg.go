@@ -0,0 +1,96 @@
+// internal/repository/postgres/contact_verification_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// ContactVerificationRepository implements repository.ContactVerificationRepository for PostgreSQL.
+type ContactVerificationRepository struct{}
+
+// NewContactVerificationRepository creates a new ContactVerificationRepository.
+func NewContactVerificationRepository(db *sqlx.DB) repository.ContactVerificationRepository {
+	return &ContactVerificationRepository{}
+}
+
+const contactVerificationColumns = `user_id, channel, target_hash, code_hash, failed_attempts, expires_at, created_at`
+
+// GetVerification retrieves the pending verification of a user and channel.
+func (r *ContactVerificationRepository) GetVerification(ctx context.Context, q repository.DBExecutor, userID int64, channel domain.ContactChannel) (*domain.ContactVerification, error) {
+	query := `SELECT ` + contactVerificationColumns + ` FROM contact_verifications WHERE user_id = $1 AND channel = $2`
+	return r.getVerification(ctx, q, query, userID, channel)
+}
+
+// GetVerificationForUpdate retrieves the pending verification of a user and channel and locks its row.
+func (r *ContactVerificationRepository) GetVerificationForUpdate(ctx context.Context, q repository.DBExecutor, userID int64, channel domain.ContactChannel) (*domain.ContactVerification, error) {
+	query := `SELECT ` + contactVerificationColumns + ` FROM contact_verifications WHERE user_id = $1 AND channel = $2 FOR UPDATE`
+	return r.getVerification(ctx, q, query, userID, channel)
+}
+
+func (r *ContactVerificationRepository) getVerification(ctx context.Context, q repository.DBExecutor, query string, userID int64, channel domain.ContactChannel) (*domain.ContactVerification, error) {
+	var verification domain.ContactVerification
+	if err := q.GetContext(ctx, &verification, query, userID, channel); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get %s verification of user %d: %w", channel, userID, translateError(err))
+	}
+	return &verification, nil
+}
+
+// SaveVerification creates or replaces the pending verification of a user and channel, clearing its failed attempts.
+func (r *ContactVerificationRepository) SaveVerification(ctx context.Context, q repository.DBExecutor, verification *domain.ContactVerification) error {
+	query := `INSERT INTO contact_verifications (user_id, channel, target_hash, code_hash, failed_attempts, expires_at, created_at)
+              VALUES ($1, $2, $3, $4, 0, $5, $6)
+              ON CONFLICT (user_id, channel) DO UPDATE
+              SET target_hash = EXCLUDED.target_hash, code_hash = EXCLUDED.code_hash, failed_attempts = 0,
+                  expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at`
+	_, err := q.ExecContext(ctx, query, verification.UserID, verification.Channel, verification.TargetHash, verification.CodeHash,
+		verification.ExpiresAt, verification.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save %s verification of user %d: %w", verification.Channel, verification.UserID, translateError(err))
+	}
+	verification.FailedAttempts = 0
+	return nil
+}
+
+// UpdateAttempts stores the failed attempts of a pending verification.
+func (r *ContactVerificationRepository) UpdateAttempts(ctx context.Context, q repository.DBExecutor, verification *domain.ContactVerification) error {
+	query := `UPDATE contact_verifications SET failed_attempts = $3 WHERE user_id = $1 AND channel = $2`
+	result, err := q.ExecContext(ctx, query, verification.UserID, verification.Channel, verification.FailedAttempts)
+	if err != nil {
+		return fmt.Errorf("failed to update %s verification attempts of user %d: %w", verification.Channel, verification.UserID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after updating %s verification attempts of user %d: %w", verification.Channel, verification.UserID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteVerification removes the pending verification of a user and channel.
+func (r *ContactVerificationRepository) DeleteVerification(ctx context.Context, q repository.DBExecutor, userID int64, channel domain.ContactChannel) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM contact_verifications WHERE user_id = $1 AND channel = $2`, userID, channel)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s verification of user %d: %w", channel, userID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting %s verification of user %d: %w", channel, userID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+// internal/repository/postgres/crypto_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// CryptoRepository implements repository.CryptoRepository for PostgreSQL.
+type CryptoRepository struct{}
+
+// NewCryptoRepository creates a new CryptoRepository.
+func NewCryptoRepository(db *sqlx.DB) repository.CryptoRepository {
+	return &CryptoRepository{}
+}
+
+// cryptoAddressSelect projects deposit addresses together with the public ID of their wallet.
+const cryptoAddressSelect = `SELECT a.id, a.wallet_id, w.public_id AS wallet_public_id, a.asset, a.network, a.address, a.created_at
+                             FROM crypto_addresses a
+                             JOIN wallets w ON w.id = a.wallet_id`
+
+// cryptoDepositColumns are the columns of crypto_deposits, qualified for use after cryptoDepositSelect and in RETURNING.
+const cryptoDepositColumns = `d.id, d.wallet_id, d.asset, d.network, d.address, d.tx_hash, d.output_index, d.amount,
+                              d.confirmations, d.status, d.transaction_id, d.detected_at, d.credited_at`
+
+// cryptoDepositSelect projects deposits together with the public ID of their wallet.
+const cryptoDepositSelect = `SELECT ` + cryptoDepositColumns + `, w.public_id AS wallet_public_id
+                             FROM crypto_deposits d
+                             JOIN wallets w ON w.id = d.wallet_id`
+
+// cryptoPayoutColumns are the columns of crypto_payouts, qualified for use after cryptoPayoutSelect and in RETURNING.
+const cryptoPayoutColumns = `p.id, p.public_id, p.wallet_id, p.asset, p.network, p.address, p.amount, p.fee, p.status,
+                             p.transaction_id, p.refund_transaction_id, p.chain_tx_hash, p.attempts, p.last_error,
+                             p.created_at, p.updated_at`
+
+// cryptoPayoutSelect projects payouts together with the public ID of their wallet.
+const cryptoPayoutSelect = `SELECT ` + cryptoPayoutColumns + `, w.public_id AS wallet_public_id
+                            FROM crypto_payouts p
+                            JOIN wallets w ON w.id = p.wallet_id`
+
+// CreateAddress stores a deposit address issued to a wallet.
+func (r *CryptoRepository) CreateAddress(ctx context.Context, q repository.DBExecutor, address *domain.CryptoAddress) error {
+	query := `INSERT INTO crypto_addresses (wallet_id, asset, network, address, created_at)
+              VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		address.WalletID,
+		address.Asset,
+		address.Network,
+		address.Address,
+		address.CreatedAt,
+	).Scan(&address.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create crypto address: %w", translateError(err))
+	}
+	return nil
+}
+
+// ListAddresses retrieves the deposit addresses of a wallet, oldest first.
+func (r *CryptoRepository) ListAddresses(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.CryptoAddress, error) {
+	var addresses []domain.CryptoAddress
+	query := cryptoAddressSelect + ` WHERE a.wallet_id = $1 ORDER BY a.id`
+	if err := q.SelectContext(ctx, &addresses, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to list crypto addresses for wallet %d: %w", walletID, translateError(err))
+	}
+	return addresses, nil
+}
+
+// GetAddress retrieves the deposit address on a network.
+func (r *CryptoRepository) GetAddress(ctx context.Context, q repository.DBExecutor, network, address string) (*domain.CryptoAddress, error) {
+	var found domain.CryptoAddress
+	query := cryptoAddressSelect + ` WHERE a.network = $1 AND a.address = $2`
+	if err := q.GetContext(ctx, &found, query, network, address); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get crypto address %s: %w", address, translateError(err))
+	}
+	return &found, nil
+}
+
+// RecordDeposit stores a deposit, or raises the confirmations of the stored one. Confirmations never go down,
+// so a report from a lagging gateway node cannot undo progress.
+func (r *CryptoRepository) RecordDeposit(ctx context.Context, q repository.DBExecutor, deposit *domain.CryptoDeposit) error {
+	query := `WITH recorded AS (
+                  INSERT INTO crypto_deposits AS d (wallet_id, asset, network, address, tx_hash, output_index, amount, confirmations, status, detected_at)
+                  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+                  ON CONFLICT (network, tx_hash, output_index)
+                  DO UPDATE SET confirmations = GREATEST(d.confirmations, EXCLUDED.confirmations)
+                  RETURNING ` + cryptoDepositColumns + `
+              )
+              SELECT d.*, w.public_id AS wallet_public_id
+              FROM recorded d
+              JOIN wallets w ON w.id = d.wallet_id`
+	err := q.GetContext(ctx, deposit, query,
+		deposit.WalletID,
+		deposit.Asset,
+		deposit.Network,
+		deposit.Address,
+		deposit.TxHash,
+		deposit.OutputIndex,
+		deposit.Amount,
+		deposit.Confirmations,
+		domain.CryptoDepositPending,
+		deposit.DetectedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record crypto deposit %s:%d: %w", deposit.TxHash, deposit.OutputIndex, translateError(err))
+	}
+	return nil
+}
+
+// GetDepositForUpdate retrieves a deposit and locks its row.
+func (r *CryptoRepository) GetDepositForUpdate(ctx context.Context, q repository.DBExecutor, depositID int64) (*domain.CryptoDeposit, error) {
+	var deposit domain.CryptoDeposit
+	query := cryptoDepositSelect + ` WHERE d.id = $1 FOR UPDATE OF d`
+	if err := q.GetContext(ctx, &deposit, query, depositID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get crypto deposit %d: %w", depositID, translateError(err))
+	}
+	return &deposit, nil
+}
+
+// MarkDepositCredited records the DEPOSIT transaction crediting a pending deposit.
+func (r *CryptoRepository) MarkDepositCredited(ctx context.Context, q repository.DBExecutor, depositID int64, transactionID uuid.UUID, creditedAt time.Time) error {
+	query := `UPDATE crypto_deposits SET status = $2, transaction_id = $3, credited_at = $4 WHERE id = $1 AND status = $5`
+	return execOneRow(ctx, q, fmt.Sprintf("crypto deposit %d", depositID), query,
+		depositID, domain.CryptoDepositCredited, transactionID, creditedAt, domain.CryptoDepositPending)
+}
+
+// ListDeposits retrieves the latest deposits of a wallet, newest first.
+func (r *CryptoRepository) ListDeposits(ctx context.Context, q repository.DBExecutor, walletID int64, limit int) ([]domain.CryptoDeposit, error) {
+	var deposits []domain.CryptoDeposit
+	query := cryptoDepositSelect + ` WHERE d.wallet_id = $1 ORDER BY d.id DESC LIMIT $2`
+	if err := q.SelectContext(ctx, &deposits, query, walletID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list crypto deposits for wallet %d: %w", walletID, translateError(err))
+	}
+	return deposits, nil
+}
+
+// CreatePayout queues a payout.
+func (r *CryptoRepository) CreatePayout(ctx context.Context, q repository.DBExecutor, payout *domain.CryptoPayout) error {
+	query := `INSERT INTO crypto_payouts (public_id, wallet_id, asset, network, address, amount, fee, status, transaction_id, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		payout.PublicID,
+		payout.WalletID,
+		payout.Asset,
+		payout.Network,
+		payout.Address,
+		payout.Amount,
+		payout.Fee,
+		payout.Status,
+		payout.TransactionID,
+		payout.CreatedAt,
+		payout.UpdatedAt,
+	).Scan(&payout.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create crypto payout: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetPayoutByPublicID retrieves a payout by its public UUID.
+func (r *CryptoRepository) GetPayoutByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.CryptoPayout, error) {
+	var payout domain.CryptoPayout
+	query := cryptoPayoutSelect + ` WHERE p.public_id = $1`
+	if err := q.GetContext(ctx, &payout, query, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get crypto payout %s: %w", publicID, translateError(err))
+	}
+	return &payout, nil
+}
+
+// ListPayouts retrieves the latest payouts of a wallet, newest first.
+func (r *CryptoRepository) ListPayouts(ctx context.Context, q repository.DBExecutor, walletID int64, limit int) ([]domain.CryptoPayout, error) {
+	var payouts []domain.CryptoPayout
+	query := cryptoPayoutSelect + ` WHERE p.wallet_id = $1 ORDER BY p.id DESC LIMIT $2`
+	if err := q.SelectContext(ctx, &payouts, query, walletID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list crypto payouts for wallet %d: %w", walletID, translateError(err))
+	}
+	return payouts, nil
+}
+
+// ClaimNextPayout claims the oldest queued payout; SKIP LOCKED lets several workers claim different payouts.
+func (r *CryptoRepository) ClaimNextPayout(ctx context.Context, q repository.DBExecutor, now time.Time) (*domain.CryptoPayout, error) {
+	var payout domain.CryptoPayout
+	query := `WITH claimed AS (
+                  UPDATE crypto_payouts p
+                  SET status = 'SENDING', attempts = p.attempts + 1, updated_at = $1
+                  WHERE p.id = (
+                      SELECT id FROM crypto_payouts WHERE status = 'QUEUED' ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED
+                  )
+                  RETURNING ` + cryptoPayoutColumns + `
+              )
+              SELECT p.*, w.public_id AS wallet_public_id
+              FROM claimed p
+              JOIN wallets w ON w.id = p.wallet_id`
+	if err := q.GetContext(ctx, &payout, query, now); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to claim crypto payout: %w", translateError(err))
+	}
+	return &payout, nil
+}
+
+// RequeueStalePayouts returns payouts stuck in SENDING to the queue.
+func (r *CryptoRepository) RequeueStalePayouts(ctx context.Context, q repository.DBExecutor, claimedBefore time.Time) (int64, error) {
+	query := `UPDATE crypto_payouts SET status = 'QUEUED', updated_at = NOW() WHERE status = 'SENDING' AND updated_at < $1`
+	result, err := q.ExecContext(ctx, query, claimedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale crypto payouts: %w", translateError(err))
+	}
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected for requeued crypto payouts: %w", err)
+	}
+	return requeued, nil
+}
+
+// RequeuePayout returns a SENDING payout to the queue after a failed attempt.
+func (r *CryptoRepository) RequeuePayout(ctx context.Context, q repository.DBExecutor, payoutID int64, lastError string) error {
+	query := `UPDATE crypto_payouts SET status = 'QUEUED', last_error = $2, updated_at = NOW() WHERE id = $1 AND status = 'SENDING'`
+	return execOneRow(ctx, q, fmt.Sprintf("crypto payout %d", payoutID), query, payoutID, lastError)
+}
+
+// MarkPayoutSent records the chain transaction of a SENDING payout.
+func (r *CryptoRepository) MarkPayoutSent(ctx context.Context, q repository.DBExecutor, payoutID int64, chainTxHash string) error {
+	query := `UPDATE crypto_payouts SET status = 'SENT', chain_tx_hash = $2, last_error = NULL, updated_at = NOW()
+              WHERE id = $1 AND status = 'SENDING'`
+	return execOneRow(ctx, q, fmt.Sprintf("crypto payout %d", payoutID), query, payoutID, chainTxHash)
+}
+
+// MarkPayoutFailed records why a SENDING payout was rejected and the DEPOSIT refunding it.
+func (r *CryptoRepository) MarkPayoutFailed(ctx context.Context, q repository.DBExecutor, payoutID int64, lastError string, refundTransactionID uuid.UUID) error {
+	query := `UPDATE crypto_payouts SET status = 'FAILED', last_error = $2, refund_transaction_id = $3, updated_at = NOW()
+              WHERE id = $1 AND status = 'SENDING'`
+	return execOneRow(ctx, q, fmt.Sprintf("crypto payout %d", payoutID), query, payoutID, lastError, refundTransactionID)
+}
+
+// execOneRow runs a conditional update of one row, returning util.ErrNotFound if the row did not match.
+func execOneRow(ctx context.Context, q repository.DBExecutor, what, query string, args ...any) error {
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", what, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after updating %s: %w", what, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
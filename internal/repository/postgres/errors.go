@@ -0,0 +1,47 @@
+// internal/repository/postgres/errors.go
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/util"
+)
+
+// SQLSTATE codes the repositories translate into application errors.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation      pq.ErrorCode = "23505"
+	pgForeignKeyViolation  pq.ErrorCode = "23503"
+	pgCheckViolation       pq.ErrorCode = "23514"
+	pgRestrictViolation    pq.ErrorCode = "23001" // Raised by the append-only triggers of the transaction tables
+	pgSerializationFailure pq.ErrorCode = "40001"
+	pgDeadlockDetected     pq.ErrorCode = "40P01"
+)
+
+// translateError maps a Postgres driver error onto the matching util sentinel, so services and
+// handlers can pick a status code without inspecting driver types. The original error stays in the
+// chain for logging. Errors without a known SQLSTATE are returned unchanged.
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	var sentinel error
+	switch pqErr.Code {
+	case pgUniqueViolation:
+		sentinel = util.ErrDuplicateEntry
+	case pgForeignKeyViolation:
+		sentinel = util.ErrReferenceViolation
+	case pgCheckViolation, pgRestrictViolation:
+		sentinel = util.ErrConstraintViolation
+	case pgSerializationFailure, pgDeadlockDetected:
+		sentinel = util.ErrConcurrentUpdate
+	default:
+		return err
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
@@ -0,0 +1,47 @@
+// internal/repository/postgres/errors_test.go
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/internal/util"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name string
+		code pq.ErrorCode
+		want error
+	}{
+		{"UniqueViolation", pgUniqueViolation, util.ErrDuplicateEntry},
+		{"ForeignKeyViolation", pgForeignKeyViolation, util.ErrReferenceViolation},
+		{"CheckViolation", pgCheckViolation, util.ErrConstraintViolation},
+		{"SerializationFailure", pgSerializationFailure, util.ErrConcurrentUpdate},
+		{"DeadlockDetected", pgDeadlockDetected, util.ErrConcurrentUpdate},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			driverErr := &pq.Error{Code: tc.code, Message: "boom"}
+			err := translateError(fmt.Errorf("exec: %w", driverErr))
+
+			assert.ErrorIs(t, err, tc.want)
+			var pqErr *pq.Error
+			assert.ErrorAs(t, err, &pqErr, "driver error must stay in the chain for logging")
+		})
+	}
+
+	t.Run("UnknownCodeUnchanged", func(t *testing.T) {
+		driverErr := &pq.Error{Code: "42P01"} // undefined_table
+		assert.Same(t, driverErr, translateError(driverErr))
+	})
+
+	t.Run("NonDriverErrorUnchanged", func(t *testing.T) {
+		plain := errors.New("connection reset")
+		assert.Same(t, plain, translateError(plain))
+	})
+}
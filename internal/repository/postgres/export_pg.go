@@ -0,0 +1,83 @@
+// internal/repository/postgres/export_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// ExportRepository implements repository.ExportRepository for PostgreSQL.
+type ExportRepository struct{}
+
+// NewExportRepository creates a new ExportRepository.
+func NewExportRepository(db *sqlx.DB) repository.ExportRepository {
+	return &ExportRepository{}
+}
+
+// GetWatermark retrieves the progress of a stream.
+func (r *ExportRepository) GetWatermark(ctx context.Context, q repository.DBExecutor, stream string) (*domain.ExportWatermark, error) {
+	var watermark domain.ExportWatermark
+	query := `SELECT stream, last_id, exported_at, updated_at FROM export_watermarks WHERE stream = $1`
+	if err := q.GetContext(ctx, &watermark, query, stream); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get export watermark %s: %w", stream, translateError(err))
+	}
+	return &watermark, nil
+}
+
+// SaveWatermark creates or replaces the progress of a stream.
+func (r *ExportRepository) SaveWatermark(ctx context.Context, q repository.DBExecutor, watermark *domain.ExportWatermark) error {
+	query := `INSERT INTO export_watermarks (stream, last_id, exported_at, updated_at)
+              VALUES ($1, $2, $3, $4)
+              ON CONFLICT (stream) DO UPDATE
+              SET last_id = EXCLUDED.last_id, exported_at = EXCLUDED.exported_at, updated_at = EXCLUDED.updated_at`
+	if _, err := q.ExecContext(ctx, query, watermark.Stream, watermark.LastID, watermark.ExportedAt, watermark.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save export watermark %s: %w", watermark.Stream, translateError(err))
+	}
+	return nil
+}
+
+// ListTransactionsAfter retrieves the next batch of transactions from the hot table. Transactions are
+// exported long before they are archived, so the archive is not read.
+func (r *ExportRepository) ListTransactionsAfter(ctx context.Context, q repository.DBExecutor, afterID int64, before time.Time, limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	query := `SELECT * FROM ` + transactionSource(false) + ` WHERE id > $1 AND created_at < $2 ORDER BY id LIMIT $3`
+	if err := q.SelectContext(ctx, &transactions, query, afterID, before, limit); err != nil {
+		return nil, fmt.Errorf("failed to list transactions for export: %w", translateError(err))
+	}
+	return transactions, nil
+}
+
+// ListTransactionsInRange retrieves the next batch of completed transactions created in the range, from the
+// hot table and the archive.
+func (r *ExportRepository) ListTransactionsInRange(ctx context.Context, q repository.DBExecutor, from, to time.Time, afterID int64, limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	query := `SELECT * FROM ` + transactionSource(true) + `
+              WHERE created_at >= $1 AND created_at < $2 AND id > $3 AND status = 'COMPLETED'
+              ORDER BY id LIMIT $4`
+	if err := q.SelectContext(ctx, &transactions, query, from, to, afterID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list transactions from %s to %s: %w", from.Format(time.RFC3339), to.Format(time.RFC3339), translateError(err))
+	}
+	return transactions, nil
+}
+
+// ListWalletsAfter retrieves the next page of wallets.
+func (r *ExportRepository) ListWalletsAfter(ctx context.Context, q repository.DBExecutor, afterID int64, limit int) ([]domain.Wallet, error) {
+	var wallets []domain.Wallet
+	query := `SELECT id, public_id, user_id, currency, ` + walletBalanceOf("wallets") + ` AS balance, kind, ` + walletVersionOf("wallets") + ` AS version, created_at, updated_at, deleted_at
+              FROM wallets WHERE id > $1 ORDER BY id LIMIT $2`
+	if err := q.SelectContext(ctx, &wallets, query, afterID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list wallets for export: %w", translateError(err))
+	}
+	return wallets, nil
+}
@@ -0,0 +1,106 @@
+// internal/repository/postgres/feature_flag_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// FeatureFlagRepository implements repository.FeatureFlagRepository for PostgreSQL.
+type FeatureFlagRepository struct{}
+
+// NewFeatureFlagRepository creates a new FeatureFlagRepository.
+func NewFeatureFlagRepository(db *sqlx.DB) repository.FeatureFlagRepository {
+	return &FeatureFlagRepository{}
+}
+
+// featureFlagRow maps a feature_flags row; the cohort is a Postgres array.
+type featureFlagRow struct {
+	Key            string        `db:"key"`
+	Description    string        `db:"description"`
+	Enabled        bool          `db:"enabled"`
+	UserIDs        pq.Int64Array `db:"user_ids"`
+	RolloutPercent int           `db:"rollout_percent"`
+	RolloutBy      string        `db:"rollout_by"`
+	Value          string        `db:"value"`
+	CreatedAt      time.Time     `db:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at"`
+}
+
+// ListFlags retrieves every feature flag using the provided DBExecutor.
+func (r *FeatureFlagRepository) ListFlags(ctx context.Context, q repository.DBExecutor) ([]domain.FeatureFlag, error) {
+	var rows []featureFlagRow
+	query := `SELECT key, description, enabled, user_ids, rollout_percent, rollout_by, value, created_at, updated_at FROM feature_flags ORDER BY key`
+	if err := q.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", translateError(err))
+	}
+
+	flags := make([]domain.FeatureFlag, len(rows))
+	for i, row := range rows {
+		flags[i] = domain.FeatureFlag{
+			Key:            row.Key,
+			Description:    row.Description,
+			Enabled:        row.Enabled,
+			UserIDs:        []int64(row.UserIDs),
+			RolloutPercent: row.RolloutPercent,
+			RolloutBy:      domain.RolloutBy(row.RolloutBy),
+			Value:          row.Value,
+			CreatedAt:      row.CreatedAt,
+			UpdatedAt:      row.UpdatedAt,
+		}
+	}
+	return flags, nil
+}
+
+// UpsertFlag creates the flag or replaces the existing flag with the same key.
+func (r *FeatureFlagRepository) UpsertFlag(ctx context.Context, q repository.DBExecutor, flag *domain.FeatureFlag) error {
+	query := `INSERT INTO feature_flags (key, description, enabled, user_ids, rollout_percent, rollout_by, value, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+              ON CONFLICT (key) DO UPDATE SET
+                  description = EXCLUDED.description,
+                  enabled = EXCLUDED.enabled,
+                  user_ids = EXCLUDED.user_ids,
+                  rollout_percent = EXCLUDED.rollout_percent,
+                  rollout_by = EXCLUDED.rollout_by,
+                  value = EXCLUDED.value,
+                  updated_at = EXCLUDED.updated_at
+              RETURNING created_at, updated_at`
+	err := q.QueryRowContext(ctx, query,
+		flag.Key,
+		flag.Description,
+		flag.Enabled,
+		pq.Int64Array(flag.UserIDs),
+		flag.RolloutPercent,
+		flag.RolloutBy,
+		flag.Value,
+		time.Now().UTC(),
+	).Scan(&flag.CreatedAt, &flag.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save feature flag %s: %w", flag.Key, translateError(err))
+	}
+	return nil
+}
+
+// DeleteFlag removes a flag; it returns util.ErrNotFound if no such flag exists.
+func (r *FeatureFlagRepository) DeleteFlag(ctx context.Context, q repository.DBExecutor, key string) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM feature_flags WHERE key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag %s: %w", key, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting feature flag %s: %w", key, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// internal/repository/postgres/fx_rate_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// FXRateRepository implements repository.FXRateRepository for PostgreSQL.
+type FXRateRepository struct{}
+
+// NewFXRateRepository creates a new FXRateRepository.
+func NewFXRateRepository(db *sqlx.DB) repository.FXRateRepository {
+	return &FXRateRepository{}
+}
+
+// ListRates retrieves the latest rate of every currency pair using the provided DBExecutor.
+func (r *FXRateRepository) ListRates(ctx context.Context, q repository.DBExecutor) ([]domain.FXRate, error) {
+	var rates []domain.FXRate
+	query := `SELECT base_currency, quote_currency, rate, as_of FROM fx_rates ORDER BY base_currency, quote_currency`
+	if err := q.SelectContext(ctx, &rates, query); err != nil {
+		return nil, fmt.Errorf("failed to list fx rates: %w", translateError(err))
+	}
+	return rates, nil
+}
@@ -0,0 +1,29 @@
+// internal/repository/postgres/hot_queries.go
+package postgres
+
+// Queries of the transfer path. They are package-level variables, built once, so that db.StmtCache,
+// which is keyed by query text, can prepare them once per connection.
+var (
+	getWalletByIDQuery = `SELECT ` + walletColumns("wallets") + ` FROM wallets WHERE id = $1 AND deleted_at IS NULL`
+
+	getWalletsByIDsQuery = `SELECT ` + walletColumns("wallets") + ` FROM wallets WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id`
+
+	updateWalletBalanceQuery = `UPDATE wallets SET balance = balance + $1, version = version + 1, updated_at = $2, last_activity_at = $2 WHERE id = $3
+              RETURNING id, ` + walletBalanceOf("wallets") + ` AS balance, ` + walletVersionOf("wallets") + ` AS version, ` +
+		walletLatestOf("wallets", "updated_at") + ` AS updated_at`
+
+	updateWalletBalancesQuery = `UPDATE wallets SET balance = wallets.balance + c.amount, version = wallets.version + 1, updated_at = $3, last_activity_at = $3
+              FROM unnest($1::bigint[], $2::numeric[]) AS c(id, amount)
+              WHERE wallets.id = c.id
+              RETURNING ` + walletColumns("wallets")
+
+	createTransactionQuery = `INSERT INTO transactions (public_id, from_wallet_id, to_wallet_id, amount, currency, type, status, transaction_time, description, category, parent_transaction_id, promo_amount, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id`
+)
+
+// HotQueries returns the queries worth running as prepared statements: those of GetWalletByID,
+// GetWalletsByIDs, UpdateWalletBalance, UpdateWalletBalances and CreateTransaction, each run at least
+// once per deposit, withdrawal or transfer.
+func HotQueries() []string {
+	return []string{getWalletByIDQuery, getWalletsByIDsQuery, updateWalletBalanceQuery, updateWalletBalancesQuery, createTransactionQuery}
+}
@@ -0,0 +1,129 @@
+// internal/repository/postgres/hot_queries_test.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// openBenchmarkDB connects to the integration test database configured by the DB_* variables,
+// skipping the benchmark when it is unreachable.
+func openBenchmarkDB(b *testing.B) *sqlx.DB {
+	env := func(key, def string) string {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+		return def
+	}
+	port, _ := strconv.Atoi(env("DB_PORT", "5432"))
+	database, err := db.NewPostgresDB(db.Config{
+		Host:     env("DB_HOST", "localhost"),
+		Port:     port,
+		User:     env("DB_USER", "user"),
+		Password: env("DB_PASSWORD", "password"),
+		DBName:   env("DB_NAME", "walletdb_test"),
+		SSLMode:  env("DB_SSLMODE", "disable"),
+	})
+	if err != nil {
+		b.Skipf("test database unavailable: %v", err)
+	}
+	b.Cleanup(func() { _ = database.Close() })
+	return database
+}
+
+// BenchmarkTransferPath compares the hot queries of one transfer leg run as plain queries and
+// through db.StmtCache. Each iteration runs in a transaction that is rolled back.
+//
+//	go test ./internal/repository/postgres -run '^$' -bench TransferPath
+func BenchmarkTransferPath(b *testing.B) {
+	ctx := context.Background()
+	database := openBenchmarkDB(b)
+	users, wallets, transactions := &UserRepository{}, &WalletRepository{}, &TransactionRepository{}
+
+	user := domain.NewUser("bench-" + uuid.NewString())
+	if err := users.CreateUser(ctx, database, user); err != nil {
+		b.Fatal(err)
+	}
+	wallet := domain.NewWallet(user.ID, "USD")
+	if err := wallets.CreateWallet(ctx, database, wallet); err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		_, _ = database.Exec("DELETE FROM wallets WHERE id = $1", wallet.ID)
+		_, _ = database.Exec("DELETE FROM users WHERE id = $1", user.ID)
+	})
+
+	cache := db.NewStmtCache(database, HotQueries()...)
+	b.Cleanup(func() { _ = cache.Close() })
+
+	benchmarks := []struct {
+		name  string
+		begin db.BeginTxFunc
+	}{
+		{"Unprepared", db.BeginTx},
+		{"Prepared", cache.WrapBeginTx(db.BeginTx)},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tx, err := bm.begin(ctx, database)
+				if err != nil {
+					b.Fatal(err)
+				}
+				q := tx.(repository.DBExecutor)
+				if _, err := wallets.GetWalletByID(ctx, q, wallet.ID); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := wallets.UpdateWalletBalance(ctx, q, wallet.ID, decimal.NewFromInt(1), time.Now().UTC()); err != nil {
+					b.Fatal(err)
+				}
+				if err := transactions.CreateTransaction(ctx, q, domain.NewTransaction(nil, &wallet.ID, decimal.NewFromInt(1), "USD", domain.TransactionTypeDeposit, nil)); err != nil {
+					b.Fatal(err)
+				}
+				_ = tx.Rollback()
+			}
+		})
+	}
+}
+
+// BenchmarkGetWalletByID compares a balance lookup outside a transaction run as a plain query and
+// through db.StmtCache.
+func BenchmarkGetWalletByID(b *testing.B) {
+	ctx := context.Background()
+	database := openBenchmarkDB(b)
+	wallets := &WalletRepository{}
+
+	cache := db.NewStmtCache(database, HotQueries()...)
+	b.Cleanup(func() { _ = cache.Close() })
+
+	benchmarks := []struct {
+		name string
+		q    repository.DBExecutor
+	}{
+		{"Unprepared", database},
+		{"Prepared", cache.Executor()},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// An unknown ID still parses, plans and runs the query
+				if _, err := wallets.GetWalletByID(ctx, bm.q, -1); err != nil && !errors.Is(err, util.ErrNotFound) {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// internal/repository/postgres/identity_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// IdentityRepository implements repository.IdentityRepository for PostgreSQL.
+type IdentityRepository struct{}
+
+// NewIdentityRepository creates a new IdentityRepository.
+func NewIdentityRepository(db *sqlx.DB) repository.IdentityRepository {
+	return &IdentityRepository{}
+}
+
+const identityColumns = `id, public_id, user_id, provider, subject, created_at, last_login_at`
+
+// CreateIdentity inserts the identity; the unique constraints on (provider, subject) and (user_id, provider)
+// surface as util.ErrDuplicateEntry.
+func (r *IdentityRepository) CreateIdentity(ctx context.Context, q repository.DBExecutor, identity *domain.UserIdentity) error {
+	query := `INSERT INTO user_identities (public_id, user_id, provider, subject, created_at, last_login_at)
+              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		identity.PublicID,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.CreatedAt,
+		identity.LastLoginAt,
+	).Scan(&identity.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create %s identity of user %d: %w", identity.Provider, identity.UserID, translateError(err))
+	}
+	return nil
+}
+
+// GetIdentity retrieves the identity of a provider account.
+func (r *IdentityRepository) GetIdentity(ctx context.Context, q repository.DBExecutor, provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	query := `SELECT ` + identityColumns + ` FROM user_identities WHERE provider = $1 AND subject = $2`
+	if err := q.GetContext(ctx, &identity, query, provider, subject); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get %s identity: %w", provider, translateError(err))
+	}
+	return &identity, nil
+}
+
+// ListIdentitiesByUser retrieves the identities of a user, by provider.
+func (r *IdentityRepository) ListIdentitiesByUser(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.UserIdentity, error) {
+	identities := []domain.UserIdentity{}
+	query := `SELECT ` + identityColumns + ` FROM user_identities WHERE user_id = $1 ORDER BY provider`
+	if err := q.SelectContext(ctx, &identities, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list identities of user %d: %w", userID, translateError(err))
+	}
+	return identities, nil
+}
+
+// DeleteIdentity unlinks the user's identity at the provider.
+func (r *IdentityRepository) DeleteIdentity(ctx context.Context, q repository.DBExecutor, userID int64, provider string) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM user_identities WHERE user_id = $1 AND provider = $2`, userID, provider)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s identity of user %d: %w", provider, userID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting identity: %w", err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// TouchIdentity records a login with the identity.
+func (r *IdentityRepository) TouchIdentity(ctx context.Context, q repository.DBExecutor, id int64, at time.Time) error {
+	return execOneRow(ctx, q, "identity", `UPDATE user_identities SET last_login_at = $2 WHERE id = $1`, id, at)
+}
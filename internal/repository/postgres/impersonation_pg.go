@@ -0,0 +1,139 @@
+// internal/repository/postgres/impersonation_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// ImpersonationRepository implements repository.ImpersonationRepository for PostgreSQL.
+type ImpersonationRepository struct{}
+
+// NewImpersonationRepository creates a new ImpersonationRepository.
+func NewImpersonationRepository(db *sqlx.DB) repository.ImpersonationRepository {
+	return &ImpersonationRepository{}
+}
+
+const impersonationSessionColumns = `id, public_id, token_hash, user_id, operator, reason, allow_transfer,
+                                     transfer_claimed_at, expires_at, revoked_at, created_at`
+
+// CreateSession stores a new session.
+func (r *ImpersonationRepository) CreateSession(ctx context.Context, q repository.DBExecutor, session *domain.ImpersonationSession) error {
+	query := `INSERT INTO impersonation_sessions (public_id, token_hash, user_id, operator, reason, allow_transfer, expires_at, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		session.PublicID,
+		session.TokenHash,
+		session.UserID,
+		session.Operator,
+		session.Reason,
+		session.AllowTransfer,
+		session.ExpiresAt,
+		session.CreatedAt,
+	).Scan(&session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create impersonation session: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetSessionByTokenHash retrieves a session by the hash of its token.
+func (r *ImpersonationRepository) GetSessionByTokenHash(ctx context.Context, q repository.DBExecutor, tokenHash string) (*domain.ImpersonationSession, error) {
+	var session domain.ImpersonationSession
+	query := `SELECT ` + impersonationSessionColumns + ` FROM impersonation_sessions WHERE token_hash = $1`
+	if err := q.GetContext(ctx, &session, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get impersonation session: %w", translateError(err))
+	}
+	return &session, nil
+}
+
+// GetSessionByPublicID retrieves a session by its public ID.
+func (r *ImpersonationRepository) GetSessionByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.ImpersonationSession, error) {
+	var session domain.ImpersonationSession
+	query := `SELECT ` + impersonationSessionColumns + ` FROM impersonation_sessions WHERE public_id = $1`
+	if err := q.GetContext(ctx, &session, query, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get impersonation session %s: %w", publicID, translateError(err))
+	}
+	return &session, nil
+}
+
+// ClaimTransfer uses up the session's one transfer in a single conditional UPDATE, so of two concurrent
+// transfers exactly one claims it. It returns util.ErrNotFound when nothing was claimed.
+func (r *ImpersonationRepository) ClaimTransfer(ctx context.Context, q repository.DBExecutor, sessionID int64, now time.Time) error {
+	query := `UPDATE impersonation_sessions SET transfer_claimed_at = $2
+              WHERE id = $1 AND allow_transfer AND transfer_claimed_at IS NULL AND revoked_at IS NULL AND expires_at > $2`
+	result, err := q.ExecContext(ctx, query, sessionID, now)
+	if err != nil {
+		return fmt.Errorf("failed to claim impersonation transfer: %w", translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for impersonation transfer claim: %w", err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// RevokeSession ends an active session; it returns util.ErrNotFound if the session is already revoked.
+func (r *ImpersonationRepository) RevokeSession(ctx context.Context, q repository.DBExecutor, sessionID int64, now time.Time) error {
+	query := `UPDATE impersonation_sessions SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`
+	result, err := q.ExecContext(ctx, query, sessionID, now)
+	if err != nil {
+		return fmt.Errorf("failed to revoke impersonation session: %w", translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected for impersonation session revocation: %w", err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// CreateAuditEntry records a request made with a session.
+func (r *ImpersonationRepository) CreateAuditEntry(ctx context.Context, q repository.DBExecutor, entry *domain.ImpersonationAuditEntry) error {
+	query := `INSERT INTO impersonation_audit (session_id, action, method, path, status, request_id, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		entry.SessionID,
+		entry.Action,
+		entry.Method,
+		entry.Path,
+		entry.Status,
+		entry.RequestID,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create impersonation audit entry: %w", translateError(err))
+	}
+	return nil
+}
+
+// ListAuditEntries retrieves a session's audit entries, oldest first.
+func (r *ImpersonationRepository) ListAuditEntries(ctx context.Context, q repository.DBExecutor, sessionID int64) ([]domain.ImpersonationAuditEntry, error) {
+	var entries []domain.ImpersonationAuditEntry
+	query := `SELECT id, session_id, action, method, path, status, request_id, created_at
+              FROM impersonation_audit WHERE session_id = $1 ORDER BY id`
+	if err := q.SelectContext(ctx, &entries, query, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to list impersonation audit entries: %w", translateError(err))
+	}
+	return entries, nil
+}
@@ -0,0 +1,161 @@
+// internal/repository/postgres/integrity_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// IntegrityRepository implements repository.IntegrityRepository for PostgreSQL.
+type IntegrityRepository struct{}
+
+// NewIntegrityRepository creates a new IntegrityRepository.
+func NewIntegrityRepository(db *sqlx.DB) repository.IntegrityRepository {
+	return &IntegrityRepository{}
+}
+
+// walletMovements are the balance changes of the completed transactions, hot and archived, one row per wallet
+// side. SPLIT parents move no money, their legs do; the promotional part of a debit, recorded on the parent of
+// a split, is not taken from the balance.
+const walletMovements = `
+	WITH completed AS (
+	    SELECT from_wallet_id, to_wallet_id, amount, promo_amount, type, currency FROM transactions WHERE status = 'COMPLETED'
+	    UNION ALL
+	    SELECT from_wallet_id, to_wallet_id, amount, promo_amount, type, currency FROM transactions_archive WHERE status = 'COMPLETED'
+	), movements AS (
+	    SELECT to_wallet_id AS wallet_id, amount, currency FROM completed WHERE to_wallet_id IS NOT NULL AND type <> 'SPLIT'
+	    UNION ALL
+	    SELECT from_wallet_id, -amount, currency FROM completed WHERE from_wallet_id IS NOT NULL AND type <> 'SPLIT'
+	    UNION ALL
+	    SELECT from_wallet_id, promo_amount, currency FROM completed WHERE from_wallet_id IS NOT NULL AND promo_amount <> 0
+	), shards AS (
+	    SELECT wallet_id, SUM(balance) AS balance FROM wallet_balance_shards GROUP BY wallet_id
+	)`
+
+// ListBalanceMismatches compares each wallet's balance with the sum of its movements.
+func (r *IntegrityRepository) ListBalanceMismatches(ctx context.Context, q repository.DBExecutor, limit int) ([]domain.BalanceMismatch, int64, error) {
+	var rows []struct {
+		domain.BalanceMismatch
+		Total int64 `db:"total"`
+	}
+	query := walletMovements + `, expected AS (
+	    SELECT wallet_id, SUM(amount) AS amount FROM movements GROUP BY wallet_id
+	)
+	SELECT w.id AS wallet_id, w.public_id AS wallet_public_id, w.currency,
+	       w.balance + COALESCE(s.balance, 0) AS balance, COALESCE(e.amount, 0) AS expected, COUNT(*) OVER () AS total
+	FROM wallets w
+	LEFT JOIN shards s ON s.wallet_id = w.id
+	LEFT JOIN expected e ON e.wallet_id = w.id
+	WHERE w.balance + COALESCE(s.balance, 0) <> COALESCE(e.amount, 0)
+	ORDER BY w.id
+	LIMIT $1`
+	if err := q.SelectContext(ctx, &rows, query, limit); err != nil {
+		return nil, 0, fmt.Errorf("failed to list balance mismatches: %w", translateError(err))
+	}
+	mismatches := make([]domain.BalanceMismatch, len(rows))
+	var total int64
+	for i, row := range rows {
+		mismatches[i], total = row.BalanceMismatch, row.Total
+	}
+	return mismatches, total, nil
+}
+
+// ListLedgerBalances recomputes the balances of a few wallets, with the rules of walletMovements restricted to
+// their transactions.
+func (r *IntegrityRepository) ListLedgerBalances(ctx context.Context, q repository.DBExecutor, walletIDs []int64) ([]domain.LedgerBalance, error) {
+	balances := []domain.LedgerBalance{}
+	query := `
+	WITH completed AS (
+	    SELECT from_wallet_id, to_wallet_id, amount, promo_amount, type FROM transactions
+	    WHERE status = 'COMPLETED' AND (from_wallet_id = ANY($1) OR to_wallet_id = ANY($1))
+	    UNION ALL
+	    SELECT from_wallet_id, to_wallet_id, amount, promo_amount, type FROM transactions_archive
+	    WHERE status = 'COMPLETED' AND (from_wallet_id = ANY($1) OR to_wallet_id = ANY($1))
+	), movements AS (
+	    SELECT to_wallet_id AS wallet_id, amount FROM completed WHERE to_wallet_id = ANY($1) AND type <> 'SPLIT'
+	    UNION ALL
+	    SELECT from_wallet_id, -amount FROM completed WHERE from_wallet_id = ANY($1) AND type <> 'SPLIT'
+	    UNION ALL
+	    SELECT from_wallet_id, promo_amount FROM completed WHERE from_wallet_id = ANY($1) AND promo_amount <> 0
+	)
+	SELECT w.id AS wallet_id, w.public_id AS wallet_public_id, w.currency,
+	       w.balance + COALESCE((SELECT SUM(s.balance) FROM wallet_balance_shards s WHERE s.wallet_id = w.id), 0) AS balance,
+	       COALESCE((SELECT SUM(m.amount) FROM movements m WHERE m.wallet_id = w.id), 0) AS expected
+	FROM wallets w
+	WHERE w.id = ANY($1)
+	ORDER BY w.id`
+	if err := q.SelectContext(ctx, &balances, query, pq.Int64Array(walletIDs)); err != nil {
+		return nil, fmt.Errorf("failed to list ledger balances of wallets %v: %w", walletIDs, translateError(err))
+	}
+	return balances, nil
+}
+
+// ListCurrencyImbalances compares, per currency, the wallet balances with the one-sided movements: money moved
+// between two wallets cancels out, so the balances must add up to what was paid in less what was paid out.
+func (r *IntegrityRepository) ListCurrencyImbalances(ctx context.Context, q repository.DBExecutor) ([]domain.CurrencyImbalance, error) {
+	var imbalances []domain.CurrencyImbalance
+	query := walletMovements + `, balances AS (
+	    SELECT w.currency, SUM(w.balance + COALESCE(s.balance, 0)) AS amount
+	    FROM wallets w LEFT JOIN shards s ON s.wallet_id = w.id
+	    GROUP BY w.currency
+	), flows AS (
+	    SELECT currency, SUM(amount) AS amount FROM movements GROUP BY currency
+	)
+	SELECT COALESCE(b.currency, f.currency) AS currency, COALESCE(b.amount, 0) AS balances, COALESCE(f.amount, 0) AS net_flows
+	FROM balances b FULL JOIN flows f ON f.currency = b.currency
+	WHERE COALESCE(b.amount, 0) <> COALESCE(f.amount, 0)
+	ORDER BY 1`
+	if err := q.SelectContext(ctx, &imbalances, query); err != nil {
+		return nil, fmt.Errorf("failed to list currency imbalances: %w", translateError(err))
+	}
+	return imbalances, nil
+}
+
+// orphanChecks are the references checked by CountOrphans. The archive and the relations of partitioned
+// tables are not enforced by foreign keys, and a partial restore may break the others.
+var orphanChecks = []struct{ relation, table, column, parent, key string }{
+	{"wallets.user_id -> users", "wallets", "user_id", "users", "id"},
+	{"transactions.from_wallet_id -> wallets", "transactions", "from_wallet_id", "wallets", "id"},
+	{"transactions.to_wallet_id -> wallets", "transactions", "to_wallet_id", "wallets", "id"},
+	{"transactions_archive.from_wallet_id -> wallets", "transactions_archive", "from_wallet_id", "wallets", "id"},
+	{"transactions_archive.to_wallet_id -> wallets", "transactions_archive", "to_wallet_id", "wallets", "id"},
+	{"wallet_members.wallet_id -> wallets", "wallet_members", "wallet_id", "wallets", "id"},
+	{"wallet_members.user_id -> users", "wallet_members", "user_id", "users", "id"},
+	{"wallet_balance_shards.wallet_id -> wallets", "wallet_balance_shards", "wallet_id", "wallets", "id"},
+	{"promo_credits.wallet_id -> wallets", "promo_credits", "wallet_id", "wallets", "id"},
+	{"async_transfers.transaction_id -> transactions", "async_transfers", "transaction_id", "transactions", "id"},
+}
+
+// CountOrphans counts the rows referencing a missing row, per relation, and the transactions whose parent is
+// neither hot nor archived.
+func (r *IntegrityRepository) CountOrphans(ctx context.Context, q repository.DBExecutor) ([]domain.OrphanCount, error) {
+	counts := make([]domain.OrphanCount, 0, len(orphanChecks)+1)
+	for _, check := range orphanChecks {
+		count := domain.OrphanCount{Relation: check.relation}
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %[1]s c WHERE c.%[2]s IS NOT NULL
+                              AND NOT EXISTS (SELECT 1 FROM %[3]s p WHERE p.%[4]s = c.%[2]s)`, check.table, check.column, check.parent, check.key)
+		if err := q.GetContext(ctx, &count.Rows, query); err != nil {
+			return nil, fmt.Errorf("failed to count orphans of %s: %w", check.relation, translateError(err))
+		}
+		counts = append(counts, count)
+	}
+
+	parents := domain.OrphanCount{Relation: "transactions.parent_transaction_id -> transactions"}
+	query := `SELECT COUNT(*) FROM (
+                  SELECT parent_transaction_id FROM transactions WHERE parent_transaction_id IS NOT NULL
+                  UNION ALL
+                  SELECT parent_transaction_id FROM transactions_archive WHERE parent_transaction_id IS NOT NULL
+              ) c
+              WHERE NOT EXISTS (SELECT 1 FROM transactions p WHERE p.public_id = c.parent_transaction_id)
+                AND NOT EXISTS (SELECT 1 FROM transactions_archive p WHERE p.public_id = c.parent_transaction_id)`
+	if err := q.GetContext(ctx, &parents.Rows, query); err != nil {
+		return nil, fmt.Errorf("failed to count orphans of %s: %w", parents.Relation, translateError(err))
+	}
+	return append(counts, parents), nil
+}
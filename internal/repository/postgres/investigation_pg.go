@@ -0,0 +1,103 @@
+// internal/repository/postgres/investigation_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// InvestigationRepository implements repository.InvestigationRepository for PostgreSQL.
+type InvestigationRepository struct{}
+
+// NewInvestigationRepository creates a new InvestigationRepository.
+func NewInvestigationRepository(db *sqlx.DB) repository.InvestigationRepository {
+	return &InvestigationRepository{}
+}
+
+// ListChildTransactions retrieves the transactions whose parent is the transaction.
+func (r *InvestigationRepository) ListChildTransactions(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID) ([]domain.Transaction, error) {
+	transactions := []domain.Transaction{}
+	query := `SELECT * FROM ` + transactionSource(true) + ` WHERE parent_transaction_id = $1 ORDER BY id`
+	if err := q.SelectContext(ctx, &transactions, query, transactionID); err != nil {
+		return nil, fmt.Errorf("failed to list child transactions of %s: %w", transactionID, translateError(err))
+	}
+	return transactions, nil
+}
+
+// transactionReferences are the records referencing a transaction by its public ID, as $1.
+const transactionReferences = `
+	SELECT 'transfer_approval' AS kind, public_id, status, amount, created_at FROM transfer_approvals WHERE transaction_id = $1
+	UNION ALL
+	SELECT 'settlement', public_id, NULL, amount, created_at FROM settlements WHERE transaction_id = $1
+	UNION ALL
+	SELECT 'charge', public_id, status, amount, created_at FROM charges WHERE transaction_id = $1
+	UNION ALL
+	SELECT 'voucher', public_id, status, amount, created_at FROM vouchers WHERE transaction_id = $1
+	UNION ALL
+	SELECT 'netting_settlement', public_id, NULL, net_amount, created_at FROM netting_settlements WHERE transaction_id = $1
+	UNION ALL
+	SELECT 'inbound_funds', public_id, status, amount, created_at FROM inbound_funds WHERE transaction_id = $1 OR adjustment_transaction_id = $1
+	UNION ALL
+	SELECT 'adjustment', public_id, status, amount, created_at FROM adjustments WHERE transaction_id = $1`
+
+// ListTransactionReferences retrieves the records that reference the transaction.
+func (r *InvestigationRepository) ListTransactionReferences(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID) ([]domain.TransactionReference, error) {
+	references := []domain.TransactionReference{}
+	query := `SELECT * FROM (` + transactionReferences + `) refs ORDER BY created_at, kind`
+	if err := q.SelectContext(ctx, &references, query, transactionID); err != nil {
+		return nil, fmt.Errorf("failed to list references to transaction %s: %w", transactionID, translateError(err))
+	}
+	return references, nil
+}
+
+// ListTransactionNotifications retrieves the users' notifications whose data names the transaction.
+func (r *InvestigationRepository) ListTransactionNotifications(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID, userIDs []int64) ([]domain.Notification, error) {
+	var rows []notificationRow
+	query := `SELECT ` + notificationColumns + ` FROM notifications
+              WHERE user_id = ANY($1) AND data->>'transaction_id' = $2 ORDER BY id`
+	if err := q.SelectContext(ctx, &rows, query, pq.Int64Array(userIDs), transactionID.String()); err != nil {
+		return nil, fmt.Errorf("failed to list notifications about transaction %s: %w", transactionID, translateError(err))
+	}
+	notifications := make([]domain.Notification, len(rows))
+	for i := range rows {
+		notification, err := rows[i].toDomain()
+		if err != nil {
+			return nil, err
+		}
+		notifications[i] = notification
+	}
+	return notifications, nil
+}
+
+// ListTransactionDeliveries retrieves the users' push deliveries whose data names the transaction.
+func (r *InvestigationRepository) ListTransactionDeliveries(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID, userIDs []int64) ([]domain.NotificationDelivery, error) {
+	var rows []deliveryRow
+	query := `SELECT ` + deliveryColumns + ` FROM notification_deliveries d
+              WHERE d.user_id = ANY($1) AND d.data->>'transaction_id' = $2 ORDER BY d.id`
+	if err := q.SelectContext(ctx, &rows, query, pq.Int64Array(userIDs), transactionID.String()); err != nil {
+		return nil, fmt.Errorf("failed to list notification deliveries about transaction %s: %w", transactionID, translateError(err))
+	}
+	return deliveriesToDomain(rows)
+}
+
+// ListTransactionAudit retrieves the impersonated requests on the users whose path contains the transaction ID.
+func (r *InvestigationRepository) ListTransactionAudit(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID, userIDs []int64) ([]domain.TransactionAuditEntry, error) {
+	entries := []domain.TransactionAuditEntry{}
+	query := `SELECT a.id, a.session_id, a.action, a.method, a.path, a.status, a.request_id, a.created_at,
+                     s.public_id AS session_public_id, s.user_id, s.operator
+              FROM impersonation_audit a
+              JOIN impersonation_sessions s ON s.id = a.session_id
+              WHERE s.user_id = ANY($1) AND strpos(a.path, $2) > 0
+              ORDER BY a.id`
+	if err := q.SelectContext(ctx, &entries, query, pq.Int64Array(userIDs), transactionID.String()); err != nil {
+		return nil, fmt.Errorf("failed to list audit entries about transaction %s: %w", transactionID, translateError(err))
+	}
+	return entries, nil
+}
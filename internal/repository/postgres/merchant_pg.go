@@ -0,0 +1,212 @@
+// internal/repository/postgres/merchant_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// MerchantRepository implements repository.MerchantRepository for PostgreSQL.
+type MerchantRepository struct{}
+
+// NewMerchantRepository creates a new MerchantRepository.
+func NewMerchantRepository(db *sqlx.DB) repository.MerchantRepository {
+	return &MerchantRepository{}
+}
+
+// merchantSettingsSelect projects merchant settings together with the public IDs of both wallets and the
+// time zone of the merchant.
+const merchantSettingsSelect = `SELECT s.wallet_id, s.payout_wallet_id, mw.public_id AS wallet_public_id,
+                                       pw.public_id AS payout_wallet_public_id, s.min_payout_amount,
+                                       u.timezone AS owner_timezone, s.created_at, s.updated_at
+                                FROM merchant_settings s
+                                JOIN wallets mw ON mw.id = s.wallet_id
+                                JOIN wallets pw ON pw.id = s.payout_wallet_id
+                                JOIN users u ON u.id = mw.user_id`
+
+// SaveSettings creates or replaces the settlement preferences of a merchant wallet.
+func (r *MerchantRepository) SaveSettings(ctx context.Context, q repository.DBExecutor, settings *domain.MerchantSettings) error {
+	query := `INSERT INTO merchant_settings (wallet_id, payout_wallet_id, min_payout_amount, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5)
+              ON CONFLICT (wallet_id) DO UPDATE
+              SET payout_wallet_id = EXCLUDED.payout_wallet_id,
+                  min_payout_amount = EXCLUDED.min_payout_amount,
+                  updated_at = EXCLUDED.updated_at
+              RETURNING created_at`
+	err := q.QueryRowContext(ctx, query,
+		settings.WalletID,
+		settings.PayoutWalletID,
+		settings.MinPayoutAmount,
+		settings.CreatedAt,
+		settings.UpdatedAt,
+	).Scan(&settings.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save merchant settings of wallet %d: %w", settings.WalletID, translateError(err))
+	}
+	return nil
+}
+
+// GetSettings retrieves a merchant wallet's preferences; it returns util.ErrNotFound if the wallet is not a merchant.
+func (r *MerchantRepository) GetSettings(ctx context.Context, q repository.DBExecutor, walletID int64) (*domain.MerchantSettings, error) {
+	var settings domain.MerchantSettings
+	if err := q.GetContext(ctx, &settings, merchantSettingsSelect+` WHERE s.wallet_id = $1`, walletID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get merchant settings of wallet %d: %w", walletID, translateError(err))
+	}
+	return &settings, nil
+}
+
+// ListSettings retrieves the preferences of all merchant wallets.
+func (r *MerchantRepository) ListSettings(ctx context.Context, q repository.DBExecutor) ([]domain.MerchantSettings, error) {
+	var settings []domain.MerchantSettings
+	if err := q.SelectContext(ctx, &settings, merchantSettingsSelect+` ORDER BY s.wallet_id`); err != nil {
+		return nil, fmt.Errorf("failed to list merchant settings: %w", translateError(err))
+	}
+	return settings, nil
+}
+
+// CreateSettlement stores a settlement; it returns util.ErrDuplicateEntry if the merchant's day is already settled.
+func (r *MerchantRepository) CreateSettlement(ctx context.Context, q repository.DBExecutor, settlement *domain.Settlement) error {
+	query := `INSERT INTO settlements (public_id, merchant_wallet_id, payout_wallet_id, amount, currency, charge_count,
+                                       settlement_date, transaction_id, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		settlement.PublicID,
+		settlement.MerchantWalletID,
+		settlement.PayoutWalletID,
+		settlement.Amount,
+		settlement.Currency,
+		settlement.ChargeCount,
+		settlement.SettlementDate,
+		settlement.TransactionID,
+		settlement.CreatedAt,
+	).Scan(&settlement.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create settlement: %w", translateError(err))
+	}
+	return nil
+}
+
+// ListSettlements retrieves the settlements of a merchant wallet, newest first.
+func (r *MerchantRepository) ListSettlements(ctx context.Context, q repository.DBExecutor, merchantWalletID int64) ([]domain.Settlement, error) {
+	var settlements []domain.Settlement
+	query := `SELECT st.id, st.public_id, st.merchant_wallet_id, st.payout_wallet_id, mw.public_id AS merchant_wallet_public_id,
+                     pw.public_id AS payout_wallet_public_id, st.amount, st.currency, st.charge_count, st.settlement_date,
+                     st.transaction_id, st.created_at
+              FROM settlements st
+              JOIN wallets mw ON mw.id = st.merchant_wallet_id
+              JOIN wallets pw ON pw.id = st.payout_wallet_id
+              WHERE st.merchant_wallet_id = $1
+              ORDER BY st.settlement_date DESC`
+	if err := q.SelectContext(ctx, &settlements, query, merchantWalletID); err != nil {
+		return nil, fmt.Errorf("failed to list settlements of wallet %d: %w", merchantWalletID, translateError(err))
+	}
+	return settlements, nil
+}
+
+// ChargeRepository implements repository.ChargeRepository for PostgreSQL.
+type ChargeRepository struct{}
+
+// NewChargeRepository creates a new ChargeRepository.
+func NewChargeRepository(db *sqlx.DB) repository.ChargeRepository {
+	return &ChargeRepository{}
+}
+
+// chargeSelect projects charges together with the public IDs of the merchant and payer wallets.
+const chargeSelect = `SELECT c.id, c.public_id, c.merchant_wallet_id, mw.public_id AS merchant_wallet_public_id,
+                             c.amount, c.currency, c.description, c.reference, c.status, c.payer_wallet_id,
+                             pw.public_id AS payer_wallet_public_id, c.transaction_id, c.settlement_id,
+                             c.expires_at, c.paid_at, c.created_at, c.updated_at
+                      FROM charges c
+                      JOIN wallets mw ON mw.id = c.merchant_wallet_id
+                      LEFT JOIN wallets pw ON pw.id = c.payer_wallet_id`
+
+// CreateCharge stores a new charge.
+func (r *ChargeRepository) CreateCharge(ctx context.Context, q repository.DBExecutor, charge *domain.Charge) error {
+	query := `INSERT INTO charges (public_id, merchant_wallet_id, amount, currency, description, reference, status,
+                                   expires_at, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		charge.PublicID,
+		charge.MerchantWalletID,
+		charge.Amount,
+		charge.Currency,
+		charge.Description,
+		charge.Reference,
+		charge.Status,
+		charge.ExpiresAt,
+		charge.CreatedAt,
+		charge.UpdatedAt,
+	).Scan(&charge.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create charge: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetChargeByPublicID retrieves a charge by its public UUID.
+func (r *ChargeRepository) GetChargeByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Charge, error) {
+	return r.getCharge(ctx, q, chargeSelect+` WHERE c.public_id = $1`, publicID)
+}
+
+// GetChargeByPublicIDForUpdate retrieves a charge and locks the row until the surrounding transaction ends.
+// It must be called with a transactional DBExecutor.
+func (r *ChargeRepository) GetChargeByPublicIDForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Charge, error) {
+	return r.getCharge(ctx, q, chargeSelect+` WHERE c.public_id = $1 FOR UPDATE OF c`, publicID)
+}
+
+func (r *ChargeRepository) getCharge(ctx context.Context, q repository.DBExecutor, query string, publicID uuid.UUID) (*domain.Charge, error) {
+	var charge domain.Charge
+	if err := q.GetContext(ctx, &charge, query, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get charge %s: %w", publicID, translateError(err))
+	}
+	return &charge, nil
+}
+
+// UpdateCharge stores the status, payer, transaction and payment time of a charge.
+func (r *ChargeRepository) UpdateCharge(ctx context.Context, q repository.DBExecutor, charge *domain.Charge) error {
+	query := `UPDATE charges SET status = $1, payer_wallet_id = $2, transaction_id = $3, paid_at = $4, updated_at = $5 WHERE id = $6`
+	_, err := q.ExecContext(ctx, query, charge.Status, charge.PayerWalletID, charge.TransactionID, charge.PaidAt, charge.UpdatedAt, charge.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update charge %s: %w", charge.PublicID, translateError(err))
+	}
+	return nil
+}
+
+// ListUnsettledChargesForUpdate retrieves a merchant's paid charges that are not yet part of a settlement and
+// were paid before the cutoff, locking them until the surrounding transaction ends.
+func (r *ChargeRepository) ListUnsettledChargesForUpdate(ctx context.Context, q repository.DBExecutor, merchantWalletID int64, paidBefore time.Time) ([]domain.Charge, error) {
+	var charges []domain.Charge
+	query := chargeSelect + `
+              WHERE c.merchant_wallet_id = $1 AND c.status = 'PAID' AND c.settlement_id IS NULL AND c.paid_at < $2
+              ORDER BY c.paid_at
+              FOR UPDATE OF c`
+	if err := q.SelectContext(ctx, &charges, query, merchantWalletID, paidBefore); err != nil {
+		return nil, fmt.Errorf("failed to list unsettled charges of wallet %d: %w", merchantWalletID, translateError(err))
+	}
+	return charges, nil
+}
+
+// MarkChargesSettled links the given charges to a settlement.
+func (r *ChargeRepository) MarkChargesSettled(ctx context.Context, q repository.DBExecutor, settlementID int64, chargeIDs []int64) error {
+	query := `UPDATE charges SET settlement_id = $1, updated_at = $2 WHERE id = ANY($3)`
+	if _, err := q.ExecContext(ctx, query, settlementID, time.Now().UTC(), pq.Int64Array(chargeIDs)); err != nil {
+		return fmt.Errorf("failed to mark charges settled by settlement %d: %w", settlementID, translateError(err))
+	}
+	return nil
+}
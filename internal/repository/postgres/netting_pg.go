@@ -0,0 +1,159 @@
+// internal/repository/postgres/netting_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// NettingRepository implements repository.NettingRepository for PostgreSQL.
+type NettingRepository struct{}
+
+// NewNettingRepository creates a new NettingRepository.
+func NewNettingRepository(db *sqlx.DB) repository.NettingRepository {
+	return &NettingRepository{}
+}
+
+// nettingInstructionSelect projects netting instructions together with the public IDs of both wallets and,
+// once settled, of the settlement and its net transaction.
+const nettingInstructionSelect = `SELECT i.id, i.public_id, i.from_wallet_id, i.to_wallet_id, fw.public_id AS from_wallet_public_id,
+                                         tw.public_id AS to_wallet_public_id, i.amount, i.currency, i.reference, i.status,
+                                         i.settlement_id, s.public_id AS settlement_public_id, s.transaction_id,
+                                         i.settled_at, i.created_at
+                                  FROM netting_instructions i
+                                  JOIN wallets fw ON fw.id = i.from_wallet_id
+                                  JOIN wallets tw ON tw.id = i.to_wallet_id
+                                  LEFT JOIN netting_settlements s ON s.id = i.settlement_id`
+
+// nettingSettlementSelect projects net settlements together with the public IDs of both wallets.
+const nettingSettlementSelect = `SELECT s.id, s.public_id, s.from_wallet_id, s.to_wallet_id, fw.public_id AS from_wallet_public_id,
+                                        tw.public_id AS to_wallet_public_id, s.net_amount, s.gross_amount, s.currency,
+                                        s.instruction_count, s.transaction_id, s.window_end, s.created_at
+                                 FROM netting_settlements s
+                                 JOIN wallets fw ON fw.id = s.from_wallet_id
+                                 JOIN wallets tw ON tw.id = s.to_wallet_id`
+
+// CreateInstruction stores a new pending netting instruction.
+func (r *NettingRepository) CreateInstruction(ctx context.Context, q repository.DBExecutor, instruction *domain.NettingInstruction) error {
+	query := `INSERT INTO netting_instructions (public_id, from_wallet_id, to_wallet_id, amount, currency, reference, status, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		instruction.PublicID,
+		instruction.FromWalletID,
+		instruction.ToWalletID,
+		instruction.Amount,
+		instruction.Currency,
+		instruction.Reference,
+		instruction.Status,
+		instruction.CreatedAt,
+	).Scan(&instruction.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create netting instruction: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetInstructionByPublicID retrieves a netting instruction by its public UUID.
+func (r *NettingRepository) GetInstructionByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.NettingInstruction, error) {
+	var instruction domain.NettingInstruction
+	if err := q.GetContext(ctx, &instruction, nettingInstructionSelect+` WHERE i.public_id = $1`, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get netting instruction %s: %w", publicID, translateError(err))
+	}
+	return &instruction, nil
+}
+
+// ListPendingPairs retrieves the wallet pairs and currencies with pending instructions created before the window end.
+func (r *NettingRepository) ListPendingPairs(ctx context.Context, q repository.DBExecutor, windowEnd time.Time) ([]domain.NettingPair, error) {
+	var pairs []domain.NettingPair
+	query := `SELECT DISTINCT LEAST(from_wallet_id, to_wallet_id) AS wallet_a, GREATEST(from_wallet_id, to_wallet_id) AS wallet_b, currency
+              FROM netting_instructions
+              WHERE status = 'PENDING' AND created_at < $1
+              ORDER BY wallet_a, wallet_b, currency`
+	if err := q.SelectContext(ctx, &pairs, query, windowEnd); err != nil {
+		return nil, fmt.Errorf("failed to list pending netting pairs: %w", translateError(err))
+	}
+	return pairs, nil
+}
+
+// ListPendingInstructionsForUpdate retrieves a pair's pending instructions created before the window end,
+// locking them until the surrounding transaction ends. Instructions settled by a concurrent run while this
+// one waited for the locks are no longer pending and are left out.
+func (r *NettingRepository) ListPendingInstructionsForUpdate(ctx context.Context, q repository.DBExecutor, pair domain.NettingPair, windowEnd time.Time) ([]domain.NettingInstruction, error) {
+	var instructions []domain.NettingInstruction
+	query := nettingInstructionSelect + `
+              WHERE LEAST(i.from_wallet_id, i.to_wallet_id) = $1 AND GREATEST(i.from_wallet_id, i.to_wallet_id) = $2
+                AND i.currency = $3 AND i.status = 'PENDING' AND i.created_at < $4
+              ORDER BY i.created_at
+              FOR UPDATE OF i`
+	if err := q.SelectContext(ctx, &instructions, query, pair.WalletA, pair.WalletB, pair.Currency, windowEnd); err != nil {
+		return nil, fmt.Errorf("failed to list pending netting instructions of wallets %d and %d: %w", pair.WalletA, pair.WalletB, translateError(err))
+	}
+	return instructions, nil
+}
+
+// CreateSettlement stores a net settlement.
+func (r *NettingRepository) CreateSettlement(ctx context.Context, q repository.DBExecutor, settlement *domain.NettingSettlement) error {
+	query := `INSERT INTO netting_settlements (public_id, from_wallet_id, to_wallet_id, net_amount, gross_amount, currency,
+                                               instruction_count, transaction_id, window_end, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		settlement.PublicID,
+		settlement.FromWalletID,
+		settlement.ToWalletID,
+		settlement.NetAmount,
+		settlement.GrossAmount,
+		settlement.Currency,
+		settlement.InstructionCount,
+		settlement.TransactionID,
+		settlement.WindowEnd,
+		settlement.CreatedAt,
+	).Scan(&settlement.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create netting settlement: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetSettlementByPublicID retrieves a net settlement by its public UUID.
+func (r *NettingRepository) GetSettlementByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.NettingSettlement, error) {
+	var settlement domain.NettingSettlement
+	if err := q.GetContext(ctx, &settlement, nettingSettlementSelect+` WHERE s.public_id = $1`, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get netting settlement %s: %w", publicID, translateError(err))
+	}
+	return &settlement, nil
+}
+
+// MarkInstructionsSettled links the given instructions to a settlement.
+func (r *NettingRepository) MarkInstructionsSettled(ctx context.Context, q repository.DBExecutor, settlementID int64, instructionIDs []int64, at time.Time) error {
+	query := `UPDATE netting_instructions SET status = 'SETTLED', settlement_id = $1, settled_at = $2 WHERE id = ANY($3)`
+	if _, err := q.ExecContext(ctx, query, settlementID, at, pq.Int64Array(instructionIDs)); err != nil {
+		return fmt.Errorf("failed to mark netting instructions settled by settlement %d: %w", settlementID, translateError(err))
+	}
+	return nil
+}
+
+// ListSettlementInstructions retrieves the instructions settled by a settlement, oldest first.
+func (r *NettingRepository) ListSettlementInstructions(ctx context.Context, q repository.DBExecutor, settlementID int64) ([]domain.NettingInstruction, error) {
+	var instructions []domain.NettingInstruction
+	query := nettingInstructionSelect + ` WHERE i.settlement_id = $1 ORDER BY i.created_at`
+	if err := q.SelectContext(ctx, &instructions, query, settlementID); err != nil {
+		return nil, fmt.Errorf("failed to list instructions of netting settlement %d: %w", settlementID, translateError(err))
+	}
+	return instructions, nil
+}
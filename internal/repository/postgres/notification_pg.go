@@ -0,0 +1,300 @@
+// internal/repository/postgres/notification_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// NotificationRepository implements repository.NotificationRepository for PostgreSQL.
+type NotificationRepository struct{}
+
+// NewNotificationRepository creates a new NotificationRepository.
+func NewNotificationRepository(db *sqlx.DB) repository.NotificationRepository {
+	return &NotificationRepository{}
+}
+
+const notificationColumns = `id, user_id, category, title, body, data, read_at, created_at`
+
+const deviceTokenColumns = `id, user_id, platform, token, label, created_at, updated_at`
+
+// deliveryColumns are the notification_deliveries columns, qualified for joining the device.
+const deliveryColumns = `d.id, d.user_id, d.notification_id, d.device_token_id, d.category, d.channel, d.title, d.body, d.data, d.status,
+       d.attempts, d.provider_message_id, d.last_error, d.created_at, d.sent_at`
+
+// notificationRow maps a notifications row; the data is JSONB.
+type notificationRow struct {
+	domain.Notification
+	DataJSON []byte `db:"data"`
+}
+
+func (row *notificationRow) toDomain() (domain.Notification, error) {
+	notification := row.Notification
+	if err := json.Unmarshal(row.DataJSON, &notification.Data); err != nil {
+		return notification, fmt.Errorf("failed to decode data of notification %d: %w", notification.ID, err)
+	}
+	return notification, nil
+}
+
+// encodeNotificationData encodes the data of a notification or delivery for its JSONB column.
+func encodeNotificationData(data map[string]string) ([]byte, error) {
+	if data == nil {
+		return []byte(`{}`), nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification data: %w", err)
+	}
+	return encoded, nil
+}
+
+// CreateNotification inserts a notification into the user's inbox and fills in its ID and creation time.
+func (r *NotificationRepository) CreateNotification(ctx context.Context, q repository.DBExecutor, notification *domain.Notification) error {
+	data, err := encodeNotificationData(notification.Data)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO notifications (user_id, category, title, body, data)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING id, created_at`
+	err = q.QueryRowContext(ctx, query, notification.UserID, notification.Category, notification.Title, notification.Body, data).
+		Scan(&notification.ID, &notification.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification for user %d: %w", notification.UserID, translateError(err))
+	}
+	return nil
+}
+
+// ListNotifications retrieves a page of the user's notifications and the total count matching.
+func (r *NotificationRepository) ListNotifications(ctx context.Context, q repository.DBExecutor, userID int64, unreadOnly bool, limit, offset int) ([]domain.Notification, int64, error) {
+	where := `WHERE user_id = $1`
+	if unreadOnly {
+		where += ` AND read_at IS NULL`
+	}
+	var total int64
+	if err := q.GetContext(ctx, &total, `SELECT COUNT(*) FROM notifications `+where, userID); err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications of user %d: %w", userID, translateError(err))
+	}
+
+	var rows []notificationRow
+	query := `SELECT ` + notificationColumns + ` FROM notifications ` + where + ` ORDER BY id DESC LIMIT $2 OFFSET $3`
+	if err := q.SelectContext(ctx, &rows, query, userID, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications of user %d: %w", userID, translateError(err))
+	}
+	notifications := make([]domain.Notification, len(rows))
+	for i := range rows {
+		notification, err := rows[i].toDomain()
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications[i] = notification
+	}
+	return notifications, total, nil
+}
+
+// CountUnreadNotifications counts the user's unread notifications.
+func (r *NotificationRepository) CountUnreadNotifications(ctx context.Context, q repository.DBExecutor, userID int64) (int64, error) {
+	var unread int64
+	if err := q.GetContext(ctx, &unread, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications of user %d: %w", userID, translateError(err))
+	}
+	return unread, nil
+}
+
+// MarkNotificationRead sets the read time of a notification of the user, keeping an earlier one.
+func (r *NotificationRepository) MarkNotificationRead(ctx context.Context, q repository.DBExecutor, userID, notificationID int64, at time.Time) (*domain.Notification, error) {
+	var row notificationRow
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, $3)
+              WHERE id = $1 AND user_id = $2
+              RETURNING ` + notificationColumns
+	if err := q.GetContext(ctx, &row, query, notificationID, userID, at); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to mark notification %d read: %w", notificationID, translateError(err))
+	}
+	notification, err := row.toDomain()
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// MarkAllNotificationsRead sets the read time of every unread notification of the user.
+func (r *NotificationRepository) MarkAllNotificationsRead(ctx context.Context, q repository.DBExecutor, userID int64, at time.Time) (int64, error) {
+	result, err := q.ExecContext(ctx, `UPDATE notifications SET read_at = $2 WHERE user_id = $1 AND read_at IS NULL`, userID, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications of user %d read: %w", userID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected after marking notifications of user %d read: %w", userID, err)
+	}
+	return rows, nil
+}
+
+// deliveryRow maps a notification_deliveries row; the data is JSONB.
+type deliveryRow struct {
+	domain.NotificationDelivery
+	DataJSON []byte `db:"data"`
+}
+
+func (row *deliveryRow) toDomain() (domain.NotificationDelivery, error) {
+	delivery := row.NotificationDelivery
+	if err := json.Unmarshal(row.DataJSON, &delivery.Data); err != nil {
+		return delivery, fmt.Errorf("failed to decode data of notification delivery %d: %w", delivery.ID, err)
+	}
+	return delivery, nil
+}
+
+func deliveriesToDomain(rows []deliveryRow) ([]domain.NotificationDelivery, error) {
+	deliveries := make([]domain.NotificationDelivery, len(rows))
+	for i := range rows {
+		delivery, err := rows[i].toDomain()
+		if err != nil {
+			return nil, err
+		}
+		deliveries[i] = delivery
+	}
+	return deliveries, nil
+}
+
+// RegisterDevice inserts the device, or takes over the row of the same platform and token, and fills in its
+// ID and timestamps.
+func (r *NotificationRepository) RegisterDevice(ctx context.Context, q repository.DBExecutor, device *domain.DeviceToken) error {
+	query := `INSERT INTO device_tokens (user_id, platform, token, label)
+              VALUES ($1, $2, $3, $4)
+              ON CONFLICT (platform, token) DO UPDATE
+              SET user_id = EXCLUDED.user_id, label = EXCLUDED.label, updated_at = NOW()
+              RETURNING id, created_at, updated_at`
+	err := q.QueryRowContext(ctx, query, device.UserID, device.Platform, device.Token, device.Label).
+		Scan(&device.ID, &device.CreatedAt, &device.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to register %s device of user %d: %w", device.Platform, device.UserID, translateError(err))
+	}
+	return nil
+}
+
+// ListDevices retrieves the user's devices, most recently registered first.
+func (r *NotificationRepository) ListDevices(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.DeviceToken, error) {
+	var devices []domain.DeviceToken
+	query := `SELECT ` + deviceTokenColumns + ` FROM device_tokens WHERE user_id = $1 ORDER BY updated_at DESC, id DESC`
+	if err := q.SelectContext(ctx, &devices, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list devices of user %d: %w", userID, translateError(err))
+	}
+	return devices, nil
+}
+
+// DeleteDevice removes a device of the user. Its deliveries are kept, without the device.
+func (r *NotificationRepository) DeleteDevice(ctx context.Context, q repository.DBExecutor, userID, deviceID int64) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM device_tokens WHERE id = $1 AND user_id = $2`, deviceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete device %d of user %d: %w", deviceID, userID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting device %d of user %d: %w", deviceID, userID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// ListPreferences retrieves the user's stored notification preferences.
+func (r *NotificationRepository) ListPreferences(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.NotificationPreference, error) {
+	var preferences []domain.NotificationPreference
+	query := `SELECT user_id, category, channel, enabled, updated_at FROM notification_preferences WHERE user_id = $1 ORDER BY category, channel`
+	if err := q.SelectContext(ctx, &preferences, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list notification preferences of user %d: %w", userID, translateError(err))
+	}
+	return preferences, nil
+}
+
+// SavePreference creates or replaces a notification preference and fills in its update time.
+func (r *NotificationRepository) SavePreference(ctx context.Context, q repository.DBExecutor, preference *domain.NotificationPreference) error {
+	query := `INSERT INTO notification_preferences (user_id, category, channel, enabled)
+              VALUES ($1, $2, $3, $4)
+              ON CONFLICT (user_id, category, channel) DO UPDATE
+              SET enabled = EXCLUDED.enabled, updated_at = NOW()
+              RETURNING updated_at`
+	err := q.QueryRowContext(ctx, query, preference.UserID, preference.Category, preference.Channel, preference.Enabled).
+		Scan(&preference.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save %s %s preference of user %d: %w", preference.Category, preference.Channel, preference.UserID, translateError(err))
+	}
+	return nil
+}
+
+// CreateDelivery inserts a delivery and fills in its ID and creation time.
+func (r *NotificationRepository) CreateDelivery(ctx context.Context, q repository.DBExecutor, delivery *domain.NotificationDelivery) error {
+	data, err := encodeNotificationData(delivery.Data)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO notification_deliveries (user_id, notification_id, device_token_id, category, channel, title, body, data, status, attempts)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+              RETURNING id, created_at`
+	err = q.QueryRowContext(ctx, query, delivery.UserID, delivery.NotificationID, delivery.DeviceID, delivery.Category, delivery.Channel,
+		delivery.Title, delivery.Body, data, delivery.Status, delivery.Attempts).Scan(&delivery.ID, &delivery.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification delivery for user %d: %w", delivery.UserID, translateError(err))
+	}
+	return nil
+}
+
+// ClaimPendingDeliveries locks pending deliveries with their device's platform and token, which are nil if the
+// device was removed since.
+func (r *NotificationRepository) ClaimPendingDeliveries(ctx context.Context, q repository.DBExecutor, limit int) ([]domain.NotificationDelivery, error) {
+	var rows []deliveryRow
+	query := `SELECT ` + deliveryColumns + `, t.platform, t.token
+              FROM notification_deliveries d
+              LEFT JOIN device_tokens t ON t.id = d.device_token_id
+              WHERE d.status = 'PENDING'
+              ORDER BY d.id
+              LIMIT $1
+              FOR UPDATE OF d SKIP LOCKED`
+	if err := q.SelectContext(ctx, &rows, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to claim pending notification deliveries: %w", translateError(err))
+	}
+	return deliveriesToDomain(rows)
+}
+
+// UpdateDelivery stores the status, attempts, provider message ID, last error and send time of a delivery.
+func (r *NotificationRepository) UpdateDelivery(ctx context.Context, q repository.DBExecutor, delivery *domain.NotificationDelivery) error {
+	query := `UPDATE notification_deliveries
+              SET status = $2, attempts = $3, provider_message_id = $4, last_error = $5, sent_at = $6, updated_at = NOW()
+              WHERE id = $1`
+	result, err := q.ExecContext(ctx, query, delivery.ID, delivery.Status, delivery.Attempts, delivery.ProviderMessageID,
+		delivery.LastError, delivery.SentAt)
+	if err != nil {
+		return fmt.Errorf("failed to update notification delivery %d: %w", delivery.ID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after updating notification delivery %d: %w", delivery.ID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// ListDeliveries retrieves the user's most recent deliveries.
+func (r *NotificationRepository) ListDeliveries(ctx context.Context, q repository.DBExecutor, userID int64, limit int) ([]domain.NotificationDelivery, error) {
+	var rows []deliveryRow
+	query := `SELECT ` + deliveryColumns + ` FROM notification_deliveries d WHERE d.user_id = $1 ORDER BY d.id DESC LIMIT $2`
+	if err := q.SelectContext(ctx, &rows, query, userID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list notification deliveries of user %d: %w", userID, translateError(err))
+	}
+	return deliveriesToDomain(rows)
+}
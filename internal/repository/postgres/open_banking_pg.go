@@ -0,0 +1,151 @@
+// internal/repository/postgres/open_banking_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// OpenBankingRepository implements repository.OpenBankingRepository for PostgreSQL.
+type OpenBankingRepository struct{}
+
+// NewOpenBankingRepository creates a new OpenBankingRepository.
+func NewOpenBankingRepository(db *sqlx.DB) repository.OpenBankingRepository {
+	return &OpenBankingRepository{}
+}
+
+// obConsentRow maps an ob_consents row; the permissions and wallets are Postgres arrays.
+type obConsentRow struct {
+	ID                      int64          `db:"id"`
+	ConsentID               uuid.UUID      `db:"consent_id"`
+	ClientID                string         `db:"client_id"`
+	UserID                  *int64         `db:"user_id"`
+	Permissions             pq.StringArray `db:"permission_list"`
+	WalletIDs               pq.Int64Array  `db:"wallet_ids"`
+	WalletPublicIDs         pq.StringArray `db:"wallet_public_ids"`
+	Status                  string         `db:"status"`
+	ExpirationDateTime      time.Time      `db:"expires_at"`
+	TransactionFromDateTime *time.Time     `db:"transaction_from"`
+	TransactionToDateTime   *time.Time     `db:"transaction_to"`
+	CreationDateTime        time.Time      `db:"created_at"`
+	StatusUpdateDateTime    time.Time      `db:"status_updated_at"`
+}
+
+func (row obConsentRow) toDomain() (*domain.OBConsent, error) {
+	consent := &domain.OBConsent{
+		ID:                      row.ID,
+		ConsentID:               row.ConsentID,
+		ClientID:                row.ClientID,
+		UserID:                  row.UserID,
+		Permissions:             make([]domain.OBPermission, len(row.Permissions)),
+		WalletIDs:               []int64(row.WalletIDs),
+		WalletPublicIDs:         make([]uuid.UUID, len(row.WalletPublicIDs)),
+		Status:                  domain.OBConsentStatus(row.Status),
+		ExpirationDateTime:      row.ExpirationDateTime,
+		TransactionFromDateTime: row.TransactionFromDateTime,
+		TransactionToDateTime:   row.TransactionToDateTime,
+		CreationDateTime:        row.CreationDateTime,
+		StatusUpdateDateTime:    row.StatusUpdateDateTime,
+	}
+	for i, permission := range row.Permissions {
+		consent.Permissions[i] = domain.OBPermission(permission)
+	}
+	for i, publicID := range row.WalletPublicIDs {
+		parsed, err := uuid.Parse(publicID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse wallet public ID of consent %s: %w", row.ConsentID, err)
+		}
+		consent.WalletPublicIDs[i] = parsed
+	}
+	return consent, nil
+}
+
+// obConsentSelect projects consents together with the public IDs of the wallets they cover, in wallet_ids order.
+const obConsentSelect = `SELECT c.id, c.consent_id, c.client_id, c.user_id, c.permission_list, c.wallet_ids,
+                                ARRAY(SELECT w.public_id::TEXT FROM wallets w WHERE w.id = ANY(c.wallet_ids) ORDER BY w.id) AS wallet_public_ids,
+                                c.status, c.expires_at, c.transaction_from, c.transaction_to, c.created_at, c.status_updated_at
+                         FROM ob_consents c`
+
+// CreateConsent stores a new consent awaiting authorisation.
+func (r *OpenBankingRepository) CreateConsent(ctx context.Context, q repository.DBExecutor, consent *domain.OBConsent) error {
+	permissions := make(pq.StringArray, len(consent.Permissions))
+	for i, permission := range consent.Permissions {
+		permissions[i] = string(permission)
+	}
+	query := `INSERT INTO ob_consents (consent_id, client_id, permission_list, status, expires_at,
+                                       transaction_from, transaction_to, created_at, status_updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		consent.ConsentID,
+		consent.ClientID,
+		permissions,
+		consent.Status,
+		consent.ExpirationDateTime,
+		consent.TransactionFromDateTime,
+		consent.TransactionToDateTime,
+		consent.CreationDateTime,
+		consent.StatusUpdateDateTime,
+	).Scan(&consent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create consent: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetConsent retrieves a consent by its consent ID.
+func (r *OpenBankingRepository) GetConsent(ctx context.Context, q repository.DBExecutor, consentID uuid.UUID) (*domain.OBConsent, error) {
+	var row obConsentRow
+	if err := q.GetContext(ctx, &row, obConsentSelect+` WHERE c.consent_id = $1`, consentID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get consent %s: %w", consentID, translateError(err))
+	}
+	return row.toDomain()
+}
+
+// ListConsentsByUser retrieves the consents a user authorised or rejected, newest first.
+func (r *OpenBankingRepository) ListConsentsByUser(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.OBConsent, error) {
+	var rows []obConsentRow
+	if err := q.SelectContext(ctx, &rows, obConsentSelect+` WHERE c.user_id = $1 ORDER BY c.id DESC`, userID); err != nil {
+		return nil, fmt.Errorf("failed to list consents of user %d: %w", userID, translateError(err))
+	}
+	consents := make([]domain.OBConsent, 0, len(rows))
+	for _, row := range rows {
+		consent, err := row.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, *consent)
+	}
+	return consents, nil
+}
+
+// ResolveConsent records the user's answer in a conditional UPDATE, so a consent is answered at most once.
+func (r *OpenBankingRepository) ResolveConsent(ctx context.Context, q repository.DBExecutor, id int64, status domain.OBConsentStatus, userID int64, walletIDs []int64, at time.Time) error {
+	if walletIDs == nil {
+		walletIDs = []int64{} // A NULL array would violate NOT NULL
+	}
+	query := `UPDATE ob_consents
+              SET status = $2, user_id = $3, wallet_ids = $4, status_updated_at = $5
+              WHERE id = $1 AND status = $6`
+	return execOneRow(ctx, q, "consent", query, id, status, userID, pq.Int64Array(walletIDs), at, domain.OBConsentAwaitingAuthorisation)
+}
+
+// RevokeConsent revokes a consent in a conditional UPDATE; rejected and revoked consents are final.
+func (r *OpenBankingRepository) RevokeConsent(ctx context.Context, q repository.DBExecutor, id int64, at time.Time) error {
+	query := `UPDATE ob_consents
+              SET status = $2, status_updated_at = $3
+              WHERE id = $1 AND status IN ($4, $5)`
+	return execOneRow(ctx, q, "consent", query, id, domain.OBConsentRevoked, at, domain.OBConsentAwaitingAuthorisation, domain.OBConsentAuthorised)
+}
@@ -0,0 +1,113 @@
+// internal/repository/postgres/ownership_transfer_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// OwnershipTransferRepository implements repository.OwnershipTransferRepository for PostgreSQL.
+type OwnershipTransferRepository struct{}
+
+// NewOwnershipTransferRepository creates a new OwnershipTransferRepository.
+func NewOwnershipTransferRepository(db *sqlx.DB) repository.OwnershipTransferRepository {
+	return &OwnershipTransferRepository{}
+}
+
+// ownershipTransferSelect projects transfers together with the public ID of their wallet.
+const ownershipTransferSelect = `SELECT t.id, t.public_id, t.wallet_id, w.public_id AS wallet_public_id, t.from_user_id, t.to_user_id,
+                                        t.status, t.reason, t.requested_by, t.cancelled_by, t.expires_at, t.created_at, t.resolved_at
+                                 FROM wallet_ownership_transfers t
+                                 JOIN wallets w ON w.id = t.wallet_id`
+
+// CreateOwnershipTransfer stores a new pending transfer.
+func (r *OwnershipTransferRepository) CreateOwnershipTransfer(ctx context.Context, q repository.DBExecutor, transfer *domain.OwnershipTransfer) error {
+	query := `INSERT INTO wallet_ownership_transfers (public_id, wallet_id, from_user_id, to_user_id, status, reason, requested_by, expires_at, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		transfer.PublicID,
+		transfer.WalletID,
+		transfer.FromUserID,
+		transfer.ToUserID,
+		transfer.Status,
+		transfer.Reason,
+		transfer.RequestedBy,
+		transfer.ExpiresAt,
+		transfer.CreatedAt,
+	).Scan(&transfer.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create ownership transfer: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetOwnershipTransfer retrieves a transfer by its public UUID.
+func (r *OwnershipTransferRepository) GetOwnershipTransfer(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	return r.getOwnershipTransfer(ctx, q, ownershipTransferSelect+` WHERE t.public_id = $1`, publicID)
+}
+
+// GetOwnershipTransferForUpdate retrieves a transfer by its public UUID and locks its row.
+func (r *OwnershipTransferRepository) GetOwnershipTransferForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	return r.getOwnershipTransfer(ctx, q, ownershipTransferSelect+` WHERE t.public_id = $1 FOR UPDATE OF t`, publicID)
+}
+
+func (r *OwnershipTransferRepository) getOwnershipTransfer(ctx context.Context, q repository.DBExecutor, query string, publicID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	var transfer domain.OwnershipTransfer
+	if err := q.GetContext(ctx, &transfer, query, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get ownership transfer %s: %w", publicID, translateError(err))
+	}
+	return &transfer, nil
+}
+
+// ListWalletOwnershipTransfers retrieves the transfers of a wallet, newest first.
+func (r *OwnershipTransferRepository) ListWalletOwnershipTransfers(ctx context.Context, q repository.DBExecutor, walletID int64, limit int) ([]domain.OwnershipTransfer, error) {
+	transfers := []domain.OwnershipTransfer{}
+	query := ownershipTransferSelect + ` WHERE t.wallet_id = $1 ORDER BY t.id DESC LIMIT $2`
+	if err := q.SelectContext(ctx, &transfers, query, walletID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list ownership transfers of wallet %d: %w", walletID, translateError(err))
+	}
+	return transfers, nil
+}
+
+// ListIncomingOwnershipTransfers retrieves the transfers to a user, newest first.
+func (r *OwnershipTransferRepository) ListIncomingOwnershipTransfers(ctx context.Context, q repository.DBExecutor, toUserID int64, status domain.OwnershipTransferStatus, limit int) ([]domain.OwnershipTransfer, error) {
+	transfers := []domain.OwnershipTransfer{}
+	query := ownershipTransferSelect + ` WHERE t.to_user_id = $1 AND ($2 = '' OR t.status = $2) ORDER BY t.id DESC LIMIT $3`
+	if err := q.SelectContext(ctx, &transfers, query, toUserID, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list ownership transfers to user %d: %w", toUserID, translateError(err))
+	}
+	return transfers, nil
+}
+
+// ListExpiredOwnershipTransfers retrieves the pending transfers past their expiry, oldest first.
+func (r *OwnershipTransferRepository) ListExpiredOwnershipTransfers(ctx context.Context, q repository.DBExecutor, before time.Time, limit int) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	query := `SELECT public_id FROM wallet_ownership_transfers
+              WHERE status = 'PENDING' AND expires_at < $1
+              ORDER BY expires_at LIMIT $2`
+	if err := q.SelectContext(ctx, &ids, query, before, limit); err != nil {
+		return nil, fmt.Errorf("failed to list expired ownership transfers: %w", translateError(err))
+	}
+	return ids, nil
+}
+
+// ResolveOwnershipTransfer records the outcome of a pending transfer.
+func (r *OwnershipTransferRepository) ResolveOwnershipTransfer(ctx context.Context, q repository.DBExecutor, transfer *domain.OwnershipTransfer) error {
+	query := `UPDATE wallet_ownership_transfers
+              SET status = $1, cancelled_by = $2, resolved_at = $3
+              WHERE id = $4 AND status = $5`
+	return execOneRow(ctx, q, fmt.Sprintf("ownership transfer %d", transfer.ID), query,
+		transfer.Status, transfer.CancelledBy, transfer.ResolvedAt, transfer.ID, domain.OwnershipTransferPending)
+}
@@ -0,0 +1,92 @@
+// internal/repository/postgres/pin_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// PINRepository implements repository.PINRepository for PostgreSQL.
+type PINRepository struct{}
+
+// NewPINRepository creates a new PINRepository.
+func NewPINRepository(db *sqlx.DB) repository.PINRepository {
+	return &PINRepository{}
+}
+
+const pinColumns = `user_id, pin_hash, failed_attempts, locked_until, step_up_until, created_at, updated_at`
+
+// GetPIN retrieves a user's PIN.
+func (r *PINRepository) GetPIN(ctx context.Context, q repository.DBExecutor, userID int64) (*domain.UserPIN, error) {
+	return r.getPIN(ctx, q, `SELECT `+pinColumns+` FROM user_pins WHERE user_id = $1`, userID)
+}
+
+// GetPINForUpdate retrieves a user's PIN and locks its row.
+func (r *PINRepository) GetPINForUpdate(ctx context.Context, q repository.DBExecutor, userID int64) (*domain.UserPIN, error) {
+	return r.getPIN(ctx, q, `SELECT `+pinColumns+` FROM user_pins WHERE user_id = $1 FOR UPDATE`, userID)
+}
+
+func (r *PINRepository) getPIN(ctx context.Context, q repository.DBExecutor, query string, userID int64) (*domain.UserPIN, error) {
+	var pin domain.UserPIN
+	if err := q.GetContext(ctx, &pin, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get PIN of user %d: %w", userID, translateError(err))
+	}
+	return &pin, nil
+}
+
+// SavePIN creates or replaces a user's PIN, clearing its failed attempts, lockout and step-up.
+func (r *PINRepository) SavePIN(ctx context.Context, q repository.DBExecutor, pin *domain.UserPIN) error {
+	query := `INSERT INTO user_pins (user_id, pin_hash, failed_attempts, locked_until, step_up_until, created_at, updated_at)
+              VALUES ($1, $2, 0, NULL, NULL, $3, $3)
+              ON CONFLICT (user_id) DO UPDATE
+              SET pin_hash = EXCLUDED.pin_hash, failed_attempts = 0, locked_until = NULL, step_up_until = NULL, updated_at = EXCLUDED.updated_at
+              RETURNING created_at`
+	if err := q.QueryRowContext(ctx, query, pin.UserID, pin.PINHash, pin.UpdatedAt).Scan(&pin.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save PIN of user %d: %w", pin.UserID, translateError(err))
+	}
+	pin.FailedAttempts, pin.LockedUntil, pin.StepUpUntil = 0, nil, nil
+	return nil
+}
+
+// UpdateAttempts stores the failed attempts, lockout and step-up state of a user's PIN.
+func (r *PINRepository) UpdateAttempts(ctx context.Context, q repository.DBExecutor, pin *domain.UserPIN) error {
+	query := `UPDATE user_pins SET failed_attempts = $2, locked_until = $3, step_up_until = $4, updated_at = $5 WHERE user_id = $1`
+	result, err := q.ExecContext(ctx, query, pin.UserID, pin.FailedAttempts, pin.LockedUntil, pin.StepUpUntil, pin.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update PIN attempts of user %d: %w", pin.UserID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after updating PIN attempts of user %d: %w", pin.UserID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// DeletePIN removes a user's PIN.
+func (r *PINRepository) DeletePIN(ctx context.Context, q repository.DBExecutor, userID int64) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM user_pins WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete PIN of user %d: %w", userID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting PIN of user %d: %w", userID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// internal/repository/postgres/promo_credit_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// PromoCreditRepository implements repository.PromoCreditRepository for PostgreSQL.
+type PromoCreditRepository struct{}
+
+// NewPromoCreditRepository creates a new PromoCreditRepository.
+func NewPromoCreditRepository(db *sqlx.DB) repository.PromoCreditRepository {
+	return &PromoCreditRepository{}
+}
+
+// promoCreditSelect projects grants together with the public ID of their wallet.
+const promoCreditSelect = `SELECT c.id, c.public_id, c.wallet_id, w.public_id AS wallet_public_id, c.amount, c.remaining,
+                                  c.allow_withdrawal, c.reason, c.status, c.expires_at, c.created_at, c.updated_at
+                           FROM promo_credits c
+                           JOIN wallets w ON w.id = c.wallet_id`
+
+// CreateCredit stores a new grant.
+func (r *PromoCreditRepository) CreateCredit(ctx context.Context, q repository.DBExecutor, credit *domain.PromoCredit) error {
+	query := `INSERT INTO promo_credits (public_id, wallet_id, amount, remaining, allow_withdrawal, reason, status,
+                                         expires_at, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		credit.PublicID,
+		credit.WalletID,
+		credit.Amount,
+		credit.Remaining,
+		credit.AllowWithdrawal,
+		credit.Reason,
+		credit.Status,
+		credit.ExpiresAt,
+		credit.CreatedAt,
+		credit.UpdatedAt,
+	).Scan(&credit.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create promo credit: %w", translateError(err))
+	}
+	return nil
+}
+
+// ListCreditsByWallet retrieves all grants of a wallet, newest first.
+func (r *PromoCreditRepository) ListCreditsByWallet(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.PromoCredit, error) {
+	var credits []domain.PromoCredit
+	query := promoCreditSelect + ` WHERE c.wallet_id = $1 ORDER BY c.created_at DESC, c.id DESC`
+	if err := q.SelectContext(ctx, &credits, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to list promo credits of wallet %d: %w", walletID, translateError(err))
+	}
+	return credits, nil
+}
+
+// ListSpendableCreditsForUpdate retrieves a wallet's grants that can be spent at now, soonest expiring first,
+// and locks them until the surrounding transaction ends, so two debits cannot spend the same credit.
+// It must be called with a transactional DBExecutor.
+func (r *PromoCreditRepository) ListSpendableCreditsForUpdate(ctx context.Context, q repository.DBExecutor, walletID int64, now time.Time, forWithdrawal bool) ([]domain.PromoCredit, error) {
+	var credits []domain.PromoCredit
+	query := promoCreditSelect + `
+              WHERE c.wallet_id = $1 AND c.status = 'ACTIVE' AND c.remaining > 0 AND c.expires_at > $2
+                AND (c.allow_withdrawal OR NOT $3)
+              ORDER BY c.expires_at, c.id
+              FOR UPDATE OF c`
+	if err := q.SelectContext(ctx, &credits, query, walletID, now, forWithdrawal); err != nil {
+		return nil, fmt.Errorf("failed to list spendable promo credits of wallet %d: %w", walletID, translateError(err))
+	}
+	return credits, nil
+}
+
+// ConsumeCredit takes amount off a grant's remaining credit; the table's check constraint rejects overspending.
+func (r *PromoCreditRepository) ConsumeCredit(ctx context.Context, q repository.DBExecutor, creditID int64, amount decimal.Decimal) error {
+	query := `UPDATE promo_credits SET remaining = remaining - $1, updated_at = $2 WHERE id = $3`
+	if _, err := q.ExecContext(ctx, query, amount, time.Now().UTC(), creditID); err != nil {
+		return fmt.Errorf("failed to consume promo credit %d: %w", creditID, translateError(err))
+	}
+	return nil
+}
+
+// ExpireCredits marks every ACTIVE grant past its expiry EXPIRED and returns the grants it expired.
+func (r *PromoCreditRepository) ExpireCredits(ctx context.Context, q repository.DBExecutor, now time.Time) ([]domain.PromoCredit, error) {
+	var credits []domain.PromoCredit
+	query := `UPDATE promo_credits SET status = 'EXPIRED', updated_at = $1
+              WHERE status = 'ACTIVE' AND expires_at <= $1
+              RETURNING id, public_id, wallet_id, amount, remaining, allow_withdrawal, reason, status, expires_at,
+                        created_at, updated_at`
+	if err := q.SelectContext(ctx, &credits, query, now); err != nil {
+		return nil, fmt.Errorf("failed to expire promo credits: %w", translateError(err))
+	}
+	return credits, nil
+}
@@ -0,0 +1,73 @@
+// internal/repository/postgres/ramp_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// RampRepository implements repository.RampRepository for PostgreSQL.
+type RampRepository struct{}
+
+// NewRampRepository creates a new RampRepository.
+func NewRampRepository(db *sqlx.DB) repository.RampRepository {
+	return &RampRepository{}
+}
+
+// rampOrderSelect projects ramp orders together with the pricing of their quote and the public IDs of its wallets.
+const rampOrderSelect = `SELECT o.id, o.public_id, o.direction, o.quote_id, o.status, o.debit_transaction_id,
+                                o.credit_transaction_id, o.executed_at, o.created_at,
+                                tq.public_id AS quote_public_id, tq.from_wallet_id, tq.to_wallet_id,
+                                fw.public_id AS from_wallet_public_id, tw.public_id AS to_wallet_public_id,
+                                tq.amount, tq.currency, tq.fee, tq.credit_amount, tq.credit_currency, tq.rate, tq.expires_at
+                         FROM ramp_orders o
+                         JOIN transfer_quotes tq ON tq.id = o.quote_id
+                         JOIN wallets fw ON fw.id = tq.from_wallet_id
+                         JOIN wallets tw ON tw.id = tq.to_wallet_id`
+
+// CreateRamp stores a new quoted ramp order.
+func (r *RampRepository) CreateRamp(ctx context.Context, q repository.DBExecutor, order *domain.RampOrder) error {
+	query := `INSERT INTO ramp_orders (public_id, direction, quote_id, status, created_at)
+              VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		order.PublicID,
+		order.Direction,
+		order.QuoteID,
+		order.Status,
+		order.CreatedAt,
+	).Scan(&order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create ramp order: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetRampByPublicID retrieves a ramp order by its public ID.
+func (r *RampRepository) GetRampByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.RampOrder, error) {
+	var order domain.RampOrder
+	if err := q.GetContext(ctx, &order, rampOrderSelect+` WHERE o.public_id = $1`, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get ramp order %s: %w", publicID, translateError(err))
+	}
+	return &order, nil
+}
+
+// MarkRampExecuted links a quoted order to its CONVERSION legs in a conditional UPDATE, so an order is
+// executed at most once.
+func (r *RampRepository) MarkRampExecuted(ctx context.Context, q repository.DBExecutor, id int64, debitTransactionID, creditTransactionID uuid.UUID, executedAt time.Time) error {
+	query := `UPDATE ramp_orders
+              SET status = $2, debit_transaction_id = $3, credit_transaction_id = $4, executed_at = $5
+              WHERE id = $1 AND status = $6`
+	return execOneRow(ctx, q, "ramp order", query, id, domain.RampExecuted, debitTransactionID, creditTransactionID, executedAt, domain.RampQuoted)
+}
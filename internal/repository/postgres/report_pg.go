@@ -0,0 +1,75 @@
+// internal/repository/postgres/report_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// ReportRepository implements repository.ReportRepository for PostgreSQL.
+type ReportRepository struct{}
+
+// NewReportRepository creates a new ReportRepository.
+func NewReportRepository(db *sqlx.DB) repository.ReportRepository {
+	return &ReportRepository{}
+}
+
+// SumBalances totals the balances of live wallets per currency.
+func (r *ReportRepository) SumBalances(ctx context.Context, q repository.DBExecutor) ([]domain.CurrencyLiability, error) {
+	var liabilities []domain.CurrencyLiability
+	query := `SELECT currency, SUM(` + walletBalanceOf("wallets") + `) AS total_balance, COUNT(*) AS wallet_count
+              FROM wallets
+              WHERE deleted_at IS NULL
+              GROUP BY currency
+              ORDER BY currency`
+	if err := q.SelectContext(ctx, &liabilities, query); err != nil {
+		return nil, fmt.Errorf("failed to sum wallet balances: %w", translateError(err))
+	}
+	return liabilities, nil
+}
+
+// SumFloatMovement totals the float movement per currency. A transaction without a source wallet brings money
+// in, one without a destination takes it out, and promotional credits become balance when they are spent into
+// another wallet. SPLIT parents move no money and are left out.
+func (r *ReportRepository) SumFloatMovement(ctx context.Context, q repository.DBExecutor, from, to time.Time) ([]domain.FloatMovement, error) {
+	var movements []domain.FloatMovement
+	query := `SELECT currency,
+                     COALESCE(SUM(CASE WHEN from_wallet_id IS NULL THEN amount
+                                       WHEN to_wallet_id IS NOT NULL THEN promo_amount
+                                       ELSE 0 END), 0) AS inflow,
+                     COALESCE(SUM(amount - promo_amount) FILTER (WHERE to_wallet_id IS NULL), 0) AS outflow
+              FROM (SELECT currency, from_wallet_id, to_wallet_id, amount, promo_amount, type, status, created_at FROM transactions
+                    UNION ALL
+                    SELECT currency, from_wallet_id, to_wallet_id, amount, promo_amount, type, status, created_at FROM transactions_archive) t
+              WHERE created_at >= $1 AND created_at < $2 AND status = 'COMPLETED' AND type <> 'SPLIT'
+              GROUP BY currency
+              ORDER BY currency`
+	if err := q.SelectContext(ctx, &movements, query, from, to); err != nil {
+		return nil, fmt.Errorf("failed to sum float movement: %w", translateError(err))
+	}
+	return movements, nil
+}
+
+// ListLargestBalances retrieves the limit largest positive balances of live wallets in each currency,
+// largest first within a currency.
+func (r *ReportRepository) ListLargestBalances(ctx context.Context, q repository.DBExecutor, limit int) ([]domain.RankedBalance, error) {
+	var balances []domain.RankedBalance
+	query := `SELECT wallet_id, wallet_public_id, user_id, currency, balance
+              FROM (SELECT id AS wallet_id, public_id AS wallet_public_id, user_id, currency, balance,
+                           ROW_NUMBER() OVER (PARTITION BY currency ORDER BY balance DESC, id) AS rank
+                    FROM (SELECT id, public_id, user_id, currency, ` + walletBalanceOf("wallets") + ` AS balance
+                          FROM wallets WHERE deleted_at IS NULL) wallets
+                    WHERE balance > 0) ranked
+              WHERE rank <= $1
+              ORDER BY currency, rank`
+	if err := q.SelectContext(ctx, &balances, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to list largest balances: %w", translateError(err))
+	}
+	return balances, nil
+}
@@ -0,0 +1,117 @@
+// internal/repository/postgres/residency_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// CurrencyRestrictionRepository implements repository.CurrencyRestrictionRepository for PostgreSQL.
+type CurrencyRestrictionRepository struct{}
+
+// NewCurrencyRestrictionRepository creates a new CurrencyRestrictionRepository.
+func NewCurrencyRestrictionRepository(db *sqlx.DB) repository.CurrencyRestrictionRepository {
+	return &CurrencyRestrictionRepository{}
+}
+
+// restrictionRow maps a currency_restrictions row; the countries are a Postgres array.
+type restrictionRow struct {
+	Currency  string         `db:"currency"`
+	Countries pq.StringArray `db:"countries"`
+	UpdatedBy string         `db:"updated_by"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+func (row restrictionRow) toDomain() domain.CurrencyRestriction {
+	return domain.CurrencyRestriction{
+		Currency:  row.Currency,
+		Countries: []string(row.Countries),
+		UpdatedBy: row.UpdatedBy,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+// ListRestrictions retrieves the restrictions of all currencies, by currency.
+func (r *CurrencyRestrictionRepository) ListRestrictions(ctx context.Context, q repository.DBExecutor) ([]domain.CurrencyRestriction, error) {
+	var rows []restrictionRow
+	if err := q.SelectContext(ctx, &rows, `SELECT * FROM currency_restrictions ORDER BY currency`); err != nil {
+		return nil, fmt.Errorf("failed to list currency restrictions: %w", translateError(err))
+	}
+	restrictions := make([]domain.CurrencyRestriction, len(rows))
+	for i, row := range rows {
+		restrictions[i] = row.toDomain()
+	}
+	return restrictions, nil
+}
+
+// GetRestriction retrieves the restriction of a currency.
+func (r *CurrencyRestrictionRepository) GetRestriction(ctx context.Context, q repository.DBExecutor, currency string) (*domain.CurrencyRestriction, error) {
+	var row restrictionRow
+	if err := q.GetContext(ctx, &row, `SELECT * FROM currency_restrictions WHERE currency = $1`, currency); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get restriction of %s: %w", currency, translateError(err))
+	}
+	restriction := row.toDomain()
+	return &restriction, nil
+}
+
+// SaveRestriction upserts the restriction of a currency.
+func (r *CurrencyRestrictionRepository) SaveRestriction(ctx context.Context, q repository.DBExecutor, restriction *domain.CurrencyRestriction) error {
+	query := `INSERT INTO currency_restrictions (currency, countries, updated_by, updated_at)
+              VALUES ($1, $2, $3, $4)
+              ON CONFLICT (currency) DO UPDATE SET
+                  countries = EXCLUDED.countries, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`
+	_, err := q.ExecContext(ctx, query, restriction.Currency, pq.StringArray(restriction.Countries), restriction.UpdatedBy, restriction.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save restriction of %s: %w", restriction.Currency, translateError(err))
+	}
+	return nil
+}
+
+// DeleteRestriction removes the restriction of a currency.
+func (r *CurrencyRestrictionRepository) DeleteRestriction(ctx context.Context, q repository.DBExecutor, currency string) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM currency_restrictions WHERE currency = $1`, currency)
+	if err != nil {
+		return fmt.Errorf("failed to delete restriction of %s: %w", currency, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting restriction of %s: %w", currency, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// IsRestricted reports whether the currency is restricted for residents of the country.
+func (r *CurrencyRestrictionRepository) IsRestricted(ctx context.Context, q repository.DBExecutor, currency, country string) (bool, error) {
+	var restricted bool
+	query := `SELECT EXISTS (SELECT 1 FROM currency_restrictions WHERE currency = $1 AND $2 = ANY (countries))`
+	if err := q.GetContext(ctx, &restricted, query, currency, country); err != nil {
+		return false, fmt.Errorf("failed to check restriction of %s for %s: %w", currency, country, translateError(err))
+	}
+	return restricted, nil
+}
+
+// IsRestrictedForUser reports whether the currency is restricted for the user's residency.
+func (r *CurrencyRestrictionRepository) IsRestrictedForUser(ctx context.Context, q repository.DBExecutor, currency string, userID int64) (bool, error) {
+	var restricted bool
+	query := `SELECT EXISTS (SELECT 1 FROM currency_restrictions c JOIN users u ON u.residency = ANY (c.countries)
+                             WHERE c.currency = $1 AND u.id = $2)`
+	if err := q.GetContext(ctx, &restricted, query, currency, userID); err != nil {
+		return false, fmt.Errorf("failed to check restriction of %s for user %d: %w", currency, userID, translateError(err))
+	}
+	return restricted, nil
+}
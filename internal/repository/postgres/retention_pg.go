@@ -0,0 +1,62 @@
+// internal/repository/postgres/retention_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// RetentionRepository implements repository.RetentionRepository for PostgreSQL.
+type RetentionRepository struct{}
+
+// NewRetentionRepository creates a new RetentionRepository.
+func NewRetentionRepository(db *sqlx.DB) repository.RetentionRepository {
+	return &RetentionRepository{}
+}
+
+// purgeableRecords is the condition selecting the purgeable records of each type from their table; $1 is the
+// cutoff.
+var purgeableRecords = map[domain.RetentionRecordType]struct{ table, condition string }{
+	domain.RetentionAuthEvents:             {"auth_events", "created_at < $1"},
+	domain.RetentionImpersonationAudit:     {"impersonation_audit", "created_at < $1"},
+	domain.RetentionNotificationDeliveries: {"notification_deliveries", "created_at < $1 AND status <> 'PENDING'"},
+	domain.RetentionNotifications:          {"notifications", "created_at < $1"},
+}
+
+// PurgeRecords deletes the oldest purgeable records of the type, by id, in one batch.
+func (r *RetentionRepository) PurgeRecords(ctx context.Context, q repository.DBExecutor, recordType domain.RetentionRecordType, cutoff time.Time, limit int) (int64, error) {
+	records, ok := purgeableRecords[recordType]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown record type %q", util.ErrInvalidInput, recordType)
+	}
+	query := fmt.Sprintf(`DELETE FROM %[1]s WHERE id IN (SELECT id FROM %[1]s WHERE %[2]s ORDER BY id LIMIT $2)`, records.table, records.condition)
+	result, err := q.ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", recordType, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected purging %s: %w", recordType, err)
+	}
+	return rows, nil
+}
+
+// CountPurgeable counts the purgeable records of the type.
+func (r *RetentionRepository) CountPurgeable(ctx context.Context, q repository.DBExecutor, recordType domain.RetentionRecordType, cutoff time.Time) (int64, error) {
+	records, ok := purgeableRecords[recordType]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown record type %q", util.ErrInvalidInput, recordType)
+	}
+	var count int64
+	if err := q.GetContext(ctx, &count, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, records.table, records.condition), cutoff); err != nil {
+		return 0, fmt.Errorf("failed to count purgeable %s: %w", recordType, translateError(err))
+	}
+	return count, nil
+}
@@ -0,0 +1,86 @@
+// internal/repository/postgres/schema_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// SchemaRepository implements repository.SchemaRepository for PostgreSQL.
+type SchemaRepository struct{}
+
+// NewSchemaRepository creates a new SchemaRepository.
+func NewSchemaRepository(db *sqlx.DB) repository.SchemaRepository {
+	return &SchemaRepository{}
+}
+
+// GetSchemaVersion reads the single row the migrate CLI keeps in schema_migrations.
+func (r *SchemaRepository) GetSchemaVersion(ctx context.Context, q repository.DBExecutor) (uint64, bool, error) {
+	var state struct {
+		Version int64 `db:"version"`
+		Dirty   bool  `db:"dirty"`
+	}
+	if err := q.GetContext(ctx, &state, `SELECT version, dirty FROM schema_migrations LIMIT 1`); err != nil {
+		return 0, false, fmt.Errorf("failed to get schema version: %w", translateError(err))
+	}
+	return uint64(state.Version), state.Dirty, nil
+}
+
+// GetColumnScale reads the scale of the column from the information schema of the current schema.
+func (r *SchemaRepository) GetColumnScale(ctx context.Context, q repository.DBExecutor, table, column string) (int32, error) {
+	var scale sql.NullInt32
+	query := `SELECT numeric_scale FROM information_schema.columns
+              WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2`
+	if err := q.GetContext(ctx, &scale, query, table, column); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, util.ErrNotFound
+		}
+		return 0, fmt.Errorf("failed to get scale of %s.%s: %w", table, column, translateError(err))
+	}
+	if !scale.Valid {
+		return 0, fmt.Errorf("column %s.%s is not a NUMERIC column with a scale", table, column)
+	}
+	return scale.Int32, nil
+}
+
+// ListBackfillProgress retrieves the progress of every backfill that ran a batch, by name.
+func (r *SchemaRepository) ListBackfillProgress(ctx context.Context, q repository.DBExecutor) ([]domain.BackfillProgress, error) {
+	var progress []domain.BackfillProgress
+	query := `SELECT name, last_key, rows_done, batches, last_error, started_at, updated_at, completed_at
+              FROM schema_backfills ORDER BY name`
+	if err := q.SelectContext(ctx, &progress, query); err != nil {
+		return nil, fmt.Errorf("failed to list backfill progress: %w", translateError(err))
+	}
+	return progress, nil
+}
+
+// RunBackfillBatch runs a backfill's query for one batch.
+func (r *SchemaRepository) RunBackfillBatch(ctx context.Context, q repository.DBExecutor, query string, after int64, limit int) ([]int64, error) {
+	var keys []int64
+	if err := q.SelectContext(ctx, &keys, query, after, limit); err != nil {
+		return nil, fmt.Errorf("failed to run backfill batch after key %d: %w", after, translateError(err))
+	}
+	return keys, nil
+}
+
+// SaveBackfillProgress inserts or updates the progress of a backfill.
+func (r *SchemaRepository) SaveBackfillProgress(ctx context.Context, q repository.DBExecutor, progress *domain.BackfillProgress) error {
+	query := `INSERT INTO schema_backfills (name, last_key, rows_done, batches, last_error, started_at, updated_at, completed_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $6, $7)
+              ON CONFLICT (name) DO UPDATE SET last_key = EXCLUDED.last_key, rows_done = EXCLUDED.rows_done,
+                  batches = EXCLUDED.batches, last_error = EXCLUDED.last_error, updated_at = EXCLUDED.updated_at,
+                  completed_at = EXCLUDED.completed_at`
+	_, err := q.ExecContext(ctx, query, progress.Name, progress.LastKey, progress.RowsDone, progress.Batches,
+		progress.LastError, progress.UpdatedAt, progress.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save progress of backfill %s: %w", progress.Name, translateError(err))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// internal/repository/postgres/scim_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// SCIMRepository implements repository.SCIMRepository for PostgreSQL.
+type SCIMRepository struct{}
+
+// NewSCIMRepository creates a new SCIMRepository.
+func NewSCIMRepository(db *sqlx.DB) repository.SCIMRepository {
+	return &SCIMRepository{}
+}
+
+const scimUserColumns = `id, public_id, tenant, external_id, user_id, created_at, updated_at`
+
+// CreateSCIMUser inserts the resource; the unique constraint on (tenant, external_id) surfaces as
+// util.ErrDuplicateEntry.
+func (r *SCIMRepository) CreateSCIMUser(ctx context.Context, q repository.DBExecutor, scimUser *domain.SCIMUser) error {
+	query := `INSERT INTO scim_users (public_id, tenant, external_id, user_id, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		scimUser.PublicID,
+		scimUser.Tenant,
+		scimUser.ExternalID,
+		scimUser.UserID,
+		scimUser.CreatedAt,
+		scimUser.UpdatedAt,
+	).Scan(&scimUser.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create SCIM user of user %d: %w", scimUser.UserID, translateError(err))
+	}
+	return nil
+}
+
+// GetSCIMUser retrieves a tenant's resource by its SCIM id, with FOR UPDATE when lock is set.
+func (r *SCIMRepository) GetSCIMUser(ctx context.Context, q repository.DBExecutor, tenant string, publicID uuid.UUID, lock bool) (*domain.SCIMUser, error) {
+	query := `SELECT ` + scimUserColumns + ` FROM scim_users WHERE tenant = $1 AND public_id = $2`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+	return r.getSCIMUser(ctx, q, query, tenant, publicID)
+}
+
+// GetSCIMUserByUserID retrieves a tenant's resource of the user.
+func (r *SCIMRepository) GetSCIMUserByUserID(ctx context.Context, q repository.DBExecutor, tenant string, userID int64) (*domain.SCIMUser, error) {
+	query := `SELECT ` + scimUserColumns + ` FROM scim_users WHERE tenant = $1 AND user_id = $2`
+	return r.getSCIMUser(ctx, q, query, tenant, userID)
+}
+
+// GetSCIMUserByExternalID retrieves a tenant's resource by the identity system's ID.
+func (r *SCIMRepository) GetSCIMUserByExternalID(ctx context.Context, q repository.DBExecutor, tenant, externalID string) (*domain.SCIMUser, error) {
+	query := `SELECT ` + scimUserColumns + ` FROM scim_users WHERE tenant = $1 AND external_id = $2`
+	return r.getSCIMUser(ctx, q, query, tenant, externalID)
+}
+
+func (r *SCIMRepository) getSCIMUser(ctx context.Context, q repository.DBExecutor, query string, tenant string, key any) (*domain.SCIMUser, error) {
+	var scimUser domain.SCIMUser
+	if err := q.GetContext(ctx, &scimUser, query, tenant, key); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get SCIM user of tenant %s: %w", tenant, translateError(err))
+	}
+	return &scimUser, nil
+}
+
+// ListSCIMUsers retrieves a page of a tenant's resources, oldest first, and their total count.
+func (r *SCIMRepository) ListSCIMUsers(ctx context.Context, q repository.DBExecutor, tenant string, limit, offset int) ([]domain.SCIMUser, int64, error) {
+	var total int64
+	if err := q.GetContext(ctx, &total, `SELECT COUNT(*) FROM scim_users WHERE tenant = $1`, tenant); err != nil {
+		return nil, 0, fmt.Errorf("failed to count SCIM users of tenant %s: %w", tenant, translateError(err))
+	}
+	var scimUsers []domain.SCIMUser
+	query := `SELECT ` + scimUserColumns + ` FROM scim_users WHERE tenant = $1 ORDER BY id LIMIT $2 OFFSET $3`
+	if err := q.SelectContext(ctx, &scimUsers, query, tenant, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list SCIM users of tenant %s: %w", tenant, translateError(err))
+	}
+	return scimUsers, total, nil
+}
+
+// UpdateSCIMUser sets the external ID of a resource.
+func (r *SCIMRepository) UpdateSCIMUser(ctx context.Context, q repository.DBExecutor, id int64, externalID *string, at time.Time) error {
+	query := `UPDATE scim_users SET external_id = $1, updated_at = $2 WHERE id = $3`
+	return execOneRow(ctx, q, fmt.Sprintf("SCIM user %d", id), query, externalID, at, id)
+}
+
+// scimEventRow maps a scim_provisioning_events row; the changes are JSONB.
+type scimEventRow struct {
+	domain.SCIMProvisioningEvent
+	ChangesJSON []byte `db:"changes"`
+}
+
+// AppendProvisioningEvent inserts the event with its changes encoded as JSON.
+func (r *SCIMRepository) AppendProvisioningEvent(ctx context.Context, q repository.DBExecutor, event *domain.SCIMProvisioningEvent) error {
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode provisioning changes: %w", err)
+	}
+	query := `INSERT INTO scim_provisioning_events (tenant, user_id, action, changes, request_id, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err = q.QueryRowContext(ctx, query, event.Tenant, event.UserID, event.Action, changes, event.RequestID, event.CreatedAt).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record provisioning event of user %d: %w", event.UserID, translateError(err))
+	}
+	return nil
+}
+
+// ListProvisioningEvents retrieves the provisioning changes of a user, oldest first.
+func (r *SCIMRepository) ListProvisioningEvents(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.SCIMProvisioningEvent, error) {
+	var rows []scimEventRow
+	query := `SELECT id, tenant, user_id, action, changes, request_id, created_at
+              FROM scim_provisioning_events WHERE user_id = $1 ORDER BY id`
+	if err := q.SelectContext(ctx, &rows, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list provisioning events of user %d: %w", userID, translateError(err))
+	}
+	events := make([]domain.SCIMProvisioningEvent, len(rows))
+	for i, row := range rows {
+		events[i] = row.SCIMProvisioningEvent
+		if err := json.Unmarshal(row.ChangesJSON, &events[i].Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode provisioning event %d: %w", row.ID, err)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,153 @@
+// internal/repository/postgres/screening_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// ScreeningRepository implements repository.ScreeningRepository for PostgreSQL.
+type ScreeningRepository struct{}
+
+// NewScreeningRepository creates a new ScreeningRepository.
+func NewScreeningRepository(db *sqlx.DB) repository.ScreeningRepository {
+	return &ScreeningRepository{}
+}
+
+// CreateDenylistEntry adds a denylist entry.
+func (r *ScreeningRepository) CreateDenylistEntry(ctx context.Context, q repository.DBExecutor, entry *domain.DenylistEntry) error {
+	query := `INSERT INTO denylist_entries (name, normalized_name, reason, created_by, created_at)
+              VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := q.QueryRowContext(ctx, query, entry.Name, entry.NormalizedName, entry.Reason, entry.CreatedBy, entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create denylist entry: %w", translateError(err))
+	}
+	return nil
+}
+
+// ListDenylistEntries retrieves all denylist entries, by name.
+func (r *ScreeningRepository) ListDenylistEntries(ctx context.Context, q repository.DBExecutor) ([]domain.DenylistEntry, error) {
+	var entries []domain.DenylistEntry
+	if err := q.SelectContext(ctx, &entries, `SELECT * FROM denylist_entries ORDER BY normalized_name`); err != nil {
+		return nil, fmt.Errorf("failed to list denylist entries: %w", translateError(err))
+	}
+	return entries, nil
+}
+
+// FindDenylistEntry retrieves the entry with the given normalized name.
+func (r *ScreeningRepository) FindDenylistEntry(ctx context.Context, q repository.DBExecutor, normalizedName string) (*domain.DenylistEntry, error) {
+	var entry domain.DenylistEntry
+	if err := q.GetContext(ctx, &entry, `SELECT * FROM denylist_entries WHERE normalized_name = $1`, normalizedName); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find denylist entry: %w", translateError(err))
+	}
+	return &entry, nil
+}
+
+// DeleteDenylistEntry removes a denylist entry.
+func (r *ScreeningRepository) DeleteDenylistEntry(ctx context.Context, q repository.DBExecutor, id int64) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM denylist_entries WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete denylist entry %d: %w", id, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting denylist entry %d: %w", id, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// screeningCaseSelect projects screening cases together with the public IDs of their wallets.
+const screeningCaseSelect = `SELECT c.*, fw.public_id AS from_wallet_public_id, tw.public_id AS to_wallet_public_id
+                             FROM screening_cases c
+                             LEFT JOIN wallets fw ON fw.id = c.from_wallet_id
+                             LEFT JOIN wallets tw ON tw.id = c.to_wallet_id`
+
+// CreateScreeningCase stores a new review case.
+func (r *ScreeningRepository) CreateScreeningCase(ctx context.Context, q repository.DBExecutor, screeningCase *domain.ScreeningCase) error {
+	query := `INSERT INTO screening_cases (public_id, entry_id, subject, normalized_name, event, user_id, from_wallet_id, to_wallet_id, status, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		screeningCase.PublicID,
+		screeningCase.EntryID,
+		screeningCase.Subject,
+		screeningCase.NormalizedName,
+		screeningCase.Event,
+		screeningCase.UserID,
+		screeningCase.FromWalletID,
+		screeningCase.ToWalletID,
+		screeningCase.Status,
+		screeningCase.CreatedAt,
+	).Scan(&screeningCase.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create screening case: %w", translateError(err))
+	}
+	return nil
+}
+
+// FindLatestScreeningCase retrieves the newest case of a party on an entry.
+func (r *ScreeningRepository) FindLatestScreeningCase(ctx context.Context, q repository.DBExecutor, normalizedName string, entryID int64) (*domain.ScreeningCase, error) {
+	var screeningCase domain.ScreeningCase
+	query := screeningCaseSelect + ` WHERE c.normalized_name = $1 AND c.entry_id = $2 ORDER BY c.created_at DESC, c.id DESC LIMIT 1`
+	if err := q.GetContext(ctx, &screeningCase, query, normalizedName, entryID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find screening case: %w", translateError(err))
+	}
+	return &screeningCase, nil
+}
+
+// GetScreeningCaseByPublicID retrieves a case by its public UUID.
+func (r *ScreeningRepository) GetScreeningCaseByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.ScreeningCase, error) {
+	var screeningCase domain.ScreeningCase
+	if err := q.GetContext(ctx, &screeningCase, screeningCaseSelect+` WHERE c.public_id = $1`, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get screening case %s: %w", publicID, translateError(err))
+	}
+	return &screeningCase, nil
+}
+
+// ListScreeningCases retrieves cases, newest first.
+func (r *ScreeningRepository) ListScreeningCases(ctx context.Context, q repository.DBExecutor, status domain.ScreeningCaseStatus, limit int) ([]domain.ScreeningCase, error) {
+	var cases []domain.ScreeningCase
+	query := screeningCaseSelect + ` WHERE ($1 = '' OR c.status = $1) ORDER BY c.created_at DESC, c.id DESC LIMIT $2`
+	if err := q.SelectContext(ctx, &cases, query, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list screening cases: %w", translateError(err))
+	}
+	return cases, nil
+}
+
+// ResolveScreeningCase closes an open case.
+func (r *ScreeningRepository) ResolveScreeningCase(ctx context.Context, q repository.DBExecutor, id int64, status domain.ScreeningCaseStatus, resolvedBy string, note *string, at time.Time) error {
+	query := `UPDATE screening_cases SET status = $1, resolved_by = $2, resolution_note = $3, resolved_at = $4
+              WHERE id = $5 AND status = 'OPEN'`
+	result, err := q.ExecContext(ctx, query, status, resolvedBy, note, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to resolve screening case %d: %w", id, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after resolving screening case %d: %w", id, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// internal/repository/postgres/security_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// AuthEventRepository implements repository.AuthEventRepository for PostgreSQL.
+type AuthEventRepository struct{}
+
+// NewAuthEventRepository creates a new AuthEventRepository.
+func NewAuthEventRepository(db *sqlx.DB) repository.AuthEventRepository {
+	return &AuthEventRepository{}
+}
+
+// CreateAuthEvent records an authentication event.
+func (r *AuthEventRepository) CreateAuthEvent(ctx context.Context, q repository.DBExecutor, event *domain.AuthEvent) error {
+	query := `INSERT INTO auth_events (user_id, type, ip_address, user_agent, geo_hint, device_hash, new_device, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		event.UserID,
+		event.Type,
+		event.IPAddress,
+		event.UserAgent,
+		event.GeoHint,
+		event.DeviceHash,
+		event.NewDevice,
+		event.CreatedAt,
+	).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create auth event for user %d: %w", event.UserID, translateError(err))
+	}
+	return nil
+}
+
+// ListAuthEvents retrieves up to limit events of a user, newest first.
+func (r *AuthEventRepository) ListAuthEvents(ctx context.Context, q repository.DBExecutor, userID int64, limit int) ([]domain.AuthEvent, error) {
+	var events []domain.AuthEvent
+	query := `SELECT id, user_id, type, ip_address, user_agent, geo_hint, device_hash, new_device, created_at
+              FROM auth_events WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`
+	if err := q.SelectContext(ctx, &events, query, userID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list auth events of user %d: %w", userID, translateError(err))
+	}
+	return events, nil
+}
+
+// KnownDevice looks at successful logins only, so failed attempts do not make a device known.
+func (r *AuthEventRepository) KnownDevice(ctx context.Context, q repository.DBExecutor, userID int64, deviceHash string) (bool, bool, error) {
+	var result struct {
+		HasLogins bool `db:"has_logins"`
+		Known     bool `db:"known"`
+	}
+	query := `SELECT COUNT(*) > 0 AS has_logins, COUNT(*) FILTER (WHERE device_hash = $2) > 0 AS known
+              FROM auth_events WHERE user_id = $1 AND type = 'LOGIN'`
+	if err := q.GetContext(ctx, &result, query, userID, deviceHash); err != nil {
+		return false, false, fmt.Errorf("failed to look up devices of user %d: %w", userID, translateError(err))
+	}
+	return result.HasLogins, result.Known, nil
+}
@@ -0,0 +1,27 @@
+// internal/repository/postgres/sqlmock_test.go
+package postgres
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+// newMockDB returns a database whose driver expects the exact SQL of each query, so that the tests catch any
+// change to the generated statements. The expectations must all be met by the end of the test.
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, mock.ExpectationsWereMet())
+		_ = conn.Close()
+	})
+	return sqlx.NewDb(conn, "postgres"), mock
+}
+
+// walletRowColumns are the columns of walletColumns, as the driver names them.
+var walletRowColumns = []string{"id", "public_id", "user_id", "currency", "balance", "kind", "version",
+	"created_at", "updated_at", "last_activity_at", "dormant_since", "frozen_at", "ownership_frozen_at"}
@@ -0,0 +1,127 @@
+// internal/repository/postgres/suspense_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// SuspenseRepository implements repository.SuspenseRepository for PostgreSQL.
+type SuspenseRepository struct{}
+
+// NewSuspenseRepository creates a new SuspenseRepository.
+func NewSuspenseRepository(db *sqlx.DB) repository.SuspenseRepository {
+	return &SuspenseRepository{}
+}
+
+// inboundFundsSelect projects inbound funds together with the public IDs of the credited and the assigned wallet.
+const inboundFundsSelect = `SELECT f.id, f.public_id, f.provider, f.reference, f.account_reference, f.amount, f.currency, f.status,
+                                   f.wallet_id, w.public_id AS wallet_public_id, f.transaction_id, f.assigned_wallet_id,
+                                   aw.public_id AS assigned_wallet_public_id, f.adjustment_transaction_id, f.resolved_by,
+                                   f.resolution_reason, f.resolved_at, f.created_at
+                            FROM inbound_funds f
+                            JOIN wallets w ON w.id = f.wallet_id
+                            LEFT JOIN wallets aw ON aw.id = f.assigned_wallet_id`
+
+// GetSuspenseWallet retrieves the live suspense wallet of a currency.
+func (r *SuspenseRepository) GetSuspenseWallet(ctx context.Context, q repository.DBExecutor, currency string) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	query := `SELECT id, public_id, user_id, currency, ` + walletBalanceOf("wallets") + ` AS balance, kind, ` + walletVersionOf("wallets") + ` AS version, created_at, updated_at
+              FROM wallets WHERE kind = $1 AND currency = $2 AND deleted_at IS NULL`
+	if err := q.GetContext(ctx, &wallet, query, domain.WalletKindSuspense, currency); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get suspense wallet for %s: %w", currency, translateError(err))
+	}
+	return &wallet, nil
+}
+
+// CreateInboundFunds records received funds.
+func (r *SuspenseRepository) CreateInboundFunds(ctx context.Context, q repository.DBExecutor, funds *domain.InboundFunds) error {
+	query := `INSERT INTO inbound_funds (public_id, provider, reference, account_reference, amount, currency, status,
+                                         wallet_id, transaction_id, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		funds.PublicID,
+		funds.Provider,
+		funds.Reference,
+		funds.AccountReference,
+		funds.Amount,
+		funds.Currency,
+		funds.Status,
+		funds.WalletID,
+		funds.TransactionID,
+		funds.CreatedAt,
+	).Scan(&funds.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create inbound funds: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetInboundFundsByPublicID retrieves inbound funds by their public UUID.
+func (r *SuspenseRepository) GetInboundFundsByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.InboundFunds, error) {
+	return r.getInboundFunds(ctx, q, inboundFundsSelect+` WHERE f.public_id = $1`, publicID)
+}
+
+// GetInboundFundsByReference retrieves the funds recorded for a provider's reference.
+func (r *SuspenseRepository) GetInboundFundsByReference(ctx context.Context, q repository.DBExecutor, provider, reference string) (*domain.InboundFunds, error) {
+	return r.getInboundFunds(ctx, q, inboundFundsSelect+` WHERE f.provider = $1 AND f.reference = $2`, provider, reference)
+}
+
+// GetInboundFundsForUpdate retrieves inbound funds by public UUID and locks their row.
+func (r *SuspenseRepository) GetInboundFundsForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.InboundFunds, error) {
+	return r.getInboundFunds(ctx, q, inboundFundsSelect+` WHERE f.public_id = $1 FOR UPDATE OF f`, publicID)
+}
+
+func (r *SuspenseRepository) getInboundFunds(ctx context.Context, q repository.DBExecutor, query string, args ...any) (*domain.InboundFunds, error) {
+	var funds domain.InboundFunds
+	if err := q.GetContext(ctx, &funds, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get inbound funds: %w", translateError(err))
+	}
+	return &funds, nil
+}
+
+// ListSuspendedFunds retrieves the funds held in suspense, oldest first.
+func (r *SuspenseRepository) ListSuspendedFunds(ctx context.Context, q repository.DBExecutor, currency string) ([]domain.InboundFunds, error) {
+	var funds []domain.InboundFunds
+	query := inboundFundsSelect + ` WHERE f.status = $1 AND ($2 = '' OR f.currency = $2) ORDER BY f.created_at, f.id`
+	if err := q.SelectContext(ctx, &funds, query, domain.InboundFundsStatusSuspended, currency); err != nil {
+		return nil, fmt.Errorf("failed to list suspended funds: %w", translateError(err))
+	}
+	return funds, nil
+}
+
+// MarkReassigned records the reassignment of suspended funds.
+func (r *SuspenseRepository) MarkReassigned(ctx context.Context, q repository.DBExecutor, fundsID, walletID int64, adjustmentID uuid.UUID, operator, reason string, at time.Time) error {
+	query := `UPDATE inbound_funds
+              SET status = $1, assigned_wallet_id = $2, adjustment_transaction_id = $3, resolved_by = $4,
+                  resolution_reason = $5, resolved_at = $6
+              WHERE id = $7 AND status = $8`
+	result, err := q.ExecContext(ctx, query, domain.InboundFundsStatusReassigned, walletID, adjustmentID, operator, reason, at,
+		fundsID, domain.InboundFundsStatusSuspended)
+	if err != nil {
+		return fmt.Errorf("failed to mark inbound funds %d reassigned: %w", fundsID, translateError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after reassigning inbound funds %d: %w", fundsID, err)
+	}
+	if rows == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
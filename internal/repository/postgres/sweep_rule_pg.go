@@ -0,0 +1,89 @@
+// internal/repository/postgres/sweep_rule_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// SweepRuleRepository implements repository.SweepRuleRepository for PostgreSQL.
+type SweepRuleRepository struct{}
+
+// NewSweepRuleRepository creates a new SweepRuleRepository.
+func NewSweepRuleRepository(db *sqlx.DB) repository.SweepRuleRepository {
+	return &SweepRuleRepository{}
+}
+
+// sweepRuleSelect projects sweep rules together with the public IDs of both wallets and their currency.
+const sweepRuleSelect = `SELECT r.id, r.user_id, r.kind, r.source_wallet_id, r.target_wallet_id,
+                                sw.public_id AS source_wallet_public_id, tw.public_id AS target_wallet_public_id,
+                                sw.currency, r.threshold, r.enabled, r.created_at, r.updated_at
+                         FROM sweep_rules r
+                         JOIN wallets sw ON sw.id = r.source_wallet_id
+                         JOIN wallets tw ON tw.id = r.target_wallet_id`
+
+// CreateRule adds a new sweep rule using the provided DBExecutor.
+func (r *SweepRuleRepository) CreateRule(ctx context.Context, q repository.DBExecutor, rule *domain.SweepRule) error {
+	query := `INSERT INTO sweep_rules (user_id, kind, source_wallet_id, target_wallet_id, threshold, enabled, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		rule.UserID,
+		rule.Kind,
+		rule.SourceWalletID,
+		rule.TargetWalletID,
+		rule.Threshold,
+		rule.Enabled,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	).Scan(&rule.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create sweep rule: %w", translateError(err))
+	}
+	return nil
+}
+
+// ListRulesByUserID retrieves all sweep rules of a user.
+func (r *SweepRuleRepository) ListRulesByUserID(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.SweepRule, error) {
+	var rules []domain.SweepRule
+	query := sweepRuleSelect + ` WHERE r.user_id = $1 ORDER BY r.id`
+	if err := q.SelectContext(ctx, &rules, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list sweep rules for user %d: %w", userID, translateError(err))
+	}
+	return rules, nil
+}
+
+// ListEnabledRulesByWatchedWallet retrieves the enabled rules triggered by the balance of the given wallet:
+// sweeps watch their source wallet, top-ups their target wallet.
+func (r *SweepRuleRepository) ListEnabledRulesByWatchedWallet(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.SweepRule, error) {
+	var rules []domain.SweepRule
+	query := sweepRuleSelect + `
+              WHERE r.enabled
+                AND ((r.kind = 'SWEEP' AND r.source_wallet_id = $1) OR (r.kind = 'TOP_UP' AND r.target_wallet_id = $1))
+              ORDER BY r.id`
+	if err := q.SelectContext(ctx, &rules, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to list sweep rules for wallet %d: %w", walletID, translateError(err))
+	}
+	return rules, nil
+}
+
+// DeleteRule removes a user's rule; it returns util.ErrNotFound if the user has no such rule.
+func (r *SweepRuleRepository) DeleteRule(ctx context.Context, q repository.DBExecutor, userID, ruleID int64) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM sweep_rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sweep rule %d: %w", ruleID, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting sweep rule %d: %w", ruleID, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// internal/repository/postgres/transaction_archive_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// partitionNamePattern matches the monthly partition names created by migrations and EnsureMonthlyPartition.
+var partitionNamePattern = regexp.MustCompile(`^transactions_p(\d{6})$`)
+
+// TransactionArchiveRepository implements repository.TransactionArchiveRepository for PostgreSQL.
+type TransactionArchiveRepository struct{}
+
+// NewTransactionArchiveRepository creates a new TransactionArchiveRepository.
+func NewTransactionArchiveRepository(db *sqlx.DB) repository.TransactionArchiveRepository {
+	return &TransactionArchiveRepository{}
+}
+
+// EnsureMonthlyPartition creates the partition covering the month of the given time if it does not exist yet.
+func (r *TransactionArchiveRepository) EnsureMonthlyPartition(ctx context.Context, q repository.DBExecutor, month time.Time) error {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	name := "transactions_p" + start.Format("200601")
+
+	// DDL does not accept bind parameters; all interpolated values are generated above.
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF transactions FOR VALUES FROM (%s) TO (%s)`,
+		pq.QuoteIdentifier(name),
+		pq.QuoteLiteral(start.Format(time.RFC3339)),
+		pq.QuoteLiteral(end.Format(time.RFC3339)),
+	)
+	if _, err := q.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create transaction partition %s: %w", name, translateError(err))
+	}
+	return nil
+}
+
+// ListPartitions returns the monthly partitions of the transactions table, oldest first.
+// The default partition is never listed.
+func (r *TransactionArchiveRepository) ListPartitions(ctx context.Context, q repository.DBExecutor) ([]domain.TransactionPartition, error) {
+	var names []string
+	query := `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = 'transactions'
+		ORDER BY c.relname`
+	if err := q.SelectContext(ctx, &names, query); err != nil {
+		return nil, fmt.Errorf("failed to list transaction partitions: %w", translateError(err))
+	}
+
+	partitions := make([]domain.TransactionPartition, 0, len(names))
+	for _, name := range names {
+		match := partitionNamePattern.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		start, err := time.Parse("200601", match[1])
+		if err != nil {
+			continue
+		}
+		partitions = append(partitions, domain.TransactionPartition{
+			Name:       name,
+			RangeStart: start,
+			RangeEnd:   start.AddDate(0, 1, 0),
+		})
+	}
+	return partitions, nil
+}
+
+// ArchivePartition copies the partition's rows into transactions_archive, then detaches and drops it.
+// It should be called with a transactional DBExecutor so the three steps are atomic.
+func (r *TransactionArchiveRepository) ArchivePartition(ctx context.Context, q repository.DBExecutor, partition domain.TransactionPartition) (int64, error) {
+	if !partitionNamePattern.MatchString(partition.Name) {
+		return 0, fmt.Errorf("refusing to archive unexpected partition %q", partition.Name)
+	}
+	table := pq.QuoteIdentifier(partition.Name)
+
+	copyQuery := fmt.Sprintf(`
+		INSERT INTO transactions_archive (%[2]s)
+		SELECT %[2]s
+		FROM %[1]s
+		ON CONFLICT (id) DO NOTHING`, table, transactionColumns)
+	result, err := q.ExecContext(ctx, copyQuery)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy partition %s into archive: %w", partition.Name, translateError(err))
+	}
+	archived, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected after archiving partition %s: %w", partition.Name, err)
+	}
+
+	if _, err := q.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE transactions DETACH PARTITION %s`, table)); err != nil {
+		return 0, fmt.Errorf("failed to detach partition %s: %w", partition.Name, translateError(err))
+	}
+	if _, err := q.ExecContext(ctx, fmt.Sprintf(`DROP TABLE %s`, table)); err != nil {
+		return 0, fmt.Errorf("failed to drop partition %s: %w", partition.Name, translateError(err))
+	}
+	return archived, nil
+}
@@ -3,12 +3,18 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"finflow-wallet/internal/domain"
 	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
 )
 
 // TransactionRepository implements repository.TransactionRepository for PostgreSQL.
@@ -21,12 +27,32 @@ func NewTransactionRepository(db *sqlx.DB) repository.TransactionRepository {
 	return &TransactionRepository{}
 }
 
+// transactionColumns are the stored columns shared by the hot and archive transaction tables.
+const transactionColumns = "id, public_id, from_wallet_id, to_wallet_id, amount, currency, type, status, transaction_time, description, category, parent_transaction_id, promo_amount, created_at"
+
+// transactionSource returns a subquery over the transactions (and optionally the archive) with the
+// public IDs of both wallets joined in, so responses never need the internal wallet IDs, and the
+// usernames of their owners, which readable descriptions name as counterparties.
+func transactionSource(includeArchive bool) string {
+	rows := "SELECT " + transactionColumns + " FROM transactions"
+	if includeArchive {
+		rows += " UNION ALL SELECT " + transactionColumns + " FROM transactions_archive"
+	}
+	return `(
+		SELECT t.*, fw.public_id AS from_wallet_public_id, tw.public_id AS to_wallet_public_id,
+		       fu.username AS from_username, tu.username AS to_username
+		FROM (` + rows + `) t
+		LEFT JOIN wallets fw ON fw.id = t.from_wallet_id
+		LEFT JOIN wallets tw ON tw.id = t.to_wallet_id
+		LEFT JOIN users fu ON fu.id = fw.user_id
+		LEFT JOIN users tu ON tu.id = tw.user_id
+	) t`
+}
+
 // CreateTransaction inserts a new transaction record into the database using the provided DBExecutor.
 func (r *TransactionRepository) CreateTransaction(ctx context.Context, q repository.DBExecutor, transaction *domain.Transaction) error {
-	query := `INSERT INTO transactions (from_wallet_id, to_wallet_id, amount, currency, type, status, transaction_time, description, created_at)
-              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
-
-	err := q.QueryRowContext(ctx, query,
+	err := q.QueryRowContext(ctx, createTransactionQuery,
+		transaction.PublicID,
 		transaction.FromWalletID,
 		transaction.ToWalletID,
 		transaction.Amount,
@@ -35,15 +61,33 @@ func (r *TransactionRepository) CreateTransaction(ctx context.Context, q reposit
 		transaction.Status,
 		transaction.TransactionTime,
 		transaction.Description,
+		transaction.Category,
+		transaction.ParentID,
+		transaction.PromoAmount,
 		transaction.CreatedAt,
 	).Scan(&transaction.ID)
 
 	if err != nil {
-		return fmt.Errorf("failed to create transaction: %w", err)
+		return fmt.Errorf("failed to create transaction: %w", translateError(err))
 	}
 	return nil
 }
 
+// GetTransactionByPublicID retrieves a single transaction by its public UUID, falling back to the
+// archive for transactions that have been moved out of the hot table.
+func (r *TransactionRepository) GetTransactionByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Transaction, error) {
+	var transaction domain.Transaction
+	query := `SELECT * FROM ` + transactionSource(true) + ` WHERE public_id = $1 LIMIT 1`
+	err := q.GetContext(ctx, &transaction, query, publicID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction by public ID %s: %w", publicID, translateError(err))
+	}
+	return &transaction, nil
+}
+
 // GetTransactionsByWalletID retrieves a paginated list of transactions for a specific wallet.
 // It performs two queries: one for the data and one for the total count.
 func (r *TransactionRepository) GetTransactionsByWalletID(ctx context.Context, q repository.DBExecutor, walletID int64, limit, offset int) ([]domain.Transaction, int64, error) {
@@ -52,14 +96,14 @@ func (r *TransactionRepository) GetTransactionsByWalletID(ctx context.Context, q
 	// Query 1: Get the paginated transactions
 	// We need to check both from_wallet_id and to_wallet_id for transactions related to this wallet.
 	query := `
-		SELECT id, from_wallet_id, to_wallet_id, amount, currency, type, status, transaction_time, description, created_at
-		FROM transactions
+		SELECT *
+		FROM ` + transactionSource(false) + `
 		WHERE from_wallet_id = $1 OR to_wallet_id = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3`
 	err := q.SelectContext(ctx, &transactions, query, walletID, limit, offset)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to fetch transactions for wallet %d: %w", walletID, err)
+		return nil, 0, fmt.Errorf("failed to fetch transactions for wallet %d: %w", walletID, translateError(err))
 	}
 
 	// Query 2: Get the total count of transactions for the wallet
@@ -70,8 +114,124 @@ func (r *TransactionRepository) GetTransactionsByWalletID(ctx context.Context, q
 		WHERE from_wallet_id = $1 OR to_wallet_id = $1`
 	err = q.GetContext(ctx, &totalCount, countQuery, walletID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get total transaction count for wallet %d: %w", walletID, err)
+		return nil, 0, fmt.Errorf("failed to get total transaction count for wallet %d: %w", walletID, translateError(err))
+	}
+
+	return transactions, totalCount, nil
+}
+
+// transactionListQuery is the shared builder for transaction list endpoints; newest first by default.
+// Public IDs are always selected because responses identify transactions and wallets by them.
+var transactionListQuery = repository.NewListQueryBuilder(
+	[]string{"id", "public_id", "from_wallet_id", "to_wallet_id", "from_wallet_public_id", "to_wallet_public_id", "from_username", "to_username",
+		"amount", "currency", "type", "status", "transaction_time", "description", "category", "parent_transaction_id", "promo_amount", "created_at"},
+	repository.SortField{Field: "created_at", Desc: true},
+).AlwaysSelect("public_id", "from_wallet_public_id", "to_wallet_public_id")
+
+// ListTransactionsByWalletID retrieves a page of a wallet's transactions matching the filter.
+// When filter.IncludeArchive is set the archive table is unioned in, so date ranges older than
+// the retention horizon still return complete history.
+func (r *TransactionRepository) ListTransactionsByWalletID(ctx context.Context, q repository.DBExecutor, walletID int64, filter repository.TransactionFilter, opts repository.ListOptions) ([]domain.Transaction, int64, error) {
+	transactions := []domain.Transaction{}
+
+	source := transactionSource(filter.IncludeArchive)
+
+	where := "(from_wallet_id = $1 OR to_wallet_id = $1)"
+	args := []any{walletID}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if filter.Type != nil {
+		args = append(args, *filter.Type)
+		where += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+	if filter.Category != nil {
+		args = append(args, *filter.Category)
+		where += fmt.Sprintf(" AND category = $%d", len(args))
+	}
+	if filter.Query != "" {
+		where += searchCondition(filter.Query, &args)
+	}
+
+	query, countQuery, queryArgs, err := transactionListQuery.Build(source, where, args, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := q.SelectContext(ctx, &transactions, query, queryArgs...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list transactions for wallet %d: %w", walletID, translateError(err))
+	}
+
+	var totalCount int64
+	if err := q.GetContext(ctx, &totalCount, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count transactions for wallet %d: %w", walletID, translateError(err))
 	}
 
 	return transactions, totalCount, nil
 }
+
+// searchCondition matches a search query: a transaction ID finds the transaction and the legs pointing at it,
+// any other text is a case-insensitive substring of the description or category, served by the trigram indexes.
+func searchCondition(query string, args *[]any) string {
+	if id, err := uuid.Parse(query); err == nil {
+		*args = append(*args, id)
+		return fmt.Sprintf(" AND (public_id = $%d OR parent_transaction_id = $%d)", len(*args), len(*args))
+	}
+	*args = append(*args, "%"+likeEscaper.Replace(query)+"%")
+	return fmt.Sprintf(" AND (description ILIKE $%d OR category ILIKE $%d)", len(*args), len(*args))
+}
+
+// likeEscaper escapes the LIKE wildcards of user input, using the default escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// FindRecentTransfer returns the latest transfer of amount in currency from one wallet to the other created at or
+// after since, or util.ErrNotFound. Pending transfers count, failed ones do not. Only the hot table is searched;
+// the window is minutes, never months.
+func (r *TransactionRepository) FindRecentTransfer(ctx context.Context, q repository.DBExecutor, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string, since time.Time) (*domain.Transaction, error) {
+	var transaction domain.Transaction
+	query := `SELECT * FROM ` + transactionSource(false) + `
+		WHERE from_wallet_id = $1 AND to_wallet_id = $2 AND type = 'TRANSFER' AND status <> 'FAILED'
+		  AND amount = $3 AND currency = $4 AND created_at >= $5
+		ORDER BY created_at DESC
+		LIMIT 1`
+	err := q.GetContext(ctx, &transaction, query, fromWalletID, toWalletID, amount, currency, since)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find recent transfer from wallet %d to %d: %w", fromWalletID, toWalletID, translateError(err))
+	}
+	return &transaction, nil
+}
+
+// HasTransferredTo reports whether fromWalletID ever completed a TRANSFER to toWalletID. Archived transfers count,
+// so a counterparty paid years ago is not new.
+func (r *TransactionRepository) HasTransferredTo(ctx context.Context, q repository.DBExecutor, fromWalletID, toWalletID int64) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM transactions WHERE from_wallet_id = $1 AND to_wallet_id = $2 AND type = 'TRANSFER' AND status = 'COMPLETED')
+	              OR EXISTS (SELECT 1 FROM transactions_archive WHERE from_wallet_id = $1 AND to_wallet_id = $2 AND type = 'TRANSFER' AND status = 'COMPLETED')`
+	if err := q.GetContext(ctx, &exists, query, fromWalletID, toWalletID); err != nil {
+		return false, fmt.Errorf("failed to look up transfers from wallet %d to %d: %w", fromWalletID, toWalletID, translateError(err))
+	}
+	return exists, nil
+}
+
+// SumOutgoingAmount totals a wallet's withdrawals, transfers out (including the debit legs of cross-currency
+// transfers) and charge payments completed within [from, to), optionally
+// only those in one category. Sweeps and settlements between a user's own wallets are not spending and are excluded.
+func (r *TransactionRepository) SumOutgoingAmount(ctx context.Context, q repository.DBExecutor, walletID int64, category *string, from, to time.Time) (decimal.Decimal, error) {
+	var total decimal.Decimal
+	query := `SELECT COALESCE(SUM(amount), 0) FROM transactions
+              WHERE from_wallet_id = $1 AND type IN ('WITHDRAWAL', 'TRANSFER', 'CONVERSION', 'PAYMENT') AND status = 'COMPLETED'
+                AND transaction_time >= $2 AND transaction_time < $3
+                AND ($4::VARCHAR IS NULL OR category = $4)`
+	if err := q.GetContext(ctx, &total, query, walletID, from, to, category); err != nil {
+		return decimal.Zero, fmt.Errorf("failed to sum outgoing amount for wallet %d: %w", walletID, translateError(err))
+	}
+	return total, nil
+}
@@ -0,0 +1,107 @@
+// internal/repository/postgres/transaction_pg_test.go
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// transactionRowColumns are the columns of transactionSource.
+var transactionRowColumns = []string{"id", "public_id", "from_wallet_id", "to_wallet_id", "amount", "currency", "type", "status",
+	"transaction_time", "description", "category", "parent_transaction_id", "promo_amount", "created_at",
+	"from_wallet_public_id", "to_wallet_public_id", "from_username", "to_username"}
+
+func TestTransactionRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := &TransactionRepository{}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	fromWalletID, toWalletID := int64(1), int64(2)
+
+	t.Run("CreateTransactionBindsColumns", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		description := "rent"
+		transaction := domain.NewTransaction(&fromWalletID, &toWalletID, decimal.RequireFromString("12.34"), "USD", domain.TransactionTypeTransfer, &description)
+		mock.ExpectQuery(createTransactionQuery).
+			WithArgs(transaction.PublicID, fromWalletID, toWalletID, transaction.Amount, "USD", domain.TransactionTypeTransfer,
+				domain.TransactionStatusCompleted, transaction.TransactionTime, description, nil, nil, decimal.Zero, transaction.CreatedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(99))
+
+		require.NoError(t, repo.CreateTransaction(ctx, database, transaction))
+		assert.Equal(t, int64(99), transaction.ID)
+	})
+
+	t.Run("CreateTransactionTranslatesForeignKeyViolation", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(createTransactionQuery).WillReturnError(&pq.Error{Code: pgForeignKeyViolation})
+
+		transaction := domain.NewTransaction(nil, &toWalletID, decimal.NewFromInt(1), "USD", domain.TransactionTypeDeposit, nil)
+		err := repo.CreateTransaction(ctx, database, transaction)
+		assert.ErrorIs(t, err, util.ErrReferenceViolation)
+	})
+
+	t.Run("GetTransactionByPublicIDSearchesArchive", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		publicID, toPublicID := uuid.New(), uuid.New()
+		mock.ExpectQuery(`SELECT * FROM ` + transactionSource(true) + ` WHERE public_id = $1 LIMIT 1`).WithArgs(publicID).
+			WillReturnRows(sqlmock.NewRows(transactionRowColumns).
+				AddRow(5, publicID.String(), nil, toWalletID, "10.0000", "USD", "DEPOSIT", "COMPLETED", now, nil, nil, nil, "0", now,
+					nil, toPublicID.String(), nil, "bob"))
+
+		transaction, err := repo.GetTransactionByPublicID(ctx, database, publicID)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.TransactionTypeDeposit, transaction.Type)
+		assert.Nil(t, transaction.FromWalletID)
+		assert.Equal(t, toPublicID, *transaction.ToWalletPublicID)
+		assert.Equal(t, "bob", *transaction.ToUsername)
+		assert.Equal(t, "10", transaction.Amount.String())
+	})
+
+	t.Run("GetTransactionByPublicIDNotFound", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		publicID := uuid.New()
+		mock.ExpectQuery(`SELECT * FROM ` + transactionSource(true) + ` WHERE public_id = $1 LIMIT 1`).WithArgs(publicID).
+			WillReturnRows(sqlmock.NewRows(transactionRowColumns))
+
+		_, err := repo.GetTransactionByPublicID(ctx, database, publicID)
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+
+	t.Run("FindRecentTransferBindsWindow", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(`SELECT * FROM `+transactionSource(false)+`
+		WHERE from_wallet_id = $1 AND to_wallet_id = $2 AND type = 'TRANSFER' AND status <> 'FAILED'
+		  AND amount = $3 AND currency = $4 AND created_at >= $5
+		ORDER BY created_at DESC
+		LIMIT 1`).
+			WithArgs(fromWalletID, toWalletID, decimal.NewFromInt(25), "EUR", now).
+			WillReturnRows(sqlmock.NewRows(transactionRowColumns))
+
+		_, err := repo.FindRecentTransfer(ctx, database, fromWalletID, toWalletID, decimal.NewFromInt(25), "EUR", now)
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+
+	t.Run("SumOutgoingAmountTranslatesSerializationFailure", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(`SELECT COALESCE(SUM(amount), 0) FROM transactions
+              WHERE from_wallet_id = $1 AND type IN ('WITHDRAWAL', 'TRANSFER', 'CONVERSION', 'PAYMENT') AND status = 'COMPLETED'
+                AND transaction_time >= $2 AND transaction_time < $3
+                AND ($4::VARCHAR IS NULL OR category = $4)`).
+			WithArgs(fromWalletID, now, now.Add(time.Hour), nil).
+			WillReturnError(&pq.Error{Code: pgSerializationFailure})
+
+		_, err := repo.SumOutgoingAmount(ctx, database, fromWalletID, nil, now, now.Add(time.Hour))
+		assert.ErrorIs(t, err, util.ErrConcurrentUpdate)
+	})
+}
@@ -0,0 +1,75 @@
+// internal/repository/postgres/transfer_quote_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// TransferQuoteRepository implements repository.TransferQuoteRepository for PostgreSQL.
+type TransferQuoteRepository struct{}
+
+// NewTransferQuoteRepository creates a new TransferQuoteRepository.
+func NewTransferQuoteRepository(db *sqlx.DB) repository.TransferQuoteRepository {
+	return &TransferQuoteRepository{}
+}
+
+// transferQuoteColumns lists the quote columns; the wallets' public IDs are joined separately.
+const transferQuoteColumns = `tq.id, tq.public_id, tq.from_wallet_id, tq.to_wallet_id, tq.amount, tq.currency, tq.fee,
+                              tq.credit_amount, tq.credit_currency, tq.rate, tq.expires_at, tq.used_at, tq.created_at`
+
+// CreateQuote stores a new quote.
+func (r *TransferQuoteRepository) CreateQuote(ctx context.Context, q repository.DBExecutor, quote *domain.TransferQuote) error {
+	query := `INSERT INTO transfer_quotes (public_id, from_wallet_id, to_wallet_id, amount, currency, fee,
+                                           credit_amount, credit_currency, rate, expires_at, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		quote.PublicID,
+		quote.FromWalletID,
+		quote.ToWalletID,
+		quote.Amount,
+		quote.Currency,
+		quote.Fee,
+		quote.CreditAmount,
+		quote.CreditCurrency,
+		quote.Rate,
+		quote.ExpiresAt,
+		quote.CreatedAt,
+	).Scan(&quote.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create transfer quote: %w", translateError(err))
+	}
+	return nil
+}
+
+// ClaimQuote marks an unused, unexpired quote used in a single conditional UPDATE, so of two concurrent
+// transfers carrying the same quote exactly one matches the row. It returns util.ErrNotFound when nothing was claimed.
+func (r *TransferQuoteRepository) ClaimQuote(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID, now time.Time) (*domain.TransferQuote, error) {
+	var quote domain.TransferQuote
+	query := `WITH claimed AS (
+                  UPDATE transfer_quotes tq
+                  SET used_at = $2
+                  WHERE tq.public_id = $1 AND tq.used_at IS NULL AND tq.expires_at > $2
+                  RETURNING ` + transferQuoteColumns + `
+              )
+              SELECT tq.*, fw.public_id AS from_wallet_public_id, tw.public_id AS to_wallet_public_id
+              FROM claimed tq
+              JOIN wallets fw ON fw.id = tq.from_wallet_id
+              JOIN wallets tw ON tw.id = tq.to_wallet_id`
+	if err := q.GetContext(ctx, &quote, query, publicID, now); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to claim transfer quote: %w", translateError(err))
+	}
+	return &quote, nil
+}
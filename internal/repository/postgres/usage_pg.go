@@ -0,0 +1,75 @@
+// internal/repository/postgres/usage_pg.go
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// UsageRepository implements repository.UsageRepository for PostgreSQL.
+type UsageRepository struct{}
+
+// NewUsageRepository creates a new UsageRepository.
+func NewUsageRepository(db *sqlx.DB) repository.UsageRepository {
+	return &UsageRepository{}
+}
+
+// usageDate renders the month as the DATE it is keyed by. A time would be converted to a date in the session's
+// time zone, which could shift it into the previous month.
+func usageDate(month time.Time) string {
+	return month.Format(time.DateOnly)
+}
+
+// IncrementRequests counts a request in one upsert, so concurrent requests are all counted.
+func (r *UsageRepository) IncrementRequests(ctx context.Context, q repository.DBExecutor, partnerID string, month time.Time) (int64, error) {
+	query := `INSERT INTO partner_usage (partner_id, month, requests) VALUES ($1, $2, 1)
+              ON CONFLICT (partner_id, month) DO UPDATE SET requests = partner_usage.requests + 1
+              RETURNING requests`
+	var requests int64
+	if err := q.QueryRowContext(ctx, query, partnerID, usageDate(month)).Scan(&requests); err != nil {
+		return 0, fmt.Errorf("failed to count request of partner %s: %w", partnerID, translateError(err))
+	}
+	return requests, nil
+}
+
+// AddVolume adds to the partner's volume in the currency in one upsert.
+func (r *UsageRepository) AddVolume(ctx context.Context, q repository.DBExecutor, partnerID string, month time.Time, currency string, amount decimal.Decimal) error {
+	query := `INSERT INTO partner_volume (partner_id, month, currency, amount) VALUES ($1, $2, $3, $4)
+              ON CONFLICT (partner_id, month, currency) DO UPDATE SET amount = partner_volume.amount + EXCLUDED.amount`
+	if _, err := q.ExecContext(ctx, query, partnerID, usageDate(month), currency, amount); err != nil {
+		return fmt.Errorf("failed to add %s volume of partner %s: %w", currency, partnerID, translateError(err))
+	}
+	return nil
+}
+
+// GetUsage reads the partner's request count and its volume per currency in the month.
+func (r *UsageRepository) GetUsage(ctx context.Context, q repository.DBExecutor, partnerID string, month time.Time) (*domain.PartnerUsage, error) {
+	usage := &domain.PartnerUsage{PartnerID: partnerID, Month: month, Volume: map[string]decimal.Decimal{}}
+	var counts []int64
+	if err := q.SelectContext(ctx, &counts, `SELECT requests FROM partner_usage WHERE partner_id = $1 AND month = $2`, partnerID, usageDate(month)); err != nil {
+		return nil, fmt.Errorf("failed to get requests of partner %s: %w", partnerID, translateError(err))
+	}
+	for _, count := range counts {
+		usage.Requests = count
+	}
+
+	var volumes []struct {
+		Currency string          `db:"currency"`
+		Amount   decimal.Decimal `db:"amount"`
+	}
+	query := `SELECT currency, amount FROM partner_volume WHERE partner_id = $1 AND month = $2 ORDER BY currency`
+	if err := q.SelectContext(ctx, &volumes, query, partnerID, usageDate(month)); err != nil {
+		return nil, fmt.Errorf("failed to get volume of partner %s: %w", partnerID, translateError(err))
+	}
+	for _, volume := range volumes {
+		usage.Volume[volume.Currency] = volume.Amount
+	}
+	return usage, nil
+}
@@ -4,62 +4,388 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"finflow-wallet/internal/domain"
 	"finflow-wallet/internal/repository"
 	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/keyring"
 
 	"github.com/jmoiron/sqlx"
 )
 
 // UserRepository implements repository.UserRepository for PostgreSQL.
 type UserRepository struct {
-	// No longer holds *sqlx.DB as methods receive DBExecutor directly
+	keyring keyring.Keyring // Encrypts the email and phone columns; without it they cannot be set
 }
 
 // NewUserRepository creates a new UserRepository.
 // The db parameter is not stored in the struct, but passed to methods.
 // This constructor is now mainly for type assertion and consistency.
-func NewUserRepository(db *sqlx.DB) repository.UserRepository {
-	return &UserRepository{}
+func NewUserRepository(db *sqlx.DB, keyring keyring.Keyring) repository.UserRepository {
+	return &UserRepository{keyring: keyring}
+}
+
+// userColumns are the columns read into userRow.
+const userColumns = `id, username, timezone, residency, email_ciphertext, phone_ciphertext, pii_key_id, email_hash, phone_hash,
+              email_verified_at, phone_verified_at, created_at, updated_at, deactivated_at`
+
+// Associated data of the encrypted columns, binding each ciphertext to its column.
+const (
+	emailAssociatedData = "users.email"
+	phoneAssociatedData = "users.phone"
+)
+
+// userRow is a users row with its encrypted columns, decrypted into domain.User by toDomain.
+type userRow struct {
+	domain.User
+	EmailCiphertext *string `db:"email_ciphertext"`
+	PhoneCiphertext *string `db:"phone_ciphertext"`
+	PIIKeyID        *string `db:"pii_key_id"`
+}
+
+// toDomain decrypts the row's email and phone into its user.
+func (r *UserRepository) toDomain(row *userRow) (*domain.User, error) {
+	user := row.User
+	var err error
+	if user.Email, err = r.decrypt(row.EmailCiphertext, emailAssociatedData); err != nil {
+		return nil, fmt.Errorf("failed to decrypt email of user %d: %w", user.ID, err)
+	}
+	if user.Phone, err = r.decrypt(row.PhoneCiphertext, phoneAssociatedData); err != nil {
+		return nil, fmt.Errorf("failed to decrypt phone of user %d: %w", user.ID, err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) decrypt(ciphertext *string, associatedData string) (*string, error) {
+	if ciphertext == nil {
+		return nil, nil
+	}
+	if r.keyring == nil {
+		return nil, errors.New("no encryption keys configured")
+	}
+	plaintext, err := r.keyring.Decrypt(*ciphertext, associatedData)
+	if err != nil {
+		return nil, err
+	}
+	return &plaintext, nil
+}
+
+// contactColumns are the stored forms of a user's email and phone.
+type contactColumns struct {
+	emailCiphertext, phoneCiphertext *string
+	keyID                            *string // nil when neither is set
+	emailHash, phoneHash             *string
+}
+
+// encryptContact encrypts an email and phone with the primary key and computes their blind indexes.
+func (r *UserRepository) encryptContact(email, phone *string) (*contactColumns, error) {
+	columns := &contactColumns{}
+	if email == nil && phone == nil {
+		return columns, nil
+	}
+	if r.keyring == nil {
+		return nil, errors.New("no encryption keys configured")
+	}
+	encrypt := func(value *string, associatedData string) (ciphertext, hash *string, err error) {
+		if value == nil {
+			return nil, nil, nil
+		}
+		encrypted, err := r.keyring.Encrypt(*value, associatedData)
+		if err != nil {
+			return nil, nil, err
+		}
+		index := r.keyring.BlindIndex(*value, associatedData)
+		return &encrypted, &index, nil
+	}
+	var err error
+	if columns.emailCiphertext, columns.emailHash, err = encrypt(email, emailAssociatedData); err != nil {
+		return nil, err
+	}
+	if columns.phoneCiphertext, columns.phoneHash, err = encrypt(phone, phoneAssociatedData); err != nil {
+		return nil, err
+	}
+	primary := r.keyring.PrimaryKeyID()
+	columns.keyID = &primary
+	return columns, nil
 }
 
 // CreateUser inserts a new user into the database using the provided DBExecutor.
 func (r *UserRepository) CreateUser(ctx context.Context, q repository.DBExecutor, user *domain.User) error {
-	query := `INSERT INTO users (username, created_at, updated_at)
-              VALUES ($1, $2, $3) RETURNING id`
-	err := q.QueryRowContext(ctx, query, user.Username, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
+	if user.Timezone == "" {
+		user.Timezone = domain.DefaultTimezone
+	}
+	contact, err := r.encryptContact(user.Email, user.Phone)
 	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+		return fmt.Errorf("failed to create user '%s': %w", user.Username, err)
 	}
+	query := `INSERT INTO users (username, timezone, residency, email_ciphertext, phone_ciphertext, pii_key_id, email_hash, phone_hash,
+              created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	err = q.QueryRowContext(ctx, query, user.Username, user.Timezone, user.Residency, contact.emailCiphertext, contact.phoneCiphertext,
+		contact.keyID, contact.emailHash, contact.phoneHash, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create user '%s': %w", user.Username, translateError(err))
+	}
+	user.EmailHash, user.PhoneHash = contact.emailHash, contact.phoneHash
 	return nil
 }
 
-// GetUserByID retrieves a user by their ID using the provided DBExecutor.
+// GetUserByID retrieves a user by their ID using the provided DBExecutor. Soft-deleted users are not found.
 func (r *UserRepository) GetUserByID(ctx context.Context, q repository.DBExecutor, id int64) (*domain.User, error) {
-	var user domain.User
-	query := `SELECT id, username, created_at, updated_at FROM users WHERE id = $1`
-	err := q.GetContext(ctx, &user, query, id)
+	var row userRow
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
+	err := q.GetContext(ctx, &row, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, util.ErrNotFound
 		}
-		return nil, fmt.Errorf("failed to get user by ID %d: %w", id, err)
+		return nil, fmt.Errorf("failed to get user by ID %d: %w", id, translateError(err))
 	}
-	return &user, nil
+	return r.toDomain(&row)
 }
 
 // GetUserByUsername retrieves a user by their username using the provided DBExecutor.
 func (r *UserRepository) GetUserByUsername(ctx context.Context, q repository.DBExecutor, username string) (*domain.User, error) {
-	var user domain.User
-	query := `SELECT id, username, created_at, updated_at FROM users WHERE username = $1`
-	err := q.GetContext(ctx, &user, query, username)
+	var row userRow
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1 AND deleted_at IS NULL`
+	err := q.GetContext(ctx, &row, query, username)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, util.ErrNotFound
 		}
-		return nil, fmt.Errorf("failed to get user by username '%s': %w", username, err)
+		return nil, fmt.Errorf("failed to get user by username '%s': %w", username, translateError(err))
 	}
-	return &user, nil
+	return r.toDomain(&row)
+}
+
+// userListQuery is the shared builder for user list endpoints; oldest first by default.
+var userListQuery = repository.NewListQueryBuilder(
+	[]string{"id", "username", "timezone", "residency", "created_at", "updated_at"},
+	repository.SortField{Field: "id"},
+).AlwaysSelect("email_ciphertext", "phone_ciphertext", "pii_key_id", "email_hash", "phone_hash", "email_verified_at", "phone_verified_at",
+	"deactivated_at")
+
+// ListUsers retrieves a page of users using the provided DBExecutor.
+func (r *UserRepository) ListUsers(ctx context.Context, q repository.DBExecutor, opts repository.ListOptions) ([]domain.User, int64, error) {
+	var rows []userRow
+
+	query, countQuery, queryArgs, err := userListQuery.Build("users", "deleted_at IS NULL", nil, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := q.SelectContext(ctx, &rows, query, queryArgs...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", translateError(err))
+	}
+	users := make([]domain.User, len(rows))
+	for i := range rows {
+		user, err := r.toDomain(&rows[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		users[i] = *user
+	}
+
+	var totalCount int64
+	if err := q.GetContext(ctx, &totalCount, countQuery); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", translateError(err))
+	}
+	return users, totalCount, nil
+}
+
+// UpdateUserTimezone sets a user's time zone and returns the updated user.
+func (r *UserRepository) UpdateUserTimezone(ctx context.Context, q repository.DBExecutor, id int64, timezone string) (*domain.User, error) {
+	var row userRow
+	query := `UPDATE users SET timezone = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL
+              RETURNING ` + userColumns
+	if err := q.GetContext(ctx, &row, query, timezone, time.Now().UTC(), id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update time zone of user %d: %w", id, translateError(err))
+	}
+	return r.toDomain(&row)
+}
+
+// UpdateUserResidency sets or, with nil, clears a user's country of residence and returns the updated user.
+func (r *UserRepository) UpdateUserResidency(ctx context.Context, q repository.DBExecutor, id int64, residency *string) (*domain.User, error) {
+	var row userRow
+	query := `UPDATE users SET residency = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL
+              RETURNING ` + userColumns
+	if err := q.GetContext(ctx, &row, query, residency, time.Now().UTC(), id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update residency of user %d: %w", id, translateError(err))
+	}
+	return r.toDomain(&row)
+}
+
+// UpdateUserContact sets a user's email and phone, encrypted with the primary key, and returns the updated user.
+// A nil value clears the field. The verification of a field whose value changes is cleared; comparing the blind
+// indexes tells whether it did.
+func (r *UserRepository) UpdateUserContact(ctx context.Context, q repository.DBExecutor, id int64, email, phone *string) (*domain.User, error) {
+	contact, err := r.encryptContact(email, phone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact of user %d: %w", id, err)
+	}
+	var row userRow
+	query := `UPDATE users SET email_ciphertext = $1, phone_ciphertext = $2, pii_key_id = $3, email_hash = $4, phone_hash = $5,
+                  email_verified_at = CASE WHEN email_hash IS NOT DISTINCT FROM $4 THEN email_verified_at END,
+                  phone_verified_at = CASE WHEN phone_hash IS NOT DISTINCT FROM $5 THEN phone_verified_at END,
+                  updated_at = $6
+              WHERE id = $7 AND deleted_at IS NULL
+              RETURNING ` + userColumns
+	err = q.GetContext(ctx, &row, query, contact.emailCiphertext, contact.phoneCiphertext, contact.keyID, contact.emailHash, contact.phoneHash,
+		time.Now().UTC(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update contact of user %d: %w", id, translateError(err))
+	}
+	return r.toDomain(&row)
+}
+
+// UpdateUsername renames a user and returns the updated user. The unique constraint on the username surfaces
+// as util.ErrDuplicateEntry.
+func (r *UserRepository) UpdateUsername(ctx context.Context, q repository.DBExecutor, id int64, username string) (*domain.User, error) {
+	var row userRow
+	query := `UPDATE users SET username = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL
+              RETURNING ` + userColumns
+	if err := q.GetContext(ctx, &row, query, username, time.Now().UTC(), id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to rename user %d: %w", id, translateError(err))
+	}
+	return r.toDomain(&row)
+}
+
+// SetUserDeactivated deactivates a user at the given time or, with nil, reactivates them, and returns the
+// updated user.
+func (r *UserRepository) SetUserDeactivated(ctx context.Context, q repository.DBExecutor, id int64, at *time.Time) (*domain.User, error) {
+	var row userRow
+	query := `UPDATE users SET deactivated_at = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL
+              RETURNING ` + userColumns
+	if err := q.GetContext(ctx, &row, query, at, time.Now().UTC(), id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update deactivation of user %d: %w", id, translateError(err))
+	}
+	return r.toDomain(&row)
+}
+
+// ReencryptPII re-encrypts the email and phone of up to limit users, deleted ones included, whose columns
+// are encrypted with a key other than the primary one. The rows are locked with SKIP LOCKED, so q should be
+// a transaction and concurrent runs take disjoint batches. It returns the number of users re-encrypted.
+func (r *UserRepository) ReencryptPII(ctx context.Context, q repository.DBExecutor, limit int) (int, error) {
+	if r.keyring == nil {
+		return 0, nil
+	}
+	var rows []userRow
+	query := `SELECT ` + userColumns + ` FROM users
+              WHERE pii_key_id IS NOT NULL AND pii_key_id <> $1
+              ORDER BY id LIMIT $2 FOR UPDATE SKIP LOCKED`
+	if err := q.SelectContext(ctx, &rows, query, r.keyring.PrimaryKeyID(), limit); err != nil {
+		return 0, fmt.Errorf("failed to list users to re-encrypt: %w", translateError(err))
+	}
+
+	update := `UPDATE users SET email_ciphertext = $1, phone_ciphertext = $2, pii_key_id = $3 WHERE id = $4`
+	for i := range rows {
+		user, err := r.toDomain(&rows[i])
+		if err != nil {
+			return i, err
+		}
+		contact, err := r.encryptContact(user.Email, user.Phone)
+		if err != nil {
+			return i, fmt.Errorf("failed to re-encrypt contact of user %d: %w", user.ID, err)
+		}
+		if _, err := q.ExecContext(ctx, update, contact.emailCiphertext, contact.phoneCiphertext, contact.keyID, user.ID); err != nil {
+			return i, fmt.Errorf("failed to re-encrypt contact of user %d: %w", user.ID, translateError(err))
+		}
+	}
+	return len(rows), nil
+}
+
+// MarkContactVerified marks a user's email or phone verified at the given time, provided its blind index is
+// still targetHash, and returns the updated user. A value verified by another user yields
+// util.ErrDuplicateEntry.
+func (r *UserRepository) MarkContactVerified(ctx context.Context, q repository.DBExecutor, id int64, channel domain.ContactChannel, targetHash string, at time.Time) (*domain.User, error) {
+	var query string
+	switch channel {
+	case domain.ContactChannelEmail:
+		query = `UPDATE users SET email_verified_at = $1, updated_at = $1 WHERE id = $2 AND email_hash = $3 AND deleted_at IS NULL
+              RETURNING ` + userColumns
+	case domain.ContactChannelPhone:
+		query = `UPDATE users SET phone_verified_at = $1, updated_at = $1 WHERE id = $2 AND phone_hash = $3 AND deleted_at IS NULL
+              RETURNING ` + userColumns
+	default:
+		return nil, fmt.Errorf("unknown contact channel %q", channel)
+	}
+	var row userRow
+	if err := q.GetContext(ctx, &row, query, at, id, targetHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to mark %s of user %d verified: %w", channel, id, translateError(err))
+	}
+	return r.toDomain(&row)
+}
+
+// SoftDeleteUser marks a user deleted at the given time.
+func (r *UserRepository) SoftDeleteUser(ctx context.Context, q repository.DBExecutor, id int64, at time.Time) error {
+	query := `UPDATE users SET deleted_at = $1, updated_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	result, err := q.ExecContext(ctx, query, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user %d: %w", id, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting user %d: %w", id, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// RestoreUser clears the deletion mark of a soft-deleted user and returns when it had been deleted.
+func (r *UserRepository) RestoreUser(ctx context.Context, q repository.DBExecutor, id int64) (time.Time, error) {
+	var deletedAt time.Time
+	query := `WITH deleted AS (SELECT id, deleted_at FROM users WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE)
+              UPDATE users u SET deleted_at = NULL, updated_at = $2
+              FROM deleted d WHERE u.id = d.id
+              RETURNING d.deleted_at`
+	if err := q.GetContext(ctx, &deletedAt, query, id, time.Now().UTC()); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, util.ErrNotFound
+		}
+		return time.Time{}, fmt.Errorf("failed to restore user %d: %w", id, translateError(err))
+	}
+	return deletedAt, nil
+}
+
+// ListDeletedUserIDs returns the IDs of users soft-deleted before the given time.
+func (r *UserRepository) ListDeletedUserIDs(ctx context.Context, q repository.DBExecutor, before time.Time) ([]int64, error) {
+	var ids []int64
+	query := `SELECT id FROM users WHERE deleted_at < $1 ORDER BY id`
+	if err := q.SelectContext(ctx, &ids, query, before); err != nil {
+		return nil, fmt.Errorf("failed to list deleted users: %w", translateError(err))
+	}
+	return ids, nil
+}
+
+// PurgeUser permanently deletes a soft-deleted user. It fails with util.ErrReferenceViolation while
+// rows such as wallets still reference the user.
+func (r *UserRepository) PurgeUser(ctx context.Context, q repository.DBExecutor, id int64) error {
+	query := `DELETE FROM users WHERE id = $1 AND deleted_at IS NOT NULL`
+	if _, err := q.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to purge user %d: %w", id, translateError(err))
+	}
+	return nil
 }
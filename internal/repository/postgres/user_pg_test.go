@@ -0,0 +1,146 @@
+// internal/repository/postgres/user_pg_test.go
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/keyring"
+)
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	keys, err := keyring.NewAESKeyring(map[string][]byte{"k1": bytes.Repeat([]byte{1}, keyring.KeySize)}, "k1", bytes.Repeat([]byte{2}, keyring.KeySize))
+	require.NoError(t, err)
+	repo := &UserRepository{keyring: keys}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	insertUserQuery := `INSERT INTO users (username, timezone, residency, email_ciphertext, phone_ciphertext, pii_key_id, email_hash, phone_hash,
+              created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	getUserByIDQuery := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND deleted_at IS NULL`
+	userRowColumns := []string{"id", "username", "timezone", "residency", "email_ciphertext", "phone_ciphertext", "pii_key_id",
+		"email_hash", "phone_hash", "email_verified_at", "phone_verified_at", "created_at", "updated_at"}
+
+	t.Run("CreateUserWithoutContact", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		user := domain.NewUser("alice")
+		mock.ExpectQuery(insertUserQuery).
+			WithArgs("alice", domain.DefaultTimezone, nil, nil, nil, nil, nil, nil, user.CreatedAt, user.UpdatedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+		require.NoError(t, repo.CreateUser(ctx, database, user))
+		assert.Equal(t, int64(7), user.ID)
+	})
+
+	t.Run("CreateUserEncryptsContact", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		user := domain.NewUser("alice")
+		email := "alice@example.com"
+		user.Email = &email
+		emailHash := keys.BlindIndex(email, emailAssociatedData)
+		mock.ExpectQuery(insertUserQuery).
+			WithArgs("alice", domain.DefaultTimezone, nil, sqlmock.AnyArg(), nil, "k1", emailHash, nil, user.CreatedAt, user.UpdatedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+		require.NoError(t, repo.CreateUser(ctx, database, user))
+		assert.Equal(t, emailHash, *user.EmailHash)
+	})
+
+	t.Run("CreateUserWithContactNeedsKeyring", func(t *testing.T) {
+		database, _ := newMockDB(t)
+		user := domain.NewUser("alice")
+		email := "alice@example.com"
+		user.Email = &email
+
+		err := (&UserRepository{}).CreateUser(ctx, database, user)
+		assert.ErrorContains(t, err, "no encryption keys configured")
+	})
+
+	t.Run("CreateUserTranslatesUniqueViolation", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(insertUserQuery).WillReturnError(&pq.Error{Code: pgUniqueViolation})
+
+		err := repo.CreateUser(ctx, database, domain.NewUser("alice"))
+		assert.ErrorIs(t, err, util.ErrDuplicateEntry)
+	})
+
+	t.Run("GetUserByIDDecryptsContact", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		ciphertext, err := keys.Encrypt("alice@example.com", emailAssociatedData)
+		require.NoError(t, err)
+		mock.ExpectQuery(getUserByIDQuery).WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows(userRowColumns).
+				AddRow(7, "alice", "Europe/Berlin", "DE", ciphertext, nil, "k1", "hash", nil, now, nil, now, now))
+
+		user, err := repo.GetUserByID(ctx, database, 7)
+
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", *user.Email)
+		assert.Nil(t, user.Phone)
+		assert.Equal(t, "DE", *user.Residency)
+		assert.Equal(t, now, *user.EmailVerifiedAt)
+	})
+
+	t.Run("GetUserByIDRejectsCiphertextOfAnotherColumn", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		ciphertext, err := keys.Encrypt("+4915112345678", phoneAssociatedData)
+		require.NoError(t, err)
+		mock.ExpectQuery(getUserByIDQuery).WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows(userRowColumns).
+				AddRow(7, "alice", "UTC", nil, ciphertext, nil, "k1", nil, nil, nil, nil, now, now))
+
+		_, err = repo.GetUserByID(ctx, database, 7)
+		assert.ErrorContains(t, err, "failed to decrypt email of user 7")
+	})
+
+	t.Run("GetUserByIDNotFound", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(getUserByIDQuery).WithArgs(int64(7)).WillReturnRows(sqlmock.NewRows(userRowColumns))
+
+		_, err := repo.GetUserByID(ctx, database, 7)
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+
+	t.Run("PurgeUserTranslatesForeignKeyViolation", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectExec(`DELETE FROM users WHERE id = $1 AND deleted_at IS NOT NULL`).WithArgs(int64(7)).
+			WillReturnError(&pq.Error{Code: pgForeignKeyViolation})
+
+		err := repo.PurgeUser(ctx, database, 7)
+		assert.ErrorIs(t, err, util.ErrReferenceViolation)
+	})
+
+	t.Run("UpdateUsernameTranslatesUniqueViolation", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(`UPDATE users SET username = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL
+              RETURNING `+userColumns).
+			WithArgs("bob", sqlmock.AnyArg(), int64(7)).
+			WillReturnError(&pq.Error{Code: pgUniqueViolation})
+
+		_, err := repo.UpdateUsername(ctx, database, 7, "bob")
+		assert.ErrorIs(t, err, util.ErrDuplicateEntry)
+	})
+
+	t.Run("SetUserDeactivatedReturnsUser", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(`UPDATE users SET deactivated_at = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL
+              RETURNING `+userColumns).
+			WithArgs(now, sqlmock.AnyArg(), int64(7)).
+			WillReturnRows(sqlmock.NewRows(append(userRowColumns, "deactivated_at")).
+				AddRow(7, "alice", domain.DefaultTimezone, nil, nil, nil, nil, nil, nil, nil, nil, now, now, now))
+
+		user, err := repo.SetUserDeactivated(ctx, database, 7, &now)
+		require.NoError(t, err)
+		require.NotNil(t, user.DeactivatedAt)
+		assert.Equal(t, now, *user.DeactivatedAt)
+	})
+}
@@ -0,0 +1,106 @@
+// internal/repository/postgres/voucher_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// VoucherRepository implements repository.VoucherRepository for PostgreSQL.
+type VoucherRepository struct{}
+
+// NewVoucherRepository creates a new VoucherRepository.
+func NewVoucherRepository(db *sqlx.DB) repository.VoucherRepository {
+	return &VoucherRepository{}
+}
+
+// voucherColumns lists the voucher columns; the redeeming wallet's public ID is joined separately.
+const voucherColumns = `v.id, v.public_id, v.code_hash, v.code_hint, v.amount, v.currency, v.status, v.expires_at,
+                        v.redeemed_wallet_id, v.transaction_id, v.redeemed_at, v.created_at, v.updated_at`
+
+// CreateVoucher stores a newly issued voucher; it returns util.ErrDuplicateEntry if the code already exists.
+func (r *VoucherRepository) CreateVoucher(ctx context.Context, q repository.DBExecutor, voucher *domain.Voucher) error {
+	query := `INSERT INTO vouchers (public_id, code_hash, code_hint, amount, currency, status, expires_at, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		voucher.PublicID,
+		voucher.CodeHash,
+		voucher.CodeHint,
+		voucher.Amount,
+		voucher.Currency,
+		voucher.Status,
+		voucher.ExpiresAt,
+		voucher.CreatedAt,
+		voucher.UpdatedAt,
+	).Scan(&voucher.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create voucher: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetVoucherByCodeHash retrieves a voucher by the hash of its code.
+func (r *VoucherRepository) GetVoucherByCodeHash(ctx context.Context, q repository.DBExecutor, codeHash string) (*domain.Voucher, error) {
+	var voucher domain.Voucher
+	query := `SELECT ` + voucherColumns + `, w.public_id AS redeemed_wallet_public_id
+              FROM vouchers v
+              LEFT JOIN wallets w ON w.id = v.redeemed_wallet_id
+              WHERE v.code_hash = $1`
+	if err := q.GetContext(ctx, &voucher, query, codeHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get voucher: %w", translateError(err))
+	}
+	return &voucher, nil
+}
+
+// ClaimVoucher marks an ACTIVE, unexpired voucher REDEEMED in a single conditional UPDATE, so of two concurrent
+// redemptions of the same code exactly one matches the row. It returns util.ErrNotFound when nothing was claimed.
+func (r *VoucherRepository) ClaimVoucher(ctx context.Context, q repository.DBExecutor, codeHash string, walletID int64, transactionID uuid.UUID, now time.Time) (*domain.Voucher, error) {
+	var voucher domain.Voucher
+	query := `WITH claimed AS (
+                  UPDATE vouchers v
+                  SET status = 'REDEEMED', redeemed_wallet_id = $2, transaction_id = $3, redeemed_at = $4, updated_at = $4
+                  WHERE v.code_hash = $1 AND v.status = 'ACTIVE' AND v.expires_at > $4
+                  RETURNING ` + voucherColumns + `
+              )
+              SELECT v.*, w.public_id AS redeemed_wallet_public_id
+              FROM claimed v
+              JOIN wallets w ON w.id = v.redeemed_wallet_id`
+	if err := q.GetContext(ctx, &voucher, query, codeHash, walletID, transactionID, now); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to claim voucher: %w", translateError(err))
+	}
+	return &voucher, nil
+}
+
+// SumLiability reports issued voucher value per currency, split into outstanding, expired and redeemed at now.
+func (r *VoucherRepository) SumLiability(ctx context.Context, q repository.DBExecutor, now time.Time) ([]domain.VoucherLiability, error) {
+	var liabilities []domain.VoucherLiability
+	query := `SELECT currency,
+                     COUNT(*) FILTER (WHERE status = 'ACTIVE' AND expires_at > $1) AS outstanding_count,
+                     COALESCE(SUM(amount) FILTER (WHERE status = 'ACTIVE' AND expires_at > $1), 0) AS outstanding_amount,
+                     COUNT(*) FILTER (WHERE status = 'ACTIVE' AND expires_at <= $1) AS expired_count,
+                     COALESCE(SUM(amount) FILTER (WHERE status = 'ACTIVE' AND expires_at <= $1), 0) AS expired_amount,
+                     COUNT(*) FILTER (WHERE status = 'REDEEMED') AS redeemed_count,
+                     COALESCE(SUM(amount) FILTER (WHERE status = 'REDEEMED'), 0) AS redeemed_amount
+              FROM vouchers
+              GROUP BY currency
+              ORDER BY currency`
+	if err := q.SelectContext(ctx, &liabilities, query, now); err != nil {
+		return nil, fmt.Errorf("failed to sum voucher liability: %w", translateError(err))
+	}
+	return liabilities, nil
+}
@@ -0,0 +1,159 @@
+// internal/repository/postgres/wallet_export_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// WalletExportRepository implements repository.WalletExportRepository for PostgreSQL.
+type WalletExportRepository struct{}
+
+// NewWalletExportRepository creates a new WalletExportRepository.
+func NewWalletExportRepository(db *sqlx.DB) repository.WalletExportRepository {
+	return &WalletExportRepository{}
+}
+
+// walletExportColumns lists the export columns; the wallet's public ID is joined separately.
+const walletExportColumns = `e.id, e.public_id, e.wallet_id, e.locale, e.status, e.total_rows, e.processed_rows, e.object_key, e.error,
+                             e.started_at, e.completed_at, e.audit_sequence, e.audit_hash, e.created_at, e.updated_at`
+
+// CreateExport queues a new export.
+func (r *WalletExportRepository) CreateExport(ctx context.Context, q repository.DBExecutor, export *domain.WalletExport) error {
+	query := `INSERT INTO wallet_exports (public_id, wallet_id, locale, status, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		export.PublicID,
+		export.WalletID,
+		export.Locale,
+		export.Status,
+		export.CreatedAt,
+		export.UpdatedAt,
+	).Scan(&export.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create wallet export: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetExportByPublicID retrieves an export by its public ID.
+func (r *WalletExportRepository) GetExportByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.WalletExport, error) {
+	var export domain.WalletExport
+	query := `SELECT ` + walletExportColumns + `, w.public_id AS wallet_public_id
+              FROM wallet_exports e
+              JOIN wallets w ON w.id = e.wallet_id
+              WHERE e.public_id = $1`
+	if err := q.GetContext(ctx, &export, query, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet export %s: %w", publicID, translateError(err))
+	}
+	return &export, nil
+}
+
+// ClaimNextExport claims the oldest queued export; SKIP LOCKED lets several workers claim different exports.
+func (r *WalletExportRepository) ClaimNextExport(ctx context.Context, q repository.DBExecutor, now time.Time) (*domain.WalletExport, error) {
+	var export domain.WalletExport
+	query := `WITH claimed AS (
+                  UPDATE wallet_exports e
+                  SET status = 'RUNNING', started_at = $1, updated_at = $1
+                  WHERE e.id = (
+                      SELECT id FROM wallet_exports WHERE status = 'PENDING' ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED
+                  )
+                  RETURNING ` + walletExportColumns + `
+              )
+              SELECT e.*, w.public_id AS wallet_public_id
+              FROM claimed e
+              JOIN wallets w ON w.id = e.wallet_id`
+	if err := q.GetContext(ctx, &export, query, now); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to claim wallet export: %w", translateError(err))
+	}
+	return &export, nil
+}
+
+// RequeueStaleExports returns exports stuck in RUNNING to the queue, restarting their progress.
+func (r *WalletExportRepository) RequeueStaleExports(ctx context.Context, q repository.DBExecutor, startedBefore time.Time) (int64, error) {
+	query := `UPDATE wallet_exports
+              SET status = 'PENDING', processed_rows = 0, started_at = NULL, updated_at = NOW()
+              WHERE status = 'RUNNING' AND started_at < $1`
+	result, err := q.ExecContext(ctx, query, startedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale wallet exports: %w", translateError(err))
+	}
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected for requeued wallet exports: %w", err)
+	}
+	return requeued, nil
+}
+
+// UpdateProgress records how many of the export's rows have been written.
+func (r *WalletExportRepository) UpdateProgress(ctx context.Context, q repository.DBExecutor, exportID int64, processed, total int64) error {
+	query := `UPDATE wallet_exports SET processed_rows = $2, total_rows = $3, updated_at = NOW() WHERE id = $1`
+	if _, err := q.ExecContext(ctx, query, exportID, processed, total); err != nil {
+		return fmt.Errorf("failed to update progress of wallet export %d: %w", exportID, translateError(err))
+	}
+	return nil
+}
+
+// CompleteExport marks the export COMPLETED with the key of its file and the audit chain head.
+func (r *WalletExportRepository) CompleteExport(ctx context.Context, q repository.DBExecutor, exportID int64, objectKey string, auditHead *domain.AuditChainHead, now time.Time) error {
+	var auditSequence *int64
+	var auditHash *string
+	if auditHead != nil {
+		auditSequence, auditHash = &auditHead.Sequence, &auditHead.Hash
+	}
+	query := `UPDATE wallet_exports SET status = 'COMPLETED', object_key = $2, audit_sequence = $3, audit_hash = $4, completed_at = $5, updated_at = $5
+              WHERE id = $1`
+	if _, err := q.ExecContext(ctx, query, exportID, objectKey, auditSequence, auditHash, now); err != nil {
+		return fmt.Errorf("failed to complete wallet export %d: %w", exportID, translateError(err))
+	}
+	return nil
+}
+
+// FailExport marks the export FAILED with the reason.
+func (r *WalletExportRepository) FailExport(ctx context.Context, q repository.DBExecutor, exportID int64, message string, now time.Time) error {
+	query := `UPDATE wallet_exports SET status = 'FAILED', error = $2, completed_at = $3, updated_at = $3 WHERE id = $1`
+	if _, err := q.ExecContext(ctx, query, exportID, message, now); err != nil {
+		return fmt.Errorf("failed to fail wallet export %d: %w", exportID, translateError(err))
+	}
+	return nil
+}
+
+// CountWalletTransactions counts a wallet's transactions in the hot table and the archive.
+func (r *WalletExportRepository) CountWalletTransactions(ctx context.Context, q repository.DBExecutor, walletID int64) (int64, error) {
+	var count int64
+	query := `SELECT
+                  (SELECT COUNT(*) FROM transactions WHERE from_wallet_id = $1 OR to_wallet_id = $1) +
+                  (SELECT COUNT(*) FROM transactions_archive WHERE from_wallet_id = $1 OR to_wallet_id = $1)`
+	if err := q.GetContext(ctx, &count, query, walletID); err != nil {
+		return 0, fmt.Errorf("failed to count transactions of wallet %d: %w", walletID, translateError(err))
+	}
+	return count, nil
+}
+
+// ListWalletTransactionsAfter retrieves the next page of a wallet's transactions in ID order, which is
+// stable across archival since archived rows keep their IDs.
+func (r *WalletExportRepository) ListWalletTransactionsAfter(ctx context.Context, q repository.DBExecutor, walletID, afterID int64, limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	query := `SELECT * FROM ` + transactionSource(true) + `
+              WHERE (from_wallet_id = $1 OR to_wallet_id = $1) AND id > $2
+              ORDER BY id LIMIT $3`
+	if err := q.SelectContext(ctx, &transactions, query, walletID, afterID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list transactions of wallet %d for export: %w", walletID, translateError(err))
+	}
+	return transactions, nil
+}
@@ -0,0 +1,192 @@
+// internal/repository/postgres/wallet_member_pg.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// WalletMemberRepository implements repository.WalletMemberRepository for PostgreSQL.
+type WalletMemberRepository struct{}
+
+// NewWalletMemberRepository creates a new WalletMemberRepository.
+func NewWalletMemberRepository(db *sqlx.DB) repository.WalletMemberRepository {
+	return &WalletMemberRepository{}
+}
+
+// AddMember adds a member to a wallet; it returns util.ErrDuplicateEntry if the user already is one.
+func (r *WalletMemberRepository) AddMember(ctx context.Context, q repository.DBExecutor, member *domain.WalletMember) error {
+	query := `INSERT INTO wallet_members (wallet_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)`
+	_, err := q.ExecContext(ctx, query, member.WalletID, member.UserID, member.Role, member.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add member %d to wallet %d: %w", member.UserID, member.WalletID, translateError(err))
+	}
+	return nil
+}
+
+// ListMembers retrieves the stored members of a wallet.
+func (r *WalletMemberRepository) ListMembers(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.WalletMember, error) {
+	var members []domain.WalletMember
+	query := `SELECT wallet_id, user_id, role, created_at FROM wallet_members WHERE wallet_id = $1 ORDER BY created_at, user_id`
+	if err := q.SelectContext(ctx, &members, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to list members of wallet %d: %w", walletID, translateError(err))
+	}
+	return members, nil
+}
+
+// RemoveMember removes a member; it returns util.ErrNotFound if the user is not a member.
+func (r *WalletMemberRepository) RemoveMember(ctx context.Context, q repository.DBExecutor, walletID, userID int64) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM wallet_members WHERE wallet_id = $1 AND user_id = $2`, walletID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove member %d from wallet %d: %w", userID, walletID, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after removing member %d from wallet %d: %w", userID, walletID, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// GetApprovalPolicy retrieves a wallet's approval policy; it returns util.ErrNotFound if the wallet has none.
+func (r *WalletMemberRepository) GetApprovalPolicy(ctx context.Context, q repository.DBExecutor, walletID int64) (*domain.ApprovalPolicy, error) {
+	var policy domain.ApprovalPolicy
+	query := `SELECT wallet_id, threshold, required_approvals, updated_at FROM wallet_approval_policies WHERE wallet_id = $1`
+	if err := q.GetContext(ctx, &policy, query, walletID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get approval policy of wallet %d: %w", walletID, translateError(err))
+	}
+	return &policy, nil
+}
+
+// SaveApprovalPolicy creates or replaces a wallet's approval policy.
+func (r *WalletMemberRepository) SaveApprovalPolicy(ctx context.Context, q repository.DBExecutor, policy *domain.ApprovalPolicy) error {
+	query := `INSERT INTO wallet_approval_policies (wallet_id, threshold, required_approvals, updated_at)
+              VALUES ($1, $2, $3, $4)
+              ON CONFLICT (wallet_id) DO UPDATE SET
+                  threshold = EXCLUDED.threshold,
+                  required_approvals = EXCLUDED.required_approvals,
+                  updated_at = EXCLUDED.updated_at`
+	_, err := q.ExecContext(ctx, query, policy.WalletID, policy.Threshold, policy.RequiredApprovals, policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save approval policy of wallet %d: %w", policy.WalletID, translateError(err))
+	}
+	return nil
+}
+
+// DeleteApprovalPolicy removes a wallet's approval policy; it returns util.ErrNotFound if the wallet has none.
+func (r *WalletMemberRepository) DeleteApprovalPolicy(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	result, err := q.ExecContext(ctx, `DELETE FROM wallet_approval_policies WHERE wallet_id = $1`, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to delete approval policy of wallet %d: %w", walletID, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting approval policy of wallet %d: %w", walletID, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// TransferApprovalRepository implements repository.TransferApprovalRepository for PostgreSQL.
+type TransferApprovalRepository struct{}
+
+// NewTransferApprovalRepository creates a new TransferApprovalRepository.
+func NewTransferApprovalRepository(db *sqlx.DB) repository.TransferApprovalRepository {
+	return &TransferApprovalRepository{}
+}
+
+// transferApprovalSelect projects approval requests together with the public IDs of both wallets.
+const transferApprovalSelect = `SELECT a.id, a.public_id, a.from_wallet_id, a.to_wallet_id,
+                                       fw.public_id AS from_wallet_public_id, tw.public_id AS to_wallet_public_id,
+                                       a.amount, a.currency, a.requested_by, a.required_approvals, a.status,
+                                       a.transaction_id, a.failure_reason, a.created_at, a.updated_at
+                                FROM transfer_approvals a
+                                JOIN wallets fw ON fw.id = a.from_wallet_id
+                                JOIN wallets tw ON tw.id = a.to_wallet_id`
+
+// CreateApproval stores a new transfer approval request.
+func (r *TransferApprovalRepository) CreateApproval(ctx context.Context, q repository.DBExecutor, approval *domain.TransferApproval) error {
+	query := `INSERT INTO transfer_approvals (public_id, from_wallet_id, to_wallet_id, amount, currency, requested_by,
+                                              required_approvals, status, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	err := q.QueryRowContext(ctx, query,
+		approval.PublicID,
+		approval.FromWalletID,
+		approval.ToWalletID,
+		approval.Amount,
+		approval.Currency,
+		approval.RequestedBy,
+		approval.RequiredApprovals,
+		approval.Status,
+		approval.CreatedAt,
+		approval.UpdatedAt,
+	).Scan(&approval.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create transfer approval: %w", translateError(err))
+	}
+	return nil
+}
+
+// GetApprovalByPublicID retrieves an approval request, including the users who approved it.
+func (r *TransferApprovalRepository) GetApprovalByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.TransferApproval, error) {
+	return r.getApproval(ctx, q, transferApprovalSelect+` WHERE a.public_id = $1`, publicID)
+}
+
+// GetApprovalByPublicIDForUpdate retrieves an approval request and locks the row until the surrounding transaction ends.
+// It must be called with a transactional DBExecutor.
+func (r *TransferApprovalRepository) GetApprovalByPublicIDForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.TransferApproval, error) {
+	return r.getApproval(ctx, q, transferApprovalSelect+` WHERE a.public_id = $1 FOR UPDATE OF a`, publicID)
+}
+
+func (r *TransferApprovalRepository) getApproval(ctx context.Context, q repository.DBExecutor, query string, publicID uuid.UUID) (*domain.TransferApproval, error) {
+	var approval domain.TransferApproval
+	if err := q.GetContext(ctx, &approval, query, publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get transfer approval %s: %w", publicID, translateError(err))
+	}
+
+	var voters pq.Int64Array
+	votesQuery := `SELECT COALESCE(array_agg(user_id ORDER BY created_at), '{}') FROM transfer_approval_votes WHERE approval_id = $1`
+	if err := q.GetContext(ctx, &voters, votesQuery, approval.ID); err != nil {
+		return nil, fmt.Errorf("failed to get votes of transfer approval %s: %w", publicID, translateError(err))
+	}
+	approval.ApprovedBy = []int64(voters)
+	return &approval, nil
+}
+
+// AddVote records a user's approval; it returns util.ErrDuplicateEntry if the user already approved.
+func (r *TransferApprovalRepository) AddVote(ctx context.Context, q repository.DBExecutor, approvalID, userID int64) error {
+	_, err := q.ExecContext(ctx, `INSERT INTO transfer_approval_votes (approval_id, user_id) VALUES ($1, $2)`, approvalID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to record approval of user %d: %w", userID, translateError(err))
+	}
+	return nil
+}
+
+// UpdateApproval stores the status, transaction ID and failure reason of an approval request.
+func (r *TransferApprovalRepository) UpdateApproval(ctx context.Context, q repository.DBExecutor, approval *domain.TransferApproval) error {
+	query := `UPDATE transfer_approvals SET status = $1, transaction_id = $2, failure_reason = $3, updated_at = $4 WHERE id = $5`
+	_, err := q.ExecContext(ctx, query, approval.Status, approval.TransactionID, approval.FailureReason, approval.UpdatedAt, approval.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update transfer approval %s: %w", approval.PublicID, translateError(err))
+	}
+	return nil
+}
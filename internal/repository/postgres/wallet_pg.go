@@ -2,16 +2,20 @@
 package postgres
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"time"
 
 	"finflow-wallet/internal/domain"
 	"finflow-wallet/internal/repository"
 	"finflow-wallet/internal/util"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/shopspring/decimal"
 )
 
@@ -27,11 +31,17 @@ func NewWalletRepository(db *sqlx.DB) repository.WalletRepository {
 
 // CreateWallet inserts a new wallet into the database using the provided DBExecutor.
 func (r *WalletRepository) CreateWallet(ctx context.Context, q repository.DBExecutor, wallet *domain.Wallet) error {
-	query := `INSERT INTO wallets (user_id, currency, balance, created_at, updated_at)
-              VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	err := q.QueryRowContext(ctx, query, wallet.UserID, wallet.Currency, wallet.Balance, wallet.CreatedAt, wallet.UpdatedAt).Scan(&wallet.ID)
+	if wallet.Kind == "" {
+		wallet.Kind = domain.WalletKindPersonal
+	}
+	if wallet.LastActivityAt.IsZero() {
+		wallet.LastActivityAt = wallet.CreatedAt
+	}
+	query := `INSERT INTO wallets (public_id, user_id, currency, balance, kind, version, created_at, updated_at, last_activity_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	err := q.QueryRowContext(ctx, query, wallet.PublicID, wallet.UserID, wallet.Currency, wallet.Balance, wallet.Kind, wallet.Version, wallet.CreatedAt, wallet.UpdatedAt, wallet.LastActivityAt).Scan(&wallet.ID)
 	if err != nil {
-		return fmt.Errorf("failed to create wallet: %w", err)
+		return fmt.Errorf("failed to create wallet: %w", translateError(err))
 	}
 	return nil
 }
@@ -39,13 +49,51 @@ func (r *WalletRepository) CreateWallet(ctx context.Context, q repository.DBExec
 // GetWalletByID retrieves a wallet by its ID using the provided DBExecutor.
 func (r *WalletRepository) GetWalletByID(ctx context.Context, q repository.DBExecutor, id int64) (*domain.Wallet, error) {
 	var wallet domain.Wallet
-	query := `SELECT id, user_id, currency, balance, created_at, updated_at FROM wallets WHERE id = $1`
+	err := q.GetContext(ctx, &wallet, getWalletByIDQuery, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet by ID %d: %w", id, translateError(err))
+	}
+	return &wallet, nil
+}
+
+// GetWalletsByIDs retrieves the given wallets in one query, in ascending ID order. Wallets that do
+// not exist or are soft-deleted are left out.
+func (r *WalletRepository) GetWalletsByIDs(ctx context.Context, q repository.DBExecutor, ids []int64) ([]domain.Wallet, error) {
+	wallets := []domain.Wallet{}
+	if err := q.SelectContext(ctx, &wallets, getWalletsByIDsQuery, pq.Array(ids)); err != nil {
+		return nil, fmt.Errorf("failed to get wallets by IDs %v: %w", ids, translateError(err))
+	}
+	return wallets, nil
+}
+
+// GetWalletByPublicID retrieves a wallet by its public UUID using the provided DBExecutor.
+func (r *WalletRepository) GetWalletByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	query := `SELECT ` + walletColumns("wallets") + ` FROM wallets WHERE public_id = $1 AND deleted_at IS NULL`
+	err := q.GetContext(ctx, &wallet, query, publicID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet by public ID %s: %w", publicID, translateError(err))
+	}
+	return &wallet, nil
+}
+
+// GetWalletByIDForUpdate retrieves a wallet by its ID and locks the row until the surrounding transaction ends.
+// It must be called with a transactional DBExecutor.
+func (r *WalletRepository) GetWalletByIDForUpdate(ctx context.Context, q repository.DBExecutor, id int64) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	query := `SELECT ` + walletColumns("wallets") + ` FROM wallets WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
 	err := q.GetContext(ctx, &wallet, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, util.ErrNotFound
 		}
-		return nil, fmt.Errorf("failed to get wallet by ID %d: %w", id, err)
+		return nil, fmt.Errorf("failed to lock wallet by ID %d: %w", id, translateError(err))
 	}
 	return &wallet, nil
 }
@@ -53,31 +101,242 @@ func (r *WalletRepository) GetWalletByID(ctx context.Context, q repository.DBExe
 // GetWalletByUserIDAndCurrency retrieves a wallet by user ID and currency using the provided DBExecutor.
 func (r *WalletRepository) GetWalletByUserIDAndCurrency(ctx context.Context, q repository.DBExecutor, userID int64, currency string) (*domain.Wallet, error) {
 	var wallet domain.Wallet
-	query := `SELECT id, user_id, currency, balance, created_at, updated_at FROM wallets WHERE user_id = $1 AND currency = $2`
+	query := `SELECT ` + walletColumns("wallets") + ` FROM wallets WHERE user_id = $1 AND currency = $2 AND deleted_at IS NULL`
 	err := q.GetContext(ctx, &wallet, query, userID, currency)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, util.ErrNotFound
 		}
-		return nil, fmt.Errorf("failed to get wallet by user ID %d and currency %s: %w", userID, currency, err)
+		return nil, fmt.Errorf("failed to get wallet by user ID %d and currency %s: %w", userID, currency, translateError(err))
 	}
 	return &wallet, nil
 }
 
-// UpdateWalletBalance updates the balance of a specific wallet using the provided DBExecutor.
-func (r *WalletRepository) UpdateWalletBalance(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal) error {
-	query := `UPDATE wallets SET balance = balance + $1, updated_at = $2 WHERE id = $3`
-	result, err := q.ExecContext(ctx, query, amount, time.Now().UTC(), walletID)
+// UpdateWalletBalance adds amount to the balance of a specific wallet using the provided DBExecutor, dated at,
+// and returns the new balance, version and update time.
+func (r *WalletRepository) UpdateWalletBalance(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal, at time.Time) (*domain.WalletBalance, error) {
+	var balance domain.WalletBalance
+	err := q.GetContext(ctx, &balance, updateWalletBalanceQuery, amount, at, walletID)
 	if err != nil {
-		return fmt.Errorf("failed to update wallet balance for ID %d: %w", walletID, err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("no rows affected when updating wallet balance for ID %d, wallet might not exist", walletID)
+		}
+		return nil, fmt.Errorf("failed to update wallet balance for ID %d: %w", walletID, translateError(err))
+	}
+	return &balance, nil
+}
+
+// UpdateWalletBalances adds each amount to the balance of its wallet in one statement dated at and returns
+// the updated wallets in ascending ID order.
+func (r *WalletRepository) UpdateWalletBalances(ctx context.Context, q repository.DBExecutor, amounts map[int64]decimal.Decimal, at time.Time) ([]domain.Wallet, error) {
+	ids := make([]int64, 0, len(amounts))
+	deltas := make([]string, 0, len(amounts))
+	for id, amount := range amounts {
+		ids = append(ids, id)
+		deltas = append(deltas, amount.String())
 	}
 
+	wallets := []domain.Wallet{}
+	if err := q.SelectContext(ctx, &wallets, updateWalletBalancesQuery, pq.Array(ids), pq.Array(deltas), at); err != nil {
+		return nil, fmt.Errorf("failed to update wallet balances for IDs %v: %w", ids, translateError(err))
+	}
+	if len(wallets) != len(amounts) {
+		return nil, fmt.Errorf("updated %d of %d wallet balances for IDs %v, a wallet might not exist", len(wallets), len(amounts), ids)
+	}
+	slices.SortFunc(wallets, func(a, b domain.Wallet) int { return cmp.Compare(a.ID, b.ID) })
+	return wallets, nil
+}
+
+// UpdateWalletKind changes the kind of a wallet using the provided DBExecutor.
+func (r *WalletRepository) UpdateWalletKind(ctx context.Context, q repository.DBExecutor, walletID int64, kind domain.WalletKind) error {
+	query := `UPDATE wallets SET kind = $1, updated_at = $2 WHERE id = $3`
+	result, err := q.ExecContext(ctx, query, kind, time.Now().UTC(), walletID)
+	if err != nil {
+		return fmt.Errorf("failed to update kind of wallet %d: %w", walletID, translateError(err))
+	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected after updating wallet balance for ID %d: %w", walletID, err)
+		return fmt.Errorf("failed to get rows affected after updating kind of wallet %d: %w", walletID, err)
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("no rows affected when updating wallet balance for ID %d, wallet might not exist", walletID)
+		return util.ErrNotFound
 	}
 	return nil
 }
+
+// walletListSource is the source of wallet list queries: the wallets with the balances of sharded ones summed.
+var walletListSource = `(SELECT ` + walletColumns("wallets") + `, wallets.deleted_at FROM wallets) wallets`
+
+// walletListQuery is the shared builder for wallet list endpoints; oldest first by default.
+var walletListQuery = repository.NewListQueryBuilder(
+	[]string{"id", "public_id", "user_id", "currency", "balance", "kind", "version", "created_at", "updated_at", "last_activity_at", "dormant_since", "frozen_at", "ownership_frozen_at"},
+	repository.SortField{Field: "id"},
+).AlwaysSelect("public_id")
+
+// ListWallets retrieves a page of wallets matching the filter using the provided DBExecutor.
+func (r *WalletRepository) ListWallets(ctx context.Context, q repository.DBExecutor, filter repository.WalletFilter, opts repository.ListOptions) ([]domain.Wallet, int64, error) {
+	wallets := []domain.Wallet{}
+
+	where := "deleted_at IS NULL"
+	var args []any
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		where += " AND user_id = $1"
+	}
+	if filter.Dormant {
+		where += " AND dormant_since IS NOT NULL"
+	}
+
+	query, countQuery, queryArgs, err := walletListQuery.Build(walletListSource, where, args, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := q.SelectContext(ctx, &wallets, query, queryArgs...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list wallets: %w", translateError(err))
+	}
+
+	var totalCount int64
+	if err := q.GetContext(ctx, &totalCount, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count wallets: %w", translateError(err))
+	}
+	return wallets, totalCount, nil
+}
+
+// SoftDeleteWallet marks a wallet deleted at the given time.
+func (r *WalletRepository) SoftDeleteWallet(ctx context.Context, q repository.DBExecutor, walletID int64, at time.Time) error {
+	query := `UPDATE wallets SET deleted_at = $1, updated_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	result, err := q.ExecContext(ctx, query, at, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to delete wallet %d: %w", walletID, translateError(err))
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected after deleting wallet %d: %w", walletID, err)
+	}
+	if rowsAffected == 0 {
+		return util.ErrNotFound
+	}
+	return nil
+}
+
+// SoftDeleteWalletsByUser marks every live wallet of a user deleted at the given time.
+func (r *WalletRepository) SoftDeleteWalletsByUser(ctx context.Context, q repository.DBExecutor, userID int64, at time.Time) error {
+	query := `UPDATE wallets SET deleted_at = $1, updated_at = $1 WHERE user_id = $2 AND deleted_at IS NULL`
+	if _, err := q.ExecContext(ctx, query, at, userID); err != nil {
+		return fmt.Errorf("failed to delete wallets of user %d: %w", userID, translateError(err))
+	}
+	return nil
+}
+
+// RestoreWallet clears the deletion mark of a soft-deleted wallet and returns the restored wallet.
+func (r *WalletRepository) RestoreWallet(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	query := `UPDATE wallets SET deleted_at = NULL, updated_at = $1
+              WHERE public_id = $2 AND deleted_at IS NOT NULL
+              RETURNING ` + walletColumns("wallets")
+	if err := q.GetContext(ctx, &wallet, query, time.Now().UTC(), publicID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to restore wallet %s: %w", publicID, translateError(err))
+	}
+	return &wallet, nil
+}
+
+// RestoreWalletsByUser clears the deletion mark of the wallets of a user that were deleted at the given time,
+// i.e. together with the user, leaving wallets deleted on their own before that deleted.
+func (r *WalletRepository) RestoreWalletsByUser(ctx context.Context, q repository.DBExecutor, userID int64, deletedAt time.Time) error {
+	query := `UPDATE wallets SET deleted_at = NULL, updated_at = $1 WHERE user_id = $2 AND deleted_at = $3`
+	if _, err := q.ExecContext(ctx, query, time.Now().UTC(), userID, deletedAt); err != nil {
+		return fmt.Errorf("failed to restore wallets of user %d: %w", userID, translateError(err))
+	}
+	return nil
+}
+
+// ListDeletedWalletIDs returns the IDs of wallets soft-deleted before the given time.
+func (r *WalletRepository) ListDeletedWalletIDs(ctx context.Context, q repository.DBExecutor, before time.Time) ([]int64, error) {
+	var ids []int64
+	query := `SELECT id FROM wallets WHERE deleted_at < $1 ORDER BY id`
+	if err := q.SelectContext(ctx, &ids, query, before); err != nil {
+		return nil, fmt.Errorf("failed to list deleted wallets: %w", translateError(err))
+	}
+	return ids, nil
+}
+
+// PurgeWallet permanently deletes a soft-deleted wallet. It fails with util.ErrReferenceViolation while
+// financial records such as transactions still reference the wallet.
+func (r *WalletRepository) PurgeWallet(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	query := `DELETE FROM wallets WHERE id = $1 AND deleted_at IS NOT NULL`
+	if _, err := q.ExecContext(ctx, query, walletID); err != nil {
+		return fmt.Errorf("failed to purge wallet %d: %w", walletID, translateError(err))
+	}
+	return nil
+}
+
+// FlagDormantWallets flags the least recently active wallets first. Suspense wallets belong to the platform
+// and are never flagged.
+func (r *WalletRepository) FlagDormantWallets(ctx context.Context, q repository.DBExecutor, inactiveSince, at time.Time, freeze bool, limit int) (int64, error) {
+	query := `UPDATE wallets SET dormant_since = $1, frozen_at = CASE WHEN $2 THEN $1::timestamptz END, updated_at = $1
+              WHERE id IN (SELECT id FROM wallets
+                           WHERE dormant_since IS NULL AND deleted_at IS NULL AND kind <> 'SUSPENSE' AND last_activity_at < $3
+                           ORDER BY last_activity_at LIMIT $4
+                           FOR UPDATE SKIP LOCKED)`
+	result, err := q.ExecContext(ctx, query, at, freeze, inactiveSince, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to flag dormant wallets: %w", translateError(err))
+	}
+	flagged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected after flagging dormant wallets: %w", err)
+	}
+	return flagged, nil
+}
+
+// ReactivateWallet clears the dormancy flag and freeze of a wallet and sets its last activity to the given
+// time, so the dormancy job does not flag it again right away.
+func (r *WalletRepository) ReactivateWallet(ctx context.Context, q repository.DBExecutor, walletID int64, at time.Time) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	query := `UPDATE wallets SET dormant_since = NULL, frozen_at = NULL, last_activity_at = $1, updated_at = $1
+              WHERE id = $2 AND deleted_at IS NULL
+              RETURNING ` + walletColumns("wallets")
+	if err := q.GetContext(ctx, &wallet, query, at, walletID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to reactivate wallet %d: %w", walletID, translateError(err))
+	}
+	return &wallet, nil
+}
+
+// SetOwnershipFrozen sets or clears the ownership transfer freeze of a wallet. The dormancy freeze is left
+// as it is.
+func (r *WalletRepository) SetOwnershipFrozen(ctx context.Context, q repository.DBExecutor, walletID int64, frozen bool, at time.Time) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	query := `UPDATE wallets SET ownership_frozen_at = CASE WHEN $1 THEN $2::timestamptz END, updated_at = $2
+              WHERE id = $3 AND deleted_at IS NULL
+              RETURNING ` + walletColumns("wallets")
+	if err := q.GetContext(ctx, &wallet, query, frozen, at, walletID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to set ownership freeze of wallet %d: %w", walletID, translateError(err))
+	}
+	return &wallet, nil
+}
+
+// ReassignWallet changes the owner of a wallet. A user holds one wallet per currency, so reassigning a
+// wallet to a user with one in its currency fails with util.ErrDuplicateEntry.
+func (r *WalletRepository) ReassignWallet(ctx context.Context, q repository.DBExecutor, walletID, userID int64, at time.Time) (*domain.Wallet, error) {
+	var wallet domain.Wallet
+	query := `UPDATE wallets SET user_id = $1, ownership_frozen_at = NULL, updated_at = $2
+              WHERE id = $3 AND deleted_at IS NULL
+              RETURNING ` + walletColumns("wallets")
+	if err := q.GetContext(ctx, &wallet, query, userID, at, walletID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, util.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to reassign wallet %d: %w", walletID, translateError(err))
+	}
+	return &wallet, nil
+}
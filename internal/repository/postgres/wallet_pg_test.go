@@ -0,0 +1,176 @@
+// internal/repository/postgres/wallet_pg_test.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+func TestWalletRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := &WalletRepository{}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	publicID := uuid.New()
+
+	t.Run("CreateWalletBindsColumns", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		wallet := domain.NewWallet(7, "USD")
+		mock.ExpectQuery(`INSERT INTO wallets (public_id, user_id, currency, balance, kind, version, created_at, updated_at, last_activity_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`).
+			WithArgs(wallet.PublicID, int64(7), "USD", decimal.Zero, domain.WalletKindPersonal, wallet.Version, wallet.CreatedAt, wallet.UpdatedAt, wallet.CreatedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+
+		require.NoError(t, repo.CreateWallet(ctx, database, wallet))
+		assert.Equal(t, int64(42), wallet.ID)
+		assert.Equal(t, domain.WalletKindPersonal, wallet.Kind)
+	})
+
+	t.Run("CreateWalletTranslatesUniqueViolation", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(`INSERT INTO wallets (public_id, user_id, currency, balance, kind, version, created_at, updated_at, last_activity_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`).
+			WillReturnError(&pq.Error{Code: pgUniqueViolation})
+
+		err := repo.CreateWallet(ctx, database, domain.NewWallet(7, "USD"))
+		assert.ErrorIs(t, err, util.ErrDuplicateEntry)
+	})
+
+	t.Run("GetWalletByIDScansRow", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(getWalletByIDQuery).WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows(walletRowColumns).AddRow(3, publicID.String(), 7, "USD", "12.5000", "PERSONAL", 4, now, now, now, nil, nil, nil))
+
+		wallet, err := repo.GetWalletByID(ctx, database, 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, publicID, wallet.PublicID)
+		assert.Equal(t, "12.5", wallet.Balance.String())
+		assert.Equal(t, int64(4), wallet.Version)
+		assert.Nil(t, wallet.FrozenAt)
+	})
+
+	t.Run("GetWalletByIDNotFound", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(getWalletByIDQuery).WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows(walletRowColumns))
+
+		_, err := repo.GetWalletByID(ctx, database, 3)
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+
+	t.Run("GetWalletsByIDsBindsArray", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(getWalletsByIDsQuery).WithArgs("{1,2}").
+			WillReturnRows(sqlmock.NewRows(walletRowColumns).
+				AddRow(1, uuid.NewString(), 7, "USD", "1", "PERSONAL", 1, now, now, now, nil, nil, nil).
+				AddRow(2, uuid.NewString(), 8, "USD", "2", "PERSONAL", 1, now, now, now, nil, nil, nil))
+
+		wallets, err := repo.GetWalletsByIDs(ctx, database, []int64{1, 2})
+
+		require.NoError(t, err)
+		assert.Len(t, wallets, 2)
+	})
+
+	t.Run("GetWalletByPublicIDExcludesDeleted", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(`SELECT ` + walletColumns("wallets") + ` FROM wallets WHERE public_id = $1 AND deleted_at IS NULL`).
+			WithArgs(publicID).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetWalletByPublicID(ctx, database, publicID)
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+
+	t.Run("UpdateWalletBalanceReturnsNewBalance", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(updateWalletBalanceQuery).WithArgs(decimal.NewFromInt(-5), now, int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "version", "updated_at"}).AddRow(3, "7.5000", 5, now))
+
+		balance, err := repo.UpdateWalletBalance(ctx, database, 3, decimal.NewFromInt(-5), now)
+
+		require.NoError(t, err)
+		assert.Equal(t, "7.5", balance.Balance.String())
+		assert.Equal(t, int64(5), balance.Version)
+	})
+
+	t.Run("UpdateWalletBalanceTranslatesCheckViolation", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(updateWalletBalanceQuery).WithArgs(decimal.NewFromInt(-500), sqlmock.AnyArg(), int64(3)).
+			WillReturnError(&pq.Error{Code: pgCheckViolation})
+
+		_, err := repo.UpdateWalletBalance(ctx, database, 3, decimal.NewFromInt(-500), now)
+		assert.ErrorIs(t, err, util.ErrConstraintViolation)
+	})
+
+	t.Run("UpdateWalletBalanceMissingWallet", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(updateWalletBalanceQuery).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UpdateWalletBalance(ctx, database, 3, decimal.NewFromInt(1), now)
+		assert.ErrorContains(t, err, "wallet might not exist")
+	})
+
+	t.Run("UpdateWalletBalancesFailsOnMissingWallet", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(updateWalletBalancesQuery).WithArgs("{3}", `{"10"}`, now).
+			WillReturnRows(sqlmock.NewRows(walletRowColumns))
+
+		_, err := repo.UpdateWalletBalances(ctx, database, map[int64]decimal.Decimal{3: decimal.NewFromInt(10)}, now)
+		assert.ErrorContains(t, err, "updated 0 of 1 wallet balances")
+	})
+
+	t.Run("UpdateWalletBalancesTranslatesDeadlock", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(updateWalletBalancesQuery).WillReturnError(&pq.Error{Code: pgDeadlockDetected})
+
+		_, err := repo.UpdateWalletBalances(ctx, database, map[int64]decimal.Decimal{3: decimal.NewFromInt(10)}, now)
+		assert.ErrorIs(t, err, util.ErrConcurrentUpdate)
+	})
+
+	t.Run("UpdateWalletKindNotFound", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectExec(`UPDATE wallets SET kind = $1, updated_at = $2 WHERE id = $3`).
+			WithArgs(domain.WalletKindMerchant, sqlmock.AnyArg(), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdateWalletKind(ctx, database, 3, domain.WalletKindMerchant)
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+
+	t.Run("ReassignWalletToUserWithCurrencyIsDuplicate", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(`UPDATE wallets SET user_id = $1, ownership_frozen_at = NULL, updated_at = $2
+              WHERE id = $3 AND deleted_at IS NULL
+              RETURNING `+walletColumns("wallets")).
+			WithArgs(int64(8), now, int64(3)).
+			WillReturnError(&pq.Error{Code: pgUniqueViolation})
+
+		_, err := repo.ReassignWallet(ctx, database, 3, 8, now)
+		assert.ErrorIs(t, err, util.ErrDuplicateEntry)
+	})
+
+	t.Run("SetOwnershipFrozenScansFreeze", func(t *testing.T) {
+		database, mock := newMockDB(t)
+		mock.ExpectQuery(`UPDATE wallets SET ownership_frozen_at = CASE WHEN $1 THEN $2::timestamptz END, updated_at = $2
+              WHERE id = $3 AND deleted_at IS NULL
+              RETURNING `+walletColumns("wallets")).
+			WithArgs(true, now, int64(3)).
+			WillReturnRows(sqlmock.NewRows(walletRowColumns).AddRow(3, publicID.String(), 7, "USD", "1", "PERSONAL", 1, now, now, now, nil, nil, now))
+
+		wallet, err := repo.SetOwnershipFrozen(ctx, database, 3, true, now)
+
+		require.NoError(t, err)
+		require.NotNil(t, wallet.OwnershipFrozenAt)
+		assert.Nil(t, wallet.FrozenAt)
+	})
+}
@@ -0,0 +1,26 @@
+// internal/repository/promo_credit_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+)
+
+// PromoCreditRepository defines the interface for promotional credit data operations.
+type PromoCreditRepository interface {
+	// CreateCredit stores a new grant.
+	CreateCredit(ctx context.Context, q DBExecutor, credit *domain.PromoCredit) error
+	// ListCreditsByWallet retrieves all grants of a wallet, newest first.
+	ListCreditsByWallet(ctx context.Context, q DBExecutor, walletID int64) ([]domain.PromoCredit, error)
+	// ListSpendableCreditsForUpdate retrieves and row-locks a wallet's grants that can be spent at now, soonest
+	// expiring first. With forWithdrawal set, only grants that allow withdrawals are returned.
+	ListSpendableCreditsForUpdate(ctx context.Context, q DBExecutor, walletID int64, now time.Time, forWithdrawal bool) ([]domain.PromoCredit, error)
+	// ConsumeCredit takes amount off a grant's remaining credit.
+	ConsumeCredit(ctx context.Context, q DBExecutor, creditID int64, amount decimal.Decimal) error
+	// ExpireCredits marks every ACTIVE grant past its expiry EXPIRED and returns the grants it expired.
+	ExpireCredits(ctx context.Context, q DBExecutor, now time.Time) ([]domain.PromoCredit, error)
+}
@@ -0,0 +1,113 @@
+// internal/repository/query.go
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"finflow-wallet/internal/util"
+)
+
+// SortField describes one ORDER BY term of a list query.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListOptions holds the shared pagination, sorting and projection options of list endpoints.
+type ListOptions struct {
+	Limit  int
+	Offset int
+	Sort   []SortField // Applied in order; empty means the builder's default sort
+	Fields []string    // Projection; empty means all columns
+}
+
+// ListQueryBuilder builds paginated SELECT statements for list endpoints.
+// Only whitelisted columns can be projected or sorted on, so user input never reaches the SQL text.
+type ListQueryBuilder struct {
+	columns     []string
+	defaultSort []SortField
+	required    []string
+}
+
+// NewListQueryBuilder creates a builder for the given whitelist of columns.
+// The first column is used as the final tie-breaker so pagination is stable.
+func NewListQueryBuilder(columns []string, defaultSort ...SortField) *ListQueryBuilder {
+	return &ListQueryBuilder{columns: columns, defaultSort: defaultSort}
+}
+
+// AlwaysSelect marks columns that are selected even when a projection omits them,
+// e.g. identifiers the handler needs to render links. It returns the builder for chaining.
+func (b *ListQueryBuilder) AlwaysSelect(columns ...string) *ListQueryBuilder {
+	b.required = append(b.required, columns...)
+	return b
+}
+
+// Build returns the page query and the matching count query for the given source and filter.
+// source is a table name or parenthesised subquery with alias; where may reference args as $1..$n
+// and may be empty. The returned args include the LIMIT and OFFSET values.
+func (b *ListQueryBuilder) Build(source, where string, args []any, opts ListOptions) (string, string, []any, error) {
+	selectList, err := b.selectList(opts.Fields)
+	if err != nil {
+		return "", "", nil, err
+	}
+	orderBy, err := b.orderBy(opts.Sort)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	whereClause := ""
+	if where != "" {
+		whereClause = " WHERE " + where
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT $%d OFFSET $%d",
+		selectList, source, whereClause, orderBy, len(args)+1, len(args)+2)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", source, whereClause)
+
+	queryArgs := append(slices.Clone(args), opts.Limit, opts.Offset)
+	return query, countQuery, queryArgs, nil
+}
+
+func (b *ListQueryBuilder) selectList(fields []string) (string, error) {
+	if len(fields) == 0 {
+		return strings.Join(b.columns, ", "), nil
+	}
+	for _, field := range fields {
+		if !slices.Contains(b.columns, field) {
+			return "", fmt.Errorf("%w: unknown field %q", util.ErrInvalidInput, field)
+		}
+	}
+	selected := slices.Clone(fields)
+	for _, column := range b.required {
+		if !slices.Contains(selected, column) {
+			selected = append(selected, column)
+		}
+	}
+	return strings.Join(selected, ", "), nil
+}
+
+func (b *ListQueryBuilder) orderBy(sort []SortField) (string, error) {
+	if len(sort) == 0 {
+		sort = b.defaultSort
+	}
+
+	terms := make([]string, 0, len(sort)+1)
+	tieBreakerSorted := false
+	for _, s := range sort {
+		if !slices.Contains(b.columns, s.Field) {
+			return "", fmt.Errorf("%w: unknown sort field %q", util.ErrInvalidInput, s.Field)
+		}
+		direction := "ASC"
+		if s.Desc {
+			direction = "DESC"
+		}
+		terms = append(terms, s.Field+" "+direction)
+		tieBreakerSorted = tieBreakerSorted || s.Field == b.columns[0]
+	}
+	if !tieBreakerSorted {
+		terms = append(terms, b.columns[0]+" DESC")
+	}
+	return strings.Join(terms, ", "), nil
+}
@@ -0,0 +1,23 @@
+// internal/repository/ramp_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// RampRepository defines the interface for ramp order data operations. Orders are read together with the
+// pricing of their transfer quote.
+type RampRepository interface {
+	// CreateRamp stores a new quoted ramp order for its stored quote.
+	CreateRamp(ctx context.Context, q DBExecutor, order *domain.RampOrder) error
+	// GetRampByPublicID retrieves a ramp order by its public ID.
+	GetRampByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.RampOrder, error)
+	// MarkRampExecuted links a quoted order to the CONVERSION legs it was executed as. It returns
+	// util.ErrNotFound if the order is not quoted anymore.
+	MarkRampExecuted(ctx context.Context, q DBExecutor, id int64, debitTransactionID, creditTransactionID uuid.UUID, executedAt time.Time) error
+}
@@ -0,0 +1,20 @@
+// internal/repository/report_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+)
+
+// ReportRepository defines the interface for the aggregate queries of treasury reports.
+type ReportRepository interface {
+	// SumBalances totals the balances of live wallets per currency.
+	SumBalances(ctx context.Context, q DBExecutor) ([]domain.CurrencyLiability, error)
+	// SumFloatMovement totals the money entering and leaving customer balances per currency, over the
+	// completed transactions created in [from, to), including archived ones.
+	SumFloatMovement(ctx context.Context, q DBExecutor, from, to time.Time) ([]domain.FloatMovement, error)
+	// ListLargestBalances retrieves the limit largest positive balances of live wallets in each currency.
+	ListLargestBalances(ctx context.Context, q DBExecutor, limit int) ([]domain.RankedBalance, error)
+}
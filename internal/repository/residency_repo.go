@@ -0,0 +1,21 @@
+// internal/repository/residency_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// CurrencyRestrictionRepository defines the interface for the residency restrictions of currencies.
+type CurrencyRestrictionRepository interface {
+	ListRestrictions(ctx context.Context, q DBExecutor) ([]domain.CurrencyRestriction, error)
+	GetRestriction(ctx context.Context, q DBExecutor, currency string) (*domain.CurrencyRestriction, error)
+	// SaveRestriction creates or replaces the restriction of a currency.
+	SaveRestriction(ctx context.Context, q DBExecutor, restriction *domain.CurrencyRestriction) error
+	DeleteRestriction(ctx context.Context, q DBExecutor, currency string) error
+	// IsRestricted reports whether residents of the country may not open or fund wallets in the currency.
+	IsRestricted(ctx context.Context, q DBExecutor, currency, country string) (bool, error)
+	// IsRestrictedForUser is IsRestricted for the user's residency; a user without one is never restricted.
+	IsRestrictedForUser(ctx context.Context, q DBExecutor, currency string, userID int64) (bool, error)
+}
@@ -0,0 +1,18 @@
+// internal/repository/retention_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+)
+
+// RetentionRepository defines the interface for purging records past their retention period.
+type RetentionRepository interface {
+	// PurgeRecords deletes at most limit records of the type created before cutoff and returns how many it
+	// deleted. Notification deliveries still pending are never purged.
+	PurgeRecords(ctx context.Context, q DBExecutor, recordType domain.RetentionRecordType, cutoff time.Time, limit int) (int64, error)
+	// CountPurgeable counts the records PurgeRecords would delete without a limit.
+	CountPurgeable(ctx context.Context, q DBExecutor, recordType domain.RetentionRecordType, cutoff time.Time) (int64, error)
+}
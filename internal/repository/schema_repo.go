@@ -0,0 +1,24 @@
+// internal/repository/schema_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// SchemaRepository defines the interface for the migration state of the database and the progress of backfills.
+type SchemaRepository interface {
+	// GetSchemaVersion retrieves the last migration applied by the migrate CLI, and whether it failed halfway.
+	GetSchemaVersion(ctx context.Context, q DBExecutor) (version uint64, dirty bool, err error)
+	// GetColumnScale retrieves the number of decimal places a NUMERIC column stores, or util.ErrNotFound if the
+	// table has no such column.
+	GetColumnScale(ctx context.Context, q DBExecutor, table, column string) (int32, error)
+	// ListBackfillProgress retrieves the progress of every backfill that ran a batch, by name.
+	ListBackfillProgress(ctx context.Context, q DBExecutor) ([]domain.BackfillProgress, error)
+	// RunBackfillBatch runs a backfill's query for the batch of at most limit rows after the key after, and
+	// returns the keys of the rows it covered.
+	RunBackfillBatch(ctx context.Context, q DBExecutor, query string, after int64, limit int) ([]int64, error)
+	// SaveBackfillProgress inserts or updates the progress of a backfill, leaving its start time once set.
+	SaveBackfillProgress(ctx context.Context, q DBExecutor, progress *domain.BackfillProgress) error
+}
@@ -0,0 +1,33 @@
+// internal/repository/scim_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// SCIMRepository defines the interface for the SCIM resources of provisioned users and their audit records.
+type SCIMRepository interface {
+	// CreateSCIMUser stores the resource of a user just provisioned. It returns util.ErrDuplicateEntry if the
+	// tenant's external ID is taken.
+	CreateSCIMUser(ctx context.Context, q DBExecutor, scimUser *domain.SCIMUser) error
+	// GetSCIMUser retrieves a tenant's resource by its SCIM id, locking it when q is a transaction and lock is set.
+	GetSCIMUser(ctx context.Context, q DBExecutor, tenant string, publicID uuid.UUID, lock bool) (*domain.SCIMUser, error)
+	// GetSCIMUserByUserID retrieves a tenant's resource of the user.
+	GetSCIMUserByUserID(ctx context.Context, q DBExecutor, tenant string, userID int64) (*domain.SCIMUser, error)
+	// GetSCIMUserByExternalID retrieves a tenant's resource by the identity system's ID.
+	GetSCIMUserByExternalID(ctx context.Context, q DBExecutor, tenant, externalID string) (*domain.SCIMUser, error)
+	// ListSCIMUsers retrieves a page of a tenant's resources, oldest first, and their total count.
+	ListSCIMUsers(ctx context.Context, q DBExecutor, tenant string, limit, offset int) ([]domain.SCIMUser, int64, error)
+	// UpdateSCIMUser sets the external ID of a resource. It returns util.ErrDuplicateEntry if the tenant's
+	// external ID is taken.
+	UpdateSCIMUser(ctx context.Context, q DBExecutor, id int64, externalID *string, at time.Time) error
+	// AppendProvisioningEvent records a provisioning change and fills in its ID.
+	AppendProvisioningEvent(ctx context.Context, q DBExecutor, event *domain.SCIMProvisioningEvent) error
+	// ListProvisioningEvents retrieves the provisioning changes of a user, oldest first.
+	ListProvisioningEvents(ctx context.Context, q DBExecutor, userID int64) ([]domain.SCIMProvisioningEvent, error)
+}
@@ -0,0 +1,31 @@
+// internal/repository/screening_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// ScreeningRepository defines the interface for the screening denylist and its review cases.
+type ScreeningRepository interface {
+	// CreateDenylistEntry adds an entry; a name normalizing like an existing entry's yields util.ErrDuplicateEntry.
+	CreateDenylistEntry(ctx context.Context, q DBExecutor, entry *domain.DenylistEntry) error
+	ListDenylistEntries(ctx context.Context, q DBExecutor) ([]domain.DenylistEntry, error)
+	// FindDenylistEntry retrieves the entry with the given normalized name, or util.ErrNotFound.
+	FindDenylistEntry(ctx context.Context, q DBExecutor, normalizedName string) (*domain.DenylistEntry, error)
+	// DeleteDenylistEntry removes an entry. One with review cases yields util.ErrReferenceViolation.
+	DeleteDenylistEntry(ctx context.Context, q DBExecutor, id int64) error
+
+	CreateScreeningCase(ctx context.Context, q DBExecutor, screeningCase *domain.ScreeningCase) error
+	// FindLatestScreeningCase retrieves the newest case of a party on an entry, or util.ErrNotFound.
+	FindLatestScreeningCase(ctx context.Context, q DBExecutor, normalizedName string, entryID int64) (*domain.ScreeningCase, error)
+	GetScreeningCaseByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.ScreeningCase, error)
+	// ListScreeningCases retrieves cases in one status or, if empty, all, newest first.
+	ListScreeningCases(ctx context.Context, q DBExecutor, status domain.ScreeningCaseStatus, limit int) ([]domain.ScreeningCase, error)
+	// ResolveScreeningCase closes an OPEN case; a case that is not open yields util.ErrNotFound.
+	ResolveScreeningCase(ctx context.Context, q DBExecutor, id int64, status domain.ScreeningCaseStatus, resolvedBy string, note *string, at time.Time) error
+}
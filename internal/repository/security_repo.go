@@ -0,0 +1,17 @@
+// internal/repository/security_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// AuthEventRepository defines the interface for the authentication events of users.
+type AuthEventRepository interface {
+	CreateAuthEvent(ctx context.Context, q DBExecutor, event *domain.AuthEvent) error
+	// ListAuthEvents retrieves up to limit events of a user, newest first.
+	ListAuthEvents(ctx context.Context, q DBExecutor, userID int64, limit int) ([]domain.AuthEvent, error)
+	// KnownDevice reports whether the user has logged in before at all, and whether from the device.
+	KnownDevice(ctx context.Context, q DBExecutor, userID int64, deviceHash string) (hasLogins, known bool, err error)
+}
@@ -0,0 +1,172 @@
+// internal/repository/shadow/shadow.go
+
+// Package shadow runs a candidate repository implementation next to the current one on read paths, to
+// de-risk persistence migrations such as a move to another driver, schema or database. The current
+// implementation keeps answering every call; a sample of its reads is repeated against the candidate in the
+// background, and the two results are compared. Divergences are counted per operation and logged, so the
+// candidate can be trusted, or fixed, before any write goes through it.
+package shadow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// maxResultLength bounds the results kept with the last divergence of an operation.
+const maxResultLength = 2048
+
+// Config holds the settings of shadow reads.
+type Config struct {
+	SamplePct   int           // Share of the eligible reads repeated against the candidate, 0 to 100
+	Timeout     time.Duration // Longest a candidate read may take
+	MaxInFlight int           // Candidate reads running at once; sampled reads beyond it are skipped
+}
+
+// Divergence is a read whose results differ between the current and the candidate implementation.
+type Divergence struct {
+	At        time.Time `json:"at"`
+	Primary   string    `json:"primary"`   // Result of the current implementation, as JSON, or "not found"
+	Candidate string    `json:"candidate"` // Result of the candidate
+}
+
+// OperationStats counts the shadow reads of one repository operation, e.g. "WalletRepository.GetWalletByID".
+type OperationStats struct {
+	Operation       string      `json:"operation"`
+	Compared        int64       `json:"compared"`
+	Diverged        int64       `json:"diverged"`
+	CandidateErrors int64       `json:"candidate_errors"` // Candidate reads that failed or timed out, so could not be compared
+	Skipped         int64       `json:"skipped"`          // Sampled reads not run because MaxInFlight were running
+	LastDivergence  *Divergence `json:"last_divergence,omitempty"`
+	LastError       *string     `json:"last_error,omitempty"`
+}
+
+// Harness schedules the candidate reads and records their comparisons. A nil *Harness shadows nothing.
+type Harness struct {
+	cfg      Config
+	logger   *slog.Logger
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+
+	mu    sync.Mutex
+	stats map[string]*OperationStats
+}
+
+// NewHarness creates a harness with the given settings.
+func NewHarness(cfg Config, logger *slog.Logger) *Harness {
+	return &Harness{
+		cfg:      cfg,
+		logger:   logger,
+		inFlight: make(chan struct{}, max(cfg.MaxInFlight, 1)),
+		stats:    make(map[string]*OperationStats),
+	}
+}
+
+// Stats returns the counts of every shadowed operation, most divergent first.
+func (h *Harness) Stats() []OperationStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stats := make([]OperationStats, 0, len(h.stats))
+	for _, s := range h.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Diverged != stats[j].Diverged {
+			return stats[i].Diverged > stats[j].Diverged
+		}
+		return stats[i].Operation < stats[j].Operation
+	})
+	return stats
+}
+
+// Wait blocks until the candidate reads in flight are done.
+func (h *Harness) Wait() {
+	h.wg.Wait()
+}
+
+// Read returns the result of primary. A sample of the calls made outside a transaction, and answered with a
+// result or util.ErrNotFound, is repeated with candidate in the background and the results are compared as
+// JSON, so decimals of different scales compare equal. Reads inside a transaction are never shadowed: they
+// may see uncommitted rows the candidate cannot.
+func Read[T any](ctx context.Context, h *Harness, q repository.DBExecutor, operation string, primary func() (T, error), candidate func(context.Context) (T, error)) (T, error) {
+	result, err := primary()
+	if h == nil || inTransaction(q) || (err != nil && !errors.Is(err, util.ErrNotFound)) || rand.IntN(100) >= h.cfg.SamplePct {
+		return result, err
+	}
+	select {
+	case h.inFlight <- struct{}{}:
+	default:
+		h.record(operation, func(s *OperationStats) { s.Skipped++ })
+		return result, err
+	}
+
+	expected := outcome(result, err)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer func() { <-h.inFlight }()
+		shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.cfg.Timeout)
+		defer cancel()
+		candidateResult, candidateErr := candidate(shadowCtx)
+		if candidateErr != nil && !errors.Is(candidateErr, util.ErrNotFound) {
+			message := candidateErr.Error()
+			h.record(operation, func(s *OperationStats) { s.CandidateErrors++; s.LastError = &message })
+			h.logger.Warn("Shadow read failed", "operation", operation, "error", candidateErr)
+			return
+		}
+		actual := outcome(candidateResult, candidateErr)
+		if actual == expected {
+			h.record(operation, func(s *OperationStats) { s.Compared++ })
+			return
+		}
+		divergence := &Divergence{At: time.Now().UTC(), Primary: truncate(expected), Candidate: truncate(actual)}
+		h.record(operation, func(s *OperationStats) { s.Compared++; s.Diverged++; s.LastDivergence = divergence })
+		// The results are left out of the log, as they hold personal data; they are kept for the admin API
+		h.logger.Warn("Shadow read diverged", "operation", operation)
+	}()
+	return result, err
+}
+
+func (h *Harness) record(operation string, update func(*OperationStats)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.stats[operation]
+	if !ok {
+		s = &OperationStats{Operation: operation}
+		h.stats[operation] = s
+	}
+	update(s)
+}
+
+// inTransaction reports whether q runs its queries in a transaction.
+func inTransaction(q repository.DBExecutor) bool {
+	_, ok := q.(interface{ Commit() error })
+	return ok
+}
+
+// outcome renders the result of a read for comparison.
+func outcome[T any](result T, err error) string {
+	if err != nil {
+		return "not found"
+	}
+	encoded, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		return "unencodable: " + marshalErr.Error()
+	}
+	return string(encoded)
+}
+
+func truncate(s string) string {
+	if len(s) <= maxResultLength {
+		return s
+	}
+	return s[:maxResultLength] + "..."
+}
@@ -0,0 +1,120 @@
+// internal/repository/shadow/shadow_test.go
+package shadow_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/repository/shadow"
+	"finflow-wallet/internal/util"
+)
+
+// txExecutor stands for a transaction: a DBExecutor that can commit.
+type txExecutor struct {
+	repository.DBExecutor
+}
+
+func (txExecutor) Commit() error { return nil }
+
+// TestRead tests which reads are shadowed and how their results are compared.
+func TestRead(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newHarness := func(maxInFlight int) *shadow.Harness {
+		return shadow.NewHarness(shadow.Config{SamplePct: 100, Timeout: time.Second, MaxInFlight: maxInFlight}, logger)
+	}
+	wallet := func(balance string) func() (*domain.Wallet, error) {
+		return func() (*domain.Wallet, error) {
+			return &domain.Wallet{ID: 7, Currency: "EUR", Balance: decimal.RequireFromString(balance)}, nil
+		}
+	}
+	candidate := func(read func() (*domain.Wallet, error)) func(context.Context) (*domain.Wallet, error) {
+		return func(context.Context) (*domain.Wallet, error) { return read() }
+	}
+
+	t.Run("EqualResultsAreCompared", func(t *testing.T) {
+		h := newHarness(1)
+
+		got, err := shadow.Read(ctx, h, nil, "GetWallet", wallet("10"), candidate(wallet("10.00")))
+		h.Wait()
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), got.ID)
+		require.Len(t, h.Stats(), 1)
+		assert.Equal(t, shadow.OperationStats{Operation: "GetWallet", Compared: 1}, h.Stats()[0], "decimal scale does not matter")
+	})
+
+	t.Run("DifferentResultsDiverge", func(t *testing.T) {
+		h := newHarness(1)
+		notFound := func() (*domain.Wallet, error) { return nil, util.ErrNotFound }
+
+		_, err := shadow.Read(ctx, h, nil, "GetWallet", wallet("10"), candidate(wallet("11")))
+		require.NoError(t, err)
+		h.Wait()
+		_, err = shadow.Read(ctx, h, nil, "GetWallet", notFound, candidate(wallet("11")))
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		h.Wait()
+
+		stats := h.Stats()[0]
+		assert.Equal(t, int64(2), stats.Compared)
+		assert.Equal(t, int64(2), stats.Diverged)
+		require.NotNil(t, stats.LastDivergence)
+		assert.Equal(t, "not found", stats.LastDivergence.Primary)
+		assert.Contains(t, stats.LastDivergence.Candidate, `"balance":"11"`)
+	})
+
+	t.Run("CandidateErrorsAreCountedApart", func(t *testing.T) {
+		h := newHarness(1)
+
+		_, err := shadow.Read(ctx, h, nil, "GetWallet", wallet("10"), func(context.Context) (*domain.Wallet, error) {
+			return nil, errors.New("relation \"wallets_v2\" does not exist")
+		})
+		require.NoError(t, err)
+		h.Wait()
+
+		stats := h.Stats()[0]
+		assert.Equal(t, int64(1), stats.CandidateErrors)
+		assert.Zero(t, stats.Compared)
+		require.NotNil(t, stats.LastError)
+	})
+
+	t.Run("TransactionsAndFailedReadsAreNotShadowed", func(t *testing.T) {
+		h := newHarness(1)
+		called := false
+		spy := func(context.Context) (*domain.Wallet, error) { called = true; return nil, nil }
+
+		_, _ = shadow.Read(ctx, h, txExecutor{}, "GetWallet", wallet("10"), spy)
+		_, err := shadow.Read(ctx, h, nil, "GetWallet", func() (*domain.Wallet, error) { return nil, errors.New("connection reset") }, spy)
+		assert.Error(t, err)
+		_, _ = shadow.Read(ctx, nil, nil, "GetWallet", wallet("10"), spy)
+		h.Wait()
+
+		assert.False(t, called)
+		assert.Empty(t, h.Stats())
+	})
+
+	t.Run("ReadsBeyondMaxInFlightAreSkipped", func(t *testing.T) {
+		h := newHarness(1)
+		release := make(chan struct{})
+		blocked := func(context.Context) (*domain.Wallet, error) { <-release; return wallet("10")() }
+
+		_, _ = shadow.Read(ctx, h, nil, "GetWallet", wallet("10"), blocked)
+		_, _ = shadow.Read(ctx, h, nil, "GetWallet", wallet("10"), blocked)
+		close(release)
+		h.Wait()
+
+		stats := h.Stats()[0]
+		assert.Equal(t, int64(1), stats.Compared)
+		assert.Equal(t, int64(1), stats.Skipped)
+	})
+}
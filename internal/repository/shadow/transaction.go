@@ -0,0 +1,84 @@
+// internal/repository/shadow/transaction.go
+package shadow
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// transactionRepository shadows the reads of a TransactionRepository. Writes only go to the current
+// implementation, and so does FindRecentTransfer, which is only called inside transactions.
+type transactionRepository struct {
+	repository.TransactionRepository
+	candidate  repository.TransactionRepository
+	candidateQ repository.DBExecutor
+	harness    *Harness
+}
+
+// NewTransactionRepository returns a TransactionRepository answering with primary and shadowing its reads
+// with candidate, which runs its queries through candidateQ.
+func NewTransactionRepository(primary, candidate repository.TransactionRepository, candidateQ repository.DBExecutor, harness *Harness) repository.TransactionRepository {
+	return &transactionRepository{TransactionRepository: primary, candidate: candidate, candidateQ: candidateQ, harness: harness}
+}
+
+func (r *transactionRepository) GetTransactionByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Transaction, error) {
+	return Read(ctx, r.harness, q, "TransactionRepository.GetTransactionByPublicID",
+		func() (*domain.Transaction, error) {
+			return r.TransactionRepository.GetTransactionByPublicID(ctx, q, publicID)
+		},
+		func(ctx context.Context) (*domain.Transaction, error) {
+			return r.candidate.GetTransactionByPublicID(ctx, r.candidateQ, publicID)
+		})
+}
+
+func (r *transactionRepository) GetTransactionsByWalletID(ctx context.Context, q repository.DBExecutor, walletID int64, limit, offset int) ([]domain.Transaction, int64, error) {
+	result, err := Read(ctx, r.harness, q, "TransactionRepository.GetTransactionsByWalletID",
+		func() (page[domain.Transaction], error) {
+			items, total, err := r.TransactionRepository.GetTransactionsByWalletID(ctx, q, walletID, limit, offset)
+			return page[domain.Transaction]{items, total}, err
+		},
+		func(ctx context.Context) (page[domain.Transaction], error) {
+			items, total, err := r.candidate.GetTransactionsByWalletID(ctx, r.candidateQ, walletID, limit, offset)
+			return page[domain.Transaction]{items, total}, err
+		})
+	return result.Items, result.Total, err
+}
+
+func (r *transactionRepository) ListTransactionsByWalletID(ctx context.Context, q repository.DBExecutor, walletID int64, filter repository.TransactionFilter, opts repository.ListOptions) ([]domain.Transaction, int64, error) {
+	result, err := Read(ctx, r.harness, q, "TransactionRepository.ListTransactionsByWalletID",
+		func() (page[domain.Transaction], error) {
+			items, total, err := r.TransactionRepository.ListTransactionsByWalletID(ctx, q, walletID, filter, opts)
+			return page[domain.Transaction]{items, total}, err
+		},
+		func(ctx context.Context) (page[domain.Transaction], error) {
+			items, total, err := r.candidate.ListTransactionsByWalletID(ctx, r.candidateQ, walletID, filter, opts)
+			return page[domain.Transaction]{items, total}, err
+		})
+	return result.Items, result.Total, err
+}
+
+func (r *transactionRepository) HasTransferredTo(ctx context.Context, q repository.DBExecutor, fromWalletID, toWalletID int64) (bool, error) {
+	return Read(ctx, r.harness, q, "TransactionRepository.HasTransferredTo",
+		func() (bool, error) {
+			return r.TransactionRepository.HasTransferredTo(ctx, q, fromWalletID, toWalletID)
+		},
+		func(ctx context.Context) (bool, error) {
+			return r.candidate.HasTransferredTo(ctx, r.candidateQ, fromWalletID, toWalletID)
+		})
+}
+
+func (r *transactionRepository) SumOutgoingAmount(ctx context.Context, q repository.DBExecutor, walletID int64, category *string, from, to time.Time) (decimal.Decimal, error) {
+	return Read(ctx, r.harness, q, "TransactionRepository.SumOutgoingAmount",
+		func() (decimal.Decimal, error) {
+			return r.TransactionRepository.SumOutgoingAmount(ctx, q, walletID, category, from, to)
+		},
+		func(ctx context.Context) (decimal.Decimal, error) {
+			return r.candidate.SumOutgoingAmount(ctx, r.candidateQ, walletID, category, from, to)
+		})
+}
@@ -0,0 +1,79 @@
+// internal/repository/shadow/wallet.go
+package shadow
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// page is the result of a list read: the items and the total count.
+type page[T any] struct {
+	Items []T   `json:"items"`
+	Total int64 `json:"total"`
+}
+
+// walletRepository shadows the reads of a WalletRepository. Writes, and reads locking rows, only go to the
+// current implementation.
+type walletRepository struct {
+	repository.WalletRepository
+	candidate  repository.WalletRepository
+	candidateQ repository.DBExecutor
+	harness    *Harness
+}
+
+// NewWalletRepository returns a WalletRepository answering with primary and shadowing its reads with
+// candidate, which runs its queries through candidateQ.
+func NewWalletRepository(primary, candidate repository.WalletRepository, candidateQ repository.DBExecutor, harness *Harness) repository.WalletRepository {
+	return &walletRepository{WalletRepository: primary, candidate: candidate, candidateQ: candidateQ, harness: harness}
+}
+
+func (r *walletRepository) GetWalletByID(ctx context.Context, q repository.DBExecutor, id int64) (*domain.Wallet, error) {
+	return Read(ctx, r.harness, q, "WalletRepository.GetWalletByID",
+		func() (*domain.Wallet, error) { return r.WalletRepository.GetWalletByID(ctx, q, id) },
+		func(ctx context.Context) (*domain.Wallet, error) {
+			return r.candidate.GetWalletByID(ctx, r.candidateQ, id)
+		})
+}
+
+func (r *walletRepository) GetWalletsByIDs(ctx context.Context, q repository.DBExecutor, ids []int64) ([]domain.Wallet, error) {
+	return Read(ctx, r.harness, q, "WalletRepository.GetWalletsByIDs",
+		func() ([]domain.Wallet, error) { return r.WalletRepository.GetWalletsByIDs(ctx, q, ids) },
+		func(ctx context.Context) ([]domain.Wallet, error) {
+			return r.candidate.GetWalletsByIDs(ctx, r.candidateQ, ids)
+		})
+}
+
+func (r *walletRepository) GetWalletByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Wallet, error) {
+	return Read(ctx, r.harness, q, "WalletRepository.GetWalletByPublicID",
+		func() (*domain.Wallet, error) { return r.WalletRepository.GetWalletByPublicID(ctx, q, publicID) },
+		func(ctx context.Context) (*domain.Wallet, error) {
+			return r.candidate.GetWalletByPublicID(ctx, r.candidateQ, publicID)
+		})
+}
+
+func (r *walletRepository) GetWalletByUserIDAndCurrency(ctx context.Context, q repository.DBExecutor, userID int64, currency string) (*domain.Wallet, error) {
+	return Read(ctx, r.harness, q, "WalletRepository.GetWalletByUserIDAndCurrency",
+		func() (*domain.Wallet, error) {
+			return r.WalletRepository.GetWalletByUserIDAndCurrency(ctx, q, userID, currency)
+		},
+		func(ctx context.Context) (*domain.Wallet, error) {
+			return r.candidate.GetWalletByUserIDAndCurrency(ctx, r.candidateQ, userID, currency)
+		})
+}
+
+func (r *walletRepository) ListWallets(ctx context.Context, q repository.DBExecutor, filter repository.WalletFilter, opts repository.ListOptions) ([]domain.Wallet, int64, error) {
+	result, err := Read(ctx, r.harness, q, "WalletRepository.ListWallets",
+		func() (page[domain.Wallet], error) {
+			items, total, err := r.WalletRepository.ListWallets(ctx, q, filter, opts)
+			return page[domain.Wallet]{items, total}, err
+		},
+		func(ctx context.Context) (page[domain.Wallet], error) {
+			items, total, err := r.candidate.ListWallets(ctx, r.candidateQ, filter, opts)
+			return page[domain.Wallet]{items, total}, err
+		})
+	return result.Items, result.Total, err
+}
@@ -0,0 +1,28 @@
+// internal/repository/suspense_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// SuspenseRepository defines the interface for inbound funds and the suspense wallets holding unmatched ones.
+type SuspenseRepository interface {
+	// GetSuspenseWallet retrieves the suspense wallet of a currency, or util.ErrNotFound if it has none yet.
+	GetSuspenseWallet(ctx context.Context, q DBExecutor, currency string) (*domain.Wallet, error)
+	// CreateInboundFunds records received funds. A provider reference recorded before fails with util.ErrDuplicateEntry.
+	CreateInboundFunds(ctx context.Context, q DBExecutor, funds *domain.InboundFunds) error
+	GetInboundFundsByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.InboundFunds, error)
+	// GetInboundFundsByReference retrieves the funds recorded for a provider's reference.
+	GetInboundFundsByReference(ctx context.Context, q DBExecutor, provider, reference string) (*domain.InboundFunds, error)
+	// GetInboundFundsForUpdate retrieves funds by public ID and row-locks them for the rest of the transaction.
+	GetInboundFundsForUpdate(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.InboundFunds, error)
+	// ListSuspendedFunds retrieves the funds held in suspense, oldest first, in one currency or, if empty, all.
+	ListSuspendedFunds(ctx context.Context, q DBExecutor, currency string) ([]domain.InboundFunds, error)
+	// MarkReassigned records that suspended funds were moved to a wallet by the adjustment transaction.
+	MarkReassigned(ctx context.Context, q DBExecutor, fundsID, walletID int64, adjustmentID uuid.UUID, operator, reason string, at time.Time) error
+}
@@ -0,0 +1,20 @@
+// internal/repository/sweep_rule_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// SweepRuleRepository defines the interface for sweep rule data operations.
+type SweepRuleRepository interface {
+	// CreateRule adds a new sweep rule using the provided DBExecutor.
+	CreateRule(ctx context.Context, q DBExecutor, rule *domain.SweepRule) error
+	// ListRulesByUserID retrieves all sweep rules of a user.
+	ListRulesByUserID(ctx context.Context, q DBExecutor, userID int64) ([]domain.SweepRule, error)
+	// ListEnabledRulesByWatchedWallet retrieves the enabled rules triggered by the balance of the given wallet.
+	ListEnabledRulesByWatchedWallet(ctx context.Context, q DBExecutor, walletID int64) ([]domain.SweepRule, error)
+	// DeleteRule removes a user's rule; it returns util.ErrNotFound if the user has no such rule.
+	DeleteRule(ctx context.Context, q DBExecutor, userID, ruleID int64) error
+}
@@ -0,0 +1,20 @@
+// internal/repository/transaction_archive_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+)
+
+// TransactionArchiveRepository defines the interface for transaction partition maintenance and archival.
+type TransactionArchiveRepository interface {
+	// EnsureMonthlyPartition creates the partition covering the month of the given time if it does not exist yet.
+	EnsureMonthlyPartition(ctx context.Context, q DBExecutor, month time.Time) error
+	// ListPartitions returns the monthly partitions of the transactions table, oldest first.
+	ListPartitions(ctx context.Context, q DBExecutor) ([]domain.TransactionPartition, error)
+	// ArchivePartition copies the partition's rows into transactions_archive, then detaches and drops it.
+	// It returns the number of rows archived.
+	ArchivePartition(ctx context.Context, q DBExecutor, partition domain.TransactionPartition) (int64, error)
+}
@@ -3,13 +3,40 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"finflow-wallet/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
-// TransactionRepository defines the interface for transaction data operations.
+// TransactionRepository defines the interface for transaction data operations. Transactions are append-only:
+// there is no method to change or delete one, and migration 000049 rejects such statements in the database too.
+// A correction is booked as a new transaction. The only change is the settlement of a PENDING transaction by
+// AsyncTransferRepository.
 type TransactionRepository interface {
 	CreateTransaction(ctx context.Context, q DBExecutor, tx *domain.Transaction) error
+	// GetTransactionByPublicID retrieves a single transaction by its public UUID using the provided DBExecutor.
+	GetTransactionByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.Transaction, error)
 	// Modified: GetTransactionsByWalletID now returns total count
 	GetTransactionsByWalletID(ctx context.Context, q DBExecutor, walletID int64, limit, offset int) ([]domain.Transaction, int64, error)
+	// ListTransactionsByWalletID returns a page of a wallet's transactions matching the filter, plus the total count.
+	ListTransactionsByWalletID(ctx context.Context, q DBExecutor, walletID int64, filter TransactionFilter, opts ListOptions) ([]domain.Transaction, int64, error)
+	// FindRecentTransfer returns the latest TRANSFER of amount in currency between the two wallets created at or after since.
+	FindRecentTransfer(ctx context.Context, q DBExecutor, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string, since time.Time) (*domain.Transaction, error)
+	// HasTransferredTo reports whether the source wallet ever sent a TRANSFER to the destination, archive included.
+	HasTransferredTo(ctx context.Context, q DBExecutor, fromWalletID, toWalletID int64) (bool, error)
+	// SumOutgoingAmount totals a wallet's withdrawals, transfers out and payments within [from, to), optionally only one category.
+	SumOutgoingAmount(ctx context.Context, q DBExecutor, walletID int64, category *string, from, to time.Time) (decimal.Decimal, error)
+}
+
+// TransactionFilter narrows a transaction list query.
+type TransactionFilter struct {
+	From           *time.Time              // Inclusive lower bound on created_at
+	To             *time.Time              // Exclusive upper bound on created_at
+	Type           *domain.TransactionType // Only transactions of this type
+	Category       *string                 // Only transactions in this spending category
+	Query          string                  // Free text matched against descriptions and categories, or a transaction or parent ID
+	IncludeArchive bool                    // Also search transactions_archive
 }
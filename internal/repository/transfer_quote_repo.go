@@ -0,0 +1,20 @@
+// internal/repository/transfer_quote_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// TransferQuoteRepository defines the interface for transfer quote data operations.
+type TransferQuoteRepository interface {
+	// CreateQuote stores a new quote.
+	CreateQuote(ctx context.Context, q DBExecutor, quote *domain.TransferQuote) error
+	// ClaimQuote atomically marks an unused, unexpired quote used at now and returns it.
+	// It returns util.ErrNotFound if no such quote exists, including when it was already used.
+	ClaimQuote(ctx context.Context, q DBExecutor, publicID uuid.UUID, now time.Time) (*domain.TransferQuote, error)
+}
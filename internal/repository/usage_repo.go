@@ -0,0 +1,21 @@
+// internal/repository/usage_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"finflow-wallet/internal/domain"
+
+	"github.com/shopspring/decimal"
+)
+
+// UsageRepository defines the interface for the monthly usage counters of partners.
+type UsageRepository interface {
+	// IncrementRequests counts a request of the partner in the month and returns the month's count so far.
+	IncrementRequests(ctx context.Context, q DBExecutor, partnerID string, month time.Time) (int64, error)
+	// AddVolume adds amount to the money the partner moved in the currency in the month.
+	AddVolume(ctx context.Context, q DBExecutor, partnerID string, month time.Time, currency string, amount decimal.Decimal) error
+	// GetUsage returns the partner's usage in the month, zero if it made no requests.
+	GetUsage(ctx context.Context, q DBExecutor, partnerID string, month time.Time) (*domain.PartnerUsage, error)
+}
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"finflow-wallet/internal/domain"
 )
@@ -11,8 +12,38 @@ import (
 type UserRepository interface {
 	// CreateUser adds a new user to the database using the provided DBExecutor.
 	CreateUser(ctx context.Context, q DBExecutor, user *domain.User) error
-	// GetUserByID retrieves a user by their ID using the provided DBExecutor.
+	// GetUserByID retrieves a user by their ID using the provided DBExecutor. Like every lookup and list,
+	// it does not find soft-deleted users.
 	GetUserByID(ctx context.Context, q DBExecutor, id int64) (*domain.User, error)
 	// GetUserByUsername retrieves a user by their username using the provided DBExecutor.
 	GetUserByUsername(ctx context.Context, q DBExecutor, username string) (*domain.User, error)
+	// ListUsers returns a page of users plus the total count using the provided DBExecutor.
+	ListUsers(ctx context.Context, q DBExecutor, opts ListOptions) ([]domain.User, int64, error)
+	// UpdateUserTimezone sets a user's IANA time zone and returns the updated user.
+	UpdateUserTimezone(ctx context.Context, q DBExecutor, id int64, timezone string) (*domain.User, error)
+	// UpdateUserResidency sets or, with nil, clears a user's country of residence and returns the updated user.
+	UpdateUserResidency(ctx context.Context, q DBExecutor, id int64, residency *string) (*domain.User, error)
+	// UpdateUserContact sets a user's email and phone, encrypted, and returns the updated user. A nil value
+	// clears the field.
+	UpdateUserContact(ctx context.Context, q DBExecutor, id int64, email, phone *string) (*domain.User, error)
+	// ReencryptPII re-encrypts the contact details of up to limit users still encrypted with a key other than
+	// the primary one and returns how many it re-encrypted. q should be a transaction.
+	ReencryptPII(ctx context.Context, q DBExecutor, limit int) (int, error)
+	// MarkContactVerified marks a user's email or phone verified, provided its blind index is still targetHash,
+	// and returns the updated user. ErrNotFound means the user or the value is gone.
+	MarkContactVerified(ctx context.Context, q DBExecutor, id int64, channel domain.ContactChannel, targetHash string, at time.Time) (*domain.User, error)
+	// UpdateUsername renames a user and returns the updated user. It returns util.ErrDuplicateEntry if the
+	// username is taken.
+	UpdateUsername(ctx context.Context, q DBExecutor, id int64, username string) (*domain.User, error)
+	// SetUserDeactivated deactivates a user at the given time or, with nil, reactivates them, and returns the
+	// updated user.
+	SetUserDeactivated(ctx context.Context, q DBExecutor, id int64, at *time.Time) (*domain.User, error)
+	// SoftDeleteUser marks a user deleted at the given time.
+	SoftDeleteUser(ctx context.Context, q DBExecutor, id int64, at time.Time) error
+	// RestoreUser clears the deletion mark of a soft-deleted user and returns when it had been deleted.
+	RestoreUser(ctx context.Context, q DBExecutor, id int64) (time.Time, error)
+	// ListDeletedUserIDs returns the IDs of users soft-deleted before the given time.
+	ListDeletedUserIDs(ctx context.Context, q DBExecutor, before time.Time) ([]int64, error)
+	// PurgeUser permanently deletes a soft-deleted user.
+	PurgeUser(ctx context.Context, q DBExecutor, id int64) error
 }
@@ -0,0 +1,24 @@
+// internal/repository/voucher_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// VoucherRepository defines the interface for voucher data operations.
+type VoucherRepository interface {
+	// CreateVoucher stores a newly issued voucher; it returns util.ErrDuplicateEntry if the code already exists.
+	CreateVoucher(ctx context.Context, q DBExecutor, voucher *domain.Voucher) error
+	// GetVoucherByCodeHash retrieves a voucher by the hash of its code.
+	GetVoucherByCodeHash(ctx context.Context, q DBExecutor, codeHash string) (*domain.Voucher, error)
+	// ClaimVoucher atomically marks an ACTIVE, unexpired voucher REDEEMED into the given wallet and returns it.
+	// It returns util.ErrNotFound if no such voucher exists, including when it was already claimed.
+	ClaimVoucher(ctx context.Context, q DBExecutor, codeHash string, walletID int64, transactionID uuid.UUID, now time.Time) (*domain.Voucher, error)
+	// SumLiability reports issued voucher value per currency, split into outstanding, expired and redeemed at now.
+	SumLiability(ctx context.Context, q DBExecutor, now time.Time) ([]domain.VoucherLiability, error)
+}
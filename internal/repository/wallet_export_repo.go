@@ -0,0 +1,32 @@
+// internal/repository/wallet_export_repo.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// WalletExportRepository defines the interface for wallet export job data operations.
+type WalletExportRepository interface {
+	// CreateExport queues a new export.
+	CreateExport(ctx context.Context, q DBExecutor, export *domain.WalletExport) error
+	GetExportByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.WalletExport, error)
+	// ClaimNextExport marks the oldest PENDING export RUNNING and returns it, or util.ErrNotFound if none is queued.
+	// Concurrent workers never claim the same export.
+	ClaimNextExport(ctx context.Context, q DBExecutor, now time.Time) (*domain.WalletExport, error)
+	// RequeueStaleExports returns exports RUNNING since before startedBefore, e.g. after a crash, to the queue.
+	RequeueStaleExports(ctx context.Context, q DBExecutor, startedBefore time.Time) (int64, error)
+	UpdateProgress(ctx context.Context, q DBExecutor, exportID int64, processed, total int64) error
+	// CompleteExport marks the export COMPLETED with the key of its file and the head of the wallet's audit chain
+	// it was written at, which is nil for an empty chain.
+	CompleteExport(ctx context.Context, q DBExecutor, exportID int64, objectKey string, auditHead *domain.AuditChainHead, now time.Time) error
+	FailExport(ctx context.Context, q DBExecutor, exportID int64, message string, now time.Time) error
+	// CountWalletTransactions counts a wallet's transactions, archived ones included.
+	CountWalletTransactions(ctx context.Context, q DBExecutor, walletID int64) (int64, error)
+	// ListWalletTransactionsAfter retrieves the next page of a wallet's transactions by ID, archived ones included.
+	ListWalletTransactionsAfter(ctx context.Context, q DBExecutor, walletID, afterID int64, limit int) ([]domain.Transaction, error)
+}
@@ -0,0 +1,40 @@
+// internal/repository/wallet_member_repo.go
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// WalletMemberRepository defines the interface for joint wallet membership and approval policy data operations.
+type WalletMemberRepository interface {
+	// AddMember adds a member to a wallet; it returns util.ErrDuplicateEntry if the user already is one.
+	AddMember(ctx context.Context, q DBExecutor, member *domain.WalletMember) error
+	// ListMembers retrieves the stored members of a wallet.
+	ListMembers(ctx context.Context, q DBExecutor, walletID int64) ([]domain.WalletMember, error)
+	// RemoveMember removes a member; it returns util.ErrNotFound if the user is not a member.
+	RemoveMember(ctx context.Context, q DBExecutor, walletID, userID int64) error
+	// GetApprovalPolicy retrieves a wallet's approval policy; it returns util.ErrNotFound if the wallet has none.
+	GetApprovalPolicy(ctx context.Context, q DBExecutor, walletID int64) (*domain.ApprovalPolicy, error)
+	// SaveApprovalPolicy creates or replaces a wallet's approval policy.
+	SaveApprovalPolicy(ctx context.Context, q DBExecutor, policy *domain.ApprovalPolicy) error
+	// DeleteApprovalPolicy removes a wallet's approval policy; it returns util.ErrNotFound if the wallet has none.
+	DeleteApprovalPolicy(ctx context.Context, q DBExecutor, walletID int64) error
+}
+
+// TransferApprovalRepository defines the interface for transfers awaiting approval.
+type TransferApprovalRepository interface {
+	// CreateApproval stores a new transfer approval request.
+	CreateApproval(ctx context.Context, q DBExecutor, approval *domain.TransferApproval) error
+	// GetApprovalByPublicID retrieves an approval request, including the users who approved it.
+	GetApprovalByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.TransferApproval, error)
+	// GetApprovalByPublicIDForUpdate retrieves an approval request and row-locks it for the rest of the transaction.
+	GetApprovalByPublicIDForUpdate(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.TransferApproval, error)
+	// AddVote records a user's approval; it returns util.ErrDuplicateEntry if the user already approved.
+	AddVote(ctx context.Context, q DBExecutor, approvalID, userID int64) error
+	// UpdateApproval stores the status, transaction ID and failure reason of an approval request.
+	UpdateApproval(ctx context.Context, q DBExecutor, approval *domain.TransferApproval) error
+}
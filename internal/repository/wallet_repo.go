@@ -3,9 +3,11 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"finflow-wallet/internal/domain"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -13,10 +15,52 @@ import (
 type WalletRepository interface {
 	// CreateWallet adds a new wallet to the database using the provided DBExecutor.
 	CreateWallet(ctx context.Context, q DBExecutor, wallet *domain.Wallet) error
-	// GetWalletByID retrieves a wallet by its ID using the provided DBExecutor.
+	// GetWalletByID retrieves a wallet by its ID using the provided DBExecutor. Like every lookup and list,
+	// it does not find soft-deleted wallets, so they can neither be read nor take part in money movements.
 	GetWalletByID(ctx context.Context, q DBExecutor, id int64) (*domain.Wallet, error)
+	// GetWalletsByIDs retrieves several wallets in one query, in ascending ID order; missing wallets are left out.
+	GetWalletsByIDs(ctx context.Context, q DBExecutor, ids []int64) ([]domain.Wallet, error)
+	// GetWalletByPublicID retrieves a wallet by the public UUID exposed through the API.
+	GetWalletByPublicID(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.Wallet, error)
+	// GetWalletByIDForUpdate retrieves a wallet by its ID and row-locks it for the rest of the transaction.
+	GetWalletByIDForUpdate(ctx context.Context, q DBExecutor, id int64) (*domain.Wallet, error)
 	// GetWalletByUserIDAndCurrency retrieves a wallet by user ID and currency using the provided DBExecutor.
 	GetWalletByUserIDAndCurrency(ctx context.Context, q DBExecutor, userID int64, currency string) (*domain.Wallet, error)
-	// UpdateWalletBalance updates the balance of a specific wallet using the provided DBExecutor.
-	UpdateWalletBalance(ctx context.Context, q DBExecutor, walletID int64, amount decimal.Decimal) error
+	// UpdateWalletBalance adds amount to the balance of a specific wallet using the provided DBExecutor, dating
+	// the update at. The new balance is returned by the update itself, so callers need not re-read the wallet.
+	UpdateWalletBalance(ctx context.Context, q DBExecutor, walletID int64, amount decimal.Decimal, at time.Time) (*domain.WalletBalance, error)
+	// UpdateWalletBalances adds each amount to its wallet's balance in one statement dated at and returns the
+	// updated wallets in ascending ID order. It fails if any of the wallets does not exist.
+	UpdateWalletBalances(ctx context.Context, q DBExecutor, amounts map[int64]decimal.Decimal, at time.Time) ([]domain.Wallet, error)
+	// UpdateWalletKind changes the kind of a wallet, e.g. when it is registered as a merchant wallet.
+	UpdateWalletKind(ctx context.Context, q DBExecutor, walletID int64, kind domain.WalletKind) error
+	// ListWallets returns a page of wallets matching the filter plus the total count using the provided DBExecutor.
+	ListWallets(ctx context.Context, q DBExecutor, filter WalletFilter, opts ListOptions) ([]domain.Wallet, int64, error)
+	// SoftDeleteWallet marks a wallet deleted at the given time.
+	SoftDeleteWallet(ctx context.Context, q DBExecutor, walletID int64, at time.Time) error
+	// SoftDeleteWalletsByUser marks every live wallet of a user deleted at the given time.
+	SoftDeleteWalletsByUser(ctx context.Context, q DBExecutor, userID int64, at time.Time) error
+	// RestoreWallet clears the deletion mark of a soft-deleted wallet and returns the restored wallet.
+	RestoreWallet(ctx context.Context, q DBExecutor, publicID uuid.UUID) (*domain.Wallet, error)
+	// RestoreWalletsByUser clears the deletion mark of the wallets of a user that were deleted at the given time.
+	RestoreWalletsByUser(ctx context.Context, q DBExecutor, userID int64, deletedAt time.Time) error
+	// ListDeletedWalletIDs returns the IDs of wallets soft-deleted before the given time.
+	ListDeletedWalletIDs(ctx context.Context, q DBExecutor, before time.Time) ([]int64, error)
+	// PurgeWallet permanently deletes a soft-deleted wallet.
+	PurgeWallet(ctx context.Context, q DBExecutor, walletID int64) error
+	// FlagDormantWallets flags up to limit wallets without activity since inactiveSince as dormant at the given
+	// time, freezing them too if freeze is set, and returns how many it flagged.
+	FlagDormantWallets(ctx context.Context, q DBExecutor, inactiveSince, at time.Time, freeze bool, limit int) (int64, error)
+	// ReactivateWallet clears the dormancy flag and freeze of a wallet and records the reactivation as activity.
+	ReactivateWallet(ctx context.Context, q DBExecutor, walletID int64, at time.Time) (*domain.Wallet, error)
+	// SetOwnershipFrozen freezes a wallet for an ownership transfer as of the given time, or lifts that freeze.
+	SetOwnershipFrozen(ctx context.Context, q DBExecutor, walletID int64, frozen bool, at time.Time) (*domain.Wallet, error)
+	// ReassignWallet makes the user the owner of a wallet and lifts its ownership transfer freeze.
+	ReassignWallet(ctx context.Context, q DBExecutor, walletID, userID int64, at time.Time) (*domain.Wallet, error)
+}
+
+// WalletFilter narrows a wallet list query.
+type WalletFilter struct {
+	UserID  *int64
+	Dormant bool // Only wallets flagged dormant
 }
@@ -0,0 +1,18 @@
+// internal/repository/wallet_serialization_repo.go
+package repository
+
+import (
+	"context"
+
+	"finflow-wallet/internal/domain"
+)
+
+// WalletSerializationRepository defines the interface for the serialization modes of contended wallets.
+type WalletSerializationRepository interface {
+	ListSerializations(ctx context.Context, q DBExecutor) ([]domain.WalletSerialization, error)
+	// SaveSerialization creates or replaces the serialization mode of a wallet.
+	SaveSerialization(ctx context.Context, q DBExecutor, serialization *domain.WalletSerialization) error
+	DeleteSerialization(ctx context.Context, q DBExecutor, walletID int64) error
+	// LockWallet takes the advisory lock of a wallet for the rest of the transaction q, waiting for its holder.
+	LockWallet(ctx context.Context, q DBExecutor, walletID int64) error
+}
@@ -0,0 +1,264 @@
+// internal/service/adjustment_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// maxListedAdjustments caps the adjustments returned by one list request.
+const maxListedAdjustments = 100
+
+// maxAdjustmentNoteLength is the longest note accepted with a proposal or decision.
+const maxAdjustmentNoteLength = 500
+
+// AdjustmentService defines the interface for manual wallet adjustments under maker-checker control. One admin
+// proposes an adjustment and a different admin approves it; only then is it posted as an ADJUSTMENT transaction.
+type AdjustmentService interface {
+	// Propose records a pending credit or debit of the wallet. Nothing is booked until it is approved.
+	Propose(ctx context.Context, wallet *domain.Wallet, direction domain.AdjustmentDirection, amount decimal.Decimal, reasonCode domain.AdjustmentReasonCode, note *string, proposedBy string) (*domain.Adjustment, error)
+	// Approve posts a pending adjustment as an ADJUSTMENT transaction. The approver must not be its proposer.
+	Approve(ctx context.Context, publicID uuid.UUID, approvedBy string, note *string) (*domain.Adjustment, *domain.Transaction, error)
+	// Reject closes a pending adjustment without booking it. Its proposer may reject it to withdraw it.
+	Reject(ctx context.Context, publicID uuid.UUID, rejectedBy string, note *string) (*domain.Adjustment, error)
+	GetAdjustment(ctx context.Context, publicID uuid.UUID) (*domain.Adjustment, error)
+	// ListAdjustments retrieves the most recent adjustments in one status or, if empty, all.
+	ListAdjustments(ctx context.Context, status domain.AdjustmentStatus) ([]domain.Adjustment, error)
+}
+
+// adjustmentService implements AdjustmentService.
+type adjustmentService struct {
+	dbBeginner      db.DBTxBeginner
+	dbExecutor      repository.DBExecutor
+	adjustmentRepo  repository.AdjustmentRepository
+	walletRepo      repository.WalletRepository
+	balances        *BalanceWriter // Applies balance changes, on a shard for sharded wallets
+	transactionRepo repository.TransactionRepository
+	annotationRepo  repository.AnnotationRepository
+	events          *TransactionEvents // Optional; posted adjustments are published here
+	beginTx         db.BeginTxFunc
+	commitTx        db.CommitTxFunc
+	rollbackTx      db.RollbackTxFunc
+	logger          *slog.Logger
+}
+
+// NewAdjustmentService creates a new instance of AdjustmentService.
+func NewAdjustmentService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	adjustmentRepo repository.AdjustmentRepository,
+	walletRepo repository.WalletRepository,
+	balances *BalanceWriter,
+	transactionRepo repository.TransactionRepository,
+	annotationRepo repository.AnnotationRepository,
+	events *TransactionEvents,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) AdjustmentService {
+	return &adjustmentService{
+		dbBeginner:      dbBeginner,
+		dbExecutor:      dbExecutor,
+		adjustmentRepo:  adjustmentRepo,
+		walletRepo:      walletRepo,
+		balances:        balances,
+		transactionRepo: transactionRepo,
+		annotationRepo:  annotationRepo,
+		events:          events,
+		beginTx:         beginTx,
+		commitTx:        commitTx,
+		rollbackTx:      rollbackTx,
+		logger:          logger,
+	}
+}
+
+// Propose validates and stores the proposal. The amount must fit the wallet currency's minor unit.
+func (s *adjustmentService) Propose(ctx context.Context, wallet *domain.Wallet, direction domain.AdjustmentDirection, amount decimal.Decimal, reasonCode domain.AdjustmentReasonCode, note *string, proposedBy string) (*domain.Adjustment, error) {
+	if !direction.Valid() {
+		return nil, fmt.Errorf("%w: direction must be CREDIT or DEBIT", util.ErrInvalidInput)
+	}
+	if !reasonCode.Valid() {
+		return nil, fmt.Errorf("%w: unknown reason code %q", util.ErrInvalidInput, reasonCode)
+	}
+	if !amount.IsPositive() || !amount.Equal(amount.Truncate(domain.CurrencyPrecision(wallet.Currency))) {
+		return nil, fmt.Errorf("%w: amount must be positive and fit the currency's minor unit", util.ErrInvalidInput)
+	}
+	if note != nil && len(*note) > maxAdjustmentNoteLength {
+		return nil, fmt.Errorf("%w: note may have at most %d characters", util.ErrInvalidInput, maxAdjustmentNoteLength)
+	}
+	if wallet.Kind == domain.WalletKindSuspense {
+		return nil, fmt.Errorf("%w: suspense wallets are adjusted by reassigning their funds", util.ErrInvalidInput)
+	}
+	if proposedBy == "" {
+		return nil, util.ErrForbidden
+	}
+
+	adjustment := &domain.Adjustment{
+		PublicID:       uuid.New(),
+		WalletID:       wallet.ID,
+		WalletPublicID: wallet.PublicID,
+		Direction:      direction,
+		Amount:         amount,
+		Currency:       wallet.Currency,
+		ReasonCode:     reasonCode,
+		Note:           note,
+		Status:         domain.AdjustmentStatusPending,
+		ProposedBy:     proposedBy,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.adjustmentRepo.CreateAdjustment(ctx, s.dbExecutor, adjustment); err != nil {
+		return nil, fmt.Errorf("propose adjustment: %w", err)
+	}
+	s.logger.Info("Adjustment proposed", "adjustment_id", adjustment.PublicID, "wallet_id", wallet.PublicID,
+		"direction", direction, "amount", amount.String(), "reason_code", reasonCode, "proposed_by", proposedBy)
+	return adjustment, nil
+}
+
+// Approve locks the adjustment and then its wallet, so two approvals of the same adjustment cannot both post it.
+// A debit is posted only if the wallet still holds the amount.
+func (s *adjustmentService) Approve(ctx context.Context, publicID uuid.UUID, approvedBy string, note *string) (*domain.Adjustment, *domain.Transaction, error) {
+	if note != nil && len(*note) > maxAdjustmentNoteLength {
+		return nil, nil, fmt.Errorf("%w: note may have at most %d characters", util.ErrInvalidInput, maxAdjustmentNoteLength)
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("approve adjustment: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, nil, fmt.Errorf("approve adjustment: transaction controller does not implement DBExecutor")
+	}
+
+	adjustment, err := s.adjustmentRepo.GetAdjustmentForUpdate(ctx, txExecutor, publicID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("approve adjustment: %w", err)
+	}
+	if adjustment.Status != domain.AdjustmentStatusPending {
+		return nil, nil, util.ErrAdjustmentNotPending
+	}
+	if approvedBy == "" || approvedBy == adjustment.ProposedBy {
+		return nil, nil, fmt.Errorf("%w: an adjustment must be approved by an admin other than its proposer", util.ErrForbidden)
+	}
+	wallet, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, adjustment.WalletID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("approve adjustment: %w", err)
+	}
+
+	change := adjustment.Amount
+	var fromWalletID, toWalletID *int64
+	if adjustment.Direction == domain.AdjustmentDebit {
+		if wallet.Balance.LessThan(adjustment.Amount) {
+			return nil, nil, util.ErrInsufficientFunds
+		}
+		change = change.Neg()
+		fromWalletID = &wallet.ID
+	} else {
+		toWalletID = &wallet.ID
+	}
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, wallet.ID, change); err != nil {
+		return nil, nil, fmt.Errorf("approve adjustment: failed to update wallet balance: %w", err)
+	}
+	description := fmt.Sprintf("Manual adjustment (%s)", adjustment.ReasonCode)
+	transaction := domain.NewTransaction(fromWalletID, toWalletID, adjustment.Amount, adjustment.Currency, domain.TransactionTypeAdjustment, &description)
+	s.balances.Stamp(transaction)
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, nil, fmt.Errorf("approve adjustment: failed to create transaction: %w", err)
+	}
+	now := transaction.CreatedAt
+	if adjustment.Note != nil {
+		patch := domain.TransactionAnnotationPatch{Note: adjustment.Note, UpdatedBy: &approvedBy}
+		if _, err := s.annotationRepo.ApplyAnnotation(ctx, txExecutor, transaction.PublicID, patch, now); err != nil {
+			return nil, nil, fmt.Errorf("approve adjustment: failed to annotate transaction: %w", err)
+		}
+	}
+
+	adjustment.Status = domain.AdjustmentStatusPosted
+	adjustment.DecidedBy = &approvedBy
+	adjustment.DecisionNote = note
+	adjustment.TransactionID = &transaction.PublicID
+	adjustment.DecidedAt = &now
+	if err := s.adjustmentRepo.UpdateDecision(ctx, txExecutor, adjustment); err != nil {
+		return nil, nil, fmt.Errorf("approve adjustment: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, nil, fmt.Errorf("approve adjustment: failed to commit transaction: %w", err)
+	}
+	if s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
+
+	s.logger.Info("Adjustment posted", "adjustment_id", adjustment.PublicID, "wallet_id", adjustment.WalletPublicID,
+		"transaction_id", transaction.PublicID, "direction", adjustment.Direction, "amount", adjustment.Amount.String(),
+		"reason_code", adjustment.ReasonCode, "proposed_by", adjustment.ProposedBy, "approved_by", approvedBy)
+	return adjustment, transaction, nil
+}
+
+// Reject records the rejection of a pending adjustment.
+func (s *adjustmentService) Reject(ctx context.Context, publicID uuid.UUID, rejectedBy string, note *string) (*domain.Adjustment, error) {
+	if note != nil && len(*note) > maxAdjustmentNoteLength {
+		return nil, fmt.Errorf("%w: note may have at most %d characters", util.ErrInvalidInput, maxAdjustmentNoteLength)
+	}
+	if rejectedBy == "" {
+		return nil, util.ErrForbidden
+	}
+
+	adjustment, err := s.adjustmentRepo.GetAdjustmentByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("reject adjustment: %w", err)
+	}
+	if adjustment.Status != domain.AdjustmentStatusPending {
+		return nil, util.ErrAdjustmentNotPending
+	}
+
+	now := time.Now().UTC()
+	adjustment.Status = domain.AdjustmentStatusRejected
+	adjustment.DecidedBy = &rejectedBy
+	adjustment.DecisionNote = note
+	adjustment.DecidedAt = &now
+	if err := s.adjustmentRepo.UpdateDecision(ctx, s.dbExecutor, adjustment); err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrAdjustmentNotPending // Decided concurrently
+		}
+		return nil, fmt.Errorf("reject adjustment: %w", err)
+	}
+	s.logger.Info("Adjustment rejected", "adjustment_id", adjustment.PublicID, "proposed_by", adjustment.ProposedBy, "rejected_by", rejectedBy)
+	return adjustment, nil
+}
+
+// GetAdjustment retrieves an adjustment by its public ID.
+func (s *adjustmentService) GetAdjustment(ctx context.Context, publicID uuid.UUID) (*domain.Adjustment, error) {
+	adjustment, err := s.adjustmentRepo.GetAdjustmentByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get adjustment: %w", err)
+	}
+	return adjustment, nil
+}
+
+// ListAdjustments retrieves up to maxListedAdjustments adjustments, newest first.
+func (s *adjustmentService) ListAdjustments(ctx context.Context, status domain.AdjustmentStatus) ([]domain.Adjustment, error) {
+	switch status {
+	case "", domain.AdjustmentStatusPending, domain.AdjustmentStatusPosted, domain.AdjustmentStatusRejected:
+	default:
+		return nil, fmt.Errorf("%w: unknown adjustment status %q", util.ErrInvalidInput, status)
+	}
+	adjustments, err := s.adjustmentRepo.ListAdjustments(ctx, s.dbExecutor, status, maxListedAdjustments)
+	if err != nil {
+		return nil, fmt.Errorf("list adjustments: %w", err)
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,180 @@
+// internal/service/adjustment_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestAdjustmentService tests proposing manual adjustments and the maker-checker rules of approving them.
+func TestAdjustmentService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Kind: domain.WalletKindPersonal, Balance: decimal.NewFromInt(20)}
+	note := "Duplicate card capture on 2026-03-01"
+	pending := func(direction domain.AdjustmentDirection, amount int64) *domain.Adjustment {
+		return &domain.Adjustment{
+			ID:             5,
+			PublicID:       uuid.New(),
+			WalletID:       wallet.ID,
+			WalletPublicID: wallet.PublicID,
+			Direction:      direction,
+			Amount:         decimal.NewFromInt(amount),
+			Currency:       "USD",
+			ReasonCode:     domain.AdjustmentReasonErrorCorrection,
+			Note:           &note,
+			Status:         domain.AdjustmentStatusPending,
+			ProposedBy:     "alice",
+		}
+	}
+
+	type mocks struct {
+		adjustmentRepo  *MockAdjustmentRepository
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		annotationRepo  *MockAnnotationRepository
+		dbExecutor      *MockDBExecutor
+		txController    *MockTxController
+	}
+	newService := func() (AdjustmentService, mocks) {
+		m := mocks{
+			adjustmentRepo:  new(MockAdjustmentRepository),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			annotationRepo:  new(MockAnnotationRepository),
+			dbExecutor:      new(MockDBExecutor),
+			txController:    new(MockTxController),
+		}
+		service := NewAdjustmentService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.adjustmentRepo,
+			m.walletRepo,
+			NewBalanceWriter(m.walletRepo, nil, nil, nil),
+			m.transactionRepo,
+			m.annotationRepo,
+			nil,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+
+	t.Run("ProposeStoresPendingAdjustment", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.adjustmentRepo.On("CreateAdjustment", ctx, m.dbExecutor, mock.MatchedBy(func(adjustment *domain.Adjustment) bool {
+			return adjustment.Status == domain.AdjustmentStatusPending && adjustment.ProposedBy == "alice" && adjustment.Currency == "USD"
+		})).Return(nil).Once()
+
+		adjustment, err := service.Propose(ctx, wallet, domain.AdjustmentCredit, decimal.NewFromInt(15), domain.AdjustmentReasonGoodwill, nil, "alice")
+
+		assert.NoError(t, err)
+		assert.Equal(t, wallet.PublicID, adjustment.WalletPublicID)
+		m.adjustmentRepo.AssertExpectations(t)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ProposeRejectsUnknownReasonCode", func(t *testing.T) {
+		service, m := newService()
+
+		_, err := service.Propose(context.Background(), wallet, domain.AdjustmentCredit, decimal.NewFromInt(15), "BECAUSE", nil, "alice")
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.adjustmentRepo.AssertNotCalled(t, "CreateAdjustment", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ApproveDebitPostsAdjustmentTransaction", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		adjustment := pending(domain.AdjustmentDebit, 15)
+
+		m.adjustmentRepo.On("GetAdjustmentForUpdate", ctx, m.txController, adjustment.PublicID).Return(adjustment, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, wallet.ID, decimal.NewFromInt(-15), mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeAdjustment && *tx.FromWalletID == wallet.ID && tx.ToWalletID == nil &&
+				*tx.Description == "Manual adjustment (ERROR_CORRECTION)"
+		})).Return(nil).Once()
+		m.annotationRepo.On("ApplyAnnotation", ctx, m.txController, mock.Anything, mock.MatchedBy(func(patch domain.TransactionAnnotationPatch) bool {
+			return *patch.Note == note && *patch.UpdatedBy == "bob"
+		}), mock.Anything).Return(nil, nil).Once()
+		m.adjustmentRepo.On("UpdateDecision", ctx, m.txController, mock.MatchedBy(func(adjustment *domain.Adjustment) bool {
+			return adjustment.Status == domain.AdjustmentStatusPosted && *adjustment.DecidedBy == "bob" && adjustment.TransactionID != nil
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		posted, transaction, err := service.Approve(ctx, adjustment.PublicID, "bob", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, transaction.PublicID, *posted.TransactionID)
+		m.walletRepo.AssertExpectations(t)
+		m.transactionRepo.AssertExpectations(t)
+		m.adjustmentRepo.AssertExpectations(t)
+		m.txController.AssertExpectations(t)
+	})
+
+	t.Run("ProposerCannotApprove", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		adjustment := pending(domain.AdjustmentCredit, 15)
+
+		m.adjustmentRepo.On("GetAdjustmentForUpdate", ctx, m.txController, adjustment.PublicID).Return(adjustment, nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		_, _, err := service.Approve(ctx, adjustment.PublicID, "alice", nil)
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.adjustmentRepo.AssertNotCalled(t, "UpdateDecision", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DebitBeyondBalanceIsNotPosted", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		adjustment := pending(domain.AdjustmentDebit, 50)
+
+		m.adjustmentRepo.On("GetAdjustmentForUpdate", ctx, m.txController, adjustment.PublicID).Return(adjustment, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		_, _, err := service.Approve(ctx, adjustment.PublicID, "bob", nil)
+
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		m.transactionRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DecidedAdjustmentIsNotPending", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		adjustment := pending(domain.AdjustmentCredit, 15)
+		adjustment.Status = domain.AdjustmentStatusRejected
+
+		m.adjustmentRepo.On("GetAdjustmentByPublicID", ctx, m.dbExecutor, adjustment.PublicID).Return(adjustment, nil).Once()
+
+		_, err := service.Reject(ctx, adjustment.PublicID, "bob", nil)
+
+		assert.ErrorIs(t, err, util.ErrAdjustmentNotPending)
+		m.adjustmentRepo.AssertNotCalled(t, "UpdateDecision", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,110 @@
+// internal/service/annotation_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// AnnotationService defines the interface for support annotations on transactions.
+type AnnotationService interface {
+	// GetAnnotation returns the transaction's annotation, or nil if it has none.
+	GetAnnotation(ctx context.Context, transactionID uuid.UUID) (*domain.TransactionAnnotation, error)
+	// AnnotateTransaction validates the patch and applies it to the transaction's annotation.
+	AnnotateTransaction(ctx context.Context, transactionID uuid.UUID, patch domain.TransactionAnnotationPatch) (*domain.TransactionAnnotation, error)
+}
+
+// annotationService implements AnnotationService.
+type annotationService struct {
+	dbExecutor      repository.DBExecutor
+	annotationRepo  repository.AnnotationRepository
+	transactionRepo repository.TransactionRepository
+	logger          *slog.Logger
+}
+
+// NewAnnotationService creates a new instance of AnnotationService.
+func NewAnnotationService(
+	dbExecutor repository.DBExecutor,
+	annotationRepo repository.AnnotationRepository,
+	transactionRepo repository.TransactionRepository,
+	logger *slog.Logger,
+) AnnotationService {
+	return &annotationService{
+		dbExecutor:      dbExecutor,
+		annotationRepo:  annotationRepo,
+		transactionRepo: transactionRepo,
+		logger:          logger,
+	}
+}
+
+func (s *annotationService) GetAnnotation(ctx context.Context, transactionID uuid.UUID) (*domain.TransactionAnnotation, error) {
+	annotation, err := s.annotationRepo.GetAnnotation(ctx, s.dbExecutor, transactionID)
+	if errors.Is(err, util.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get annotation: %w", err)
+	}
+	return annotation, nil
+}
+
+// AnnotateTransaction trims the case references and drops duplicates, keeping their order.
+func (s *annotationService) AnnotateTransaction(ctx context.Context, transactionID uuid.UUID, patch domain.TransactionAnnotationPatch) (*domain.TransactionAnnotation, error) {
+	if patch.Note == nil && patch.CaseIDs == nil {
+		return nil, fmt.Errorf("%w: note or case_ids is required", util.ErrInvalidInput)
+	}
+	if patch.Note != nil {
+		note := strings.TrimSpace(*patch.Note)
+		if utf8.RuneCountInString(note) > domain.MaxAnnotationNoteLength {
+			return nil, fmt.Errorf("%w: note is longer than %d characters", util.ErrInvalidInput, domain.MaxAnnotationNoteLength)
+		}
+		patch.Note = &note
+	}
+	if patch.CaseIDs != nil {
+		caseIDs, err := normalizeCaseIDs(*patch.CaseIDs)
+		if err != nil {
+			return nil, err
+		}
+		patch.CaseIDs = &caseIDs
+	}
+
+	if _, err := s.transactionRepo.GetTransactionByPublicID(ctx, s.dbExecutor, transactionID); err != nil {
+		return nil, fmt.Errorf("annotate transaction: failed to get transaction %s: %w", transactionID, err)
+	}
+	annotation, err := s.annotationRepo.ApplyAnnotation(ctx, s.dbExecutor, transactionID, patch, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("annotate transaction: %w", err)
+	}
+	s.logger.Info("Transaction annotated", "transaction_id", transactionID, "case_ids", annotation.CaseIDs)
+	return annotation, nil
+}
+
+func normalizeCaseIDs(raw []string) ([]string, error) {
+	caseIDs := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, caseID := range raw {
+		caseID = strings.TrimSpace(caseID)
+		if caseID == "" || len(caseID) > domain.MaxCaseIDLength {
+			return nil, fmt.Errorf("%w: case IDs must be 1 to %d characters", util.ErrInvalidInput, domain.MaxCaseIDLength)
+		}
+		if !seen[caseID] {
+			seen[caseID] = true
+			caseIDs = append(caseIDs, caseID)
+		}
+	}
+	if len(caseIDs) > domain.MaxAnnotationCaseIDs {
+		return nil, fmt.Errorf("%w: at most %d case IDs", util.ErrInvalidInput, domain.MaxAnnotationCaseIDs)
+	}
+	return caseIDs, nil
+}
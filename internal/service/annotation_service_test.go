@@ -0,0 +1,80 @@
+// internal/service/annotation_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestAnnotationService tests validation and patching of transaction annotations.
+func TestAnnotationService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	transactionID := uuid.New()
+	newService := func() (AnnotationService, *MockAnnotationRepository, *MockTransactionRepository, *MockDBExecutor) {
+		annotationRepo := new(MockAnnotationRepository)
+		transactionRepo := new(MockTransactionRepository)
+		dbExecutor := new(MockDBExecutor)
+		return NewAnnotationService(dbExecutor, annotationRepo, transactionRepo, logger), annotationRepo, transactionRepo, dbExecutor
+	}
+
+	t.Run("CaseIDsAreTrimmedAndDeduplicated", func(t *testing.T) {
+		ctx := context.Background()
+		service, annotationRepo, transactionRepo, dbExecutor := newService()
+		caseIDs := []string{" CASE-1 ", "CASE-2", "CASE-1"}
+		note := "  Refund requested by phone  "
+
+		transactionRepo.On("GetTransactionByPublicID", ctx, dbExecutor, transactionID).Return(&domain.Transaction{PublicID: transactionID}, nil).Once()
+		annotationRepo.On("ApplyAnnotation", ctx, dbExecutor, transactionID, mock.MatchedBy(func(p domain.TransactionAnnotationPatch) bool {
+			return *p.Note == "Refund requested by phone" && assert.ObjectsAreEqual([]string{"CASE-1", "CASE-2"}, *p.CaseIDs)
+		}), mock.Anything).Return(&domain.TransactionAnnotation{TransactionID: transactionID, CaseIDs: []string{"CASE-1", "CASE-2"}}, nil).Once()
+
+		annotation, err := service.AnnotateTransaction(ctx, transactionID, domain.TransactionAnnotationPatch{Note: &note, CaseIDs: &caseIDs})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"CASE-1", "CASE-2"}, annotation.CaseIDs)
+		mock.AssertExpectationsForObjects(t, annotationRepo, transactionRepo)
+	})
+
+	t.Run("EmptyPatchIsRejected", func(t *testing.T) {
+		service, annotationRepo, _, _ := newService()
+
+		_, err := service.AnnotateTransaction(context.Background(), transactionID, domain.TransactionAnnotationPatch{})
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		annotationRepo.AssertNotCalled(t, "ApplyAnnotation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnknownTransactionIsNotFound", func(t *testing.T) {
+		ctx := context.Background()
+		service, annotationRepo, transactionRepo, dbExecutor := newService()
+		caseIDs := []string{"CASE-1"}
+
+		transactionRepo.On("GetTransactionByPublicID", ctx, dbExecutor, transactionID).Return(nil, util.ErrNotFound).Once()
+
+		_, err := service.AnnotateTransaction(ctx, transactionID, domain.TransactionAnnotationPatch{CaseIDs: &caseIDs})
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		annotationRepo.AssertNotCalled(t, "ApplyAnnotation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("MissingAnnotationIsNil", func(t *testing.T) {
+		ctx := context.Background()
+		service, annotationRepo, _, dbExecutor := newService()
+
+		annotationRepo.On("GetAnnotation", ctx, dbExecutor, transactionID).Return(nil, util.ErrNotFound).Once()
+
+		annotation, err := service.GetAnnotation(ctx, transactionID)
+
+		assert.NoError(t, err)
+		assert.Nil(t, annotation)
+	})
+}
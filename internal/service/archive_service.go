@@ -0,0 +1,117 @@
+// internal/service/archive_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/pkg/db"
+)
+
+// ArchiveService defines the interface for transaction partition maintenance and archival.
+type ArchiveService interface {
+	// EnsurePartitions creates the partitions for the current and next month ahead of time.
+	EnsurePartitions(ctx context.Context, now time.Time) error
+	// ArchiveExpiredPartitions moves every partition older than the retention horizon into the archive table.
+	// It returns the total number of rows archived.
+	ArchiveExpiredPartitions(ctx context.Context, now time.Time) (int64, error)
+}
+
+// archiveService implements the ArchiveService interface.
+type archiveService struct {
+	dbBeginner    db.DBTxBeginner
+	dbExecutor    repository.DBExecutor
+	archiveRepo   repository.TransactionArchiveRepository
+	horizonMonths int
+	logger        *slog.Logger
+	beginTx       db.BeginTxFunc
+	commitTx      db.CommitTxFunc
+	rollbackTx    db.RollbackTxFunc
+}
+
+// NewArchiveService creates a new instance of ArchiveService.
+// horizonMonths is the number of whole months kept in the hot table before archival.
+func NewArchiveService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	archiveRepo repository.TransactionArchiveRepository,
+	horizonMonths int,
+	logger *slog.Logger,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+) ArchiveService {
+	return &archiveService{
+		dbBeginner:    dbBeginner,
+		dbExecutor:    dbExecutor,
+		archiveRepo:   archiveRepo,
+		horizonMonths: horizonMonths,
+		logger:        logger,
+		beginTx:       beginTx,
+		commitTx:      commitTx,
+		rollbackTx:    rollbackTx,
+	}
+}
+
+// EnsurePartitions creates the partitions for the current and next month ahead of time,
+// so new rows never land in the default partition.
+func (s *archiveService) EnsurePartitions(ctx context.Context, now time.Time) error {
+	for _, month := range []time.Time{now, now.AddDate(0, 1, 0)} {
+		if err := s.archiveRepo.EnsureMonthlyPartition(ctx, s.dbExecutor, month); err != nil {
+			return fmt.Errorf("ensure partitions: %w", err)
+		}
+	}
+	return nil
+}
+
+// ArchiveExpiredPartitions archives each expired partition in its own database transaction,
+// so a failure part-way through leaves already archived months in place.
+func (s *archiveService) ArchiveExpiredPartitions(ctx context.Context, now time.Time) (int64, error) {
+	cutoff := domain.ArchiveCutoff(now, s.horizonMonths)
+
+	partitions, err := s.archiveRepo.ListPartitions(ctx, s.dbExecutor)
+	if err != nil {
+		return 0, fmt.Errorf("archive partitions: %w", err)
+	}
+
+	var total int64
+	for _, partition := range partitions {
+		if partition.RangeEnd.After(cutoff) {
+			continue
+		}
+		archived, err := s.archivePartition(ctx, partition)
+		if err != nil {
+			return total, err
+		}
+		s.logger.Info("Archived transaction partition", "partition", partition.Name, "rows", archived)
+		total += archived
+	}
+	return total, nil
+}
+
+func (s *archiveService) archivePartition(ctx context.Context, partition domain.TransactionPartition) (int64, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return 0, fmt.Errorf("archive partition %s: failed to begin transaction: %w", partition.Name, err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return 0, fmt.Errorf("archive partition %s: transaction controller does not implement DBExecutor", partition.Name)
+	}
+
+	archived, err := s.archiveRepo.ArchivePartition(ctx, txExecutor, partition)
+	if err != nil {
+		return 0, fmt.Errorf("archive partition %s: %w", partition.Name, err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return 0, fmt.Errorf("archive partition %s: failed to commit transaction: %w", partition.Name, err)
+	}
+	return archived, nil
+}
@@ -0,0 +1,269 @@
+// internal/service/async_transfer.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+type asyncTransferKey struct{}
+
+// withAsyncTransfer marks ctx as making the accepted transfer, whose PENDING transaction the transfer completes
+// instead of creating its own. It is unexported so only the worker can grant it.
+func withAsyncTransfer(ctx context.Context, transfer *domain.AsyncTransfer) context.Context {
+	return context.WithValue(ctx, asyncTransferKey{}, transfer)
+}
+
+// asyncTransferOf returns the accepted transfer ctx makes, or nil.
+func asyncTransferOf(ctx context.Context) *domain.AsyncTransfer {
+	transfer, _ := ctx.Value(asyncTransferKey{}).(*domain.AsyncTransfer)
+	return transfer
+}
+
+// asyncTransferBackoff delays the next attempt of an accepted transfer by initial, doubled with each failed
+// attempt up to max.
+type asyncTransferBackoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+// delay returns the delay after the given number of failed attempts, the first one included.
+func (b asyncTransferBackoff) delay(attempts int) time.Duration {
+	delay := b.initial
+	for i := 1; i < attempts && delay < b.max; i++ {
+		delay *= 2
+	}
+	return min(delay, b.max)
+}
+
+// asyncTransferFailures are the errors for which an accepted transfer fails for good, with the stable code
+// recorded as its failure reason. They stem from the transfer itself, so retrying would not help. Any other
+// error, e.g. a dropped database connection or an unreachable policy, leaves the transfer PENDING.
+var asyncTransferFailures = []struct {
+	err  error
+	code string
+}{
+	{util.ErrInsufficientFunds, "insufficient_funds"},
+	{util.ErrWalletNotFound, "wallet_not_found"},
+	{util.ErrNotFound, "wallet_not_found"}, // A wallet deleted since the transfer was accepted
+	{util.ErrWalletFrozen, "wallet_frozen"},
+	{util.ErrOwnershipTransferFrozen, "wallet_ownership_frozen"},
+	{util.ErrCurrencyMismatch, "currency_mismatch"},
+	{util.ErrCurrencyRestricted, "currency_restricted"},
+	{util.ErrBudgetExceeded, "budget_exceeded"},
+	{util.ErrVolumeQuotaExceeded, "volume_quota_exceeded"},
+	{util.ErrScreeningHit, "screening_hit"},
+	{util.ErrApprovalRequired, "approval_required"},
+	{util.ErrForbidden, "forbidden"},
+}
+
+// asyncTransferFailure returns the failure code of err, and whether err fails the transfer for good.
+func asyncTransferFailure(err error) (string, bool) {
+	for _, failure := range asyncTransferFailures {
+		if errors.Is(err, failure.err) {
+			return failure.code, true
+		}
+	}
+	return "", false
+}
+
+// WithAsyncTransfers enables SubmitTransfer, which accepts transfers for ProcessAsyncTransfers to make later.
+// Without it SubmitTransfer fails with util.ErrInvalidInput. A transfer that could not be made for a passing
+// reason is tried again after retryBackoff, doubled with each further attempt up to maxRetryBackoff.
+func WithAsyncTransfers(asyncRepo repository.AsyncTransferRepository, retryBackoff, maxRetryBackoff time.Duration) WalletServiceOption {
+	return func(s *walletService) {
+		s.asyncRepo = asyncRepo
+		s.asyncBackoff = asyncTransferBackoff{initial: retryBackoff, max: max(retryBackoff, maxRetryBackoff)}
+	}
+}
+
+// SubmitTransfer checks a transfer as Transfer would, short of locking anything, and records it as a PENDING
+// TRANSFER for the worker to make. The checks that depend on the balance at the time of the transfer, i.e.
+// funds, budgets and promotional credits, are made again by the worker, and a transfer failing them ends
+// FAILED. Quoted transfers cannot be accepted, as their quote could expire before the worker gets to them.
+func (s *walletService) SubmitTransfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.Transaction, error) {
+	if s.asyncRepo == nil {
+		return nil, fmt.Errorf("%w: asynchronous transfers are not enabled", util.ErrInvalidInput)
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, util.ErrInvalidInput
+	}
+	if fromWalletID == toWalletID {
+		return nil, util.ErrSameWalletTransfer
+	}
+	if _, quoted := transferQuoteID(ctx); quoted {
+		return nil, fmt.Errorf("%w: a quoted transfer cannot be made asynchronously", util.ErrInvalidInput)
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("submit transfer: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("submit transfer: transaction controller does not implement DBExecutor")
+	}
+	if err := s.checkWalletPrecondition(ctx, txExecutor, fromWalletID); err != nil {
+		return nil, fmt.Errorf("submit transfer: %w", err)
+	}
+
+	wallets, err := s.walletRepo.GetWalletsByIDs(ctx, txExecutor, []int64{fromWalletID, toWalletID})
+	if err != nil {
+		return nil, fmt.Errorf("submit transfer: failed to get wallets: %w", err)
+	}
+	fromWallet, toWallet := findWallet(wallets, fromWalletID), findWallet(wallets, toWalletID)
+	if fromWallet == nil {
+		return nil, fmt.Errorf("submit transfer: failed to get source wallet %d: %w", fromWalletID, util.ErrNotFound)
+	}
+	if toWallet == nil {
+		return nil, fmt.Errorf("submit transfer: failed to get destination wallet %d: %w", toWalletID, util.ErrNotFound)
+	}
+	if fromWallet.Currency != currency || toWallet.Currency != currency {
+		return nil, util.ErrCurrencyMismatch
+	}
+	if err := checkImpersonatedOwner(ctx, fromWallet); err != nil {
+		return nil, err
+	}
+	if err := s.claimImpersonatedTransfer(ctx, txExecutor); err != nil {
+		return nil, fmt.Errorf("submit transfer: %w", err)
+	}
+	if err := s.authorize(ctx, ActionTransfer, fromWallet, toWallet, amount); err != nil {
+		return nil, fmt.Errorf("submit transfer: %w", err)
+	}
+	if err := s.checkVolumeQuota(ctx, currency, amount); err != nil {
+		return nil, fmt.Errorf("submit transfer: %w", err)
+	}
+	if err := checkNotFrozen(fromWallet); err != nil {
+		return nil, fmt.Errorf("submit transfer: %w", err)
+	}
+	if err := s.screenCounterparty(ctx, txExecutor, fromWallet, toWallet); err != nil {
+		return nil, fmt.Errorf("submit transfer: %w", err)
+	}
+	if err := s.checkDuplicateTransfer(ctx, txExecutor, fromWalletID, toWalletID, amount, currency); err != nil {
+		return nil, fmt.Errorf("submit transfer: %w", err)
+	}
+	if err := s.checkApprovalPolicy(ctx, txExecutor, fromWalletID, amount); err != nil {
+		return nil, fmt.Errorf("submit transfer: %w", err)
+	}
+
+	transaction := domain.NewTransaction(&fromWalletID, &toWalletID, amount, currency, domain.TransactionTypeTransfer, nil)
+	s.balances.Stamp(transaction)
+	transaction.Status = domain.TransactionStatusPending
+	transaction.Category = transactionCategory(ctx)
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, fmt.Errorf("submit transfer: failed to create transaction: %w", err)
+	}
+	transfer := &domain.AsyncTransfer{
+		TransactionID:  transaction.ID,
+		OverrideBudget: budgetOverridden(ctx),
+		CreatedAt:      transaction.CreatedAt,
+	}
+	if partnerID := PartnerFromContext(ctx); partnerID != "" {
+		transfer.PartnerID = &partnerID
+	}
+	if err := s.asyncRepo.CreateAsyncTransfer(ctx, txExecutor, transfer); err != nil {
+		return nil, fmt.Errorf("submit transfer: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("submit transfer: failed to commit transaction: %w", err)
+	}
+	transaction.FromWalletPublicID, transaction.ToWalletPublicID = &fromWallet.PublicID, &toWallet.PublicID
+	return transaction, nil
+}
+
+// ProcessAsyncTransfers makes up to limit due accepted transfers, oldest first, and returns how many it completed
+// or failed. A transfer failing for a reason of its own, e.g. insufficient funds, ends FAILED rather than being
+// retried, like an approved joint wallet transfer. Conflicts with concurrent updates are left PENDING for the next
+// run, and any other error, e.g. of the database, leaves the transfer PENDING until its next attempt is due. Both
+// are reported in the returned error with the failures that could not be recorded.
+// Transfers are made as the system, with the duplicate check lifted, since both were settled on acceptance.
+func (s *walletService) ProcessAsyncTransfers(ctx context.Context, limit int) (int, error) {
+	if s.asyncRepo == nil {
+		return 0, nil
+	}
+	transfers, err := s.asyncRepo.ListPendingAsyncTransfers(ctx, s.dbExecutor, s.clock.Now().UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("process async transfers: %w", err)
+	}
+	processed := 0
+	var errs []error
+	for i := range transfers {
+		if ctx.Err() != nil {
+			break
+		}
+		done, err := s.processAsyncTransfer(ctx, &transfers[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("async transfer %s: %w", transfers[i].TransactionPublicID, err))
+		}
+		if done {
+			processed++
+		}
+	}
+	return processed, errors.Join(errs...)
+}
+
+// processAsyncTransfer makes one accepted transfer and reports whether it left PENDING.
+func (s *walletService) processAsyncTransfer(ctx context.Context, transfer *domain.AsyncTransfer) (bool, error) {
+	transferCtx := WithForcedTransfer(WithSubject(ctx, Subject{Kind: SubjectSystem}))
+	if transfer.Category != nil {
+		transferCtx = WithTransactionCategory(transferCtx, *transfer.Category)
+	}
+	if transfer.OverrideBudget {
+		transferCtx = WithBudgetOverride(transferCtx)
+	}
+	if transfer.PartnerID != nil {
+		transferCtx = WithPartner(transferCtx, *transfer.PartnerID)
+	}
+
+	_, _, _, err := s.Transfer(withAsyncTransfer(transferCtx, transfer), transfer.FromWalletID, transfer.ToWalletID, transfer.Amount, transfer.Currency)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, util.ErrConcurrentUpdate) || ctx.Err() != nil {
+		return false, err
+	}
+	code, failed := asyncTransferFailure(err)
+	now := s.clock.Now().UTC()
+	if !failed {
+		nextAttemptAt := now.Add(s.asyncBackoff.delay(transfer.Attempts + 1))
+		if retryErr := s.asyncRepo.RetryAsyncTransfer(ctx, s.dbExecutor, transfer.TransactionID, nextAttemptAt, now); retryErr != nil {
+			return false, fmt.Errorf("failed to schedule retry after %w: %v", err, retryErr)
+		}
+		return false, fmt.Errorf("%w; retrying at %s", err, nextAttemptAt.Format(time.RFC3339))
+	}
+
+	failErr := s.asyncRepo.FailAsyncTransfer(ctx, s.dbExecutor, transfer.TransactionID, code, now)
+	if errors.Is(failErr, util.ErrNotFound) {
+		return false, nil // Completed by another worker meanwhile
+	}
+	if failErr != nil {
+		return false, fmt.Errorf("failed to record failure %q: %w", err, failErr)
+	}
+	// Only the watcher learns of the failure; the other listeners expect money to have moved
+	if s.watcher != nil {
+		s.watcher.OnTransaction(ctx, &domain.Transaction{PublicID: transfer.TransactionPublicID, Status: domain.TransactionStatusFailed})
+	}
+	return true, nil
+}
+
+// recordTransaction creates a money movement's transaction, or completes the PENDING one of the accepted
+// transfer the movement makes.
+func (s *walletService) recordTransaction(ctx context.Context, q repository.DBExecutor, transaction *domain.Transaction) error {
+	transfer := asyncTransferOf(ctx)
+	if transfer == nil || transaction.Type != domain.TransactionTypeTransfer {
+		return s.transactionRepo.CreateTransaction(ctx, q, transaction)
+	}
+	transaction.ID, transaction.PublicID, transaction.CreatedAt = transfer.TransactionID, transfer.TransactionPublicID, transfer.CreatedAt
+	return s.asyncRepo.CompleteAsyncTransfer(ctx, q, transaction)
+}
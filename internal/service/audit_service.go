@@ -0,0 +1,241 @@
+// internal/service/audit_service.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// auditVerifyPageSize is the number of events VerifyChain reads at a time.
+const auditVerifyPageSize = 500
+
+type serviceIdentityKey struct{}
+
+// WithServiceIdentity attaches the name of the internal service a request comes from, as established by its
+// client certificate, to ctx; the audit events of the money the request moves record it.
+func WithServiceIdentity(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, serviceIdentityKey{}, name)
+}
+
+// ServiceIdentityFromContext returns the internal service ctx acts for, or "".
+func ServiceIdentityFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(serviceIdentityKey{}).(string)
+	return name
+}
+
+// AuditService defines the interface for the tamper-evident audit trail: structured events per wallet, each
+// chained to the wallet's previous event by hash.
+type AuditService interface {
+	// TransactionListener records an AuditEventTransaction event in the chain of each wallet a committed
+	// transaction moved money into or out of.
+	TransactionListener
+	// Record appends an event with the payload, encoded as JSON, to the wallet's chain.
+	Record(ctx context.Context, walletID int64, eventType domain.AuditEventType, payload any) (*domain.AuditEvent, error)
+	// ListEvents retrieves up to limit events of the wallet after the given sequence number, in chain order.
+	ListEvents(ctx context.Context, walletID, afterSequence int64, limit int) ([]domain.AuditEvent, error)
+	// ChainHead returns the digest of the wallet's chain, or nil if the wallet has no events.
+	ChainHead(ctx context.Context, walletID int64) (*domain.AuditChainHead, error)
+	// VerifyChain walks the wallet's chain from its first event and reports the first broken link, if any.
+	VerifyChain(ctx context.Context, wallet *domain.Wallet) (*domain.AuditChainVerification, error)
+}
+
+// auditService implements AuditService.
+type auditService struct {
+	dbBeginner db.DBTxBeginner
+	dbExecutor repository.DBExecutor
+	auditRepo  repository.AuditRepository
+	walletRepo repository.WalletRepository
+	logger     *slog.Logger
+	beginTx    db.BeginTxFunc
+	commitTx   db.CommitTxFunc
+	rollbackTx db.RollbackTxFunc
+}
+
+// NewAuditService creates a new instance of AuditService.
+func NewAuditService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	auditRepo repository.AuditRepository,
+	walletRepo repository.WalletRepository,
+	logger *slog.Logger,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+) AuditService {
+	return &auditService{
+		dbBeginner: dbBeginner,
+		dbExecutor: dbExecutor,
+		auditRepo:  auditRepo,
+		walletRepo: walletRepo,
+		logger:     logger,
+		beginTx:    beginTx,
+		commitTx:   commitTx,
+		rollbackTx: rollbackTx,
+	}
+}
+
+// Record links the event to the chain's head under the chain's lock, so concurrent events of one wallet are
+// chained one after the other rather than both after the same head.
+func (s *auditService) Record(ctx context.Context, walletID int64, eventType domain.AuditEventType, payload any) (*domain.AuditEvent, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("record audit event: failed to encode payload: %w", err)
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("record audit event: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, errors.New("record audit event: transaction controller does not implement DBExecutor")
+	}
+
+	event, err := appendAuditEvent(ctx, txExecutor, s.auditRepo, walletID, eventType, encoded)
+	if err != nil {
+		return nil, fmt.Errorf("record audit event: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("record audit event: failed to commit transaction: %w", err)
+	}
+	return event, nil
+}
+
+// appendAuditEvent appends an event with the encoded payload to the wallet's chain in the caller's
+// transaction, which holds the chain's lock from then on. Services changing a wallet use it to record the
+// change in the same transaction as the change itself.
+func appendAuditEvent(ctx context.Context, q repository.DBExecutor, auditRepo repository.AuditRepository, walletID int64, eventType domain.AuditEventType, encoded []byte) (*domain.AuditEvent, error) {
+	if err := auditRepo.LockChain(ctx, q, walletID); err != nil {
+		return nil, err
+	}
+	head, err := auditRepo.GetChainHead(ctx, q, walletID)
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		return nil, err
+	}
+	event := domain.NewAuditEvent(walletID, eventType, string(encoded), time.Now(), head)
+	if err := auditRepo.AppendEvent(ctx, q, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// OnTransaction records the transaction in the source wallet's chain as a debit and in the destination
+// wallet's as a credit. The money has moved already, so a failure is logged rather than returned.
+func (s *auditService) OnTransaction(ctx context.Context, transaction *domain.Transaction) {
+	legs := []struct {
+		walletID, counterpartyID *int64
+		counterparty             *uuid.UUID
+		direction                string
+	}{
+		{transaction.FromWalletID, transaction.ToWalletID, transaction.ToWalletPublicID, domain.AuditDirectionDebit},
+		{transaction.ToWalletID, transaction.FromWalletID, transaction.FromWalletPublicID, domain.AuditDirectionCredit},
+	}
+	for _, leg := range legs {
+		if leg.walletID == nil {
+			continue
+		}
+		payload := domain.AuditTransactionPayload{
+			TransactionID:        transaction.PublicID,
+			Type:                 transaction.Type,
+			Status:               transaction.Status,
+			Direction:            leg.direction,
+			Amount:               transaction.Amount,
+			Currency:             transaction.Currency,
+			CounterpartyWalletID: s.publicWalletID(ctx, leg.counterpartyID, leg.counterparty),
+			TransactionTime:      transaction.TransactionTime.UTC(),
+			Service:              ServiceIdentityFromContext(ctx),
+		}
+		if _, err := s.Record(ctx, *leg.walletID, domain.AuditEventTransaction, payload); err != nil {
+			s.logger.Error("Failed to record audit event", "wallet_id", *leg.walletID, "transaction_id", transaction.PublicID, "error", err)
+		}
+	}
+}
+
+// publicWalletID returns the public ID of a transaction's wallet, looking it up if the transaction does not
+// carry it. It returns nil if there is no such wallet or it cannot be found.
+func (s *auditService) publicWalletID(ctx context.Context, walletID *int64, publicID *uuid.UUID) *uuid.UUID {
+	if publicID != nil || walletID == nil {
+		return publicID
+	}
+	wallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, *walletID)
+	if err != nil {
+		s.logger.Warn("Failed to look up counterparty of audit event", "wallet_id", *walletID, "error", err)
+		return nil
+	}
+	return &wallet.PublicID
+}
+
+func (s *auditService) ListEvents(ctx context.Context, walletID, afterSequence int64, limit int) ([]domain.AuditEvent, error) {
+	events, err := s.auditRepo.ListEventsAfter(ctx, s.dbExecutor, walletID, afterSequence, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list audit events: %w", err)
+	}
+	return events, nil
+}
+
+func (s *auditService) ChainHead(ctx context.Context, walletID int64) (*domain.AuditChainHead, error) {
+	head, err := s.auditRepo.GetChainHead(ctx, s.dbExecutor, walletID)
+	if errors.Is(err, util.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get audit chain head: %w", err)
+	}
+	return head, nil
+}
+
+// VerifyChain checks that the sequence numbers follow each other from 1, that each event links to the hash of
+// the one before it, and that each event's hash matches its content. Events appended while it runs are
+// verified as well.
+func (s *auditService) VerifyChain(ctx context.Context, wallet *domain.Wallet) (*domain.AuditChainVerification, error) {
+	verification := &domain.AuditChainVerification{WalletPublicID: wallet.PublicID, Valid: true}
+	previous := &domain.AuditChainHead{Sequence: 0, Hash: domain.AuditGenesisHash}
+	for {
+		events, err := s.auditRepo.ListEventsAfter(ctx, s.dbExecutor, wallet.ID, previous.Sequence, auditVerifyPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("verify audit chain: %w", err)
+		}
+		for i := range events {
+			event := &events[i]
+			verification.Events++
+			reason := ""
+			switch {
+			case event.Sequence != previous.Sequence+1:
+				reason = fmt.Sprintf("event %d follows event %d", event.Sequence, previous.Sequence)
+			case event.PrevHash != previous.Hash:
+				reason = "the previous hash does not match the hash of the previous event"
+			case event.ComputeHash() != event.Hash:
+				reason = "the hash does not match the event's content"
+			}
+			if reason != "" {
+				verification.Valid = false
+				verification.BrokenAt = &event.Sequence
+				verification.Reason = reason
+				s.logger.Warn("Audit chain broken", "wallet_id", wallet.PublicID, "sequence", event.Sequence, "reason", reason)
+				return verification, nil
+			}
+			previous = event.Head()
+			verification.Head = previous
+		}
+		if len(events) < auditVerifyPageSize {
+			return verification, nil
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// internal/service/audit_service_test.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestAuditService tests appending events to the wallet hash chains and verifying the chains.
+func TestAuditService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), Currency: "USD"}
+
+	type mocks struct {
+		auditRepo    *MockAuditRepository
+		walletRepo   *MockWalletRepository
+		dbExecutor   *MockDBExecutor
+		txController *MockTxController
+	}
+	newService := func() (AuditService, mocks) {
+		m := mocks{
+			auditRepo:    new(MockAuditRepository),
+			walletRepo:   new(MockWalletRepository),
+			dbExecutor:   new(MockDBExecutor),
+			txController: new(MockTxController),
+		}
+		service := NewAuditService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.auditRepo,
+			m.walletRepo,
+			logger,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+		)
+		m.txController.On("Rollback").Return(nil).Maybe()
+		return service, m
+	}
+	// chain builds n linked events of the wallet
+	chain := func(n int) []domain.AuditEvent {
+		events := make([]domain.AuditEvent, 0, n)
+		var head *domain.AuditChainHead
+		for i := 0; i < n; i++ {
+			event := domain.NewAuditEvent(wallet.ID, domain.AuditEventTransaction, `{"n":`+strconv.Itoa(i)+`}`, time.Now(), head)
+			events = append(events, *event)
+			head = event.Head()
+		}
+		return events
+	}
+
+	t.Run("RecordStartsChainFromGenesis", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.auditRepo.On("LockChain", ctx, m.txController, wallet.ID).Return(nil).Once()
+		m.auditRepo.On("GetChainHead", ctx, m.txController, wallet.ID).Return(nil, util.ErrNotFound).Once()
+		m.auditRepo.On("AppendEvent", ctx, m.txController, mock.AnythingOfType("*domain.AuditEvent")).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		event, err := service.Record(ctx, wallet.ID, domain.AuditEventTransaction, map[string]string{"k": "v"})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), event.Sequence)
+		assert.Equal(t, domain.AuditGenesisHash, event.PrevHash)
+		assert.Equal(t, `{"k":"v"}`, event.Payload)
+		assert.Equal(t, event.ComputeHash(), event.Hash)
+		m.auditRepo.AssertExpectations(t)
+		m.txController.AssertExpectations(t)
+	})
+
+	t.Run("RecordLinksToChainHead", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		head := &domain.AuditChainHead{Sequence: 7, Hash: "ab" + domain.AuditGenesisHash[2:]}
+		m.auditRepo.On("LockChain", ctx, m.txController, wallet.ID).Return(nil).Once()
+		m.auditRepo.On("GetChainHead", ctx, m.txController, wallet.ID).Return(head, nil).Once()
+		m.auditRepo.On("AppendEvent", ctx, m.txController, mock.AnythingOfType("*domain.AuditEvent")).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		event, err := service.Record(ctx, wallet.ID, domain.AuditEventTransaction, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), event.Sequence)
+		assert.Equal(t, head.Hash, event.PrevHash)
+	})
+
+	t.Run("RecordFailsWithoutCommitOnAppendError", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.auditRepo.On("LockChain", ctx, m.txController, wallet.ID).Return(nil).Once()
+		m.auditRepo.On("GetChainHead", ctx, m.txController, wallet.ID).Return(nil, util.ErrNotFound).Once()
+		m.auditRepo.On("AppendEvent", ctx, m.txController, mock.Anything).Return(util.ErrDuplicateEntry).Once()
+
+		_, err := service.Record(ctx, wallet.ID, domain.AuditEventTransaction, nil)
+
+		assert.ErrorIs(t, err, util.ErrDuplicateEntry)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("OnTransactionRecordsBothLegs", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		fromID, toID := int64(1), int64(2)
+		toPublicID := uuid.New()
+		transaction := &domain.Transaction{
+			PublicID:         uuid.New(),
+			FromWalletID:     &fromID,
+			ToWalletID:       &toID,
+			ToWalletPublicID: &toPublicID,
+			Type:             domain.TransactionTypeTransfer,
+			Status:           domain.TransactionStatusCompleted,
+			Amount:           decimal.NewFromInt(25),
+			Currency:         "USD",
+			TransactionTime:  time.Now(),
+		}
+		// The source wallet's public ID is not on the transaction and is looked up
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, fromID).Return(wallet, nil).Once()
+		var recorded []domain.AuditTransactionPayload
+		for _, walletID := range []int64{fromID, toID} {
+			m.auditRepo.On("LockChain", ctx, m.txController, walletID).Return(nil).Once()
+			m.auditRepo.On("GetChainHead", ctx, m.txController, walletID).Return(nil, util.ErrNotFound).Once()
+		}
+		m.auditRepo.On("AppendEvent", ctx, m.txController, mock.Anything).Run(func(args mock.Arguments) {
+			var payload domain.AuditTransactionPayload
+			require.NoError(t, json.Unmarshal([]byte(args.Get(2).(*domain.AuditEvent).Payload), &payload))
+			recorded = append(recorded, payload)
+		}).Return(nil).Twice()
+		m.txController.On("Commit").Return(nil).Twice()
+
+		service.OnTransaction(ctx, transaction)
+
+		require.Len(t, recorded, 2)
+		assert.Equal(t, domain.AuditDirectionDebit, recorded[0].Direction)
+		assert.Equal(t, toPublicID, *recorded[0].CounterpartyWalletID)
+		assert.Equal(t, domain.AuditDirectionCredit, recorded[1].Direction)
+		assert.Equal(t, wallet.PublicID, *recorded[1].CounterpartyWalletID)
+		assert.True(t, recorded[1].Amount.Equal(decimal.NewFromInt(25)))
+		m.auditRepo.AssertExpectations(t)
+	})
+
+	t.Run("OnTransactionRecordsServiceIdentity", func(t *testing.T) {
+		ctx := WithServiceIdentity(context.Background(), "ledger")
+		service, m := newService()
+		toID := int64(2)
+		transaction := &domain.Transaction{
+			PublicID:        uuid.New(),
+			ToWalletID:      &toID,
+			Type:            domain.TransactionTypeDeposit,
+			Status:          domain.TransactionStatusCompleted,
+			Amount:          decimal.NewFromInt(25),
+			Currency:        "USD",
+			TransactionTime: time.Now(),
+		}
+		var payload domain.AuditTransactionPayload
+		m.auditRepo.On("LockChain", ctx, m.txController, toID).Return(nil).Once()
+		m.auditRepo.On("GetChainHead", ctx, m.txController, toID).Return(nil, util.ErrNotFound).Once()
+		m.auditRepo.On("AppendEvent", ctx, m.txController, mock.Anything).Run(func(args mock.Arguments) {
+			require.NoError(t, json.Unmarshal([]byte(args.Get(2).(*domain.AuditEvent).Payload), &payload))
+		}).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		service.OnTransaction(ctx, transaction)
+
+		assert.Equal(t, "ledger", payload.Service)
+		m.auditRepo.AssertExpectations(t)
+	})
+
+	t.Run("VerifyChainAcceptsIntactChain", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		events := chain(3)
+		m.auditRepo.On("ListEventsAfter", ctx, m.dbExecutor, wallet.ID, int64(0), auditVerifyPageSize).Return(events, nil).Once()
+
+		verification, err := service.VerifyChain(ctx, wallet)
+
+		require.NoError(t, err)
+		assert.True(t, verification.Valid)
+		assert.Equal(t, int64(3), verification.Events)
+		assert.Equal(t, events[2].Head(), verification.Head)
+		assert.Nil(t, verification.BrokenAt)
+	})
+
+	t.Run("VerifyChainDetectsTamperedPayload", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		events := chain(3)
+		events[1].Payload = `{"n":9}`
+		m.auditRepo.On("ListEventsAfter", ctx, m.dbExecutor, wallet.ID, int64(0), auditVerifyPageSize).Return(events, nil).Once()
+
+		verification, err := service.VerifyChain(ctx, wallet)
+
+		require.NoError(t, err)
+		assert.False(t, verification.Valid)
+		assert.Equal(t, int64(2), *verification.BrokenAt)
+		assert.Equal(t, events[0].Head(), verification.Head)
+		assert.Contains(t, verification.Reason, "content")
+	})
+
+	t.Run("VerifyChainDetectsRewrittenLink", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		events := chain(3)
+		// Rehashing a tampered event does not help: the next event still links to the original hash
+		events[1].Payload = `{"n":9}`
+		events[1].Hash = events[1].ComputeHash()
+		m.auditRepo.On("ListEventsAfter", ctx, m.dbExecutor, wallet.ID, int64(0), auditVerifyPageSize).Return(events, nil).Once()
+
+		verification, err := service.VerifyChain(ctx, wallet)
+
+		require.NoError(t, err)
+		assert.False(t, verification.Valid)
+		assert.Equal(t, int64(3), *verification.BrokenAt)
+		assert.Contains(t, verification.Reason, "previous hash")
+	})
+
+	t.Run("VerifyChainDetectsDeletedEvent", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		events := chain(3)
+		m.auditRepo.On("ListEventsAfter", ctx, m.dbExecutor, wallet.ID, int64(0), auditVerifyPageSize).
+			Return([]domain.AuditEvent{events[0], events[2]}, nil).Once()
+
+		verification, err := service.VerifyChain(ctx, wallet)
+
+		require.NoError(t, err)
+		assert.False(t, verification.Valid)
+		assert.Equal(t, int64(3), *verification.BrokenAt)
+		assert.Equal(t, "event 3 follows event 1", verification.Reason)
+	})
+}
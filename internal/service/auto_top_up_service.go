@@ -0,0 +1,316 @@
+// internal/service/auto_top_up_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// AutoTopUpService defines the interface for a wallet's linked funding sources and its auto top-up rule,
+// and executes the rules: a wallet whose balance drops below its threshold is refilled from the funding source.
+type AutoTopUpService interface {
+	AddFundingSource(ctx context.Context, source *domain.FundingSource) error
+	ListFundingSources(ctx context.Context, walletID int64) ([]domain.FundingSource, error)
+	RemoveFundingSource(ctx context.Context, walletID, sourceID int64) error
+	GetRule(ctx context.Context, wallet *domain.Wallet) (*domain.AutoTopUpRule, error)
+	// SetRule creates or replaces the wallet's rule: below threshold, charge amount to the funding source.
+	SetRule(ctx context.Context, wallet *domain.Wallet, fundingSourceID int64, threshold, amount decimal.Decimal) (*domain.AutoTopUpRule, error)
+	DeleteRule(ctx context.Context, wallet *domain.Wallet) error
+	// RunTopUps executes every due rule and returns the number of wallets topped up.
+	RunTopUps(ctx context.Context, now time.Time) (int, error)
+}
+
+// autoTopUpService implements AutoTopUpService.
+type autoTopUpService struct {
+	dbBeginner      db.DBTxBeginner
+	dbExecutor      repository.DBExecutor
+	topUpRepo       repository.AutoTopUpRepository
+	walletRepo      repository.WalletRepository
+	balances        *BalanceWriter // Applies balance changes, on a shard for sharded wallets
+	transactionRepo repository.TransactionRepository
+	gateway         PaymentGateway     // Optional; without it rules cannot be set and none are executed
+	events          *TransactionEvents // Optional; top-ups are published here
+	cooldown        time.Duration      // Minimum time between two attempts of a rule
+	maxFailures     int                // Consecutive failures after which a rule is disabled; 0 never disables
+	beginTx         db.BeginTxFunc
+	commitTx        db.CommitTxFunc
+	rollbackTx      db.RollbackTxFunc
+	logger          *slog.Logger
+}
+
+// NewAutoTopUpService creates a new instance of AutoTopUpService.
+func NewAutoTopUpService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	topUpRepo repository.AutoTopUpRepository,
+	walletRepo repository.WalletRepository,
+	balances *BalanceWriter,
+	transactionRepo repository.TransactionRepository,
+	gateway PaymentGateway,
+	events *TransactionEvents,
+	cooldown time.Duration,
+	maxFailures int,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) AutoTopUpService {
+	return &autoTopUpService{
+		dbBeginner:      dbBeginner,
+		dbExecutor:      dbExecutor,
+		topUpRepo:       topUpRepo,
+		walletRepo:      walletRepo,
+		balances:        balances,
+		transactionRepo: transactionRepo,
+		gateway:         gateway,
+		events:          events,
+		cooldown:        cooldown,
+		maxFailures:     maxFailures,
+		beginTx:         beginTx,
+		commitTx:        commitTx,
+		rollbackTx:      rollbackTx,
+		logger:          logger,
+	}
+}
+
+// AddFundingSource validates and links a new funding source. Providers are matched case-insensitively.
+func (s *autoTopUpService) AddFundingSource(ctx context.Context, source *domain.FundingSource) error {
+	source.Provider = strings.ToLower(strings.TrimSpace(source.Provider))
+	source.Reference = strings.TrimSpace(source.Reference)
+	if source.Provider == "" || len(source.Provider) > 50 {
+		return fmt.Errorf("%w: provider is required and may have at most 50 characters", util.ErrInvalidInput)
+	}
+	if source.Reference == "" || len(source.Reference) > 255 {
+		return fmt.Errorf("%w: reference is required and may have at most 255 characters", util.ErrInvalidInput)
+	}
+	if source.Label != nil && len(*source.Label) > 100 {
+		return fmt.Errorf("%w: label may have at most 100 characters", util.ErrInvalidInput)
+	}
+
+	source.CreatedAt = time.Now().UTC()
+	if err := s.topUpRepo.CreateFundingSource(ctx, s.dbExecutor, source); err != nil {
+		return fmt.Errorf("add funding source: %w", err)
+	}
+	s.logger.Info("Funding source linked", "funding_source_id", source.ID, "wallet_id", source.WalletID, "provider", source.Provider)
+	return nil
+}
+
+// ListFundingSources retrieves all funding sources of a wallet.
+func (s *autoTopUpService) ListFundingSources(ctx context.Context, walletID int64) ([]domain.FundingSource, error) {
+	sources, err := s.topUpRepo.ListFundingSources(ctx, s.dbExecutor, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("list funding sources: %w", err)
+	}
+	return sources, nil
+}
+
+// RemoveFundingSource unlinks a wallet's funding source; one used by the auto top-up rule cannot be unlinked.
+func (s *autoTopUpService) RemoveFundingSource(ctx context.Context, walletID, sourceID int64) error {
+	if err := s.topUpRepo.DeleteFundingSource(ctx, s.dbExecutor, walletID, sourceID); err != nil {
+		return fmt.Errorf("remove funding source %d: %w", sourceID, err)
+	}
+	s.logger.Info("Funding source unlinked", "funding_source_id", sourceID, "wallet_id", walletID)
+	return nil
+}
+
+// GetRule retrieves the wallet's auto top-up rule.
+func (s *autoTopUpService) GetRule(ctx context.Context, wallet *domain.Wallet) (*domain.AutoTopUpRule, error) {
+	rule, err := s.topUpRepo.GetRule(ctx, s.dbExecutor, wallet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get auto top-up rule: %w", err)
+	}
+	return rule, nil
+}
+
+// SetRule validates and stores the wallet's rule. The funding source must be linked to the wallet, and the
+// amount must fit the wallet currency's minor unit, as it is charged as it is.
+func (s *autoTopUpService) SetRule(ctx context.Context, wallet *domain.Wallet, fundingSourceID int64, threshold, amount decimal.Decimal) (*domain.AutoTopUpRule, error) {
+	if s.gateway == nil {
+		return nil, fmt.Errorf("%w: auto top-up is not enabled", util.ErrInvalidInput)
+	}
+	if threshold.IsNegative() {
+		return nil, fmt.Errorf("%w: threshold must not be negative", util.ErrInvalidInput)
+	}
+	if !amount.IsPositive() || !amount.Equal(amount.Truncate(domain.CurrencyPrecision(wallet.Currency))) {
+		return nil, fmt.Errorf("%w: amount must be positive and fit the currency's minor unit", util.ErrInvalidInput)
+	}
+	source, err := s.topUpRepo.GetFundingSource(ctx, s.dbExecutor, wallet.ID, fundingSourceID)
+	if errors.Is(err, util.ErrNotFound) {
+		return nil, fmt.Errorf("%w: funding source %d is not linked to the wallet", util.ErrInvalidInput, fundingSourceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set auto top-up rule: %w", err)
+	}
+
+	now := time.Now().UTC()
+	rule := &domain.AutoTopUpRule{
+		WalletID:        wallet.ID,
+		WalletPublicID:  wallet.PublicID,
+		Currency:        wallet.Currency,
+		FundingSourceID: source.ID,
+		Provider:        source.Provider,
+		Reference:       source.Reference,
+		Threshold:       threshold,
+		Amount:          amount,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.topUpRepo.SaveRule(ctx, s.dbExecutor, rule); err != nil {
+		return nil, fmt.Errorf("set auto top-up rule: %w", err)
+	}
+	s.logger.Info("Auto top-up rule set", "wallet_id", wallet.PublicID, "funding_source_id", source.ID,
+		"threshold", threshold.String(), "amount", amount.String())
+	return rule, nil
+}
+
+// DeleteRule removes the wallet's auto top-up rule.
+func (s *autoTopUpService) DeleteRule(ctx context.Context, wallet *domain.Wallet) error {
+	if err := s.topUpRepo.DeleteRule(ctx, s.dbExecutor, wallet.ID); err != nil {
+		return fmt.Errorf("delete auto top-up rule: %w", err)
+	}
+	s.logger.Info("Auto top-up rule deleted", "wallet_id", wallet.PublicID)
+	return nil
+}
+
+// RunTopUps tops up each due wallet on its own; a failed top-up is logged as an alert, retried after the
+// cooldown and, after too many consecutive failures, disables the rule until it is set again.
+func (s *autoTopUpService) RunTopUps(ctx context.Context, now time.Time) (int, error) {
+	if s.gateway == nil {
+		return 0, nil
+	}
+	rules, err := s.topUpRepo.ListDueRules(ctx, s.dbExecutor, now.Add(-s.cooldown))
+	if err != nil {
+		return 0, fmt.Errorf("run auto top-ups: %w", err)
+	}
+
+	toppedUp := 0
+	for i := range rules {
+		transaction, err := s.topUp(ctx, &rules[i], now)
+		if err != nil {
+			continue // Already logged and recorded
+		}
+		if transaction != nil {
+			toppedUp++
+		}
+	}
+	return toppedUp, nil
+}
+
+// topUp claims the rule for this attempt, charges the funding source and deposits the charged amount.
+// It returns nil without an error when the rule no longer fires, e.g. another instance claimed it first.
+func (s *autoTopUpService) topUp(ctx context.Context, rule *domain.AutoTopUpRule, now time.Time) (*domain.Transaction, error) {
+	if err := s.topUpRepo.ClaimRule(ctx, s.dbExecutor, rule.WalletID, now, now.Add(-s.cooldown)); err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return nil, nil
+		}
+		s.logger.Error("Auto top-up failed", "wallet_id", rule.WalletPublicID, "error", err)
+		return nil, err
+	}
+	// The balance may have recovered since the rules were listed
+	wallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, rule.WalletID)
+	if err != nil {
+		s.recordFailure(ctx, rule, err, false)
+		return nil, err
+	}
+	if !wallet.Balance.LessThan(rule.Threshold) {
+		return nil, nil
+	}
+	// The money could not be credited, so it is not collected
+	if err := s.balances.CheckFunding(ctx, s.dbExecutor, wallet); err != nil {
+		s.recordFailure(ctx, rule, err, true)
+		return nil, err
+	}
+
+	chargeID, err := s.gateway.Charge(ctx, ChargeRequest{
+		Provider:       rule.Provider,
+		Reference:      rule.Reference,
+		Amount:         rule.Amount,
+		Currency:       rule.Currency,
+		IdempotencyKey: fmt.Sprintf("auto-top-up-%s-%d", rule.WalletPublicID, now.Unix()),
+	})
+	if err != nil {
+		s.recordFailure(ctx, rule, err, false)
+		return nil, err
+	}
+
+	transaction, err := s.deposit(ctx, rule, chargeID)
+	if err != nil {
+		// The money was collected but not credited; stop charging until an operator has reconciled the charge
+		s.logger.Error("Auto top-up charged but not credited", "wallet_id", rule.WalletPublicID, "charge_id", chargeID,
+			"amount", rule.Amount.String(), "currency", rule.Currency, "error", err)
+		s.recordFailure(ctx, rule, fmt.Errorf("charge %s was not credited: %w", chargeID, err), true)
+		return nil, err
+	}
+	s.logger.Info("Auto top-up executed", "wallet_id", rule.WalletPublicID, "transaction_id", transaction.PublicID,
+		"charge_id", chargeID, "amount", rule.Amount.String())
+	return transaction, nil
+}
+
+// deposit credits a charged top-up as a DEPOSIT and clears the rule's failures in one database transaction.
+func (s *autoTopUpService) deposit(ctx context.Context, rule *domain.AutoTopUpRule, chargeID string) (*domain.Transaction, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("transaction controller does not implement DBExecutor")
+	}
+
+	wallet, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, rule.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Currency != rule.Currency {
+		return nil, util.ErrCurrencyMismatch
+	}
+	if _, err := s.balances.Credit(ctx, txExecutor, wallet, rule.Amount); err != nil {
+		return nil, err
+	}
+	description := fmt.Sprintf("Auto top-up, charge %s", chargeID)
+	transaction := domain.NewTransaction(nil, &rule.WalletID, rule.Amount, rule.Currency, domain.TransactionTypeDeposit, &description)
+	s.balances.Stamp(transaction)
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if err := s.topUpRepo.RecordOutcome(ctx, txExecutor, rule.WalletID, 0, nil, true); err != nil {
+		return nil, err
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
+	return transaction, nil
+}
+
+// recordFailure stores a failed attempt and raises the alerts: every failure, and a rule disabled by it.
+// A rule is disabled after maxFailures consecutive failures, or right away when disable is set.
+func (s *autoTopUpService) recordFailure(ctx context.Context, rule *domain.AutoTopUpRule, cause error, disable bool) {
+	failures := rule.ConsecutiveFailures + 1
+	enabled := !disable && (s.maxFailures <= 0 || failures < s.maxFailures)
+	message := cause.Error()
+	s.logger.Error("Auto top-up failed", "wallet_id", rule.WalletPublicID, "consecutive_failures", failures, "error", cause)
+	if err := s.topUpRepo.RecordOutcome(ctx, s.dbExecutor, rule.WalletID, failures, &message, enabled); err != nil {
+		s.logger.Error("Failed to record auto top-up failure", "wallet_id", rule.WalletPublicID, "error", err)
+		return
+	}
+	if !enabled {
+		s.logger.Error("Auto top-up rule disabled", "wallet_id", rule.WalletPublicID, "consecutive_failures", failures)
+	}
+}
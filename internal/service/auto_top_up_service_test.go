@@ -0,0 +1,168 @@
+// internal/service/auto_top_up_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestAutoTopUpService tests rule validation and the execution of due auto top-ups.
+func TestAutoTopUpService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cooldown := time.Hour
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Balance: decimal.NewFromInt(5)}
+	rule := domain.AutoTopUpRule{
+		WalletID:        wallet.ID,
+		WalletPublicID:  wallet.PublicID,
+		Currency:        "USD",
+		FundingSourceID: 7,
+		Provider:        "card",
+		Reference:       "pm_123",
+		Threshold:       decimal.NewFromInt(20),
+		Amount:          decimal.NewFromInt(50),
+		Enabled:         true,
+	}
+
+	type mocks struct {
+		topUpRepo       *MockAutoTopUpRepository
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		gateway         *MockPaymentGateway
+		dbExecutor      *MockDBExecutor
+		txController    *MockTxController
+	}
+	newService := func() (AutoTopUpService, mocks) {
+		m := mocks{
+			topUpRepo:       new(MockAutoTopUpRepository),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			gateway:         new(MockPaymentGateway),
+			dbExecutor:      new(MockDBExecutor),
+			txController:    new(MockTxController),
+		}
+		service := NewAutoTopUpService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.topUpRepo,
+			m.walletRepo,
+			NewBalanceWriter(m.walletRepo, nil, nil, nil),
+			m.transactionRepo,
+			m.gateway,
+			nil,
+			cooldown,
+			3,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+
+	t.Run("DueRuleChargesAndDeposits", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.topUpRepo.On("ListDueRules", ctx, m.dbExecutor, now.Add(-cooldown)).Return([]domain.AutoTopUpRule{rule}, nil).Once()
+		m.topUpRepo.On("ClaimRule", ctx, m.dbExecutor, wallet.ID, now, now.Add(-cooldown)).Return(nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, wallet.ID).Return(wallet, nil).Once()
+		m.gateway.On("Charge", ctx, mock.MatchedBy(func(req ChargeRequest) bool {
+			return req.Reference == "pm_123" && req.Amount.Equal(rule.Amount) && req.Currency == "USD" && req.IdempotencyKey != ""
+		})).Return("ch_1", nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, wallet.ID, rule.Amount, mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeDeposit && *tx.ToWalletID == wallet.ID && tx.Amount.Equal(rule.Amount)
+		})).Return(nil).Once()
+		m.topUpRepo.On("RecordOutcome", ctx, m.txController, wallet.ID, 0, (*string)(nil), true).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		toppedUp, err := service.RunTopUps(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, toppedUp)
+		m.topUpRepo.AssertExpectations(t)
+		m.gateway.AssertExpectations(t)
+		m.transactionRepo.AssertExpectations(t)
+		m.txController.AssertExpectations(t)
+	})
+
+	t.Run("ClaimedElsewhereIsSkipped", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.topUpRepo.On("ListDueRules", ctx, m.dbExecutor, now.Add(-cooldown)).Return([]domain.AutoTopUpRule{rule}, nil).Once()
+		m.topUpRepo.On("ClaimRule", ctx, m.dbExecutor, wallet.ID, now, now.Add(-cooldown)).Return(util.ErrNotFound).Once()
+
+		toppedUp, err := service.RunTopUps(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, toppedUp)
+		m.gateway.AssertNotCalled(t, "Charge", mock.Anything, mock.Anything)
+		m.topUpRepo.AssertNotCalled(t, "RecordOutcome", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("GatewayFailureCountsAndDisablesAtLimit", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		failing := rule
+		failing.ConsecutiveFailures = 2
+
+		m.topUpRepo.On("ListDueRules", ctx, m.dbExecutor, now.Add(-cooldown)).Return([]domain.AutoTopUpRule{failing}, nil).Once()
+		m.topUpRepo.On("ClaimRule", ctx, m.dbExecutor, wallet.ID, now, now.Add(-cooldown)).Return(nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, wallet.ID).Return(wallet, nil).Once()
+		m.gateway.On("Charge", ctx, mock.Anything).Return("", errors.New("card declined")).Once()
+		m.topUpRepo.On("RecordOutcome", ctx, m.dbExecutor, wallet.ID, 3, mock.MatchedBy(func(message *string) bool {
+			return message != nil && *message == "card declined"
+		}), false).Return(nil).Once()
+
+		toppedUp, err := service.RunTopUps(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, toppedUp)
+		m.topUpRepo.AssertExpectations(t)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SetRuleRejectsUnlinkedSource", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.topUpRepo.On("GetFundingSource", ctx, m.dbExecutor, wallet.ID, int64(99)).Return(nil, util.ErrNotFound).Once()
+
+		_, err := service.SetRule(ctx, wallet, 99, decimal.NewFromInt(20), decimal.NewFromInt(50))
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.topUpRepo.AssertNotCalled(t, "SaveRule", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SetRuleRejectsSubMinorUnitAmount", func(t *testing.T) {
+		service, m := newService()
+
+		_, err := service.SetRule(context.Background(), wallet, 7, decimal.NewFromInt(20), decimal.RequireFromString("10.005"))
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.topUpRepo.AssertNotCalled(t, "GetFundingSource", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,192 @@
+// internal/service/backup_verification_service.go
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// verificationSamples bounds the failures listed in a check of a verification report.
+const verificationSamples = 20
+
+// ErrInvalidSignature is returned for a verification report whose signature does not match its content.
+var ErrInvalidSignature = errors.New("verification report signature does not match")
+
+// BackupVerificationService verifies the integrity of a database, typically a backup restored in a disaster
+// recovery drill.
+type BackupVerificationService interface {
+	// Verify runs the invariant checks against the database and returns the signed report. A check that
+	// cannot run fails the report rather than the call.
+	Verify(ctx context.Context) (*domain.VerificationReport, error)
+}
+
+type backupVerificationService struct {
+	dbExecutor      repository.DBExecutor
+	integrityRepo   repository.IntegrityRepository
+	schemaRepo      repository.SchemaRepository
+	latestMigration uint64 // Last migration of the code
+	database        string // Named in the report
+	signingKey      []byte
+	logger          *slog.Logger
+}
+
+// NewBackupVerificationService creates a new instance of BackupVerificationService, whose reports name
+// database and are signed with signingKey.
+func NewBackupVerificationService(
+	dbExecutor repository.DBExecutor,
+	integrityRepo repository.IntegrityRepository,
+	schemaRepo repository.SchemaRepository,
+	latestMigration uint64,
+	database string,
+	signingKey []byte,
+	logger *slog.Logger,
+) BackupVerificationService {
+	return &backupVerificationService{
+		dbExecutor:      dbExecutor,
+		integrityRepo:   integrityRepo,
+		schemaRepo:      schemaRepo,
+		latestMigration: latestMigration,
+		database:        database,
+		signingKey:      signingKey,
+		logger:          logger,
+	}
+}
+
+// Verify runs the invariant checks in order and signs the report.
+func (s *backupVerificationService) Verify(ctx context.Context) (*domain.VerificationReport, error) {
+	if len(s.signingKey) == 0 {
+		return nil, fmt.Errorf("%w: no verification signing key is configured", util.ErrInvalidInput)
+	}
+	report := &domain.VerificationReport{ID: uuid.New(), Database: s.database, StartedAt: time.Now().UTC(), Passed: true}
+	for _, check := range []func(context.Context) (domain.IntegrityCheck, error){
+		s.checkSchemaVersion,
+		s.checkLedger,
+		s.checkWalletBalances,
+		s.checkForeignKeys,
+	} {
+		result, err := check(ctx)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Passed = err == nil && result.Failures == 0
+		report.Passed = report.Passed && result.Passed
+		report.Checks = append(report.Checks, result)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("verify backup: %w", ctx.Err())
+		}
+	}
+	report.CompletedAt = time.Now().UTC()
+
+	if err := SignReport(report, s.signingKey); err != nil {
+		return nil, fmt.Errorf("verify backup: %w", err)
+	}
+	s.logger.Info("Backup verified", "report", report.ID, "database", s.database, "passed", report.Passed)
+	return report, nil
+}
+
+func (s *backupVerificationService) checkSchemaVersion(ctx context.Context) (domain.IntegrityCheck, error) {
+	check := domain.IntegrityCheck{Name: domain.CheckSchemaVersion}
+	version, dirty, err := s.schemaRepo.GetSchemaVersion(ctx, s.dbExecutor)
+	if err != nil {
+		return check, err
+	}
+	if dirty {
+		check.Failures++
+		check.Samples = append(check.Samples, fmt.Sprintf("migration %d is dirty", version))
+	}
+	if version != s.latestMigration {
+		check.Failures++
+		check.Samples = append(check.Samples, fmt.Sprintf("schema version %d, code expects %d", version, s.latestMigration))
+	}
+	return check, nil
+}
+
+func (s *backupVerificationService) checkLedger(ctx context.Context) (domain.IntegrityCheck, error) {
+	check := domain.IntegrityCheck{Name: domain.CheckLedgerBalanced}
+	imbalances, err := s.integrityRepo.ListCurrencyImbalances(ctx, s.dbExecutor)
+	if err != nil {
+		return check, err
+	}
+	check.Failures = int64(len(imbalances))
+	for _, imbalance := range imbalances {
+		check.Samples = append(check.Samples, fmt.Sprintf("%s: balances %s, net flows %s", imbalance.Currency, imbalance.Balances, imbalance.NetFlows))
+	}
+	return check, nil
+}
+
+func (s *backupVerificationService) checkWalletBalances(ctx context.Context) (domain.IntegrityCheck, error) {
+	check := domain.IntegrityCheck{Name: domain.CheckWalletBalances}
+	mismatches, total, err := s.integrityRepo.ListBalanceMismatches(ctx, s.dbExecutor, verificationSamples)
+	if err != nil {
+		return check, err
+	}
+	check.Failures = total
+	for _, mismatch := range mismatches {
+		check.Samples = append(check.Samples, fmt.Sprintf("wallet %s: balance %s %s, transactions sum to %s",
+			mismatch.WalletPublicID, mismatch.Balance, mismatch.Currency, mismatch.Expected))
+	}
+	return check, nil
+}
+
+func (s *backupVerificationService) checkForeignKeys(ctx context.Context) (domain.IntegrityCheck, error) {
+	check := domain.IntegrityCheck{Name: domain.CheckForeignKeys}
+	orphans, err := s.integrityRepo.CountOrphans(ctx, s.dbExecutor)
+	if err != nil {
+		return check, err
+	}
+	for _, orphan := range orphans {
+		if orphan.Rows > 0 {
+			check.Failures += orphan.Rows
+			check.Samples = append(check.Samples, fmt.Sprintf("%s: %d rows", orphan.Relation, orphan.Rows))
+		}
+	}
+	return check, nil
+}
+
+// SignReport sets the signature of a verification report: the HMAC-SHA256 of its JSON without the signature.
+func SignReport(report *domain.VerificationReport, key []byte) error {
+	signature, err := reportSignature(report, key)
+	if err != nil {
+		return err
+	}
+	report.Signature = signature
+	return nil
+}
+
+// VerifyReportSignature checks that a verification report was signed with key and not changed since. It
+// fails with ErrInvalidSignature otherwise.
+func VerifyReportSignature(report *domain.VerificationReport, key []byte) error {
+	expected, err := reportSignature(report, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(report.Signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func reportSignature(report *domain.VerificationReport, key []byte) (string, error) {
+	unsigned := *report
+	unsigned.Signature = ""
+	content, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode verification report: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
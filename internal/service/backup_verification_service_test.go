@@ -0,0 +1,121 @@
+// internal/service/backup_verification_service_test.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestBackupVerificationService tests the checks of a verification report and its signature.
+func TestBackupVerificationService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	key := []byte("drill-signing-key")
+
+	type mocks struct {
+		integrityRepo *MockIntegrityRepository
+		schemaRepo    *MockSchemaRepository
+		dbExecutor    *MockDBExecutor
+	}
+	newService := func(key []byte) (BackupVerificationService, mocks) {
+		m := mocks{
+			integrityRepo: new(MockIntegrityRepository),
+			schemaRepo:    new(MockSchemaRepository),
+			dbExecutor:    new(MockDBExecutor),
+		}
+		return NewBackupVerificationService(m.dbExecutor, m.integrityRepo, m.schemaRepo, 45, "restore-db/finflow", key, logger), m
+	}
+	healthy := func(ctx context.Context, m mocks) {
+		m.schemaRepo.On("GetSchemaVersion", ctx, m.dbExecutor).Return(uint64(45), false, nil).Once()
+		m.integrityRepo.On("ListCurrencyImbalances", ctx, m.dbExecutor).Return([]domain.CurrencyImbalance{}, nil).Once()
+		m.integrityRepo.On("ListBalanceMismatches", ctx, m.dbExecutor, verificationSamples).Return([]domain.BalanceMismatch{}, int64(0), nil).Once()
+		m.integrityRepo.On("CountOrphans", ctx, m.dbExecutor).Return([]domain.OrphanCount{{Relation: "wallets.user_id -> users"}}, nil).Once()
+	}
+
+	t.Run("HealthyBackupPassesAndIsSigned", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(key)
+		healthy(ctx, m)
+
+		report, err := service.Verify(ctx)
+
+		require.NoError(t, err)
+		assert.True(t, report.Passed)
+		assert.Equal(t, "restore-db/finflow", report.Database)
+		require.Len(t, report.Checks, 4)
+		for _, check := range report.Checks {
+			assert.True(t, check.Passed, check.Name)
+		}
+		assert.NotEmpty(t, report.Signature)
+		assert.NoError(t, VerifyReportSignature(report, key))
+	})
+
+	t.Run("BrokenInvariantsFailTheReport", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(key)
+		m.schemaRepo.On("GetSchemaVersion", ctx, m.dbExecutor).Return(uint64(44), false, nil).Once()
+		m.integrityRepo.On("ListCurrencyImbalances", ctx, m.dbExecutor).Return(nil, errors.New("canceling statement due to statement timeout")).Once()
+		m.integrityRepo.On("ListBalanceMismatches", ctx, m.dbExecutor, verificationSamples).Return([]domain.BalanceMismatch{
+			{WalletID: 7, WalletPublicID: uuid.New(), Currency: "EUR", Balance: decimal.NewFromInt(100), Expected: decimal.NewFromInt(90)},
+		}, int64(3), nil).Once()
+		m.integrityRepo.On("CountOrphans", ctx, m.dbExecutor).Return([]domain.OrphanCount{
+			{Relation: "wallets.user_id -> users", Rows: 2},
+			{Relation: "transactions.to_wallet_id -> wallets"},
+		}, nil).Once()
+
+		report, err := service.Verify(ctx)
+
+		require.NoError(t, err)
+		assert.False(t, report.Passed)
+		byName := map[string]domain.IntegrityCheck{}
+		for _, check := range report.Checks {
+			byName[check.Name] = check
+		}
+		assert.Equal(t, int64(1), byName[domain.CheckSchemaVersion].Failures)
+		assert.False(t, byName[domain.CheckLedgerBalanced].Passed)
+		assert.Contains(t, byName[domain.CheckLedgerBalanced].Error, "statement timeout")
+		assert.Equal(t, int64(3), byName[domain.CheckWalletBalances].Failures)
+		assert.Len(t, byName[domain.CheckWalletBalances].Samples, 1)
+		assert.Equal(t, int64(2), byName[domain.CheckForeignKeys].Failures)
+		assert.Equal(t, []string{"wallets.user_id -> users: 2 rows"}, byName[domain.CheckForeignKeys].Samples)
+	})
+
+	t.Run("TamperedReportFailsItsSignature", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(key)
+		healthy(ctx, m)
+		report, err := service.Verify(ctx)
+		require.NoError(t, err)
+
+		encoded, err := json.Marshal(report)
+		require.NoError(t, err)
+		var decoded domain.VerificationReport
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.NoError(t, VerifyReportSignature(&decoded, key), "the signature survives a JSON round trip")
+
+		decoded.Database = "prod-db/finflow"
+		assert.ErrorIs(t, VerifyReportSignature(&decoded, key), ErrInvalidSignature)
+		assert.ErrorIs(t, VerifyReportSignature(report, []byte("other-key")), ErrInvalidSignature)
+	})
+
+	t.Run("MissingSigningKeyIsRefused", func(t *testing.T) {
+		service, m := newService(nil)
+
+		_, err := service.Verify(context.Background())
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.integrityRepo.AssertNotCalled(t, "CountOrphans", mock.Anything, mock.Anything)
+	})
+}
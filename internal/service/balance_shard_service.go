@@ -0,0 +1,300 @@
+// internal/service/balance_shard_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// BalanceSharder spreads the balance updates of sharded wallets over their shards. It is the narrow view
+// the wallet service depends on.
+type BalanceSharder interface {
+	// UpdateShardedBalance adds amount to one of the wallet's shards in the transaction q, dated at, and returns
+	// the wallet's new balance. It returns nil if the wallet is not sharded, or no shard can take the amount, in
+	// which case the caller updates the wallet's own balance instead.
+	UpdateShardedBalance(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal, at time.Time) (*domain.WalletBalance, error)
+}
+
+// BalanceShardService manages the balance shards of high-throughput wallets.
+type BalanceShardService interface {
+	BalanceSharder
+	ListShards(ctx context.Context) ([]domain.WalletShards, error)
+	// SetShards splits the wallet's balance into shards sub-balances, or merges it back into the wallet's own
+	// balance for 0, and spreads the balance evenly over them. The change is attributed to updatedBy.
+	SetShards(ctx context.Context, walletID int64, shards int, updatedBy string) error
+	// Rebalance spreads the balance of every sharded wallet evenly over its shards again, and returns how many
+	// wallets it changed.
+	Rebalance(ctx context.Context) (int, error)
+}
+
+// balanceShardService implements BalanceShardService with an in-memory cache of the shard counts of the
+// sharded wallets, reloaded when older than the TTL and dropped on every change, like the feature flags.
+type balanceShardService struct {
+	dbBeginner db.DBTxBeginner
+	dbExecutor repository.DBExecutor
+	shardRepo  repository.BalanceShardRepository
+	beginTx    db.BeginTxFunc
+	commitTx   db.CommitTxFunc
+	rollbackTx db.RollbackTxFunc
+	ttl        time.Duration
+	logger     *slog.Logger
+
+	next atomic.Uint64 // Round-robin position of the next balance update
+
+	mu       sync.RWMutex
+	counts   map[int64]int
+	loadedAt time.Time
+}
+
+// NewBalanceShardService creates a new instance of BalanceShardService.
+func NewBalanceShardService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	shardRepo repository.BalanceShardRepository,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	ttl time.Duration,
+	logger *slog.Logger,
+) BalanceShardService {
+	return &balanceShardService{
+		dbBeginner: dbBeginner,
+		dbExecutor: dbExecutor,
+		shardRepo:  shardRepo,
+		beginTx:    beginTx,
+		commitTx:   commitTx,
+		rollbackTx: rollbackTx,
+		ttl:        ttl,
+		logger:     logger,
+	}
+}
+
+// UpdateShardedBalance takes the shards round-robin, skipping those locked by concurrent movements and, for a
+// debit, those that do not hold the amount. The wallet's total balance has been checked by the caller, so a
+// debit no single shard holds is left to the wallet's own balance, which may go negative until the next
+// rebalance.
+func (s *balanceShardService) UpdateShardedBalance(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal, at time.Time) (*domain.WalletBalance, error) {
+	counts, err := s.cachedCounts(ctx)
+	if err != nil {
+		s.logger.Error("Failed to refresh balance shards, using cached values", "error", err)
+	}
+	shards := counts[walletID]
+	if shards == 0 {
+		return nil, nil
+	}
+	start := int(s.next.Add(1) % uint64(shards))
+	balance, err := s.shardRepo.UpdateShardBalance(ctx, q, walletID, start, shards, amount, at)
+	if errors.Is(err, util.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// ListShards returns every sharded wallet with its shards, bypassing the cache.
+func (s *balanceShardService) ListShards(ctx context.Context) ([]domain.WalletShards, error) {
+	shards, err := s.shardRepo.ListShards(ctx, s.dbExecutor)
+	if err != nil {
+		return nil, fmt.Errorf("list balance shards: %w", err)
+	}
+	wallets := []domain.WalletShards{}
+	for _, shard := range shards {
+		if n := len(wallets); n == 0 || wallets[n-1].WalletID != shard.WalletID {
+			wallets = append(wallets, domain.WalletShards{WalletID: shard.WalletID, WalletPublicID: shard.WalletPublicID})
+		}
+		wallets[len(wallets)-1].Shards = append(wallets[len(wallets)-1].Shards, shard)
+	}
+	return wallets, nil
+}
+
+// SetShards resizes the wallet's shards and rebalances them in one transaction.
+func (s *balanceShardService) SetShards(ctx context.Context, walletID int64, shards int, updatedBy string) error {
+	if shards < 0 || shards == 1 || shards > domain.MaxBalanceShards {
+		return fmt.Errorf("%w: shards must be 0, to merge them, or from 2 to %d", util.ErrInvalidInput, domain.MaxBalanceShards)
+	}
+	if updatedBy == "" {
+		return util.ErrForbidden
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return fmt.Errorf("set balance shards: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return fmt.Errorf("set balance shards: transaction controller does not implement DBExecutor")
+	}
+
+	if _, _, err := s.shardRepo.LockShards(ctx, txExecutor, walletID); err != nil {
+		return fmt.Errorf("set balance shards of wallet %d: %w", walletID, err)
+	}
+	if err := s.shardRepo.ResizeShards(ctx, txExecutor, walletID, shards); err != nil {
+		return fmt.Errorf("set balance shards of wallet %d: %w", walletID, err)
+	}
+	if _, err := s.rebalance(ctx, txExecutor, walletID); err != nil {
+		return fmt.Errorf("set balance shards of wallet %d: %w", walletID, err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return fmt.Errorf("set balance shards: failed to commit transaction: %w", err)
+	}
+	s.invalidate()
+	s.logger.Info("Wallet balance shards set", "wallet_id", walletID, "shards", shards, "updated_by", updatedBy)
+	return nil
+}
+
+// Rebalance rebalances the sharded wallets one transaction each, so a wallet's shards are locked only while
+// its own are moved. Wallets that fail are logged and retried on the next run.
+func (s *balanceShardService) Rebalance(ctx context.Context) (int, error) {
+	wallets, err := s.ListShards(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("rebalance: %w", err)
+	}
+	rebalanced := 0
+	for _, wallet := range wallets {
+		changed, err := s.rebalanceWallet(ctx, wallet.WalletID)
+		if err != nil {
+			s.logger.Error("Failed to rebalance wallet balance shards", "wallet_id", wallet.WalletID, "error", err)
+			continue
+		}
+		if changed {
+			rebalanced++
+		}
+	}
+	if rebalanced > 0 {
+		s.logger.Info("Wallet balance shards rebalanced", "wallets", rebalanced)
+	}
+	return rebalanced, nil
+}
+
+func (s *balanceShardService) rebalanceWallet(ctx context.Context, walletID int64) (bool, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return false, fmt.Errorf("transaction controller does not implement DBExecutor")
+	}
+	changed, err := s.rebalance(ctx, txExecutor, walletID)
+	if err != nil || !changed {
+		return false, err
+	}
+	if err := s.commitTx(txController); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// rebalance locks the wallet and its shards and spreads its total balance over the shards, unless it already
+// is. It reports whether anything changed.
+func (s *balanceShardService) rebalance(ctx context.Context, q repository.DBExecutor, walletID int64) (bool, error) {
+	own, shards, err := s.shardRepo.LockShards(ctx, q, walletID)
+	if err != nil {
+		return false, err
+	}
+	if len(shards) == 0 {
+		return false, nil
+	}
+
+	total := own
+	var lastActivity time.Time
+	for _, shard := range shards {
+		total = total.Add(shard.Balance)
+		if shard.UpdatedAt.After(lastActivity) {
+			lastActivity = shard.UpdatedAt
+		}
+	}
+	spreadOwn, spread := spreadBalance(total, len(shards))
+
+	changed := !spreadOwn.Equal(own)
+	for i, shard := range shards {
+		changed = changed || !shard.Balance.Equal(spread[i])
+	}
+	if !changed {
+		return false, nil
+	}
+	if err := s.shardRepo.SetShardBalances(ctx, q, walletID, spreadOwn, spread, lastActivity); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// spreadBalance splits total evenly over n shards, to the places of domain.AmountScale, the first shards
+// taking the remainder. A negative total, e.g. of an overdrawn wallet, is left on the wallet's
+// own balance, so every shard stays non-negative.
+func spreadBalance(total decimal.Decimal, n int) (decimal.Decimal, []decimal.Decimal) {
+	shards := make([]decimal.Decimal, n)
+	if !total.IsPositive() {
+		for i := range shards {
+			shards[i] = decimal.Zero
+		}
+		return total, shards
+	}
+	scale := domain.AmountScale()
+	unit := decimal.New(1, -scale)
+	share := total.Div(decimal.NewFromInt(int64(n))).Truncate(scale)
+	rest := total.Sub(share.Mul(decimal.NewFromInt(int64(n))))
+	for i := range shards {
+		shards[i] = share
+		if rest.IsPositive() {
+			shards[i] = shards[i].Add(unit)
+			rest = rest.Sub(unit)
+		}
+	}
+	return decimal.Zero, shards
+}
+
+// cachedCounts returns the cached shard counts by wallet ID, reloading them once the cache has expired.
+// On a failed reload the stale counts are returned together with the error.
+func (s *balanceShardService) cachedCounts(ctx context.Context) (map[int64]int, error) {
+	s.mu.RLock()
+	counts, fresh := s.counts, s.counts != nil && time.Since(s.loadedAt) < s.ttl
+	s.mu.RUnlock()
+	if fresh {
+		return counts, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts != nil && time.Since(s.loadedAt) < s.ttl {
+		return s.counts, nil // Reloaded by a concurrent caller
+	}
+
+	shards, err := s.shardRepo.ListShards(ctx, s.dbExecutor)
+	if err != nil {
+		// Retry on the next movement rather than hammering the database from every request
+		s.loadedAt = time.Now()
+		return s.counts, err
+	}
+	s.counts = make(map[int64]int)
+	for _, shard := range shards {
+		s.counts[shard.WalletID]++
+	}
+	s.loadedAt = time.Now()
+	return s.counts, nil
+}
+
+func (s *balanceShardService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
@@ -0,0 +1,201 @@
+// internal/service/balance_shard_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestBalanceShards tests the spreading of the balance updates of sharded wallets over their shards.
+func TestBalanceShards(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	shards := []domain.BalanceShard{
+		{WalletID: 1, Shard: 0, Balance: decimal.NewFromInt(40)},
+		{WalletID: 1, Shard: 1, Balance: decimal.NewFromInt(10), UpdatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{WalletID: 1, Shard: 2, Balance: decimal.NewFromInt(10)},
+	}
+
+	type mocks struct {
+		shardRepo    *MockBalanceShardRepository
+		dbExecutor   *MockDBExecutor
+		txController *MockTxController
+	}
+	newService := func() (BalanceShardService, mocks) {
+		m := mocks{
+			shardRepo:    new(MockBalanceShardRepository),
+			dbExecutor:   new(MockDBExecutor),
+			txController: new(MockTxController),
+		}
+		m.txController.On("Rollback").Return(nil).Maybe()
+		service := NewBalanceShardService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.shardRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			time.Minute,
+			logger,
+		)
+		return service, m
+	}
+
+	t.Run("UpdatesTakeTheShardsRoundRobin", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		tx := new(MockDBExecutor)
+		amount := decimal.NewFromInt(5)
+		m.shardRepo.On("ListShards", mock.Anything, m.dbExecutor).Return(shards, nil).Once()
+		for _, start := range []int{1, 2, 0} {
+			m.shardRepo.On("UpdateShardBalance", ctx, tx, int64(1), start, 3, amount, mock.Anything).Return(&domain.WalletBalance{ID: 1}, nil).Once()
+		}
+
+		for range 3 {
+			balance, err := service.UpdateShardedBalance(ctx, tx, 1, amount, time.Now())
+			require.NoError(t, err)
+			assert.NotNil(t, balance)
+		}
+
+		m.shardRepo.AssertExpectations(t)
+	})
+
+	t.Run("UnshardedWalletIsLeftToTheCaller", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.shardRepo.On("ListShards", mock.Anything, m.dbExecutor).Return(shards, nil).Once()
+
+		balance, err := service.UpdateShardedBalance(ctx, new(MockDBExecutor), 2, decimal.NewFromInt(5), time.Now())
+
+		assert.NoError(t, err)
+		assert.Nil(t, balance)
+		m.shardRepo.AssertNotCalled(t, "UpdateShardBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DebitNoShardHoldsIsLeftToTheCaller", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		tx := new(MockDBExecutor)
+		m.shardRepo.On("ListShards", mock.Anything, m.dbExecutor).Return(shards, nil).Once()
+		m.shardRepo.On("UpdateShardBalance", ctx, tx, int64(1), mock.Anything, 3, decimal.NewFromInt(-50), mock.Anything).Return(nil, util.ErrNotFound).Once()
+
+		balance, err := service.UpdateShardedBalance(ctx, tx, 1, decimal.NewFromInt(-50), time.Now())
+
+		assert.NoError(t, err)
+		assert.Nil(t, balance)
+	})
+
+	t.Run("RebalanceSpreadsTheTotalEvenly", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.shardRepo.On("ListShards", mock.Anything, m.dbExecutor).Return(shards, nil).Once()
+		m.shardRepo.On("LockShards", ctx, m.txController, int64(1)).Return(decimal.RequireFromString("-9.99"), shards, nil).Once()
+		m.shardRepo.On("SetShardBalances", ctx, m.txController, int64(1), decimal.Zero, mock.MatchedBy(func(balances []decimal.Decimal) bool {
+			return len(balances) == 3 && balances[0].Equal(decimal.RequireFromString("16.67")) &&
+				balances[1].Equal(balances[0]) && balances[2].Equal(balances[0])
+		}), shards[1].UpdatedAt).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		rebalanced, err := service.Rebalance(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, rebalanced)
+		mock.AssertExpectationsForObjects(t, m.shardRepo, m.txController)
+	})
+
+	t.Run("BalancedWalletIsNotWritten", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		even := []domain.BalanceShard{{WalletID: 1, Shard: 0, Balance: decimal.NewFromInt(5)}, {WalletID: 1, Shard: 1, Balance: decimal.NewFromInt(5)}}
+		m.shardRepo.On("ListShards", mock.Anything, m.dbExecutor).Return(even, nil).Once()
+		m.shardRepo.On("LockShards", ctx, m.txController, int64(1)).Return(decimal.Zero, even, nil).Once()
+
+		rebalanced, err := service.Rebalance(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, rebalanced)
+		m.shardRepo.AssertNotCalled(t, "SetShardBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("SetShardsRejectsOneShard", func(t *testing.T) {
+		service, m := newService()
+
+		err := service.SetShards(context.Background(), 1, 1, "alice")
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.shardRepo.AssertNotCalled(t, "ResizeShards", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SetShardsRequiresNamedAdmin", func(t *testing.T) {
+		service, m := newService()
+
+		err := service.SetShards(context.Background(), 1, 4, "")
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		m.shardRepo.AssertNotCalled(t, "LockShards", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestSpreadBalance tests the even split of a sharded wallet's balance.
+func TestSpreadBalance(t *testing.T) {
+	own, shards := spreadBalance(decimal.RequireFromString("10.0002"), 4)
+	assert.True(t, own.IsZero())
+	assert.Equal(t, []string{"2.5001", "2.5001", "2.5", "2.5"}, []string{shards[0].String(), shards[1].String(), shards[2].String(), shards[3].String()})
+
+	own, shards = spreadBalance(decimal.NewFromInt(-3), 2)
+	assert.True(t, own.Equal(decimal.NewFromInt(-3)), "an overdrawn balance stays on the wallet")
+	assert.True(t, shards[0].IsZero() && shards[1].IsZero())
+}
+
+// shardEverything is a BalanceSharder for which every wallet is sharded.
+type shardEverything struct{ updated []int64 }
+
+func (s *shardEverything) UpdateShardedBalance(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal, at time.Time) (*domain.WalletBalance, error) {
+	s.updated = append(s.updated, walletID)
+	return &domain.WalletBalance{ID: walletID, Balance: decimal.NewFromInt(100).Add(amount), Version: 7}, nil
+}
+
+// TestDepositIntoShardedWallet tests that a deposit into a sharded wallet updates a shard, not the wallet's row.
+func TestDepositIntoShardedWallet(t *testing.T) {
+	ctx := context.Background()
+	walletRepo, transactionRepo, txController := new(MockWalletRepository), new(MockTransactionRepository), new(MockTxController)
+	sharder := &shardEverything{}
+	service := NewWalletService(
+		new(MockDBBeginner), new(MockDBExecutor), new(MockUserRepository), walletRepo, transactionRepo,
+		func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return txController, nil },
+		func(tx db.TxController) error { return txController.Commit() },
+		func(tx db.TxController) { _ = txController.Rollback() },
+		WithBalanceSharder(sharder),
+	)
+	txController.On("Commit").Return(nil).Once()
+	txController.On("Rollback").Return(nil).Maybe()
+	walletRepo.On("GetWalletByID", ctx, txController, int64(1)).Return(&domain.Wallet{ID: 1, Currency: "USD", Balance: decimal.NewFromInt(100)}, nil).Once()
+	transactionRepo.On("CreateTransaction", ctx, txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+
+	wallet, _, err := service.Deposit(ctx, 1, decimal.NewFromInt(5), "USD")
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, sharder.updated)
+	assert.True(t, wallet.Balance.Equal(decimal.NewFromInt(105)))
+	assert.Equal(t, int64(7), wallet.Version)
+	walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
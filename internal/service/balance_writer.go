@@ -0,0 +1,119 @@
+// internal/service/balance_writer.go
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/clock"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// BalanceWriter applies the balance changes of money movements and dates their transactions. Every service
+// moving money goes through it, so a sharded wallet is updated on one of its shards, money entering the
+// platform is checked against the currency restrictions, and the movement is dated by the service clock,
+// whichever service moves the money.
+type BalanceWriter struct {
+	walletRepo      repository.WalletRepository
+	sharder         BalanceSharder                           // Optional; spreads the balance updates of sharded wallets
+	restrictionRepo repository.CurrencyRestrictionRepository // Optional; enables residency restrictions of funding
+	clock           clock.Clock
+}
+
+// NewBalanceWriter creates a BalanceWriter. Without a sharder every balance is updated on the wallet's row,
+// without a restriction repository no funding is restricted, and without a clock movements are dated by the
+// system clock.
+func NewBalanceWriter(walletRepo repository.WalletRepository, sharder BalanceSharder, restrictionRepo repository.CurrencyRestrictionRepository, clk clock.Clock) *BalanceWriter {
+	if clk == nil {
+		clk = clock.System{}
+	}
+	return &BalanceWriter{walletRepo: walletRepo, sharder: sharder, restrictionRepo: restrictionRepo, clock: clk}
+}
+
+// Now returns the time of the writer's clock in UTC.
+func (b *BalanceWriter) Now() time.Time {
+	return b.clock.Now().UTC()
+}
+
+// Stamp dates the transactions at the time of the writer's clock.
+func (b *BalanceWriter) Stamp(transactions ...*domain.Transaction) {
+	now := b.Now()
+	for _, transaction := range transactions {
+		transaction.TransactionTime, transaction.CreatedAt = now, now
+	}
+}
+
+// CheckFunding returns util.ErrCurrencyRestricted if the wallet's currency is restricted for its owner's
+// residency, so money from outside the platform may not be credited to it.
+func (b *BalanceWriter) CheckFunding(ctx context.Context, q repository.DBExecutor, wallet *domain.Wallet) error {
+	return checkFundingRestriction(ctx, q, b.restrictionRepo, wallet.Currency, wallet.UserID)
+}
+
+// Credit adds money entering the platform, e.g. a deposit, voucher or inbound payment, to the wallet's balance
+// like UpdateBalance, after checking it with CheckFunding.
+func (b *BalanceWriter) Credit(ctx context.Context, q repository.DBExecutor, wallet *domain.Wallet, amount decimal.Decimal) (*domain.WalletBalance, error) {
+	if err := b.CheckFunding(ctx, q, wallet); err != nil {
+		return nil, err
+	}
+	balance, err := b.UpdateBalance(ctx, q, wallet.ID, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update wallet balance: %w", err)
+	}
+	return balance, nil
+}
+
+// UpdateBalance adds amount to the wallet's balance, on one of its shards if it is sharded, dating the update
+// by the writer's clock.
+func (b *BalanceWriter) UpdateBalance(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal) (*domain.WalletBalance, error) {
+	now := b.Now()
+	if b.sharder != nil {
+		balance, err := b.sharder.UpdateShardedBalance(ctx, q, walletID, amount, now)
+		if err != nil || balance != nil {
+			return balance, err
+		}
+	}
+	return b.walletRepo.UpdateWalletBalance(ctx, q, walletID, amount, now)
+}
+
+// UpdateBalances adds each amount to its wallet's balance, like UpdateWalletBalances, updating sharded wallets
+// on one of their shards. wallets holds the wallets as read before the update, and the updated wallets are
+// returned in ascending ID order.
+func (b *BalanceWriter) UpdateBalances(ctx context.Context, q repository.DBExecutor, wallets []domain.Wallet, amounts map[int64]decimal.Decimal) ([]domain.Wallet, error) {
+	now := b.Now()
+	if b.sharder == nil {
+		return b.walletRepo.UpdateWalletBalances(ctx, q, amounts, now)
+	}
+	var updated []domain.Wallet
+	unsharded := make(map[int64]decimal.Decimal, len(amounts))
+	for walletID, amount := range amounts {
+		wallet := findWallet(wallets, walletID)
+		if wallet == nil {
+			unsharded[walletID] = amount
+			continue
+		}
+		balance, err := b.sharder.UpdateShardedBalance(ctx, q, walletID, amount, now)
+		if err != nil {
+			return nil, err
+		}
+		if balance == nil {
+			unsharded[walletID] = amount
+			continue
+		}
+		updated = append(updated, *wallet.WithBalance(balance))
+	}
+	if len(unsharded) > 0 {
+		rest, err := b.walletRepo.UpdateWalletBalances(ctx, q, unsharded, now)
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, rest...)
+	}
+	slices.SortFunc(updated, func(a, b domain.Wallet) int { return cmp.Compare(a.ID, b.ID) })
+	return updated, nil
+}
@@ -0,0 +1,372 @@
+// internal/service/bill_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// BillService defines the interface for shared bills: an amount owed to one wallet, split between
+// participant wallets that each approve their share and pay it off with transfers.
+type BillService interface {
+	// CreateBill splits a bill owed to the owner wallet between the legs' wallets, by fixed amounts or shares.
+	CreateBill(ctx context.Context, owner *domain.Wallet, split domain.Split, description *string) (*domain.Bill, error)
+	GetBill(ctx context.Context, publicID uuid.UUID) (*domain.Bill, error)
+	ListBills(ctx context.Context, walletID int64) ([]domain.Bill, error)
+	// ApproveShare records that a participant accepts their share of the bill.
+	ApproveShare(ctx context.Context, publicID uuid.UUID, walletID int64) (*domain.Bill, error)
+	// PayShare transfers amount, or the whole outstanding share if amount is zero, from a participant to the owner.
+	PayShare(ctx context.Context, publicID uuid.UUID, walletID int64, amount decimal.Decimal) (*domain.Bill, *domain.Transaction, error)
+	CancelBill(ctx context.Context, publicID uuid.UUID) (*domain.Bill, error)
+	// SendReminders reminds every participant who still owes money and was not reminded within the reminder
+	// interval. It returns the number of reminders sent.
+	SendReminders(ctx context.Context, now time.Time) (int, error)
+}
+
+// billService implements BillService.
+type billService struct {
+	dbBeginner       db.DBTxBeginner
+	dbExecutor       repository.DBExecutor
+	billRepo         repository.BillRepository
+	walletRepo       repository.WalletRepository
+	balances         *BalanceWriter // Applies balance changes, on a shard for sharded wallets
+	transactionRepo  repository.TransactionRepository
+	reminderInterval time.Duration      // Minimum time between two reminders to the same participant
+	events           *TransactionEvents // Optional; payments are published here
+	beginTx          db.BeginTxFunc
+	commitTx         db.CommitTxFunc
+	rollbackTx       db.RollbackTxFunc
+	logger           *slog.Logger
+}
+
+// NewBillService creates a new instance of BillService.
+func NewBillService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	billRepo repository.BillRepository,
+	walletRepo repository.WalletRepository,
+	balances *BalanceWriter,
+	transactionRepo repository.TransactionRepository,
+	reminderInterval time.Duration,
+	events *TransactionEvents,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) BillService {
+	return &billService{
+		dbBeginner:       dbBeginner,
+		dbExecutor:       dbExecutor,
+		billRepo:         billRepo,
+		walletRepo:       walletRepo,
+		balances:         balances,
+		transactionRepo:  transactionRepo,
+		reminderInterval: reminderInterval,
+		events:           events,
+		beginTx:          beginTx,
+		commitTx:         commitTx,
+		rollbackTx:       rollbackTx,
+		logger:           logger,
+	}
+}
+
+// CreateBill resolves the split into one share per participant and stores the bill with its participants.
+// Participants must hold the bill's currency and may not include the owner or appear twice.
+func (s *billService) CreateBill(ctx context.Context, owner *domain.Wallet, split domain.Split, description *string) (*domain.Bill, error) {
+	if split.Currency != owner.Currency {
+		return nil, util.ErrCurrencyMismatch
+	}
+	total, amounts, err := split.Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", util.ErrInvalidInput, err)
+	}
+
+	now := time.Now().UTC()
+	bill := &domain.Bill{
+		PublicID:            uuid.New(),
+		OwnerWalletID:       owner.ID,
+		OwnerWalletPublicID: owner.PublicID,
+		Amount:              total,
+		Currency:            owner.Currency,
+		Description:         description,
+		Status:              domain.BillStatusOpen,
+		Participants:        make([]domain.BillParticipant, 0, len(split.Legs)),
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	for i, leg := range split.Legs {
+		if leg.ToWalletID == owner.ID {
+			return nil, fmt.Errorf("%w: the owner wallet cannot take part in its own bill", util.ErrInvalidInput)
+		}
+		if bill.Participant(leg.ToWalletID) != nil {
+			return nil, fmt.Errorf("%w: wallet %d appears twice in the bill", util.ErrInvalidInput, leg.ToWalletID)
+		}
+		wallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, leg.ToWalletID)
+		if err != nil {
+			if util.IsError(err, util.ErrNotFound) {
+				return nil, util.ErrWalletNotFound
+			}
+			return nil, fmt.Errorf("create bill: %w", err)
+		}
+		if wallet.Currency != bill.Currency {
+			return nil, util.ErrCurrencyMismatch
+		}
+		bill.Participants = append(bill.Participants, domain.BillParticipant{
+			BillPublicID:   bill.PublicID,
+			WalletID:       wallet.ID,
+			WalletPublicID: wallet.PublicID,
+			ShareAmount:    amounts[i],
+			PaidAmount:     decimal.Zero,
+			Status:         domain.ParticipantStatusPending,
+			UpdatedAt:      now,
+		})
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("create bill: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("create bill: transaction controller does not implement DBExecutor")
+	}
+	if err := s.billRepo.CreateBill(ctx, txExecutor, bill); err != nil {
+		return nil, fmt.Errorf("create bill: %w", err)
+	}
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("create bill: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Bill created", "bill_id", bill.PublicID, "owner_wallet_id", owner.ID, "amount", total.String(),
+		"participants", len(bill.Participants))
+	return bill, nil
+}
+
+// GetBill retrieves a bill and its participants by the bill's public ID.
+func (s *billService) GetBill(ctx context.Context, publicID uuid.UUID) (*domain.Bill, error) {
+	bill, err := s.billRepo.GetBillByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get bill %s: %w", publicID, err)
+	}
+	return bill, nil
+}
+
+// ListBills retrieves the bills a wallet owns or takes part in.
+func (s *billService) ListBills(ctx context.Context, walletID int64) ([]domain.Bill, error) {
+	bills, err := s.billRepo.ListBillsByWallet(ctx, s.dbExecutor, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("list bills: %w", err)
+	}
+	return bills, nil
+}
+
+// ApproveShare moves a PENDING participant to APPROVED. Approving an already approved share is a no-op.
+func (s *billService) ApproveShare(ctx context.Context, publicID uuid.UUID, walletID int64) (*domain.Bill, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("approve bill share: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("approve bill share: transaction controller does not implement DBExecutor")
+	}
+
+	bill, participant, err := s.lockParticipant(ctx, txExecutor, publicID, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("approve bill share: %w", err)
+	}
+	if participant.Status != domain.ParticipantStatusPending {
+		return bill, nil
+	}
+
+	now := time.Now().UTC()
+	participant.Status = domain.ParticipantStatusApproved
+	participant.ApprovedAt = &now
+	participant.UpdatedAt = now
+	if err := s.billRepo.UpdateParticipant(ctx, txExecutor, participant); err != nil {
+		return nil, fmt.Errorf("approve bill share: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("approve bill share: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Bill share approved", "bill_id", bill.PublicID, "wallet_id", walletID)
+	return bill, nil
+}
+
+// PayShare transfers a payment from an approved participant to the owner wallet and records it against the
+// participant's share, all in one database transaction with the bill locked. A share is PAID once nothing is
+// outstanding, and the bill is SETTLED once every share is paid. Payments may not exceed the outstanding amount.
+func (s *billService) PayShare(ctx context.Context, publicID uuid.UUID, walletID int64, amount decimal.Decimal) (*domain.Bill, *domain.Transaction, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pay bill share: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, nil, fmt.Errorf("pay bill share: transaction controller does not implement DBExecutor")
+	}
+
+	bill, participant, err := s.lockParticipant(ctx, txExecutor, publicID, walletID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pay bill share: %w", err)
+	}
+	switch participant.Status {
+	case domain.ParticipantStatusPending:
+		return nil, nil, util.ErrBillNotApproved
+	case domain.ParticipantStatusPaid:
+		return nil, nil, fmt.Errorf("%w: the share is already paid", util.ErrInvalidInput)
+	}
+	outstanding := participant.Outstanding()
+	if amount.IsZero() {
+		amount = outstanding
+	}
+	if !amount.IsPositive() || amount.GreaterThan(outstanding) {
+		return nil, nil, fmt.Errorf("%w: amount must be positive and at most the outstanding %s", util.ErrInvalidInput, outstanding.String())
+	}
+	if !amount.Equal(amount.Truncate(domain.CurrencyPrecision(bill.Currency))) {
+		return nil, nil, fmt.Errorf("%w: amount has more decimals than the currency allows", util.ErrInvalidInput)
+	}
+
+	wallets, err := lockWallets(ctx, s.walletRepo, txExecutor, walletID, bill.OwnerWalletID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, nil, util.ErrWalletNotFound
+		}
+		return nil, nil, fmt.Errorf("pay bill share: %w", err)
+	}
+	if err := checkNotFrozen(wallets[walletID]); err != nil {
+		return nil, nil, fmt.Errorf("pay bill share: %w", err)
+	}
+	if wallets[walletID].Balance.LessThan(amount) {
+		return nil, nil, util.ErrInsufficientFunds
+	}
+
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, walletID, amount.Neg()); err != nil {
+		return nil, nil, fmt.Errorf("pay bill share: failed to update participant wallet balance: %w", err)
+	}
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, bill.OwnerWalletID, amount); err != nil {
+		return nil, nil, fmt.Errorf("pay bill share: failed to update owner wallet balance: %w", err)
+	}
+	description := fmt.Sprintf("Bill %s", bill.PublicID)
+	transaction := domain.NewTransaction(&walletID, &bill.OwnerWalletID, amount, bill.Currency, domain.TransactionTypeTransfer, &description)
+	s.balances.Stamp(transaction)
+	transaction.Category = transactionCategory(ctx)
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, nil, fmt.Errorf("pay bill share: failed to create transaction: %w", err)
+	}
+
+	now := time.Now().UTC()
+	participant.PaidAmount = participant.PaidAmount.Add(amount)
+	if participant.Outstanding().IsZero() {
+		participant.Status = domain.ParticipantStatusPaid
+	}
+	participant.UpdatedAt = now
+	if err := s.billRepo.UpdateParticipant(ctx, txExecutor, participant); err != nil {
+		return nil, nil, fmt.Errorf("pay bill share: %w", err)
+	}
+	if bill.PaidAmount().Equal(bill.Amount) {
+		bill.Status = domain.BillStatusSettled
+		bill.UpdatedAt = now
+		if err := s.billRepo.UpdateBillStatus(ctx, txExecutor, bill); err != nil {
+			return nil, nil, fmt.Errorf("pay bill share: %w", err)
+		}
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, nil, fmt.Errorf("pay bill share: failed to commit transaction: %w", err)
+	}
+	if s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
+	s.logger.Info("Bill share paid", "bill_id", bill.PublicID, "wallet_id", walletID, "amount", amount.String(),
+		"outstanding", participant.Outstanding().String(), "bill_status", bill.Status)
+	return bill, transaction, nil
+}
+
+// CancelBill withdraws an open bill. Payments already made stay with the owner.
+func (s *billService) CancelBill(ctx context.Context, publicID uuid.UUID) (*domain.Bill, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("cancel bill: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("cancel bill: transaction controller does not implement DBExecutor")
+	}
+
+	bill, err := s.billRepo.GetBillByPublicIDForUpdate(ctx, txExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("cancel bill %s: %w", publicID, err)
+	}
+	if bill.Status != domain.BillStatusOpen {
+		return nil, util.ErrBillNotOpen
+	}
+	bill.Status = domain.BillStatusCancelled
+	bill.UpdatedAt = time.Now().UTC()
+	if err := s.billRepo.UpdateBillStatus(ctx, txExecutor, bill); err != nil {
+		return nil, fmt.Errorf("cancel bill %s: %w", publicID, err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("cancel bill: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Bill cancelled", "bill_id", bill.PublicID, "paid_amount", bill.PaidAmount().String())
+	return bill, nil
+}
+
+// SendReminders logs a reminder for every participant that is due one and records the time, so each
+// participant is reminded at most once per reminder interval. A reminder that cannot be recorded is
+// logged and retried on the next run.
+func (s *billService) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	now = now.UTC()
+	due, err := s.billRepo.ListDueReminders(ctx, s.dbExecutor, now.Add(-s.reminderInterval))
+	if err != nil {
+		return 0, fmt.Errorf("send bill reminders: %w", err)
+	}
+
+	sent := 0
+	for _, participant := range due {
+		if err := s.billRepo.MarkReminded(ctx, s.dbExecutor, participant.BillID, participant.WalletID, now); err != nil {
+			s.logger.Warn("Bill reminder failed", "bill_id", participant.BillPublicID, "wallet_id", participant.WalletID, "error", err)
+			continue
+		}
+		s.logger.Info("Bill payment reminder", "bill_id", participant.BillPublicID, "wallet_id", participant.WalletPublicID,
+			"status", participant.Status, "outstanding", participant.Outstanding().String())
+		sent++
+	}
+	return sent, nil
+}
+
+// lockParticipant locks an open bill and returns it with the participant paying from walletID.
+func (s *billService) lockParticipant(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID, walletID int64) (*domain.Bill, *domain.BillParticipant, error) {
+	bill, err := s.billRepo.GetBillByPublicIDForUpdate(ctx, q, publicID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bill.Status != domain.BillStatusOpen {
+		return nil, nil, util.ErrBillNotOpen
+	}
+	participant := bill.Participant(walletID)
+	if participant == nil {
+		return nil, nil, fmt.Errorf("%w: wallet %d is not a participant of bill %s", util.ErrForbidden, walletID, publicID)
+	}
+	return bill, participant, nil
+}
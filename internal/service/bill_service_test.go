@@ -0,0 +1,230 @@
+// internal/service/bill_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestBillService tests bill creation, share approval, partial and final payments, and reminders.
+func TestBillService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ownerWallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Balance: decimal.NewFromInt(0)}
+	aliceWallet := &domain.Wallet{ID: 2, PublicID: uuid.New(), UserID: 20, Currency: "USD", Balance: decimal.NewFromInt(100)}
+	bobWallet := &domain.Wallet{ID: 3, PublicID: uuid.New(), UserID: 30, Currency: "USD", Balance: decimal.NewFromInt(100)}
+
+	type mocks struct {
+		dbExecutor      *MockDBExecutor
+		billRepo        *MockBillRepository
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		txController    *MockTxController
+	}
+	newService := func() (BillService, mocks) {
+		m := mocks{
+			dbExecutor:      new(MockDBExecutor),
+			billRepo:        new(MockBillRepository),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			txController:    new(MockTxController),
+		}
+		service := NewBillService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.billRepo,
+			m.walletRepo,
+			NewBalanceWriter(m.walletRepo, nil, nil, nil),
+			m.transactionRepo,
+			24*time.Hour,
+			nil,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+	openBill := func(aliceStatus domain.ParticipantStatus, alicePaid decimal.Decimal) *domain.Bill {
+		return &domain.Bill{
+			ID:            5,
+			PublicID:      uuid.New(),
+			OwnerWalletID: ownerWallet.ID,
+			Amount:        decimal.NewFromInt(30),
+			Currency:      "USD",
+			Status:        domain.BillStatusOpen,
+			Participants: []domain.BillParticipant{
+				{BillID: 5, WalletID: aliceWallet.ID, ShareAmount: decimal.NewFromInt(20), PaidAmount: alicePaid, Status: aliceStatus},
+				{BillID: 5, WalletID: bobWallet.ID, ShareAmount: decimal.NewFromInt(10), PaidAmount: decimal.NewFromInt(10), Status: domain.ParticipantStatusPaid},
+			},
+		}
+	}
+
+	t.Run("CreateBillSplitsByShare", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		split := domain.Split{Currency: "USD", Total: decimal.RequireFromString("25.01"), Legs: []domain.SplitLeg{
+			{ToWalletID: aliceWallet.ID, Share: decimal.NewFromInt(50)},
+			{ToWalletID: bobWallet.ID, Share: decimal.NewFromInt(50)},
+		}}
+
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, aliceWallet.ID).Return(aliceWallet, nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, bobWallet.ID).Return(bobWallet, nil).Once()
+		m.billRepo.On("CreateBill", ctx, m.txController, mock.AnythingOfType("*domain.Bill")).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		bill, err := service.CreateBill(ctx, ownerWallet, split, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BillStatusOpen, bill.Status)
+		assert.True(t, bill.Participants[0].ShareAmount.Equal(decimal.RequireFromString("12.51")))
+		assert.True(t, bill.Participants[1].ShareAmount.Equal(decimal.RequireFromString("12.50")))
+		assert.Equal(t, domain.ParticipantStatusPending, bill.Participants[0].Status)
+		mock.AssertExpectationsForObjects(t, m.walletRepo, m.billRepo, m.txController)
+	})
+
+	t.Run("CreateBillRejectsOwnerAsParticipant", func(t *testing.T) {
+		service, m := newService()
+		split := domain.Split{Currency: "USD", Legs: []domain.SplitLeg{
+			{ToWalletID: ownerWallet.ID, Amount: decimal.NewFromInt(5)},
+			{ToWalletID: aliceWallet.ID, Amount: decimal.NewFromInt(5)},
+		}}
+
+		_, err := service.CreateBill(context.Background(), ownerWallet, split, nil)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.billRepo.AssertNotCalled(t, "CreateBill", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PayShareRequiresApproval", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		bill := openBill(domain.ParticipantStatusPending, decimal.Zero)
+
+		m.billRepo.On("GetBillByPublicIDForUpdate", ctx, m.txController, bill.PublicID).Return(bill, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := service.PayShare(ctx, bill.PublicID, aliceWallet.ID, decimal.Zero)
+
+		assert.ErrorIs(t, err, util.ErrBillNotApproved)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, m.billRepo, m.txController)
+	})
+
+	t.Run("PartialPaymentKeepsBillOpen", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		bill := openBill(domain.ParticipantStatusApproved, decimal.Zero)
+		amount := decimal.NewFromInt(5)
+
+		m.billRepo.On("GetBillByPublicIDForUpdate", ctx, m.txController, bill.PublicID).Return(bill, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, ownerWallet.ID).Return(ownerWallet, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, aliceWallet.ID).Return(aliceWallet, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, aliceWallet.ID, amount.Neg(), mock.Anything).Return(nil, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, ownerWallet.ID, amount, mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeTransfer && tx.Amount.Equal(amount)
+		})).Return(nil).Once()
+		m.billRepo.On("UpdateParticipant", ctx, m.txController, &bill.Participants[0]).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		paid, _, err := service.PayShare(ctx, bill.PublicID, aliceWallet.ID, amount)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BillStatusOpen, paid.Status)
+		assert.Equal(t, domain.ParticipantStatusApproved, paid.Participants[0].Status)
+		assert.True(t, paid.Participants[0].Outstanding().Equal(decimal.NewFromInt(15)))
+		m.billRepo.AssertNotCalled(t, "UpdateBillStatus", mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, m.billRepo, m.walletRepo, m.transactionRepo, m.txController)
+	})
+
+	t.Run("FinalPaymentSettlesBill", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		bill := openBill(domain.ParticipantStatusApproved, decimal.NewFromInt(5))
+		outstanding := decimal.NewFromInt(15)
+
+		m.billRepo.On("GetBillByPublicIDForUpdate", ctx, m.txController, bill.PublicID).Return(bill, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, mock.AnythingOfType("int64")).Return(aliceWallet, nil).Twice()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, aliceWallet.ID, outstanding.Neg(), mock.Anything).Return(nil, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, ownerWallet.ID, outstanding, mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		m.billRepo.On("UpdateParticipant", ctx, m.txController, &bill.Participants[0]).Return(nil).Once()
+		m.billRepo.On("UpdateBillStatus", ctx, m.txController, bill).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		paid, _, err := service.PayShare(ctx, bill.PublicID, aliceWallet.ID, decimal.Zero)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BillStatusSettled, paid.Status)
+		assert.Equal(t, domain.ParticipantStatusPaid, paid.Participants[0].Status)
+		mock.AssertExpectationsForObjects(t, m.billRepo, m.walletRepo, m.transactionRepo, m.txController)
+	})
+
+	t.Run("OverpaymentRejected", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		bill := openBill(domain.ParticipantStatusApproved, decimal.NewFromInt(15))
+
+		m.billRepo.On("GetBillByPublicIDForUpdate", ctx, m.txController, bill.PublicID).Return(bill, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := service.PayShare(ctx, bill.PublicID, aliceWallet.ID, decimal.NewFromInt(6))
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.walletRepo.AssertNotCalled(t, "GetWalletByIDForUpdate", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NonParticipantForbidden", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		bill := openBill(domain.ParticipantStatusApproved, decimal.Zero)
+
+		m.billRepo.On("GetBillByPublicIDForUpdate", ctx, m.txController, bill.PublicID).Return(bill, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, err := service.ApproveShare(ctx, bill.PublicID, ownerWallet.ID)
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+	})
+
+	t.Run("SendRemindersMarksDueParticipants", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		now := time.Date(2025, time.May, 6, 12, 0, 0, 0, time.UTC)
+		due := []domain.BillParticipant{
+			{BillID: 5, WalletID: aliceWallet.ID, ShareAmount: decimal.NewFromInt(20), Status: domain.ParticipantStatusPending},
+			{BillID: 6, WalletID: bobWallet.ID, ShareAmount: decimal.NewFromInt(10), Status: domain.ParticipantStatusApproved},
+		}
+
+		m.billRepo.On("ListDueReminders", ctx, m.dbExecutor, now.Add(-24*time.Hour)).Return(due, nil).Once()
+		m.billRepo.On("MarkReminded", ctx, m.dbExecutor, int64(5), aliceWallet.ID, now).Return(nil).Once()
+		m.billRepo.On("MarkReminded", ctx, m.dbExecutor, int64(6), bobWallet.ID, now).Return(util.ErrConcurrentUpdate).Once()
+
+		sent, err := service.SendReminders(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		mock.AssertExpectationsForObjects(t, m.billRepo)
+	})
+}
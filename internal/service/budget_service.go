@@ -0,0 +1,214 @@
+// internal/service/budget_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+type transactionCategoryKey struct{}
+
+type budgetOverrideKey struct{}
+
+// WithTransactionCategory attaches a spending category to ctx; transactions created with it are tagged
+// with the category and count against the wallet's budgets for that category.
+func WithTransactionCategory(ctx context.Context, category string) context.Context {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, transactionCategoryKey{}, category)
+}
+
+// transactionCategory returns the category carried in ctx, or nil.
+func transactionCategory(ctx context.Context) *string {
+	category, ok := ctx.Value(transactionCategoryKey{}).(string)
+	if !ok {
+		return nil
+	}
+	return &category
+}
+
+// WithBudgetOverride marks the spending in ctx as explicitly allowed past SOFT_BLOCK budgets.
+func WithBudgetOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, budgetOverrideKey{}, true)
+}
+
+// budgetOverridden reports whether ctx carries WithBudgetOverride.
+func budgetOverridden(ctx context.Context) bool {
+	overridden, _ := ctx.Value(budgetOverrideKey{}).(bool)
+	return overridden
+}
+
+// BudgetService defines the interface for managing spending budgets and reporting their consumption.
+// Subscribed to TransactionEvents, it also raises the alerts of ALERT budgets.
+type BudgetService interface {
+	TransactionListener
+	CreateBudget(ctx context.Context, budget *domain.Budget) error
+	ListBudgets(ctx context.Context, walletID int64) ([]domain.Budget, error)
+	DeleteBudget(ctx context.Context, walletID, budgetID int64) error
+	// BudgetStatuses reports how much of each of the wallet's budgets is consumed in the period containing now.
+	BudgetStatuses(ctx context.Context, walletID int64, now time.Time) ([]domain.BudgetStatus, error)
+}
+
+// budgetService implements BudgetService.
+type budgetService struct {
+	dbExecutor      repository.DBExecutor
+	budgetRepo      repository.BudgetRepository
+	transactionRepo repository.TransactionRepository
+	notifier        Notifier // Optional; alerts are always logged
+	logger          *slog.Logger
+}
+
+// NewBudgetService creates a new instance of BudgetService.
+func NewBudgetService(
+	dbExecutor repository.DBExecutor,
+	budgetRepo repository.BudgetRepository,
+	transactionRepo repository.TransactionRepository,
+	notifier Notifier,
+	logger *slog.Logger,
+) BudgetService {
+	return &budgetService{
+		dbExecutor:      dbExecutor,
+		budgetRepo:      budgetRepo,
+		transactionRepo: transactionRepo,
+		notifier:        notifier,
+		logger:          logger,
+	}
+}
+
+// CreateBudget validates and stores a new budget. Categories are matched case-insensitively.
+func (s *budgetService) CreateBudget(ctx context.Context, budget *domain.Budget) error {
+	switch budget.Period {
+	case domain.BudgetPeriodDaily, domain.BudgetPeriodWeekly, domain.BudgetPeriodMonthly:
+	default:
+		return fmt.Errorf("%w: period must be %s, %s or %s", util.ErrInvalidInput,
+			domain.BudgetPeriodDaily, domain.BudgetPeriodWeekly, domain.BudgetPeriodMonthly)
+	}
+	if budget.Enforcement == "" {
+		budget.Enforcement = domain.BudgetEnforcementNone
+	}
+	switch budget.Enforcement {
+	case domain.BudgetEnforcementNone, domain.BudgetEnforcementAlert, domain.BudgetEnforcementSoftBlock:
+	default:
+		return fmt.Errorf("%w: enforcement must be %s, %s or %s", util.ErrInvalidInput,
+			domain.BudgetEnforcementNone, domain.BudgetEnforcementAlert, domain.BudgetEnforcementSoftBlock)
+	}
+	if !budget.Limit.IsPositive() {
+		return fmt.Errorf("%w: limit must be positive", util.ErrInvalidInput)
+	}
+	if budget.Category != nil {
+		budget.Category = transactionCategory(WithTransactionCategory(ctx, *budget.Category))
+	}
+
+	now := time.Now().UTC()
+	budget.CreatedAt = now
+	budget.UpdatedAt = now
+	if err := s.budgetRepo.CreateBudget(ctx, s.dbExecutor, budget); err != nil {
+		return fmt.Errorf("create budget: %w", err)
+	}
+	s.logger.Info("Budget created", "budget_id", budget.ID, "wallet_id", budget.WalletID, "period", budget.Period)
+	return nil
+}
+
+// ListBudgets retrieves all budgets of a wallet.
+func (s *budgetService) ListBudgets(ctx context.Context, walletID int64) ([]domain.Budget, error) {
+	budgets, err := s.budgetRepo.ListBudgetsByWalletID(ctx, s.dbExecutor, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("list budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+// DeleteBudget removes a wallet's budget.
+func (s *budgetService) DeleteBudget(ctx context.Context, walletID, budgetID int64) error {
+	if err := s.budgetRepo.DeleteBudget(ctx, s.dbExecutor, walletID, budgetID); err != nil {
+		return fmt.Errorf("delete budget %d: %w", budgetID, err)
+	}
+	s.logger.Info("Budget deleted", "budget_id", budgetID, "wallet_id", walletID)
+	return nil
+}
+
+// BudgetStatuses computes the consumption of each budget from the wallet's outgoing transactions
+// in the budget's current period, so a new period starts from zero without any rollover job.
+// Periods follow the time zone of the wallet's owner.
+func (s *budgetService) BudgetStatuses(ctx context.Context, walletID int64, now time.Time) ([]domain.BudgetStatus, error) {
+	budgets, err := s.budgetRepo.ListBudgetsByWalletID(ctx, s.dbExecutor, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("budget statuses: %w", err)
+	}
+	statuses := make([]domain.BudgetStatus, 0, len(budgets))
+	for _, budget := range budgets {
+		start, end := budget.Period.Window(now, budget.Location())
+		spent, err := s.transactionRepo.SumOutgoingAmount(ctx, s.dbExecutor, walletID, budget.Category, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("budget statuses: %w", err)
+		}
+		statuses = append(statuses, domain.NewBudgetStatus(budget, start, end, spent))
+	}
+	return statuses, nil
+}
+
+// OnTransaction raises an alert for every ALERT budget the committed transaction took over its limit.
+// Only the crossing transaction alerts; later spending in the same period stays quiet.
+func (s *budgetService) OnTransaction(ctx context.Context, transaction *domain.Transaction) {
+	switch transaction.Type {
+	case domain.TransactionTypeWithdrawal, domain.TransactionTypeTransfer, domain.TransactionTypeConversion, domain.TransactionTypePayment:
+	default:
+		return
+	}
+	if transaction.FromWalletID == nil {
+		return
+	}
+	walletID := *transaction.FromWalletID
+	statuses, err := s.BudgetStatuses(ctx, walletID, transaction.TransactionTime)
+	if err != nil {
+		s.logger.Error("Failed to evaluate budgets", "wallet_id", walletID, "error", err)
+		return
+	}
+	for _, status := range statuses {
+		budget := status.Budget
+		if budget.Enforcement != domain.BudgetEnforcementAlert || !budget.Covers(transaction.Category) {
+			continue
+		}
+		if status.Exceeded && status.Spent.Sub(transaction.Amount).LessThanOrEqual(budget.Limit) {
+			s.logger.Warn("Budget exceeded",
+				"budget_id", budget.ID,
+				"wallet_id", walletID,
+				"limit", budget.Limit.String(),
+				"spent", status.Spent.String(),
+				"transaction_id", transaction.PublicID,
+			)
+			s.notifyExceeded(ctx, status, transaction)
+		}
+	}
+}
+
+// notifyExceeded sends the wallet's owner a budget alert, in the currency of the crossing transaction.
+func (s *budgetService) notifyExceeded(ctx context.Context, status domain.BudgetStatus, transaction *domain.Transaction) {
+	if s.notifier == nil {
+		return
+	}
+	precision := domain.CurrencyPrecision(transaction.Currency)
+	title, body := notificationText("notification_budget_exceeded", map[string]string{
+		"spent": status.Spent.StringFixed(precision) + " " + transaction.Currency,
+		"limit": status.Budget.Limit.StringFixed(precision) + " " + transaction.Currency,
+	})
+	notification := &domain.Notification{
+		UserID:   status.Budget.OwnerID,
+		Category: domain.NotificationCategoryBudget,
+		Title:    title,
+		Body:     body,
+		Data:     map[string]string{"budget_id": fmt.Sprint(status.Budget.ID), "wallet_id": status.Budget.WalletPublicID.String()},
+	}
+	if err := s.notifier.Notify(ctx, notification); err != nil {
+		s.logger.Error("Failed to send budget alert", "budget_id", status.Budget.ID, "error", err)
+	}
+}
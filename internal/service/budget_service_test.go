@@ -0,0 +1,147 @@
+// internal/service/budget_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestBudgetService tests budget validation and the computation of consumption per period.
+func TestBudgetService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	walletID := int64(1)
+
+	t.Run("StatusesUseCurrentPeriod", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockBudgetRepo := new(MockBudgetRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewBudgetService(mockDBExecutor, mockBudgetRepo, mockTransactionRepo, nil, logger)
+
+		now := time.Date(2025, time.March, 13, 15, 0, 0, 0, time.UTC) // A Thursday
+		budgets := []domain.Budget{
+			{ID: 1, WalletID: walletID, Period: domain.BudgetPeriodMonthly, Limit: decimal.NewFromInt(1000)},
+			{ID: 2, WalletID: walletID, Period: domain.BudgetPeriodWeekly, Limit: decimal.NewFromInt(100)},
+		}
+		monthStart := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+		weekStart := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+		mockBudgetRepo.On("ListBudgetsByWalletID", ctx, mockDBExecutor, walletID).Return(budgets, nil).Once()
+		mockTransactionRepo.On("SumOutgoingAmount", ctx, mockDBExecutor, walletID, (*string)(nil), monthStart, monthStart.AddDate(0, 1, 0)).
+			Return(decimal.NewFromInt(400), nil).Once()
+		mockTransactionRepo.On("SumOutgoingAmount", ctx, mockDBExecutor, walletID, (*string)(nil), weekStart, weekStart.AddDate(0, 0, 7)).
+			Return(decimal.NewFromInt(120), nil).Once()
+
+		statuses, err := service.BudgetStatuses(ctx, walletID, now)
+
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 2)
+		assert.True(t, statuses[0].Remaining.Equal(decimal.NewFromInt(600)))
+		assert.False(t, statuses[0].Exceeded)
+		assert.True(t, statuses[1].Remaining.IsZero())
+		assert.True(t, statuses[1].Exceeded)
+		mock.AssertExpectationsForObjects(t, mockBudgetRepo, mockTransactionRepo)
+	})
+
+	t.Run("CreateNormalizesCategoryAndDefaultsEnforcement", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockBudgetRepo := new(MockBudgetRepository)
+		service := NewBudgetService(mockDBExecutor, mockBudgetRepo, new(MockTransactionRepository), nil, logger)
+
+		category := " Groceries "
+		budget := &domain.Budget{WalletID: walletID, Category: &category, Period: domain.BudgetPeriodWeekly, Limit: decimal.NewFromInt(50)}
+		mockBudgetRepo.On("CreateBudget", ctx, mockDBExecutor, budget).Return(nil).Once()
+
+		err := service.CreateBudget(ctx, budget)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "groceries", *budget.Category)
+		assert.Equal(t, domain.BudgetEnforcementNone, budget.Enforcement)
+		mockBudgetRepo.AssertExpectations(t)
+	})
+
+	t.Run("PeriodsFollowOwnerTimezone", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockBudgetRepo := new(MockBudgetRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := NewBudgetService(mockDBExecutor, mockBudgetRepo, mockTransactionRepo, nil, logger)
+
+		// 02:00 UTC on March 13 is still the evening of March 12 in New York (EDT, UTC-4)
+		now := time.Date(2025, time.March, 13, 2, 0, 0, 0, time.UTC)
+		budgets := []domain.Budget{{ID: 5, WalletID: walletID, Period: domain.BudgetPeriodDaily, Limit: decimal.NewFromInt(100), OwnerTimezone: "America/New_York"}}
+		dayStart := time.Date(2025, time.March, 12, 4, 0, 0, 0, time.UTC)
+		mockBudgetRepo.On("ListBudgetsByWalletID", ctx, mockDBExecutor, walletID).Return(budgets, nil).Once()
+		mockTransactionRepo.On("SumOutgoingAmount", ctx, mockDBExecutor, walletID, (*string)(nil),
+			mock.MatchedBy(dayStart.Equal), mock.MatchedBy(dayStart.Add(24*time.Hour).Equal)).Return(decimal.NewFromInt(30), nil).Once()
+
+		statuses, err := service.BudgetStatuses(ctx, walletID, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-03-12T00:00:00-04:00", statuses[0].PeriodStart.Format(time.RFC3339))
+		mock.AssertExpectationsForObjects(t, mockBudgetRepo, mockTransactionRepo)
+
+		// The day daylight saving time starts is 23 hours long
+		start, end := domain.BudgetPeriodDaily.Window(time.Date(2025, time.March, 9, 12, 0, 0, 0, time.UTC), budgets[0].Location())
+		assert.Equal(t, 23*time.Hour, end.Sub(start))
+	})
+
+	t.Run("InvalidBudgetRejected", func(t *testing.T) {
+		ctx := context.Background()
+		mockBudgetRepo := new(MockBudgetRepository)
+		service := NewBudgetService(new(MockDBExecutor), mockBudgetRepo, new(MockTransactionRepository), nil, logger)
+
+		for _, budget := range []*domain.Budget{
+			{WalletID: walletID, Period: "YEARLY", Limit: decimal.NewFromInt(50)},
+			{WalletID: walletID, Period: domain.BudgetPeriodMonthly, Limit: decimal.Zero},
+			{WalletID: walletID, Period: domain.BudgetPeriodMonthly, Limit: decimal.NewFromInt(50), Enforcement: "BLOCK"},
+		} {
+			assert.ErrorIs(t, service.CreateBudget(ctx, budget), util.ErrInvalidInput)
+		}
+		mockBudgetRepo.AssertNotCalled(t, "CreateBudget", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("WithdrawalEvaluatesBudgets", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockBudgetRepo := new(MockBudgetRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		notifier := new(collectingNotifier)
+		service := NewBudgetService(mockDBExecutor, mockBudgetRepo, mockTransactionRepo, notifier, logger)
+
+		travel := "travel"
+		budgets := []domain.Budget{{ID: 4, WalletID: walletID, OwnerID: 7, Category: &travel, Period: domain.BudgetPeriodMonthly, Limit: decimal.NewFromInt(10), Enforcement: domain.BudgetEnforcementAlert}}
+		mockBudgetRepo.On("ListBudgetsByWalletID", ctx, mockDBExecutor, walletID).Return(budgets, nil).Once()
+		mockTransactionRepo.On("SumOutgoingAmount", ctx, mockDBExecutor, walletID, &travel, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return(decimal.NewFromInt(15), nil).Once()
+
+		withdrawal := domain.NewTransaction(&walletID, nil, decimal.NewFromInt(15), "USD", domain.TransactionTypeWithdrawal, nil)
+		withdrawal.Category = &travel
+		service.OnTransaction(ctx, withdrawal)
+		mock.AssertExpectationsForObjects(t, mockBudgetRepo, mockTransactionRepo)
+		if assert.Len(t, notifier.notifications, 1) {
+			alert := notifier.notifications[0]
+			assert.Equal(t, int64(7), alert.UserID)
+			assert.Equal(t, domain.NotificationCategoryBudget, alert.Category)
+			assert.Equal(t, "You have spent 15.00 USD of your budget of 10.00 USD.", alert.Body)
+		}
+	})
+
+	t.Run("DepositsIgnored", func(t *testing.T) {
+		mockBudgetRepo := new(MockBudgetRepository)
+		service := NewBudgetService(new(MockDBExecutor), mockBudgetRepo, new(MockTransactionRepository), nil, logger)
+
+		service.OnTransaction(context.Background(), domain.NewTransaction(nil, &walletID, decimal.NewFromInt(15), "USD", domain.TransactionTypeDeposit, nil))
+		mockBudgetRepo.AssertNotCalled(t, "ListBudgetsByWalletID", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
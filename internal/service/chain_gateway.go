@@ -0,0 +1,212 @@
+// internal/service/chain_gateway.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// ErrChainRejected is returned by a ChainGateway that refused a payout for good, e.g. for an invalid address
+// or an exhausted hot wallet. Any other payout error may be retried.
+var ErrChainRejected = errors.New("rejected by the chain gateway")
+
+// ChainDeposit is an on-chain transfer to a deposit address, as seen by the chain gateway.
+type ChainDeposit struct {
+	Address       string
+	TxHash        string
+	OutputIndex   int
+	Amount        decimal.Decimal
+	Confirmations int
+}
+
+// PayoutRequest asks the chain gateway to send an asset to an external address.
+type PayoutRequest struct {
+	Asset          string
+	Network        string
+	Address        string
+	Amount         decimal.Decimal
+	IdempotencyKey string // A retried request with the same key is not sent twice
+}
+
+// ChainGateway connects crypto wallets to the chains: it issues deposit addresses, watches them for deposits
+// and sends payouts from the platform's hot wallets, e.g. a custody provider or a self-hosted node service.
+type ChainGateway interface {
+	// NewAddress issues a new deposit address for the asset, labelled with the wallet it is issued to.
+	NewAddress(ctx context.Context, asset domain.CryptoAsset, label string) (string, error)
+	// ListDeposits returns the recent transfers of the asset to the addresses it issued, with their current
+	// confirmations. The same transfer is reported again until it has dropped out of the gateway's window.
+	ListDeposits(ctx context.Context, asset domain.CryptoAsset) ([]ChainDeposit, error)
+	// EstimateFee returns the network fee of sending amount of the asset to address. An address the gateway
+	// cannot send to fails with util.ErrInvalidInput.
+	EstimateFee(ctx context.Context, asset domain.CryptoAsset, address string, amount decimal.Decimal) (decimal.Decimal, error)
+	// SendPayout broadcasts the payout and returns the hash of its chain transaction. A payout refused for good
+	// fails with ErrChainRejected; nothing was sent.
+	SendPayout(ctx context.Context, req PayoutRequest) (string, error)
+	// HotWalletBalance returns how much of the asset the platform's hot wallets hold and can pay out.
+	HotWalletBalance(ctx context.Context, asset domain.CryptoAsset) (decimal.Decimal, error)
+}
+
+// httpChainGateway is a ChainGateway speaking the gateway's JSON API.
+type httpChainGateway struct {
+	url    string // Base URL; resources are under url + "/assets/{code}"
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPChainGateway creates a ChainGateway for the gateway API at url, authenticated with apiKey if set.
+func NewHTTPChainGateway(url, apiKey string, timeout time.Duration) ChainGateway {
+	return &httpChainGateway{url: strings.TrimSuffix(url, "/"), apiKey: apiKey, client: &http.Client{Timeout: timeout}}
+}
+
+// gatewayError is an error response of the gateway.
+type gatewayError struct {
+	Reason string `json:"reason"`
+}
+
+// NewAddress implements ChainGateway.
+func (g *httpChainGateway) NewAddress(ctx context.Context, asset domain.CryptoAsset, label string) (string, error) {
+	var address struct {
+		Address string `json:"address"`
+	}
+	if err := g.do(ctx, http.MethodPost, asset, "/addresses", map[string]string{"label": label}, "", &address); err != nil {
+		return "", fmt.Errorf("chain gateway: failed to issue %s address: %w", asset.Code, err)
+	}
+	if address.Address == "" {
+		return "", fmt.Errorf("chain gateway: issued an empty %s address", asset.Code)
+	}
+	return address.Address, nil
+}
+
+// ListDeposits implements ChainGateway.
+func (g *httpChainGateway) ListDeposits(ctx context.Context, asset domain.CryptoAsset) ([]ChainDeposit, error) {
+	var deposits []struct {
+		Address       string          `json:"address"`
+		TxHash        string          `json:"tx_hash"`
+		OutputIndex   int             `json:"output_index"`
+		Amount        decimal.Decimal `json:"amount"`
+		Confirmations int             `json:"confirmations"`
+	}
+	if err := g.do(ctx, http.MethodGet, asset, "/deposits", nil, "", &deposits); err != nil {
+		return nil, fmt.Errorf("chain gateway: failed to list %s deposits: %w", asset.Code, err)
+	}
+	result := make([]ChainDeposit, len(deposits))
+	for i, deposit := range deposits {
+		result[i] = ChainDeposit(deposit)
+	}
+	return result, nil
+}
+
+// EstimateFee implements ChainGateway. The gateway answers 422 for an address it cannot send to.
+func (g *httpChainGateway) EstimateFee(ctx context.Context, asset domain.CryptoAsset, address string, amount decimal.Decimal) (decimal.Decimal, error) {
+	var estimate struct {
+		Fee decimal.Decimal `json:"fee"`
+	}
+	path := "/fee-estimate?" + url.Values{"address": {address}, "amount": {amount.String()}}.Encode()
+	if err := g.do(ctx, http.MethodGet, asset, path, nil, "", &estimate); err != nil {
+		var rejected *gatewayRejection
+		if errors.As(err, &rejected) && rejected.status == http.StatusUnprocessableEntity {
+			return decimal.Zero, fmt.Errorf("%w: %s", util.ErrInvalidInput, rejected.reason)
+		}
+		return decimal.Zero, fmt.Errorf("chain gateway: failed to estimate %s fee: %w", asset.Code, err)
+	}
+	if estimate.Fee.IsNegative() {
+		return decimal.Zero, fmt.Errorf("chain gateway: estimated a negative %s fee", asset.Code)
+	}
+	return estimate.Fee, nil
+}
+
+// SendPayout implements ChainGateway. A 4xx response other than 409 and 429 rejects the payout for good; 409
+// answers a request still in progress under the same key.
+func (g *httpChainGateway) SendPayout(ctx context.Context, req PayoutRequest) (string, error) {
+	asset, _ := domain.LookupCryptoAsset(req.Asset)
+	var payout struct {
+		TxHash string `json:"tx_hash"`
+	}
+	body := map[string]string{"address": req.Address, "amount": req.Amount.StringFixed(asset.Places)}
+	if err := g.do(ctx, http.MethodPost, asset, "/payouts", body, req.IdempotencyKey, &payout); err != nil {
+		var rejected *gatewayRejection
+		if errors.As(err, &rejected) && rejected.status < 500 &&
+			rejected.status != http.StatusConflict && rejected.status != http.StatusTooManyRequests {
+			return "", fmt.Errorf("%w: %s", ErrChainRejected, rejected.reason)
+		}
+		return "", fmt.Errorf("chain gateway: failed to send %s payout: %w", req.Asset, err)
+	}
+	if payout.TxHash == "" {
+		return "", fmt.Errorf("chain gateway: sent %s payout without a transaction hash", req.Asset)
+	}
+	return payout.TxHash, nil
+}
+
+// HotWalletBalance implements ChainGateway.
+func (g *httpChainGateway) HotWalletBalance(ctx context.Context, asset domain.CryptoAsset) (decimal.Decimal, error) {
+	var balance struct {
+		Available decimal.Decimal `json:"available"`
+	}
+	if err := g.do(ctx, http.MethodGet, asset, "/balance", nil, "", &balance); err != nil {
+		return decimal.Zero, fmt.Errorf("chain gateway: failed to get %s hot wallet balance: %w", asset.Code, err)
+	}
+	return balance.Available, nil
+}
+
+// gatewayRejection is a response of the gateway with a non-2xx status.
+type gatewayRejection struct {
+	status int
+	reason string
+}
+
+func (e *gatewayRejection) Error() string {
+	return fmt.Sprintf("status %d: %s", e.status, e.reason)
+}
+
+// do sends a request for the asset's resource at path and decodes the response into out.
+func (g *httpChainGateway) do(ctx context.Context, method string, asset domain.CryptoAsset, path string, body any, idempotencyKey string, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, g.url+"/assets/"+asset.Code+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	if g.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var reason gatewayError
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&reason) // The reason is optional
+		return &gatewayRejection{status: resp.StatusCode, reason: reason.Reason}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// internal/service/change_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// ChangeService defines the interface for the change feed, which lets clients delta-sync wallets and
+// transactions instead of re-fetching them.
+type ChangeService interface {
+	// ListChanges returns up to limit wallets and transactions changed after the since cursor, optionally only
+	// those of one user. Start with since 0 and pass the returned cursor on the next call.
+	ListChanges(ctx context.Context, since int64, userID *int64, limit int) (*domain.ChangeSet, error)
+}
+
+// changeService implements ChangeService.
+type changeService struct {
+	dbExecutor  repository.DBExecutor
+	changeRepo  repository.ChangeRepository
+	settleDelay time.Duration // Changes younger than this are left for the next call
+	logger      *slog.Logger
+}
+
+// NewChangeService creates a new instance of ChangeService.
+// Sequence values are taken before commit, so a change can become visible after one with a higher value.
+// Holding back changes younger than settleDelay keeps a cursor from moving past one that is still committing.
+func NewChangeService(
+	dbExecutor repository.DBExecutor,
+	changeRepo repository.ChangeRepository,
+	settleDelay time.Duration,
+	logger *slog.Logger,
+) ChangeService {
+	return &changeService{
+		dbExecutor:  dbExecutor,
+		changeRepo:  changeRepo,
+		settleDelay: settleDelay,
+		logger:      logger,
+	}
+}
+
+// ListChanges reads up to limit changes from each table and merges them in change order. The page ends at
+// the limit-th change overall; changes past it are dropped and returned again by the next call.
+func (s *changeService) ListChanges(ctx context.Context, since int64, userID *int64, limit int) (*domain.ChangeSet, error) {
+	if since < 0 || limit < 1 || limit > domain.MaxChangesPerPage {
+		return nil, fmt.Errorf("%w: since must not be negative and limit must be between 1 and %d", util.ErrInvalidInput, domain.MaxChangesPerPage)
+	}
+	filter := repository.ChangeFilter{UserID: userID, SettleDelay: s.settleDelay}
+
+	wallets, err := s.changeRepo.ListWalletChanges(ctx, s.dbExecutor, since, filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list changes: %w", err)
+	}
+	transactions, err := s.changeRepo.ListTransactionChanges(ctx, s.dbExecutor, since, filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list changes: %w", err)
+	}
+
+	changes := &domain.ChangeSet{
+		Cursor:  since,
+		HasMore: len(wallets) == limit || len(transactions) == limit,
+	}
+	w, t := 0, 0
+	for w+t < limit && (w < len(wallets) || t < len(transactions)) {
+		if t == len(transactions) || (w < len(wallets) && wallets[w].Seq < transactions[t].Seq) {
+			changes.Cursor = wallets[w].Seq
+			w++
+		} else {
+			changes.Cursor = transactions[t].Seq
+			t++
+		}
+	}
+	changes.Wallets = wallets[:w]
+	changes.Transactions = transactions[:t]
+	if w < len(wallets) || t < len(transactions) {
+		changes.HasMore = true
+	}
+	return changes, nil
+}
@@ -0,0 +1,83 @@
+// internal/service/change_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// TestChangeService tests that wallet and transaction changes are merged in change order behind one cursor.
+func TestChangeService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	walletChange := func(seq int64) domain.WalletChange {
+		return domain.WalletChange{Wallet: domain.Wallet{ID: seq}, Seq: seq}
+	}
+	transactionChange := func(seq int64) domain.TransactionChange {
+		return domain.TransactionChange{Transaction: domain.Transaction{ID: seq}, Seq: seq}
+	}
+	settled := func(filter repository.ChangeFilter) bool {
+		return filter.UserID == nil && filter.SettleDelay == 2*time.Second
+	}
+
+	newService := func() (ChangeService, *MockChangeRepository, *MockDBExecutor) {
+		changeRepo := new(MockChangeRepository)
+		dbExecutor := new(MockDBExecutor)
+		return NewChangeService(dbExecutor, changeRepo, 2*time.Second, logger), changeRepo, dbExecutor
+	}
+
+	t.Run("MergesInChangeOrderUpToLimit", func(t *testing.T) {
+		ctx := context.Background()
+		service, changeRepo, dbExecutor := newService()
+
+		changeRepo.On("ListWalletChanges", ctx, dbExecutor, int64(10), mock.MatchedBy(settled), 3).
+			Return([]domain.WalletChange{walletChange(11), walletChange(14)}, nil).Once()
+		changeRepo.On("ListTransactionChanges", ctx, dbExecutor, int64(10), mock.MatchedBy(settled), 3).
+			Return([]domain.TransactionChange{transactionChange(12), transactionChange(13), transactionChange(15)}, nil).Once()
+
+		changes, err := service.ListChanges(ctx, 10, nil, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(13), changes.Cursor)
+		assert.True(t, changes.HasMore)
+		assert.Len(t, changes.Wallets, 1)
+		assert.Len(t, changes.Transactions, 2)
+		changeRepo.AssertExpectations(t)
+	})
+
+	t.Run("NothingNewKeepsCursor", func(t *testing.T) {
+		ctx := context.Background()
+		service, changeRepo, dbExecutor := newService()
+		userID := int64(7)
+
+		changeRepo.On("ListWalletChanges", ctx, dbExecutor, int64(42), mock.MatchedBy(func(f repository.ChangeFilter) bool { return *f.UserID == userID }), 100).
+			Return([]domain.WalletChange{}, nil).Once()
+		changeRepo.On("ListTransactionChanges", ctx, dbExecutor, int64(42), mock.Anything, 100).
+			Return([]domain.TransactionChange{}, nil).Once()
+
+		changes, err := service.ListChanges(ctx, 42, &userID, 100)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), changes.Cursor)
+		assert.False(t, changes.HasMore)
+		changeRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidCursorRejected", func(t *testing.T) {
+		service, changeRepo, _ := newService()
+
+		_, err := service.ListChanges(context.Background(), -1, nil, 10)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		changeRepo.AssertNotCalled(t, "ListWalletChanges", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,155 @@
+// internal/service/clock_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/clock"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestWalletServiceClock tests that the wallet service reads the time from its clock.
+func TestWalletServiceClock(t *testing.T) {
+	walletID := int64(1)
+	testClock := clock.NewTest()
+	_, err := testClock.Advance(45 * 24 * time.Hour)
+	require.NoError(t, err)
+
+	walletRepo := new(MockWalletRepository)
+	transactionRepo := new(MockTransactionRepository)
+	txController := new(MockTxController)
+	service := NewWalletService(
+		new(MockDBBeginner),
+		new(MockDBExecutor),
+		new(MockUserRepository),
+		walletRepo,
+		transactionRepo,
+		func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+			return txController, nil
+		},
+		func(tx db.TxController) error {
+			return txController.Commit()
+		},
+		func(tx db.TxController) {},
+		WithClock(testClock),
+	)
+
+	ctx := context.Background()
+	amount := decimal.NewFromInt(100)
+	walletRepo.On("GetWalletByID", ctx, txController, walletID).Return(&domain.Wallet{ID: walletID, Currency: "USD"}, nil).Once()
+	walletRepo.On("UpdateWalletBalance", ctx, txController, walletID, amount, mock.MatchedBy(func(at time.Time) bool {
+		return at.After(time.Now().Add(44 * 24 * time.Hour))
+	})).Return(&domain.WalletBalance{ID: walletID, Balance: amount, Version: 2}, nil).Once()
+	transactionRepo.On("CreateTransaction", ctx, txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+	txController.On("Commit").Return(nil).Once()
+
+	_, transaction, err := service.Deposit(ctx, walletID, amount, "USD")
+
+	require.NoError(t, err)
+	assert.WithinDuration(t, testClock.Now(), transaction.TransactionTime, time.Minute)
+	assert.False(t, transaction.CreatedAt.Before(time.Now().Add(44*24*time.Hour)))
+	mock.AssertExpectationsForObjects(t, walletRepo, transactionRepo, txController)
+
+	_, err = testClock.Advance(-time.Hour)
+	assert.Error(t, err, "the clock never goes backwards")
+}
+
+// TestSuspenseServiceClock tests that inbound funds are credited and dated at the time of the balance writer's clock.
+func TestSuspenseServiceClock(t *testing.T) {
+	testClock := clock.NewTest()
+	_, err := testClock.Advance(45 * 24 * time.Hour)
+	require.NoError(t, err)
+	future := func(at time.Time) bool { return at.After(time.Now().Add(44 * 24 * time.Hour)) }
+
+	suspenseRepo, walletRepo, transactionRepo := new(MockSuspenseRepository), new(MockWalletRepository), new(MockTransactionRepository)
+	dbExecutor, txController := new(MockDBExecutor), new(MockTxController)
+	service := NewSuspenseService(
+		new(MockDBBeginner), dbExecutor, suspenseRepo, walletRepo, NewBalanceWriter(walletRepo, nil, nil, testClock),
+		new(MockUserRepository), transactionRepo, new(MockAnnotationRepository), nil,
+		func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return txController, nil },
+		func(tx db.TxController) error { return txController.Commit() },
+		func(tx db.TxController) { _ = txController.Rollback() },
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+
+	ctx := context.Background()
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), Currency: "USD"}
+	accountReference := wallet.PublicID.String()
+	amount := decimal.NewFromInt(75)
+	suspenseRepo.On("GetInboundFundsByReference", ctx, dbExecutor, "bank", "pay_1").Return(nil, util.ErrNotFound).Once()
+	walletRepo.On("GetWalletByPublicID", ctx, dbExecutor, wallet.PublicID).Return(wallet, nil).Once()
+	walletRepo.On("GetWalletByIDForUpdate", ctx, txController, wallet.ID).Return(wallet, nil).Once()
+	walletRepo.On("UpdateWalletBalance", ctx, txController, wallet.ID, amount, mock.MatchedBy(future)).Return(nil, nil).Once()
+	transactionRepo.On("CreateTransaction", ctx, txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+		return future(tx.TransactionTime) && future(tx.CreatedAt)
+	})).Return(nil).Once()
+	suspenseRepo.On("CreateInboundFunds", ctx, txController, mock.AnythingOfType("*domain.InboundFunds")).Return(nil).Once()
+	txController.On("Commit").Return(nil).Once()
+	txController.On("Rollback").Return(nil).Maybe()
+
+	funds, _, err := service.ReceiveFunds(ctx, "bank", "pay_1", &accountReference, amount, "USD")
+
+	require.NoError(t, err)
+	assert.True(t, future(funds.CreatedAt))
+	mock.AssertExpectationsForObjects(t, walletRepo, transactionRepo, suspenseRepo)
+}
+
+// TestOwnershipTransferServiceClock tests that the ownership transfer service dates requests and answers by
+// its clock, so a transfer expires on the test clock.
+func TestOwnershipTransferServiceClock(t *testing.T) {
+	testClock := clock.NewTest()
+	_, err := testClock.Advance(45 * 24 * time.Hour)
+	require.NoError(t, err)
+	future := func(at time.Time) bool { return at.After(time.Now().Add(44 * 24 * time.Hour)) }
+
+	transferRepo, walletRepo, userRepo, auditRepo := new(MockOwnershipTransferRepository), new(MockWalletRepository), new(MockUserRepository), new(MockAuditRepository)
+	txController := new(MockTxController)
+	service := NewOwnershipTransferService(
+		new(MockDBBeginner), new(MockDBExecutor), transferRepo, walletRepo, userRepo, auditRepo, nil, 72*time.Hour, testClock,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return txController, nil },
+		func(tx db.TxController) error { return txController.Commit() },
+		func(tx db.TxController) { _ = txController.Rollback() },
+	)
+	txController.On("Rollback").Return(nil).Maybe()
+
+	ctx := context.Background()
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "EUR"}
+	walletRepo.On("GetWalletByIDForUpdate", ctx, txController, wallet.ID).Return(wallet, nil).Once()
+	userRepo.On("GetUserByID", ctx, txController, int64(20)).Return(&domain.User{ID: 20}, nil).Once()
+	walletRepo.On("GetWalletByUserIDAndCurrency", ctx, txController, int64(20), "EUR").Return(nil, util.ErrNotFound).Once()
+	transferRepo.On("CreateOwnershipTransfer", ctx, txController, mock.MatchedBy(func(transfer *domain.OwnershipTransfer) bool {
+		return future(transfer.CreatedAt) && transfer.ExpiresAt.Equal(transfer.CreatedAt.Add(72*time.Hour))
+	})).Return(nil).Once()
+	walletRepo.On("SetOwnershipFrozen", ctx, txController, wallet.ID, true, mock.MatchedBy(future)).Return(wallet, nil).Once()
+	auditRepo.On("LockChain", ctx, txController, wallet.ID).Return(nil).Once()
+	auditRepo.On("GetChainHead", ctx, txController, wallet.ID).Return(&domain.AuditChainHead{}, nil).Once()
+	auditRepo.On("AppendEvent", ctx, txController, mock.AnythingOfType("*domain.AuditEvent")).Return(nil).Once()
+	txController.On("Commit").Return(nil).Once()
+
+	_, err = service.Request(ctx, wallet, 20, "Duplicate registration", "alice")
+	require.NoError(t, err)
+
+	// A transfer that has not expired by the wall clock has expired by the test clock
+	pending := &domain.OwnershipTransfer{ID: 3, PublicID: uuid.New(), WalletID: wallet.ID, FromUserID: 10, ToUserID: 20,
+		Status: domain.OwnershipTransferPending, ExpiresAt: time.Now().Add(72 * time.Hour)}
+	transferRepo.On("GetOwnershipTransferForUpdate", ctx, txController, pending.PublicID).Return(pending, nil).Once()
+
+	_, _, err = service.Accept(ctx, 20, pending.PublicID)
+
+	assert.ErrorIs(t, err, util.ErrOwnershipNotPending)
+	mock.AssertExpectationsForObjects(t, transferRepo, walletRepo, userRepo, auditRepo)
+	walletRepo.AssertNotCalled(t, "ReassignWallet", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,219 @@
+// internal/service/contact_verification_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// Limits of contact verification codes.
+const (
+	verificationCodeTTL        = 15 * time.Minute
+	verificationResendInterval = time.Minute // Minimum time between two codes for the same user and channel
+	maxVerificationAttempts    = 5           // Wrong codes after which a code is used up
+)
+
+// ContactVerificationSender delivers verification codes to users.
+type ContactVerificationSender interface {
+	// SendVerificationCode sends code to target, the user's email address or phone number.
+	SendVerificationCode(ctx context.Context, user *domain.User, channel domain.ContactChannel, target, code string) error
+}
+
+// LogContactVerificationSender is the default ContactVerificationSender, for a deployment without an email or
+// SMS provider: it logs that a code was issued, and the code itself only at debug level.
+type LogContactVerificationSender struct {
+	logger *slog.Logger
+}
+
+// NewLogContactVerificationSender creates a new LogContactVerificationSender.
+func NewLogContactVerificationSender(logger *slog.Logger) *LogContactVerificationSender {
+	return &LogContactVerificationSender{logger: logger}
+}
+
+// SendVerificationCode implements ContactVerificationSender.
+func (n *LogContactVerificationSender) SendVerificationCode(ctx context.Context, user *domain.User, channel domain.ContactChannel, target, code string) error {
+	n.logger.Info("Contact verification code issued", "user_id", user.ID, "channel", channel)
+	n.logger.Debug("Contact verification code", "user_id", user.ID, "channel", channel, "code", code)
+	return nil
+}
+
+// ContactVerificationService defines the interface for verifying that users receive messages at their email
+// address and phone number.
+type ContactVerificationService interface {
+	// Issue sends a new code to the user's email or phone, replacing any pending one.
+	Issue(ctx context.Context, userID int64, channel domain.ContactChannel) (*domain.ContactVerification, error)
+	// Confirm marks the user's email or phone verified if code is the pending one, and returns the updated user.
+	Confirm(ctx context.Context, userID int64, channel domain.ContactChannel, code string) (*domain.User, error)
+}
+
+// contactVerificationService implements ContactVerificationService.
+type contactVerificationService struct {
+	dbBeginner       db.DBTxBeginner
+	dbExecutor       repository.DBExecutor
+	verificationRepo repository.ContactVerificationRepository
+	userRepo         repository.UserRepository
+	sender           ContactVerificationSender
+	beginTx          db.BeginTxFunc
+	commitTx         db.CommitTxFunc
+	rollbackTx       db.RollbackTxFunc
+	logger           *slog.Logger
+}
+
+// NewContactVerificationService creates a new instance of ContactVerificationService.
+func NewContactVerificationService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	verificationRepo repository.ContactVerificationRepository,
+	userRepo repository.UserRepository,
+	sender ContactVerificationSender,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) ContactVerificationService {
+	return &contactVerificationService{
+		dbBeginner:       dbBeginner,
+		dbExecutor:       dbExecutor,
+		verificationRepo: verificationRepo,
+		userRepo:         userRepo,
+		sender:           sender,
+		beginTx:          beginTx,
+		commitTx:         commitTx,
+		rollbackTx:       rollbackTx,
+		logger:           logger,
+	}
+}
+
+// contactTarget returns the user's value for the channel, its blind index and when it was verified.
+func contactTarget(user *domain.User, channel domain.ContactChannel) (target, hash *string, verifiedAt *time.Time) {
+	if channel == domain.ContactChannelEmail {
+		return user.Email, user.EmailHash, user.EmailVerifiedAt
+	}
+	return user.Phone, user.PhoneHash, user.PhoneVerifiedAt
+}
+
+// Issue stores only the hash of the code, bound to the current value of the email or phone, before sending it.
+// If sending fails the code is withdrawn, so the user can ask again right away.
+func (s *contactVerificationService) Issue(ctx context.Context, userID int64, channel domain.ContactChannel) (*domain.ContactVerification, error) {
+	if !channel.Valid() {
+		return nil, fmt.Errorf("%w: channel must be %s or %s", util.ErrInvalidInput, domain.ContactChannelEmail, domain.ContactChannelPhone)
+	}
+	user, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, userID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("issue verification: failed to get user %d: %w", userID, err)
+	}
+	// A verified email or phone can be verified again, e.g. to reactivate a dormant wallet
+	target, hash, _ := contactTarget(user, channel)
+	if target == nil || hash == nil {
+		return nil, fmt.Errorf("%w: user has no %s to verify", util.ErrInvalidInput, channel)
+	}
+
+	now := time.Now().UTC()
+	pending, err := s.verificationRepo.GetVerification(ctx, s.dbExecutor, userID, channel)
+	if err != nil && !util.IsError(err, util.ErrNotFound) {
+		return nil, fmt.Errorf("issue verification: %w", err)
+	}
+	if pending != nil && now.Before(pending.CreatedAt.Add(verificationResendInterval)) {
+		return nil, util.ErrVerificationThrottled
+	}
+
+	code, err := domain.NewVerificationCode()
+	if err != nil {
+		return nil, fmt.Errorf("issue verification: %w", err)
+	}
+	verification := &domain.ContactVerification{
+		UserID:     userID,
+		Channel:    channel,
+		TargetHash: *hash,
+		CodeHash:   domain.HashVerificationCode(code),
+		ExpiresAt:  now.Add(verificationCodeTTL),
+		CreatedAt:  now,
+	}
+	if err := s.verificationRepo.SaveVerification(ctx, s.dbExecutor, verification); err != nil {
+		return nil, fmt.Errorf("issue verification: %w", err)
+	}
+	if err := s.sender.SendVerificationCode(ctx, user, channel, *target, code); err != nil {
+		if delErr := s.verificationRepo.DeleteVerification(ctx, s.dbExecutor, userID, channel); delErr != nil {
+			s.logger.Error("Failed to withdraw unsent verification code", "user_id", userID, "channel", channel, "error", delErr)
+		}
+		return nil, fmt.Errorf("issue verification: failed to send code: %w", err)
+	}
+	return verification, nil
+}
+
+// Confirm locks the pending verification so concurrent attempts are counted one after another. Failed
+// attempts are committed; the last allowed one, like a successful one, removes the code. A code sent to a
+// value that has since changed is rejected and removed.
+func (s *contactVerificationService) Confirm(ctx context.Context, userID int64, channel domain.ContactChannel, code string) (*domain.User, error) {
+	if !channel.Valid() {
+		return nil, fmt.Errorf("%w: channel must be %s or %s", util.ErrInvalidInput, domain.ContactChannelEmail, domain.ContactChannelPhone)
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("confirm verification: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("confirm verification: transaction controller does not implement DBExecutor")
+	}
+
+	verification, err := s.verificationRepo.GetVerificationForUpdate(ctx, txExecutor, userID, channel)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrInvalidVerificationCode
+		}
+		return nil, fmt.Errorf("confirm verification: %w", err)
+	}
+	now := time.Now().UTC()
+	if verification.Expired(now) {
+		return nil, util.ErrInvalidVerificationCode
+	}
+
+	if !verification.VerifyCode(code) {
+		verification.FailedAttempts++
+		if verification.FailedAttempts >= maxVerificationAttempts {
+			err = s.verificationRepo.DeleteVerification(ctx, txExecutor, userID, channel)
+		} else {
+			err = s.verificationRepo.UpdateAttempts(ctx, txExecutor, verification)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("confirm verification: %w", err)
+		}
+		if err := s.commitTx(txController); err != nil {
+			return nil, fmt.Errorf("confirm verification: failed to commit transaction: %w", err)
+		}
+		return nil, util.ErrInvalidVerificationCode
+	}
+
+	user, markErr := s.userRepo.MarkContactVerified(ctx, txExecutor, userID, channel, verification.TargetHash, now)
+	if markErr != nil && !util.IsError(markErr, util.ErrNotFound) {
+		if util.IsError(markErr, util.ErrDuplicateEntry) {
+			return nil, util.ErrContactInUse
+		}
+		return nil, fmt.Errorf("confirm verification: %w", markErr)
+	}
+	if err := s.verificationRepo.DeleteVerification(ctx, txExecutor, userID, channel); err != nil {
+		return nil, fmt.Errorf("confirm verification: %w", err)
+	}
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("confirm verification: failed to commit transaction: %w", err)
+	}
+	if markErr != nil {
+		return nil, util.ErrInvalidVerificationCode // The email or phone changed, or the user was deleted
+	}
+	s.logger.Info("Contact verified", "user_id", userID, "channel", channel)
+	return user, nil
+}
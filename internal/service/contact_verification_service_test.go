@@ -0,0 +1,191 @@
+// internal/service/contact_verification_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// recordingVerificationSender is a ContactVerificationSender remembering the last code sent.
+type recordingVerificationSender struct {
+	target, code string
+}
+
+func (s *recordingVerificationSender) SendVerificationCode(ctx context.Context, user *domain.User, channel domain.ContactChannel, target, code string) error {
+	s.target, s.code = target, code
+	return nil
+}
+
+// TestContactVerificationService tests issuing verification codes, confirming them and their limits.
+func TestContactVerificationService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	userID := int64(10)
+	email, emailHash := "alice@example.com", "e-hash"
+	user := &domain.User{ID: userID, Email: &email, EmailHash: &emailHash}
+
+	type mocks struct {
+		verificationRepo *MockContactVerificationRepository
+		userRepo         *MockUserRepository
+		sender           *recordingVerificationSender
+		dbExecutor       *MockDBExecutor
+		txController     *MockTxController
+	}
+	newService := func() (ContactVerificationService, mocks) {
+		m := mocks{
+			verificationRepo: new(MockContactVerificationRepository),
+			userRepo:         new(MockUserRepository),
+			sender:           new(recordingVerificationSender),
+			dbExecutor:       new(MockDBExecutor),
+			txController:     new(MockTxController),
+		}
+		service := NewContactVerificationService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.verificationRepo,
+			m.userRepo,
+			m.sender,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+	pending := func(code string, failedAttempts int) *domain.ContactVerification {
+		return &domain.ContactVerification{
+			UserID:         userID,
+			Channel:        domain.ContactChannelEmail,
+			TargetHash:     emailHash,
+			CodeHash:       domain.HashVerificationCode(code),
+			FailedAttempts: failedAttempts,
+			ExpiresAt:      time.Now().Add(time.Minute),
+			CreatedAt:      time.Now().Add(-time.Hour),
+		}
+	}
+
+	t.Run("IssueSendsCodeAndStoresItsHash", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, userID).Return(user, nil).Once()
+		m.verificationRepo.On("GetVerification", ctx, m.dbExecutor, userID, domain.ContactChannelEmail).Return(nil, util.ErrNotFound).Once()
+		m.verificationRepo.On("SaveVerification", ctx, m.dbExecutor, mock.Anything).Return(nil).Once()
+
+		verification, err := service.Issue(ctx, userID, domain.ContactChannelEmail)
+
+		assert.NoError(t, err)
+		assert.Equal(t, email, m.sender.target)
+		assert.Len(t, m.sender.code, domain.VerificationCodeLength)
+		assert.Equal(t, domain.HashVerificationCode(m.sender.code), verification.CodeHash)
+		assert.Equal(t, emailHash, verification.TargetHash)
+		m.verificationRepo.AssertExpectations(t)
+	})
+
+	t.Run("IssueIsThrottled", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		recent := pending("123456", 0)
+		recent.CreatedAt = time.Now().Add(-10 * time.Second)
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, userID).Return(user, nil).Once()
+		m.verificationRepo.On("GetVerification", ctx, m.dbExecutor, userID, domain.ContactChannelEmail).Return(recent, nil).Once()
+
+		_, err := service.Issue(ctx, userID, domain.ContactChannelEmail)
+
+		assert.ErrorIs(t, err, util.ErrVerificationThrottled)
+		m.verificationRepo.AssertNotCalled(t, "SaveVerification", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("IssueWithoutPhoneRejected", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, userID).Return(user, nil).Once()
+
+		_, err := service.Issue(ctx, userID, domain.ContactChannelPhone)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+	})
+
+	t.Run("ConfirmMarksVerified", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		verifiedAt := time.Now()
+
+		m.verificationRepo.On("GetVerificationForUpdate", ctx, m.txController, userID, domain.ContactChannelEmail).Return(pending("123456", 2), nil).Once()
+		m.userRepo.On("MarkContactVerified", ctx, m.txController, userID, domain.ContactChannelEmail, emailHash, mock.Anything).
+			Return(&domain.User{ID: userID, EmailVerifiedAt: &verifiedAt}, nil).Once()
+		m.verificationRepo.On("DeleteVerification", ctx, m.txController, userID, domain.ContactChannelEmail).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		verified, err := service.Confirm(ctx, userID, domain.ContactChannelEmail, "123456")
+
+		assert.NoError(t, err)
+		assert.True(t, verified.HasVerifiedContact())
+		m.verificationRepo.AssertExpectations(t)
+		m.txController.AssertExpectations(t)
+	})
+
+	t.Run("WrongCodeIsCountedAndUsedUp", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.verificationRepo.On("GetVerificationForUpdate", ctx, m.txController, userID, domain.ContactChannelEmail).Return(pending("123456", maxVerificationAttempts-1), nil).Once()
+		m.verificationRepo.On("DeleteVerification", ctx, m.txController, userID, domain.ContactChannelEmail).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		_, err := service.Confirm(ctx, userID, domain.ContactChannelEmail, "654321")
+
+		assert.ErrorIs(t, err, util.ErrInvalidVerificationCode)
+		m.userRepo.AssertNotCalled(t, "MarkContactVerified", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.txController.AssertExpectations(t)
+	})
+
+	t.Run("ChangedContactRejected", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.verificationRepo.On("GetVerificationForUpdate", ctx, m.txController, userID, domain.ContactChannelEmail).Return(pending("123456", 0), nil).Once()
+		m.userRepo.On("MarkContactVerified", ctx, m.txController, userID, domain.ContactChannelEmail, emailHash, mock.Anything).Return(nil, util.ErrNotFound).Once()
+		m.verificationRepo.On("DeleteVerification", ctx, m.txController, userID, domain.ContactChannelEmail).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		_, err := service.Confirm(ctx, userID, domain.ContactChannelEmail, "123456")
+
+		assert.ErrorIs(t, err, util.ErrInvalidVerificationCode)
+		m.verificationRepo.AssertExpectations(t)
+	})
+
+	t.Run("VerifiedByAnotherUser", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.verificationRepo.On("GetVerificationForUpdate", ctx, m.txController, userID, domain.ContactChannelEmail).Return(pending("123456", 0), nil).Once()
+		m.userRepo.On("MarkContactVerified", ctx, m.txController, userID, domain.ContactChannelEmail, emailHash, mock.Anything).Return(nil, util.ErrDuplicateEntry).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, err := service.Confirm(ctx, userID, domain.ContactChannelEmail, "123456")
+
+		assert.ErrorIs(t, err, util.ErrContactInUse)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+}
@@ -0,0 +1,516 @@
+// internal/service/crypto_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+type cryptoPayoutKey struct{}
+
+// withCryptoPayout attaches a payout to the withdrawal made with ctx. WalletService.Withdraw then queues the
+// payout with the WITHDRAWAL debiting it, in the same database transaction.
+func withCryptoPayout(ctx context.Context, payout *domain.CryptoPayout) context.Context {
+	return context.WithValue(ctx, cryptoPayoutKey{}, payout)
+}
+
+// WithCryptoPayouts enables withdrawals of crypto wallets, which CryptoService.RequestWithdrawal routes through
+// the payout pipeline. Without it, and for any withdrawal made otherwise, crypto wallets cannot be withdrawn from.
+func WithCryptoPayouts(cryptoRepo repository.CryptoRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.cryptoRepo = cryptoRepo
+	}
+}
+
+// cryptoPayout returns the payout attached to ctx for a withdrawal of amount from wallet. A crypto wallet only
+// withdraws through a payout, as nothing else would send the asset on chain, and only a crypto wallet pays out.
+func (s *walletService) cryptoPayout(ctx context.Context, wallet *domain.Wallet, amount decimal.Decimal) (*domain.CryptoPayout, error) {
+	payout, _ := ctx.Value(cryptoPayoutKey{}).(*domain.CryptoPayout)
+	if wallet.Kind != domain.WalletKindCrypto {
+		if payout != nil {
+			return nil, fmt.Errorf("%w: only crypto wallets pay out on chain", util.ErrInvalidInput)
+		}
+		return nil, nil
+	}
+	if payout == nil || s.cryptoRepo == nil {
+		return nil, fmt.Errorf("%w: crypto wallets withdraw to an address through their crypto withdrawals", util.ErrInvalidInput)
+	}
+	if payout.WalletID != wallet.ID || !payout.Total().Equal(amount) {
+		return nil, fmt.Errorf("%w: the payout was requested for another withdrawal", util.ErrInvalidInput)
+	}
+	return payout, nil
+}
+
+// queuePayout queues the payout of a crypto withdrawal, if any, as paid for by transaction.
+func (s *walletService) queuePayout(ctx context.Context, q repository.DBExecutor, payout *domain.CryptoPayout, transaction *domain.Transaction) error {
+	if payout == nil {
+		return nil
+	}
+	payout.TransactionID = transaction.PublicID
+	if err := s.cryptoRepo.CreatePayout(ctx, q, payout); err != nil {
+		return fmt.Errorf("failed to queue crypto payout: %w", err)
+	}
+	return nil
+}
+
+// payoutDescription describes the WITHDRAWAL of a crypto payout, or returns nil for any other withdrawal.
+func payoutDescription(payout *domain.CryptoPayout) *string {
+	if payout == nil {
+		return nil
+	}
+	description := fmt.Sprintf("Crypto withdrawal to %s, network fee %s", payout.Address, payout.Fee.String())
+	return &description
+}
+
+// CryptoService defines the interface for crypto wallets: their deposit addresses, the detection and crediting
+// of on-chain deposits, and withdrawals sent on chain by the payout pipeline.
+type CryptoService interface {
+	// NewAddress issues a new deposit address to a crypto wallet.
+	NewAddress(ctx context.Context, wallet *domain.Wallet) (*domain.CryptoAddress, error)
+	ListAddresses(ctx context.Context, wallet *domain.Wallet) ([]domain.CryptoAddress, error)
+	ListDeposits(ctx context.Context, wallet *domain.Wallet, limit int) ([]domain.CryptoDeposit, error)
+	// EstimateFee returns the network fee a withdrawal of amount to address would be charged.
+	EstimateFee(ctx context.Context, wallet *domain.Wallet, address string, amount decimal.Decimal) (decimal.Decimal, error)
+	// RequestWithdrawal debits amount and the estimated network fee from a crypto wallet and queues the payout
+	// of amount to address.
+	RequestWithdrawal(ctx context.Context, wallet *domain.Wallet, address string, amount decimal.Decimal) (*domain.CryptoPayout, error)
+	GetPayout(ctx context.Context, wallet *domain.Wallet, publicID uuid.UUID) (*domain.CryptoPayout, error)
+	ListPayouts(ctx context.Context, wallet *domain.Wallet, limit int) ([]domain.CryptoPayout, error)
+	// PollDeposits records the deposits the chain gateway reports and credits those with enough
+	// confirmations. It returns the number of deposits credited.
+	PollDeposits(ctx context.Context) (int, error)
+	// RunPayouts sends the queued payouts and returns the number sent.
+	RunPayouts(ctx context.Context, now time.Time) (int, error)
+}
+
+// cryptoService implements CryptoService.
+type cryptoService struct {
+	dbBeginner      db.DBTxBeginner
+	dbExecutor      repository.DBExecutor
+	cryptoRepo      repository.CryptoRepository
+	walletRepo      repository.WalletRepository
+	balances        *BalanceWriter // Applies balance changes, on a shard for sharded wallets
+	transactionRepo repository.TransactionRepository
+	wallets         WalletService
+	gateway         ChainGateway       // Optional; without it crypto wallets can neither be funded nor withdrawn from
+	events          *TransactionEvents // Optional; credited deposits and refunds are published here
+	staleAfter      time.Duration      // A payout still SENDING after this is requeued
+	beginTx         db.BeginTxFunc
+	commitTx        db.CommitTxFunc
+	rollbackTx      db.RollbackTxFunc
+	logger          *slog.Logger
+}
+
+// NewCryptoService creates a new instance of CryptoService.
+func NewCryptoService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	cryptoRepo repository.CryptoRepository,
+	walletRepo repository.WalletRepository,
+	balances *BalanceWriter,
+	transactionRepo repository.TransactionRepository,
+	wallets WalletService,
+	gateway ChainGateway,
+	events *TransactionEvents,
+	staleAfter time.Duration,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) CryptoService {
+	return &cryptoService{
+		dbBeginner:      dbBeginner,
+		dbExecutor:      dbExecutor,
+		cryptoRepo:      cryptoRepo,
+		walletRepo:      walletRepo,
+		balances:        balances,
+		transactionRepo: transactionRepo,
+		wallets:         wallets,
+		gateway:         gateway,
+		events:          events,
+		staleAfter:      staleAfter,
+		beginTx:         beginTx,
+		commitTx:        commitTx,
+		rollbackTx:      rollbackTx,
+		logger:          logger,
+	}
+}
+
+// cryptoAsset returns the asset a crypto wallet holds; any other wallet fails with util.ErrInvalidInput.
+func (s *cryptoService) cryptoAsset(wallet *domain.Wallet) (domain.CryptoAsset, error) {
+	if s.gateway == nil {
+		return domain.CryptoAsset{}, fmt.Errorf("%w: crypto wallets are not enabled", util.ErrInvalidInput)
+	}
+	asset, ok := domain.LookupCryptoAsset(wallet.Currency)
+	if wallet.Kind != domain.WalletKindCrypto || !ok {
+		return domain.CryptoAsset{}, fmt.Errorf("%w: the wallet does not hold a crypto asset", util.ErrInvalidInput)
+	}
+	return asset, nil
+}
+
+// NewAddress asks the chain gateway for a new address and assigns it to the wallet.
+func (s *cryptoService) NewAddress(ctx context.Context, wallet *domain.Wallet) (*domain.CryptoAddress, error) {
+	asset, err := s.cryptoAsset(wallet)
+	if err != nil {
+		return nil, err
+	}
+	issued, err := s.gateway.NewAddress(ctx, asset, wallet.PublicID.String())
+	if err != nil {
+		return nil, fmt.Errorf("new crypto address: %w", err)
+	}
+
+	address := &domain.CryptoAddress{
+		WalletID:       wallet.ID,
+		WalletPublicID: wallet.PublicID,
+		Asset:          asset.Code,
+		Network:        asset.Network,
+		Address:        issued,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.cryptoRepo.CreateAddress(ctx, s.dbExecutor, address); err != nil {
+		return nil, fmt.Errorf("new crypto address: %w", err)
+	}
+	s.logger.Info("Crypto deposit address issued", "wallet_id", wallet.PublicID, "asset", asset.Code, "address", issued)
+	return address, nil
+}
+
+// ListAddresses retrieves the deposit addresses of a crypto wallet.
+func (s *cryptoService) ListAddresses(ctx context.Context, wallet *domain.Wallet) ([]domain.CryptoAddress, error) {
+	addresses, err := s.cryptoRepo.ListAddresses(ctx, s.dbExecutor, wallet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list crypto addresses: %w", err)
+	}
+	return addresses, nil
+}
+
+// ListDeposits retrieves the latest deposits of a crypto wallet, credited or not.
+func (s *cryptoService) ListDeposits(ctx context.Context, wallet *domain.Wallet, limit int) ([]domain.CryptoDeposit, error) {
+	deposits, err := s.cryptoRepo.ListDeposits(ctx, s.dbExecutor, wallet.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list crypto deposits: %w", err)
+	}
+	return deposits, nil
+}
+
+// checkWithdrawal validates a withdrawal of amount to address and returns the asset and the trimmed address.
+func (s *cryptoService) checkWithdrawal(wallet *domain.Wallet, address string, amount decimal.Decimal) (domain.CryptoAsset, string, error) {
+	asset, err := s.cryptoAsset(wallet)
+	if err != nil {
+		return asset, "", err
+	}
+	address = strings.TrimSpace(address)
+	if address == "" || len(address) > 128 {
+		return asset, "", fmt.Errorf("%w: address is required and may have at most 128 characters", util.ErrInvalidInput)
+	}
+	if !amount.IsPositive() || domain.AmountPlaces(amount) > asset.Places {
+		return asset, "", fmt.Errorf("%w: amount must be positive and have at most %d decimal places", util.ErrInvalidInput, asset.Places)
+	}
+	return asset, address, nil
+}
+
+// EstimateFee asks the chain gateway for the network fee, rounded up to the asset's places as it is charged.
+func (s *cryptoService) EstimateFee(ctx context.Context, wallet *domain.Wallet, address string, amount decimal.Decimal) (decimal.Decimal, error) {
+	asset, address, err := s.checkWithdrawal(wallet, address, amount)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	fee, err := s.gateway.EstimateFee(ctx, asset, address, amount)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("estimate crypto fee: %w", err)
+	}
+	return fee.RoundCeil(asset.Places), nil
+}
+
+// RequestWithdrawal estimates the network fee and withdraws amount and fee from the wallet with the payout
+// attached, so the withdrawal goes through every check of WalletService.Withdraw and queues the payout with
+// its debit. The wallet pays the fee estimated now, whatever the payout ends up costing on chain.
+func (s *cryptoService) RequestWithdrawal(ctx context.Context, wallet *domain.Wallet, address string, amount decimal.Decimal) (*domain.CryptoPayout, error) {
+	asset, address, err := s.checkWithdrawal(wallet, address, amount)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := s.gateway.EstimateFee(ctx, asset, address, amount)
+	if err != nil {
+		return nil, fmt.Errorf("request crypto withdrawal: %w", err)
+	}
+
+	payout := domain.NewCryptoPayout(wallet, asset, address, amount, fee.RoundCeil(asset.Places))
+	if _, _, err := s.wallets.Withdraw(withCryptoPayout(ctx, payout), wallet.ID, payout.Total(), wallet.Currency); err != nil {
+		return nil, fmt.Errorf("request crypto withdrawal: %w", err)
+	}
+	s.logger.Info("Crypto withdrawal queued", "payout_id", payout.PublicID, "wallet_id", wallet.PublicID,
+		"asset", asset.Code, "amount", amount.String(), "fee", payout.Fee.String(), "address", address)
+	return payout, nil
+}
+
+// GetPayout retrieves a payout of the wallet; a payout of another wallet is not found.
+func (s *cryptoService) GetPayout(ctx context.Context, wallet *domain.Wallet, publicID uuid.UUID) (*domain.CryptoPayout, error) {
+	payout, err := s.cryptoRepo.GetPayoutByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get crypto payout %s: %w", publicID, err)
+	}
+	if payout.WalletID != wallet.ID {
+		return nil, fmt.Errorf("get crypto payout %s: %w", publicID, util.ErrNotFound)
+	}
+	return payout, nil
+}
+
+// ListPayouts retrieves the latest payouts of a crypto wallet.
+func (s *cryptoService) ListPayouts(ctx context.Context, wallet *domain.Wallet, limit int) ([]domain.CryptoPayout, error) {
+	payouts, err := s.cryptoRepo.ListPayouts(ctx, s.dbExecutor, wallet.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list crypto payouts: %w", err)
+	}
+	return payouts, nil
+}
+
+// PollDeposits polls the chain gateway for the deposits of each asset. A deposit that cannot be recorded or
+// credited is logged and picked up again by the next poll, as the gateway keeps reporting it.
+func (s *cryptoService) PollDeposits(ctx context.Context) (int, error) {
+	if s.gateway == nil {
+		return 0, nil
+	}
+	credited := 0
+	var errs []error
+	for _, asset := range domain.CryptoAssets() {
+		reported, err := s.gateway.ListDeposits(ctx, asset)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, chainDeposit := range reported {
+			deposit, err := s.recordDeposit(ctx, asset, chainDeposit)
+			if err != nil {
+				s.logger.Error("Failed to record crypto deposit", "asset", asset.Code, "tx_hash", chainDeposit.TxHash,
+					"output_index", chainDeposit.OutputIndex, "error", err)
+				continue
+			}
+			if deposit == nil || deposit.Status != domain.CryptoDepositPending || deposit.Confirmations < asset.Confirmations {
+				continue
+			}
+			transaction, err := s.creditDeposit(ctx, deposit)
+			if err != nil {
+				s.logger.Error("Failed to credit crypto deposit", "asset", asset.Code, "tx_hash", deposit.TxHash,
+					"output_index", deposit.OutputIndex, "error", err)
+				continue
+			}
+			if transaction != nil {
+				credited++
+			}
+		}
+	}
+	return credited, errors.Join(errs...)
+}
+
+// recordDeposit stores a reported deposit, or its new confirmations, and returns the stored deposit. A deposit
+// to an address not issued here for the asset, or too small to be credited, is skipped and returns nil.
+func (s *cryptoService) recordDeposit(ctx context.Context, asset domain.CryptoAsset, chainDeposit ChainDeposit) (*domain.CryptoDeposit, error) {
+	address, err := s.cryptoRepo.GetAddress(ctx, s.dbExecutor, asset.Network, chainDeposit.Address)
+	if errors.Is(err, util.ErrNotFound) {
+		s.logger.Warn("Crypto deposit to an unknown address", "asset", asset.Code, "address", chainDeposit.Address,
+			"tx_hash", chainDeposit.TxHash)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if address.Asset != asset.Code {
+		// e.g. ETH sent to a USDC address; the funds are held by the platform until an operator reconciles them
+		s.logger.Error("Crypto deposit of another asset", "asset", asset.Code, "address_asset", address.Asset,
+			"address", address.Address, "tx_hash", chainDeposit.TxHash)
+		return nil, nil
+	}
+	amount := chainDeposit.Amount.Truncate(asset.Places)
+	if !amount.IsPositive() {
+		return nil, nil
+	}
+
+	deposit := &domain.CryptoDeposit{
+		WalletID:      address.WalletID,
+		Asset:         asset.Code,
+		Network:       asset.Network,
+		Address:       address.Address,
+		TxHash:        chainDeposit.TxHash,
+		OutputIndex:   chainDeposit.OutputIndex,
+		Amount:        amount,
+		Confirmations: chainDeposit.Confirmations,
+		DetectedAt:    time.Now().UTC(),
+	}
+	if err := s.cryptoRepo.RecordDeposit(ctx, s.dbExecutor, deposit); err != nil {
+		return nil, err
+	}
+	return deposit, nil
+}
+
+// creditDeposit credits a confirmed deposit as a DEPOSIT in one database transaction with marking it credited.
+// It returns nil without an error when the deposit was credited in the meantime, e.g. by another instance.
+func (s *cryptoService) creditDeposit(ctx context.Context, deposit *domain.CryptoDeposit) (*domain.Transaction, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("transaction controller does not implement DBExecutor")
+	}
+
+	locked, err := s.cryptoRepo.GetDepositForUpdate(ctx, txExecutor, deposit.ID)
+	if err != nil {
+		return nil, err
+	}
+	if locked.Status != domain.CryptoDepositPending {
+		return nil, nil
+	}
+	wallet, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, locked.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Currency != locked.Asset {
+		return nil, util.ErrCurrencyMismatch
+	}
+	if _, err := s.balances.Credit(ctx, txExecutor, wallet, locked.Amount); err != nil {
+		return nil, err
+	}
+	description := fmt.Sprintf("Crypto deposit, transaction %s:%d", locked.TxHash, locked.OutputIndex)
+	transaction := domain.NewTransaction(nil, &wallet.ID, locked.Amount, locked.Asset, domain.TransactionTypeDeposit, &description)
+	s.balances.Stamp(transaction)
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if err := s.cryptoRepo.MarkDepositCredited(ctx, txExecutor, locked.ID, transaction.PublicID, transaction.TransactionTime); err != nil {
+		return nil, err
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
+	s.logger.Info("Crypto deposit credited", "wallet_id", wallet.PublicID, "transaction_id", transaction.PublicID,
+		"asset", locked.Asset, "amount", locked.Amount.String(), "tx_hash", locked.TxHash)
+	return transaction, nil
+}
+
+// RunPayouts requeues the payouts a crashed worker left SENDING, then sends queued payouts one by one. A payout
+// that may be retried ends the run, as the gateway is unlikely to take the next one either.
+func (s *cryptoService) RunPayouts(ctx context.Context, now time.Time) (int, error) {
+	if s.gateway == nil {
+		return 0, nil
+	}
+	requeued, err := s.cryptoRepo.RequeueStalePayouts(ctx, s.dbExecutor, now.Add(-s.staleAfter))
+	if err != nil {
+		return 0, fmt.Errorf("run crypto payouts: %w", err)
+	}
+	if requeued > 0 {
+		s.logger.Warn("Requeued stale crypto payouts", "count", requeued)
+	}
+
+	sent := 0
+	for {
+		payout, err := s.cryptoRepo.ClaimNextPayout(ctx, s.dbExecutor, now)
+		if errors.Is(err, util.ErrNotFound) {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, fmt.Errorf("run crypto payouts: %w", err)
+		}
+		err = s.sendPayout(ctx, payout)
+		if errors.Is(err, ErrChainRejected) {
+			continue // Refunded
+		}
+		if err != nil {
+			return sent, fmt.Errorf("run crypto payouts: %w", err)
+		}
+		sent++
+	}
+}
+
+// sendPayout sends a claimed payout. The payout's public ID is the idempotency key, so a payout requeued after
+// it was sent, e.g. because recording it failed, is not sent twice. A rejected payout is refunded.
+func (s *cryptoService) sendPayout(ctx context.Context, payout *domain.CryptoPayout) error {
+	txHash, err := s.gateway.SendPayout(ctx, PayoutRequest{
+		Asset:          payout.Asset,
+		Network:        payout.Network,
+		Address:        payout.Address,
+		Amount:         payout.Amount,
+		IdempotencyKey: payout.PublicID.String(),
+	})
+	if errors.Is(err, ErrChainRejected) {
+		s.logger.Error("Crypto payout rejected", "payout_id", payout.PublicID, "wallet_id", payout.WalletPublicID, "error", err)
+		if refundErr := s.refundPayout(ctx, payout, err.Error()); refundErr != nil {
+			// Left SENDING, the payout is requeued once stale and its refund attempted again
+			s.logger.Error("Failed to refund rejected crypto payout", "payout_id", payout.PublicID, "error", refundErr)
+		}
+		return err
+	}
+	if err != nil {
+		s.logger.Warn("Crypto payout failed; it will be retried", "payout_id", payout.PublicID, "attempts", payout.Attempts, "error", err)
+		if requeueErr := s.cryptoRepo.RequeuePayout(ctx, s.dbExecutor, payout.ID, err.Error()); requeueErr != nil {
+			s.logger.Error("Failed to requeue crypto payout", "payout_id", payout.PublicID, "error", requeueErr)
+		}
+		return err
+	}
+
+	if err := s.cryptoRepo.MarkPayoutSent(ctx, s.dbExecutor, payout.ID, txHash); err != nil {
+		return fmt.Errorf("payout %s was sent in %s but not recorded: %w", payout.PublicID, txHash, err)
+	}
+	s.logger.Info("Crypto payout sent", "payout_id", payout.PublicID, "wallet_id", payout.WalletPublicID, "tx_hash", txHash)
+	return nil
+}
+
+// refundPayout credits the amount and fee of a rejected payout back to its wallet as a DEPOSIT pointing at the
+// WITHDRAWAL, in one database transaction with marking the payout failed.
+func (s *cryptoService) refundPayout(ctx context.Context, payout *domain.CryptoPayout, reason string) error {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return fmt.Errorf("transaction controller does not implement DBExecutor")
+	}
+
+	if _, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, payout.WalletID); err != nil {
+		return err
+	}
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, payout.WalletID, payout.Total()); err != nil {
+		return fmt.Errorf("failed to update wallet balance: %w", err)
+	}
+	description := fmt.Sprintf("Refund of rejected crypto withdrawal to %s", payout.Address)
+	refund := domain.NewTransaction(nil, &payout.WalletID, payout.Total(), payout.Asset, domain.TransactionTypeDeposit, &description)
+	s.balances.Stamp(refund)
+	refund.ParentID = &payout.TransactionID
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, refund); err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if err := s.cryptoRepo.MarkPayoutFailed(ctx, txExecutor, payout.ID, reason, refund.PublicID); err != nil {
+		return err
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if s.events != nil {
+		s.events.Publish(ctx, refund)
+	}
+	s.logger.Info("Rejected crypto payout refunded", "payout_id", payout.PublicID, "transaction_id", refund.PublicID,
+		"amount", payout.Total().String())
+	return nil
+}
@@ -0,0 +1,304 @@
+// internal/service/crypto_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestCryptoService tests the crediting of on-chain deposits and withdrawals through the payout pipeline.
+func TestCryptoService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	btc, _ := domain.LookupCryptoAsset("BTC")
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "BTC", Kind: domain.WalletKindCrypto, Balance: decimal.NewFromInt(2)}
+	address := &domain.CryptoAddress{ID: 3, WalletID: wallet.ID, WalletPublicID: wallet.PublicID, Asset: "BTC", Network: "bitcoin", Address: "bc1qdeposit"}
+
+	type mocks struct {
+		cryptoRepo      *MockCryptoRepository
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		gateway         *MockChainGateway
+		dbExecutor      *MockDBExecutor
+		txController    *MockTxController
+	}
+	newService := func() (CryptoService, mocks) {
+		m := mocks{
+			cryptoRepo:      new(MockCryptoRepository),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			gateway:         new(MockChainGateway),
+			dbExecutor:      new(MockDBExecutor),
+			txController:    new(MockTxController),
+		}
+		beginTx := func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+			return m.txController, nil
+		}
+		commitTx := func(tx db.TxController) error {
+			return m.txController.Commit()
+		}
+		rollbackTx := func(tx db.TxController) {
+			_ = m.txController.Rollback()
+		}
+		wallets := NewWalletService(new(MockDBBeginner), m.dbExecutor, new(MockUserRepository), m.walletRepo, m.transactionRepo,
+			beginTx, commitTx, rollbackTx, WithCryptoPayouts(m.cryptoRepo))
+		service := NewCryptoService(new(MockDBBeginner), m.dbExecutor, m.cryptoRepo, m.walletRepo, NewBalanceWriter(m.walletRepo, nil, nil, nil), m.transactionRepo, wallets,
+			m.gateway, nil, 10*time.Minute, beginTx, commitTx, rollbackTx, logger)
+		m.txController.On("Rollback").Return(nil).Maybe()
+		return service, m
+	}
+	// reportDeposits makes the gateway report the deposits for BTC and nothing for the other assets
+	reportDeposits := func(m mocks, deposits ...ChainDeposit) {
+		m.gateway.On("ListDeposits", mock.Anything, mock.MatchedBy(func(asset domain.CryptoAsset) bool { return asset.Code == "BTC" })).
+			Return(deposits, nil).Once()
+		m.gateway.On("ListDeposits", mock.Anything, mock.MatchedBy(func(asset domain.CryptoAsset) bool { return asset.Code != "BTC" })).
+			Return([]ChainDeposit{}, nil)
+	}
+	// recordAs stores a reported deposit as the pending deposit with the given ID
+	recordAs := func(id int64) func(mock.Arguments) {
+		return func(args mock.Arguments) {
+			deposit := args.Get(2).(*domain.CryptoDeposit)
+			deposit.ID = id
+			deposit.Status = domain.CryptoDepositPending
+		}
+	}
+	payout := func() *domain.CryptoPayout {
+		p := domain.NewCryptoPayout(wallet, btc, "bc1qexternal", decimal.RequireFromString("0.5"), decimal.RequireFromString("0.0001"))
+		p.ID = 9
+		p.TransactionID = uuid.New()
+		p.Status = domain.CryptoPayoutSending
+		return p
+	}
+
+	t.Run("PollDepositsCreditsConfirmedDeposit", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		// The gateway reports more places than BTC has; the dust is not credited
+		reportDeposits(m, ChainDeposit{Address: address.Address, TxHash: "f00d", OutputIndex: 1, Amount: decimal.RequireFromString("0.123456789"), Confirmations: 3})
+		credited := decimal.RequireFromString("0.12345678")
+		m.cryptoRepo.On("GetAddress", ctx, m.dbExecutor, "bitcoin", address.Address).Return(address, nil).Once()
+		var recorded *domain.CryptoDeposit
+		m.cryptoRepo.On("RecordDeposit", ctx, m.dbExecutor, mock.Anything).Run(func(args mock.Arguments) {
+			recordAs(5)(args)
+			recorded = args.Get(2).(*domain.CryptoDeposit)
+		}).Return(nil).Once()
+		m.cryptoRepo.On("GetDepositForUpdate", ctx, m.txController, int64(5)).
+			Return(&domain.CryptoDeposit{ID: 5, WalletID: wallet.ID, Asset: "BTC", TxHash: "f00d", OutputIndex: 1, Amount: credited, Status: domain.CryptoDepositPending}, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, wallet.ID, credited, mock.Anything).Return(&domain.WalletBalance{}, nil).Once()
+		var transaction *domain.Transaction
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.Anything).Run(func(args mock.Arguments) {
+			transaction = args.Get(2).(*domain.Transaction)
+		}).Return(nil).Once()
+		m.cryptoRepo.On("MarkDepositCredited", ctx, m.txController, int64(5), mock.Anything, mock.Anything).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		count, err := service.PollDeposits(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.True(t, recorded.Amount.Equal(credited))
+		assert.Equal(t, domain.TransactionTypeDeposit, transaction.Type)
+		assert.Equal(t, "BTC", transaction.Currency)
+		assert.Equal(t, wallet.ID, *transaction.ToWalletID)
+		m.cryptoRepo.AssertCalled(t, "MarkDepositCredited", ctx, m.txController, int64(5), transaction.PublicID, mock.Anything)
+	})
+
+	t.Run("PollDepositsWaitsForConfirmations", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		reportDeposits(m, ChainDeposit{Address: address.Address, TxHash: "f00d", Amount: decimal.NewFromInt(1), Confirmations: btc.Confirmations - 1})
+		m.cryptoRepo.On("GetAddress", ctx, m.dbExecutor, "bitcoin", address.Address).Return(address, nil).Once()
+		m.cryptoRepo.On("RecordDeposit", ctx, m.dbExecutor, mock.Anything).Run(recordAs(5)).Return(nil).Once()
+
+		count, err := service.PollDeposits(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		m.cryptoRepo.AssertNotCalled(t, "GetDepositForUpdate", mock.Anything, mock.Anything, mock.Anything)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PollDepositsSkipsUnknownAddress", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		reportDeposits(m, ChainDeposit{Address: "bc1qelsewhere", TxHash: "f00d", Amount: decimal.NewFromInt(1), Confirmations: 6})
+		m.cryptoRepo.On("GetAddress", ctx, m.dbExecutor, "bitcoin", "bc1qelsewhere").Return(nil, util.ErrNotFound).Once()
+
+		count, err := service.PollDeposits(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		m.cryptoRepo.AssertNotCalled(t, "RecordDeposit", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PollDepositsCreditsDepositOnce", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		reportDeposits(m, ChainDeposit{Address: address.Address, TxHash: "f00d", Amount: decimal.NewFromInt(1), Confirmations: 6})
+		m.cryptoRepo.On("GetAddress", ctx, m.dbExecutor, "bitcoin", address.Address).Return(address, nil).Once()
+		m.cryptoRepo.On("RecordDeposit", ctx, m.dbExecutor, mock.Anything).Run(recordAs(5)).Return(nil).Once()
+		// Another instance credited the deposit between recording and locking it
+		m.cryptoRepo.On("GetDepositForUpdate", ctx, m.txController, int64(5)).
+			Return(&domain.CryptoDeposit{ID: 5, WalletID: wallet.ID, Asset: "BTC", Status: domain.CryptoDepositCredited}, nil).Once()
+
+		count, err := service.PollDeposits(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("RequestWithdrawalQueuesPayoutWithDebit", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		amount := decimal.RequireFromString("0.5")
+		// The estimate is rounded up to the places the fee is charged with
+		m.gateway.On("EstimateFee", ctx, btc, "bc1qexternal", amount).Return(decimal.RequireFromString("0.000012341"), nil).Once()
+		fee := decimal.RequireFromString("0.00001235")
+		m.walletRepo.On("GetWalletByID", mock.Anything, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", mock.Anything, m.txController, wallet.ID, amount.Add(fee).Neg(), mock.Anything).Return(&domain.WalletBalance{}, nil).Once()
+		var transaction *domain.Transaction
+		m.transactionRepo.On("CreateTransaction", mock.Anything, m.txController, mock.Anything).Run(func(args mock.Arguments) {
+			transaction = args.Get(2).(*domain.Transaction)
+		}).Return(nil).Once()
+		var queued *domain.CryptoPayout
+		m.cryptoRepo.On("CreatePayout", mock.Anything, m.txController, mock.Anything).Run(func(args mock.Arguments) {
+			queued = args.Get(2).(*domain.CryptoPayout)
+		}).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		payout, err := service.RequestWithdrawal(ctx, wallet, " bc1qexternal ", amount)
+
+		require.NoError(t, err)
+		assert.Same(t, queued, payout)
+		assert.Equal(t, domain.CryptoPayoutQueued, payout.Status)
+		assert.True(t, payout.Fee.Equal(fee))
+		assert.Equal(t, transaction.PublicID, payout.TransactionID)
+		assert.Equal(t, domain.TransactionTypeWithdrawal, transaction.Type)
+		assert.True(t, transaction.Amount.Equal(amount.Add(fee)))
+		assert.Contains(t, *transaction.Description, "bc1qexternal")
+	})
+
+	t.Run("RequestWithdrawalRejectsInvalidAddress", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		amount := decimal.RequireFromString("0.5")
+		m.gateway.On("EstimateFee", ctx, btc, "not-an-address", amount).
+			Return(decimal.Zero, fmt.Errorf("%w: invalid address", util.ErrInvalidInput)).Once()
+
+		_, err := service.RequestWithdrawal(ctx, wallet, "not-an-address", amount)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RequestWithdrawalRejectsExcessPlaces", func(t *testing.T) {
+		service, m := newService()
+
+		_, err := service.RequestWithdrawal(context.Background(), wallet, "bc1qexternal", decimal.RequireFromString("0.123456789"))
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.gateway.AssertNotCalled(t, "EstimateFee", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PlainWithdrawalFromCryptoWalletIsRejected", func(t *testing.T) {
+		ctx := context.Background()
+		_, m := newService()
+		wallets := NewWalletService(new(MockDBBeginner), m.dbExecutor, new(MockUserRepository), m.walletRepo, m.transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return m.txController, nil },
+			func(tx db.TxController) error { return m.txController.Commit() },
+			func(tx db.TxController) { _ = m.txController.Rollback() },
+			WithCryptoPayouts(m.cryptoRepo))
+		m.walletRepo.On("GetWalletByID", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+
+		_, _, err := wallets.Withdraw(ctx, wallet.ID, decimal.NewFromInt(1), "BTC")
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RunPayoutsSendsQueuedPayouts", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		queued := payout()
+		m.cryptoRepo.On("RequeueStalePayouts", ctx, m.dbExecutor, now.Add(-10*time.Minute)).Return(int64(0), nil).Once()
+		m.cryptoRepo.On("ClaimNextPayout", ctx, m.dbExecutor, now).Return(queued, nil).Once()
+		m.cryptoRepo.On("ClaimNextPayout", ctx, m.dbExecutor, now).Return(nil, util.ErrNotFound).Once()
+		m.gateway.On("SendPayout", ctx, PayoutRequest{
+			Asset:          "BTC",
+			Network:        "bitcoin",
+			Address:        "bc1qexternal",
+			Amount:         queued.Amount,
+			IdempotencyKey: queued.PublicID.String(),
+		}).Return("beef", nil).Once()
+		m.cryptoRepo.On("MarkPayoutSent", ctx, m.dbExecutor, queued.ID, "beef").Return(nil).Once()
+
+		sent, err := service.RunPayouts(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		m.cryptoRepo.AssertExpectations(t)
+	})
+
+	t.Run("RunPayoutsRefundsRejectedPayout", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		rejected := payout()
+		m.cryptoRepo.On("RequeueStalePayouts", ctx, m.dbExecutor, mock.Anything).Return(int64(0), nil).Once()
+		m.cryptoRepo.On("ClaimNextPayout", ctx, m.dbExecutor, now).Return(rejected, nil).Once()
+		m.cryptoRepo.On("ClaimNextPayout", ctx, m.dbExecutor, now).Return(nil, util.ErrNotFound).Once()
+		m.gateway.On("SendPayout", ctx, mock.Anything).Return("", fmt.Errorf("%w: invalid address", ErrChainRejected)).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, wallet.ID, rejected.Total(), mock.Anything).Return(&domain.WalletBalance{}, nil).Once()
+		var refund *domain.Transaction
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.Anything).Run(func(args mock.Arguments) {
+			refund = args.Get(2).(*domain.Transaction)
+		}).Return(nil).Once()
+		m.cryptoRepo.On("MarkPayoutFailed", ctx, m.txController, rejected.ID, mock.Anything, mock.Anything).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		sent, err := service.RunPayouts(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		assert.Equal(t, domain.TransactionTypeDeposit, refund.Type)
+		assert.True(t, refund.Amount.Equal(rejected.Total()))
+		assert.Equal(t, rejected.TransactionID, *refund.ParentID)
+		m.cryptoRepo.AssertCalled(t, "MarkPayoutFailed", ctx, m.txController, rejected.ID, mock.Anything, refund.PublicID)
+	})
+
+	t.Run("RunPayoutsRequeuesRetriableFailure", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		failing := payout()
+		m.cryptoRepo.On("RequeueStalePayouts", ctx, m.dbExecutor, mock.Anything).Return(int64(0), nil).Once()
+		m.cryptoRepo.On("ClaimNextPayout", ctx, m.dbExecutor, now).Return(failing, nil).Once()
+		m.gateway.On("SendPayout", ctx, mock.Anything).Return("", errors.New("connection refused")).Once()
+		m.cryptoRepo.On("RequeuePayout", ctx, m.dbExecutor, failing.ID, "connection refused").Return(nil).Once()
+
+		sent, err := service.RunPayouts(ctx, now)
+
+		// The run ends at the first retriable failure rather than claiming the requeued payout again
+		assert.Error(t, err)
+		assert.Equal(t, 0, sent)
+		m.cryptoRepo.AssertNumberOfCalls(t, "ClaimNextPayout", 1)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,270 @@
+// internal/service/deletion_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// maxWalletsPerUser bounds the wallet lookup when a user is deleted; a user has at most one wallet per currency.
+const maxWalletsPerUser = 1000
+
+// DeletionService defines the interface for soft-deleting users and wallets, restoring them, and purging them
+// once they have been deleted for longer than the retention period.
+type DeletionService interface {
+	// DeleteUser soft-deletes a user together with their wallets. Every wallet must be empty.
+	DeleteUser(ctx context.Context, userID int64) error
+	// DeleteWallet soft-deletes an empty wallet.
+	DeleteWallet(ctx context.Context, walletID int64) error
+	// RestoreUser restores a soft-deleted user and the wallets that were deleted with them.
+	RestoreUser(ctx context.Context, userID int64) (*domain.User, error)
+	// RestoreWallet restores a soft-deleted wallet of a user who is not deleted.
+	RestoreWallet(ctx context.Context, publicID uuid.UUID) (*domain.Wallet, error)
+	// Purge permanently deletes the users and wallets deleted before now minus the retention period,
+	// and returns the number of rows purged.
+	Purge(ctx context.Context, now time.Time) (int, error)
+}
+
+// deletionService implements DeletionService.
+type deletionService struct {
+	dbBeginner db.DBTxBeginner
+	dbExecutor repository.DBExecutor
+	userRepo   repository.UserRepository
+	walletRepo repository.WalletRepository
+	retention  time.Duration // How long deleted rows can still be restored
+	beginTx    db.BeginTxFunc
+	commitTx   db.CommitTxFunc
+	rollbackTx db.RollbackTxFunc
+	logger     *slog.Logger
+}
+
+// NewDeletionService creates a new instance of DeletionService.
+func NewDeletionService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	userRepo repository.UserRepository,
+	walletRepo repository.WalletRepository,
+	retention time.Duration,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) DeletionService {
+	return &deletionService{
+		dbBeginner: dbBeginner,
+		dbExecutor: dbExecutor,
+		userRepo:   userRepo,
+		walletRepo: walletRepo,
+		retention:  retention,
+		beginTx:    beginTx,
+		commitTx:   commitTx,
+		rollbackTx: rollbackTx,
+		logger:     logger,
+	}
+}
+
+// DeleteUser locks each of the user's wallets to check it is empty, so no money can arrive between the check
+// and the deletion. The user and the wallets share one deletion time, which RestoreUser relies on.
+func (s *deletionService) DeleteUser(ctx context.Context, userID int64) error {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return fmt.Errorf("delete user: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return fmt.Errorf("delete user: transaction controller does not implement DBExecutor")
+	}
+
+	if _, err := s.userRepo.GetUserByID(ctx, txExecutor, userID); err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return util.ErrUserNotFound
+		}
+		return fmt.Errorf("delete user: %w", err)
+	}
+	wallets, _, err := s.walletRepo.ListWallets(ctx, txExecutor, repository.WalletFilter{UserID: &userID}, repository.ListOptions{Limit: maxWalletsPerUser})
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	for _, wallet := range wallets {
+		locked, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, wallet.ID)
+		if err != nil {
+			return fmt.Errorf("delete user: %w", err)
+		}
+		if !locked.Balance.IsZero() {
+			return util.ErrWalletNotEmpty
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := s.walletRepo.SoftDeleteWalletsByUser(ctx, txExecutor, userID, now); err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	if err := s.userRepo.SoftDeleteUser(ctx, txExecutor, userID, now); err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return fmt.Errorf("delete user: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("User deleted", "user_id", userID, "wallets", len(wallets))
+	return nil
+}
+
+// DeleteWallet locks the wallet to check it is empty before deleting it.
+func (s *deletionService) DeleteWallet(ctx context.Context, walletID int64) error {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return fmt.Errorf("delete wallet: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return fmt.Errorf("delete wallet: transaction controller does not implement DBExecutor")
+	}
+
+	wallet, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, walletID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return util.ErrWalletNotFound
+		}
+		return fmt.Errorf("delete wallet: %w", err)
+	}
+	if !wallet.Balance.IsZero() {
+		return util.ErrWalletNotEmpty
+	}
+	if err := s.walletRepo.SoftDeleteWallet(ctx, txExecutor, walletID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("delete wallet: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return fmt.Errorf("delete wallet: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Wallet deleted", "wallet_id", wallet.PublicID)
+	return nil
+}
+
+// RestoreUser restores the user and the wallets deleted at the same time. Wallets the user had deleted
+// before stay deleted.
+func (s *deletionService) RestoreUser(ctx context.Context, userID int64) (*domain.User, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("restore user: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("restore user: transaction controller does not implement DBExecutor")
+	}
+
+	deletedAt, err := s.userRepo.RestoreUser(ctx, txExecutor, userID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("restore user: %w", err)
+	}
+	if err := s.walletRepo.RestoreWalletsByUser(ctx, txExecutor, userID, deletedAt); err != nil {
+		return nil, fmt.Errorf("restore user: %w", err)
+	}
+	user, err := s.userRepo.GetUserByID(ctx, txExecutor, userID)
+	if err != nil {
+		return nil, fmt.Errorf("restore user: failed to re-fetch user %d: %w", userID, err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("restore user: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("User restored", "user_id", userID, "deleted_at", deletedAt)
+	return user, nil
+}
+
+// RestoreWallet restores a wallet; the wallet of a deleted user is restored by restoring the user.
+func (s *deletionService) RestoreWallet(ctx context.Context, publicID uuid.UUID) (*domain.Wallet, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("restore wallet: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("restore wallet: transaction controller does not implement DBExecutor")
+	}
+
+	wallet, err := s.walletRepo.RestoreWallet(ctx, txExecutor, publicID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return nil, util.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("restore wallet: %w", err)
+	}
+	if _, err := s.userRepo.GetUserByID(ctx, txExecutor, wallet.UserID); err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return nil, fmt.Errorf("%w: the wallet's user is deleted; restore the user instead", util.ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("restore wallet: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("restore wallet: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Wallet restored", "wallet_id", wallet.PublicID)
+	return wallet, nil
+}
+
+// Purge deletes each expired row on its own, wallets before users. Wallets still referenced by transactions
+// or other financial records, and users who still have wallets, are kept: the records must outlive the
+// retention period, and purging is retried on every run.
+func (s *deletionService) Purge(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.UTC().Add(-s.retention)
+	purged := 0
+
+	walletIDs, err := s.walletRepo.ListDeletedWalletIDs(ctx, s.dbExecutor, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge: %w", err)
+	}
+	for _, walletID := range walletIDs {
+		if err := s.walletRepo.PurgeWallet(ctx, s.dbExecutor, walletID); err != nil {
+			if errors.Is(err, util.ErrReferenceViolation) {
+				s.logger.Debug("Deleted wallet kept, still referenced", "wallet_id", walletID)
+				continue
+			}
+			return purged, fmt.Errorf("purge: %w", err)
+		}
+		purged++
+	}
+
+	userIDs, err := s.userRepo.ListDeletedUserIDs(ctx, s.dbExecutor, cutoff)
+	if err != nil {
+		return purged, fmt.Errorf("purge: %w", err)
+	}
+	for _, userID := range userIDs {
+		if err := s.userRepo.PurgeUser(ctx, s.dbExecutor, userID); err != nil {
+			if errors.Is(err, util.ErrReferenceViolation) {
+				s.logger.Debug("Deleted user kept, still referenced", "user_id", userID)
+				continue
+			}
+			return purged, fmt.Errorf("purge: %w", err)
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		s.logger.Info("Deleted records purged", "count", purged, "deleted_before", cutoff)
+	}
+	return purged, nil
+}
@@ -0,0 +1,170 @@
+// internal/service/deletion_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestDeletionService tests soft deletion, restore, and the purge of users and wallets past retention.
+func TestDeletionService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	retention := 90 * 24 * time.Hour
+
+	type mocks struct {
+		userRepo     *MockUserRepository
+		walletRepo   *MockWalletRepository
+		dbExecutor   *MockDBExecutor
+		txController *MockTxController
+	}
+	newService := func() (DeletionService, mocks) {
+		m := mocks{
+			userRepo:     new(MockUserRepository),
+			walletRepo:   new(MockWalletRepository),
+			dbExecutor:   new(MockDBExecutor),
+			txController: new(MockTxController),
+		}
+		service := NewDeletionService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.userRepo,
+			m.walletRepo,
+			retention,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+
+	t.Run("DeleteUserDeletesEmptyWalletsAtTheSameTime", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		userID := int64(10)
+		wallet := domain.Wallet{ID: 1, UserID: userID, Currency: "USD", Balance: decimal.Zero}
+
+		m.userRepo.On("GetUserByID", ctx, m.txController, userID).Return(&domain.User{ID: userID}, nil).Once()
+		m.walletRepo.On("ListWallets", ctx, m.txController, repository.WalletFilter{UserID: &userID}, mock.Anything).
+			Return([]domain.Wallet{wallet}, int64(1), nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(&wallet, nil).Once()
+		var walletsDeletedAt time.Time
+		m.walletRepo.On("SoftDeleteWalletsByUser", ctx, m.txController, userID, mock.Anything).
+			Run(func(args mock.Arguments) { walletsDeletedAt = args.Get(3).(time.Time) }).Return(nil).Once()
+		m.userRepo.On("SoftDeleteUser", ctx, m.txController, userID, mock.MatchedBy(func(at time.Time) bool {
+			return at.Equal(walletsDeletedAt)
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		err := service.DeleteUser(ctx, userID)
+
+		assert.NoError(t, err)
+		mock.AssertExpectationsForObjects(t, m.userRepo, m.walletRepo, m.txController)
+	})
+
+	t.Run("DeleteUserRejectsFundedWallet", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		userID := int64(10)
+		wallet := domain.Wallet{ID: 1, UserID: userID, Currency: "USD", Balance: decimal.NewFromInt(3)}
+
+		m.userRepo.On("GetUserByID", ctx, m.txController, userID).Return(&domain.User{ID: userID}, nil).Once()
+		m.walletRepo.On("ListWallets", ctx, m.txController, repository.WalletFilter{UserID: &userID}, mock.Anything).
+			Return([]domain.Wallet{wallet}, int64(1), nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(&wallet, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		err := service.DeleteUser(ctx, userID)
+
+		assert.ErrorIs(t, err, util.ErrWalletNotEmpty)
+		m.userRepo.AssertNotCalled(t, "SoftDeleteUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("DeleteWalletRejectsFundedWallet", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		wallet := &domain.Wallet{ID: 1, UserID: 10, Currency: "USD", Balance: decimal.NewFromFloat(0.01)}
+
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		err := service.DeleteWallet(ctx, wallet.ID)
+
+		assert.ErrorIs(t, err, util.ErrWalletNotEmpty)
+		m.walletRepo.AssertNotCalled(t, "SoftDeleteWallet", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RestoreUserRestoresWalletsDeletedWithThem", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		userID := int64(10)
+		deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		m.userRepo.On("RestoreUser", ctx, m.txController, userID).Return(deletedAt, nil).Once()
+		m.walletRepo.On("RestoreWalletsByUser", ctx, m.txController, userID, deletedAt).Return(nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.txController, userID).Return(&domain.User{ID: userID}, nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		user, err := service.RestoreUser(ctx, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, userID, user.ID)
+		mock.AssertExpectationsForObjects(t, m.userRepo, m.walletRepo, m.txController)
+	})
+
+	t.Run("RestoreWalletOfDeletedUserIsRejected", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD"}
+
+		m.walletRepo.On("RestoreWallet", ctx, m.txController, wallet.PublicID).Return(wallet, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.txController, wallet.UserID).Return(nil, util.ErrNotFound).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, err := service.RestoreWallet(ctx, wallet.PublicID)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("PurgeSkipsReferencedRows", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		now := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+		cutoff := now.Add(-retention)
+
+		m.walletRepo.On("ListDeletedWalletIDs", ctx, m.dbExecutor, cutoff).Return([]int64{1, 2}, nil).Once()
+		m.walletRepo.On("PurgeWallet", ctx, m.dbExecutor, int64(1)).Return(util.ErrReferenceViolation).Once()
+		m.walletRepo.On("PurgeWallet", ctx, m.dbExecutor, int64(2)).Return(nil).Once()
+		m.userRepo.On("ListDeletedUserIDs", ctx, m.dbExecutor, cutoff).Return([]int64{10}, nil).Once()
+		m.userRepo.On("PurgeUser", ctx, m.dbExecutor, int64(10)).Return(util.ErrReferenceViolation).Once()
+
+		purged, err := service.Purge(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, purged)
+		mock.AssertExpectationsForObjects(t, m.userRepo, m.walletRepo)
+	})
+}
@@ -0,0 +1,91 @@
+// internal/service/description_service.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/i18n"
+)
+
+// DescriptionRenderer renders readable descriptions of transactions, such as "Transfer to alice", for
+// statements and histories. The stored transaction, including its own description, is left as it is.
+type DescriptionRenderer interface {
+	// Describe renders the transaction in the context's locale as seen from the given wallet: a transfer
+	// out of it names the recipient, one into it the sender. A walletID of 0 renders it from neither side.
+	Describe(ctx context.Context, transaction *domain.Transaction, walletID int64) string
+}
+
+// DescriptionTemplates overrides the built-in description templates: locale -> template key -> template.
+// Keys are "transaction_" and the lower-case transaction type, optionally with an ".out" or ".in" variant
+// for either side of the transaction, e.g. "transaction_deposit" or "transaction_transfer.out".
+// Templates may use {counterparty}, {category} and {note}, the transaction's own description.
+type DescriptionTemplates map[string]map[string]string
+
+// LoadDescriptionTemplates reads DescriptionTemplates from a JSON file. An empty path loads none.
+func LoadDescriptionTemplates(path string) (DescriptionTemplates, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read description templates: %w", err)
+	}
+	var templates DescriptionTemplates
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("invalid description templates %s: %w", path, err)
+	}
+	return templates, nil
+}
+
+// descriptionRenderer implements DescriptionRenderer with the templates of the i18n bundles, overridden
+// per locale by configured templates.
+type descriptionRenderer struct {
+	overrides DescriptionTemplates
+}
+
+// NewDescriptionRenderer creates a new instance of DescriptionRenderer.
+func NewDescriptionRenderer(overrides DescriptionTemplates) DescriptionRenderer {
+	return &descriptionRenderer{overrides: overrides}
+}
+
+// Describe picks the variant for the wallet's side when the counterparty is known, and otherwise the plain
+// template. A type without any template is rendered as the type itself.
+func (r *descriptionRenderer) Describe(ctx context.Context, transaction *domain.Transaction, walletID int64) string {
+	key := "transaction_" + strings.ToLower(string(transaction.Type))
+	params := map[string]string{
+		"category": optionalString(transaction.Category),
+		"note":     optionalString(transaction.Description),
+	}
+
+	keys := []string{key}
+	switch {
+	case walletID == 0:
+	case transaction.FromWalletID != nil && *transaction.FromWalletID == walletID && transaction.ToUsername != nil:
+		keys = []string{key + ".out", key}
+		params["counterparty"] = *transaction.ToUsername
+	case transaction.ToWalletID != nil && *transaction.ToWalletID == walletID && transaction.FromUsername != nil:
+		keys = []string{key + ".in", key}
+		params["counterparty"] = *transaction.FromUsername
+	}
+
+	locale := i18n.LocaleFromContext(ctx)
+	for _, k := range keys {
+		if template, ok := r.template(locale, k); ok {
+			return i18n.Format(template, params)
+		}
+	}
+	return string(transaction.Type)
+}
+
+// template returns the configured template for the locale, or else the built-in one.
+func (r *descriptionRenderer) template(locale, key string) (string, bool) {
+	if template, ok := r.overrides[locale][key]; ok {
+		return template, true
+	}
+	return i18n.Lookup(locale, key)
+}
@@ -0,0 +1,53 @@
+// internal/service/description_service_test.go
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/i18n"
+)
+
+// TestDescriptionRenderer tests the sides, locales and overrides of rendered transaction descriptions.
+func TestDescriptionRenderer(t *testing.T) {
+	from, to := int64(1), int64(2)
+	alice, bob := "alice", "bob"
+	transfer := &domain.Transaction{Type: domain.TransactionTypeTransfer, FromWalletID: &from, ToWalletID: &to, FromUsername: &alice, ToUsername: &bob}
+	deposit := &domain.Transaction{Type: domain.TransactionTypeDeposit, ToWalletID: &to}
+	ctx := context.Background()
+	de := i18n.WithLocale(ctx, "de")
+
+	t.Run("BuiltInTemplates", func(t *testing.T) {
+		renderer := NewDescriptionRenderer(nil)
+
+		assert.Equal(t, "Transfer to bob", renderer.Describe(ctx, transfer, from))
+		assert.Equal(t, "Transfer from alice", renderer.Describe(ctx, transfer, to))
+		assert.Equal(t, "Transfer", renderer.Describe(ctx, transfer, 0))
+		assert.Equal(t, "Überweisung an bob", renderer.Describe(de, transfer, from))
+		assert.Equal(t, "Top-up", renderer.Describe(ctx, deposit, to))
+		assert.Equal(t, "MYSTERY", renderer.Describe(ctx, &domain.Transaction{Type: "MYSTERY"}, 0))
+	})
+
+	t.Run("UnknownCounterpartyUsesPlainTemplate", func(t *testing.T) {
+		renderer := NewDescriptionRenderer(nil)
+		deleted := &domain.Transaction{Type: domain.TransactionTypeTransfer, FromWalletID: &from, ToWalletID: &to}
+
+		assert.Equal(t, "Transfer", renderer.Describe(ctx, deleted, from))
+	})
+
+	t.Run("OverridesPerLocale", func(t *testing.T) {
+		category := "groceries"
+		renderer := NewDescriptionRenderer(DescriptionTemplates{
+			"en": {"transaction_deposit": "Card top-up", "transaction_transfer.out": "Sent to {counterparty} ({category})"},
+		})
+		categorized := *transfer
+		categorized.Category = &category
+
+		assert.Equal(t, "Card top-up", renderer.Describe(ctx, deposit, to))
+		assert.Equal(t, "Sent to bob (groceries)", renderer.Describe(ctx, &categorized, from))
+		assert.Equal(t, "Aufladung", renderer.Describe(de, deposit, to)) // Not overridden for de
+	})
+}
@@ -0,0 +1,174 @@
+// internal/service/dormancy.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// dormancyBatchSize bounds the wallets flagged per statement, so a first run over a large backlog does not
+// hold row locks for long.
+const dormancyBatchSize = 500
+
+// checkNotFrozen rejects moving money out of a wallet frozen for dormancy or for a pending ownership
+// transfer. Money can still be paid in.
+func checkNotFrozen(wallet *domain.Wallet) error {
+	if wallet.FrozenAt != nil {
+		return fmt.Errorf("%w: wallet %s", util.ErrWalletFrozen, wallet.PublicID)
+	}
+	if wallet.OwnershipFrozenAt != nil {
+		return fmt.Errorf("%w: wallet %s", util.ErrOwnershipTransferFrozen, wallet.PublicID)
+	}
+	return nil
+}
+
+// DormancyPolicy decides when a wallet becomes dormant and what happens to it then.
+type DormancyPolicy struct {
+	InactiveMonths int  // Months without a balance change after which a wallet is dormant
+	Freeze         bool // Freeze dormant wallets until they are reactivated
+}
+
+// DormancyService defines the interface for flagging wallets without activity as dormant, listing them and
+// reactivating them.
+type DormancyService interface {
+	// FlagDormant flags the wallets without activity for the policy's number of months as dormant as of now,
+	// freezing them if the policy says so, and returns how many it flagged.
+	FlagDormant(ctx context.Context, now time.Time) (int64, error)
+	// ListDormant retrieves a page of the dormant wallets and their total count.
+	ListDormant(ctx context.Context, opts repository.ListOptions) ([]domain.Wallet, int64, error)
+	// Reactivate lifts the dormancy flag and freeze of a wallet. The owner must have verified their email or
+	// phone since the wallet went dormant, else it fails with util.ErrVerificationRequired.
+	Reactivate(ctx context.Context, walletID int64) (*domain.Wallet, error)
+}
+
+// dormancyService implements DormancyService.
+type dormancyService struct {
+	dbBeginner db.DBTxBeginner
+	dbExecutor repository.DBExecutor
+	userRepo   repository.UserRepository
+	walletRepo repository.WalletRepository
+	policy     DormancyPolicy
+	beginTx    db.BeginTxFunc
+	commitTx   db.CommitTxFunc
+	rollbackTx db.RollbackTxFunc
+	logger     *slog.Logger
+}
+
+// NewDormancyService creates a new instance of DormancyService.
+func NewDormancyService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	userRepo repository.UserRepository,
+	walletRepo repository.WalletRepository,
+	policy DormancyPolicy,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) DormancyService {
+	return &dormancyService{
+		dbBeginner: dbBeginner,
+		dbExecutor: dbExecutor,
+		userRepo:   userRepo,
+		walletRepo: walletRepo,
+		policy:     policy,
+		beginTx:    beginTx,
+		commitTx:   commitTx,
+		rollbackTx: rollbackTx,
+		logger:     logger,
+	}
+}
+
+// FlagDormant flags the wallets in batches until none is left.
+func (s *dormancyService) FlagDormant(ctx context.Context, now time.Time) (int64, error) {
+	if s.policy.InactiveMonths <= 0 {
+		return 0, nil
+	}
+	inactiveSince := now.AddDate(0, -s.policy.InactiveMonths, 0)
+	var total int64
+	for {
+		flagged, err := s.walletRepo.FlagDormantWallets(ctx, s.dbExecutor, inactiveSince, now, s.policy.Freeze, dormancyBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("flag dormant wallets: %w", err)
+		}
+		total += flagged
+		if flagged < dormancyBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		s.logger.Info("Dormant wallets flagged", "count", total, "inactive_since", inactiveSince, "frozen", s.policy.Freeze)
+	}
+	return total, nil
+}
+
+// ListDormant retrieves a page of the dormant wallets.
+func (s *dormancyService) ListDormant(ctx context.Context, opts repository.ListOptions) ([]domain.Wallet, int64, error) {
+	wallets, total, err := s.walletRepo.ListWallets(ctx, s.dbExecutor, repository.WalletFilter{Dormant: true}, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list dormant wallets: %w", err)
+	}
+	return wallets, total, nil
+}
+
+// Reactivate locks the wallet, so a concurrent run of the dormancy job cannot interleave. A wallet that is not
+// dormant is returned as it is.
+func (s *dormancyService) Reactivate(ctx context.Context, walletID int64) (*domain.Wallet, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("reactivate wallet: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("reactivate wallet: transaction controller does not implement DBExecutor")
+	}
+
+	wallet, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, walletID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return nil, util.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("reactivate wallet: %w", err)
+	}
+	if wallet.DormantSince == nil {
+		return wallet, nil
+	}
+	user, err := s.userRepo.GetUserByID(ctx, txExecutor, wallet.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("reactivate wallet: failed to get owner %d: %w", wallet.UserID, err)
+	}
+	if !verifiedSince(user, *wallet.DormantSince) {
+		return nil, util.ErrVerificationRequired
+	}
+
+	reactivated, err := s.walletRepo.ReactivateWallet(ctx, txExecutor, walletID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("reactivate wallet: %w", err)
+	}
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("reactivate wallet: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Dormant wallet reactivated", "wallet_id", reactivated.PublicID, "dormant_since", *wallet.DormantSince)
+	return reactivated, nil
+}
+
+// verifiedSince reports whether the user verified their email or phone after the given time. Confirming a
+// verification code again refreshes the verification time, so an already verified contact can be re-verified.
+func verifiedSince(user *domain.User, since time.Time) bool {
+	for _, verifiedAt := range []*time.Time{user.EmailVerifiedAt, user.PhoneVerifiedAt} {
+		if verifiedAt != nil && verifiedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+// internal/service/dormancy_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestDormancyService tests flagging inactive wallets as dormant, the freeze of dormant wallets and their
+// reactivation after a fresh contact verification.
+func TestDormancyService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC)
+
+	type mocks struct {
+		userRepo     *MockUserRepository
+		walletRepo   *MockWalletRepository
+		dbExecutor   *MockDBExecutor
+		txController *MockTxController
+	}
+	newService := func(policy DormancyPolicy) (DormancyService, mocks) {
+		m := mocks{
+			userRepo:     new(MockUserRepository),
+			walletRepo:   new(MockWalletRepository),
+			dbExecutor:   new(MockDBExecutor),
+			txController: new(MockTxController),
+		}
+		service := NewDormancyService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.userRepo,
+			m.walletRepo,
+			policy,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+
+	t.Run("FlagDormantRunsBatchesUntilDone", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(DormancyPolicy{InactiveMonths: 12, Freeze: true})
+		inactiveSince := time.Date(2025, 10, 16, 3, 0, 0, 0, time.UTC)
+
+		m.walletRepo.On("FlagDormantWallets", ctx, m.dbExecutor, inactiveSince, now, true, dormancyBatchSize).
+			Return(int64(dormancyBatchSize), nil).Once()
+		m.walletRepo.On("FlagDormantWallets", ctx, m.dbExecutor, inactiveSince, now, true, dormancyBatchSize).
+			Return(int64(3), nil).Once()
+
+		flagged, err := service.FlagDormant(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(dormancyBatchSize+3), flagged)
+		m.walletRepo.AssertExpectations(t)
+	})
+
+	t.Run("FlagDormantDisabled", func(t *testing.T) {
+		service, m := newService(DormancyPolicy{})
+
+		flagged, err := service.FlagDormant(context.Background(), now)
+
+		assert.NoError(t, err)
+		assert.Zero(t, flagged)
+		m.walletRepo.AssertNotCalled(t, "FlagDormantWallets", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ReactivateRequiresVerificationSinceDormancy", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(DormancyPolicy{InactiveMonths: 12, Freeze: true})
+		dormantSince := now.AddDate(0, 0, -7)
+		verifiedAt := dormantSince.AddDate(-1, 0, 0)
+		wallet := &domain.Wallet{ID: 1, UserID: 10, DormantSince: &dormantSince, FrozenAt: &dormantSince}
+
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.txController, wallet.UserID).Return(&domain.User{ID: wallet.UserID, EmailVerifiedAt: &verifiedAt}, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, err := service.Reactivate(ctx, wallet.ID)
+
+		assert.ErrorIs(t, err, util.ErrVerificationRequired)
+		m.walletRepo.AssertNotCalled(t, "ReactivateWallet", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ReactivateAfterFreshVerification", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(DormancyPolicy{InactiveMonths: 12, Freeze: true})
+		dormantSince := now.AddDate(0, 0, -7)
+		verifiedAt := now.Add(-time.Minute)
+		wallet := &domain.Wallet{ID: 1, UserID: 10, DormantSince: &dormantSince, FrozenAt: &dormantSince}
+		reactivated := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10}
+
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.txController, wallet.UserID).Return(&domain.User{ID: wallet.UserID, PhoneVerifiedAt: &verifiedAt}, nil).Once()
+		m.walletRepo.On("ReactivateWallet", ctx, m.txController, wallet.ID, mock.AnythingOfType("time.Time")).Return(reactivated, nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		result, err := service.Reactivate(ctx, wallet.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, reactivated, result)
+		mock.AssertExpectationsForObjects(t, m.userRepo, m.walletRepo, m.txController)
+	})
+
+	t.Run("ReactivateActiveWalletChangesNothing", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(DormancyPolicy{InactiveMonths: 12})
+		wallet := &domain.Wallet{ID: 1, UserID: 10}
+
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		result, err := service.Reactivate(ctx, wallet.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, wallet, result)
+		m.userRepo.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("FrozenWalletCannotBeDebited", func(t *testing.T) {
+		ctx := context.Background()
+		walletRepo, txController := new(MockWalletRepository), new(MockTxController)
+		walletService := NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
+			walletRepo,
+			new(MockTransactionRepository),
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return txController, nil
+			},
+			func(tx db.TxController) error {
+				return txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = txController.Rollback()
+			},
+		)
+		frozenAt := now.AddDate(0, -1, 0)
+		wallet := &domain.Wallet{ID: 1, UserID: 10, Currency: "USD", Balance: decimal.NewFromInt(100), DormantSince: &frozenAt, FrozenAt: &frozenAt}
+
+		walletRepo.On("GetWalletByID", ctx, txController, wallet.ID).Return(wallet, nil).Once()
+		txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := walletService.Withdraw(ctx, wallet.ID, decimal.NewFromInt(10), "USD")
+
+		assert.ErrorIs(t, err, util.ErrWalletFrozen)
+		walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,20 @@
+// internal/service/dry_run.go
+package service
+
+import "context"
+
+type dryRunKey struct{}
+
+// WithDryRun marks the deposit, withdrawal or transfer in ctx as a dry run: it is validated and executed as
+// usual, limits, funds, currency, policy and quoted pricing included, but its database transaction is rolled
+// back instead of committed and no event is published. The would-be wallets and transaction are returned; the
+// transaction is never stored.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// isDryRun reports whether ctx carries WithDryRun.
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
@@ -0,0 +1,113 @@
+// internal/service/dry_run_test.go
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// countingListener counts the published transactions.
+type countingListener struct{ published int }
+
+func (l *countingListener) OnTransaction(ctx context.Context, transaction *domain.Transaction) {
+	l.published++
+}
+
+// TestDryRun tests that dry runs are validated and executed but never committed nor published.
+func TestDryRun(t *testing.T) {
+	fromWalletID, toWalletID := int64(1), int64(2)
+	currency := "USD"
+	type mocks struct {
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		txController    *MockTxController
+		listener        *countingListener
+	}
+	newService := func() (WalletService, mocks) {
+		m := mocks{
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			txController:    new(MockTxController),
+			listener:        &countingListener{},
+		}
+		events := NewTransactionEvents()
+		events.Subscribe(m.listener)
+		service := NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
+			m.walletRepo,
+			m.transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			WithTransactionEvents(events),
+		)
+		m.txController.On("Rollback").Return(nil).Once()
+		return service, m
+	}
+
+	t.Run("DepositIsRolledBack", func(t *testing.T) {
+		ctx := WithDryRun(context.Background())
+		service, m := newService()
+		amount := decimal.NewFromInt(100)
+		m.walletRepo.On("GetWalletByID", ctx, m.txController, fromWalletID).Return(&domain.Wallet{ID: fromWalletID, Currency: currency}, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, fromWalletID, amount, mock.Anything).Return(&domain.WalletBalance{ID: fromWalletID, Balance: amount, Version: 2}, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+
+		wallet, transaction, err := service.Deposit(ctx, fromWalletID, amount, currency)
+
+		require.NoError(t, err)
+		assert.True(t, amount.Equal(wallet.Balance))
+		assert.Equal(t, domain.TransactionTypeDeposit, transaction.Type)
+		assert.Zero(t, m.listener.published)
+		m.txController.AssertNotCalled(t, "Commit")
+		mock.AssertExpectationsForObjects(t, m.walletRepo, m.transactionRepo, m.txController)
+	})
+
+	t.Run("TransferIsRolledBack", func(t *testing.T) {
+		ctx := WithDryRun(context.Background())
+		service, m := newService()
+		amount := decimal.NewFromInt(30)
+		from := domain.Wallet{ID: fromWalletID, UserID: 1, Currency: currency, Balance: decimal.NewFromInt(100)}
+		to := domain.Wallet{ID: toWalletID, UserID: 2, Currency: currency}
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{fromWalletID, toWalletID}).Return([]domain.Wallet{from, to}, nil).Once()
+		m.walletRepo.On("UpdateWalletBalances", ctx, m.txController, map[int64]decimal.Decimal{fromWalletID: amount.Neg(), toWalletID: amount}, mock.Anything).
+			Return([]domain.Wallet{*from.WithBalance(&domain.WalletBalance{Balance: decimal.NewFromInt(70)}), *to.WithBalance(&domain.WalletBalance{Balance: amount})}, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+
+		fromWallet, _, _, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+
+		require.NoError(t, err)
+		assert.Equal(t, "70", fromWallet.Balance.String())
+		assert.Zero(t, m.listener.published)
+		m.txController.AssertNotCalled(t, "Commit")
+		mock.AssertExpectationsForObjects(t, m.walletRepo, m.transactionRepo, m.txController)
+	})
+
+	t.Run("WithdrawalStillChecksFunds", func(t *testing.T) {
+		ctx := WithDryRun(context.Background())
+		service, m := newService()
+		m.walletRepo.On("GetWalletByID", ctx, m.txController, fromWalletID).Return(&domain.Wallet{ID: fromWalletID, Currency: currency, Balance: decimal.NewFromInt(10)}, nil).Once()
+
+		_, _, err := service.Withdraw(ctx, fromWalletID, decimal.NewFromInt(50), currency)
+
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		m.transactionRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
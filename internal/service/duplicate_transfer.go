@@ -0,0 +1,49 @@
+// internal/service/duplicate_transfer.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+type forcedTransferKey struct{}
+
+// WithForcedTransfer marks the transfer in ctx as intended even if it repeats a recent one,
+// lifting the duplicate transfer check.
+func WithForcedTransfer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcedTransferKey{}, true)
+}
+
+// transferForced reports whether ctx carries WithForcedTransfer.
+func transferForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(forcedTransferKey{}).(bool)
+	return forced
+}
+
+// checkDuplicateTransfer fails with a util.DuplicateTransferError when the same amount was transferred between
+// the same wallets within the duplicate window, which most likely means the user sent the payment twice.
+// Forced, approved and quoted transfers are not checked: each of them already confirms the sender's intent.
+func (s *walletService) checkDuplicateTransfer(ctx context.Context, q repository.DBExecutor, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) error {
+	if s.duplicateWindow <= 0 || transferForced(ctx) || isApprovedTransfer(ctx) {
+		return nil
+	}
+	if _, quoted := transferQuoteID(ctx); quoted {
+		return nil
+	}
+
+	since := s.clock.Now().UTC().Add(-s.duplicateWindow)
+	earlier, err := s.transactionRepo.FindRecentTransfer(ctx, q, fromWalletID, toWalletID, amount, currency, since)
+	if errors.Is(err, util.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up recent transfers: %w", err)
+	}
+	return &util.DuplicateTransferError{ExistingID: earlier.PublicID, CreatedAt: earlier.CreatedAt}
+}
@@ -0,0 +1,94 @@
+// internal/service/events.go
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+)
+
+// TransactionListener reacts to money movements after they have been committed.
+// Listeners run in the publishing request and handle their own errors.
+type TransactionListener interface {
+	OnTransaction(ctx context.Context, transaction *domain.Transaction)
+}
+
+// TransactionEvents dispatches committed transactions to its listeners, in subscription order.
+type TransactionEvents struct {
+	mu        sync.RWMutex
+	listeners []TransactionListener
+}
+
+// NewTransactionEvents creates an event bus without listeners.
+func NewTransactionEvents() *TransactionEvents {
+	return &TransactionEvents{}
+}
+
+// Subscribe registers a listener for every subsequently published transaction.
+func (e *TransactionEvents) Subscribe(listener TransactionListener) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners = append(e.listeners, listener)
+}
+
+// Publish hands a committed transaction to every listener. The money has already moved,
+// so listeners keep running even if the request that caused it is cancelled.
+func (e *TransactionEvents) Publish(ctx context.Context, transaction *domain.Transaction) {
+	e.mu.RLock()
+	listeners := e.listeners
+	e.mu.RUnlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for _, listener := range listeners {
+		listener.OnTransaction(ctx, transaction)
+	}
+}
+
+// TransactionWatcher lets requests wait for a change of a transaction. Subscribed to TransactionEvents, it
+// hands every published transaction to the requests watching it.
+type TransactionWatcher struct {
+	mu       sync.Mutex
+	watchers map[uuid.UUID]map[chan *domain.Transaction]struct{}
+}
+
+// NewTransactionWatcher creates a watcher without watches.
+func NewTransactionWatcher() *TransactionWatcher {
+	return &TransactionWatcher{watchers: map[uuid.UUID]map[chan *domain.Transaction]struct{}{}}
+}
+
+// Watch starts watching the transaction. The channel receives the transaction each time it is published;
+// stop ends the watch and must be called.
+func (w *TransactionWatcher) Watch(publicID uuid.UUID) (updates <-chan *domain.Transaction, stop func()) {
+	ch := make(chan *domain.Transaction, 1)
+	w.mu.Lock()
+	if w.watchers[publicID] == nil {
+		w.watchers[publicID] = map[chan *domain.Transaction]struct{}{}
+	}
+	w.watchers[publicID][ch] = struct{}{}
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.watchers[publicID], ch)
+		if len(w.watchers[publicID]) == 0 {
+			delete(w.watchers, publicID)
+		}
+	}
+}
+
+// OnTransaction wakes the requests watching the transaction. It never blocks: a watcher that has not taken
+// the previous update yet keeps that one, and re-reads the transaction anyway.
+func (w *TransactionWatcher) OnTransaction(ctx context.Context, transaction *domain.Transaction) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.watchers[transaction.PublicID] {
+		select {
+		case ch <- transaction:
+		default:
+		}
+	}
+}
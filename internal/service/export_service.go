@@ -0,0 +1,241 @@
+// internal/service/export_service.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// ExportService defines the interface for exporting transactions and wallet snapshots to the data warehouse,
+// so analytics can ingest them incrementally instead of querying the OLTP database.
+type ExportService interface {
+	// Export writes the transactions created since the last run, and a snapshot of all wallets once per UTC day,
+	// to the object store. It returns the number of transactions exported.
+	Export(ctx context.Context, now time.Time) (int, error)
+}
+
+// exportService implements ExportService, writing CSV files.
+type exportService struct {
+	dbExecutor  repository.DBExecutor
+	exportRepo  repository.ExportRepository
+	store       ObjectStore
+	batchSize   int           // Rows per transaction file and per wallet page
+	settleDelay time.Duration // Only transactions at least this old are exported
+	logger      *slog.Logger
+}
+
+// NewExportService creates a new instance of ExportService.
+// settleDelay keeps the export behind the newest transactions: IDs are assigned before commit, so a
+// transaction may become visible after one with a higher ID, and the watermark would skip it.
+func NewExportService(
+	dbExecutor repository.DBExecutor,
+	exportRepo repository.ExportRepository,
+	store ObjectStore,
+	batchSize int,
+	settleDelay time.Duration,
+	logger *slog.Logger,
+) ExportService {
+	return &exportService{
+		dbExecutor:  dbExecutor,
+		exportRepo:  exportRepo,
+		store:       store,
+		batchSize:   batchSize,
+		settleDelay: settleDelay,
+		logger:      logger,
+	}
+}
+
+var transactionExportHeader = []string{
+	"id", "from_wallet_id", "to_wallet_id", "amount", "promo_amount", "currency", "type", "status",
+	"transaction_time", "description", "category", "parent_transaction_id", "created_at",
+}
+
+var walletExportHeader = []string{
+	"id", "user_id", "currency", "balance", "kind", "version", "created_at", "updated_at", "deleted_at", "snapshot_at",
+}
+
+// Export writes each batch of transactions to its own object and advances the watermark after the object is
+// stored. A run interrupted in between exports that batch again under the same key, so delivery is at least
+// once and a re-export replaces the earlier object.
+func (s *exportService) Export(ctx context.Context, now time.Time) (int, error) {
+	now = now.UTC()
+	exported, err := s.exportTransactions(ctx, now)
+	if err != nil {
+		return exported, fmt.Errorf("export: %w", err)
+	}
+	if err := s.exportWalletSnapshot(ctx, now); err != nil {
+		return exported, fmt.Errorf("export: %w", err)
+	}
+	return exported, nil
+}
+
+func (s *exportService) exportTransactions(ctx context.Context, now time.Time) (int, error) {
+	watermark, err := s.watermark(ctx, domain.ExportStreamTransactions)
+	if err != nil {
+		return 0, err
+	}
+	before := now.Add(-s.settleDelay)
+
+	exported := 0
+	for {
+		transactions, err := s.exportRepo.ListTransactionsAfter(ctx, s.dbExecutor, watermark.LastID, before, s.batchSize)
+		if err != nil {
+			return exported, err
+		}
+		if len(transactions) == 0 {
+			return exported, nil
+		}
+
+		records := make([][]string, 0, len(transactions)+1)
+		records = append(records, transactionExportHeader)
+		for i := range transactions {
+			records = append(records, transactionExportRecord(&transactions[i]))
+		}
+		first, last := transactions[0].ID, transactions[len(transactions)-1].ID
+		key := fmt.Sprintf("%s/date=%s/%s-%020d-%020d.csv", domain.ExportStreamTransactions, now.Format(time.DateOnly),
+			domain.ExportStreamTransactions, first, last)
+		if err := s.put(ctx, key, records); err != nil {
+			return exported, err
+		}
+
+		watermark.LastID = last
+		watermark.ExportedAt = now
+		watermark.UpdatedAt = now
+		if err := s.exportRepo.SaveWatermark(ctx, s.dbExecutor, watermark); err != nil {
+			return exported, err
+		}
+		exported += len(transactions)
+		s.logger.Info("Transactions exported", "key", key, "count", len(transactions), "last_id", last)
+
+		if len(transactions) < s.batchSize {
+			return exported, nil
+		}
+	}
+}
+
+// exportWalletSnapshot writes every wallet to one object, unless today's snapshot was already taken.
+func (s *exportService) exportWalletSnapshot(ctx context.Context, now time.Time) error {
+	watermark, err := s.watermark(ctx, domain.ExportStreamWallets)
+	if err != nil {
+		return err
+	}
+	if !watermark.ExportedAt.IsZero() && watermark.ExportedAt.UTC().Format(time.DateOnly) == now.Format(time.DateOnly) {
+		return nil
+	}
+
+	records := [][]string{walletExportHeader}
+	afterID := int64(0)
+	for {
+		wallets, err := s.exportRepo.ListWalletsAfter(ctx, s.dbExecutor, afterID, s.batchSize)
+		if err != nil {
+			return err
+		}
+		for i := range wallets {
+			records = append(records, walletExportRecord(&wallets[i], now))
+		}
+		if len(wallets) < s.batchSize {
+			break
+		}
+		afterID = wallets[len(wallets)-1].ID
+	}
+
+	key := fmt.Sprintf("%s/date=%s/%s.csv", domain.ExportStreamWallets, now.Format(time.DateOnly), domain.ExportStreamWallets)
+	if err := s.put(ctx, key, records); err != nil {
+		return err
+	}
+	watermark.ExportedAt = now
+	watermark.UpdatedAt = now
+	if err := s.exportRepo.SaveWatermark(ctx, s.dbExecutor, watermark); err != nil {
+		return err
+	}
+	s.logger.Info("Wallet snapshot exported", "key", key, "count", len(records)-1)
+	return nil
+}
+
+// watermark returns the progress of a stream, starting from scratch for a stream never exported.
+func (s *exportService) watermark(ctx context.Context, stream string) (*domain.ExportWatermark, error) {
+	watermark, err := s.exportRepo.GetWatermark(ctx, s.dbExecutor, stream)
+	if errors.Is(err, util.ErrNotFound) {
+		return &domain.ExportWatermark{Stream: stream}, nil
+	}
+	return watermark, err
+}
+
+func (s *exportService) put(ctx context.Context, key string, records [][]string) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	if err := s.store.Put(ctx, key, &buf, "text/csv"); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+// transactionExportRecord formats a transaction for the warehouse, identifying wallets by their public IDs.
+func transactionExportRecord(tx *domain.Transaction) []string {
+	return []string{
+		tx.PublicID.String(),
+		optionalUUID(tx.FromWalletPublicID),
+		optionalUUID(tx.ToWalletPublicID),
+		tx.Amount.String(),
+		tx.PromoAmount.String(),
+		tx.Currency,
+		string(tx.Type),
+		string(tx.Status),
+		tx.TransactionTime.UTC().Format(time.RFC3339Nano),
+		optionalString(tx.Description),
+		optionalString(tx.Category),
+		optionalUUID(tx.ParentID),
+		tx.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func walletExportRecord(wallet *domain.Wallet, snapshotAt time.Time) []string {
+	return []string{
+		wallet.PublicID.String(),
+		strconv.FormatInt(wallet.UserID, 10),
+		wallet.Currency,
+		wallet.Balance.String(),
+		string(wallet.Kind),
+		strconv.FormatInt(wallet.Version, 10),
+		wallet.CreatedAt.UTC().Format(time.RFC3339Nano),
+		wallet.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		optionalTime(wallet.DeletedAt),
+		snapshotAt.Format(time.RFC3339Nano),
+	}
+}
+
+func optionalUUID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func optionalString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,139 @@
+// internal/service/export_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestExportService tests that transactions are exported incrementally from the watermark and wallets once a day.
+func TestExportService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Minute)
+	wallet := domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Balance: decimal.NewFromInt(40), Kind: domain.WalletKindPersonal, Version: 3}
+	transaction := func(id int64) domain.Transaction {
+		return domain.Transaction{ID: id, PublicID: uuid.New(), ToWalletPublicID: &wallet.PublicID, Amount: decimal.NewFromInt(10), Currency: "USD",
+			Type: domain.TransactionTypeDeposit, Status: domain.TransactionStatusCompleted, TransactionTime: now, CreatedAt: now}
+	}
+	snapshotTaken := &domain.ExportWatermark{Stream: domain.ExportStreamWallets, ExportedAt: now.Add(-time.Hour)}
+
+	type mocks struct {
+		exportRepo *MockExportRepository
+		store      *MockObjectStore
+		dbExecutor *MockDBExecutor
+	}
+	newService := func() (ExportService, mocks) {
+		m := mocks{
+			exportRepo: new(MockExportRepository),
+			store:      new(MockObjectStore),
+			dbExecutor: new(MockDBExecutor),
+		}
+		return NewExportService(m.dbExecutor, m.exportRepo, m.store, 2, time.Minute, logger), m
+	}
+
+	t.Run("ExportsFromWatermarkInBatches", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		first := []domain.Transaction{transaction(6), transaction(7)}
+		second := []domain.Transaction{transaction(9)}
+
+		m.exportRepo.On("GetWatermark", ctx, m.dbExecutor, domain.ExportStreamTransactions).
+			Return(&domain.ExportWatermark{Stream: domain.ExportStreamTransactions, LastID: 5}, nil).Once()
+		m.exportRepo.On("ListTransactionsAfter", ctx, m.dbExecutor, int64(5), before, 2).Return(first, nil).Once()
+		m.store.On("Put", ctx, "transactions/date=2025-03-01/transactions-00000000000000000006-00000000000000000007.csv",
+			mock.MatchedBy(func(body string) bool {
+				lines := strings.Split(strings.TrimSpace(body), "\n")
+				return len(lines) == 3 && strings.HasPrefix(lines[1], first[0].PublicID.String()+",,"+wallet.PublicID.String()+",10,0,USD,DEPOSIT")
+			}), "text/csv").Return(nil).Once()
+		m.exportRepo.On("SaveWatermark", ctx, m.dbExecutor, mock.MatchedBy(func(w domain.ExportWatermark) bool { return w.LastID == 7 })).Return(nil).Once()
+		m.exportRepo.On("ListTransactionsAfter", ctx, m.dbExecutor, int64(7), before, 2).Return(second, nil).Once()
+		m.store.On("Put", ctx, "transactions/date=2025-03-01/transactions-00000000000000000009-00000000000000000009.csv", mock.Anything, "text/csv").Return(nil).Once()
+		m.exportRepo.On("SaveWatermark", ctx, m.dbExecutor, mock.MatchedBy(func(w domain.ExportWatermark) bool { return w.LastID == 9 })).Return(nil).Once()
+		m.exportRepo.On("GetWatermark", ctx, m.dbExecutor, domain.ExportStreamWallets).Return(snapshotTaken, nil).Once()
+
+		exported, err := service.Export(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, exported)
+		mock.AssertExpectationsForObjects(t, m.exportRepo, m.store)
+	})
+
+	t.Run("FailedUploadKeepsWatermark", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.exportRepo.On("GetWatermark", ctx, m.dbExecutor, domain.ExportStreamTransactions).Return(nil, util.ErrNotFound).Once()
+		m.exportRepo.On("ListTransactionsAfter", ctx, m.dbExecutor, int64(0), before, 2).Return([]domain.Transaction{transaction(1)}, nil).Once()
+		m.store.On("Put", ctx, mock.Anything, mock.Anything, "text/csv").Return(assert.AnError).Once()
+
+		_, err := service.Export(ctx, now)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		m.exportRepo.AssertNotCalled(t, "SaveWatermark", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("WalletSnapshotTakenOncePerDay", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.exportRepo.On("GetWatermark", ctx, m.dbExecutor, domain.ExportStreamTransactions).Return(nil, util.ErrNotFound).Once()
+		m.exportRepo.On("ListTransactionsAfter", ctx, m.dbExecutor, int64(0), before, 2).Return([]domain.Transaction{}, nil).Once()
+		m.exportRepo.On("GetWatermark", ctx, m.dbExecutor, domain.ExportStreamWallets).
+			Return(&domain.ExportWatermark{Stream: domain.ExportStreamWallets, ExportedAt: now.AddDate(0, 0, -1)}, nil).Once()
+		m.exportRepo.On("ListWalletsAfter", ctx, m.dbExecutor, int64(0), 2).Return([]domain.Wallet{wallet}, nil).Once()
+		m.store.On("Put", ctx, "wallets/date=2025-03-01/wallets.csv", mock.MatchedBy(func(body string) bool {
+			return strings.Contains(body, wallet.PublicID.String()+",10,USD,40,PERSONAL,3,")
+		}), "text/csv").Return(nil).Once()
+		m.exportRepo.On("SaveWatermark", ctx, m.dbExecutor, mock.MatchedBy(func(w domain.ExportWatermark) bool {
+			return w.Stream == domain.ExportStreamWallets && w.ExportedAt.Equal(now)
+		})).Return(nil).Once()
+
+		_, err := service.Export(ctx, now)
+		assert.NoError(t, err)
+
+		// A second run on the same day finds today's snapshot and skips it
+		service, m = newService()
+		m.exportRepo.On("GetWatermark", ctx, m.dbExecutor, domain.ExportStreamTransactions).Return(nil, util.ErrNotFound).Once()
+		m.exportRepo.On("ListTransactionsAfter", ctx, m.dbExecutor, int64(0), before, 2).Return([]domain.Transaction{}, nil).Once()
+		m.exportRepo.On("GetWatermark", ctx, m.dbExecutor, domain.ExportStreamWallets).Return(snapshotTaken, nil).Once()
+
+		_, err = service.Export(ctx, now)
+
+		assert.NoError(t, err)
+		m.exportRepo.AssertNotCalled(t, "ListWalletsAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestDirObjectStore tests that objects are written as files under the root and replaced on rewrite.
+func TestDirObjectStore(t *testing.T) {
+	root := t.TempDir()
+	store := NewDirObjectStore(root)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Put(ctx, "wallets/date=2025-03-01/wallets.csv", strings.NewReader("first"), "text/csv"))
+	assert.NoError(t, store.Put(ctx, "wallets/date=2025-03-01/wallets.csv", strings.NewReader("second"), "text/csv"))
+
+	data, err := os.ReadFile(filepath.Join(root, "wallets", "date=2025-03-01", "wallets.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+	entries, _ := os.ReadDir(filepath.Join(root, "wallets", "date=2025-03-01"))
+	assert.Len(t, entries, 1)
+
+	assert.Error(t, store.Put(ctx, "../escape.csv", strings.NewReader("x"), "text/csv"))
+}
@@ -0,0 +1,175 @@
+// internal/service/feature_flag_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// FeatureFlags evaluates feature flags. It is the narrow view other services depend on.
+type FeatureFlags interface {
+	// Evaluate decides whether the flag is on for the user. Unknown flags are off.
+	Evaluate(ctx context.Context, key string, userID int64) domain.FlagDecision
+	// EvaluateFor decides whether the flag is on for the subject, bucketing rollouts by user or wallet as the
+	// flag says. Unknown flags are off.
+	EvaluateFor(ctx context.Context, key string, subject domain.FlagSubject) domain.FlagDecision
+}
+
+// FeatureFlagService defines the interface for managing and evaluating feature flags.
+type FeatureFlagService interface {
+	FeatureFlags
+	ListFlags(ctx context.Context) ([]domain.FeatureFlag, error)
+	GetFlag(ctx context.Context, key string) (*domain.FeatureFlag, error)
+	SaveFlag(ctx context.Context, flag *domain.FeatureFlag) error
+	DeleteFlag(ctx context.Context, key string) error
+}
+
+// flagKeyPattern restricts flag keys to short, log-friendly identifiers.
+var flagKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,99}$`)
+
+// featureFlagService implements FeatureFlagService with an in-memory cache of all flags.
+// The cache is reloaded when it is older than the TTL and dropped on every write, so changes
+// made through this instance apply immediately and changes made elsewhere within one TTL.
+type featureFlagService struct {
+	dbExecutor repository.DBExecutor
+	flagRepo   repository.FeatureFlagRepository
+	ttl        time.Duration
+	logger     *slog.Logger
+
+	mu       sync.RWMutex
+	flags    map[string]domain.FeatureFlag
+	loadedAt time.Time
+}
+
+// NewFeatureFlagService creates a new instance of FeatureFlagService.
+func NewFeatureFlagService(dbExecutor repository.DBExecutor, flagRepo repository.FeatureFlagRepository, ttl time.Duration, logger *slog.Logger) FeatureFlagService {
+	return &featureFlagService{
+		dbExecutor: dbExecutor,
+		flagRepo:   flagRepo,
+		ttl:        ttl,
+		logger:     logger,
+	}
+}
+
+// Evaluate decides whether the flag is on for the user.
+func (s *featureFlagService) Evaluate(ctx context.Context, key string, userID int64) domain.FlagDecision {
+	return s.EvaluateFor(ctx, key, domain.FlagSubject{UserID: userID})
+}
+
+// EvaluateFor decides whether the flag is on for the subject and logs the decision.
+// If the flags cannot be loaded, the last known flags are used; with none, every flag is off.
+func (s *featureFlagService) EvaluateFor(ctx context.Context, key string, subject domain.FlagSubject) domain.FlagDecision {
+	flags, err := s.cachedFlags(ctx)
+	if err != nil {
+		s.logger.Error("Failed to refresh feature flags, using cached values", "error", err)
+	}
+
+	decision := domain.FlagDecision{Key: key, Reason: "missing"}
+	if flag, ok := flags[key]; ok {
+		decision = flag.EvaluateFor(subject)
+	}
+	s.logger.DebugContext(ctx, "Feature flag evaluated", "flag", key, "user_id", subject.UserID,
+		"wallet_id", subject.WalletID, "enabled", decision.Enabled, "reason", decision.Reason)
+	return decision
+}
+
+// ListFlags returns every flag, bypassing the cache.
+func (s *featureFlagService) ListFlags(ctx context.Context) ([]domain.FeatureFlag, error) {
+	flags, err := s.flagRepo.ListFlags(ctx, s.dbExecutor)
+	if err != nil {
+		return nil, fmt.Errorf("list feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+// GetFlag returns a single flag, bypassing the cache.
+func (s *featureFlagService) GetFlag(ctx context.Context, key string) (*domain.FeatureFlag, error) {
+	flags, err := s.ListFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range flags {
+		if flags[i].Key == key {
+			return &flags[i], nil
+		}
+	}
+	return nil, util.ErrNotFound
+}
+
+// SaveFlag creates or replaces a flag.
+func (s *featureFlagService) SaveFlag(ctx context.Context, flag *domain.FeatureFlag) error {
+	if !flagKeyPattern.MatchString(flag.Key) {
+		return fmt.Errorf("%w: invalid feature flag key %q", util.ErrInvalidInput, flag.Key)
+	}
+	if flag.RolloutPercent < 0 || flag.RolloutPercent > 100 {
+		return fmt.Errorf("%w: rollout_percent must be between 0 and 100", util.ErrInvalidInput)
+	}
+	switch flag.RolloutBy {
+	case "":
+		flag.RolloutBy = domain.RolloutByUser
+	case domain.RolloutByUser, domain.RolloutByWallet:
+	default:
+		return fmt.Errorf("%w: rollout_by must be user or wallet", util.ErrInvalidInput)
+	}
+	if err := s.flagRepo.UpsertFlag(ctx, s.dbExecutor, flag); err != nil {
+		return fmt.Errorf("save feature flag: %w", err)
+	}
+	s.invalidate()
+	s.logger.Info("Feature flag saved", "flag", flag.Key, "enabled", flag.Enabled,
+		"cohort_size", len(flag.UserIDs), "rollout_percent", flag.RolloutPercent, "rollout_by", flag.RolloutBy)
+	return nil
+}
+
+// DeleteFlag removes a flag.
+func (s *featureFlagService) DeleteFlag(ctx context.Context, key string) error {
+	if err := s.flagRepo.DeleteFlag(ctx, s.dbExecutor, key); err != nil {
+		return fmt.Errorf("delete feature flag %s: %w", key, err)
+	}
+	s.invalidate()
+	s.logger.Info("Feature flag deleted", "flag", key)
+	return nil
+}
+
+// cachedFlags returns the cached flags, reloading them once the cache has expired.
+// On a failed reload the stale flags are returned together with the error.
+func (s *featureFlagService) cachedFlags(ctx context.Context) (map[string]domain.FeatureFlag, error) {
+	s.mu.RLock()
+	flags, fresh := s.flags, s.flags != nil && time.Since(s.loadedAt) < s.ttl
+	s.mu.RUnlock()
+	if fresh {
+		return flags, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.flags != nil && time.Since(s.loadedAt) < s.ttl {
+		return s.flags, nil // Reloaded by a concurrent caller
+	}
+
+	list, err := s.flagRepo.ListFlags(ctx, s.dbExecutor)
+	if err != nil {
+		// Retry on the next evaluation rather than hammering the database from every request
+		s.loadedAt = time.Now()
+		return s.flags, err
+	}
+	s.flags = make(map[string]domain.FeatureFlag, len(list))
+	for _, flag := range list {
+		s.flags[flag.Key] = flag
+	}
+	s.loadedAt = time.Now()
+	return s.flags, nil
+}
+
+func (s *featureFlagService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
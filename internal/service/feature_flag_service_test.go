@@ -0,0 +1,113 @@
+// internal/service/feature_flag_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestFeatureFlagService tests flag evaluation and cache behaviour of FeatureFlagService.
+func TestFeatureFlagService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	flags := []domain.FeatureFlag{
+		{Key: "overdraft", UserIDs: []int64{7}, Value: "50.00"},
+		{Key: "fx_transfers", Enabled: true},
+	}
+
+	t.Run("EvaluatesFromCache", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRepo := new(MockFeatureFlagRepository)
+		service := NewFeatureFlagService(mockDBExecutor, mockRepo, time.Minute, logger)
+
+		mockRepo.On("ListFlags", ctx, mockDBExecutor).Return(flags, nil).Once()
+
+		cohort := service.Evaluate(ctx, "overdraft", 7)
+		outsider := service.Evaluate(ctx, "overdraft", 8)
+		global := service.Evaluate(ctx, "fx_transfers", 8)
+		missing := service.Evaluate(ctx, "unknown", 7)
+
+		assert.Equal(t, domain.FlagDecision{Key: "overdraft", Enabled: true, Value: "50.00", Reason: "cohort"}, cohort)
+		assert.False(t, outsider.Enabled)
+		assert.Equal(t, "global", global.Reason)
+		assert.Equal(t, domain.FlagDecision{Key: "unknown", Reason: "missing"}, missing)
+		mockRepo.AssertExpectations(t) // Loaded once for all four evaluations
+	})
+
+	t.Run("SaveInvalidatesCache", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRepo := new(MockFeatureFlagRepository)
+		service := NewFeatureFlagService(mockDBExecutor, mockRepo, time.Hour, logger)
+
+		updated := &domain.FeatureFlag{Key: "overdraft", Enabled: true, Value: "50.00"}
+		mockRepo.On("ListFlags", ctx, mockDBExecutor).Return(flags, nil).Once()
+		mockRepo.On("UpsertFlag", ctx, mockDBExecutor, updated).Return(nil).Once()
+		mockRepo.On("ListFlags", ctx, mockDBExecutor).Return([]domain.FeatureFlag{*updated}, nil).Once()
+
+		assert.False(t, service.Evaluate(ctx, "overdraft", 8).Enabled)
+		assert.NoError(t, service.SaveFlag(ctx, updated))
+		assert.True(t, service.Evaluate(ctx, "overdraft", 8).Enabled)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RefreshErrorKeepsStaleFlags", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRepo := new(MockFeatureFlagRepository)
+		service := NewFeatureFlagService(mockDBExecutor, mockRepo, time.Nanosecond, logger)
+
+		mockRepo.On("ListFlags", ctx, mockDBExecutor).Return(flags, nil).Once()
+		mockRepo.On("ListFlags", ctx, mockDBExecutor).Return(nil, errors.New("connection refused")).Once()
+
+		assert.True(t, service.Evaluate(ctx, "fx_transfers", 1).Enabled)
+		time.Sleep(time.Millisecond)
+		assert.True(t, service.Evaluate(ctx, "fx_transfers", 1).Enabled)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidFlagRejected", func(t *testing.T) {
+		mockRepo := new(MockFeatureFlagRepository)
+		service := NewFeatureFlagService(new(MockDBExecutor), mockRepo, time.Minute, logger)
+
+		err := service.SaveFlag(context.Background(), &domain.FeatureFlag{Key: "Bad Key"})
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		err = service.SaveFlag(context.Background(), &domain.FeatureFlag{Key: "rollout", RolloutPercent: 101})
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		err = service.SaveFlag(context.Background(), &domain.FeatureFlag{Key: "rollout", RolloutBy: "merchant"})
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		mockRepo.AssertNotCalled(t, "UpsertFlag", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RolloutByWalletBucketsEachWallet", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRepo := new(MockFeatureFlagRepository)
+		service := NewFeatureFlagService(mockDBExecutor, mockRepo, time.Minute, logger)
+		mockRepo.On("ListFlags", ctx, mockDBExecutor).Return([]domain.FeatureFlag{
+			{Key: "by_wallet", RolloutPercent: 50, RolloutBy: domain.RolloutByWallet},
+			{Key: "by_user", RolloutPercent: 50, RolloutBy: domain.RolloutByUser},
+		}, nil).Once()
+
+		byWallet, byUser := map[bool]int{}, map[bool]int{}
+		for walletID := int64(1); walletID <= 200; walletID++ {
+			byWallet[service.EvaluateFor(ctx, "by_wallet", domain.FlagSubject{UserID: 7, WalletID: walletID}).Enabled]++
+			byUser[service.EvaluateFor(ctx, "by_user", domain.FlagSubject{UserID: 7, WalletID: walletID}).Enabled]++
+		}
+
+		assert.Positive(t, byWallet[true], "some wallets of the user are in the rollout")
+		assert.Positive(t, byWallet[false], "and some are not")
+		assert.Len(t, byUser, 1, "all wallets of a user share the user's bucket")
+		assert.False(t, service.EvaluateFor(ctx, "by_wallet", domain.FlagSubject{UserID: 7}).Enabled, "no wallet, no rollout")
+	})
+}
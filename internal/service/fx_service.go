@@ -0,0 +1,150 @@
+// internal/service/fx_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// FXService defines the interface for exchange rates and currency conversion.
+type FXService interface {
+	// Rates returns the cached rate table.
+	Rates(ctx context.Context) (*FXRateTable, error)
+	// Convert converts amount from one currency to another, rounded to the places of domain.AmountScale.
+	// It fails with util.ErrFXRateUnavailable for unknown pairs and util.ErrFXRateStale for rates older than the max age.
+	Convert(ctx context.Context, amount decimal.Decimal, from, to string) (decimal.Decimal, domain.FXRate, error)
+	// Refresh reloads the rate table; it is run periodically by the background refresher.
+	Refresh(ctx context.Context) error
+}
+
+// FXRateTable is a snapshot of the cached exchange rates.
+type FXRateTable struct {
+	Rates       []domain.FXRate
+	RefreshedAt time.Time     // When the cache was last loaded from the database
+	MaxAge      time.Duration // Rates older than this are not used for conversions
+}
+
+// fxService implements FXService with an in-memory cache of the rate table.
+// The cache is kept warm by Refresh and reloaded on demand once it is older than the TTL.
+type fxService struct {
+	dbExecutor repository.DBExecutor
+	rateRepo   repository.FXRateRepository
+	ttl        time.Duration
+	maxAge     time.Duration
+	logger     *slog.Logger
+
+	mu          sync.RWMutex
+	rates       map[string]domain.FXRate // Keyed by pair, e.g. "USD/HKD"
+	refreshedAt time.Time
+	attemptedAt time.Time
+}
+
+// NewFXService creates a new instance of FXService.
+func NewFXService(dbExecutor repository.DBExecutor, rateRepo repository.FXRateRepository, ttl, maxAge time.Duration, logger *slog.Logger) FXService {
+	return &fxService{
+		dbExecutor: dbExecutor,
+		rateRepo:   rateRepo,
+		ttl:        ttl,
+		maxAge:     maxAge,
+		logger:     logger,
+	}
+}
+
+// Rates returns the cached rate table, sorted by pair.
+func (s *fxService) Rates(ctx context.Context) (*FXRateTable, error) {
+	rates, refreshedAt, err := s.cachedRates(ctx)
+	if rates == nil && err != nil {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.Error("Failed to refresh fx rates, serving cached rates", "error", err)
+	}
+
+	table := &FXRateTable{Rates: make([]domain.FXRate, 0, len(rates)), RefreshedAt: refreshedAt, MaxAge: s.maxAge}
+	for _, rate := range rates {
+		table.Rates = append(table.Rates, rate)
+	}
+	sort.Slice(table.Rates, func(i, j int) bool { return table.Rates[i].Pair() < table.Rates[j].Pair() })
+	return table, nil
+}
+
+// Convert converts amount from one currency to another using the direct or the inverse pair.
+func (s *fxService) Convert(ctx context.Context, amount decimal.Decimal, from, to string) (decimal.Decimal, domain.FXRate, error) {
+	if from == to {
+		return amount, domain.FXRate{Base: from, Quote: to, Rate: decimal.NewFromInt(1), AsOf: time.Now().UTC()}, nil
+	}
+
+	rates, _, err := s.cachedRates(ctx)
+	if err != nil {
+		s.logger.Error("Failed to refresh fx rates, using cached rates", "error", err)
+	}
+
+	rate, ok := rates[from+"/"+to]
+	if !ok {
+		inverse, found := rates[to+"/"+from]
+		if !found {
+			return decimal.Zero, domain.FXRate{}, fmt.Errorf("%w: %s/%s", util.ErrFXRateUnavailable, from, to)
+		}
+		rate = inverse.Inverse()
+	}
+	if rate.IsStale(time.Now(), s.maxAge) {
+		s.logger.Warn("Rejected conversion with stale fx rate", "pair", rate.Pair(), "as_of", rate.AsOf)
+		return decimal.Zero, rate, fmt.Errorf("%w: %s as of %s", util.ErrFXRateStale, rate.Pair(), rate.AsOf.Format(time.RFC3339))
+	}
+	return amount.Mul(rate.Rate).Round(domain.AmountScale()), rate, nil
+}
+
+// Refresh reloads the rate table. On failure the previously loaded rates are kept.
+func (s *fxService) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(ctx)
+}
+
+// cachedRates returns the cached rates, reloading them once the cache has expired.
+// On a failed reload the previous rates are returned together with the error.
+func (s *fxService) cachedRates(ctx context.Context) (map[string]domain.FXRate, time.Time, error) {
+	s.mu.RLock()
+	rates, refreshedAt := s.rates, s.refreshedAt
+	fresh := time.Since(s.attemptedAt) < s.ttl
+	s.mu.RUnlock()
+	if fresh {
+		return rates, refreshedAt, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.attemptedAt) < s.ttl {
+		return s.rates, s.refreshedAt, nil // Reloaded by a concurrent caller
+	}
+	err := s.load(ctx)
+	return s.rates, s.refreshedAt, err
+}
+
+// load replaces the cached rates; callers must hold the write lock.
+func (s *fxService) load(ctx context.Context) error {
+	// Failed loads count as attempts too, so a database outage is not retried by every request
+	s.attemptedAt = time.Now()
+
+	list, err := s.rateRepo.ListRates(ctx, s.dbExecutor)
+	if err != nil {
+		return fmt.Errorf("load fx rates: %w", err)
+	}
+	rates := make(map[string]domain.FXRate, len(list))
+	for _, rate := range list {
+		rates[rate.Pair()] = rate
+	}
+	s.rates = rates
+	s.refreshedAt = s.attemptedAt.UTC()
+	return nil
+}
@@ -0,0 +1,101 @@
+// internal/service/fx_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestFXService tests conversions, staleness and caching of FXService.
+func TestFXService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Now().UTC()
+	rates := []domain.FXRate{
+		{Base: "USD", Quote: "HKD", Rate: decimal.RequireFromString("7.8"), AsOf: now.Add(-time.Minute)},
+		{Base: "EUR", Quote: "USD", Rate: decimal.RequireFromString("1.1"), AsOf: now.Add(-2 * time.Hour)},
+	}
+
+	t.Run("DirectAndInversePairs", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRepo := new(MockFXRateRepository)
+		service := NewFXService(mockDBExecutor, mockRepo, time.Minute, time.Hour, logger)
+
+		mockRepo.On("ListRates", ctx, mockDBExecutor).Return(rates, nil).Once()
+
+		converted, rate, err := service.Convert(ctx, decimal.NewFromInt(100), "USD", "HKD")
+		assert.NoError(t, err)
+		assert.True(t, converted.Equal(decimal.NewFromInt(780)))
+		assert.Equal(t, "USD/HKD", rate.Pair())
+
+		converted, rate, err = service.Convert(ctx, decimal.NewFromInt(78), "HKD", "USD")
+		assert.NoError(t, err)
+		assert.True(t, converted.Equal(decimal.NewFromInt(10)), converted.String())
+		assert.Equal(t, "HKD/USD", rate.Pair())
+
+		mockRepo.AssertExpectations(t) // Loaded once for both conversions
+	})
+
+	t.Run("StaleRateRejected", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRepo := new(MockFXRateRepository)
+		service := NewFXService(mockDBExecutor, mockRepo, time.Minute, time.Hour, logger)
+
+		mockRepo.On("ListRates", ctx, mockDBExecutor).Return(rates, nil).Once()
+
+		_, _, err := service.Convert(ctx, decimal.NewFromInt(10), "EUR", "USD")
+		assert.ErrorIs(t, err, util.ErrFXRateStale)
+
+		table, err := service.Rates(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, table.Rates, 2)
+		assert.Equal(t, "EUR/USD", table.Rates[0].Pair())
+		assert.True(t, table.Rates[0].IsStale(time.Now(), table.MaxAge))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("UnknownPairRejected", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRepo := new(MockFXRateRepository)
+		service := NewFXService(mockDBExecutor, mockRepo, time.Minute, time.Hour, logger)
+
+		mockRepo.On("ListRates", ctx, mockDBExecutor).Return(rates, nil).Once()
+
+		_, _, err := service.Convert(ctx, decimal.NewFromInt(10), "USD", "JPY")
+		assert.ErrorIs(t, err, util.ErrFXRateUnavailable)
+
+		converted, _, err := service.Convert(ctx, decimal.NewFromInt(10), "JPY", "JPY")
+		assert.NoError(t, err)
+		assert.True(t, converted.Equal(decimal.NewFromInt(10)))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("FailedRefreshKeepsRates", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRepo := new(MockFXRateRepository)
+		service := NewFXService(mockDBExecutor, mockRepo, time.Minute, time.Hour, logger)
+
+		mockRepo.On("ListRates", ctx, mockDBExecutor).Return(rates, nil).Once()
+		mockRepo.On("ListRates", ctx, mockDBExecutor).Return(nil, errors.New("connection refused")).Once()
+
+		assert.NoError(t, service.Refresh(ctx))
+		assert.Error(t, service.Refresh(ctx))
+
+		_, _, err := service.Convert(ctx, decimal.NewFromInt(1), "USD", "HKD")
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,238 @@
+// internal/service/identity_service.go
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+type provisionedIdentityKey struct{}
+
+// withProvisionedIdentity attaches the identity a user is provisioned for to the CreateUserAndWallet call made
+// with ctx, which then links it to the new user in the same database transaction.
+func withProvisionedIdentity(ctx context.Context, identity *domain.UserIdentity) context.Context {
+	return context.WithValue(ctx, provisionedIdentityKey{}, identity)
+}
+
+// WithIdentities enables provisioning users at their first OpenID Connect login through IdentityService.
+func WithIdentities(identityRepo repository.IdentityRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.identityRepo = identityRepo
+	}
+}
+
+// linkProvisionedIdentity links the identity attached to ctx, if any, to the user just created. An identity
+// linked meanwhile by a concurrent first login fails with util.ErrIdentityLinked, rolling the user back.
+func (s *walletService) linkProvisionedIdentity(ctx context.Context, q repository.DBExecutor, user *domain.User) error {
+	identity, _ := ctx.Value(provisionedIdentityKey{}).(*domain.UserIdentity)
+	if identity == nil {
+		return nil
+	}
+	if s.identityRepo == nil {
+		return fmt.Errorf("identities are not enabled")
+	}
+	identity.UserID = user.ID
+	if err := s.identityRepo.CreateIdentity(ctx, q, identity); err != nil {
+		if errors.Is(err, util.ErrDuplicateEntry) {
+			return util.ErrIdentityLinked
+		}
+		return fmt.Errorf("failed to link %s identity: %w", identity.Provider, err)
+	}
+	return nil
+}
+
+// usernameUnsafe matches what a provisioned username may not contain.
+var usernameUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// maxProvisionedUsername bounds the part of a provisioned username taken from the ID token.
+const maxProvisionedUsername = 40
+
+// OIDCLogin is the outcome of a login with an ID token.
+type OIDCLogin struct {
+	User        *domain.User
+	Identity    *domain.UserIdentity
+	Wallet      *domain.Wallet // The first wallet, if the user was provisioned by this login
+	Provisioned bool
+}
+
+// IdentityService defines the interface for delegated login with the ID tokens of OpenID Connect providers.
+type IdentityService interface {
+	// Login logs in the user linked to the ID token's provider account. A provider account not linked yet is
+	// provisioned as a new user with a wallet in the provider's default currency. Deactivated users are refused.
+	Login(ctx context.Context, provider, idToken string) (*OIDCLogin, error)
+	// ListIdentities retrieves the identities linked to a user.
+	ListIdentities(ctx context.Context, userID int64) ([]domain.UserIdentity, error)
+	// LinkIdentity links the ID token's provider account to an existing user. Linking the same account
+	// again returns its identity unchanged.
+	LinkIdentity(ctx context.Context, userID int64, provider, idToken string) (*domain.UserIdentity, error)
+	// UnlinkIdentity unlinks the user's identity at the provider.
+	UnlinkIdentity(ctx context.Context, userID int64, provider string) error
+}
+
+// identityService implements IdentityService.
+type identityService struct {
+	dbExecutor   repository.DBExecutor
+	identityRepo repository.IdentityRepository
+	wallets      WalletService
+	verifier     IDTokenVerifier
+	logger       *slog.Logger
+}
+
+// NewIdentityService creates a new instance of IdentityService.
+func NewIdentityService(dbExecutor repository.DBExecutor, identityRepo repository.IdentityRepository, wallets WalletService, verifier IDTokenVerifier, logger *slog.Logger) IdentityService {
+	return &identityService{
+		dbExecutor:   dbExecutor,
+		identityRepo: identityRepo,
+		wallets:      wallets,
+		verifier:     verifier,
+		logger:       logger,
+	}
+}
+
+// Login verifies the ID token first, so nothing is looked up or provisioned for an unverified subject.
+func (s *identityService) Login(ctx context.Context, provider, idToken string) (*OIDCLogin, error) {
+	claims, err := s.verifier.Verify(ctx, provider, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	identity, err := s.identityRepo.GetIdentity(ctx, s.dbExecutor, provider, claims.Subject)
+	if errors.Is(err, util.ErrNotFound) {
+		login, provisionErr := s.provision(ctx, provider, claims)
+		if !errors.Is(provisionErr, util.ErrIdentityLinked) {
+			return login, provisionErr
+		}
+		// A concurrent first login provisioned the user; log in as them
+		identity, err = s.identityRepo.GetIdentity(ctx, s.dbExecutor, provider, claims.Subject)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+
+	user, err := s.wallets.GetUser(ctx, identity.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	if user.DeactivatedAt != nil {
+		return nil, fmt.Errorf("login: %w", util.ErrUserDeactivated)
+	}
+	now := time.Now().UTC()
+	if err := s.identityRepo.TouchIdentity(ctx, s.dbExecutor, identity.ID, now); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	identity.LastLoginAt = &now
+	return &OIDCLogin{User: user, Identity: identity}, nil
+}
+
+// provision creates a user for a provider account seen for the first time, named after the account's
+// preferred username or email. A taken name gets a suffix derived from the subject, then a random one.
+func (s *identityService) provision(ctx context.Context, provider string, claims *domain.IDTokenClaims) (*OIDCLogin, error) {
+	settings, _ := s.verifier.Provider(provider)
+	base := provisionedUsername(claims)
+	digest := sha256.Sum256([]byte(provider + "|" + claims.Subject))
+	candidates := []string{base, base + "-" + hex.EncodeToString(digest[:3]), base + "-" + randomSuffix()}
+
+	var lastErr error
+	for _, username := range candidates {
+		now := time.Now().UTC()
+		identity := domain.NewUserIdentity(0, provider, claims.Subject)
+		identity.LastLoginAt = &now
+		user, wallet, err := s.wallets.CreateUserAndWallet(withProvisionedIdentity(ctx, identity), username, settings.DefaultCurrency, nil)
+		if errors.Is(err, util.ErrDuplicateEntry) {
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("login: provision user: %w", err)
+		}
+		s.logger.Info("User provisioned at first login", "user_id", user.ID, "provider", provider, "identity_id", identity.PublicID)
+		return &OIDCLogin{User: user, Identity: identity, Wallet: wallet, Provisioned: true}, nil
+	}
+	return nil, fmt.Errorf("login: provision user: %w", lastErr)
+}
+
+// provisionedUsername derives a username from the ID token: the preferred username, else the local part
+// of the email, reduced to lower-case letters, digits, dots, underscores and dashes.
+func provisionedUsername(claims *domain.IDTokenClaims) string {
+	name := claims.PreferredUsername
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	name = usernameUnsafe.ReplaceAllString(strings.ToLower(name), "")
+	if len(name) > maxProvisionedUsername {
+		name = name[:maxProvisionedUsername]
+	}
+	if name == "" {
+		name = "user"
+	}
+	return name
+}
+
+func randomSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ListIdentities retrieves the identities of an existing user.
+func (s *identityService) ListIdentities(ctx context.Context, userID int64) ([]domain.UserIdentity, error) {
+	if _, err := s.wallets.GetUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("list identities: %w", err)
+	}
+	identities, err := s.identityRepo.ListIdentitiesByUser(ctx, s.dbExecutor, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list identities: %w", err)
+	}
+	return identities, nil
+}
+
+// LinkIdentity refuses a provider account linked to another user, and a second account at the same provider.
+func (s *identityService) LinkIdentity(ctx context.Context, userID int64, provider, idToken string) (*domain.UserIdentity, error) {
+	if _, err := s.wallets.GetUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("link identity: %w", err)
+	}
+	claims, err := s.verifier.Verify(ctx, provider, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("link identity: %w", err)
+	}
+	existing, err := s.identityRepo.GetIdentity(ctx, s.dbExecutor, provider, claims.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, fmt.Errorf("link identity: %w: to another user", util.ErrIdentityLinked)
+		}
+		return existing, nil
+	}
+	if !errors.Is(err, util.ErrNotFound) {
+		return nil, fmt.Errorf("link identity: %w", err)
+	}
+
+	identity := domain.NewUserIdentity(userID, provider, claims.Subject)
+	if err := s.identityRepo.CreateIdentity(ctx, s.dbExecutor, identity); err != nil {
+		if errors.Is(err, util.ErrDuplicateEntry) {
+			return nil, fmt.Errorf("link identity: %w: the user has a %s identity", util.ErrIdentityLinked, provider)
+		}
+		return nil, fmt.Errorf("link identity: %w", err)
+	}
+	s.logger.Info("Identity linked", "user_id", userID, "provider", provider, "identity_id", identity.PublicID)
+	return identity, nil
+}
+
+// UnlinkIdentity unlinks the identity; the user can then only log in with their other identities.
+func (s *identityService) UnlinkIdentity(ctx context.Context, userID int64, provider string) error {
+	if err := s.identityRepo.DeleteIdentity(ctx, s.dbExecutor, userID, provider); err != nil {
+		return fmt.Errorf("unlink identity: %w", err)
+	}
+	s.logger.Info("Identity unlinked", "user_id", userID, "provider", provider)
+	return nil
+}
@@ -0,0 +1,183 @@
+// internal/service/identity_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// stubVerifier verifies the ID tokens it knows the claims of.
+type stubVerifier map[string]*domain.IDTokenClaims
+
+func (v stubVerifier) Provider(name string) (OIDCProvider, bool) {
+	return OIDCProvider{Issuer: "https://id.example.com", Audiences: []string{"finflow"}, DefaultCurrency: "EUR"}, name == "acme"
+}
+
+func (v stubVerifier) Verify(ctx context.Context, provider, rawToken string) (*domain.IDTokenClaims, error) {
+	if provider != "acme" {
+		return nil, util.ErrNotFound
+	}
+	claims, ok := v[rawToken]
+	if !ok {
+		return nil, util.ErrInvalidIDToken
+	}
+	return claims, nil
+}
+
+// TestIdentityService tests the login with ID tokens, provisioning users at their first login, and linking.
+func TestIdentityService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	verifier := stubVerifier{
+		"token-ada": {Issuer: "https://id.example.com", Subject: "sub-ada", PreferredUsername: "Ada.Lovelace", ExpiresAt: time.Now().Add(time.Hour)},
+	}
+
+	type mocks struct {
+		identityRepo *MockIdentityRepository
+		userRepo     *MockUserRepository
+		walletRepo   *MockWalletRepository
+		txController *MockTxController
+		dbExecutor   *MockDBExecutor
+	}
+	newService := func() (IdentityService, mocks) {
+		m := mocks{
+			identityRepo: new(MockIdentityRepository),
+			userRepo:     new(MockUserRepository),
+			walletRepo:   new(MockWalletRepository),
+			txController: new(MockTxController),
+			dbExecutor:   new(MockDBExecutor),
+		}
+		m.txController.On("Rollback").Return(nil).Maybe()
+		wallets := NewWalletService(new(MockDBBeginner), m.dbExecutor, m.userRepo, m.walletRepo, new(MockTransactionRepository),
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return m.txController, nil },
+			func(tx db.TxController) error { return m.txController.Commit() },
+			func(tx db.TxController) { _ = m.txController.Rollback() },
+			WithIdentities(m.identityRepo))
+		return NewIdentityService(m.dbExecutor, m.identityRepo, wallets, verifier, logger), m
+	}
+
+	t.Run("LoginOfLinkedAccount", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		identity := &domain.UserIdentity{ID: 3, UserID: 10, Provider: "acme", Subject: "sub-ada"}
+		m.identityRepo.On("GetIdentity", ctx, m.dbExecutor, "acme", "sub-ada").Return(identity, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, int64(10)).Return(&domain.User{ID: 10, Username: "ada"}, nil).Once()
+		m.identityRepo.On("TouchIdentity", ctx, m.dbExecutor, int64(3), mock.Anything).Return(nil).Once()
+
+		login, err := service.Login(ctx, "acme", "token-ada")
+
+		require.NoError(t, err)
+		assert.False(t, login.Provisioned)
+		assert.Equal(t, int64(10), login.User.ID)
+		assert.NotNil(t, login.Identity.LastLoginAt)
+		m.userRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("FirstLoginProvisionsUserWithIdentity", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.identityRepo.On("GetIdentity", ctx, m.dbExecutor, "acme", "sub-ada").Return(nil, util.ErrNotFound).Once()
+		// The sanitized preferred username is taken, so the subject-derived suffix is used
+		m.userRepo.On("GetUserByUsername", mock.Anything, m.txController, "ada.lovelace").Return(&domain.User{ID: 7}, nil).Once()
+		m.userRepo.On("GetUserByUsername", mock.Anything, m.txController, mock.MatchedBy(func(name string) bool {
+			return len(name) == len("ada.lovelace-")+6
+		})).Return(nil, util.ErrNotFound).Once()
+		m.userRepo.On("CreateUser", mock.Anything, m.txController, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) {
+			args.Get(2).(*domain.User).ID = 11
+		}).Return(nil).Once()
+		m.identityRepo.On("CreateIdentity", mock.Anything, m.txController, mock.MatchedBy(func(identity *domain.UserIdentity) bool {
+			return identity.UserID == 11 && identity.Provider == "acme" && identity.Subject == "sub-ada"
+		})).Return(nil).Once()
+		m.walletRepo.On("CreateWallet", mock.Anything, m.txController, mock.MatchedBy(func(wallet *domain.Wallet) bool {
+			return wallet.UserID == 11 && wallet.Currency == "EUR"
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		login, err := service.Login(ctx, "acme", "token-ada")
+
+		require.NoError(t, err)
+		assert.True(t, login.Provisioned)
+		assert.Equal(t, int64(11), login.User.ID)
+		assert.Equal(t, "EUR", login.Wallet.Currency)
+		m.identityRepo.AssertExpectations(t)
+		m.walletRepo.AssertExpectations(t)
+	})
+
+	t.Run("ConcurrentFirstLoginLogsInAsTheWinner", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		winner := &domain.UserIdentity{ID: 4, UserID: 12, Provider: "acme", Subject: "sub-ada"}
+		m.identityRepo.On("GetIdentity", ctx, m.dbExecutor, "acme", "sub-ada").Return(nil, util.ErrNotFound).Once()
+		m.userRepo.On("GetUserByUsername", mock.Anything, m.txController, "ada.lovelace").Return(nil, util.ErrNotFound).Once()
+		m.userRepo.On("CreateUser", mock.Anything, m.txController, mock.AnythingOfType("*domain.User")).Return(nil).Once()
+		m.identityRepo.On("CreateIdentity", mock.Anything, m.txController, mock.Anything).Return(util.ErrDuplicateEntry).Once()
+		m.identityRepo.On("GetIdentity", ctx, m.dbExecutor, "acme", "sub-ada").Return(winner, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, int64(12)).Return(&domain.User{ID: 12}, nil).Once()
+		m.identityRepo.On("TouchIdentity", ctx, m.dbExecutor, int64(4), mock.Anything).Return(nil).Once()
+
+		login, err := service.Login(ctx, "acme", "token-ada")
+
+		require.NoError(t, err)
+		assert.False(t, login.Provisioned)
+		assert.Equal(t, int64(12), login.User.ID)
+		m.walletRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("DeactivatedUserCannotLogIn", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		deactivated := time.Now()
+		identity := &domain.UserIdentity{ID: 3, UserID: 10, Provider: "acme", Subject: "sub-ada"}
+		m.identityRepo.On("GetIdentity", ctx, m.dbExecutor, "acme", "sub-ada").Return(identity, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, int64(10)).Return(&domain.User{ID: 10, DeactivatedAt: &deactivated}, nil).Once()
+
+		_, err := service.Login(ctx, "acme", "token-ada")
+
+		assert.ErrorIs(t, err, util.ErrUserDeactivated)
+		m.identityRepo.AssertNotCalled(t, "TouchIdentity", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidTokenLooksNothingUp", func(t *testing.T) {
+		service, m := newService()
+
+		_, err := service.Login(context.Background(), "acme", "forged")
+
+		assert.ErrorIs(t, err, util.ErrInvalidIDToken)
+		m.identityRepo.AssertNotCalled(t, "GetIdentity", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("LinkAccountOfAnotherUser", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, int64(10)).Return(&domain.User{ID: 10}, nil).Once()
+		m.identityRepo.On("GetIdentity", ctx, m.dbExecutor, "acme", "sub-ada").Return(&domain.UserIdentity{ID: 4, UserID: 12}, nil).Once()
+
+		_, err := service.LinkIdentity(ctx, 10, "acme", "token-ada")
+
+		assert.ErrorIs(t, err, util.ErrIdentityLinked)
+		m.identityRepo.AssertNotCalled(t, "CreateIdentity", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("LinkSecondAccountAtProvider", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, int64(10)).Return(&domain.User{ID: 10}, nil).Once()
+		m.identityRepo.On("GetIdentity", ctx, m.dbExecutor, "acme", "sub-ada").Return(nil, util.ErrNotFound).Once()
+		m.identityRepo.On("CreateIdentity", ctx, m.dbExecutor, mock.Anything).Return(util.ErrDuplicateEntry).Once()
+
+		_, err := service.LinkIdentity(ctx, 10, "acme", "token-ada")
+
+		assert.ErrorIs(t, err, util.ErrIdentityLinked)
+	})
+}
@@ -0,0 +1,207 @@
+// internal/service/impersonation_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// ImpersonationService defines the interface for support impersonation sessions and their audit trail.
+// Requests are matched to sessions and audited by the HTTP middleware; WalletService checks, through
+// WithImpersonation, that an impersonated transfer leaves a wallet of the impersonated user, and uses up the
+// session's one transfer in the transaction making it.
+type ImpersonationService interface {
+	// StartSession creates a session for acting as the user. The returned session carries its token,
+	// which is not stored and cannot be retrieved again.
+	StartSession(ctx context.Context, userID int64, operator, reason string, allowTransfer bool) (*domain.ImpersonationSession, error)
+	// Authenticate returns the active session of the token, or util.ErrNotFound.
+	Authenticate(ctx context.Context, token string) (*domain.ImpersonationSession, error)
+	// Record stores an audit entry for a request made with the session.
+	Record(ctx context.Context, entry *domain.ImpersonationAuditEntry) error
+	GetSession(ctx context.Context, publicID uuid.UUID) (*domain.ImpersonationSession, error)
+	// EndSession revokes a session before it expires.
+	EndSession(ctx context.Context, publicID uuid.UUID) error
+	ListAuditEntries(ctx context.Context, publicID uuid.UUID) ([]domain.ImpersonationAuditEntry, error)
+}
+
+type impersonationKey struct{}
+
+// WithImpersonation marks ctx as acting for the session's user.
+func WithImpersonation(ctx context.Context, session *domain.ImpersonationSession) context.Context {
+	return context.WithValue(ctx, impersonationKey{}, session)
+}
+
+// ImpersonationFromContext returns the impersonation session ctx acts for, or nil.
+func ImpersonationFromContext(ctx context.Context) *domain.ImpersonationSession {
+	session, _ := ctx.Value(impersonationKey{}).(*domain.ImpersonationSession)
+	return session
+}
+
+// checkImpersonatedOwner lets an impersonated operation touch only the impersonated user's wallets.
+func checkImpersonatedOwner(ctx context.Context, wallet *domain.Wallet) error {
+	session := ImpersonationFromContext(ctx)
+	if session != nil && wallet.UserID != session.UserID {
+		return fmt.Errorf("%w: wallet does not belong to the impersonated user", util.ErrForbidden)
+	}
+	return nil
+}
+
+// WithImpersonations lets impersonation sessions created with allow_transfer make their one transfer. Without it
+// impersonated transfers fail with util.ErrForbidden.
+func WithImpersonations(impersonationRepo repository.ImpersonationRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.impersonations = impersonationRepo
+	}
+}
+
+// claimImpersonatedTransfer uses up the one transfer of the impersonation session ctx acts for, if any. It is
+// claimed in the transaction making the transfer, so a transfer that fails leaves it unused. A dry run only
+// checks that the session has its transfer left.
+func (s *walletService) claimImpersonatedTransfer(ctx context.Context, q repository.DBExecutor) error {
+	session := ImpersonationFromContext(ctx)
+	if session == nil {
+		return nil
+	}
+	denied := fmt.Errorf("%w: the impersonation session may not make a transfer", util.ErrForbidden)
+	if s.impersonations == nil || !session.AllowTransfer || session.TransferClaimedAt != nil {
+		return denied
+	}
+	if isDryRun(ctx) {
+		return nil
+	}
+	// Sessions expire by the real time, like their tokens
+	if err := s.impersonations.ClaimTransfer(ctx, q, session.ID, time.Now().UTC()); err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return denied
+		}
+		return fmt.Errorf("failed to claim impersonation transfer: %w", err)
+	}
+	return nil
+}
+
+// impersonationService implements ImpersonationService.
+type impersonationService struct {
+	dbExecutor        repository.DBExecutor
+	impersonationRepo repository.ImpersonationRepository
+	userRepo          repository.UserRepository
+	ttl               time.Duration // Lifetime of a session
+	logger            *slog.Logger
+}
+
+// NewImpersonationService creates a new instance of ImpersonationService.
+func NewImpersonationService(
+	dbExecutor repository.DBExecutor,
+	impersonationRepo repository.ImpersonationRepository,
+	userRepo repository.UserRepository,
+	ttl time.Duration,
+	logger *slog.Logger,
+) ImpersonationService {
+	return &impersonationService{
+		dbExecutor:        dbExecutor,
+		impersonationRepo: impersonationRepo,
+		userRepo:          userRepo,
+		ttl:               ttl,
+		logger:            logger,
+	}
+}
+
+// StartSession requires the operator to name themselves and give a reason, both of which are kept with the audit trail.
+func (s *impersonationService) StartSession(ctx context.Context, userID int64, operator, reason string, allowTransfer bool) (*domain.ImpersonationSession, error) {
+	operator, reason = strings.TrimSpace(operator), strings.TrimSpace(reason)
+	if operator == "" || reason == "" {
+		return nil, fmt.Errorf("%w: operator and reason are required", util.ErrInvalidInput)
+	}
+	if _, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, userID); err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("start impersonation: %w", err)
+	}
+
+	token, err := domain.NewImpersonationToken()
+	if err != nil {
+		return nil, fmt.Errorf("start impersonation: %w", err)
+	}
+	now := time.Now().UTC()
+	session := &domain.ImpersonationSession{
+		PublicID:      uuid.New(),
+		Token:         token,
+		TokenHash:     domain.HashImpersonationToken(token),
+		UserID:        userID,
+		Operator:      operator,
+		Reason:        reason,
+		AllowTransfer: allowTransfer,
+		ExpiresAt:     now.Add(s.ttl),
+		CreatedAt:     now,
+	}
+	if err := s.impersonationRepo.CreateSession(ctx, s.dbExecutor, session); err != nil {
+		return nil, fmt.Errorf("start impersonation: %w", err)
+	}
+	s.logger.Warn("Impersonation session started", "impersonation_id", session.PublicID, "operator", operator,
+		"user_id", userID, "allow_transfer", allowTransfer, "reason", reason, "expires_at", session.ExpiresAt)
+	return session, nil
+}
+
+// Authenticate treats expired and revoked sessions like unknown tokens.
+func (s *impersonationService) Authenticate(ctx context.Context, token string) (*domain.ImpersonationSession, error) {
+	session, err := s.impersonationRepo.GetSessionByTokenHash(ctx, s.dbExecutor, domain.HashImpersonationToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if !session.Active(time.Now().UTC()) {
+		return nil, util.ErrNotFound
+	}
+	return session, nil
+}
+
+func (s *impersonationService) Record(ctx context.Context, entry *domain.ImpersonationAuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	if err := s.impersonationRepo.CreateAuditEntry(ctx, s.dbExecutor, entry); err != nil {
+		return fmt.Errorf("record impersonation audit entry: %w", err)
+	}
+	return nil
+}
+
+func (s *impersonationService) GetSession(ctx context.Context, publicID uuid.UUID) (*domain.ImpersonationSession, error) {
+	session, err := s.impersonationRepo.GetSessionByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get impersonation session: %w", err)
+	}
+	return session, nil
+}
+
+func (s *impersonationService) EndSession(ctx context.Context, publicID uuid.UUID) error {
+	session, err := s.impersonationRepo.GetSessionByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return fmt.Errorf("end impersonation: %w", err)
+	}
+	if err := s.impersonationRepo.RevokeSession(ctx, s.dbExecutor, session.ID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("end impersonation: %w", err)
+	}
+	s.logger.Warn("Impersonation session ended", "impersonation_id", publicID, "operator", session.Operator, "user_id", session.UserID)
+	return nil
+}
+
+func (s *impersonationService) ListAuditEntries(ctx context.Context, publicID uuid.UUID) ([]domain.ImpersonationAuditEntry, error) {
+	session, err := s.impersonationRepo.GetSessionByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("list impersonation audit entries: %w", err)
+	}
+	entries, err := s.impersonationRepo.ListAuditEntries(ctx, s.dbExecutor, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list impersonation audit entries: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,189 @@
+// internal/service/impersonation_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestImpersonationService tests impersonation sessions: tokens, expiry, and the one corrective transfer.
+func TestImpersonationService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newService := func() (ImpersonationService, *MockImpersonationRepository, *MockUserRepository, *MockDBExecutor) {
+		impersonationRepo := new(MockImpersonationRepository)
+		userRepo := new(MockUserRepository)
+		dbExecutor := new(MockDBExecutor)
+		return NewImpersonationService(dbExecutor, impersonationRepo, userRepo, 30*time.Minute, logger), impersonationRepo, userRepo, dbExecutor
+	}
+
+	t.Run("StartSessionStoresOnlyTheTokenHash", func(t *testing.T) {
+		ctx := context.Background()
+		service, impersonationRepo, userRepo, dbExecutor := newService()
+
+		userRepo.On("GetUserByID", ctx, dbExecutor, int64(10)).Return(&domain.User{ID: 10}, nil).Once()
+		impersonationRepo.On("CreateSession", ctx, dbExecutor, mock.MatchedBy(func(s *domain.ImpersonationSession) bool {
+			return s.TokenHash == domain.HashImpersonationToken(s.Token) && s.UserID == 10 && !s.AllowTransfer
+		})).Return(nil).Once()
+
+		session, err := service.StartSession(ctx, 10, "alice", "CASE-1234", false)
+
+		assert.NoError(t, err)
+		assert.Contains(t, session.Token, "imp_")
+		assert.WithinDuration(t, time.Now().Add(30*time.Minute), session.ExpiresAt, time.Minute)
+		mock.AssertExpectationsForObjects(t, impersonationRepo, userRepo)
+	})
+
+	t.Run("StartSessionRequiresOperatorAndReason", func(t *testing.T) {
+		service, impersonationRepo, _, _ := newService()
+
+		_, err := service.StartSession(context.Background(), 10, "alice", "  ", false)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		impersonationRepo.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AuthenticateRejectsEndedSessions", func(t *testing.T) {
+		ctx := context.Background()
+		service, impersonationRepo, _, dbExecutor := newService()
+		revokedAt := time.Now().Add(-time.Minute)
+
+		impersonationRepo.On("GetSessionByTokenHash", ctx, dbExecutor, domain.HashImpersonationToken("imp_expired")).
+			Return(&domain.ImpersonationSession{ID: 1, ExpiresAt: time.Now().Add(-time.Second)}, nil).Once()
+		impersonationRepo.On("GetSessionByTokenHash", ctx, dbExecutor, domain.HashImpersonationToken("imp_revoked")).
+			Return(&domain.ImpersonationSession{ID: 2, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil).Once()
+
+		_, err := service.Authenticate(ctx, "imp_expired")
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		_, err = service.Authenticate(ctx, "imp_revoked")
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+
+	t.Run("ImpersonatedTransferMustLeaveTheUsersWallet", func(t *testing.T) {
+		ctx := WithImpersonation(context.Background(), &domain.ImpersonationSession{ID: 1, UserID: 10})
+
+		assert.NoError(t, checkImpersonatedOwner(ctx, &domain.Wallet{ID: 1, UserID: 10}))
+		assert.ErrorIs(t, checkImpersonatedOwner(ctx, &domain.Wallet{ID: 2, UserID: 11}), util.ErrForbidden)
+		assert.NoError(t, checkImpersonatedOwner(context.Background(), &domain.Wallet{ID: 2, UserID: 11}))
+	})
+}
+
+// TestImpersonatedTransfer tests that the wallet service uses up the one transfer of an impersonation session
+// in the transaction making the transfer.
+func TestImpersonatedTransfer(t *testing.T) {
+	source := domain.Wallet{ID: 1, UserID: 10, Currency: "USD", Balance: decimal.NewFromInt(100)}
+	destination := domain.Wallet{ID: 2, UserID: 20, Currency: "USD", Balance: decimal.NewFromInt(10)}
+	amount := decimal.NewFromInt(30)
+
+	type mocks struct {
+		walletRepo        *MockWalletRepository
+		transactionRepo   *MockTransactionRepository
+		impersonationRepo *MockImpersonationRepository
+		txController      *MockTxController
+	}
+	newService := func() (WalletService, mocks) {
+		m := mocks{
+			walletRepo:        new(MockWalletRepository),
+			transactionRepo:   new(MockTransactionRepository),
+			impersonationRepo: new(MockImpersonationRepository),
+			txController:      new(MockTxController),
+		}
+		m.txController.On("Rollback").Return(nil).Maybe()
+		service := NewWalletService(
+			new(MockDBBeginner), new(MockDBExecutor), new(MockUserRepository), m.walletRepo, m.transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return m.txController, nil },
+			func(tx db.TxController) error { return m.txController.Commit() },
+			func(tx db.TxController) { _ = m.txController.Rollback() },
+			WithImpersonations(m.impersonationRepo),
+		)
+		return service, m
+	}
+	impersonated := func(session domain.ImpersonationSession) context.Context {
+		return WithImpersonation(context.Background(), &session)
+	}
+	allowed := domain.ImpersonationSession{ID: 3, PublicID: uuid.New(), UserID: 10, AllowTransfer: true}
+
+	t.Run("TransferIsClaimedInItsTransaction", func(t *testing.T) {
+		ctx := impersonated(allowed)
+		service, m := newService()
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{1, 2}).Return([]domain.Wallet{source, destination}, nil).Once()
+		m.impersonationRepo.On("ClaimTransfer", ctx, m.txController, int64(3), mock.AnythingOfType("time.Time")).Return(nil).Once()
+		m.walletRepo.On("UpdateWalletBalances", ctx, m.txController, map[int64]decimal.Decimal{1: amount.Neg(), 2: amount}, mock.Anything).
+			Return([]domain.Wallet{source, destination}, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, amount, "USD")
+
+		assert.NoError(t, err)
+		mock.AssertExpectationsForObjects(t, m.impersonationRepo, m.txController)
+	})
+
+	t.Run("FailedTransferLeavesItUnused", func(t *testing.T) {
+		ctx := impersonated(allowed)
+		service, m := newService()
+		poor := source
+		poor.Balance = decimal.NewFromInt(5)
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{1, 2}).Return([]domain.Wallet{poor, destination}, nil).Once()
+		m.impersonationRepo.On("ClaimTransfer", ctx, m.txController, int64(3), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, amount, "USD")
+
+		// The claim was made in the transaction, which is rolled back rather than committed
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		m.txController.AssertCalled(t, "Rollback")
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("DryRunDoesNotClaim", func(t *testing.T) {
+		ctx := WithDryRun(impersonated(allowed))
+		service, m := newService()
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{1, 2}).Return([]domain.Wallet{source, destination}, nil).Once()
+		m.walletRepo.On("UpdateWalletBalances", ctx, m.txController, map[int64]decimal.Decimal{1: amount.Neg(), 2: amount}, mock.Anything).
+			Return([]domain.Wallet{source, destination}, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, amount, "USD")
+
+		assert.NoError(t, err)
+		m.impersonationRepo.AssertNotCalled(t, "ClaimTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("DryRunOfUsedUpSessionIsForbidden", func(t *testing.T) {
+		claimedAt := time.Now()
+		used := allowed
+		used.TransferClaimedAt = &claimedAt
+		ctx := WithDryRun(impersonated(used))
+		service, m := newService()
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{1, 2}).Return([]domain.Wallet{source, destination}, nil).Once()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, amount, "USD")
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+	})
+
+	t.Run("SecondTransferIsForbidden", func(t *testing.T) {
+		ctx := impersonated(allowed)
+		service, m := newService()
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{1, 2}).Return([]domain.Wallet{source, destination}, nil).Once()
+		// Claimed by a concurrent request since the session was authenticated
+		m.impersonationRepo.On("ClaimTransfer", ctx, m.txController, int64(3), mock.AnythingOfType("time.Time")).Return(util.ErrNotFound).Once()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, amount, "USD")
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
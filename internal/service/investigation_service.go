@@ -0,0 +1,136 @@
+// internal/service/investigation_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// InvestigationService gathers a transaction and its linked entities for support.
+type InvestigationService interface {
+	// Investigate returns the transaction with its wallets, their owners, its parent and child transactions,
+	// the records referencing it, its annotation, the notifications about it and the impersonated requests
+	// that touched it. It fails with util.ErrNotFound for an unknown transaction.
+	Investigate(ctx context.Context, transactionID uuid.UUID) (*domain.TransactionInvestigation, error)
+}
+
+type investigationService struct {
+	dbExecutor        repository.DBExecutor
+	investigationRepo repository.InvestigationRepository
+	transactionRepo   repository.TransactionRepository
+	walletRepo        repository.WalletRepository
+	userRepo          repository.UserRepository
+	annotationRepo    repository.AnnotationRepository
+	logger            *slog.Logger
+}
+
+// NewInvestigationService creates a new instance of InvestigationService.
+func NewInvestigationService(
+	dbExecutor repository.DBExecutor,
+	investigationRepo repository.InvestigationRepository,
+	transactionRepo repository.TransactionRepository,
+	walletRepo repository.WalletRepository,
+	userRepo repository.UserRepository,
+	annotationRepo repository.AnnotationRepository,
+	logger *slog.Logger,
+) InvestigationService {
+	return &investigationService{
+		dbExecutor:        dbExecutor,
+		investigationRepo: investigationRepo,
+		transactionRepo:   transactionRepo,
+		walletRepo:        walletRepo,
+		userRepo:          userRepo,
+		annotationRepo:    annotationRepo,
+		logger:            logger,
+	}
+}
+
+// Investigate reads the entities one after the other, outside a transaction: the view is for reading, and a
+// record changed in between shows as it is now. Soft-deleted wallets and users are not found and left nil.
+func (s *investigationService) Investigate(ctx context.Context, transactionID uuid.UUID) (*domain.TransactionInvestigation, error) {
+	transaction, err := s.transactionRepo.GetTransactionByPublicID(ctx, s.dbExecutor, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("investigate transaction: %w", err)
+	}
+	investigation := &domain.TransactionInvestigation{Transaction: transaction}
+
+	if investigation.FromWallet, investigation.FromUser, err = s.walletAndOwner(ctx, transaction.FromWalletID); err != nil {
+		return nil, fmt.Errorf("investigate transaction: %w", err)
+	}
+	if investigation.ToWallet, investigation.ToUser, err = s.walletAndOwner(ctx, transaction.ToWalletID); err != nil {
+		return nil, fmt.Errorf("investigate transaction: %w", err)
+	}
+
+	if transaction.ParentID != nil {
+		parent, err := s.transactionRepo.GetTransactionByPublicID(ctx, s.dbExecutor, *transaction.ParentID)
+		if err != nil && !errors.Is(err, util.ErrNotFound) {
+			return nil, fmt.Errorf("investigate transaction: %w", err)
+		}
+		investigation.Parent = parent
+	}
+	if investigation.Children, err = s.investigationRepo.ListChildTransactions(ctx, s.dbExecutor, transactionID); err != nil {
+		return nil, fmt.Errorf("investigate transaction: %w", err)
+	}
+	if investigation.References, err = s.investigationRepo.ListTransactionReferences(ctx, s.dbExecutor, transactionID); err != nil {
+		return nil, fmt.Errorf("investigate transaction: %w", err)
+	}
+	annotation, err := s.annotationRepo.GetAnnotation(ctx, s.dbExecutor, transactionID)
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		return nil, fmt.Errorf("investigate transaction: %w", err)
+	}
+	investigation.Annotation = annotation
+
+	var userIDs []int64
+	for _, user := range []*domain.User{investigation.FromUser, investigation.ToUser} {
+		if user != nil {
+			userIDs = append(userIDs, user.ID)
+		}
+	}
+	investigation.Notifications = []domain.Notification{}
+	investigation.Deliveries = []domain.NotificationDelivery{}
+	investigation.Audit = []domain.TransactionAuditEntry{}
+	if len(userIDs) == 0 {
+		return investigation, nil
+	}
+	if investigation.Notifications, err = s.investigationRepo.ListTransactionNotifications(ctx, s.dbExecutor, transactionID, userIDs); err != nil {
+		return nil, fmt.Errorf("investigate transaction: %w", err)
+	}
+	if investigation.Deliveries, err = s.investigationRepo.ListTransactionDeliveries(ctx, s.dbExecutor, transactionID, userIDs); err != nil {
+		return nil, fmt.Errorf("investigate transaction: %w", err)
+	}
+	if investigation.Audit, err = s.investigationRepo.ListTransactionAudit(ctx, s.dbExecutor, transactionID, userIDs); err != nil {
+		return nil, fmt.Errorf("investigate transaction: %w", err)
+	}
+	return investigation, nil
+}
+
+// walletAndOwner reads a side of the transaction; a side without a wallet, or whose wallet or owner was
+// deleted, has neither.
+func (s *investigationService) walletAndOwner(ctx context.Context, walletID *int64) (*domain.Wallet, *domain.User, error) {
+	if walletID == nil {
+		return nil, nil, nil
+	}
+	wallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, *walletID)
+	if errors.Is(err, util.ErrNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, wallet.UserID)
+	if errors.Is(err, util.ErrNotFound) {
+		return wallet, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return wallet, user, nil
+}
@@ -0,0 +1,107 @@
+// internal/service/investigation_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestInvestigationService tests gathering a transaction with its linked entities.
+func TestInvestigationService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	transactionID := uuid.New()
+	type mocks struct {
+		investigationRepo *MockInvestigationRepository
+		transactionRepo   *MockTransactionRepository
+		walletRepo        *MockWalletRepository
+		userRepo          *MockUserRepository
+		annotationRepo    *MockAnnotationRepository
+		dbExecutor        *MockDBExecutor
+	}
+	newService := func() (InvestigationService, mocks) {
+		m := mocks{
+			investigationRepo: new(MockInvestigationRepository),
+			transactionRepo:   new(MockTransactionRepository),
+			walletRepo:        new(MockWalletRepository),
+			userRepo:          new(MockUserRepository),
+			annotationRepo:    new(MockAnnotationRepository),
+			dbExecutor:        new(MockDBExecutor),
+		}
+		return NewInvestigationService(m.dbExecutor, m.investigationRepo, m.transactionRepo, m.walletRepo, m.userRepo, m.annotationRepo, logger), m
+	}
+
+	t.Run("TransferGathersBothSides", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		fromWalletID, toWalletID := int64(1), int64(2)
+		parentID := uuid.New()
+		transaction := &domain.Transaction{PublicID: transactionID, FromWalletID: &fromWalletID, ToWalletID: &toWalletID, ParentID: &parentID}
+		references := []domain.TransactionReference{{Kind: domain.ReferenceCharge, PublicID: uuid.New()}}
+		audit := []domain.TransactionAuditEntry{{Operator: "alice", UserID: 10}}
+
+		m.transactionRepo.On("GetTransactionByPublicID", ctx, m.dbExecutor, transactionID).Return(transaction, nil).Once()
+		m.transactionRepo.On("GetTransactionByPublicID", ctx, m.dbExecutor, parentID).Return(&domain.Transaction{PublicID: parentID}, nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, fromWalletID).Return(&domain.Wallet{ID: fromWalletID, UserID: 10}, nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, toWalletID).Return(&domain.Wallet{ID: toWalletID, UserID: 20}, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, int64(10)).Return(&domain.User{ID: 10}, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, int64(20)).Return(&domain.User{ID: 20}, nil).Once()
+		m.investigationRepo.On("ListChildTransactions", ctx, m.dbExecutor, transactionID).Return([]domain.Transaction{}, nil).Once()
+		m.investigationRepo.On("ListTransactionReferences", ctx, m.dbExecutor, transactionID).Return(references, nil).Once()
+		m.annotationRepo.On("GetAnnotation", ctx, m.dbExecutor, transactionID).Return(nil, util.ErrNotFound).Once()
+		m.investigationRepo.On("ListTransactionNotifications", ctx, m.dbExecutor, transactionID, []int64{10, 20}).Return([]domain.Notification{}, nil).Once()
+		m.investigationRepo.On("ListTransactionDeliveries", ctx, m.dbExecutor, transactionID, []int64{10, 20}).Return([]domain.NotificationDelivery{}, nil).Once()
+		m.investigationRepo.On("ListTransactionAudit", ctx, m.dbExecutor, transactionID, []int64{10, 20}).Return(audit, nil).Once()
+
+		investigation, err := service.Investigate(ctx, transactionID)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), investigation.FromUser.ID)
+		assert.Equal(t, int64(20), investigation.ToUser.ID)
+		assert.Equal(t, parentID, investigation.Parent.PublicID)
+		assert.Nil(t, investigation.Annotation)
+		assert.Equal(t, references, investigation.References)
+		assert.Equal(t, audit, investigation.Audit)
+		mock.AssertExpectationsForObjects(t, m.investigationRepo, m.transactionRepo, m.walletRepo, m.userRepo, m.annotationRepo)
+	})
+
+	t.Run("DeletedWalletLeavesSideEmpty", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		toWalletID := int64(2)
+		transaction := &domain.Transaction{PublicID: transactionID, ToWalletID: &toWalletID}
+
+		m.transactionRepo.On("GetTransactionByPublicID", ctx, m.dbExecutor, transactionID).Return(transaction, nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, toWalletID).Return(nil, util.ErrNotFound).Once()
+		m.investigationRepo.On("ListChildTransactions", ctx, m.dbExecutor, transactionID).Return([]domain.Transaction{}, nil).Once()
+		m.investigationRepo.On("ListTransactionReferences", ctx, m.dbExecutor, transactionID).Return([]domain.TransactionReference{}, nil).Once()
+		m.annotationRepo.On("GetAnnotation", ctx, m.dbExecutor, transactionID).Return(nil, util.ErrNotFound).Once()
+
+		investigation, err := service.Investigate(ctx, transactionID)
+
+		require.NoError(t, err)
+		assert.Nil(t, investigation.FromWallet)
+		assert.Nil(t, investigation.ToWallet)
+		assert.Empty(t, investigation.Notifications)
+		m.investigationRepo.AssertNotCalled(t, "ListTransactionAudit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnknownTransactionIsNotFound", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.transactionRepo.On("GetTransactionByPublicID", ctx, m.dbExecutor, transactionID).Return(nil, util.ErrNotFound).Once()
+
+		_, err := service.Investigate(ctx, transactionID)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+}
@@ -0,0 +1,388 @@
+// internal/service/joint_wallet_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// JointWalletService defines the interface for shared wallets: membership, approval policies
+// and transfers awaiting the approval of the wallet's owners.
+type JointWalletService interface {
+	// ListMembers returns the wallet's members, starting with its creator.
+	ListMembers(ctx context.Context, wallet *domain.Wallet) ([]domain.WalletMember, error)
+	AddMember(ctx context.Context, wallet *domain.Wallet, userID int64, role domain.WalletRole) (*domain.WalletMember, error)
+	RemoveMember(ctx context.Context, wallet *domain.Wallet, userID int64) error
+	GetApprovalPolicy(ctx context.Context, wallet *domain.Wallet) (*domain.ApprovalPolicy, error)
+	SetApprovalPolicy(ctx context.Context, wallet *domain.Wallet, threshold decimal.Decimal, requiredApprovals int) (*domain.ApprovalPolicy, error)
+	DeleteApprovalPolicy(ctx context.Context, wallet *domain.Wallet) error
+	// RequestTransferApproval holds a transfer back until enough owners approve it. An owner requesting
+	// the transfer approves it at the same time.
+	RequestTransferApproval(ctx context.Context, from, to *domain.Wallet, amount decimal.Decimal, currency string, requestedBy *int64) (*domain.TransferApproval, error)
+	GetTransferApproval(ctx context.Context, publicID uuid.UUID) (*domain.TransferApproval, error)
+	// ApproveTransfer records an owner's approval and executes the transfer once enough owners approved.
+	ApproveTransfer(ctx context.Context, publicID uuid.UUID, userID int64) (*domain.TransferApproval, error)
+	RejectTransfer(ctx context.Context, publicID uuid.UUID, userID int64) (*domain.TransferApproval, error)
+}
+
+// TransferExecutor makes transfers. WalletService implements it.
+type TransferExecutor interface {
+	Transfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Wallet, *domain.Transaction, error)
+}
+
+type approvedTransferKey struct{}
+
+// withApprovedTransfer marks ctx as executing an approved transfer, which lifts the approval policy check.
+// It is unexported so only this service can grant it.
+func withApprovedTransfer(ctx context.Context, approvalID uuid.UUID) context.Context {
+	return context.WithValue(ctx, approvedTransferKey{}, approvalID)
+}
+
+func isApprovedTransfer(ctx context.Context) bool {
+	_, ok := ctx.Value(approvedTransferKey{}).(uuid.UUID)
+	return ok
+}
+
+// jointWalletService implements JointWalletService.
+type jointWalletService struct {
+	dbBeginner   db.DBTxBeginner
+	dbExecutor   repository.DBExecutor
+	memberRepo   repository.WalletMemberRepository
+	approvalRepo repository.TransferApprovalRepository
+	walletRepo   repository.WalletRepository
+	transfers    TransferExecutor // Executes approved transfers
+	beginTx      db.BeginTxFunc
+	commitTx     db.CommitTxFunc
+	rollbackTx   db.RollbackTxFunc
+	logger       *slog.Logger
+}
+
+// NewJointWalletService creates a new instance of JointWalletService.
+func NewJointWalletService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	memberRepo repository.WalletMemberRepository,
+	approvalRepo repository.TransferApprovalRepository,
+	walletRepo repository.WalletRepository,
+	transfers TransferExecutor,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) JointWalletService {
+	return &jointWalletService{
+		dbBeginner:   dbBeginner,
+		dbExecutor:   dbExecutor,
+		memberRepo:   memberRepo,
+		approvalRepo: approvalRepo,
+		walletRepo:   walletRepo,
+		transfers:    transfers,
+		beginTx:      beginTx,
+		commitTx:     commitTx,
+		rollbackTx:   rollbackTx,
+		logger:       logger,
+	}
+}
+
+// ListMembers returns the wallet's members, starting with its creator.
+func (s *jointWalletService) ListMembers(ctx context.Context, wallet *domain.Wallet) ([]domain.WalletMember, error) {
+	return s.members(ctx, s.dbExecutor, wallet)
+}
+
+// AddMember grants a user a role on the wallet.
+func (s *jointWalletService) AddMember(ctx context.Context, wallet *domain.Wallet, userID int64, role domain.WalletRole) (*domain.WalletMember, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: unknown wallet role %q", util.ErrInvalidInput, role)
+	}
+	if userID == wallet.UserID {
+		return nil, fmt.Errorf("add wallet member: %w", &util.DuplicateEntryError{Resource: "wallet member"})
+	}
+
+	member := &domain.WalletMember{WalletID: wallet.ID, UserID: userID, Role: role, CreatedAt: time.Now().UTC()}
+	if err := s.memberRepo.AddMember(ctx, s.dbExecutor, member); err != nil {
+		if errors.Is(err, util.ErrDuplicateEntry) {
+			return nil, fmt.Errorf("add wallet member: %w", &util.DuplicateEntryError{Resource: "wallet member"})
+		}
+		return nil, fmt.Errorf("add wallet member: %w", err)
+	}
+	s.logger.Info("Wallet member added", "wallet_id", wallet.PublicID, "user_id", userID, "role", role)
+	return member, nil
+}
+
+// RemoveMember revokes a user's role. Owners cannot be removed while the remaining owners
+// could no longer reach the approvals the wallet's policy requires.
+func (s *jointWalletService) RemoveMember(ctx context.Context, wallet *domain.Wallet, userID int64) error {
+	if userID == wallet.UserID {
+		return fmt.Errorf("%w: the wallet's creator cannot be removed", util.ErrInvalidInput)
+	}
+
+	members, err := s.members(ctx, s.dbExecutor, wallet)
+	if err != nil {
+		return err
+	}
+	if roleOf(members, userID) == domain.WalletRoleOwner {
+		policy, err := s.memberRepo.GetApprovalPolicy(ctx, s.dbExecutor, wallet.ID)
+		if err != nil && !errors.Is(err, util.ErrNotFound) {
+			return fmt.Errorf("remove wallet member: %w", err)
+		}
+		if policy != nil && countOwners(members)-1 < policy.RequiredApprovals {
+			return fmt.Errorf("%w: removing this owner would leave fewer owners than the approval policy requires", util.ErrInvalidInput)
+		}
+	}
+
+	if err := s.memberRepo.RemoveMember(ctx, s.dbExecutor, wallet.ID, userID); err != nil {
+		return fmt.Errorf("remove wallet member: %w", err)
+	}
+	s.logger.Info("Wallet member removed", "wallet_id", wallet.PublicID, "user_id", userID)
+	return nil
+}
+
+// GetApprovalPolicy retrieves the wallet's approval policy.
+func (s *jointWalletService) GetApprovalPolicy(ctx context.Context, wallet *domain.Wallet) (*domain.ApprovalPolicy, error) {
+	policy, err := s.memberRepo.GetApprovalPolicy(ctx, s.dbExecutor, wallet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get approval policy: %w", err)
+	}
+	return policy, nil
+}
+
+// SetApprovalPolicy requires transfers above threshold to be approved by requiredApprovals of the wallet's owners.
+func (s *jointWalletService) SetApprovalPolicy(ctx context.Context, wallet *domain.Wallet, threshold decimal.Decimal, requiredApprovals int) (*domain.ApprovalPolicy, error) {
+	if threshold.IsNegative() {
+		return nil, fmt.Errorf("%w: threshold must not be negative", util.ErrInvalidInput)
+	}
+	members, err := s.members(ctx, s.dbExecutor, wallet)
+	if err != nil {
+		return nil, err
+	}
+	if owners := countOwners(members); requiredApprovals < 1 || requiredApprovals > owners {
+		return nil, fmt.Errorf("%w: required_approvals must be between 1 and the number of owners (%d)", util.ErrInvalidInput, owners)
+	}
+
+	policy := &domain.ApprovalPolicy{
+		WalletID:          wallet.ID,
+		Threshold:         threshold,
+		RequiredApprovals: requiredApprovals,
+		UpdatedAt:         time.Now().UTC(),
+	}
+	if err := s.memberRepo.SaveApprovalPolicy(ctx, s.dbExecutor, policy); err != nil {
+		return nil, fmt.Errorf("set approval policy: %w", err)
+	}
+	s.logger.Info("Wallet approval policy set", "wallet_id", wallet.PublicID,
+		"threshold", threshold.String(), "required_approvals", requiredApprovals)
+	return policy, nil
+}
+
+// DeleteApprovalPolicy lifts the wallet's approval policy; transfers no longer need approval.
+func (s *jointWalletService) DeleteApprovalPolicy(ctx context.Context, wallet *domain.Wallet) error {
+	if err := s.memberRepo.DeleteApprovalPolicy(ctx, s.dbExecutor, wallet.ID); err != nil {
+		return fmt.Errorf("delete approval policy: %w", err)
+	}
+	s.logger.Info("Wallet approval policy deleted", "wallet_id", wallet.PublicID)
+	return nil
+}
+
+// RequestTransferApproval holds a transfer back until enough owners approve it.
+func (s *jointWalletService) RequestTransferApproval(ctx context.Context, from, to *domain.Wallet, amount decimal.Decimal, currency string, requestedBy *int64) (*domain.TransferApproval, error) {
+	policy, err := s.memberRepo.GetApprovalPolicy(ctx, s.dbExecutor, from.ID)
+	if err != nil {
+		return nil, fmt.Errorf("request transfer approval: %w", err)
+	}
+
+	requesterIsOwner := false
+	if requestedBy != nil {
+		members, err := s.members(ctx, s.dbExecutor, from)
+		if err != nil {
+			return nil, err
+		}
+		role := roleOf(members, *requestedBy)
+		if !role.CanSpend() {
+			return nil, util.ErrForbidden
+		}
+		requesterIsOwner = role == domain.WalletRoleOwner
+	}
+
+	now := time.Now().UTC()
+	approval := &domain.TransferApproval{
+		PublicID:           uuid.New(),
+		FromWalletID:       from.ID,
+		ToWalletID:         to.ID,
+		FromWalletPublicID: from.PublicID,
+		ToWalletPublicID:   to.PublicID,
+		Amount:             amount,
+		Currency:           currency,
+		RequestedBy:        requestedBy,
+		RequiredApprovals:  policy.RequiredApprovals,
+		ApprovedBy:         []int64{},
+		Status:             domain.TransferApprovalPending,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := s.approvalRepo.CreateApproval(ctx, s.dbExecutor, approval); err != nil {
+		return nil, fmt.Errorf("request transfer approval: %w", err)
+	}
+	s.logger.Info("Transfer awaiting approval", "approval_id", approval.PublicID, "from_wallet_id", from.PublicID,
+		"amount", amount.String(), "required_approvals", approval.RequiredApprovals)
+
+	if requesterIsOwner {
+		return s.ApproveTransfer(ctx, approval.PublicID, *requestedBy)
+	}
+	return approval, nil
+}
+
+// GetTransferApproval retrieves a transfer approval request.
+func (s *jointWalletService) GetTransferApproval(ctx context.Context, publicID uuid.UUID) (*domain.TransferApproval, error) {
+	approval, err := s.approvalRepo.GetApprovalByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get transfer approval: %w", err)
+	}
+	return approval, nil
+}
+
+// ApproveTransfer records an owner's approval. The request row is locked while voting, so exactly one
+// approval moves it to APPROVED; that caller then executes the transfer.
+func (s *jointWalletService) ApproveTransfer(ctx context.Context, publicID uuid.UUID, userID int64) (*domain.TransferApproval, error) {
+	approval, err := s.vote(ctx, publicID, userID, func(q repository.DBExecutor, approval *domain.TransferApproval) error {
+		if err := s.approvalRepo.AddVote(ctx, q, approval.ID, userID); err != nil {
+			return err
+		}
+		approval.ApprovedBy = append(approval.ApprovedBy, userID)
+		if len(approval.ApprovedBy) >= approval.RequiredApprovals {
+			approval.Status = domain.TransferApprovalApproved
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("approve transfer: %w", err)
+	}
+	if approval.Status == domain.TransferApprovalApproved {
+		s.execute(ctx, approval)
+	}
+	return approval, nil
+}
+
+// RejectTransfer lets an owner cancel a pending transfer.
+func (s *jointWalletService) RejectTransfer(ctx context.Context, publicID uuid.UUID, userID int64) (*domain.TransferApproval, error) {
+	approval, err := s.vote(ctx, publicID, userID, func(q repository.DBExecutor, approval *domain.TransferApproval) error {
+		approval.Status = domain.TransferApprovalRejected
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reject transfer: %w", err)
+	}
+	s.logger.Info("Transfer rejected", "approval_id", approval.PublicID, "user_id", userID)
+	return approval, nil
+}
+
+// vote locks a pending approval request, checks that userID owns the source wallet and applies cast
+// within one transaction.
+func (s *jointWalletService) vote(ctx context.Context, publicID uuid.UUID, userID int64, cast func(q repository.DBExecutor, approval *domain.TransferApproval) error) (*domain.TransferApproval, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("transaction controller does not implement DBExecutor")
+	}
+
+	approval, err := s.approvalRepo.GetApprovalByPublicIDForUpdate(ctx, txExecutor, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if approval.Status != domain.TransferApprovalPending {
+		return nil, util.ErrApprovalNotPending
+	}
+
+	wallet, err := s.walletRepo.GetWalletByID(ctx, txExecutor, approval.FromWalletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source wallet %d: %w", approval.FromWalletID, err)
+	}
+	members, err := s.members(ctx, txExecutor, wallet)
+	if err != nil {
+		return nil, err
+	}
+	if roleOf(members, userID) != domain.WalletRoleOwner {
+		return nil, util.ErrForbidden
+	}
+
+	if err := cast(txExecutor, approval); err != nil {
+		if errors.Is(err, util.ErrDuplicateEntry) {
+			return nil, &util.DuplicateEntryError{Resource: "approval"}
+		}
+		return nil, err
+	}
+	approval.UpdatedAt = time.Now().UTC()
+	if err := s.approvalRepo.UpdateApproval(ctx, txExecutor, approval); err != nil {
+		return nil, err
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return approval, nil
+}
+
+// execute makes an approved transfer and records the outcome. A failed transfer (e.g. insufficient
+// funds by the time the last owner approved) ends the request as FAILED rather than retrying it.
+func (s *jointWalletService) execute(ctx context.Context, approval *domain.TransferApproval) {
+	_, _, transaction, err := s.transfers.Transfer(withApprovedTransfer(ctx, approval.PublicID),
+		approval.FromWalletID, approval.ToWalletID, approval.Amount, approval.Currency)
+	if err != nil {
+		reason := err.Error()
+		approval.Status = domain.TransferApprovalFailed
+		approval.FailureReason = &reason
+		s.logger.Warn("Approved transfer failed", "approval_id", approval.PublicID, "error", err)
+	} else {
+		approval.Status = domain.TransferApprovalExecuted
+		approval.TransactionID = &transaction.PublicID
+		s.logger.Info("Approved transfer executed", "approval_id", approval.PublicID, "transaction_id", transaction.PublicID)
+	}
+
+	approval.UpdatedAt = time.Now().UTC()
+	if err := s.approvalRepo.UpdateApproval(ctx, s.dbExecutor, approval); err != nil {
+		s.logger.Error("Failed to record outcome of approved transfer", "approval_id", approval.PublicID, "status", approval.Status, "error", err)
+	}
+}
+
+// members returns the wallet's creator as an owner followed by the stored members.
+func (s *jointWalletService) members(ctx context.Context, q repository.DBExecutor, wallet *domain.Wallet) ([]domain.WalletMember, error) {
+	stored, err := s.memberRepo.ListMembers(ctx, q, wallet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list wallet members: %w", err)
+	}
+	creator := domain.WalletMember{WalletID: wallet.ID, UserID: wallet.UserID, Role: domain.WalletRoleOwner, CreatedAt: wallet.CreatedAt}
+	return append([]domain.WalletMember{creator}, stored...), nil
+}
+
+func roleOf(members []domain.WalletMember, userID int64) domain.WalletRole {
+	for _, member := range members {
+		if member.UserID == userID {
+			return member.Role
+		}
+	}
+	return ""
+}
+
+func countOwners(members []domain.WalletMember) int {
+	owners := 0
+	for _, member := range members {
+		if member.Role == domain.WalletRoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
@@ -0,0 +1,161 @@
+// internal/service/joint_wallet_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestJointWalletService tests membership rules and the N-of-M transfer approval workflow.
+func TestJointWalletService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD"}
+	members := []domain.WalletMember{
+		{WalletID: 1, UserID: 11, Role: domain.WalletRoleOwner},
+		{WalletID: 1, UserID: 12, Role: domain.WalletRoleSpender},
+	}
+
+	type mocks struct {
+		dbExecutor   *MockDBExecutor
+		memberRepo   *MockWalletMemberRepository
+		approvalRepo *MockTransferApprovalRepository
+		walletRepo   *MockWalletRepository
+		transfers    *MockTransferExecutor
+		txController *MockTxController
+	}
+	newService := func() (JointWalletService, mocks) {
+		m := mocks{
+			dbExecutor:   new(MockDBExecutor),
+			memberRepo:   new(MockWalletMemberRepository),
+			approvalRepo: new(MockTransferApprovalRepository),
+			walletRepo:   new(MockWalletRepository),
+			transfers:    new(MockTransferExecutor),
+			txController: new(MockTxController),
+		}
+		service := NewJointWalletService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.memberRepo,
+			m.approvalRepo,
+			m.walletRepo,
+			m.transfers,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+	pendingApproval := func() *domain.TransferApproval {
+		return &domain.TransferApproval{
+			ID:                5,
+			PublicID:          uuid.New(),
+			FromWalletID:      1,
+			ToWalletID:        2,
+			Amount:            decimal.NewFromInt(500),
+			Currency:          "USD",
+			RequiredApprovals: 2,
+			ApprovedBy:        []int64{10},
+			Status:            domain.TransferApprovalPending,
+		}
+	}
+
+	t.Run("QuorumExecutesTransfer", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		approval := pendingApproval()
+		transaction := &domain.Transaction{PublicID: uuid.New()}
+
+		m.approvalRepo.On("GetApprovalByPublicIDForUpdate", ctx, m.txController, approval.PublicID).Return(approval, nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.txController, int64(1)).Return(wallet, nil).Once()
+		m.memberRepo.On("ListMembers", ctx, m.txController, int64(1)).Return(members, nil).Once()
+		m.approvalRepo.On("AddVote", ctx, m.txController, int64(5), int64(11)).Return(nil).Once()
+		m.approvalRepo.On("UpdateApproval", ctx, m.txController, approval).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+		m.transfers.On("Transfer", mock.MatchedBy(isApprovedTransfer), int64(1), int64(2), approval.Amount, "USD").
+			Return(wallet, &domain.Wallet{ID: 2}, transaction, nil).Once()
+		m.approvalRepo.On("UpdateApproval", ctx, m.dbExecutor, approval).Return(nil).Once()
+
+		result, err := service.ApproveTransfer(ctx, approval.PublicID, 11)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.TransferApprovalExecuted, result.Status)
+		assert.Equal(t, []int64{10, 11}, result.ApprovedBy)
+		assert.Equal(t, transaction.PublicID, *result.TransactionID)
+		mock.AssertExpectationsForObjects(t, m.approvalRepo, m.walletRepo, m.memberRepo, m.transfers, m.txController)
+	})
+
+	t.Run("SpenderCannotApprove", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		approval := pendingApproval()
+
+		m.approvalRepo.On("GetApprovalByPublicIDForUpdate", ctx, m.txController, approval.PublicID).Return(approval, nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.txController, int64(1)).Return(wallet, nil).Once()
+		m.memberRepo.On("ListMembers", ctx, m.txController, int64(1)).Return(members, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, err := service.ApproveTransfer(ctx, approval.PublicID, 12)
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		m.approvalRepo.AssertNotCalled(t, "AddVote", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.transfers.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ClosedApprovalRejected", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		approval := pendingApproval()
+		approval.Status = domain.TransferApprovalRejected
+
+		m.approvalRepo.On("GetApprovalByPublicIDForUpdate", ctx, m.txController, approval.PublicID).Return(approval, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, err := service.ApproveTransfer(ctx, approval.PublicID, 11)
+
+		assert.ErrorIs(t, err, util.ErrApprovalNotPending)
+	})
+
+	t.Run("PolicyCannotExceedOwners", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.memberRepo.On("ListMembers", ctx, m.dbExecutor, int64(1)).Return(members, nil).Once()
+
+		_, err := service.SetApprovalPolicy(ctx, wallet, decimal.NewFromInt(100), 3)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.memberRepo.AssertNotCalled(t, "SaveApprovalPolicy", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("LastRequiredOwnerCannotBeRemoved", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.memberRepo.On("ListMembers", ctx, m.dbExecutor, int64(1)).Return(members, nil).Once()
+		m.memberRepo.On("GetApprovalPolicy", ctx, m.dbExecutor, int64(1)).Return(&domain.ApprovalPolicy{RequiredApprovals: 2}, nil).Once()
+
+		err := service.RemoveMember(ctx, wallet, 11)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.memberRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
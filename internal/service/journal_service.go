@@ -0,0 +1,198 @@
+// internal/service/journal_service.go
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// maxJournalRange is the longest date range one journal export may cover.
+const maxJournalRange = 366 * 24 * time.Hour
+
+// JournalService defines the interface for the accounting journal export, which books wallet activity as
+// double-entry journal entries on the accounts of a chart of accounts, for import into an ERP.
+type JournalService interface {
+	// WriteJournal writes the journal entries of the transactions created in [from, to) to w as CSV, one line
+	// per debit or credit, in transaction order. It returns the number of entries written.
+	WriteJournal(ctx context.Context, from, to time.Time, w io.Writer) (int, error)
+}
+
+// LoadChartOfAccounts reads a ChartOfAccounts from a JSON file. Accounts the file leaves out keep their
+// built-in codes; an empty path loads the built-in chart.
+func LoadChartOfAccounts(path string) (domain.ChartOfAccounts, error) {
+	chart := domain.DefaultChartOfAccounts()
+	if path == "" {
+		return chart, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return chart, fmt.Errorf("failed to read chart of accounts: %w", err)
+	}
+	var configured domain.ChartOfAccounts
+	if err := json.Unmarshal(data, &configured); err != nil {
+		return chart, fmt.Errorf("invalid chart of accounts %s: %w", path, err)
+	}
+	for _, account := range []struct{ target, value *string }{
+		{&chart.WalletAccount, &configured.WalletAccount},
+		{&chart.PromoAccount, &configured.PromoAccount},
+		{&chart.SuspenseAccount, &configured.SuspenseAccount},
+	} {
+		if *account.value != "" {
+			*account.target = *account.value
+		}
+	}
+	for txType, account := range configured.CounterAccounts {
+		chart.CounterAccounts[txType] = account
+	}
+	return chart, nil
+}
+
+// journalService implements JournalService.
+type journalService struct {
+	dbExecutor repository.DBExecutor
+	exportRepo repository.ExportRepository
+	chart      domain.ChartOfAccounts
+	batchSize  int // Transactions read per query
+	logger     *slog.Logger
+}
+
+// NewJournalService creates a new instance of JournalService.
+func NewJournalService(dbExecutor repository.DBExecutor, exportRepo repository.ExportRepository, chart domain.ChartOfAccounts, batchSize int, logger *slog.Logger) JournalService {
+	return &journalService{
+		dbExecutor: dbExecutor,
+		exportRepo: exportRepo,
+		chart:      chart,
+		batchSize:  batchSize,
+		logger:     logger,
+	}
+}
+
+var journalHeader = []string{
+	"transaction_id", "date", "transaction_time", "type", "account", "wallet_id", "debit", "credit", "currency", "description",
+}
+
+// WriteJournal reads the range in batches and writes each batch before reading the next, so an export of a
+// long range is streamed rather than held in memory.
+func (s *journalService) WriteJournal(ctx context.Context, from, to time.Time, w io.Writer) (int, error) {
+	if !from.Before(to) || to.Sub(from) > maxJournalRange {
+		return 0, fmt.Errorf("%w: the range must end after it starts and span at most 366 days", util.ErrInvalidInput)
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(journalHeader); err != nil {
+		return 0, err
+	}
+	written, afterID := 0, int64(0)
+	for {
+		transactions, err := s.exportRepo.ListTransactionsInRange(ctx, s.dbExecutor, from.UTC(), to.UTC(), afterID, s.batchSize)
+		if err != nil {
+			return written, fmt.Errorf("write journal: %w", err)
+		}
+		for i := range transactions {
+			entry := s.journalEntry(&transactions[i])
+			if entry == nil {
+				continue
+			}
+			for _, record := range journalRecords(entry) {
+				if err := out.Write(record); err != nil {
+					return written, err
+				}
+			}
+			written++
+		}
+		out.Flush()
+		if err := out.Error(); err != nil {
+			return written, err
+		}
+		if len(transactions) < s.batchSize {
+			return written, nil
+		}
+		afterID = transactions[len(transactions)-1].ID
+	}
+}
+
+// journalEntry books a transaction: the side money leaves is debited and the side it reaches is credited.
+// The part of a debit paid with promotional credits is debited to the promo account instead of the wallet.
+// SPLIT parents move no money themselves, their legs do, and are not booked.
+func (s *journalService) journalEntry(tx *domain.Transaction) *domain.JournalEntry {
+	if tx.Type == domain.TransactionTypeSplit {
+		return nil
+	}
+	entry := &domain.JournalEntry{
+		TransactionID:   tx.PublicID,
+		TransactionTime: tx.TransactionTime,
+		Type:            tx.Type,
+		Currency:        tx.Currency,
+		Description:     tx.Description,
+	}
+
+	debit := tx.Amount
+	if tx.FromWalletID != nil && tx.PromoAmount.IsPositive() {
+		debit = tx.Amount.Sub(tx.PromoAmount)
+		entry.Lines = append(entry.Lines, domain.JournalLine{Account: s.chart.PromoAccount, Debit: tx.PromoAmount})
+	}
+	if debit.IsPositive() {
+		entry.Lines = append(entry.Lines, s.side(tx, tx.FromWalletID, tx.FromWalletPublicID, debit, true))
+	}
+	entry.Lines = append(entry.Lines, s.side(tx, tx.ToWalletID, tx.ToWalletPublicID, tx.Amount, false))
+	return entry
+}
+
+// side books one side of a transaction to the wallet account, or to the type's counter account if the side
+// has no wallet. A counter account falling back to suspense is logged, so the chart can be completed.
+func (s *journalService) side(tx *domain.Transaction, walletID *int64, walletPublicID *uuid.UUID, amount decimal.Decimal, isDebit bool) domain.JournalLine {
+	line := domain.JournalLine{Account: s.chart.WalletAccount, WalletID: walletPublicID}
+	if walletID == nil {
+		line = domain.JournalLine{Account: s.chart.CounterAccount(tx.Type)}
+		if line.Account == s.chart.SuspenseAccount {
+			s.logger.Warn("No counter account mapped; booked to suspense", "transaction_id", tx.PublicID, "type", tx.Type)
+		}
+	}
+	if isDebit {
+		line.Debit = amount
+	} else {
+		line.Credit = amount
+	}
+	return line
+}
+
+// journalRecords formats an entry's lines as CSV records.
+func journalRecords(entry *domain.JournalEntry) [][]string {
+	records := make([][]string, len(entry.Lines))
+	for i, line := range entry.Lines {
+		records[i] = []string{
+			entry.TransactionID.String(),
+			entry.TransactionTime.UTC().Format(time.DateOnly),
+			entry.TransactionTime.UTC().Format(time.RFC3339Nano),
+			string(entry.Type),
+			line.Account,
+			optionalUUID(line.WalletID),
+			journalAmount(line.Debit),
+			journalAmount(line.Credit),
+			entry.Currency,
+			optionalString(entry.Description),
+		}
+	}
+	return records
+}
+
+// journalAmount formats a debit or credit; the unused column of a line is left empty.
+func journalAmount(amount decimal.Decimal) string {
+	if amount.IsZero() {
+		return ""
+	}
+	return amount.String()
+}
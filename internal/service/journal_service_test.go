@@ -0,0 +1,126 @@
+// internal/service/journal_service_test.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestJournalService tests the double-entry booking of transactions on the chart of accounts.
+func TestJournalService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	alice, bob := uuid.New(), uuid.New()
+	aliceID, bobID := int64(1), int64(2)
+	transaction := func(id int64, txType domain.TransactionType, fromWallet, toWallet *int64, amount string) domain.Transaction {
+		tx := domain.Transaction{ID: id, PublicID: uuid.New(), FromWalletID: fromWallet, ToWalletID: toWallet, Amount: decimal.RequireFromString(amount),
+			Currency: "USD", Type: txType, Status: domain.TransactionStatusCompleted, TransactionTime: from.Add(time.Hour)}
+		if fromWallet != nil {
+			tx.FromWalletPublicID = &alice
+		}
+		if toWallet != nil {
+			tx.ToWalletPublicID = &bob
+		}
+		return tx
+	}
+	newService := func(batchSize int) (JournalService, *MockExportRepository, *MockDBExecutor) {
+		exportRepo, dbExecutor := new(MockExportRepository), new(MockDBExecutor)
+		return NewJournalService(dbExecutor, exportRepo, domain.DefaultChartOfAccounts(), batchSize, logger), exportRepo, dbExecutor
+	}
+	lines := func(buf *bytes.Buffer) []string {
+		return strings.Split(strings.TrimSpace(buf.String()), "\n")
+	}
+
+	t.Run("BooksEachSideOnItsAccount", func(t *testing.T) {
+		ctx := context.Background()
+		service, exportRepo, dbExecutor := newService(10)
+		deposit := transaction(1, domain.TransactionTypeDeposit, nil, &bobID, "50")
+		transfer := transaction(2, domain.TransactionTypeTransfer, &aliceID, &bobID, "20")
+		transfer.PromoAmount = decimal.NewFromInt(5)
+		split := transaction(3, domain.TransactionTypeSplit, &aliceID, nil, "20")
+		exportRepo.On("ListTransactionsInRange", ctx, dbExecutor, from, to, int64(0), 10).Return([]domain.Transaction{deposit, transfer, split}, nil).Once()
+		var buf bytes.Buffer
+
+		entries, err := service.WriteJournal(ctx, from, to, &buf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, entries) // The SPLIT parent moves no money
+		assert.Equal(t, []string{
+			"transaction_id,date,transaction_time,type,account,wallet_id,debit,credit,currency,description",
+			deposit.PublicID.String() + ",2026-03-01,2026-03-01T01:00:00Z,DEPOSIT,1100,,50,,USD,",
+			deposit.PublicID.String() + ",2026-03-01,2026-03-01T01:00:00Z,DEPOSIT,2000," + bob.String() + ",,50,USD,",
+			transfer.PublicID.String() + ",2026-03-01,2026-03-01T01:00:00Z,TRANSFER,6100,,5,,USD,",
+			transfer.PublicID.String() + ",2026-03-01,2026-03-01T01:00:00Z,TRANSFER,2000," + alice.String() + ",15,,USD,",
+			transfer.PublicID.String() + ",2026-03-01,2026-03-01T01:00:00Z,TRANSFER,2000," + bob.String() + ",,20,USD,",
+		}, lines(&buf))
+	})
+
+	t.Run("ReadsInBatches", func(t *testing.T) {
+		ctx := context.Background()
+		service, exportRepo, dbExecutor := newService(2)
+		exportRepo.On("ListTransactionsInRange", ctx, dbExecutor, from, to, int64(0), 2).
+			Return([]domain.Transaction{transaction(4, domain.TransactionTypeDeposit, nil, &bobID, "1"), transaction(8, domain.TransactionTypeDeposit, nil, &bobID, "2")}, nil).Once()
+		exportRepo.On("ListTransactionsInRange", ctx, dbExecutor, from, to, int64(8), 2).
+			Return([]domain.Transaction{transaction(9, domain.TransactionTypeWithdrawal, &aliceID, nil, "3")}, nil).Once()
+		var buf bytes.Buffer
+
+		entries, err := service.WriteJournal(ctx, from, to, &buf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, entries)
+		assert.Len(t, lines(&buf), 7)
+		exportRepo.AssertExpectations(t)
+	})
+
+	t.Run("UnmappedCounterAccountUsesSuspense", func(t *testing.T) {
+		ctx := context.Background()
+		service, exportRepo, dbExecutor := newService(10)
+		exportRepo.On("ListTransactionsInRange", ctx, dbExecutor, from, to, int64(0), 10).
+			Return([]domain.Transaction{transaction(1, "REFUND", nil, &bobID, "7")}, nil).Once()
+		var buf bytes.Buffer
+
+		_, err := service.WriteJournal(ctx, from, to, &buf)
+
+		assert.NoError(t, err)
+		assert.Contains(t, lines(&buf)[1], ",REFUND,9999,,7,,USD,")
+	})
+
+	t.Run("RejectsInvalidRange", func(t *testing.T) {
+		service, exportRepo, _ := newService(10)
+
+		_, err := service.WriteJournal(context.Background(), to, from, io.Discard)
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		_, err = service.WriteJournal(context.Background(), from, from.AddDate(2, 0, 0), io.Discard)
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		exportRepo.AssertNotCalled(t, "ListTransactionsInRange")
+	})
+
+	t.Run("LoadChartMergesWithDefaults", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "accounts.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"wallet_account": "2300", "counter_accounts": {"DEPOSIT": "1200", "NETTING": "2400"}}`), 0o600))
+
+		chart, err := LoadChartOfAccounts(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2300", chart.WalletAccount)
+		assert.Equal(t, "6100", chart.PromoAccount)
+		assert.Equal(t, "1200", chart.CounterAccount(domain.TransactionTypeDeposit))
+		assert.Equal(t, "1100", chart.CounterAccount(domain.TransactionTypeWithdrawal))
+		assert.Equal(t, "2400", chart.CounterAccount(domain.TransactionTypeNetting))
+	})
+}
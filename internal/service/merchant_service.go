@@ -0,0 +1,440 @@
+// internal/service/merchant_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// MerchantService defines the interface for merchant wallets: settlement preferences, charges paid by
+// customers, and the daily settlement of a merchant's receipts into its payout wallet.
+type MerchantService interface {
+	// RegisterMerchant turns a wallet into a MERCHANT wallet, or updates its preferences if it already is one.
+	RegisterMerchant(ctx context.Context, wallet *domain.Wallet, payoutWalletID int64, minPayout decimal.Decimal) (*domain.MerchantSettings, error)
+	GetSettings(ctx context.Context, walletID int64) (*domain.MerchantSettings, error)
+	CreateCharge(ctx context.Context, merchant *domain.Wallet, charge *domain.Charge) error
+	GetCharge(ctx context.Context, publicID uuid.UUID) (*domain.Charge, error)
+	// PayCharge confirms a charge on the customer side, moving its amount from the payer wallet to the merchant.
+	PayCharge(ctx context.Context, publicID uuid.UUID, payerWalletID int64) (*domain.Charge, *domain.Transaction, error)
+	CancelCharge(ctx context.Context, publicID uuid.UUID) (*domain.Charge, error)
+	ListSettlements(ctx context.Context, merchantWalletID int64) ([]domain.Settlement, error)
+	// SettleMerchants pays out every merchant's charges paid before the start of now's day in the merchant's time zone.
+	// It is idempotent per day and returns the number of settlements made.
+	SettleMerchants(ctx context.Context, now time.Time) (int, error)
+}
+
+// merchantService implements MerchantService.
+type merchantService struct {
+	dbBeginner      db.DBTxBeginner
+	dbExecutor      repository.DBExecutor
+	merchantRepo    repository.MerchantRepository
+	chargeRepo      repository.ChargeRepository
+	walletRepo      repository.WalletRepository
+	balances        *BalanceWriter // Applies balance changes, on a shard for sharded wallets
+	transactionRepo repository.TransactionRepository
+	chargeTTL       time.Duration      // How long a charge stays payable
+	events          *TransactionEvents // Optional; payments and payouts are published here
+	beginTx         db.BeginTxFunc
+	commitTx        db.CommitTxFunc
+	rollbackTx      db.RollbackTxFunc
+	logger          *slog.Logger
+}
+
+// NewMerchantService creates a new instance of MerchantService.
+func NewMerchantService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	merchantRepo repository.MerchantRepository,
+	chargeRepo repository.ChargeRepository,
+	walletRepo repository.WalletRepository,
+	balances *BalanceWriter,
+	transactionRepo repository.TransactionRepository,
+	chargeTTL time.Duration,
+	events *TransactionEvents,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) MerchantService {
+	return &merchantService{
+		dbBeginner:      dbBeginner,
+		dbExecutor:      dbExecutor,
+		merchantRepo:    merchantRepo,
+		chargeRepo:      chargeRepo,
+		walletRepo:      walletRepo,
+		balances:        balances,
+		transactionRepo: transactionRepo,
+		chargeTTL:       chargeTTL,
+		events:          events,
+		beginTx:         beginTx,
+		commitTx:        commitTx,
+		rollbackTx:      rollbackTx,
+		logger:          logger,
+	}
+}
+
+// RegisterMerchant validates the settlement preferences and stores them together with the wallet kind.
+// The payout wallet must belong to the same user and hold the same currency.
+func (s *merchantService) RegisterMerchant(ctx context.Context, wallet *domain.Wallet, payoutWalletID int64, minPayout decimal.Decimal) (*domain.MerchantSettings, error) {
+	if minPayout.IsNegative() {
+		return nil, fmt.Errorf("%w: min_payout_amount must not be negative", util.ErrInvalidInput)
+	}
+	if payoutWalletID == wallet.ID {
+		return nil, fmt.Errorf("%w: payout wallet must differ from the merchant wallet", util.ErrInvalidInput)
+	}
+	payout, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, payoutWalletID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("register merchant: %w", err)
+	}
+	if payout.UserID != wallet.UserID {
+		return nil, fmt.Errorf("%w: payout wallet does not belong to user %d", util.ErrInvalidInput, wallet.UserID)
+	}
+	if payout.Currency != wallet.Currency {
+		return nil, util.ErrCurrencyMismatch
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("register merchant: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("register merchant: transaction controller does not implement DBExecutor")
+	}
+
+	now := time.Now().UTC()
+	settings := &domain.MerchantSettings{
+		WalletID:             wallet.ID,
+		PayoutWalletID:       payout.ID,
+		WalletPublicID:       wallet.PublicID,
+		PayoutWalletPublicID: payout.PublicID,
+		MinPayoutAmount:      minPayout,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	if err := s.walletRepo.UpdateWalletKind(ctx, txExecutor, wallet.ID, domain.WalletKindMerchant); err != nil {
+		return nil, fmt.Errorf("register merchant: %w", err)
+	}
+	if err := s.merchantRepo.SaveSettings(ctx, txExecutor, settings); err != nil {
+		return nil, fmt.Errorf("register merchant: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("register merchant: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Merchant wallet registered", "wallet_id", wallet.ID, "payout_wallet_id", payout.ID)
+	return settings, nil
+}
+
+// GetSettings retrieves the settlement preferences of a merchant wallet.
+func (s *merchantService) GetSettings(ctx context.Context, walletID int64) (*domain.MerchantSettings, error) {
+	settings, err := s.merchantRepo.GetSettings(ctx, s.dbExecutor, walletID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrNotMerchantWallet
+		}
+		return nil, fmt.Errorf("get merchant settings: %w", err)
+	}
+	return settings, nil
+}
+
+// CreateCharge stores a new PENDING charge against a merchant wallet, payable until the charge TTL elapses.
+func (s *merchantService) CreateCharge(ctx context.Context, merchant *domain.Wallet, charge *domain.Charge) error {
+	if merchant.Kind != domain.WalletKindMerchant {
+		return util.ErrNotMerchantWallet
+	}
+	if !charge.Amount.IsPositive() {
+		return fmt.Errorf("%w: amount must be positive", util.ErrInvalidInput)
+	}
+	if charge.Currency != merchant.Currency {
+		return util.ErrCurrencyMismatch
+	}
+
+	now := time.Now().UTC()
+	charge.PublicID = uuid.New()
+	charge.MerchantWalletID = merchant.ID
+	charge.MerchantWalletPublicID = merchant.PublicID
+	charge.Status = domain.ChargeStatusPending
+	charge.ExpiresAt = now.Add(s.chargeTTL)
+	charge.CreatedAt = now
+	charge.UpdatedAt = now
+	if err := s.chargeRepo.CreateCharge(ctx, s.dbExecutor, charge); err != nil {
+		return fmt.Errorf("create charge: %w", err)
+	}
+	s.logger.Info("Charge created", "charge_id", charge.PublicID, "merchant_wallet_id", merchant.ID, "amount", charge.Amount.String())
+	return nil
+}
+
+// GetCharge retrieves a charge by its public ID.
+func (s *merchantService) GetCharge(ctx context.Context, publicID uuid.UUID) (*domain.Charge, error) {
+	charge, err := s.chargeRepo.GetChargeByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get charge %s: %w", publicID, err)
+	}
+	return charge, nil
+}
+
+// PayCharge moves the charge amount from the payer to the merchant as a PAYMENT and marks the charge PAID,
+// all in one database transaction with the charge row locked, so a charge cannot be paid twice.
+// A charge found past its expiry is marked EXPIRED instead.
+func (s *merchantService) PayCharge(ctx context.Context, publicID uuid.UUID, payerWalletID int64) (*domain.Charge, *domain.Transaction, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pay charge: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, nil, fmt.Errorf("pay charge: transaction controller does not implement DBExecutor")
+	}
+
+	charge, err := s.chargeRepo.GetChargeByPublicIDForUpdate(ctx, txExecutor, publicID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pay charge %s: %w", publicID, err)
+	}
+	now := time.Now().UTC()
+	if charge.Expired(now) {
+		charge.Status = domain.ChargeStatusExpired
+		charge.UpdatedAt = now
+		if err := s.chargeRepo.UpdateCharge(ctx, txExecutor, charge); err != nil {
+			return nil, nil, fmt.Errorf("pay charge %s: %w", publicID, err)
+		}
+		if err := s.commitTx(txController); err != nil {
+			return nil, nil, fmt.Errorf("pay charge: failed to commit transaction: %w", err)
+		}
+		return nil, nil, util.ErrChargeNotPending
+	}
+	if charge.Status != domain.ChargeStatusPending {
+		return nil, nil, util.ErrChargeNotPending
+	}
+	if payerWalletID == charge.MerchantWalletID {
+		return nil, nil, util.ErrSameWalletTransfer
+	}
+
+	wallets, err := lockWallets(ctx, s.walletRepo, txExecutor, payerWalletID, charge.MerchantWalletID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, nil, util.ErrWalletNotFound
+		}
+		return nil, nil, fmt.Errorf("pay charge: %w", err)
+	}
+	payer := wallets[payerWalletID]
+	if payer.Currency != charge.Currency {
+		return nil, nil, util.ErrCurrencyMismatch
+	}
+	if err := checkNotFrozen(payer); err != nil {
+		return nil, nil, fmt.Errorf("pay charge: %w", err)
+	}
+	if payer.Balance.LessThan(charge.Amount) {
+		return nil, nil, util.ErrInsufficientFunds
+	}
+
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, payerWalletID, charge.Amount.Neg()); err != nil {
+		return nil, nil, fmt.Errorf("pay charge: failed to update payer wallet balance: %w", err)
+	}
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, charge.MerchantWalletID, charge.Amount); err != nil {
+		return nil, nil, fmt.Errorf("pay charge: failed to update merchant wallet balance: %w", err)
+	}
+
+	description := fmt.Sprintf("Charge %s", charge.PublicID)
+	transaction := domain.NewTransaction(&payerWalletID, &charge.MerchantWalletID, charge.Amount, charge.Currency, domain.TransactionTypePayment, &description)
+	s.balances.Stamp(transaction)
+	transaction.Category = transactionCategory(ctx)
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, nil, fmt.Errorf("pay charge: failed to create transaction: %w", err)
+	}
+
+	charge.Status = domain.ChargeStatusPaid
+	charge.PayerWalletID = &payerWalletID
+	charge.PayerWalletPublicID = &payer.PublicID
+	charge.TransactionID = &transaction.PublicID
+	charge.PaidAt = &now
+	charge.UpdatedAt = now
+	if err := s.chargeRepo.UpdateCharge(ctx, txExecutor, charge); err != nil {
+		return nil, nil, fmt.Errorf("pay charge %s: %w", publicID, err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, nil, fmt.Errorf("pay charge: failed to commit transaction: %w", err)
+	}
+	s.publish(ctx, transaction)
+	s.logger.Info("Charge paid", "charge_id", charge.PublicID, "transaction_id", transaction.PublicID)
+
+	return charge, transaction, nil
+}
+
+// CancelCharge withdraws a pending charge.
+func (s *merchantService) CancelCharge(ctx context.Context, publicID uuid.UUID) (*domain.Charge, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("cancel charge: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("cancel charge: transaction controller does not implement DBExecutor")
+	}
+
+	charge, err := s.chargeRepo.GetChargeByPublicIDForUpdate(ctx, txExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("cancel charge %s: %w", publicID, err)
+	}
+	if charge.Status != domain.ChargeStatusPending {
+		return nil, util.ErrChargeNotPending
+	}
+
+	charge.Status = domain.ChargeStatusCancelled
+	charge.UpdatedAt = time.Now().UTC()
+	if err := s.chargeRepo.UpdateCharge(ctx, txExecutor, charge); err != nil {
+		return nil, fmt.Errorf("cancel charge %s: %w", publicID, err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("cancel charge: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Charge cancelled", "charge_id", charge.PublicID)
+	return charge, nil
+}
+
+// ListSettlements retrieves the settlements of a merchant wallet.
+func (s *merchantService) ListSettlements(ctx context.Context, merchantWalletID int64) ([]domain.Settlement, error) {
+	settlements, err := s.merchantRepo.ListSettlements(ctx, s.dbExecutor, merchantWalletID)
+	if err != nil {
+		return nil, fmt.Errorf("list settlements: %w", err)
+	}
+	return settlements, nil
+}
+
+// SettleMerchants settles each merchant in its own database transaction; a merchant that fails to settle
+// is logged and retried on the next run without holding back the others.
+func (s *merchantService) SettleMerchants(ctx context.Context, now time.Time) (int, error) {
+	merchants, err := s.merchantRepo.ListSettings(ctx, s.dbExecutor)
+	if err != nil {
+		return 0, fmt.Errorf("settle merchants: %w", err)
+	}
+
+	settled := 0
+	for i := range merchants {
+		local := now.In(domain.TimezoneLocation(merchants[i].OwnerTimezone))
+		cutoff := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+		settlement, err := s.settle(ctx, &merchants[i], cutoff)
+		if err != nil {
+			s.logger.Warn("Merchant settlement failed", "wallet_id", merchants[i].WalletID, "error", err)
+			continue
+		}
+		if settlement != nil {
+			settled++
+		}
+	}
+	return settled, nil
+}
+
+// settle pays out one merchant's unsettled charges paid before the cutoff as a single SETTLEMENT transaction
+// for the business day that ended at the cutoff. It returns nil when there is nothing to pay out yet: no
+// charges, a total below the merchant's minimum payout (the charges roll over), or a day already settled.
+func (s *merchantService) settle(ctx context.Context, merchant *domain.MerchantSettings, cutoff time.Time) (*domain.Settlement, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("transaction controller does not implement DBExecutor")
+	}
+
+	wallets, err := lockWallets(ctx, s.walletRepo, txExecutor, merchant.WalletID, merchant.PayoutWalletID)
+	if err != nil {
+		return nil, err
+	}
+	source := wallets[merchant.WalletID]
+
+	charges, err := s.chargeRepo.ListUnsettledChargesForUpdate(ctx, txExecutor, merchant.WalletID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if len(charges) == 0 {
+		return nil, nil
+	}
+	total := decimal.Zero
+	chargeIDs := make([]int64, 0, len(charges))
+	for _, charge := range charges {
+		total = total.Add(charge.Amount)
+		chargeIDs = append(chargeIDs, charge.ID)
+	}
+	if total.LessThan(merchant.MinPayoutAmount) {
+		return nil, nil
+	}
+	if source.Balance.LessThan(total) {
+		return nil, util.ErrInsufficientFunds
+	}
+
+	description := fmt.Sprintf("Settlement of %d charges", len(charges))
+	transaction := domain.NewTransaction(&merchant.WalletID, &merchant.PayoutWalletID, total, source.Currency, domain.TransactionTypeSettlement, &description)
+	s.balances.Stamp(transaction)
+	settlement := &domain.Settlement{
+		PublicID:               uuid.New(),
+		MerchantWalletID:       merchant.WalletID,
+		PayoutWalletID:         merchant.PayoutWalletID,
+		MerchantWalletPublicID: merchant.WalletPublicID,
+		PayoutWalletPublicID:   merchant.PayoutWalletPublicID,
+		Amount:                 total,
+		Currency:               source.Currency,
+		ChargeCount:            len(charges),
+		SettlementDate:         time.Date(cutoff.Year(), cutoff.Month(), cutoff.Day()-1, 0, 0, 0, 0, time.UTC), // The local calendar date
+		TransactionID:          transaction.PublicID,
+		CreatedAt:              time.Now().UTC(),
+	}
+	if err := s.merchantRepo.CreateSettlement(ctx, txExecutor, settlement); err != nil {
+		if errors.Is(err, util.ErrDuplicateEntry) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := s.chargeRepo.MarkChargesSettled(ctx, txExecutor, settlement.ID, chargeIDs); err != nil {
+		return nil, err
+	}
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, merchant.WalletID, total.Neg()); err != nil {
+		return nil, fmt.Errorf("failed to update merchant wallet balance: %w", err)
+	}
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, merchant.PayoutWalletID, total); err != nil {
+		return nil, fmt.Errorf("failed to update payout wallet balance: %w", err)
+	}
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.publish(ctx, transaction)
+	s.logger.Info("Merchant settled", "wallet_id", merchant.WalletID, "settlement_id", settlement.PublicID,
+		"amount", total.String(), "charges", len(charges))
+	return settlement, nil
+}
+
+// publish hands a committed transaction to the event bus, if one is configured.
+func (s *merchantService) publish(ctx context.Context, transaction *domain.Transaction) {
+	if s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
+}
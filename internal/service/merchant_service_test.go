@@ -0,0 +1,266 @@
+// internal/service/merchant_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestMerchantService tests charge creation, customer payment and the daily settlement batching.
+func TestMerchantService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	merchantWallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Kind: domain.WalletKindMerchant, Balance: decimal.NewFromInt(300)}
+	payerWallet := &domain.Wallet{ID: 2, PublicID: uuid.New(), UserID: 20, Currency: "USD", Kind: domain.WalletKindPersonal, Balance: decimal.NewFromInt(100)}
+	payoutWallet := &domain.Wallet{ID: 3, PublicID: uuid.New(), UserID: 10, Currency: "USD", Kind: domain.WalletKindPersonal}
+
+	type mocks struct {
+		dbExecutor      *MockDBExecutor
+		merchantRepo    *MockMerchantRepository
+		chargeRepo      *MockChargeRepository
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		txController    *MockTxController
+	}
+	newService := func() (MerchantService, mocks) {
+		m := mocks{
+			dbExecutor:      new(MockDBExecutor),
+			merchantRepo:    new(MockMerchantRepository),
+			chargeRepo:      new(MockChargeRepository),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			txController:    new(MockTxController),
+		}
+		service := NewMerchantService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.merchantRepo,
+			m.chargeRepo,
+			m.walletRepo,
+			NewBalanceWriter(m.walletRepo, nil, nil, nil),
+			m.transactionRepo,
+			30*time.Minute,
+			nil,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+	pendingCharge := func() *domain.Charge {
+		return &domain.Charge{
+			ID:                     7,
+			PublicID:               uuid.New(),
+			MerchantWalletID:       merchantWallet.ID,
+			MerchantWalletPublicID: merchantWallet.PublicID,
+			Amount:                 decimal.NewFromInt(40),
+			Currency:               "USD",
+			Status:                 domain.ChargeStatusPending,
+			ExpiresAt:              time.Now().UTC().Add(10 * time.Minute),
+		}
+	}
+
+	t.Run("CreateChargeRequiresMerchantWallet", func(t *testing.T) {
+		service, m := newService()
+
+		err := service.CreateCharge(context.Background(), payerWallet, &domain.Charge{Amount: decimal.NewFromInt(5), Currency: "USD"})
+
+		assert.ErrorIs(t, err, util.ErrNotMerchantWallet)
+		m.chargeRepo.AssertNotCalled(t, "CreateCharge", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PayChargeMovesMoneyAndMarksPaid", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		charge := pendingCharge()
+
+		m.chargeRepo.On("GetChargeByPublicIDForUpdate", ctx, m.txController, charge.PublicID).Return(charge, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, merchantWallet.ID).Return(merchantWallet, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, payerWallet.ID).Return(payerWallet, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, payerWallet.ID, charge.Amount.Neg(), mock.Anything).Return(nil, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, merchantWallet.ID, charge.Amount, mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypePayment && *tx.FromWalletID == payerWallet.ID && *tx.ToWalletID == merchantWallet.ID
+		})).Return(nil).Once()
+		m.chargeRepo.On("UpdateCharge", ctx, m.txController, charge).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		paid, transaction, err := service.PayCharge(ctx, charge.PublicID, payerWallet.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ChargeStatusPaid, paid.Status)
+		assert.Equal(t, transaction.PublicID, *paid.TransactionID)
+		assert.Equal(t, payerWallet.PublicID, *paid.PayerWalletPublicID)
+		mock.AssertExpectationsForObjects(t, m.chargeRepo, m.walletRepo, m.transactionRepo, m.txController)
+	})
+
+	t.Run("ExpiredChargeMarkedExpired", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		charge := pendingCharge()
+		charge.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+
+		m.chargeRepo.On("GetChargeByPublicIDForUpdate", ctx, m.txController, charge.PublicID).Return(charge, nil).Once()
+		m.chargeRepo.On("UpdateCharge", ctx, m.txController, charge).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		_, _, err := service.PayCharge(ctx, charge.PublicID, payerWallet.ID)
+
+		assert.ErrorIs(t, err, util.ErrChargeNotPending)
+		assert.Equal(t, domain.ChargeStatusExpired, charge.Status)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, m.chargeRepo, m.txController)
+	})
+
+	t.Run("PaidChargeCannotBePaidAgain", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		charge := pendingCharge()
+		charge.Status = domain.ChargeStatusPaid
+
+		m.chargeRepo.On("GetChargeByPublicIDForUpdate", ctx, m.txController, charge.PublicID).Return(charge, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := service.PayCharge(ctx, charge.PublicID, payerWallet.ID)
+
+		assert.ErrorIs(t, err, util.ErrChargeNotPending)
+		mock.AssertExpectationsForObjects(t, m.chargeRepo, m.txController)
+	})
+
+	t.Run("SettlementPaysOutPreviousDay", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		now := time.Date(2025, time.May, 6, 1, 0, 0, 0, time.UTC)
+		cutoff := time.Date(2025, time.May, 6, 0, 0, 0, 0, time.UTC)
+		settings := []domain.MerchantSettings{{WalletID: merchantWallet.ID, PayoutWalletID: payoutWallet.ID, MinPayoutAmount: decimal.NewFromInt(50)}}
+		charges := []domain.Charge{{ID: 7, Amount: decimal.NewFromInt(40)}, {ID: 8, Amount: decimal.NewFromInt(25)}}
+
+		m.merchantRepo.On("ListSettings", ctx, m.dbExecutor).Return(settings, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, merchantWallet.ID).Return(merchantWallet, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, payoutWallet.ID).Return(payoutWallet, nil).Once()
+		m.chargeRepo.On("ListUnsettledChargesForUpdate", ctx, m.txController, merchantWallet.ID, cutoff).Return(charges, nil).Once()
+		m.merchantRepo.On("CreateSettlement", ctx, m.txController, mock.MatchedBy(func(s *domain.Settlement) bool {
+			return s.Amount.Equal(decimal.NewFromInt(65)) && s.ChargeCount == 2 && s.SettlementDate.Equal(cutoff.AddDate(0, 0, -1))
+		})).Return(nil).Once()
+		m.chargeRepo.On("MarkChargesSettled", ctx, m.txController, mock.AnythingOfType("int64"), []int64{7, 8}).Return(nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, merchantWallet.ID, decimal.NewFromInt(65).Neg(), mock.Anything).Return(nil, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, payoutWallet.ID, decimal.NewFromInt(65), mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeSettlement
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		settled, err := service.SettleMerchants(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, settled)
+		mock.AssertExpectationsForObjects(t, m.merchantRepo, m.chargeRepo, m.walletRepo, m.transactionRepo, m.txController)
+	})
+
+	t.Run("SettlementBelowMinimumRollsOver", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		now := time.Date(2025, time.May, 6, 1, 0, 0, 0, time.UTC)
+		settings := []domain.MerchantSettings{{WalletID: merchantWallet.ID, PayoutWalletID: payoutWallet.ID, MinPayoutAmount: decimal.NewFromInt(50)}}
+
+		m.merchantRepo.On("ListSettings", ctx, m.dbExecutor).Return(settings, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, mock.AnythingOfType("int64")).Return(merchantWallet, nil).Twice()
+		m.chargeRepo.On("ListUnsettledChargesForUpdate", ctx, m.txController, merchantWallet.ID, mock.AnythingOfType("time.Time")).
+			Return([]domain.Charge{{ID: 7, Amount: decimal.NewFromInt(40)}}, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		settled, err := service.SettleMerchants(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, settled)
+		m.merchantRepo.AssertNotCalled(t, "CreateSettlement", mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, m.merchantRepo, m.chargeRepo, m.walletRepo, m.txController)
+	})
+
+	t.Run("SettlementDayFollowsMerchantTimezone", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		// 01:00 UTC on May 6 is the evening of May 5 in Los Angeles (PDT, UTC-7), so May 4 is settled
+		now := time.Date(2025, time.May, 6, 1, 0, 0, 0, time.UTC)
+		cutoff := time.Date(2025, time.May, 5, 7, 0, 0, 0, time.UTC)
+		settings := []domain.MerchantSettings{{WalletID: merchantWallet.ID, PayoutWalletID: payoutWallet.ID, OwnerTimezone: "America/Los_Angeles"}}
+
+		m.merchantRepo.On("ListSettings", ctx, m.dbExecutor).Return(settings, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, merchantWallet.ID).Return(merchantWallet, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, payoutWallet.ID).Return(payoutWallet, nil).Once()
+		m.chargeRepo.On("ListUnsettledChargesForUpdate", ctx, m.txController, merchantWallet.ID, mock.MatchedBy(cutoff.Equal)).
+			Return([]domain.Charge{{ID: 7, Amount: decimal.NewFromInt(40)}}, nil).Once()
+		m.merchantRepo.On("CreateSettlement", ctx, m.txController, mock.MatchedBy(func(s *domain.Settlement) bool {
+			return s.SettlementDate.Equal(time.Date(2025, time.May, 4, 0, 0, 0, 0, time.UTC))
+		})).Return(util.ErrDuplicateEntry).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		settled, err := service.SettleMerchants(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, settled)
+		mock.AssertExpectationsForObjects(t, m.merchantRepo, m.chargeRepo, m.walletRepo)
+	})
+}
+
+// TestPayChargeToShardedWallet tests that paying a charge to a sharded merchant wallet updates a shard, not the
+// wallet's row.
+func TestPayChargeToShardedWallet(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	merchantWallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Kind: domain.WalletKindMerchant}
+	payerWallet := &domain.Wallet{ID: 2, PublicID: uuid.New(), UserID: 20, Currency: "USD", Balance: decimal.NewFromInt(100)}
+	charge := &domain.Charge{
+		ID:               7,
+		PublicID:         uuid.New(),
+		MerchantWalletID: merchantWallet.ID,
+		Amount:           decimal.NewFromInt(40),
+		Currency:         "USD",
+		Status:           domain.ChargeStatusPending,
+		ExpiresAt:        time.Now().UTC().Add(10 * time.Minute),
+	}
+	chargeRepo, walletRepo, transactionRepo, txController := new(MockChargeRepository), new(MockWalletRepository), new(MockTransactionRepository), new(MockTxController)
+	sharder := &shardEverything{}
+	service := NewMerchantService(
+		new(MockDBBeginner), new(MockDBExecutor), new(MockMerchantRepository), chargeRepo, walletRepo,
+		NewBalanceWriter(walletRepo, sharder, nil, nil), transactionRepo, 30*time.Minute, nil,
+		func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return txController, nil },
+		func(tx db.TxController) error { return txController.Commit() },
+		func(tx db.TxController) { _ = txController.Rollback() },
+		logger,
+	)
+	chargeRepo.On("GetChargeByPublicIDForUpdate", ctx, txController, charge.PublicID).Return(charge, nil).Once()
+	walletRepo.On("GetWalletByIDForUpdate", ctx, txController, merchantWallet.ID).Return(merchantWallet, nil).Once()
+	walletRepo.On("GetWalletByIDForUpdate", ctx, txController, payerWallet.ID).Return(payerWallet, nil).Once()
+	transactionRepo.On("CreateTransaction", ctx, txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+	chargeRepo.On("UpdateCharge", ctx, txController, charge).Return(nil).Once()
+	txController.On("Commit").Return(nil).Once()
+	txController.On("Rollback").Return(nil).Maybe()
+
+	_, _, err := service.PayCharge(ctx, charge.PublicID, payerWallet.ID)
+
+	assert.NoError(t, err)
+	assert.Contains(t, sharder.updated, merchantWallet.ID)
+	walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,205 @@
+// internal/service/migration_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/migration"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/pkg/db"
+)
+
+// MigrationService reports the migration state of the database and runs the out-of-band backfills of expand
+// migrations.
+type MigrationService interface {
+	// Status compares the schema of the database with the migrations of the code, with the progress of every
+	// backfill.
+	Status(ctx context.Context) (*domain.MigrationStatus, error)
+	// RunBackfills runs up to batches batches of the unfinished backfills whose migration is applied, in
+	// registration order, and returns how many it ran. A failed batch stops its backfill until the next run;
+	// its error is recorded with the progress and returned.
+	RunBackfills(ctx context.Context, batches int) (int, error)
+}
+
+type migrationService struct {
+	dbBeginner db.DBTxBeginner
+	dbExecutor repository.DBExecutor
+	schemaRepo repository.SchemaRepository
+	migrations []migration.Migration
+	backfills  []migration.Backfill
+	beginTx    db.BeginTxFunc
+	commitTx   db.CommitTxFunc
+	rollbackTx db.RollbackTxFunc
+	logger     *slog.Logger
+}
+
+// NewMigrationService creates a new instance of MigrationService for the given migrations and backfills.
+func NewMigrationService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	schemaRepo repository.SchemaRepository,
+	migrations []migration.Migration,
+	backfills []migration.Backfill,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) MigrationService {
+	return &migrationService{
+		dbBeginner: dbBeginner,
+		dbExecutor: dbExecutor,
+		schemaRepo: schemaRepo,
+		migrations: migrations,
+		backfills:  backfills,
+		beginTx:    beginTx,
+		commitTx:   commitTx,
+		rollbackTx: rollbackTx,
+		logger:     logger,
+	}
+}
+
+// Status compares the schema of the database with the migrations of the code.
+func (s *migrationService) Status(ctx context.Context) (*domain.MigrationStatus, error) {
+	version, dirty, err := s.schemaRepo.GetSchemaVersion(ctx, s.dbExecutor)
+	if err != nil {
+		return nil, fmt.Errorf("migration status: %w", err)
+	}
+	status := &domain.MigrationStatus{Version: version, Dirty: dirty, Pending: []domain.MigrationInfo{}}
+	for _, m := range s.migrations {
+		status.Latest = max(status.Latest, m.Version)
+		if m.Version > version {
+			status.Pending = append(status.Pending, domain.MigrationInfo{Version: m.Version, Name: m.Name, Phase: string(m.Phase)})
+		}
+	}
+
+	progress, err := s.backfillProgress(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migration status: %w", err)
+	}
+	status.Backfills = make([]domain.BackfillProgress, 0, len(progress))
+	for _, p := range progress {
+		status.Backfills = append(status.Backfills, *p)
+	}
+	sort.Slice(status.Backfills, func(i, j int) bool { return status.Backfills[i].Name < status.Backfills[j].Name })
+	return status, nil
+}
+
+// backfillProgress returns the progress of the recorded backfills and of the registered ones not started yet.
+func (s *migrationService) backfillProgress(ctx context.Context) (map[string]*domain.BackfillProgress, error) {
+	recorded, err := s.schemaRepo.ListBackfillProgress(ctx, s.dbExecutor)
+	if err != nil {
+		return nil, err
+	}
+	progress := make(map[string]*domain.BackfillProgress, len(recorded)+len(s.backfills))
+	for i := range recorded {
+		progress[recorded[i].Name] = &recorded[i]
+	}
+	for _, backfill := range s.backfills {
+		if progress[backfill.Name] == nil {
+			progress[backfill.Name] = &domain.BackfillProgress{Name: backfill.Name}
+		}
+	}
+	return progress, nil
+}
+
+// RunBackfills runs up to batches batches of the unfinished backfills. Nothing runs while the last migration
+// is dirty, as the schema may be half changed.
+func (s *migrationService) RunBackfills(ctx context.Context, batches int) (int, error) {
+	if len(s.backfills) == 0 {
+		return 0, nil
+	}
+	version, dirty, err := s.schemaRepo.GetSchemaVersion(ctx, s.dbExecutor)
+	if err != nil {
+		return 0, fmt.Errorf("run backfills: %w", err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("run backfills: migration %d is dirty", version)
+	}
+	progress, err := s.backfillProgress(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("run backfills: %w", err)
+	}
+
+	run := 0
+	var errs []error
+	for _, backfill := range s.backfills {
+		p := progress[backfill.Name]
+		if p.CompletedAt != nil || version < backfill.After {
+			continue
+		}
+		for run < batches && p.CompletedAt == nil && ctx.Err() == nil {
+			run++
+			if err := s.runBatch(ctx, backfill, p); err != nil {
+				errs = append(errs, fmt.Errorf("backfill %s: %w", backfill.Name, err))
+				break
+			}
+		}
+	}
+	return run, errors.Join(errs...)
+}
+
+// runBatch runs the next batch of a backfill and saves its progress in the same transaction, so a batch is
+// never run twice. A failed batch leaves the progress as it was but for the error.
+func (s *migrationService) runBatch(ctx context.Context, backfill migration.Backfill, p *domain.BackfillProgress) error {
+	next := *p
+	now := time.Now().UTC()
+	next.UpdatedAt = &now
+	if next.StartedAt == nil {
+		next.StartedAt = &now
+	}
+
+	err := func() error {
+		txController, err := s.beginTx(ctx, s.dbBeginner)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer s.rollbackTx(txController)
+
+		txExecutor, ok := txController.(repository.DBExecutor)
+		if !ok {
+			return fmt.Errorf("transaction controller does not implement DBExecutor")
+		}
+		keys, err := s.schemaRepo.RunBackfillBatch(ctx, txExecutor, backfill.Query, p.LastKey, backfill.BatchSize)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			next.CompletedAt = &now
+		}
+		for _, key := range keys {
+			next.LastKey = max(next.LastKey, key)
+		}
+		next.RowsDone += int64(len(keys))
+		next.Batches++
+		next.LastError = nil
+		if err := s.schemaRepo.SaveBackfillProgress(ctx, txExecutor, &next); err != nil {
+			return err
+		}
+		if err := s.commitTx(txController); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	}()
+	if err != nil {
+		failed := *p
+		message := err.Error()
+		failed.LastError, failed.UpdatedAt, failed.StartedAt = &message, &now, next.StartedAt
+		if saveErr := s.schemaRepo.SaveBackfillProgress(ctx, s.dbExecutor, &failed); saveErr != nil {
+			return errors.Join(err, saveErr)
+		}
+		*p = failed
+		return err
+	}
+
+	*p = next
+	if next.CompletedAt != nil {
+		s.logger.Info("Backfill completed", "backfill", backfill.Name, "rows", next.RowsDone, "batches", next.Batches)
+	}
+	return nil
+}
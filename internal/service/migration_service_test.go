@@ -0,0 +1,145 @@
+// internal/service/migration_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/migration"
+	"finflow-wallet/pkg/db"
+)
+
+// TestMigrationService tests the migration status and the batches of the backfills.
+func TestMigrationService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	migrations := []migration.Migration{
+		{Version: 44, Name: "create_schema_backfills", Phase: migration.PhaseExpand},
+		{Version: 45, Name: "add_wallet_nickname", Phase: migration.PhaseExpand},
+		{Version: 46, Name: "require_wallet_nickname", Phase: migration.PhaseContract},
+	}
+	nicknames := migration.Backfill{Name: "wallet_nicknames", After: 45, Query: "UPDATE wallets ...", BatchSize: 2}
+
+	type mocks struct {
+		schemaRepo   *MockSchemaRepository
+		dbExecutor   *MockDBExecutor
+		txController *MockTxController
+	}
+	newService := func() (MigrationService, mocks) {
+		m := mocks{
+			schemaRepo:   new(MockSchemaRepository),
+			dbExecutor:   new(MockDBExecutor),
+			txController: new(MockTxController),
+		}
+		m.txController.On("Rollback").Return(nil).Maybe()
+		service := NewMigrationService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.schemaRepo,
+			migrations,
+			[]migration.Backfill{nicknames},
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+
+	t.Run("StatusListsPendingMigrationsAndBackfills", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.schemaRepo.On("GetSchemaVersion", ctx, m.dbExecutor).Return(uint64(45), false, nil).Once()
+		m.schemaRepo.On("ListBackfillProgress", ctx, m.dbExecutor).Return([]domain.BackfillProgress{}, nil).Once()
+
+		status, err := service.Status(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint64(45), status.Version)
+		assert.Equal(t, uint64(46), status.Latest)
+		assert.Equal(t, []domain.MigrationInfo{{Version: 46, Name: "require_wallet_nickname", Phase: "contract"}}, status.Pending)
+		assert.Equal(t, []domain.BackfillProgress{{Name: "wallet_nicknames"}}, status.Backfills, "not started yet")
+	})
+
+	t.Run("BackfillRunsBatchesUntilEmpty", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.schemaRepo.On("GetSchemaVersion", ctx, m.dbExecutor).Return(uint64(45), false, nil).Once()
+		m.schemaRepo.On("ListBackfillProgress", ctx, m.dbExecutor).Return([]domain.BackfillProgress{{Name: "wallet_nicknames", LastKey: 10, RowsDone: 4, Batches: 2}}, nil).Once()
+		m.schemaRepo.On("RunBackfillBatch", ctx, m.txController, nicknames.Query, int64(10), 2).Return([]int64{11, 14}, nil).Once()
+		m.schemaRepo.On("RunBackfillBatch", ctx, m.txController, nicknames.Query, int64(14), 2).Return([]int64{}, nil).Once()
+		var saved []domain.BackfillProgress
+		m.schemaRepo.On("SaveBackfillProgress", ctx, m.txController, mock.Anything).Run(func(args mock.Arguments) {
+			saved = append(saved, *args.Get(2).(*domain.BackfillProgress))
+		}).Return(nil).Twice()
+		m.txController.On("Commit").Return(nil).Twice()
+
+		run, err := service.RunBackfills(ctx, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, run)
+		require.Len(t, saved, 2)
+		assert.Equal(t, int64(14), saved[0].LastKey)
+		assert.Equal(t, int64(6), saved[0].RowsDone)
+		assert.Nil(t, saved[0].CompletedAt)
+		assert.NotNil(t, saved[1].CompletedAt)
+		assert.Equal(t, int64(4), saved[1].Batches)
+	})
+
+	t.Run("BackfillWaitsForItsMigration", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.schemaRepo.On("GetSchemaVersion", ctx, m.dbExecutor).Return(uint64(44), false, nil).Once()
+		m.schemaRepo.On("ListBackfillProgress", ctx, m.dbExecutor).Return([]domain.BackfillProgress{}, nil).Once()
+
+		run, err := service.RunBackfills(ctx, 10)
+
+		require.NoError(t, err)
+		assert.Zero(t, run)
+		m.schemaRepo.AssertNotCalled(t, "RunBackfillBatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("FailedBatchRecordsItsError", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		started := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		m.schemaRepo.On("GetSchemaVersion", ctx, m.dbExecutor).Return(uint64(46), false, nil).Once()
+		m.schemaRepo.On("ListBackfillProgress", ctx, m.dbExecutor).Return([]domain.BackfillProgress{{Name: "wallet_nicknames", LastKey: 10, StartedAt: &started}}, nil).Once()
+		m.schemaRepo.On("RunBackfillBatch", ctx, m.txController, nicknames.Query, int64(10), 2).Return(nil, errors.New("deadlock detected")).Once()
+		m.schemaRepo.On("SaveBackfillProgress", ctx, m.dbExecutor, mock.MatchedBy(func(p *domain.BackfillProgress) bool {
+			return p.LastKey == 10 && p.LastError != nil && *p.LastError == "deadlock detected" && p.StartedAt.Equal(started)
+		})).Return(nil).Once()
+
+		run, err := service.RunBackfills(ctx, 10)
+
+		assert.ErrorContains(t, err, "backfill wallet_nicknames: deadlock detected")
+		assert.Equal(t, 1, run)
+		m.schemaRepo.AssertExpectations(t)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("DirtySchemaRunsNothing", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.schemaRepo.On("GetSchemaVersion", ctx, m.dbExecutor).Return(uint64(45), true, nil).Once()
+
+		_, err := service.RunBackfills(ctx, 10)
+
+		assert.ErrorContains(t, err, "migration 45 is dirty")
+		m.schemaRepo.AssertNotCalled(t, "ListBackfillProgress", mock.Anything, mock.Anything)
+	})
+}
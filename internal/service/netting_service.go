@@ -0,0 +1,246 @@
+// internal/service/netting_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// NettingService defines the interface for netting: transfer instructions between two wallets are accumulated
+// during a window and settled as a single net transfer when it closes, for counterparties with heavy
+// bilateral flows. Every instruction stays traceable to the settlement and transaction that settled it.
+type NettingService interface {
+	// SubmitInstruction accepts a PENDING instruction to move amount from one wallet to the other. No money
+	// moves until the window closes, and the balance is only checked for the net amount.
+	SubmitInstruction(ctx context.Context, from, to *domain.Wallet, amount decimal.Decimal, currency string, reference *string) (*domain.NettingInstruction, error)
+	GetInstruction(ctx context.Context, publicID uuid.UUID) (*domain.NettingInstruction, error)
+	// GetSettlement retrieves a net settlement together with the instructions it settled.
+	GetSettlement(ctx context.Context, publicID uuid.UUID) (*domain.NettingSettlement, []domain.NettingInstruction, error)
+	// SettleWindow closes the window at windowEnd: the pending instructions created before it are settled per
+	// wallet pair and currency. It returns the number of settlements made.
+	SettleWindow(ctx context.Context, windowEnd time.Time) (int, error)
+}
+
+// nettingService implements NettingService.
+type nettingService struct {
+	dbBeginner      db.DBTxBeginner
+	dbExecutor      repository.DBExecutor
+	nettingRepo     repository.NettingRepository
+	walletRepo      repository.WalletRepository
+	balances        *BalanceWriter // Applies balance changes, on a shard for sharded wallets
+	transactionRepo repository.TransactionRepository
+	events          *TransactionEvents // Optional; net transfers are published here
+	beginTx         db.BeginTxFunc
+	commitTx        db.CommitTxFunc
+	rollbackTx      db.RollbackTxFunc
+	logger          *slog.Logger
+}
+
+// NewNettingService creates a new instance of NettingService.
+func NewNettingService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	nettingRepo repository.NettingRepository,
+	walletRepo repository.WalletRepository,
+	balances *BalanceWriter,
+	transactionRepo repository.TransactionRepository,
+	events *TransactionEvents,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) NettingService {
+	return &nettingService{
+		dbBeginner:      dbBeginner,
+		dbExecutor:      dbExecutor,
+		nettingRepo:     nettingRepo,
+		walletRepo:      walletRepo,
+		balances:        balances,
+		transactionRepo: transactionRepo,
+		events:          events,
+		beginTx:         beginTx,
+		commitTx:        commitTx,
+		rollbackTx:      rollbackTx,
+		logger:          logger,
+	}
+}
+
+// SubmitInstruction validates and stores a pending instruction. Both wallets must hold the instruction's currency.
+func (s *nettingService) SubmitInstruction(ctx context.Context, from, to *domain.Wallet, amount decimal.Decimal, currency string, reference *string) (*domain.NettingInstruction, error) {
+	if !amount.IsPositive() || !amount.Equal(amount.Truncate(domain.CurrencyPrecision(currency))) {
+		return nil, fmt.Errorf("%w: amount must be positive and fit the currency's minor unit", util.ErrInvalidInput)
+	}
+	if reference != nil && len(*reference) > 100 {
+		return nil, fmt.Errorf("%w: reference may have at most 100 characters", util.ErrInvalidInput)
+	}
+	if from.ID == to.ID {
+		return nil, util.ErrSameWalletTransfer
+	}
+	if from.Currency != currency || to.Currency != currency {
+		return nil, util.ErrCurrencyMismatch
+	}
+	if err := checkNotFrozen(from); err != nil {
+		return nil, fmt.Errorf("submit netting instruction: %w", err)
+	}
+
+	instruction := &domain.NettingInstruction{
+		PublicID:           uuid.New(),
+		FromWalletID:       from.ID,
+		ToWalletID:         to.ID,
+		FromWalletPublicID: from.PublicID,
+		ToWalletPublicID:   to.PublicID,
+		Amount:             amount,
+		Currency:           currency,
+		Reference:          reference,
+		Status:             domain.NettingInstructionStatusPending,
+		CreatedAt:          time.Now().UTC(),
+	}
+	if err := s.nettingRepo.CreateInstruction(ctx, s.dbExecutor, instruction); err != nil {
+		return nil, fmt.Errorf("submit netting instruction: %w", err)
+	}
+	s.logger.Info("Netting instruction accepted", "instruction_id", instruction.PublicID, "from_wallet_id", from.PublicID,
+		"to_wallet_id", to.PublicID, "amount", amount.String())
+	return instruction, nil
+}
+
+// GetInstruction retrieves a netting instruction by its public ID.
+func (s *nettingService) GetInstruction(ctx context.Context, publicID uuid.UUID) (*domain.NettingInstruction, error) {
+	instruction, err := s.nettingRepo.GetInstructionByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get netting instruction %s: %w", publicID, err)
+	}
+	return instruction, nil
+}
+
+// GetSettlement retrieves a net settlement by its public ID, with its instructions.
+func (s *nettingService) GetSettlement(ctx context.Context, publicID uuid.UUID) (*domain.NettingSettlement, []domain.NettingInstruction, error) {
+	settlement, err := s.nettingRepo.GetSettlementByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get netting settlement %s: %w", publicID, err)
+	}
+	instructions, err := s.nettingRepo.ListSettlementInstructions(ctx, s.dbExecutor, settlement.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get netting settlement %s: %w", publicID, err)
+	}
+	return settlement, instructions, nil
+}
+
+// SettleWindow settles each pair in its own database transaction; a pair that fails to settle, e.g. because
+// the net payer lacks the funds, is logged and its instructions roll over into the next window.
+func (s *nettingService) SettleWindow(ctx context.Context, windowEnd time.Time) (int, error) {
+	pairs, err := s.nettingRepo.ListPendingPairs(ctx, s.dbExecutor, windowEnd)
+	if err != nil {
+		return 0, fmt.Errorf("settle netting window: %w", err)
+	}
+
+	settled := 0
+	for _, pair := range pairs {
+		settlement, err := s.settle(ctx, pair, windowEnd)
+		if err != nil {
+			s.logger.Error("Netting settlement failed", "wallet_a", pair.WalletA, "wallet_b", pair.WalletB,
+				"currency", pair.Currency, "error", err)
+			continue
+		}
+		if settlement != nil {
+			settled++
+		}
+	}
+	return settled, nil
+}
+
+// settle nets one pair's pending instructions and moves the net amount as a single NETTING transaction. When
+// the flows cancel out, the instructions are settled without a transaction. It returns nil when a concurrent
+// run already settled the instructions.
+func (s *nettingService) settle(ctx context.Context, pair domain.NettingPair, windowEnd time.Time) (*domain.NettingSettlement, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("transaction controller does not implement DBExecutor")
+	}
+
+	wallets, err := lockWallets(ctx, s.walletRepo, txExecutor, pair.WalletA, pair.WalletB)
+	if err != nil {
+		return nil, err
+	}
+	instructions, err := s.nettingRepo.ListPendingInstructionsForUpdate(ctx, txExecutor, pair, windowEnd)
+	if err != nil {
+		return nil, err
+	}
+	if len(instructions) == 0 {
+		return nil, nil
+	}
+	from, to, net, gross := domain.NetFlows(pair.WalletA, pair.WalletB, instructions)
+	payer := wallets[from]
+	if payer.Currency != pair.Currency || wallets[to].Currency != pair.Currency {
+		return nil, util.ErrCurrencyMismatch
+	}
+	if payer.Balance.LessThan(net) {
+		return nil, util.ErrInsufficientFunds
+	}
+
+	now := time.Now().UTC()
+	settlement := &domain.NettingSettlement{
+		PublicID:           uuid.New(),
+		FromWalletID:       from,
+		ToWalletID:         to,
+		FromWalletPublicID: payer.PublicID,
+		ToWalletPublicID:   wallets[to].PublicID,
+		NetAmount:          net,
+		GrossAmount:        gross,
+		Currency:           pair.Currency,
+		InstructionCount:   len(instructions),
+		WindowEnd:          windowEnd,
+		CreatedAt:          now,
+	}
+	var transaction *domain.Transaction
+	if net.IsPositive() {
+		description := fmt.Sprintf("Net settlement of %d instructions", len(instructions))
+		transaction = domain.NewTransaction(&from, &to, net, pair.Currency, domain.TransactionTypeNetting, &description)
+		s.balances.Stamp(transaction)
+		settlement.TransactionID = &transaction.PublicID
+		if _, err := s.balances.UpdateBalance(ctx, txExecutor, from, net.Neg()); err != nil {
+			return nil, fmt.Errorf("failed to update payer wallet balance: %w", err)
+		}
+		if _, err := s.balances.UpdateBalance(ctx, txExecutor, to, net); err != nil {
+			return nil, fmt.Errorf("failed to update payee wallet balance: %w", err)
+		}
+		if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+			return nil, fmt.Errorf("failed to create transaction: %w", err)
+		}
+	}
+	if err := s.nettingRepo.CreateSettlement(ctx, txExecutor, settlement); err != nil {
+		return nil, err
+	}
+	instructionIDs := make([]int64, len(instructions))
+	for i := range instructions {
+		instructionIDs[i] = instructions[i].ID
+	}
+	if err := s.nettingRepo.MarkInstructionsSettled(ctx, txExecutor, settlement.ID, instructionIDs, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if transaction != nil && s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
+	s.logger.Info("Netting window settled", "settlement_id", settlement.PublicID, "from_wallet_id", from, "to_wallet_id", to,
+		"net_amount", net.String(), "gross_amount", gross.String(), "instructions", len(instructions))
+	return settlement, nil
+}
@@ -0,0 +1,155 @@
+// internal/service/netting_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestNettingService tests accepting netting instructions and settling a window as net transfers.
+func TestNettingService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	windowEnd := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pair := domain.NettingPair{WalletA: 1, WalletB: 2, Currency: "USD"}
+	walletA := &domain.Wallet{ID: 1, PublicID: uuid.New(), Currency: "USD", Balance: decimal.NewFromInt(100)}
+	walletB := &domain.Wallet{ID: 2, PublicID: uuid.New(), Currency: "USD", Balance: decimal.NewFromInt(100)}
+	instruction := func(id, from, to int64, amount int64) domain.NettingInstruction {
+		return domain.NettingInstruction{ID: id, FromWalletID: from, ToWalletID: to, Amount: decimal.NewFromInt(amount), Currency: "USD", Status: domain.NettingInstructionStatusPending}
+	}
+
+	type mocks struct {
+		nettingRepo     *MockNettingRepository
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		dbExecutor      *MockDBExecutor
+		txController    *MockTxController
+	}
+	newService := func() (NettingService, mocks) {
+		m := mocks{
+			nettingRepo:     new(MockNettingRepository),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			dbExecutor:      new(MockDBExecutor),
+			txController:    new(MockTxController),
+		}
+		service := NewNettingService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.nettingRepo,
+			m.walletRepo,
+			NewBalanceWriter(m.walletRepo, nil, nil, nil),
+			m.transactionRepo,
+			nil,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+	expectWindow := func(ctx context.Context, m mocks, instructions []domain.NettingInstruction) {
+		m.nettingRepo.On("ListPendingPairs", ctx, m.dbExecutor, windowEnd).Return([]domain.NettingPair{pair}, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, int64(1)).Return(walletA, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, int64(2)).Return(walletB, nil).Once()
+		m.nettingRepo.On("ListPendingInstructionsForUpdate", ctx, m.txController, pair, windowEnd).Return(instructions, nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+	}
+
+	t.Run("SettlesNetAmountAsOneTransaction", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		// B owes A 30 + 50, A owes B 20: B pays A the net 60
+		expectWindow(ctx, m, []domain.NettingInstruction{instruction(11, 2, 1, 30), instruction(12, 1, 2, 20), instruction(13, 2, 1, 50)})
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, int64(2), decimal.NewFromInt(-60), mock.Anything).Return(nil, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, int64(1), decimal.NewFromInt(60), mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeNetting && *tx.FromWalletID == 2 && *tx.ToWalletID == 1 && tx.Amount.Equal(decimal.NewFromInt(60))
+		})).Return(nil).Once()
+		m.nettingRepo.On("CreateSettlement", ctx, m.txController, mock.MatchedBy(func(s *domain.NettingSettlement) bool {
+			return s.FromWalletID == 2 && s.NetAmount.Equal(decimal.NewFromInt(60)) && s.GrossAmount.Equal(decimal.NewFromInt(100)) &&
+				s.InstructionCount == 3 && s.TransactionID != nil
+		})).Return(nil).Once()
+		m.nettingRepo.On("MarkInstructionsSettled", ctx, m.txController, mock.Anything, []int64{11, 12, 13}, mock.Anything).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		settled, err := service.SettleWindow(ctx, windowEnd)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, settled)
+		m.walletRepo.AssertExpectations(t)
+		m.transactionRepo.AssertExpectations(t)
+		m.nettingRepo.AssertExpectations(t)
+	})
+
+	t.Run("OffsettingFlowsSettleWithoutTransaction", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		expectWindow(ctx, m, []domain.NettingInstruction{instruction(11, 1, 2, 40), instruction(12, 2, 1, 40)})
+		m.nettingRepo.On("CreateSettlement", ctx, m.txController, mock.MatchedBy(func(s *domain.NettingSettlement) bool {
+			return s.NetAmount.IsZero() && s.TransactionID == nil
+		})).Return(nil).Once()
+		m.nettingRepo.On("MarkInstructionsSettled", ctx, m.txController, mock.Anything, []int64{11, 12}, mock.Anything).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		settled, err := service.SettleWindow(ctx, windowEnd)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, settled)
+		m.transactionRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("InsufficientFundsRollsOver", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		expectWindow(ctx, m, []domain.NettingInstruction{instruction(11, 2, 1, 250)}) // B holds only 100
+
+		settled, err := service.SettleWindow(ctx, windowEnd)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, settled)
+		m.nettingRepo.AssertNotCalled(t, "MarkInstructionsSettled", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("SubmitRejectsCurrencyMismatch", func(t *testing.T) {
+		service, m := newService()
+		eur := &domain.Wallet{ID: 3, PublicID: uuid.New(), Currency: "EUR"}
+
+		_, err := service.SubmitInstruction(context.Background(), walletA, eur, decimal.NewFromInt(5), "USD", nil)
+
+		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
+		m.nettingRepo.AssertNotCalled(t, "CreateInstruction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SubmitStoresPendingInstruction", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.nettingRepo.On("CreateInstruction", ctx, m.dbExecutor, mock.AnythingOfType("*domain.NettingInstruction")).Return(nil).Once()
+
+		created, err := service.SubmitInstruction(ctx, walletA, walletB, decimal.RequireFromString("12.50"), "USD", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.NettingInstructionStatusPending, created.Status)
+		assert.Equal(t, walletB.PublicID, created.ToWalletPublicID)
+		m.nettingRepo.AssertExpectations(t)
+	})
+}
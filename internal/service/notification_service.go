@@ -0,0 +1,445 @@
+// internal/service/notification_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/i18n"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+const (
+	maxPushAttempts       = 3   // Sends of a delivery before a transient failure counts as final
+	maxDeviceLabelLength  = 100 // Matches device_tokens.label
+	maxListedDeliveries   = 100
+	deviceRemovedDelivery = "device removed before delivery"
+)
+
+// Notifier delivers notifications to users.
+type Notifier interface {
+	// Notify puts the notification in the user's inbox and queues it on every channel the user enabled for
+	// its category.
+	Notify(ctx context.Context, notification *domain.Notification) error
+}
+
+// notificationText renders the title and body of a notification from the "<key>.title" and "<key>.body"
+// messages. Users have no stored locale, so notifications are written in the default one.
+func notificationText(key string, params map[string]string) (string, string) {
+	title, _ := i18n.Lookup(i18n.DefaultLocale, key+".title")
+	body, _ := i18n.Lookup(i18n.DefaultLocale, key+".body")
+	return i18n.Format(title, params), i18n.Format(body, params)
+}
+
+// NotificationService defines the interface for users' notification inboxes, their push devices and
+// notification preferences, and the delivery of notifications to them. As a TransactionListener and SecurityNotifier it turns money movements
+// and new-device logins into notifications.
+type NotificationService interface {
+	Notifier
+	TransactionListener
+	SecurityNotifier
+	// ListNotifications retrieves a page of the user's inbox, newest first, optionally only the unread
+	// notifications, and the total count matching.
+	ListNotifications(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]domain.Notification, int64, error)
+	// CountUnread counts the user's unread notifications.
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	// MarkRead marks a notification of the user read and returns it. Marking it again keeps the first read time.
+	MarkRead(ctx context.Context, userID, notificationID int64) (*domain.Notification, error)
+	// MarkAllRead marks every unread notification of the user read and returns how many there were.
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	// RegisterDevice registers a device's push token for the user, taking it over if another user had it.
+	RegisterDevice(ctx context.Context, device *domain.DeviceToken) error
+	ListDevices(ctx context.Context, userID int64) ([]domain.DeviceToken, error)
+	DeleteDevice(ctx context.Context, userID, deviceID int64) error
+	// ListPreferences returns the user's preference for every category and channel, the default ones included.
+	ListPreferences(ctx context.Context, userID int64) ([]domain.NotificationPreference, error)
+	// SetPreferences stores the given preferences of the user, leaving the others as they are, and returns all.
+	SetPreferences(ctx context.Context, userID int64, preferences []domain.NotificationPreference) ([]domain.NotificationPreference, error)
+	// ListDeliveries retrieves the user's most recent deliveries and their status, newest first.
+	ListDeliveries(ctx context.Context, userID int64) ([]domain.NotificationDelivery, error)
+	// Deliver sends a batch of pending deliveries and returns how many were sent.
+	Deliver(ctx context.Context) (int, error)
+}
+
+// notificationService implements NotificationService.
+type notificationService struct {
+	dbBeginner       db.DBTxBeginner
+	dbExecutor       repository.DBExecutor
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	walletRepo       repository.WalletRepository
+	descriptions     DescriptionRenderer
+	senders          map[domain.PushPlatform]PushSender // Devices of other platforms are not notified
+	batchSize        int
+	beginTx          db.BeginTxFunc
+	commitTx         db.CommitTxFunc
+	rollbackTx       db.RollbackTxFunc
+	logger           *slog.Logger
+}
+
+// NewNotificationService creates a new instance of NotificationService.
+func NewNotificationService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	notificationRepo repository.NotificationRepository,
+	userRepo repository.UserRepository,
+	walletRepo repository.WalletRepository,
+	descriptions DescriptionRenderer,
+	senders map[domain.PushPlatform]PushSender,
+	batchSize int,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) NotificationService {
+	return &notificationService{
+		dbBeginner:       dbBeginner,
+		dbExecutor:       dbExecutor,
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		walletRepo:       walletRepo,
+		descriptions:     descriptions,
+		senders:          senders,
+		batchSize:        batchSize,
+		beginTx:          beginTx,
+		commitTx:         commitTx,
+		rollbackTx:       rollbackTx,
+		logger:           logger,
+	}
+}
+
+// ListNotifications retrieves a page of the user's inbox.
+func (s *notificationService) ListNotifications(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]domain.Notification, int64, error) {
+	notifications, total, err := s.notificationRepo.ListNotifications(ctx, s.dbExecutor, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list notifications: %w", err)
+	}
+	return notifications, total, nil
+}
+
+// CountUnread counts the user's unread notifications.
+func (s *notificationService) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	unread, err := s.notificationRepo.CountUnreadNotifications(ctx, s.dbExecutor, userID)
+	if err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	return unread, nil
+}
+
+// MarkRead marks a notification of the user read.
+func (s *notificationService) MarkRead(ctx context.Context, userID, notificationID int64) (*domain.Notification, error) {
+	notification, err := s.notificationRepo.MarkNotificationRead(ctx, s.dbExecutor, userID, notificationID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("mark notification %d read: %w", notificationID, err)
+	}
+	return notification, nil
+}
+
+// MarkAllRead marks the user's unread notifications read.
+func (s *notificationService) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	marked, err := s.notificationRepo.MarkAllNotificationsRead(ctx, s.dbExecutor, userID, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("mark notifications read: %w", err)
+	}
+	return marked, nil
+}
+
+// RegisterDevice validates the platform, token and label. Re-registering one of the user's devices refreshes
+// it; a new one is refused once the user has MaxDevicesPerUser.
+func (s *notificationService) RegisterDevice(ctx context.Context, device *domain.DeviceToken) error {
+	if !device.Platform.Valid() {
+		return fmt.Errorf("%w: platform must be %s or %s", util.ErrInvalidInput, domain.PushPlatformFCM, domain.PushPlatformAPNs)
+	}
+	if device.Token == "" || len(device.Token) > domain.MaxDeviceTokenBytes {
+		return fmt.Errorf("%w: token must have 1 to %d bytes", util.ErrInvalidInput, domain.MaxDeviceTokenBytes)
+	}
+	if device.Label != nil && len([]rune(*device.Label)) > maxDeviceLabelLength {
+		return fmt.Errorf("%w: label must have at most %d characters", util.ErrInvalidInput, maxDeviceLabelLength)
+	}
+	if _, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, device.UserID); err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return util.ErrUserNotFound
+		}
+		return fmt.Errorf("register device: failed to get user %d: %w", device.UserID, err)
+	}
+
+	devices, err := s.notificationRepo.ListDevices(ctx, s.dbExecutor, device.UserID)
+	if err != nil {
+		return fmt.Errorf("register device: %w", err)
+	}
+	known := false
+	for _, d := range devices {
+		known = known || (d.Platform == device.Platform && d.Token == device.Token)
+	}
+	if !known && len(devices) >= domain.MaxDevicesPerUser {
+		return fmt.Errorf("%w: at most %d devices can be registered", util.ErrInvalidInput, domain.MaxDevicesPerUser)
+	}
+
+	if err := s.notificationRepo.RegisterDevice(ctx, s.dbExecutor, device); err != nil {
+		return fmt.Errorf("register device: %w", err)
+	}
+	s.logger.Info("Push device registered", "user_id", device.UserID, "device_id", device.ID, "platform", device.Platform)
+	return nil
+}
+
+// ListDevices retrieves the user's registered devices.
+func (s *notificationService) ListDevices(ctx context.Context, userID int64) ([]domain.DeviceToken, error) {
+	devices, err := s.notificationRepo.ListDevices(ctx, s.dbExecutor, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	return devices, nil
+}
+
+// DeleteDevice unregisters one of the user's devices, e.g. on logout.
+func (s *notificationService) DeleteDevice(ctx context.Context, userID, deviceID int64) error {
+	if err := s.notificationRepo.DeleteDevice(ctx, s.dbExecutor, userID, deviceID); err != nil {
+		return fmt.Errorf("delete device %d: %w", deviceID, err)
+	}
+	s.logger.Info("Push device removed", "user_id", userID, "device_id", deviceID)
+	return nil
+}
+
+// ListPreferences fills in an enabled default, without an update time, for every combination the user has
+// not set.
+func (s *notificationService) ListPreferences(ctx context.Context, userID int64) ([]domain.NotificationPreference, error) {
+	stored, err := s.notificationRepo.ListPreferences(ctx, s.dbExecutor, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list notification preferences: %w", err)
+	}
+	preferences := make([]domain.NotificationPreference, 0, len(domain.NotificationCategories))
+	for _, category := range domain.NotificationCategories {
+		preference := domain.NotificationPreference{UserID: userID, Category: category, Channel: domain.NotificationChannelPush, Enabled: true}
+		for _, p := range stored {
+			if p.Category == category && p.Channel == domain.NotificationChannelPush {
+				preference = p
+			}
+		}
+		preferences = append(preferences, preference)
+	}
+	return preferences, nil
+}
+
+// SetPreferences validates every preference before storing any.
+func (s *notificationService) SetPreferences(ctx context.Context, userID int64, preferences []domain.NotificationPreference) ([]domain.NotificationPreference, error) {
+	for _, p := range preferences {
+		if !p.Category.Valid() {
+			return nil, fmt.Errorf("%w: unknown notification category %q", util.ErrInvalidInput, p.Category)
+		}
+		if !p.Channel.Valid() {
+			return nil, fmt.Errorf("%w: unknown notification channel %q", util.ErrInvalidInput, p.Channel)
+		}
+	}
+	if _, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, userID); err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("set notification preferences: failed to get user %d: %w", userID, err)
+	}
+	for i := range preferences {
+		preferences[i].UserID = userID
+		if err := s.notificationRepo.SavePreference(ctx, s.dbExecutor, &preferences[i]); err != nil {
+			return nil, fmt.Errorf("set notification preferences: %w", err)
+		}
+	}
+	return s.ListPreferences(ctx, userID)
+}
+
+// ListDeliveries retrieves the user's most recent deliveries.
+func (s *notificationService) ListDeliveries(ctx context.Context, userID int64) ([]domain.NotificationDelivery, error) {
+	deliveries, err := s.notificationRepo.ListDeliveries(ctx, s.dbExecutor, userID, maxListedDeliveries)
+	if err != nil {
+		return nil, fmt.Errorf("list notification deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Notify stores the notification in the user's inbox whatever their preferences, then records a pending
+// delivery per device of the user on a platform with a sender, unless the user switched off push
+// notifications of the category. Sending is left to Deliver, so a slow or unavailable push service never
+// holds up the request that caused the notification.
+func (s *notificationService) Notify(ctx context.Context, notification *domain.Notification) error {
+	if !notification.Category.Valid() {
+		return fmt.Errorf("%w: unknown notification category %q", util.ErrInvalidInput, notification.Category)
+	}
+	if err := s.notificationRepo.CreateNotification(ctx, s.dbExecutor, notification); err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+	if len(s.senders) == 0 {
+		return nil
+	}
+	preferences, err := s.notificationRepo.ListPreferences(ctx, s.dbExecutor, notification.UserID)
+	if err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+	for _, p := range preferences {
+		if p.Category == notification.Category && p.Channel == domain.NotificationChannelPush && !p.Enabled {
+			return nil
+		}
+	}
+	devices, err := s.notificationRepo.ListDevices(ctx, s.dbExecutor, notification.UserID)
+	if err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+	for _, device := range devices {
+		if s.senders[device.Platform] == nil {
+			continue
+		}
+		delivery := &domain.NotificationDelivery{
+			UserID:         notification.UserID,
+			NotificationID: &notification.ID,
+			DeviceID:       &device.ID,
+			Category:       notification.Category,
+			Channel:        domain.NotificationChannelPush,
+			Title:          notification.Title,
+			Body:           notification.Body,
+			Data:           notification.Data,
+			Status:         domain.DeliveryStatusPending,
+		}
+		if err := s.notificationRepo.CreateDelivery(ctx, s.dbExecutor, delivery); err != nil {
+			return fmt.Errorf("notify: %w", err)
+		}
+	}
+	return nil
+}
+
+// Deliver claims a batch of pending deliveries in a transaction, so concurrent instances send each one once.
+// A delivery is sent once per run; after a transient failure it stays pending for the next run, up to
+// maxPushAttempts. A token the push service no longer knows fails its delivery and removes the device.
+func (s *notificationService) Deliver(ctx context.Context) (int, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return 0, fmt.Errorf("deliver notifications: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return 0, fmt.Errorf("deliver notifications: transaction controller does not implement DBExecutor")
+	}
+
+	deliveries, err := s.notificationRepo.ClaimPendingDeliveries(ctx, txExecutor, s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("deliver notifications: %w", err)
+	}
+	sent := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		s.send(ctx, txExecutor, delivery)
+		if delivery.Status == domain.DeliveryStatusSent {
+			sent++
+		}
+		if err := s.notificationRepo.UpdateDelivery(ctx, txExecutor, delivery); err != nil {
+			return 0, fmt.Errorf("deliver notifications: %w", err)
+		}
+	}
+	if err := s.commitTx(txController); err != nil {
+		return 0, fmt.Errorf("deliver notifications: failed to commit transaction: %w", err)
+	}
+	if len(deliveries) > 0 {
+		s.logger.Info("Notifications delivered", "claimed", len(deliveries), "sent", sent)
+	}
+	return sent, nil
+}
+
+// send attempts a delivery and sets its outcome.
+func (s *notificationService) send(ctx context.Context, q repository.DBExecutor, delivery *domain.NotificationDelivery) {
+	fail := func(reason string) {
+		delivery.Status = domain.DeliveryStatusFailed
+		delivery.LastError = &reason
+	}
+	if delivery.DeviceID == nil || delivery.DeviceToken == nil || delivery.Platform == nil {
+		fail(deviceRemovedDelivery)
+		return
+	}
+	sender := s.senders[*delivery.Platform]
+	if sender == nil {
+		fail(fmt.Sprintf("no sender for %s", *delivery.Platform))
+		return
+	}
+
+	delivery.Attempts++
+	messageID, err := sender.Send(ctx, *delivery.DeviceToken, &domain.Notification{
+		UserID:   delivery.UserID,
+		Category: delivery.Category,
+		Title:    delivery.Title,
+		Body:     delivery.Body,
+		Data:     delivery.Data,
+	})
+	switch {
+	case err == nil:
+		now := time.Now().UTC()
+		delivery.Status = domain.DeliveryStatusSent
+		delivery.ProviderMessageID = &messageID
+		delivery.LastError = nil
+		delivery.SentAt = &now
+	case util.IsError(err, util.ErrPushTokenInvalid):
+		fail(err.Error())
+		if delErr := s.notificationRepo.DeleteDevice(ctx, q, delivery.UserID, *delivery.DeviceID); delErr != nil && !util.IsError(delErr, util.ErrNotFound) {
+			s.logger.Error("Failed to remove unregistered push device", "device_id", *delivery.DeviceID, "error", delErr)
+		} else {
+			s.logger.Info("Unregistered push device removed", "user_id", delivery.UserID, "device_id", *delivery.DeviceID)
+		}
+	case util.IsError(err, util.ErrPushRejected) || delivery.Attempts >= maxPushAttempts:
+		fail(err.Error())
+		s.logger.Warn("Notification delivery failed", "delivery_id", delivery.ID, "attempts", delivery.Attempts, "error", err)
+	default:
+		reason := err.Error()
+		delivery.LastError = &reason // Stays pending for a retry
+	}
+}
+
+// OnTransaction notifies the owners of the wallets a completed transaction moved money out of and into. An
+// owner on both sides, e.g. of a transfer between their own wallets, is notified once.
+func (s *notificationService) OnTransaction(ctx context.Context, transaction *domain.Transaction) {
+	if transaction.Status != domain.TransactionStatusCompleted {
+		return
+	}
+	ctx = i18n.WithLocale(ctx, i18n.DefaultLocale)
+	amount := transaction.Amount.StringFixed(domain.CurrencyPrecision(transaction.Currency)) + " " + transaction.Currency
+	notified := make(map[int64]bool, 2)
+	for _, side := range []struct {
+		walletID *int64
+		sign     string
+	}{{transaction.FromWalletID, "-"}, {transaction.ToWalletID, "+"}} {
+		if side.walletID == nil {
+			continue
+		}
+		wallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, *side.walletID)
+		if err != nil {
+			s.logger.Error("Failed to get wallet for transaction notification", "wallet_id", *side.walletID, "error", err)
+			continue
+		}
+		if notified[wallet.UserID] {
+			continue
+		}
+		notified[wallet.UserID] = true
+		notification := &domain.Notification{
+			UserID:   wallet.UserID,
+			Category: domain.NotificationCategoryTransaction,
+			Title:    s.descriptions.Describe(ctx, transaction, wallet.ID),
+			Body:     side.sign + amount,
+			Data:     map[string]string{"transaction_id": transaction.PublicID.String(), "wallet_id": wallet.PublicID.String()},
+		}
+		if err := s.Notify(ctx, notification); err != nil {
+			s.logger.Error("Failed to queue transaction notification", "user_id", wallet.UserID, "transaction_id", transaction.PublicID, "error", err)
+		}
+	}
+}
+
+// NotifyNewDevice implements SecurityNotifier.
+func (s *notificationService) NotifyNewDevice(ctx context.Context, user *domain.User, event *domain.AuthEvent) error {
+	title, body := notificationText("notification_new_device", nil)
+	return s.Notify(ctx, &domain.Notification{
+		UserID:   user.ID,
+		Category: domain.NotificationCategorySecurity,
+		Title:    title,
+		Body:     body,
+		Data:     map[string]string{"auth_event_id": fmt.Sprint(event.ID)},
+	})
+}
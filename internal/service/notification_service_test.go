@@ -0,0 +1,404 @@
+// internal/service/notification_service_test.go
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// collectingNotifier is a Notifier collecting every notification.
+type collectingNotifier struct {
+	notifications []*domain.Notification
+}
+
+func (n *collectingNotifier) Notify(ctx context.Context, notification *domain.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+// stubPushSender is a PushSender answering every send with the error for the token, or a message ID.
+type stubPushSender struct {
+	errs map[string]error
+	sent []string
+}
+
+func (s *stubPushSender) Send(ctx context.Context, token string, notification *domain.Notification) (string, error) {
+	if err := s.errs[token]; err != nil {
+		return "", err
+	}
+	s.sent = append(s.sent, token)
+	return "msg-" + token, nil
+}
+
+// TestNotificationService tests device registration, queuing notifications by preference and their delivery.
+func TestNotificationService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	userID := int64(10)
+
+	type mocks struct {
+		notificationRepo *MockNotificationRepository
+		userRepo         *MockUserRepository
+		walletRepo       *MockWalletRepository
+		sender           *stubPushSender
+		dbExecutor       *MockDBExecutor
+		txController     *MockTxController
+	}
+	newService := func() (NotificationService, mocks) {
+		m := mocks{
+			notificationRepo: new(MockNotificationRepository),
+			userRepo:         new(MockUserRepository),
+			walletRepo:       new(MockWalletRepository),
+			sender:           &stubPushSender{errs: map[string]error{}},
+			dbExecutor:       new(MockDBExecutor),
+			txController:     new(MockTxController),
+		}
+		service := NewNotificationService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.notificationRepo,
+			m.userRepo,
+			m.walletRepo,
+			NewDescriptionRenderer(nil),
+			map[domain.PushPlatform]PushSender{domain.PushPlatformFCM: m.sender}, // No APNs
+			50,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+	devices := func(n int) []domain.DeviceToken {
+		list := make([]domain.DeviceToken, n)
+		for i := range list {
+			list[i] = domain.DeviceToken{ID: int64(i + 1), UserID: userID, Platform: domain.PushPlatformFCM, Token: fmt.Sprintf("token-%d", i+1)}
+		}
+		return list
+	}
+
+	t.Run("RegisterDeviceLimit", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, userID).Return(&domain.User{ID: userID}, nil)
+		m.notificationRepo.On("ListDevices", ctx, m.dbExecutor, userID).Return(devices(domain.MaxDevicesPerUser), nil)
+		m.notificationRepo.On("RegisterDevice", ctx, m.dbExecutor, mock.Anything).Return(nil).Once()
+
+		err := service.RegisterDevice(ctx, &domain.DeviceToken{UserID: userID, Platform: domain.PushPlatformFCM, Token: "token-new"})
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+
+		err = service.RegisterDevice(ctx, &domain.DeviceToken{UserID: userID, Platform: domain.PushPlatformFCM, Token: "token-3"})
+		assert.NoError(t, err, "re-registering a known device is allowed at the limit")
+		m.notificationRepo.AssertExpectations(t)
+	})
+
+	t.Run("RegisterDeviceValidates", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		for _, device := range []*domain.DeviceToken{
+			{UserID: userID, Platform: "WNS", Token: "token"},
+			{UserID: userID, Platform: domain.PushPlatformAPNs, Token: ""},
+			{UserID: userID, Platform: domain.PushPlatformAPNs, Token: strings.Repeat("a", domain.MaxDeviceTokenBytes+1)},
+		} {
+			assert.ErrorIs(t, service.RegisterDevice(ctx, device), util.ErrInvalidInput)
+		}
+		m.notificationRepo.AssertNotCalled(t, "RegisterDevice", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PreferencesDefaultToEnabled", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		updatedAt := time.Now()
+
+		m.notificationRepo.On("ListPreferences", ctx, m.dbExecutor, userID).Return([]domain.NotificationPreference{
+			{UserID: userID, Category: domain.NotificationCategoryBudget, Channel: domain.NotificationChannelPush, Enabled: false, UpdatedAt: &updatedAt},
+		}, nil).Once()
+
+		preferences, err := service.ListPreferences(ctx, userID)
+
+		assert.NoError(t, err)
+		assert.Len(t, preferences, len(domain.NotificationCategories))
+		for _, p := range preferences {
+			assert.Equal(t, p.Category != domain.NotificationCategoryBudget, p.Enabled, p.Category)
+		}
+	})
+
+	t.Run("NotifyQueuesPerSupportedDevice", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		list := devices(2)
+		list[1].Platform = domain.PushPlatformAPNs
+
+		m.notificationRepo.On("CreateNotification", ctx, m.dbExecutor, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(2).(*domain.Notification).ID = 42
+		}).Once()
+		m.notificationRepo.On("ListPreferences", ctx, m.dbExecutor, userID).Return([]domain.NotificationPreference{}, nil).Once()
+		m.notificationRepo.On("ListDevices", ctx, m.dbExecutor, userID).Return(list, nil).Once()
+		m.notificationRepo.On("CreateDelivery", ctx, m.dbExecutor, mock.MatchedBy(func(d *domain.NotificationDelivery) bool {
+			return *d.DeviceID == 1 && *d.NotificationID == 42 && d.Status == domain.DeliveryStatusPending && d.Title == "Hi"
+		})).Return(nil).Once()
+
+		err := service.Notify(ctx, &domain.Notification{UserID: userID, Category: domain.NotificationCategorySecurity, Title: "Hi"})
+
+		assert.NoError(t, err)
+		m.notificationRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotifySkipsDisabledCategory", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.notificationRepo.On("CreateNotification", ctx, m.dbExecutor, mock.Anything).Return(nil).Once()
+		m.notificationRepo.On("ListPreferences", ctx, m.dbExecutor, userID).Return([]domain.NotificationPreference{
+			{UserID: userID, Category: domain.NotificationCategoryTransaction, Channel: domain.NotificationChannelPush, Enabled: false},
+		}, nil).Once()
+
+		err := service.Notify(ctx, &domain.Notification{UserID: userID, Category: domain.NotificationCategoryTransaction})
+
+		assert.NoError(t, err)
+		m.notificationRepo.AssertExpectations(t) // Still in the inbox
+		m.notificationRepo.AssertNotCalled(t, "ListDevices", mock.Anything, mock.Anything, mock.Anything)
+		m.notificationRepo.AssertNotCalled(t, "CreateDelivery", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("TransactionNotifiesEachOwnerOnce", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		fromID, toID, ownWalletID := int64(1), int64(2), int64(3)
+		recipientID := int64(20)
+
+		m.walletRepo.On("GetWalletByID", mock.Anything, m.dbExecutor, fromID).Return(&domain.Wallet{ID: fromID, UserID: userID, PublicID: uuid.New()}, nil)
+		m.walletRepo.On("GetWalletByID", mock.Anything, m.dbExecutor, toID).Return(&domain.Wallet{ID: toID, UserID: recipientID, PublicID: uuid.New()}, nil)
+		m.walletRepo.On("GetWalletByID", mock.Anything, m.dbExecutor, ownWalletID).Return(&domain.Wallet{ID: ownWalletID, UserID: userID, PublicID: uuid.New()}, nil)
+		m.notificationRepo.On("CreateNotification", mock.Anything, m.dbExecutor, mock.Anything).Return(nil)
+		m.notificationRepo.On("ListPreferences", mock.Anything, m.dbExecutor, mock.Anything).Return([]domain.NotificationPreference{}, nil)
+		m.notificationRepo.On("ListDevices", mock.Anything, m.dbExecutor, mock.Anything).Return(devices(1), nil)
+		var bodies []string
+		m.notificationRepo.On("CreateDelivery", mock.Anything, m.dbExecutor, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			bodies = append(bodies, args.Get(2).(*domain.NotificationDelivery).Body)
+		})
+
+		transfer := domain.NewTransaction(&fromID, &toID, decimal.NewFromFloat(12.5), "EUR", domain.TransactionTypeTransfer, nil)
+		transfer.Status = domain.TransactionStatusCompleted
+		service.OnTransaction(ctx, transfer)
+		assert.Equal(t, []string{"-12.50 EUR", "+12.50 EUR"}, bodies)
+
+		bodies = nil
+		own := domain.NewTransaction(&fromID, &ownWalletID, decimal.NewFromInt(5), "EUR", domain.TransactionTypeTransfer, nil)
+		own.Status = domain.TransactionStatusCompleted
+		service.OnTransaction(ctx, own)
+		assert.Equal(t, []string{"-5.00 EUR"}, bodies)
+	})
+
+	t.Run("MarkReadOfOtherUser", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.notificationRepo.On("MarkNotificationRead", ctx, m.dbExecutor, userID, int64(7), mock.Anything).Return(nil, util.ErrNotFound).Once()
+
+		_, err := service.MarkRead(ctx, userID, 7)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+
+	t.Run("DeliverOutcomes", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		fcm, apns := domain.PushPlatformFCM, domain.PushPlatformAPNs
+		pending := func(id int64, platform *domain.PushPlatform, token string, attempts int) domain.NotificationDelivery {
+			deviceID := id * 10
+			return domain.NotificationDelivery{ID: id, UserID: userID, DeviceID: &deviceID, Status: domain.DeliveryStatusPending,
+				Attempts: attempts, Platform: platform, DeviceToken: &token}
+		}
+		removed := domain.NotificationDelivery{ID: 6, UserID: userID, Status: domain.DeliveryStatusPending}
+		m.sender.errs["gone"] = fmt.Errorf("fcm: %w", util.ErrPushTokenInvalid)
+		m.sender.errs["flaky"] = errors.New("fcm: message rejected with status 503")
+
+		m.notificationRepo.On("ClaimPendingDeliveries", ctx, m.txController, 50).Return([]domain.NotificationDelivery{
+			pending(1, &fcm, "ok", 0),
+			pending(2, &fcm, "gone", 0),
+			pending(3, &fcm, "flaky", 0),
+			pending(4, &fcm, "flaky", maxPushAttempts-1),
+			pending(5, &apns, "ios", 0),
+			removed,
+		}, nil).Once()
+		outcomes := map[int64]domain.DeliveryStatus{}
+		m.notificationRepo.On("UpdateDelivery", ctx, m.txController, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			delivery := args.Get(2).(*domain.NotificationDelivery)
+			outcomes[delivery.ID] = delivery.Status
+		})
+		m.notificationRepo.On("DeleteDevice", ctx, m.txController, userID, int64(20)).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		sent, err := service.Deliver(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Equal(t, map[int64]domain.DeliveryStatus{
+			1: domain.DeliveryStatusSent,
+			2: domain.DeliveryStatusFailed,
+			3: domain.DeliveryStatusPending, // Retried on the next run
+			4: domain.DeliveryStatusFailed,
+			5: domain.DeliveryStatusFailed, // No APNs sender
+			6: domain.DeliveryStatusFailed,
+		}, outcomes)
+		m.notificationRepo.AssertExpectations(t)
+		m.txController.AssertExpectations(t)
+	})
+}
+
+// decodeJWT returns the header and claims of a compact JWT and verifies its signature with verify.
+func decodeJWT(t *testing.T, token string, verify func(digest, signature []byte) bool) (map[string]any, map[string]any) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+	var header, claims map[string]any
+	for i, dest := range []*map[string]any{&header, &claims} {
+		raw, err := base64.RawURLEncoding.DecodeString(parts[i])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(raw, dest))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.True(t, verify(digest[:], signature), "JWT signature")
+	return header, claims
+}
+
+// TestFCMSender tests the service account token exchange and the mapping of FCM errors.
+func TestFCMSender(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			require.NoError(t, r.ParseForm())
+			_, claims := decodeJWT(t, r.Form.Get("assertion"), func(digest, signature []byte) bool {
+				return rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest, signature) == nil
+			})
+			assert.Equal(t, "sender@project.iam.gserviceaccount.com", claims["iss"])
+			assert.Equal(t, fcmScope, claims["scope"])
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "access", "expires_in": 3600})
+		case "/v1/projects/project/messages:send":
+			assert.Equal(t, "Bearer access", r.Header.Get("Authorization"))
+			var body struct {
+				Message struct {
+					Token string `json:"token"`
+				} `json:"message"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body.Message.Token == "gone" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"name": "projects/project/messages/1"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	credentials, err := json.Marshal(map[string]string{
+		"project_id":     "project",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "sender@project.iam.gserviceaccount.com",
+		"token_uri":      server.URL + "/token",
+	})
+	require.NoError(t, err)
+	sender, err := NewFCMSender(credentials, time.Second)
+	require.NoError(t, err)
+	sender.(*fcmSender).endpoint = server.URL
+
+	ctx := context.Background()
+	id, err := sender.Send(ctx, "device", &domain.Notification{Title: "Hi", Data: map[string]string{"k": "v"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "projects/project/messages/1", id)
+
+	_, err = sender.Send(ctx, "gone", &domain.Notification{Title: "Hi"})
+	assert.ErrorIs(t, err, util.ErrPushTokenInvalid)
+	assert.Equal(t, 1, tokenRequests, "the access token is reused")
+}
+
+// TestAPNsSender tests the provider token and the mapping of APNs errors.
+func TestAPNsSender(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header, claims := decodeJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "bearer "), func(digest, signature []byte) bool {
+			return len(signature) == 64 && ecdsa.Verify(&key.PublicKey, digest, new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:]))
+		})
+		assert.Equal(t, "KEY123", header["kid"])
+		assert.Equal(t, "TEAM123", claims["iss"])
+		assert.Equal(t, "com.example.wallet", r.Header.Get("apns-topic"))
+		if r.URL.Path == "/3/device/gone" {
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"reason":"Unregistered"}`))
+			return
+		}
+		var payload map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "tx-1", payload["transaction_id"])
+		w.Header().Set("apns-id", "apns-1")
+	}))
+	defer server.Close()
+
+	sender, err := NewAPNsSender(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), "KEY123", "TEAM123", "com.example.wallet", true, time.Second)
+	require.NoError(t, err)
+	sender.(*apnsSender).endpoint = server.URL
+	sender.(*apnsSender).client = server.Client()
+
+	ctx := context.Background()
+	id, err := sender.Send(ctx, "device", &domain.Notification{Title: "Hi", Data: map[string]string{"transaction_id": "tx-1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "apns-1", id)
+
+	_, err = sender.Send(ctx, "gone", &domain.Notification{Title: "Hi"})
+	assert.ErrorIs(t, err, util.ErrPushTokenInvalid)
+}
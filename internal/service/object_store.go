@@ -0,0 +1,84 @@
+// internal/service/object_store.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"finflow-wallet/internal/util"
+)
+
+// ObjectStore is where exports are written, e.g. an S3 or GCS bucket. Keys are slash-separated paths;
+// writing an existing key replaces the object.
+type ObjectStore interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Get opens an object for reading; it returns util.ErrNotFound if there is no such object.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// dirObjectStore is an ObjectStore backed by a local directory, e.g. a mounted bucket or a volume
+// picked up by a sync agent.
+type dirObjectStore struct {
+	root string
+}
+
+// NewDirObjectStore creates an ObjectStore that writes objects as files under root.
+func NewDirObjectStore(root string) ObjectStore {
+	return &dirObjectStore{root: root}
+}
+
+func (s *dirObjectStore) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object to a temporary file and renames it into place, so readers never see a partial object.
+func (s *dirObjectStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get opens the object's file.
+func (s *dirObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, util.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	return file, nil
+}
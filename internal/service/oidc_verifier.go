@@ -0,0 +1,309 @@
+// internal/service/oidc_verifier.go
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// idTokenLeeway is the clock skew tolerated between the provider and this service.
+const idTokenLeeway = time.Minute
+
+// jwksMinRefresh is the least time between two fetches of a provider's keys for a token signed with an unknown
+// key, so forged key IDs cannot make the service hammer the provider.
+const jwksMinRefresh = time.Minute
+
+// OIDCProvider is an OpenID Connect provider whose ID tokens are accepted.
+type OIDCProvider struct {
+	Issuer          string   // Required "iss" of the ID tokens
+	Audiences       []string // Client IDs, one of which the "aud" of an ID token must contain
+	JWKSURL         string   // Where the signing keys are published; discovered from the issuer if empty
+	DefaultCurrency string   // Currency of the wallet of users provisioned at first login
+}
+
+// IDTokenVerifier verifies the ID tokens of the configured OpenID Connect providers.
+type IDTokenVerifier interface {
+	// Provider returns the settings of the named provider, or false if it is not configured.
+	Provider(name string) (OIDCProvider, bool)
+	// Verify checks the signature, issuer, audience and lifetime of an ID token of the named provider and
+	// returns its claims. An unknown provider is util.ErrNotFound; an unacceptable token is
+	// util.ErrInvalidIDToken.
+	Verify(ctx context.Context, provider, rawToken string) (*domain.IDTokenClaims, error)
+}
+
+// jwksVerifier is an IDTokenVerifier fetching the providers' signing keys from their JWKS endpoints.
+type jwksVerifier struct {
+	providers map[string]OIDCProvider
+	ttl       time.Duration
+	client    *http.Client
+
+	mu   sync.Mutex
+	keys map[string]*providerKeys // Provider name -> cached signing keys
+}
+
+// providerKeys are the signing keys of a provider by key ID, as last fetched.
+type providerKeys struct {
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewIDTokenVerifier creates an IDTokenVerifier for the providers, caching their signing keys for ttl.
+func NewIDTokenVerifier(providers map[string]OIDCProvider, ttl, timeout time.Duration) IDTokenVerifier {
+	return &jwksVerifier{
+		providers: providers,
+		ttl:       ttl,
+		client:    &http.Client{Timeout: timeout},
+		keys:      make(map[string]*providerKeys),
+	}
+}
+
+// Provider implements IDTokenVerifier.
+func (v *jwksVerifier) Provider(name string) (OIDCProvider, bool) {
+	provider, ok := v.providers[name]
+	return provider, ok
+}
+
+// idTokenPayload is the payload of an ID token; "aud" is a string or an array of strings.
+type idTokenPayload struct {
+	Issuer            string          `json:"iss"`
+	Subject           string          `json:"sub"`
+	Audience          json.RawMessage `json:"aud"`
+	ExpiresAt         int64           `json:"exp"`
+	IssuedAt          int64           `json:"iat"`
+	NotBefore         int64           `json:"nbf"`
+	Email             string          `json:"email"`
+	EmailVerified     any             `json:"email_verified"` // Some providers send "true" as a string
+	PreferredUsername string          `json:"preferred_username"`
+	Name              string          `json:"name"`
+}
+
+func (p *idTokenPayload) audiences() []string {
+	var single string
+	if json.Unmarshal(p.Audience, &single) == nil {
+		return []string{single}
+	}
+	var multiple []string
+	_ = json.Unmarshal(p.Audience, &multiple)
+	return multiple
+}
+
+// Verify implements IDTokenVerifier. Only RS256 and ES256 signatures are accepted, so a token cannot pick
+// "none" or an HMAC keyed with a public key.
+func (v *jwksVerifier) Verify(ctx context.Context, name, rawToken string) (*domain.IDTokenClaims, error) {
+	provider, ok := v.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("OIDC provider %q: %w", name, util.ErrNotFound)
+	}
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a compact JWT", util.ErrInvalidIDToken)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", util.ErrInvalidIDToken)
+	}
+	if header.Alg != "RS256" && header.Alg != "ES256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", util.ErrInvalidIDToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", util.ErrInvalidIDToken)
+	}
+	key, err := v.signingKey(ctx, name, provider, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(header.Alg, key, digest[:], signature) {
+		return nil, fmt.Errorf("%w: bad signature", util.ErrInvalidIDToken)
+	}
+
+	var payload idTokenPayload
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", util.ErrInvalidIDToken)
+	}
+	now := time.Now()
+	switch {
+	case payload.Issuer != provider.Issuer:
+		return nil, fmt.Errorf("%w: issuer %q is not %q", util.ErrInvalidIDToken, payload.Issuer, provider.Issuer)
+	case !slices.ContainsFunc(payload.audiences(), func(aud string) bool { return slices.Contains(provider.Audiences, aud) }):
+		return nil, fmt.Errorf("%w: issued for another audience", util.ErrInvalidIDToken)
+	case payload.Subject == "":
+		return nil, fmt.Errorf("%w: no subject", util.ErrInvalidIDToken)
+	case !now.Before(time.Unix(payload.ExpiresAt, 0).Add(idTokenLeeway)):
+		return nil, fmt.Errorf("%w: expired", util.ErrInvalidIDToken)
+	case payload.IssuedAt != 0 && now.Add(idTokenLeeway).Before(time.Unix(payload.IssuedAt, 0)):
+		return nil, fmt.Errorf("%w: issued in the future", util.ErrInvalidIDToken)
+	case payload.NotBefore != 0 && now.Add(idTokenLeeway).Before(time.Unix(payload.NotBefore, 0)):
+		return nil, fmt.Errorf("%w: not valid yet", util.ErrInvalidIDToken)
+	}
+	return &domain.IDTokenClaims{
+		Issuer:            payload.Issuer,
+		Subject:           payload.Subject,
+		Email:             payload.Email,
+		EmailVerified:     payload.EmailVerified == true || payload.EmailVerified == "true",
+		PreferredUsername: payload.PreferredUsername,
+		Name:              payload.Name,
+		ExpiresAt:         time.Unix(payload.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verifySignature checks a JWS signature of the digest; an ES256 signature is r || s, 32 bytes each.
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) bool {
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest, signature) == nil
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve != elliptic.P256() || len(signature) != 64 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(ecKey, digest, r, s)
+	}
+	return false
+}
+
+// signingKey returns the provider's key with the ID. The keys are fetched again once the cache expires, or
+// early for an unknown key ID, as providers rotate their keys without notice.
+func (v *jwksVerifier) signingKey(ctx context.Context, name string, provider OIDCProvider, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	cached := v.keys[name]
+	now := time.Now()
+	if cached != nil && now.Sub(cached.fetchedAt) < v.ttl {
+		if key, ok := cached.keys[kid]; ok {
+			return key, nil
+		}
+		if now.Sub(cached.fetchedAt) < jwksMinRefresh {
+			return nil, fmt.Errorf("%w: unknown signing key %q", util.ErrInvalidIDToken, kid)
+		}
+	}
+	keys, err := v.fetchKeys(ctx, provider)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC provider %q: %w", name, err)
+	}
+	v.keys[name] = &providerKeys{keys: keys, fetchedAt: now}
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signing key %q", util.ErrInvalidIDToken, kid)
+	}
+	return key, nil
+}
+
+// fetchKeys fetches the provider's JWKS, discovering its URL first if it is not configured.
+func (v *jwksVerifier) fetchKeys(ctx context.Context, provider OIDCProvider) (map[string]crypto.PublicKey, error) {
+	jwksURL := provider.JWKSURL
+	if jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, strings.TrimSuffix(provider.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, fmt.Errorf("failed to discover signing keys: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, fmt.Errorf("failed to discover signing keys: no jwks_uri")
+		}
+		jwksURL = discovery.JWKSURI
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, jwksURL, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, ok := jwk.publicKey(); ok {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (v *jwksVerifier) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jsonWebKey is a public key of a JWKS (RFC 7517). Keys of other types or curves are skipped.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`   // RSA modulus
+	E   string `json:"e"`   // RSA exponent
+	Crv string `json:"crv"` // EC curve
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, bool) {
+	switch k.Kty {
+	case "RSA":
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			return nil, false
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, true
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, false
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil, false
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, false
+		}
+		return key, true
+	}
+	return nil, false
+}
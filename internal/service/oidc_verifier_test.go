@@ -0,0 +1,165 @@
+// internal/service/oidc_verifier_test.go
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/util"
+)
+
+// TestIDTokenVerifier tests ID tokens signed with the keys of a provider's JWKS, discovered from its issuer.
+func TestIDTokenVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var jwksFetches atomic.Int32
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "jwks_uri": issuer + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwksFetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	newVerifier := func() IDTokenVerifier {
+		return NewIDTokenVerifier(map[string]OIDCProvider{
+			"acme": {Issuer: issuer, Audiences: []string{"finflow"}, DefaultCurrency: "EUR"},
+		}, time.Hour, 5*time.Second)
+	}
+	claims := func() map[string]any {
+		return map[string]any{
+			"iss":                issuer,
+			"sub":                "user-42",
+			"aud":                []string{"other", "finflow"},
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"iat":                time.Now().Unix(),
+			"email":              "ada@example.com",
+			"email_verified":     "true",
+			"preferred_username": "ada",
+		}
+	}
+	signRS256 := func(kid string, payload map[string]any) string {
+		signingInput := jwtSigningInput(t, "RS256", kid, payload)
+		digest := sha256.Sum256([]byte(signingInput))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signingInput + "." + b64(signature)
+	}
+	signES256 := func(kid string, payload map[string]any) string {
+		signingInput := jwtSigningInput(t, "ES256", kid, payload)
+		digest := sha256.Sum256([]byte(signingInput))
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		require.NoError(t, err)
+		return signingInput + "." + b64(append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...))
+	}
+
+	t.Run("ValidTokens", func(t *testing.T) {
+		verifier := newVerifier()
+
+		rsaClaims, err := verifier.Verify(context.Background(), "acme", signRS256("rsa-1", claims()))
+		require.NoError(t, err)
+		assert.Equal(t, "user-42", rsaClaims.Subject)
+		assert.Equal(t, "ada", rsaClaims.PreferredUsername)
+		assert.True(t, rsaClaims.EmailVerified)
+
+		_, err = verifier.Verify(context.Background(), "acme", signES256("ec-1", claims()))
+		require.NoError(t, err)
+		settings, ok := verifier.Provider("acme")
+		require.True(t, ok)
+		assert.Equal(t, "EUR", settings.DefaultCurrency)
+	})
+
+	t.Run("RejectedClaims", func(t *testing.T) {
+		verifier := newVerifier()
+		cases := map[string]func(map[string]any){
+			"OtherIssuer":   func(c map[string]any) { c["iss"] = "https://evil.example.com" },
+			"OtherAudience": func(c map[string]any) { c["aud"] = "other" },
+			"Expired":       func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+			"NotYetValid":   func(c map[string]any) { c["nbf"] = time.Now().Add(time.Hour).Unix() },
+			"NoSubject":     func(c map[string]any) { delete(c, "sub") },
+		}
+		for name, modify := range cases {
+			t.Run(name, func(t *testing.T) {
+				payload := claims()
+				modify(payload)
+				_, err := verifier.Verify(context.Background(), "acme", signRS256("rsa-1", payload))
+				assert.ErrorIs(t, err, util.ErrInvalidIDToken)
+			})
+		}
+	})
+
+	t.Run("RejectedSignatures", func(t *testing.T) {
+		verifier := newVerifier()
+		token := signRS256("rsa-1", claims())
+
+		// A payload swapped under a valid signature
+		forged := jwtSigningInput(t, "RS256", "rsa-1", map[string]any{"iss": issuer, "sub": "admin", "aud": "finflow", "exp": time.Now().Add(time.Hour).Unix()})
+		_, err := verifier.Verify(context.Background(), "acme", forged+token[strings.LastIndex(token, "."):])
+		assert.ErrorIs(t, err, util.ErrInvalidIDToken)
+		// An unsigned token
+		_, err = verifier.Verify(context.Background(), "acme", jwtSigningInput(t, "none", "", claims())+".")
+		assert.ErrorIs(t, err, util.ErrInvalidIDToken)
+		// The RSA key ID on an EC signature
+		_, err = verifier.Verify(context.Background(), "acme", signES256("rsa-1", claims()))
+		assert.ErrorIs(t, err, util.ErrInvalidIDToken)
+	})
+
+	t.Run("UnknownKeyRefetchesAtMostOncePerMinute", func(t *testing.T) {
+		verifier := newVerifier()
+		before := jwksFetches.Load()
+
+		_, err := verifier.Verify(context.Background(), "acme", signRS256("rsa-1", claims()))
+		require.NoError(t, err)
+		_, err = verifier.Verify(context.Background(), "acme", signRS256("rotated", claims()))
+		assert.ErrorIs(t, err, util.ErrInvalidIDToken)
+		_, err = verifier.Verify(context.Background(), "acme", signRS256("rotated", claims()))
+		assert.ErrorIs(t, err, util.ErrInvalidIDToken)
+
+		assert.Equal(t, int32(1), jwksFetches.Load()-before)
+	})
+
+	t.Run("UnknownProvider", func(t *testing.T) {
+		_, err := newVerifier().Verify(context.Background(), "other", signRS256("rsa-1", claims()))
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+}
+
+func jwtSigningInput(t *testing.T, alg, kid string, payload map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return b64(header) + "." + b64(body)
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,93 @@
+// internal/service/opa_policy.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// opaPolicy asks an Open Policy Agent server for each decision through its Data API.
+type opaPolicy struct {
+	url    string // Full URL of the decision document, e.g. http://opa:8181/v1/data/finflow/authz/allow
+	client *http.Client
+}
+
+// NewOPAPolicy creates a Policy backed by the OPA decision at url, which must evaluate to a boolean.
+// An undefined decision denies the operation, and an unreachable server fails it.
+func NewOPAPolicy(url string, timeout time.Duration) Policy {
+	return &opaPolicy{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// opaWallet is the input shape of a wallet.
+type opaWallet struct {
+	ID       uuid.UUID `json:"id"`
+	UserID   int64     `json:"user_id"`
+	Currency string    `json:"currency"`
+}
+
+// opaInput is the input document sent to OPA.
+type opaInput struct {
+	Subject      Subject      `json:"subject"`
+	Action       PolicyAction `json:"action"`
+	Resource     opaWallet    `json:"resource"`
+	Counterparty *opaWallet   `json:"counterparty,omitempty"`
+	Amount       string       `json:"amount"`
+}
+
+func toOPAWallet(wallet *domain.Wallet) opaWallet {
+	return opaWallet{ID: wallet.PublicID, UserID: wallet.UserID, Currency: wallet.Currency}
+}
+
+// Authorize implements Policy.
+func (p *opaPolicy) Authorize(ctx context.Context, req PolicyRequest) error {
+	input := opaInput{
+		Subject:  req.Subject,
+		Action:   req.Action,
+		Resource: toOPAWallet(req.Resource),
+		Amount:   req.Amount.String(),
+	}
+	if req.Counterparty != nil {
+		counterparty := toOPAWallet(req.Counterparty)
+		input.Counterparty = &counterparty
+	}
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return fmt.Errorf("opa policy: failed to encode input: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("opa policy: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("opa policy: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("opa policy: unexpected status %d", resp.StatusCode)
+	}
+
+	var decision struct {
+		Result *bool `json:"result"` // Absent when the decision is undefined
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return fmt.Errorf("opa policy: failed to decode decision: %w", err)
+	}
+	if decision.Result == nil || !*decision.Result {
+		return fmt.Errorf("%w: %s denied by policy", util.ErrForbidden, req.Action)
+	}
+	return nil
+}
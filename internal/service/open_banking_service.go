@@ -0,0 +1,334 @@
+// internal/service/open_banking_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// OBAccess identifies who reads account information: the third party an access token was issued to, and the
+// consent the token was issued for.
+type OBAccess struct {
+	ClientID  string
+	ConsentID uuid.UUID
+}
+
+// OpenBankingService defines the interface for account access consents and the account information
+// third parties read through them.
+type OpenBankingService interface {
+	// CreateConsent stores a consent a third party asks the user for. A nil expiration defaults to the maximum
+	// consent age; the transaction window is optional on either side.
+	CreateConsent(ctx context.Context, clientID string, permissions []domain.OBPermission, expiresAt, transactionFrom, transactionTo *time.Time) (*domain.OBConsent, error)
+	// GetConsent retrieves a consent of the third party.
+	GetConsent(ctx context.Context, clientID string, consentID uuid.UUID) (*domain.OBConsent, error)
+	// DeleteConsent revokes a consent of the third party. Deleting a rejected or revoked consent does nothing.
+	DeleteConsent(ctx context.Context, clientID string, consentID uuid.UUID) error
+
+	// ListUserConsents retrieves the consents the user authorised or rejected, newest first.
+	ListUserConsents(ctx context.Context, userID int64) ([]domain.OBConsent, error)
+	// AuthoriseConsent grants a consent awaiting authorisation for some of the user's wallets.
+	AuthoriseConsent(ctx context.Context, userID int64, consentID uuid.UUID, walletIDs []uuid.UUID) (*domain.OBConsent, error)
+	// RejectConsent refuses a consent awaiting authorisation.
+	RejectConsent(ctx context.Context, userID int64, consentID uuid.UUID) (*domain.OBConsent, error)
+	// RevokeConsent withdraws a consent the user authorised.
+	RevokeConsent(ctx context.Context, userID int64, consentID uuid.UUID) (*domain.OBConsent, error)
+
+	// ListAccounts retrieves the wallets the consent covers. It requires ReadAccountsDetail.
+	ListAccounts(ctx context.Context, access OBAccess) ([]domain.Wallet, error)
+	// GetAccount retrieves a wallet the consent covers. It requires ReadAccountsDetail.
+	GetAccount(ctx context.Context, access OBAccess, walletID uuid.UUID) (*domain.Wallet, error)
+	// GetBalance retrieves a wallet the consent covers with its balance. It requires ReadBalances.
+	GetBalance(ctx context.Context, access OBAccess, walletID uuid.UUID) (*domain.Wallet, error)
+	// ListTransactions retrieves a page of the transactions of a wallet the consent covers, within the
+	// consent's transaction window. It requires ReadTransactionsDetail.
+	ListTransactions(ctx context.Context, access OBAccess, walletID uuid.UUID, from, to *time.Time, opts repository.ListOptions) (*domain.Wallet, []domain.Transaction, int64, error)
+}
+
+// openBankingService implements OpenBankingService.
+type openBankingService struct {
+	dbExecutor    repository.DBExecutor
+	consentRepo   repository.OpenBankingRepository
+	walletRepo    repository.WalletRepository
+	wallets       WalletService
+	maxConsentAge time.Duration
+	logger        *slog.Logger
+}
+
+// NewOpenBankingService creates a new instance of OpenBankingService.
+func NewOpenBankingService(
+	dbExecutor repository.DBExecutor,
+	consentRepo repository.OpenBankingRepository,
+	walletRepo repository.WalletRepository,
+	wallets WalletService,
+	maxConsentAge time.Duration,
+	logger *slog.Logger,
+) OpenBankingService {
+	return &openBankingService{
+		dbExecutor:    dbExecutor,
+		consentRepo:   consentRepo,
+		walletRepo:    walletRepo,
+		wallets:       wallets,
+		maxConsentAge: maxConsentAge,
+		logger:        logger,
+	}
+}
+
+// CreateConsent validates the request and caps the expiration at the maximum consent age, so no third party
+// holds access for longer than the user could be asked to renew it.
+func (s *openBankingService) CreateConsent(ctx context.Context, clientID string, permissions []domain.OBPermission, expiresAt, transactionFrom, transactionTo *time.Time) (*domain.OBConsent, error) {
+	if len(permissions) == 0 {
+		return nil, fmt.Errorf("%w: a consent needs at least one permission", util.ErrInvalidInput)
+	}
+	seen := make(map[domain.OBPermission]bool, len(permissions))
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return nil, fmt.Errorf("%w: unknown permission %q", util.ErrInvalidInput, permission)
+		}
+		if seen[permission] {
+			return nil, fmt.Errorf("%w: duplicate permission %q", util.ErrInvalidInput, permission)
+		}
+		seen[permission] = true
+	}
+	if transactionFrom != nil && transactionTo != nil && !transactionFrom.Before(*transactionTo) {
+		return nil, fmt.Errorf("%w: the transaction window ends before it starts", util.ErrInvalidInput)
+	}
+
+	now := time.Now().UTC()
+	expiration := now.Add(s.maxConsentAge)
+	if expiresAt != nil {
+		if !expiresAt.After(now) {
+			return nil, fmt.Errorf("%w: the expiration is in the past", util.ErrInvalidInput)
+		}
+		if expiresAt.Before(expiration) {
+			expiration = expiresAt.UTC()
+		}
+	}
+
+	consent := &domain.OBConsent{
+		ConsentID:               uuid.New(),
+		ClientID:                clientID,
+		Permissions:             permissions,
+		Status:                  domain.OBConsentAwaitingAuthorisation,
+		ExpirationDateTime:      expiration,
+		TransactionFromDateTime: transactionFrom,
+		TransactionToDateTime:   transactionTo,
+		CreationDateTime:        now,
+		StatusUpdateDateTime:    now,
+	}
+	if err := s.consentRepo.CreateConsent(ctx, s.dbExecutor, consent); err != nil {
+		return nil, fmt.Errorf("create consent: %w", err)
+	}
+	s.logger.Info("Account access consent created", "consent_id", consent.ConsentID, "client_id", clientID,
+		"expires_at", expiration)
+	return consent, nil
+}
+
+// GetConsent retrieves a consent; a consent of another third party is not found.
+func (s *openBankingService) GetConsent(ctx context.Context, clientID string, consentID uuid.UUID) (*domain.OBConsent, error) {
+	consent, err := s.consentRepo.GetConsent(ctx, s.dbExecutor, consentID)
+	if err != nil {
+		return nil, fmt.Errorf("get consent %s: %w", consentID, err)
+	}
+	if consent.ClientID != clientID {
+		return nil, fmt.Errorf("get consent %s: %w", consentID, util.ErrNotFound)
+	}
+	return consent, nil
+}
+
+// DeleteConsent revokes the consent unless it is already rejected or revoked.
+func (s *openBankingService) DeleteConsent(ctx context.Context, clientID string, consentID uuid.UUID) error {
+	consent, err := s.GetConsent(ctx, clientID, consentID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.revoke(ctx, consent); err != nil && !util.IsError(err, util.ErrConsentNotPending) {
+		return fmt.Errorf("delete consent %s: %w", consentID, err)
+	}
+	return nil
+}
+
+// ListUserConsents retrieves the consents of the user.
+func (s *openBankingService) ListUserConsents(ctx context.Context, userID int64) ([]domain.OBConsent, error) {
+	consents, err := s.consentRepo.ListConsentsByUser(ctx, s.dbExecutor, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list consents of user %d: %w", userID, err)
+	}
+	return consents, nil
+}
+
+// AuthoriseConsent checks that every wallet belongs to the user before granting the consent for them.
+func (s *openBankingService) AuthoriseConsent(ctx context.Context, userID int64, consentID uuid.UUID, walletIDs []uuid.UUID) (*domain.OBConsent, error) {
+	if len(walletIDs) == 0 {
+		return nil, fmt.Errorf("%w: a consent is authorised for at least one wallet", util.ErrInvalidInput)
+	}
+	ids := make([]int64, 0, len(walletIDs))
+	for _, walletID := range walletIDs {
+		wallet, err := s.wallets.GetWalletByPublicID(ctx, walletID)
+		if err != nil {
+			return nil, fmt.Errorf("authorise consent %s: %w", consentID, err)
+		}
+		if wallet.UserID != userID {
+			return nil, fmt.Errorf("authorise consent %s: wallet %s: %w", consentID, walletID, util.ErrForbidden)
+		}
+		ids = append(ids, wallet.ID)
+	}
+	return s.resolve(ctx, userID, consentID, domain.OBConsentAuthorised, ids)
+}
+
+// RejectConsent refuses the consent.
+func (s *openBankingService) RejectConsent(ctx context.Context, userID int64, consentID uuid.UUID) (*domain.OBConsent, error) {
+	return s.resolve(ctx, userID, consentID, domain.OBConsentRejected, nil)
+}
+
+// resolve records the user's answer to a consent awaiting authorisation. An expired consent can no longer
+// be answered.
+func (s *openBankingService) resolve(ctx context.Context, userID int64, consentID uuid.UUID, status domain.OBConsentStatus, walletIDs []int64) (*domain.OBConsent, error) {
+	consent, err := s.consentRepo.GetConsent(ctx, s.dbExecutor, consentID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve consent %s: %w", consentID, err)
+	}
+	now := time.Now().UTC()
+	if consent.Status != domain.OBConsentAwaitingAuthorisation || !now.Before(consent.ExpirationDateTime) {
+		return nil, fmt.Errorf("resolve consent %s: %w", consentID, util.ErrConsentNotPending)
+	}
+	err = s.consentRepo.ResolveConsent(ctx, s.dbExecutor, consent.ID, status, userID, walletIDs, now)
+	if util.IsError(err, util.ErrNotFound) {
+		return nil, fmt.Errorf("resolve consent %s: %w", consentID, util.ErrConsentNotPending)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve consent %s: %w", consentID, err)
+	}
+	s.logger.Info("Account access consent resolved", "consent_id", consentID, "client_id", consent.ClientID,
+		"user_id", userID, "status", status, "wallets", len(walletIDs))
+	return s.consentRepo.GetConsent(ctx, s.dbExecutor, consentID)
+}
+
+// RevokeConsent withdraws the consent; a consent of another user is not found.
+func (s *openBankingService) RevokeConsent(ctx context.Context, userID int64, consentID uuid.UUID) (*domain.OBConsent, error) {
+	consent, err := s.consentRepo.GetConsent(ctx, s.dbExecutor, consentID)
+	if err != nil {
+		return nil, fmt.Errorf("revoke consent %s: %w", consentID, err)
+	}
+	if consent.UserID == nil || *consent.UserID != userID {
+		return nil, fmt.Errorf("revoke consent %s: %w", consentID, util.ErrNotFound)
+	}
+	return s.revoke(ctx, consent)
+}
+
+// revoke revokes a consent awaiting authorisation or authorised.
+func (s *openBankingService) revoke(ctx context.Context, consent *domain.OBConsent) (*domain.OBConsent, error) {
+	if consent.Status == domain.OBConsentRejected || consent.Status == domain.OBConsentRevoked {
+		return nil, util.ErrConsentNotPending
+	}
+	err := s.consentRepo.RevokeConsent(ctx, s.dbExecutor, consent.ID, time.Now().UTC())
+	if util.IsError(err, util.ErrNotFound) {
+		return nil, util.ErrConsentNotPending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("revoke consent %s: %w", consent.ConsentID, err)
+	}
+	s.logger.Info("Account access consent revoked", "consent_id", consent.ConsentID, "client_id", consent.ClientID)
+	return s.consentRepo.GetConsent(ctx, s.dbExecutor, consent.ConsentID)
+}
+
+// authorizedConsent returns the consent of the access if it is authorised, unexpired and grants the permission.
+func (s *openBankingService) authorizedConsent(ctx context.Context, access OBAccess, permission domain.OBPermission) (*domain.OBConsent, error) {
+	consent, err := s.consentRepo.GetConsent(ctx, s.dbExecutor, access.ConsentID)
+	if util.IsError(err, util.ErrNotFound) {
+		return nil, util.ErrConsentInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if consent.ClientID != access.ClientID || !consent.Active(time.Now().UTC()) || !consent.Grants(permission) {
+		return nil, util.ErrConsentInvalid
+	}
+	return consent, nil
+}
+
+// coveredWallet returns the wallet if the consent covers it; any other wallet is not found, so a third party
+// cannot tell wallets outside its consent from unknown ones.
+func (s *openBankingService) coveredWallet(ctx context.Context, access OBAccess, permission domain.OBPermission, walletID uuid.UUID) (*domain.OBConsent, *domain.Wallet, error) {
+	consent, err := s.authorizedConsent(ctx, access, permission)
+	if err != nil {
+		return nil, nil, err
+	}
+	wallet, err := s.wallets.GetWalletByPublicID(ctx, walletID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !consent.CoversWallet(wallet.ID) || wallet.DeletedAt != nil {
+		return nil, nil, util.ErrWalletNotFound
+	}
+	return consent, wallet, nil
+}
+
+// ListAccounts retrieves the wallets of the consent that still exist.
+func (s *openBankingService) ListAccounts(ctx context.Context, access OBAccess) ([]domain.Wallet, error) {
+	consent, err := s.authorizedConsent(ctx, access, domain.OBReadAccountsDetail)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+	wallets, err := s.walletRepo.GetWalletsByIDs(ctx, s.dbExecutor, consent.WalletIDs)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+	accounts := make([]domain.Wallet, 0, len(wallets))
+	for _, wallet := range wallets {
+		if wallet.DeletedAt == nil {
+			accounts = append(accounts, wallet)
+		}
+	}
+	return accounts, nil
+}
+
+// GetAccount retrieves a wallet of the consent.
+func (s *openBankingService) GetAccount(ctx context.Context, access OBAccess, walletID uuid.UUID) (*domain.Wallet, error) {
+	_, wallet, err := s.coveredWallet(ctx, access, domain.OBReadAccountsDetail, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("get account %s: %w", walletID, err)
+	}
+	return wallet, nil
+}
+
+// GetBalance retrieves a wallet of the consent with its balance.
+func (s *openBankingService) GetBalance(ctx context.Context, access OBAccess, walletID uuid.UUID) (*domain.Wallet, error) {
+	_, wallet, err := s.coveredWallet(ctx, access, domain.OBReadBalances, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("get balance of %s: %w", walletID, err)
+	}
+	return wallet, nil
+}
+
+// ListTransactions narrows the requested range to the consent's transaction window and reads the history as
+// the consenting user, so the authorization policy applies as it does to the user's own requests.
+func (s *openBankingService) ListTransactions(ctx context.Context, access OBAccess, walletID uuid.UUID, from, to *time.Time, opts repository.ListOptions) (*domain.Wallet, []domain.Transaction, int64, error) {
+	consent, wallet, err := s.coveredWallet(ctx, access, domain.OBReadTransactionsDetail, walletID)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("list transactions of %s: %w", walletID, err)
+	}
+	if window := consent.TransactionFromDateTime; window != nil && (from == nil || from.Before(*window)) {
+		from = window
+	}
+	if window := consent.TransactionToDateTime; window != nil && (to == nil || to.After(*window)) {
+		to = window
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return wallet, []domain.Transaction{}, 0, nil // The requested range lies outside the window
+	}
+
+	ctx = WithSubject(ctx, Subject{Kind: SubjectUser, UserID: *consent.UserID})
+	transactions, total, err := s.wallets.ListTransactionHistory(ctx, wallet.ID, repository.TransactionFilter{From: from, To: to}, opts)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("list transactions of %s: %w", walletID, err)
+	}
+	return wallet, transactions, total, nil
+}
@@ -0,0 +1,191 @@
+// internal/service/open_banking_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestOpenBankingService tests account access consents and the account information read through them.
+func TestOpenBankingService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	userID := int64(10)
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: userID, Currency: "EUR", Kind: domain.WalletKindPersonal, Balance: decimal.NewFromInt(80)}
+	other := &domain.Wallet{ID: 2, PublicID: uuid.New(), UserID: userID, Currency: "USD", Kind: domain.WalletKindPersonal}
+	foreign := &domain.Wallet{ID: 3, PublicID: uuid.New(), UserID: 11, Currency: "EUR", Kind: domain.WalletKindPersonal}
+
+	type mocks struct {
+		consentRepo     *MockOpenBankingRepository
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		dbExecutor      *MockDBExecutor
+	}
+	newService := func() (OpenBankingService, mocks) {
+		m := mocks{
+			consentRepo:     new(MockOpenBankingRepository),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			dbExecutor:      new(MockDBExecutor),
+		}
+		txController := new(MockTxController)
+		wallets := NewWalletService(new(MockDBBeginner), m.dbExecutor, new(MockUserRepository), m.walletRepo, m.transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return txController, nil },
+			func(tx db.TxController) error { return txController.Commit() },
+			func(tx db.TxController) { _ = txController.Rollback() })
+		return NewOpenBankingService(m.dbExecutor, m.consentRepo, m.walletRepo, wallets, 90*24*time.Hour, logger), m
+	}
+	// authorised returns a consent of tpp-1 authorised by the user for the wallet
+	authorised := func(permissions ...domain.OBPermission) *domain.OBConsent {
+		return &domain.OBConsent{
+			ID:                 5,
+			ConsentID:          uuid.New(),
+			ClientID:           "tpp-1",
+			UserID:             &userID,
+			Permissions:        permissions,
+			WalletIDs:          []int64{wallet.ID},
+			Status:             domain.OBConsentAuthorised,
+			ExpirationDateTime: time.Now().Add(time.Hour),
+		}
+	}
+
+	t.Run("CreateConsentCapsExpiration", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.consentRepo.On("CreateConsent", ctx, m.dbExecutor, mock.Anything).Return(nil).Once()
+		farFuture := time.Now().AddDate(2, 0, 0)
+
+		consent, err := service.CreateConsent(ctx, "tpp-1", []domain.OBPermission{domain.OBReadBalances}, &farFuture, nil, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.OBConsentAwaitingAuthorisation, consent.Status)
+		assert.Equal(t, "tpp-1", consent.ClientID)
+		assert.WithinDuration(t, time.Now().Add(90*24*time.Hour), consent.ExpirationDateTime, time.Minute)
+	})
+
+	t.Run("CreateConsentRejectsUnknownPermissions", func(t *testing.T) {
+		service, m := newService()
+
+		_, err := service.CreateConsent(context.Background(), "tpp-1", []domain.OBPermission{"ReadEverything"}, nil, nil, nil)
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		_, err = service.CreateConsent(context.Background(), "tpp-1", nil, nil, nil, nil)
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.consentRepo.AssertNotCalled(t, "CreateConsent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AuthoriseConsentForOwnWallets", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		pending := &domain.OBConsent{ID: 5, ConsentID: uuid.New(), ClientID: "tpp-1", Status: domain.OBConsentAwaitingAuthorisation, ExpirationDateTime: time.Now().Add(time.Hour)}
+		m.walletRepo.On("GetWalletByPublicID", ctx, m.dbExecutor, wallet.PublicID).Return(wallet, nil).Once()
+		m.walletRepo.On("GetWalletByPublicID", ctx, m.dbExecutor, other.PublicID).Return(other, nil).Once()
+		m.consentRepo.On("GetConsent", ctx, m.dbExecutor, pending.ConsentID).Return(pending, nil)
+		m.consentRepo.On("ResolveConsent", ctx, m.dbExecutor, pending.ID, domain.OBConsentAuthorised, userID, []int64{wallet.ID, other.ID}, mock.Anything).Return(nil).Once()
+
+		_, err := service.AuthoriseConsent(ctx, userID, pending.ConsentID, []uuid.UUID{wallet.PublicID, other.PublicID})
+
+		require.NoError(t, err)
+		m.consentRepo.AssertExpectations(t)
+	})
+
+	t.Run("AuthoriseConsentRejectsWalletsOfAnotherUser", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.walletRepo.On("GetWalletByPublicID", ctx, m.dbExecutor, foreign.PublicID).Return(foreign, nil).Once()
+
+		_, err := service.AuthoriseConsent(ctx, userID, uuid.New(), []uuid.UUID{foreign.PublicID})
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		m.consentRepo.AssertNotCalled(t, "ResolveConsent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AuthoriseResolvedConsentAgain", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		consent := authorised(domain.OBReadBalances)
+		m.walletRepo.On("GetWalletByPublicID", ctx, m.dbExecutor, wallet.PublicID).Return(wallet, nil).Once()
+		m.consentRepo.On("GetConsent", ctx, m.dbExecutor, consent.ConsentID).Return(consent, nil).Once()
+
+		_, err := service.AuthoriseConsent(ctx, userID, consent.ConsentID, []uuid.UUID{wallet.PublicID})
+
+		assert.ErrorIs(t, err, util.ErrConsentNotPending)
+	})
+
+	t.Run("BalanceOfCoveredWallet", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		consent := authorised(domain.OBReadBalances)
+		m.consentRepo.On("GetConsent", ctx, m.dbExecutor, consent.ConsentID).Return(consent, nil).Once()
+		m.walletRepo.On("GetWalletByPublicID", ctx, m.dbExecutor, wallet.PublicID).Return(wallet, nil).Once()
+
+		result, err := service.GetBalance(ctx, OBAccess{ClientID: "tpp-1", ConsentID: consent.ConsentID}, wallet.PublicID)
+
+		require.NoError(t, err)
+		assert.True(t, result.Balance.Equal(decimal.NewFromInt(80)))
+	})
+
+	t.Run("AccessDeniedOutsideTheConsent", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		consent := authorised(domain.OBReadBalances)
+		m.consentRepo.On("GetConsent", ctx, m.dbExecutor, consent.ConsentID).Return(consent, nil)
+		m.walletRepo.On("GetWalletByPublicID", ctx, m.dbExecutor, other.PublicID).Return(other, nil).Once()
+
+		// A wallet the user did not grant is not found
+		_, err := service.GetBalance(ctx, OBAccess{ClientID: "tpp-1", ConsentID: consent.ConsentID}, other.PublicID)
+		assert.ErrorIs(t, err, util.ErrWalletNotFound)
+		// A permission the consent does not include
+		_, err = service.GetAccount(ctx, OBAccess{ClientID: "tpp-1", ConsentID: consent.ConsentID}, wallet.PublicID)
+		assert.ErrorIs(t, err, util.ErrConsentInvalid)
+		// Another third party's token for the consent
+		_, err = service.GetBalance(ctx, OBAccess{ClientID: "tpp-2", ConsentID: consent.ConsentID}, wallet.PublicID)
+		assert.ErrorIs(t, err, util.ErrConsentInvalid)
+		// A revoked consent
+		consent.Status = domain.OBConsentRevoked
+		_, err = service.GetBalance(ctx, OBAccess{ClientID: "tpp-1", ConsentID: consent.ConsentID}, wallet.PublicID)
+		assert.ErrorIs(t, err, util.ErrConsentInvalid)
+	})
+
+	t.Run("TransactionsWithinConsentWindow", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		consent := authorised(domain.OBReadTransactionsDetail)
+		windowFrom := time.Now().AddDate(0, -1, 0)
+		consent.TransactionFromDateTime = &windowFrom
+		requestedFrom := windowFrom.AddDate(0, -6, 0)
+		m.consentRepo.On("GetConsent", ctx, m.dbExecutor, consent.ConsentID).Return(consent, nil).Once()
+		m.walletRepo.On("GetWalletByPublicID", ctx, m.dbExecutor, wallet.PublicID).Return(wallet, nil).Once()
+		m.walletRepo.On("GetWalletByID", mock.Anything, m.dbExecutor, wallet.ID).Return(wallet, nil).Once()
+		m.transactionRepo.On("ListTransactionsByWalletID", mock.Anything, m.dbExecutor, wallet.ID,
+			repository.TransactionFilter{From: &windowFrom}, repository.ListOptions{Limit: 10}).Return([]domain.Transaction{}, int64(0), nil).Once()
+
+		_, _, _, err := service.ListTransactions(ctx, OBAccess{ClientID: "tpp-1", ConsentID: consent.ConsentID}, wallet.PublicID, &requestedFrom, nil, repository.ListOptions{Limit: 10})
+
+		require.NoError(t, err)
+		m.transactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("DeleteRevokedConsentAgain", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		consent := authorised(domain.OBReadBalances)
+		consent.Status = domain.OBConsentRevoked
+		m.consentRepo.On("GetConsent", ctx, m.dbExecutor, consent.ConsentID).Return(consent, nil).Once()
+
+		require.NoError(t, service.DeleteConsent(ctx, "tpp-1", consent.ConsentID))
+		m.consentRepo.AssertNotCalled(t, "RevokeConsent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,377 @@
+// internal/service/ownership_transfer_service.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/clock"
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// maxListedOwnershipTransfers caps the transfers returned by one list request.
+const maxListedOwnershipTransfers = 100
+
+// maxOwnershipTransferReasonLength is the longest reason accepted with a transfer request.
+const maxOwnershipTransferReasonLength = 500
+
+// ownershipTransferExpiryBatchSize bounds the transfers expired per run of the expiry job.
+const ownershipTransferExpiryBatchSize = 100
+
+// OwnershipTransferService defines the interface for admin-mediated transfers of wallets to other users, e.g.
+// to merge the accounts of a user who registered twice. An admin requests a transfer, which freezes the
+// wallet; the wallet changes owner only once the target user accepts it. Each step is recorded in the
+// wallet's audit trail with the ownership before and after it.
+type OwnershipTransferService interface {
+	// Request starts the transfer of the wallet to the target user and freezes the wallet until the transfer
+	// is accepted, declined, cancelled or expires.
+	Request(ctx context.Context, wallet *domain.Wallet, toUserID int64, reason, requestedBy string) (*domain.OwnershipTransfer, error)
+	// Accept completes a pending transfer to the user, making them the owner of the unfrozen wallet. A
+	// transfer to another user is not found.
+	Accept(ctx context.Context, userID int64, transferID uuid.UUID) (*domain.OwnershipTransfer, *domain.Wallet, error)
+	// Decline closes a pending transfer to the user and unfreezes the wallet, which keeps its owner.
+	Decline(ctx context.Context, userID int64, transferID uuid.UUID) (*domain.OwnershipTransfer, error)
+	// Cancel closes a pending transfer on behalf of an admin and unfreezes the wallet.
+	Cancel(ctx context.Context, transferID uuid.UUID, cancelledBy string) (*domain.OwnershipTransfer, error)
+	GetTransfer(ctx context.Context, transferID uuid.UUID) (*domain.OwnershipTransfer, error)
+	// ListWalletTransfers retrieves the most recent transfers of a wallet.
+	ListWalletTransfers(ctx context.Context, walletID int64) ([]domain.OwnershipTransfer, error)
+	// ListIncomingTransfers retrieves the most recent transfers to a user in one status or, if empty, all.
+	ListIncomingTransfers(ctx context.Context, userID int64, status domain.OwnershipTransferStatus) ([]domain.OwnershipTransfer, error)
+	// ExpireTransfers expires the pending transfers not answered by the given time, unfreezing their wallets,
+	// and returns how many it expired.
+	ExpireTransfers(ctx context.Context, now time.Time) (int, error)
+}
+
+// ownershipTransferService implements OwnershipTransferService.
+type ownershipTransferService struct {
+	dbBeginner      db.DBTxBeginner
+	dbExecutor      repository.DBExecutor
+	transferRepo    repository.OwnershipTransferRepository
+	walletRepo      repository.WalletRepository
+	userRepo        repository.UserRepository
+	auditRepo       repository.AuditRepository
+	restrictionRepo repository.CurrencyRestrictionRepository // Optional; rejects target users the currency is restricted for
+	ttl             time.Duration                            // How long the target user has to answer a transfer
+	clock           clock.Clock                              // Dates requests and answers; the system clock by default
+	logger          *slog.Logger
+	beginTx         db.BeginTxFunc
+	commitTx        db.CommitTxFunc
+	rollbackTx      db.RollbackTxFunc
+}
+
+// NewOwnershipTransferService creates a new instance of OwnershipTransferService.
+func NewOwnershipTransferService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	transferRepo repository.OwnershipTransferRepository,
+	walletRepo repository.WalletRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	restrictionRepo repository.CurrencyRestrictionRepository,
+	ttl time.Duration,
+	clk clock.Clock,
+	logger *slog.Logger,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+) OwnershipTransferService {
+	if clk == nil {
+		clk = clock.System{}
+	}
+	return &ownershipTransferService{
+		dbBeginner:      dbBeginner,
+		dbExecutor:      dbExecutor,
+		transferRepo:    transferRepo,
+		walletRepo:      walletRepo,
+		userRepo:        userRepo,
+		auditRepo:       auditRepo,
+		restrictionRepo: restrictionRepo,
+		ttl:             ttl,
+		clock:           clk,
+		logger:          logger,
+		beginTx:         beginTx,
+		commitTx:        commitTx,
+		rollbackTx:      rollbackTx,
+	}
+}
+
+// Request locks the wallet, so that two requests for the same wallet cannot both freeze it. The target user
+// must be active and must not hold a wallet in the wallet's currency, as a user holds one wallet per currency.
+func (s *ownershipTransferService) Request(ctx context.Context, wallet *domain.Wallet, toUserID int64, reason, requestedBy string) (*domain.OwnershipTransfer, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxOwnershipTransferReasonLength {
+		return nil, fmt.Errorf("%w: reason is required and may have at most %d characters", util.ErrInvalidInput, maxOwnershipTransferReasonLength)
+	}
+	if toUserID <= 0 {
+		return nil, fmt.Errorf("%w: target user is required", util.ErrInvalidInput)
+	}
+	if wallet.Kind == domain.WalletKindSuspense {
+		return nil, fmt.Errorf("%w: suspense wallets belong to the platform", util.ErrInvalidInput)
+	}
+	if requestedBy == "" {
+		return nil, util.ErrForbidden
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("request ownership transfer: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, errors.New("request ownership transfer: transaction controller does not implement DBExecutor")
+	}
+
+	locked, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, wallet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("request ownership transfer: %w", err)
+	}
+	if locked.OwnershipFrozenAt != nil {
+		return nil, fmt.Errorf("%w: wallet %s already has a pending transfer", util.ErrOwnershipTransferFrozen, locked.PublicID)
+	}
+	if locked.UserID == toUserID {
+		return nil, fmt.Errorf("%w: the user already owns the wallet", util.ErrInvalidInput)
+	}
+	if err := s.checkTarget(ctx, txExecutor, toUserID, locked.Currency); err != nil {
+		return nil, fmt.Errorf("request ownership transfer: %w", err)
+	}
+
+	now := s.clock.Now().UTC()
+	transfer := &domain.OwnershipTransfer{
+		PublicID:       uuid.New(),
+		WalletID:       locked.ID,
+		WalletPublicID: locked.PublicID,
+		FromUserID:     locked.UserID,
+		ToUserID:       toUserID,
+		Status:         domain.OwnershipTransferPending,
+		Reason:         reason,
+		RequestedBy:    requestedBy,
+		ExpiresAt:      now.Add(s.ttl),
+		CreatedAt:      now,
+	}
+	if err := s.transferRepo.CreateOwnershipTransfer(ctx, txExecutor, transfer); err != nil {
+		return nil, fmt.Errorf("request ownership transfer: %w", err)
+	}
+	if _, err := s.walletRepo.SetOwnershipFrozen(ctx, txExecutor, locked.ID, true, now); err != nil {
+		return nil, fmt.Errorf("request ownership transfer: failed to freeze wallet: %w", err)
+	}
+	owner := domain.AuditWalletOwnership{UserID: locked.UserID}
+	frozen := domain.AuditWalletOwnership{UserID: locked.UserID, Frozen: true}
+	if err := s.recordStep(ctx, txExecutor, transfer, owner, frozen); err != nil {
+		return nil, fmt.Errorf("request ownership transfer: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("request ownership transfer: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Wallet ownership transfer requested", "transfer_id", transfer.PublicID, "wallet_id", locked.PublicID,
+		"from_user_id", transfer.FromUserID, "to_user_id", toUserID, "requested_by", requestedBy)
+	return transfer, nil
+}
+
+// checkTarget rejects a target user who is deactivated, already holds a wallet in the currency or may not hold
+// the currency for their residency.
+func (s *ownershipTransferService) checkTarget(ctx context.Context, q repository.DBExecutor, userID int64, currency string) error {
+	user, err := s.userRepo.GetUserByID(ctx, q, userID)
+	if util.IsError(err, util.ErrNotFound) {
+		return util.ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if user.DeactivatedAt != nil {
+		return util.ErrUserDeactivated
+	}
+	_, err = s.walletRepo.GetWalletByUserIDAndCurrency(ctx, q, userID, currency)
+	if err == nil {
+		return fmt.Errorf("%w: user %d already has a %s wallet", util.ErrDuplicateEntry, userID, currency)
+	}
+	if !util.IsError(err, util.ErrNotFound) {
+		return err
+	}
+	return checkFundingRestriction(ctx, q, s.restrictionRepo, currency, userID)
+}
+
+// Accept reassigns the wallet and completes the transfer in one transaction.
+func (s *ownershipTransferService) Accept(ctx context.Context, userID int64, transferID uuid.UUID) (*domain.OwnershipTransfer, *domain.Wallet, error) {
+	return s.resolve(ctx, transferID, domain.OwnershipTransferCompleted, userID, "", s.clock.Now().UTC())
+}
+
+// Decline records the target user's refusal of the transfer.
+func (s *ownershipTransferService) Decline(ctx context.Context, userID int64, transferID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	transfer, _, err := s.resolve(ctx, transferID, domain.OwnershipTransferDeclined, userID, "", s.clock.Now().UTC())
+	return transfer, err
+}
+
+// Cancel records the cancellation of the transfer by the admin. An expired transfer can still be cancelled
+// before the expiry job has run.
+func (s *ownershipTransferService) Cancel(ctx context.Context, transferID uuid.UUID, cancelledBy string) (*domain.OwnershipTransfer, error) {
+	if cancelledBy == "" {
+		return nil, util.ErrForbidden
+	}
+	transfer, _, err := s.resolve(ctx, transferID, domain.OwnershipTransferCancelled, 0, cancelledBy, s.clock.Now().UTC())
+	return transfer, err
+}
+
+// resolve moves a pending transfer to its final status under the locks of the transfer and then its wallet.
+// Completing it reassigns the wallet; any other outcome unfreezes it for its owner. userID is the target user
+// answering the transfer, or 0 for an admin or the expiry job. The target user can no longer answer an
+// expired transfer.
+func (s *ownershipTransferService) resolve(ctx context.Context, transferID uuid.UUID, status domain.OwnershipTransferStatus, userID int64, cancelledBy string, now time.Time) (*domain.OwnershipTransfer, *domain.Wallet, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve ownership transfer: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, nil, errors.New("resolve ownership transfer: transaction controller does not implement DBExecutor")
+	}
+
+	transfer, err := s.transferRepo.GetOwnershipTransferForUpdate(ctx, txExecutor, transferID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolve ownership transfer %s: %w", transferID, err)
+	}
+	if userID != 0 && transfer.ToUserID != userID {
+		return nil, nil, fmt.Errorf("resolve ownership transfer %s: %w", transferID, util.ErrNotFound)
+	}
+	expired := !now.Before(transfer.ExpiresAt)
+	if transfer.Status != domain.OwnershipTransferPending || (userID != 0 && expired) ||
+		(status == domain.OwnershipTransferExpired && !expired) {
+		return nil, nil, fmt.Errorf("resolve ownership transfer %s: %w", transferID, util.ErrOwnershipNotPending)
+	}
+
+	before := domain.AuditWalletOwnership{UserID: transfer.FromUserID, Frozen: true}
+	after := domain.AuditWalletOwnership{UserID: transfer.FromUserID}
+	var wallet *domain.Wallet
+	if status == domain.OwnershipTransferCompleted {
+		locked, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, transfer.WalletID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolve ownership transfer %s: %w", transferID, err)
+		}
+		// The target user may have been deactivated, opened a wallet in the currency or moved since the request
+		if err := s.checkTarget(ctx, txExecutor, transfer.ToUserID, locked.Currency); err != nil {
+			return nil, nil, fmt.Errorf("resolve ownership transfer %s: %w", transferID, err)
+		}
+		if wallet, err = s.walletRepo.ReassignWallet(ctx, txExecutor, transfer.WalletID, transfer.ToUserID, now); err != nil {
+			return nil, nil, fmt.Errorf("resolve ownership transfer %s: failed to reassign wallet: %w", transferID, err)
+		}
+		after = domain.AuditWalletOwnership{UserID: transfer.ToUserID}
+	} else {
+		wallet, err = s.walletRepo.SetOwnershipFrozen(ctx, txExecutor, transfer.WalletID, false, now)
+		// A wallet deleted in the meantime has nothing to unfreeze; the transfer is closed all the same
+		if err != nil && !util.IsError(err, util.ErrNotFound) {
+			return nil, nil, fmt.Errorf("resolve ownership transfer %s: failed to unfreeze wallet: %w", transferID, err)
+		}
+	}
+
+	transfer.Status = status
+	if cancelledBy != "" {
+		transfer.CancelledBy = &cancelledBy
+	}
+	transfer.ResolvedAt = &now
+	if err := s.transferRepo.ResolveOwnershipTransfer(ctx, txExecutor, transfer); err != nil {
+		return nil, nil, fmt.Errorf("resolve ownership transfer %s: %w", transferID, err)
+	}
+	if err := s.recordStep(ctx, txExecutor, transfer, before, after); err != nil {
+		return nil, nil, fmt.Errorf("resolve ownership transfer %s: %w", transferID, err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, nil, fmt.Errorf("resolve ownership transfer %s: failed to commit transaction: %w", transferID, err)
+	}
+	s.logger.Info("Wallet ownership transfer resolved", "transfer_id", transfer.PublicID, "wallet_id", transfer.WalletPublicID,
+		"status", status, "owner_before", before.UserID, "owner_after", after.UserID, "cancelled_by", cancelledBy)
+	return transfer, wallet, nil
+}
+
+// recordStep appends the step of the transfer to the wallet's audit chain, in the transaction making it.
+func (s *ownershipTransferService) recordStep(ctx context.Context, q repository.DBExecutor, transfer *domain.OwnershipTransfer, before, after domain.AuditWalletOwnership) error {
+	payload := domain.AuditOwnershipTransferPayload{
+		TransferID:  transfer.PublicID,
+		Status:      transfer.Status,
+		Before:      before,
+		After:       after,
+		ToUserID:    transfer.ToUserID,
+		Reason:      transfer.Reason,
+		RequestedBy: transfer.RequestedBy,
+	}
+	if transfer.CancelledBy != nil {
+		payload.CancelledBy = *transfer.CancelledBy
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit payload: %w", err)
+	}
+	if _, err := appendAuditEvent(ctx, q, s.auditRepo, transfer.WalletID, domain.AuditEventOwnershipTransfer, encoded); err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// GetTransfer retrieves a transfer by its public ID.
+func (s *ownershipTransferService) GetTransfer(ctx context.Context, transferID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	transfer, err := s.transferRepo.GetOwnershipTransfer(ctx, s.dbExecutor, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("get ownership transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// ListWalletTransfers retrieves up to maxListedOwnershipTransfers transfers of the wallet, newest first.
+func (s *ownershipTransferService) ListWalletTransfers(ctx context.Context, walletID int64) ([]domain.OwnershipTransfer, error) {
+	transfers, err := s.transferRepo.ListWalletOwnershipTransfers(ctx, s.dbExecutor, walletID, maxListedOwnershipTransfers)
+	if err != nil {
+		return nil, fmt.Errorf("list ownership transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// ListIncomingTransfers retrieves up to maxListedOwnershipTransfers transfers to the user, newest first.
+func (s *ownershipTransferService) ListIncomingTransfers(ctx context.Context, userID int64, status domain.OwnershipTransferStatus) ([]domain.OwnershipTransfer, error) {
+	switch status {
+	case "", domain.OwnershipTransferPending, domain.OwnershipTransferCompleted, domain.OwnershipTransferDeclined,
+		domain.OwnershipTransferCancelled, domain.OwnershipTransferExpired:
+	default:
+		return nil, fmt.Errorf("%w: unknown ownership transfer status %q", util.ErrInvalidInput, status)
+	}
+	transfers, err := s.transferRepo.ListIncomingOwnershipTransfers(ctx, s.dbExecutor, userID, status, maxListedOwnershipTransfers)
+	if err != nil {
+		return nil, fmt.Errorf("list incoming ownership transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// ExpireTransfers expires up to ownershipTransferExpiryBatchSize transfers, each in its own transaction. A
+// transfer answered or cancelled since it was listed is skipped; other failures are logged, so that one
+// transfer cannot hold up the rest.
+func (s *ownershipTransferService) ExpireTransfers(ctx context.Context, now time.Time) (int, error) {
+	ids, err := s.transferRepo.ListExpiredOwnershipTransfers(ctx, s.dbExecutor, now, ownershipTransferExpiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("expire ownership transfers: %w", err)
+	}
+	expired := 0
+	for _, id := range ids {
+		if _, _, err := s.resolve(ctx, id, domain.OwnershipTransferExpired, 0, "", now); err != nil {
+			if !util.IsError(err, util.ErrOwnershipNotPending) {
+				s.logger.Error("Failed to expire wallet ownership transfer", "transfer_id", id, "error", err)
+			}
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
@@ -0,0 +1,259 @@
+// internal/service/ownership_transfer_service_test.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestOwnershipTransferService tests transferring wallets to other users: the freeze while a transfer is
+// pending, the target user's confirmation and the audit records of each step.
+func TestOwnershipTransferService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "EUR", Kind: domain.WalletKindPersonal}
+
+	type mocks struct {
+		transferRepo    *MockOwnershipTransferRepository
+		walletRepo      *MockWalletRepository
+		userRepo        *MockUserRepository
+		auditRepo       *MockAuditRepository
+		restrictionRepo *MockCurrencyRestrictionRepository
+		txController    *MockTxController
+		dbExecutor      *MockDBExecutor
+	}
+	newService := func() (OwnershipTransferService, mocks) {
+		m := mocks{
+			transferRepo:    new(MockOwnershipTransferRepository),
+			walletRepo:      new(MockWalletRepository),
+			userRepo:        new(MockUserRepository),
+			auditRepo:       new(MockAuditRepository),
+			restrictionRepo: new(MockCurrencyRestrictionRepository),
+			txController:    new(MockTxController),
+			dbExecutor:      new(MockDBExecutor),
+		}
+		m.txController.On("Rollback").Return(nil).Maybe()
+		m.restrictionRepo.On("IsRestrictedForUser", mock.Anything, m.txController, "EUR", int64(20)).Return(false, nil).Maybe()
+		beginTx := func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return m.txController, nil }
+		commitTx := func(tx db.TxController) error { return m.txController.Commit() }
+		rollbackTx := func(tx db.TxController) { _ = m.txController.Rollback() }
+		return NewOwnershipTransferService(new(MockDBBeginner), m.dbExecutor, m.transferRepo, m.walletRepo, m.userRepo, m.auditRepo,
+			m.restrictionRepo, 72*time.Hour, nil, logger, beginTx, commitTx, rollbackTx), m
+	}
+	// pending sets up the locked pending transfer of the wallet to user 20.
+	pending := func(m mocks, expiresAt time.Time) *domain.OwnershipTransfer {
+		transfer := &domain.OwnershipTransfer{
+			ID:             3,
+			PublicID:       uuid.New(),
+			WalletID:       wallet.ID,
+			WalletPublicID: wallet.PublicID,
+			FromUserID:     10,
+			ToUserID:       20,
+			Status:         domain.OwnershipTransferPending,
+			Reason:         "Duplicate registration",
+			RequestedBy:    "alice",
+			ExpiresAt:      expiresAt,
+		}
+		m.transferRepo.On("GetOwnershipTransferForUpdate", mock.Anything, m.txController, transfer.PublicID).Return(transfer, nil).Once()
+		return transfer
+	}
+	// expectAudit expects one audit event in the transaction and decodes its payload into recorded.
+	expectAudit := func(m mocks, recorded *domain.AuditOwnershipTransferPayload) {
+		m.auditRepo.On("LockChain", mock.Anything, m.txController, wallet.ID).Return(nil).Once()
+		m.auditRepo.On("GetChainHead", mock.Anything, m.txController, wallet.ID).Return(&domain.AuditChainHead{Sequence: 4, Hash: "abc"}, nil).Once()
+		m.auditRepo.On("AppendEvent", mock.Anything, m.txController, mock.MatchedBy(func(event *domain.AuditEvent) bool {
+			return event.Type == domain.AuditEventOwnershipTransfer && event.Sequence == 5
+		})).Run(func(args mock.Arguments) {
+			require.NoError(t, json.Unmarshal([]byte(args.Get(2).(*domain.AuditEvent).Payload), recorded))
+		}).Return(nil).Once()
+	}
+
+	t.Run("RequestFreezesWalletAndRecordsFreeze", func(t *testing.T) {
+		service, m := newService()
+		m.walletRepo.On("GetWalletByIDForUpdate", mock.Anything, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.userRepo.On("GetUserByID", mock.Anything, m.txController, int64(20)).Return(&domain.User{ID: 20}, nil).Once()
+		m.walletRepo.On("GetWalletByUserIDAndCurrency", mock.Anything, m.txController, int64(20), "EUR").Return(nil, util.ErrNotFound).Once()
+		m.transferRepo.On("CreateOwnershipTransfer", mock.Anything, m.txController, mock.MatchedBy(func(transfer *domain.OwnershipTransfer) bool {
+			return transfer.FromUserID == 10 && transfer.ToUserID == 20 && transfer.Status == domain.OwnershipTransferPending &&
+				transfer.ExpiresAt.Sub(transfer.CreatedAt) == 72*time.Hour
+		})).Return(nil).Once()
+		m.walletRepo.On("SetOwnershipFrozen", mock.Anything, m.txController, wallet.ID, true, mock.Anything).Return(wallet, nil).Once()
+		var recorded domain.AuditOwnershipTransferPayload
+		expectAudit(m, &recorded)
+		m.txController.On("Commit").Return(nil).Once()
+
+		transfer, err := service.Request(context.Background(), wallet, 20, " Duplicate registration ", "alice")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Duplicate registration", transfer.Reason)
+		assert.Equal(t, domain.AuditWalletOwnership{UserID: 10}, recorded.Before)
+		assert.Equal(t, domain.AuditWalletOwnership{UserID: 10, Frozen: true}, recorded.After)
+		assert.Equal(t, domain.OwnershipTransferPending, recorded.Status)
+		assert.Equal(t, "alice", recorded.RequestedBy)
+	})
+
+	t.Run("RequestToUserWithWalletInCurrencyIsDuplicate", func(t *testing.T) {
+		service, m := newService()
+		m.walletRepo.On("GetWalletByIDForUpdate", mock.Anything, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.userRepo.On("GetUserByID", mock.Anything, m.txController, int64(20)).Return(&domain.User{ID: 20}, nil).Once()
+		m.walletRepo.On("GetWalletByUserIDAndCurrency", mock.Anything, m.txController, int64(20), "EUR").Return(&domain.Wallet{ID: 7}, nil).Once()
+
+		_, err := service.Request(context.Background(), wallet, 20, "Duplicate registration", "alice")
+
+		assert.ErrorIs(t, err, util.ErrDuplicateEntry)
+		m.walletRepo.AssertNotCalled(t, "SetOwnershipFrozen", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RequestWhileTransferPendingIsFrozen", func(t *testing.T) {
+		service, m := newService()
+		frozenAt := time.Now()
+		frozen := *wallet
+		frozen.OwnershipFrozenAt = &frozenAt
+		m.walletRepo.On("GetWalletByIDForUpdate", mock.Anything, m.txController, wallet.ID).Return(&frozen, nil).Once()
+
+		_, err := service.Request(context.Background(), wallet, 20, "Duplicate registration", "alice")
+
+		assert.ErrorIs(t, err, util.ErrOwnershipTransferFrozen)
+		m.transferRepo.AssertNotCalled(t, "CreateOwnershipTransfer", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RequestToDeactivatedUserIsRejected", func(t *testing.T) {
+		service, m := newService()
+		deactivated := time.Now()
+		m.walletRepo.On("GetWalletByIDForUpdate", mock.Anything, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.userRepo.On("GetUserByID", mock.Anything, m.txController, int64(20)).Return(&domain.User{ID: 20, DeactivatedAt: &deactivated}, nil).Once()
+
+		_, err := service.Request(context.Background(), wallet, 20, "Duplicate registration", "alice")
+
+		assert.ErrorIs(t, err, util.ErrUserDeactivated)
+	})
+
+	t.Run("RequestToUserRestrictedForCurrencyIsRejected", func(t *testing.T) {
+		service, m := newService()
+		m.walletRepo.On("GetWalletByIDForUpdate", mock.Anything, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.userRepo.On("GetUserByID", mock.Anything, m.txController, int64(30)).Return(&domain.User{ID: 30}, nil).Once()
+		m.walletRepo.On("GetWalletByUserIDAndCurrency", mock.Anything, m.txController, int64(30), "EUR").Return(nil, util.ErrNotFound).Once()
+		m.restrictionRepo.On("IsRestrictedForUser", mock.Anything, m.txController, "EUR", int64(30)).Return(true, nil).Once()
+
+		_, err := service.Request(context.Background(), wallet, 30, "Duplicate registration", "alice")
+
+		assert.ErrorIs(t, err, util.ErrCurrencyRestricted)
+		m.walletRepo.AssertNotCalled(t, "SetOwnershipFrozen", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AcceptReassignsWalletAndRecordsOwners", func(t *testing.T) {
+		service, m := newService()
+		transfer := pending(m, time.Now().Add(time.Hour))
+		m.walletRepo.On("GetWalletByIDForUpdate", mock.Anything, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.userRepo.On("GetUserByID", mock.Anything, m.txController, int64(20)).Return(&domain.User{ID: 20}, nil).Once()
+		m.walletRepo.On("GetWalletByUserIDAndCurrency", mock.Anything, m.txController, int64(20), "EUR").Return(nil, util.ErrNotFound).Once()
+		m.walletRepo.On("ReassignWallet", mock.Anything, m.txController, wallet.ID, int64(20), mock.Anything).
+			Return(&domain.Wallet{ID: wallet.ID, UserID: 20, Currency: "EUR"}, nil).Once()
+		m.transferRepo.On("ResolveOwnershipTransfer", mock.Anything, m.txController, mock.MatchedBy(func(resolved *domain.OwnershipTransfer) bool {
+			return resolved.Status == domain.OwnershipTransferCompleted && resolved.ResolvedAt != nil && resolved.CancelledBy == nil
+		})).Return(nil).Once()
+		var recorded domain.AuditOwnershipTransferPayload
+		expectAudit(m, &recorded)
+		m.txController.On("Commit").Return(nil).Once()
+
+		_, reassigned, err := service.Accept(context.Background(), 20, transfer.PublicID)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), reassigned.UserID)
+		assert.Equal(t, domain.AuditWalletOwnership{UserID: 10, Frozen: true}, recorded.Before)
+		assert.Equal(t, domain.AuditWalletOwnership{UserID: 20}, recorded.After)
+		assert.Equal(t, domain.OwnershipTransferCompleted, recorded.Status)
+		m.walletRepo.AssertNotCalled(t, "SetOwnershipFrozen", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AcceptByAnotherUserIsNotFound", func(t *testing.T) {
+		service, m := newService()
+		transfer := pending(m, time.Now().Add(time.Hour))
+
+		_, _, err := service.Accept(context.Background(), 10, transfer.PublicID)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		m.walletRepo.AssertNotCalled(t, "ReassignWallet", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AcceptAfterExpiryIsNotPending", func(t *testing.T) {
+		service, m := newService()
+		transfer := pending(m, time.Now().Add(-time.Minute))
+
+		_, _, err := service.Accept(context.Background(), 20, transfer.PublicID)
+
+		assert.ErrorIs(t, err, util.ErrOwnershipNotPending)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("CancelUnfreezesWallet", func(t *testing.T) {
+		service, m := newService()
+		transfer := pending(m, time.Now().Add(time.Hour))
+		m.walletRepo.On("SetOwnershipFrozen", mock.Anything, m.txController, wallet.ID, false, mock.Anything).Return(wallet, nil).Once()
+		m.transferRepo.On("ResolveOwnershipTransfer", mock.Anything, m.txController, mock.MatchedBy(func(resolved *domain.OwnershipTransfer) bool {
+			return resolved.Status == domain.OwnershipTransferCancelled && *resolved.CancelledBy == "bob"
+		})).Return(nil).Once()
+		var recorded domain.AuditOwnershipTransferPayload
+		expectAudit(m, &recorded)
+		m.txController.On("Commit").Return(nil).Once()
+
+		_, err := service.Cancel(context.Background(), transfer.PublicID, "bob")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.AuditWalletOwnership{UserID: 10}, recorded.After)
+		assert.Equal(t, "bob", recorded.CancelledBy)
+	})
+
+	t.Run("ExpireSkipsTransfersAnsweredMeanwhile", func(t *testing.T) {
+		service, m := newService()
+		now := time.Now().UTC()
+		expired := pending(m, now.Add(-time.Minute))
+		answered := &domain.OwnershipTransfer{ID: 4, PublicID: uuid.New(), WalletID: wallet.ID, Status: domain.OwnershipTransferCompleted}
+		m.transferRepo.On("ListExpiredOwnershipTransfers", mock.Anything, m.dbExecutor, now, ownershipTransferExpiryBatchSize).
+			Return([]uuid.UUID{answered.PublicID, expired.PublicID}, nil).Once()
+		m.transferRepo.On("GetOwnershipTransferForUpdate", mock.Anything, m.txController, answered.PublicID).Return(answered, nil).Once()
+		m.walletRepo.On("SetOwnershipFrozen", mock.Anything, m.txController, wallet.ID, false, now).Return(wallet, nil).Once()
+		m.transferRepo.On("ResolveOwnershipTransfer", mock.Anything, m.txController, mock.MatchedBy(func(resolved *domain.OwnershipTransfer) bool {
+			return resolved.ID == expired.ID && resolved.Status == domain.OwnershipTransferExpired
+		})).Return(nil).Once()
+		var recorded domain.AuditOwnershipTransferPayload
+		expectAudit(m, &recorded)
+		m.txController.On("Commit").Return(nil).Once()
+
+		count, err := service.ExpireTransfers(context.Background(), now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, domain.OwnershipTransferExpired, recorded.Status)
+	})
+
+	t.Run("FrozenForTransferCannotBeDebited", func(t *testing.T) {
+		walletRepo, txController := new(MockWalletRepository), new(MockTxController)
+		walletService := NewWalletService(new(MockDBBeginner), new(MockDBExecutor), new(MockUserRepository), walletRepo, new(MockTransactionRepository),
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return txController, nil },
+			func(tx db.TxController) error { return txController.Commit() },
+			func(tx db.TxController) { _ = txController.Rollback() })
+		frozenAt := time.Now()
+		frozen := &domain.Wallet{ID: 1, UserID: 10, Currency: "EUR", Balance: decimal.NewFromInt(100), OwnershipFrozenAt: &frozenAt}
+		walletRepo.On("GetWalletByID", mock.Anything, txController, frozen.ID).Return(frozen, nil).Once()
+		txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := walletService.Withdraw(context.Background(), frozen.ID, decimal.NewFromInt(10), "EUR")
+
+		assert.ErrorIs(t, err, util.ErrOwnershipTransferFrozen)
+		walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
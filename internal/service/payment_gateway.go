@@ -0,0 +1,93 @@
+// internal/service/payment_gateway.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+)
+
+// ChargeRequest asks the payments gateway to collect money from a funding source.
+type ChargeRequest struct {
+	Provider       string // The funding source's payment method type
+	Reference      string // The gateway's token for the payment method
+	Amount         decimal.Decimal
+	Currency       string
+	IdempotencyKey string // A retried request with the same key is not charged twice
+}
+
+// PaymentGateway collects money from external payment methods, e.g. a card acquirer or a direct debit provider.
+type PaymentGateway interface {
+	// Charge collects the requested amount and returns the gateway's ID of the charge. A declined or failed
+	// charge returns an error; nothing was collected.
+	Charge(ctx context.Context, req ChargeRequest) (string, error)
+}
+
+// httpPaymentGateway is a PaymentGateway speaking the gateway's JSON API.
+type httpPaymentGateway struct {
+	url    string // Base URL; charges are created at url + "/charges"
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPPaymentGateway creates a PaymentGateway for the gateway API at url, authenticated with apiKey if set.
+func NewHTTPPaymentGateway(url, apiKey string, timeout time.Duration) PaymentGateway {
+	return &httpPaymentGateway{url: strings.TrimSuffix(url, "/"), apiKey: apiKey, client: &http.Client{Timeout: timeout}}
+}
+
+// gatewayCharge is a charge as returned by the gateway.
+type gatewayCharge struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"` // "succeeded" once the money is collected
+	FailureReason string `json:"failure_reason"`
+}
+
+// Charge implements PaymentGateway. Only a "succeeded" charge counts; a pending one is treated as failed,
+// as the wallet must not be credited with money that may never arrive.
+func (g *httpPaymentGateway) Charge(ctx context.Context, req ChargeRequest) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"payment_method": map[string]string{"type": req.Provider, "token": req.Reference},
+		"amount":         req.Amount.StringFixed(domain.CurrencyPrecision(req.Currency)),
+		"currency":       req.Currency,
+	})
+	if err != nil {
+		return "", fmt.Errorf("payment gateway: failed to encode charge: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.url+"/charges", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("payment gateway: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Idempotency-Key", req.IdempotencyKey)
+	if g.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("payment gateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var charge gatewayCharge
+	if resp.StatusCode >= 300 {
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&charge) // The reason is optional
+		return "", fmt.Errorf("payment gateway: charge rejected with status %d: %s", resp.StatusCode, charge.FailureReason)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&charge); err != nil {
+		return "", fmt.Errorf("payment gateway: failed to decode charge: %w", err)
+	}
+	if charge.Status != "succeeded" {
+		return "", fmt.Errorf("payment gateway: charge %s %s: %s", charge.ID, charge.Status, charge.FailureReason)
+	}
+	return charge.ID, nil
+}
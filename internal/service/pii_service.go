@@ -0,0 +1,94 @@
+// internal/service/pii_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/pkg/db"
+)
+
+// PIIReencryptionService re-encrypts users' personal data after the primary encryption key has been rotated,
+// so that retired keys can eventually be removed from the keyring.
+type PIIReencryptionService interface {
+	// Reencrypt re-encrypts, with the primary key, every user's contact details still encrypted with another
+	// key, and returns the number of users re-encrypted.
+	Reencrypt(ctx context.Context) (int, error)
+}
+
+// piiReencryptionService implements PIIReencryptionService.
+type piiReencryptionService struct {
+	dbBeginner db.DBTxBeginner
+	userRepo   repository.UserRepository
+	batchSize  int // Users re-encrypted per transaction
+	beginTx    db.BeginTxFunc
+	commitTx   db.CommitTxFunc
+	rollbackTx db.RollbackTxFunc
+	logger     *slog.Logger
+}
+
+// NewPIIReencryptionService creates a new instance of PIIReencryptionService.
+func NewPIIReencryptionService(
+	dbBeginner db.DBTxBeginner,
+	userRepo repository.UserRepository,
+	batchSize int,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) PIIReencryptionService {
+	return &piiReencryptionService{
+		dbBeginner: dbBeginner,
+		userRepo:   userRepo,
+		batchSize:  batchSize,
+		beginTx:    beginTx,
+		commitTx:   commitTx,
+		rollbackTx: rollbackTx,
+		logger:     logger,
+	}
+}
+
+// Reencrypt commits one transaction per batch, so a run over many users neither holds locks for long nor
+// loses its progress on failure. It stops at the first batch short of the batch size; rows locked by a
+// concurrent run are skipped and left to that run.
+func (s *piiReencryptionService) Reencrypt(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := s.reencryptBatch(ctx)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < s.batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		s.logger.Info("Personal data re-encrypted with the primary key", "users", total)
+	}
+	return total, nil
+}
+
+func (s *piiReencryptionService) reencryptBatch(ctx context.Context) (int, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return 0, fmt.Errorf("re-encrypt personal data: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return 0, fmt.Errorf("re-encrypt personal data: transaction controller does not implement DBExecutor")
+	}
+
+	n, err := s.userRepo.ReencryptPII(ctx, txExecutor, s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("re-encrypt personal data: %w", err)
+	}
+	if err := s.commitTx(txController); err != nil {
+		return 0, fmt.Errorf("re-encrypt personal data: failed to commit transaction: %w", err)
+	}
+	return n, nil
+}
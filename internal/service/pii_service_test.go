@@ -0,0 +1,132 @@
+// internal/service/pii_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestSetUserContact tests the normalization of contact details and their merging with the stored ones.
+func TestSetUserContact(t *testing.T) {
+	newWalletService := func(userRepo *MockUserRepository, dbExecutor *MockDBExecutor) WalletService {
+		return NewWalletService(new(MockDBBeginner), dbExecutor, userRepo, new(MockWalletRepository), new(MockTransactionRepository), nil, nil, nil)
+	}
+
+	t.Run("Normalize", func(t *testing.T) {
+		email, err := domain.NormalizeEmail(" Alice.Smith@Example.COM ")
+		assert.NoError(t, err)
+		assert.Equal(t, "Alice.Smith@example.com", email)
+		phone, err := domain.NormalizePhone("+49 (151) 123-456.78")
+		assert.NoError(t, err)
+		assert.Equal(t, "+4915112345678", phone)
+
+		for _, invalid := range []string{"", "alice", "@example.com", "alice@localhost", "a@b@example.com"} {
+			_, err := domain.NormalizeEmail(invalid)
+			assert.Error(t, err, invalid)
+		}
+		for _, invalid := range []string{"", "015112345678", "+0151123456", "+1234567", "+49151abc4567"} {
+			_, err := domain.NormalizePhone(invalid)
+			assert.Error(t, err, invalid)
+		}
+	})
+
+	t.Run("KeepsOmittedAndClearsEmpty", func(t *testing.T) {
+		ctx := context.Background()
+		userRepo, dbExecutor := new(MockUserRepository), new(MockDBExecutor)
+		service := newWalletService(userRepo, dbExecutor)
+		email, phone := "alice@example.com", "+4915112345678"
+		user := &domain.User{ID: 1, Username: "alice", Email: &email, Phone: &phone}
+
+		userRepo.On("GetUserByID", ctx, dbExecutor, int64(1)).Return(user, nil).Once()
+		userRepo.On("UpdateUserContact", ctx, dbExecutor, int64(1), &email, (*string)(nil)).Return(&domain.User{ID: 1, Email: &email}, nil).Once()
+
+		empty := ""
+		updated, err := service.SetUserContact(ctx, 1, nil, &empty)
+
+		assert.NoError(t, err)
+		assert.Nil(t, updated.Phone)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidPhoneIsRejected", func(t *testing.T) {
+		ctx := context.Background()
+		userRepo, dbExecutor := new(MockUserRepository), new(MockDBExecutor)
+		service := newWalletService(userRepo, dbExecutor)
+
+		userRepo.On("GetUserByID", ctx, dbExecutor, int64(1)).Return(&domain.User{ID: 1}, nil).Once()
+
+		phone := "0151 1234"
+		_, err := service.SetUserContact(ctx, 1, nil, &phone)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		userRepo.AssertNotCalled(t, "UpdateUserContact", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestPIIReencryptionService tests that re-encryption runs in batches until a batch comes back short.
+func TestPIIReencryptionService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newService := func() (PIIReencryptionService, *MockUserRepository, *MockTxController) {
+		userRepo := new(MockUserRepository)
+		txController := new(MockTxController)
+		service := NewPIIReencryptionService(
+			new(MockDBBeginner),
+			userRepo,
+			2,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return txController, nil
+			},
+			func(tx db.TxController) error {
+				return txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = txController.Rollback()
+			},
+			logger,
+		)
+		return service, userRepo, txController
+	}
+
+	t.Run("BatchesUntilShort", func(t *testing.T) {
+		ctx := context.Background()
+		service, userRepo, txController := newService()
+
+		userRepo.On("ReencryptPII", ctx, txController, 2).Return(2, nil).Twice()
+		userRepo.On("ReencryptPII", ctx, txController, 2).Return(1, nil).Once()
+		txController.On("Commit").Return(nil).Times(3)
+		txController.On("Rollback").Return(nil).Maybe()
+
+		reencrypted, err := service.Reencrypt(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, reencrypted)
+		userRepo.AssertExpectations(t)
+		txController.AssertExpectations(t)
+	})
+
+	t.Run("FailureKeepsCommittedBatches", func(t *testing.T) {
+		ctx := context.Background()
+		service, userRepo, txController := newService()
+
+		userRepo.On("ReencryptPII", ctx, txController, 2).Return(2, nil).Once()
+		userRepo.On("ReencryptPII", ctx, txController, 2).Return(0, errors.New("cipher: message authentication failed")).Once()
+		txController.On("Commit").Return(nil).Once()
+		txController.On("Rollback").Return(nil).Maybe()
+
+		reencrypted, err := service.Reencrypt(ctx)
+
+		assert.Error(t, err)
+		assert.Equal(t, 2, reencrypted)
+		txController.AssertExpectations(t)
+	})
+}
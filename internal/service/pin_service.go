@@ -0,0 +1,223 @@
+// internal/service/pin_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+const (
+	// maxPINAttempts failed attempts in a row lock the PIN for pinLockout.
+	maxPINAttempts = 5
+	pinLockout     = 15 * time.Minute
+	// pinStepUpWindow is how long a verification of the PIN confirms operations without it.
+	pinStepUpWindow = 5 * time.Minute
+)
+
+// PINService defines the interface for the optional transaction PINs of users. Once a user sets a PIN, their
+// withdrawals and transfers must be confirmed with it, or follow a recent verification of it.
+type PINService interface {
+	// GetPIN retrieves the user's PIN, or nil if they have not set one.
+	GetPIN(ctx context.Context, userID int64) (*domain.UserPIN, error)
+	// SetPIN sets the user's PIN. Changing an existing PIN requires the current one.
+	SetPIN(ctx context.Context, userID int64, pin, currentPIN string) (*domain.UserPIN, error)
+	// DeletePIN removes the user's PIN, confirmed with the current one.
+	DeletePIN(ctx context.Context, userID int64, currentPIN string) error
+	// StepUp verifies the user's PIN, so their operations are confirmed without it for a short while.
+	StepUp(ctx context.Context, userID int64, pin string) (*domain.UserPIN, error)
+	// Confirm confirms an operation of the user with their PIN. It passes if the user has no PIN or has
+	// recently stepped up, and otherwise requires the right PIN.
+	Confirm(ctx context.Context, userID int64, pin string) error
+}
+
+// pinService implements PINService.
+type pinService struct {
+	dbBeginner db.DBTxBeginner
+	dbExecutor repository.DBExecutor
+	pinRepo    repository.PINRepository
+	userRepo   repository.UserRepository
+	beginTx    db.BeginTxFunc
+	commitTx   db.CommitTxFunc
+	rollbackTx db.RollbackTxFunc
+	logger     *slog.Logger
+}
+
+// NewPINService creates a new instance of PINService.
+func NewPINService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	pinRepo repository.PINRepository,
+	userRepo repository.UserRepository,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) PINService {
+	return &pinService{
+		dbBeginner: dbBeginner,
+		dbExecutor: dbExecutor,
+		pinRepo:    pinRepo,
+		userRepo:   userRepo,
+		beginTx:    beginTx,
+		commitTx:   commitTx,
+		rollbackTx: rollbackTx,
+		logger:     logger,
+	}
+}
+
+// GetPIN yields util.ErrUserNotFound for an unknown user.
+func (s *pinService) GetPIN(ctx context.Context, userID int64) (*domain.UserPIN, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, userID); err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get PIN: failed to get user %d: %w", userID, err)
+	}
+	pin, err := s.pinRepo.GetPIN(ctx, s.dbExecutor, userID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get PIN: %w", err)
+	}
+	return pin, nil
+}
+
+// SetPIN stores only a hash of the PIN. A wrong current PIN counts towards the lockout like any other attempt.
+func (s *pinService) SetPIN(ctx context.Context, userID int64, pin, currentPIN string) (*domain.UserPIN, error) {
+	if err := domain.ValidatePIN(pin); err != nil {
+		return nil, fmt.Errorf("%w: %v", util.ErrInvalidInput, err)
+	}
+	existing, err := s.GetPIN(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if currentPIN == "" {
+			return nil, util.ErrPINRequired
+		}
+		if _, err := s.attempt(ctx, userID, currentPIN, false); err != nil {
+			return nil, err
+		}
+	}
+
+	hash, err := domain.HashPIN(pin)
+	if err != nil {
+		return nil, fmt.Errorf("set PIN: %w", err)
+	}
+	userPIN := &domain.UserPIN{UserID: userID, PINHash: hash, UpdatedAt: time.Now().UTC()}
+	if err := s.pinRepo.SavePIN(ctx, s.dbExecutor, userPIN); err != nil {
+		return nil, fmt.Errorf("set PIN: %w", err)
+	}
+	s.logger.Info("Transaction PIN set", "user_id", userID, "changed", existing != nil)
+	return userPIN, nil
+}
+
+// DeletePIN yields util.ErrNotFound if the user has no PIN.
+func (s *pinService) DeletePIN(ctx context.Context, userID int64, currentPIN string) error {
+	if currentPIN == "" {
+		return util.ErrPINRequired
+	}
+	if _, err := s.attempt(ctx, userID, currentPIN, false); err != nil {
+		return err
+	}
+	if err := s.pinRepo.DeletePIN(ctx, s.dbExecutor, userID); err != nil {
+		return fmt.Errorf("delete PIN: %w", err)
+	}
+	s.logger.Info("Transaction PIN deleted", "user_id", userID)
+	return nil
+}
+
+// StepUp yields util.ErrNotFound if the user has no PIN.
+func (s *pinService) StepUp(ctx context.Context, userID int64, pin string) (*domain.UserPIN, error) {
+	if pin == "" {
+		return nil, util.ErrPINRequired
+	}
+	return s.attempt(ctx, userID, pin, true)
+}
+
+// Confirm reads the PIN without locking it, so users without a PIN or within a step-up window pay no
+// transaction for the check.
+func (s *pinService) Confirm(ctx context.Context, userID int64, pin string) error {
+	userPIN, err := s.pinRepo.GetPIN(ctx, s.dbExecutor, userID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("confirm PIN: %w", err)
+	}
+	now := time.Now().UTC()
+	if userPIN.SteppedUp(now) {
+		return nil
+	}
+	if userPIN.Locked(now) {
+		return util.ErrPINLocked
+	}
+	if pin == "" {
+		return util.ErrPINRequired
+	}
+	_, err = s.attempt(ctx, userID, pin, false)
+	return err
+}
+
+// attempt checks pin against the user's PIN under a row lock, so concurrent attempts cannot outrun the
+// lockout. A failure is counted and committed even though the attempt fails; the last allowed failure locks
+// the PIN for pinLockout and ends any step-up. A success resets the count and, with stepUp, opens a step-up
+// window.
+func (s *pinService) attempt(ctx context.Context, userID int64, pin string, stepUp bool) (*domain.UserPIN, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("verify PIN: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("verify PIN: transaction controller does not implement DBExecutor")
+	}
+
+	userPIN, err := s.pinRepo.GetPINForUpdate(ctx, txExecutor, userID)
+	if err != nil {
+		return nil, fmt.Errorf("verify PIN: %w", err)
+	}
+	now := time.Now().UTC()
+	if userPIN.Locked(now) {
+		return nil, util.ErrPINLocked
+	}
+
+	var verifyErr error
+	if domain.VerifyPIN(userPIN.PINHash, pin) {
+		userPIN.FailedAttempts, userPIN.LockedUntil = 0, nil
+		if stepUp {
+			until := now.Add(pinStepUpWindow)
+			userPIN.StepUpUntil = &until
+		}
+	} else {
+		userPIN.FailedAttempts++
+		verifyErr = util.ErrInvalidPIN
+		if userPIN.FailedAttempts >= maxPINAttempts {
+			lockedUntil := now.Add(pinLockout)
+			userPIN.FailedAttempts, userPIN.LockedUntil, userPIN.StepUpUntil = 0, &lockedUntil, nil
+			verifyErr = util.ErrPINLocked
+			s.logger.Warn("Transaction PIN locked after failed attempts", "user_id", userID, "locked_until", lockedUntil)
+		}
+	}
+	userPIN.UpdatedAt = now
+	if err := s.pinRepo.UpdateAttempts(ctx, txExecutor, userPIN); err != nil {
+		return nil, fmt.Errorf("verify PIN: %w", err)
+	}
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("verify PIN: failed to commit transaction: %w", err)
+	}
+	if verifyErr != nil {
+		return nil, verifyErr
+	}
+	return userPIN, nil
+}
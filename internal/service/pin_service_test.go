@@ -0,0 +1,216 @@
+// internal/service/pin_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestPINService tests setting transaction PINs, confirming operations with them and their lockout.
+func TestPINService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	hash, err := domain.HashPIN("4821")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := int64(10)
+
+	type mocks struct {
+		pinRepo      *MockPINRepository
+		userRepo     *MockUserRepository
+		dbExecutor   *MockDBExecutor
+		txController *MockTxController
+	}
+	newService := func() (PINService, mocks) {
+		m := mocks{
+			pinRepo:      new(MockPINRepository),
+			userRepo:     new(MockUserRepository),
+			dbExecutor:   new(MockDBExecutor),
+			txController: new(MockTxController),
+		}
+		service := NewPINService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.pinRepo,
+			m.userRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+
+	t.Run("HashAndVerifyPIN", func(t *testing.T) {
+		assert.True(t, domain.VerifyPIN(hash, "4821"))
+		assert.False(t, domain.VerifyPIN(hash, "4822"))
+		assert.False(t, domain.VerifyPIN("4821", "4821"))
+		assert.NotContains(t, hash, "4821")
+
+		assert.NoError(t, domain.ValidatePIN("0000"))
+		assert.Error(t, domain.ValidatePIN("123"))
+		assert.Error(t, domain.ValidatePIN("123456789"))
+		assert.Error(t, domain.ValidatePIN("12a4"))
+	})
+
+	t.Run("ConfirmWithoutPINSet", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.pinRepo.On("GetPIN", ctx, m.dbExecutor, userID).Return(nil, util.ErrNotFound).Once()
+
+		assert.NoError(t, service.Confirm(ctx, userID, ""))
+	})
+
+	t.Run("ConfirmRequiresPIN", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.pinRepo.On("GetPIN", ctx, m.dbExecutor, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash}, nil).Once()
+
+		assert.ErrorIs(t, service.Confirm(ctx, userID, ""), util.ErrPINRequired)
+	})
+
+	t.Run("ConfirmWithinStepUpWindow", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		until := time.Now().Add(time.Minute)
+
+		m.pinRepo.On("GetPIN", ctx, m.dbExecutor, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash, StepUpUntil: &until}, nil).Once()
+
+		assert.NoError(t, service.Confirm(ctx, userID, ""))
+		m.pinRepo.AssertNotCalled(t, "GetPINForUpdate", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ConfirmWithRightPINResetsFailures", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.pinRepo.On("GetPIN", ctx, m.dbExecutor, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash}, nil).Once()
+		m.pinRepo.On("GetPINForUpdate", ctx, m.txController, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash, FailedAttempts: 2}, nil).Once()
+		m.pinRepo.On("UpdateAttempts", ctx, m.txController, mock.MatchedBy(func(pin *domain.UserPIN) bool {
+			return pin.FailedAttempts == 0 && pin.LockedUntil == nil && pin.StepUpUntil == nil
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		assert.NoError(t, service.Confirm(ctx, userID, "4821"))
+		m.pinRepo.AssertExpectations(t)
+	})
+
+	t.Run("WrongPINIsCountedAndCommitted", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.pinRepo.On("GetPIN", ctx, m.dbExecutor, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash}, nil).Once()
+		m.pinRepo.On("GetPINForUpdate", ctx, m.txController, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash, FailedAttempts: 1}, nil).Once()
+		m.pinRepo.On("UpdateAttempts", ctx, m.txController, mock.MatchedBy(func(pin *domain.UserPIN) bool {
+			return pin.FailedAttempts == 2 && pin.LockedUntil == nil
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		assert.ErrorIs(t, service.Confirm(ctx, userID, "0000"), util.ErrInvalidPIN)
+		m.txController.AssertExpectations(t)
+	})
+
+	t.Run("LastFailedAttemptLocks", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.pinRepo.On("GetPIN", ctx, m.dbExecutor, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash}, nil).Once()
+		m.pinRepo.On("GetPINForUpdate", ctx, m.txController, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash, FailedAttempts: maxPINAttempts - 1}, nil).Once()
+		m.pinRepo.On("UpdateAttempts", ctx, m.txController, mock.MatchedBy(func(pin *domain.UserPIN) bool {
+			return pin.FailedAttempts == 0 && pin.LockedUntil != nil && pin.LockedUntil.After(time.Now().Add(pinLockout-time.Minute))
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		assert.ErrorIs(t, service.Confirm(ctx, userID, "0000"), util.ErrPINLocked)
+		m.pinRepo.AssertExpectations(t)
+	})
+
+	t.Run("LockedPINRejectsEvenTheRightPIN", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		lockedUntil := time.Now().Add(time.Minute)
+		locked := &domain.UserPIN{UserID: userID, PINHash: hash, LockedUntil: &lockedUntil}
+
+		m.pinRepo.On("GetPIN", ctx, m.dbExecutor, userID).Return(locked, nil).Once()
+		m.pinRepo.On("GetPINForUpdate", ctx, m.txController, userID).Return(locked, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		assert.ErrorIs(t, service.Confirm(ctx, userID, "4821"), util.ErrPINLocked)
+		m.pinRepo.AssertNotCalled(t, "UpdateAttempts", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("StepUpOpensWindow", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.pinRepo.On("GetPINForUpdate", ctx, m.txController, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash}, nil).Once()
+		m.pinRepo.On("UpdateAttempts", ctx, m.txController, mock.AnythingOfType("*domain.UserPIN")).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		pin, err := service.StepUp(ctx, userID, "4821")
+
+		assert.NoError(t, err)
+		assert.True(t, pin.SteppedUp(time.Now()))
+		assert.False(t, pin.SteppedUp(time.Now().Add(pinStepUpWindow+time.Second)))
+	})
+
+	t.Run("ChangingPINRequiresCurrentPIN", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, userID).Return(&domain.User{ID: userID}, nil).Once()
+		m.pinRepo.On("GetPIN", ctx, m.dbExecutor, userID).Return(&domain.UserPIN{UserID: userID, PINHash: hash}, nil).Once()
+
+		_, err := service.SetPIN(ctx, userID, "1357", "")
+
+		assert.ErrorIs(t, err, util.ErrPINRequired)
+		m.pinRepo.AssertNotCalled(t, "SavePIN", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("FirstPINIsStoredHashed", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, userID).Return(&domain.User{ID: userID}, nil).Once()
+		m.pinRepo.On("GetPIN", ctx, m.dbExecutor, userID).Return(nil, util.ErrNotFound).Once()
+		m.pinRepo.On("SavePIN", ctx, m.dbExecutor, mock.MatchedBy(func(pin *domain.UserPIN) bool {
+			return pin.UserID == userID && domain.VerifyPIN(pin.PINHash, "1357")
+		})).Return(nil).Once()
+
+		_, err := service.SetPIN(ctx, userID, "1357", "")
+
+		assert.NoError(t, err)
+		m.pinRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidPINIsRejected", func(t *testing.T) {
+		service, m := newService()
+
+		_, err := service.SetPIN(context.Background(), userID, "12", "")
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.pinRepo.AssertNotCalled(t, "SavePIN", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,97 @@
+// internal/service/policy.go
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// SubjectKind tells who is acting.
+type SubjectKind string
+
+const (
+	SubjectAnonymous SubjectKind = "anonymous" // No identity was established, e.g. the public API without user authentication
+	SubjectUser      SubjectKind = "user"
+	SubjectSystem    SubjectKind = "system" // Background jobs and operators
+)
+
+// Subject is who performs an operation, as established by the caller's authentication.
+type Subject struct {
+	Kind   SubjectKind `json:"kind"`
+	UserID int64       `json:"user_id,omitempty"` // Set for SubjectUser
+}
+
+type subjectKey struct{}
+
+// WithSubject attaches the acting subject to ctx; the authorization policy is evaluated against it.
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext returns the subject attached to ctx, or an anonymous subject.
+func SubjectFromContext(ctx context.Context) Subject {
+	if subject, ok := ctx.Value(subjectKey{}).(Subject); ok {
+		return subject
+	}
+	return Subject{Kind: SubjectAnonymous}
+}
+
+// PolicyAction names a wallet operation checked by the authorization policy.
+type PolicyAction string
+
+const (
+	ActionDeposit       PolicyAction = "wallet.deposit"
+	ActionWithdraw      PolicyAction = "wallet.withdraw"
+	ActionTransfer      PolicyAction = "wallet.transfer"
+	ActionSplitTransfer PolicyAction = "wallet.split_transfer"
+	ActionReadBalance   PolicyAction = "wallet.read_balance"
+	ActionReadHistory   PolicyAction = "wallet.read_history"
+)
+
+// PolicyRequest describes an operation about to be performed.
+type PolicyRequest struct {
+	Subject      Subject
+	Action       PolicyAction
+	Resource     *domain.Wallet  // The wallet operated on; the source of a transfer
+	Counterparty *domain.Wallet  // The destination of a transfer; nil otherwise
+	Amount       decimal.Decimal // Zero for reads
+}
+
+// Policy decides whether a subject may perform an operation. WalletService evaluates it before every
+// deposit, withdrawal, transfer, balance read and history read, after loading the wallet involved.
+type Policy interface {
+	// Authorize returns nil to permit the operation, an error wrapping util.ErrForbidden to deny it,
+	// or another error if no decision could be made, which fails the operation.
+	Authorize(ctx context.Context, req PolicyRequest) error
+}
+
+// AllowOwnerPolicy is the default policy: users may only operate on their own wallets. Anonymous and
+// system subjects are permitted, since the public API does not authenticate users itself.
+type AllowOwnerPolicy struct{}
+
+// Authorize implements Policy.
+func (AllowOwnerPolicy) Authorize(ctx context.Context, req PolicyRequest) error {
+	if req.Subject.Kind == SubjectUser && req.Resource.UserID != req.Subject.UserID {
+		return fmt.Errorf("%w: %s on another user's wallet", util.ErrForbidden, req.Action)
+	}
+	return nil
+}
+
+// authorize evaluates the service's policy for an operation on wallet.
+func (s *walletService) authorize(ctx context.Context, action PolicyAction, wallet, counterparty *domain.Wallet, amount decimal.Decimal) error {
+	if s.policy == nil {
+		return nil
+	}
+	return s.policy.Authorize(ctx, PolicyRequest{
+		Subject:      SubjectFromContext(ctx),
+		Action:       action,
+		Resource:     wallet,
+		Counterparty: counterparty,
+		Amount:       amount,
+	})
+}
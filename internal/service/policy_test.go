@@ -0,0 +1,120 @@
+// internal/service/policy_test.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// denyingPolicy denies every operation and records the requests it saw.
+type denyingPolicy struct {
+	requests []PolicyRequest
+}
+
+func (p *denyingPolicy) Authorize(ctx context.Context, req PolicyRequest) error {
+	p.requests = append(p.requests, req)
+	return util.ErrForbidden
+}
+
+// TestAuthorizationPolicy tests the default policy, the OPA policy and their evaluation by the wallet service.
+func TestAuthorizationPolicy(t *testing.T) {
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 7, Currency: "USD", Balance: decimal.NewFromInt(50)}
+
+	t.Run("AllowOwnerPolicy", func(t *testing.T) {
+		policy := AllowOwnerPolicy{}
+		ctx := context.Background()
+		amount := decimal.NewFromInt(5)
+
+		assert.NoError(t, policy.Authorize(ctx, PolicyRequest{Subject: Subject{Kind: SubjectUser, UserID: 7}, Action: ActionWithdraw, Resource: wallet, Amount: amount}))
+		assert.ErrorIs(t, policy.Authorize(ctx, PolicyRequest{Subject: Subject{Kind: SubjectUser, UserID: 8}, Action: ActionWithdraw, Resource: wallet, Amount: amount}), util.ErrForbidden)
+		assert.NoError(t, policy.Authorize(ctx, PolicyRequest{Subject: SubjectFromContext(ctx), Action: ActionWithdraw, Resource: wallet, Amount: amount}))
+	})
+
+	t.Run("OPAPolicy", func(t *testing.T) {
+		var input opaInput
+		decision := `{"result": true}`
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Input opaInput `json:"input"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			input = body.Input
+			_, _ = w.Write([]byte(decision))
+		}))
+		defer server.Close()
+		policy := NewOPAPolicy(server.URL, time.Second)
+		req := PolicyRequest{Subject: Subject{Kind: SubjectUser, UserID: 7}, Action: ActionTransfer, Resource: wallet, Amount: decimal.NewFromFloat(12.5)}
+
+		assert.NoError(t, policy.Authorize(context.Background(), req))
+		assert.Equal(t, ActionTransfer, input.Action)
+		assert.Equal(t, wallet.PublicID, input.Resource.ID)
+		assert.Equal(t, "12.5", input.Amount)
+
+		decision = `{"result": false}`
+		assert.ErrorIs(t, policy.Authorize(context.Background(), req), util.ErrForbidden)
+
+		decision = `{}` // Undefined decision
+		assert.ErrorIs(t, policy.Authorize(context.Background(), req), util.ErrForbidden)
+	})
+
+	t.Run("OPAPolicyUnreachableFails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		err := NewOPAPolicy(server.URL, time.Second).Authorize(context.Background(), PolicyRequest{Action: ActionDeposit, Resource: wallet})
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, util.ErrForbidden)
+	})
+
+	t.Run("WalletServiceEvaluatesPolicyBeforeWithdrawal", func(t *testing.T) {
+		ctx := WithSubject(context.Background(), Subject{Kind: SubjectUser, UserID: 7})
+		walletRepo := new(MockWalletRepository)
+		txController := new(MockTxController)
+		policy := &denyingPolicy{}
+		service := NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
+			walletRepo,
+			new(MockTransactionRepository),
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return txController, nil
+			},
+			func(tx db.TxController) error {
+				return txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = txController.Rollback()
+			},
+			WithPolicy(policy),
+		)
+
+		walletRepo.On("GetWalletByID", ctx, txController, wallet.ID).Return(wallet, nil).Once()
+		txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := service.Withdraw(ctx, wallet.ID, decimal.NewFromInt(5), "USD")
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		if assert.Len(t, policy.requests, 1) {
+			assert.Equal(t, ActionWithdraw, policy.requests[0].Action)
+			assert.Equal(t, Subject{Kind: SubjectUser, UserID: 7}, policy.requests[0].Subject)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+// internal/service/precondition.go
+package service
+
+import (
+	"context"
+	"slices"
+
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+type ifMatchKey struct{}
+
+// WithIfMatch attaches the entity tags of an If-Match request header to ctx.
+// Money-moving operations then only proceed if the affected wallet still has one of those tags.
+func WithIfMatch(ctx context.Context, etags []string) context.Context {
+	if len(etags) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, ifMatchKey{}, etags)
+}
+
+// checkWalletPrecondition enforces an If-Match precondition carried in ctx, if any.
+// The wallet row is locked first, so its version cannot change between the check and the balance update.
+func (s *walletService) checkWalletPrecondition(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	etags, ok := ctx.Value(ifMatchKey{}).([]string)
+	if !ok {
+		return nil
+	}
+
+	wallet, err := s.walletRepo.GetWalletByIDForUpdate(ctx, q, walletID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(etags, "*") || slices.Contains(etags, wallet.ETag()) {
+		return nil
+	}
+	return util.ErrPreconditionFailed
+}
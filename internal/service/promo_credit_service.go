@@ -0,0 +1,92 @@
+// internal/service/promo_credit_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// PromoCreditService defines the interface for granting promotional credits and expiring them.
+// Spending credits is done by WalletService, configured WithPromoCredits.
+type PromoCreditService interface {
+	// GrantCredit validates and stores a new grant to the credit's wallet.
+	GrantCredit(ctx context.Context, credit *domain.PromoCredit) error
+	ListCredits(ctx context.Context, walletID int64) ([]domain.PromoCredit, error)
+	// ExpireCredits expires every active grant past its expiry at now and returns the number expired.
+	ExpireCredits(ctx context.Context, now time.Time) (int, error)
+}
+
+// promoCreditService implements PromoCreditService.
+type promoCreditService struct {
+	dbExecutor repository.DBExecutor
+	promoRepo  repository.PromoCreditRepository
+	logger     *slog.Logger
+}
+
+// NewPromoCreditService creates a new instance of PromoCreditService.
+func NewPromoCreditService(
+	dbExecutor repository.DBExecutor,
+	promoRepo repository.PromoCreditRepository,
+	logger *slog.Logger,
+) PromoCreditService {
+	return &promoCreditService{
+		dbExecutor: dbExecutor,
+		promoRepo:  promoRepo,
+		logger:     logger,
+	}
+}
+
+// GrantCredit stores a new grant with its full amount remaining.
+func (s *promoCreditService) GrantCredit(ctx context.Context, credit *domain.PromoCredit) error {
+	if !credit.Amount.IsPositive() {
+		return fmt.Errorf("%w: amount must be positive", util.ErrInvalidInput)
+	}
+	now := time.Now().UTC()
+	if !credit.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expires_at must be in the future", util.ErrInvalidInput)
+	}
+
+	credit.PublicID = uuid.New()
+	credit.Remaining = credit.Amount
+	credit.Status = domain.PromoCreditStatusActive
+	credit.ExpiresAt = credit.ExpiresAt.UTC()
+	credit.CreatedAt = now
+	credit.UpdatedAt = now
+	if err := s.promoRepo.CreateCredit(ctx, s.dbExecutor, credit); err != nil {
+		return fmt.Errorf("grant promo credit: %w", err)
+	}
+	s.logger.Info("Promo credit granted", "credit_id", credit.PublicID, "wallet_id", credit.WalletID,
+		"amount", credit.Amount.String(), "expires_at", credit.ExpiresAt)
+	return nil
+}
+
+// ListCredits retrieves all grants of a wallet, including spent and expired ones.
+func (s *promoCreditService) ListCredits(ctx context.Context, walletID int64) ([]domain.PromoCredit, error) {
+	credits, err := s.promoRepo.ListCreditsByWallet(ctx, s.dbExecutor, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("list promo credits: %w", err)
+	}
+	return credits, nil
+}
+
+// ExpireCredits expires all due grants in a single statement, so a grant being spent concurrently is either
+// spent first or expired first, never both.
+func (s *promoCreditService) ExpireCredits(ctx context.Context, now time.Time) (int, error) {
+	expired, err := s.promoRepo.ExpireCredits(ctx, s.dbExecutor, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("expire promo credits: %w", err)
+	}
+	for _, credit := range expired {
+		s.logger.Info("Promo credit expired", "credit_id", credit.PublicID, "wallet_id", credit.WalletID,
+			"forfeited", credit.Remaining.String())
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,72 @@
+// internal/service/promo_credit_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestPromoCreditService tests granting promotional credits and the expiry job.
+func TestPromoCreditService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	newService := func() (PromoCreditService, *MockPromoCreditRepository, *MockDBExecutor) {
+		promoRepo := new(MockPromoCreditRepository)
+		dbExecutor := new(MockDBExecutor)
+		return NewPromoCreditService(dbExecutor, promoRepo, logger), promoRepo, dbExecutor
+	}
+
+	t.Run("GrantStartsFullyRemaining", func(t *testing.T) {
+		ctx := context.Background()
+		service, promoRepo, dbExecutor := newService()
+		credit := &domain.PromoCredit{WalletID: 1, Amount: decimal.NewFromInt(10), ExpiresAt: time.Now().Add(24 * time.Hour)}
+
+		promoRepo.On("CreateCredit", ctx, dbExecutor, credit).Return(nil).Once()
+
+		err := service.GrantCredit(ctx, credit)
+
+		assert.NoError(t, err)
+		assert.True(t, credit.Remaining.Equal(credit.Amount))
+		assert.Equal(t, domain.PromoCreditStatusActive, credit.Status)
+		promoRepo.AssertExpectations(t)
+	})
+
+	t.Run("GrantRejectsInvalidInput", func(t *testing.T) {
+		service, promoRepo, _ := newService()
+
+		err := service.GrantCredit(context.Background(), &domain.PromoCredit{WalletID: 1, Amount: decimal.Zero, ExpiresAt: time.Now().Add(time.Hour)})
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+
+		err = service.GrantCredit(context.Background(), &domain.PromoCredit{WalletID: 1, Amount: decimal.NewFromInt(5), ExpiresAt: time.Now().Add(-time.Hour)})
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+
+		promoRepo.AssertNotCalled(t, "CreateCredit", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ExpireReportsExpiredCount", func(t *testing.T) {
+		ctx := context.Background()
+		service, promoRepo, dbExecutor := newService()
+		now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		promoRepo.On("ExpireCredits", ctx, dbExecutor, now).Return([]domain.PromoCredit{
+			{ID: 1, Remaining: decimal.NewFromInt(3), Status: domain.PromoCreditStatusExpired},
+			{ID: 2, Remaining: decimal.Zero, Status: domain.PromoCreditStatusExpired},
+		}, nil).Once()
+
+		expired, err := service.ExpireCredits(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, expired)
+		promoRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,61 @@
+// internal/service/push.go
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"finflow-wallet/internal/domain"
+)
+
+// PushSender delivers notifications to devices through a push service, e.g. FCM or APNs.
+type PushSender interface {
+	// Send delivers the notification to the device and returns the push service's ID of the message. A token
+	// the service no longer knows returns util.ErrPushTokenInvalid, and a message it refuses for good
+	// util.ErrPushRejected; any other error is transient.
+	Send(ctx context.Context, token string, notification *domain.Notification) (string, error)
+}
+
+// signJWT returns the compact serialization of a JWT with the given header and claims, signed with an RSA key
+// (RS256) or an ECDSA P-256 key (ES256) as the header's "alg" says.
+func signJWT(header, claims map[string]any, key crypto.Signer) (string, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		// JWS wants the raw r || s of 32 bytes each rather than the ASN.1 encoding
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		if err == nil {
+			signature = make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+		}
+	default:
+		return "", fmt.Errorf("unsupported JWT signing key %T", key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
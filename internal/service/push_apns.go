@@ -0,0 +1,142 @@
+// internal/service/push_apns.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+const (
+	apnsEndpoint        = "https://api.push.apple.com"
+	apnsSandboxEndpoint = "https://api.sandbox.push.apple.com"
+	// apnsTokenLifetime is how long a provider token is reused. APNs rejects tokens older than an hour and
+	// throttles providers that renew them more often than every 20 minutes.
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// apnsSender is a PushSender using the APNs HTTP/2 API with token-based authentication.
+type apnsSender struct {
+	key      crypto.Signer
+	keyID    string
+	teamID   string
+	topic    string // The app's bundle ID
+	endpoint string
+	client   *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender creates a PushSender for APNs from a .p8 signing key of the Apple developer team. The sandbox
+// environment serves development builds of the app.
+func NewAPNsSender(key []byte, keyID, teamID, topic string, sandbox bool, timeout time.Duration) (PushSender, error) {
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, fmt.Errorf("apns: key ID, team ID and topic are required")
+	}
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, fmt.Errorf("apns: signing key is not PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("apns: invalid signing key: %w", err)
+	}
+	signer, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("apns: signing key must be an ECDSA key, not %T", parsed)
+	}
+	endpoint := apnsEndpoint
+	if sandbox {
+		endpoint = apnsSandboxEndpoint
+	}
+	// The default transport negotiates HTTP/2, which APNs requires
+	return &apnsSender{key: signer, keyID: keyID, teamID: teamID, topic: topic, endpoint: endpoint, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// providerToken returns the current provider token, signing a new one once it reaches apnsTokenLifetime.
+func (s *apnsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.token != "" && now.Before(s.issuedAt.Add(apnsTokenLifetime)) {
+		return s.token, nil
+	}
+	token, err := signJWT(map[string]any{"alg": "ES256", "kid": s.keyID}, map[string]any{"iss": s.teamID, "iat": now.Unix()}, s.key)
+	if err != nil {
+		return "", fmt.Errorf("apns: %w", err)
+	}
+	s.token, s.issuedAt = token, now
+	return token, nil
+}
+
+// Send implements PushSender. The notification's data is passed to the app as custom keys beside "aps".
+// APNs reports an unknown token as BadDeviceToken, DeviceTokenNotForTopic or, with status 410, Unregistered.
+func (s *apnsSender) Send(ctx context.Context, token string, notification *domain.Notification) (string, error) {
+	providerToken, err := s.providerToken()
+	if err != nil {
+		return "", err
+	}
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{"title": notification.Title, "body": notification.Body},
+			"sound": "default",
+		},
+	}
+	for key, value := range notification.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("apns: failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/3/device/"+url.PathEscape(token), bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("apns: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", s.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("apns: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return resp.Header.Get("apns-id"), nil
+	}
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&apnsErr) // The reason is optional
+	switch {
+	case resp.StatusCode == http.StatusGone || apnsErr.Reason == "BadDeviceToken" || apnsErr.Reason == "DeviceTokenNotForTopic":
+		return "", fmt.Errorf("apns: %w: %s", util.ErrPushTokenInvalid, apnsErr.Reason)
+	case apnsErr.Reason == "ExpiredProviderToken" || apnsErr.Reason == "InvalidProviderToken":
+		s.mu.Lock()
+		s.token = "" // Sign a new one for the retry
+		s.mu.Unlock()
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusRequestEntityTooLarge:
+		return "", fmt.Errorf("apns: %w: %s", util.ErrPushRejected, apnsErr.Reason)
+	}
+	return "", fmt.Errorf("apns: notification rejected with status %d: %s", resp.StatusCode, apnsErr.Reason)
+}
@@ -0,0 +1,188 @@
+// internal/service/push_fcm.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+const (
+	fcmEndpoint = "https://fcm.googleapis.com"
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+// fcmCredentials is the part of a Google service account key file the sender needs.
+type fcmCredentials struct {
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"` // PEM, PKCS #8
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// fcmSender is a PushSender using the FCM HTTP v1 API, authenticated as a Google service account.
+type fcmSender struct {
+	creds    fcmCredentials
+	key      crypto.Signer
+	endpoint string // Base URL of the FCM API
+	client   *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMSender creates a PushSender for FCM from the JSON key file of a service account of the Firebase project.
+func NewFCMSender(credentials []byte, timeout time.Duration) (PushSender, error) {
+	var creds fcmCredentials
+	if err := json.Unmarshal(credentials, &creds); err != nil {
+		return nil, fmt.Errorf("fcm: invalid credentials: %w", err)
+	}
+	if creds.ProjectID == "" || creds.ClientEmail == "" || creds.TokenURI == "" {
+		return nil, fmt.Errorf("fcm: credentials need project_id, client_email and token_uri")
+	}
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("fcm: credentials have no PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("fcm: invalid private key: %w", err)
+	}
+	key, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("fcm: unsupported private key %T", parsed)
+	}
+	return &fcmSender{creds: creds, key: key, endpoint: fcmEndpoint, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// token returns a cached OAuth access token, exchanging a freshly signed assertion for a new one a minute
+// before the cached one expires.
+func (s *fcmSender) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.accessToken != "" && now.Before(s.expiresAt.Add(-time.Minute)) {
+		return s.accessToken, nil
+	}
+
+	assertion, err := signJWT(
+		map[string]any{"alg": "RS256", "typ": "JWT", "kid": s.creds.PrivateKeyID},
+		map[string]any{"iss": s.creds.ClientEmail, "scope": fcmScope, "aud": s.creds.TokenURI, "iat": now.Unix(), "exp": now.Add(time.Hour).Unix()},
+		s.key,
+	)
+	if err != nil {
+		return "", fmt.Errorf("fcm: %w", err)
+	}
+	form := url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"}, "assertion": {assertion}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.creds.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("fcm: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm: failed to get access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return "", fmt.Errorf("fcm: access token refused with status %d: %s", resp.StatusCode, body)
+	}
+	var grant struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&grant); err != nil {
+		return "", fmt.Errorf("fcm: failed to decode access token: %w", err)
+	}
+	s.accessToken = grant.AccessToken
+	s.expiresAt = now.Add(time.Duration(grant.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
+
+// fcmError is the error body of the FCM API.
+type fcmError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send implements PushSender. FCM reports an unknown token as UNREGISTERED, and a malformed one or payload as
+// INVALID_ARGUMENT; throttling and server errors are transient.
+func (s *fcmSender) Send(ctx context.Context, token string, notification *domain.Notification) (string, error) {
+	accessToken, err := s.token(ctx)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token":        token,
+			"notification": map[string]string{"title": notification.Title, "body": notification.Body},
+			"data":         notification.Data,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("fcm: failed to encode message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/v1/projects/"+url.PathEscape(s.creds.ProjectID)+"/messages:send", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("fcm: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var fcmErr fcmError
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&fcmErr) // The details are optional
+		reason := fcmErr.Error.Status
+		for _, detail := range fcmErr.Error.Details {
+			if detail.ErrorCode != "" {
+				reason = detail.ErrorCode
+			}
+		}
+		switch {
+		case reason == "UNREGISTERED" || resp.StatusCode == http.StatusNotFound:
+			return "", fmt.Errorf("fcm: %w: %s", util.ErrPushTokenInvalid, fcmErr.Error.Message)
+		case reason == "INVALID_ARGUMENT" || reason == "SENDER_ID_MISMATCH":
+			return "", fmt.Errorf("fcm: %w: %s: %s", util.ErrPushRejected, reason, fcmErr.Error.Message)
+		case resp.StatusCode == http.StatusUnauthorized:
+			s.mu.Lock()
+			s.accessToken = "" // Revoked early; get a new one on the retry
+			s.mu.Unlock()
+		}
+		return "", fmt.Errorf("fcm: message rejected with status %d: %s %s", resp.StatusCode, reason, fcmErr.Error.Message)
+	}
+	var sent struct {
+		Name string `json:"name"` // projects/{project}/messages/{id}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&sent); err != nil {
+		return "", fmt.Errorf("fcm: failed to decode response: %w", err)
+	}
+	return sent.Name, nil
+}
@@ -0,0 +1,220 @@
+// internal/service/ramp_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+type rampOrderKey struct{}
+
+// withRampOrder attaches a ramp order to the quoted transfer made with ctx. WalletService.Transfer then marks
+// the order executed with the CONVERSION legs it records, in the same database transaction.
+func withRampOrder(ctx context.Context, order *domain.RampOrder) context.Context {
+	return context.WithValue(ctx, rampOrderKey{}, order)
+}
+
+// WithRamps enables conversions between fiat and crypto wallets, which RampService.ExecuteRamp makes as quoted
+// transfers carrying their order. Without it, and for any quoted transfer made otherwise, fiat and crypto
+// wallets cannot convert into each other.
+func WithRamps(rampRepo repository.RampRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.rampRepo = rampRepo
+	}
+}
+
+// rampOrder returns the ramp order attached to ctx for a transfer between the wallets at the pricing of quote.
+// A conversion between a fiat and a crypto wallet is only made for a ramp order priced by the same quote, so
+// that it is linked to its legs and the stablecoins it credits are backed by the hot wallet.
+func (s *walletService) rampOrder(ctx context.Context, from, to *domain.Wallet, quote *domain.TransferQuote) (*domain.RampOrder, error) {
+	order, _ := ctx.Value(rampOrderKey{}).(*domain.RampOrder)
+	if order == nil {
+		if quote != nil && quote.CrossCurrency() && (from.Kind == domain.WalletKindCrypto) != (to.Kind == domain.WalletKindCrypto) {
+			return nil, fmt.Errorf("%w: conversions between fiat and crypto wallets are made through a ramp", util.ErrInvalidInput)
+		}
+		return nil, nil
+	}
+	if s.rampRepo == nil || quote == nil || quote.ID != order.QuoteID {
+		return nil, fmt.Errorf("%w: ramp %s is not priced by the transfer's quote", util.ErrQuoteNotUsable, order.PublicID)
+	}
+	return order, nil
+}
+
+// recordRamp marks the ramp order, if any, executed as the debit and credit legs of the conversion.
+func (s *walletService) recordRamp(ctx context.Context, q repository.DBExecutor, order *domain.RampOrder, transactions []*domain.Transaction) error {
+	if order == nil {
+		return nil
+	}
+	if len(transactions) != 2 {
+		return fmt.Errorf("ramp %s was executed without a conversion", order.PublicID)
+	}
+	err := s.rampRepo.MarkRampExecuted(ctx, q, order.ID, transactions[0].PublicID, transactions[1].PublicID, transactions[0].CreatedAt)
+	if errors.Is(err, util.ErrNotFound) {
+		return fmt.Errorf("%w: ramp %s was already executed", util.ErrQuoteNotUsable, order.PublicID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record ramp execution: %w", err)
+	}
+	return nil
+}
+
+// rampDescription describes the CONVERSION legs of a ramp, or returns nil for any other transfer.
+func rampDescription(order *domain.RampOrder) *string {
+	if order == nil {
+		return nil
+	}
+	description := fmt.Sprintf("On-ramp %s", order.PublicID)
+	if order.Direction == domain.RampOff {
+		description = fmt.Sprintf("Off-ramp %s", order.PublicID)
+	}
+	return &description
+}
+
+// RampService defines the interface for converting between a user's fiat wallets and stablecoin wallets.
+type RampService interface {
+	// QuoteRamp prices the conversion of amount from one wallet of the user to another, one holding fiat and
+	// the other a stablecoin, and stores it as a quoted order. No money moves.
+	QuoteRamp(ctx context.Context, from, to *domain.Wallet, amount decimal.Decimal) (*domain.RampOrder, error)
+	// GetRamp retrieves a ramp order from or to the wallet.
+	GetRamp(ctx context.Context, wallet *domain.Wallet, publicID uuid.UUID) (*domain.RampOrder, error)
+	// ExecuteRamp executes a quoted order from the wallet at its quoted pricing. Executing an executed order
+	// again returns it unchanged.
+	ExecuteRamp(ctx context.Context, wallet *domain.Wallet, publicID uuid.UUID) (*domain.RampOrder, error)
+}
+
+// rampService implements RampService.
+type rampService struct {
+	dbExecutor repository.DBExecutor
+	rampRepo   repository.RampRepository
+	quotes     TransferQuoteService
+	wallets    WalletService
+	gateway    ChainGateway // Optional; without it there are no stablecoin wallets to ramp to
+	logger     *slog.Logger
+}
+
+// NewRampService creates a new instance of RampService.
+func NewRampService(dbExecutor repository.DBExecutor, rampRepo repository.RampRepository, quotes TransferQuoteService, wallets WalletService, gateway ChainGateway, logger *slog.Logger) RampService {
+	return &rampService{
+		dbExecutor: dbExecutor,
+		rampRepo:   rampRepo,
+		quotes:     quotes,
+		wallets:    wallets,
+		gateway:    gateway,
+		logger:     logger,
+	}
+}
+
+// rampDirection returns which way a conversion between the wallets ramps and the stablecoin it converts.
+// The wallets must belong to the same user, and exactly one of them must hold a stablecoin.
+func (s *rampService) rampDirection(from, to *domain.Wallet) (domain.RampDirection, domain.CryptoAsset, error) {
+	if s.gateway == nil {
+		return "", domain.CryptoAsset{}, fmt.Errorf("%w: crypto wallets are not enabled", util.ErrInvalidInput)
+	}
+	if from.UserID != to.UserID {
+		return "", domain.CryptoAsset{}, fmt.Errorf("%w: a ramp converts between wallets of the same user", util.ErrForbidden)
+	}
+	fromAsset, fromCrypto := domain.LookupCryptoAsset(from.Currency)
+	toAsset, toCrypto := domain.LookupCryptoAsset(to.Currency)
+	switch {
+	case !fromCrypto && toCrypto && toAsset.Stablecoin() && to.Kind == domain.WalletKindCrypto:
+		return domain.RampOn, toAsset, nil
+	case fromCrypto && fromAsset.Stablecoin() && from.Kind == domain.WalletKindCrypto && !toCrypto:
+		return domain.RampOff, fromAsset, nil
+	}
+	return "", domain.CryptoAsset{}, fmt.Errorf("%w: a ramp converts between a fiat wallet and a stablecoin wallet", util.ErrInvalidInput)
+}
+
+// checkLiquidity checks that the hot wallets hold the stablecoins an on-ramp credits, as every stablecoin
+// balance must be backed on chain. Off-ramps take stablecoins in and need no liquidity.
+func (s *rampService) checkLiquidity(ctx context.Context, direction domain.RampDirection, asset domain.CryptoAsset, credit decimal.Decimal) error {
+	if direction != domain.RampOn {
+		return nil
+	}
+	available, err := s.gateway.HotWalletBalance(ctx, asset)
+	if err != nil {
+		return err
+	}
+	if available.LessThan(credit) {
+		s.logger.Warn("Ramp declined for lack of stablecoin liquidity", "asset", asset.Code,
+			"credit", credit.String(), "available", available.String())
+		return util.ErrRampLiquidity
+	}
+	return nil
+}
+
+// QuoteRamp prices the conversion with the transfer quote engine, so the order is executed at the quoted rate
+// until the quote expires, and checks the liquidity for it.
+func (s *rampService) QuoteRamp(ctx context.Context, from, to *domain.Wallet, amount decimal.Decimal) (*domain.RampOrder, error) {
+	direction, asset, err := s.rampDirection(from, to)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.quotes.QuoteTransfer(ctx, from, to, amount, from.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("quote ramp: %w", err)
+	}
+	if err := s.checkLiquidity(ctx, direction, asset, quote.CreditAmount); err != nil {
+		return nil, fmt.Errorf("quote ramp: %w", err)
+	}
+
+	order := domain.NewRampOrder(direction, quote)
+	if err := s.rampRepo.CreateRamp(ctx, s.dbExecutor, order); err != nil {
+		return nil, fmt.Errorf("quote ramp: %w", err)
+	}
+	return order, nil
+}
+
+// GetRamp retrieves a ramp order; an order between other wallets is not found.
+func (s *rampService) GetRamp(ctx context.Context, wallet *domain.Wallet, publicID uuid.UUID) (*domain.RampOrder, error) {
+	order, err := s.rampRepo.GetRampByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get ramp %s: %w", publicID, err)
+	}
+	if order.FromWalletID != wallet.ID && order.ToWalletID != wallet.ID {
+		return nil, fmt.Errorf("get ramp %s: %w", publicID, util.ErrNotFound)
+	}
+	return order, nil
+}
+
+// ExecuteRamp makes the quoted transfer with the order attached, so the conversion goes through every check of
+// WalletService.Transfer and the order is linked to its legs in the same database transaction. The liquidity
+// is checked again, as the hot wallets may have paid out since the quote.
+func (s *rampService) ExecuteRamp(ctx context.Context, wallet *domain.Wallet, publicID uuid.UUID) (*domain.RampOrder, error) {
+	order, err := s.rampRepo.GetRampByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("execute ramp %s: %w", publicID, err)
+	}
+	if order.FromWalletID != wallet.ID {
+		return nil, fmt.Errorf("execute ramp %s: %w", publicID, util.ErrNotFound)
+	}
+	if order.Status == domain.RampExecuted {
+		return order, nil
+	}
+
+	asset, _ := domain.LookupCryptoAsset(order.CreditCurrency)
+	if err := s.checkLiquidity(ctx, order.Direction, asset, order.CreditAmount); err != nil {
+		return nil, fmt.Errorf("execute ramp %s: %w", publicID, err)
+	}
+	ctx = withRampOrder(WithTransferQuote(ctx, order.QuotePublicID), order)
+	if _, _, _, err := s.wallets.Transfer(ctx, order.FromWalletID, order.ToWalletID, order.Amount, order.Currency); err != nil {
+		return nil, fmt.Errorf("execute ramp %s: %w", publicID, err)
+	}
+
+	executed, err := s.rampRepo.GetRampByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("execute ramp %s: %w", publicID, err)
+	}
+	s.logger.Info("Ramp executed", "ramp_id", publicID, "direction", order.Direction,
+		"debit", order.DebitAmount().String(), "currency", order.Currency,
+		"credit", order.CreditAmount.String(), "credit_currency", order.CreditCurrency)
+	return executed, nil
+}
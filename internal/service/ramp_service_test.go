@@ -0,0 +1,212 @@
+// internal/service/ramp_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestRampService tests quoting and executing ramps between fiat and stablecoin wallets.
+func TestRampService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rates := []domain.FXRate{{Base: "USD", Quote: "USDC", Rate: decimal.RequireFromString("0.9995"), AsOf: time.Now().UTC()}}
+	usdc, _ := domain.LookupCryptoAsset("USDC")
+	usd := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Kind: domain.WalletKindPersonal, Balance: decimal.NewFromInt(500)}
+	stable := &domain.Wallet{ID: 2, PublicID: uuid.New(), UserID: 10, Currency: "USDC", Kind: domain.WalletKindCrypto, Balance: decimal.NewFromInt(200)}
+
+	type mocks struct {
+		rampRepo        *MockRampRepository
+		quoteRepo       *MockTransferQuoteRepository
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		gateway         *MockChainGateway
+		dbExecutor      *MockDBExecutor
+		txController    *MockTxController
+	}
+	newService := func() (RampService, WalletService, mocks) {
+		m := mocks{
+			rampRepo:        new(MockRampRepository),
+			quoteRepo:       new(MockTransferQuoteRepository),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			gateway:         new(MockChainGateway),
+			dbExecutor:      new(MockDBExecutor),
+			txController:    new(MockTxController),
+		}
+		rateRepo := new(MockFXRateRepository)
+		rateRepo.On("ListRates", mock.Anything, m.dbExecutor).Return(rates, nil).Maybe()
+		fx := NewFXService(m.dbExecutor, rateRepo, time.Minute, time.Hour, logger)
+		quotes := NewTransferQuoteService(m.dbExecutor, m.quoteRepo, fx, 30*time.Second)
+		wallets := NewWalletService(new(MockDBBeginner), m.dbExecutor, new(MockUserRepository), m.walletRepo, m.transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return m.txController, nil },
+			func(tx db.TxController) error { return m.txController.Commit() },
+			func(tx db.TxController) { _ = m.txController.Rollback() },
+			WithTransferQuotes(m.quoteRepo), WithRamps(m.rampRepo))
+		m.txController.On("Rollback").Return(nil).Maybe()
+		return NewRampService(m.dbExecutor, m.rampRepo, quotes, wallets, m.gateway, logger), wallets, m
+	}
+	// quoted returns a quoted off-ramp of 100 USDC to the USD wallet with its quote
+	quoted := func() (*domain.RampOrder, *domain.TransferQuote) {
+		rate := decimal.RequireFromString("1.0005")
+		quote := &domain.TransferQuote{
+			ID:                 7,
+			PublicID:           uuid.New(),
+			FromWalletID:       stable.ID,
+			ToWalletID:         usd.ID,
+			FromWalletPublicID: stable.PublicID,
+			ToWalletPublicID:   usd.PublicID,
+			Amount:             decimal.NewFromInt(100),
+			Currency:           "USDC",
+			Fee:                decimal.Zero,
+			CreditAmount:       decimal.RequireFromString("100.05"),
+			CreditCurrency:     "USD",
+			Rate:               &rate,
+			ExpiresAt:          time.Now().UTC().Add(30 * time.Second),
+			CreatedAt:          time.Now().UTC(),
+		}
+		order := domain.NewRampOrder(domain.RampOff, quote)
+		order.ID = 3
+		return order, quote
+	}
+
+	t.Run("QuoteOnRamp", func(t *testing.T) {
+		ctx := context.Background()
+		service, _, m := newService()
+		m.quoteRepo.On("CreateQuote", ctx, m.dbExecutor, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(2).(*domain.TransferQuote).ID = 7
+		}).Return(nil).Once()
+		m.gateway.On("HotWalletBalance", ctx, usdc).Return(decimal.NewFromInt(1000), nil).Once()
+		m.rampRepo.On("CreateRamp", ctx, m.dbExecutor, mock.Anything).Return(nil).Once()
+
+		order, err := service.QuoteRamp(ctx, usd, stable, decimal.NewFromInt(100))
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.RampOn, order.Direction)
+		assert.Equal(t, domain.RampQuoted, order.Status)
+		assert.Equal(t, int64(7), order.QuoteID)
+		assert.Equal(t, "USDC", order.CreditCurrency)
+		assert.True(t, order.CreditAmount.Equal(decimal.RequireFromString("99.95")))
+		m.rampRepo.AssertExpectations(t)
+	})
+
+	t.Run("QuoteOnRampWithoutLiquidity", func(t *testing.T) {
+		ctx := context.Background()
+		service, _, m := newService()
+		m.quoteRepo.On("CreateQuote", ctx, m.dbExecutor, mock.Anything).Return(nil).Once()
+		m.gateway.On("HotWalletBalance", ctx, usdc).Return(decimal.NewFromInt(50), nil).Once()
+
+		_, err := service.QuoteRamp(ctx, usd, stable, decimal.NewFromInt(100))
+
+		assert.ErrorIs(t, err, util.ErrRampLiquidity)
+		m.rampRepo.AssertNotCalled(t, "CreateRamp", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("QuoteRejectsWalletsOfAnotherUser", func(t *testing.T) {
+		service, _, m := newService()
+		other := &domain.Wallet{ID: 4, PublicID: uuid.New(), UserID: 11, Currency: "USDC", Kind: domain.WalletKindCrypto}
+
+		_, err := service.QuoteRamp(context.Background(), usd, other, decimal.NewFromInt(100))
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		m.quoteRepo.AssertNotCalled(t, "CreateQuote", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("QuoteRejectsOtherAssets", func(t *testing.T) {
+		service, _, m := newService()
+		btc := &domain.Wallet{ID: 5, PublicID: uuid.New(), UserID: 10, Currency: "BTC", Kind: domain.WalletKindCrypto}
+		eur := &domain.Wallet{ID: 6, PublicID: uuid.New(), UserID: 10, Currency: "EUR", Kind: domain.WalletKindPersonal}
+
+		_, err := service.QuoteRamp(context.Background(), usd, btc, decimal.NewFromInt(100))
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		_, err = service.QuoteRamp(context.Background(), usd, eur, decimal.NewFromInt(100))
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.quoteRepo.AssertNotCalled(t, "CreateQuote", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ExecuteLinksConversionLegs", func(t *testing.T) {
+		ctx := context.Background()
+		service, _, m := newService()
+		order, quote := quoted()
+		m.rampRepo.On("GetRampByPublicID", ctx, m.dbExecutor, order.PublicID).Return(order, nil).Once()
+		m.quoteRepo.On("ClaimQuote", mock.Anything, m.txController, quote.PublicID, mock.Anything).Return(quote, nil).Once()
+		m.walletRepo.On("GetWalletsByIDs", mock.Anything, m.txController, []int64{stable.ID, usd.ID}).Return([]domain.Wallet{*usd, *stable}, nil).Once()
+		m.walletRepo.On("UpdateWalletBalances", mock.Anything, m.txController, map[int64]decimal.Decimal{
+			stable.ID: decimal.NewFromInt(-100),
+			usd.ID:    decimal.RequireFromString("100.05"),
+		}, mock.Anything).Return([]domain.Wallet{*usd, *stable}, nil).Once()
+		var legs []*domain.Transaction
+		m.transactionRepo.On("CreateTransaction", mock.Anything, m.txController, mock.Anything).Run(func(args mock.Arguments) {
+			legs = append(legs, args.Get(2).(*domain.Transaction))
+		}).Return(nil).Twice()
+		m.rampRepo.On("MarkRampExecuted", mock.Anything, m.txController, order.ID, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		executed := *order
+		executed.Status = domain.RampExecuted
+		m.rampRepo.On("GetRampByPublicID", mock.Anything, m.dbExecutor, order.PublicID).Return(&executed, nil).Once()
+
+		result, err := service.ExecuteRamp(ctx, stable, order.PublicID)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.RampExecuted, result.Status)
+		require.Len(t, legs, 2)
+		assert.Equal(t, domain.TransactionTypeConversion, legs[0].Type)
+		assert.Equal(t, &legs[0].PublicID, legs[1].ParentID)
+		assert.Equal(t, "Off-ramp "+order.PublicID.String(), *legs[0].Description)
+		assert.Equal(t, "Off-ramp "+order.PublicID.String(), *legs[1].Description)
+		m.rampRepo.AssertCalled(t, "MarkRampExecuted", mock.Anything, m.txController, order.ID, legs[0].PublicID, legs[1].PublicID, mock.Anything)
+		// Off-ramps take stablecoins in and need no liquidity
+		m.gateway.AssertNotCalled(t, "HotWalletBalance", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ExecuteExecutedRampAgain", func(t *testing.T) {
+		ctx := context.Background()
+		service, _, m := newService()
+		order, _ := quoted()
+		order.Status = domain.RampExecuted
+		m.rampRepo.On("GetRampByPublicID", ctx, m.dbExecutor, order.PublicID).Return(order, nil).Once()
+
+		result, err := service.ExecuteRamp(ctx, stable, order.PublicID)
+
+		require.NoError(t, err)
+		assert.Same(t, order, result)
+		m.quoteRepo.AssertNotCalled(t, "ClaimQuote", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ExecuteFromDestinationWalletIsNotFound", func(t *testing.T) {
+		ctx := context.Background()
+		service, _, m := newService()
+		order, _ := quoted()
+		m.rampRepo.On("GetRampByPublicID", ctx, m.dbExecutor, order.PublicID).Return(order, nil).Once()
+
+		_, err := service.ExecuteRamp(ctx, usd, order.PublicID)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		m.quoteRepo.AssertNotCalled(t, "ClaimQuote", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("QuotedConversionWithoutRampIsRejected", func(t *testing.T) {
+		_, wallets, m := newService()
+		_, quote := quoted()
+		ctx := WithTransferQuote(context.Background(), quote.PublicID)
+		m.quoteRepo.On("ClaimQuote", ctx, m.txController, quote.PublicID, mock.Anything).Return(quote, nil).Once()
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{stable.ID, usd.ID}).Return([]domain.Wallet{*usd, *stable}, nil).Once()
+
+		_, _, _, err := wallets.Transfer(ctx, stable.ID, usd.ID, decimal.NewFromInt(100), "USDC")
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
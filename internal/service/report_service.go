@@ -0,0 +1,121 @@
+// internal/service/report_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+const (
+	// maxReportRange is the longest period one liability report may cover.
+	maxReportRange = 366 * 24 * time.Hour
+	// maxReportTop is the most balances per currency a liability report may list.
+	maxReportTop = 100
+)
+
+// ReportService defines the interface for treasury reports over customer funds.
+type ReportService interface {
+	// LiabilityReport reports the total customer balances per currency, the float movement over [from, to)
+	// and the top largest balances per currency. Reports are cached, so repeated requests for the same
+	// period do not repeat the aggregate queries.
+	LiabilityReport(ctx context.Context, from, to time.Time, top int) (*domain.LiabilityReport, error)
+}
+
+// reportKey identifies a cached report.
+type reportKey struct {
+	from, to time.Time
+	top      int
+}
+
+// reportService implements ReportService with an in-memory cache of generated reports.
+// A cached report is served until it is older than the TTL; expired reports are evicted on the next store.
+type reportService struct {
+	dbExecutor repository.DBExecutor
+	reportRepo repository.ReportRepository
+	ttl        time.Duration
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	reports map[reportKey]*domain.LiabilityReport
+}
+
+// NewReportService creates a new instance of ReportService.
+func NewReportService(dbExecutor repository.DBExecutor, reportRepo repository.ReportRepository, ttl time.Duration, logger *slog.Logger) ReportService {
+	return &reportService{
+		dbExecutor: dbExecutor,
+		reportRepo: reportRepo,
+		ttl:        ttl,
+		logger:     logger,
+		reports:    make(map[reportKey]*domain.LiabilityReport),
+	}
+}
+
+// LiabilityReport returns the cached report for the period when it is fresh, and otherwise generates it.
+func (s *reportService) LiabilityReport(ctx context.Context, from, to time.Time, top int) (*domain.LiabilityReport, error) {
+	if !from.Before(to) || to.Sub(from) > maxReportRange {
+		return nil, fmt.Errorf("%w: the period must end after it starts and span at most 366 days", util.ErrInvalidInput)
+	}
+	if top < 1 || top > maxReportTop {
+		return nil, fmt.Errorf("%w: top must be between 1 and %d", util.ErrInvalidInput, maxReportTop)
+	}
+	key := reportKey{from: from.UTC(), to: to.UTC(), top: top}
+
+	s.mu.Lock()
+	report, ok := s.reports[key]
+	s.mu.Unlock()
+	if ok && time.Since(report.GeneratedAt) < s.ttl {
+		return report, nil
+	}
+
+	report, err := s.generate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	s.store(key, report)
+	return report, nil
+}
+
+// generate runs the aggregate queries of a report.
+func (s *reportService) generate(ctx context.Context, key reportKey) (*domain.LiabilityReport, error) {
+	start := time.Now()
+	liabilities, err := s.reportRepo.SumBalances(ctx, s.dbExecutor)
+	if err != nil {
+		return nil, fmt.Errorf("liability report: %w", err)
+	}
+	float, err := s.reportRepo.SumFloatMovement(ctx, s.dbExecutor, key.from, key.to)
+	if err != nil {
+		return nil, fmt.Errorf("liability report: %w", err)
+	}
+	largest, err := s.reportRepo.ListLargestBalances(ctx, s.dbExecutor, key.top)
+	if err != nil {
+		return nil, fmt.Errorf("liability report: %w", err)
+	}
+	s.logger.Info("Liability report generated", "from", key.from, "to", key.to, "duration", time.Since(start))
+	return &domain.LiabilityReport{
+		From:            key.from,
+		To:              key.to,
+		Liabilities:     liabilities,
+		Float:           float,
+		LargestBalances: largest,
+		GeneratedAt:     time.Now().UTC(),
+	}, nil
+}
+
+// store caches the report and evicts the expired ones, so reports of arbitrary periods do not pile up.
+func (s *reportService) store(key reportKey, report *domain.LiabilityReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, cached := range s.reports {
+		if time.Since(cached.GeneratedAt) >= s.ttl {
+			delete(s.reports, k)
+		}
+	}
+	s.reports[key] = report
+}
@@ -0,0 +1,100 @@
+// internal/service/report_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestReportService tests the liability report's validation, aggregation and caching.
+func TestReportService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	liabilities := []domain.CurrencyLiability{{Currency: "USD", TotalBalance: decimal.NewFromInt(1500), WalletCount: 3}}
+	float := []domain.FloatMovement{{Currency: "USD", Inflow: decimal.NewFromInt(400), Outflow: decimal.NewFromInt(150)}}
+	largest := []domain.RankedBalance{{WalletID: 1, WalletPublicID: uuid.New(), UserID: 10, Currency: "USD", Balance: decimal.NewFromInt(1000)}}
+
+	newService := func(ttl time.Duration) (ReportService, *MockReportRepository, *MockDBExecutor) {
+		reportRepo, dbExecutor := new(MockReportRepository), new(MockDBExecutor)
+		return NewReportService(dbExecutor, reportRepo, ttl, logger), reportRepo, dbExecutor
+	}
+
+	t.Run("AggregatesAndCaches", func(t *testing.T) {
+		ctx := context.Background()
+		service, reportRepo, dbExecutor := newService(time.Minute)
+
+		reportRepo.On("SumBalances", ctx, dbExecutor).Return(liabilities, nil).Once()
+		reportRepo.On("SumFloatMovement", ctx, dbExecutor, from, to).Return(float, nil).Once()
+		reportRepo.On("ListLargestBalances", ctx, dbExecutor, 10).Return(largest, nil).Once()
+
+		report, err := service.LiabilityReport(ctx, from, to, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, liabilities, report.Liabilities)
+		assert.True(t, report.Float[0].Net().Equal(decimal.NewFromInt(250)))
+		assert.Equal(t, largest, report.LargestBalances)
+
+		cached, err := service.LiabilityReport(ctx, from, to, 10)
+		assert.NoError(t, err)
+		assert.Same(t, report, cached)
+		reportRepo.AssertExpectations(t) // Each query ran once
+	})
+
+	t.Run("ExpiredReportIsRegenerated", func(t *testing.T) {
+		ctx := context.Background()
+		service, reportRepo, dbExecutor := newService(0)
+
+		reportRepo.On("SumBalances", ctx, dbExecutor).Return(liabilities, nil).Twice()
+		reportRepo.On("SumFloatMovement", ctx, dbExecutor, from, to).Return(float, nil).Twice()
+		reportRepo.On("ListLargestBalances", ctx, dbExecutor, 5).Return(largest, nil).Twice()
+
+		_, err := service.LiabilityReport(ctx, from, to, 5)
+		assert.NoError(t, err)
+		_, err = service.LiabilityReport(ctx, from, to, 5)
+		assert.NoError(t, err)
+		reportRepo.AssertExpectations(t)
+	})
+
+	t.Run("FailureIsNotCached", func(t *testing.T) {
+		ctx := context.Background()
+		service, reportRepo, dbExecutor := newService(time.Minute)
+
+		reportRepo.On("SumBalances", ctx, dbExecutor).Return(nil, errors.New("db down")).Once()
+
+		_, err := service.LiabilityReport(ctx, from, to, 10)
+		assert.Error(t, err)
+
+		reportRepo.On("SumBalances", ctx, dbExecutor).Return(liabilities, nil).Once()
+		reportRepo.On("SumFloatMovement", ctx, dbExecutor, from, to).Return(float, nil).Once()
+		reportRepo.On("ListLargestBalances", ctx, dbExecutor, 10).Return(largest, nil).Once()
+
+		report, err := service.LiabilityReport(ctx, from, to, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, liabilities, report.Liabilities)
+	})
+
+	t.Run("RejectsInvalidPeriodAndTop", func(t *testing.T) {
+		ctx := context.Background()
+		service, reportRepo, _ := newService(time.Minute)
+
+		_, err := service.LiabilityReport(ctx, to, from, 10)
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		_, err = service.LiabilityReport(ctx, from, from.Add(400*24*time.Hour), 10)
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		_, err = service.LiabilityReport(ctx, from, to, 101)
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		reportRepo.AssertNotCalled(t, "SumBalances", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,143 @@
+// internal/service/residency.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// checkCurrencyRestriction rejects opening a wallet in the currency for a resident of the country. A user
+// without a residency is never restricted.
+func (s *walletService) checkCurrencyRestriction(ctx context.Context, q repository.DBExecutor, currency string, residency *string) error {
+	if s.restrictionRepo == nil || residency == nil {
+		return nil
+	}
+	restricted, err := s.restrictionRepo.IsRestricted(ctx, q, currency, *residency)
+	if err != nil {
+		return fmt.Errorf("failed to check currency restriction: %w", err)
+	}
+	if restricted {
+		return fmt.Errorf("%w: %s for %s", util.ErrCurrencyRestricted, currency, *residency)
+	}
+	return nil
+}
+
+// checkFundingRestriction rejects funding a wallet in the currency owned by the user when the currency is
+// restricted for the user's current residency, including wallets opened before the restriction or the move.
+// Without a restriction repository nothing is restricted.
+func checkFundingRestriction(ctx context.Context, q repository.DBExecutor, restrictionRepo repository.CurrencyRestrictionRepository, currency string, userID int64) error {
+	if restrictionRepo == nil {
+		return nil
+	}
+	restricted, err := restrictionRepo.IsRestrictedForUser(ctx, q, currency, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check currency restriction: %w", err)
+	}
+	if restricted {
+		return fmt.Errorf("%w: %s for user %d", util.ErrCurrencyRestricted, currency, userID)
+	}
+	return nil
+}
+
+// CurrencyRestrictionService defines the interface for managing the residency restrictions of currencies.
+type CurrencyRestrictionService interface {
+	ListRestrictions(ctx context.Context) ([]domain.CurrencyRestriction, error)
+	GetRestriction(ctx context.Context, currency string) (*domain.CurrencyRestriction, error)
+	// SetRestriction replaces the countries whose residents may neither open nor fund wallets in the currency.
+	SetRestriction(ctx context.Context, currency string, countries []string, updatedBy string) (*domain.CurrencyRestriction, error)
+	// DeleteRestriction lifts the restriction of the currency on behalf of deletedBy.
+	DeleteRestriction(ctx context.Context, currency string, deletedBy string) error
+}
+
+// currencyRestrictionService implements CurrencyRestrictionService.
+type currencyRestrictionService struct {
+	dbExecutor      repository.DBExecutor
+	restrictionRepo repository.CurrencyRestrictionRepository
+	logger          *slog.Logger
+}
+
+// NewCurrencyRestrictionService creates a new instance of CurrencyRestrictionService.
+func NewCurrencyRestrictionService(dbExecutor repository.DBExecutor, restrictionRepo repository.CurrencyRestrictionRepository, logger *slog.Logger) CurrencyRestrictionService {
+	return &currencyRestrictionService{dbExecutor: dbExecutor, restrictionRepo: restrictionRepo, logger: logger}
+}
+
+// ListRestrictions retrieves the restrictions of all currencies that have one.
+func (s *currencyRestrictionService) ListRestrictions(ctx context.Context) ([]domain.CurrencyRestriction, error) {
+	restrictions, err := s.restrictionRepo.ListRestrictions(ctx, s.dbExecutor)
+	if err != nil {
+		return nil, fmt.Errorf("list currency restrictions: %w", err)
+	}
+	return restrictions, nil
+}
+
+// GetRestriction retrieves the restriction of a currency.
+func (s *currencyRestrictionService) GetRestriction(ctx context.Context, currency string) (*domain.CurrencyRestriction, error) {
+	if !domain.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("%w: unsupported currency %q", util.ErrInvalidInput, currency)
+	}
+	restriction, err := s.restrictionRepo.GetRestriction(ctx, s.dbExecutor, currency)
+	if err != nil {
+		return nil, fmt.Errorf("get currency restriction: %w", err)
+	}
+	return restriction, nil
+}
+
+// SetRestriction normalizes, deduplicates and sorts the country codes. It takes effect for the next wallet
+// creation or deposit; wallets already open keep their balances.
+func (s *currencyRestrictionService) SetRestriction(ctx context.Context, currency string, countries []string, updatedBy string) (*domain.CurrencyRestriction, error) {
+	if !domain.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("%w: unsupported currency %q", util.ErrInvalidInput, currency)
+	}
+	if len(countries) == 0 {
+		return nil, fmt.Errorf("%w: at least one country is required; delete the restriction to lift it", util.ErrInvalidInput)
+	}
+	if updatedBy == "" {
+		return nil, util.ErrForbidden
+	}
+
+	seen := make(map[string]bool, len(countries))
+	normalized := make([]string, 0, len(countries))
+	for _, country := range countries {
+		code, err := domain.NormalizeCountryCode(country)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", util.ErrInvalidInput, err)
+		}
+		if !seen[code] {
+			seen[code] = true
+			normalized = append(normalized, code)
+		}
+	}
+	sort.Strings(normalized)
+
+	restriction := &domain.CurrencyRestriction{
+		Currency:  currency,
+		Countries: normalized,
+		UpdatedBy: updatedBy,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.restrictionRepo.SaveRestriction(ctx, s.dbExecutor, restriction); err != nil {
+		return nil, fmt.Errorf("set currency restriction: %w", err)
+	}
+	s.logger.Info("Currency restriction set", "currency", currency, "countries", normalized, "updated_by", updatedBy)
+	return restriction, nil
+}
+
+// DeleteRestriction lifts the restriction of a currency. The restriction is gone afterwards, so the deleting
+// admin is logged.
+func (s *currencyRestrictionService) DeleteRestriction(ctx context.Context, currency string, deletedBy string) error {
+	if deletedBy == "" {
+		return util.ErrForbidden
+	}
+	if err := s.restrictionRepo.DeleteRestriction(ctx, s.dbExecutor, currency); err != nil {
+		return fmt.Errorf("delete currency restriction: %w", err)
+	}
+	s.logger.Info("Currency restriction deleted", "currency", currency, "deleted_by", deletedBy)
+	return nil
+}
@@ -0,0 +1,327 @@
+// internal/service/residency_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestCurrencyRestrictions tests the residency restrictions of currencies at wallet creation and deposit, and
+// their administration.
+func TestCurrencyRestrictions(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	newWalletService := func(userRepo *MockUserRepository, walletRepo *MockWalletRepository, transactionRepo *MockTransactionRepository, txController *MockTxController, restrictionRepo *MockCurrencyRestrictionRepository) WalletService {
+		return NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			userRepo,
+			walletRepo,
+			transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return txController, nil
+			},
+			func(tx db.TxController) error {
+				return txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = txController.Rollback()
+			},
+			WithCurrencyRestrictions(restrictionRepo),
+		)
+	}
+
+	t.Run("NormalizeCountryCode", func(t *testing.T) {
+		code, err := domain.NormalizeCountryCode(" de ")
+		assert.NoError(t, err)
+		assert.Equal(t, "DE", code)
+
+		for _, invalid := range []string{"", "D", "DEU", "D1"} {
+			_, err := domain.NormalizeCountryCode(invalid)
+			assert.Error(t, err, invalid)
+		}
+	})
+
+	t.Run("RestrictedResidentCannotOpenWallet", func(t *testing.T) {
+		ctx := context.Background()
+		userRepo, txController, restrictionRepo := new(MockUserRepository), new(MockTxController), new(MockCurrencyRestrictionRepository)
+		service := newWalletService(userRepo, new(MockWalletRepository), new(MockTransactionRepository), txController, restrictionRepo)
+		residency := "us"
+
+		userRepo.On("GetUserByUsername", ctx, txController, "alice").Return(nil, util.ErrNotFound).Once()
+		restrictionRepo.On("IsRestricted", ctx, txController, "EUR", "US").Return(true, nil).Once()
+		txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := service.CreateUserAndWallet(ctx, "alice", "EUR", &residency)
+
+		assert.ErrorIs(t, err, util.ErrCurrencyRestricted)
+		userRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+		restrictionRepo.AssertExpectations(t)
+	})
+
+	t.Run("UnrestrictedResidentOpensWallet", func(t *testing.T) {
+		ctx := context.Background()
+		userRepo, walletRepo, txController, restrictionRepo := new(MockUserRepository), new(MockWalletRepository), new(MockTxController), new(MockCurrencyRestrictionRepository)
+		service := newWalletService(userRepo, walletRepo, new(MockTransactionRepository), txController, restrictionRepo)
+		residency := "DE"
+
+		userRepo.On("GetUserByUsername", ctx, txController, "alice").Return(nil, util.ErrNotFound).Once()
+		restrictionRepo.On("IsRestricted", ctx, txController, "EUR", "DE").Return(false, nil).Once()
+		userRepo.On("CreateUser", ctx, txController, mock.MatchedBy(func(user *domain.User) bool {
+			return user.Residency != nil && *user.Residency == "DE"
+		})).Return(nil).Once()
+		walletRepo.On("CreateWallet", ctx, txController, mock.AnythingOfType("*domain.Wallet")).Return(nil).Once()
+		txController.On("Commit").Return(nil).Once()
+		txController.On("Rollback").Return(nil).Maybe()
+
+		_, _, err := service.CreateUserAndWallet(ctx, "alice", "EUR", &residency)
+
+		assert.NoError(t, err)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidResidencyIsRejected", func(t *testing.T) {
+		ctx := context.Background()
+		userRepo, restrictionRepo := new(MockUserRepository), new(MockCurrencyRestrictionRepository)
+		service := newWalletService(userRepo, new(MockWalletRepository), new(MockTransactionRepository), new(MockTxController), restrictionRepo)
+		residency := "Germany"
+
+		_, _, err := service.CreateUserAndWallet(ctx, "alice", "EUR", &residency)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		restrictionRepo.AssertNotCalled(t, "IsRestricted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RestrictedResidentCannotDeposit", func(t *testing.T) {
+		ctx := context.Background()
+		walletRepo, txController, restrictionRepo := new(MockWalletRepository), new(MockTxController), new(MockCurrencyRestrictionRepository)
+		service := newWalletService(new(MockUserRepository), walletRepo, new(MockTransactionRepository), txController, restrictionRepo)
+		wallet := &domain.Wallet{ID: 1, UserID: 10, Currency: "EUR", Balance: decimal.Zero}
+
+		walletRepo.On("GetWalletByID", ctx, txController, int64(1)).Return(wallet, nil).Once()
+		restrictionRepo.On("IsRestrictedForUser", ctx, txController, "EUR", int64(10)).Return(true, nil).Once()
+		txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := service.Deposit(ctx, 1, decimal.NewFromInt(50), "EUR")
+
+		assert.ErrorIs(t, err, util.ErrCurrencyRestricted)
+		walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SetRestrictionNormalizesCountries", func(t *testing.T) {
+		ctx := context.Background()
+		dbExecutor, restrictionRepo := new(MockDBExecutor), new(MockCurrencyRestrictionRepository)
+		service := NewCurrencyRestrictionService(dbExecutor, restrictionRepo, logger)
+
+		restrictionRepo.On("SaveRestriction", ctx, dbExecutor, mock.MatchedBy(func(restriction *domain.CurrencyRestriction) bool {
+			return restriction.Currency == "EUR" && restriction.UpdatedBy == "ops"
+		})).Return(nil).Once()
+
+		restriction, err := service.SetRestriction(ctx, "EUR", []string{"us", "CA", "US"}, "ops")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"CA", "US"}, restriction.Countries)
+		restrictionRepo.AssertExpectations(t)
+	})
+
+	t.Run("SetRestrictionRejectsInvalidInput", func(t *testing.T) {
+		ctx := context.Background()
+		restrictionRepo := new(MockCurrencyRestrictionRepository)
+		service := NewCurrencyRestrictionService(new(MockDBExecutor), restrictionRepo, logger)
+
+		_, err := service.SetRestriction(ctx, "XYZ", []string{"US"}, "ops")
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		_, err = service.SetRestriction(ctx, "EUR", nil, "ops")
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		_, err = service.SetRestriction(ctx, "EUR", []string{"USA"}, "ops")
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		restrictionRepo.AssertNotCalled(t, "SaveRestriction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeleteRestrictionRequiresNamedAdmin", func(t *testing.T) {
+		restrictionRepo := new(MockCurrencyRestrictionRepository)
+		service := NewCurrencyRestrictionService(new(MockDBExecutor), restrictionRepo, logger)
+
+		err := service.DeleteRestriction(context.Background(), "EUR", "")
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		restrictionRepo.AssertNotCalled(t, "DeleteRestriction", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestFundingRestrictions tests that every path bringing money into a wallet honours the residency
+// restrictions of its currency, not only deposits.
+func TestFundingRestrictions(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	customer := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Balance: decimal.NewFromInt(5)}
+	suspense := &domain.Wallet{ID: 2, PublicID: uuid.New(), UserID: 99, Currency: "USD", Kind: domain.WalletKindSuspense, Balance: decimal.NewFromInt(500)}
+	amount := decimal.NewFromInt(75)
+
+	type mocks struct {
+		dbExecutor      *MockDBExecutor
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		restrictionRepo *MockCurrencyRestrictionRepository
+		txController    *MockTxController
+	}
+	newMocks := func() (mocks, *BalanceWriter) {
+		m := mocks{
+			dbExecutor:      new(MockDBExecutor),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			restrictionRepo: new(MockCurrencyRestrictionRepository),
+			txController:    new(MockTxController),
+		}
+		m.txController.On("Rollback").Return(nil).Maybe()
+		return m, NewBalanceWriter(m.walletRepo, nil, m.restrictionRepo, nil)
+	}
+	beginTx := func(m mocks) db.BeginTxFunc {
+		return func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return m.txController, nil }
+	}
+	commitTx := func(m mocks) db.CommitTxFunc {
+		return func(tx db.TxController) error { return m.txController.Commit() }
+	}
+	rollbackTx := func(m mocks) db.RollbackTxFunc {
+		return func(tx db.TxController) { _ = m.txController.Rollback() }
+	}
+
+	t.Run("InboundFundsForRestrictedWalletAreHeldInSuspense", func(t *testing.T) {
+		ctx := context.Background()
+		m, balances := newMocks()
+		suspenseRepo := new(MockSuspenseRepository)
+		service := NewSuspenseService(new(MockDBBeginner), m.dbExecutor, suspenseRepo, m.walletRepo, balances, new(MockUserRepository),
+			m.transactionRepo, new(MockAnnotationRepository), nil, beginTx(m), commitTx(m), rollbackTx(m), logger)
+		accountReference := customer.PublicID.String()
+
+		suspenseRepo.On("GetInboundFundsByReference", ctx, m.dbExecutor, "bank", "pay_1").Return(nil, util.ErrNotFound).Once()
+		m.walletRepo.On("GetWalletByPublicID", ctx, m.dbExecutor, customer.PublicID).Return(customer, nil).Once()
+		m.restrictionRepo.On("IsRestrictedForUser", ctx, m.dbExecutor, "USD", customer.UserID).Return(true, nil).Once()
+		suspenseRepo.On("GetSuspenseWallet", ctx, m.dbExecutor, "USD").Return(suspense, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, suspense.ID).Return(suspense, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, suspense.ID, amount, mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		suspenseRepo.On("CreateInboundFunds", ctx, m.txController, mock.AnythingOfType("*domain.InboundFunds")).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		funds, _, err := service.ReceiveFunds(ctx, "bank", "pay_1", &accountReference, amount, "USD")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.InboundFundsStatusSuspended, funds.Status)
+		assert.Equal(t, suspense.ID, funds.WalletID)
+		mock.AssertExpectationsForObjects(t, suspenseRepo, m.walletRepo, m.restrictionRepo)
+	})
+
+	t.Run("SuspendedFundsCannotBeReassignedToRestrictedWallet", func(t *testing.T) {
+		ctx := context.Background()
+		m, balances := newMocks()
+		suspenseRepo := new(MockSuspenseRepository)
+		service := NewSuspenseService(new(MockDBBeginner), m.dbExecutor, suspenseRepo, m.walletRepo, balances, new(MockUserRepository),
+			m.transactionRepo, new(MockAnnotationRepository), nil, beginTx(m), commitTx(m), rollbackTx(m), logger)
+		funds := &domain.InboundFunds{ID: 4, PublicID: uuid.New(), Reference: "pay_1", Amount: amount, Currency: "USD",
+			Status: domain.InboundFundsStatusSuspended, WalletID: suspense.ID}
+
+		suspenseRepo.On("GetInboundFundsForUpdate", ctx, m.txController, funds.PublicID).Return(funds, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, customer.ID).Return(customer, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, suspense.ID).Return(suspense, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, suspense.ID, amount.Neg(), mock.Anything).Return(nil, nil).Once()
+		m.restrictionRepo.On("IsRestrictedForUser", ctx, m.txController, "USD", customer.UserID).Return(true, nil).Once()
+
+		_, _, err := service.Reassign(ctx, funds.PublicID, customer, "alice", "payer quoted invoice 4711")
+
+		assert.ErrorIs(t, err, util.ErrCurrencyRestricted)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", ctx, m.txController, customer.ID, amount, mock.Anything)
+		suspenseRepo.AssertNotCalled(t, "MarkReassigned", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AutoTopUpOfRestrictedWalletIsNotCharged", func(t *testing.T) {
+		ctx := context.Background()
+		m, balances := newMocks()
+		topUpRepo, gateway := new(MockAutoTopUpRepository), new(MockPaymentGateway)
+		now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		service := NewAutoTopUpService(new(MockDBBeginner), m.dbExecutor, topUpRepo, m.walletRepo, balances, m.transactionRepo, gateway,
+			nil, time.Hour, 3, beginTx(m), commitTx(m), rollbackTx(m), logger)
+		rule := domain.AutoTopUpRule{WalletID: customer.ID, WalletPublicID: customer.PublicID, Currency: "USD",
+			Threshold: decimal.NewFromInt(20), Amount: decimal.NewFromInt(50), Enabled: true}
+
+		topUpRepo.On("ListDueRules", ctx, m.dbExecutor, now.Add(-time.Hour)).Return([]domain.AutoTopUpRule{rule}, nil).Once()
+		topUpRepo.On("ClaimRule", ctx, m.dbExecutor, customer.ID, now, now.Add(-time.Hour)).Return(nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, customer.ID).Return(customer, nil).Once()
+		m.restrictionRepo.On("IsRestrictedForUser", ctx, m.dbExecutor, "USD", customer.UserID).Return(true, nil).Once()
+		// The rule is disabled at once, as retrying cannot succeed until the restriction changes
+		topUpRepo.On("RecordOutcome", ctx, m.dbExecutor, customer.ID, 1, mock.AnythingOfType("*string"), false).Return(nil).Once()
+
+		toppedUp, err := service.RunTopUps(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Zero(t, toppedUp)
+		gateway.AssertNotCalled(t, "Charge", mock.Anything, mock.Anything)
+		topUpRepo.AssertExpectations(t)
+	})
+
+	t.Run("VoucherCannotBeRedeemedIntoRestrictedWallet", func(t *testing.T) {
+		ctx := context.Background()
+		m, balances := newMocks()
+		voucherRepo := new(MockVoucherRepository)
+		service := NewVoucherService(new(MockDBBeginner), m.dbExecutor, voucherRepo, m.walletRepo, balances, m.transactionRepo, nil,
+			beginTx(m), commitTx(m), rollbackTx(m), logger)
+		voucher := &domain.Voucher{ID: 3, PublicID: uuid.New(), Amount: decimal.NewFromInt(25), Currency: "USD"}
+
+		voucherRepo.On("ClaimVoucher", ctx, m.txController, domain.HashVoucherCode("7KQMX2ZD9HTR4WPA"), customer.ID, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("time.Time")).
+			Return(voucher, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, customer.ID).Return(customer, nil).Once()
+		m.restrictionRepo.On("IsRestrictedForUser", ctx, m.txController, "USD", customer.UserID).Return(true, nil).Once()
+
+		_, _, _, err := service.RedeemVoucher(ctx, "7KQM-X2ZD-9HTR-4WPA", customer.ID)
+
+		// The claim is rolled back with the transaction, so the voucher stays redeemable
+		assert.ErrorIs(t, err, util.ErrCurrencyRestricted)
+		m.txController.AssertNotCalled(t, "Commit")
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CryptoDepositToRestrictedWalletIsNotCredited", func(t *testing.T) {
+		ctx := context.Background()
+		m, balances := newMocks()
+		cryptoRepo, gateway := new(MockCryptoRepository), new(MockChainGateway)
+		service := NewCryptoService(new(MockDBBeginner), m.dbExecutor, cryptoRepo, m.walletRepo, balances, m.transactionRepo, nil,
+			gateway, nil, 10*time.Minute, beginTx(m), commitTx(m), rollbackTx(m), logger)
+		wallet := &domain.Wallet{ID: 5, PublicID: uuid.New(), UserID: 10, Currency: "BTC", Kind: domain.WalletKindCrypto}
+		address := &domain.CryptoAddress{ID: 3, WalletID: wallet.ID, Asset: "BTC", Network: "bitcoin", Address: "bc1qdeposit"}
+		deposit := &domain.CryptoDeposit{ID: 6, WalletID: wallet.ID, Asset: "BTC", TxHash: "f00d", Amount: decimal.RequireFromString("0.5"),
+			Confirmations: 6, Status: domain.CryptoDepositPending}
+
+		gateway.On("ListDeposits", mock.Anything, mock.MatchedBy(func(asset domain.CryptoAsset) bool { return asset.Code == "BTC" })).
+			Return([]ChainDeposit{{Address: address.Address, TxHash: "f00d", Amount: deposit.Amount, Confirmations: 6}}, nil).Once()
+		gateway.On("ListDeposits", mock.Anything, mock.MatchedBy(func(asset domain.CryptoAsset) bool { return asset.Code != "BTC" })).
+			Return([]ChainDeposit{}, nil)
+		cryptoRepo.On("GetAddress", ctx, m.dbExecutor, "bitcoin", address.Address).Return(address, nil).Once()
+		cryptoRepo.On("RecordDeposit", ctx, m.dbExecutor, mock.Anything).Run(func(args mock.Arguments) {
+			recorded := args.Get(2).(*domain.CryptoDeposit)
+			recorded.ID, recorded.Status = deposit.ID, domain.CryptoDepositPending
+		}).Return(nil).Once()
+		cryptoRepo.On("GetDepositForUpdate", ctx, m.txController, deposit.ID).Return(deposit, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.restrictionRepo.On("IsRestrictedForUser", ctx, m.txController, "BTC", wallet.UserID).Return(true, nil).Once()
+
+		credited, err := service.PollDeposits(ctx)
+
+		// The deposit stays PENDING and is credited by a later poll once the restriction no longer applies
+		assert.NoError(t, err)
+		assert.Zero(t, credited)
+		cryptoRepo.AssertNotCalled(t, "MarkDepositCredited", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
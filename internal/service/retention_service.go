@@ -0,0 +1,149 @@
+// internal/service/retention_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// RetentionPolicy says how long each type of record is kept and when the nightly purge runs.
+type RetentionPolicy struct {
+	Periods   map[domain.RetentionRecordType]time.Duration // Retention of each type; 0 or absent keeps the records forever
+	BatchSize int                                          // Records deleted per statement, so the purge never holds locks for long
+	Hour      int                                          // Hour of the day, in UTC, from which the nightly purge runs
+	DryRun    bool                                         // The nightly purge only counts the records it would delete
+}
+
+// RetentionService defines the interface for purging records past their retention period.
+type RetentionService interface {
+	// Purge deletes the records of every type older than its retention period as of now, and returns how many
+	// it deleted per type. A dry run deletes nothing and returns how many it would have deleted.
+	Purge(ctx context.Context, now time.Time, dryRun bool) ([]domain.PurgeResult, error)
+	// PurgeNightly runs Purge, dry or not as the policy says, at the first call of each UTC day from the purge
+	// hour on. It returns nil results when the purge is not due.
+	PurgeNightly(ctx context.Context, now time.Time) ([]domain.PurgeResult, error)
+	// Stats returns the purge metrics of every record type, in purge order.
+	Stats() []domain.RetentionStats
+}
+
+// retentionService implements RetentionService. The purges are plain batched deletes outside a transaction:
+// a purge cut short deletes the rest on its next run.
+type retentionService struct {
+	dbExecutor    repository.DBExecutor
+	retentionRepo repository.RetentionRepository
+	policy        RetentionPolicy
+	logger        *slog.Logger
+
+	mu          sync.Mutex
+	stats       map[domain.RetentionRecordType]*domain.RetentionStats
+	lastNightly time.Time // Day of the last nightly purge
+}
+
+// NewRetentionService creates a new instance of RetentionService.
+func NewRetentionService(dbExecutor repository.DBExecutor, retentionRepo repository.RetentionRepository, policy RetentionPolicy, logger *slog.Logger) RetentionService {
+	stats := make(map[domain.RetentionRecordType]*domain.RetentionStats, len(domain.RetentionRecordTypes))
+	for _, recordType := range domain.RetentionRecordTypes {
+		stats[recordType] = &domain.RetentionStats{Type: recordType, Retention: policy.Periods[recordType]}
+	}
+	return &retentionService{
+		dbExecutor:    dbExecutor,
+		retentionRepo: retentionRepo,
+		policy:        policy,
+		logger:        logger,
+		stats:         stats,
+	}
+}
+
+// Purge deletes, or counts on a dry run, the records of every type past its retention period.
+func (s *retentionService) Purge(ctx context.Context, now time.Time, dryRun bool) ([]domain.PurgeResult, error) {
+	results := []domain.PurgeResult{}
+	var errs []error
+	for _, recordType := range domain.RetentionRecordTypes {
+		period := s.policy.Periods[recordType]
+		if period <= 0 {
+			continue
+		}
+		result := domain.PurgeResult{Type: recordType, Cutoff: now.Add(-period).UTC(), DryRun: dryRun}
+		err := s.purge(ctx, &result)
+		s.record(result, now, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("purge %s: %w", recordType, err))
+			continue
+		}
+		results = append(results, result)
+		if result.Rows > 0 {
+			s.logger.Info("Purged records past their retention period", "type", recordType, "rows", result.Rows,
+				"cutoff", result.Cutoff, "dry_run", dryRun)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func (s *retentionService) purge(ctx context.Context, result *domain.PurgeResult) error {
+	if result.DryRun {
+		count, err := s.retentionRepo.CountPurgeable(ctx, s.dbExecutor, result.Type, result.Cutoff)
+		result.Rows = count
+		return err
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		deleted, err := s.retentionRepo.PurgeRecords(ctx, s.dbExecutor, result.Type, result.Cutoff, s.policy.BatchSize)
+		result.Rows += deleted
+		if err != nil || deleted < int64(s.policy.BatchSize) {
+			return err
+		}
+	}
+}
+
+// record adds the outcome of a purge to the metrics of its type.
+func (s *retentionService) record(result domain.PurgeResult, at time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats[result.Type]
+	stats.Runs++
+	stats.LastRun, stats.LastRunAt = &result, &at
+	stats.LastFailure = nil
+	if err != nil {
+		message := err.Error()
+		stats.LastFailure = &message
+	}
+	if !result.DryRun {
+		stats.Purged += result.Rows
+	}
+}
+
+// PurgeNightly runs the purge once per UTC day from the purge hour on.
+func (s *retentionService) PurgeNightly(ctx context.Context, now time.Time) ([]domain.PurgeResult, error) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	s.mu.Lock()
+	due := now.Hour() >= s.policy.Hour && day.After(s.lastNightly)
+	if due {
+		s.lastNightly = day
+	}
+	s.mu.Unlock()
+	if !due {
+		return nil, nil
+	}
+	return s.Purge(ctx, now, s.policy.DryRun)
+}
+
+// Stats returns the purge metrics of every record type.
+func (s *retentionService) Stats() []domain.RetentionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]domain.RetentionStats, 0, len(domain.RetentionRecordTypes))
+	for _, recordType := range domain.RetentionRecordTypes {
+		stats = append(stats, *s.stats[recordType])
+	}
+	return stats
+}
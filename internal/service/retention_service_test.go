@@ -0,0 +1,114 @@
+// internal/service/retention_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+)
+
+// TestRetentionService tests the batched purges, dry runs, the nightly schedule and the purge metrics.
+func TestRetentionService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2026, 10, 16, 3, 30, 0, 0, time.UTC)
+	policy := RetentionPolicy{
+		Periods: map[domain.RetentionRecordType]time.Duration{
+			domain.RetentionAuthEvents:             365 * 24 * time.Hour,
+			domain.RetentionNotificationDeliveries: 90 * 24 * time.Hour,
+		},
+		BatchSize: 100,
+		Hour:      3,
+	}
+	authCutoff := now.Add(-365 * 24 * time.Hour)
+	deliveryCutoff := now.Add(-90 * 24 * time.Hour)
+
+	t.Run("PurgeDeletesInBatches", func(t *testing.T) {
+		ctx := context.Background()
+		repo, dbExecutor := new(MockRetentionRepository), new(MockDBExecutor)
+		service := NewRetentionService(dbExecutor, repo, policy, logger)
+		repo.On("PurgeRecords", ctx, dbExecutor, domain.RetentionAuthEvents, authCutoff, 100).Return(int64(100), nil).Twice()
+		repo.On("PurgeRecords", ctx, dbExecutor, domain.RetentionAuthEvents, authCutoff, 100).Return(int64(7), nil).Once()
+		repo.On("PurgeRecords", ctx, dbExecutor, domain.RetentionNotificationDeliveries, deliveryCutoff, 100).Return(int64(0), nil).Once()
+
+		results, err := service.Purge(ctx, now, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.PurgeResult{
+			{Type: domain.RetentionAuthEvents, Cutoff: authCutoff, Rows: 207},
+			{Type: domain.RetentionNotificationDeliveries, Cutoff: deliveryCutoff},
+		}, results)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "PurgeRecords", mock.Anything, mock.Anything, domain.RetentionNotifications, mock.Anything, mock.Anything)
+
+		stats := service.Stats()
+		require.Len(t, stats, len(domain.RetentionRecordTypes))
+		assert.Equal(t, int64(207), stats[0].Purged)
+		assert.Equal(t, int64(1), stats[0].Runs)
+		assert.Zero(t, stats[3].Runs, "notifications are kept forever")
+	})
+
+	t.Run("DryRunOnlyCounts", func(t *testing.T) {
+		ctx := context.Background()
+		repo, dbExecutor := new(MockRetentionRepository), new(MockDBExecutor)
+		service := NewRetentionService(dbExecutor, repo, policy, logger)
+		repo.On("CountPurgeable", ctx, dbExecutor, domain.RetentionAuthEvents, authCutoff).Return(int64(42), nil).Once()
+		repo.On("CountPurgeable", ctx, dbExecutor, domain.RetentionNotificationDeliveries, deliveryCutoff).Return(int64(3), nil).Once()
+
+		results, err := service.Purge(ctx, now, true)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), results[0].Rows)
+		assert.True(t, results[0].DryRun)
+		assert.Zero(t, service.Stats()[0].Purged, "a dry run purges nothing")
+		repo.AssertNotCalled(t, "PurgeRecords", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("FailureOfOneTypeLeavesTheOthers", func(t *testing.T) {
+		ctx := context.Background()
+		repo, dbExecutor := new(MockRetentionRepository), new(MockDBExecutor)
+		service := NewRetentionService(dbExecutor, repo, policy, logger)
+		repo.On("PurgeRecords", ctx, dbExecutor, domain.RetentionAuthEvents, authCutoff, 100).Return(int64(0), errors.New("lock timeout")).Once()
+		repo.On("PurgeRecords", ctx, dbExecutor, domain.RetentionNotificationDeliveries, deliveryCutoff, 100).Return(int64(5), nil).Once()
+
+		results, err := service.Purge(ctx, now, false)
+
+		assert.ErrorContains(t, err, "purge auth_events: lock timeout")
+		assert.Len(t, results, 1)
+		assert.NotNil(t, service.Stats()[0].LastFailure)
+	})
+
+	t.Run("NightlyPurgeRunsOncePerDay", func(t *testing.T) {
+		ctx := context.Background()
+		repo, dbExecutor := new(MockRetentionRepository), new(MockDBExecutor)
+		nightly := policy
+		nightly.DryRun = true
+		service := NewRetentionService(dbExecutor, repo, nightly, logger)
+		repo.On("CountPurgeable", ctx, dbExecutor, mock.Anything, mock.Anything).Return(int64(0), nil)
+
+		results, err := service.PurgeNightly(ctx, now.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Nil(t, results, "before the purge hour")
+
+		results, err = service.PurgeNightly(ctx, now)
+		require.NoError(t, err)
+		assert.Len(t, results, 2)
+
+		results, err = service.PurgeNightly(ctx, now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Nil(t, results, "already purged today")
+
+		results, err = service.PurgeNightly(ctx, now.Add(24*time.Hour))
+		require.NoError(t, err)
+		assert.Len(t, results, 2)
+		repo.AssertNumberOfCalls(t, "CountPurgeable", 4)
+	})
+}
@@ -0,0 +1,137 @@
+// internal/service/rollout.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// Rollout variants: the current code path, and the one being rolled out behind a flag.
+const (
+	VariantControl = "control"
+	VariantCanary  = "canary"
+)
+
+// RolloutStats compares the two code paths of one flagged change.
+type RolloutStats struct {
+	Flag      string        `json:"flag"`
+	Variant   string        `json:"variant"`
+	Calls     int64         `json:"calls"`
+	Errors    int64         `json:"errors"`    // Calls that failed, for any reason
+	Conflicts int64         `json:"conflicts"` // Failures on deadlocks and serialization conflicts
+	Total     time.Duration `json:"-"`
+	Max       time.Duration `json:"-"`
+}
+
+// AverageLatency returns the mean duration of the calls.
+func (s RolloutStats) AverageLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Calls)
+}
+
+// Rollouts decides which code path of a risky change each call takes, from a feature flag bucketed by user or
+// wallet, and measures both paths, so that a change enabled for a small percentage of traffic can be compared
+// with the code it replaces before it is rolled out further. A nil *Rollouts keeps every call on the control
+// path.
+type Rollouts struct {
+	flags FeatureFlags
+
+	mu    sync.Mutex
+	stats map[string]*RolloutStats // By flag and variant
+}
+
+// NewRollouts creates rollouts deciding with flags.
+func NewRollouts(flags FeatureFlags) *Rollouts {
+	return &Rollouts{flags: flags, stats: make(map[string]*RolloutStats)}
+}
+
+// Start decides whether the call takes the canary path of the flagged change. The returned function records
+// the outcome of the call, with its duration since Start, under the variant taken.
+func (r *Rollouts) Start(ctx context.Context, flag string, subject domain.FlagSubject) (bool, func(error)) {
+	if r == nil {
+		return false, func(error) {}
+	}
+	canary := r.flags.EvaluateFor(ctx, flag, subject).Enabled
+	variant := VariantControl
+	if canary {
+		variant = VariantCanary
+	}
+	started := time.Now()
+	return canary, func(err error) { r.observe(flag, variant, time.Since(started), err) }
+}
+
+func (r *Rollouts) observe(flag, variant string, elapsed time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := flag + "/" + variant
+	s, ok := r.stats[key]
+	if !ok {
+		s = &RolloutStats{Flag: flag, Variant: variant}
+		r.stats[key] = s
+	}
+	s.Calls++
+	s.Total += elapsed
+	s.Max = max(s.Max, elapsed)
+	if err != nil {
+		s.Errors++
+		if errors.Is(err, util.ErrConcurrentUpdate) {
+			s.Conflicts++
+		}
+	}
+}
+
+// Stats returns the measures of every flag and variant called so far, by flag then variant.
+func (r *Rollouts) Stats() []RolloutStats {
+	if r == nil {
+		return []RolloutStats{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := make([]RolloutStats, 0, len(r.stats))
+	for _, s := range r.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Flag != stats[j].Flag {
+			return stats[i].Flag < stats[j].Flag
+		}
+		return stats[i].Variant < stats[j].Variant
+	})
+	return stats
+}
+
+// WritePrometheus writes the measures in the Prometheus text exposition format, labelled by flag and variant.
+func (r *Rollouts) WritePrometheus(w io.Writer) error {
+	stats := r.Stats()
+	metrics := []struct {
+		name, kind, help string
+		value            func(RolloutStats) any
+	}{
+		{"rollout_calls_total", "counter", "Calls per code path of a flagged change.", func(s RolloutStats) any { return s.Calls }},
+		{"rollout_errors_total", "counter", "Failed calls per code path of a flagged change.", func(s RolloutStats) any { return s.Errors }},
+		{"rollout_conflicts_total", "counter", "Calls failed on deadlocks and serialization conflicts.", func(s RolloutStats) any { return s.Conflicts }},
+		{"rollout_duration_seconds_total", "counter", "Time spent in the calls.", func(s RolloutStats) any { return s.Total.Seconds() }},
+		{"rollout_duration_seconds_max", "gauge", "Longest call since startup.", func(s RolloutStats) any { return s.Max.Seconds() }},
+	}
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP finflow_%s %s\n# TYPE finflow_%s %s\n", metric.name, metric.help, metric.name, metric.kind); err != nil {
+			return err
+		}
+		for _, s := range stats {
+			if _, err := fmt.Fprintf(w, "finflow_%s{flag=%q,variant=%q} %v\n", metric.name, s.Flag, s.Variant, metric.value(s)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// internal/service/rollout_test.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestRollouts tests the choice of code path and the measures of each.
+func TestRollouts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("MeasuresEachVariant", func(t *testing.T) {
+		flags := new(MockFeatureFlags)
+		flags.On("EvaluateFor", ctx, "transfer.row_locks", domain.FlagSubject{UserID: 1, WalletID: 10}).Return(domain.FlagDecision{Enabled: true}).Twice()
+		flags.On("EvaluateFor", ctx, "transfer.row_locks", domain.FlagSubject{UserID: 1, WalletID: 11}).Return(domain.FlagDecision{}).Once()
+		rollouts := NewRollouts(flags)
+
+		canary, done := rollouts.Start(ctx, "transfer.row_locks", domain.FlagSubject{UserID: 1, WalletID: 10})
+		assert.True(t, canary)
+		done(nil)
+		_, done = rollouts.Start(ctx, "transfer.row_locks", domain.FlagSubject{UserID: 1, WalletID: 10})
+		done(fmt.Errorf("transfer: %w", util.ErrConcurrentUpdate))
+		canary, done = rollouts.Start(ctx, "transfer.row_locks", domain.FlagSubject{UserID: 1, WalletID: 11})
+		assert.False(t, canary)
+		done(nil)
+
+		stats := rollouts.Stats()
+		require.Len(t, stats, 2)
+		assert.Equal(t, VariantCanary, stats[0].Variant)
+		assert.Equal(t, int64(2), stats[0].Calls)
+		assert.Equal(t, int64(1), stats[0].Errors)
+		assert.Equal(t, int64(1), stats[0].Conflicts)
+		assert.Equal(t, VariantControl, stats[1].Variant)
+		assert.Equal(t, int64(1), stats[1].Calls)
+
+		var metrics strings.Builder
+		require.NoError(t, rollouts.WritePrometheus(&metrics))
+		assert.Contains(t, metrics.String(), "# TYPE finflow_rollout_calls_total counter\n")
+		assert.Contains(t, metrics.String(), `finflow_rollout_conflicts_total{flag="transfer.row_locks",variant="canary"} 1`)
+	})
+
+	t.Run("NilRolloutsStayOnControl", func(t *testing.T) {
+		var rollouts *Rollouts
+
+		canary, done := rollouts.Start(ctx, "transfer.row_locks", domain.FlagSubject{WalletID: 10})
+		done(nil)
+
+		assert.False(t, canary)
+		assert.Empty(t, rollouts.Stats())
+	})
+
+	t.Run("CanaryTransferLocksBothWallets", func(t *testing.T) {
+		walletRepo := new(MockWalletRepository)
+		transactionRepo := new(MockTransactionRepository)
+		txController := new(MockTxController)
+		flags := new(MockFeatureFlags)
+		flags.On("EvaluateFor", ctx, domain.FlagTransferRowLocks, domain.FlagSubject{WalletID: 2}).Return(domain.FlagDecision{Enabled: true}).Once()
+		flags.On("Evaluate", ctx, domain.FlagOverdraft, int64(1)).Return(domain.FlagDecision{}).Maybe()
+		rollouts := NewRollouts(flags)
+		service := NewWalletService(new(MockDBBeginner), new(MockDBExecutor), new(MockUserRepository), walletRepo, transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return txController, nil },
+			func(tx db.TxController) error { return txController.Commit() },
+			func(tx db.TxController) { _ = txController.Rollback() },
+			WithFeatureFlags(flags),
+			WithRollouts(rollouts),
+		)
+		from := domain.Wallet{ID: 2, UserID: 1, Currency: "EUR", Balance: decimal.NewFromInt(100)}
+		to := domain.Wallet{ID: 1, UserID: 2, Currency: "EUR", Balance: decimal.Zero}
+		amount := decimal.NewFromInt(30)
+
+		txController.On("Commit").Return(nil).Once()
+		txController.On("Rollback").Return(nil).Maybe()
+		lockOne := walletRepo.On("GetWalletByIDForUpdate", ctx, txController, int64(1)).Return(&to, nil).Once()
+		walletRepo.On("GetWalletByIDForUpdate", ctx, txController, int64(2)).Return(&from, nil).Once().NotBefore(lockOne)
+		walletRepo.On("UpdateWalletBalances", ctx, txController, map[int64]decimal.Decimal{2: amount.Neg(), 1: amount}, mock.Anything).Return([]domain.Wallet{
+			{ID: 1, UserID: 2, Currency: "EUR", Balance: amount},
+			{ID: 2, UserID: 1, Currency: "EUR", Balance: decimal.NewFromInt(70)},
+		}, nil).Once()
+		transactionRepo.On("CreateTransaction", ctx, txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+
+		fromWallet, toWallet, _, err := service.Transfer(ctx, 2, 1, amount, "EUR")
+
+		require.NoError(t, err)
+		assert.True(t, fromWallet.Balance.Equal(decimal.NewFromInt(70)))
+		assert.True(t, toWallet.Balance.Equal(amount))
+		walletRepo.AssertNotCalled(t, "GetWalletsByIDs", mock.Anything, mock.Anything, mock.Anything)
+		walletRepo.AssertExpectations(t)
+		assert.Equal(t, int64(1), rollouts.Stats()[0].Calls)
+	})
+}
@@ -0,0 +1,144 @@
+// internal/service/sandbox.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// Magic amounts of sandbox mode, in any currency.
+var (
+	SandboxAmountInsufficientFunds = decimal.NewFromInt(4001) // Withdrawals and transfers fail for insufficient funds
+	SandboxAmountPending           = decimal.NewFromInt(4002) // Transfers are accepted as PENDING and settled in the background
+	SandboxAmountRiskFlag          = decimal.NewFromInt(4003) // Deposits, withdrawals and transfers are flagged by screening
+)
+
+// Magic username prefixes of sandbox mode, matched against the owner of the destination wallet of a transfer,
+// or of the wallet of a deposit or withdrawal.
+const (
+	SandboxUserPending  = "sandbox_pending" // Transfers to the user are accepted as PENDING
+	SandboxUserRiskFlag = "sandbox_risk"    // Movements involving the user are flagged by screening
+)
+
+// sandboxOutcome is the deterministic outcome a sandbox movement is forced to.
+type sandboxOutcome int
+
+const (
+	sandboxNormal sandboxOutcome = iota
+	sandboxInsufficientFunds
+	sandboxPending
+	sandboxRiskFlag
+)
+
+// sandboxWalletService forces magic deposits, withdrawals and transfers to deterministic outcomes, so that
+// integrators can exercise their error handling. Everything else goes to the wrapped service.
+type sandboxWalletService struct {
+	WalletService
+	dbExecutor repository.DBExecutor
+	walletRepo repository.WalletRepository
+	userRepo   repository.UserRepository
+}
+
+// NewSandboxWalletService wraps a WalletService for sandbox mode: movements of a magic amount, or whose
+// wallet is owned by a user with a magic username, take the matching outcome instead of their real one. It
+// must only wrap services on a sandbox database. The magic usernames are read with the repositories, as the
+// caller may not read the counterparty's wallet.
+func NewSandboxWalletService(
+	wallets WalletService,
+	dbExecutor repository.DBExecutor,
+	walletRepo repository.WalletRepository,
+	userRepo repository.UserRepository,
+) WalletService {
+	return &sandboxWalletService{WalletService: wallets, dbExecutor: dbExecutor, walletRepo: walletRepo, userRepo: userRepo}
+}
+
+func (s *sandboxWalletService) Deposit(ctx context.Context, walletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Transaction, error) {
+	if s.outcome(ctx, amount, walletID) == sandboxRiskFlag {
+		return nil, nil, fmt.Errorf("deposit: sandbox: %w", util.ErrScreeningHit)
+	}
+	return s.WalletService.Deposit(ctx, walletID, amount, currency)
+}
+
+func (s *sandboxWalletService) Withdraw(ctx context.Context, walletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Transaction, error) {
+	switch s.outcome(ctx, amount, walletID) {
+	case sandboxInsufficientFunds:
+		return nil, nil, util.ErrInsufficientFunds
+	case sandboxRiskFlag:
+		return nil, nil, fmt.Errorf("withdraw: sandbox: %w", util.ErrScreeningHit)
+	}
+	return s.WalletService.Withdraw(ctx, walletID, amount, currency)
+}
+
+// Transfer submits a pending one as an asynchronous transfer, which the worker makes later, and returns the
+// wallets unchanged with the PENDING transaction. A pending dry run is made as usual, as it stores nothing.
+func (s *sandboxWalletService) Transfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Wallet, *domain.Transaction, error) {
+	switch s.outcome(ctx, amount, toWalletID) {
+	case sandboxInsufficientFunds:
+		return nil, nil, nil, util.ErrInsufficientFunds
+	case sandboxRiskFlag:
+		return nil, nil, nil, fmt.Errorf("transfer: sandbox: %w", util.ErrScreeningHit)
+	case sandboxPending:
+		if isDryRun(ctx) {
+			break
+		}
+		transaction, err := s.WalletService.SubmitTransfer(ctx, fromWalletID, toWalletID, amount, currency)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		fromWallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, fromWalletID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("transfer: failed to get source wallet %d: %w", fromWalletID, err)
+		}
+		toWallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, toWalletID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("transfer: failed to get destination wallet %d: %w", toWalletID, err)
+		}
+		return fromWallet, toWallet, transaction, nil
+	}
+	return s.WalletService.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+}
+
+func (s *sandboxWalletService) SubmitTransfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.Transaction, error) {
+	switch s.outcome(ctx, amount, toWalletID) {
+	case sandboxInsufficientFunds:
+		return nil, util.ErrInsufficientFunds
+	case sandboxRiskFlag:
+		return nil, fmt.Errorf("submit transfer: sandbox: %w", util.ErrScreeningHit)
+	}
+	return s.WalletService.SubmitTransfer(ctx, fromWalletID, toWalletID, amount, currency)
+}
+
+// outcome returns the outcome forced by the amount, or else by the username of the wallet's owner. A wallet
+// that cannot be read is left to the wrapped service to fail on.
+func (s *sandboxWalletService) outcome(ctx context.Context, amount decimal.Decimal, walletID int64) sandboxOutcome {
+	switch {
+	case amount.Equal(SandboxAmountInsufficientFunds):
+		return sandboxInsufficientFunds
+	case amount.Equal(SandboxAmountPending):
+		return sandboxPending
+	case amount.Equal(SandboxAmountRiskFlag):
+		return sandboxRiskFlag
+	}
+	wallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, walletID)
+	if err != nil {
+		return sandboxNormal
+	}
+	user, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, wallet.UserID)
+	if err != nil {
+		return sandboxNormal
+	}
+	switch {
+	case strings.HasPrefix(user.Username, SandboxUserPending):
+		return sandboxPending
+	case strings.HasPrefix(user.Username, SandboxUserRiskFlag):
+		return sandboxRiskFlag
+	}
+	return sandboxNormal
+}
@@ -0,0 +1,77 @@
+// internal/service/sandbox_test.go
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestSandboxWalletService tests the deterministic outcomes of magic amounts and usernames.
+func TestSandboxWalletService(t *testing.T) {
+	fromWalletID, toWalletID := int64(1), int64(2)
+	type mocks struct {
+		walletRepo      *MockWalletRepository
+		userRepo        *MockUserRepository
+		transactionRepo *MockTransactionRepository
+		dbExecutor      *MockDBExecutor
+	}
+	newService := func() (WalletService, mocks) {
+		m := mocks{
+			walletRepo:      new(MockWalletRepository),
+			userRepo:        new(MockUserRepository),
+			transactionRepo: new(MockTransactionRepository),
+			dbExecutor:      new(MockDBExecutor),
+		}
+		wallets := NewWalletService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.userRepo,
+			m.walletRepo,
+			m.transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				t.Fatal("sandbox outcome reached the wrapped service")
+				return nil, nil
+			},
+			func(tx db.TxController) error { return nil },
+			func(tx db.TxController) {},
+		)
+		return NewSandboxWalletService(wallets, m.dbExecutor, m.walletRepo, m.userRepo), m
+	}
+
+	t.Run("MagicAmountFailsForInsufficientFunds", func(t *testing.T) {
+		service, m := newService()
+
+		_, _, _, err := service.Transfer(context.Background(), fromWalletID, toWalletID, SandboxAmountInsufficientFunds, "USD")
+
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		m.walletRepo.AssertNotCalled(t, "GetWalletByID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("MagicAmountIsFlagged", func(t *testing.T) {
+		service, _ := newService()
+
+		_, _, err := service.Withdraw(context.Background(), fromWalletID, SandboxAmountRiskFlag, "USD")
+
+		assert.ErrorIs(t, err, util.ErrScreeningHit)
+	})
+
+	t.Run("MagicUsernameIsFlagged", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.walletRepo.On("GetWalletByID", ctx, m.dbExecutor, toWalletID).Return(&domain.Wallet{ID: toWalletID, UserID: 7}, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, int64(7)).Return(&domain.User{ID: 7, Username: SandboxUserRiskFlag + "_acme"}, nil).Once()
+
+		_, err := service.SubmitTransfer(ctx, fromWalletID, toWalletID, decimal.NewFromInt(10), "USD")
+
+		assert.ErrorIs(t, err, util.ErrScreeningHit)
+		mock.AssertExpectationsForObjects(t, m.walletRepo, m.userRepo)
+	})
+}
@@ -0,0 +1,450 @@
+// internal/service/scim_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+type scimProvisioningKey struct{}
+
+// scimProvisioning is what the CreateUserAndWallet call of a SCIM create records besides the user and wallet.
+type scimProvisioning struct {
+	client       SCIMClient
+	resource     *domain.SCIMUser
+	email, phone *string
+	active       bool
+}
+
+// WithSCIM enables provisioning users by the identity systems of SCIM tenants through SCIMService.
+func WithSCIM(scimRepo repository.SCIMRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.scimRepo = scimRepo
+	}
+}
+
+// recordSCIMProvisioning completes the user just created for a SCIM create attached to ctx, if any: it sets
+// the contact details, deactivates a user created inactive, and stores the resource and its audit record.
+func (s *walletService) recordSCIMProvisioning(ctx context.Context, q repository.DBExecutor, user *domain.User) error {
+	p, _ := ctx.Value(scimProvisioningKey{}).(*scimProvisioning)
+	if p == nil {
+		return nil
+	}
+	if s.scimRepo == nil {
+		return fmt.Errorf("SCIM provisioning is not enabled")
+	}
+	changes := map[string]domain.SCIMChange{"userName": {To: user.Username}, "active": {To: p.active}}
+	if p.email != nil || p.phone != nil {
+		updated, err := s.userRepo.UpdateUserContact(ctx, q, user.ID, p.email, p.phone)
+		if err != nil {
+			return fmt.Errorf("failed to set contact: %w", err)
+		}
+		*user = *updated
+		addContactChanges(changes, nil, nil, p.email, p.phone)
+	}
+	if !p.active {
+		now := time.Now().UTC()
+		updated, err := s.userRepo.SetUserDeactivated(ctx, q, user.ID, &now)
+		if err != nil {
+			return fmt.Errorf("failed to deactivate: %w", err)
+		}
+		*user = *updated
+	}
+	p.resource.UserID = user.ID
+	if err := s.scimRepo.CreateSCIMUser(ctx, q, p.resource); err != nil {
+		if errors.Is(err, util.ErrDuplicateEntry) {
+			return &util.DuplicateEntryError{Resource: "scim_user"}
+		}
+		return err
+	}
+	if p.resource.ExternalID != nil {
+		changes["externalId"] = domain.SCIMChange{To: *p.resource.ExternalID}
+	}
+	return s.scimRepo.AppendProvisioningEvent(ctx, q, p.client.event(user.ID, domain.SCIMActionCreate, changes))
+}
+
+// addContactChanges records which contact details changed, without their values.
+func addContactChanges(changes map[string]domain.SCIMChange, oldEmail, oldPhone, newEmail, newPhone *string) {
+	if !equalOptional(oldEmail, newEmail) {
+		changes["email"] = domain.SCIMChange{}
+	}
+	if !equalOptional(oldPhone, newPhone) {
+		changes["phone"] = domain.SCIMChange{}
+	}
+}
+
+func equalOptional(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// SCIMClient is the tenant a SCIM request comes from, and the request's ID for the audit record.
+type SCIMClient struct {
+	Tenant    string
+	RequestID string
+}
+
+// event returns the audit record of a provisioning change the client made now.
+func (c SCIMClient) event(userID int64, action domain.SCIMAction, changes map[string]domain.SCIMChange) *domain.SCIMProvisioningEvent {
+	event := &domain.SCIMProvisioningEvent{
+		Tenant:    c.Tenant,
+		UserID:    userID,
+		Action:    action,
+		Changes:   changes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if c.RequestID != "" {
+		event.RequestID = &c.RequestID
+	}
+	return event
+}
+
+// SCIMUserAttributes are the attributes of a user that a tenant's identity system manages. A nil ExternalID,
+// Email or Phone is absent.
+type SCIMUserAttributes struct {
+	UserName   string
+	ExternalID *string
+	Email      *string // The primary email
+	Phone      *string // The primary phone number, in E.164 form once normalized
+	Active     bool
+}
+
+// SCIMUserPatch is a partial update of SCIMUserAttributes. A nil field keeps its value, and an empty
+// ExternalID, Email or Phone removes it.
+type SCIMUserPatch struct {
+	UserName   *string
+	ExternalID *string
+	Email      *string
+	Phone      *string
+	Active     *bool
+}
+
+// SCIMFilter selects the users of a list by an attribute's exact value; the zero filter selects all.
+type SCIMFilter struct {
+	Attribute string // "userName" or "externalId"
+	Value     string
+}
+
+// SCIMUserResult is a provisioned user with their SCIM resource.
+type SCIMUserResult struct {
+	Resource *domain.SCIMUser
+	User     *domain.User
+	Wallet   *domain.Wallet // The default wallet, if the user was created by the call
+}
+
+// SCIMService defines the interface for provisioning users by the identity systems of enterprise tenants,
+// following the SCIM 2.0 User resource. Every change is recorded as a provisioning event.
+type SCIMService interface {
+	// CreateUser creates a user with a wallet in the tenant's default currency.
+	CreateUser(ctx context.Context, client SCIMClient, attrs SCIMUserAttributes) (*SCIMUserResult, error)
+	// GetUser retrieves a user the tenant provisioned by their SCIM id.
+	GetUser(ctx context.Context, tenant string, id uuid.UUID) (*SCIMUserResult, error)
+	// ListUsers retrieves a page of the users the tenant provisioned that match the filter, and their total.
+	ListUsers(ctx context.Context, tenant string, filter SCIMFilter, limit, offset int) ([]SCIMUserResult, int64, error)
+	// ReplaceUser sets all of a user's managed attributes.
+	ReplaceUser(ctx context.Context, client SCIMClient, id uuid.UUID, attrs SCIMUserAttributes) (*SCIMUserResult, error)
+	// PatchUser changes some of a user's managed attributes.
+	PatchUser(ctx context.Context, client SCIMClient, id uuid.UUID, patch SCIMUserPatch) (*SCIMUserResult, error)
+	// DeactivateUser deactivates a user; they keep their wallets but can no longer log in.
+	DeactivateUser(ctx context.Context, client SCIMClient, id uuid.UUID) error
+	// ListProvisioningEvents retrieves the provisioning changes of a user, oldest first.
+	ListProvisioningEvents(ctx context.Context, userID int64) ([]domain.SCIMProvisioningEvent, error)
+}
+
+// scimService implements SCIMService.
+type scimService struct {
+	dbBeginner db.DBTxBeginner
+	dbExecutor repository.DBExecutor
+	scimRepo   repository.SCIMRepository
+	userRepo   repository.UserRepository
+	wallets    WalletService
+	currencies map[string]string // Tenant -> currency of the default wallet
+	logger     *slog.Logger
+	beginTx    db.BeginTxFunc
+	commitTx   db.CommitTxFunc
+	rollbackTx db.RollbackTxFunc
+}
+
+// NewSCIMService creates a new instance of SCIMService.
+func NewSCIMService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	scimRepo repository.SCIMRepository,
+	userRepo repository.UserRepository,
+	wallets WalletService,
+	currencies map[string]string,
+	logger *slog.Logger,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+) SCIMService {
+	return &scimService{
+		dbBeginner: dbBeginner,
+		dbExecutor: dbExecutor,
+		scimRepo:   scimRepo,
+		userRepo:   userRepo,
+		wallets:    wallets,
+		currencies: currencies,
+		logger:     logger,
+		beginTx:    beginTx,
+		commitTx:   commitTx,
+		rollbackTx: rollbackTx,
+	}
+}
+
+// normalizeSCIMAttributes trims the username and external ID and normalizes the contact details.
+func normalizeSCIMAttributes(attrs SCIMUserAttributes) (SCIMUserAttributes, error) {
+	attrs.UserName = strings.TrimSpace(attrs.UserName)
+	if attrs.UserName == "" {
+		return attrs, fmt.Errorf("%w: userName is required", util.ErrInvalidInput)
+	}
+	if attrs.ExternalID != nil {
+		if externalID := strings.TrimSpace(*attrs.ExternalID); externalID != "" {
+			attrs.ExternalID = &externalID
+		} else {
+			attrs.ExternalID = nil
+		}
+	}
+	var err error
+	if attrs.Email, err = mergeContactField(nil, attrs.Email, domain.NormalizeEmail); err != nil {
+		return attrs, err
+	}
+	if attrs.Phone, err = mergeContactField(nil, attrs.Phone, domain.NormalizePhone); err != nil {
+		return attrs, err
+	}
+	return attrs, nil
+}
+
+// CreateUser provisions the user, the wallet, the resource and its audit record in one transaction, so a
+// failure leaves none of them behind.
+func (s *scimService) CreateUser(ctx context.Context, client SCIMClient, attrs SCIMUserAttributes) (*SCIMUserResult, error) {
+	currency, ok := s.currencies[client.Tenant]
+	if !ok {
+		return nil, fmt.Errorf("create SCIM user: %w: unknown tenant %q", util.ErrForbidden, client.Tenant)
+	}
+	attrs, err := normalizeSCIMAttributes(attrs)
+	if err != nil {
+		return nil, fmt.Errorf("create SCIM user: %w", err)
+	}
+	resource := domain.NewSCIMUser(client.Tenant, attrs.ExternalID)
+	provisioning := &scimProvisioning{client: client, resource: resource, email: attrs.Email, phone: attrs.Phone, active: attrs.Active}
+	user, wallet, err := s.wallets.CreateUserAndWallet(context.WithValue(ctx, scimProvisioningKey{}, provisioning), attrs.UserName, currency, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create SCIM user: %w", err)
+	}
+	s.logger.Info("User provisioned by SCIM tenant", "tenant", client.Tenant, "user_id", user.ID, "scim_id", resource.PublicID)
+	return &SCIMUserResult{Resource: resource, User: user, Wallet: wallet}, nil
+}
+
+func (s *scimService) GetUser(ctx context.Context, tenant string, id uuid.UUID) (*SCIMUserResult, error) {
+	resource, err := s.scimRepo.GetSCIMUser(ctx, s.dbExecutor, tenant, id, false)
+	if err != nil {
+		return nil, fmt.Errorf("get SCIM user: %w", scimNotFound(err))
+	}
+	user, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, resource.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("get SCIM user: %w", scimNotFound(err))
+	}
+	return &SCIMUserResult{Resource: resource, User: user}, nil
+}
+
+// scimNotFound reports a resource of another tenant, or of a deleted user, as a user that does not exist.
+func scimNotFound(err error) error {
+	if errors.Is(err, util.ErrNotFound) {
+		return util.ErrUserNotFound
+	}
+	return err
+}
+
+// ListUsers answers the filters identity systems use to look a user up before creating them with a single
+// lookup. Without a filter, users deleted since their provisioning are left out of the page but not the total.
+func (s *scimService) ListUsers(ctx context.Context, tenant string, filter SCIMFilter, limit, offset int) ([]SCIMUserResult, int64, error) {
+	var resource *domain.SCIMUser
+	var err error
+	switch filter.Attribute {
+	case "":
+		return s.listAllUsers(ctx, tenant, limit, offset)
+	case "userName":
+		var user *domain.User
+		if user, err = s.userRepo.GetUserByUsername(ctx, s.dbExecutor, filter.Value); err == nil {
+			resource, err = s.scimRepo.GetSCIMUserByUserID(ctx, s.dbExecutor, tenant, user.ID)
+		}
+	case "externalId":
+		resource, err = s.scimRepo.GetSCIMUserByExternalID(ctx, s.dbExecutor, tenant, filter.Value)
+	default:
+		return nil, 0, fmt.Errorf("list SCIM users: %w: unsupported filter attribute %q", util.ErrInvalidInput, filter.Attribute)
+	}
+	if errors.Is(err, util.ErrNotFound) {
+		return []SCIMUserResult{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("list SCIM users: %w", err)
+	}
+	result, err := s.GetUser(ctx, tenant, resource.PublicID)
+	if errors.Is(err, util.ErrUserNotFound) {
+		return []SCIMUserResult{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if offset > 0 || limit == 0 {
+		return []SCIMUserResult{}, 1, nil
+	}
+	return []SCIMUserResult{*result}, 1, nil
+}
+
+func (s *scimService) listAllUsers(ctx context.Context, tenant string, limit, offset int) ([]SCIMUserResult, int64, error) {
+	resources, total, err := s.scimRepo.ListSCIMUsers(ctx, s.dbExecutor, tenant, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list SCIM users: %w", err)
+	}
+	results := make([]SCIMUserResult, 0, len(resources))
+	for i := range resources {
+		user, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, resources[i].UserID)
+		if errors.Is(err, util.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("list SCIM users: %w", err)
+		}
+		results = append(results, SCIMUserResult{Resource: &resources[i], User: user})
+	}
+	return results, total, nil
+}
+
+func (s *scimService) ReplaceUser(ctx context.Context, client SCIMClient, id uuid.UUID, attrs SCIMUserAttributes) (*SCIMUserResult, error) {
+	return s.update(ctx, client, id, func(SCIMUserAttributes) SCIMUserAttributes { return attrs })
+}
+
+func (s *scimService) PatchUser(ctx context.Context, client SCIMClient, id uuid.UUID, patch SCIMUserPatch) (*SCIMUserResult, error) {
+	return s.update(ctx, client, id, func(attrs SCIMUserAttributes) SCIMUserAttributes {
+		if patch.UserName != nil {
+			attrs.UserName = *patch.UserName
+		}
+		if patch.ExternalID != nil {
+			attrs.ExternalID = patch.ExternalID
+		}
+		if patch.Email != nil {
+			attrs.Email = patch.Email
+		}
+		if patch.Phone != nil {
+			attrs.Phone = patch.Phone
+		}
+		if patch.Active != nil {
+			attrs.Active = *patch.Active
+		}
+		return attrs
+	})
+}
+
+// DeactivateUser is idempotent: deactivating an inactive user records nothing.
+func (s *scimService) DeactivateUser(ctx context.Context, client SCIMClient, id uuid.UUID) error {
+	inactive := false
+	_, err := s.PatchUser(ctx, client, id, SCIMUserPatch{Active: &inactive})
+	return err
+}
+
+// update applies the attributes apply derives from the current ones under the resource's lock, and records
+// the attributes that changed, if any, as one provisioning event.
+func (s *scimService) update(ctx context.Context, client SCIMClient, id uuid.UUID, apply func(SCIMUserAttributes) SCIMUserAttributes) (*SCIMUserResult, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("update SCIM user: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, errors.New("update SCIM user: transaction controller does not implement DBExecutor")
+	}
+
+	resource, err := s.scimRepo.GetSCIMUser(ctx, txExecutor, client.Tenant, id, true)
+	if err != nil {
+		return nil, fmt.Errorf("update SCIM user: %w", scimNotFound(err))
+	}
+	user, err := s.userRepo.GetUserByID(ctx, txExecutor, resource.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("update SCIM user: %w", scimNotFound(err))
+	}
+	current := SCIMUserAttributes{
+		UserName:   user.Username,
+		ExternalID: resource.ExternalID,
+		Email:      user.Email,
+		Phone:      user.Phone,
+		Active:     user.DeactivatedAt == nil,
+	}
+	next, err := normalizeSCIMAttributes(apply(current))
+	if err != nil {
+		return nil, fmt.Errorf("update SCIM user: %w", err)
+	}
+
+	now := time.Now().UTC()
+	changes := map[string]domain.SCIMChange{}
+	if next.UserName != current.UserName {
+		if user, err = s.userRepo.UpdateUsername(ctx, txExecutor, user.ID, next.UserName); err != nil {
+			if errors.Is(err, util.ErrDuplicateEntry) {
+				err = &util.DuplicateEntryError{Resource: "user"}
+			}
+			return nil, fmt.Errorf("update SCIM user: %w", err)
+		}
+		changes["userName"] = domain.SCIMChange{From: current.UserName, To: next.UserName}
+	}
+	if !equalOptional(next.ExternalID, current.ExternalID) {
+		if err := s.scimRepo.UpdateSCIMUser(ctx, txExecutor, resource.ID, next.ExternalID, now); err != nil {
+			if errors.Is(err, util.ErrDuplicateEntry) {
+				err = &util.DuplicateEntryError{Resource: "scim_user"}
+			}
+			return nil, fmt.Errorf("update SCIM user: %w", err)
+		}
+		changes["externalId"] = domain.SCIMChange{From: current.ExternalID, To: next.ExternalID}
+		resource.ExternalID, resource.UpdatedAt = next.ExternalID, now
+	}
+	if !equalOptional(next.Email, current.Email) || !equalOptional(next.Phone, current.Phone) {
+		if user, err = s.userRepo.UpdateUserContact(ctx, txExecutor, user.ID, next.Email, next.Phone); err != nil {
+			return nil, fmt.Errorf("update SCIM user: %w", err)
+		}
+		addContactChanges(changes, current.Email, current.Phone, next.Email, next.Phone)
+	}
+	action := domain.SCIMActionUpdate
+	if next.Active != current.Active {
+		var deactivatedAt *time.Time
+		action = domain.SCIMActionReactivate
+		if !next.Active {
+			deactivatedAt, action = &now, domain.SCIMActionDeactivate
+		}
+		if user, err = s.userRepo.SetUserDeactivated(ctx, txExecutor, user.ID, deactivatedAt); err != nil {
+			return nil, fmt.Errorf("update SCIM user: %w", err)
+		}
+		changes["active"] = domain.SCIMChange{From: current.Active, To: next.Active}
+	}
+	if len(changes) == 0 {
+		return &SCIMUserResult{Resource: resource, User: user}, nil
+	}
+	if err := s.scimRepo.AppendProvisioningEvent(ctx, txExecutor, client.event(user.ID, action, changes)); err != nil {
+		return nil, fmt.Errorf("update SCIM user: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("update SCIM user: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("User updated by SCIM tenant", "tenant", client.Tenant, "user_id", user.ID, "action", action)
+	return &SCIMUserResult{Resource: resource, User: user}, nil
+}
+
+func (s *scimService) ListProvisioningEvents(ctx context.Context, userID int64) ([]domain.SCIMProvisioningEvent, error) {
+	events, err := s.scimRepo.ListProvisioningEvents(ctx, s.dbExecutor, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list provisioning events: %w", err)
+	}
+	return events, nil
+}
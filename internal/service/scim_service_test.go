@@ -0,0 +1,161 @@
+// internal/service/scim_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestSCIMService tests provisioning users by SCIM tenants and the audit records of their changes.
+func TestSCIMService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := SCIMClient{Tenant: "acme", RequestID: "req-1"}
+
+	type mocks struct {
+		scimRepo     *MockSCIMRepository
+		userRepo     *MockUserRepository
+		walletRepo   *MockWalletRepository
+		txController *MockTxController
+		dbExecutor   *MockDBExecutor
+	}
+	newService := func() (SCIMService, mocks) {
+		m := mocks{
+			scimRepo:     new(MockSCIMRepository),
+			userRepo:     new(MockUserRepository),
+			walletRepo:   new(MockWalletRepository),
+			txController: new(MockTxController),
+			dbExecutor:   new(MockDBExecutor),
+		}
+		m.txController.On("Rollback").Return(nil).Maybe()
+		beginTx := func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return m.txController, nil }
+		commitTx := func(tx db.TxController) error { return m.txController.Commit() }
+		rollbackTx := func(tx db.TxController) { _ = m.txController.Rollback() }
+		wallets := NewWalletService(new(MockDBBeginner), m.dbExecutor, m.userRepo, m.walletRepo, new(MockTransactionRepository),
+			beginTx, commitTx, rollbackTx, WithSCIM(m.scimRepo))
+		return NewSCIMService(new(MockDBBeginner), m.dbExecutor, m.scimRepo, m.userRepo, wallets, map[string]string{"acme": "EUR"},
+			logger, beginTx, commitTx, rollbackTx), m
+	}
+	// provisioned sets up the locked resource of an active user for an update.
+	provisioned := func(m mocks, id uuid.UUID) *domain.User {
+		externalID := "ext-ada"
+		resource := &domain.SCIMUser{ID: 5, PublicID: id, Tenant: "acme", ExternalID: &externalID, UserID: 10}
+		user := &domain.User{ID: 10, Username: "ada"}
+		m.scimRepo.On("GetSCIMUser", mock.Anything, m.txController, "acme", id, true).Return(resource, nil).Once()
+		m.userRepo.On("GetUserByID", mock.Anything, m.txController, int64(10)).Return(user, nil).Once()
+		return user
+	}
+
+	t.Run("CreateProvisionsUserWalletAndResource", func(t *testing.T) {
+		service, m := newService()
+		externalID := " ext-ada "
+		email := "Ada@Example.com"
+		m.userRepo.On("GetUserByUsername", mock.Anything, m.txController, "ada").Return(nil, util.ErrNotFound).Once()
+		m.userRepo.On("CreateUser", mock.Anything, m.txController, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) {
+			args.Get(2).(*domain.User).ID = 10
+		}).Return(nil).Once()
+		m.userRepo.On("UpdateUserContact", mock.Anything, m.txController, int64(10), mock.Anything, (*string)(nil)).
+			Return(&domain.User{ID: 10, Username: "ada"}, nil).Once()
+		m.scimRepo.On("CreateSCIMUser", mock.Anything, m.txController, mock.MatchedBy(func(resource *domain.SCIMUser) bool {
+			return resource.UserID == 10 && resource.Tenant == "acme" && *resource.ExternalID == "ext-ada"
+		})).Return(nil).Once()
+		m.scimRepo.On("AppendProvisioningEvent", mock.Anything, m.txController, mock.MatchedBy(func(event *domain.SCIMProvisioningEvent) bool {
+			_, emailChanged := event.Changes["email"]
+			return event.Action == domain.SCIMActionCreate && event.UserID == 10 && *event.RequestID == "req-1" &&
+				emailChanged && event.Changes["email"] == domain.SCIMChange{}
+		})).Return(nil).Once()
+		m.walletRepo.On("CreateWallet", mock.Anything, m.txController, mock.MatchedBy(func(wallet *domain.Wallet) bool {
+			return wallet.UserID == 10 && wallet.Currency == "EUR"
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		result, err := service.CreateUser(context.Background(), client,
+			SCIMUserAttributes{UserName: " ada ", ExternalID: &externalID, Email: &email, Active: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), result.User.ID)
+		assert.Equal(t, "EUR", result.Wallet.Currency)
+		m.scimRepo.AssertExpectations(t)
+		m.userRepo.AssertNotCalled(t, "SetUserDeactivated", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateForUnknownTenantIsForbidden", func(t *testing.T) {
+		service, m := newService()
+
+		_, err := service.CreateUser(context.Background(), SCIMClient{Tenant: "other"}, SCIMUserAttributes{UserName: "ada", Active: true})
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		m.userRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PatchInactiveRecordsDeactivation", func(t *testing.T) {
+		service, m := newService()
+		id := uuid.New()
+		provisioned(m, id)
+		deactivated := time.Now()
+		m.userRepo.On("SetUserDeactivated", mock.Anything, m.txController, int64(10), mock.MatchedBy(func(at *time.Time) bool {
+			return at != nil
+		})).Return(&domain.User{ID: 10, Username: "ada", DeactivatedAt: &deactivated}, nil).Once()
+		m.scimRepo.On("AppendProvisioningEvent", mock.Anything, m.txController, mock.MatchedBy(func(event *domain.SCIMProvisioningEvent) bool {
+			return event.Action == domain.SCIMActionDeactivate && len(event.Changes) == 1 &&
+				event.Changes["active"] == domain.SCIMChange{From: true, To: false}
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		inactive := false
+		result, err := service.PatchUser(context.Background(), client, id, SCIMUserPatch{Active: &inactive})
+
+		require.NoError(t, err)
+		assert.NotNil(t, result.User.DeactivatedAt)
+		m.scimRepo.AssertExpectations(t)
+	})
+
+	t.Run("RenameToTakenUsernameIsDuplicate", func(t *testing.T) {
+		service, m := newService()
+		id := uuid.New()
+		provisioned(m, id)
+		m.userRepo.On("UpdateUsername", mock.Anything, m.txController, int64(10), "grace").Return(nil, util.ErrDuplicateEntry).Once()
+
+		name := "grace"
+		_, err := service.PatchUser(context.Background(), client, id, SCIMUserPatch{UserName: &name})
+
+		assert.ErrorIs(t, err, util.ErrDuplicateEntry)
+		m.scimRepo.AssertNotCalled(t, "AppendProvisioningEvent", mock.Anything, mock.Anything, mock.Anything)
+		m.txController.AssertNotCalled(t, "Commit")
+	})
+
+	t.Run("UnchangedReplaceRecordsNothing", func(t *testing.T) {
+		service, m := newService()
+		id := uuid.New()
+		provisioned(m, id)
+
+		externalID := "ext-ada"
+		result, err := service.ReplaceUser(context.Background(), client, id, SCIMUserAttributes{UserName: "ada", ExternalID: &externalID, Active: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, "ada", result.User.Username)
+		m.scimRepo.AssertNotCalled(t, "AppendProvisioningEvent", mock.Anything, mock.Anything, mock.Anything)
+		m.scimRepo.AssertNotCalled(t, "UpdateSCIMUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ResourceOfAnotherTenantIsNotFound", func(t *testing.T) {
+		service, m := newService()
+		id := uuid.New()
+		m.scimRepo.On("GetSCIMUser", mock.Anything, m.dbExecutor, "acme", id, false).Return(nil, util.ErrNotFound).Once()
+
+		_, err := service.GetUser(context.Background(), "acme", id)
+
+		assert.ErrorIs(t, err, util.ErrUserNotFound)
+	})
+}
@@ -0,0 +1,259 @@
+// internal/service/screening.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// maxScreeningNameLength is the longest denylist name accepted.
+const maxScreeningNameLength = 200
+
+// maxListedScreeningCases caps the cases returned by one list request.
+const maxListedScreeningCases = 100
+
+// ScreeningRequest describes a party about to be onboarded or paid for the first time.
+type ScreeningRequest struct {
+	Event        domain.ScreeningEvent
+	Name         string // The party's name as the wallet knows it, i.e. its username
+	UserID       *int64 // The screened user; nil for a user not created yet
+	FromWalletID *int64 // The paying wallet, for domain.ScreeningEventNewCounterparty
+	ToWalletID   *int64
+}
+
+// Screener screens parties, e.g. against a sanctions list. WalletService calls it before creating a user and
+// before the first transfer from a wallet to another user's wallet.
+type Screener interface {
+	// Screen returns nil to let the operation go ahead, an error wrapping util.ErrScreeningHit to block it,
+	// or another error if the party could not be screened, which fails the operation. Anything it records,
+	// such as a review case, must not depend on the operation's database transaction, which is rolled back.
+	Screen(ctx context.Context, req ScreeningRequest) error
+}
+
+// DenylistScreener is the default Screener: it matches the party's normalized name against the denylist. A hit
+// opens a review case, or joins the open one of the same party and entry; once a case is cleared, the entry no
+// longer blocks that party.
+type DenylistScreener struct {
+	dbExecutor    repository.DBExecutor
+	screeningRepo repository.ScreeningRepository
+	logger        *slog.Logger
+}
+
+// NewDenylistScreener creates a new DenylistScreener.
+func NewDenylistScreener(dbExecutor repository.DBExecutor, screeningRepo repository.ScreeningRepository, logger *slog.Logger) *DenylistScreener {
+	return &DenylistScreener{dbExecutor: dbExecutor, screeningRepo: screeningRepo, logger: logger}
+}
+
+// Screen implements Screener. Cases are written outside any transaction, so they outlive the blocked operation.
+func (sc *DenylistScreener) Screen(ctx context.Context, req ScreeningRequest) error {
+	normalized := domain.NormalizeScreeningName(req.Name)
+	if normalized == "" {
+		return nil
+	}
+	entry, err := sc.screeningRepo.FindDenylistEntry(ctx, sc.dbExecutor, normalized)
+	if errors.Is(err, util.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to screen %q: %w", req.Name, err)
+	}
+
+	latest, err := sc.screeningRepo.FindLatestScreeningCase(ctx, sc.dbExecutor, normalized, entry.ID)
+	switch {
+	case err == nil && latest.Status == domain.ScreeningCaseCleared:
+		return nil
+	case err == nil:
+		return fmt.Errorf("%w: %q matches denylist entry %d, case %s", util.ErrScreeningHit, req.Name, entry.ID, latest.PublicID)
+	case !errors.Is(err, util.ErrNotFound):
+		return fmt.Errorf("failed to look up screening cases of %q: %w", req.Name, err)
+	}
+
+	screeningCase := &domain.ScreeningCase{
+		PublicID:       uuid.New(),
+		EntryID:        entry.ID,
+		Subject:        req.Name,
+		NormalizedName: normalized,
+		Event:          req.Event,
+		UserID:         req.UserID,
+		FromWalletID:   req.FromWalletID,
+		ToWalletID:     req.ToWalletID,
+		Status:         domain.ScreeningCaseOpen,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := sc.screeningRepo.CreateScreeningCase(ctx, sc.dbExecutor, screeningCase); err != nil {
+		return fmt.Errorf("failed to open screening case for %q: %w", req.Name, err)
+	}
+	sc.logger.Warn("Screening hit, review case opened", "case_id", screeningCase.PublicID, "subject", req.Name,
+		"entry_id", entry.ID, "event", req.Event)
+	return fmt.Errorf("%w: %q matches denylist entry %d, case %s", util.ErrScreeningHit, req.Name, entry.ID, screeningCase.PublicID)
+}
+
+// screenNewUser screens a username before the user is created.
+func (s *walletService) screenNewUser(ctx context.Context, username string) error {
+	if s.screener == nil {
+		return nil
+	}
+	return s.screener.Screen(ctx, ScreeningRequest{Event: domain.ScreeningEventUserCreation, Name: username})
+}
+
+// screenCounterparty screens the owner of the destination wallet, unless the source wallet has paid it before or
+// both wallets belong to the same user.
+func (s *walletService) screenCounterparty(ctx context.Context, q repository.DBExecutor, fromWallet, toWallet *domain.Wallet) error {
+	if s.screener == nil || fromWallet.UserID == toWallet.UserID {
+		return nil
+	}
+	known, err := s.transactionRepo.HasTransferredTo(ctx, q, fromWallet.ID, toWallet.ID)
+	if err != nil {
+		return err
+	}
+	if known {
+		return nil
+	}
+	owner, err := s.userRepo.GetUserByID(ctx, q, toWallet.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get owner of wallet %d: %w", toWallet.ID, err)
+	}
+	return s.screener.Screen(ctx, ScreeningRequest{
+		Event:        domain.ScreeningEventNewCounterparty,
+		Name:         owner.Username,
+		UserID:       &owner.ID,
+		FromWalletID: &fromWallet.ID,
+		ToWalletID:   &toWallet.ID,
+	})
+}
+
+// ScreeningService defines the interface for managing the screening denylist and reviewing screening cases.
+type ScreeningService interface {
+	AddDenylistEntry(ctx context.Context, name string, reason *string, createdBy string) (*domain.DenylistEntry, error)
+	ListDenylistEntries(ctx context.Context) ([]domain.DenylistEntry, error)
+	// DeleteDenylistEntry removes an entry on behalf of deletedBy. Entries with review cases are kept for the
+	// audit trail.
+	DeleteDenylistEntry(ctx context.Context, id int64, deletedBy string) error
+	GetCase(ctx context.Context, publicID uuid.UUID) (*domain.ScreeningCase, error)
+	// ListCases retrieves the most recent cases in one status or, if empty, all.
+	ListCases(ctx context.Context, status domain.ScreeningCaseStatus) ([]domain.ScreeningCase, error)
+	// ResolveCase clears an open case as a false positive or confirms it as a true match.
+	ResolveCase(ctx context.Context, publicID uuid.UUID, status domain.ScreeningCaseStatus, resolvedBy string, note *string) (*domain.ScreeningCase, error)
+}
+
+// screeningService implements ScreeningService.
+type screeningService struct {
+	dbExecutor    repository.DBExecutor
+	screeningRepo repository.ScreeningRepository
+	logger        *slog.Logger
+}
+
+// NewScreeningService creates a new instance of ScreeningService.
+func NewScreeningService(dbExecutor repository.DBExecutor, screeningRepo repository.ScreeningRepository, logger *slog.Logger) ScreeningService {
+	return &screeningService{dbExecutor: dbExecutor, screeningRepo: screeningRepo, logger: logger}
+}
+
+// AddDenylistEntry adds a name to the denylist. A name that normalizes to nothing, or like an existing entry,
+// is rejected.
+func (s *screeningService) AddDenylistEntry(ctx context.Context, name string, reason *string, createdBy string) (*domain.DenylistEntry, error) {
+	normalized := domain.NormalizeScreeningName(name)
+	if normalized == "" || len(name) > maxScreeningNameLength {
+		return nil, fmt.Errorf("%w: name must contain letters or digits and have at most %d characters", util.ErrInvalidInput, maxScreeningNameLength)
+	}
+	if createdBy == "" {
+		return nil, util.ErrForbidden
+	}
+
+	entry := &domain.DenylistEntry{
+		Name:           name,
+		NormalizedName: normalized,
+		Reason:         reason,
+		CreatedBy:      createdBy,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.screeningRepo.CreateDenylistEntry(ctx, s.dbExecutor, entry); err != nil {
+		return nil, fmt.Errorf("add denylist entry: %w", err)
+	}
+	s.logger.Info("Denylist entry added", "entry_id", entry.ID, "name", name, "created_by", createdBy)
+	return entry, nil
+}
+
+// ListDenylistEntries retrieves the whole denylist.
+func (s *screeningService) ListDenylistEntries(ctx context.Context) ([]domain.DenylistEntry, error) {
+	entries, err := s.screeningRepo.ListDenylistEntries(ctx, s.dbExecutor)
+	if err != nil {
+		return nil, fmt.Errorf("list denylist entries: %w", err)
+	}
+	return entries, nil
+}
+
+// DeleteDenylistEntry removes a denylist entry. The entry is gone afterwards, so the deleting admin is logged.
+func (s *screeningService) DeleteDenylistEntry(ctx context.Context, id int64, deletedBy string) error {
+	if deletedBy == "" {
+		return util.ErrForbidden
+	}
+	if err := s.screeningRepo.DeleteDenylistEntry(ctx, s.dbExecutor, id); err != nil {
+		return fmt.Errorf("delete denylist entry: %w", err)
+	}
+	s.logger.Info("Denylist entry deleted", "entry_id", id, "deleted_by", deletedBy)
+	return nil
+}
+
+// GetCase retrieves a screening case by its public ID.
+func (s *screeningService) GetCase(ctx context.Context, publicID uuid.UUID) (*domain.ScreeningCase, error) {
+	screeningCase, err := s.screeningRepo.GetScreeningCaseByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get screening case: %w", err)
+	}
+	return screeningCase, nil
+}
+
+// ListCases retrieves up to maxListedScreeningCases cases, newest first.
+func (s *screeningService) ListCases(ctx context.Context, status domain.ScreeningCaseStatus) ([]domain.ScreeningCase, error) {
+	switch status {
+	case "", domain.ScreeningCaseOpen, domain.ScreeningCaseCleared, domain.ScreeningCaseConfirmed:
+	default:
+		return nil, fmt.Errorf("%w: unknown screening case status %q", util.ErrInvalidInput, status)
+	}
+	cases, err := s.screeningRepo.ListScreeningCases(ctx, s.dbExecutor, status, maxListedScreeningCases)
+	if err != nil {
+		return nil, fmt.Errorf("list screening cases: %w", err)
+	}
+	return cases, nil
+}
+
+// ResolveCase closes an open case. A case that is already resolved yields util.ErrScreeningCaseClosed.
+func (s *screeningService) ResolveCase(ctx context.Context, publicID uuid.UUID, status domain.ScreeningCaseStatus, resolvedBy string, note *string) (*domain.ScreeningCase, error) {
+	if status != domain.ScreeningCaseCleared && status != domain.ScreeningCaseConfirmed {
+		return nil, fmt.Errorf("%w: status must be CLEARED or CONFIRMED", util.ErrInvalidInput)
+	}
+	if resolvedBy == "" {
+		return nil, util.ErrForbidden
+	}
+
+	screeningCase, err := s.screeningRepo.GetScreeningCaseByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve screening case: %w", err)
+	}
+	if screeningCase.Status != domain.ScreeningCaseOpen {
+		return nil, util.ErrScreeningCaseClosed
+	}
+	now := time.Now().UTC()
+	if err := s.screeningRepo.ResolveScreeningCase(ctx, s.dbExecutor, screeningCase.ID, status, resolvedBy, note, now); err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrScreeningCaseClosed // Resolved concurrently
+		}
+		return nil, fmt.Errorf("resolve screening case: %w", err)
+	}
+
+	screeningCase.Status = status
+	screeningCase.ResolvedBy = &resolvedBy
+	screeningCase.ResolutionNote = note
+	screeningCase.ResolvedAt = &now
+	s.logger.Info("Screening case resolved", "case_id", publicID, "status", status, "resolved_by", resolvedBy)
+	return screeningCase, nil
+}
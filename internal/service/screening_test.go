@@ -0,0 +1,201 @@
+// internal/service/screening_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// blockingScreener blocks every party and records the requests it saw.
+type blockingScreener struct {
+	requests []ScreeningRequest
+}
+
+func (sc *blockingScreener) Screen(ctx context.Context, req ScreeningRequest) error {
+	sc.requests = append(sc.requests, req)
+	return util.ErrScreeningHit
+}
+
+// TestScreening tests the denylist screener, its review cases and the screening of new users and counterparties.
+func TestScreening(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	entry := &domain.DenylistEntry{ID: 3, Name: "John O'Neil", NormalizedName: "johnoneil"}
+
+	t.Run("NormalizeScreeningName", func(t *testing.T) {
+		assert.Equal(t, "johnoneil", domain.NormalizeScreeningName("John O'Neil"))
+		assert.Equal(t, "johnoneil", domain.NormalizeScreeningName("JOHN_ONEIL"))
+		assert.Equal(t, "", domain.NormalizeScreeningName(" .-_ "))
+	})
+
+	t.Run("UnlistedNamePasses", func(t *testing.T) {
+		ctx := context.Background()
+		repo, dbExecutor := new(MockScreeningRepository), new(MockDBExecutor)
+		screener := NewDenylistScreener(dbExecutor, repo, logger)
+
+		repo.On("FindDenylistEntry", ctx, dbExecutor, "alice").Return(nil, util.ErrNotFound).Once()
+
+		assert.NoError(t, screener.Screen(ctx, ScreeningRequest{Event: domain.ScreeningEventUserCreation, Name: "alice"}))
+		repo.AssertNotCalled(t, "CreateScreeningCase", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("HitOpensReviewCase", func(t *testing.T) {
+		ctx := context.Background()
+		repo, dbExecutor := new(MockScreeningRepository), new(MockDBExecutor)
+		screener := NewDenylistScreener(dbExecutor, repo, logger)
+
+		repo.On("FindDenylistEntry", ctx, dbExecutor, "johnoneil").Return(entry, nil).Once()
+		repo.On("FindLatestScreeningCase", ctx, dbExecutor, "johnoneil", entry.ID).Return(nil, util.ErrNotFound).Once()
+		repo.On("CreateScreeningCase", ctx, dbExecutor, mock.MatchedBy(func(c *domain.ScreeningCase) bool {
+			return c.EntryID == entry.ID && c.Subject == "john.oneil" && c.Status == domain.ScreeningCaseOpen &&
+				c.Event == domain.ScreeningEventUserCreation
+		})).Return(nil).Once()
+
+		err := screener.Screen(ctx, ScreeningRequest{Event: domain.ScreeningEventUserCreation, Name: "john.oneil"})
+
+		assert.ErrorIs(t, err, util.ErrScreeningHit)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("RepeatedHitJoinsOpenCase", func(t *testing.T) {
+		ctx := context.Background()
+		repo, dbExecutor := new(MockScreeningRepository), new(MockDBExecutor)
+		screener := NewDenylistScreener(dbExecutor, repo, logger)
+		open := &domain.ScreeningCase{PublicID: uuid.New(), Status: domain.ScreeningCaseOpen}
+
+		repo.On("FindDenylistEntry", ctx, dbExecutor, "johnoneil").Return(entry, nil).Once()
+		repo.On("FindLatestScreeningCase", ctx, dbExecutor, "johnoneil", entry.ID).Return(open, nil).Once()
+
+		err := screener.Screen(ctx, ScreeningRequest{Event: domain.ScreeningEventNewCounterparty, Name: "johnoneil"})
+
+		assert.ErrorIs(t, err, util.ErrScreeningHit)
+		repo.AssertNotCalled(t, "CreateScreeningCase", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ClearedCasePasses", func(t *testing.T) {
+		ctx := context.Background()
+		repo, dbExecutor := new(MockScreeningRepository), new(MockDBExecutor)
+		screener := NewDenylistScreener(dbExecutor, repo, logger)
+		cleared := &domain.ScreeningCase{PublicID: uuid.New(), Status: domain.ScreeningCaseCleared}
+
+		repo.On("FindDenylistEntry", ctx, dbExecutor, "johnoneil").Return(entry, nil).Once()
+		repo.On("FindLatestScreeningCase", ctx, dbExecutor, "johnoneil", entry.ID).Return(cleared, nil).Once()
+
+		assert.NoError(t, screener.Screen(ctx, ScreeningRequest{Event: domain.ScreeningEventNewCounterparty, Name: "johnoneil"}))
+	})
+
+	t.Run("ResolvedCaseCannotBeResolvedAgain", func(t *testing.T) {
+		ctx := context.Background()
+		repo, dbExecutor := new(MockScreeningRepository), new(MockDBExecutor)
+		service := NewScreeningService(dbExecutor, repo, logger)
+		confirmed := &domain.ScreeningCase{ID: 8, PublicID: uuid.New(), Status: domain.ScreeningCaseConfirmed}
+
+		repo.On("GetScreeningCaseByPublicID", ctx, dbExecutor, confirmed.PublicID).Return(confirmed, nil).Once()
+
+		_, err := service.ResolveCase(ctx, confirmed.PublicID, domain.ScreeningCaseCleared, "alice", nil)
+
+		assert.ErrorIs(t, err, util.ErrScreeningCaseClosed)
+		repo.AssertNotCalled(t, "ResolveScreeningCase", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeleteEntryRequiresNamedAdmin", func(t *testing.T) {
+		repo, dbExecutor := new(MockScreeningRepository), new(MockDBExecutor)
+		service := NewScreeningService(dbExecutor, repo, logger)
+
+		err := service.DeleteDenylistEntry(context.Background(), entry.ID, "")
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		repo.AssertNotCalled(t, "DeleteDenylistEntry", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	// Wallet service integration
+	fromWallet := domain.Wallet{ID: 1, UserID: 1, Currency: "USD", Balance: decimal.NewFromInt(500)}
+	toWallet := domain.Wallet{ID: 2, UserID: 2, Currency: "USD", Balance: decimal.NewFromInt(100)}
+	newWalletService := func(userRepo *MockUserRepository, walletRepo *MockWalletRepository, transactionRepo *MockTransactionRepository, txController *MockTxController, screener Screener) WalletService {
+		return NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			userRepo,
+			walletRepo,
+			transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return txController, nil
+			},
+			func(tx db.TxController) error {
+				return txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = txController.Rollback()
+			},
+			WithScreener(screener),
+		)
+	}
+
+	t.Run("TransferToNewCounterpartyIsScreened", func(t *testing.T) {
+		ctx := context.Background()
+		userRepo, walletRepo, transactionRepo, txController := new(MockUserRepository), new(MockWalletRepository), new(MockTransactionRepository), new(MockTxController)
+		screener := &blockingScreener{}
+		service := newWalletService(userRepo, walletRepo, transactionRepo, txController, screener)
+
+		walletRepo.On("GetWalletsByIDs", ctx, txController, []int64{1, 2}).Return([]domain.Wallet{fromWallet, toWallet}, nil).Once()
+		transactionRepo.On("HasTransferredTo", ctx, txController, int64(1), int64(2)).Return(false, nil).Once()
+		userRepo.On("GetUserByID", ctx, txController, int64(2)).Return(&domain.User{ID: 2, Username: "bob"}, nil).Once()
+		txController.On("Rollback").Return(nil).Once()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, decimal.NewFromInt(50), "USD")
+
+		assert.ErrorIs(t, err, util.ErrScreeningHit)
+		walletRepo.AssertNotCalled(t, "UpdateWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		if assert.Len(t, screener.requests, 1) {
+			assert.Equal(t, domain.ScreeningEventNewCounterparty, screener.requests[0].Event)
+			assert.Equal(t, "bob", screener.requests[0].Name)
+		}
+	})
+
+	t.Run("TransferToKnownCounterpartyIsNotScreened", func(t *testing.T) {
+		ctx := context.Background()
+		userRepo, walletRepo, transactionRepo, txController := new(MockUserRepository), new(MockWalletRepository), new(MockTransactionRepository), new(MockTxController)
+		screener := &blockingScreener{}
+		service := newWalletService(userRepo, walletRepo, transactionRepo, txController, screener)
+
+		walletRepo.On("GetWalletsByIDs", ctx, txController, []int64{1, 2}).Return([]domain.Wallet{fromWallet, toWallet}, nil).Once()
+		transactionRepo.On("HasTransferredTo", ctx, txController, int64(1), int64(2)).Return(true, nil).Once()
+		walletRepo.On("UpdateWalletBalances", ctx, txController, mock.Anything, mock.Anything).Return([]domain.Wallet{fromWallet, toWallet}, nil).Once()
+		transactionRepo.On("CreateTransaction", ctx, txController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		txController.On("Commit").Return(nil).Once()
+		txController.On("Rollback").Return(nil).Maybe()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, decimal.NewFromInt(50), "USD")
+
+		assert.NoError(t, err)
+		assert.Empty(t, screener.requests)
+	})
+
+	t.Run("NewUserIsScreened", func(t *testing.T) {
+		ctx := context.Background()
+		userRepo, txController := new(MockUserRepository), new(MockTxController)
+		screener := &blockingScreener{}
+		service := newWalletService(userRepo, new(MockWalletRepository), new(MockTransactionRepository), txController, screener)
+
+		userRepo.On("GetUserByUsername", ctx, txController, "mallory").Return(nil, util.ErrNotFound).Once()
+		txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := service.CreateUserAndWallet(ctx, "mallory", "USD", nil)
+
+		assert.ErrorIs(t, err, util.ErrScreeningHit)
+		userRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+		if assert.Len(t, screener.requests, 1) {
+			assert.Equal(t, domain.ScreeningEventUserCreation, screener.requests[0].Event)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+// internal/service/security_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// maxListedAuthEvents caps the events returned as a user's security activity.
+const maxListedAuthEvents = 100
+
+// Maximum lengths of the reported attributes of an authentication event.
+const (
+	maxUserAgentLength = 512
+	maxGeoHintLength   = 100
+)
+
+// SecurityNotifier alerts users about security-relevant events on their account.
+type SecurityNotifier interface {
+	// NotifyNewDevice alerts the user to a login from a device they had not logged in from before.
+	NotifyNewDevice(ctx context.Context, user *domain.User, event *domain.AuthEvent) error
+}
+
+// LogSecurityNotifier is the default SecurityNotifier: it only logs the alert, for a deployment without a
+// channel to reach users.
+type LogSecurityNotifier struct {
+	logger *slog.Logger
+}
+
+// NewLogSecurityNotifier creates a new LogSecurityNotifier.
+func NewLogSecurityNotifier(logger *slog.Logger) *LogSecurityNotifier {
+	return &LogSecurityNotifier{logger: logger}
+}
+
+// NotifyNewDevice implements SecurityNotifier.
+func (n *LogSecurityNotifier) NotifyNewDevice(ctx context.Context, user *domain.User, event *domain.AuthEvent) error {
+	n.logger.Warn("Login from a new device", "user_id", user.ID, "event_id", event.ID, "ip_address", optionalString(event.IPAddress), "geo_hint", optionalString(event.GeoHint))
+	return nil
+}
+
+// SecurityService defines the interface for the authentication events of users.
+type SecurityService interface {
+	// RecordEvent records an authentication event reported for the user, identifying the device by deviceID
+	// or, without one, by the event's user agent.
+	RecordEvent(ctx context.Context, event *domain.AuthEvent, deviceID string) error
+	// ListActivity retrieves the user's most recent authentication events, newest first.
+	ListActivity(ctx context.Context, userID int64) ([]domain.AuthEvent, error)
+}
+
+// securityService implements SecurityService.
+type securityService struct {
+	dbExecutor repository.DBExecutor
+	eventRepo  repository.AuthEventRepository
+	userRepo   repository.UserRepository
+	notifier   SecurityNotifier
+	logger     *slog.Logger
+}
+
+// NewSecurityService creates a new instance of SecurityService.
+func NewSecurityService(dbExecutor repository.DBExecutor, eventRepo repository.AuthEventRepository, userRepo repository.UserRepository, notifier SecurityNotifier, logger *slog.Logger) SecurityService {
+	return &securityService{dbExecutor: dbExecutor, eventRepo: eventRepo, userRepo: userRepo, notifier: notifier, logger: logger}
+}
+
+// RecordEvent flags a login as from a new device when the user has logged in before, but never from this
+// device; the notifier is then told after the event is stored. A user's first login, and failed logins,
+// are never flagged. A notifier failure is logged and does not fail the request.
+func (s *securityService) RecordEvent(ctx context.Context, event *domain.AuthEvent, deviceID string) error {
+	if !event.Type.Valid() {
+		return fmt.Errorf("%w: unknown auth event type %q", util.ErrInvalidInput, event.Type)
+	}
+	if event.IPAddress != nil && net.ParseIP(*event.IPAddress) == nil {
+		return fmt.Errorf("%w: invalid IP address %q", util.ErrInvalidInput, *event.IPAddress)
+	}
+	if event.UserAgent != nil && len(*event.UserAgent) > maxUserAgentLength {
+		return fmt.Errorf("%w: user agent must have at most %d characters", util.ErrInvalidInput, maxUserAgentLength)
+	}
+	if event.GeoHint != nil && len(*event.GeoHint) > maxGeoHintLength {
+		return fmt.Errorf("%w: geo hint must have at most %d characters", util.ErrInvalidInput, maxGeoHintLength)
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, event.UserID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return util.ErrUserNotFound
+		}
+		return fmt.Errorf("record auth event: failed to get user %d: %w", event.UserID, err)
+	}
+
+	event.DeviceHash = nil
+	if fingerprint := domain.DeviceFingerprint(deviceID, optionalString(event.UserAgent)); fingerprint != "" {
+		event.DeviceHash = &fingerprint
+	}
+	event.NewDevice = false
+	if event.Type == domain.AuthEventLogin && event.DeviceHash != nil {
+		hasLogins, known, err := s.eventRepo.KnownDevice(ctx, s.dbExecutor, user.ID, *event.DeviceHash)
+		if err != nil {
+			return fmt.Errorf("record auth event: %w", err)
+		}
+		event.NewDevice = hasLogins && !known
+	}
+	event.CreatedAt = time.Now().UTC()
+
+	if err := s.eventRepo.CreateAuthEvent(ctx, s.dbExecutor, event); err != nil {
+		return fmt.Errorf("record auth event: %w", err)
+	}
+	if event.NewDevice {
+		if err := s.notifier.NotifyNewDevice(ctx, user, event); err != nil {
+			s.logger.Error("Failed to send new device alert", "user_id", user.ID, "event_id", event.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// ListActivity retrieves up to maxListedAuthEvents events. An unknown user yields util.ErrUserNotFound rather
+// than an empty list.
+func (s *securityService) ListActivity(ctx context.Context, userID int64) ([]domain.AuthEvent, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, userID); err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("list security activity: failed to get user %d: %w", userID, err)
+	}
+	events, err := s.eventRepo.ListAuthEvents(ctx, s.dbExecutor, userID, maxListedAuthEvents)
+	if err != nil {
+		return nil, fmt.Errorf("list security activity: %w", err)
+	}
+	return events, nil
+}
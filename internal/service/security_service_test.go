@@ -0,0 +1,153 @@
+// internal/service/security_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// recordingNotifier records the new device alerts it is asked to send.
+type recordingNotifier struct {
+	events []*domain.AuthEvent
+	err    error
+}
+
+func (n *recordingNotifier) NotifyNewDevice(ctx context.Context, user *domain.User, event *domain.AuthEvent) error {
+	n.events = append(n.events, event)
+	return n.err
+}
+
+// TestSecurityService tests the recording of authentication events and the detection of new devices.
+func TestSecurityService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	user := &domain.User{ID: 10, Username: "alice"}
+	userAgent := "Mozilla/5.0 (iPhone)"
+	ip := "203.0.113.7"
+	device := domain.DeviceFingerprint("", userAgent)
+
+	type mocks struct {
+		eventRepo  *MockAuthEventRepository
+		userRepo   *MockUserRepository
+		dbExecutor *MockDBExecutor
+		notifier   *recordingNotifier
+	}
+	newService := func() (SecurityService, mocks) {
+		m := mocks{
+			eventRepo:  new(MockAuthEventRepository),
+			userRepo:   new(MockUserRepository),
+			dbExecutor: new(MockDBExecutor),
+			notifier:   &recordingNotifier{},
+		}
+		return NewSecurityService(m.dbExecutor, m.eventRepo, m.userRepo, m.notifier, logger), m
+	}
+
+	t.Run("DeviceFingerprint", func(t *testing.T) {
+		assert.Equal(t, "", domain.DeviceFingerprint("", ""))
+		assert.Len(t, device, 64)
+		assert.NotEqual(t, device, domain.DeviceFingerprint("device-1", userAgent))
+		assert.Equal(t, domain.DeviceFingerprint("device-1", "a"), domain.DeviceFingerprint("device-1", "b"))
+	})
+
+	t.Run("LoginFromNewDeviceNotifies", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		event := &domain.AuthEvent{UserID: user.ID, Type: domain.AuthEventLogin, IPAddress: &ip, UserAgent: &userAgent}
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, user.ID).Return(user, nil).Once()
+		m.eventRepo.On("KnownDevice", ctx, m.dbExecutor, user.ID, device).Return(true, false, nil).Once()
+		m.eventRepo.On("CreateAuthEvent", ctx, m.dbExecutor, event).Return(nil).Once()
+
+		err := service.RecordEvent(ctx, event, "")
+
+		assert.NoError(t, err)
+		assert.True(t, event.NewDevice)
+		assert.Equal(t, []*domain.AuthEvent{event}, m.notifier.events)
+		m.eventRepo.AssertExpectations(t)
+	})
+
+	t.Run("LoginFromKnownDeviceDoesNotNotify", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		event := &domain.AuthEvent{UserID: user.ID, Type: domain.AuthEventLogin, UserAgent: &userAgent}
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, user.ID).Return(user, nil).Once()
+		m.eventRepo.On("KnownDevice", ctx, m.dbExecutor, user.ID, device).Return(true, true, nil).Once()
+		m.eventRepo.On("CreateAuthEvent", ctx, m.dbExecutor, event).Return(nil).Once()
+
+		assert.NoError(t, service.RecordEvent(ctx, event, ""))
+		assert.False(t, event.NewDevice)
+		assert.Empty(t, m.notifier.events)
+	})
+
+	t.Run("FirstLoginDoesNotNotify", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		event := &domain.AuthEvent{UserID: user.ID, Type: domain.AuthEventLogin, UserAgent: &userAgent}
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, user.ID).Return(user, nil).Once()
+		m.eventRepo.On("KnownDevice", ctx, m.dbExecutor, user.ID, device).Return(false, false, nil).Once()
+		m.eventRepo.On("CreateAuthEvent", ctx, m.dbExecutor, event).Return(nil).Once()
+
+		assert.NoError(t, service.RecordEvent(ctx, event, ""))
+		assert.False(t, event.NewDevice)
+		assert.Empty(t, m.notifier.events)
+	})
+
+	t.Run("FailedLoginIsNotCheckedForNewDevice", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		event := &domain.AuthEvent{UserID: user.ID, Type: domain.AuthEventLoginFailed, UserAgent: &userAgent}
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, user.ID).Return(user, nil).Once()
+		m.eventRepo.On("CreateAuthEvent", ctx, m.dbExecutor, event).Return(nil).Once()
+
+		assert.NoError(t, service.RecordEvent(ctx, event, "device-1"))
+		assert.Equal(t, domain.DeviceFingerprint("device-1", userAgent), *event.DeviceHash)
+		m.eventRepo.AssertNotCalled(t, "KnownDevice", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotifierFailureDoesNotFailTheEvent", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.notifier.err = errors.New("mail server down")
+		event := &domain.AuthEvent{UserID: user.ID, Type: domain.AuthEventLogin, UserAgent: &userAgent}
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, user.ID).Return(user, nil).Once()
+		m.eventRepo.On("KnownDevice", ctx, m.dbExecutor, user.ID, device).Return(true, false, nil).Once()
+		m.eventRepo.On("CreateAuthEvent", ctx, m.dbExecutor, event).Return(nil).Once()
+
+		assert.NoError(t, service.RecordEvent(ctx, event, ""))
+		assert.Len(t, m.notifier.events, 1)
+	})
+
+	t.Run("InvalidEventIsRejected", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		badIP := "not-an-ip"
+
+		assert.ErrorIs(t, service.RecordEvent(ctx, &domain.AuthEvent{UserID: user.ID, Type: "SIGNUP"}, ""), util.ErrInvalidInput)
+		assert.ErrorIs(t, service.RecordEvent(ctx, &domain.AuthEvent{UserID: user.ID, Type: domain.AuthEventLogin, IPAddress: &badIP}, ""), util.ErrInvalidInput)
+		m.eventRepo.AssertNotCalled(t, "CreateAuthEvent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ActivityOfUnknownUser", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.userRepo.On("GetUserByID", ctx, m.dbExecutor, int64(99)).Return(nil, util.ErrNotFound).Once()
+
+		_, err := service.ListActivity(ctx, 99)
+
+		assert.ErrorIs(t, err, util.ErrUserNotFound)
+		m.eventRepo.AssertNotCalled(t, "ListAuthEvents", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
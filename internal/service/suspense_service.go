@@ -0,0 +1,343 @@
+// internal/service/suspense_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// maxInboundReferenceLength is the longest provider, reference or account reference accepted with inbound funds.
+const maxInboundReferenceLength = 100
+
+// SuspenseService defines the interface for funds received from payment providers. Funds that cannot be
+// matched to a wallet are credited to the platform's suspense wallet of their currency, so the money is
+// accounted for, until an operator reassigns them to the right wallet.
+type SuspenseService interface {
+	// ReceiveFunds credits funds reported by a provider to the wallet whose ID the payer quoted as the account
+	// reference, if it exists, holds the funds' currency and may be funded under the currency restrictions,
+	// and otherwise to the suspense wallet. A reference the provider reported before is not credited again:
+	// its record is returned with created false.
+	ReceiveFunds(ctx context.Context, provider, reference string, accountReference *string, amount decimal.Decimal, currency string) (funds *domain.InboundFunds, created bool, err error)
+	GetFunds(ctx context.Context, publicID uuid.UUID) (*domain.InboundFunds, error)
+	// ListSuspendedFunds retrieves the funds held in suspense, oldest first, in one currency or, if empty, all.
+	ListSuspendedFunds(ctx context.Context, currency string) ([]domain.InboundFunds, error)
+	// Reassign moves suspended funds to the wallet with an ADJUSTMENT transaction. The operator and reason are
+	// recorded with the funds and as the transaction's support annotation. A wallet whose currency is restricted
+	// for its owner is refused with util.ErrCurrencyRestricted.
+	Reassign(ctx context.Context, publicID uuid.UUID, target *domain.Wallet, operator, reason string) (*domain.InboundFunds, *domain.Transaction, error)
+}
+
+// suspenseService implements SuspenseService.
+type suspenseService struct {
+	dbBeginner      db.DBTxBeginner
+	dbExecutor      repository.DBExecutor
+	suspenseRepo    repository.SuspenseRepository
+	walletRepo      repository.WalletRepository
+	balances        *BalanceWriter // Applies balance changes and dates the transactions
+	userRepo        repository.UserRepository
+	transactionRepo repository.TransactionRepository
+	annotationRepo  repository.AnnotationRepository
+	events          *TransactionEvents // Optional; credits and adjustments are published here
+	beginTx         db.BeginTxFunc
+	commitTx        db.CommitTxFunc
+	rollbackTx      db.RollbackTxFunc
+	logger          *slog.Logger
+}
+
+// NewSuspenseService creates a new instance of SuspenseService.
+func NewSuspenseService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	suspenseRepo repository.SuspenseRepository,
+	walletRepo repository.WalletRepository,
+	balances *BalanceWriter,
+	userRepo repository.UserRepository,
+	transactionRepo repository.TransactionRepository,
+	annotationRepo repository.AnnotationRepository,
+	events *TransactionEvents,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) SuspenseService {
+	return &suspenseService{
+		dbBeginner:      dbBeginner,
+		dbExecutor:      dbExecutor,
+		suspenseRepo:    suspenseRepo,
+		walletRepo:      walletRepo,
+		balances:        balances,
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		annotationRepo:  annotationRepo,
+		events:          events,
+		beginTx:         beginTx,
+		commitTx:        commitTx,
+		rollbackTx:      rollbackTx,
+		logger:          logger,
+	}
+}
+
+// ReceiveFunds matches the funds, then credits them and records the receipt in one database transaction.
+// A concurrent delivery of the same reference loses on the unique reference and returns the winner's record.
+func (s *suspenseService) ReceiveFunds(ctx context.Context, provider, reference string, accountReference *string, amount decimal.Decimal, currency string) (*domain.InboundFunds, bool, error) {
+	if provider == "" || reference == "" || len(provider) > maxInboundReferenceLength || len(reference) > maxInboundReferenceLength ||
+		(accountReference != nil && len(*accountReference) > maxInboundReferenceLength) {
+		return nil, false, fmt.Errorf("%w: reference is required and references may have at most %d characters", util.ErrInvalidInput, maxInboundReferenceLength)
+	}
+	if !amount.IsPositive() || !amount.Equal(amount.Truncate(domain.CurrencyPrecision(currency))) {
+		return nil, false, fmt.Errorf("%w: amount must be positive and fit the currency's minor unit", util.ErrInvalidInput)
+	}
+	if existing, err := s.suspenseRepo.GetInboundFundsByReference(ctx, s.dbExecutor, provider, reference); err == nil {
+		return existing, false, nil
+	} else if !util.IsError(err, util.ErrNotFound) {
+		return nil, false, fmt.Errorf("receive funds: %w", err)
+	}
+
+	wallet, err := s.matchWallet(ctx, accountReference, currency)
+	if err != nil {
+		return nil, false, fmt.Errorf("receive funds: %w", err)
+	}
+	status := domain.InboundFundsStatusCredited
+	if wallet == nil {
+		status = domain.InboundFundsStatusSuspended
+		if wallet, err = s.suspenseWallet(ctx, currency); err != nil {
+			return nil, false, fmt.Errorf("receive funds: %w", err)
+		}
+	}
+
+	funds, transaction, err := s.credit(ctx, wallet, provider, reference, accountReference, amount, currency, status)
+	if util.IsError(err, util.ErrDuplicateEntry) {
+		existing, lookupErr := s.suspenseRepo.GetInboundFundsByReference(ctx, s.dbExecutor, provider, reference)
+		if lookupErr != nil {
+			return nil, false, fmt.Errorf("receive funds: %w", lookupErr)
+		}
+		return existing, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("receive funds: %w", err)
+	}
+	if s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
+
+	if status == domain.InboundFundsStatusSuspended {
+		s.logger.Warn("Unmatched inbound funds held in suspense", "funds_id", funds.PublicID, "provider", provider,
+			"reference", reference, "amount", amount.String(), "currency", currency)
+	} else {
+		s.logger.Info("Inbound funds credited", "funds_id", funds.PublicID, "provider", provider, "wallet_id", wallet.PublicID,
+			"amount", amount.String())
+	}
+	return funds, true, nil
+}
+
+// matchWallet returns the wallet the account reference names, or nil if it names no live customer wallet in
+// the currency that may be funded.
+func (s *suspenseService) matchWallet(ctx context.Context, accountReference *string, currency string) (*domain.Wallet, error) {
+	if accountReference == nil {
+		return nil, nil
+	}
+	publicID, err := uuid.Parse(*accountReference)
+	if err != nil {
+		return nil, nil
+	}
+	wallet, err := s.walletRepo.GetWalletByPublicID(ctx, s.dbExecutor, publicID)
+	if util.IsError(err, util.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Kind == domain.WalletKindSuspense || wallet.Currency != currency {
+		return nil, nil
+	}
+	if err := s.balances.CheckFunding(ctx, s.dbExecutor, wallet); err != nil {
+		if util.IsError(err, util.ErrCurrencyRestricted) {
+			s.logger.Warn("Inbound funds for a restricted wallet held in suspense", "wallet_id", wallet.PublicID, "currency", currency)
+			return nil, nil
+		}
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// suspenseWallet returns the suspense wallet of the currency, creating it on first use.
+func (s *suspenseService) suspenseWallet(ctx context.Context, currency string) (*domain.Wallet, error) {
+	wallet, err := s.suspenseRepo.GetSuspenseWallet(ctx, s.dbExecutor, currency)
+	if err == nil || !util.IsError(err, util.ErrNotFound) {
+		return wallet, err
+	}
+
+	platform, err := s.userRepo.GetUserByUsername(ctx, s.dbExecutor, domain.PlatformUsername)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get platform user: %w", err)
+	}
+	wallet = domain.NewWallet(platform.ID, currency)
+	wallet.Kind = domain.WalletKindSuspense
+	if err := s.walletRepo.CreateWallet(ctx, s.dbExecutor, wallet); err != nil {
+		if util.IsError(err, util.ErrDuplicateEntry) {
+			return s.suspenseRepo.GetSuspenseWallet(ctx, s.dbExecutor, currency) // Created by a concurrent delivery
+		}
+		return nil, fmt.Errorf("failed to create suspense wallet: %w", err)
+	}
+	s.logger.Info("Suspense wallet created", "wallet_id", wallet.PublicID, "currency", currency)
+	return wallet, nil
+}
+
+// credit deposits the funds into the wallet and records them. Funds credited to a customer wallet are checked
+// against the currency restrictions again, as the owner may have moved since the wallet was matched.
+func (s *suspenseService) credit(ctx context.Context, wallet *domain.Wallet, provider, reference string, accountReference *string, amount decimal.Decimal, currency string, status domain.InboundFundsStatus) (*domain.InboundFunds, *domain.Transaction, error) {
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, nil, fmt.Errorf("transaction controller does not implement DBExecutor")
+	}
+
+	locked, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, wallet.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock wallet %d: %w", wallet.ID, err)
+	}
+	if status == domain.InboundFundsStatusCredited {
+		if _, err := s.balances.Credit(ctx, txExecutor, locked, amount); err != nil {
+			return nil, nil, err
+		}
+	} else if _, err := s.balances.UpdateBalance(ctx, txExecutor, wallet.ID, amount); err != nil {
+		return nil, nil, fmt.Errorf("failed to update wallet balance: %w", err)
+	}
+	description := fmt.Sprintf("Inbound payment %s via %s", reference, provider)
+	transaction := domain.NewTransaction(nil, &wallet.ID, amount, currency, domain.TransactionTypeDeposit, &description)
+	s.balances.Stamp(transaction)
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	funds := &domain.InboundFunds{
+		PublicID:         uuid.New(),
+		Provider:         provider,
+		Reference:        reference,
+		AccountReference: accountReference,
+		Amount:           amount,
+		Currency:         currency,
+		Status:           status,
+		WalletID:         wallet.ID,
+		WalletPublicID:   wallet.PublicID,
+		TransactionID:    transaction.PublicID,
+		CreatedAt:        transaction.CreatedAt,
+	}
+	if err := s.suspenseRepo.CreateInboundFunds(ctx, txExecutor, funds); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return funds, transaction, nil
+}
+
+// GetFunds retrieves inbound funds by their public ID.
+func (s *suspenseService) GetFunds(ctx context.Context, publicID uuid.UUID) (*domain.InboundFunds, error) {
+	funds, err := s.suspenseRepo.GetInboundFundsByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get inbound funds %s: %w", publicID, err)
+	}
+	return funds, nil
+}
+
+// ListSuspendedFunds retrieves the funds held in suspense.
+func (s *suspenseService) ListSuspendedFunds(ctx context.Context, currency string) ([]domain.InboundFunds, error) {
+	funds, err := s.suspenseRepo.ListSuspendedFunds(ctx, s.dbExecutor, currency)
+	if err != nil {
+		return nil, fmt.Errorf("list suspended funds: %w", err)
+	}
+	return funds, nil
+}
+
+// Reassign locks the funds before the wallets, so two operators reassigning the same funds cannot both move them.
+func (s *suspenseService) Reassign(ctx context.Context, publicID uuid.UUID, target *domain.Wallet, operator, reason string) (*domain.InboundFunds, *domain.Transaction, error) {
+	if operator == "" || reason == "" || len(operator) > maxInboundReferenceLength {
+		return nil, nil, fmt.Errorf("%w: operator and reason are required", util.ErrInvalidInput)
+	}
+	if target.Kind == domain.WalletKindSuspense {
+		return nil, nil, fmt.Errorf("%w: funds cannot be reassigned to a suspense wallet", util.ErrInvalidInput)
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reassign funds: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, nil, fmt.Errorf("reassign funds: transaction controller does not implement DBExecutor")
+	}
+
+	funds, err := s.suspenseRepo.GetInboundFundsForUpdate(ctx, txExecutor, publicID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reassign funds: %w", err)
+	}
+	if funds.Status != domain.InboundFundsStatusSuspended {
+		return nil, nil, util.ErrFundsNotSuspended
+	}
+	if target.Currency != funds.Currency {
+		return nil, nil, util.ErrCurrencyMismatch
+	}
+	wallets, err := lockWallets(ctx, s.walletRepo, txExecutor, funds.WalletID, target.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reassign funds: %w", err)
+	}
+	if wallets[funds.WalletID].Balance.LessThan(funds.Amount) {
+		return nil, nil, util.ErrInsufficientFunds
+	}
+
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, funds.WalletID, funds.Amount.Neg()); err != nil {
+		return nil, nil, fmt.Errorf("reassign funds: failed to update suspense wallet balance: %w", err)
+	}
+	if _, err := s.balances.Credit(ctx, txExecutor, wallets[target.ID], funds.Amount); err != nil {
+		return nil, nil, fmt.Errorf("reassign funds: %w", err)
+	}
+	description := fmt.Sprintf("Reassigned inbound payment %s", funds.Reference)
+	transaction := domain.NewTransaction(&funds.WalletID, &target.ID, funds.Amount, funds.Currency, domain.TransactionTypeAdjustment, &description)
+	s.balances.Stamp(transaction)
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, nil, fmt.Errorf("reassign funds: failed to create transaction: %w", err)
+	}
+	now := transaction.CreatedAt
+	patch := domain.TransactionAnnotationPatch{Note: &reason, UpdatedBy: &operator}
+	if _, err := s.annotationRepo.ApplyAnnotation(ctx, txExecutor, transaction.PublicID, patch, now); err != nil {
+		return nil, nil, fmt.Errorf("reassign funds: failed to annotate adjustment: %w", err)
+	}
+	if err := s.suspenseRepo.MarkReassigned(ctx, txExecutor, funds.ID, target.ID, transaction.PublicID, operator, reason, now); err != nil {
+		return nil, nil, fmt.Errorf("reassign funds: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, nil, fmt.Errorf("reassign funds: failed to commit transaction: %w", err)
+	}
+	if s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
+
+	funds.Status = domain.InboundFundsStatusReassigned
+	funds.AssignedWalletID = &target.ID
+	funds.AssignedWalletPublicID = &target.PublicID
+	funds.AdjustmentTransactionID = &transaction.PublicID
+	funds.ResolvedBy = &operator
+	funds.ResolutionReason = &reason
+	funds.ResolvedAt = &now
+	s.logger.Info("Suspense funds reassigned", "funds_id", funds.PublicID, "wallet_id", target.PublicID, "transaction_id",
+		transaction.PublicID, "amount", funds.Amount.String(), "operator", operator, "reason", reason)
+	return funds, transaction, nil
+}
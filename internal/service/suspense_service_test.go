@@ -0,0 +1,195 @@
+// internal/service/suspense_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestSuspenseService tests matching inbound funds, holding unmatched ones in suspense and reassigning them.
+func TestSuspenseService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	amount := decimal.NewFromInt(75)
+	customer := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Kind: domain.WalletKindPersonal}
+	suspense := &domain.Wallet{ID: 9, PublicID: uuid.New(), UserID: 99, Currency: "USD", Kind: domain.WalletKindSuspense, Balance: decimal.NewFromInt(500)}
+
+	type mocks struct {
+		suspenseRepo    *MockSuspenseRepository
+		walletRepo      *MockWalletRepository
+		userRepo        *MockUserRepository
+		transactionRepo *MockTransactionRepository
+		annotationRepo  *MockAnnotationRepository
+		dbExecutor      *MockDBExecutor
+		txController    *MockTxController
+	}
+	newService := func() (SuspenseService, mocks) {
+		m := mocks{
+			suspenseRepo:    new(MockSuspenseRepository),
+			walletRepo:      new(MockWalletRepository),
+			userRepo:        new(MockUserRepository),
+			transactionRepo: new(MockTransactionRepository),
+			annotationRepo:  new(MockAnnotationRepository),
+			dbExecutor:      new(MockDBExecutor),
+			txController:    new(MockTxController),
+		}
+		service := NewSuspenseService(
+			new(MockDBBeginner),
+			m.dbExecutor,
+			m.suspenseRepo,
+			m.walletRepo,
+			NewBalanceWriter(m.walletRepo, nil, nil, nil),
+			m.userRepo,
+			m.transactionRepo,
+			m.annotationRepo,
+			nil,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+	expectCredit := func(ctx context.Context, m mocks, wallet *domain.Wallet, status domain.InboundFundsStatus) {
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, wallet.ID, amount, mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeDeposit && *tx.ToWalletID == wallet.ID && tx.Amount.Equal(amount)
+		})).Return(nil).Once()
+		m.suspenseRepo.On("CreateInboundFunds", ctx, m.txController, mock.MatchedBy(func(funds *domain.InboundFunds) bool {
+			return funds.WalletID == wallet.ID && funds.Status == status && funds.Reference == "pay_1"
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+	}
+
+	t.Run("MatchedFundsCreditTheWallet", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		accountReference := customer.PublicID.String()
+
+		m.suspenseRepo.On("GetInboundFundsByReference", ctx, m.dbExecutor, "bank", "pay_1").Return(nil, util.ErrNotFound).Once()
+		m.walletRepo.On("GetWalletByPublicID", ctx, m.dbExecutor, customer.PublicID).Return(customer, nil).Once()
+		expectCredit(ctx, m, customer, domain.InboundFundsStatusCredited)
+
+		funds, created, err := service.ReceiveFunds(ctx, "bank", "pay_1", &accountReference, amount, "USD")
+
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, customer.PublicID, funds.WalletPublicID)
+		m.suspenseRepo.AssertNotCalled(t, "GetSuspenseWallet", mock.Anything, mock.Anything, mock.Anything)
+		m.suspenseRepo.AssertExpectations(t)
+		m.transactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("UnmatchedFundsCreateTheSuspenseWallet", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		accountReference := "INVOICE 4711"
+
+		m.suspenseRepo.On("GetInboundFundsByReference", ctx, m.dbExecutor, "bank", "pay_1").Return(nil, util.ErrNotFound).Once()
+		m.suspenseRepo.On("GetSuspenseWallet", ctx, m.dbExecutor, "USD").Return(nil, util.ErrNotFound).Once()
+		m.userRepo.On("GetUserByUsername", ctx, m.dbExecutor, domain.PlatformUsername).Return(&domain.User{ID: 99}, nil).Once()
+		m.walletRepo.On("CreateWallet", ctx, m.dbExecutor, mock.MatchedBy(func(wallet *domain.Wallet) bool {
+			if wallet.Kind != domain.WalletKindSuspense || wallet.UserID != 99 || wallet.Currency != "USD" {
+				return false
+			}
+			wallet.ID = suspense.ID
+			return true
+		})).Return(nil).Once()
+		expectCredit(ctx, m, suspense, domain.InboundFundsStatusSuspended)
+
+		funds, created, err := service.ReceiveFunds(ctx, "bank", "pay_1", &accountReference, amount, "USD")
+
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, domain.InboundFundsStatusSuspended, funds.Status)
+		m.walletRepo.AssertExpectations(t)
+		m.suspenseRepo.AssertExpectations(t)
+	})
+
+	t.Run("RedeliveryIsNotCreditedAgain", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		existing := &domain.InboundFunds{PublicID: uuid.New(), Provider: "bank", Reference: "pay_1", Status: domain.InboundFundsStatusSuspended}
+
+		m.suspenseRepo.On("GetInboundFundsByReference", ctx, m.dbExecutor, "bank", "pay_1").Return(existing, nil).Once()
+
+		funds, created, err := service.ReceiveFunds(ctx, "bank", "pay_1", nil, amount, "USD")
+
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Same(t, existing, funds)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ReassignMovesFundsWithAnnotatedAdjustment", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		funds := &domain.InboundFunds{ID: 5, PublicID: uuid.New(), Reference: "pay_1", Amount: amount, Currency: "USD",
+			Status: domain.InboundFundsStatusSuspended, WalletID: suspense.ID}
+
+		m.suspenseRepo.On("GetInboundFundsForUpdate", ctx, m.txController, funds.PublicID).Return(funds, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, customer.ID).Return(customer, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, suspense.ID).Return(suspense, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, suspense.ID, amount.Neg(), mock.Anything).Return(nil, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, customer.ID, amount, mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeAdjustment && *tx.FromWalletID == suspense.ID && *tx.ToWalletID == customer.ID
+		})).Return(nil).Once()
+		m.annotationRepo.On("ApplyAnnotation", ctx, m.txController, mock.Anything, mock.MatchedBy(func(patch domain.TransactionAnnotationPatch) bool {
+			return *patch.Note == "payer quoted invoice number" && *patch.UpdatedBy == "alice"
+		}), mock.Anything).Return(&domain.TransactionAnnotation{}, nil).Once()
+		m.suspenseRepo.On("MarkReassigned", ctx, m.txController, funds.ID, customer.ID, mock.Anything, "alice", "payer quoted invoice number", mock.Anything).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		reassigned, transaction, err := service.Reassign(ctx, funds.PublicID, customer, "alice", "payer quoted invoice number")
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.InboundFundsStatusReassigned, reassigned.Status)
+		assert.Equal(t, transaction.PublicID, *reassigned.AdjustmentTransactionID)
+		m.annotationRepo.AssertExpectations(t)
+		m.suspenseRepo.AssertExpectations(t)
+		m.txController.AssertExpectations(t)
+	})
+
+	t.Run("ReassignRejectsResolvedFunds", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		funds := &domain.InboundFunds{ID: 5, PublicID: uuid.New(), Amount: amount, Currency: "USD", Status: domain.InboundFundsStatusReassigned, WalletID: suspense.ID}
+
+		m.suspenseRepo.On("GetInboundFundsForUpdate", ctx, m.txController, funds.PublicID).Return(funds, nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		_, _, err := service.Reassign(ctx, funds.PublicID, customer, "alice", "duplicate request")
+
+		assert.ErrorIs(t, err, util.ErrFundsNotSuspended)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ReassignRequiresOperatorAndReason", func(t *testing.T) {
+		service, m := newService()
+
+		_, _, err := service.Reassign(context.Background(), uuid.New(), customer, "alice", "")
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.suspenseRepo.AssertNotCalled(t, "GetInboundFundsForUpdate", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
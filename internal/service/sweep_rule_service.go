@@ -0,0 +1,176 @@
+// internal/service/sweep_rule_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// SweepExecutor executes a sweep rule as an internal transfer. WalletService implements it.
+type SweepExecutor interface {
+	// ApplySweepRule moves the amount the rule calls for, if any; it returns nil when the rule does not fire.
+	ApplySweepRule(ctx context.Context, rule *domain.SweepRule) (*domain.Transaction, error)
+}
+
+// SweepRuleService defines the interface for managing sweep rules. It is also the rules engine:
+// subscribed to TransactionEvents, it applies the rules watching every wallet a transaction touched.
+type SweepRuleService interface {
+	TransactionListener
+	CreateRule(ctx context.Context, rule *domain.SweepRule) error
+	ListRules(ctx context.Context, userID int64) ([]domain.SweepRule, error)
+	DeleteRule(ctx context.Context, userID, ruleID int64) error
+}
+
+// sweepRuleService implements SweepRuleService.
+type sweepRuleService struct {
+	dbExecutor repository.DBExecutor
+	ruleRepo   repository.SweepRuleRepository
+	walletRepo repository.WalletRepository
+	executor   SweepExecutor
+	notifier   Notifier // Optional; tells users about rules that failed
+	logger     *slog.Logger
+}
+
+// NewSweepRuleService creates a new instance of SweepRuleService.
+func NewSweepRuleService(
+	dbExecutor repository.DBExecutor,
+	ruleRepo repository.SweepRuleRepository,
+	walletRepo repository.WalletRepository,
+	executor SweepExecutor,
+	notifier Notifier,
+	logger *slog.Logger,
+) SweepRuleService {
+	return &sweepRuleService{
+		dbExecutor: dbExecutor,
+		ruleRepo:   ruleRepo,
+		walletRepo: walletRepo,
+		executor:   executor,
+		notifier:   notifier,
+		logger:     logger,
+	}
+}
+
+// CreateRule validates and stores a new rule. Both wallets must belong to the rule's user and share a currency.
+func (s *sweepRuleService) CreateRule(ctx context.Context, rule *domain.SweepRule) error {
+	if rule.Kind != domain.SweepRuleKindSweep && rule.Kind != domain.SweepRuleKindTopUp {
+		return fmt.Errorf("%w: kind must be %s or %s", util.ErrInvalidInput, domain.SweepRuleKindSweep, domain.SweepRuleKindTopUp)
+	}
+	if rule.Threshold.IsNegative() {
+		return fmt.Errorf("%w: threshold must not be negative", util.ErrInvalidInput)
+	}
+	if rule.SourceWalletID == rule.TargetWalletID {
+		return util.ErrSameWalletTransfer
+	}
+
+	source, err := s.ownedWallet(ctx, rule.UserID, rule.SourceWalletID)
+	if err != nil {
+		return fmt.Errorf("create sweep rule: %w", err)
+	}
+	target, err := s.ownedWallet(ctx, rule.UserID, rule.TargetWalletID)
+	if err != nil {
+		return fmt.Errorf("create sweep rule: %w", err)
+	}
+	if source.Currency != target.Currency {
+		return util.ErrCurrencyMismatch
+	}
+
+	now := time.Now().UTC()
+	rule.SourceWalletPublicID = source.PublicID
+	rule.TargetWalletPublicID = target.PublicID
+	rule.Currency = source.Currency
+	rule.Enabled = true
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	if err := s.ruleRepo.CreateRule(ctx, s.dbExecutor, rule); err != nil {
+		return fmt.Errorf("create sweep rule: %w", err)
+	}
+	s.logger.Info("Sweep rule created", "rule_id", rule.ID, "user_id", rule.UserID, "kind", rule.Kind)
+	return nil
+}
+
+// ListRules retrieves all sweep rules of a user.
+func (s *sweepRuleService) ListRules(ctx context.Context, userID int64) ([]domain.SweepRule, error) {
+	rules, err := s.ruleRepo.ListRulesByUserID(ctx, s.dbExecutor, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list sweep rules: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteRule removes a user's rule.
+func (s *sweepRuleService) DeleteRule(ctx context.Context, userID, ruleID int64) error {
+	if err := s.ruleRepo.DeleteRule(ctx, s.dbExecutor, userID, ruleID); err != nil {
+		return fmt.Errorf("delete sweep rule %d: %w", ruleID, err)
+	}
+	s.logger.Info("Sweep rule deleted", "rule_id", ruleID, "user_id", userID)
+	return nil
+}
+
+// OnTransaction applies the rules watching the wallets a committed transaction touched.
+// Sweeps do not trigger further rules, so two rules moving money back and forth cannot loop.
+func (s *sweepRuleService) OnTransaction(ctx context.Context, transaction *domain.Transaction) {
+	if transaction.Type == domain.TransactionTypeAutoSweep {
+		return
+	}
+	for _, walletID := range []*int64{transaction.FromWalletID, transaction.ToWalletID} {
+		if walletID == nil {
+			continue
+		}
+		rules, err := s.ruleRepo.ListEnabledRulesByWatchedWallet(ctx, s.dbExecutor, *walletID)
+		if err != nil {
+			s.logger.Error("Failed to load sweep rules", "wallet_id", *walletID, "error", err)
+			continue
+		}
+		for i := range rules {
+			rule := &rules[i]
+			sweep, err := s.executor.ApplySweepRule(ctx, rule)
+			if err != nil {
+				s.logger.Warn("Sweep rule failed", "rule_id", rule.ID, "trigger_transaction_id", transaction.PublicID, "error", err)
+				s.notifyFailed(ctx, rule)
+				continue
+			}
+			if sweep != nil {
+				s.logger.Info("Sweep rule applied", "rule_id", rule.ID, "transaction_id", sweep.PublicID, "amount", sweep.Amount.String())
+			}
+		}
+	}
+}
+
+// notifyFailed tells the rule's user that it could not run, so a sweep they rely on does not silently stop.
+func (s *sweepRuleService) notifyFailed(ctx context.Context, rule *domain.SweepRule) {
+	if s.notifier == nil {
+		return
+	}
+	title, body := notificationText("notification_sweep_failed", map[string]string{"currency": rule.Currency})
+	notification := &domain.Notification{
+		UserID:   rule.UserID,
+		Category: domain.NotificationCategoryRule,
+		Title:    title,
+		Body:     body,
+		Data:     map[string]string{"rule_id": fmt.Sprint(rule.ID), "kind": string(rule.Kind)},
+	}
+	if err := s.notifier.Notify(ctx, notification); err != nil {
+		s.logger.Error("Failed to send sweep rule alert", "rule_id", rule.ID, "error", err)
+	}
+}
+
+// ownedWallet loads a wallet and checks that it belongs to the user.
+func (s *sweepRuleService) ownedWallet(ctx context.Context, userID, walletID int64) (*domain.Wallet, error) {
+	wallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, walletID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrWalletNotFound
+		}
+		return nil, err
+	}
+	if wallet.UserID != userID {
+		return nil, fmt.Errorf("%w: wallet does not belong to user %d", util.ErrInvalidInput, userID)
+	}
+	return wallet, nil
+}
@@ -0,0 +1,121 @@
+// internal/service/sweep_rule_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestSweepRuleService tests rule validation and rule evaluation after transactions.
+func TestSweepRuleService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("TransactionTriggersWatchingRules", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRuleRepo := new(MockSweepRuleRepository)
+		mockExecutor := new(MockSweepExecutor)
+		service := NewSweepRuleService(mockDBExecutor, mockRuleRepo, new(MockWalletRepository), mockExecutor, nil, logger)
+
+		from, to := int64(1), int64(2)
+		rules := []domain.SweepRule{{ID: 7, Kind: domain.SweepRuleKindTopUp, SourceWalletID: 3, TargetWalletID: 1}}
+		mockRuleRepo.On("ListEnabledRulesByWatchedWallet", ctx, mockDBExecutor, from).Return(rules, nil).Once()
+		mockRuleRepo.On("ListEnabledRulesByWatchedWallet", ctx, mockDBExecutor, to).Return([]domain.SweepRule{}, nil).Once()
+		mockExecutor.On("ApplySweepRule", ctx, &rules[0]).Return(&domain.Transaction{Amount: decimal.NewFromInt(5)}, nil).Once()
+
+		service.OnTransaction(ctx, domain.NewTransaction(&from, &to, decimal.NewFromInt(5), "USD", domain.TransactionTypeTransfer, nil))
+
+		mock.AssertExpectationsForObjects(t, mockRuleRepo, mockExecutor)
+	})
+
+	t.Run("FailedRuleNotifiesUser", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRuleRepo := new(MockSweepRuleRepository)
+		mockExecutor := new(MockSweepExecutor)
+		notifier := &collectingNotifier{}
+		service := NewSweepRuleService(mockDBExecutor, mockRuleRepo, new(MockWalletRepository), mockExecutor, notifier, logger)
+
+		from := int64(1)
+		rules := []domain.SweepRule{{ID: 7, UserID: 10, Kind: domain.SweepRuleKindSweep, SourceWalletID: 1, TargetWalletID: 3, Currency: "USD"}}
+		mockRuleRepo.On("ListEnabledRulesByWatchedWallet", ctx, mockDBExecutor, from).Return(rules, nil).Once()
+		mockExecutor.On("ApplySweepRule", ctx, &rules[0]).Return(nil, util.ErrInsufficientFunds).Once()
+
+		service.OnTransaction(ctx, domain.NewTransaction(&from, nil, decimal.NewFromInt(5), "USD", domain.TransactionTypeWithdrawal, nil))
+
+		if assert.Len(t, notifier.notifications, 1) {
+			notification := notifier.notifications[0]
+			assert.Equal(t, int64(10), notification.UserID)
+			assert.Equal(t, domain.NotificationCategoryRule, notification.Category)
+			assert.Equal(t, "7", notification.Data["rule_id"])
+		}
+	})
+
+	t.Run("SweepsDoNotCascade", func(t *testing.T) {
+		mockRuleRepo := new(MockSweepRuleRepository)
+		mockExecutor := new(MockSweepExecutor)
+		service := NewSweepRuleService(new(MockDBExecutor), mockRuleRepo, new(MockWalletRepository), mockExecutor, nil, logger)
+
+		from, to := int64(1), int64(2)
+		service.OnTransaction(context.Background(), domain.NewTransaction(&from, &to, decimal.NewFromInt(5), "USD", domain.TransactionTypeAutoSweep, nil))
+
+		mockRuleRepo.AssertNotCalled(t, "ListEnabledRulesByWatchedWallet", mock.Anything, mock.Anything, mock.Anything)
+		mockExecutor.AssertNotCalled(t, "ApplySweepRule", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateRuleRequiresOwnedWallets", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRuleRepo := new(MockSweepRuleRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		service := NewSweepRuleService(mockDBExecutor, mockRuleRepo, mockWalletRepo, new(MockSweepExecutor), nil, logger)
+
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, int64(1)).Return(&domain.Wallet{ID: 1, UserID: 9, Currency: "USD"}, nil).Once()
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, int64(2)).Return(&domain.Wallet{ID: 2, UserID: 8, Currency: "USD"}, nil).Once()
+
+		err := service.CreateRule(ctx, &domain.SweepRule{UserID: 9, Kind: domain.SweepRuleKindSweep, SourceWalletID: 1, TargetWalletID: 2})
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		mockRuleRepo.AssertNotCalled(t, "CreateRule", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("CreateRule", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockRuleRepo := new(MockSweepRuleRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		service := NewSweepRuleService(mockDBExecutor, mockRuleRepo, mockWalletRepo, new(MockSweepExecutor), nil, logger)
+
+		source := domain.NewWallet(9, "USD")
+		target := domain.NewWallet(9, "USD")
+		source.ID, target.ID = 1, 2
+		rule := &domain.SweepRule{UserID: 9, Kind: domain.SweepRuleKindSweep, SourceWalletID: 1, TargetWalletID: 2, Threshold: decimal.NewFromInt(500)}
+
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, int64(1)).Return(source, nil).Once()
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, int64(2)).Return(target, nil).Once()
+		mockRuleRepo.On("CreateRule", ctx, mockDBExecutor, rule).Return(nil).Once()
+
+		err := service.CreateRule(ctx, rule)
+
+		assert.NoError(t, err)
+		assert.True(t, rule.Enabled)
+		assert.Equal(t, source.PublicID, rule.SourceWalletPublicID)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockRuleRepo)
+	})
+
+	t.Run("InvalidKindRejected", func(t *testing.T) {
+		service := NewSweepRuleService(new(MockDBExecutor), new(MockSweepRuleRepository), new(MockWalletRepository), new(MockSweepExecutor), nil, logger)
+
+		err := service.CreateRule(context.Background(), &domain.SweepRule{Kind: "MIRROR", SourceWalletID: 1, TargetWalletID: 2})
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+	})
+}
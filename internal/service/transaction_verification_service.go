@@ -0,0 +1,140 @@
+// internal/service/transaction_verification_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+)
+
+// TransactionVerificationService checks transactions against the ledger, e.g. after an incident.
+type TransactionVerificationService interface {
+	// VerifyTransaction recomputes the balances of the transaction's wallets from their completed
+	// transactions and reports whether they, and the transaction itself, are consistent. It fails with
+	// util.ErrNotFound for an unknown transaction; corruption is reported, not returned as an error.
+	VerifyTransaction(ctx context.Context, transactionID uuid.UUID) (*domain.TransactionVerification, error)
+}
+
+type transactionVerificationService struct {
+	dbExecutor        repository.DBExecutor
+	transactionRepo   repository.TransactionRepository
+	integrityRepo     repository.IntegrityRepository
+	investigationRepo repository.InvestigationRepository
+	logger            *slog.Logger
+}
+
+// NewTransactionVerificationService creates a new instance of TransactionVerificationService.
+func NewTransactionVerificationService(
+	dbExecutor repository.DBExecutor,
+	transactionRepo repository.TransactionRepository,
+	integrityRepo repository.IntegrityRepository,
+	investigationRepo repository.InvestigationRepository,
+	logger *slog.Logger,
+) TransactionVerificationService {
+	return &transactionVerificationService{
+		dbExecutor:        dbExecutor,
+		transactionRepo:   transactionRepo,
+		integrityRepo:     integrityRepo,
+		investigationRepo: investigationRepo,
+		logger:            logger,
+	}
+}
+
+// VerifyTransaction checks that each wallet of the transaction exists in its currency and that its balance is
+// the sum of its transactions, and that the legs of a completed SPLIT add up to it. A wallet whose balance is
+// off is inconsistent whichever of its transactions caused it; the effect of the verified one is returned to
+// tell.
+func (s *transactionVerificationService) VerifyTransaction(ctx context.Context, transactionID uuid.UUID) (*domain.TransactionVerification, error) {
+	transaction, err := s.transactionRepo.GetTransactionByPublicID(ctx, s.dbExecutor, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("verify transaction: %w", err)
+	}
+	verification := &domain.TransactionVerification{
+		TransactionID: transactionID,
+		Status:        transaction.Status,
+		Wallets:       []domain.WalletVerification{},
+		Mismatches:    []string{},
+	}
+
+	sides := []struct {
+		name     string
+		walletID *int64
+	}{{domain.SideFrom, transaction.FromWalletID}, {domain.SideTo, transaction.ToWalletID}}
+	var walletIDs []int64
+	for _, side := range sides {
+		if side.walletID != nil {
+			walletIDs = append(walletIDs, *side.walletID)
+		}
+	}
+	balances := map[int64]domain.LedgerBalance{}
+	if len(walletIDs) > 0 {
+		ledger, err := s.integrityRepo.ListLedgerBalances(ctx, s.dbExecutor, walletIDs)
+		if err != nil {
+			return nil, fmt.Errorf("verify transaction: %w", err)
+		}
+		for _, balance := range ledger {
+			balances[balance.WalletID] = balance
+		}
+	}
+
+	for _, side := range sides {
+		if side.walletID == nil {
+			continue
+		}
+		balance, ok := balances[*side.walletID]
+		if !ok {
+			verification.Mismatches = append(verification.Mismatches, fmt.Sprintf("%s wallet %d does not exist", side.name, *side.walletID))
+			continue
+		}
+		wallet := domain.WalletVerification{
+			Side:          side.name,
+			WalletID:      balance.WalletPublicID,
+			Currency:      balance.Currency,
+			Effect:        transaction.LedgerEffect(side.name),
+			Balance:       balance.Balance,
+			LedgerBalance: balance.Expected,
+			Consistent:    true,
+		}
+		if balance.Currency != transaction.Currency {
+			wallet.Consistent = false
+			verification.Mismatches = append(verification.Mismatches, fmt.Sprintf("%s wallet %s holds %s, the transaction is in %s",
+				side.name, balance.WalletPublicID, balance.Currency, transaction.Currency))
+		}
+		if !balance.Balance.Equal(balance.Expected) {
+			wallet.Consistent = false
+			verification.Mismatches = append(verification.Mismatches, fmt.Sprintf("%s wallet %s: balance %s, transactions sum to %s",
+				side.name, balance.WalletPublicID, balance.Balance, balance.Expected))
+		}
+		verification.Wallets = append(verification.Wallets, wallet)
+	}
+
+	if transaction.Type == domain.TransactionTypeSplit && transaction.Status == domain.TransactionStatusCompleted {
+		legs, err := s.investigationRepo.ListChildTransactions(ctx, s.dbExecutor, transactionID)
+		if err != nil {
+			return nil, fmt.Errorf("verify transaction: %w", err)
+		}
+		total := decimal.Zero
+		for _, leg := range legs {
+			if leg.Status == domain.TransactionStatusCompleted {
+				total = total.Add(leg.Amount)
+			}
+		}
+		if !total.Equal(transaction.Amount) {
+			verification.Mismatches = append(verification.Mismatches, fmt.Sprintf("split legs sum to %s, the split is %s", total, transaction.Amount))
+		}
+	}
+
+	verification.Consistent = len(verification.Mismatches) == 0
+	verification.VerifiedAt = time.Now().UTC()
+	if !verification.Consistent {
+		s.logger.Error("Transaction is inconsistent with the ledger", "transaction", transactionID, "mismatches", verification.Mismatches)
+	}
+	return verification, nil
+}
@@ -0,0 +1,121 @@
+// internal/service/transaction_verification_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestTransactionVerificationService tests checking transactions against the ledger.
+func TestTransactionVerificationService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	transactionID := uuid.New()
+	fromWalletID, toWalletID := int64(1), int64(2)
+	fromPublicID, toPublicID := uuid.New(), uuid.New()
+	type mocks struct {
+		transactionRepo   *MockTransactionRepository
+		integrityRepo     *MockIntegrityRepository
+		investigationRepo *MockInvestigationRepository
+		dbExecutor        *MockDBExecutor
+	}
+	newService := func() (TransactionVerificationService, mocks) {
+		m := mocks{
+			transactionRepo:   new(MockTransactionRepository),
+			integrityRepo:     new(MockIntegrityRepository),
+			investigationRepo: new(MockInvestigationRepository),
+			dbExecutor:        new(MockDBExecutor),
+		}
+		return NewTransactionVerificationService(m.dbExecutor, m.transactionRepo, m.integrityRepo, m.investigationRepo, logger), m
+	}
+	transfer := func() *domain.Transaction {
+		return &domain.Transaction{
+			PublicID:     transactionID,
+			FromWalletID: &fromWalletID,
+			ToWalletID:   &toWalletID,
+			Amount:       decimal.NewFromInt(30),
+			Currency:     "USD",
+			Type:         domain.TransactionTypeTransfer,
+			Status:       domain.TransactionStatusCompleted,
+		}
+	}
+
+	t.Run("ConsistentTransfer", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.transactionRepo.On("GetTransactionByPublicID", ctx, m.dbExecutor, transactionID).Return(transfer(), nil).Once()
+		m.integrityRepo.On("ListLedgerBalances", ctx, m.dbExecutor, []int64{fromWalletID, toWalletID}).Return([]domain.LedgerBalance{
+			{WalletID: fromWalletID, WalletPublicID: fromPublicID, Currency: "USD", Balance: decimal.NewFromInt(70), Expected: decimal.NewFromInt(70)},
+			{WalletID: toWalletID, WalletPublicID: toPublicID, Currency: "USD", Balance: decimal.NewFromInt(30), Expected: decimal.NewFromInt(30)},
+		}, nil).Once()
+
+		verification, err := service.VerifyTransaction(ctx, transactionID)
+
+		require.NoError(t, err)
+		assert.True(t, verification.Consistent)
+		assert.Empty(t, verification.Mismatches)
+		require.Len(t, verification.Wallets, 2)
+		assert.Equal(t, "-30", verification.Wallets[0].Effect.String())
+		assert.Equal(t, "30", verification.Wallets[1].Effect.String())
+		mock.AssertExpectationsForObjects(t, m.transactionRepo, m.integrityRepo)
+	})
+
+	t.Run("CorruptedBalanceIsReported", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.transactionRepo.On("GetTransactionByPublicID", ctx, m.dbExecutor, transactionID).Return(transfer(), nil).Once()
+		m.integrityRepo.On("ListLedgerBalances", ctx, m.dbExecutor, []int64{fromWalletID, toWalletID}).Return([]domain.LedgerBalance{
+			{WalletID: fromWalletID, WalletPublicID: fromPublicID, Currency: "USD", Balance: decimal.NewFromInt(100), Expected: decimal.NewFromInt(70)},
+		}, nil).Once()
+
+		verification, err := service.VerifyTransaction(ctx, transactionID)
+
+		require.NoError(t, err)
+		assert.False(t, verification.Consistent)
+		require.Len(t, verification.Wallets, 1)
+		assert.False(t, verification.Wallets[0].Consistent)
+		assert.Len(t, verification.Mismatches, 2) // The balance, and the missing destination wallet
+	})
+
+	t.Run("SplitLegsMustAddUp", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		split := transfer()
+		split.Type, split.ToWalletID = domain.TransactionTypeSplit, nil
+		m.transactionRepo.On("GetTransactionByPublicID", ctx, m.dbExecutor, transactionID).Return(split, nil).Once()
+		m.integrityRepo.On("ListLedgerBalances", ctx, m.dbExecutor, []int64{fromWalletID}).Return([]domain.LedgerBalance{
+			{WalletID: fromWalletID, WalletPublicID: fromPublicID, Currency: "USD", Balance: decimal.NewFromInt(70), Expected: decimal.NewFromInt(70)},
+		}, nil).Once()
+		m.investigationRepo.On("ListChildTransactions", ctx, m.dbExecutor, transactionID).Return([]domain.Transaction{
+			{Amount: decimal.NewFromInt(10), Status: domain.TransactionStatusCompleted},
+			{Amount: decimal.NewFromInt(10), Status: domain.TransactionStatusCompleted},
+		}, nil).Once()
+
+		verification, err := service.VerifyTransaction(ctx, transactionID)
+
+		require.NoError(t, err)
+		assert.False(t, verification.Consistent)
+		assert.Equal(t, []string{"split legs sum to 20, the split is 30"}, verification.Mismatches)
+		assert.True(t, verification.Wallets[0].Effect.IsZero())
+	})
+
+	t.Run("UnknownTransactionIsNotFound", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		m.transactionRepo.On("GetTransactionByPublicID", ctx, m.dbExecutor, transactionID).Return(nil, util.ErrNotFound).Once()
+
+		_, err := service.VerifyTransaction(ctx, transactionID)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+}
@@ -0,0 +1,107 @@
+// internal/service/transfer_quote_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+type transferQuoteKey struct{}
+
+// WithTransferQuote attaches a quote to the transfer made with ctx. WalletService.Transfer then claims the
+// quote and executes the transfer at the quoted pricing, which may convert between currencies.
+func WithTransferQuote(ctx context.Context, quoteID uuid.UUID) context.Context {
+	if quoteID == uuid.Nil {
+		return ctx
+	}
+	return context.WithValue(ctx, transferQuoteKey{}, quoteID)
+}
+
+// transferQuoteID returns the quote attached to ctx, if any.
+func transferQuoteID(ctx context.Context) (uuid.UUID, bool) {
+	quoteID, ok := ctx.Value(transferQuoteKey{}).(uuid.UUID)
+	return quoteID, ok
+}
+
+// TransferQuoteService defines the interface for pricing transfers before they are made.
+type TransferQuoteService interface {
+	// QuoteTransfer prices a transfer of amount from one wallet to another without moving money. The quote
+	// is stored, and a transfer carrying its ID with WithTransferQuote is executed at the quoted pricing.
+	QuoteTransfer(ctx context.Context, from, to *domain.Wallet, amount decimal.Decimal, currency string) (*domain.TransferQuote, error)
+}
+
+// transferQuoteService implements TransferQuoteService.
+type transferQuoteService struct {
+	dbExecutor repository.DBExecutor
+	quoteRepo  repository.TransferQuoteRepository
+	fx         FXService
+	ttl        time.Duration // How long a quote can be executed
+}
+
+// NewTransferQuoteService creates a new instance of TransferQuoteService.
+func NewTransferQuoteService(dbExecutor repository.DBExecutor, quoteRepo repository.TransferQuoteRepository, fx FXService, ttl time.Duration) TransferQuoteService {
+	return &transferQuoteService{
+		dbExecutor: dbExecutor,
+		quoteRepo:  quoteRepo,
+		fx:         fx,
+		ttl:        ttl,
+	}
+}
+
+// QuoteTransfer prices a transfer. No fees are charged on transfers, so the fee is always zero; a
+// cross-currency transfer is converted at the current rate and the credit rounded down to the
+// destination currency's minor unit.
+func (s *transferQuoteService) QuoteTransfer(ctx context.Context, from, to *domain.Wallet, amount decimal.Decimal, currency string) (*domain.TransferQuote, error) {
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, util.ErrInvalidInput
+	}
+	if !amount.Equal(amount.Truncate(domain.CurrencyPrecision(currency))) {
+		return nil, fmt.Errorf("%w: amount must fit the currency's minor unit", util.ErrInvalidInput)
+	}
+	if from.ID == to.ID {
+		return nil, util.ErrSameWalletTransfer
+	}
+	if from.Currency != currency {
+		return nil, util.ErrCurrencyMismatch
+	}
+
+	now := time.Now().UTC()
+	quote := &domain.TransferQuote{
+		PublicID:           uuid.New(),
+		FromWalletID:       from.ID,
+		ToWalletID:         to.ID,
+		FromWalletPublicID: from.PublicID,
+		ToWalletPublicID:   to.PublicID,
+		Amount:             amount,
+		Currency:           currency,
+		Fee:                decimal.Zero,
+		CreditAmount:       amount,
+		CreditCurrency:     to.Currency,
+		ExpiresAt:          now.Add(s.ttl),
+		CreatedAt:          now,
+	}
+	if quote.CrossCurrency() {
+		converted, rate, err := s.fx.Convert(ctx, amount, currency, to.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("quote transfer: %w", err)
+		}
+		quote.CreditAmount = converted.RoundDown(domain.CurrencyPrecision(to.Currency))
+		quote.Rate = &rate.Rate
+		if !quote.CreditAmount.IsPositive() {
+			return nil, fmt.Errorf("%w: amount converts to less than the destination currency's minor unit", util.ErrInvalidInput)
+		}
+	}
+
+	if err := s.quoteRepo.CreateQuote(ctx, s.dbExecutor, quote); err != nil {
+		return nil, fmt.Errorf("quote transfer: %w", err)
+	}
+	return quote, nil
+}
@@ -0,0 +1,84 @@
+// internal/service/transfer_quote_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestTransferQuoteService tests pricing of same- and cross-currency transfer quotes.
+func TestTransferQuoteService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rates := []domain.FXRate{{Base: "USD", Quote: "HKD", Rate: decimal.RequireFromString("7.8125"), AsOf: time.Now().UTC()}}
+	usd := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD"}
+	otherUSD := &domain.Wallet{ID: 2, PublicID: uuid.New(), UserID: 11, Currency: "USD"}
+	hkd := &domain.Wallet{ID: 3, PublicID: uuid.New(), UserID: 11, Currency: "HKD"}
+
+	newService := func() (TransferQuoteService, *MockTransferQuoteRepository, *MockDBExecutor) {
+		mockDBExecutor := new(MockDBExecutor)
+		mockRateRepo := new(MockFXRateRepository)
+		mockRateRepo.On("ListRates", mock.Anything, mockDBExecutor).Return(rates, nil).Maybe()
+		mockQuoteRepo := new(MockTransferQuoteRepository)
+		fx := NewFXService(mockDBExecutor, mockRateRepo, time.Minute, time.Hour, logger)
+		return NewTransferQuoteService(mockDBExecutor, mockQuoteRepo, fx, 30*time.Second), mockQuoteRepo, mockDBExecutor
+	}
+
+	t.Run("SameCurrency", func(t *testing.T) {
+		ctx := context.Background()
+		service, mockQuoteRepo, mockDBExecutor := newService()
+		mockQuoteRepo.On("CreateQuote", ctx, mockDBExecutor, mock.AnythingOfType("*domain.TransferQuote")).Return(nil).Once()
+
+		quote, err := service.QuoteTransfer(ctx, usd, otherUSD, decimal.RequireFromString("12.50"), "USD")
+
+		assert.NoError(t, err)
+		assert.False(t, quote.CrossCurrency())
+		assert.Nil(t, quote.Rate)
+		assert.True(t, quote.Fee.IsZero())
+		assert.True(t, quote.CreditAmount.Equal(decimal.RequireFromString("12.50")))
+		assert.WithinDuration(t, quote.CreatedAt.Add(30*time.Second), quote.ExpiresAt, 0)
+		mockQuoteRepo.AssertExpectations(t)
+	})
+
+	t.Run("CrossCurrencyRoundsCreditDown", func(t *testing.T) {
+		ctx := context.Background()
+		service, mockQuoteRepo, mockDBExecutor := newService()
+		mockQuoteRepo.On("CreateQuote", ctx, mockDBExecutor, mock.AnythingOfType("*domain.TransferQuote")).Return(nil).Once()
+
+		quote, err := service.QuoteTransfer(ctx, usd, hkd, decimal.RequireFromString("1.01"), "USD")
+
+		assert.NoError(t, err)
+		assert.True(t, quote.CrossCurrency())
+		assert.True(t, quote.Rate.Equal(decimal.RequireFromString("7.8125")))
+		assert.True(t, quote.CreditAmount.Equal(decimal.RequireFromString("7.89")), quote.CreditAmount.String()) // 7.890625
+		assert.Equal(t, "HKD", quote.CreditCurrency)
+		mockQuoteRepo.AssertExpectations(t)
+	})
+
+	t.Run("CurrencyMismatch", func(t *testing.T) {
+		service, mockQuoteRepo, _ := newService()
+
+		_, err := service.QuoteTransfer(context.Background(), usd, hkd, decimal.NewFromInt(5), "HKD")
+
+		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
+		mockQuoteRepo.AssertNotCalled(t, "CreateQuote", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SameWallet", func(t *testing.T) {
+		service, _, _ := newService()
+
+		_, err := service.QuoteTransfer(context.Background(), usd, usd, decimal.NewFromInt(5), "USD")
+
+		assert.ErrorIs(t, err, util.ErrSameWalletTransfer)
+	})
+}
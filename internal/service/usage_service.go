@@ -0,0 +1,143 @@
+// internal/service/usage_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+type partnerKey struct{}
+
+// WithPartner attaches the ID of the partner a request was signed by to ctx; the money it moves counts
+// against the partner's volume quota.
+func WithPartner(ctx context.Context, partnerID string) context.Context {
+	if partnerID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, partnerKey{}, partnerID)
+}
+
+// PartnerFromContext returns the partner ctx acts for, or "".
+func PartnerFromContext(ctx context.Context) string {
+	partnerID, _ := ctx.Value(partnerKey{}).(string)
+	return partnerID
+}
+
+// VolumeQuotas checks money movements against the monthly volume quota of the partner requesting them.
+type VolumeQuotas interface {
+	// CheckVolume fails with util.ErrVolumeQuotaExceeded if moving amount would take the partner in ctx over
+	// its quota in the currency. Without a partner in ctx, it allows everything.
+	CheckVolume(ctx context.Context, currency string, amount decimal.Decimal) error
+}
+
+// checkVolumeQuota checks a money movement against the volume quota of the requesting partner, if the
+// wallet service enforces quotas.
+func (s *walletService) checkVolumeQuota(ctx context.Context, currency string, amount decimal.Decimal) error {
+	if s.volumeQuotas == nil {
+		return nil
+	}
+	return s.volumeQuotas.CheckVolume(ctx, currency, amount)
+}
+
+// UsageService defines the interface for metering the requests and money movements of partners per calendar
+// month and enforcing their quotas. Subscribed to TransactionEvents, it records the volume partners move.
+type UsageService interface {
+	TransactionListener
+	VolumeQuotas
+	// CountRequest counts a request of the partner and fails with util.ErrRequestQuotaExceeded if it is over
+	// its request quota for the month containing now.
+	CountRequest(ctx context.Context, partnerID string, now time.Time) error
+	// GetUsage reports the partner's usage in the month containing now, and its quota.
+	GetUsage(ctx context.Context, partnerID string, now time.Time) (*domain.PartnerUsage, domain.PartnerQuota, error)
+}
+
+// usageService implements UsageService.
+type usageService struct {
+	dbExecutor repository.DBExecutor
+	usageRepo  repository.UsageRepository
+	quotas     map[string]domain.PartnerQuota // Partner ID -> quota; partners without one are unlimited
+	now        func() time.Time
+	logger     *slog.Logger
+}
+
+// NewUsageService creates a new instance of UsageService.
+func NewUsageService(
+	dbExecutor repository.DBExecutor,
+	usageRepo repository.UsageRepository,
+	quotas map[string]domain.PartnerQuota,
+	logger *slog.Logger,
+) UsageService {
+	return &usageService{
+		dbExecutor: dbExecutor,
+		usageRepo:  usageRepo,
+		quotas:     quotas,
+		now:        time.Now,
+		logger:     logger,
+	}
+}
+
+// CountRequest counts the request before checking the quota, so refused requests show in the usage too.
+func (s *usageService) CountRequest(ctx context.Context, partnerID string, now time.Time) error {
+	requests, err := s.usageRepo.IncrementRequests(ctx, s.dbExecutor, partnerID, domain.UsageMonth(now))
+	if err != nil {
+		return fmt.Errorf("count request: %w", err)
+	}
+	if limit := s.quotas[partnerID].Requests; limit > 0 && requests > limit {
+		return fmt.Errorf("%w: partner %s made %d of %d requests", util.ErrRequestQuotaExceeded, partnerID, requests, limit)
+	}
+	return nil
+}
+
+// CheckVolume compares against the volume recorded so far. Concurrent movements of the same partner are
+// checked independently, so they can overshoot the quota by up to one movement each.
+func (s *usageService) CheckVolume(ctx context.Context, currency string, amount decimal.Decimal) error {
+	partnerID := PartnerFromContext(ctx)
+	if partnerID == "" {
+		return nil
+	}
+	limit, ok := s.quotas[partnerID].Volume[currency]
+	if !ok || !limit.IsPositive() {
+		return nil
+	}
+	usage, err := s.usageRepo.GetUsage(ctx, s.dbExecutor, partnerID, domain.UsageMonth(s.now()))
+	if err != nil {
+		return fmt.Errorf("check volume quota: %w", err)
+	}
+	if used := usage.Volume[currency]; used.Add(amount).GreaterThan(limit) {
+		return fmt.Errorf("%w: partner %s moved %s of %s %s", util.ErrVolumeQuotaExceeded, partnerID, used, limit, currency)
+	}
+	return nil
+}
+
+// GetUsage reads the partner's usage in the month.
+func (s *usageService) GetUsage(ctx context.Context, partnerID string, now time.Time) (*domain.PartnerUsage, domain.PartnerQuota, error) {
+	usage, err := s.usageRepo.GetUsage(ctx, s.dbExecutor, partnerID, domain.UsageMonth(now))
+	if err != nil {
+		return nil, domain.PartnerQuota{}, fmt.Errorf("get usage: %w", err)
+	}
+	return usage, s.quotas[partnerID], nil
+}
+
+// OnTransaction adds a completed money movement requested by a partner to its volume. The credit leg of a
+// cross-currency transfer is skipped, as its debit leg already counted the movement.
+func (s *usageService) OnTransaction(ctx context.Context, transaction *domain.Transaction) {
+	partnerID := PartnerFromContext(ctx)
+	if partnerID == "" || transaction.Status != domain.TransactionStatusCompleted {
+		return
+	}
+	if transaction.Type == domain.TransactionTypeConversion && transaction.FromWalletID == nil && transaction.ParentID != nil {
+		return
+	}
+	month := domain.UsageMonth(transaction.TransactionTime)
+	if err := s.usageRepo.AddVolume(ctx, s.dbExecutor, partnerID, month, transaction.Currency, transaction.Amount); err != nil {
+		s.logger.Error("Failed to record partner volume", "partner_id", partnerID, "transaction_id", transaction.PublicID, "error", err)
+	}
+}
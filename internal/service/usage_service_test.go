@@ -0,0 +1,88 @@
+// internal/service/usage_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestUsageService tests the metering of partner requests and volume and the enforcement of their quotas.
+func TestUsageService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	month := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	quotas := map[string]domain.PartnerQuota{
+		"acme": {Requests: 100, Volume: map[string]decimal.Decimal{"USD": decimal.NewFromInt(1000)}},
+	}
+	newService := func() (*usageService, *MockUsageRepository, *MockDBExecutor) {
+		usageRepo, dbExecutor := new(MockUsageRepository), new(MockDBExecutor)
+		service := NewUsageService(dbExecutor, usageRepo, quotas, logger).(*usageService)
+		service.now = func() time.Time { return now }
+		return service, usageRepo, dbExecutor
+	}
+
+	t.Run("CountRequestOverQuota", func(t *testing.T) {
+		ctx := context.Background()
+		service, usageRepo, dbExecutor := newService()
+		usageRepo.On("IncrementRequests", ctx, dbExecutor, "acme", month).Return(int64(100), nil).Once()
+		usageRepo.On("IncrementRequests", ctx, dbExecutor, "acme", month).Return(int64(101), nil).Once()
+
+		assert.NoError(t, service.CountRequest(ctx, "acme", now))
+		assert.ErrorIs(t, service.CountRequest(ctx, "acme", now), util.ErrRequestQuotaExceeded)
+	})
+
+	t.Run("CountRequestWithoutQuota", func(t *testing.T) {
+		ctx := context.Background()
+		service, usageRepo, dbExecutor := newService()
+		usageRepo.On("IncrementRequests", ctx, dbExecutor, "other", month).Return(int64(1_000_000), nil).Once()
+
+		assert.NoError(t, service.CountRequest(ctx, "other", now))
+	})
+
+	t.Run("CheckVolumeOverQuota", func(t *testing.T) {
+		ctx := WithPartner(context.Background(), "acme")
+		service, usageRepo, dbExecutor := newService()
+		usage := &domain.PartnerUsage{PartnerID: "acme", Month: month, Volume: map[string]decimal.Decimal{"USD": decimal.NewFromInt(900)}}
+		usageRepo.On("GetUsage", ctx, dbExecutor, "acme", month).Return(usage, nil)
+
+		assert.NoError(t, service.CheckVolume(ctx, "USD", decimal.NewFromInt(100)))
+		assert.ErrorIs(t, service.CheckVolume(ctx, "USD", decimal.NewFromInt(101)), util.ErrVolumeQuotaExceeded)
+		assert.NoError(t, service.CheckVolume(ctx, "EUR", decimal.NewFromInt(5000)))
+	})
+
+	t.Run("CheckVolumeWithoutPartner", func(t *testing.T) {
+		service, usageRepo, _ := newService()
+
+		assert.NoError(t, service.CheckVolume(context.Background(), "USD", decimal.NewFromInt(5000)))
+		usageRepo.AssertNotCalled(t, "GetUsage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("OnTransactionCountsConversionOnce", func(t *testing.T) {
+		ctx := WithPartner(context.Background(), "acme")
+		service, usageRepo, dbExecutor := newService()
+		fromWalletID, toWalletID, parentID := int64(1), int64(2), uuid.New()
+		debit := &domain.Transaction{FromWalletID: &fromWalletID, Amount: decimal.NewFromInt(100), Currency: "USD",
+			Type: domain.TransactionTypeConversion, Status: domain.TransactionStatusCompleted, TransactionTime: now}
+		credit := &domain.Transaction{ToWalletID: &toWalletID, ParentID: &parentID, Amount: decimal.NewFromInt(92), Currency: "EUR",
+			Type: domain.TransactionTypeConversion, Status: domain.TransactionStatusCompleted, TransactionTime: now}
+		usageRepo.On("AddVolume", ctx, dbExecutor, "acme", month, "USD", debit.Amount).Return(nil).Once()
+
+		service.OnTransaction(ctx, debit)
+		service.OnTransaction(ctx, credit)
+		service.OnTransaction(context.Background(), debit)
+
+		usageRepo.AssertExpectations(t)
+		usageRepo.AssertNumberOfCalls(t, "AddVolume", 1)
+	})
+}
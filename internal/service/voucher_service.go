@@ -0,0 +1,204 @@
+// internal/service/voucher_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// VoucherService defines the interface for vouchers: prepaid codes issued by operators and redeemed once into a wallet.
+type VoucherService interface {
+	// IssueVouchers creates count vouchers of the given value. The returned vouchers carry their codes,
+	// which are not stored and cannot be retrieved again.
+	IssueVouchers(ctx context.Context, amount decimal.Decimal, currency string, expiresAt time.Time, count int) ([]domain.Voucher, error)
+	// RedeemVoucher credits the voucher's value to the wallet. A code can be redeemed only once.
+	RedeemVoucher(ctx context.Context, code string, walletID int64) (*domain.Voucher, *domain.Wallet, *domain.Transaction, error)
+	// GetLiability reports the value of issued vouchers per currency at now.
+	GetLiability(ctx context.Context, now time.Time) ([]domain.VoucherLiability, error)
+}
+
+// voucherService implements VoucherService.
+type voucherService struct {
+	dbBeginner      db.DBTxBeginner
+	dbExecutor      repository.DBExecutor
+	voucherRepo     repository.VoucherRepository
+	walletRepo      repository.WalletRepository
+	balances        *BalanceWriter // Applies balance changes, on a shard for sharded wallets
+	transactionRepo repository.TransactionRepository
+	events          *TransactionEvents // Optional; redemptions are published here
+	beginTx         db.BeginTxFunc
+	commitTx        db.CommitTxFunc
+	rollbackTx      db.RollbackTxFunc
+	logger          *slog.Logger
+}
+
+// NewVoucherService creates a new instance of VoucherService.
+func NewVoucherService(
+	dbBeginner db.DBTxBeginner,
+	dbExecutor repository.DBExecutor,
+	voucherRepo repository.VoucherRepository,
+	walletRepo repository.WalletRepository,
+	balances *BalanceWriter,
+	transactionRepo repository.TransactionRepository,
+	events *TransactionEvents,
+	beginTx db.BeginTxFunc,
+	commitTx db.CommitTxFunc,
+	rollbackTx db.RollbackTxFunc,
+	logger *slog.Logger,
+) VoucherService {
+	return &voucherService{
+		dbBeginner:      dbBeginner,
+		dbExecutor:      dbExecutor,
+		voucherRepo:     voucherRepo,
+		walletRepo:      walletRepo,
+		balances:        balances,
+		transactionRepo: transactionRepo,
+		events:          events,
+		beginTx:         beginTx,
+		commitTx:        commitTx,
+		rollbackTx:      rollbackTx,
+		logger:          logger,
+	}
+}
+
+// IssueVouchers stores the whole batch in one database transaction, so either every code is issued or none is.
+func (s *voucherService) IssueVouchers(ctx context.Context, amount decimal.Decimal, currency string, expiresAt time.Time, count int) ([]domain.Voucher, error) {
+	if currency == "" || count < 1 || count > domain.MaxVouchersPerIssue {
+		return nil, fmt.Errorf("%w: currency is required and count must be between 1 and %d", util.ErrInvalidInput, domain.MaxVouchersPerIssue)
+	}
+	if !amount.IsPositive() || !amount.Equal(amount.Truncate(domain.CurrencyPrecision(currency))) {
+		return nil, fmt.Errorf("%w: amount must be positive and fit the currency's minor unit", util.ErrInvalidInput)
+	}
+	now := time.Now().UTC()
+	if !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", util.ErrInvalidInput)
+	}
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, fmt.Errorf("issue vouchers: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("issue vouchers: transaction controller does not implement DBExecutor")
+	}
+
+	vouchers := make([]domain.Voucher, 0, count)
+	for i := 0; i < count; i++ {
+		code, err := domain.NewVoucherCode()
+		if err != nil {
+			return nil, fmt.Errorf("issue vouchers: %w", err)
+		}
+		voucher := domain.Voucher{
+			PublicID:  uuid.New(),
+			Code:      code,
+			CodeHash:  domain.HashVoucherCode(code),
+			CodeHint:  domain.VoucherCodeHint(code),
+			Amount:    amount,
+			Currency:  currency,
+			Status:    domain.VoucherStatusActive,
+			ExpiresAt: expiresAt.UTC(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.voucherRepo.CreateVoucher(ctx, txExecutor, &voucher); err != nil {
+			return nil, fmt.Errorf("issue vouchers: %w", err)
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, fmt.Errorf("issue vouchers: failed to commit transaction: %w", err)
+	}
+	s.logger.Info("Vouchers issued", "count", count, "amount", amount.String(), "currency", currency, "expires_at", expiresAt)
+	return vouchers, nil
+}
+
+// RedeemVoucher claims the voucher and credits the wallet in one database transaction. The claim is a conditional
+// update, so concurrent redemptions of one code cannot both succeed, and a failed credit (e.g. a currency
+// mismatch) rolls the claim back and leaves the voucher redeemable.
+func (s *voucherService) RedeemVoucher(ctx context.Context, code string, walletID int64) (*domain.Voucher, *domain.Wallet, *domain.Transaction, error) {
+	if domain.NormalizeVoucherCode(code) == "" {
+		return nil, nil, nil, fmt.Errorf("%w: code is required", util.ErrInvalidInput)
+	}
+	codeHash := domain.HashVoucherCode(code)
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("redeem voucher: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("redeem voucher: transaction controller does not implement DBExecutor")
+	}
+
+	transactionID := uuid.New()
+	voucher, err := s.voucherRepo.ClaimVoucher(ctx, txExecutor, codeHash, walletID, transactionID, time.Now().UTC())
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			// Tell an unknown code apart from one that is used up
+			if _, lookupErr := s.voucherRepo.GetVoucherByCodeHash(ctx, txExecutor, codeHash); lookupErr == nil {
+				return nil, nil, nil, util.ErrVoucherNotRedeemable
+			}
+		}
+		return nil, nil, nil, fmt.Errorf("redeem voucher: %w", err)
+	}
+
+	wallet, err := s.walletRepo.GetWalletByIDForUpdate(ctx, txExecutor, walletID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, nil, nil, util.ErrWalletNotFound
+		}
+		return nil, nil, nil, fmt.Errorf("redeem voucher: %w", err)
+	}
+	if wallet.Currency != voucher.Currency {
+		return nil, nil, nil, util.ErrCurrencyMismatch
+	}
+
+	if _, err := s.balances.Credit(ctx, txExecutor, wallet, voucher.Amount); err != nil {
+		return nil, nil, nil, fmt.Errorf("redeem voucher: %w", err)
+	}
+	description := fmt.Sprintf("Voucher %s", voucher.PublicID)
+	transaction := domain.NewTransaction(nil, &walletID, voucher.Amount, voucher.Currency, domain.TransactionTypeVoucher, &description)
+	s.balances.Stamp(transaction)
+	transaction.PublicID = transactionID
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, nil, nil, fmt.Errorf("redeem voucher: failed to create transaction: %w", err)
+	}
+	updated, err := s.walletRepo.GetWalletByID(ctx, txExecutor, walletID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("redeem voucher: failed to re-fetch wallet: %w", err)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, nil, nil, fmt.Errorf("redeem voucher: failed to commit transaction: %w", err)
+	}
+	if s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
+	s.logger.Info("Voucher redeemed", "voucher_id", voucher.PublicID, "wallet_id", walletID, "amount", voucher.Amount.String())
+	return voucher, updated, transaction, nil
+}
+
+// GetLiability reports the value of issued vouchers per currency at now.
+func (s *voucherService) GetLiability(ctx context.Context, now time.Time) ([]domain.VoucherLiability, error) {
+	liabilities, err := s.voucherRepo.SumLiability(ctx, s.dbExecutor, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("get voucher liability: %w", err)
+	}
+	return liabilities, nil
+}
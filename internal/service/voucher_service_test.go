@@ -0,0 +1,163 @@
+// internal/service/voucher_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+	"finflow-wallet/pkg/db"
+)
+
+// TestVoucherService tests voucher issuing and single-use redemption.
+func TestVoucherService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	wallet := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 10, Currency: "USD", Balance: decimal.NewFromInt(5)}
+	code := "7kqm-x2zd 9htr-4wpa"
+	codeHash := domain.HashVoucherCode("7KQMX2ZD9HTR4WPA")
+
+	type mocks struct {
+		voucherRepo     *MockVoucherRepository
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		txController    *MockTxController
+	}
+	newService := func() (VoucherService, mocks) {
+		m := mocks{
+			voucherRepo:     new(MockVoucherRepository),
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			txController:    new(MockTxController),
+		}
+		service := NewVoucherService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			m.voucherRepo,
+			m.walletRepo,
+			NewBalanceWriter(m.walletRepo, nil, nil, nil),
+			m.transactionRepo,
+			nil,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return m.txController, nil
+			},
+			func(tx db.TxController) error {
+				return m.txController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = m.txController.Rollback()
+			},
+			logger,
+		)
+		return service, m
+	}
+	claimed := func(currency string) *domain.Voucher {
+		return &domain.Voucher{ID: 3, PublicID: uuid.New(), CodeHash: codeHash, Amount: decimal.NewFromInt(25), Currency: currency, Status: domain.VoucherStatusRedeemed}
+	}
+
+	t.Run("IssueReturnsCodesAndStoresHashes", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.voucherRepo.On("CreateVoucher", ctx, m.txController, mock.MatchedBy(func(v *domain.Voucher) bool {
+			return v.CodeHash == domain.HashVoucherCode(v.Code) && v.CodeHint == domain.VoucherCodeHint(v.Code)
+		})).Return(nil).Times(3)
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		vouchers, err := service.IssueVouchers(ctx, decimal.NewFromInt(25), "USD", time.Now().Add(time.Hour), 3)
+
+		assert.NoError(t, err)
+		assert.Len(t, vouchers, 3)
+		assert.NotEqual(t, vouchers[0].Code, vouchers[1].Code)
+		assert.Len(t, vouchers[0].Code, 19)
+		mock.AssertExpectationsForObjects(t, m.voucherRepo, m.txController)
+	})
+
+	t.Run("IssueRejectsPastExpiry", func(t *testing.T) {
+		service, m := newService()
+
+		_, err := service.IssueVouchers(context.Background(), decimal.NewFromInt(25), "USD", time.Now().Add(-time.Minute), 1)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		m.voucherRepo.AssertNotCalled(t, "CreateVoucher", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RedeemCreditsWallet", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		voucher := claimed("USD")
+		updated := *wallet
+		updated.Balance = wallet.Balance.Add(voucher.Amount)
+
+		m.voucherRepo.On("ClaimVoucher", ctx, m.txController, codeHash, wallet.ID, mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("time.Time")).Return(voucher, nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, wallet.ID, voucher.Amount, mock.Anything).Return(nil, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeVoucher && tx.FromWalletID == nil && *tx.ToWalletID == wallet.ID
+		})).Return(nil).Once()
+		m.walletRepo.On("GetWalletByID", ctx, m.txController, wallet.ID).Return(&updated, nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		_, resWallet, transaction, err := service.RedeemVoucher(ctx, code, wallet.ID)
+
+		assert.NoError(t, err)
+		assert.True(t, resWallet.Balance.Equal(decimal.NewFromInt(30)))
+		// The claim recorded the ID of the transaction that was then created
+		claimedID := m.voucherRepo.Calls[0].Arguments.Get(4).(uuid.UUID)
+		assert.Equal(t, claimedID, transaction.PublicID)
+		mock.AssertExpectationsForObjects(t, m.voucherRepo, m.walletRepo, m.transactionRepo, m.txController)
+	})
+
+	t.Run("SecondRedemptionRejected", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.voucherRepo.On("ClaimVoucher", ctx, m.txController, codeHash, wallet.ID, mock.Anything, mock.Anything).Return(nil, util.ErrNotFound).Once()
+		m.voucherRepo.On("GetVoucherByCodeHash", ctx, m.txController, codeHash).Return(claimed("USD"), nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, _, _, err := service.RedeemVoucher(ctx, code, wallet.ID)
+
+		assert.ErrorIs(t, err, util.ErrVoucherNotRedeemable)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, m.voucherRepo, m.txController)
+	})
+
+	t.Run("UnknownCodeNotFound", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.voucherRepo.On("ClaimVoucher", ctx, m.txController, codeHash, wallet.ID, mock.Anything, mock.Anything).Return(nil, util.ErrNotFound).Once()
+		m.voucherRepo.On("GetVoucherByCodeHash", ctx, m.txController, codeHash).Return(nil, util.ErrNotFound).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, _, _, err := service.RedeemVoucher(ctx, code, wallet.ID)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+	})
+
+	t.Run("CurrencyMismatchRollsBackClaim", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.voucherRepo.On("ClaimVoucher", ctx, m.txController, codeHash, wallet.ID, mock.Anything, mock.Anything).Return(claimed("EUR"), nil).Once()
+		m.walletRepo.On("GetWalletByIDForUpdate", ctx, m.txController, wallet.ID).Return(wallet, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, _, _, err := service.RedeemVoucher(ctx, code, wallet.ID)
+
+		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
+		m.txController.AssertNotCalled(t, "Commit")
+		mock.AssertExpectationsForObjects(t, m.voucherRepo, m.walletRepo, m.txController)
+	})
+}
@@ -0,0 +1,270 @@
+// internal/service/wallet_export_service.go
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/i18n"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// WalletExportService defines the interface for asynchronous full-history exports of a wallet. Requests are
+// queued and answered at once; a background worker writes the CSV file, and the finished file is downloaded
+// through a signed, expiring URL.
+type WalletExportService interface {
+	// RequestExport queues an export of the wallet's full transaction history, with readable descriptions
+	// in the context's locale.
+	RequestExport(ctx context.Context, wallet *domain.Wallet) (*domain.WalletExport, error)
+	GetExport(ctx context.Context, publicID uuid.UUID) (*domain.WalletExport, error)
+	// Work processes queued exports until the queue is empty and returns the number processed.
+	Work(ctx context.Context, now time.Time) (int, error)
+	// DownloadURL returns a signed download URL of a completed export and when it expires.
+	DownloadURL(export *domain.WalletExport, now time.Time) (string, time.Time)
+	// OpenDownload checks a download URL's signature and opens the export's file.
+	OpenDownload(ctx context.Context, publicID uuid.UUID, expires int64, signature string, now time.Time) (*domain.WalletExport, io.ReadCloser, error)
+}
+
+// walletExportService implements WalletExportService.
+type walletExportService struct {
+	dbExecutor   repository.DBExecutor
+	exportRepo   repository.WalletExportRepository
+	store        ObjectStore
+	descriptions DescriptionRenderer
+	policy       Policy       // Optional; requesting an export is a history read
+	audit        AuditService // Optional; completed exports record the head of the wallet's audit chain
+	signingKey   []byte
+	urlTTL       time.Duration // Lifetime of a download URL
+	staleAfter   time.Duration // A RUNNING export older than this is presumed abandoned and requeued
+	pageSize     int
+	logger       *slog.Logger
+}
+
+// NewWalletExportService creates a new instance of WalletExportService.
+func NewWalletExportService(
+	dbExecutor repository.DBExecutor,
+	exportRepo repository.WalletExportRepository,
+	store ObjectStore,
+	descriptions DescriptionRenderer,
+	policy Policy,
+	audit AuditService,
+	signingKey []byte,
+	urlTTL time.Duration,
+	staleAfter time.Duration,
+	pageSize int,
+	logger *slog.Logger,
+) WalletExportService {
+	return &walletExportService{
+		dbExecutor:   dbExecutor,
+		exportRepo:   exportRepo,
+		store:        store,
+		descriptions: descriptions,
+		policy:       policy,
+		audit:        audit,
+		signingKey:   signingKey,
+		urlTTL:       urlTTL,
+		staleAfter:   staleAfter,
+		pageSize:     pageSize,
+		logger:       logger,
+	}
+}
+
+func (s *walletExportService) RequestExport(ctx context.Context, wallet *domain.Wallet) (*domain.WalletExport, error) {
+	if s.policy != nil {
+		err := s.policy.Authorize(ctx, PolicyRequest{Subject: SubjectFromContext(ctx), Action: ActionReadHistory, Resource: wallet, Amount: decimal.Zero})
+		if err != nil {
+			return nil, fmt.Errorf("request wallet export: %w", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	export := &domain.WalletExport{
+		PublicID:       uuid.New(),
+		WalletID:       wallet.ID,
+		WalletPublicID: wallet.PublicID,
+		Locale:         i18n.LocaleFromContext(ctx),
+		Status:         domain.WalletExportStatusPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.exportRepo.CreateExport(ctx, s.dbExecutor, export); err != nil {
+		return nil, fmt.Errorf("request wallet export: %w", err)
+	}
+	s.logger.Info("Wallet export requested", "export_id", export.PublicID, "wallet_id", wallet.PublicID)
+	return export, nil
+}
+
+func (s *walletExportService) GetExport(ctx context.Context, publicID uuid.UUID) (*domain.WalletExport, error) {
+	export, err := s.exportRepo.GetExportByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get wallet export: %w", err)
+	}
+	return export, nil
+}
+
+// Work first requeues exports abandoned by a worker that stopped mid-way. A failing export is marked FAILED
+// and the worker moves on to the next one.
+func (s *walletExportService) Work(ctx context.Context, now time.Time) (int, error) {
+	requeued, err := s.exportRepo.RequeueStaleExports(ctx, s.dbExecutor, now.Add(-s.staleAfter))
+	if err != nil {
+		return 0, fmt.Errorf("wallet export worker: %w", err)
+	}
+	if requeued > 0 {
+		s.logger.Warn("Stale wallet exports requeued", "count", requeued)
+	}
+
+	processed := 0
+	for ctx.Err() == nil {
+		export, err := s.exportRepo.ClaimNextExport(ctx, s.dbExecutor, time.Now().UTC())
+		if errors.Is(err, util.ErrNotFound) {
+			return processed, nil
+		}
+		if err != nil {
+			return processed, fmt.Errorf("wallet export worker: %w", err)
+		}
+		processed++
+
+		key, auditHead, err := s.write(ctx, export)
+		if err != nil {
+			s.logger.Error("Wallet export failed", "export_id", export.PublicID, "error", err)
+			if err := s.exportRepo.FailExport(ctx, s.dbExecutor, export.ID, "the export could not be written", time.Now().UTC()); err != nil {
+				return processed, fmt.Errorf("wallet export worker: %w", err)
+			}
+			continue
+		}
+		if err := s.exportRepo.CompleteExport(ctx, s.dbExecutor, export.ID, key, auditHead, time.Now().UTC()); err != nil {
+			return processed, fmt.Errorf("wallet export worker: %w", err)
+		}
+		s.logger.Info("Wallet export completed", "export_id", export.PublicID, "key", key)
+	}
+	return processed, ctx.Err()
+}
+
+// write streams the wallet's transactions into the object store page by page, recording progress after each
+// page, so a large history is never held in memory. It returns the file's key and the head of the wallet's
+// audit chain, taken before the first page so that it vouches only for events recorded before the file.
+func (s *walletExportService) write(ctx context.Context, export *domain.WalletExport) (string, *domain.AuditChainHead, error) {
+	var auditHead *domain.AuditChainHead
+	if s.audit != nil {
+		head, err := s.audit.ChainHead(ctx, export.WalletID)
+		if err != nil {
+			return "", nil, err
+		}
+		auditHead = head
+	}
+	total, err := s.exportRepo.CountWalletTransactions(ctx, s.dbExecutor, export.WalletID)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.exportRepo.UpdateProgress(ctx, s.dbExecutor, export.ID, 0, total); err != nil {
+		return "", nil, err
+	}
+
+	key := fmt.Sprintf("wallet-exports/%s/%s.csv", export.WalletPublicID, export.PublicID)
+	pr, pw := io.Pipe()
+	written := make(chan error, 1)
+	go func() {
+		err := s.writeRecords(ctx, export, total, pw)
+		pw.CloseWithError(err)
+		written <- err
+	}()
+	putErr := s.store.Put(ctx, key, pr, "text/csv")
+	pr.CloseWithError(putErr) // Unblocks the writer if the store gave up early
+	if err := <-written; err != nil {
+		return "", nil, err
+	}
+	if putErr != nil {
+		return "", nil, fmt.Errorf("failed to store %s: %w", key, putErr)
+	}
+	return key, auditHead, nil
+}
+
+// writeRecords writes the columns of the warehouse export followed by each transaction's readable description,
+// rendered in the export's locale as seen from its wallet.
+func (s *walletExportService) writeRecords(ctx context.Context, export *domain.WalletExport, total int64, out io.Writer) error {
+	localized := i18n.WithLocale(ctx, export.Locale)
+	w := csv.NewWriter(out)
+	if err := w.Write(append(slices.Clone(transactionExportHeader), "display_description")); err != nil {
+		return err
+	}
+	processed, afterID := int64(0), int64(0)
+	for {
+		transactions, err := s.exportRepo.ListWalletTransactionsAfter(ctx, s.dbExecutor, export.WalletID, afterID, s.pageSize)
+		if err != nil {
+			return err
+		}
+		for i := range transactions {
+			record := append(transactionExportRecord(&transactions[i]), s.descriptions.Describe(localized, &transactions[i], export.WalletID))
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+		if len(transactions) == 0 {
+			return nil
+		}
+
+		processed += int64(len(transactions))
+		afterID = transactions[len(transactions)-1].ID
+		// Transactions created after the count was taken are exported too
+		if err := s.exportRepo.UpdateProgress(ctx, s.dbExecutor, export.ID, processed, max(total, processed)); err != nil {
+			return err
+		}
+		if len(transactions) < s.pageSize {
+			return nil
+		}
+	}
+}
+
+func (s *walletExportService) DownloadURL(export *domain.WalletExport, now time.Time) (string, time.Time) {
+	expiresAt := now.Add(s.urlTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	return fmt.Sprintf("/exports/%s/download?expires=%d&signature=%s", export.PublicID, expires, s.sign(export.PublicID, expires)), expiresAt
+}
+
+func (s *walletExportService) OpenDownload(ctx context.Context, publicID uuid.UUID, expires int64, signature string, now time.Time) (*domain.WalletExport, io.ReadCloser, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(publicID, expires))) {
+		return nil, nil, fmt.Errorf("%w: invalid download signature", util.ErrForbidden)
+	}
+	if now.Unix() >= expires {
+		return nil, nil, fmt.Errorf("%w: download URL expired", util.ErrForbidden)
+	}
+
+	export, err := s.exportRepo.GetExportByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open wallet export: %w", err)
+	}
+	if export.Status != domain.WalletExportStatusCompleted || export.ObjectKey == nil {
+		return nil, nil, util.ErrNotFound
+	}
+	body, err := s.store.Get(ctx, *export.ObjectKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open wallet export: %w", err)
+	}
+	return export, body, nil
+}
+
+// sign returns the hex HMAC-SHA256 of the export ID and expiry.
+func (s *walletExportService) sign(publicID uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(publicID.String() + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
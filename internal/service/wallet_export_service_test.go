@@ -0,0 +1,168 @@
+// internal/service/wallet_export_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestWalletExportService tests queueing, background writing and signed downloads of wallet exports.
+func TestWalletExportService(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	wallet := &domain.Wallet{ID: 7, PublicID: uuid.New(), UserID: 1, Currency: "USD"}
+	newService := func() (WalletExportService, *MockWalletExportRepository, *MockObjectStore, *MockDBExecutor) {
+		exportRepo := new(MockWalletExportRepository)
+		store := new(MockObjectStore)
+		dbExecutor := new(MockDBExecutor)
+		service := NewWalletExportService(dbExecutor, exportRepo, store, NewDescriptionRenderer(nil), AllowOwnerPolicy{}, nil, []byte("secret"), 15*time.Minute, 30*time.Minute, 2, logger)
+		return service, exportRepo, store, dbExecutor
+	}
+	newTransaction := func(id int64) domain.Transaction {
+		return domain.Transaction{
+			ID:               id,
+			PublicID:         uuid.New(),
+			ToWalletPublicID: &wallet.PublicID,
+			Amount:           decimal.NewFromInt(id),
+			Currency:         "USD",
+			Type:             domain.TransactionTypeDeposit,
+			Status:           domain.TransactionStatusCompleted,
+			TransactionTime:  now,
+			CreatedAt:        now,
+		}
+	}
+
+	t.Run("RequestIsQueued", func(t *testing.T) {
+		ctx := context.Background()
+		service, exportRepo, _, dbExecutor := newService()
+		exportRepo.On("CreateExport", ctx, dbExecutor, mock.MatchedBy(func(e *domain.WalletExport) bool {
+			return e.WalletID == wallet.ID && e.Status == domain.WalletExportStatusPending
+		})).Return(nil).Once()
+
+		export, err := service.RequestExport(ctx, wallet)
+
+		assert.NoError(t, err)
+		assert.Equal(t, wallet.PublicID, export.WalletPublicID)
+		exportRepo.AssertExpectations(t)
+	})
+
+	t.Run("RequestIsAuthorizedAsHistoryRead", func(t *testing.T) {
+		service, exportRepo, _, _ := newService()
+		ctx := WithSubject(context.Background(), Subject{Kind: SubjectUser, UserID: 2})
+
+		_, err := service.RequestExport(ctx, wallet)
+
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		exportRepo.AssertNotCalled(t, "CreateExport", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("WorkWritesPagesAndCompletes", func(t *testing.T) {
+		ctx := context.Background()
+		service, exportRepo, store, dbExecutor := newService()
+		export := &domain.WalletExport{ID: 3, PublicID: uuid.New(), WalletID: wallet.ID, WalletPublicID: wallet.PublicID, Status: domain.WalletExportStatusRunning}
+		key := "wallet-exports/" + wallet.PublicID.String() + "/" + export.PublicID.String() + ".csv"
+		txs := []domain.Transaction{newTransaction(1), newTransaction(2), newTransaction(3)}
+
+		exportRepo.On("RequeueStaleExports", ctx, dbExecutor, now.Add(-30*time.Minute)).Return(int64(0), nil).Once()
+		exportRepo.On("ClaimNextExport", ctx, dbExecutor, mock.Anything).Return(export, nil).Once()
+		exportRepo.On("CountWalletTransactions", ctx, dbExecutor, wallet.ID).Return(int64(3), nil).Once()
+		exportRepo.On("UpdateProgress", ctx, dbExecutor, export.ID, int64(0), int64(3)).Return(nil).Once()
+		exportRepo.On("ListWalletTransactionsAfter", ctx, dbExecutor, wallet.ID, int64(0), 2).Return(txs[:2], nil).Once()
+		exportRepo.On("UpdateProgress", ctx, dbExecutor, export.ID, int64(2), int64(3)).Return(nil).Once()
+		exportRepo.On("ListWalletTransactionsAfter", ctx, dbExecutor, wallet.ID, int64(2), 2).Return(txs[2:], nil).Once()
+		exportRepo.On("UpdateProgress", ctx, dbExecutor, export.ID, int64(3), int64(3)).Return(nil).Once()
+		store.On("Put", ctx, key, mock.MatchedBy(func(body string) bool {
+			lines := strings.Split(strings.TrimSpace(body), "\n")
+			return len(lines) == 4 && strings.HasPrefix(lines[3], txs[2].PublicID.String()) && strings.HasSuffix(lines[3], ",Top-up")
+		}), "text/csv").Return(nil).Once()
+		exportRepo.On("CompleteExport", ctx, dbExecutor, export.ID, key, (*domain.AuditChainHead)(nil), mock.Anything).Return(nil).Once()
+		exportRepo.On("ClaimNextExport", ctx, dbExecutor, mock.Anything).Return(nil, util.ErrNotFound).Once()
+
+		processed, err := service.Work(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		mock.AssertExpectationsForObjects(t, exportRepo, store)
+	})
+
+	t.Run("FailedExportIsMarkedAndWorkContinues", func(t *testing.T) {
+		ctx := context.Background()
+		service, exportRepo, _, dbExecutor := newService()
+		export := &domain.WalletExport{ID: 4, PublicID: uuid.New(), WalletID: wallet.ID, WalletPublicID: wallet.PublicID}
+
+		exportRepo.On("RequeueStaleExports", ctx, dbExecutor, mock.Anything).Return(int64(1), nil).Once()
+		exportRepo.On("ClaimNextExport", ctx, dbExecutor, mock.Anything).Return(export, nil).Once()
+		exportRepo.On("CountWalletTransactions", ctx, dbExecutor, wallet.ID).Return(int64(0), errors.New("connection reset")).Once()
+		exportRepo.On("FailExport", ctx, dbExecutor, export.ID, mock.Anything, mock.Anything).Return(nil).Once()
+		exportRepo.On("ClaimNextExport", ctx, dbExecutor, mock.Anything).Return(nil, util.ErrNotFound).Once()
+
+		processed, err := service.Work(ctx, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		exportRepo.AssertExpectations(t)
+	})
+
+	t.Run("SignedURLOpensDownload", func(t *testing.T) {
+		ctx := context.Background()
+		service, exportRepo, store, dbExecutor := newService()
+		key := "wallet-exports/a.csv"
+		export := &domain.WalletExport{ID: 5, PublicID: uuid.New(), Status: domain.WalletExportStatusCompleted, ObjectKey: &key}
+
+		rawURL, expiresAt := service.DownloadURL(export, now)
+		assert.Equal(t, now.Add(15*time.Minute), expiresAt)
+		parsed, err := url.Parse(rawURL)
+		assert.NoError(t, err)
+		assert.Equal(t, "/exports/"+export.PublicID.String()+"/download", parsed.Path)
+		expires, _ := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+		signature := parsed.Query().Get("signature")
+
+		exportRepo.On("GetExportByPublicID", ctx, dbExecutor, export.PublicID).Return(export, nil).Once()
+		store.On("Get", ctx, key).Return("id\n", nil).Once()
+
+		_, body, err := service.OpenDownload(ctx, export.PublicID, expires, signature, now.Add(time.Minute))
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(body)
+		assert.Equal(t, "id\n", string(data))
+
+		// A tampered expiry, another export's ID and an expired URL are all rejected before any lookup
+		_, _, err = service.OpenDownload(ctx, export.PublicID, expires+3600, signature, now)
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		_, _, err = service.OpenDownload(ctx, uuid.New(), expires, signature, now)
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		_, _, err = service.OpenDownload(ctx, export.PublicID, expires, signature, expiresAt)
+		assert.ErrorIs(t, err, util.ErrForbidden)
+		mock.AssertExpectationsForObjects(t, exportRepo, store)
+	})
+
+	t.Run("UnfinishedExportCannotBeDownloaded", func(t *testing.T) {
+		ctx := context.Background()
+		service, exportRepo, store, dbExecutor := newService()
+		export := &domain.WalletExport{ID: 6, PublicID: uuid.New(), Status: domain.WalletExportStatusRunning}
+		rawURL, _ := service.DownloadURL(export, now)
+		parsed, _ := url.Parse(rawURL)
+		expires, _ := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+
+		exportRepo.On("GetExportByPublicID", ctx, dbExecutor, export.PublicID).Return(export, nil).Once()
+
+		_, _, err := service.OpenDownload(ctx, export.PublicID, expires, parsed.Query().Get("signature"), now)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		store.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,274 @@
+// internal/service/wallet_serialization_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/repository"
+	"finflow-wallet/internal/util"
+)
+
+// WalletSerializer orders the money movements of contended wallets. It is the narrow view the wallet
+// service depends on.
+type WalletSerializer interface {
+	// Enter waits for the caller's turn in the queues of the QUEUE wallets among walletIDs. leave must be
+	// called once the movement is committed or abandoned; it hands the turn to the next caller.
+	Enter(ctx context.Context, walletIDs ...int64) (leave func(), err error)
+	// Lock takes the advisory locks of the ADVISORY_LOCK wallets among walletIDs in the transaction q.
+	Lock(ctx context.Context, q repository.DBExecutor, walletIDs ...int64) error
+}
+
+// WalletQueueStats describes the in-process queue of a QUEUE wallet since the process started.
+type WalletQueueStats struct {
+	Depth    int           // Movements queued now, including the one whose turn it is
+	MaxDepth int           // Deepest the queue has been
+	Entered  int64         // Movements that got their turn
+	AvgWait  time.Duration // Average wait for the turn
+}
+
+// WalletSerializationStatus is the serialization mode of a wallet with the state of its queue.
+type WalletSerializationStatus struct {
+	domain.WalletSerialization
+	Queue *WalletQueueStats // Set for wallets that have queued in this process
+}
+
+// WalletSerializationService manages the serialization modes of contended wallets and applies them.
+type WalletSerializationService interface {
+	WalletSerializer
+	ListSerializations(ctx context.Context) ([]WalletSerializationStatus, error)
+	SetSerialization(ctx context.Context, wallet *domain.Wallet, mode domain.SerializationMode, updatedBy string) (*domain.WalletSerialization, error)
+	DeleteSerialization(ctx context.Context, walletID int64, deletedBy string) error
+}
+
+// walletQueue is the FIFO queue of one wallet. The caller whose channel is first holds the turn; its
+// channel is closed when it gets it.
+type walletQueue struct {
+	waiters   []chan struct{}
+	maxDepth  int
+	entered   int64
+	totalWait time.Duration
+}
+
+// walletSerializationService implements WalletSerializationService with an in-memory cache of the modes,
+// reloaded when older than the TTL and dropped on every write, like the feature flags. The queues are per
+// process: with several replicas, only ADVISORY_LOCK orders all movements of a wallet.
+type walletSerializationService struct {
+	dbExecutor        repository.DBExecutor
+	serializationRepo repository.WalletSerializationRepository
+	ttl               time.Duration
+	logger            *slog.Logger
+
+	mu       sync.RWMutex
+	modes    map[int64]domain.SerializationMode
+	loadedAt time.Time
+
+	queuesMu sync.Mutex
+	queues   map[int64]*walletQueue
+}
+
+// NewWalletSerializationService creates a new instance of WalletSerializationService.
+func NewWalletSerializationService(dbExecutor repository.DBExecutor, serializationRepo repository.WalletSerializationRepository, ttl time.Duration, logger *slog.Logger) WalletSerializationService {
+	return &walletSerializationService{
+		dbExecutor:        dbExecutor,
+		serializationRepo: serializationRepo,
+		ttl:               ttl,
+		logger:            logger,
+		queues:            map[int64]*walletQueue{},
+	}
+}
+
+// Enter queues the caller on each QUEUE wallet in ascending ID order, so two movements between the same
+// wallets cannot wait for each other.
+func (s *walletSerializationService) Enter(ctx context.Context, walletIDs ...int64) (func(), error) {
+	queued := s.walletsInMode(ctx, domain.SerializationQueue, walletIDs)
+	leaves := make([]func(), 0, len(queued))
+	leaveAll := func() {
+		for _, leave := range slices.Backward(leaves) {
+			leave()
+		}
+	}
+	for _, walletID := range queued {
+		leave, err := s.enterQueue(ctx, walletID)
+		if err != nil {
+			leaveAll()
+			return nil, err
+		}
+		leaves = append(leaves, leave)
+	}
+	return leaveAll, nil
+}
+
+// Lock takes the advisory locks in ascending wallet ID order, for the same reason.
+func (s *walletSerializationService) Lock(ctx context.Context, q repository.DBExecutor, walletIDs ...int64) error {
+	for _, walletID := range s.walletsInMode(ctx, domain.SerializationAdvisoryLock, walletIDs) {
+		if err := s.serializationRepo.LockWallet(ctx, q, walletID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enterQueue waits until the caller is first in the wallet's queue, or until ctx is done.
+func (s *walletSerializationService) enterQueue(ctx context.Context, walletID int64) (func(), error) {
+	turn := make(chan struct{})
+	s.queuesMu.Lock()
+	queue := s.queues[walletID]
+	if queue == nil {
+		queue = &walletQueue{}
+		s.queues[walletID] = queue
+	}
+	queue.waiters = append(queue.waiters, turn)
+	queue.maxDepth = max(queue.maxDepth, len(queue.waiters))
+	if len(queue.waiters) == 1 {
+		close(turn)
+	}
+	s.queuesMu.Unlock()
+
+	start := time.Now()
+	select {
+	case <-turn:
+	case <-ctx.Done():
+		s.leaveQueue(walletID, turn)
+		return nil, fmt.Errorf("wait for turn on wallet %d: %w", walletID, ctx.Err())
+	}
+
+	s.queuesMu.Lock()
+	queue.entered++
+	queue.totalWait += time.Since(start)
+	s.queuesMu.Unlock()
+	var once sync.Once
+	return func() { once.Do(func() { s.leaveQueue(walletID, turn) }) }, nil
+}
+
+// leaveQueue removes the caller from the wallet's queue, handing the turn on if it held it.
+func (s *walletSerializationService) leaveQueue(walletID int64, turn chan struct{}) {
+	s.queuesMu.Lock()
+	defer s.queuesMu.Unlock()
+	queue := s.queues[walletID]
+	i := slices.Index(queue.waiters, turn)
+	if i < 0 {
+		return
+	}
+	queue.waiters = slices.Delete(queue.waiters, i, i+1)
+	if i == 0 && len(queue.waiters) > 0 {
+		close(queue.waiters[0])
+	}
+}
+
+// walletsInMode returns the distinct wallets among walletIDs that have the mode, in ascending order. If the
+// modes cannot be loaded, the last known modes are used.
+func (s *walletSerializationService) walletsInMode(ctx context.Context, mode domain.SerializationMode, walletIDs []int64) []int64 {
+	modes, err := s.cachedModes(ctx)
+	if err != nil {
+		s.logger.Error("Failed to refresh wallet serialization modes, using cached values", "error", err)
+	}
+	var matching []int64
+	for _, walletID := range walletIDs {
+		if modes[walletID] == mode {
+			matching = append(matching, walletID)
+		}
+	}
+	slices.Sort(matching)
+	return slices.Compact(matching)
+}
+
+// ListSerializations returns the mode of every wallet that has one, bypassing the cache, with the state of
+// its queue in this process.
+func (s *walletSerializationService) ListSerializations(ctx context.Context) ([]WalletSerializationStatus, error) {
+	serializations, err := s.serializationRepo.ListSerializations(ctx, s.dbExecutor)
+	if err != nil {
+		return nil, fmt.Errorf("list wallet serializations: %w", err)
+	}
+	statuses := make([]WalletSerializationStatus, len(serializations))
+	s.queuesMu.Lock()
+	defer s.queuesMu.Unlock()
+	for i, serialization := range serializations {
+		statuses[i] = WalletSerializationStatus{WalletSerialization: serialization}
+		if queue := s.queues[serialization.WalletID]; queue != nil {
+			stats := WalletQueueStats{Depth: len(queue.waiters), MaxDepth: queue.maxDepth, Entered: queue.entered}
+			if queue.entered > 0 {
+				stats.AvgWait = queue.totalWait / time.Duration(queue.entered)
+			}
+			statuses[i].Queue = &stats
+		}
+	}
+	return statuses, nil
+}
+
+// SetSerialization chooses the serialization mode of a wallet.
+func (s *walletSerializationService) SetSerialization(ctx context.Context, wallet *domain.Wallet, mode domain.SerializationMode, updatedBy string) (*domain.WalletSerialization, error) {
+	mode = domain.SerializationMode(strings.ToUpper(string(mode)))
+	if !mode.Valid() {
+		return nil, fmt.Errorf("%w: mode must be QUEUE or ADVISORY_LOCK", util.ErrInvalidInput)
+	}
+	serialization := &domain.WalletSerialization{
+		WalletID:       wallet.ID,
+		WalletPublicID: wallet.PublicID,
+		Mode:           mode,
+		UpdatedBy:      updatedBy,
+		UpdatedAt:      time.Now().UTC(),
+	}
+	if err := s.serializationRepo.SaveSerialization(ctx, s.dbExecutor, serialization); err != nil {
+		return nil, fmt.Errorf("set wallet serialization: %w", err)
+	}
+	s.invalidate()
+	s.logger.Info("Wallet serialization set", "wallet_id", wallet.ID, "mode", mode, "updated_by", updatedBy)
+	return serialization, nil
+}
+
+// DeleteSerialization returns a wallet to plain row locking. The mode is gone afterwards, so the deleting
+// admin is logged.
+func (s *walletSerializationService) DeleteSerialization(ctx context.Context, walletID int64, deletedBy string) error {
+	if deletedBy == "" {
+		return util.ErrForbidden
+	}
+	if err := s.serializationRepo.DeleteSerialization(ctx, s.dbExecutor, walletID); err != nil {
+		return fmt.Errorf("delete wallet serialization of %d: %w", walletID, err)
+	}
+	s.invalidate()
+	s.logger.Info("Wallet serialization removed", "wallet_id", walletID, "deleted_by", deletedBy)
+	return nil
+}
+
+// cachedModes returns the cached modes by wallet ID, reloading them once the cache has expired.
+// On a failed reload the stale modes are returned together with the error.
+func (s *walletSerializationService) cachedModes(ctx context.Context) (map[int64]domain.SerializationMode, error) {
+	s.mu.RLock()
+	modes, fresh := s.modes, s.modes != nil && time.Since(s.loadedAt) < s.ttl
+	s.mu.RUnlock()
+	if fresh {
+		return modes, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.modes != nil && time.Since(s.loadedAt) < s.ttl {
+		return s.modes, nil // Reloaded by a concurrent caller
+	}
+
+	list, err := s.serializationRepo.ListSerializations(ctx, s.dbExecutor)
+	if err != nil {
+		// Retry on the next movement rather than hammering the database from every request
+		s.loadedAt = time.Now()
+		return s.modes, err
+	}
+	s.modes = make(map[int64]domain.SerializationMode, len(list))
+	for _, serialization := range list {
+		s.modes[serialization.WalletID] = serialization.Mode
+	}
+	s.loadedAt = time.Now()
+	return s.modes, nil
+}
+
+func (s *walletSerializationService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
@@ -0,0 +1,138 @@
+// internal/service/wallet_serialization_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"finflow-wallet/internal/domain"
+	"finflow-wallet/internal/util"
+)
+
+// TestWalletSerialization tests the ordering of money movements of wallets with a serialization mode.
+func TestWalletSerialization(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newService := func(repo *MockWalletSerializationRepository, executor *MockDBExecutor) WalletSerializationService {
+		return NewWalletSerializationService(executor, repo, time.Minute, logger)
+	}
+	modes := []domain.WalletSerialization{
+		{WalletID: 1, Mode: domain.SerializationQueue},
+		{WalletID: 2, Mode: domain.SerializationAdvisoryLock},
+		{WalletID: 3, Mode: domain.SerializationQueue},
+	}
+
+	t.Run("QueueServesInArrivalOrder", func(t *testing.T) {
+		ctx := context.Background()
+		repo, executor := new(MockWalletSerializationRepository), new(MockDBExecutor)
+		repo.On("ListSerializations", mock.Anything, executor).Return(modes, nil)
+		service := newService(repo, executor)
+
+		leaveFirst, err := service.Enter(ctx, 1)
+		require.NoError(t, err)
+
+		var mu sync.Mutex
+		var order []int
+		var wg sync.WaitGroup
+		for i := range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				leave, err := service.Enter(ctx, 1)
+				assert.NoError(t, err)
+				mu.Lock()
+				order = append(order, i)
+				mu.Unlock()
+				leave()
+			}()
+			// Let each caller join the queue before the next
+			assert.Eventually(t, func() bool { return queueDepth(t, service, 1) == i+2 }, time.Second, time.Millisecond)
+		}
+		leaveFirst()
+		wg.Wait()
+
+		assert.Equal(t, []int{0, 1, 2}, order)
+		statuses, err := service.ListSerializations(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, statuses[0].Queue.Depth)
+		assert.Equal(t, 4, statuses[0].Queue.MaxDepth)
+		assert.Equal(t, int64(4), statuses[0].Queue.Entered)
+		assert.Nil(t, statuses[1].Queue, "advisory-locked wallets do not queue")
+	})
+
+	t.Run("CancelledWaiterLeavesTheQueue", func(t *testing.T) {
+		repo, executor := new(MockWalletSerializationRepository), new(MockDBExecutor)
+		repo.On("ListSerializations", mock.Anything, executor).Return(modes, nil)
+		service := newService(repo, executor)
+
+		leave, err := service.Enter(context.Background(), 1, 3)
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = service.Enter(ctx, 3, 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		leave()
+
+		assert.Equal(t, 0, queueDepth(t, service, 1))
+		assert.Equal(t, 0, queueDepth(t, service, 3))
+		leave, err = service.Enter(context.Background(), 1)
+		require.NoError(t, err)
+		leave()
+	})
+
+	t.Run("LockTakesAdvisoryLocksOnly", func(t *testing.T) {
+		ctx := context.Background()
+		repo, executor, tx := new(MockWalletSerializationRepository), new(MockDBExecutor), new(MockDBExecutor)
+		repo.On("ListSerializations", mock.Anything, executor).Return(modes, nil).Once()
+		repo.On("LockWallet", ctx, tx, int64(2)).Return(nil).Once()
+		service := newService(repo, executor)
+
+		assert.NoError(t, service.Lock(ctx, tx, 1, 2, 4))
+		assert.NoError(t, service.Lock(ctx, tx, 4)) // Served from the cache
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("UnknownModeIsRejected", func(t *testing.T) {
+		repo := new(MockWalletSerializationRepository)
+		service := newService(repo, new(MockDBExecutor))
+
+		_, err := service.SetSerialization(context.Background(), &domain.Wallet{ID: 1}, "FAST", "alice")
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		repo.AssertNotCalled(t, "SaveSerialization", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeleteRequiresNamedAdmin", func(t *testing.T) {
+		ctx := context.Background()
+		repo, executor := new(MockWalletSerializationRepository), new(MockDBExecutor)
+		service := newService(repo, executor)
+
+		assert.ErrorIs(t, service.DeleteSerialization(ctx, 1, ""), util.ErrForbidden)
+		repo.AssertNotCalled(t, "DeleteSerialization", mock.Anything, mock.Anything, mock.Anything)
+
+		repo.On("DeleteSerialization", ctx, executor, int64(1)).Return(nil).Once()
+		assert.NoError(t, service.DeleteSerialization(ctx, 1, "alice"))
+		repo.AssertExpectations(t)
+	})
+}
+
+// queueDepth returns the current depth of the wallet's queue.
+func queueDepth(t *testing.T, service WalletSerializationService, walletID int64) int {
+	t.Helper()
+	statuses, err := service.ListSerializations(context.Background())
+	require.NoError(t, err)
+	for _, status := range statuses {
+		if status.WalletID == walletID && status.Queue != nil {
+			return status.Queue.Depth
+		}
+	}
+	return 0
+}
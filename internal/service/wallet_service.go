@@ -2,15 +2,20 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
+	"finflow-wallet/internal/clock"
 	"finflow-wallet/internal/domain"
 	"finflow-wallet/internal/repository"
 	"finflow-wallet/internal/util"
 	"finflow-wallet/pkg/db"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -19,9 +24,31 @@ type WalletService interface {
 	Deposit(ctx context.Context, walletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Transaction, error)
 	Withdraw(ctx context.Context, walletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Transaction, error)
 	Transfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Wallet, *domain.Transaction, error)
+	// SplitTransfer pays several wallets from one atomically; it returns the source wallet, the SPLIT parent and its TRANSFER legs.
+	SplitTransfer(ctx context.Context, fromWalletID int64, split domain.Split) (*domain.Wallet, *domain.Transaction, []domain.Transaction, error)
 	GetBalance(ctx context.Context, walletID int64) (*domain.Wallet, error)
+	GetUser(ctx context.Context, userID int64) (*domain.User, error)
+	// SetUserTimezone sets the IANA time zone whose midnight starts the user's days.
+	SetUserTimezone(ctx context.Context, userID int64, timezone string) (*domain.User, error)
+	// SetUserResidency sets or, with nil, clears the user's ISO 3166-1 alpha-2 country of residence.
+	SetUserResidency(ctx context.Context, userID int64, residency *string) (*domain.User, error)
+	// SetUserContact updates the user's email and phone. A nil value keeps the field and an empty one clears it.
+	SetUserContact(ctx context.Context, userID int64, email, phone *string) (*domain.User, error)
+	GetWalletByPublicID(ctx context.Context, publicID uuid.UUID) (*domain.Wallet, error)
+	GetTransaction(ctx context.Context, publicID uuid.UUID) (*domain.Transaction, error)
+	// WaitForTransaction returns the transaction once it has left PENDING, or as it is when timeout elapses.
+	WaitForTransaction(ctx context.Context, publicID uuid.UUID, timeout time.Duration) (*domain.Transaction, error)
+	// SubmitTransfer accepts a transfer as a PENDING transaction, which ProcessAsyncTransfers completes or fails.
+	SubmitTransfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.Transaction, error)
+	// ProcessAsyncTransfers makes up to limit accepted transfers and returns how many left PENDING.
+	ProcessAsyncTransfers(ctx context.Context, limit int) (int, error)
 	GetTransactionHistory(ctx context.Context, walletID int64, limit, offset int) ([]domain.Transaction, int64, error)
-	CreateUserAndWallet(ctx context.Context, username, currency string) (*domain.User, *domain.Wallet, error)
+	ListTransactionHistory(ctx context.Context, walletID int64, filter repository.TransactionFilter, opts repository.ListOptions) ([]domain.Transaction, int64, error)
+	ListUsers(ctx context.Context, opts repository.ListOptions) ([]domain.User, int64, error)
+	ListWallets(ctx context.Context, filter repository.WalletFilter, opts repository.ListOptions) ([]domain.Wallet, int64, error)
+	// CreateUserAndWallet creates a user, with an optional ISO 3166-1 alpha-2 country of residence, and their first wallet.
+	CreateUserAndWallet(ctx context.Context, username, currency string, residency *string) (*domain.User, *domain.Wallet, error)
+	SweepExecutor
 }
 
 // walletService implements the WalletService interface.
@@ -31,9 +58,170 @@ type walletService struct {
 	userRepo        repository.UserRepository
 	walletRepo      repository.WalletRepository
 	transactionRepo repository.TransactionRepository
-	beginTx         db.BeginTxFunc    // Injected dependency for beginning transactions
-	commitTx        db.CommitTxFunc   // Injected dependency for committing transactions
-	rollbackTx      db.RollbackTxFunc // Injected dependency for rolling back transactions
+	beginTx         db.BeginTxFunc                           // Injected dependency for beginning transactions
+	commitTx        db.CommitTxFunc                          // Injected dependency for committing transactions
+	rollbackTx      db.RollbackTxFunc                        // Injected dependency for rolling back transactions
+	archiveHorizon  int                                      // Months kept in the hot transactions table; 0 means archival is disabled
+	flags           FeatureFlags                             // Optional; without it every flag is off
+	events          *TransactionEvents                       // Optional; committed transactions are published here
+	memberRepo      repository.WalletMemberRepository        // Optional; enables joint wallet approval policies
+	budgetRepo      repository.BudgetRepository              // Optional; enables SOFT_BLOCK budget enforcement
+	promoRepo       repository.PromoCreditRepository         // Optional; enables spending promotional credits first
+	quoteRepo       repository.TransferQuoteRepository       // Optional; enables transfers executed at a quoted pricing
+	duplicateWindow time.Duration                            // Repeated transfers within it are rejected as duplicates; 0 disables the check
+	policy          Policy                                   // Authorizes each operation; AllowOwnerPolicy by default
+	screener        Screener                                 // Optional; screens new users and new transfer counterparties
+	restrictionRepo repository.CurrencyRestrictionRepository // Optional; enables residency restrictions of currencies
+	volumeQuotas    VolumeQuotas                             // Optional; enforces the volume quotas of partners
+	watcher         *TransactionWatcher                      // Optional; without it, waiting for a transaction returns at once
+	serializer      WalletSerializer                         // Optional; orders the movements of contended wallets
+	sharder         BalanceSharder                           // Optional; spreads the balance updates of sharded wallets
+	balances        *BalanceWriter                           // Applies balance changes, honouring the sharder
+	asyncRepo       repository.AsyncTransferRepository       // Optional; enables accepting transfers to make later
+	asyncBackoff    asyncTransferBackoff                     // Delays the retries of accepted transfers
+	impersonations  repository.ImpersonationRepository       // Optional; lets impersonation sessions make their one transfer
+	rampRepo        repository.RampRepository                // Optional; enables conversions between fiat and crypto wallets
+	cryptoRepo      repository.CryptoRepository              // Optional; enables withdrawals of crypto wallets through payouts
+	identityRepo    repository.IdentityRepository            // Optional; enables provisioning users at their first OpenID Connect login
+	scimRepo        repository.SCIMRepository                // Optional; enables provisioning users by SCIM tenants
+	rollouts        *Rollouts                                // Optional; picks and measures the code paths of flagged changes
+	clock           clock.Clock                              // Tells the time of windows and expirations; the system clock by default
+}
+
+// WalletServiceOption configures optional dependencies of the wallet service.
+type WalletServiceOption func(*walletService)
+
+// WithArchiveHorizon tells the service how many months of history stay in the hot table,
+// so range queries reaching further back also search the archive.
+func WithArchiveHorizon(months int) WalletServiceOption {
+	return func(s *walletService) {
+		s.archiveHorizon = months
+	}
+}
+
+// WithFeatureFlags lets the service consult feature flags, e.g. domain.FlagOverdraft.
+func WithFeatureFlags(flags FeatureFlags) WalletServiceOption {
+	return func(s *walletService) {
+		s.flags = flags
+	}
+}
+
+// WithTransactionEvents publishes every committed money movement to the given event bus.
+func WithTransactionEvents(events *TransactionEvents) WalletServiceOption {
+	return func(s *walletService) {
+		s.events = events
+	}
+}
+
+// WithApprovalPolicies makes transfers honour joint wallet approval policies: a transfer above a wallet's
+// threshold fails with util.ErrApprovalRequired unless JointWalletService executes it after approval.
+func WithApprovalPolicies(memberRepo repository.WalletMemberRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.memberRepo = memberRepo
+	}
+}
+
+// WithBudgets makes withdrawals and transfers honour SOFT_BLOCK budgets: spending that would take a
+// budget over its limit fails with util.ErrBudgetExceeded unless the request carries WithBudgetOverride.
+func WithBudgets(budgetRepo repository.BudgetRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.budgetRepo = budgetRepo
+	}
+}
+
+// WithPromoCredits makes withdrawals and transfers spend the wallet's promotional credits before its balance,
+// soonest expiring first. Withdrawals only spend credits granted with AllowWithdrawal.
+func WithPromoCredits(promoRepo repository.PromoCreditRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.promoRepo = promoRepo
+	}
+}
+
+// WithTransferQuotes lets transfers carrying WithTransferQuote claim their quote and execute at its pricing,
+// including cross-currency transfers. Without it such transfers fail with util.ErrQuoteNotUsable.
+func WithTransferQuotes(quoteRepo repository.TransferQuoteRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.quoteRepo = quoteRepo
+	}
+}
+
+// WithDuplicateTransferWindow rejects a transfer repeating one made within window, i.e. the same amount and
+// currency between the same wallets, with util.ErrDuplicateTransfer unless the request carries WithForcedTransfer.
+func WithDuplicateTransferWindow(window time.Duration) WalletServiceOption {
+	return func(s *walletService) {
+		s.duplicateWindow = window
+	}
+}
+
+// WithPolicy replaces the default AllowOwnerPolicy with the given authorization policy.
+func WithPolicy(policy Policy) WalletServiceOption {
+	return func(s *walletService) {
+		s.policy = policy
+	}
+}
+
+// WithScreener screens every user before it is created, and the owner of a wallet before another user's wallet
+// first transfers to it. A hit fails the operation with util.ErrScreeningHit.
+func WithScreener(screener Screener) WalletServiceOption {
+	return func(s *walletService) {
+		s.screener = screener
+	}
+}
+
+// WithCurrencyRestrictions enforces the residency restrictions of currencies: a user may neither open nor
+// deposit into a wallet in a currency restricted for their country of residence (util.ErrCurrencyRestricted).
+func WithCurrencyRestrictions(restrictionRepo repository.CurrencyRestrictionRepository) WalletServiceOption {
+	return func(s *walletService) {
+		s.restrictionRepo = restrictionRepo
+	}
+}
+
+// WithVolumeQuotas checks deposits, withdrawals and transfers requested by a partner against its monthly
+// volume quota; those that would exceed it fail with util.ErrVolumeQuotaExceeded.
+func WithVolumeQuotas(quotas VolumeQuotas) WalletServiceOption {
+	return func(s *walletService) {
+		s.volumeQuotas = quotas
+	}
+}
+
+// WithTransactionWatcher lets WaitForTransaction wait for pending transactions to complete. The watcher must
+// be subscribed to the events the transactions are completed on.
+func WithTransactionWatcher(watcher *TransactionWatcher) WalletServiceOption {
+	return func(s *walletService) {
+		s.watcher = watcher
+	}
+}
+
+// WithWalletSerializer orders the money movements of the wallets with a serialization mode: they queue for
+// their turn before the database transaction, or take an advisory lock first thing in it.
+func WithWalletSerializer(serializer WalletSerializer) WalletServiceOption {
+	return func(s *walletService) {
+		s.serializer = serializer
+	}
+}
+
+// WithRollouts lets flagged changes to money movement logic, e.g. domain.FlagTransferRowLocks, be enabled for a
+// percentage of wallets or users, measuring the calls on either code path.
+func WithRollouts(rollouts *Rollouts) WalletServiceOption {
+	return func(s *walletService) {
+		s.rollouts = rollouts
+	}
+}
+
+// WithBalanceSharder makes deposits, withdrawals and transfers update one of the shards of a sharded wallet
+// instead of the wallet's own balance, so they do not all queue for the lock of its row.
+func WithBalanceSharder(sharder BalanceSharder) WalletServiceOption {
+	return func(s *walletService) {
+		s.sharder = sharder
+	}
+}
+
+// WithClock makes the service read the time of budget periods, duplicate windows, quote and promotional
+// credit expirations and the archive cutoff from the given clock, e.g. a test clock in sandbox mode.
+func WithClock(c clock.Clock) WalletServiceOption {
+	return func(s *walletService) {
+		s.clock = c
+	}
 }
 
 // NewWalletService creates a new instance of WalletService.
@@ -46,8 +234,9 @@ func NewWalletService(
 	beginTx db.BeginTxFunc,
 	commitTx db.CommitTxFunc,
 	rollbackTx db.RollbackTxFunc,
+	opts ...WalletServiceOption,
 ) WalletService {
-	return &walletService{
+	s := &walletService{
 		dbBeginner:      dbBeginner,
 		dbExecutor:      dbExecutor,
 		userRepo:        userRepo,
@@ -56,7 +245,14 @@ func NewWalletService(
 		beginTx:         beginTx,
 		commitTx:        commitTx,
 		rollbackTx:      rollbackTx,
+		policy:          AllowOwnerPolicy{},
+		clock:           clock.System{},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.balances = NewBalanceWriter(s.walletRepo, s.sharder, s.restrictionRepo, s.clock)
+	return s
 }
 
 // Deposit adds money to a user's wallet.
@@ -65,6 +261,12 @@ func (s *walletService) Deposit(ctx context.Context, walletID int64, amount deci
 		return nil, nil, util.ErrInvalidInput
 	}
 
+	leave, err := s.enterSerialization(ctx, walletID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("deposit: %w", err)
+	}
+	defer leave()
+
 	txController, err := s.beginTx(ctx, s.dbBeginner) // Use injected function
 	if err != nil {
 		return nil, nil, fmt.Errorf("deposit: failed to begin transaction: %w", err)
@@ -75,6 +277,13 @@ func (s *walletService) Deposit(ctx context.Context, walletID int64, amount deci
 	if !ok {
 		return nil, nil, fmt.Errorf("deposit: transaction controller does not implement DBExecutor")
 	}
+	if err := s.lockSerialization(ctx, txExecutor, walletID); err != nil {
+		return nil, nil, fmt.Errorf("deposit: %w", err)
+	}
+
+	if err := s.checkWalletPrecondition(ctx, txExecutor, walletID); err != nil {
+		return nil, nil, fmt.Errorf("deposit: %w", err)
+	}
 
 	wallet, err := s.walletRepo.GetWalletByID(ctx, txExecutor, walletID)
 	if err != nil {
@@ -83,26 +292,37 @@ func (s *walletService) Deposit(ctx context.Context, walletID int64, amount deci
 	if wallet.Currency != currency {
 		return nil, nil, util.ErrCurrencyMismatch
 	}
+	if err := s.authorize(ctx, ActionDeposit, wallet, nil, amount); err != nil {
+		return nil, nil, fmt.Errorf("deposit: %w", err)
+	}
+	if err := s.checkVolumeQuota(ctx, currency, amount); err != nil {
+		return nil, nil, fmt.Errorf("deposit: %w", err)
+	}
+	if wallet.Kind == domain.WalletKindCrypto {
+		return nil, nil, fmt.Errorf("deposit: %w: crypto wallets are funded through their deposit addresses", util.ErrInvalidInput)
+	}
 
-	if err := s.walletRepo.UpdateWalletBalance(ctx, txExecutor, walletID, amount); err != nil {
-		return nil, nil, fmt.Errorf("deposit: failed to update wallet balance: %w", err)
+	balance, err := s.balances.Credit(ctx, txExecutor, wallet, amount)
+	if err != nil {
+		return nil, nil, fmt.Errorf("deposit: %w", err)
 	}
 
 	transaction := domain.NewTransaction(nil, &walletID, amount, currency, domain.TransactionTypeDeposit, nil)
+	s.balances.Stamp(transaction)
+	transaction.Category = transactionCategory(ctx)
 	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
 		return nil, nil, fmt.Errorf("deposit: failed to create transaction: %w", err)
 	}
 
-	updatedWallet, err := s.walletRepo.GetWalletByID(ctx, txExecutor, walletID)
-	if err != nil {
-		return nil, nil, fmt.Errorf("deposit: failed to re-fetch updated wallet %d: %w", walletID, err)
+	if isDryRun(ctx) {
+		return wallet.WithBalance(balance), transaction, nil // Rolled back by the deferred rollback
 	}
-
 	if err := s.commitTx(txController); err != nil { // Use injected function
 		return nil, nil, fmt.Errorf("deposit: failed to commit transaction: %w", err)
 	}
+	s.publish(ctx, transaction)
 
-	return updatedWallet, transaction, nil
+	return wallet.WithBalance(balance), transaction, nil
 }
 
 // Withdraw, Transfer, GetBalance, GetTransactionHistory, CreateUserAndWallet methods
@@ -114,6 +334,12 @@ func (s *walletService) Withdraw(ctx context.Context, walletID int64, amount dec
 		return nil, nil, util.ErrInvalidInput
 	}
 
+	leave, err := s.enterSerialization(ctx, walletID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
+	defer leave()
+
 	txController, err := s.beginTx(ctx, s.dbBeginner)
 	if err != nil {
 		return nil, nil, fmt.Errorf("withdraw: failed to begin transaction: %w", err)
@@ -124,6 +350,13 @@ func (s *walletService) Withdraw(ctx context.Context, walletID int64, amount dec
 	if !ok {
 		return nil, nil, fmt.Errorf("withdraw: transaction controller does not implement DBExecutor")
 	}
+	if err := s.lockSerialization(ctx, txExecutor, walletID); err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
+
+	if err := s.checkWalletPrecondition(ctx, txExecutor, walletID); err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
 
 	wallet, err := s.walletRepo.GetWalletByID(ctx, txExecutor, walletID)
 	if err != nil {
@@ -132,33 +365,78 @@ func (s *walletService) Withdraw(ctx context.Context, walletID int64, amount dec
 	if wallet.Currency != currency {
 		return nil, nil, util.ErrCurrencyMismatch
 	}
+	payout, err := s.cryptoPayout(ctx, wallet, amount)
+	if err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
+	if err := s.authorize(ctx, ActionWithdraw, wallet, nil, amount); err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
+	if err := s.checkVolumeQuota(ctx, currency, amount); err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
+	if err := checkNotFrozen(wallet); err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
+
+	credits, promo, debit, err := s.lockPromoCredits(ctx, txExecutor, walletID, amount, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
 
-	if wallet.Balance.LessThan(amount) {
+	if !s.canDebit(ctx, wallet, debit) {
 		return nil, nil, util.ErrInsufficientFunds
 	}
 
-	if err := s.walletRepo.UpdateWalletBalance(ctx, txExecutor, walletID, amount.Neg()); err != nil {
+	if err := s.checkBudgets(ctx, txExecutor, walletID, amount); err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
+
+	if err := s.consumePromoCredits(ctx, txExecutor, credits, promo); err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
+	}
+	balance, err := s.balances.UpdateBalance(ctx, txExecutor, walletID, debit.Neg())
+	if err != nil {
 		return nil, nil, fmt.Errorf("withdraw: failed to update wallet balance: %w", err)
 	}
 
-	transaction := domain.NewTransaction(&walletID, nil, amount, currency, domain.TransactionTypeWithdrawal, nil)
+	transaction := domain.NewTransaction(&walletID, nil, amount, currency, domain.TransactionTypeWithdrawal, payoutDescription(payout))
+	s.balances.Stamp(transaction)
+	transaction.Category = transactionCategory(ctx)
+	transaction.PromoAmount = promo
 	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
 		return nil, nil, fmt.Errorf("withdraw: failed to create transaction: %w", err)
 	}
-
-	updatedWallet, err := s.walletRepo.GetWalletByID(ctx, txExecutor, walletID)
-	if err != nil {
-		return nil, nil, fmt.Errorf("withdraw: failed to re-fetch updated wallet %d: %w", walletID, err)
+	if err := s.queuePayout(ctx, txExecutor, payout, transaction); err != nil {
+		return nil, nil, fmt.Errorf("withdraw: %w", err)
 	}
 
+	if isDryRun(ctx) {
+		return wallet.WithBalance(balance), transaction, nil
+	}
 	if err := s.commitTx(txController); err != nil {
 		return nil, nil, fmt.Errorf("withdraw: failed to commit transaction: %w", err)
 	}
+	s.publish(ctx, transaction)
 
-	return updatedWallet, transaction, nil
+	return wallet.WithBalance(balance), transaction, nil
 }
 
+// Transfer moves amount between two wallets of the same currency. A transfer carrying a quote (see
+// WithTransferQuote) claims it and is executed at the quoted pricing instead: the source pays the quoted
+// debit and the destination receives the quoted credit, which for a cross-currency quote is recorded as a
+// pair of CONVERSION legs. The returned transaction is the TRANSFER, or the debit leg of a conversion.
+// A transfer repeating a recent one fails as a likely duplicate (see WithDuplicateTransferWindow).
 func (s *walletService) Transfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Wallet, *domain.Transaction, error) {
+	rowLocks, done := s.rollouts.Start(ctx, domain.FlagTransferRowLocks,
+		domain.FlagSubject{UserID: SubjectFromContext(ctx).UserID, WalletID: fromWalletID})
+	fromWallet, toWallet, transaction, err := s.transfer(ctx, fromWalletID, toWalletID, amount, currency, rowLocks)
+	done(err)
+	return fromWallet, toWallet, transaction, err
+}
+
+// transfer makes a transfer. With rowLocks both wallets are row-locked, in ID order, before they are checked.
+func (s *walletService) transfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string, rowLocks bool) (*domain.Wallet, *domain.Wallet, *domain.Transaction, error) {
 	if amount.LessThanOrEqual(decimal.Zero) {
 		return nil, nil, nil, util.ErrInvalidInput
 	}
@@ -166,6 +444,12 @@ func (s *walletService) Transfer(ctx context.Context, fromWalletID, toWalletID i
 		return nil, nil, nil, util.ErrSameWalletTransfer
 	}
 
+	leave, err := s.enterSerialization(ctx, fromWalletID, toWalletID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+	defer leave()
+
 	txController, err := s.beginTx(ctx, s.dbBeginner)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("transfer: failed to begin transaction: %w", err)
@@ -176,54 +460,423 @@ func (s *walletService) Transfer(ctx context.Context, fromWalletID, toWalletID i
 	if !ok {
 		return nil, nil, nil, fmt.Errorf("transfer: transaction controller does not implement DBExecutor")
 	}
+	if err := s.lockSerialization(ctx, txExecutor, fromWalletID, toWalletID); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+
+	if err := s.checkWalletPrecondition(ctx, txExecutor, fromWalletID); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
 
-	fromWallet, err := s.walletRepo.GetWalletByID(ctx, txExecutor, fromWalletID)
+	// Without a quote the source pays, and the destination receives, exactly amount in currency
+	debitTotal, credit, creditCurrency := amount, amount, currency
+	quote, err := s.claimTransferQuote(ctx, txExecutor, fromWalletID, toWalletID, amount, currency)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("transfer: failed to get source wallet %d: %w", fromWalletID, err)
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+	if quote != nil {
+		debitTotal, credit, creditCurrency = quote.DebitAmount(), quote.CreditAmount, quote.CreditCurrency
+	}
+
+	// Both wallets are read in one round trip, or locked one after the other
+	wallets, err := s.readTransferWallets(ctx, txExecutor, fromWalletID, toWalletID, rowLocks)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: failed to get wallets: %w", err)
+	}
+	fromWallet := findWallet(wallets, fromWalletID)
+	if fromWallet == nil {
+		return nil, nil, nil, fmt.Errorf("transfer: failed to get source wallet %d: %w", fromWalletID, util.ErrNotFound)
 	}
 	if fromWallet.Currency != currency {
 		return nil, nil, nil, util.ErrCurrencyMismatch
 	}
+	if err := checkImpersonatedOwner(ctx, fromWallet); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := s.claimImpersonatedTransfer(ctx, txExecutor); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
 
-	toWallet, err := s.walletRepo.GetWalletByID(ctx, txExecutor, toWalletID)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("transfer: failed to get destination wallet %d: %w", toWalletID, err)
+	toWallet := findWallet(wallets, toWalletID)
+	if toWallet == nil {
+		return nil, nil, nil, fmt.Errorf("transfer: failed to get destination wallet %d: %w", toWalletID, util.ErrNotFound)
 	}
-	if toWallet.Currency != currency {
+	if toWallet.Currency != creditCurrency {
 		return nil, nil, nil, util.ErrCurrencyMismatch
 	}
+	ramp, err := s.rampOrder(ctx, fromWallet, toWallet, quote)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+	if err := s.authorize(ctx, ActionTransfer, fromWallet, toWallet, amount); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+	if err := s.checkVolumeQuota(ctx, currency, amount); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+	if err := checkNotFrozen(fromWallet); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+	if err := s.screenCounterparty(ctx, txExecutor, fromWallet, toWallet); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+
+	if err := s.checkDuplicateTransfer(ctx, txExecutor, fromWalletID, toWalletID, amount, currency); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+
+	if err := s.checkApprovalPolicy(ctx, txExecutor, fromWalletID, debitTotal); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
 
-	if fromWallet.Balance.LessThan(amount) {
+	credits, promo, debit, err := s.lockPromoCredits(ctx, txExecutor, fromWalletID, debitTotal, false)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+
+	if !s.canDebit(ctx, fromWallet, debit) {
 		return nil, nil, nil, util.ErrInsufficientFunds
 	}
 
-	if err := s.walletRepo.UpdateWalletBalance(ctx, txExecutor, fromWalletID, amount.Neg()); err != nil {
-		return nil, nil, nil, fmt.Errorf("transfer: failed to update source wallet balance: %w", err)
+	if err := s.checkBudgets(ctx, txExecutor, fromWalletID, debitTotal); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
 	}
 
-	if err := s.walletRepo.UpdateWalletBalance(ctx, txExecutor, toWalletID, amount); err != nil {
-		return nil, nil, nil, fmt.Errorf("transfer: failed to update destination wallet balance: %w", err)
+	if err := s.consumePromoCredits(ctx, txExecutor, credits, promo); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+	// Both balances are updated, and the updated wallets returned, by one statement unless one is sharded
+	updatedWallets, err := s.balances.UpdateBalances(ctx, txExecutor, wallets, map[int64]decimal.Decimal{
+		fromWalletID: debit.Neg(),
+		toWalletID:   credit,
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: failed to update wallet balances: %w", err)
 	}
 
-	transaction := domain.NewTransaction(&fromWalletID, &toWalletID, amount, currency, domain.TransactionTypeTransfer, nil)
-	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
-		return nil, nil, nil, fmt.Errorf("transfer: failed to create transaction: %w", err)
+	transactions := transferTransactions(fromWalletID, toWalletID, debitTotal, currency, credit, creditCurrency)
+	s.balances.Stamp(transactions...)
+	transactions[0].PromoAmount = promo
+	for _, transaction := range transactions {
+		transaction.Category = transactionCategory(ctx)
+		transaction.Description = rampDescription(ramp)
+		if err := s.recordTransaction(ctx, txExecutor, transaction); err != nil {
+			return nil, nil, nil, fmt.Errorf("transfer: failed to create transaction: %w", err)
+		}
+	}
+	if err := s.recordRamp(ctx, txExecutor, ramp, transactions); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: %w", err)
+	}
+
+	updatedFromWallet, updatedToWallet := findWallet(updatedWallets, fromWalletID), findWallet(updatedWallets, toWalletID)
+	if updatedFromWallet == nil || updatedToWallet == nil {
+		return nil, nil, nil, fmt.Errorf("transfer: updated wallets %d and %d were not returned", fromWalletID, toWalletID)
+	}
+
+	if isDryRun(ctx) {
+		return updatedFromWallet, updatedToWallet, transactions[0], nil
+	}
+	if err := s.commitTx(txController); err != nil {
+		return nil, nil, nil, fmt.Errorf("transfer: failed to commit transaction: %w", err)
+	}
+	for _, transaction := range transactions {
+		s.publish(ctx, transaction)
+	}
+
+	return updatedFromWallet, updatedToWallet, transactions[0], nil
+}
+
+// readTransferWallets reads the two wallets of a transfer in ascending ID order, row-locking them if rowLocks
+// is set. Missing wallets are left out.
+func (s *walletService) readTransferWallets(ctx context.Context, q repository.DBExecutor, fromWalletID, toWalletID int64, rowLocks bool) ([]domain.Wallet, error) {
+	if !rowLocks {
+		return s.walletRepo.GetWalletsByIDs(ctx, q, []int64{fromWalletID, toWalletID})
+	}
+	locked, err := lockWallets(ctx, s.walletRepo, q, fromWalletID, toWalletID)
+	if err != nil {
+		return nil, err
+	}
+	wallets := make([]domain.Wallet, 0, len(locked))
+	for _, wallet := range locked {
+		wallets = append(wallets, *wallet)
+	}
+	slices.SortFunc(wallets, func(a, b domain.Wallet) int { return cmp.Compare(a.ID, b.ID) })
+	return wallets, nil
+}
+
+// transferTransactions records a transfer: one TRANSFER between same-currency wallets, otherwise a CONVERSION
+// debit from the source and a CONVERSION credit to the destination whose parent is the debit.
+func transferTransactions(fromWalletID, toWalletID int64, debit decimal.Decimal, currency string, credit decimal.Decimal, creditCurrency string) []*domain.Transaction {
+	if currency == creditCurrency {
+		return []*domain.Transaction{domain.NewTransaction(&fromWalletID, &toWalletID, debit, currency, domain.TransactionTypeTransfer, nil)}
+	}
+	debitLeg := domain.NewTransaction(&fromWalletID, nil, debit, currency, domain.TransactionTypeConversion, nil)
+	creditLeg := domain.NewTransaction(nil, &toWalletID, credit, creditCurrency, domain.TransactionTypeConversion, nil)
+	creditLeg.ParentID = &debitLeg.PublicID
+	return []*domain.Transaction{debitLeg, creditLeg}
+}
+
+// claimTransferQuote claims the quote attached to ctx, if any, for a transfer of amount in currency between
+// the two wallets. A quote that is unknown, expired, already used or issued for another transfer fails with
+// util.ErrQuoteNotUsable; the claim is undone with the transaction if the transfer fails later on.
+func (s *walletService) claimTransferQuote(ctx context.Context, q repository.DBExecutor, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.TransferQuote, error) {
+	quoteID, ok := transferQuoteID(ctx)
+	if !ok {
+		return nil, nil
+	}
+	if s.quoteRepo == nil {
+		return nil, util.ErrQuoteNotUsable
+	}
+	quote, err := s.quoteRepo.ClaimQuote(ctx, q, quoteID, s.clock.Now().UTC())
+	if errors.Is(err, util.ErrNotFound) {
+		return nil, util.ErrQuoteNotUsable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim transfer quote %s: %w", quoteID, err)
+	}
+	if quote.FromWalletID != fromWalletID || quote.ToWalletID != toWalletID || !quote.Amount.Equal(amount) || quote.Currency != currency {
+		return nil, fmt.Errorf("%w: quote %s was issued for another transfer", util.ErrQuoteNotUsable, quoteID)
+	}
+	return quote, nil
+}
+
+// SplitTransfer executes a split payment in one database transaction: either every leg is transferred or none is.
+// Approval policies, overdraft and budgets apply to the total leaving the source wallet.
+func (s *walletService) SplitTransfer(ctx context.Context, fromWalletID int64, split domain.Split) (*domain.Wallet, *domain.Transaction, []domain.Transaction, error) {
+	total, amounts, err := split.Resolve()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %s", util.ErrInvalidInput, err)
+	}
+	walletIDs := []int64{fromWalletID}
+	for _, leg := range split.Legs {
+		if leg.ToWalletID == fromWalletID {
+			return nil, nil, nil, util.ErrSameWalletTransfer
+		}
+		if slices.Contains(walletIDs, leg.ToWalletID) {
+			return nil, nil, nil, fmt.Errorf("%w: each destination wallet may appear only once", util.ErrInvalidInput)
+		}
+		walletIDs = append(walletIDs, leg.ToWalletID)
+	}
+
+	leave, err := s.enterSerialization(ctx, walletIDs...)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+	defer leave()
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("split transfer: transaction controller does not implement DBExecutor")
+	}
+	if err := s.lockSerialization(ctx, txExecutor, walletIDs...); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+
+	if err := s.checkWalletPrecondition(ctx, txExecutor, fromWalletID); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+
+	wallets, err := lockWallets(ctx, s.walletRepo, txExecutor, walletIDs...)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, nil, nil, util.ErrWalletNotFound
+		}
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+	for _, walletID := range walletIDs {
+		if wallets[walletID].Currency != split.Currency {
+			return nil, nil, nil, util.ErrCurrencyMismatch
+		}
+	}
+	if err := s.authorize(ctx, ActionSplitTransfer, wallets[fromWalletID], nil, total); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+	if err := s.checkVolumeQuota(ctx, split.Currency, total); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+	if err := checkNotFrozen(wallets[fromWalletID]); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+	for _, leg := range split.Legs {
+		if err := s.screenCounterparty(ctx, txExecutor, wallets[fromWalletID], wallets[leg.ToWalletID]); err != nil {
+			return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+		}
+	}
+
+	if err := s.checkApprovalPolicy(ctx, txExecutor, fromWalletID, total); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+	credits, promo, debit, err := s.lockPromoCredits(ctx, txExecutor, fromWalletID, total, false)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+	if !s.canDebit(ctx, wallets[fromWalletID], debit) {
+		return nil, nil, nil, util.ErrInsufficientFunds
+	}
+	if err := s.checkBudgets(ctx, txExecutor, fromWalletID, total); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
 	}
 
-	updatedFromWallet, err := s.walletRepo.GetWalletByID(ctx, txExecutor, fromWalletID)
+	// Promotional credit is accounted on the parent; the legs deliver real money to each destination
+	parent := domain.NewTransaction(&fromWalletID, nil, total, split.Currency, domain.TransactionTypeSplit, nil)
+	s.balances.Stamp(parent)
+	parent.Category = transactionCategory(ctx)
+	parent.PromoAmount = promo
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, parent); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: failed to create parent transaction: %w", err)
+	}
+	if err := s.consumePromoCredits(ctx, txExecutor, credits, promo); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: %w", err)
+	}
+	balance, err := s.balances.UpdateBalance(ctx, txExecutor, fromWalletID, debit.Neg())
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: failed to update source wallet balance: %w", err)
+	}
+
+	legs := make([]domain.Transaction, 0, len(split.Legs))
+	for i, leg := range split.Legs {
+		toWalletID := leg.ToWalletID
+		if _, err := s.balances.UpdateBalance(ctx, txExecutor, toWalletID, amounts[i]); err != nil {
+			return nil, nil, nil, fmt.Errorf("split transfer: failed to update destination wallet balance: %w", err)
+		}
+		transaction := domain.NewTransaction(&fromWalletID, &toWalletID, amounts[i], split.Currency, domain.TransactionTypeTransfer, nil)
+		s.balances.Stamp(transaction)
+		transaction.Category = parent.Category
+		transaction.ParentID = &parent.PublicID
+		if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+			return nil, nil, nil, fmt.Errorf("split transfer: failed to create leg transaction: %w", err)
+		}
+		transaction.ToWalletPublicID = &wallets[toWalletID].PublicID
+		legs = append(legs, *transaction)
+	}
+
+	if err := s.commitTx(txController); err != nil {
+		return nil, nil, nil, fmt.Errorf("split transfer: failed to commit transaction: %w", err)
+	}
+	// The legs are the money movements; the parent only groups them
+	for i := range legs {
+		s.publish(ctx, &legs[i])
+	}
+
+	return wallets[fromWalletID].WithBalance(balance), parent, legs, nil
+}
+
+// ApplySweepRule executes a sweep rule as an AUTO_SWEEP transfer. The amount is computed from the
+// balances after both wallets are locked, so concurrent transactions cannot make it overshoot.
+func (s *walletService) ApplySweepRule(ctx context.Context, rule *domain.SweepRule) (*domain.Transaction, error) {
+	leave, err := s.enterSerialization(ctx, rule.SourceWalletID, rule.TargetWalletID)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("transfer: failed to re-fetch updated source wallet %d: %w", fromWalletID, err)
+		return nil, fmt.Errorf("sweep: %w", err)
 	}
-	updatedToWallet, err := s.walletRepo.GetWalletByID(ctx, txExecutor, toWalletID)
+	defer leave()
+
+	txController, err := s.beginTx(ctx, s.dbBeginner)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("transfer: failed to re-fetch updated destination wallet %d: %w", toWalletID, err)
+		return nil, fmt.Errorf("sweep: failed to begin transaction: %w", err)
+	}
+	defer s.rollbackTx(txController)
+
+	txExecutor, ok := txController.(repository.DBExecutor)
+	if !ok {
+		return nil, fmt.Errorf("sweep: transaction controller does not implement DBExecutor")
+	}
+	if err := s.lockSerialization(ctx, txExecutor, rule.SourceWalletID, rule.TargetWalletID); err != nil {
+		return nil, fmt.Errorf("sweep: %w", err)
+	}
+
+	wallets, err := lockWallets(ctx, s.walletRepo, txExecutor, rule.SourceWalletID, rule.TargetWalletID)
+	if err != nil {
+		return nil, fmt.Errorf("sweep: %w", err)
+	}
+	source, target := wallets[rule.SourceWalletID], wallets[rule.TargetWalletID]
+	if source.Currency != target.Currency {
+		return nil, util.ErrCurrencyMismatch
+	}
+	if err := checkNotFrozen(source); err != nil {
+		return nil, fmt.Errorf("sweep: %w", err)
+	}
+
+	amount := rule.Amount(source, target)
+	if amount.IsZero() {
+		return nil, nil
+	}
+
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, rule.SourceWalletID, amount.Neg()); err != nil {
+		return nil, fmt.Errorf("sweep: failed to update source wallet balance: %w", err)
+	}
+	if _, err := s.balances.UpdateBalance(ctx, txExecutor, rule.TargetWalletID, amount); err != nil {
+		return nil, fmt.Errorf("sweep: failed to update target wallet balance: %w", err)
+	}
+
+	description := fmt.Sprintf("Sweep rule %d", rule.ID)
+	transaction := domain.NewTransaction(&rule.SourceWalletID, &rule.TargetWalletID, amount, source.Currency, domain.TransactionTypeAutoSweep, &description)
+	s.balances.Stamp(transaction)
+	if err := s.transactionRepo.CreateTransaction(ctx, txExecutor, transaction); err != nil {
+		return nil, fmt.Errorf("sweep: failed to create transaction: %w", err)
 	}
 
 	if err := s.commitTx(txController); err != nil {
-		return nil, nil, nil, fmt.Errorf("transfer: failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("sweep: failed to commit transaction: %w", err)
+	}
+	s.publish(ctx, transaction)
+
+	return transaction, nil
+}
+
+// findWallet returns the wallet with the given ID from a batch lookup, or nil if it is missing.
+func findWallet(wallets []domain.Wallet, walletID int64) *domain.Wallet {
+	for i := range wallets {
+		if wallets[i].ID == walletID {
+			return &wallets[i]
+		}
+	}
+	return nil
+}
+
+// lockWallets row-locks the given wallets in ID order, so two transactions moving money between
+// the same wallets cannot deadlock. It must be called with a transactional DBExecutor.
+func lockWallets(ctx context.Context, walletRepo repository.WalletRepository, q repository.DBExecutor, walletIDs ...int64) (map[int64]*domain.Wallet, error) {
+	lockOrder := slices.Clone(walletIDs)
+	slices.Sort(lockOrder)
+	wallets := make(map[int64]*domain.Wallet, len(lockOrder))
+	for _, walletID := range slices.Compact(lockOrder) {
+		wallet, err := walletRepo.GetWalletByIDForUpdate(ctx, q, walletID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock wallet %d: %w", walletID, err)
+		}
+		wallets[walletID] = wallet
+	}
+	return wallets, nil
+}
+
+// enterSerialization waits for the movement's turn on the queued wallets among walletIDs; the returned
+// function ends it.
+func (s *walletService) enterSerialization(ctx context.Context, walletIDs ...int64) (func(), error) {
+	if s.serializer == nil {
+		return func() {}, nil
+	}
+	return s.serializer.Enter(ctx, walletIDs...)
+}
+
+// lockSerialization takes the advisory locks of the advisory-locked wallets among walletIDs in the transaction.
+func (s *walletService) lockSerialization(ctx context.Context, q repository.DBExecutor, walletIDs ...int64) error {
+	if s.serializer == nil {
+		return nil
 	}
+	return s.serializer.Lock(ctx, q, walletIDs...)
+}
 
-	return updatedFromWallet, updatedToWallet, transaction, nil
+// publish hands a committed transaction to the event bus, if one is configured.
+func (s *walletService) publish(ctx context.Context, transaction *domain.Transaction) {
+	if s.events != nil {
+		s.events.Publish(ctx, transaction)
+	}
 }
 
 func (s *walletService) GetBalance(ctx context.Context, walletID int64) (*domain.Wallet, error) {
@@ -232,19 +885,276 @@ func (s *walletService) GetBalance(ctx context.Context, walletID int64) (*domain
 	if err != nil {
 		return nil, fmt.Errorf("get balance: failed to get wallet %d: %w", walletID, err)
 	}
+	if err := s.authorize(ctx, ActionReadBalance, wallet, nil, decimal.Zero); err != nil {
+		return nil, fmt.Errorf("get balance: %w", err)
+	}
+	return wallet, nil
+}
+
+// GetUser retrieves a user by ID.
+func (s *walletService) GetUser(ctx context.Context, userID int64) (*domain.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, userID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user: failed to get user %d: %w", userID, err)
+	}
+	return user, nil
+}
+
+// SetUserTimezone validates the name against the IANA time zone database before storing it.
+func (s *walletService) SetUserTimezone(ctx context.Context, userID int64, timezone string) (*domain.User, error) {
+	loc, err := domain.LoadTimezone(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", util.ErrInvalidInput, err)
+	}
+	user, err := s.userRepo.UpdateUserTimezone(ctx, s.dbExecutor, userID, loc.String())
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("set user timezone: failed to update user %d: %w", userID, err)
+	}
+	return user, nil
+}
+
+// SetUserResidency validates the country code before storing it. Wallets the user already holds are kept, but
+// deposits into those in a currency restricted for the new residency fail.
+func (s *walletService) SetUserResidency(ctx context.Context, userID int64, residency *string) (*domain.User, error) {
+	if residency != nil {
+		country, err := domain.NormalizeCountryCode(*residency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", util.ErrInvalidInput, err)
+		}
+		residency = &country
+	}
+	user, err := s.userRepo.UpdateUserResidency(ctx, s.dbExecutor, userID, residency)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("set user residency: failed to update user %d: %w", userID, err)
+	}
+	return user, nil
+}
+
+// SetUserContact normalizes the email and phone and merges them with the user's current ones before storing
+// both, encrypted.
+func (s *walletService) SetUserContact(ctx context.Context, userID int64, email, phone *string) (*domain.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, userID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("set user contact: failed to get user %d: %w", userID, err)
+	}
+
+	if email, err = mergeContactField(user.Email, email, domain.NormalizeEmail); err != nil {
+		return nil, err
+	}
+	if phone, err = mergeContactField(user.Phone, phone, domain.NormalizePhone); err != nil {
+		return nil, err
+	}
+	user, err = s.userRepo.UpdateUserContact(ctx, s.dbExecutor, userID, email, phone)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("set user contact: failed to update user %d: %w", userID, err)
+	}
+	return user, nil
+}
+
+// mergeContactField returns the current value when update is nil, nil when it is empty, and otherwise the
+// normalized update.
+func mergeContactField(current, update *string, normalize func(string) (string, error)) (*string, error) {
+	switch {
+	case update == nil:
+		return current, nil
+	case *update == "":
+		return nil, nil
+	}
+	normalized, err := normalize(*update)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", util.ErrInvalidInput, err)
+	}
+	return &normalized, nil
+}
+
+// canDebit reports whether amount can be taken from the wallet. Balances may only go negative
+// when the overdraft flag is on for the wallet's owner, down to the limit held in the flag's value,
+// and the owner has verified their email or phone.
+func (s *walletService) canDebit(ctx context.Context, wallet *domain.Wallet, amount decimal.Decimal) bool {
+	if wallet.Balance.GreaterThanOrEqual(amount) {
+		return true
+	}
+	if s.flags == nil {
+		return false
+	}
+	decision := s.flags.Evaluate(ctx, domain.FlagOverdraft, wallet.UserID)
+	if !decision.Enabled {
+		return false
+	}
+	limit, err := decimal.NewFromString(decision.Value)
+	if err != nil || limit.IsNegative() {
+		return false
+	}
+	if wallet.Balance.Sub(amount).LessThan(limit.Neg()) {
+		return false
+	}
+	owner, err := s.userRepo.GetUserByID(ctx, s.dbExecutor, wallet.UserID)
+	return err == nil && owner.HasVerifiedContact()
+}
+
+// lockPromoCredits locks the wallet's spendable promotional credits, soonest expiring first, and returns them
+// with the part of amount they cover and the rest, which is debited from the balance. Without a promo credit
+// repository nothing is covered.
+func (s *walletService) lockPromoCredits(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal, forWithdrawal bool) ([]domain.PromoCredit, decimal.Decimal, decimal.Decimal, error) {
+	if s.promoRepo == nil {
+		return nil, decimal.Zero, amount, nil
+	}
+	credits, err := s.promoRepo.ListSpendableCreditsForUpdate(ctx, q, walletID, s.clock.Now().UTC(), forWithdrawal)
+	if err != nil {
+		return nil, decimal.Zero, amount, err
+	}
+	covered := decimal.Zero
+	for i := range credits {
+		covered = covered.Add(credits[i].Remaining)
+	}
+	if !covered.IsPositive() {
+		return nil, decimal.Zero, amount, nil
+	}
+	covered = decimal.Min(covered, amount)
+	return credits, covered, amount.Sub(covered), nil
+}
+
+// consumePromoCredits spends amount from credits in the order lockPromoCredits returned them.
+func (s *walletService) consumePromoCredits(ctx context.Context, q repository.DBExecutor, credits []domain.PromoCredit, amount decimal.Decimal) error {
+	for i := range credits {
+		if !amount.IsPositive() {
+			break
+		}
+		spend := decimal.Min(credits[i].Remaining, amount)
+		if err := s.promoRepo.ConsumeCredit(ctx, q, credits[i].ID, spend); err != nil {
+			return err
+		}
+		amount = amount.Sub(spend)
+	}
+	return nil
+}
+
+// checkApprovalPolicy fails with util.ErrApprovalRequired when the source wallet's approval policy covers
+// the amount, unless the transfer is being executed for an approved request.
+func (s *walletService) checkApprovalPolicy(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal) error {
+	if s.memberRepo == nil || isApprovedTransfer(ctx) {
+		return nil
+	}
+	policy, err := s.memberRepo.GetApprovalPolicy(ctx, q, walletID)
+	if errors.Is(err, util.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get approval policy of wallet %d: %w", walletID, err)
+	}
+	if policy.Requires(amount) {
+		return util.ErrApprovalRequired
+	}
+	return nil
+}
+
+// checkBudgets fails with util.ErrBudgetExceeded when spending amount would take one of the wallet's
+// SOFT_BLOCK budgets covering the request's category over its limit for the current period.
+func (s *walletService) checkBudgets(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal) error {
+	if s.budgetRepo == nil || budgetOverridden(ctx) {
+		return nil
+	}
+	budgets, err := s.budgetRepo.ListBudgetsByWalletID(ctx, q, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to get budgets of wallet %d: %w", walletID, err)
+	}
+	category := transactionCategory(ctx)
+	now := s.clock.Now().UTC()
+	for i := range budgets {
+		budget := &budgets[i]
+		if budget.Enforcement != domain.BudgetEnforcementSoftBlock || !budget.Covers(category) {
+			continue
+		}
+		start, end := budget.Period.Window(now, budget.Location())
+		spent, err := s.transactionRepo.SumOutgoingAmount(ctx, q, walletID, budget.Category, start, end)
+		if err != nil {
+			return err
+		}
+		if spent.Add(amount).GreaterThan(budget.Limit) {
+			return util.ErrBudgetExceeded
+		}
+	}
+	return nil
+}
+
+// GetWalletByPublicID resolves the public UUID used by the API to a wallet. Suspense wallets are not found.
+func (s *walletService) GetWalletByPublicID(ctx context.Context, publicID uuid.UUID) (*domain.Wallet, error) {
+	wallet, err := s.walletRepo.GetWalletByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, util.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("get wallet: failed to get wallet %s: %w", publicID, err)
+	}
+	if wallet.Kind == domain.WalletKindSuspense {
+		return nil, util.ErrWalletNotFound // System wallets are only moved through the suspense admin API
+	}
 	return wallet, nil
 }
 
+// GetTransaction retrieves a single transaction by its public UUID.
+func (s *walletService) GetTransaction(ctx context.Context, publicID uuid.UUID) (*domain.Transaction, error) {
+	transaction, err := s.transactionRepo.GetTransactionByPublicID(ctx, s.dbExecutor, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get transaction: failed to get transaction %s: %w", publicID, err)
+	}
+	return transaction, nil
+}
+
+// WaitForTransaction watches the transaction before reading it, so a completion between the read and the
+// wait is not missed. Every update re-reads the transaction, as only the database holds its latest state.
+func (s *walletService) WaitForTransaction(ctx context.Context, publicID uuid.UUID, timeout time.Duration) (*domain.Transaction, error) {
+	if s.watcher == nil {
+		return s.GetTransaction(ctx, publicID)
+	}
+	updates, stop := s.watcher.Watch(publicID)
+	defer stop()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		transaction, err := s.GetTransaction(ctx, publicID)
+		if err != nil || transaction.Status != domain.TransactionStatusPending {
+			return transaction, err
+		}
+		select {
+		case <-updates:
+		case <-timer.C:
+			return transaction, nil
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait for transaction %s: %w", publicID, ctx.Err())
+		}
+	}
+}
+
 // GetTransactionHistory retrieves a paginated list of transactions for a specific wallet.
 func (s *walletService) GetTransactionHistory(ctx context.Context, walletID int64, limit, offset int) ([]domain.Transaction, int64, error) {
 	// First, check if the wallet exists
-	_, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, walletID)
+	wallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, walletID)
 	if err != nil {
 		if util.IsError(err, util.ErrNotFound) {
 			return nil, 0, util.ErrWalletNotFound
 		}
 		return nil, 0, fmt.Errorf("failed to check wallet existence: %w", err)
 	}
+	if err := s.authorize(ctx, ActionReadHistory, wallet, nil, decimal.Zero); err != nil {
+		return nil, 0, err
+	}
 
 	// Call repository to get transactions and total count
 	transactions, totalCount, err := s.transactionRepo.GetTransactionsByWalletID(ctx, s.dbExecutor, walletID, limit, offset)
@@ -255,7 +1165,66 @@ func (s *walletService) GetTransactionHistory(ctx context.Context, walletID int6
 	return transactions, totalCount, nil
 }
 
-func (s *walletService) CreateUserAndWallet(ctx context.Context, username, currency string) (*domain.User, *domain.Wallet, error) {
+// ListTransactionHistory retrieves a page of a wallet's transactions with sorting, projection and an optional date range.
+// The archive is only searched when the range starts before the retention horizon (or has no lower bound),
+// and by every search query without a lower bound.
+func (s *walletService) ListTransactionHistory(ctx context.Context, walletID int64, filter repository.TransactionFilter, opts repository.ListOptions) ([]domain.Transaction, int64, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, 0, util.ErrInvalidInput
+	}
+
+	wallet, err := s.walletRepo.GetWalletByID(ctx, s.dbExecutor, walletID)
+	if err != nil {
+		if util.IsError(err, util.ErrNotFound) {
+			return nil, 0, util.ErrWalletNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to check wallet existence: %w", err)
+	}
+	if err := s.authorize(ctx, ActionReadHistory, wallet, nil, decimal.Zero); err != nil {
+		return nil, 0, err
+	}
+
+	if s.archiveHorizon > 0 && (filter.From != nil || filter.To != nil || filter.Query != "") {
+		cutoff := domain.ArchiveCutoff(s.clock.Now().UTC(), s.archiveHorizon)
+		filter.IncludeArchive = filter.From == nil || filter.From.Before(cutoff)
+	}
+
+	transactions, totalCount, err := s.transactionRepo.ListTransactionsByWalletID(ctx, s.dbExecutor, walletID, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve transaction history: %w", err)
+	}
+
+	return transactions, totalCount, nil
+}
+
+// ListUsers retrieves a page of users.
+func (s *walletService) ListUsers(ctx context.Context, opts repository.ListOptions) ([]domain.User, int64, error) {
+	users, totalCount, err := s.userRepo.ListUsers(ctx, s.dbExecutor, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list users: %w", err)
+	}
+	return users, totalCount, nil
+}
+
+// ListWallets retrieves a page of wallets, optionally restricted to one user.
+func (s *walletService) ListWallets(ctx context.Context, filter repository.WalletFilter, opts repository.ListOptions) ([]domain.Wallet, int64, error) {
+	wallets, totalCount, err := s.walletRepo.ListWallets(ctx, s.dbExecutor, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list wallets: %w", err)
+	}
+	return wallets, totalCount, nil
+}
+
+// CreateUserAndWallet validates the residency and checks it against the wallet currency's restrictions first.
+func (s *walletService) CreateUserAndWallet(ctx context.Context, username, currency string, residency *string) (*domain.User, *domain.Wallet, error) {
+	if residency != nil {
+		country, err := domain.NormalizeCountryCode(*residency)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", util.ErrInvalidInput, err)
+		}
+		residency = &country
+	}
+
 	txController, err := s.beginTx(ctx, s.dbBeginner)
 	if err != nil {
 		return nil, nil, fmt.Errorf("create user and wallet: failed to begin transaction: %w", err)
@@ -267,19 +1236,43 @@ func (s *walletService) CreateUserAndWallet(ctx context.Context, username, curre
 		return nil, nil, fmt.Errorf("create user and wallet: transaction controller does not implement DBExecutor")
 	}
 
-	_, err = s.userRepo.GetUserByUsername(ctx, txExecutor, username)
+	existingUser, err := s.userRepo.GetUserByUsername(ctx, txExecutor, username)
 	if err == nil {
-		return nil, nil, fmt.Errorf("create user and wallet: user with username '%s' already exists", username)
+		return nil, nil, fmt.Errorf("create user and wallet: user with username '%s' already exists: %w",
+			username, &util.DuplicateEntryError{Resource: "user", ExistingID: existingUser.ID})
 	}
 	if !errors.Is(err, util.ErrNotFound) {
 		return nil, nil, fmt.Errorf("create user and wallet: failed to check existing user: %w", err)
 	}
+	if err := s.checkCurrencyRestriction(ctx, txExecutor, currency, residency); err != nil {
+		return nil, nil, fmt.Errorf("create user and wallet: %w", err)
+	}
+	if err := s.screenNewUser(ctx, username); err != nil {
+		return nil, nil, fmt.Errorf("create user and wallet: %w", err)
+	}
 
 	user := domain.NewUser(username)
+	user.Residency = residency
 	if err := s.userRepo.CreateUser(ctx, txExecutor, user); err != nil {
+		if errors.Is(err, util.ErrDuplicateEntry) {
+			// A concurrent request won the race past the check above; the unique constraint caught it.
+			// The transaction is aborted, so look the winner up outside of it.
+			duplicate := &util.DuplicateEntryError{Resource: "user"}
+			if existing, lookupErr := s.userRepo.GetUserByUsername(ctx, s.dbExecutor, username); lookupErr == nil {
+				duplicate.ExistingID = existing.ID
+			}
+			return nil, nil, fmt.Errorf("create user and wallet: user with username '%s' already exists: %w", username, duplicate)
+		}
 		return nil, nil, fmt.Errorf("create user and wallet: failed to create user: %w", err)
 	}
 
+	if err := s.linkProvisionedIdentity(ctx, txExecutor, user); err != nil {
+		return nil, nil, fmt.Errorf("create user and wallet: %w", err)
+	}
+	if err := s.recordSCIMProvisioning(ctx, txExecutor, user); err != nil {
+		return nil, nil, fmt.Errorf("create user and wallet: %w", err)
+	}
+
 	wallet := domain.NewWallet(user.ID, currency)
 	if err := s.walletRepo.CreateWallet(ctx, txExecutor, wallet); err != nil {
 		return nil, nil, fmt.Errorf("create user and wallet: failed to create wallet: %w", err)
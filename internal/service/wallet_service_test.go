@@ -5,13 +5,18 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"finflow-wallet/internal/domain"
 	"finflow-wallet/internal/repository"
 	"finflow-wallet/internal/util"
 	"finflow-wallet/pkg/db" // Import pkg/db for interfaces and function types
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -69,6 +74,87 @@ func (m *MockUserRepository) GetUserByUsername(ctx context.Context, q repository
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdateUsername(ctx context.Context, q repository.DBExecutor, id int64, username string) (*domain.User, error) {
+	args := m.Called(ctx, q, id, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) SetUserDeactivated(ctx context.Context, q repository.DBExecutor, id int64, at *time.Time) (*domain.User, error) {
+	args := m.Called(ctx, q, id, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUserTimezone(ctx context.Context, q repository.DBExecutor, id int64, timezone string) (*domain.User, error) {
+	args := m.Called(ctx, q, id, timezone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUserResidency(ctx context.Context, q repository.DBExecutor, id int64, residency *string) (*domain.User, error) {
+	args := m.Called(ctx, q, id, residency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUserContact(ctx context.Context, q repository.DBExecutor, id int64, email, phone *string) (*domain.User, error) {
+	args := m.Called(ctx, q, id, email, phone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) MarkContactVerified(ctx context.Context, q repository.DBExecutor, id int64, channel domain.ContactChannel, targetHash string, at time.Time) (*domain.User, error) {
+	args := m.Called(ctx, q, id, channel, targetHash, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ReencryptPII(ctx context.Context, q repository.DBExecutor, limit int) (int, error) {
+	args := m.Called(ctx, q, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) ListUsers(ctx context.Context, q repository.DBExecutor, opts repository.ListOptions) ([]domain.User, int64, error) {
+	args := m.Called(ctx, q, opts)
+	return args.Get(0).([]domain.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) SoftDeleteUser(ctx context.Context, q repository.DBExecutor, id int64, at time.Time) error {
+	args := m.Called(ctx, q, id, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RestoreUser(ctx context.Context, q repository.DBExecutor, id int64) (time.Time, error) {
+	args := m.Called(ctx, q, id)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockUserRepository) ListDeletedUserIDs(ctx context.Context, q repository.DBExecutor, before time.Time) ([]int64, error) {
+	args := m.Called(ctx, q, before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockUserRepository) PurgeUser(ctx context.Context, q repository.DBExecutor, id int64) error {
+	args := m.Called(ctx, q, id)
+	return args.Error(0)
+}
+
 // MockWalletRepository is a mock implementation of repository.WalletRepository.
 type MockWalletRepository struct {
 	mock.Mock
@@ -87,6 +173,30 @@ func (m *MockWalletRepository) GetWalletByID(ctx context.Context, q repository.D
 	return args.Get(0).(*domain.Wallet), args.Error(1)
 }
 
+func (m *MockWalletRepository) GetWalletsByIDs(ctx context.Context, q repository.DBExecutor, ids []int64) ([]domain.Wallet, error) {
+	args := m.Called(ctx, q, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) GetWalletByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Wallet, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) GetWalletByIDForUpdate(ctx context.Context, q repository.DBExecutor, id int64) (*domain.Wallet, error) {
+	args := m.Called(ctx, q, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
 func (m *MockWalletRepository) GetWalletByUserIDAndCurrency(ctx context.Context, q repository.DBExecutor, userID int64, currency string) (*domain.Wallet, error) {
 	args := m.Called(ctx, q, userID, currency)
 	if args.Get(0) == nil {
@@ -95,11 +205,97 @@ func (m *MockWalletRepository) GetWalletByUserIDAndCurrency(ctx context.Context,
 	return args.Get(0).(*domain.Wallet), args.Error(1)
 }
 
-func (m *MockWalletRepository) UpdateWalletBalance(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal) error {
-	args := m.Called(ctx, q, walletID, amount)
+func (m *MockWalletRepository) UpdateWalletBalance(ctx context.Context, q repository.DBExecutor, walletID int64, amount decimal.Decimal, at time.Time) (*domain.WalletBalance, error) {
+	args := m.Called(ctx, q, walletID, amount, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WalletBalance), args.Error(1)
+}
+
+func (m *MockWalletRepository) UpdateWalletBalances(ctx context.Context, q repository.DBExecutor, amounts map[int64]decimal.Decimal, at time.Time) ([]domain.Wallet, error) {
+	args := m.Called(ctx, q, amounts, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) UpdateWalletKind(ctx context.Context, q repository.DBExecutor, walletID int64, kind domain.WalletKind) error {
+	args := m.Called(ctx, q, walletID, kind)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) ListWallets(ctx context.Context, q repository.DBExecutor, filter repository.WalletFilter, opts repository.ListOptions) ([]domain.Wallet, int64, error) {
+	args := m.Called(ctx, q, filter, opts)
+	return args.Get(0).([]domain.Wallet), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockWalletRepository) SoftDeleteWallet(ctx context.Context, q repository.DBExecutor, walletID int64, at time.Time) error {
+	args := m.Called(ctx, q, walletID, at)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) SoftDeleteWalletsByUser(ctx context.Context, q repository.DBExecutor, userID int64, at time.Time) error {
+	args := m.Called(ctx, q, userID, at)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) RestoreWallet(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Wallet, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) RestoreWalletsByUser(ctx context.Context, q repository.DBExecutor, userID int64, deletedAt time.Time) error {
+	args := m.Called(ctx, q, userID, deletedAt)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) ListDeletedWalletIDs(ctx context.Context, q repository.DBExecutor, before time.Time) ([]int64, error) {
+	args := m.Called(ctx, q, before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockWalletRepository) PurgeWallet(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	args := m.Called(ctx, q, walletID)
 	return args.Error(0)
 }
 
+func (m *MockWalletRepository) FlagDormantWallets(ctx context.Context, q repository.DBExecutor, inactiveSince, at time.Time, freeze bool, limit int) (int64, error) {
+	args := m.Called(ctx, q, inactiveSince, at, freeze, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWalletRepository) ReactivateWallet(ctx context.Context, q repository.DBExecutor, walletID int64, at time.Time) (*domain.Wallet, error) {
+	args := m.Called(ctx, q, walletID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) SetOwnershipFrozen(ctx context.Context, q repository.DBExecutor, walletID int64, frozen bool, at time.Time) (*domain.Wallet, error) {
+	args := m.Called(ctx, q, walletID, frozen, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) ReassignWallet(ctx context.Context, q repository.DBExecutor, walletID, userID int64, at time.Time) (*domain.Wallet, error) {
+	args := m.Called(ctx, q, walletID, userID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
 // MockTransactionRepository is a mock implementation of repository.TransactionRepository.
 type MockTransactionRepository struct {
 	mock.Mock
@@ -110,49 +306,2297 @@ func (m *MockTransactionRepository) CreateTransaction(ctx context.Context, q rep
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) GetTransactionByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Transaction, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
 func (m *MockTransactionRepository) GetTransactionsByWalletID(ctx context.Context, q repository.DBExecutor, walletID int64, limit, offset int) ([]domain.Transaction, int64, error) {
 	args := m.Called(ctx, q, walletID, limit, offset)
 	// Ensure that args.Get(1) is always an int64 to prevent panic
 	return args.Get(0).([]domain.Transaction), args.Get(1).(int64), args.Error(2)
 }
 
-// MockDBBeginner is a mock implementation of db.DBTxBeginner.
-type MockDBBeginner struct {
-	mock.Mock
-}
+func (m *MockTransactionRepository) ListTransactionsByWalletID(ctx context.Context, q repository.DBExecutor, walletID int64, filter repository.TransactionFilter, opts repository.ListOptions) ([]domain.Transaction, int64, error) {
+	args := m.Called(ctx, q, walletID, filter, opts)
+	return args.Get(0).([]domain.Transaction), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTransactionRepository) FindRecentTransfer(ctx context.Context, q repository.DBExecutor, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string, since time.Time) (*domain.Transaction, error) {
+	args := m.Called(ctx, q, fromWalletID, toWalletID, amount, currency, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) HasTransferredTo(ctx context.Context, q repository.DBExecutor, fromWalletID, toWalletID int64) (bool, error) {
+	args := m.Called(ctx, q, fromWalletID, toWalletID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) SumOutgoingAmount(ctx context.Context, q repository.DBExecutor, walletID int64, category *string, from, to time.Time) (decimal.Decimal, error) {
+	args := m.Called(ctx, q, walletID, category, from, to)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+// MockFeatureFlagRepository is a mock implementation of repository.FeatureFlagRepository.
+type MockFeatureFlagRepository struct {
+	mock.Mock
+}
+
+func (m *MockFeatureFlagRepository) ListFlags(ctx context.Context, q repository.DBExecutor) ([]domain.FeatureFlag, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.FeatureFlag), args.Error(1)
+}
+
+func (m *MockFeatureFlagRepository) UpsertFlag(ctx context.Context, q repository.DBExecutor, flag *domain.FeatureFlag) error {
+	args := m.Called(ctx, q, flag)
+	return args.Error(0)
+}
+
+func (m *MockFeatureFlagRepository) DeleteFlag(ctx context.Context, q repository.DBExecutor, key string) error {
+	args := m.Called(ctx, q, key)
+	return args.Error(0)
+}
+
+// MockFXRateRepository is a mock implementation of repository.FXRateRepository.
+type MockFXRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockFXRateRepository) ListRates(ctx context.Context, q repository.DBExecutor) ([]domain.FXRate, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.FXRate), args.Error(1)
+}
+
+// MockSweepRuleRepository is a mock implementation of repository.SweepRuleRepository.
+type MockSweepRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockSweepRuleRepository) CreateRule(ctx context.Context, q repository.DBExecutor, rule *domain.SweepRule) error {
+	args := m.Called(ctx, q, rule)
+	return args.Error(0)
+}
+
+func (m *MockSweepRuleRepository) ListRulesByUserID(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.SweepRule, error) {
+	args := m.Called(ctx, q, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SweepRule), args.Error(1)
+}
+
+func (m *MockSweepRuleRepository) ListEnabledRulesByWatchedWallet(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.SweepRule, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SweepRule), args.Error(1)
+}
+
+func (m *MockSweepRuleRepository) DeleteRule(ctx context.Context, q repository.DBExecutor, userID, ruleID int64) error {
+	args := m.Called(ctx, q, userID, ruleID)
+	return args.Error(0)
+}
+
+// MockSweepExecutor is a mock implementation of SweepExecutor.
+type MockSweepExecutor struct {
+	mock.Mock
+}
+
+func (m *MockSweepExecutor) ApplySweepRule(ctx context.Context, rule *domain.SweepRule) (*domain.Transaction, error) {
+	args := m.Called(ctx, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
+// MockWalletMemberRepository is a mock implementation of repository.WalletMemberRepository.
+type MockWalletMemberRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletMemberRepository) AddMember(ctx context.Context, q repository.DBExecutor, member *domain.WalletMember) error {
+	args := m.Called(ctx, q, member)
+	return args.Error(0)
+}
+
+func (m *MockWalletMemberRepository) ListMembers(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.WalletMember, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WalletMember), args.Error(1)
+}
+
+func (m *MockWalletMemberRepository) RemoveMember(ctx context.Context, q repository.DBExecutor, walletID, userID int64) error {
+	args := m.Called(ctx, q, walletID, userID)
+	return args.Error(0)
+}
+
+func (m *MockWalletMemberRepository) GetApprovalPolicy(ctx context.Context, q repository.DBExecutor, walletID int64) (*domain.ApprovalPolicy, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ApprovalPolicy), args.Error(1)
+}
+
+func (m *MockWalletMemberRepository) SaveApprovalPolicy(ctx context.Context, q repository.DBExecutor, policy *domain.ApprovalPolicy) error {
+	args := m.Called(ctx, q, policy)
+	return args.Error(0)
+}
+
+func (m *MockWalletMemberRepository) DeleteApprovalPolicy(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	args := m.Called(ctx, q, walletID)
+	return args.Error(0)
+}
+
+// MockTransferApprovalRepository is a mock implementation of repository.TransferApprovalRepository.
+type MockTransferApprovalRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferApprovalRepository) CreateApproval(ctx context.Context, q repository.DBExecutor, approval *domain.TransferApproval) error {
+	args := m.Called(ctx, q, approval)
+	return args.Error(0)
+}
+
+func (m *MockTransferApprovalRepository) GetApprovalByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.TransferApproval, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferApproval), args.Error(1)
+}
+
+func (m *MockTransferApprovalRepository) GetApprovalByPublicIDForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.TransferApproval, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferApproval), args.Error(1)
+}
+
+func (m *MockTransferApprovalRepository) AddVote(ctx context.Context, q repository.DBExecutor, approvalID, userID int64) error {
+	args := m.Called(ctx, q, approvalID, userID)
+	return args.Error(0)
+}
+
+func (m *MockTransferApprovalRepository) UpdateApproval(ctx context.Context, q repository.DBExecutor, approval *domain.TransferApproval) error {
+	args := m.Called(ctx, q, approval)
+	return args.Error(0)
+}
+
+// MockTransferExecutor is a mock implementation of TransferExecutor.
+type MockTransferExecutor struct {
+	mock.Mock
+}
+
+func (m *MockTransferExecutor) Transfer(ctx context.Context, fromWalletID, toWalletID int64, amount decimal.Decimal, currency string) (*domain.Wallet, *domain.Wallet, *domain.Transaction, error) {
+	args := m.Called(ctx, fromWalletID, toWalletID, amount, currency)
+	if args.Get(2) == nil {
+		return nil, nil, nil, args.Error(3)
+	}
+	return args.Get(0).(*domain.Wallet), args.Get(1).(*domain.Wallet), args.Get(2).(*domain.Transaction), args.Error(3)
+}
+
+// MockBudgetRepository is a mock implementation of repository.BudgetRepository.
+type MockBudgetRepository struct {
+	mock.Mock
+}
+
+func (m *MockBudgetRepository) CreateBudget(ctx context.Context, q repository.DBExecutor, budget *domain.Budget) error {
+	args := m.Called(ctx, q, budget)
+	return args.Error(0)
+}
+
+func (m *MockBudgetRepository) ListBudgetsByWalletID(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.Budget, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Budget), args.Error(1)
+}
+
+func (m *MockBudgetRepository) DeleteBudget(ctx context.Context, q repository.DBExecutor, walletID, budgetID int64) error {
+	args := m.Called(ctx, q, walletID, budgetID)
+	return args.Error(0)
+}
+
+// MockMerchantRepository is a mock implementation of repository.MerchantRepository.
+type MockMerchantRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchantRepository) SaveSettings(ctx context.Context, q repository.DBExecutor, settings *domain.MerchantSettings) error {
+	args := m.Called(ctx, q, settings)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) GetSettings(ctx context.Context, q repository.DBExecutor, walletID int64) (*domain.MerchantSettings, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MerchantSettings), args.Error(1)
+}
+
+func (m *MockMerchantRepository) ListSettings(ctx context.Context, q repository.DBExecutor) ([]domain.MerchantSettings, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.MerchantSettings), args.Error(1)
+}
+
+func (m *MockMerchantRepository) CreateSettlement(ctx context.Context, q repository.DBExecutor, settlement *domain.Settlement) error {
+	args := m.Called(ctx, q, settlement)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) ListSettlements(ctx context.Context, q repository.DBExecutor, merchantWalletID int64) ([]domain.Settlement, error) {
+	args := m.Called(ctx, q, merchantWalletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Settlement), args.Error(1)
+}
+
+// MockChargeRepository is a mock implementation of repository.ChargeRepository.
+type MockChargeRepository struct {
+	mock.Mock
+}
+
+func (m *MockChargeRepository) CreateCharge(ctx context.Context, q repository.DBExecutor, charge *domain.Charge) error {
+	args := m.Called(ctx, q, charge)
+	return args.Error(0)
+}
+
+func (m *MockChargeRepository) GetChargeByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Charge, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Charge), args.Error(1)
+}
+
+func (m *MockChargeRepository) GetChargeByPublicIDForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Charge, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Charge), args.Error(1)
+}
+
+func (m *MockChargeRepository) UpdateCharge(ctx context.Context, q repository.DBExecutor, charge *domain.Charge) error {
+	args := m.Called(ctx, q, charge)
+	return args.Error(0)
+}
+
+func (m *MockChargeRepository) ListUnsettledChargesForUpdate(ctx context.Context, q repository.DBExecutor, merchantWalletID int64, paidBefore time.Time) ([]domain.Charge, error) {
+	args := m.Called(ctx, q, merchantWalletID, paidBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Charge), args.Error(1)
+}
+
+func (m *MockChargeRepository) MarkChargesSettled(ctx context.Context, q repository.DBExecutor, settlementID int64, chargeIDs []int64) error {
+	args := m.Called(ctx, q, settlementID, chargeIDs)
+	return args.Error(0)
+}
+
+// MockBillRepository is a mock implementation of repository.BillRepository.
+type MockBillRepository struct {
+	mock.Mock
+}
+
+func (m *MockBillRepository) CreateBill(ctx context.Context, q repository.DBExecutor, bill *domain.Bill) error {
+	args := m.Called(ctx, q, bill)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) GetBillByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Bill, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) GetBillByPublicIDForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Bill, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) ListBillsByWallet(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.Bill, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Bill), args.Error(1)
+}
+
+func (m *MockBillRepository) UpdateBillStatus(ctx context.Context, q repository.DBExecutor, bill *domain.Bill) error {
+	args := m.Called(ctx, q, bill)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) UpdateParticipant(ctx context.Context, q repository.DBExecutor, participant *domain.BillParticipant) error {
+	args := m.Called(ctx, q, participant)
+	return args.Error(0)
+}
+
+func (m *MockBillRepository) ListDueReminders(ctx context.Context, q repository.DBExecutor, since time.Time) ([]domain.BillParticipant, error) {
+	args := m.Called(ctx, q, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.BillParticipant), args.Error(1)
+}
+
+func (m *MockBillRepository) MarkReminded(ctx context.Context, q repository.DBExecutor, billID, walletID int64, at time.Time) error {
+	args := m.Called(ctx, q, billID, walletID, at)
+	return args.Error(0)
+}
+
+// MockVoucherRepository is a mock implementation of repository.VoucherRepository.
+type MockVoucherRepository struct {
+	mock.Mock
+}
+
+func (m *MockVoucherRepository) CreateVoucher(ctx context.Context, q repository.DBExecutor, voucher *domain.Voucher) error {
+	args := m.Called(ctx, q, voucher)
+	return args.Error(0)
+}
+
+func (m *MockVoucherRepository) GetVoucherByCodeHash(ctx context.Context, q repository.DBExecutor, codeHash string) (*domain.Voucher, error) {
+	args := m.Called(ctx, q, codeHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Voucher), args.Error(1)
+}
+
+func (m *MockVoucherRepository) ClaimVoucher(ctx context.Context, q repository.DBExecutor, codeHash string, walletID int64, transactionID uuid.UUID, now time.Time) (*domain.Voucher, error) {
+	args := m.Called(ctx, q, codeHash, walletID, transactionID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Voucher), args.Error(1)
+}
+
+func (m *MockVoucherRepository) SumLiability(ctx context.Context, q repository.DBExecutor, now time.Time) ([]domain.VoucherLiability, error) {
+	args := m.Called(ctx, q, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.VoucherLiability), args.Error(1)
+}
+
+// MockAutoTopUpRepository is a mock implementation of repository.AutoTopUpRepository.
+type MockAutoTopUpRepository struct {
+	mock.Mock
+}
+
+func (m *MockAutoTopUpRepository) CreateFundingSource(ctx context.Context, q repository.DBExecutor, source *domain.FundingSource) error {
+	args := m.Called(ctx, q, source)
+	return args.Error(0)
+}
+
+func (m *MockAutoTopUpRepository) ListFundingSources(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.FundingSource, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.FundingSource), args.Error(1)
+}
+
+func (m *MockAutoTopUpRepository) GetFundingSource(ctx context.Context, q repository.DBExecutor, walletID, sourceID int64) (*domain.FundingSource, error) {
+	args := m.Called(ctx, q, walletID, sourceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.FundingSource), args.Error(1)
+}
+
+func (m *MockAutoTopUpRepository) DeleteFundingSource(ctx context.Context, q repository.DBExecutor, walletID, sourceID int64) error {
+	args := m.Called(ctx, q, walletID, sourceID)
+	return args.Error(0)
+}
+
+func (m *MockAutoTopUpRepository) GetRule(ctx context.Context, q repository.DBExecutor, walletID int64) (*domain.AutoTopUpRule, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AutoTopUpRule), args.Error(1)
+}
+
+func (m *MockAutoTopUpRepository) SaveRule(ctx context.Context, q repository.DBExecutor, rule *domain.AutoTopUpRule) error {
+	args := m.Called(ctx, q, rule)
+	return args.Error(0)
+}
+
+func (m *MockAutoTopUpRepository) DeleteRule(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	args := m.Called(ctx, q, walletID)
+	return args.Error(0)
+}
+
+func (m *MockAutoTopUpRepository) ListDueRules(ctx context.Context, q repository.DBExecutor, attemptedBefore time.Time) ([]domain.AutoTopUpRule, error) {
+	args := m.Called(ctx, q, attemptedBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AutoTopUpRule), args.Error(1)
+}
+
+func (m *MockAutoTopUpRepository) ClaimRule(ctx context.Context, q repository.DBExecutor, walletID int64, now, attemptedBefore time.Time) error {
+	args := m.Called(ctx, q, walletID, now, attemptedBefore)
+	return args.Error(0)
+}
+
+func (m *MockAutoTopUpRepository) RecordOutcome(ctx context.Context, q repository.DBExecutor, walletID int64, consecutiveFailures int, lastError *string, enabled bool) error {
+	args := m.Called(ctx, q, walletID, consecutiveFailures, lastError, enabled)
+	return args.Error(0)
+}
+
+// MockPaymentGateway is a mock implementation of PaymentGateway.
+type MockPaymentGateway struct {
+	mock.Mock
+}
+
+func (m *MockPaymentGateway) Charge(ctx context.Context, req ChargeRequest) (string, error) {
+	args := m.Called(ctx, req)
+	return args.String(0), args.Error(1)
+}
+
+// MockCryptoRepository is a mock implementation of repository.CryptoRepository.
+type MockCryptoRepository struct {
+	mock.Mock
+}
+
+func (m *MockCryptoRepository) CreateAddress(ctx context.Context, q repository.DBExecutor, address *domain.CryptoAddress) error {
+	args := m.Called(ctx, q, address)
+	return args.Error(0)
+}
+
+func (m *MockCryptoRepository) ListAddresses(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.CryptoAddress, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CryptoAddress), args.Error(1)
+}
+
+func (m *MockCryptoRepository) GetAddress(ctx context.Context, q repository.DBExecutor, network, address string) (*domain.CryptoAddress, error) {
+	args := m.Called(ctx, q, network, address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CryptoAddress), args.Error(1)
+}
+
+func (m *MockCryptoRepository) RecordDeposit(ctx context.Context, q repository.DBExecutor, deposit *domain.CryptoDeposit) error {
+	args := m.Called(ctx, q, deposit)
+	return args.Error(0)
+}
+
+func (m *MockCryptoRepository) GetDepositForUpdate(ctx context.Context, q repository.DBExecutor, depositID int64) (*domain.CryptoDeposit, error) {
+	args := m.Called(ctx, q, depositID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CryptoDeposit), args.Error(1)
+}
+
+func (m *MockCryptoRepository) MarkDepositCredited(ctx context.Context, q repository.DBExecutor, depositID int64, transactionID uuid.UUID, creditedAt time.Time) error {
+	args := m.Called(ctx, q, depositID, transactionID, creditedAt)
+	return args.Error(0)
+}
+
+func (m *MockCryptoRepository) ListDeposits(ctx context.Context, q repository.DBExecutor, walletID int64, limit int) ([]domain.CryptoDeposit, error) {
+	args := m.Called(ctx, q, walletID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CryptoDeposit), args.Error(1)
+}
+
+func (m *MockCryptoRepository) CreatePayout(ctx context.Context, q repository.DBExecutor, payout *domain.CryptoPayout) error {
+	args := m.Called(ctx, q, payout)
+	return args.Error(0)
+}
+
+func (m *MockCryptoRepository) GetPayoutByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.CryptoPayout, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CryptoPayout), args.Error(1)
+}
+
+func (m *MockCryptoRepository) ListPayouts(ctx context.Context, q repository.DBExecutor, walletID int64, limit int) ([]domain.CryptoPayout, error) {
+	args := m.Called(ctx, q, walletID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CryptoPayout), args.Error(1)
+}
+
+func (m *MockCryptoRepository) ClaimNextPayout(ctx context.Context, q repository.DBExecutor, now time.Time) (*domain.CryptoPayout, error) {
+	args := m.Called(ctx, q, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CryptoPayout), args.Error(1)
+}
+
+func (m *MockCryptoRepository) RequeueStalePayouts(ctx context.Context, q repository.DBExecutor, claimedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, q, claimedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCryptoRepository) RequeuePayout(ctx context.Context, q repository.DBExecutor, payoutID int64, lastError string) error {
+	args := m.Called(ctx, q, payoutID, lastError)
+	return args.Error(0)
+}
+
+func (m *MockCryptoRepository) MarkPayoutSent(ctx context.Context, q repository.DBExecutor, payoutID int64, chainTxHash string) error {
+	args := m.Called(ctx, q, payoutID, chainTxHash)
+	return args.Error(0)
+}
+
+func (m *MockCryptoRepository) MarkPayoutFailed(ctx context.Context, q repository.DBExecutor, payoutID int64, lastError string, refundTransactionID uuid.UUID) error {
+	args := m.Called(ctx, q, payoutID, lastError, refundTransactionID)
+	return args.Error(0)
+}
+
+// MockChainGateway is a mock implementation of ChainGateway.
+type MockChainGateway struct {
+	mock.Mock
+}
+
+func (m *MockChainGateway) NewAddress(ctx context.Context, asset domain.CryptoAsset, label string) (string, error) {
+	args := m.Called(ctx, asset, label)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockChainGateway) ListDeposits(ctx context.Context, asset domain.CryptoAsset) ([]ChainDeposit, error) {
+	args := m.Called(ctx, asset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ChainDeposit), args.Error(1)
+}
+
+func (m *MockChainGateway) EstimateFee(ctx context.Context, asset domain.CryptoAsset, address string, amount decimal.Decimal) (decimal.Decimal, error) {
+	args := m.Called(ctx, asset, address, amount)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func (m *MockChainGateway) SendPayout(ctx context.Context, req PayoutRequest) (string, error) {
+	args := m.Called(ctx, req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockChainGateway) HotWalletBalance(ctx context.Context, asset domain.CryptoAsset) (decimal.Decimal, error) {
+	args := m.Called(ctx, asset)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+// MockRampRepository is a mock implementation of repository.RampRepository.
+type MockRampRepository struct {
+	mock.Mock
+}
+
+func (m *MockRampRepository) CreateRamp(ctx context.Context, q repository.DBExecutor, order *domain.RampOrder) error {
+	args := m.Called(ctx, q, order)
+	return args.Error(0)
+}
+
+func (m *MockRampRepository) GetRampByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.RampOrder, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RampOrder), args.Error(1)
+}
+
+func (m *MockRampRepository) MarkRampExecuted(ctx context.Context, q repository.DBExecutor, id int64, debitTransactionID, creditTransactionID uuid.UUID, executedAt time.Time) error {
+	args := m.Called(ctx, q, id, debitTransactionID, creditTransactionID, executedAt)
+	return args.Error(0)
+}
+
+// MockOpenBankingRepository is a mock implementation of repository.OpenBankingRepository.
+type MockOpenBankingRepository struct {
+	mock.Mock
+}
+
+func (m *MockOpenBankingRepository) CreateConsent(ctx context.Context, q repository.DBExecutor, consent *domain.OBConsent) error {
+	args := m.Called(ctx, q, consent)
+	return args.Error(0)
+}
+
+func (m *MockOpenBankingRepository) GetConsent(ctx context.Context, q repository.DBExecutor, consentID uuid.UUID) (*domain.OBConsent, error) {
+	args := m.Called(ctx, q, consentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OBConsent), args.Error(1)
+}
+
+func (m *MockOpenBankingRepository) ListConsentsByUser(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.OBConsent, error) {
+	args := m.Called(ctx, q, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.OBConsent), args.Error(1)
+}
+
+func (m *MockOpenBankingRepository) ResolveConsent(ctx context.Context, q repository.DBExecutor, id int64, status domain.OBConsentStatus, userID int64, walletIDs []int64, at time.Time) error {
+	args := m.Called(ctx, q, id, status, userID, walletIDs, at)
+	return args.Error(0)
+}
+
+func (m *MockOpenBankingRepository) RevokeConsent(ctx context.Context, q repository.DBExecutor, id int64, at time.Time) error {
+	args := m.Called(ctx, q, id, at)
+	return args.Error(0)
+}
+
+// MockIdentityRepository is a mock implementation of repository.IdentityRepository.
+type MockIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdentityRepository) CreateIdentity(ctx context.Context, q repository.DBExecutor, identity *domain.UserIdentity) error {
+	args := m.Called(ctx, q, identity)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepository) GetIdentity(ctx context.Context, q repository.DBExecutor, provider, subject string) (*domain.UserIdentity, error) {
+	args := m.Called(ctx, q, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserIdentity), args.Error(1)
+}
+
+func (m *MockIdentityRepository) ListIdentitiesByUser(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.UserIdentity, error) {
+	args := m.Called(ctx, q, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.UserIdentity), args.Error(1)
+}
+
+func (m *MockIdentityRepository) DeleteIdentity(ctx context.Context, q repository.DBExecutor, userID int64, provider string) error {
+	args := m.Called(ctx, q, userID, provider)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepository) TouchIdentity(ctx context.Context, q repository.DBExecutor, id int64, at time.Time) error {
+	args := m.Called(ctx, q, id, at)
+	return args.Error(0)
+}
+
+// MockSCIMRepository is a mock implementation of repository.SCIMRepository.
+type MockSCIMRepository struct {
+	mock.Mock
+}
+
+func (m *MockSCIMRepository) CreateSCIMUser(ctx context.Context, q repository.DBExecutor, scimUser *domain.SCIMUser) error {
+	args := m.Called(ctx, q, scimUser)
+	return args.Error(0)
+}
+
+func (m *MockSCIMRepository) GetSCIMUser(ctx context.Context, q repository.DBExecutor, tenant string, publicID uuid.UUID, lock bool) (*domain.SCIMUser, error) {
+	args := m.Called(ctx, q, tenant, publicID, lock)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SCIMUser), args.Error(1)
+}
+
+func (m *MockSCIMRepository) GetSCIMUserByUserID(ctx context.Context, q repository.DBExecutor, tenant string, userID int64) (*domain.SCIMUser, error) {
+	args := m.Called(ctx, q, tenant, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SCIMUser), args.Error(1)
+}
+
+func (m *MockSCIMRepository) GetSCIMUserByExternalID(ctx context.Context, q repository.DBExecutor, tenant, externalID string) (*domain.SCIMUser, error) {
+	args := m.Called(ctx, q, tenant, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SCIMUser), args.Error(1)
+}
+
+func (m *MockSCIMRepository) ListSCIMUsers(ctx context.Context, q repository.DBExecutor, tenant string, limit, offset int) ([]domain.SCIMUser, int64, error) {
+	args := m.Called(ctx, q, tenant, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]domain.SCIMUser), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockSCIMRepository) UpdateSCIMUser(ctx context.Context, q repository.DBExecutor, id int64, externalID *string, at time.Time) error {
+	args := m.Called(ctx, q, id, externalID, at)
+	return args.Error(0)
+}
+
+func (m *MockSCIMRepository) AppendProvisioningEvent(ctx context.Context, q repository.DBExecutor, event *domain.SCIMProvisioningEvent) error {
+	args := m.Called(ctx, q, event)
+	return args.Error(0)
+}
+
+func (m *MockSCIMRepository) ListProvisioningEvents(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.SCIMProvisioningEvent, error) {
+	args := m.Called(ctx, q, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SCIMProvisioningEvent), args.Error(1)
+}
+
+// MockNettingRepository is a mock implementation of repository.NettingRepository.
+type MockNettingRepository struct {
+	mock.Mock
+}
+
+func (m *MockNettingRepository) CreateInstruction(ctx context.Context, q repository.DBExecutor, instruction *domain.NettingInstruction) error {
+	args := m.Called(ctx, q, instruction)
+	return args.Error(0)
+}
+
+func (m *MockNettingRepository) GetInstructionByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.NettingInstruction, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NettingInstruction), args.Error(1)
+}
+
+func (m *MockNettingRepository) ListPendingPairs(ctx context.Context, q repository.DBExecutor, windowEnd time.Time) ([]domain.NettingPair, error) {
+	args := m.Called(ctx, q, windowEnd)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.NettingPair), args.Error(1)
+}
+
+func (m *MockNettingRepository) ListPendingInstructionsForUpdate(ctx context.Context, q repository.DBExecutor, pair domain.NettingPair, windowEnd time.Time) ([]domain.NettingInstruction, error) {
+	args := m.Called(ctx, q, pair, windowEnd)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.NettingInstruction), args.Error(1)
+}
+
+func (m *MockNettingRepository) CreateSettlement(ctx context.Context, q repository.DBExecutor, settlement *domain.NettingSettlement) error {
+	args := m.Called(ctx, q, settlement)
+	return args.Error(0)
+}
+
+func (m *MockNettingRepository) GetSettlementByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.NettingSettlement, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NettingSettlement), args.Error(1)
+}
+
+func (m *MockNettingRepository) MarkInstructionsSettled(ctx context.Context, q repository.DBExecutor, settlementID int64, instructionIDs []int64, at time.Time) error {
+	args := m.Called(ctx, q, settlementID, instructionIDs, at)
+	return args.Error(0)
+}
+
+func (m *MockNettingRepository) ListSettlementInstructions(ctx context.Context, q repository.DBExecutor, settlementID int64) ([]domain.NettingInstruction, error) {
+	args := m.Called(ctx, q, settlementID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.NettingInstruction), args.Error(1)
+}
+
+// MockReportRepository is a mock implementation of repository.ReportRepository.
+type MockReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportRepository) SumBalances(ctx context.Context, q repository.DBExecutor) ([]domain.CurrencyLiability, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CurrencyLiability), args.Error(1)
+}
+
+func (m *MockReportRepository) SumFloatMovement(ctx context.Context, q repository.DBExecutor, from, to time.Time) ([]domain.FloatMovement, error) {
+	args := m.Called(ctx, q, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.FloatMovement), args.Error(1)
+}
+
+func (m *MockReportRepository) ListLargestBalances(ctx context.Context, q repository.DBExecutor, limit int) ([]domain.RankedBalance, error) {
+	args := m.Called(ctx, q, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.RankedBalance), args.Error(1)
+}
+
+// MockSuspenseRepository is a mock implementation of repository.SuspenseRepository.
+type MockSuspenseRepository struct {
+	mock.Mock
+}
+
+func (m *MockSuspenseRepository) GetSuspenseWallet(ctx context.Context, q repository.DBExecutor, currency string) (*domain.Wallet, error) {
+	args := m.Called(ctx, q, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockSuspenseRepository) CreateInboundFunds(ctx context.Context, q repository.DBExecutor, funds *domain.InboundFunds) error {
+	args := m.Called(ctx, q, funds)
+	return args.Error(0)
+}
+
+func (m *MockSuspenseRepository) GetInboundFundsByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.InboundFunds, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InboundFunds), args.Error(1)
+}
+
+func (m *MockSuspenseRepository) GetInboundFundsByReference(ctx context.Context, q repository.DBExecutor, provider, reference string) (*domain.InboundFunds, error) {
+	args := m.Called(ctx, q, provider, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InboundFunds), args.Error(1)
+}
+
+func (m *MockSuspenseRepository) GetInboundFundsForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.InboundFunds, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InboundFunds), args.Error(1)
+}
+
+func (m *MockSuspenseRepository) ListSuspendedFunds(ctx context.Context, q repository.DBExecutor, currency string) ([]domain.InboundFunds, error) {
+	args := m.Called(ctx, q, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.InboundFunds), args.Error(1)
+}
+
+func (m *MockSuspenseRepository) MarkReassigned(ctx context.Context, q repository.DBExecutor, fundsID, walletID int64, adjustmentID uuid.UUID, operator, reason string, at time.Time) error {
+	args := m.Called(ctx, q, fundsID, walletID, adjustmentID, operator, reason, at)
+	return args.Error(0)
+}
+
+// MockAdjustmentRepository is a mock implementation of repository.AdjustmentRepository.
+type MockAdjustmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdjustmentRepository) CreateAdjustment(ctx context.Context, q repository.DBExecutor, adjustment *domain.Adjustment) error {
+	args := m.Called(ctx, q, adjustment)
+	return args.Error(0)
+}
+
+func (m *MockAdjustmentRepository) GetAdjustmentByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Adjustment, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Adjustment), args.Error(1)
+}
+
+func (m *MockAdjustmentRepository) GetAdjustmentForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.Adjustment, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Adjustment), args.Error(1)
+}
+
+func (m *MockAdjustmentRepository) ListAdjustments(ctx context.Context, q repository.DBExecutor, status domain.AdjustmentStatus, limit int) ([]domain.Adjustment, error) {
+	args := m.Called(ctx, q, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Adjustment), args.Error(1)
+}
+
+func (m *MockAdjustmentRepository) UpdateDecision(ctx context.Context, q repository.DBExecutor, adjustment *domain.Adjustment) error {
+	args := m.Called(ctx, q, adjustment)
+	return args.Error(0)
+}
+
+// MockOwnershipTransferRepository is a mock implementation of repository.OwnershipTransferRepository.
+type MockOwnershipTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockOwnershipTransferRepository) CreateOwnershipTransfer(ctx context.Context, q repository.DBExecutor, transfer *domain.OwnershipTransfer) error {
+	args := m.Called(ctx, q, transfer)
+	return args.Error(0)
+}
+
+func (m *MockOwnershipTransferRepository) GetOwnershipTransfer(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OwnershipTransfer), args.Error(1)
+}
+
+func (m *MockOwnershipTransferRepository) GetOwnershipTransferForUpdate(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.OwnershipTransfer, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OwnershipTransfer), args.Error(1)
+}
+
+func (m *MockOwnershipTransferRepository) ListWalletOwnershipTransfers(ctx context.Context, q repository.DBExecutor, walletID int64, limit int) ([]domain.OwnershipTransfer, error) {
+	args := m.Called(ctx, q, walletID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.OwnershipTransfer), args.Error(1)
+}
+
+func (m *MockOwnershipTransferRepository) ListIncomingOwnershipTransfers(ctx context.Context, q repository.DBExecutor, toUserID int64, status domain.OwnershipTransferStatus, limit int) ([]domain.OwnershipTransfer, error) {
+	args := m.Called(ctx, q, toUserID, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.OwnershipTransfer), args.Error(1)
+}
+
+func (m *MockOwnershipTransferRepository) ListExpiredOwnershipTransfers(ctx context.Context, q repository.DBExecutor, before time.Time, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, q, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockOwnershipTransferRepository) ResolveOwnershipTransfer(ctx context.Context, q repository.DBExecutor, transfer *domain.OwnershipTransfer) error {
+	args := m.Called(ctx, q, transfer)
+	return args.Error(0)
+}
+
+// MockScreeningRepository is a mock implementation of repository.ScreeningRepository.
+type MockScreeningRepository struct {
+	mock.Mock
+}
+
+func (m *MockScreeningRepository) CreateDenylistEntry(ctx context.Context, q repository.DBExecutor, entry *domain.DenylistEntry) error {
+	args := m.Called(ctx, q, entry)
+	return args.Error(0)
+}
+
+func (m *MockScreeningRepository) ListDenylistEntries(ctx context.Context, q repository.DBExecutor) ([]domain.DenylistEntry, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.DenylistEntry), args.Error(1)
+}
+
+func (m *MockScreeningRepository) FindDenylistEntry(ctx context.Context, q repository.DBExecutor, normalizedName string) (*domain.DenylistEntry, error) {
+	args := m.Called(ctx, q, normalizedName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DenylistEntry), args.Error(1)
+}
+
+func (m *MockScreeningRepository) DeleteDenylistEntry(ctx context.Context, q repository.DBExecutor, id int64) error {
+	args := m.Called(ctx, q, id)
+	return args.Error(0)
+}
+
+func (m *MockScreeningRepository) CreateScreeningCase(ctx context.Context, q repository.DBExecutor, screeningCase *domain.ScreeningCase) error {
+	args := m.Called(ctx, q, screeningCase)
+	return args.Error(0)
+}
+
+func (m *MockScreeningRepository) FindLatestScreeningCase(ctx context.Context, q repository.DBExecutor, normalizedName string, entryID int64) (*domain.ScreeningCase, error) {
+	args := m.Called(ctx, q, normalizedName, entryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScreeningCase), args.Error(1)
+}
+
+func (m *MockScreeningRepository) GetScreeningCaseByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.ScreeningCase, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScreeningCase), args.Error(1)
+}
+
+func (m *MockScreeningRepository) ListScreeningCases(ctx context.Context, q repository.DBExecutor, status domain.ScreeningCaseStatus, limit int) ([]domain.ScreeningCase, error) {
+	args := m.Called(ctx, q, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ScreeningCase), args.Error(1)
+}
+
+func (m *MockScreeningRepository) ResolveScreeningCase(ctx context.Context, q repository.DBExecutor, id int64, status domain.ScreeningCaseStatus, resolvedBy string, note *string, at time.Time) error {
+	args := m.Called(ctx, q, id, status, resolvedBy, note, at)
+	return args.Error(0)
+}
+
+// MockCurrencyRestrictionRepository is a mock implementation of repository.CurrencyRestrictionRepository.
+type MockBalanceShardRepository struct {
+	mock.Mock
+}
+
+func (m *MockBalanceShardRepository) ListShards(ctx context.Context, q repository.DBExecutor) ([]domain.BalanceShard, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.BalanceShard), args.Error(1)
+}
+
+func (m *MockBalanceShardRepository) UpdateShardBalance(ctx context.Context, q repository.DBExecutor, walletID int64, start, shards int, amount decimal.Decimal, at time.Time) (*domain.WalletBalance, error) {
+	args := m.Called(ctx, q, walletID, start, shards, amount, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WalletBalance), args.Error(1)
+}
+
+func (m *MockBalanceShardRepository) LockShards(ctx context.Context, q repository.DBExecutor, walletID int64) (decimal.Decimal, []domain.BalanceShard, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(1) == nil {
+		return args.Get(0).(decimal.Decimal), nil, args.Error(2)
+	}
+	return args.Get(0).(decimal.Decimal), args.Get(1).([]domain.BalanceShard), args.Error(2)
+}
+
+func (m *MockBalanceShardRepository) ResizeShards(ctx context.Context, q repository.DBExecutor, walletID int64, shards int) error {
+	args := m.Called(ctx, q, walletID, shards)
+	return args.Error(0)
+}
+
+func (m *MockBalanceShardRepository) SetShardBalances(ctx context.Context, q repository.DBExecutor, walletID int64, own decimal.Decimal, shards []decimal.Decimal, lastActivity time.Time) error {
+	args := m.Called(ctx, q, walletID, own, shards, lastActivity)
+	return args.Error(0)
+}
+
+type MockWalletSerializationRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletSerializationRepository) ListSerializations(ctx context.Context, q repository.DBExecutor) ([]domain.WalletSerialization, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WalletSerialization), args.Error(1)
+}
+
+func (m *MockWalletSerializationRepository) SaveSerialization(ctx context.Context, q repository.DBExecutor, serialization *domain.WalletSerialization) error {
+	args := m.Called(ctx, q, serialization)
+	return args.Error(0)
+}
+
+func (m *MockWalletSerializationRepository) DeleteSerialization(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	args := m.Called(ctx, q, walletID)
+	return args.Error(0)
+}
+
+func (m *MockWalletSerializationRepository) LockWallet(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	args := m.Called(ctx, q, walletID)
+	return args.Error(0)
+}
+
+type MockAsyncTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockAsyncTransferRepository) CreateAsyncTransfer(ctx context.Context, q repository.DBExecutor, transfer *domain.AsyncTransfer) error {
+	args := m.Called(ctx, q, transfer)
+	return args.Error(0)
+}
+
+func (m *MockAsyncTransferRepository) ListPendingAsyncTransfers(ctx context.Context, q repository.DBExecutor, now time.Time, limit int) ([]domain.AsyncTransfer, error) {
+	args := m.Called(ctx, q, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AsyncTransfer), args.Error(1)
+}
+
+func (m *MockAsyncTransferRepository) CompleteAsyncTransfer(ctx context.Context, q repository.DBExecutor, transaction *domain.Transaction) error {
+	args := m.Called(ctx, q, transaction)
+	return args.Error(0)
+}
+
+func (m *MockAsyncTransferRepository) RetryAsyncTransfer(ctx context.Context, q repository.DBExecutor, transactionID int64, nextAttemptAt, at time.Time) error {
+	args := m.Called(ctx, q, transactionID, nextAttemptAt, at)
+	return args.Error(0)
+}
+
+func (m *MockAsyncTransferRepository) FailAsyncTransfer(ctx context.Context, q repository.DBExecutor, transactionID int64, reason string, at time.Time) error {
+	args := m.Called(ctx, q, transactionID, reason, at)
+	return args.Error(0)
+}
+
+type MockSchemaRepository struct {
+	mock.Mock
+}
+
+func (m *MockSchemaRepository) GetSchemaVersion(ctx context.Context, q repository.DBExecutor) (uint64, bool, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).(uint64), args.Bool(1), args.Error(2)
+}
+
+func (m *MockSchemaRepository) GetColumnScale(ctx context.Context, q repository.DBExecutor, table, column string) (int32, error) {
+	args := m.Called(ctx, q, table, column)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockSchemaRepository) ListBackfillProgress(ctx context.Context, q repository.DBExecutor) ([]domain.BackfillProgress, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.BackfillProgress), args.Error(1)
+}
+
+func (m *MockSchemaRepository) RunBackfillBatch(ctx context.Context, q repository.DBExecutor, query string, after int64, limit int) ([]int64, error) {
+	args := m.Called(ctx, q, query, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockSchemaRepository) SaveBackfillProgress(ctx context.Context, q repository.DBExecutor, progress *domain.BackfillProgress) error {
+	args := m.Called(ctx, q, progress)
+	return args.Error(0)
+}
+
+type MockRetentionRepository struct {
+	mock.Mock
+}
+
+func (m *MockRetentionRepository) PurgeRecords(ctx context.Context, q repository.DBExecutor, recordType domain.RetentionRecordType, cutoff time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, q, recordType, cutoff, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRetentionRepository) CountPurgeable(ctx context.Context, q repository.DBExecutor, recordType domain.RetentionRecordType, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, q, recordType, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockIntegrityRepository struct {
+	mock.Mock
+}
+
+func (m *MockIntegrityRepository) ListBalanceMismatches(ctx context.Context, q repository.DBExecutor, limit int) ([]domain.BalanceMismatch, int64, error) {
+	args := m.Called(ctx, q, limit)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]domain.BalanceMismatch), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockIntegrityRepository) ListLedgerBalances(ctx context.Context, q repository.DBExecutor, walletIDs []int64) ([]domain.LedgerBalance, error) {
+	args := m.Called(ctx, q, walletIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.LedgerBalance), args.Error(1)
+}
+
+func (m *MockIntegrityRepository) ListCurrencyImbalances(ctx context.Context, q repository.DBExecutor) ([]domain.CurrencyImbalance, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CurrencyImbalance), args.Error(1)
+}
+
+func (m *MockIntegrityRepository) CountOrphans(ctx context.Context, q repository.DBExecutor) ([]domain.OrphanCount, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.OrphanCount), args.Error(1)
+}
+
+type MockInvestigationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvestigationRepository) ListChildTransactions(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID) ([]domain.Transaction, error) {
+	args := m.Called(ctx, q, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockInvestigationRepository) ListTransactionReferences(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID) ([]domain.TransactionReference, error) {
+	args := m.Called(ctx, q, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TransactionReference), args.Error(1)
+}
+
+func (m *MockInvestigationRepository) ListTransactionNotifications(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID, userIDs []int64) ([]domain.Notification, error) {
+	args := m.Called(ctx, q, transactionID, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Notification), args.Error(1)
+}
+
+func (m *MockInvestigationRepository) ListTransactionDeliveries(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID, userIDs []int64) ([]domain.NotificationDelivery, error) {
+	args := m.Called(ctx, q, transactionID, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockInvestigationRepository) ListTransactionAudit(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID, userIDs []int64) ([]domain.TransactionAuditEntry, error) {
+	args := m.Called(ctx, q, transactionID, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TransactionAuditEntry), args.Error(1)
+}
+
+type MockCurrencyRestrictionRepository struct {
+	mock.Mock
+}
+
+func (m *MockCurrencyRestrictionRepository) ListRestrictions(ctx context.Context, q repository.DBExecutor) ([]domain.CurrencyRestriction, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CurrencyRestriction), args.Error(1)
+}
+
+func (m *MockCurrencyRestrictionRepository) GetRestriction(ctx context.Context, q repository.DBExecutor, currency string) (*domain.CurrencyRestriction, error) {
+	args := m.Called(ctx, q, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CurrencyRestriction), args.Error(1)
+}
+
+func (m *MockCurrencyRestrictionRepository) SaveRestriction(ctx context.Context, q repository.DBExecutor, restriction *domain.CurrencyRestriction) error {
+	args := m.Called(ctx, q, restriction)
+	return args.Error(0)
+}
+
+func (m *MockCurrencyRestrictionRepository) DeleteRestriction(ctx context.Context, q repository.DBExecutor, currency string) error {
+	args := m.Called(ctx, q, currency)
+	return args.Error(0)
+}
+
+func (m *MockCurrencyRestrictionRepository) IsRestricted(ctx context.Context, q repository.DBExecutor, currency, country string) (bool, error) {
+	args := m.Called(ctx, q, currency, country)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCurrencyRestrictionRepository) IsRestrictedForUser(ctx context.Context, q repository.DBExecutor, currency string, userID int64) (bool, error) {
+	args := m.Called(ctx, q, currency, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockAuthEventRepository is a mock implementation of repository.AuthEventRepository.
+type MockAuthEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuthEventRepository) CreateAuthEvent(ctx context.Context, q repository.DBExecutor, event *domain.AuthEvent) error {
+	args := m.Called(ctx, q, event)
+	return args.Error(0)
+}
+
+func (m *MockAuthEventRepository) ListAuthEvents(ctx context.Context, q repository.DBExecutor, userID int64, limit int) ([]domain.AuthEvent, error) {
+	args := m.Called(ctx, q, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.AuthEvent), args.Error(1)
+}
+
+func (m *MockAuthEventRepository) KnownDevice(ctx context.Context, q repository.DBExecutor, userID int64, deviceHash string) (bool, bool, error) {
+	args := m.Called(ctx, q, userID, deviceHash)
+	return args.Bool(0), args.Bool(1), args.Error(2)
+}
+
+// MockPINRepository is a mock implementation of repository.PINRepository.
+type MockPINRepository struct {
+	mock.Mock
+}
+
+func (m *MockPINRepository) GetPIN(ctx context.Context, q repository.DBExecutor, userID int64) (*domain.UserPIN, error) {
+	args := m.Called(ctx, q, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserPIN), args.Error(1)
+}
+
+func (m *MockPINRepository) GetPINForUpdate(ctx context.Context, q repository.DBExecutor, userID int64) (*domain.UserPIN, error) {
+	args := m.Called(ctx, q, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserPIN), args.Error(1)
+}
+
+func (m *MockPINRepository) SavePIN(ctx context.Context, q repository.DBExecutor, pin *domain.UserPIN) error {
+	args := m.Called(ctx, q, pin)
+	return args.Error(0)
+}
+
+func (m *MockPINRepository) UpdateAttempts(ctx context.Context, q repository.DBExecutor, pin *domain.UserPIN) error {
+	args := m.Called(ctx, q, pin)
+	return args.Error(0)
+}
+
+func (m *MockPINRepository) DeletePIN(ctx context.Context, q repository.DBExecutor, userID int64) error {
+	args := m.Called(ctx, q, userID)
+	return args.Error(0)
+}
+
+// MockContactVerificationRepository is a mock implementation of repository.ContactVerificationRepository.
+type MockContactVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockContactVerificationRepository) GetVerification(ctx context.Context, q repository.DBExecutor, userID int64, channel domain.ContactChannel) (*domain.ContactVerification, error) {
+	args := m.Called(ctx, q, userID, channel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ContactVerification), args.Error(1)
+}
+
+func (m *MockContactVerificationRepository) GetVerificationForUpdate(ctx context.Context, q repository.DBExecutor, userID int64, channel domain.ContactChannel) (*domain.ContactVerification, error) {
+	args := m.Called(ctx, q, userID, channel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ContactVerification), args.Error(1)
+}
+
+func (m *MockContactVerificationRepository) SaveVerification(ctx context.Context, q repository.DBExecutor, verification *domain.ContactVerification) error {
+	args := m.Called(ctx, q, verification)
+	return args.Error(0)
+}
+
+func (m *MockContactVerificationRepository) UpdateAttempts(ctx context.Context, q repository.DBExecutor, verification *domain.ContactVerification) error {
+	args := m.Called(ctx, q, verification)
+	return args.Error(0)
+}
+
+func (m *MockContactVerificationRepository) DeleteVerification(ctx context.Context, q repository.DBExecutor, userID int64, channel domain.ContactChannel) error {
+	args := m.Called(ctx, q, userID, channel)
+	return args.Error(0)
+}
+
+// MockNotificationRepository is a mock implementation of repository.NotificationRepository.
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) CreateNotification(ctx context.Context, q repository.DBExecutor, notification *domain.Notification) error {
+	args := m.Called(ctx, q, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ListNotifications(ctx context.Context, q repository.DBExecutor, userID int64, unreadOnly bool, limit, offset int) ([]domain.Notification, int64, error) {
+	args := m.Called(ctx, q, userID, unreadOnly, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]domain.Notification), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockNotificationRepository) CountUnreadNotifications(ctx context.Context, q repository.DBExecutor, userID int64) (int64, error) {
+	args := m.Called(ctx, q, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkNotificationRead(ctx context.Context, q repository.DBExecutor, userID, notificationID int64, at time.Time) (*domain.Notification, error) {
+	args := m.Called(ctx, q, userID, notificationID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkAllNotificationsRead(ctx context.Context, q repository.DBExecutor, userID int64, at time.Time) (int64, error) {
+	args := m.Called(ctx, q, userID, at)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) RegisterDevice(ctx context.Context, q repository.DBExecutor, device *domain.DeviceToken) error {
+	args := m.Called(ctx, q, device)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ListDevices(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.DeviceToken, error) {
+	args := m.Called(ctx, q, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.DeviceToken), args.Error(1)
+}
+
+func (m *MockNotificationRepository) DeleteDevice(ctx context.Context, q repository.DBExecutor, userID, deviceID int64) error {
+	args := m.Called(ctx, q, userID, deviceID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ListPreferences(ctx context.Context, q repository.DBExecutor, userID int64) ([]domain.NotificationPreference, error) {
+	args := m.Called(ctx, q, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationRepository) SavePreference(ctx context.Context, q repository.DBExecutor, preference *domain.NotificationPreference) error {
+	args := m.Called(ctx, q, preference)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) CreateDelivery(ctx context.Context, q repository.DBExecutor, delivery *domain.NotificationDelivery) error {
+	args := m.Called(ctx, q, delivery)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ClaimPendingDeliveries(ctx context.Context, q repository.DBExecutor, limit int) ([]domain.NotificationDelivery, error) {
+	args := m.Called(ctx, q, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationRepository) UpdateDelivery(ctx context.Context, q repository.DBExecutor, delivery *domain.NotificationDelivery) error {
+	args := m.Called(ctx, q, delivery)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ListDeliveries(ctx context.Context, q repository.DBExecutor, userID int64, limit int) ([]domain.NotificationDelivery, error) {
+	args := m.Called(ctx, q, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.NotificationDelivery), args.Error(1)
+}
+
+// MockTransferQuoteRepository is a mock implementation of repository.TransferQuoteRepository.
+type MockTransferQuoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferQuoteRepository) CreateQuote(ctx context.Context, q repository.DBExecutor, quote *domain.TransferQuote) error {
+	args := m.Called(ctx, q, quote)
+	return args.Error(0)
+}
+
+func (m *MockTransferQuoteRepository) ClaimQuote(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID, now time.Time) (*domain.TransferQuote, error) {
+	args := m.Called(ctx, q, publicID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransferQuote), args.Error(1)
+}
+
+// MockPromoCreditRepository is a mock implementation of repository.PromoCreditRepository.
+type MockPromoCreditRepository struct {
+	mock.Mock
+}
+
+func (m *MockPromoCreditRepository) CreateCredit(ctx context.Context, q repository.DBExecutor, credit *domain.PromoCredit) error {
+	args := m.Called(ctx, q, credit)
+	return args.Error(0)
+}
+
+func (m *MockPromoCreditRepository) ListCreditsByWallet(ctx context.Context, q repository.DBExecutor, walletID int64) ([]domain.PromoCredit, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.PromoCredit), args.Error(1)
+}
+
+func (m *MockPromoCreditRepository) ListSpendableCreditsForUpdate(ctx context.Context, q repository.DBExecutor, walletID int64, now time.Time, forWithdrawal bool) ([]domain.PromoCredit, error) {
+	args := m.Called(ctx, q, walletID, now, forWithdrawal)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.PromoCredit), args.Error(1)
+}
+
+func (m *MockPromoCreditRepository) ConsumeCredit(ctx context.Context, q repository.DBExecutor, creditID int64, amount decimal.Decimal) error {
+	args := m.Called(ctx, q, creditID, amount)
+	return args.Error(0)
+}
+
+func (m *MockPromoCreditRepository) ExpireCredits(ctx context.Context, q repository.DBExecutor, now time.Time) ([]domain.PromoCredit, error) {
+	args := m.Called(ctx, q, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.PromoCredit), args.Error(1)
+}
+
+// MockExportRepository is a mock implementation of repository.ExportRepository.
+type MockExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportRepository) GetWatermark(ctx context.Context, q repository.DBExecutor, stream string) (*domain.ExportWatermark, error) {
+	args := m.Called(ctx, q, stream)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ExportWatermark), args.Error(1)
+}
+
+func (m *MockExportRepository) SaveWatermark(ctx context.Context, q repository.DBExecutor, watermark *domain.ExportWatermark) error {
+	args := m.Called(ctx, q, *watermark)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) ListTransactionsAfter(ctx context.Context, q repository.DBExecutor, afterID int64, before time.Time, limit int) ([]domain.Transaction, error) {
+	args := m.Called(ctx, q, afterID, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockExportRepository) ListTransactionsInRange(ctx context.Context, q repository.DBExecutor, from, to time.Time, afterID int64, limit int) ([]domain.Transaction, error) {
+	args := m.Called(ctx, q, from, to, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockExportRepository) ListWalletsAfter(ctx context.Context, q repository.DBExecutor, afterID int64, limit int) ([]domain.Wallet, error) {
+	args := m.Called(ctx, q, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Wallet), args.Error(1)
+}
+
+// MockObjectStore is a mock implementation of ObjectStore. The body is passed to the mock as a string.
+type MockObjectStore struct {
+	mock.Mock
+}
+
+func (m *MockObjectStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	args := m.Called(ctx, key, string(data), contentType)
+	return args.Error(0)
+}
+
+func (m *MockObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return io.NopCloser(strings.NewReader(args.String(0))), args.Error(1)
+}
+
+// MockChangeRepository is a mock implementation of repository.ChangeRepository.
+type MockChangeRepository struct {
+	mock.Mock
+}
+
+func (m *MockChangeRepository) ListWalletChanges(ctx context.Context, q repository.DBExecutor, since int64, filter repository.ChangeFilter, limit int) ([]domain.WalletChange, error) {
+	args := m.Called(ctx, q, since, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WalletChange), args.Error(1)
+}
+
+func (m *MockChangeRepository) ListTransactionChanges(ctx context.Context, q repository.DBExecutor, since int64, filter repository.ChangeFilter, limit int) ([]domain.TransactionChange, error) {
+	args := m.Called(ctx, q, since, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TransactionChange), args.Error(1)
+}
+
+// MockImpersonationRepository is a mock implementation of repository.ImpersonationRepository.
+type MockImpersonationRepository struct {
+	mock.Mock
+}
+
+func (m *MockImpersonationRepository) CreateSession(ctx context.Context, q repository.DBExecutor, session *domain.ImpersonationSession) error {
+	args := m.Called(ctx, q, session)
+	if args.Error(0) == nil {
+		session.ID = 1
+	}
+	return args.Error(0)
+}
+
+func (m *MockImpersonationRepository) GetSessionByTokenHash(ctx context.Context, q repository.DBExecutor, tokenHash string) (*domain.ImpersonationSession, error) {
+	args := m.Called(ctx, q, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ImpersonationSession), args.Error(1)
+}
+
+func (m *MockImpersonationRepository) GetSessionByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.ImpersonationSession, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ImpersonationSession), args.Error(1)
+}
+
+func (m *MockImpersonationRepository) ClaimTransfer(ctx context.Context, q repository.DBExecutor, sessionID int64, now time.Time) error {
+	args := m.Called(ctx, q, sessionID, now)
+	return args.Error(0)
+}
+
+func (m *MockImpersonationRepository) RevokeSession(ctx context.Context, q repository.DBExecutor, sessionID int64, now time.Time) error {
+	args := m.Called(ctx, q, sessionID, now)
+	return args.Error(0)
+}
+
+func (m *MockImpersonationRepository) CreateAuditEntry(ctx context.Context, q repository.DBExecutor, entry *domain.ImpersonationAuditEntry) error {
+	args := m.Called(ctx, q, entry)
+	return args.Error(0)
+}
+
+func (m *MockImpersonationRepository) ListAuditEntries(ctx context.Context, q repository.DBExecutor, sessionID int64) ([]domain.ImpersonationAuditEntry, error) {
+	args := m.Called(ctx, q, sessionID)
+	return args.Get(0).([]domain.ImpersonationAuditEntry), args.Error(1)
+}
+
+// MockAnnotationRepository is a mock implementation of repository.AnnotationRepository.
+type MockAnnotationRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnnotationRepository) GetAnnotation(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID) (*domain.TransactionAnnotation, error) {
+	args := m.Called(ctx, q, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransactionAnnotation), args.Error(1)
+}
+
+func (m *MockAnnotationRepository) ApplyAnnotation(ctx context.Context, q repository.DBExecutor, transactionID uuid.UUID, patch domain.TransactionAnnotationPatch, now time.Time) (*domain.TransactionAnnotation, error) {
+	args := m.Called(ctx, q, transactionID, patch, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TransactionAnnotation), args.Error(1)
+}
+
+// MockWalletExportRepository is a mock implementation of repository.WalletExportRepository.
+type MockWalletExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletExportRepository) CreateExport(ctx context.Context, q repository.DBExecutor, export *domain.WalletExport) error {
+	args := m.Called(ctx, q, export)
+	return args.Error(0)
+}
+
+func (m *MockWalletExportRepository) GetExportByPublicID(ctx context.Context, q repository.DBExecutor, publicID uuid.UUID) (*domain.WalletExport, error) {
+	args := m.Called(ctx, q, publicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WalletExport), args.Error(1)
+}
+
+func (m *MockWalletExportRepository) ClaimNextExport(ctx context.Context, q repository.DBExecutor, now time.Time) (*domain.WalletExport, error) {
+	args := m.Called(ctx, q, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WalletExport), args.Error(1)
+}
+
+func (m *MockWalletExportRepository) RequeueStaleExports(ctx context.Context, q repository.DBExecutor, startedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, q, startedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWalletExportRepository) UpdateProgress(ctx context.Context, q repository.DBExecutor, exportID int64, processed, total int64) error {
+	args := m.Called(ctx, q, exportID, processed, total)
+	return args.Error(0)
+}
+
+func (m *MockWalletExportRepository) CompleteExport(ctx context.Context, q repository.DBExecutor, exportID int64, objectKey string, auditHead *domain.AuditChainHead, now time.Time) error {
+	args := m.Called(ctx, q, exportID, objectKey, auditHead, now)
+	return args.Error(0)
+}
+
+func (m *MockWalletExportRepository) FailExport(ctx context.Context, q repository.DBExecutor, exportID int64, message string, now time.Time) error {
+	args := m.Called(ctx, q, exportID, message, now)
+	return args.Error(0)
+}
+
+func (m *MockWalletExportRepository) CountWalletTransactions(ctx context.Context, q repository.DBExecutor, walletID int64) (int64, error) {
+	args := m.Called(ctx, q, walletID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWalletExportRepository) ListWalletTransactionsAfter(ctx context.Context, q repository.DBExecutor, walletID, afterID int64, limit int) ([]domain.Transaction, error) {
+	args := m.Called(ctx, q, walletID, afterID, limit)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+// MockAuditRepository is a mock implementation of repository.AuditRepository.
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) LockChain(ctx context.Context, q repository.DBExecutor, walletID int64) error {
+	args := m.Called(ctx, q, walletID)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) GetChainHead(ctx context.Context, q repository.DBExecutor, walletID int64) (*domain.AuditChainHead, error) {
+	args := m.Called(ctx, q, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditChainHead), args.Error(1)
+}
+
+func (m *MockAuditRepository) AppendEvent(ctx context.Context, q repository.DBExecutor, event *domain.AuditEvent) error {
+	args := m.Called(ctx, q, event)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) ListEventsAfter(ctx context.Context, q repository.DBExecutor, walletID, afterSequence int64, limit int) ([]domain.AuditEvent, error) {
+	args := m.Called(ctx, q, walletID, afterSequence, limit)
+	return args.Get(0).([]domain.AuditEvent), args.Error(1)
+}
+
+// MockUsageRepository is a mock implementation of repository.UsageRepository.
+type MockUsageRepository struct {
+	mock.Mock
+}
+
+func (m *MockUsageRepository) IncrementRequests(ctx context.Context, q repository.DBExecutor, partnerID string, month time.Time) (int64, error) {
+	args := m.Called(ctx, q, partnerID, month)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUsageRepository) AddVolume(ctx context.Context, q repository.DBExecutor, partnerID string, month time.Time, currency string, amount decimal.Decimal) error {
+	args := m.Called(ctx, q, partnerID, month, currency, amount)
+	return args.Error(0)
+}
+
+func (m *MockUsageRepository) GetUsage(ctx context.Context, q repository.DBExecutor, partnerID string, month time.Time) (*domain.PartnerUsage, error) {
+	args := m.Called(ctx, q, partnerID, month)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PartnerUsage), args.Error(1)
+}
+
+// MockFeatureFlags is a mock implementation of FeatureFlags.
+type MockFeatureFlags struct {
+	mock.Mock
+}
+
+func (m *MockFeatureFlags) Evaluate(ctx context.Context, key string, userID int64) domain.FlagDecision {
+	args := m.Called(ctx, key, userID)
+	return args.Get(0).(domain.FlagDecision)
+}
+
+func (m *MockFeatureFlags) EvaluateFor(ctx context.Context, key string, subject domain.FlagSubject) domain.FlagDecision {
+	args := m.Called(ctx, key, subject)
+	return args.Get(0).(domain.FlagDecision)
+}
+
+// MockDBBeginner is a mock implementation of db.DBTxBeginner.
+type MockDBBeginner struct {
+	mock.Mock
+}
+
+func (m *MockDBBeginner) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	args := m.Called(ctx, opts)
+	return &sqlx.Tx{}, args.Error(1)
+}
+
+// MockTxController is a mock implementation of db.TxController.
+// It also implicitly implements repository.DBExecutor for testing purposes
+// by embedding MockDBExecutor.
+type MockTxController struct {
+	mock.Mock
+	MockDBExecutor // Embed MockDBExecutor to satisfy repository.DBExecutor interface
+}
+
+func (m *MockTxController) Commit() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockTxController) Rollback() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// TestDeposit tests the Deposit method of WalletService.
+func TestDeposit(t *testing.T) {
+	walletID := int64(1)
+	amount := decimal.NewFromFloat(100.00)
+	currency := "USD"
+
+	// Test Case 1: Successful Deposit
+	t.Run("SuccessfulDeposit", func(t *testing.T) {
+		// Create mocks and service instance INSIDE the t.Run block
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBBeginner := new(MockDBBeginner)
+		mockDBExecutor := new(MockDBExecutor)
+		mockTxController := new(MockTxController)
+
+		service := NewWalletService(
+			mockDBBeginner,
+			mockDBExecutor,
+			mockUserRepo,
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+		)
+
+		initialWallet := &domain.Wallet{
+			ID:       walletID,
+			UserID:   1,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(500.00),
+		}
+		expectedNewBalance := initialWallet.Balance.Add(amount)
+		updatedBalance := &domain.WalletBalance{ID: walletID, Balance: expectedNewBalance, Version: 2}
+
+		// Set expectations for this specific test case
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe() // Rollback might be called if Commit fails or defer runs after Commit.
+
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController for transactional calls
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount, mock.Anything).Return(updatedBalance, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+
+		resWallet, resTx, err := service.Deposit(ctx, walletID, amount, currency)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resWallet)
+		assert.NotNil(t, resTx)
+		assert.Equal(t, expectedNewBalance, resWallet.Balance)
+		assert.Equal(t, int64(2), resWallet.Version) // Taken from the update, not re-read
+		assert.Equal(t, domain.TransactionTypeDeposit, resTx.Type)
+		assert.Equal(t, amount, resTx.Amount)
+
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
+
+	// Test Case 2: Invalid Amount
+	t.Run("InvalidAmount", func(t *testing.T) {
+		// Create mocks and service instance INSIDE the t.Run block
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBBeginner := new(MockDBBeginner)
+		mockDBExecutor := new(MockDBExecutor)
+		mockTxController := new(MockTxController)
+
+		service := NewWalletService(
+			mockDBBeginner,
+			mockDBExecutor,
+			mockUserRepo,
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+		)
+
+		invalidAmount := decimal.NewFromFloat(-10.00)
+		resWallet, resTx, err := service.Deposit(ctx, walletID, invalidAmount, currency)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		assert.Nil(t, resWallet)
+		assert.Nil(t, resTx)
+
+		// Ensure no transaction was begun (because it's an early return due to invalid input)
+		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
+		mockTxController.AssertNotCalled(t, "Commit")
+		mockTxController.AssertNotCalled(t, "Rollback")
+
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
+
+	// Test Case 3: Wallet Not Found
+	t.Run("WalletNotFound", func(t *testing.T) {
+		// Create mocks and service instance INSIDE the t.Run block
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBBeginner := new(MockDBBeginner)
+		mockDBExecutor := new(MockDBExecutor)
+		mockTxController := new(MockTxController)
+
+		service := NewWalletService(
+			mockDBBeginner,
+			mockDBExecutor,
+			mockUserRepo,
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil // Simulates successful beginTx
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+		)
+
+		// Set expectations for this specific test case
+		// A transaction begins, then GetWalletByID fails, so Rollback is called. Commit is NOT called.
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(nil, util.ErrNotFound).Once() // Use mockTxController
+		mockTxController.On("Rollback").Return(nil).Once()                                                       // Expect rollback to return nil
+
+		resWallet, resTx, err := service.Deposit(ctx, walletID, amount, currency)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		assert.Nil(t, resWallet)
+		assert.Nil(t, resTx)
+
+		mockTxController.AssertNotCalled(t, "Commit") // Ensure Commit was not called
+
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
+
+	// Test Case 4: Currency Mismatch
+	t.Run("CurrencyMismatch", func(t *testing.T) {
+		// Create mocks and service instance INSIDE the t.Run block
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBBeginner := new(MockDBBeginner)
+		mockDBExecutor := new(MockDBExecutor)
+		mockTxController := new(MockTxController)
+
+		service := NewWalletService(
+			mockDBBeginner,
+			mockDBExecutor,
+			mockUserRepo,
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil // Simulates successful beginTx
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+		)
+
+		initialWallet := &domain.Wallet{
+			ID:       walletID,
+			UserID:   1,
+			Currency: "EUR", // Mismatch
+			Balance:  decimal.NewFromFloat(500.00),
+		}
+
+		// Set expectations for this specific test case
+		// A transaction begins, then currency mismatch occurs, so Rollback is called. Commit is NOT called.
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
+		mockTxController.On("Rollback").Return(nil).Once()                                                    // Expect rollback to return nil
+
+		resWallet, resTx, err := service.Deposit(ctx, walletID, amount, currency)
+
+		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
+		assert.Nil(t, resWallet)
+		assert.Nil(t, resTx)
+
+		mockTxController.AssertNotCalled(t, "Commit") // Ensure Commit was not called
+
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
+
+	// Test Case 5: Update Balance Error
+	t.Run("UpdateBalanceError", func(t *testing.T) {
+		// Create mocks and service instance INSIDE the t.Run block
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBBeginner := new(MockDBBeginner)
+		mockDBExecutor := new(MockDBExecutor)
+		mockTxController := new(MockTxController)
+
+		service := NewWalletService(
+			mockDBBeginner,
+			mockDBExecutor,
+			mockUserRepo,
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil // Simulates successful beginTx
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+		)
+
+		initialWallet := &domain.Wallet{
+			ID:       walletID,
+			UserID:   1,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(500.00),
+		}
+
+		// Set expectations for this specific test case
+		// A transaction begins, then UpdateWalletBalance fails, so Rollback is called. Commit is NOT called.
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount, mock.Anything).Return(nil, errors.New("db error")).Once()
+		mockTxController.On("Rollback").Return(nil).Once() // Expect rollback to return nil
+
+		resWallet, resTx, err := service.Deposit(ctx, walletID, amount, currency)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to update wallet balance")
+		assert.Nil(t, resWallet)
+		assert.Nil(t, resTx)
+
+		mockTxController.AssertNotCalled(t, "Commit") // Ensure Commit was not called
+
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
+}
+
+// TestWithdraw tests the Withdraw method of WalletService.
+func TestWithdraw(t *testing.T) {
+	walletID := int64(1)
+	amount := decimal.NewFromFloat(50.00)
+	currency := "USD"
+
+	// Test Case 1: Successful Withdrawal
+	t.Run("SuccessfulWithdrawal", func(t *testing.T) {
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBBeginner := new(MockDBBeginner)
+		mockDBExecutor := new(MockDBExecutor)
+		mockTxController := new(MockTxController)
+
+		service := NewWalletService(
+			mockDBBeginner,
+			mockDBExecutor,
+			mockUserRepo,
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+		)
+
+		initialWallet := &domain.Wallet{
+			ID:       walletID,
+			UserID:   1,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(500.00),
+		}
+		expectedNewBalance := initialWallet.Balance.Sub(amount)
+		updatedBalance := &domain.WalletBalance{ID: walletID, Balance: expectedNewBalance, Version: 2}
+
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
+
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount.Neg(), mock.Anything).Return(updatedBalance, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+
+		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resWallet)
+		assert.NotNil(t, resTx)
+		assert.Equal(t, expectedNewBalance, resWallet.Balance)
+		assert.Equal(t, domain.TransactionTypeWithdrawal, resTx.Type)
+		assert.Equal(t, amount, resTx.Amount)
+
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
+
+	// Test Case 2: Invalid Amount
+	t.Run("InvalidAmount", func(t *testing.T) {
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBBeginner := new(MockDBBeginner)
+		mockDBExecutor := new(MockDBExecutor)
+		mockTxController := new(MockTxController)
+
+		service := NewWalletService(
+			mockDBBeginner,
+			mockDBExecutor,
+			mockUserRepo,
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+		)
+
+		invalidAmount := decimal.NewFromFloat(-10.00)
+		resWallet, resTx, err := service.Withdraw(ctx, walletID, invalidAmount, currency)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		assert.Nil(t, resWallet)
+		assert.Nil(t, resTx)
+
+		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
+		mockTxController.AssertNotCalled(t, "Commit")
+		mockTxController.AssertNotCalled(t, "Rollback")
+
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
+
+	// Test Case 3: Wallet Not Found
+	t.Run("WalletNotFound", func(t *testing.T) {
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBBeginner := new(MockDBBeginner)
+		mockDBExecutor := new(MockDBExecutor)
+		mockTxController := new(MockTxController)
+
+		service := NewWalletService(
+			mockDBBeginner,
+			mockDBExecutor,
+			mockUserRepo,
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+		)
 
-func (m *MockDBBeginner) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
-	args := m.Called(ctx, opts)
-	return &sqlx.Tx{}, args.Error(1)
-}
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(nil, util.ErrNotFound).Once() // Use mockTxController
+		mockTxController.On("Rollback").Return(nil).Once()
 
-// MockTxController is a mock implementation of db.TxController.
-// It also implicitly implements repository.DBExecutor for testing purposes
-// by embedding MockDBExecutor.
-type MockTxController struct {
-	mock.Mock
-	MockDBExecutor // Embed MockDBExecutor to satisfy repository.DBExecutor interface
-}
+		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
 
-func (m *MockTxController) Commit() error {
-	args := m.Called()
-	return args.Error(0)
-}
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		assert.Nil(t, resWallet)
+		assert.Nil(t, resTx)
 
-func (m *MockTxController) Rollback() error {
-	args := m.Called()
-	return args.Error(0)
-}
+		mockTxController.AssertNotCalled(t, "Commit")
 
-// TestDeposit tests the Deposit method of WalletService.
-func TestDeposit(t *testing.T) {
-	walletID := int64(1)
-	amount := decimal.NewFromFloat(100.00)
-	currency := "USD"
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
 
-	// Test Case 1: Successful Deposit
-	t.Run("SuccessfulDeposit", func(t *testing.T) {
-		// Create mocks and service instance INSIDE the t.Run block
+	// Test Case 4: Currency Mismatch
+	t.Run("CurrencyMismatch", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -181,41 +2625,26 @@ func TestDeposit(t *testing.T) {
 		initialWallet := &domain.Wallet{
 			ID:       walletID,
 			UserID:   1,
-			Currency: currency,
+			Currency: "EUR", // Mismatch
 			Balance:  decimal.NewFromFloat(500.00),
 		}
-		expectedNewBalance := initialWallet.Balance.Add(amount)
-		updatedWallet := &domain.Wallet{
-			ID:       walletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  expectedNewBalance,
-		}
 
-		// Set expectations for this specific test case
-		mockTxController.On("Commit").Return(nil).Once()
-		mockTxController.On("Rollback").Return(nil).Maybe() // Rollback might be called if Commit fails or defer runs after Commit.
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
+		mockTxController.On("Rollback").Return(nil).Once()
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController for transactional calls
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount).Return(nil).Once()
-		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(updatedWallet, nil).Once() // Re-fetch updated wallet
+		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
 
-		resWallet, resTx, err := service.Deposit(ctx, walletID, amount, currency)
+		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
+		assert.Nil(t, resWallet)
+		assert.Nil(t, resTx)
 
-		assert.NoError(t, err)
-		assert.NotNil(t, resWallet)
-		assert.NotNil(t, resTx)
-		assert.Equal(t, expectedNewBalance, resWallet.Balance)
-		assert.Equal(t, domain.TransactionTypeDeposit, resTx.Type)
-		assert.Equal(t, amount, resTx.Amount)
+		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 2: Invalid Amount
-	t.Run("InvalidAmount", func(t *testing.T) {
-		// Create mocks and service instance INSIDE the t.Run block
+	// Test Case 5: Insufficient Funds
+	t.Run("InsufficientFunds", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -241,24 +2670,31 @@ func TestDeposit(t *testing.T) {
 			},
 		)
 
-		invalidAmount := decimal.NewFromFloat(-10.00)
-		resWallet, resTx, err := service.Deposit(ctx, walletID, invalidAmount, currency)
+		initialWallet := &domain.Wallet{
+			ID:       walletID,
+			UserID:   1,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(20.00), // Less than amount
+		}
 
-		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
+		mockTxController.On("Rollback").Return(nil).Once()
+
+		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
+
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
 		assert.Nil(t, resWallet)
 		assert.Nil(t, resTx)
 
-		// Ensure no transaction was begun (because it's an early return due to invalid input)
-		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
+		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything)
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
 		mockTxController.AssertNotCalled(t, "Commit")
-		mockTxController.AssertNotCalled(t, "Rollback")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 3: Wallet Not Found
-	t.Run("WalletNotFound", func(t *testing.T) {
-		// Create mocks and service instance INSIDE the t.Run block
+	// Test Case 6: Update Balance Error
+	t.Run("UpdateBalanceError", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -274,7 +2710,7 @@ func TestDeposit(t *testing.T) {
 			mockWalletRepo,
 			mockTransactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
-				return mockTxController, nil // Simulates successful beginTx
+				return mockTxController, nil
 			},
 			func(tx db.TxController) error {
 				return mockTxController.Commit()
@@ -284,25 +2720,32 @@ func TestDeposit(t *testing.T) {
 			},
 		)
 
-		// Set expectations for this specific test case
-		// A transaction begins, then GetWalletByID fails, so Rollback is called. Commit is NOT called.
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(nil, util.ErrNotFound).Once() // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()                                                       // Expect rollback to return nil
+		initialWallet := &domain.Wallet{
+			ID:       walletID,
+			UserID:   1,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(500.00),
+		}
 
-		resWallet, resTx, err := service.Deposit(ctx, walletID, amount, currency)
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount.Neg(), mock.Anything).Return(nil, errors.New("db error")).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
 
-		assert.ErrorIs(t, err, util.ErrNotFound)
+		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to update wallet balance")
 		assert.Nil(t, resWallet)
 		assert.Nil(t, resTx)
 
-		mockTxController.AssertNotCalled(t, "Commit") // Ensure Commit was not called
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
+		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 4: Currency Mismatch
-	t.Run("CurrencyMismatch", func(t *testing.T) {
-		// Create mocks and service instance INSIDE the t.Run block
+	// Test Case 7: Create Transaction Error
+	t.Run("CreateTransactionError", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -318,7 +2761,7 @@ func TestDeposit(t *testing.T) {
 			mockWalletRepo,
 			mockTransactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
-				return mockTxController, nil // Simulates successful beginTx
+				return mockTxController, nil
 			},
 			func(tx db.TxController) error {
 				return mockTxController.Commit()
@@ -331,29 +2774,37 @@ func TestDeposit(t *testing.T) {
 		initialWallet := &domain.Wallet{
 			ID:       walletID,
 			UserID:   1,
-			Currency: "EUR", // Mismatch
+			Currency: currency,
 			Balance:  decimal.NewFromFloat(500.00),
 		}
 
-		// Set expectations for this specific test case
-		// A transaction begins, then currency mismatch occurs, so Rollback is called. Commit is NOT called.
 		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()                                                    // Expect rollback to return nil
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount.Neg(), mock.Anything).Return(nil, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(errors.New("db error")).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
 
-		resWallet, resTx, err := service.Deposit(ctx, walletID, amount, currency)
+		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
 
-		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create transaction")
 		assert.Nil(t, resWallet)
 		assert.Nil(t, resTx)
 
-		mockTxController.AssertNotCalled(t, "Commit") // Ensure Commit was not called
+		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
+}
 
-	// Test Case 5: Update Balance Error
-	t.Run("UpdateBalanceError", func(t *testing.T) {
-		// Create mocks and service instance INSIDE the t.Run block
+// TestTransfer tests the Transfer method of WalletService.
+func TestTransfer(t *testing.T) {
+	fromWalletID := int64(1)
+	toWalletID := int64(2)
+	amount := decimal.NewFromFloat(50.00)
+	currency := "USD"
+
+	// Test Case 1: Successful Transfer
+	t.Run("SuccessfulTransfer", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -369,7 +2820,7 @@ func TestDeposit(t *testing.T) {
 			mockWalletRepo,
 			mockTransactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
-				return mockTxController, nil // Simulates successful beginTx
+				return mockTxController, nil
 			},
 			func(tx db.TxController) error {
 				return mockTxController.Commit()
@@ -379,40 +2830,99 @@ func TestDeposit(t *testing.T) {
 			},
 		)
 
-		initialWallet := &domain.Wallet{
-			ID:       walletID,
+		initialFromWallet := &domain.Wallet{
+			ID:       fromWalletID,
 			UserID:   1,
 			Currency: currency,
 			Balance:  decimal.NewFromFloat(500.00),
 		}
+		initialToWallet := &domain.Wallet{
+			ID:       toWalletID,
+			UserID:   2,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(100.00),
+		}
+		expectedFromBalance := initialFromWallet.Balance.Sub(amount)
+		expectedToBalance := initialToWallet.Balance.Add(amount)
+		updatedFromWallet := &domain.Wallet{
+			ID:       fromWalletID,
+			UserID:   1,
+			Currency: currency,
+			Balance:  expectedFromBalance,
+		}
+		updatedToWallet := &domain.Wallet{
+			ID:       toWalletID,
+			UserID:   2,
+			Currency: currency,
+			Balance:  expectedToBalance,
+		}
 
-		// Set expectations for this specific test case
-		// A transaction begins, then UpdateWalletBalance fails, so Rollback is called. Commit is NOT called.
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount).Return(errors.New("db error")).Once()
-		mockTxController.On("Rollback").Return(nil).Once() // Expect rollback to return nil
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
 
-		resWallet, resTx, err := service.Deposit(ctx, walletID, amount, currency)
+		// One read and one update for both wallets; the update returns the updated wallets
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{fromWalletID, toWalletID}).Return([]domain.Wallet{*initialFromWallet, *initialToWallet}, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalances", ctx, mockTxController, map[int64]decimal.Decimal{fromWalletID: amount.Neg(), toWalletID: amount}, mock.Anything).Return([]domain.Wallet{*updatedFromWallet, *updatedToWallet}, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to update wallet balance")
-		assert.Nil(t, resWallet)
+		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resFromWallet)
+		assert.NotNil(t, resToWallet)
+		assert.NotNil(t, resTx)
+		assert.Equal(t, expectedFromBalance, resFromWallet.Balance)
+		assert.Equal(t, expectedToBalance, resToWallet.Balance)
+		assert.Equal(t, domain.TransactionTypeTransfer, resTx.Type)
+		assert.Equal(t, amount, resTx.Amount)
+
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
+
+	// Test Case 2: Invalid Amount
+	t.Run("InvalidAmount", func(t *testing.T) {
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBBeginner := new(MockDBBeginner)
+		mockDBExecutor := new(MockDBExecutor)
+		mockTxController := new(MockTxController)
+
+		service := NewWalletService(
+			mockDBBeginner,
+			mockDBExecutor,
+			mockUserRepo,
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+		)
+
+		invalidAmount := decimal.NewFromFloat(-10.00)
+		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, invalidAmount, currency)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		assert.Nil(t, resFromWallet)
+		assert.Nil(t, resToWallet)
 		assert.Nil(t, resTx)
 
-		mockTxController.AssertNotCalled(t, "Commit") // Ensure Commit was not called
+		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
+		mockTxController.AssertNotCalled(t, "Commit")
+		mockTxController.AssertNotCalled(t, "Rollback")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
-}
-
-// TestWithdraw tests the Withdraw method of WalletService.
-func TestWithdraw(t *testing.T) {
-	walletID := int64(1)
-	amount := decimal.NewFromFloat(50.00)
-	currency := "USD"
 
-	// Test Case 1: Successful Withdrawal
-	t.Run("SuccessfulWithdrawal", func(t *testing.T) {
+	// Test Case 3: Same Wallet Transfer
+	t.Run("SameWalletTransfer", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -438,42 +2948,22 @@ func TestWithdraw(t *testing.T) {
 			},
 		)
 
-		initialWallet := &domain.Wallet{
-			ID:       walletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(500.00),
-		}
-		expectedNewBalance := initialWallet.Balance.Sub(amount)
-		updatedWallet := &domain.Wallet{
-			ID:       walletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  expectedNewBalance,
-		}
-
-		mockTxController.On("Commit").Return(nil).Once()
-		mockTxController.On("Rollback").Return(nil).Maybe()
-
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount.Neg()).Return(nil).Once()
-		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(updatedWallet, nil).Once()
+		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, fromWalletID, amount, currency) // fromWalletID == toWalletID
 
-		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
+		assert.ErrorIs(t, err, util.ErrSameWalletTransfer)
+		assert.Nil(t, resFromWallet)
+		assert.Nil(t, resToWallet)
+		assert.Nil(t, resTx)
 
-		assert.NoError(t, err)
-		assert.NotNil(t, resWallet)
-		assert.NotNil(t, resTx)
-		assert.Equal(t, expectedNewBalance, resWallet.Balance)
-		assert.Equal(t, domain.TransactionTypeWithdrawal, resTx.Type)
-		assert.Equal(t, amount, resTx.Amount)
+		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
+		mockTxController.AssertNotCalled(t, "Commit")
+		mockTxController.AssertNotCalled(t, "Rollback")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 2: Invalid Amount
-	t.Run("InvalidAmount", func(t *testing.T) {
+	// Test Case 4: From Wallet Not Found
+	t.Run("FromWalletNotFound", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -499,22 +2989,23 @@ func TestWithdraw(t *testing.T) {
 			},
 		)
 
-		invalidAmount := decimal.NewFromFloat(-10.00)
-		resWallet, resTx, err := service.Withdraw(ctx, walletID, invalidAmount, currency)
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{fromWalletID, toWalletID}).Return([]domain.Wallet{}, nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
 
-		assert.ErrorIs(t, err, util.ErrInvalidInput)
-		assert.Nil(t, resWallet)
+		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		assert.Nil(t, resFromWallet)
+		assert.Nil(t, resToWallet)
 		assert.Nil(t, resTx)
 
-		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
-		mockTxController.AssertNotCalled(t, "Rollback")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 3: Wallet Not Found
-	t.Run("WalletNotFound", func(t *testing.T) {
+	// Test Case 5: To Wallet Not Found
+	t.Run("ToWalletNotFound", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -540,13 +3031,21 @@ func TestWithdraw(t *testing.T) {
 			},
 		)
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(nil, util.ErrNotFound).Once() // Use mockTxController
+		initialFromWallet := &domain.Wallet{
+			ID:       fromWalletID,
+			UserID:   1,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(500.00),
+		}
+
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{fromWalletID, toWalletID}).Return([]domain.Wallet{*initialFromWallet}, nil).Once()
 		mockTxController.On("Rollback").Return(nil).Once()
 
-		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
+		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
 
 		assert.ErrorIs(t, err, util.ErrNotFound)
-		assert.Nil(t, resWallet)
+		assert.Nil(t, resFromWallet)
+		assert.Nil(t, resToWallet)
 		assert.Nil(t, resTx)
 
 		mockTxController.AssertNotCalled(t, "Commit")
@@ -554,8 +3053,8 @@ func TestWithdraw(t *testing.T) {
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 4: Currency Mismatch
-	t.Run("CurrencyMismatch", func(t *testing.T) {
+	// Test Case 6: From Wallet Currency Mismatch
+	t.Run("FromWalletCurrencyMismatch", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -581,20 +3080,21 @@ func TestWithdraw(t *testing.T) {
 			},
 		)
 
-		initialWallet := &domain.Wallet{
-			ID:       walletID,
+		initialFromWallet := &domain.Wallet{
+			ID:       fromWalletID,
 			UserID:   1,
 			Currency: "EUR", // Mismatch
 			Balance:  decimal.NewFromFloat(500.00),
 		}
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{fromWalletID, toWalletID}).Return([]domain.Wallet{*initialFromWallet}, nil).Once()
 		mockTxController.On("Rollback").Return(nil).Once()
 
-		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
+		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
 
 		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
-		assert.Nil(t, resWallet)
+		assert.Nil(t, resFromWallet)
+		assert.Nil(t, resToWallet)
 		assert.Nil(t, resTx)
 
 		mockTxController.AssertNotCalled(t, "Commit")
@@ -602,8 +3102,8 @@ func TestWithdraw(t *testing.T) {
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 5: Insufficient Funds
-	t.Run("InsufficientFunds", func(t *testing.T) {
+	// Test Case 7: To Wallet Currency Mismatch
+	t.Run("ToWalletCurrencyMismatch", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -629,31 +3129,36 @@ func TestWithdraw(t *testing.T) {
 			},
 		)
 
-		initialWallet := &domain.Wallet{
-			ID:       walletID,
+		initialFromWallet := &domain.Wallet{
+			ID:       fromWalletID,
 			UserID:   1,
 			Currency: currency,
-			Balance:  decimal.NewFromFloat(20.00), // Less than amount
+			Balance:  decimal.NewFromFloat(500.00),
+		}
+		initialToWallet := &domain.Wallet{
+			ID:       toWalletID,
+			UserID:   2,
+			Currency: "EUR", // Mismatch
+			Balance:  decimal.NewFromFloat(100.00),
 		}
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{fromWalletID, toWalletID}).Return([]domain.Wallet{*initialFromWallet, *initialToWallet}, nil).Once()
 		mockTxController.On("Rollback").Return(nil).Once()
 
-		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
+		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
 
-		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
-		assert.Nil(t, resWallet)
+		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
+		assert.Nil(t, resFromWallet)
+		assert.Nil(t, resToWallet)
 		assert.Nil(t, resTx)
 
-		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalance")
-		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
 		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 6: Update Balance Error
-	t.Run("UpdateBalanceError", func(t *testing.T) {
+	// Test Case 8: Insufficient Funds (From Wallet)
+	t.Run("InsufficientFunds", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -679,32 +3184,38 @@ func TestWithdraw(t *testing.T) {
 			},
 		)
 
-		initialWallet := &domain.Wallet{
-			ID:       walletID,
+		initialFromWallet := &domain.Wallet{
+			ID:       fromWalletID,
 			UserID:   1,
 			Currency: currency,
-			Balance:  decimal.NewFromFloat(500.00),
+			Balance:  decimal.NewFromFloat(20.00), // Less than amount
+		}
+		initialToWallet := &domain.Wallet{
+			ID:       toWalletID,
+			UserID:   2,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(100.00),
 		}
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount.Neg()).Return(errors.New("db error")).Once()
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{fromWalletID, toWalletID}).Return([]domain.Wallet{*initialFromWallet, *initialToWallet}, nil).Once()
 		mockTxController.On("Rollback").Return(nil).Once()
 
-		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
+		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to update wallet balance")
-		assert.Nil(t, resWallet)
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		assert.Nil(t, resFromWallet)
+		assert.Nil(t, resToWallet)
 		assert.Nil(t, resTx)
 
+		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalances", mock.Anything)
 		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
 		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 7: Create Transaction Error
-	t.Run("CreateTransactionError", func(t *testing.T) {
+	// Test Case 9: Update Wallet Balances Error
+	t.Run("UpdateWalletBalancesError", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -730,40 +3241,39 @@ func TestWithdraw(t *testing.T) {
 			},
 		)
 
-		initialWallet := &domain.Wallet{
-			ID:       walletID,
+		initialFromWallet := &domain.Wallet{
+			ID:       fromWalletID,
 			UserID:   1,
 			Currency: currency,
 			Balance:  decimal.NewFromFloat(500.00),
 		}
+		initialToWallet := &domain.Wallet{
+			ID:       toWalletID,
+			UserID:   2,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(100.00),
+		}
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(initialWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount.Neg()).Return(nil).Once()
-		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(errors.New("db error")).Once()
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{fromWalletID, toWalletID}).Return([]domain.Wallet{*initialFromWallet, *initialToWallet}, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalances", ctx, mockTxController, map[int64]decimal.Decimal{fromWalletID: amount.Neg(), toWalletID: amount}, mock.Anything).Return(nil, errors.New("db error")).Once()
 		mockTxController.On("Rollback").Return(nil).Once()
 
-		resWallet, resTx, err := service.Withdraw(ctx, walletID, amount, currency)
+		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to create transaction")
-		assert.Nil(t, resWallet)
+		assert.Contains(t, err.Error(), "failed to update wallet balances")
+		assert.Nil(t, resFromWallet)
+		assert.Nil(t, resToWallet)
 		assert.Nil(t, resTx)
 
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
 		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
-}
-
-// TestTransfer tests the Transfer method of WalletService.
-func TestTransfer(t *testing.T) {
-	fromWalletID := int64(1)
-	toWalletID := int64(2)
-	amount := decimal.NewFromFloat(50.00)
-	currency := "USD"
 
-	// Test Case 1: Successful Transfer
-	t.Run("SuccessfulTransfer", func(t *testing.T) {
+	// Test Case 10: Create Transaction Error
+	t.Run("CreateTransactionError", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -801,60 +3311,44 @@ func TestTransfer(t *testing.T) {
 			Currency: currency,
 			Balance:  decimal.NewFromFloat(100.00),
 		}
-		expectedFromBalance := initialFromWallet.Balance.Sub(amount)
-		expectedToBalance := initialToWallet.Balance.Add(amount)
-		updatedFromWallet := &domain.Wallet{
-			ID:       fromWalletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  expectedFromBalance,
-		}
-		updatedToWallet := &domain.Wallet{
-			ID:       toWalletID,
-			UserID:   2,
-			Currency: currency,
-			Balance:  expectedToBalance,
-		}
-
-		mockTxController.On("Commit").Return(nil).Once()
-		mockTxController.On("Rollback").Return(nil).Maybe()
 
-		// First GetWalletByID for fromWallet, then for toWallet
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(initialFromWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, toWalletID).Return(initialToWallet, nil).Once()     // Use mockTxController
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, fromWalletID, amount.Neg()).Return(nil).Once()
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, toWalletID, amount).Return(nil).Once()
-		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(updatedFromWallet, nil).Once() // Re-fetch
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, toWalletID).Return(updatedToWallet, nil).Once()     // Re-fetch
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{fromWalletID, toWalletID}).Return([]domain.Wallet{*initialFromWallet, *initialToWallet}, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalances", ctx, mockTxController, map[int64]decimal.Decimal{fromWalletID: amount.Neg(), toWalletID: amount}, mock.Anything).Return([]domain.Wallet{*initialFromWallet, *initialToWallet}, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(errors.New("db error")).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
 
 		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
 
-		assert.NoError(t, err)
-		assert.NotNil(t, resFromWallet)
-		assert.NotNil(t, resToWallet)
-		assert.NotNil(t, resTx)
-		assert.Equal(t, expectedFromBalance, resFromWallet.Balance)
-		assert.Equal(t, expectedToBalance, resToWallet.Balance)
-		assert.Equal(t, domain.TransactionTypeTransfer, resTx.Type)
-		assert.Equal(t, amount, resTx.Amount)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create transaction")
+		assert.Nil(t, resFromWallet)
+		assert.Nil(t, resToWallet)
+		assert.Nil(t, resTx)
+
+		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
+}
 
-	// Test Case 2: Invalid Amount
-	t.Run("InvalidAmount", func(t *testing.T) {
+// TestGetBalance tests the GetBalance method of WalletService.
+func TestGetBalance(t *testing.T) {
+	walletID := int64(1)
+	currency := "USD"
+
+	// Test Case 1: Successful GetBalance
+	t.Run("SuccessfulGetBalance", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
 		mockTransactionRepo := new(MockTransactionRepository)
 		mockDBBeginner := new(MockDBBeginner)
-		mockDBExecutor := new(MockDBExecutor)
+		mockDBExecutor := new(MockDBExecutor) // This is used for read-only operations
 		mockTxController := new(MockTxController)
 
 		service := NewWalletService(
 			mockDBBeginner,
-			mockDBExecutor,
+			mockDBExecutor, // Pass mockDBExecutor here
 			mockUserRepo,
 			mockWalletRepo,
 			mockTransactionRepo,
@@ -869,14 +3363,23 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		invalidAmount := decimal.NewFromFloat(-10.00)
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, invalidAmount, currency)
+		expectedWallet := &domain.Wallet{
+			ID:       walletID,
+			UserID:   1,
+			Currency: currency,
+			Balance:  decimal.NewFromFloat(750.00),
+		}
 
-		assert.ErrorIs(t, err, util.ErrInvalidInput)
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
+		// GetBalance uses s.dbExecutor directly, not a transaction
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(expectedWallet, nil).Once() // Already correct
+
+		resWallet, err := service.GetBalance(ctx, walletID)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resWallet)
+		assert.Equal(t, expectedWallet, resWallet)
 
+		// Assert that no transaction-related methods were called
 		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
 		mockTxController.AssertNotCalled(t, "Rollback")
@@ -884,8 +3387,8 @@ func TestTransfer(t *testing.T) {
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 3: Same Wallet Transfer
-	t.Run("SameWalletTransfer", func(t *testing.T) {
+	// Test Case 2: Wallet Not Found
+	t.Run("WalletNotFound", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -911,12 +3414,12 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, fromWalletID, amount, currency) // fromWalletID == toWalletID
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(nil, util.ErrNotFound).Once() // Already correct
 
-		assert.ErrorIs(t, err, util.ErrSameWalletTransfer)
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
+		resWallet, err := service.GetBalance(ctx, walletID)
+
+		assert.ErrorIs(t, err, util.ErrNotFound)
+		assert.Nil(t, resWallet)
 
 		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
@@ -925,8 +3428,8 @@ func TestTransfer(t *testing.T) {
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 4: From Wallet Not Found
-	t.Run("FromWalletNotFound", func(t *testing.T) {
+	// Test Case 3: Repository Error
+	t.Run("RepositoryError", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -952,24 +3455,30 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(nil, util.ErrNotFound).Once() // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()
+		testError := errors.New("database connection lost")
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(nil, testError).Once() // Already correct
 
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+		resWallet, err := service.GetBalance(ctx, walletID)
 
-		assert.ErrorIs(t, err, util.ErrNotFound)
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), testError.Error())
+		assert.Nil(t, resWallet)
 
-		mockWalletRepo.AssertNotCalled(t, "GetWalletByID", ctx, mock.Anything, toWalletID) // toWallet not fetched
+		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
+		mockTxController.AssertNotCalled(t, "Rollback")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
+}
 
-	// Test Case 5: To Wallet Not Found
-	t.Run("ToWalletNotFound", func(t *testing.T) {
+// TestCreateUserAndWallet tests the CreateUserAndWallet method of WalletService.
+func TestCreateUserAndWallet(t *testing.T) {
+	username := "testuser"
+	currency := "USD"
+
+	// Test Case 1: Successful CreateUserAndWallet
+	t.Run("SuccessfulCreateUserAndWallet", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -995,31 +3504,47 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		initialFromWallet := &domain.Wallet{
-			ID:       fromWalletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(500.00),
-		}
+		// Expect no user to be found initially
+		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, util.ErrNotFound).Once() // Use mockTxController
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(initialFromWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, toWalletID).Return(nil, util.ErrNotFound).Once()    // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()
+		// Expect user and wallet creation
+		createdUser := &domain.User{ID: 1, Username: username}
+		createdWallet := &domain.Wallet{ID: 101, UserID: createdUser.ID, Currency: currency, Balance: decimal.Zero}
 
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+		// Mock CreateUser and CreateWallet calls
+		mockUserRepo.On("CreateUser", ctx, mockTxController, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) { // Use mockTxController
+			// Simulate setting ID on the passed user object
+			userArg := args.Get(2).(*domain.User)
+			userArg.ID = createdUser.ID
+		}).Return(nil).Once()
 
-		assert.ErrorIs(t, err, util.ErrNotFound)
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
+		mockWalletRepo.On("CreateWallet", ctx, mockTxController, mock.AnythingOfType("*domain.Wallet")).Run(func(args mock.Arguments) { // Use mockTxController
+			// Simulate setting ID on the passed wallet object
+			walletArg := args.Get(2).(*domain.Wallet)
+			walletArg.ID = createdWallet.ID
+		}).Return(nil).Once()
 
-		mockTxController.AssertNotCalled(t, "Commit")
+		// Expect transaction commit
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe() // In case of unexpected rollback
+
+		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency, nil)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resUser)
+		assert.NotNil(t, resWallet)
+		assert.Equal(t, createdUser.ID, resUser.ID)
+		assert.Equal(t, createdUser.Username, resUser.Username)
+		assert.Equal(t, createdWallet.ID, resWallet.ID)
+		assert.Equal(t, createdWallet.UserID, resWallet.UserID)
+		assert.Equal(t, createdWallet.Currency, resWallet.Currency)
+		assert.True(t, createdWallet.Balance.Equal(decimal.Zero))
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 6: From Wallet Currency Mismatch
-	t.Run("FromWalletCurrencyMismatch", func(t *testing.T) {
+	// Test Case 2: User Already Exists
+	t.Run("UserAlreadyExists", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -1045,31 +3570,31 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		initialFromWallet := &domain.Wallet{
-			ID:       fromWalletID,
-			UserID:   1,
-			Currency: "EUR", // Mismatch
-			Balance:  decimal.NewFromFloat(500.00),
-		}
-
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(initialFromWallet, nil).Once() // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()
+		existingUser := &domain.User{ID: 1, Username: username}
+		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(existingUser, nil).Once() // Use mockTxController
+		mockTxController.On("Rollback").Return(nil).Once()                                                     // Expect rollback
 
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency, nil)
 
-		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+		assert.ErrorIs(t, err, util.ErrDuplicateEntry)
+		var duplicate *util.DuplicateEntryError
+		if assert.ErrorAs(t, err, &duplicate) {
+			assert.Equal(t, existingUser.ID, duplicate.ExistingID)
+		}
+		assert.Nil(t, resUser)
+		assert.Nil(t, resWallet)
 
-		mockWalletRepo.AssertNotCalled(t, "GetWalletByID", ctx, mock.Anything, toWalletID)
+		mockUserRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+		mockWalletRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 7: To Wallet Currency Mismatch
-	t.Run("ToWalletCurrencyMismatch", func(t *testing.T) {
+	// Test Case 2b: Concurrent create wins the race past the existence check
+	t.Run("UniqueViolationOnInsert", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -1095,37 +3620,32 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		initialFromWallet := &domain.Wallet{
-			ID:       fromWalletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(500.00),
-		}
-		initialToWallet := &domain.Wallet{
-			ID:       toWalletID,
-			UserID:   2,
-			Currency: "EUR", // Mismatch
-			Balance:  decimal.NewFromFloat(100.00),
-		}
-
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(initialFromWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, toWalletID).Return(initialToWallet, nil).Once()     // Use mockTxController
+		winner := &domain.User{ID: 7, Username: username}
+		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, util.ErrNotFound).Once()
+		mockUserRepo.On("CreateUser", ctx, mockTxController, mock.AnythingOfType("*domain.User")).
+			Return(fmt.Errorf("failed to create user '%s': %w", username, util.ErrDuplicateEntry)).Once()
+		// The winner is looked up outside the aborted transaction
+		mockUserRepo.On("GetUserByUsername", ctx, mockDBExecutor, username).Return(winner, nil).Once()
 		mockTxController.On("Rollback").Return(nil).Once()
 
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency, nil)
 
-		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
+		assert.ErrorIs(t, err, util.ErrDuplicateEntry)
+		var duplicate *util.DuplicateEntryError
+		if assert.ErrorAs(t, err, &duplicate) {
+			assert.Equal(t, winner.ID, duplicate.ExistingID)
+		}
+		assert.Nil(t, resUser)
+		assert.Nil(t, resWallet)
 
+		mockWalletRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 8: Insufficient Funds (From Wallet)
-	t.Run("InsufficientFunds", func(t *testing.T) {
+	// Test Case 3: Error Checking Existing User (not ErrNotFound)
+	t.Run("ErrorCheckingExistingUser", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -1151,39 +3671,26 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		initialFromWallet := &domain.Wallet{
-			ID:       fromWalletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(20.00), // Less than amount
-		}
-		initialToWallet := &domain.Wallet{
-			ID:       toWalletID,
-			UserID:   2,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(100.00),
-		}
-
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(initialFromWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, toWalletID).Return(initialToWallet, nil).Once()     // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()
+		testError := errors.New("db connection failed")
+		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, testError).Once() // Use mockTxController
+		mockTxController.On("Rollback").Return(nil).Once()                                                  // Expect rollback
 
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency, nil)
 
-		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to check existing user")
+		assert.Nil(t, resUser)
+		assert.Nil(t, resWallet)
 
-		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalance")
-		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
+		mockUserRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+		mockWalletRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 9: Update From Wallet Balance Error
-	t.Run("UpdateFromWalletBalanceError", func(t *testing.T) {
+	// Test Case 4: Create User Error
+	t.Run("CreateUserError", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -1209,41 +3716,26 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		initialFromWallet := &domain.Wallet{
-			ID:       fromWalletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(500.00),
-		}
-		initialToWallet := &domain.Wallet{
-			ID:       toWalletID,
-			UserID:   2,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(100.00),
-		}
-
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(initialFromWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, toWalletID).Return(initialToWallet, nil).Once()     // Use mockTxController
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, fromWalletID, amount.Neg()).Return(errors.New("db error")).Once()
-		mockTxController.On("Rollback").Return(nil).Once()
+		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, util.ErrNotFound).Once() // Use mockTxController
+		testError := errors.New("user repo save error")
+		mockUserRepo.On("CreateUser", ctx, mockTxController, mock.AnythingOfType("*domain.User")).Return(testError).Once() // Use mockTxController
+		mockTxController.On("Rollback").Return(nil).Once()                                                                 // Expect rollback
 
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency, nil)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to update source wallet balance")
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
+		assert.Contains(t, err.Error(), "failed to create user")
+		assert.Nil(t, resUser)
+		assert.Nil(t, resWallet)
 
-		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalance", ctx, mock.Anything, toWalletID, mock.Anything) // To wallet not updated
-		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
+		mockWalletRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 10: Update To Wallet Balance Error
-	t.Run("UpdateToWalletBalanceError", func(t *testing.T) {
+	// Test Case 5: Create Wallet Error
+	t.Run("CreateWalletError", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -1269,41 +3761,29 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		initialFromWallet := &domain.Wallet{
-			ID:       fromWalletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(500.00),
-		}
-		initialToWallet := &domain.Wallet{
-			ID:       toWalletID,
-			UserID:   2,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(100.00),
-		}
-
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(initialFromWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, toWalletID).Return(initialToWallet, nil).Once()     // Use mockTxController
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, fromWalletID, amount.Neg()).Return(nil).Once()
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, toWalletID, amount).Return(errors.New("db error")).Once()
-		mockTxController.On("Rollback").Return(nil).Once()
+		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, util.ErrNotFound).Once()                // Use mockTxController
+		mockUserRepo.On("CreateUser", ctx, mockTxController, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) { // Use mockTxController
+			userArg := args.Get(2).(*domain.User)
+			userArg.ID = 1 // Simulate ID being set
+		}).Return(nil).Once()
+		testError := errors.New("wallet repo save error")
+		mockWalletRepo.On("CreateWallet", ctx, mockTxController, mock.AnythingOfType("*domain.Wallet")).Return(testError).Once() // Use mockTxController
+		mockTxController.On("Rollback").Return(nil).Once()                                                                       // Expect rollback
 
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency, nil)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to update destination wallet balance")
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
+		assert.Contains(t, err.Error(), "failed to create wallet")
+		assert.Nil(t, resUser)
+		assert.Nil(t, resWallet)
 
-		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction")
 		mockTxController.AssertNotCalled(t, "Commit")
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 11: Create Transaction Error
-	t.Run("CreateTransactionError", func(t *testing.T) {
+	// Test Case 6: Commit Error
+	t.Run("CommitError", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -1329,47 +3809,39 @@ func TestTransfer(t *testing.T) {
 			},
 		)
 
-		initialFromWallet := &domain.Wallet{
-			ID:       fromWalletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(500.00),
-		}
-		initialToWallet := &domain.Wallet{
-			ID:       toWalletID,
-			UserID:   2,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(100.00),
-		}
+		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, util.ErrNotFound).Once()                // Use mockTxController
+		mockUserRepo.On("CreateUser", ctx, mockTxController, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) { // Use mockTxController
+			userArg := args.Get(2).(*domain.User)
+			userArg.ID = 1 // Simulate ID being set
+		}).Return(nil).Once()
+		mockWalletRepo.On("CreateWallet", ctx, mockTxController, mock.AnythingOfType("*domain.Wallet")).Run(func(args mock.Arguments) { // Use mockTxController
+			walletArg := args.Get(2).(*domain.Wallet)
+			walletArg.ID = 101 // Simulate ID being set
+		}).Return(nil).Once()
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, fromWalletID).Return(initialFromWallet, nil).Once() // Use mockTxController
-		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, toWalletID).Return(initialToWallet, nil).Once()     // Use mockTxController
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, fromWalletID, amount.Neg()).Return(nil).Once()
-		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, toWalletID, amount).Return(nil).Once()
-		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(errors.New("db error")).Once()
-		mockTxController.On("Rollback").Return(nil).Once()
+		testError := errors.New("commit failed")
+		mockTxController.On("Commit").Return(testError).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe() // Rollback might be called after commit fails
 
-		resFromWallet, resToWallet, resTx, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, currency)
+		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency, nil)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to create transaction")
-		assert.Nil(t, resFromWallet)
-		assert.Nil(t, resToWallet)
-		assert.Nil(t, resTx)
-
-		mockTxController.AssertNotCalled(t, "Commit")
+		assert.Contains(t, err.Error(), "failed to commit transaction")
+		assert.Nil(t, resUser)
+		assert.Nil(t, resWallet)
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 }
 
-// TestGetBalance tests the GetBalance method of WalletService.
-func TestGetBalance(t *testing.T) {
+// TestGetTransactionHistory tests the GetTransactionHistory method of WalletService.
+func TestGetTransactionHistory(t *testing.T) {
 	walletID := int64(1)
-	currency := "USD"
+	limit := 10
+	offset := 0
 
-	// Test Case 1: Successful GetBalance
-	t.Run("SuccessfulGetBalance", func(t *testing.T) {
+	// Test Case 1: Successful GetTransactionHistory with results
+	t.Run("SuccessfulGetTransactionHistoryWithResults", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -1395,23 +3867,40 @@ func TestGetBalance(t *testing.T) {
 			},
 		)
 
-		expectedWallet := &domain.Wallet{
-			ID:       walletID,
-			UserID:   1,
-			Currency: currency,
-			Balance:  decimal.NewFromFloat(750.00),
+		// FIX: Use mockDBExecutor for the second argument
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
+		// Corrected expectedTransactions definition
+		expectedTransactions := []domain.Transaction{
+			{
+				ID:           1,
+				FromWalletID: nil,       // Deposit has no from_wallet_id
+				ToWalletID:   &walletID, // Deposit goes to wallet_id
+				Type:         domain.TransactionTypeDeposit,
+				Amount:       decimal.NewFromFloat(100),
+				Currency:     "USD", // Assuming currency is "USD" for these transactions
+			},
+			{
+				ID:           2,
+				FromWalletID: &walletID, // Withdrawal comes from wallet_id
+				ToWalletID:   nil,       // Withdrawal has no to_wallet_id
+				Type:         domain.TransactionTypeWithdrawal,
+				Amount:       decimal.NewFromFloat(50),
+				Currency:     "USD", // Assuming currency is "USD" for these transactions
+			},
 		}
+		expectedTotalCount := int64(len(expectedTransactions)) // FIX: Define expectedTotalCount
 
-		// GetBalance uses s.dbExecutor directly, not a transaction
-		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(expectedWallet, nil).Once() // Already correct
+		// GetTransactionHistory uses s.dbExecutor directly, not a transaction
+		// FIX: Add expectedTotalCount as the second return value
+		mockTransactionRepo.On("GetTransactionsByWalletID", ctx, mockDBExecutor, walletID, limit, offset).Return(expectedTransactions, expectedTotalCount, nil).Once()
 
-		resWallet, err := service.GetBalance(ctx, walletID)
+		resTransactions, totalCount, err := service.GetTransactionHistory(ctx, walletID, limit, offset)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, resWallet)
-		assert.Equal(t, expectedWallet, resWallet)
+		assert.NotNil(t, resTransactions)
+		assert.Equal(t, expectedTransactions, resTransactions)
+		assert.Equal(t, expectedTotalCount, totalCount) // FIX: Assert totalCount
 
-		// Assert that no transaction-related methods were called
 		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
 		mockTxController.AssertNotCalled(t, "Rollback")
@@ -1419,8 +3908,8 @@ func TestGetBalance(t *testing.T) {
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 2: Wallet Not Found
-	t.Run("WalletNotFound", func(t *testing.T) {
+	// Test Case 2: Successful GetTransactionHistory with no results
+	t.Run("SuccessfulGetTransactionHistoryNoResults", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -1446,12 +3935,20 @@ func TestGetBalance(t *testing.T) {
 			},
 		)
 
-		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(nil, util.ErrNotFound).Once() // Already correct
+		// FIX: Use mockDBExecutor for the second argument
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
+		expectedTransactions := []domain.Transaction{} // Empty slice
+		expectedTotalCount := int64(0)                 // FIX: Define expectedTotalCount
 
-		resWallet, err := service.GetBalance(ctx, walletID)
+		// FIX: Add expectedTotalCount as the second return value
+		mockTransactionRepo.On("GetTransactionsByWalletID", ctx, mockDBExecutor, walletID, limit, offset).Return(expectedTransactions, expectedTotalCount, nil).Once()
 
-		assert.ErrorIs(t, err, util.ErrNotFound)
-		assert.Nil(t, resWallet)
+		resTransactions, totalCount, err := service.GetTransactionHistory(ctx, walletID, limit, offset)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, resTransactions)
+		assert.Empty(t, resTransactions)
+		assert.Equal(t, expectedTotalCount, totalCount) // FIX: Assert totalCount
 
 		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
@@ -1487,14 +3984,18 @@ func TestGetBalance(t *testing.T) {
 			},
 		)
 
-		testError := errors.New("database connection lost")
-		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(nil, testError).Once() // Already correct
+		testError := errors.New("network error")
+		// FIX: Explicitly return a nil slice of the correct type AND an int64 for totalCount
+		mockTransactionRepo.On("GetTransactionsByWalletID", ctx, mockDBExecutor, walletID, limit, offset).Return([]domain.Transaction(nil), int64(0), testError).Once()
+		// FIX: Use mockDBExecutor for the second argument
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
 
-		resWallet, err := service.GetBalance(ctx, walletID)
+		resTransactions, totalCount, err := service.GetTransactionHistory(ctx, walletID, limit, offset)
 
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), testError.Error())
-		assert.Nil(t, resWallet)
+		assert.Nil(t, resTransactions)        // resTransactions should be nil here if the repo returns nil slice and error
+		assert.Equal(t, int64(0), totalCount) // FIX: Assert totalCount is 0 on error
 
 		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
@@ -1502,15 +4003,9 @@ func TestGetBalance(t *testing.T) {
 
 		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
 	})
-}
-
-// TestCreateUserAndWallet tests the CreateUserAndWallet method of WalletService.
-func TestCreateUserAndWallet(t *testing.T) {
-	username := "testuser"
-	currency := "USD"
 
-	// Test Case 1: Successful CreateUserAndWallet
-	t.Run("SuccessfulCreateUserAndWallet", func(t *testing.T) {
+	// Test Case 4: Invalid Limit/Offset (should use defaults)
+	t.Run("InvalidLimitOffset", func(t *testing.T) {
 		ctx := context.Background()
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
@@ -1536,59 +4031,182 @@ func TestCreateUserAndWallet(t *testing.T) {
 			},
 		)
 
-		// Expect no user to be found initially
-		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, util.ErrNotFound).Once() // Use mockTxController
+		// Corrected expectedTransactions definition
+		expectedTransactions := []domain.Transaction{
+			{
+				ID:           1,
+				FromWalletID: nil,
+				ToWalletID:   &walletID,
+				Type:         domain.TransactionTypeDeposit,
+				Amount:       decimal.NewFromFloat(100),
+				Currency:     "USD",
+			},
+		}
+		expectedTotalCount := int64(len(expectedTransactions))
 
-		// Expect user and wallet creation
-		createdUser := &domain.User{ID: 1, Username: username}
-		createdWallet := &domain.Wallet{ID: 101, UserID: createdUser.ID, Currency: currency, Balance: decimal.Zero}
+		// FIX: Change expected limit and offset to -5 and -10 respectively
+		mockTransactionRepo.On("GetTransactionsByWalletID", ctx, mockDBExecutor, walletID, -5, -10).Return(expectedTransactions, expectedTotalCount, nil).Once()
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
 
-		// Mock CreateUser and CreateWallet calls
-		mockUserRepo.On("CreateUser", ctx, mockTxController, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) { // Use mockTxController
-			// Simulate setting ID on the passed user object
-			userArg := args.Get(2).(*domain.User)
-			userArg.ID = createdUser.ID
-		}).Return(nil).Once()
+		resTransactions, totalCount, err := service.GetTransactionHistory(ctx, walletID, -5, -10) // Invalid limit/offset
 
-		mockWalletRepo.On("CreateWallet", ctx, mockTxController, mock.AnythingOfType("*domain.Wallet")).Run(func(args mock.Arguments) { // Use mockTxController
-			// Simulate setting ID on the passed wallet object
-			walletArg := args.Get(2).(*domain.Wallet)
-			walletArg.ID = createdWallet.ID
-		}).Return(nil).Once()
+		assert.NoError(t, err)
+		assert.NotNil(t, resTransactions)
+		assert.Equal(t, expectedTransactions, resTransactions)
+		assert.Equal(t, expectedTotalCount, totalCount)
 
-		// Expect transaction commit
-		mockTxController.On("Commit").Return(nil).Once()
-		mockTxController.On("Rollback").Return(nil).Maybe() // In case of unexpected rollback
+		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
+		mockTxController.AssertNotCalled(t, "Commit")
+		mockTxController.AssertNotCalled(t, "Rollback")
+
+		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	})
+}
+
+// TestListTransactionHistory tests that the archive is only searched for ranges older than the horizon.
+func TestListTransactionHistory(t *testing.T) {
+	walletID := int64(1)
+	opts := repository.ListOptions{Limit: 10, Offset: 0}
+	horizonMonths := 12
+
+	newService := func(mockDBExecutor *MockDBExecutor, mockWalletRepo *MockWalletRepository, mockTransactionRepo *MockTransactionRepository) WalletService {
+		mockTxController := new(MockTxController)
+		return NewWalletService(
+			new(MockDBBeginner),
+			mockDBExecutor,
+			new(MockUserRepository),
+			mockWalletRepo,
+			mockTransactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+				return mockTxController, nil
+			},
+			func(tx db.TxController) error {
+				return mockTxController.Commit()
+			},
+			func(tx db.TxController) {
+				_ = mockTxController.Rollback()
+			},
+			WithArchiveHorizon(horizonMonths),
+		)
+	}
+
+	t.Run("NoRangeSkipsArchive", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := newService(mockDBExecutor, mockWalletRepo, mockTransactionRepo)
 
-		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency)
+		expectedTransactions := []domain.Transaction{{ID: 1, ToWalletID: &walletID, Type: domain.TransactionTypeDeposit}}
+
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Currency: "USD"}, nil).Once()
+		mockTransactionRepo.On("ListTransactionsByWalletID", ctx, mockDBExecutor, walletID, repository.TransactionFilter{}, opts).Return(expectedTransactions, int64(1), nil).Once()
+
+		resTransactions, totalCount, err := service.ListTransactionHistory(ctx, walletID, repository.TransactionFilter{}, opts)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, resUser)
-		assert.NotNil(t, resWallet)
-		assert.Equal(t, createdUser.ID, resUser.ID)
-		assert.Equal(t, createdUser.Username, resUser.Username)
-		assert.Equal(t, createdWallet.ID, resWallet.ID)
-		assert.Equal(t, createdWallet.UserID, resWallet.UserID)
-		assert.Equal(t, createdWallet.Currency, resWallet.Currency)
-		assert.True(t, createdWallet.Balance.Equal(decimal.Zero))
+		assert.Equal(t, expectedTransactions, resTransactions)
+		assert.Equal(t, int64(1), totalCount)
+		mock.AssertExpectationsForObjects(t, mockDBExecutor, mockWalletRepo, mockTransactionRepo)
+	})
 
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+	t.Run("RecentRangeSkipsArchive", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := newService(mockDBExecutor, mockWalletRepo, mockTransactionRepo)
+
+		to := time.Now().UTC()
+		from := to.AddDate(0, -1, 0)
+		filter := repository.TransactionFilter{From: &from, To: &to}
+
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Currency: "USD"}, nil).Once()
+		mockTransactionRepo.On("ListTransactionsByWalletID", ctx, mockDBExecutor, walletID, filter, opts).Return([]domain.Transaction{}, int64(0), nil).Once()
+
+		_, _, err := service.ListTransactionHistory(ctx, walletID, filter, opts)
+
+		assert.NoError(t, err)
+		mock.AssertExpectationsForObjects(t, mockDBExecutor, mockWalletRepo, mockTransactionRepo)
 	})
 
-	// Test Case 2: User Already Exists
-	t.Run("UserAlreadyExists", func(t *testing.T) {
+	t.Run("OldRangeIncludesArchive", func(t *testing.T) {
 		ctx := context.Background()
-		mockUserRepo := new(MockUserRepository)
+		mockDBExecutor := new(MockDBExecutor)
 		mockWalletRepo := new(MockWalletRepository)
 		mockTransactionRepo := new(MockTransactionRepository)
-		mockDBBeginner := new(MockDBBeginner)
+		service := newService(mockDBExecutor, mockWalletRepo, mockTransactionRepo)
+
+		to := time.Now().UTC()
+		from := to.AddDate(-2, 0, 0)
+		expectedFilter := repository.TransactionFilter{From: &from, To: &to, IncludeArchive: true}
+
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Currency: "USD"}, nil).Once()
+		mockTransactionRepo.On("ListTransactionsByWalletID", ctx, mockDBExecutor, walletID, expectedFilter, opts).Return([]domain.Transaction{}, int64(0), nil).Once()
+
+		_, _, err := service.ListTransactionHistory(ctx, walletID, repository.TransactionFilter{From: &from, To: &to}, opts)
+
+		assert.NoError(t, err)
+		mock.AssertExpectationsForObjects(t, mockDBExecutor, mockWalletRepo, mockTransactionRepo)
+	})
+
+	t.Run("SearchWithoutRangeIncludesArchive", func(t *testing.T) {
+		ctx := context.Background()
 		mockDBExecutor := new(MockDBExecutor)
-		mockTxController := new(MockTxController)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := newService(mockDBExecutor, mockWalletRepo, mockTransactionRepo)
 
-		service := NewWalletService(
-			mockDBBeginner,
-			mockDBExecutor,
-			mockUserRepo,
+		category := "rent"
+		filter := repository.TransactionFilter{Query: "march", Category: &category}
+		expectedFilter := filter
+		expectedFilter.IncludeArchive = true
+
+		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Currency: "USD"}, nil).Once()
+		mockTransactionRepo.On("ListTransactionsByWalletID", ctx, mockDBExecutor, walletID, expectedFilter, opts).Return([]domain.Transaction{}, int64(0), nil).Once()
+
+		_, _, err := service.ListTransactionHistory(ctx, walletID, filter, opts)
+
+		assert.NoError(t, err)
+		mock.AssertExpectationsForObjects(t, mockDBExecutor, mockWalletRepo, mockTransactionRepo)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		ctx := context.Background()
+		mockDBExecutor := new(MockDBExecutor)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		service := newService(mockDBExecutor, mockWalletRepo, mockTransactionRepo)
+
+		from := time.Now().UTC()
+		to := from.AddDate(0, -1, 0)
+		_, _, err := service.ListTransactionHistory(ctx, walletID, repository.TransactionFilter{From: &from, To: &to}, opts)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		mockWalletRepo.AssertNotCalled(t, "GetWalletByID", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestDepositIfMatchPrecondition tests that Deposit honours an If-Match precondition carried in the context.
+func TestDepositIfMatchPrecondition(t *testing.T) {
+	walletID := int64(1)
+	amount := decimal.NewFromFloat(100.00)
+	currency := "USD"
+
+	lockedWallet := &domain.Wallet{
+		ID:        walletID,
+		UserID:    1,
+		Currency:  currency,
+		Balance:   decimal.NewFromFloat(500.00),
+		Version:   3,
+		UpdatedAt: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	newService := func(mockWalletRepo *MockWalletRepository, mockTransactionRepo *MockTransactionRepository, mockTxController *MockTxController) WalletService {
+		return NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
 			mockWalletRepo,
 			mockTransactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
@@ -1601,38 +4219,70 @@ func TestCreateUserAndWallet(t *testing.T) {
 				_ = mockTxController.Rollback()
 			},
 		)
+	}
 
-		existingUser := &domain.User{ID: 1, Username: username}
-		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(existingUser, nil).Once() // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()                                                     // Expect rollback
+	t.Run("StaleETagRejected", func(t *testing.T) {
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
+		ctx := WithIfMatch(context.Background(), []string{`"stale"`})
 
-		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency)
+		mockWalletRepo.On("GetWalletByIDForUpdate", ctx, mockTxController, walletID).Return(lockedWallet, nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "already exists")
-		assert.Nil(t, resUser)
-		assert.Nil(t, resWallet)
+		resWallet, resTx, err := service.Deposit(ctx, walletID, amount, currency)
 
-		mockUserRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
-		mockWalletRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
+		assert.ErrorIs(t, err, util.ErrPreconditionFailed)
+		assert.Nil(t, resWallet)
+		assert.Nil(t, resTx)
+		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 		mockTxController.AssertNotCalled(t, "Commit")
-
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController)
 	})
 
-	// Test Case 3: Error Checking Existing User (not ErrNotFound)
-	t.Run("ErrorCheckingExistingUser", func(t *testing.T) {
-		ctx := context.Background()
-		mockUserRepo := new(MockUserRepository)
+	t.Run("CurrentETagAccepted", func(t *testing.T) {
 		mockWalletRepo := new(MockWalletRepository)
 		mockTransactionRepo := new(MockTransactionRepository)
-		mockDBBeginner := new(MockDBBeginner)
-		mockDBExecutor := new(MockDBExecutor)
 		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
+		ctx := WithIfMatch(context.Background(), []string{lockedWallet.ETag()})
+
+		updatedBalance := &domain.WalletBalance{
+			ID:        walletID,
+			Balance:   lockedWallet.Balance.Add(amount),
+			Version:   lockedWallet.Version + 1,
+			UpdatedAt: lockedWallet.UpdatedAt.Add(time.Second),
+		}
+
+		mockWalletRepo.On("GetWalletByIDForUpdate", ctx, mockTxController, walletID).Return(lockedWallet, nil).Once()
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(lockedWallet, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount, mock.Anything).Return(updatedBalance, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
 
-		service := NewWalletService(
-			mockDBBeginner,
-			mockDBExecutor,
+		resWallet, _, err := service.Deposit(ctx, walletID, amount, currency)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, lockedWallet.ETag(), resWallet.ETag())
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController)
+	})
+}
+
+// TestWithdrawOverdraftFlag tests that the overdraft flag lets a balance go negative down to its limit, for
+// owners with verified contact details.
+func TestWithdrawOverdraftFlag(t *testing.T) {
+	walletID := int64(1)
+	currency := "USD"
+	wallet := &domain.Wallet{ID: walletID, UserID: 7, Currency: currency, Balance: decimal.NewFromFloat(20.00)}
+	verifiedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	dbExecutor := new(MockDBExecutor)
+
+	newService := func(mockUserRepo *MockUserRepository, mockWalletRepo *MockWalletRepository, mockTransactionRepo *MockTransactionRepository, mockTxController *MockTxController, flags FeatureFlags) WalletService {
+		return NewWalletService(
+			new(MockDBBeginner),
+			dbExecutor,
 			mockUserRepo,
 			mockWalletRepo,
 			mockTransactionRepo,
@@ -1645,85 +4295,104 @@ func TestCreateUserAndWallet(t *testing.T) {
 			func(tx db.TxController) {
 				_ = mockTxController.Rollback()
 			},
+			WithFeatureFlags(flags),
 		)
+	}
 
-		testError := errors.New("db connection failed")
-		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, testError).Once() // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()                                                  // Expect rollback
+	t.Run("WithinLimitAllowed", func(t *testing.T) {
+		ctx := context.Background()
+		amount := decimal.NewFromFloat(100.00)
+		mockUserRepo := new(MockUserRepository)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		mockFlags := new(MockFeatureFlags)
+		service := newService(mockUserRepo, mockWalletRepo, mockTransactionRepo, mockTxController, mockFlags)
 
-		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency)
+		overdrawn := &domain.WalletBalance{ID: walletID, Balance: wallet.Balance.Sub(amount)}
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to check existing user")
-		assert.Nil(t, resUser)
-		assert.Nil(t, resWallet)
+		mockFlags.On("Evaluate", ctx, domain.FlagOverdraft, wallet.UserID).Return(domain.FlagDecision{Key: domain.FlagOverdraft, Enabled: true, Value: "100.00", Reason: "cohort"}).Once()
+		mockUserRepo.On("GetUserByID", ctx, dbExecutor, wallet.UserID).Return(&domain.User{ID: wallet.UserID, PhoneVerifiedAt: &verifiedAt}, nil).Once()
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(wallet, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount.Neg(), mock.Anything).Return(overdrawn, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
 
-		mockUserRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
-		mockWalletRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
-		mockTxController.AssertNotCalled(t, "Commit")
+		resWallet, _, err := service.Withdraw(ctx, walletID, amount, currency)
 
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+		assert.NoError(t, err)
+		assert.True(t, resWallet.Balance.Equal(decimal.NewFromFloat(-80.00)))
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController, mockFlags)
 	})
 
-	// Test Case 4: Create User Error
-	t.Run("CreateUserError", func(t *testing.T) {
+	t.Run("BeyondLimitRejected", func(t *testing.T) {
 		ctx := context.Background()
-		mockUserRepo := new(MockUserRepository)
+		amount := decimal.NewFromFloat(150.00)
 		mockWalletRepo := new(MockWalletRepository)
 		mockTransactionRepo := new(MockTransactionRepository)
-		mockDBBeginner := new(MockDBBeginner)
-		mockDBExecutor := new(MockDBExecutor)
 		mockTxController := new(MockTxController)
+		mockFlags := new(MockFeatureFlags)
+		service := newService(new(MockUserRepository), mockWalletRepo, mockTransactionRepo, mockTxController, mockFlags)
 
-		service := NewWalletService(
-			mockDBBeginner,
-			mockDBExecutor,
-			mockUserRepo,
-			mockWalletRepo,
-			mockTransactionRepo,
-			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
-				return mockTxController, nil
-			},
-			func(tx db.TxController) error {
-				return mockTxController.Commit()
-			},
-			func(tx db.TxController) {
-				_ = mockTxController.Rollback()
-			},
-		)
+		mockFlags.On("Evaluate", ctx, domain.FlagOverdraft, wallet.UserID).Return(domain.FlagDecision{Key: domain.FlagOverdraft, Enabled: true, Value: "100.00", Reason: "cohort"}).Once()
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(wallet, nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
 
-		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, util.ErrNotFound).Once() // Use mockTxController
-		testError := errors.New("user repo save error")
-		mockUserRepo.On("CreateUser", ctx, mockTxController, mock.AnythingOfType("*domain.User")).Return(testError).Once() // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()                                                                 // Expect rollback
+		_, _, err := service.Withdraw(ctx, walletID, amount, currency)
 
-		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency)
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController, mockFlags)
+	})
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to create user")
-		assert.Nil(t, resUser)
-		assert.Nil(t, resWallet)
+	t.Run("FlagOffRejected", func(t *testing.T) {
+		ctx := context.Background()
+		amount := decimal.NewFromFloat(30.00)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		mockFlags := new(MockFeatureFlags)
+		service := newService(new(MockUserRepository), mockWalletRepo, mockTransactionRepo, mockTxController, mockFlags)
 
-		mockWalletRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.Anything)
-		mockTxController.AssertNotCalled(t, "Commit")
+		mockFlags.On("Evaluate", ctx, domain.FlagOverdraft, wallet.UserID).Return(domain.FlagDecision{Key: domain.FlagOverdraft, Reason: "off"}).Once()
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(wallet, nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
 
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+		_, _, err := service.Withdraw(ctx, walletID, amount, currency)
+
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController, mockFlags)
 	})
 
-	// Test Case 5: Create Wallet Error
-	t.Run("CreateWalletError", func(t *testing.T) {
+	t.Run("UnverifiedOwnerRejected", func(t *testing.T) {
 		ctx := context.Background()
+		amount := decimal.NewFromFloat(30.00)
 		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
-		mockTransactionRepo := new(MockTransactionRepository)
-		mockDBBeginner := new(MockDBBeginner)
-		mockDBExecutor := new(MockDBExecutor)
 		mockTxController := new(MockTxController)
+		mockFlags := new(MockFeatureFlags)
+		service := newService(mockUserRepo, mockWalletRepo, new(MockTransactionRepository), mockTxController, mockFlags)
 
-		service := NewWalletService(
-			mockDBBeginner,
-			mockDBExecutor,
-			mockUserRepo,
+		mockFlags.On("Evaluate", ctx, domain.FlagOverdraft, wallet.UserID).Return(domain.FlagDecision{Key: domain.FlagOverdraft, Enabled: true, Value: "100.00", Reason: "cohort"}).Once()
+		mockUserRepo.On("GetUserByID", ctx, dbExecutor, wallet.UserID).Return(&domain.User{ID: wallet.UserID}, nil).Once()
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(wallet, nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
+
+		_, _, err := service.Withdraw(ctx, walletID, amount, currency)
+
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		mock.AssertExpectationsForObjects(t, mockUserRepo, mockWalletRepo, mockTxController, mockFlags)
+	})
+}
+
+// TestApplySweepRule tests that sweep rules move money as AUTO_SWEEP transfers computed from locked balances.
+func TestApplySweepRule(t *testing.T) {
+	newService := func(mockWalletRepo *MockWalletRepository, mockTransactionRepo *MockTransactionRepository, mockTxController *MockTxController) WalletService {
+		return NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
 			mockWalletRepo,
 			mockTransactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
@@ -1736,42 +4405,146 @@ func TestCreateUserAndWallet(t *testing.T) {
 				_ = mockTxController.Rollback()
 			},
 		)
+	}
 
-		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, util.ErrNotFound).Once()                // Use mockTxController
-		mockUserRepo.On("CreateUser", ctx, mockTxController, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) { // Use mockTxController
-			userArg := args.Get(2).(*domain.User)
-			userArg.ID = 1 // Simulate ID being set
-		}).Return(nil).Once()
-		testError := errors.New("wallet repo save error")
-		mockWalletRepo.On("CreateWallet", ctx, mockTxController, mock.AnythingOfType("*domain.Wallet")).Return(testError).Once() // Use mockTxController
-		mockTxController.On("Rollback").Return(nil).Once()                                                                       // Expect rollback
+	t.Run("SweepMovesExcess", func(t *testing.T) {
+		ctx := context.Background()
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
+
+		rule := &domain.SweepRule{ID: 3, UserID: 1, Kind: domain.SweepRuleKindSweep, SourceWalletID: 2, TargetWalletID: 1, Threshold: decimal.NewFromInt(100)}
+		source := &domain.Wallet{ID: 2, UserID: 1, Currency: "USD", Balance: decimal.NewFromInt(250)}
+		target := &domain.Wallet{ID: 1, UserID: 1, Currency: "USD", Balance: decimal.NewFromInt(10)}
+		excess := decimal.NewFromInt(150)
+
+		mockWalletRepo.On("GetWalletByIDForUpdate", ctx, mockTxController, int64(1)).Return(target, nil).Once()
+		mockWalletRepo.On("GetWalletByIDForUpdate", ctx, mockTxController, int64(2)).Return(source, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, int64(2), excess.Neg(), mock.Anything).Return(nil, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, int64(1), excess, mock.Anything).Return(nil, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeAutoSweep && tx.Amount.Equal(excess) && *tx.FromWalletID == 2 && *tx.ToWalletID == 1
+		})).Return(nil).Once()
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
 
-		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency)
+		transaction, err := service.ApplySweepRule(ctx, rule)
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to create wallet")
-		assert.Nil(t, resUser)
-		assert.Nil(t, resWallet)
+		assert.NoError(t, err)
+		assert.NotNil(t, transaction)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController)
+	})
 
-		mockTxController.AssertNotCalled(t, "Commit")
+	t.Run("TopUpCappedBySourceBalance", func(t *testing.T) {
+		ctx := context.Background()
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
 
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+		rule := &domain.SweepRule{ID: 4, UserID: 1, Kind: domain.SweepRuleKindTopUp, SourceWalletID: 1, TargetWalletID: 2, Threshold: decimal.NewFromInt(100)}
+		source := &domain.Wallet{ID: 1, UserID: 1, Currency: "USD", Balance: decimal.NewFromInt(30)}
+		target := &domain.Wallet{ID: 2, UserID: 1, Currency: "USD", Balance: decimal.NewFromInt(20)}
+		amount := decimal.NewFromInt(30)
+
+		mockWalletRepo.On("GetWalletByIDForUpdate", ctx, mockTxController, int64(1)).Return(source, nil).Once()
+		mockWalletRepo.On("GetWalletByIDForUpdate", ctx, mockTxController, int64(2)).Return(target, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, int64(1), amount.Neg(), mock.Anything).Return(nil, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, int64(2), amount, mock.Anything).Return(nil, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
+
+		transaction, err := service.ApplySweepRule(ctx, rule)
+
+		assert.NoError(t, err)
+		assert.True(t, transaction.Amount.Equal(amount))
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController)
 	})
 
-	// Test Case 6: Commit Error
-	t.Run("CommitError", func(t *testing.T) {
+	t.Run("BelowThresholdDoesNothing", func(t *testing.T) {
 		ctx := context.Background()
-		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
 		mockTransactionRepo := new(MockTransactionRepository)
-		mockDBBeginner := new(MockDBBeginner)
-		mockDBExecutor := new(MockDBExecutor)
 		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
 
-		service := NewWalletService(
-			mockDBBeginner,
-			mockDBExecutor,
-			mockUserRepo,
+		rule := &domain.SweepRule{ID: 5, UserID: 1, Kind: domain.SweepRuleKindSweep, SourceWalletID: 1, TargetWalletID: 2, Threshold: decimal.NewFromInt(100)}
+		mockWalletRepo.On("GetWalletByIDForUpdate", ctx, mockTxController, int64(1)).Return(&domain.Wallet{ID: 1, Currency: "USD", Balance: decimal.NewFromInt(80)}, nil).Once()
+		mockWalletRepo.On("GetWalletByIDForUpdate", ctx, mockTxController, int64(2)).Return(&domain.Wallet{ID: 2, Currency: "USD"}, nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
+
+		transaction, err := service.ApplySweepRule(ctx, rule)
+
+		assert.NoError(t, err)
+		assert.Nil(t, transaction)
+		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController)
+	})
+}
+
+// TestTransferApprovalPolicy tests that transfers above a joint wallet's threshold are held back for approval.
+func TestTransferApprovalPolicy(t *testing.T) {
+	ctx := context.Background()
+	amount := decimal.NewFromFloat(500.00)
+	fromWallet := &domain.Wallet{ID: 1, UserID: 1, Currency: "USD", Balance: decimal.NewFromFloat(1000.00)}
+	toWallet := &domain.Wallet{ID: 2, UserID: 2, Currency: "USD", Balance: decimal.Zero}
+
+	mockWalletRepo := new(MockWalletRepository)
+	mockMemberRepo := new(MockWalletMemberRepository)
+	mockTxController := new(MockTxController)
+	service := NewWalletService(
+		new(MockDBBeginner),
+		new(MockDBExecutor),
+		new(MockUserRepository),
+		mockWalletRepo,
+		new(MockTransactionRepository),
+		func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
+			return mockTxController, nil
+		},
+		func(tx db.TxController) error {
+			return mockTxController.Commit()
+		},
+		func(tx db.TxController) {
+			_ = mockTxController.Rollback()
+		},
+		WithApprovalPolicies(mockMemberRepo),
+	)
+
+	mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{1, 2}).Return([]domain.Wallet{*fromWallet, *toWallet}, nil).Once()
+	mockMemberRepo.On("GetApprovalPolicy", ctx, mockTxController, int64(1)).Return(&domain.ApprovalPolicy{WalletID: 1, Threshold: decimal.NewFromInt(100), RequiredApprovals: 2}, nil).Once()
+	mockTxController.On("Rollback").Return(nil).Once()
+
+	_, _, _, err := service.Transfer(ctx, 1, 2, amount, "USD")
+
+	assert.ErrorIs(t, err, util.ErrApprovalRequired)
+	mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mock.AssertExpectationsForObjects(t, mockWalletRepo, mockMemberRepo, mockTxController)
+}
+
+func TestQuotedTransfer(t *testing.T) {
+	fromWallet := &domain.Wallet{ID: 1, UserID: 1, Currency: "USD", Balance: decimal.NewFromInt(500)}
+	toWallet := &domain.Wallet{ID: 2, UserID: 2, Currency: "HKD", Balance: decimal.NewFromInt(100)}
+	rate := decimal.RequireFromString("7.8")
+	quote := &domain.TransferQuote{
+		ID:             9,
+		PublicID:       uuid.New(),
+		FromWalletID:   1,
+		ToWalletID:     2,
+		Amount:         decimal.NewFromInt(50),
+		Currency:       "USD",
+		Fee:            decimal.Zero,
+		CreditAmount:   decimal.NewFromInt(390),
+		CreditCurrency: "HKD",
+		Rate:           &rate,
+	}
+
+	newService := func(mockWalletRepo *MockWalletRepository, mockTransactionRepo *MockTransactionRepository, mockQuoteRepo *MockTransferQuoteRepository, mockTxController *MockTxController) WalletService {
+		return NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
 			mockWalletRepo,
 			mockTransactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
@@ -1783,53 +4556,108 @@ func TestCreateUserAndWallet(t *testing.T) {
 			func(tx db.TxController) {
 				_ = mockTxController.Rollback()
 			},
+			WithTransferQuotes(mockQuoteRepo),
 		)
+	}
 
-		mockUserRepo.On("GetUserByUsername", ctx, mockTxController, username).Return(nil, util.ErrNotFound).Once()                // Use mockTxController
-		mockUserRepo.On("CreateUser", ctx, mockTxController, mock.AnythingOfType("*domain.User")).Run(func(args mock.Arguments) { // Use mockTxController
-			userArg := args.Get(2).(*domain.User)
-			userArg.ID = 1 // Simulate ID being set
-		}).Return(nil).Once()
-		mockWalletRepo.On("CreateWallet", ctx, mockTxController, mock.AnythingOfType("*domain.Wallet")).Run(func(args mock.Arguments) { // Use mockTxController
-			walletArg := args.Get(2).(*domain.Wallet)
-			walletArg.ID = 101 // Simulate ID being set
-		}).Return(nil).Once()
+	t.Run("CrossCurrencyConversionLegs", func(t *testing.T) {
+		ctx := WithTransferQuote(context.Background(), quote.PublicID)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockQuoteRepo := new(MockTransferQuoteRepository)
+		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockQuoteRepo, mockTxController)
+
+		var legs []*domain.Transaction
+		mockQuoteRepo.On("ClaimQuote", ctx, mockTxController, quote.PublicID, mock.AnythingOfType("time.Time")).Return(quote, nil).Once()
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{1, 2}).Return([]domain.Wallet{*fromWallet, *toWallet}, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalances", ctx, mockTxController, map[int64]decimal.Decimal{1: decimal.NewFromInt(-50), 2: decimal.NewFromInt(390)}, mock.Anything).
+			Return([]domain.Wallet{{ID: 1, UserID: 1, Currency: "USD", Balance: decimal.NewFromInt(450)}, {ID: 2, UserID: 2, Currency: "HKD", Balance: decimal.NewFromInt(490)}}, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).
+			Run(func(args mock.Arguments) { legs = append(legs, args.Get(2).(*domain.Transaction)) }).Return(nil).Twice()
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
 
-		testError := errors.New("commit failed")
-		mockTxController.On("Commit").Return(testError).Once()
-		mockTxController.On("Rollback").Return(nil).Maybe() // Rollback might be called after commit fails
+		updatedFrom, updatedTo, transaction, err := service.Transfer(ctx, 1, 2, decimal.NewFromInt(50), "USD")
 
-		resUser, resWallet, err := service.CreateUserAndWallet(ctx, username, currency)
+		assert.NoError(t, err)
+		assert.True(t, updatedFrom.Balance.Equal(decimal.NewFromInt(450)))
+		assert.True(t, updatedTo.Balance.Equal(decimal.NewFromInt(490)))
+		if assert.Len(t, legs, 2) {
+			assert.Same(t, legs[0], transaction)
+			assert.Equal(t, domain.TransactionTypeConversion, legs[0].Type)
+			assert.Equal(t, "USD", legs[0].Currency)
+			assert.Nil(t, legs[0].ToWalletID)
+			assert.Equal(t, domain.TransactionTypeConversion, legs[1].Type)
+			assert.Equal(t, "HKD", legs[1].Currency)
+			assert.True(t, legs[1].Amount.Equal(decimal.NewFromInt(390)))
+			assert.Nil(t, legs[1].FromWalletID)
+			assert.Equal(t, &legs[0].PublicID, legs[1].ParentID)
+		}
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockQuoteRepo, mockTxController)
+	})
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to commit transaction")
-		assert.Nil(t, resUser)
-		assert.Nil(t, resWallet)
+	t.Run("QuoteForAnotherAmount", func(t *testing.T) {
+		ctx := WithTransferQuote(context.Background(), quote.PublicID)
+		mockWalletRepo := new(MockWalletRepository)
+		mockQuoteRepo := new(MockTransferQuoteRepository)
+		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, new(MockTransactionRepository), mockQuoteRepo, mockTxController)
 
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+		mockQuoteRepo.On("ClaimQuote", ctx, mockTxController, quote.PublicID, mock.AnythingOfType("time.Time")).Return(quote, nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, decimal.NewFromInt(60), "USD")
+
+		assert.ErrorIs(t, err, util.ErrQuoteNotUsable)
+		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, mockQuoteRepo, mockTxController)
 	})
-}
 
-// TestGetTransactionHistory tests the GetTransactionHistory method of WalletService.
-func TestGetTransactionHistory(t *testing.T) {
-	walletID := int64(1)
-	limit := 10
-	offset := 0
+	t.Run("ExpiredOrUsedQuote", func(t *testing.T) {
+		ctx := WithTransferQuote(context.Background(), quote.PublicID)
+		mockQuoteRepo := new(MockTransferQuoteRepository)
+		mockTxController := new(MockTxController)
+		service := newService(new(MockWalletRepository), new(MockTransactionRepository), mockQuoteRepo, mockTxController)
 
-	// Test Case 1: Successful GetTransactionHistory with results
-	t.Run("SuccessfulGetTransactionHistoryWithResults", func(t *testing.T) {
+		mockQuoteRepo.On("ClaimQuote", ctx, mockTxController, quote.PublicID, mock.AnythingOfType("time.Time")).Return(nil, util.ErrNotFound).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, decimal.NewFromInt(50), "USD")
+
+		assert.ErrorIs(t, err, util.ErrQuoteNotUsable)
+		mock.AssertExpectationsForObjects(t, mockQuoteRepo, mockTxController)
+	})
+
+	t.Run("CrossCurrencyWithoutQuote", func(t *testing.T) {
 		ctx := context.Background()
-		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
-		mockTransactionRepo := new(MockTransactionRepository)
-		mockDBBeginner := new(MockDBBeginner)
-		mockDBExecutor := new(MockDBExecutor) // This is used for read-only operations
+		mockQuoteRepo := new(MockTransferQuoteRepository)
 		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, new(MockTransactionRepository), mockQuoteRepo, mockTxController)
 
-		service := NewWalletService(
-			mockDBBeginner,
-			mockDBExecutor, // Pass mockDBExecutor here
-			mockUserRepo,
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{1, 2}).Return([]domain.Wallet{*fromWallet, *toWallet}, nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, decimal.NewFromInt(50), "USD")
+
+		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
+		mockQuoteRepo.AssertNotCalled(t, "ClaimQuote", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestDuplicateTransfer tests that a transfer repeating a recent one is rejected unless forced.
+func TestDuplicateTransfer(t *testing.T) {
+	fromWallet := domain.Wallet{ID: 1, UserID: 1, Currency: "USD", Balance: decimal.NewFromInt(500)}
+	toWallet := domain.Wallet{ID: 2, UserID: 2, Currency: "USD", Balance: decimal.NewFromInt(100)}
+	amount := decimal.NewFromInt(50)
+	earlier := &domain.Transaction{PublicID: uuid.New(), Type: domain.TransactionTypeTransfer, CreatedAt: time.Now().UTC().Add(-time.Minute)}
+
+	newService := func(mockWalletRepo *MockWalletRepository, mockTransactionRepo *MockTransactionRepository, mockTxController *MockTxController) WalletService {
+		return NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
 			mockWalletRepo,
 			mockTransactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
@@ -1841,63 +4669,92 @@ func TestGetTransactionHistory(t *testing.T) {
 			func(tx db.TxController) {
 				_ = mockTxController.Rollback()
 			},
+			WithDuplicateTransferWindow(10*time.Minute),
 		)
+	}
 
-		// FIX: Use mockDBExecutor for the second argument
-		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
-		// Corrected expectedTransactions definition
-		expectedTransactions := []domain.Transaction{
-			{
-				ID:           1,
-				FromWalletID: nil,       // Deposit has no from_wallet_id
-				ToWalletID:   &walletID, // Deposit goes to wallet_id
-				Type:         domain.TransactionTypeDeposit,
-				Amount:       decimal.NewFromFloat(100),
-				Currency:     "USD", // Assuming currency is "USD" for these transactions
-			},
-			{
-				ID:           2,
-				FromWalletID: &walletID, // Withdrawal comes from wallet_id
-				ToWalletID:   nil,       // Withdrawal has no to_wallet_id
-				Type:         domain.TransactionTypeWithdrawal,
-				Amount:       decimal.NewFromFloat(50),
-				Currency:     "USD", // Assuming currency is "USD" for these transactions
-			},
-		}
-		expectedTotalCount := int64(len(expectedTransactions)) // FIX: Define expectedTotalCount
+	t.Run("RecentDuplicateRejected", func(t *testing.T) {
+		ctx := context.Background()
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
 
-		// GetTransactionHistory uses s.dbExecutor directly, not a transaction
-		// FIX: Add expectedTotalCount as the second return value
-		mockTransactionRepo.On("GetTransactionsByWalletID", ctx, mockDBExecutor, walletID, limit, offset).Return(expectedTransactions, expectedTotalCount, nil).Once()
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{1, 2}).Return([]domain.Wallet{fromWallet, toWallet}, nil).Once()
+		mockTransactionRepo.On("FindRecentTransfer", ctx, mockTxController, int64(1), int64(2), amount, "USD", mock.MatchedBy(func(since time.Time) bool {
+			return time.Since(since) >= 10*time.Minute && time.Since(since) < 11*time.Minute
+		})).Return(earlier, nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
 
-		resTransactions, totalCount, err := service.GetTransactionHistory(ctx, walletID, limit, offset)
+		_, _, _, err := service.Transfer(ctx, 1, 2, amount, "USD")
 
-		assert.NoError(t, err)
-		assert.NotNil(t, resTransactions)
-		assert.Equal(t, expectedTransactions, resTransactions)
-		assert.Equal(t, expectedTotalCount, totalCount) // FIX: Assert totalCount
+		assert.ErrorIs(t, err, util.ErrDuplicateTransfer)
+		var duplicate *util.DuplicateTransferError
+		if assert.ErrorAs(t, err, &duplicate) {
+			assert.Equal(t, earlier.PublicID, duplicate.ExistingID)
+			assert.Equal(t, earlier.CreatedAt, duplicate.CreatedAt)
+		}
+		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController)
+	})
 
-		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
-		mockTxController.AssertNotCalled(t, "Commit")
-		mockTxController.AssertNotCalled(t, "Rollback")
+	t.Run("NoRecentTransfer", func(t *testing.T) {
+		ctx := context.Background()
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
 
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{1, 2}).Return([]domain.Wallet{fromWallet, toWallet}, nil).Once()
+		mockTransactionRepo.On("FindRecentTransfer", ctx, mockTxController, int64(1), int64(2), amount, "USD", mock.AnythingOfType("time.Time")).Return(nil, util.ErrNotFound).Once()
+		mockWalletRepo.On("UpdateWalletBalances", ctx, mockTxController, map[int64]decimal.Decimal{1: amount.Neg(), 2: amount}, mock.Anything).
+			Return([]domain.Wallet{fromWallet, toWallet}, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, amount, "USD")
+
+		assert.NoError(t, err)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController)
 	})
 
-	// Test Case 2: Successful GetTransactionHistory with no results
-	t.Run("SuccessfulGetTransactionHistoryNoResults", func(t *testing.T) {
-		ctx := context.Background()
-		mockUserRepo := new(MockUserRepository)
+	t.Run("ForcedSkipsCheck", func(t *testing.T) {
+		ctx := WithForcedTransfer(context.Background())
 		mockWalletRepo := new(MockWalletRepository)
 		mockTransactionRepo := new(MockTransactionRepository)
-		mockDBBeginner := new(MockDBBeginner)
-		mockDBExecutor := new(MockDBExecutor)
 		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
 
-		service := NewWalletService(
-			mockDBBeginner,
-			mockDBExecutor,
-			mockUserRepo,
+		mockWalletRepo.On("GetWalletsByIDs", ctx, mockTxController, []int64{1, 2}).Return([]domain.Wallet{fromWallet, toWallet}, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalances", ctx, mockTxController, map[int64]decimal.Decimal{1: amount.Neg(), 2: amount}, mock.Anything).
+			Return([]domain.Wallet{fromWallet, toWallet}, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
+
+		_, _, _, err := service.Transfer(ctx, 1, 2, amount, "USD")
+
+		assert.NoError(t, err)
+		mockTransactionRepo.AssertNotCalled(t, "FindRecentTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController)
+	})
+}
+
+func TestWithdrawSoftBlockBudget(t *testing.T) {
+	walletID := int64(1)
+	currency := "USD"
+	wallet := &domain.Wallet{ID: walletID, UserID: 7, Currency: currency, Balance: decimal.NewFromFloat(500.00)}
+	groceries := "groceries"
+	budgets := []domain.Budget{
+		{ID: 3, WalletID: walletID, Category: &groceries, Period: domain.BudgetPeriodMonthly, Limit: decimal.NewFromFloat(200.00), Enforcement: domain.BudgetEnforcementSoftBlock},
+	}
+
+	newService := func(mockWalletRepo *MockWalletRepository, mockTransactionRepo *MockTransactionRepository, mockTxController *MockTxController, budgetRepo repository.BudgetRepository) WalletService {
+		return NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
 			mockWalletRepo,
 			mockTransactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
@@ -1909,44 +4766,93 @@ func TestGetTransactionHistory(t *testing.T) {
 			func(tx db.TxController) {
 				_ = mockTxController.Rollback()
 			},
+			WithBudgets(budgetRepo),
 		)
+	}
 
-		// FIX: Use mockDBExecutor for the second argument
-		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
-		expectedTransactions := []domain.Transaction{} // Empty slice
-		expectedTotalCount := int64(0)                 // FIX: Define expectedTotalCount
+	t.Run("ExceedingBudgetRejected", func(t *testing.T) {
+		ctx := WithTransactionCategory(context.Background(), "Groceries")
+		amount := decimal.NewFromFloat(60.00)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		mockBudgetRepo := new(MockBudgetRepository)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController, mockBudgetRepo)
 
-		// FIX: Add expectedTotalCount as the second return value
-		mockTransactionRepo.On("GetTransactionsByWalletID", ctx, mockDBExecutor, walletID, limit, offset).Return(expectedTransactions, expectedTotalCount, nil).Once()
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(wallet, nil).Once()
+		mockBudgetRepo.On("ListBudgetsByWalletID", ctx, mockTxController, walletID).Return(budgets, nil).Once()
+		mockTransactionRepo.On("SumOutgoingAmount", ctx, mockTxController, walletID, &groceries, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return(decimal.NewFromFloat(150.00), nil).Once()
+		mockTxController.On("Rollback").Return(nil).Once()
 
-		resTransactions, totalCount, err := service.GetTransactionHistory(ctx, walletID, limit, offset)
+		_, _, err := service.Withdraw(ctx, walletID, amount, currency)
 
-		assert.NoError(t, err)
-		assert.NotNil(t, resTransactions)
-		assert.Empty(t, resTransactions)
-		assert.Equal(t, expectedTotalCount, totalCount) // FIX: Assert totalCount
+		assert.ErrorIs(t, err, util.ErrBudgetExceeded)
+		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController, mockBudgetRepo)
+	})
 
-		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
-		mockTxController.AssertNotCalled(t, "Commit")
-		mockTxController.AssertNotCalled(t, "Rollback")
+	t.Run("OtherCategoryAllowed", func(t *testing.T) {
+		ctx := WithTransactionCategory(context.Background(), "rent")
+		amount := decimal.NewFromFloat(300.00)
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		mockBudgetRepo := new(MockBudgetRepository)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController, mockBudgetRepo)
+
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(wallet, nil).Once()
+		mockBudgetRepo.On("ListBudgetsByWalletID", ctx, mockTxController, walletID).Return(budgets, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount.Neg(), mock.Anything).Return(&domain.WalletBalance{ID: walletID, Balance: wallet.Balance.Sub(amount)}, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Category != nil && *tx.Category == "rent"
+		})).Return(nil).Once()
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
 
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+		_, _, err := service.Withdraw(ctx, walletID, amount, currency)
+
+		assert.NoError(t, err)
+		mockTransactionRepo.AssertNotCalled(t, "SumOutgoingAmount", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController, mockBudgetRepo)
 	})
 
-	// Test Case 3: Repository Error
-	t.Run("RepositoryError", func(t *testing.T) {
-		ctx := context.Background()
-		mockUserRepo := new(MockUserRepository)
+	t.Run("OverrideSkipsBudgets", func(t *testing.T) {
+		ctx := WithBudgetOverride(WithTransactionCategory(context.Background(), "groceries"))
+		amount := decimal.NewFromFloat(60.00)
 		mockWalletRepo := new(MockWalletRepository)
 		mockTransactionRepo := new(MockTransactionRepository)
-		mockDBBeginner := new(MockDBBeginner)
-		mockDBExecutor := new(MockDBExecutor)
 		mockTxController := new(MockTxController)
+		mockBudgetRepo := new(MockBudgetRepository)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController, mockBudgetRepo)
 
-		service := NewWalletService(
-			mockDBBeginner,
-			mockDBExecutor,
-			mockUserRepo,
+		mockWalletRepo.On("GetWalletByID", ctx, mockTxController, walletID).Return(wallet, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, walletID, amount.Neg(), mock.Anything).Return(&domain.WalletBalance{ID: walletID, Balance: wallet.Balance.Sub(amount)}, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
+
+		_, _, err := service.Withdraw(ctx, walletID, amount, currency)
+
+		assert.NoError(t, err)
+		mockBudgetRepo.AssertNotCalled(t, "ListBudgetsByWalletID", mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController, mockBudgetRepo)
+	})
+}
+
+// TestSplitTransfer tests that a split is resolved to legs that add up to the total and moves nothing when it cannot be paid.
+func TestSplitTransfer(t *testing.T) {
+	currency := "USD"
+	source := &domain.Wallet{ID: 1, PublicID: uuid.New(), UserID: 7, Currency: currency, Balance: decimal.NewFromFloat(50.00)}
+	first := &domain.Wallet{ID: 2, PublicID: uuid.New(), UserID: 8, Currency: currency}
+	second := &domain.Wallet{ID: 3, PublicID: uuid.New(), UserID: 9, Currency: currency}
+	third := &domain.Wallet{ID: 4, PublicID: uuid.New(), UserID: 9, Currency: currency}
+
+	newService := func(mockWalletRepo *MockWalletRepository, mockTransactionRepo *MockTransactionRepository, mockTxController *MockTxController) WalletService {
+		return NewWalletService(
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
 			mockWalletRepo,
 			mockTransactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
@@ -1959,82 +4865,481 @@ func TestGetTransactionHistory(t *testing.T) {
 				_ = mockTxController.Rollback()
 			},
 		)
+	}
+	lockAll := func(ctx context.Context, mockWalletRepo *MockWalletRepository, mockTxController *MockTxController) {
+		for _, wallet := range []*domain.Wallet{source, first, second, third} {
+			mockWalletRepo.On("GetWalletByIDForUpdate", ctx, mockTxController, wallet.ID).Return(wallet, nil).Once()
+		}
+	}
 
-		testError := errors.New("network error")
-		// FIX: Explicitly return a nil slice of the correct type AND an int64 for totalCount
-		mockTransactionRepo.On("GetTransactionsByWalletID", ctx, mockDBExecutor, walletID, limit, offset).Return([]domain.Transaction(nil), int64(0), testError).Once()
-		// FIX: Use mockDBExecutor for the second argument
-		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
+	t.Run("ShareSplitHandsOutRemainder", func(t *testing.T) {
+		ctx := context.Background()
+		mockWalletRepo := new(MockWalletRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
+
+		total := decimal.RequireFromString("10.01")
+		split := domain.Split{Currency: currency, Total: total, Legs: []domain.SplitLeg{
+			{ToWalletID: first.ID, Share: decimal.NewFromInt(50)},
+			{ToWalletID: second.ID, Share: decimal.NewFromInt(25)},
+			{ToWalletID: third.ID, Share: decimal.NewFromInt(25)},
+		}}
+		updated := &domain.WalletBalance{ID: source.ID, Balance: source.Balance.Sub(total)}
+
+		lockAll(ctx, mockWalletRepo, mockTxController)
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeSplit
+		})).Return(nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, source.ID, total.Neg(), mock.Anything).Return(updated, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, first.ID, decimal.RequireFromString("5.01"), mock.Anything).Return(nil, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, second.ID, decimal.RequireFromString("2.50"), mock.Anything).Return(nil, nil).Once()
+		mockWalletRepo.On("UpdateWalletBalance", ctx, mockTxController, third.ID, decimal.RequireFromString("2.50"), mock.Anything).Return(nil, nil).Once()
+		mockTransactionRepo.On("CreateTransaction", ctx, mockTxController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Type == domain.TransactionTypeTransfer && tx.ParentID != nil
+		})).Return(nil).Times(3)
+		mockTxController.On("Commit").Return(nil).Once()
+		mockTxController.On("Rollback").Return(nil).Maybe()
 
-		resTransactions, totalCount, err := service.GetTransactionHistory(ctx, walletID, limit, offset)
+		resWallet, parent, legs, err := service.SplitTransfer(ctx, source.ID, split)
 
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), testError.Error())
-		assert.Nil(t, resTransactions)        // resTransactions should be nil here if the repo returns nil slice and error
-		assert.Equal(t, int64(0), totalCount) // FIX: Assert totalCount is 0 on error
+		assert.NoError(t, err)
+		assert.True(t, parent.Amount.Equal(total))
+		assert.Len(t, legs, 3)
+		assert.Equal(t, parent.PublicID, *legs[0].ParentID)
+		assert.True(t, resWallet.Balance.Equal(updated.Balance))
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTransactionRepo, mockTxController)
+	})
 
-		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
-		mockTxController.AssertNotCalled(t, "Commit")
-		mockTxController.AssertNotCalled(t, "Rollback")
+	t.Run("AmountsMustMatchTotal", func(t *testing.T) {
+		mockWalletRepo := new(MockWalletRepository)
+		service := newService(mockWalletRepo, new(MockTransactionRepository), new(MockTxController))
 
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+		split := domain.Split{Currency: currency, Total: decimal.NewFromInt(20), Legs: []domain.SplitLeg{
+			{ToWalletID: first.ID, Amount: decimal.NewFromInt(5)},
+			{ToWalletID: second.ID, Amount: decimal.NewFromInt(10)},
+		}}
+		_, _, _, err := service.SplitTransfer(context.Background(), source.ID, split)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+		mockWalletRepo.AssertNotCalled(t, "GetWalletByIDForUpdate", mock.Anything, mock.Anything, mock.Anything)
 	})
 
-	// Test Case 4: Invalid Limit/Offset (should use defaults)
-	t.Run("InvalidLimitOffset", func(t *testing.T) {
+	t.Run("AmountBeyondCurrencyPrecisionRejected", func(t *testing.T) {
+		service := newService(new(MockWalletRepository), new(MockTransactionRepository), new(MockTxController))
+
+		split := domain.Split{Currency: "JPY", Legs: []domain.SplitLeg{
+			{ToWalletID: first.ID, Amount: decimal.RequireFromString("100.5")},
+			{ToWalletID: second.ID, Amount: decimal.NewFromInt(100)},
+		}}
+		_, _, _, err := service.SplitTransfer(context.Background(), source.ID, split)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+	})
+
+	t.Run("DuplicateDestinationRejected", func(t *testing.T) {
+		service := newService(new(MockWalletRepository), new(MockTransactionRepository), new(MockTxController))
+
+		split := domain.Split{Currency: currency, Legs: []domain.SplitLeg{
+			{ToWalletID: first.ID, Amount: decimal.NewFromInt(5)},
+			{ToWalletID: first.ID, Amount: decimal.NewFromInt(5)},
+		}}
+		_, _, _, err := service.SplitTransfer(context.Background(), source.ID, split)
+
+		assert.ErrorIs(t, err, util.ErrInvalidInput)
+	})
+
+	t.Run("InsufficientFundsMovesNothing", func(t *testing.T) {
 		ctx := context.Background()
-		mockUserRepo := new(MockUserRepository)
 		mockWalletRepo := new(MockWalletRepository)
 		mockTransactionRepo := new(MockTransactionRepository)
-		mockDBBeginner := new(MockDBBeginner)
-		mockDBExecutor := new(MockDBExecutor)
 		mockTxController := new(MockTxController)
+		service := newService(mockWalletRepo, mockTransactionRepo, mockTxController)
+
+		split := domain.Split{Currency: currency, Total: decimal.NewFromInt(90), Legs: []domain.SplitLeg{
+			{ToWalletID: first.ID, Share: decimal.NewFromInt(40)},
+			{ToWalletID: second.ID, Share: decimal.NewFromInt(30)},
+			{ToWalletID: third.ID, Share: decimal.NewFromInt(30)},
+		}}
+		lockAll(ctx, mockWalletRepo, mockTxController)
+		mockTxController.On("Rollback").Return(nil).Once()
+
+		_, _, _, err := service.SplitTransfer(ctx, source.ID, split)
+
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		mockWalletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockTransactionRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything)
+		mock.AssertExpectationsForObjects(t, mockWalletRepo, mockTxController)
+	})
+}
+
+// TestPromoCreditSpending tests that withdrawals and transfers spend promotional credits, soonest expiring first,
+// before the wallet's balance.
+func TestPromoCreditSpending(t *testing.T) {
+	currency := "USD"
+	source := &domain.Wallet{ID: 1, UserID: 7, Currency: currency, Balance: decimal.NewFromFloat(20.00)}
+	target := &domain.Wallet{ID: 2, UserID: 8, Currency: currency}
+	credits := []domain.PromoCredit{
+		{ID: 11, WalletID: 1, Remaining: decimal.NewFromFloat(5.00), Status: domain.PromoCreditStatusActive},
+		{ID: 12, WalletID: 1, Remaining: decimal.NewFromFloat(10.00), Status: domain.PromoCreditStatusActive},
+	}
 
+	type mocks struct {
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		promoRepo       *MockPromoCreditRepository
+		txController    *MockTxController
+	}
+	newService := func() (WalletService, mocks) {
+		m := mocks{
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			promoRepo:       new(MockPromoCreditRepository),
+			txController:    new(MockTxController),
+		}
 		service := NewWalletService(
-			mockDBBeginner,
-			mockDBExecutor,
-			mockUserRepo,
-			mockWalletRepo,
-			mockTransactionRepo,
+			new(MockDBBeginner),
+			new(MockDBExecutor),
+			new(MockUserRepository),
+			m.walletRepo,
+			m.transactionRepo,
 			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) {
-				return mockTxController, nil
+				return m.txController, nil
 			},
 			func(tx db.TxController) error {
-				return mockTxController.Commit()
+				return m.txController.Commit()
 			},
 			func(tx db.TxController) {
-				_ = mockTxController.Rollback()
+				_ = m.txController.Rollback()
 			},
+			WithPromoCredits(m.promoRepo),
 		)
+		return service, m
+	}
 
-		// Corrected expectedTransactions definition
-		expectedTransactions := []domain.Transaction{
-			{
-				ID:           1,
-				FromWalletID: nil,
-				ToWalletID:   &walletID,
-				Type:         domain.TransactionTypeDeposit,
-				Amount:       decimal.NewFromFloat(100),
-				Currency:     "USD",
-			},
+	t.Run("TransferSpendsCreditsBeforeBalance", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		amount := decimal.NewFromFloat(12.00)
+
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{source.ID, target.ID}).Return([]domain.Wallet{*source, *target}, nil).Once()
+		m.promoRepo.On("ListSpendableCreditsForUpdate", ctx, m.txController, source.ID, mock.AnythingOfType("time.Time"), false).Return(credits, nil).Once()
+		m.promoRepo.On("ConsumeCredit", ctx, m.txController, int64(11), decimal.NewFromFloat(5.00)).Return(nil).Once()
+		m.promoRepo.On("ConsumeCredit", ctx, m.txController, int64(12), decimal.NewFromFloat(7.00)).Return(nil).Once()
+		// Fully covered by credits, so nothing is taken from the source balance
+		m.walletRepo.On("UpdateWalletBalances", ctx, m.txController, mock.MatchedBy(func(amounts map[int64]decimal.Decimal) bool {
+			return len(amounts) == 2 && amounts[source.ID].IsZero() && amounts[target.ID].Equal(amount)
+		}), mock.Anything).Return([]domain.Wallet{*source, *target}, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Amount.Equal(amount) && tx.PromoAmount.Equal(amount)
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		_, _, _, err := service.Transfer(ctx, source.ID, target.ID, amount, currency)
+
+		assert.NoError(t, err)
+		mock.AssertExpectationsForObjects(t, m.walletRepo, m.transactionRepo, m.promoRepo, m.txController)
+	})
+
+	t.Run("WithdrawDebitsRemainderFromBalance", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+		amount := decimal.NewFromFloat(30.00)
+
+		m.walletRepo.On("GetWalletByID", ctx, m.txController, source.ID).Return(source, nil).Once()
+		m.promoRepo.On("ListSpendableCreditsForUpdate", ctx, m.txController, source.ID, mock.AnythingOfType("time.Time"), true).Return(credits[1:], nil).Once()
+		m.promoRepo.On("ConsumeCredit", ctx, m.txController, int64(12), decimal.NewFromFloat(10.00)).Return(nil).Once()
+		m.walletRepo.On("UpdateWalletBalance", ctx, m.txController, source.ID, decimal.NewFromFloat(-20.00), mock.Anything).Return(&domain.WalletBalance{ID: source.ID, Balance: source.Balance.Sub(decimal.NewFromFloat(20.00))}, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Amount.Equal(amount) && tx.PromoAmount.Equal(decimal.NewFromFloat(10.00))
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+		m.txController.On("Rollback").Return(nil).Maybe()
+
+		_, _, err := service.Withdraw(ctx, source.ID, amount, currency)
+
+		assert.NoError(t, err)
+		mock.AssertExpectationsForObjects(t, m.walletRepo, m.transactionRepo, m.promoRepo, m.txController)
+	})
+
+	t.Run("InsufficientEvenWithCreditsSpendsNothing", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService()
+
+		m.walletRepo.On("GetWalletByID", ctx, m.txController, source.ID).Return(source, nil).Once()
+		m.promoRepo.On("ListSpendableCreditsForUpdate", ctx, m.txController, source.ID, mock.AnythingOfType("time.Time"), true).Return(credits, nil).Once()
+		m.txController.On("Rollback").Return(nil).Once()
+
+		_, _, err := service.Withdraw(ctx, source.ID, decimal.NewFromFloat(36.00), currency)
+
+		assert.ErrorIs(t, err, util.ErrInsufficientFunds)
+		m.promoRepo.AssertNotCalled(t, "ConsumeCredit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestSetUserTimezone tests validation of user time zones against the IANA database.
+func TestSetUserTimezone(t *testing.T) {
+	newService := func(mockUserRepo *MockUserRepository, mockDBExecutor *MockDBExecutor) WalletService {
+		return NewWalletService(new(MockDBBeginner), mockDBExecutor, mockUserRepo, new(MockWalletRepository), new(MockTransactionRepository),
+			nil, nil, nil)
+	}
+
+	t.Run("KnownZoneIsStored", func(t *testing.T) {
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockDBExecutor := new(MockDBExecutor)
+		service := newService(mockUserRepo, mockDBExecutor)
+		mockUserRepo.On("UpdateUserTimezone", ctx, mockDBExecutor, int64(7), "Europe/Berlin").
+			Return(&domain.User{ID: 7, Timezone: "Europe/Berlin"}, nil).Once()
+
+		user, err := service.SetUserTimezone(ctx, 7, "Europe/Berlin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Europe/Berlin", user.Timezone)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("UnknownZoneIsRejected", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := newService(mockUserRepo, new(MockDBExecutor))
+
+		for _, timezone := range []string{"", "Local", "Mars/Olympus_Mons", "+02:00"} {
+			_, err := service.SetUserTimezone(context.Background(), 7, timezone)
+			assert.ErrorIs(t, err, util.ErrInvalidInput, timezone)
 		}
-		expectedTotalCount := int64(len(expectedTransactions))
+		mockUserRepo.AssertNotCalled(t, "UpdateUserTimezone", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 
-		// FIX: Change expected limit and offset to -5 and -10 respectively
-		mockTransactionRepo.On("GetTransactionsByWalletID", ctx, mockDBExecutor, walletID, -5, -10).Return(expectedTransactions, expectedTotalCount, nil).Once()
-		mockWalletRepo.On("GetWalletByID", ctx, mockDBExecutor, walletID).Return(&domain.Wallet{ID: walletID, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
+	t.Run("UnknownUserIsNotFound", func(t *testing.T) {
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockDBExecutor := new(MockDBExecutor)
+		service := newService(mockUserRepo, mockDBExecutor)
+		mockUserRepo.On("UpdateUserTimezone", ctx, mockDBExecutor, int64(8), "UTC").Return(nil, util.ErrNotFound).Once()
 
-		resTransactions, totalCount, err := service.GetTransactionHistory(ctx, walletID, -5, -10) // Invalid limit/offset
+		_, err := service.SetUserTimezone(ctx, 8, "UTC")
+
+		assert.ErrorIs(t, err, util.ErrUserNotFound)
+	})
+}
+
+func TestWaitForTransaction(t *testing.T) {
+	publicID := uuid.New()
+	newService := func(mockTransactionRepo *MockTransactionRepository, mockDBExecutor *MockDBExecutor, watcher *TransactionWatcher) WalletService {
+		return NewWalletService(new(MockDBBeginner), mockDBExecutor, new(MockUserRepository), new(MockWalletRepository), mockTransactionRepo,
+			nil, nil, nil, WithTransactionWatcher(watcher))
+	}
+
+	t.Run("CompletionEndsTheWait", func(t *testing.T) {
+		ctx := context.Background()
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBExecutor := new(MockDBExecutor)
+		watcher := NewTransactionWatcher()
+		service := newService(mockTransactionRepo, mockDBExecutor, watcher)
+		pending := &domain.Transaction{PublicID: publicID, Status: domain.TransactionStatusPending}
+		completed := &domain.Transaction{PublicID: publicID, Status: domain.TransactionStatusCompleted}
+		firstRead := make(chan struct{})
+		mockTransactionRepo.On("GetTransactionByPublicID", ctx, mockDBExecutor, publicID).Return(pending, nil).
+			Run(func(mock.Arguments) { close(firstRead) }).Once()
+		mockTransactionRepo.On("GetTransactionByPublicID", ctx, mockDBExecutor, publicID).Return(completed, nil).Once()
+
+		// The watch starts before the first read, so a completion published after it is seen
+		go func() {
+			<-firstRead
+			watcher.OnTransaction(ctx, completed)
+		}()
+		transaction, err := service.WaitForTransaction(ctx, publicID, 10*time.Second)
 
 		assert.NoError(t, err)
-		assert.NotNil(t, resTransactions)
-		assert.Equal(t, expectedTransactions, resTransactions)
-		assert.Equal(t, expectedTotalCount, totalCount)
+		assert.Equal(t, domain.TransactionStatusCompleted, transaction.Status)
+		mockTransactionRepo.AssertExpectations(t)
+	})
 
-		mockDBBeginner.AssertNotCalled(t, "BeginTxx", mock.Anything, mock.Anything)
-		mockTxController.AssertNotCalled(t, "Commit")
-		mockTxController.AssertNotCalled(t, "Rollback")
+	t.Run("TimeoutReturnsThePendingTransaction", func(t *testing.T) {
+		ctx := context.Background()
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBExecutor := new(MockDBExecutor)
+		service := newService(mockTransactionRepo, mockDBExecutor, NewTransactionWatcher())
+		pending := &domain.Transaction{PublicID: publicID, Status: domain.TransactionStatusPending}
+		mockTransactionRepo.On("GetTransactionByPublicID", ctx, mockDBExecutor, publicID).Return(pending, nil).Once()
 
-		mock.AssertExpectationsForObjects(t, mockDBBeginner, mockDBExecutor, mockTxController, mockUserRepo, mockWalletRepo, mockTransactionRepo)
+		transaction, err := service.WaitForTransaction(ctx, publicID, 10*time.Millisecond)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.TransactionStatusPending, transaction.Status)
+	})
+
+	t.Run("SettledTransactionReturnsAtOnce", func(t *testing.T) {
+		ctx := context.Background()
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockDBExecutor := new(MockDBExecutor)
+		service := newService(mockTransactionRepo, mockDBExecutor, NewTransactionWatcher())
+		failed := &domain.Transaction{PublicID: publicID, Status: domain.TransactionStatusFailed}
+		mockTransactionRepo.On("GetTransactionByPublicID", ctx, mockDBExecutor, publicID).Return(failed, nil).Once()
+
+		transaction, err := service.WaitForTransaction(ctx, publicID, time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, failed, transaction)
+	})
+}
+
+func TestAsyncTransfer(t *testing.T) {
+	source := domain.Wallet{ID: 1, UserID: 1, Currency: "USD", Balance: decimal.NewFromInt(100)}
+	destination := domain.Wallet{ID: 2, UserID: 2, Currency: "USD", Balance: decimal.NewFromInt(10)}
+	amount := decimal.NewFromInt(30)
+
+	type mocks struct {
+		walletRepo      *MockWalletRepository
+		transactionRepo *MockTransactionRepository
+		asyncRepo       *MockAsyncTransferRepository
+		dbExecutor      *MockDBExecutor
+		txController    *MockTxController
+	}
+	newService := func(watcher *TransactionWatcher) (WalletService, mocks) {
+		m := mocks{
+			walletRepo:      new(MockWalletRepository),
+			transactionRepo: new(MockTransactionRepository),
+			asyncRepo:       new(MockAsyncTransferRepository),
+			dbExecutor:      new(MockDBExecutor),
+			txController:    new(MockTxController),
+		}
+		m.txController.On("Rollback").Return(nil).Maybe()
+		service := NewWalletService(
+			new(MockDBBeginner), m.dbExecutor, new(MockUserRepository), m.walletRepo, m.transactionRepo,
+			func(ctx context.Context, dbConn db.DBTxBeginner) (db.TxController, error) { return m.txController, nil },
+			func(tx db.TxController) error { return m.txController.Commit() },
+			func(tx db.TxController) { _ = m.txController.Rollback() },
+			WithAsyncTransfers(m.asyncRepo, time.Second, time.Minute),
+			WithTransactionWatcher(watcher),
+		)
+		return service, m
+	}
+	pending := domain.AsyncTransfer{
+		ID: 5, TransactionID: 40, TransactionPublicID: uuid.New(), FromWalletID: 1, ToWalletID: 2,
+		Amount: amount, Currency: "USD", CreatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("SubmitRecordsAPendingTransfer", func(t *testing.T) {
+		ctx := WithBudgetOverride(WithTransactionCategory(context.Background(), "Rent"))
+		service, m := newService(nil)
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{1, 2}).Return([]domain.Wallet{source, destination}, nil).Once()
+		m.transactionRepo.On("CreateTransaction", ctx, m.txController, mock.MatchedBy(func(transaction *domain.Transaction) bool {
+			return transaction.Status == domain.TransactionStatusPending && transaction.Type == domain.TransactionTypeTransfer &&
+				*transaction.Category == "rent"
+		})).Run(func(args mock.Arguments) { args.Get(2).(*domain.Transaction).ID = 40 }).Return(nil).Once()
+		m.asyncRepo.On("CreateAsyncTransfer", ctx, m.txController, mock.MatchedBy(func(transfer *domain.AsyncTransfer) bool {
+			return transfer.TransactionID == 40 && transfer.OverrideBudget
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		transaction, err := service.SubmitTransfer(ctx, 1, 2, amount, "USD")
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.TransactionStatusPending, transaction.Status)
+		mock.AssertExpectationsForObjects(t, m.transactionRepo, m.asyncRepo, m.txController)
+		m.walletRepo.AssertNotCalled(t, "UpdateWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SubmitChecksTheCurrencies", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(nil)
+		euros := destination
+		euros.Currency = "EUR"
+		m.walletRepo.On("GetWalletsByIDs", ctx, m.txController, []int64{1, 2}).Return([]domain.Wallet{source, euros}, nil).Once()
+
+		_, err := service.SubmitTransfer(ctx, 1, 2, amount, "USD")
+
+		assert.ErrorIs(t, err, util.ErrCurrencyMismatch)
+		m.transactionRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("WorkerCompletesThePendingTransaction", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(nil)
+		m.asyncRepo.On("ListPendingAsyncTransfers", ctx, m.dbExecutor, mock.AnythingOfType("time.Time"), 10).Return([]domain.AsyncTransfer{pending}, nil).Once()
+		m.walletRepo.On("GetWalletsByIDs", mock.Anything, m.txController, []int64{1, 2}).Return([]domain.Wallet{source, destination}, nil).Once()
+		m.walletRepo.On("UpdateWalletBalances", mock.Anything, m.txController, map[int64]decimal.Decimal{1: amount.Neg(), 2: amount}, mock.Anything).
+			Return([]domain.Wallet{source, destination}, nil).Once()
+		m.asyncRepo.On("CompleteAsyncTransfer", mock.Anything, m.txController, mock.MatchedBy(func(transaction *domain.Transaction) bool {
+			return transaction.ID == 40 && transaction.PublicID == pending.TransactionPublicID && transaction.CreatedAt.Equal(pending.CreatedAt)
+		})).Return(nil).Once()
+		m.txController.On("Commit").Return(nil).Once()
+
+		processed, err := service.ProcessAsyncTransfers(ctx, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		mock.AssertExpectationsForObjects(t, m.walletRepo, m.asyncRepo, m.txController)
+		m.transactionRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("WorkerFailsATransferThatCannotBeMade", func(t *testing.T) {
+		ctx := context.Background()
+		watcher := NewTransactionWatcher()
+		updates, stop := watcher.Watch(pending.TransactionPublicID)
+		defer stop()
+		service, m := newService(watcher)
+		poor := source
+		poor.Balance = decimal.NewFromInt(5)
+		m.asyncRepo.On("ListPendingAsyncTransfers", ctx, m.dbExecutor, mock.AnythingOfType("time.Time"), 10).Return([]domain.AsyncTransfer{pending}, nil).Once()
+		m.walletRepo.On("GetWalletsByIDs", mock.Anything, m.txController, []int64{1, 2}).Return([]domain.Wallet{poor, destination}, nil).Once()
+		m.asyncRepo.On("FailAsyncTransfer", ctx, m.dbExecutor, int64(40), "insufficient_funds", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		processed, err := service.ProcessAsyncTransfers(ctx, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		m.asyncRepo.AssertExpectations(t)
+		select {
+		case transaction := <-updates:
+			assert.Equal(t, domain.TransactionStatusFailed, transaction.Status)
+		default:
+			t.Fatal("the watcher was not told of the failure")
+		}
+	})
+
+	t.Run("ConcurrentUpdateIsRetried", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(nil)
+		m.asyncRepo.On("ListPendingAsyncTransfers", ctx, m.dbExecutor, mock.AnythingOfType("time.Time"), 10).Return([]domain.AsyncTransfer{pending}, nil).Once()
+		m.walletRepo.On("GetWalletsByIDs", mock.Anything, m.txController, []int64{1, 2}).Return(nil, util.ErrConcurrentUpdate).Once()
+
+		processed, err := service.ProcessAsyncTransfers(ctx, 10)
+
+		assert.ErrorIs(t, err, util.ErrConcurrentUpdate)
+		assert.Zero(t, processed)
+		m.asyncRepo.AssertNotCalled(t, "FailAsyncTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("InfrastructureErrorIsRetriedWithBackoff", func(t *testing.T) {
+		ctx := context.Background()
+		service, m := newService(nil)
+		retried := pending
+		retried.Attempts = 2
+		dropped := errors.New("driver: bad connection")
+		m.asyncRepo.On("ListPendingAsyncTransfers", ctx, m.dbExecutor, mock.AnythingOfType("time.Time"), 10).Return([]domain.AsyncTransfer{retried}, nil).Once()
+		m.walletRepo.On("GetWalletsByIDs", mock.Anything, m.txController, []int64{1, 2}).Return(nil, dropped).Once()
+		var nextAttemptAt, at time.Time
+		m.asyncRepo.On("RetryAsyncTransfer", ctx, m.dbExecutor, int64(40), mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) { nextAttemptAt, at = args.Get(3).(time.Time), args.Get(4).(time.Time) }).Return(nil).Once()
+
+		processed, err := service.ProcessAsyncTransfers(ctx, 10)
+
+		// The third attempt failed, so the next one waits four times the initial backoff
+		assert.ErrorIs(t, err, dropped)
+		assert.Zero(t, processed)
+		assert.Equal(t, 4*time.Second, nextAttemptAt.Sub(at))
+		m.asyncRepo.AssertNotCalled(t, "FailAsyncTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("BackoffIsCapped", func(t *testing.T) {
+		backoff := asyncTransferBackoff{initial: time.Second, max: time.Minute}
+
+		assert.Equal(t, time.Second, backoff.delay(1))
+		assert.Equal(t, 2*time.Second, backoff.delay(2))
+		assert.Equal(t, time.Minute, backoff.delay(100))
 	})
 }
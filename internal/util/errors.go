@@ -1,20 +1,120 @@
 // internal/util/errors.go
 package util
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Common application-specific errors.
 var (
-	ErrNotFound           = errors.New("resource not found")
-	ErrInvalidInput       = errors.New("invalid input provided")
-	ErrInsufficientFunds  = errors.New("insufficient funds")
-	ErrSameWalletTransfer = errors.New("cannot transfer to the same wallet")
-	ErrWalletNotFound     = errors.New("wallet not found")
-	ErrUserNotFound       = errors.New("user not found")
-	ErrDuplicateEntry     = errors.New("duplicate entry") // For cases like creating a user with existing username
-	ErrCurrencyMismatch   = errors.New("wallet currency mismatch")
+	ErrNotFound                = errors.New("resource not found")
+	ErrInvalidInput            = errors.New("invalid input provided")
+	ErrInsufficientFunds       = errors.New("insufficient funds")
+	ErrSameWalletTransfer      = errors.New("cannot transfer to the same wallet")
+	ErrWalletNotFound          = errors.New("wallet not found")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrDuplicateEntry          = errors.New("duplicate entry") // For cases like creating a user with existing username
+	ErrCurrencyMismatch        = errors.New("wallet currency mismatch")
+	ErrCurrencyUnsupported     = errors.New("currency is not supported") // Not an ISO 4217 code in the currency registry
+	ErrPreconditionFailed      = errors.New("precondition failed")       // If-Match did not match the current resource version
+	ErrFXRateUnavailable       = errors.New("no exchange rate for currency pair")
+	ErrFXRateStale             = errors.New("exchange rate is stale")  // Older than the configured max rate age
+	ErrForbidden               = errors.New("operation not permitted") // Denied by a wallet role or the authorization policy
+	ErrApprovalRequired        = errors.New("transfer requires approval")
+	ErrApprovalNotPending      = errors.New("transfer approval is no longer pending")
+	ErrBudgetExceeded          = errors.New("spending budget exceeded") // A SOFT_BLOCK budget would be exceeded
+	ErrNotMerchantWallet       = errors.New("wallet is not a merchant wallet")
+	ErrChargeNotPending        = errors.New("charge is no longer pending") // Already paid, cancelled or expired
+	ErrBillNotOpen             = errors.New("bill is no longer open")      // Settled or cancelled
+	ErrBillNotApproved         = errors.New("bill share is not approved")  // A participant pays only after approving their share
+	ErrVoucherNotRedeemable    = errors.New("voucher is already redeemed or expired")
+	ErrWalletNotEmpty          = errors.New("wallet balance is not zero") // Only empty wallets can be deleted
+	ErrQuoteNotUsable          = errors.New("transfer quote is unknown, expired or already used")
+	ErrDuplicateTransfer       = errors.New("transfer repeats a recent transfer") // Same wallets and amount within the duplicate window
+	ErrFundsNotSuspended       = errors.New("inbound funds not in suspense")      // Credited directly or already reassigned
+	ErrAdjustmentNotPending    = errors.New("adjustment is no longer pending")
+	ErrScreeningHit            = errors.New("party matches the screening denylist")
+	ErrScreeningCaseClosed     = errors.New("screening case is already resolved")
+	ErrCurrencyRestricted      = errors.New("currency is not available in the user's country of residence")
+	ErrPINRequired             = errors.New("transaction PIN required")
+	ErrInvalidPIN              = errors.New("invalid transaction PIN")
+	ErrPINLocked               = errors.New("transaction PIN locked after too many failed attempts")
+	ErrInvalidVerificationCode = errors.New("verification code is wrong, expired or used up")
+	ErrVerificationThrottled   = errors.New("a verification code was sent too recently") // Resending is rate limited
+	ErrContactInUse            = errors.New("email or phone is already verified by another user")
+	ErrWalletFrozen            = errors.New("wallet is frozen after a period of inactivity") // Lifted by reactivating the wallet
+	ErrVerificationRequired    = errors.New("contact must be verified again")                // No email or phone verification since the wallet went dormant
+	ErrRequestQuotaExceeded    = errors.New("monthly request quota exceeded")
+	ErrVolumeQuotaExceeded     = errors.New("monthly money movement quota exceeded")         // The amount would take the partner over its quota
+	ErrAmountPrecision         = errors.New("amount has more decimal places than supported") // More than the deployment's AMOUNT_SCALE
+	ErrRampLiquidity           = errors.New("not enough stablecoin liquidity for the ramp")  // The hot wallet cannot back the credit
+	ErrConsentInvalid          = errors.New("consent does not grant the access")             // Not authorised, expired, or lacking the permission
+	ErrConsentNotPending       = errors.New("consent is no longer awaiting authorisation")
+	ErrInvalidIDToken          = errors.New("invalid ID token")                           // Bad signature, issuer, audience or lifetime
+	ErrIdentityLinked          = errors.New("identity is already linked")                 // To another user, or the user has one at the provider
+	ErrUserDeactivated         = errors.New("user is deactivated")                        // By the tenant that provisioned them
+	ErrOwnershipTransferFrozen = errors.New("wallet is frozen for an ownership transfer") // Lifted when the transfer is accepted, declined, cancelled or expires
+	ErrOwnershipNotPending     = errors.New("ownership transfer is no longer pending")
+
+	// Push service outcomes, reported by the push senders
+	ErrPushTokenInvalid = errors.New("device token is not registered with the push service") // The app was uninstalled or the token rotated
+	ErrPushRejected     = errors.New("notification rejected by the push service")            // Retrying would not help
+
+	// Database constraint errors, translated from SQLSTATE codes by the repositories
+	ErrReferenceViolation  = errors.New("referenced resource does not exist or is still referenced") // foreign_key_violation
+	ErrConstraintViolation = errors.New("data constraint violated")                                  // check_violation, restrict_violation
+	ErrConcurrentUpdate    = errors.New("concurrent update conflict")                                // serialization_failure, deadlock_detected
 )
 
 func IsError(err error, target error) bool {
 	return errors.Is(err, target)
 }
+
+// DuplicateEntryError reports a create request that collides with an existing resource.
+// It wraps ErrDuplicateEntry and carries the ID of the existing resource, if known.
+type DuplicateEntryError struct {
+	Resource   string
+	ExistingID int64
+}
+
+func (e *DuplicateEntryError) Error() string {
+	return fmt.Sprintf("%s already exists: %s", e.Resource, ErrDuplicateEntry)
+}
+
+func (e *DuplicateEntryError) Unwrap() error {
+	return ErrDuplicateEntry
+}
+
+// DuplicateTransferError reports a transfer that most likely repeats a recent one by accident.
+// It wraps ErrDuplicateTransfer and carries the earlier transfer, so the client can show it to the user.
+type DuplicateTransferError struct {
+	ExistingID uuid.UUID // Public ID of the earlier transaction
+	CreatedAt  time.Time
+}
+
+func (e *DuplicateTransferError) Error() string {
+	return fmt.Sprintf("%s: transaction %s", ErrDuplicateTransfer, e.ExistingID)
+}
+
+func (e *DuplicateTransferError) Unwrap() error {
+	return ErrDuplicateTransfer
+}
+
+// AmountPrecisionError reports an amount with more decimal places than the deployment accepts.
+// It wraps ErrAmountPrecision and carries both numbers, so the client can round the amount itself.
+type AmountPrecisionError struct {
+	Places    int32 // Decimal places of the amount sent
+	MaxPlaces int32 // Decimal places the deployment accepts
+}
+
+func (e *AmountPrecisionError) Error() string {
+	return fmt.Sprintf("%s: %d decimal places, at most %d", ErrAmountPrecision, e.Places, e.MaxPlaces)
+}
+
+func (e *AmountPrecisionError) Unwrap() error {
+	return ErrAmountPrecision
+}
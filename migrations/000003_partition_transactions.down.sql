@@ -0,0 +1,40 @@
+-- 000003_partition_transactions.down.sql
+-- Restores a plain transactions table, folding archived rows back in.
+
+ALTER SEQUENCE transactions_id_seq OWNED BY NONE;
+ALTER TABLE transactions RENAME TO transactions_partitioned;
+DROP INDEX IF EXISTS idx_transactions_from_wallet_id;
+DROP INDEX IF EXISTS idx_transactions_to_wallet_id;
+DROP INDEX IF EXISTS idx_transactions_transaction_time;
+DROP INDEX IF EXISTS idx_transactions_created_at;
+
+CREATE TABLE transactions (
+    id BIGINT PRIMARY KEY DEFAULT nextval('transactions_id_seq'),
+    from_wallet_id BIGINT REFERENCES wallets(id),
+    to_wallet_id BIGINT REFERENCES wallets(id),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'COMPLETED',
+    transaction_time TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (from_wallet_id IS NOT NULL OR to_wallet_id IS NOT NULL),
+    CHECK (from_wallet_id IS NULL OR to_wallet_id IS NULL OR from_wallet_id <> to_wallet_id)
+);
+
+ALTER SEQUENCE transactions_id_seq OWNED BY transactions.id;
+
+INSERT INTO transactions (id, from_wallet_id, to_wallet_id, amount, currency, type, status, transaction_time, description, created_at)
+SELECT id, from_wallet_id, to_wallet_id, amount, currency, type, status, transaction_time, description, created_at
+FROM transactions_partitioned
+UNION ALL
+SELECT id, from_wallet_id, to_wallet_id, amount, currency, type, status, transaction_time, description, created_at
+FROM transactions_archive;
+
+DROP TABLE transactions_partitioned;
+DROP TABLE transactions_archive;
+
+CREATE INDEX idx_transactions_from_wallet_id ON transactions (from_wallet_id);
+CREATE INDEX idx_transactions_to_wallet_id ON transactions (to_wallet_id);
+CREATE INDEX idx_transactions_transaction_time ON transactions (transaction_time DESC);
@@ -0,0 +1,85 @@
+-- 000003_partition_transactions.up.sql
+-- Converts transactions into a table partitioned by month on created_at,
+-- and adds transactions_archive for rows moved out by the archival job.
+
+-- Keep the id sequence alive while the old table is replaced
+ALTER SEQUENCE transactions_id_seq OWNED BY NONE;
+ALTER TABLE transactions RENAME TO transactions_legacy;
+DROP INDEX IF EXISTS idx_transactions_from_wallet_id;
+DROP INDEX IF EXISTS idx_transactions_to_wallet_id;
+DROP INDEX IF EXISTS idx_transactions_transaction_time;
+
+-- Table: transactions (partitioned)
+-- The partition key must be part of the primary key, hence (id, created_at).
+CREATE TABLE transactions (
+    id BIGINT NOT NULL DEFAULT nextval('transactions_id_seq'),
+    from_wallet_id BIGINT REFERENCES wallets(id),
+    to_wallet_id BIGINT REFERENCES wallets(id),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'COMPLETED',
+    transaction_time TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (from_wallet_id IS NOT NULL OR to_wallet_id IS NOT NULL),
+    CHECK (from_wallet_id IS NULL OR to_wallet_id IS NULL OR from_wallet_id <> to_wallet_id),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+ALTER SEQUENCE transactions_id_seq OWNED BY transactions.id;
+
+-- Catch-all partition for rows outside any monthly partition
+CREATE TABLE transactions_default PARTITION OF transactions DEFAULT;
+
+-- Monthly partitions (transactions_pYYYYMM) covering existing data through next month.
+-- The archival job keeps creating partitions ahead of time from here on.
+DO $$
+DECLARE
+    month_start DATE;
+    last_month DATE := date_trunc('month', NOW() + INTERVAL '1 month')::DATE;
+BEGIN
+    SELECT date_trunc('month', COALESCE(MIN(created_at), NOW()))::DATE INTO month_start FROM transactions_legacy;
+    WHILE month_start <= last_month LOOP
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF transactions FOR VALUES FROM (%L) TO (%L)',
+            'transactions_p' || to_char(month_start, 'YYYYMM'),
+            month_start,
+            (month_start + INTERVAL '1 month')::DATE
+        );
+        month_start := (month_start + INTERVAL '1 month')::DATE;
+    END LOOP;
+END $$;
+
+INSERT INTO transactions (id, from_wallet_id, to_wallet_id, amount, currency, type, status, transaction_time, description, created_at)
+SELECT id, from_wallet_id, to_wallet_id, amount, currency, type, status, transaction_time, description, created_at
+FROM transactions_legacy;
+
+DROP TABLE transactions_legacy;
+
+-- Indexes are created on the parent and propagated to every partition
+CREATE INDEX idx_transactions_from_wallet_id ON transactions (from_wallet_id);
+CREATE INDEX idx_transactions_to_wallet_id ON transactions (to_wallet_id);
+CREATE INDEX idx_transactions_transaction_time ON transactions (transaction_time DESC);
+CREATE INDEX idx_transactions_created_at ON transactions (created_at DESC);
+
+-- Table: transactions_archive
+-- Cold storage for partitions older than the retention horizon.
+-- No foreign keys, so archived history survives wallet clean-ups.
+CREATE TABLE transactions_archive (
+    id BIGINT PRIMARY KEY,
+    from_wallet_id BIGINT,
+    to_wallet_id BIGINT,
+    amount NUMERIC(20, 4) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    transaction_time TIMESTAMPTZ NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transactions_archive_from_wallet_id ON transactions_archive (from_wallet_id);
+CREATE INDEX idx_transactions_archive_to_wallet_id ON transactions_archive (to_wallet_id);
+CREATE INDEX idx_transactions_archive_created_at ON transactions_archive (created_at DESC);
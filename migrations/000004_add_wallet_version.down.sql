@@ -0,0 +1,2 @@
+-- 000004_add_wallet_version.down.sql
+ALTER TABLE wallets DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- 000004_add_wallet_version.up.sql
+-- Monotonic version counter, bumped on every balance change.
+-- Used for ETag generation and If-Match preconditions on wallet endpoints.
+ALTER TABLE wallets ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- 000005_unique_usernames.down.sql
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+CREATE INDEX idx_users_username ON users (username);
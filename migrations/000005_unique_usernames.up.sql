@@ -0,0 +1,4 @@
+-- 000005_unique_usernames.up.sql
+-- Enforce username uniqueness in the database so concurrent sign-ups cannot both succeed.
+DROP INDEX IF EXISTS idx_users_username;
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
@@ -0,0 +1,9 @@
+-- 000006_add_public_ids.down.sql
+DROP INDEX IF EXISTS idx_transactions_archive_public_id;
+ALTER TABLE transactions_archive DROP COLUMN IF EXISTS public_id;
+
+DROP INDEX IF EXISTS idx_transactions_public_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS public_id;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_public_id_key;
+ALTER TABLE wallets DROP COLUMN IF EXISTS public_id;
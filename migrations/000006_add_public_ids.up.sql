@@ -0,0 +1,12 @@
+-- 000006_add_public_ids.up.sql
+-- Public UUID identifiers for wallets and transactions. The API only exposes these;
+-- BIGSERIAL ids stay internal so wallet and transaction ids cannot be enumerated.
+ALTER TABLE wallets ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE wallets ADD CONSTRAINT wallets_public_id_key UNIQUE (public_id);
+
+ALTER TABLE transactions ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid();
+-- Unique indexes on a partitioned table must include the partition key
+CREATE UNIQUE INDEX idx_transactions_public_id ON transactions (public_id, created_at);
+
+ALTER TABLE transactions_archive ADD COLUMN public_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX idx_transactions_archive_public_id ON transactions_archive (public_id);
@@ -0,0 +1,2 @@
+-- 000007_create_feature_flags.down.sql
+DROP TABLE IF EXISTS feature_flags;
@@ -0,0 +1,13 @@
+-- 000007_create_feature_flags.up.sql
+-- Table: feature_flags
+-- Runtime feature switches, evaluated per user: on for everyone, for an explicit cohort, or for a rollout percentage.
+CREATE TABLE feature_flags (
+    key VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,           -- On for every user
+    user_ids BIGINT[] NOT NULL DEFAULT '{}',           -- Cohort the flag is on for
+    rollout_percent SMALLINT NOT NULL DEFAULT 0 CHECK (rollout_percent BETWEEN 0 AND 100),
+    value TEXT NOT NULL DEFAULT '',                    -- Optional flag-specific setting, e.g. a limit
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+-- 000008_create_fx_rates.down.sql
+DROP TABLE IF EXISTS fx_rates;
@@ -0,0 +1,12 @@
+-- 000008_create_fx_rates.up.sql
+-- Table: fx_rates
+-- Latest exchange rate per currency pair, written by the rate feed loader and cached by the API.
+CREATE TABLE fx_rates (
+    base_currency VARCHAR(10) NOT NULL,
+    quote_currency VARCHAR(10) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0), -- Units of quote currency per unit of base currency
+    as_of TIMESTAMPTZ NOT NULL,                    -- When the provider published the rate
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency),
+    CHECK (base_currency <> quote_currency)
+);
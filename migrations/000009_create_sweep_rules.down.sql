@@ -0,0 +1,2 @@
+-- 000009_create_sweep_rules.down.sql
+DROP TABLE IF EXISTS sweep_rules;
@@ -0,0 +1,19 @@
+-- 000009_create_sweep_rules.up.sql
+-- Table: sweep_rules
+-- Standing instructions to move money between two wallets of the same user, evaluated after every transaction.
+CREATE TABLE sweep_rules (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('SWEEP', 'TOP_UP')),
+    source_wallet_id BIGINT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    target_wallet_id BIGINT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    threshold NUMERIC(20, 4) NOT NULL CHECK (threshold >= 0), -- SWEEP: keep at most this; TOP_UP: keep at least this
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (source_wallet_id <> target_wallet_id)
+);
+
+CREATE INDEX idx_sweep_rules_user_id ON sweep_rules (user_id);
+CREATE INDEX idx_sweep_rules_source_wallet_id ON sweep_rules (source_wallet_id);
+CREATE INDEX idx_sweep_rules_target_wallet_id ON sweep_rules (target_wallet_id);
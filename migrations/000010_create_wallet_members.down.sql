@@ -0,0 +1,5 @@
+-- 000010_create_wallet_members.down.sql
+DROP TABLE IF EXISTS transfer_approval_votes;
+DROP TABLE IF EXISTS transfer_approvals;
+DROP TABLE IF EXISTS wallet_approval_policies;
+DROP TABLE IF EXISTS wallet_members;
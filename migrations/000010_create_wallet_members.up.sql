@@ -0,0 +1,44 @@
+-- 000010_create_wallet_members.up.sql
+-- Joint wallets: extra members of a wallet besides its creator (wallets.user_id, always an owner),
+-- an optional approval policy per wallet, and transfers held back until enough owners approve them.
+CREATE TABLE wallet_members (
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('OWNER', 'SPENDER', 'VIEWER')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (wallet_id, user_id)
+);
+
+CREATE INDEX idx_wallet_members_user_id ON wallet_members (user_id);
+
+CREATE TABLE wallet_approval_policies (
+    wallet_id BIGINT PRIMARY KEY REFERENCES wallets(id) ON DELETE CASCADE,
+    threshold NUMERIC(20, 4) NOT NULL CHECK (threshold >= 0), -- Transfers above this need approval
+    required_approvals SMALLINT NOT NULL CHECK (required_approvals >= 1),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE transfer_approvals (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    from_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    to_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    requested_by BIGINT REFERENCES users(id),
+    required_approvals SMALLINT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPROVED', 'EXECUTED', 'REJECTED', 'FAILED')),
+    transaction_id UUID,  -- Public ID of the executed transfer
+    failure_reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transfer_approvals_from_wallet_id ON transfer_approvals (from_wallet_id, status);
+
+CREATE TABLE transfer_approval_votes (
+    approval_id BIGINT NOT NULL REFERENCES transfer_approvals(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (approval_id, user_id) -- One approval per owner
+);
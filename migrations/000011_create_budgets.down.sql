@@ -0,0 +1,4 @@
+-- 000011_create_budgets.down.sql
+DROP TABLE IF EXISTS budgets;
+ALTER TABLE transactions_archive DROP COLUMN IF EXISTS category;
+ALTER TABLE transactions DROP COLUMN IF EXISTS category;
@@ -0,0 +1,17 @@
+-- 000011_create_budgets.up.sql
+-- Optional spending category on transactions, and per-wallet budgets over weekly or monthly periods.
+ALTER TABLE transactions ADD COLUMN category VARCHAR(50);
+ALTER TABLE transactions_archive ADD COLUMN category VARCHAR(50);
+
+CREATE TABLE budgets (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    category VARCHAR(50),                        -- NULL budgets all spending from the wallet
+    period VARCHAR(10) NOT NULL CHECK (period IN ('WEEKLY', 'MONTHLY')),
+    amount_limit NUMERIC(20, 4) NOT NULL CHECK (amount_limit > 0),
+    enforcement VARCHAR(10) NOT NULL DEFAULT 'NONE' CHECK (enforcement IN ('NONE', 'ALERT', 'SOFT_BLOCK')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_budgets_wallet_id ON budgets (wallet_id);
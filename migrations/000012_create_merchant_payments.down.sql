@@ -0,0 +1,5 @@
+-- 000012_create_merchant_payments.down.sql
+DROP TABLE IF EXISTS charges;
+DROP TABLE IF EXISTS settlements;
+DROP TABLE IF EXISTS merchant_settings;
+ALTER TABLE wallets DROP COLUMN IF EXISTS kind;
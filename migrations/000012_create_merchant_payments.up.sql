@@ -0,0 +1,47 @@
+-- 000012_create_merchant_payments.up.sql
+-- Merchant wallets: a wallet kind, settlement preferences per merchant wallet, charge requests paid by
+-- customers, and the daily settlements that pay a merchant's receipts out to its payout wallet.
+ALTER TABLE wallets ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT 'PERSONAL' CHECK (kind IN ('PERSONAL', 'MERCHANT'));
+
+CREATE TABLE merchant_settings (
+    wallet_id BIGINT PRIMARY KEY REFERENCES wallets(id) ON DELETE CASCADE,
+    payout_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    min_payout_amount NUMERIC(20, 4) NOT NULL DEFAULT 0 CHECK (min_payout_amount >= 0), -- Smaller batches roll over to the next day
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (payout_wallet_id <> wallet_id)
+);
+
+CREATE TABLE settlements (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    merchant_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    payout_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    charge_count INT NOT NULL,
+    settlement_date DATE NOT NULL,  -- The business day (UTC) closed by this settlement
+    transaction_id UUID NOT NULL,   -- Public ID of the payout transaction
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (merchant_wallet_id, settlement_date) -- At most one batch per merchant and day
+);
+
+CREATE TABLE charges (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    merchant_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    description TEXT,
+    reference VARCHAR(100),         -- The merchant's own order reference
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'PAID', 'CANCELLED', 'EXPIRED')),
+    payer_wallet_id BIGINT REFERENCES wallets(id),
+    transaction_id UUID,            -- Public ID of the payment transaction
+    settlement_id BIGINT REFERENCES settlements(id),
+    expires_at TIMESTAMPTZ NOT NULL,
+    paid_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_charges_unsettled ON charges (merchant_wallet_id, paid_at) WHERE status = 'PAID' AND settlement_id IS NULL;
@@ -0,0 +1,3 @@
+-- 000013_add_transaction_parent.down.sql
+ALTER TABLE transactions_archive DROP COLUMN IF EXISTS parent_transaction_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS parent_transaction_id;
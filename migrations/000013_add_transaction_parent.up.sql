@@ -0,0 +1,4 @@
+-- 000013_add_transaction_parent.up.sql
+-- Split payments: the legs of a split transfer point at the SPLIT parent transaction by its public ID.
+ALTER TABLE transactions ADD COLUMN parent_transaction_id UUID;
+ALTER TABLE transactions_archive ADD COLUMN parent_transaction_id UUID;
@@ -0,0 +1,3 @@
+-- 000014_create_bills.down.sql
+DROP TABLE IF EXISTS bill_participants;
+DROP TABLE IF EXISTS bills;
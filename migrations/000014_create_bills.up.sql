@@ -0,0 +1,28 @@
+-- 000014_create_bills.up.sql
+-- Shared bills: one wallet is owed an amount split between participant wallets, each of which
+-- approves its share and pays it off, possibly in several payments.
+CREATE TABLE bills (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    owner_wallet_id BIGINT NOT NULL REFERENCES wallets(id), -- The wallet the participants pay
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    description TEXT,
+    status VARCHAR(10) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'SETTLED', 'CANCELLED')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE bill_participants (
+    bill_id BIGINT NOT NULL REFERENCES bills(id) ON DELETE CASCADE,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    share_amount NUMERIC(20, 4) NOT NULL CHECK (share_amount > 0),
+    paid_amount NUMERIC(20, 4) NOT NULL DEFAULT 0 CHECK (paid_amount >= 0 AND paid_amount <= share_amount),
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPROVED', 'PAID')),
+    approved_at TIMESTAMPTZ,
+    last_reminded_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (bill_id, wallet_id)
+);
+
+CREATE INDEX idx_bill_participants_wallet ON bill_participants (wallet_id);
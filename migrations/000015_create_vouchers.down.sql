@@ -0,0 +1,2 @@
+-- 000015_create_vouchers.down.sql
+DROP TABLE IF EXISTS vouchers;
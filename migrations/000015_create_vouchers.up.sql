@@ -0,0 +1,20 @@
+-- 000015_create_vouchers.up.sql
+-- Vouchers: prepaid codes issued by operators and redeemed once into a wallet. Only a hash of each code is stored.
+CREATE TABLE vouchers (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    code_hash CHAR(64) NOT NULL UNIQUE,  -- hex(sha256(normalized code))
+    code_hint VARCHAR(4) NOT NULL,       -- Last characters of the code, to tell vouchers apart
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'REDEEMED')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    redeemed_wallet_id BIGINT REFERENCES wallets(id),
+    transaction_id UUID,                 -- Public ID of the redemption transaction
+    redeemed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((status = 'REDEEMED') = (redeemed_at IS NOT NULL))
+);
+
+CREATE INDEX idx_vouchers_active ON vouchers (currency, expires_at) WHERE status = 'ACTIVE';
@@ -0,0 +1,4 @@
+-- 000016_create_promo_credits.down.sql
+ALTER TABLE transactions_archive DROP COLUMN IF EXISTS promo_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS promo_amount;
+DROP TABLE IF EXISTS promo_credits;
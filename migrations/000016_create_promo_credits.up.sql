@@ -0,0 +1,21 @@
+-- 000016_create_promo_credits.up.sql
+-- Promotional credits: a second balance bucket per wallet, made of grants that expire and are spent
+-- before the real balance. Transactions record how much of their amount the credits covered.
+CREATE TABLE promo_credits (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    remaining NUMERIC(20, 4) NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+    allow_withdrawal BOOLEAN NOT NULL DEFAULT FALSE, -- Whether the credit may fund withdrawals, not only transfers
+    reason TEXT,
+    status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'EXPIRED')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_promo_credits_spendable ON promo_credits (wallet_id, expires_at) WHERE status = 'ACTIVE' AND remaining > 0;
+
+ALTER TABLE transactions ADD COLUMN promo_amount NUMERIC(20, 4) NOT NULL DEFAULT 0;
+ALTER TABLE transactions_archive ADD COLUMN promo_amount NUMERIC(20, 4) NOT NULL DEFAULT 0;
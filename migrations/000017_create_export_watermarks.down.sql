@@ -0,0 +1,2 @@
+-- 000017_create_export_watermarks.down.sql
+DROP TABLE IF EXISTS export_watermarks;
@@ -0,0 +1,8 @@
+-- 000017_create_export_watermarks.up.sql
+-- Progress of the data warehouse export, one row per exported stream.
+CREATE TABLE export_watermarks (
+    stream VARCHAR(50) PRIMARY KEY,      -- e.g. 'transactions', 'wallets'
+    last_id BIGINT NOT NULL DEFAULT 0,   -- Highest row ID exported so far; 0 for snapshot streams
+    exported_at TIMESTAMPTZ NOT NULL,    -- When the stream was last exported
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+-- 000018_add_change_sequence.down.sql
+DROP TRIGGER IF EXISTS transactions_track_change ON transactions;
+DROP TRIGGER IF EXISTS wallets_track_change ON wallets;
+DROP FUNCTION IF EXISTS transactions_track_change();
+DROP FUNCTION IF EXISTS wallets_track_change();
+ALTER TABLE transactions DROP COLUMN IF EXISTS change_seq;
+ALTER TABLE wallets DROP COLUMN IF EXISTS change_seq;
+DROP SEQUENCE IF EXISTS change_seq;
@@ -0,0 +1,35 @@
+-- 000018_add_change_sequence.up.sql
+-- Change sequence for delta sync: every insert or update of a wallet or transaction takes the next value,
+-- so GET /changes can return what changed after a client's cursor.
+CREATE SEQUENCE change_seq;
+
+-- The volatile default numbers existing rows too
+ALTER TABLE wallets ADD COLUMN change_seq BIGINT NOT NULL DEFAULT nextval('change_seq');
+ALTER TABLE transactions ADD COLUMN change_seq BIGINT NOT NULL DEFAULT nextval('change_seq');
+
+CREATE INDEX idx_wallets_change_seq ON wallets (change_seq);
+CREATE INDEX idx_transactions_change_seq ON transactions (change_seq);
+
+-- Updates take a new sequence value. A wallet update that does not set updated_at itself (e.g. a manual fix)
+-- gets it set here, so updated_at always reflects the last change.
+CREATE FUNCTION wallets_track_change() RETURNS trigger AS $$
+BEGIN
+    NEW.change_seq := nextval('change_seq');
+    IF NEW.updated_at IS NOT DISTINCT FROM OLD.updated_at THEN
+        NEW.updated_at := clock_timestamp();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION transactions_track_change() RETURNS trigger AS $$
+BEGIN
+    NEW.change_seq := nextval('change_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER wallets_track_change BEFORE UPDATE ON wallets
+    FOR EACH ROW EXECUTE FUNCTION wallets_track_change();
+CREATE TRIGGER transactions_track_change BEFORE UPDATE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION transactions_track_change();
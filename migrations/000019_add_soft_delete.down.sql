@@ -0,0 +1,3 @@
+-- 000019_add_soft_delete.down.sql
+ALTER TABLE wallets DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,8 @@
+-- 000019_add_soft_delete.up.sql
+-- Soft delete for users and wallets: deleted rows are hidden from the API, can be restored by an operator
+-- and are purged once past the retention period.
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE wallets ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_wallets_deleted_at ON wallets (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- 000020_create_impersonation_sessions.down.sql
+DROP TABLE IF EXISTS impersonation_audit;
+DROP TABLE IF EXISTS impersonation_sessions;
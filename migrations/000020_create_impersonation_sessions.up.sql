@@ -0,0 +1,31 @@
+-- 000020_create_impersonation_sessions.up.sql
+-- Impersonation sessions let support operators act as a user, and every request made with one is audited.
+CREATE TABLE impersonation_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    token_hash CHAR(64) NOT NULL UNIQUE, -- hex(sha256(token)); the token itself is shown once
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    operator VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL,
+    allow_transfer BOOLEAN NOT NULL DEFAULT FALSE, -- One corrective transfer may be made
+    transfer_claimed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (allow_transfer OR transfer_claimed_at IS NULL)
+);
+
+CREATE INDEX idx_impersonation_sessions_user ON impersonation_sessions (user_id, created_at);
+
+CREATE TABLE impersonation_audit (
+    id BIGSERIAL PRIMARY KEY,
+    session_id BIGINT NOT NULL REFERENCES impersonation_sessions(id),
+    action VARCHAR(20) NOT NULL, -- READ, TRANSFER or DENIED
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    request_id VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_impersonation_audit_session ON impersonation_audit (session_id, id);
@@ -0,0 +1,2 @@
+-- 000021_create_transaction_annotations.down.sql
+DROP TABLE IF EXISTS transaction_annotations;
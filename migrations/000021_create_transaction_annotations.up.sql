@@ -0,0 +1,12 @@
+-- 000021_create_transaction_annotations.up.sql
+-- Internal support notes and case references on transactions, kept apart from the ledger and never shown to users.
+CREATE TABLE transaction_annotations (
+    transaction_id UUID PRIMARY KEY, -- Public ID; no foreign key, as transactions are partitioned and archived
+    note TEXT,
+    case_ids TEXT[] NOT NULL DEFAULT '{}',
+    updated_by VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transaction_annotations_case_ids ON transaction_annotations USING GIN (case_ids);
@@ -0,0 +1,2 @@
+-- 000022_create_wallet_exports.down.sql
+DROP TABLE IF EXISTS wallet_exports;
@@ -0,0 +1,19 @@
+-- 000022_create_wallet_exports.up.sql
+-- Full-history exports requested per wallet, queued here and written to the object store by a background worker.
+CREATE TABLE wallet_exports (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'RUNNING', 'COMPLETED', 'FAILED')),
+    total_rows BIGINT,                      -- Known once the worker starts
+    processed_rows BIGINT NOT NULL DEFAULT 0,
+    object_key TEXT,                        -- Set once COMPLETED
+    error TEXT,                             -- Set once FAILED
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_wallet_exports_pending ON wallet_exports (id) WHERE status = 'PENDING';
+CREATE INDEX idx_wallet_exports_wallet ON wallet_exports (wallet_id, created_at);
@@ -0,0 +1,6 @@
+-- 000023_add_user_timezones.down.sql
+DELETE FROM budgets WHERE period = 'DAILY';
+ALTER TABLE budgets DROP CONSTRAINT budgets_period_check;
+ALTER TABLE budgets ADD CONSTRAINT budgets_period_check CHECK (period IN ('WEEKLY', 'MONTHLY'));
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
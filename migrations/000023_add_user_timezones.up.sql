@@ -0,0 +1,6 @@
+-- 000023_add_user_timezones.up.sql
+-- Per-user IANA time zone for day boundaries, and daily budgets.
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+ALTER TABLE budgets DROP CONSTRAINT budgets_period_check;
+ALTER TABLE budgets ADD CONSTRAINT budgets_period_check CHECK (period IN ('DAILY', 'WEEKLY', 'MONTHLY'));
@@ -0,0 +1,2 @@
+-- 000024_create_transfer_quotes.down.sql
+DROP TABLE IF EXISTS transfer_quotes;
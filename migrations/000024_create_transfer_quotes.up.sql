@@ -0,0 +1,19 @@
+-- 000024_create_transfer_quotes.up.sql
+-- Short-lived transfer quotes. A transfer carrying a quote's ID claims it by setting used_at, once.
+CREATE TABLE transfer_quotes (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    from_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    to_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    fee NUMERIC(20, 4) NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    credit_amount NUMERIC(20, 4) NOT NULL CHECK (credit_amount > 0),
+    credit_currency VARCHAR(10) NOT NULL,
+    rate NUMERIC(20, 10),                   -- NULL for a same-currency transfer
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transfer_quotes_expires_at ON transfer_quotes (expires_at);
@@ -0,0 +1,2 @@
+-- 000025_add_wallet_export_locale.down.sql
+ALTER TABLE wallet_exports DROP COLUMN IF EXISTS locale;
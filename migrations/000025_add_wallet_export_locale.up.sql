@@ -0,0 +1,3 @@
+-- 000025_add_wallet_export_locale.up.sql
+-- Locale of the readable transaction descriptions written into a wallet export, negotiated when it was requested.
+ALTER TABLE wallet_exports ADD COLUMN locale VARCHAR(16) NOT NULL DEFAULT 'en';
@@ -0,0 +1,5 @@
+-- 000026_add_transaction_search_indexes.down.sql
+DROP INDEX IF EXISTS idx_transactions_archive_category_trgm;
+DROP INDEX IF EXISTS idx_transactions_archive_description_trgm;
+DROP INDEX IF EXISTS idx_transactions_category_trgm;
+DROP INDEX IF EXISTS idx_transactions_description_trgm;
@@ -0,0 +1,8 @@
+-- 000026_add_transaction_search_indexes.up.sql
+-- Trigram indexes serving the case-insensitive substring search of GET /wallets/{id}/transactions/search.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_transactions_description_trgm ON transactions USING GIN (description gin_trgm_ops);
+CREATE INDEX idx_transactions_category_trgm ON transactions USING GIN (category gin_trgm_ops);
+CREATE INDEX idx_transactions_archive_description_trgm ON transactions_archive USING GIN (description gin_trgm_ops);
+CREATE INDEX idx_transactions_archive_category_trgm ON transactions_archive USING GIN (category gin_trgm_ops);
@@ -0,0 +1,3 @@
+-- 000027_create_auto_top_ups.down.sql
+DROP TABLE IF EXISTS auto_top_up_rules;
+DROP TABLE IF EXISTS funding_sources;
@@ -0,0 +1,29 @@
+-- 000027_create_auto_top_ups.up.sql
+-- Table: funding_sources
+-- External payment methods linked to a wallet, referenced by the payments gateway's token only.
+CREATE TABLE funding_sources (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    reference VARCHAR(255) NOT NULL,
+    label VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (wallet_id, provider, reference)
+);
+
+-- Table: auto_top_up_rules
+-- At most one rule per wallet: below threshold, charge amount to the funding source and deposit it.
+CREATE TABLE auto_top_up_rules (
+    wallet_id BIGINT PRIMARY KEY REFERENCES wallets(id) ON DELETE CASCADE,
+    funding_source_id BIGINT NOT NULL REFERENCES funding_sources(id), -- A source in use cannot be unlinked
+    threshold NUMERIC(20, 4) NOT NULL CHECK (threshold >= 0),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    last_attempt_at TIMESTAMPTZ, -- Attempts are spaced by the cooldown
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_auto_top_up_rules_funding_source_id ON auto_top_up_rules (funding_source_id);
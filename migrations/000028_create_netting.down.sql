@@ -0,0 +1,3 @@
+-- 000028_create_netting.down.sql
+DROP TABLE IF EXISTS netting_instructions;
+DROP TABLE IF EXISTS netting_settlements;
@@ -0,0 +1,38 @@
+-- 000028_create_netting.up.sql
+-- Netting: transfer instructions accumulated between two wallets during a window, and the single net
+-- transfer that settles them when the window closes.
+CREATE TABLE netting_settlements (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    from_wallet_id BIGINT NOT NULL REFERENCES wallets(id), -- The net payer
+    to_wallet_id BIGINT NOT NULL REFERENCES wallets(id),   -- The net payee
+    net_amount NUMERIC(20, 4) NOT NULL CHECK (net_amount >= 0), -- Zero if the flows cancelled out
+    gross_amount NUMERIC(20, 4) NOT NULL CHECK (gross_amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    instruction_count INT NOT NULL,
+    transaction_id UUID,            -- Public ID of the NETTING transaction; NULL if net_amount is zero
+    window_end TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (to_wallet_id <> from_wallet_id)
+);
+
+CREATE TABLE netting_instructions (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    from_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    to_wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    reference VARCHAR(100),         -- The submitter's own reference
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'SETTLED')),
+    settlement_id BIGINT REFERENCES netting_settlements(id),
+    settled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (to_wallet_id <> from_wallet_id)
+);
+
+-- Pending instructions of a pair, whichever way they flow
+CREATE INDEX idx_netting_instructions_pending ON netting_instructions
+    (LEAST(from_wallet_id, to_wallet_id), GREATEST(from_wallet_id, to_wallet_id), currency, created_at)
+    WHERE status = 'PENDING';
+CREATE INDEX idx_netting_instructions_settlement ON netting_instructions (settlement_id);
@@ -0,0 +1,6 @@
+-- 000029_create_suspense.down.sql
+DROP TABLE IF EXISTS inbound_funds;
+DELETE FROM wallets WHERE kind = 'SUSPENSE';
+DELETE FROM users WHERE username = 'finflow-platform';
+ALTER TABLE wallets DROP CONSTRAINT wallets_kind_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_kind_check CHECK (kind IN ('PERSONAL', 'MERCHANT'));
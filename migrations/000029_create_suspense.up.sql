@@ -0,0 +1,30 @@
+-- 000029_create_suspense.up.sql
+-- Suspense wallets: platform-owned wallets, one per currency, that hold inbound funds which could not be
+-- matched to a wallet until an operator reassigns them; and the record of every provider-reported receipt.
+ALTER TABLE wallets DROP CONSTRAINT wallets_kind_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_kind_check CHECK (kind IN ('PERSONAL', 'MERCHANT', 'SUSPENSE'));
+
+-- Owner of the system wallets; suspense wallets are created on first use
+INSERT INTO users (username) VALUES ('finflow-platform');
+
+CREATE TABLE inbound_funds (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    provider VARCHAR(100) NOT NULL,           -- Partner ID of the reporting provider
+    reference VARCHAR(100) NOT NULL,          -- The provider's ID of the payment
+    account_reference VARCHAR(100),           -- What the payer quoted to identify the wallet
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('CREDITED', 'SUSPENDED', 'REASSIGNED')),
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id),    -- Credited on receipt: the matched or the suspense wallet
+    transaction_id UUID NOT NULL,             -- Public ID of the DEPOSIT transaction
+    assigned_wallet_id BIGINT REFERENCES wallets(id),
+    adjustment_transaction_id UUID,           -- Public ID of the ADJUSTMENT transaction out of suspense
+    resolved_by VARCHAR(100),
+    resolution_reason TEXT,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, reference)              -- A redelivered webhook is recorded once
+);
+
+CREATE INDEX idx_inbound_funds_suspended ON inbound_funds (currency, created_at) WHERE status = 'SUSPENDED';
@@ -0,0 +1,2 @@
+-- 000030_create_adjustments.down.sql
+DROP TABLE IF EXISTS adjustments;
@@ -0,0 +1,23 @@
+-- 000030_create_adjustments.up.sql
+-- Manual wallet adjustments under maker-checker control: proposed by one admin, approved by another.
+CREATE TABLE adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('CREDIT', 'DEBIT')),
+    amount NUMERIC(20, 4) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(10) NOT NULL,
+    reason_code VARCHAR(20) NOT NULL,
+    note TEXT,
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'POSTED', 'REJECTED')),
+    proposed_by VARCHAR(100) NOT NULL,
+    decided_by VARCHAR(100),
+    decision_note TEXT,
+    transaction_id UUID,            -- Public ID of the ADJUSTMENT transaction once posted
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMPTZ,
+    CHECK (status <> 'POSTED' OR decided_by <> proposed_by) -- Four eyes: the approver is not the proposer
+);
+
+CREATE INDEX idx_adjustments_pending ON adjustments (created_at) WHERE status = 'PENDING';
+CREATE INDEX idx_adjustments_wallet ON adjustments (wallet_id, created_at);
@@ -0,0 +1,3 @@
+-- 000031_create_screening.down.sql
+DROP TABLE IF EXISTS screening_cases;
+DROP TABLE IF EXISTS denylist_entries;
@@ -0,0 +1,31 @@
+-- 000031_create_screening.up.sql
+-- Denylist screening: names that new users and new transfer counterparties are screened against, and the
+-- review cases opened for each hit.
+CREATE TABLE denylist_entries (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    normalized_name VARCHAR(200) NOT NULL UNIQUE, -- Lower-case letters and digits of the name
+    reason TEXT,
+    created_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE screening_cases (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    entry_id BIGINT NOT NULL REFERENCES denylist_entries(id), -- Entries with cases cannot be deleted
+    subject VARCHAR(200) NOT NULL,
+    normalized_name VARCHAR(200) NOT NULL,
+    event VARCHAR(20) NOT NULL CHECK (event IN ('USER_CREATION', 'NEW_COUNTERPARTY')),
+    user_id BIGINT REFERENCES users(id),
+    from_wallet_id BIGINT REFERENCES wallets(id),
+    to_wallet_id BIGINT REFERENCES wallets(id),
+    status VARCHAR(10) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'CLEARED', 'CONFIRMED')),
+    resolved_by VARCHAR(100),
+    resolution_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_screening_cases_party ON screening_cases (normalized_name, entry_id, created_at);
+CREATE INDEX idx_screening_cases_open ON screening_cases (created_at) WHERE status = 'OPEN';
@@ -0,0 +1,3 @@
+-- 000032_add_user_residency.down.sql
+DROP TABLE IF EXISTS currency_restrictions;
+ALTER TABLE users DROP COLUMN IF EXISTS residency;
@@ -0,0 +1,10 @@
+-- 000032_add_user_residency.up.sql
+-- Users' country of residence, and the countries whose residents may not open or fund wallets in a currency.
+ALTER TABLE users ADD COLUMN residency CHAR(2); -- ISO 3166-1 alpha-2; NULL while unknown
+
+CREATE TABLE currency_restrictions (
+    currency VARCHAR(10) PRIMARY KEY,
+    countries TEXT[] NOT NULL,       -- ISO 3166-1 alpha-2 codes of the restricted residencies
+    updated_by VARCHAR(100) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+-- 000033_create_auth_events.down.sql
+DROP TABLE IF EXISTS auth_events;
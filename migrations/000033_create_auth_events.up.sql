@@ -0,0 +1,17 @@
+-- 000033_create_auth_events.up.sql
+-- Authentication events of users, reported by the service that authenticates them, for the security
+-- activity of a user and the detection of logins from new devices.
+CREATE TABLE auth_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('LOGIN', 'LOGIN_FAILED', 'LOGOUT')),
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(512),
+    geo_hint VARCHAR(100),
+    device_hash CHAR(64), -- SHA-256 of the reported device ID, or else of the user agent
+    new_device BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_auth_events_user ON auth_events (user_id, created_at DESC);
+CREATE INDEX idx_auth_events_user_device ON auth_events (user_id, device_hash) WHERE type = 'LOGIN';
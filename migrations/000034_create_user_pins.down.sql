@@ -0,0 +1,2 @@
+-- 000034_create_user_pins.down.sql
+DROP TABLE IF EXISTS user_pins;
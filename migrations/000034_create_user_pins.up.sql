@@ -0,0 +1,11 @@
+-- 000034_create_user_pins.up.sql
+-- Optional transaction PINs confirming a user's withdrawals and transfers, with their lockout state.
+CREATE TABLE user_pins (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    pin_hash VARCHAR(200) NOT NULL, -- PBKDF2-SHA256, never the PIN itself
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    step_up_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,6 @@
+-- 000035_add_user_contact.down.sql
+DROP INDEX IF EXISTS idx_users_pii_key_id;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS pii_key_id,
+    DROP COLUMN IF EXISTS phone_ciphertext,
+    DROP COLUMN IF EXISTS email_ciphertext;
@@ -0,0 +1,9 @@
+-- 000035_add_user_contact.up.sql
+-- Email address and phone number of users, encrypted by the application. pii_key_id names the keyring key
+-- both were encrypted with, so rows still using a retired key can be found and re-encrypted.
+ALTER TABLE users
+    ADD COLUMN email_ciphertext TEXT,
+    ADD COLUMN phone_ciphertext TEXT,
+    ADD COLUMN pii_key_id VARCHAR(64);
+
+CREATE INDEX idx_users_pii_key_id ON users (pii_key_id) WHERE pii_key_id IS NOT NULL;
@@ -0,0 +1,11 @@
+-- 000036_add_contact_verification.down.sql
+DROP TABLE IF EXISTS contact_verifications;
+
+DROP INDEX IF EXISTS idx_users_verified_phone;
+DROP INDEX IF EXISTS idx_users_verified_email;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS phone_verified_at,
+    DROP COLUMN IF EXISTS email_verified_at,
+    DROP COLUMN IF EXISTS phone_hash,
+    DROP COLUMN IF EXISTS email_hash;
@@ -0,0 +1,26 @@
+-- 000036_add_contact_verification.up.sql
+-- Verification of users' email addresses and phone numbers. The *_hash columns are keyed blind indexes of the
+-- encrypted values; a verified address or number belongs to one live user at most.
+ALTER TABLE users
+    ADD COLUMN email_hash CHAR(64),
+    ADD COLUMN phone_hash CHAR(64),
+    ADD COLUMN email_verified_at TIMESTAMPTZ,
+    ADD COLUMN phone_verified_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX idx_users_verified_email ON users (email_hash)
+    WHERE email_verified_at IS NOT NULL AND deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_users_verified_phone ON users (phone_hash)
+    WHERE phone_verified_at IS NOT NULL AND deleted_at IS NULL;
+
+-- The pending verification code of each user and channel. target_hash is the blind index of the address or
+-- number the code was sent to, so changing it invalidates the code.
+CREATE TABLE contact_verifications (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('EMAIL', 'PHONE')),
+    target_hash CHAR(64) NOT NULL,
+    code_hash CHAR(64) NOT NULL, -- SHA-256 of the code, never the code itself
+    failed_attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, channel)
+);
@@ -0,0 +1,4 @@
+-- 000037_create_push_notifications.down.sql
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS device_tokens;
@@ -0,0 +1,45 @@
+-- 000037_create_push_notifications.up.sql
+-- Devices registered for push notifications, users' notification preferences, and the delivery of each
+-- notification to each device.
+CREATE TABLE device_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(10) NOT NULL CHECK (platform IN ('FCM', 'APNS')),
+    token TEXT NOT NULL,
+    label VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (platform, token) -- A device re-registered by another user moves to them
+);
+
+CREATE INDEX idx_device_tokens_user_id ON device_tokens (user_id);
+
+CREATE TABLE notification_preferences (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(20) NOT NULL,
+    channel VARCHAR(10) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, category, channel)
+);
+
+CREATE TABLE notification_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_token_id BIGINT REFERENCES device_tokens(id) ON DELETE SET NULL,
+    category VARCHAR(20) NOT NULL,
+    channel VARCHAR(10) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(10) NOT NULL CHECK (status IN ('PENDING', 'SENT', 'FAILED')),
+    attempts INT NOT NULL DEFAULT 0,
+    provider_message_id TEXT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notification_deliveries_pending ON notification_deliveries (id) WHERE status = 'PENDING';
+CREATE INDEX idx_notification_deliveries_user_id ON notification_deliveries (user_id, id DESC);
@@ -0,0 +1,3 @@
+-- 000038_create_notification_inbox.down.sql
+ALTER TABLE notification_deliveries DROP COLUMN IF EXISTS notification_id;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,18 @@
+-- 000038_create_notification_inbox.up.sql
+-- Every notification sent to a user, kept as their in-app inbox whatever channels delivered it.
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(20) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id, id DESC);
+CREATE INDEX idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+-- Deliveries queued before the inbox existed have no notification
+ALTER TABLE notification_deliveries ADD COLUMN notification_id BIGINT REFERENCES notifications(id) ON DELETE CASCADE;
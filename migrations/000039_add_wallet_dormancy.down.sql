@@ -0,0 +1,4 @@
+-- 000039_add_wallet_dormancy.down.sql
+ALTER TABLE wallets DROP COLUMN IF EXISTS frozen_at;
+ALTER TABLE wallets DROP COLUMN IF EXISTS dormant_since;
+ALTER TABLE wallets DROP COLUMN IF EXISTS last_activity_at;
@@ -0,0 +1,19 @@
+-- 000039_add_wallet_dormancy.up.sql
+-- Dormancy: every balance change records the wallet's last activity. A background job flags wallets without
+-- activity for the configured number of months as dormant and, if the policy says so, freezes them until the
+-- owner reactivates the wallet after verifying their contact again.
+ALTER TABLE wallets ADD COLUMN last_activity_at TIMESTAMPTZ;
+ALTER TABLE wallets ADD COLUMN dormant_since TIMESTAMPTZ;
+ALTER TABLE wallets ADD COLUMN frozen_at TIMESTAMPTZ;
+
+-- The last update is the best record of past activity. The backfill is not a change of the wallets, so the
+-- change feed trigger is bypassed.
+ALTER TABLE wallets DISABLE TRIGGER wallets_track_change;
+UPDATE wallets SET last_activity_at = updated_at;
+ALTER TABLE wallets ENABLE TRIGGER wallets_track_change;
+
+ALTER TABLE wallets ALTER COLUMN last_activity_at SET DEFAULT NOW();
+ALTER TABLE wallets ALTER COLUMN last_activity_at SET NOT NULL;
+
+CREATE INDEX idx_wallets_last_activity_at ON wallets (last_activity_at) WHERE dormant_since IS NULL AND deleted_at IS NULL;
+CREATE INDEX idx_wallets_dormant_since ON wallets (dormant_since) WHERE dormant_since IS NOT NULL;
@@ -0,0 +1,3 @@
+-- 000040_create_partner_usage.down.sql
+DROP TABLE IF EXISTS partner_volume;
+DROP TABLE IF EXISTS partner_usage;
@@ -0,0 +1,17 @@
+-- 000040_create_partner_usage.up.sql
+-- Monthly usage of each partner (integrator signing with a partner key): requests made, and money moved per
+-- currency, counted against the partner's quotas. Months are calendar months in UTC, keyed by their first day.
+CREATE TABLE partner_usage (
+    partner_id VARCHAR(100) NOT NULL,
+    month DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (partner_id, month)
+);
+
+CREATE TABLE partner_volume (
+    partner_id VARCHAR(100) NOT NULL,
+    month DATE NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    amount NUMERIC(20, 4) NOT NULL DEFAULT 0,
+    PRIMARY KEY (partner_id, month, currency)
+);
@@ -0,0 +1,2 @@
+-- 000041_create_wallet_serialization.down.sql
+DROP TABLE IF EXISTS wallet_serialization;
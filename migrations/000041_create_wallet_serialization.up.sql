@@ -0,0 +1,9 @@
+-- 000041_create_wallet_serialization.up.sql
+-- Serialization modes of heavily contended wallets, e.g. a merchant's settlement wallet. Wallets without a row
+-- rely on the row lock of their balance update alone.
+CREATE TABLE wallet_serialization (
+    wallet_id BIGINT PRIMARY KEY REFERENCES wallets (id) ON DELETE CASCADE,
+    mode VARCHAR(20) NOT NULL CHECK (mode IN ('QUEUE', 'ADVISORY_LOCK')),
+    updated_by VARCHAR(100) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+-- 000042_create_wallet_balance_shards.down.sql
+-- Fold the shards back into their wallets before dropping them, so no balance is lost.
+UPDATE wallets w SET balance = w.balance + s.balance, version = w.version + s.version
+FROM (SELECT wallet_id, SUM(balance) AS balance, SUM(version) AS version FROM wallet_balance_shards GROUP BY wallet_id) s
+WHERE w.id = s.wallet_id;
+
+DROP TABLE IF EXISTS wallet_balance_shards;
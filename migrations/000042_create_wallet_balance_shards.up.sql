@@ -0,0 +1,11 @@
+-- 000042_create_wallet_balance_shards.up.sql
+-- Sub-balances of wallets with more concurrent money movements than the row lock of one balance allows. The
+-- balance of a sharded wallet is its own balance plus those of its shards, and its version the sum of theirs.
+CREATE TABLE wallet_balance_shards (
+    wallet_id BIGINT NOT NULL REFERENCES wallets (id) ON DELETE CASCADE,
+    shard INT NOT NULL CHECK (shard >= 0),
+    balance NUMERIC(20, 4) NOT NULL DEFAULT 0.00,
+    version BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (wallet_id, shard)
+);
@@ -0,0 +1,3 @@
+-- 000043_create_async_transfers.down.sql
+DROP INDEX IF EXISTS idx_transactions_pending;
+DROP TABLE IF EXISTS async_transfers;
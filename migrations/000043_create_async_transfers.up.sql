@@ -0,0 +1,15 @@
+-- 000043_create_async_transfers.up.sql
+-- Transfers accepted with async=true. The transfer itself is a PENDING TRANSFER in transactions; this table holds
+-- the request options the worker applies when it makes the transfer, and why it failed if it did.
+CREATE TABLE async_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transactions (id) ON DELETE CASCADE,
+    override_budget BOOLEAN NOT NULL DEFAULT FALSE,
+    partner_id VARCHAR(100),
+    failure_reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The worker only looks for the transfers still pending
+CREATE INDEX idx_transactions_pending ON transactions (id) WHERE status = 'PENDING';
@@ -0,0 +1,2 @@
+-- 000044_create_schema_backfills.down.sql
+DROP TABLE IF EXISTS schema_backfills;
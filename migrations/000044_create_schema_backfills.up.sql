@@ -0,0 +1,13 @@
+-- 000044_create_schema_backfills.up.sql
+-- Progress of the out-of-band backfills of expand migrations, updated with each batch so a backfill resumes
+-- where it stopped after a restart.
+CREATE TABLE schema_backfills (
+    name VARCHAR(100) PRIMARY KEY,
+    last_key BIGINT NOT NULL DEFAULT 0,
+    rows_done BIGINT NOT NULL DEFAULT 0,
+    batches BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
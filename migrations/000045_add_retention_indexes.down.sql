@@ -0,0 +1,5 @@
+-- 000045_add_retention_indexes.down.sql
+DROP INDEX IF EXISTS idx_notifications_created_at;
+DROP INDEX IF EXISTS idx_notification_deliveries_created_at;
+DROP INDEX IF EXISTS idx_impersonation_audit_created_at;
+DROP INDEX IF EXISTS idx_auth_events_created_at;
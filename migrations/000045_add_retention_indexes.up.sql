@@ -0,0 +1,6 @@
+-- 000045_add_retention_indexes.up.sql
+-- Let the retention purge find the records past their retention period without scanning whole tables.
+CREATE INDEX idx_auth_events_created_at ON auth_events (created_at);
+CREATE INDEX idx_impersonation_audit_created_at ON impersonation_audit (created_at);
+CREATE INDEX idx_notification_deliveries_created_at ON notification_deliveries (created_at) WHERE status <> 'PENDING';
+CREATE INDEX idx_notifications_created_at ON notifications (created_at);
@@ -0,0 +1,2 @@
+-- 000046_add_feature_flag_rollout_by.down.sql
+ALTER TABLE feature_flags DROP COLUMN IF EXISTS rollout_by;
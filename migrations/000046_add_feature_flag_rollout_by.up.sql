@@ -0,0 +1,3 @@
+-- 000046_add_feature_flag_rollout_by.up.sql
+-- Percentage rollouts bucket by user ID, or by wallet ID for changes to money movement logic.
+ALTER TABLE feature_flags ADD COLUMN rollout_by VARCHAR(10) NOT NULL DEFAULT 'user' CHECK (rollout_by IN ('user', 'wallet'));
@@ -0,0 +1,10 @@
+-- 000047_add_transaction_reference_indexes.down.sql
+DROP INDEX IF EXISTS idx_transactions_parent_transaction_id;
+DROP INDEX IF EXISTS idx_adjustments_transaction_id;
+DROP INDEX IF EXISTS idx_inbound_funds_adjustment_transaction_id;
+DROP INDEX IF EXISTS idx_inbound_funds_transaction_id;
+DROP INDEX IF EXISTS idx_netting_settlements_transaction_id;
+DROP INDEX IF EXISTS idx_vouchers_transaction_id;
+DROP INDEX IF EXISTS idx_charges_transaction_id;
+DROP INDEX IF EXISTS idx_settlements_transaction_id;
+DROP INDEX IF EXISTS idx_transfer_approvals_transaction_id;
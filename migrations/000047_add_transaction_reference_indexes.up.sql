@@ -0,0 +1,11 @@
+-- 000047_add_transaction_reference_indexes.up.sql
+-- Let the admin investigation view find the records that reference a transaction by its public ID.
+CREATE INDEX idx_transfer_approvals_transaction_id ON transfer_approvals (transaction_id) WHERE transaction_id IS NOT NULL;
+CREATE INDEX idx_settlements_transaction_id ON settlements (transaction_id);
+CREATE INDEX idx_charges_transaction_id ON charges (transaction_id) WHERE transaction_id IS NOT NULL;
+CREATE INDEX idx_vouchers_transaction_id ON vouchers (transaction_id) WHERE transaction_id IS NOT NULL;
+CREATE INDEX idx_netting_settlements_transaction_id ON netting_settlements (transaction_id) WHERE transaction_id IS NOT NULL;
+CREATE INDEX idx_inbound_funds_transaction_id ON inbound_funds (transaction_id);
+CREATE INDEX idx_inbound_funds_adjustment_transaction_id ON inbound_funds (adjustment_transaction_id) WHERE adjustment_transaction_id IS NOT NULL;
+CREATE INDEX idx_adjustments_transaction_id ON adjustments (transaction_id) WHERE transaction_id IS NOT NULL;
+CREATE INDEX idx_transactions_parent_transaction_id ON transactions (parent_transaction_id) WHERE parent_transaction_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- 000048_create_audit_events.down.sql
+ALTER TABLE wallet_exports DROP COLUMN IF EXISTS audit_hash;
+ALTER TABLE wallet_exports DROP COLUMN IF EXISTS audit_sequence;
+DROP TABLE IF EXISTS audit_events;
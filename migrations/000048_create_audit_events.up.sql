@@ -0,0 +1,19 @@
+-- 000048_create_audit_events.up.sql
+-- The audit trail: structured events per wallet, each chained to the wallet's previous event. An event's hash
+-- covers its content and the previous event's hash, so changing or deleting an event breaks every later link.
+-- The payload is stored as text, not JSONB, because the hash covers its exact bytes.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id BIGINT NOT NULL REFERENCES wallets (id),
+    sequence BIGINT NOT NULL CHECK (sequence > 0),
+    event_type VARCHAR(40) NOT NULL,
+    payload TEXT NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (wallet_id, sequence)
+);
+
+-- The head of each wallet's chain when its export was written
+ALTER TABLE wallet_exports ADD COLUMN audit_sequence BIGINT;
+ALTER TABLE wallet_exports ADD COLUMN audit_hash CHAR(64);
@@ -0,0 +1,5 @@
+-- 000049_enforce_append_only_transactions.down.sql
+DROP TRIGGER IF EXISTS transactions_archive_append_only ON transactions_archive;
+DROP TRIGGER IF EXISTS transactions_append_only ON transactions;
+DROP FUNCTION IF EXISTS transactions_archive_append_only();
+DROP FUNCTION IF EXISTS transactions_append_only();
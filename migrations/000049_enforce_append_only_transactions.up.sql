@@ -0,0 +1,36 @@
+-- 000049_enforce_append_only_transactions.up.sql
+-- Transactions are append-only: a booked transaction is never changed or removed, and a correction is a new
+-- transaction booked against it (an ERROR_CORRECTION adjustment). The one change allowed is the settlement of a
+-- PENDING transaction, which sets its final status with the time, category and promotional amount it settled
+-- with. Rows still leave the hot table with their partition, which the archival job detaches and drops after
+-- copying it into transactions_archive; neither fires row triggers.
+CREATE FUNCTION transactions_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        RAISE EXCEPTION 'transaction % is append-only and cannot be deleted', OLD.id
+            USING ERRCODE = 'restrict_violation', HINT = 'Book a correcting transaction instead.';
+    END IF;
+    IF OLD.status <> 'PENDING' OR NEW.status NOT IN ('COMPLETED', 'FAILED')
+        OR to_jsonb(NEW) - '{status,transaction_time,category,promo_amount,change_seq}'::text[]
+            IS DISTINCT FROM to_jsonb(OLD) - '{status,transaction_time,category,promo_amount,change_seq}'::text[] THEN
+        RAISE EXCEPTION 'transaction % is append-only; only the settlement of a PENDING transaction can change it', OLD.id
+            USING ERRCODE = 'restrict_violation', HINT = 'Book a correcting transaction instead.';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Archived transactions are final; rows only arrive by the archival job's inserts
+CREATE FUNCTION transactions_archive_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'archived transaction % is append-only', OLD.id
+        USING ERRCODE = 'restrict_violation';
+END;
+$$ LANGUAGE plpgsql;
+
+-- Named to sort before transactions_track_change, so the check sees the row before change_seq moves on.
+-- Created on the partitioned table, the trigger applies to every partition, including those created later.
+CREATE TRIGGER transactions_append_only BEFORE UPDATE OR DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION transactions_append_only();
+CREATE TRIGGER transactions_archive_append_only BEFORE UPDATE OR DELETE ON transactions_archive
+    FOR EACH ROW EXECUTE FUNCTION transactions_archive_append_only();
@@ -0,0 +1,75 @@
+-- 000050_widen_amount_scale.down.sql
+-- Rounds amounts back to 4 decimal places.
+ALTER TABLE wallets
+    ALTER COLUMN balance TYPE NUMERIC(20, 4);
+
+ALTER TABLE wallet_balance_shards
+    ALTER COLUMN balance TYPE NUMERIC(20, 4);
+
+ALTER TABLE transactions
+    ALTER COLUMN amount TYPE NUMERIC(20, 4),
+    ALTER COLUMN promo_amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE transactions_archive
+    ALTER COLUMN amount TYPE NUMERIC(20, 4),
+    ALTER COLUMN promo_amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE sweep_rules
+    ALTER COLUMN threshold TYPE NUMERIC(20, 4);
+
+ALTER TABLE wallet_approval_policies
+    ALTER COLUMN threshold TYPE NUMERIC(20, 4);
+
+ALTER TABLE transfer_approvals
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE budgets
+    ALTER COLUMN amount_limit TYPE NUMERIC(20, 4);
+
+ALTER TABLE merchant_settings
+    ALTER COLUMN min_payout_amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE settlements
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE charges
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE bills
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE bill_participants
+    ALTER COLUMN share_amount TYPE NUMERIC(20, 4),
+    ALTER COLUMN paid_amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE vouchers
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE promo_credits
+    ALTER COLUMN amount TYPE NUMERIC(20, 4),
+    ALTER COLUMN remaining TYPE NUMERIC(20, 4);
+
+ALTER TABLE transfer_quotes
+    ALTER COLUMN amount TYPE NUMERIC(20, 4),
+    ALTER COLUMN fee TYPE NUMERIC(20, 4),
+    ALTER COLUMN credit_amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE auto_top_up_rules
+    ALTER COLUMN threshold TYPE NUMERIC(20, 4),
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE netting_settlements
+    ALTER COLUMN net_amount TYPE NUMERIC(20, 4),
+    ALTER COLUMN gross_amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE netting_instructions
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE inbound_funds
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE adjustments
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
+
+ALTER TABLE partner_volume
+    ALTER COLUMN amount TYPE NUMERIC(20, 4);
@@ -0,0 +1,80 @@
+-- 000050_widen_amount_scale.up.sql
+-- migrate:contract
+-- Stores amounts with 8 decimal places instead of 4, so a deployment can accept amounts down to the smallest
+-- unit of crypto assets such as BTC. AMOUNT_SCALE decides how many places a deployment accepts, up to the 8
+-- stored here. The integer part keeps its 16 digits: NUMERIC(20, 4) becomes NUMERIC(24, 8). Existing values
+-- keep their value. Widening is safe for running code, but retyping rewrites each table under an exclusive
+-- lock, so this is applied in a maintenance window like a contract step.
+ALTER TABLE wallets
+    ALTER COLUMN balance TYPE NUMERIC(24, 8);
+
+ALTER TABLE wallet_balance_shards
+    ALTER COLUMN balance TYPE NUMERIC(24, 8);
+
+ALTER TABLE transactions
+    ALTER COLUMN amount TYPE NUMERIC(24, 8),
+    ALTER COLUMN promo_amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE transactions_archive
+    ALTER COLUMN amount TYPE NUMERIC(24, 8),
+    ALTER COLUMN promo_amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE sweep_rules
+    ALTER COLUMN threshold TYPE NUMERIC(24, 8);
+
+ALTER TABLE wallet_approval_policies
+    ALTER COLUMN threshold TYPE NUMERIC(24, 8);
+
+ALTER TABLE transfer_approvals
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE budgets
+    ALTER COLUMN amount_limit TYPE NUMERIC(24, 8);
+
+ALTER TABLE merchant_settings
+    ALTER COLUMN min_payout_amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE settlements
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE charges
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE bills
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE bill_participants
+    ALTER COLUMN share_amount TYPE NUMERIC(24, 8),
+    ALTER COLUMN paid_amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE vouchers
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE promo_credits
+    ALTER COLUMN amount TYPE NUMERIC(24, 8),
+    ALTER COLUMN remaining TYPE NUMERIC(24, 8);
+
+ALTER TABLE transfer_quotes
+    ALTER COLUMN amount TYPE NUMERIC(24, 8),
+    ALTER COLUMN fee TYPE NUMERIC(24, 8),
+    ALTER COLUMN credit_amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE auto_top_up_rules
+    ALTER COLUMN threshold TYPE NUMERIC(24, 8),
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE netting_settlements
+    ALTER COLUMN net_amount TYPE NUMERIC(24, 8),
+    ALTER COLUMN gross_amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE netting_instructions
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE inbound_funds
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE adjustments
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
+
+ALTER TABLE partner_volume
+    ALTER COLUMN amount TYPE NUMERIC(24, 8);
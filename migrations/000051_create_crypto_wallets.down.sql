@@ -0,0 +1,8 @@
+-- 000051_create_crypto_wallets.down.sql
+DROP TABLE IF EXISTS crypto_payouts;
+DROP TABLE IF EXISTS crypto_deposits;
+DROP TABLE IF EXISTS crypto_addresses;
+-- Crypto wallets keep their balances as personal wallets
+UPDATE wallets SET kind = 'PERSONAL' WHERE kind = 'CRYPTO';
+ALTER TABLE wallets DROP CONSTRAINT wallets_kind_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_kind_check CHECK (kind IN ('PERSONAL', 'MERCHANT', 'SUSPENSE'));
@@ -0,0 +1,60 @@
+-- 000051_create_crypto_wallets.up.sql
+-- Crypto wallets: deposit addresses issued by the chain gateway, the on-chain deposits seen on them and the
+-- payouts of crypto withdrawals.
+ALTER TABLE wallets DROP CONSTRAINT wallets_kind_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_kind_check CHECK (kind IN ('PERSONAL', 'MERCHANT', 'SUSPENSE', 'CRYPTO'));
+
+CREATE TABLE crypto_addresses (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    asset VARCHAR(10) NOT NULL,
+    network VARCHAR(20) NOT NULL,
+    address VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (network, address) -- An address is never reassigned to another wallet
+);
+
+CREATE INDEX idx_crypto_addresses_wallet ON crypto_addresses (wallet_id, id);
+
+-- A deposit is one output of an on-chain transaction; it is credited once, however often the gateway reports it
+CREATE TABLE crypto_deposits (
+    id BIGSERIAL PRIMARY KEY,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    asset VARCHAR(10) NOT NULL,
+    network VARCHAR(20) NOT NULL,
+    address VARCHAR(128) NOT NULL,
+    tx_hash VARCHAR(128) NOT NULL,
+    output_index INT NOT NULL CHECK (output_index >= 0),
+    amount NUMERIC(24, 8) NOT NULL CHECK (amount > 0),
+    confirmations INT NOT NULL DEFAULT 0,
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'CREDITED')),
+    transaction_id UUID, -- Public ID of the DEPOSIT transaction once credited
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    credited_at TIMESTAMPTZ,
+    UNIQUE (network, tx_hash, output_index)
+);
+
+CREATE INDEX idx_crypto_deposits_wallet ON crypto_deposits (wallet_id, id);
+
+CREATE TABLE crypto_payouts (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id),
+    asset VARCHAR(10) NOT NULL,
+    network VARCHAR(20) NOT NULL,
+    address VARCHAR(128) NOT NULL,
+    amount NUMERIC(24, 8) NOT NULL CHECK (amount > 0),
+    fee NUMERIC(24, 8) NOT NULL CHECK (fee >= 0),
+    status VARCHAR(10) NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'SENDING', 'SENT', 'FAILED')),
+    transaction_id UUID NOT NULL UNIQUE, -- Public ID of the WITHDRAWAL debiting amount and fee
+    refund_transaction_id UUID,          -- Public ID of the DEPOSIT refunding a failed payout
+    chain_tx_hash VARCHAR(128),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_crypto_payouts_wallet ON crypto_payouts (wallet_id, id);
+-- The payout worker only looks for the payouts not yet sent
+CREATE INDEX idx_crypto_payouts_unsent ON crypto_payouts (id) WHERE status IN ('QUEUED', 'SENDING');
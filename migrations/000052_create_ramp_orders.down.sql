@@ -0,0 +1,2 @@
+-- 000052_create_ramp_orders.down.sql
+DROP TABLE IF EXISTS ramp_orders;
@@ -0,0 +1,14 @@
+-- 000052_create_ramp_orders.up.sql
+-- Ramp orders between fiat and stablecoin wallets. The pricing is that of the transfer quote; once executed,
+-- an order links the quote to the CONVERSION legs that moved the money.
+CREATE TABLE ramp_orders (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('ON_RAMP', 'OFF_RAMP')),
+    quote_id BIGINT NOT NULL UNIQUE REFERENCES transfer_quotes(id),
+    status VARCHAR(10) NOT NULL DEFAULT 'QUOTED' CHECK (status IN ('QUOTED', 'EXECUTED')),
+    debit_transaction_id UUID UNIQUE,  -- Public ID of the CONVERSION debit once executed
+    credit_transaction_id UUID UNIQUE, -- Public ID of the CONVERSION credit once executed
+    executed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+-- 000053_create_ob_consents.down.sql
+DROP TABLE IF EXISTS ob_consents;
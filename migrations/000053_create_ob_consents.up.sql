@@ -0,0 +1,20 @@
+-- 000053_create_ob_consents.up.sql
+-- Account access consents of the Open Banking API: requested by a third party, authorised by a user for some
+-- of their wallets, and revocable by either.
+CREATE TABLE ob_consents (
+    id BIGSERIAL PRIMARY KEY,
+    consent_id UUID NOT NULL UNIQUE,
+    client_id VARCHAR(100) NOT NULL,
+    user_id BIGINT REFERENCES users(id), -- Set when the user authorises or rejects the consent
+    permission_list TEXT[] NOT NULL,
+    wallet_ids BIGINT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(30) NOT NULL DEFAULT 'AwaitingAuthorisation'
+        CHECK (status IN ('AwaitingAuthorisation', 'Authorised', 'Rejected', 'Revoked')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    transaction_from TIMESTAMPTZ,
+    transaction_to TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    status_updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ob_consents_user ON ob_consents (user_id, id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,2 @@
+-- 000054_create_user_identities.down.sql
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,13 @@
+-- 000054_create_user_identities.up.sql
+-- Accounts of users at OpenID Connect providers, by the provider's subject ID, for delegated login.
+CREATE TABLE user_identities (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ,
+    UNIQUE (provider, subject), -- A provider account logs in as one user
+    UNIQUE (user_id, provider)  -- A user links one account per provider
+);
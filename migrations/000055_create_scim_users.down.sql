@@ -0,0 +1,4 @@
+-- 000055_create_scim_users.down.sql
+DROP TABLE IF EXISTS scim_provisioning_events;
+DROP TABLE IF EXISTS scim_users;
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
@@ -0,0 +1,29 @@
+-- 000055_create_scim_users.up.sql
+-- Users provisioned by the identity systems of enterprise tenants over SCIM 2.0, and the audit record of
+-- each provisioning change. A deactivated user keeps their wallets but can no longer log in.
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMPTZ;
+
+CREATE TABLE scim_users (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE, -- The SCIM resource id
+    tenant VARCHAR(100) NOT NULL,
+    external_id VARCHAR(255), -- The identity system's own ID of the user, if it sent one
+    user_id BIGINT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant, external_id)
+);
+
+CREATE INDEX idx_scim_users_tenant ON scim_users (tenant, id);
+
+CREATE TABLE scim_provisioning_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant VARCHAR(100) NOT NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('CREATE', 'UPDATE', 'DEACTIVATE', 'REACTIVATE')),
+    changes JSONB NOT NULL, -- Field -> {from, to}; contact details only name the field
+    request_id VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scim_provisioning_events_user ON scim_provisioning_events (user_id, id);
@@ -0,0 +1,3 @@
+-- 000056_create_wallet_ownership_transfers.down.sql
+DROP TABLE IF EXISTS wallet_ownership_transfers;
+ALTER TABLE wallets DROP COLUMN IF EXISTS ownership_frozen_at;
@@ -0,0 +1,27 @@
+-- 000056_create_wallet_ownership_transfers.up.sql
+-- Admin-mediated transfers of a wallet to another user, e.g. to merge the accounts of a user who registered
+-- twice. The wallet is frozen from the request until the target user accepts or declines it, an admin
+-- cancels it or it expires; accepting reassigns the wallet.
+ALTER TABLE wallets ADD COLUMN ownership_frozen_at TIMESTAMPTZ;
+
+CREATE TABLE wallet_ownership_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL UNIQUE,
+    wallet_id BIGINT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    from_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('PENDING', 'COMPLETED', 'DECLINED', 'CANCELLED', 'EXPIRED')),
+    reason VARCHAR(500) NOT NULL,
+    requested_by VARCHAR(100) NOT NULL, -- The named admin who requested it
+    cancelled_by VARCHAR(100),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    CHECK (from_user_id <> to_user_id)
+);
+
+-- At most one transfer of a wallet is pending at a time
+CREATE UNIQUE INDEX idx_wallet_ownership_transfers_pending ON wallet_ownership_transfers (wallet_id) WHERE status = 'PENDING';
+CREATE INDEX idx_wallet_ownership_transfers_wallet ON wallet_ownership_transfers (wallet_id, id);
+CREATE INDEX idx_wallet_ownership_transfers_to_user ON wallet_ownership_transfers (to_user_id, id);
+CREATE INDEX idx_wallet_ownership_transfers_expiry ON wallet_ownership_transfers (expires_at) WHERE status = 'PENDING';
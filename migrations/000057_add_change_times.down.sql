@@ -0,0 +1,20 @@
+-- 000057_add_change_times.down.sql
+CREATE OR REPLACE FUNCTION wallets_track_change() RETURNS trigger AS $$
+BEGIN
+    NEW.change_seq := nextval('change_seq');
+    IF NEW.updated_at IS NOT DISTINCT FROM OLD.updated_at THEN
+        NEW.updated_at := clock_timestamp();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION transactions_track_change() RETURNS trigger AS $$
+BEGIN
+    NEW.change_seq := nextval('change_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS changed_at;
+ALTER TABLE wallets DROP COLUMN IF EXISTS changed_at;
@@ -0,0 +1,30 @@
+-- 000057_add_change_times.up.sql
+-- The change feed holds back rows that changed too recently to be sure every earlier sequence value has
+-- committed. It went by created_at and updated_at, but the settlement of a PENDING transaction takes a new
+-- sequence value and keeps its created_at, and both times can come from the test clock of sandbox mode. The
+-- database stamps changed_at with each sequence value instead.
+
+-- Rows from before the column have settled long ago; the constant default does not rewrite the tables
+ALTER TABLE wallets ADD COLUMN changed_at TIMESTAMPTZ NOT NULL DEFAULT '-infinity';
+ALTER TABLE transactions ADD COLUMN changed_at TIMESTAMPTZ NOT NULL DEFAULT '-infinity';
+ALTER TABLE wallets ALTER COLUMN changed_at SET DEFAULT clock_timestamp();
+ALTER TABLE transactions ALTER COLUMN changed_at SET DEFAULT clock_timestamp();
+
+CREATE OR REPLACE FUNCTION wallets_track_change() RETURNS trigger AS $$
+BEGIN
+    NEW.change_seq := nextval('change_seq');
+    NEW.changed_at := clock_timestamp();
+    IF NEW.updated_at IS NOT DISTINCT FROM OLD.updated_at THEN
+        NEW.updated_at := clock_timestamp();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION transactions_track_change() RETURNS trigger AS $$
+BEGIN
+    NEW.change_seq := nextval('change_seq');
+    NEW.changed_at := clock_timestamp();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
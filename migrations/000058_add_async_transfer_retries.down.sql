@@ -0,0 +1,3 @@
+-- 000058_add_async_transfer_retries.down.sql
+ALTER TABLE async_transfers DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE async_transfers DROP COLUMN IF EXISTS attempts;
@@ -0,0 +1,6 @@
+-- 000058_add_async_transfer_retries.up.sql
+-- A transfer the worker could not make for a reason other than the transfer itself, e.g. a dropped database
+-- connection, stays PENDING and is tried again after a growing delay. failure_reason now holds a stable error
+-- code rather than the text of the error.
+ALTER TABLE async_transfers ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE async_transfers ADD COLUMN next_attempt_at TIMESTAMPTZ; -- NULL: due at once
@@ -0,0 +1,20 @@
+// migrations/migrations.go
+
+// Package migrations embeds the SQL migrations, applied with the migrate CLI, and registers the backfills that
+// go with them.
+package migrations
+
+import (
+	"embed"
+
+	"finflow-wallet/internal/migration"
+)
+
+// FS holds the up and down migrations.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// Backfills are the out-of-band backfills of expand migrations. Add one with the migration whose data it fills
+// in, and mark the migration relying on it as a contract step.
+var Backfills = []migration.Backfill{}
@@ -0,0 +1,298 @@
+// pkg/client/client.go
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Defaults of the retry policy.
+const (
+	DefaultMaxAttempts  = 3
+	DefaultRetryBackoff = 200 * time.Millisecond
+	DefaultMaxRetryWait = 5 * time.Second
+)
+
+// IdempotencyKeyHeader carries the key that identifies one logical write across its attempts.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// requestTimeoutHeader tells the server how long the caller waits, so it gives up on the request in time.
+const requestTimeoutHeader = "X-Request-Timeout"
+
+// transactionPINHeader carries the PIN confirming a withdrawal or transfer, when the user has one.
+const transactionPINHeader = "X-Transaction-PIN"
+
+// Client calls the wallet HTTP API. It is safe for concurrent use.
+//
+// Reads are retried on any transient failure. A write is retried only when its response shows that it was
+// not applied (503, 429 and 409 concurrent_update), or, for transfers, when the server's duplicate transfer
+// check can tell a repeat of it from a new transfer; see Transfer.
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	header       http.Header // Sent with every request, e.g. Authorization
+	maxAttempts  int
+	retryBackoff time.Duration // Wait before the second attempt; doubled for each further one
+	maxRetryWait time.Duration // A Retry-After longer than this fails the call instead of waiting
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with httpClient instead of a client with a 30 second timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader sends the header with every request, e.g. an Authorization or X-Admin-Token header.
+func WithHeader(name, value string) Option {
+	return func(c *Client) {
+		c.header.Set(name, value)
+	}
+}
+
+// WithRetries sets how often a call is attempted (1 disables retries) and the wait before the first retry,
+// which doubles for each further one.
+func WithRetries(maxAttempts int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = max(maxAttempts, 1)
+		c.retryBackoff = backoff
+	}
+}
+
+// WithMaxRetryWait sets the longest Retry-After the client waits for; longer ones fail the call at once.
+func WithMaxRetryWait(wait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetryWait = wait
+	}
+}
+
+// New creates a Client for the API at baseURL, e.g. "http://wallet.internal:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		header:       http.Header{},
+		maxAttempts:  DefaultMaxAttempts,
+		retryBackoff: DefaultRetryBackoff,
+		maxRetryWait: DefaultMaxRetryWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is an error response of the API.
+type APIError struct {
+	Status  int               // HTTP status
+	Code    string            // Stable error code, e.g. "insufficient_funds"
+	Message string            // Human-readable message in the requested locale
+	Details map[string]string // Extra fields of the error body, e.g. existing_transaction_id
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("wallet api: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// IsCode reports whether err is an APIError with the code.
+func IsCode(err error, code string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// call describes one logical API call, attempted until it succeeds or may not be retried.
+type call struct {
+	method         string
+	path           string // Including the query, if any
+	body           any    // Encoded as JSON; nil sends no body
+	header         http.Header
+	write          bool // Only retried when the failed attempt was not applied
+	retryAmbiguous bool // A write whose repeat the server detects, so it is also retried on ambiguous failures
+}
+
+// attemptError is the failure of one attempt and whether the next attempt may be made.
+type attemptError struct {
+	err          error
+	retryable    bool
+	maybeApplied bool          // Whether a write may have been applied although the attempt failed
+	retryAfter   time.Duration // Wait requested by the server, if any
+}
+
+// do performs the call and decodes the data of its response envelope into out. onRetryError may turn
+// the error of a retried attempt into a result; it is called only for attempts after an ambiguous failure.
+func (c *Client) do(ctx context.Context, req call, out any, onRetryError func(*APIError) bool) error {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("wallet api: failed to encode request: %w", err)
+		}
+	}
+
+	ambiguous := false // Whether an earlier attempt may have been applied
+	for attempt := 1; ; attempt++ {
+		failure := c.attempt(ctx, req, body, out)
+		if failure == nil {
+			return nil
+		}
+		var apiErr *APIError
+		if ambiguous && onRetryError != nil && errors.As(failure.err, &apiErr) && onRetryError(apiErr) {
+			return nil
+		}
+		if !failure.retryable || attempt >= c.maxAttempts {
+			return failure.err
+		}
+		ambiguous = ambiguous || failure.maybeApplied
+
+		wait := c.retryBackoff << (attempt - 1)
+		wait += rand.N(wait/2 + 1) // Jitter, so callers failing together do not retry together
+		if failure.retryAfter > 0 {
+			if failure.retryAfter > c.maxRetryWait {
+				return failure.err
+			}
+			wait = failure.retryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("wallet api: %w (last error: %w)", ctx.Err(), failure.err)
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends the request once.
+func (c *Client) attempt(ctx context.Context, req call, body []byte, out any) *attemptError {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, reader)
+	if err != nil {
+		return &attemptError{err: fmt.Errorf("wallet api: %w", err)}
+	}
+	for name, values := range c.header {
+		httpReq.Header[name] = values
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline).Truncate(time.Millisecond); remaining > 0 {
+			httpReq.Header.Set(requestTimeoutHeader, remaining.String())
+		}
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return &attemptError{err: fmt.Errorf("wallet api: %w", err)}
+		}
+		// A write may have been applied unless the connection was never made
+		var opErr *net.OpError
+		notSent := errors.As(err, &opErr) && opErr.Op == "dial"
+		return &attemptError{
+			err:          fmt.Errorf("wallet api: %s %s: %w", req.method, req.path, err),
+			retryable:    !req.write || notSent || req.retryAmbiguous,
+			maybeApplied: !notSent,
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{Status: resp.StatusCode}
+		var errBody map[string]string
+		if json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&errBody) == nil {
+			apiErr.Code, apiErr.Message = errBody["code"], errBody["error"]
+			delete(errBody, "code")
+			delete(errBody, "error")
+			if len(errBody) > 0 {
+				apiErr.Details = errBody
+			}
+		}
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return &attemptError{
+			err:          apiErr,
+			retryable:    retryableStatus(req, apiErr),
+			maybeApplied: apiErr.Status >= http.StatusInternalServerError && apiErr.Status != http.StatusServiceUnavailable,
+			retryAfter:   parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	envelope := struct {
+		Data any `json:"data"`
+	}{Data: out}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return &attemptError{err: fmt.Errorf("wallet api: failed to decode %s %s response: %w", req.method, req.path, err)}
+	}
+	return nil
+}
+
+// retryableStatus reports whether an error response may be retried: always for a read failing with a
+// server error, and for a write only when the response shows the write was not applied.
+func retryableStatus(req call, apiErr *APIError) bool {
+	switch {
+	case apiErr.Status == http.StatusServiceUnavailable, apiErr.Status == http.StatusTooManyRequests:
+		return true // Rejected before anything was applied, e.g. in maintenance mode
+	case apiErr.Status == http.StatusConflict && apiErr.Code == "concurrent_update":
+		return true // The transaction was rolled back
+	case apiErr.Status == http.StatusBadGateway, apiErr.Status == http.StatusGatewayTimeout:
+		return !req.write || req.retryAmbiguous
+	case apiErr.Status >= http.StatusInternalServerError:
+		return !req.write
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header in seconds; other forms are ignored.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// writeHeader returns the headers of a write: its idempotency key, a new one unless given, and the PIN.
+func writeHeader(idempotencyKey, pin string) http.Header {
+	if idempotencyKey == "" {
+		idempotencyKey = uuid.NewString()
+	}
+	header := http.Header{}
+	header.Set(IdempotencyKeyHeader, idempotencyKey)
+	if pin != "" {
+		header.Set(transactionPINHeader, pin)
+	}
+	return header
+}
+
+// walletPath returns the path of a wallet's resource, e.g. walletPath(id, "balance").
+func walletPath(walletID uuid.UUID, resource string) string {
+	return "/wallets/" + url.PathEscape(walletID.String()) + "/" + resource
+}
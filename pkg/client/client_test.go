@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedServer answers the requests in turn with the responses, recording the requests.
+type scriptedServer struct {
+	mu        sync.Mutex
+	responses []func(w http.ResponseWriter, r *http.Request)
+	requests  []*http.Request
+}
+
+func (s *scriptedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	respond := s.responses[len(s.requests)]
+	s.requests = append(s.requests, r)
+	s.mu.Unlock()
+	respond(w, r)
+}
+
+func respondJSON(status int, body any) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+func newTestClient(t *testing.T, server *scriptedServer) *Client {
+	t.Helper()
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	return New(ts.URL, WithRetries(3, time.Millisecond), WithHeader("Authorization", "Bearer test"))
+}
+
+func TestDeposit(t *testing.T) {
+	walletID, transactionID := uuid.New(), uuid.New()
+	server := &scriptedServer{responses: []func(http.ResponseWriter, *http.Request){
+		respondJSON(http.StatusServiceUnavailable, map[string]string{"error": "read-only", "code": "read_only"}),
+		respondJSON(http.StatusOK, map[string]any{"data": map[string]any{
+			"wallet_id": walletID, "new_balance": "112.50", "transaction_id": transactionID,
+		}}),
+	}}
+	c := newTestClient(t, server)
+
+	result, err := c.Deposit(context.Background(), walletID, DepositRequest{Amount: decimal.RequireFromString("12.50"), Currency: "USD"})
+
+	require.NoError(t, err)
+	assert.Equal(t, transactionID, result.TransactionID)
+	assert.True(t, decimal.RequireFromString("112.5").Equal(result.NewBalance))
+	require.Len(t, server.requests, 2)
+	assert.Equal(t, fmt.Sprintf("/wallets/%s/deposit", walletID), server.requests[0].URL.Path)
+	assert.Equal(t, "Bearer test", server.requests[0].Header.Get("Authorization"))
+	key := server.requests[0].Header.Get(IdempotencyKeyHeader)
+	assert.NotEmpty(t, key)
+	assert.Equal(t, key, server.requests[1].Header.Get(IdempotencyKeyHeader), "retries keep the idempotency key")
+}
+
+func TestRequestTimeoutFollowsTheDeadline(t *testing.T) {
+	server := &scriptedServer{responses: []func(http.ResponseWriter, *http.Request){
+		respondJSON(http.StatusOK, map[string]any{"data": map[string]any{"balance": "1.00", "currency": "USD"}}),
+	}}
+	c := newTestClient(t, server)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := c.GetBalance(ctx, uuid.New())
+
+	require.NoError(t, err)
+	timeout, err := time.ParseDuration(server.requests[0].Header.Get("X-Request-Timeout"))
+	require.NoError(t, err)
+	assert.InDelta(t, 2*time.Second, timeout, float64(500*time.Millisecond))
+}
+
+func TestWriteIsNotRetriedWhenItMayHaveBeenApplied(t *testing.T) {
+	server := &scriptedServer{responses: []func(http.ResponseWriter, *http.Request){
+		respondJSON(http.StatusGatewayTimeout, map[string]string{"error": "timeout", "code": "timeout"}),
+	}}
+	c := newTestClient(t, server)
+
+	_, err := c.Withdraw(context.Background(), uuid.New(), WithdrawRequest{Amount: decimal.NewFromInt(5), Currency: "USD", PIN: "1234"})
+
+	assert.True(t, IsCode(err, "timeout"))
+	require.Len(t, server.requests, 1)
+	assert.Equal(t, "1234", server.requests[0].Header.Get("X-Transaction-PIN"))
+}
+
+func TestTransferRetryResolvesDuplicateToEarlierAttempt(t *testing.T) {
+	existing := uuid.New()
+	server := &scriptedServer{responses: []func(http.ResponseWriter, *http.Request){
+		respondJSON(http.StatusGatewayTimeout, map[string]string{"error": "timeout", "code": "timeout"}),
+		respondJSON(http.StatusConflict, map[string]string{
+			"error": "duplicate", "code": "duplicate_transfer", "existing_transaction_id": existing.String(),
+		}),
+	}}
+	c := newTestClient(t, server)
+
+	result, err := c.Transfer(context.Background(), TransferRequest{
+		FromWalletID: uuid.New(), ToWalletID: uuid.New(), Amount: decimal.NewFromInt(5), Currency: "USD",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, existing, result.TransactionID)
+	assert.True(t, result.Replayed)
+}
+
+func TestTransferDuplicateOnFirstAttemptIsAnError(t *testing.T) {
+	server := &scriptedServer{responses: []func(http.ResponseWriter, *http.Request){
+		respondJSON(http.StatusConflict, map[string]string{
+			"error": "duplicate", "code": "duplicate_transfer", "existing_transaction_id": uuid.NewString(),
+		}),
+	}}
+	c := newTestClient(t, server)
+
+	_, err := c.Transfer(context.Background(), TransferRequest{
+		FromWalletID: uuid.New(), ToWalletID: uuid.New(), Amount: decimal.NewFromInt(5), Currency: "USD",
+	})
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.Status)
+	assert.Contains(t, apiErr.Details, "existing_transaction_id")
+}
+
+func TestTransferAwaitingApproval(t *testing.T) {
+	approvalID := uuid.New()
+	server := &scriptedServer{responses: []func(http.ResponseWriter, *http.Request){
+		respondJSON(http.StatusAccepted, map[string]any{"data": map[string]any{"id": approvalID, "status": "PENDING", "transaction_id": nil}}),
+	}}
+	c := newTestClient(t, server)
+
+	result, err := c.Transfer(context.Background(), TransferRequest{
+		FromWalletID: uuid.New(), ToWalletID: uuid.New(), Amount: decimal.NewFromInt(5000), Currency: "USD",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, result.TransactionID)
+	assert.Equal(t, approvalID, result.ApprovalID)
+}
+
+func TestAsyncTransfer(t *testing.T) {
+	transactionID := uuid.New()
+	server := &scriptedServer{responses: []func(http.ResponseWriter, *http.Request){
+		respondJSON(http.StatusAccepted, map[string]any{"data": map[string]any{"transaction_id": transactionID, "status": "PENDING"}}),
+	}}
+	c := newTestClient(t, server)
+
+	result, err := c.Transfer(context.Background(), TransferRequest{
+		FromWalletID: uuid.New(), ToWalletID: uuid.New(), Amount: decimal.NewFromInt(5), Currency: "USD", Async: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, transactionID, result.TransactionID)
+	assert.True(t, result.Pending)
+	assert.Equal(t, uuid.Nil, result.ApprovalID)
+}
+
+func TestTransactionIterator(t *testing.T) {
+	walletID := uuid.New()
+	page := func(ids ...uuid.UUID) func(http.ResponseWriter, *http.Request) {
+		data := make([]map[string]any, len(ids))
+		for i, id := range ids {
+			data[i] = map[string]any{"id": id, "amount": "1.00", "currency": "USD"}
+		}
+		return respondJSON(http.StatusOK, map[string]any{"data": data})
+	}
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	server := &scriptedServer{responses: []func(http.ResponseWriter, *http.Request){
+		page(ids[0], ids[1]),
+		respondJSON(http.StatusInternalServerError, map[string]string{"error": "boom", "code": "internal_error"}),
+		page(ids[2]),
+	}}
+	c := newTestClient(t, server)
+
+	it := c.Transactions(walletID, HistoryOptions{PageSize: 2, Sort: "created_at:asc"})
+	var got []uuid.UUID
+	for it.Next(context.Background()) {
+		got = append(got, it.Transaction().ID)
+	}
+
+	require.NoError(t, it.Err())
+	assert.Equal(t, ids, got)
+	require.Len(t, server.requests, 3, "the last page is short, so nothing is fetched after it")
+	assert.Equal(t, "0", server.requests[0].URL.Query().Get("offset"))
+	assert.Equal(t, "2", server.requests[2].URL.Query().Get("offset"))
+	assert.Equal(t, "created_at:asc", server.requests[2].URL.Query().Get("sort"))
+}
+
+func TestRetryAfterBeyondMaxWaitFails(t *testing.T) {
+	server := &scriptedServer{responses: []func(http.ResponseWriter, *http.Request){
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", strconv.Itoa(3600))
+			respondJSON(http.StatusTooManyRequests, map[string]string{"error": "quota", "code": "request_quota_exceeded"})(w, r)
+		},
+	}}
+	c := newTestClient(t, server)
+
+	_, err := c.GetBalance(context.Background(), uuid.New())
+
+	assert.True(t, IsCode(err, "request_quota_exceeded"))
+	assert.Len(t, server.requests, 1)
+}
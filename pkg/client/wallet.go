@@ -0,0 +1,279 @@
+// pkg/client/wallet.go
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// DepositRequest is a deposit into a wallet.
+type DepositRequest struct {
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	Category       string          `json:"category,omitempty"`
+	IdempotencyKey string          `json:"-"` // Optional; a new key is generated for each call otherwise
+}
+
+// WithdrawRequest is a withdrawal from a wallet.
+type WithdrawRequest struct {
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	Category       string          `json:"category,omitempty"`
+	OverrideBudget bool            `json:"override_budget,omitempty"`
+	PIN            string          `json:"-"` // The owner's transaction PIN, if they have one
+	IdempotencyKey string          `json:"-"`
+}
+
+// TransferRequest is a transfer between two wallets.
+type TransferRequest struct {
+	FromWalletID   uuid.UUID       `json:"from_wallet_id"`
+	ToWalletID     uuid.UUID       `json:"to_wallet_id"`
+	Amount         decimal.Decimal `json:"amount"`
+	Currency       string          `json:"currency"`
+	Category       string          `json:"category,omitempty"`
+	OverrideBudget bool            `json:"override_budget,omitempty"`
+	QuoteID        *uuid.UUID      `json:"quote_id,omitempty"`
+	Force          bool            `json:"force,omitempty"` // Send even if it repeats a recent transfer
+	Async          bool            `json:"async,omitempty"` // Have the server accept the transfer and make it in the background
+	PIN            string          `json:"-"`
+	IdempotencyKey string          `json:"-"`
+}
+
+// MovementResult is the outcome of a deposit or withdrawal.
+type MovementResult struct {
+	WalletID      uuid.UUID       `json:"wallet_id"`
+	NewBalance    decimal.Decimal `json:"new_balance"`
+	TransactionID uuid.UUID       `json:"transaction_id"`
+}
+
+// TransferResult is the outcome of a transfer. A transfer above the source wallet's approval threshold
+// is not executed but awaits approval: its ApprovalID is set instead of TransactionID. An async transfer is
+// Pending until its transaction, polled with GetTransaction, leaves the PENDING status.
+type TransferResult struct {
+	TransactionID        uuid.UUID       `json:"transaction_id"`
+	FromWalletNewBalance decimal.Decimal `json:"from_wallet_new_balance"`
+	ApprovalID           uuid.UUID       `json:"-"`
+	Pending              bool            `json:"-"` // FromWalletNewBalance is then unknown
+	// Replayed is set when a retry found the transfer already executed by an earlier attempt of the call.
+	// FromWalletNewBalance is then unknown.
+	Replayed bool `json:"-"`
+}
+
+// Balance is the balance of a wallet.
+type Balance struct {
+	WalletID uuid.UUID       `json:"wallet_id"`
+	Balance  decimal.Decimal `json:"balance"`
+	Currency string          `json:"currency"`
+}
+
+// Transaction is a transaction as the API renders it.
+type Transaction struct {
+	ID                  uuid.UUID       `json:"id"`
+	FromWalletID        *uuid.UUID      `json:"from_wallet_id"`
+	ToWalletID          *uuid.UUID      `json:"to_wallet_id"`
+	Amount              decimal.Decimal `json:"amount"`
+	Currency            string          `json:"currency"`
+	Type                string          `json:"type"`
+	Status              string          `json:"status"`
+	TransactionTime     time.Time       `json:"transaction_time"`
+	Description         *string         `json:"description"`
+	DisplayDescription  string          `json:"display_description"`
+	Category            *string         `json:"category"`
+	ParentTransactionID *uuid.UUID      `json:"parent_transaction_id"`
+	PromoAmount         decimal.Decimal `json:"promo_amount"`
+	CreatedAt           time.Time       `json:"created_at"`
+}
+
+// Deposit deposits money into the wallet.
+func (c *Client) Deposit(ctx context.Context, walletID uuid.UUID, req DepositRequest) (*MovementResult, error) {
+	var result MovementResult
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   walletPath(walletID, "deposit"),
+		body:   req,
+		header: writeHeader(req.IdempotencyKey, ""),
+		write:  true,
+	}, &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Withdraw withdraws money from the wallet.
+func (c *Client) Withdraw(ctx context.Context, walletID uuid.UUID, req WithdrawRequest) (*MovementResult, error) {
+	var result MovementResult
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   walletPath(walletID, "withdraw"),
+		body:   req,
+		header: writeHeader(req.IdempotencyKey, req.PIN),
+		write:  true,
+	}, &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Transfer transfers money between two wallets. Unless Force is set, the server rejects a transfer that
+// repeats a recent one with 409 duplicate_transfer, so a transfer is also retried when an attempt may have
+// gone through, e.g. after a timeout: if the retry is rejected as a duplicate, the earlier attempt was
+// executed and its transaction is returned with Replayed set. The first attempt of a call is never
+// treated this way; its duplicate_transfer error is returned as is.
+func (c *Client) Transfer(ctx context.Context, req TransferRequest) (*TransferResult, error) {
+	// The 202 response of a transfer awaiting approval carries the approval rather than a transaction
+	var data struct {
+		TransferResult
+		ID     uuid.UUID `json:"id"`
+		Status string    `json:"status"`
+	}
+	err := c.do(ctx, call{
+		method:         http.MethodPost,
+		path:           "/transfers",
+		body:           req,
+		header:         writeHeader(req.IdempotencyKey, req.PIN),
+		write:          true,
+		retryAmbiguous: !req.Force,
+	}, &data, func(apiErr *APIError) bool {
+		existing, err := uuid.Parse(apiErr.Details["existing_transaction_id"])
+		if apiErr.Code != "duplicate_transfer" || err != nil {
+			return false
+		}
+		data.TransferResult = TransferResult{TransactionID: existing, Replayed: true}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := data.TransferResult
+	if result.TransactionID == uuid.Nil {
+		result.ApprovalID = data.ID
+	} else {
+		result.Pending = data.Status == "PENDING"
+	}
+	return &result, nil
+}
+
+// GetBalance returns the balance of the wallet.
+func (c *Client) GetBalance(ctx context.Context, walletID uuid.UUID) (*Balance, error) {
+	var balance Balance
+	if err := c.do(ctx, call{method: http.MethodGet, path: walletPath(walletID, "balance")}, &balance, nil); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// GetTransaction returns a transaction.
+func (c *Client) GetTransaction(ctx context.Context, transactionID uuid.UUID) (*Transaction, error) {
+	var transaction Transaction
+	path := "/transactions/" + url.PathEscape(transactionID.String())
+	if err := c.do(ctx, call{method: http.MethodGet, path: path}, &transaction, nil); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// maxPageSize is the largest page the API serves.
+const maxPageSize = 100
+
+// HistoryOptions narrows the transactions a TransactionIterator walks.
+type HistoryOptions struct {
+	From     time.Time // Optional; transactions created at or after
+	To       time.Time // Optional; transactions created before
+	Sort     string    // Optional, e.g. "created_at:asc"; newest first by default
+	PageSize int       // Optional; at most 100, the default
+}
+
+// TransactionIterator walks a wallet's transaction history page by page, fetching a page when the
+// previous one is used up:
+//
+//	it := c.Transactions(walletID, client.HistoryOptions{})
+//	for it.Next(ctx) {
+//		tx := it.Transaction()
+//	}
+//	if err := it.Err(); err != nil { ... }
+//
+// Pages are fetched by offset, so transactions committed during the walk may shift it by a few entries;
+// sort by created_at:asc to walk a stable history.
+type TransactionIterator struct {
+	client   *Client
+	walletID uuid.UUID
+	query    url.Values
+	pageSize int
+	offset   int
+	page     []Transaction
+	current  Transaction
+	done     bool // The last page has been fetched
+	err      error
+}
+
+// Transactions returns an iterator over the wallet's transactions. Nothing is fetched before Next.
+func (c *Client) Transactions(walletID uuid.UUID, opts HistoryOptions) *TransactionIterator {
+	query := url.Values{}
+	if !opts.From.IsZero() {
+		query.Set("from", opts.From.UTC().Format(time.RFC3339Nano))
+	}
+	if !opts.To.IsZero() {
+		query.Set("to", opts.To.UTC().Format(time.RFC3339Nano))
+	}
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	pageSize := opts.PageSize
+	if pageSize <= 0 || pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return &TransactionIterator{client: c, walletID: walletID, query: query, pageSize: pageSize}
+}
+
+// Next advances to the next transaction, fetching the next page if needed. It returns false when the
+// history is exhausted or a fetch failed; Err tells which.
+func (it *TransactionIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.page) == 0 && !it.done {
+		it.fetch(ctx)
+	}
+	if len(it.page) == 0 {
+		return false
+	}
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Transaction returns the transaction Next advanced to.
+func (it *TransactionIterator) Transaction() Transaction {
+	return it.current
+}
+
+// Err returns the error that ended the iteration, if any.
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
+
+// fetch fetches the page at the iterator's offset.
+func (it *TransactionIterator) fetch(ctx context.Context) {
+	query := url.Values{}
+	for key, values := range it.query {
+		query[key] = values
+	}
+	query.Set("limit", strconv.Itoa(it.pageSize))
+	query.Set("offset", strconv.Itoa(it.offset))
+
+	var page []Transaction
+	path := walletPath(it.walletID, "transactions") + "?" + query.Encode()
+	if it.err = it.client.do(ctx, call{method: http.MethodGet, path: path}, &page, nil); it.err != nil {
+		return
+	}
+	it.page = page
+	it.offset += len(page)
+	it.done = len(page) < it.pageSize
+}
@@ -18,6 +18,10 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	PrepareStatements bool          // Run the hot queries as prepared statements; off behind poolers that do not support them
+	ReadRetries       int           // Retries of read-only queries outside transactions on connection errors; 0 disables
+	ReadRetryBackoff  time.Duration // Wait before the first retry, doubled for each further one
 }
 
 // NewPostgresDB initializes and returns a new PostgreSQL database connection.
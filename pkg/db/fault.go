@@ -0,0 +1,26 @@
+// pkg/db/fault.go
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+type droppedConnectionKey struct{}
+
+// WithDroppedConnection marks ctx so that a transaction begun with it fails as if the database connection
+// had dropped. It is used by fault injection in test environments.
+func WithDroppedConnection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, droppedConnectionKey{}, true)
+}
+
+// DropMarkedConnections wraps begin so that it fails with driver.ErrBadConn for contexts marked with
+// WithDroppedConnection, and begins the transaction as usual otherwise.
+func DropMarkedConnections(begin BeginTxFunc) BeginTxFunc {
+	return func(ctx context.Context, dbConn DBTxBeginner) (TxController, error) {
+		if dropped, _ := ctx.Value(droppedConnectionKey{}).(bool); dropped {
+			return nil, driver.ErrBadConn
+		}
+		return begin(ctx, dbConn)
+	}
+}
@@ -0,0 +1,117 @@
+// pkg/db/pool_monitor.go
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// PoolSample is the state of the connection pool at a sampling, with the waits for a connection since the
+// previous sampling.
+type PoolSample struct {
+	At           time.Time
+	Stats        sql.DBStats
+	Waits        int64         // Connections waited for since the previous sample
+	WaitDuration time.Duration // Time spent waiting for them
+}
+
+// AverageWait returns the average wait for a connection since the previous sample.
+func (s PoolSample) AverageWait() time.Duration {
+	if s.Waits == 0 {
+		return 0
+	}
+	return s.WaitDuration / time.Duration(s.Waits)
+}
+
+// PoolMonitor samples the statistics of a connection pool, keeps the last sample for the metrics endpoint,
+// and raises an alarm in the log while callers wait long for a connection, the first sign the pool is too
+// small for the load: a warning when the average wait since the previous sample reaches the warn threshold,
+// an error when it reaches the critical one, and a notice once waits are back under the warn threshold.
+type PoolMonitor struct {
+	stats    func() sql.DBStats
+	warn     time.Duration // 0 disables the alarms
+	critical time.Duration // 0 raises only warnings
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	last     PoolSample
+	alarming bool
+}
+
+// NewPoolMonitor creates a monitor of the pool whose statistics stats returns, e.g. (*sql.DB).Stats.
+func NewPoolMonitor(stats func() sql.DBStats, warn, critical time.Duration, logger *slog.Logger) *PoolMonitor {
+	return &PoolMonitor{stats: stats, warn: warn, critical: critical, logger: logger}
+}
+
+// Sample takes a sample of the pool and raises or clears the alarm.
+func (m *PoolMonitor) Sample() PoolSample {
+	stats := m.stats()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sample := PoolSample{
+		At:           time.Now().UTC(),
+		Stats:        stats,
+		Waits:        stats.WaitCount - m.last.Stats.WaitCount,
+		WaitDuration: stats.WaitDuration - m.last.Stats.WaitDuration,
+	}
+	m.last = sample
+
+	if m.warn <= 0 {
+		return sample
+	}
+	avg := sample.AverageWait()
+	attrs := []any{
+		"average_wait", avg, "waits", sample.Waits,
+		"in_use", stats.InUse, "open", stats.OpenConnections, "max_open", stats.MaxOpenConnections,
+	}
+	switch {
+	case m.critical > 0 && avg >= m.critical:
+		m.logger.Error("Database pool saturated: callers wait long for a connection", attrs...)
+		m.alarming = true
+	case avg >= m.warn:
+		m.logger.Warn("Database pool under pressure: callers wait for a connection", attrs...)
+		m.alarming = true
+	case m.alarming:
+		m.logger.Info("Database pool waits back to normal", attrs...)
+		m.alarming = false
+	}
+	return sample
+}
+
+// Last returns the last sample; its zero value before the first sampling.
+func (m *PoolMonitor) Last() PoolSample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+// WritePrometheus writes the last sample in the Prometheus text exposition format.
+func (m *PoolMonitor) WritePrometheus(w io.Writer) error {
+	sample := m.Last()
+	metrics := []struct {
+		name, kind, help string
+		value            any
+	}{
+		{"db_pool_max_open_connections", "gauge", "Maximum number of open connections to the database.", sample.Stats.MaxOpenConnections},
+		{"db_pool_open_connections", "gauge", "Established connections, in use and idle.", sample.Stats.OpenConnections},
+		{"db_pool_in_use_connections", "gauge", "Connections currently in use.", sample.Stats.InUse},
+		{"db_pool_idle_connections", "gauge", "Idle connections.", sample.Stats.Idle},
+		{"db_pool_wait_count_total", "counter", "Connections waited for.", sample.Stats.WaitCount},
+		{"db_pool_wait_duration_seconds_total", "counter", "Time spent waiting for connections.", sample.Stats.WaitDuration.Seconds()},
+		{"db_pool_average_wait_seconds", "gauge", "Average wait for a connection between the last two samples.", sample.AverageWait().Seconds()},
+		{"db_pool_max_idle_closed_total", "counter", "Connections closed due to the idle limit.", sample.Stats.MaxIdleClosed},
+		{"db_pool_max_lifetime_closed_total", "counter", "Connections closed due to the lifetime limit.", sample.Stats.MaxLifetimeClosed},
+	}
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP finflow_%s %s\n# TYPE finflow_%s %s\nfinflow_%s %v\n",
+			metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// pkg/db/pool_monitor_test.go
+package db_test
+
+import (
+	"bytes"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/pkg/db"
+)
+
+// TestPoolMonitor tests the pool samples, the wait alarms and the Prometheus exposition.
+func TestPoolMonitor(t *testing.T) {
+	var logs bytes.Buffer
+	stats := sql.DBStats{MaxOpenConnections: 25, OpenConnections: 10, InUse: 4, Idle: 6}
+	monitor := db.NewPoolMonitor(func() sql.DBStats { return stats }, 10*time.Millisecond, 100*time.Millisecond, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	sample := monitor.Sample()
+	assert.Zero(t, sample.AverageWait())
+	assert.Empty(t, logs.String())
+
+	// 4 waits of 20ms on average since the previous sample
+	stats.WaitCount, stats.WaitDuration = 4, 80*time.Millisecond
+	sample = monitor.Sample()
+	assert.EqualValues(t, 4, sample.Waits)
+	assert.Equal(t, 20*time.Millisecond, sample.AverageWait())
+	assert.Contains(t, logs.String(), "level=WARN msg=\"Database pool under pressure")
+
+	stats.WaitCount, stats.WaitDuration, stats.InUse = 5, 280*time.Millisecond, 25
+	monitor.Sample()
+	assert.Contains(t, logs.String(), "level=ERROR msg=\"Database pool saturated")
+
+	logs.Reset()
+	monitor.Sample()
+	assert.Contains(t, logs.String(), "Database pool waits back to normal")
+	logs.Reset()
+	monitor.Sample()
+	assert.Empty(t, logs.String(), "the recovery is logged once")
+
+	var metrics strings.Builder
+	assert.NoError(t, monitor.WritePrometheus(&metrics))
+	assert.Contains(t, metrics.String(), "# TYPE finflow_db_pool_in_use_connections gauge\nfinflow_db_pool_in_use_connections 25\n")
+	assert.Contains(t, metrics.String(), "finflow_db_pool_wait_count_total 5\n")
+	assert.Contains(t, metrics.String(), "finflow_db_pool_wait_duration_seconds_total 0.28\n")
+}
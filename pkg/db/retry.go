@@ -0,0 +1,83 @@
+// pkg/db/retry.go
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RetryReads returns an executor that retries the read-only queries run through q when they fail with a
+// transient connection error, e.g. while a replica fails over, up to retries times with a backoff doubling
+// from backoff. Only GetContext and SelectContext queries starting with SELECT are retried; statements that
+// may write, whose effect is unknown after a dropped connection, fail as they did. It must only wrap
+// executors outside transactions, as a broken transaction cannot be resumed on another connection.
+func RetryReads(q Queryer, retries int, backoff time.Duration, logger *slog.Logger) Queryer {
+	return &retryingQueryer{Queryer: q, retries: retries, backoff: backoff, logger: logger}
+}
+
+type retryingQueryer struct {
+	Queryer
+	retries int
+	backoff time.Duration
+	logger  *slog.Logger
+}
+
+func (q *retryingQueryer) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return q.retry(ctx, query, func() error { return q.Queryer.GetContext(ctx, dest, query, args...) })
+}
+
+func (q *retryingQueryer) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return q.retry(ctx, query, func() error { return q.Queryer.SelectContext(ctx, dest, query, args...) })
+}
+
+func (q *retryingQueryer) retry(ctx context.Context, query string, run func() error) error {
+	err := run()
+	if err == nil || !isReadOnly(query) {
+		return err
+	}
+	backoff := q.backoff
+	for attempt := 1; attempt <= q.retries && IsTransientConnError(err); attempt++ {
+		q.logger.Warn("Retrying read query after connection error", "attempt", attempt, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+		if err = run(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// isReadOnly reports whether query is a plain SELECT.
+func isReadOnly(query string) bool {
+	fields := strings.Fields(query)
+	return len(fields) > 0 && strings.EqualFold(fields[0], "SELECT")
+}
+
+// IsTransientConnError reports whether err means the connection to the database was lost or could not be
+// made, rather than the query having failed, so that running the query again may succeed.
+func IsTransientConnError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Class 08 is connection exceptions; 57P01-57P03 are shutdowns and a server not accepting connections yet
+		return pqErr.Code.Class() == "08" || pqErr.Code == "57P01" || pqErr.Code == "57P02" || pqErr.Code == "57P03"
+	}
+	var netErr *net.OpError
+	return errors.As(err, &netErr)
+}
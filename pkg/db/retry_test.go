@@ -0,0 +1,92 @@
+// pkg/db/retry_test.go
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/pkg/db"
+)
+
+// flakyQueryer fails its first queries with the given errors, then succeeds.
+type flakyQueryer struct {
+	errs  []error
+	calls int
+}
+
+func (q *flakyQueryer) run() error {
+	q.calls++
+	if q.calls <= len(q.errs) {
+		return q.errs[q.calls-1]
+	}
+	return nil
+}
+
+func (q *flakyQueryer) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return q.run()
+}
+
+func (q *flakyQueryer) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return q.run()
+}
+
+func (q *flakyQueryer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return nil, q.run()
+}
+
+func (q *flakyQueryer) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return nil
+}
+
+// TestRetryReads tests which queries are retried on which errors, and how often.
+func TestRetryReads(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.DiscardHandler)
+	const selectBalance = "\n\t\tSELECT balance FROM wallets WHERE id = $1"
+
+	t.Run("ReadSucceedsAfterConnectionErrors", func(t *testing.T) {
+		q := &flakyQueryer{errs: []error{driver.ErrBadConn, syscall.ECONNRESET}}
+		err := db.RetryReads(q, 2, time.Millisecond, logger).GetContext(ctx, nil, selectBalance, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, q.calls)
+	})
+
+	t.Run("RetriesAreBounded", func(t *testing.T) {
+		q := &flakyQueryer{errs: []error{driver.ErrBadConn, driver.ErrBadConn, driver.ErrBadConn}}
+		err := db.RetryReads(q, 2, time.Millisecond, logger).SelectContext(ctx, nil, selectBalance, 1)
+		assert.ErrorIs(t, err, driver.ErrBadConn)
+		assert.Equal(t, 3, q.calls)
+	})
+
+	t.Run("QueryErrorsAreNotRetried", func(t *testing.T) {
+		q := &flakyQueryer{errs: []error{sql.ErrNoRows, &pq.Error{Code: "23505"}}}
+		r := db.RetryReads(q, 2, time.Millisecond, logger)
+		assert.ErrorIs(t, r.GetContext(ctx, nil, selectBalance, 1), sql.ErrNoRows)
+		assert.Error(t, r.GetContext(ctx, nil, selectBalance, 1))
+		assert.Equal(t, 2, q.calls)
+	})
+
+	t.Run("WritesAreNotRetried", func(t *testing.T) {
+		q := &flakyQueryer{errs: []error{driver.ErrBadConn, driver.ErrBadConn}}
+		r := db.RetryReads(q, 2, time.Millisecond, logger)
+		assert.Error(t, r.GetContext(ctx, nil, "UPDATE users SET timezone = $1 RETURNING *", "UTC"))
+		_, err := r.ExecContext(ctx, "DELETE FROM sessions")
+		assert.Error(t, err)
+		assert.Equal(t, 2, q.calls)
+	})
+
+	t.Run("ServerShutdownIsTransient", func(t *testing.T) {
+		assert.True(t, db.IsTransientConnError(&pq.Error{Code: "57P01"}))
+		assert.True(t, db.IsTransientConnError(&pq.Error{Code: "08006"}))
+		assert.False(t, db.IsTransientConnError(errors.New("syntax error")))
+	})
+}
@@ -0,0 +1,159 @@
+// pkg/db/stmt_cache.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StmtCache runs a fixed set of hot queries as prepared statements, so PostgreSQL parses and plans
+// them once per connection instead of on every call. Statements are keyed by query text and
+// prepared on first use; every other query is passed through unchanged, as is a hot query whose
+// preparation fails.
+type StmtCache struct {
+	db  *sqlx.DB
+	hot map[string]bool
+
+	mu    sync.RWMutex
+	stmts map[string]*sqlx.Stmt
+}
+
+// NewStmtCache creates a cache of prepared statements for the given queries on database.
+func NewStmtCache(database *sqlx.DB, queries ...string) *StmtCache {
+	hot := make(map[string]bool, len(queries))
+	for _, query := range queries {
+		hot[query] = true
+	}
+	return &StmtCache{db: database, hot: hot, stmts: make(map[string]*sqlx.Stmt)}
+}
+
+// Executor returns an executor that runs queries on the database outside a transaction.
+func (c *StmtCache) Executor() Queryer {
+	return &preparedQueryer{db: c.db, cache: c}
+}
+
+// WrapBeginTx wraps begin so that the hot queries of the transactions it begins use the cached statements.
+func (c *StmtCache) WrapBeginTx(begin BeginTxFunc) BeginTxFunc {
+	return func(ctx context.Context, dbConn DBTxBeginner) (TxController, error) {
+		tx, err := begin(ctx, dbConn)
+		if err != nil {
+			return nil, err
+		}
+		if sqlxTx, ok := tx.(*sqlx.Tx); ok {
+			return &preparedTx{Tx: sqlxTx, cache: c}, nil
+		}
+		return tx, nil
+	}
+}
+
+// Close closes the cached statements. Queries run afterwards are no longer prepared.
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.stmts, query)
+	}
+	c.hot = nil
+	return firstErr
+}
+
+// stmt returns the prepared statement of query, preparing it on first use. It returns nil for
+// queries that are not hot and for hot queries that could not be prepared; they run unprepared.
+func (c *StmtCache) stmt(ctx context.Context, query string) *sqlx.Stmt {
+	c.mu.RLock()
+	stmt, hot := c.stmts[query], c.hot[query]
+	c.mu.RUnlock()
+	if stmt != nil || !hot {
+		return stmt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stmt := c.stmts[query]; stmt != nil || !c.hot[query] {
+		return stmt // Prepared concurrently, or the cache was closed
+	}
+	stmt, err := c.db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil // Retried on the next call
+	}
+	c.stmts[query] = stmt
+	return stmt
+}
+
+// preparedQueryer runs the hot queries on the database through their cached statements.
+type preparedQueryer struct {
+	db    *sqlx.DB
+	cache *StmtCache
+}
+
+func (q *preparedQueryer) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	if stmt := q.cache.stmt(ctx, query); stmt != nil {
+		return stmt.GetContext(ctx, dest, args...)
+	}
+	return q.db.GetContext(ctx, dest, query, args...)
+}
+
+func (q *preparedQueryer) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	if stmt := q.cache.stmt(ctx, query); stmt != nil {
+		return stmt.SelectContext(ctx, dest, args...)
+	}
+	return q.db.SelectContext(ctx, dest, query, args...)
+}
+
+func (q *preparedQueryer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if stmt := q.cache.stmt(ctx, query); stmt != nil {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return q.db.ExecContext(ctx, query, args...)
+}
+
+func (q *preparedQueryer) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if stmt := q.cache.stmt(ctx, query); stmt != nil {
+		return stmt.Stmt.QueryRowContext(ctx, args...)
+	}
+	return q.db.QueryRowContext(ctx, query, args...)
+}
+
+// preparedTx is a transaction whose hot queries use the cached statements. database/sql reuses a
+// statement already prepared on the transaction's connection and prepares it there otherwise.
+// Transaction-bound statements are closed when the transaction ends.
+type preparedTx struct {
+	*sqlx.Tx
+	cache *StmtCache
+}
+
+func (tx *preparedTx) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	if stmt := tx.cache.stmt(ctx, query); stmt != nil {
+		return tx.Tx.StmtxContext(ctx, stmt).GetContext(ctx, dest, args...)
+	}
+	return tx.Tx.GetContext(ctx, dest, query, args...)
+}
+
+func (tx *preparedTx) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	if stmt := tx.cache.stmt(ctx, query); stmt != nil {
+		return tx.Tx.StmtxContext(ctx, stmt).SelectContext(ctx, dest, args...)
+	}
+	return tx.Tx.SelectContext(ctx, dest, query, args...)
+}
+
+func (tx *preparedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if stmt := tx.cache.stmt(ctx, query); stmt != nil {
+		return tx.Tx.StmtxContext(ctx, stmt).ExecContext(ctx, args...)
+	}
+	return tx.Tx.ExecContext(ctx, query, args...)
+}
+
+func (tx *preparedTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if stmt := tx.cache.stmt(ctx, query); stmt != nil {
+		return tx.Tx.StmtxContext(ctx, stmt).Stmt.QueryRowContext(ctx, args...)
+	}
+	return tx.Tx.QueryRowContext(ctx, query, args...)
+}
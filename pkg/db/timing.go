@@ -0,0 +1,274 @@
+// pkg/db/timing.go
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Queryer is the set of query methods shared by *sqlx.DB and *sqlx.Tx.
+// It has the same method set as repository.DBExecutor, so a timed Queryer can be handed to the repositories.
+type Queryer interface {
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// LatencyBuckets are the upper bounds of the query latency histogram buckets.
+// Slower queries are counted in a final, unbounded bucket.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// QueryStats is the latency histogram of one query name.
+type QueryStats struct {
+	Name    string
+	Count   int64
+	Total   time.Duration
+	Max     time.Duration
+	Buckets []int64 // Counts per LatencyBuckets entry, plus one for slower queries; not cumulative
+}
+
+// QueryTimer times the queries run through the executors it wraps. Queries are named after the
+// repository method that ran them, e.g. "WalletRepository.UpdateWalletBalance". Every query adds
+// to the latency histogram of its name, and queries slower than the threshold are logged with
+// their arguments redacted. Timing can be switched off and on at runtime.
+type QueryTimer struct {
+	enabled   atomic.Bool
+	threshold atomic.Int64 // time.Duration; 0 disables slow query logging
+	logger    *slog.Logger
+
+	mu    sync.Mutex
+	stats map[string]*QueryStats
+}
+
+// NewQueryTimer creates a timer in the given initial state.
+func NewQueryTimer(enabled bool, threshold time.Duration, logger *slog.Logger) *QueryTimer {
+	t := &QueryTimer{logger: logger, stats: make(map[string]*QueryStats)}
+	t.Set(enabled, threshold)
+	return t
+}
+
+// Set switches timing on or off and changes the slow query threshold.
+func (t *QueryTimer) Set(enabled bool, threshold time.Duration) {
+	t.threshold.Store(int64(threshold))
+	t.enabled.Store(enabled)
+}
+
+// Settings returns whether timing is on and the slow query threshold.
+func (t *QueryTimer) Settings() (bool, time.Duration) {
+	return t.enabled.Load(), time.Duration(t.threshold.Load())
+}
+
+// Stats returns a snapshot of the histograms, slowest total time first.
+func (t *QueryTimer) Stats() []QueryStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make([]QueryStats, 0, len(t.stats))
+	for _, s := range t.stats {
+		snapshot := *s
+		snapshot.Buckets = append([]int64(nil), s.Buckets...)
+		stats = append(stats, snapshot)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// Reset discards the collected histograms.
+func (t *QueryTimer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats = make(map[string]*QueryStats)
+}
+
+// Wrap returns an executor that times the queries run through q.
+func (t *QueryTimer) Wrap(q Queryer) Queryer {
+	return &timedQueryer{Queryer: q, timer: t}
+}
+
+// WrapBeginTx wraps begin so that the queries of the transactions it begins are timed too.
+func (t *QueryTimer) WrapBeginTx(begin BeginTxFunc) BeginTxFunc {
+	return func(ctx context.Context, dbConn DBTxBeginner) (TxController, error) {
+		tx, err := begin(ctx, dbConn)
+		if err != nil {
+			return nil, err
+		}
+		if q, ok := tx.(txQueryer); ok {
+			return &timedTx{txQueryer: q, timer: t}, nil
+		}
+		return tx, nil
+	}
+}
+
+// start returns the function that records a query started now, or nil when timing is off.
+func (t *QueryTimer) start(query string, args []any) func() {
+	if !t.enabled.Load() {
+		return nil
+	}
+	name := queryName()
+	started := time.Now()
+	return func() {
+		t.observe(name, query, args, time.Since(started))
+	}
+}
+
+func (t *QueryTimer) observe(name, query string, args []any, elapsed time.Duration) {
+	t.mu.Lock()
+	s, ok := t.stats[name]
+	if !ok {
+		s = &QueryStats{Name: name, Buckets: make([]int64, len(LatencyBuckets)+1)}
+		t.stats[name] = s
+	}
+	s.Count++
+	s.Total += elapsed
+	s.Max = max(s.Max, elapsed)
+	s.Buckets[sort.Search(len(LatencyBuckets), func(i int) bool { return elapsed <= LatencyBuckets[i] })]++
+	t.mu.Unlock()
+
+	if threshold := time.Duration(t.threshold.Load()); threshold > 0 && elapsed >= threshold {
+		t.logger.Warn("Slow query",
+			"name", name,
+			"duration", elapsed,
+			"query", strings.Join(strings.Fields(query), " "),
+			"args", redactArgs(args),
+		)
+	}
+}
+
+// queryName names a query after the first caller outside this package, i.e. the repository method.
+func queryName() string {
+	pcs := make([]uintptr, 8)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "finflow-wallet/pkg/db.") {
+			return shortFuncName(frame.Function)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// shortFuncName turns "finflow-wallet/internal/repository/postgres.(*WalletRepository).GetWalletByID"
+// into "WalletRepository.GetWalletByID".
+func shortFuncName(function string) string {
+	if function == "" {
+		return "unknown"
+	}
+	name := function[strings.LastIndex(function, "/")+1:]
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
+
+// redactArgs replaces query arguments with their types, so that slow query logs never contain
+// balances, names or other user data.
+func redactArgs(args []any) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = fmt.Sprintf("$%d=<%T>", i+1, arg)
+	}
+	return redacted
+}
+
+// timedQueryer times the queries of a wrapped Queryer.
+type timedQueryer struct {
+	Queryer
+	timer *QueryTimer
+}
+
+func (q *timedQueryer) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	if done := q.timer.start(query, args); done != nil {
+		defer done()
+	}
+	return q.Queryer.GetContext(ctx, dest, query, args...)
+}
+
+func (q *timedQueryer) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	if done := q.timer.start(query, args); done != nil {
+		defer done()
+	}
+	return q.Queryer.SelectContext(ctx, dest, query, args...)
+}
+
+func (q *timedQueryer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if done := q.timer.start(query, args); done != nil {
+		defer done()
+	}
+	return q.Queryer.ExecContext(ctx, query, args...)
+}
+
+// QueryRowContext times the execution of the query; scanning the returned row is not included.
+func (q *timedQueryer) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if done := q.timer.start(query, args); done != nil {
+		defer done()
+	}
+	return q.Queryer.QueryRowContext(ctx, query, args...)
+}
+
+// txQueryer is a transaction that can run queries, such as *sqlx.Tx.
+type txQueryer interface {
+	TxController
+	Queryer
+}
+
+// timedTx is a transaction whose queries are timed. It still satisfies TxController.
+type timedTx struct {
+	txQueryer
+	timer *QueryTimer
+}
+
+func (tx *timedTx) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	if done := tx.timer.start(query, args); done != nil {
+		defer done()
+	}
+	return tx.txQueryer.GetContext(ctx, dest, query, args...)
+}
+
+func (tx *timedTx) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	if done := tx.timer.start(query, args); done != nil {
+		defer done()
+	}
+	return tx.txQueryer.SelectContext(ctx, dest, query, args...)
+}
+
+func (tx *timedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if done := tx.timer.start(query, args); done != nil {
+		defer done()
+	}
+	return tx.txQueryer.ExecContext(ctx, query, args...)
+}
+
+func (tx *timedTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if done := tx.timer.start(query, args); done != nil {
+		defer done()
+	}
+	return tx.txQueryer.QueryRowContext(ctx, query, args...)
+}
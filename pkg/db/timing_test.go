@@ -0,0 +1,100 @@
+// pkg/db/timing_test.go
+package db_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"finflow-wallet/pkg/db"
+)
+
+// sleepyQueryer pretends every query takes delay.
+type sleepyQueryer struct {
+	delay time.Duration
+}
+
+func (q sleepyQueryer) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	time.Sleep(q.delay)
+	return nil
+}
+
+func (q sleepyQueryer) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	time.Sleep(q.delay)
+	return nil
+}
+
+func (q sleepyQueryer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	time.Sleep(q.delay)
+	return nil, nil
+}
+
+func (q sleepyQueryer) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	time.Sleep(q.delay)
+	return nil
+}
+
+// walletRepo stands in for a repository, whose method names the queries.
+type walletRepo struct{}
+
+func (walletRepo) UpdateWalletBalance(ctx context.Context, q db.Queryer, balance string) error {
+	_, err := q.ExecContext(ctx, "UPDATE wallets\n\tSET balance = $1", balance)
+	return err
+}
+
+// TestQueryTimer tests the query histograms, the slow query log and the runtime switch.
+func TestQueryTimer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RecordsHistogramPerQueryName", func(t *testing.T) {
+		timer := db.NewQueryTimer(true, 0, slog.New(slog.DiscardHandler))
+		q := timer.Wrap(sleepyQueryer{})
+
+		assert.NoError(t, walletRepo{}.UpdateWalletBalance(ctx, q, "10.00"))
+		assert.NoError(t, walletRepo{}.UpdateWalletBalance(ctx, q, "20.00"))
+
+		stats := timer.Stats()
+		if assert.Len(t, stats, 1) {
+			assert.Equal(t, "walletRepo.UpdateWalletBalance", stats[0].Name)
+			assert.EqualValues(t, 2, stats[0].Count)
+			assert.Len(t, stats[0].Buckets, len(db.LatencyBuckets)+1)
+			assert.EqualValues(t, 2, stats[0].Buckets[0]) // Both well under a millisecond
+		}
+
+		timer.Reset()
+		assert.Empty(t, timer.Stats())
+	})
+
+	t.Run("LogsSlowQueriesWithRedactedArgs", func(t *testing.T) {
+		var logs bytes.Buffer
+		timer := db.NewQueryTimer(true, 5*time.Millisecond, slog.New(slog.NewTextHandler(&logs, nil)))
+
+		assert.NoError(t, walletRepo{}.UpdateWalletBalance(ctx, timer.Wrap(sleepyQueryer{}), "10.00"))
+		assert.Empty(t, logs.String())
+
+		assert.NoError(t, walletRepo{}.UpdateWalletBalance(ctx, timer.Wrap(sleepyQueryer{delay: 10 * time.Millisecond}), "1234.56"))
+		assert.Contains(t, logs.String(), "Slow query")
+		assert.Contains(t, logs.String(), "walletRepo.UpdateWalletBalance")
+		assert.Contains(t, logs.String(), `"UPDATE wallets SET balance = $1"`)
+		assert.Contains(t, logs.String(), "$1=<string>")
+		assert.NotContains(t, logs.String(), "1234.56")
+	})
+
+	t.Run("SwitchedOffAtRuntime", func(t *testing.T) {
+		timer := db.NewQueryTimer(true, time.Second, slog.New(slog.DiscardHandler))
+		q := timer.Wrap(sleepyQueryer{})
+
+		timer.Set(false, 50*time.Millisecond)
+		assert.NoError(t, walletRepo{}.UpdateWalletBalance(ctx, q, "10.00"))
+
+		enabled, threshold := timer.Settings()
+		assert.False(t, enabled)
+		assert.Equal(t, 50*time.Millisecond, threshold)
+		assert.Empty(t, timer.Stats())
+	})
+}
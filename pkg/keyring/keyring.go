@@ -0,0 +1,129 @@
+// pkg/keyring/keyring.go
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the size of a key in bytes; keys are AES-256 keys.
+const KeySize = 32
+
+// ErrUnknownKey is returned for a ciphertext encrypted with a key the keyring does not hold.
+var ErrUnknownKey = errors.New("ciphertext was encrypted with an unknown key")
+
+// Keyring encrypts values with its primary key and decrypts them with whichever of its keys encrypted them,
+// so keys can be rotated while values encrypted with older keys stay readable.
+type Keyring interface {
+	// Encrypt encrypts plaintext with the primary key. The same associatedData, e.g. the column name, must be
+	// passed to Decrypt, so a ciphertext cannot be moved to another field.
+	Encrypt(plaintext, associatedData string) (string, error)
+	// Decrypt decrypts a ciphertext made by Encrypt with any key of the keyring.
+	Decrypt(ciphertext, associatedData string) (string, error)
+	// PrimaryKeyID returns the ID of the key new values are encrypted with.
+	PrimaryKeyID() string
+	// BlindIndex returns a keyed hash of plaintext under associatedData, equal for equal values, so encrypted
+	// values can be looked up and kept unique without being decrypted. It does not change with key rotation.
+	BlindIndex(plaintext, associatedData string) string
+}
+
+// AESKeyring is a Keyring using AES-256-GCM. Ciphertexts have the form "<key ID>:<base64 of nonce and sealed
+// value>", so the key of each value is known without trying them all.
+type AESKeyring struct {
+	ciphers  map[string]cipher.AEAD
+	primary  string
+	indexKey []byte // HMAC-SHA256 key of blind indexes
+}
+
+// NewAESKeyring creates an AESKeyring from keys by ID, encrypting with the primary one. indexKey keys the
+// blind indexes and is kept separate from the encryption keys, so that rotating them leaves indexes intact.
+func NewAESKeyring(keys map[string][]byte, primary string, indexKey []byte) (*AESKeyring, error) {
+	if len(indexKey) != KeySize {
+		return nil, fmt.Errorf("index key has %d bytes, want %d", len(indexKey), KeySize)
+	}
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q is not in the keyring", primary)
+	}
+	ciphers := make(map[string]cipher.AEAD, len(keys))
+	for id, key := range keys {
+		if id == "" || strings.ContainsAny(id, ":,") {
+			return nil, fmt.Errorf("key ID %q must be non-empty and must not contain ':' or ','", id)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key %q has %d bytes, want %d", id, len(key), KeySize)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		ciphers[id] = aead
+	}
+	return &AESKeyring{ciphers: ciphers, primary: primary, indexKey: indexKey}, nil
+}
+
+// Encrypt implements Keyring, with a random nonce per value.
+func (k *AESKeyring) Encrypt(plaintext, associatedData string) (string, error) {
+	aead := k.ciphers[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(associatedData))
+	return k.primary + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt implements Keyring.
+func (k *AESKeyring) Decrypt(ciphertext, associatedData string) (string, error) {
+	id, err := KeyID(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	aead, ok := k.ciphers[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(ciphertext[len(id)+1:])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed ciphertext of key %q", id)
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(associatedData))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt with key %q: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// PrimaryKeyID implements Keyring.
+func (k *AESKeyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// BlindIndex implements Keyring with the hex-encoded HMAC-SHA256 of associatedData and plaintext.
+func (k *AESKeyring) BlindIndex(plaintext, associatedData string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(associatedData))
+	mac.Write([]byte{0})
+	mac.Write([]byte(plaintext))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// KeyID returns the ID of the key a ciphertext made by AESKeyring was encrypted with.
+func KeyID(ciphertext string) (string, error) {
+	id, _, ok := strings.Cut(ciphertext, ":")
+	if !ok || id == "" {
+		return "", errors.New("malformed ciphertext: missing key ID")
+	}
+	return id, nil
+}
@@ -0,0 +1,119 @@
+// pkg/keyring/keyring_test.go
+package keyring
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mapSecretProvider is a SecretProvider holding fixed secrets.
+type mapSecretProvider map[string]string
+
+func (p mapSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	if value, ok := p[name]; ok {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+// TestAESKeyring tests encryption, decryption across rotated keys and loading keys from secrets.
+func TestAESKeyring(t *testing.T) {
+	oldKey, newKey, indexKey := bytes.Repeat([]byte{1}, KeySize), bytes.Repeat([]byte{2}, KeySize), bytes.Repeat([]byte{3}, KeySize)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		keyring, err := NewAESKeyring(map[string][]byte{"k1": oldKey}, "k1", indexKey)
+		assert.NoError(t, err)
+
+		ciphertext, err := keyring.Encrypt("alice@example.com", "users.email")
+		assert.NoError(t, err)
+		assert.NotContains(t, ciphertext, "alice")
+		id, _ := KeyID(ciphertext)
+		assert.Equal(t, "k1", id)
+
+		again, _ := keyring.Encrypt("alice@example.com", "users.email")
+		assert.NotEqual(t, ciphertext, again) // Random nonces
+
+		plaintext, err := keyring.Decrypt(ciphertext, "users.email")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice@example.com", plaintext)
+	})
+
+	t.Run("AssociatedDataMustMatch", func(t *testing.T) {
+		keyring, _ := NewAESKeyring(map[string][]byte{"k1": oldKey}, "k1", indexKey)
+		ciphertext, _ := keyring.Encrypt("alice@example.com", "users.email")
+
+		_, err := keyring.Decrypt(ciphertext, "users.phone")
+		assert.Error(t, err)
+	})
+
+	t.Run("RotatedKeyStillDecrypts", func(t *testing.T) {
+		before, _ := NewAESKeyring(map[string][]byte{"k1": oldKey}, "k1", indexKey)
+		ciphertext, _ := before.Encrypt("+4915112345678", "users.phone")
+		after, err := NewAESKeyring(map[string][]byte{"k1": oldKey, "k2": newKey}, "k2", indexKey)
+		assert.NoError(t, err)
+
+		plaintext, err := after.Decrypt(ciphertext, "users.phone")
+		assert.NoError(t, err)
+		assert.Equal(t, "+4915112345678", plaintext)
+
+		reencrypted, _ := after.Encrypt(plaintext, "users.phone")
+		id, _ := KeyID(reencrypted)
+		assert.Equal(t, "k2", id)
+	})
+
+	t.Run("RetiredKeyIsUnknown", func(t *testing.T) {
+		before, _ := NewAESKeyring(map[string][]byte{"k1": oldKey}, "k1", indexKey)
+		ciphertext, _ := before.Encrypt("x", "users.email")
+		after, _ := NewAESKeyring(map[string][]byte{"k2": newKey}, "k2", indexKey)
+
+		_, err := after.Decrypt(ciphertext, "users.email")
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("InvalidKeys", func(t *testing.T) {
+		_, err := NewAESKeyring(map[string][]byte{"k1": oldKey[:16]}, "k1", indexKey)
+		assert.Error(t, err)
+		_, err = NewAESKeyring(map[string][]byte{"k1": oldKey}, "k2", indexKey)
+		assert.Error(t, err)
+		_, err = NewAESKeyring(map[string][]byte{"k:1": oldKey}, "k:1", indexKey)
+		assert.Error(t, err)
+		_, err = NewAESKeyring(map[string][]byte{"k1": oldKey}, "k1", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("BlindIndexSurvivesRotation", func(t *testing.T) {
+		before, _ := NewAESKeyring(map[string][]byte{"k1": oldKey}, "k1", indexKey)
+		after, _ := NewAESKeyring(map[string][]byte{"k2": newKey}, "k2", indexKey)
+
+		assert.Equal(t, before.BlindIndex("alice@example.com", "users.email"), after.BlindIndex("alice@example.com", "users.email"))
+		assert.NotEqual(t, before.BlindIndex("alice@example.com", "users.email"), before.BlindIndex("alice@example.com", "users.phone"))
+		assert.NotEqual(t, before.BlindIndex("alice@example.com", "users.email"), before.BlindIndex("bob@example.com", "users.email"))
+	})
+
+	t.Run("LoadKeyring", func(t *testing.T) {
+		ctx := context.Background()
+		encode := base64.StdEncoding.EncodeToString
+
+		keyring, err := LoadKeyring(ctx, mapSecretProvider{})
+		assert.NoError(t, err)
+		assert.Nil(t, keyring)
+
+		_, err = LoadKeyring(ctx, mapSecretProvider{SecretKeys: "k1:" + encode(oldKey)})
+		assert.Error(t, err) // No index key
+
+		keyring, err = LoadKeyring(ctx, mapSecretProvider{SecretKeys: "k1:" + encode(oldKey), SecretIndexKey: encode(indexKey)})
+		assert.NoError(t, err)
+		assert.Equal(t, "k1", keyring.PrimaryKeyID())
+
+		_, err = LoadKeyring(ctx, mapSecretProvider{SecretKeys: "k1:" + encode(oldKey) + ",k2:" + encode(newKey), SecretIndexKey: encode(indexKey)})
+		assert.Error(t, err)
+
+		keyring, err = LoadKeyring(ctx, mapSecretProvider{SecretKeys: "k1:" + encode(oldKey) + ", k2:" + encode(newKey), SecretPrimaryKey: "k2", SecretIndexKey: encode(indexKey)})
+		assert.NoError(t, err)
+		assert.Equal(t, "k2", keyring.PrimaryKeyID())
+	})
+}
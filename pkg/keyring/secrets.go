@@ -0,0 +1,93 @@
+// pkg/keyring/secrets.go
+package keyring
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Names of the secrets LoadKeyring reads.
+const (
+	// SecretKeys lists the keys as comma-separated "<key ID>:<base64 key>" pairs, e.g. "2026-10:q83v...,2026-01:Zm9v...".
+	SecretKeys = "PII_ENCRYPTION_KEYS"
+	// SecretPrimaryKey names the ID of the key new values are encrypted with; optional with a single key.
+	SecretPrimaryKey = "PII_ENCRYPTION_PRIMARY_KEY"
+	// SecretIndexKey is the base64 key of blind indexes, required with SecretKeys. Unlike the encryption keys
+	// it cannot be rotated without recomputing every index.
+	SecretIndexKey = "PII_INDEX_KEY"
+)
+
+// ErrSecretNotFound is returned by a SecretProvider for a secret it does not hold.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider supplies secrets such as encryption keys by name, e.g. from the environment or a vault.
+type SecretProvider interface {
+	// Secret returns the named secret, or ErrSecretNotFound.
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// EnvSecretProvider is a SecretProvider reading secrets from environment variables of the same name.
+type EnvSecretProvider struct{}
+
+// Secret implements SecretProvider. An empty variable counts as unset.
+func (EnvSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// LoadKeyring builds an AESKeyring from the SecretKeys, SecretPrimaryKey and SecretIndexKey secrets. Without SecretKeys it
+// returns nil and no error, leaving encryption unconfigured.
+func LoadKeyring(ctx context.Context, provider SecretProvider) (*AESKeyring, error) {
+	encoded, err := provider.Secret(ctx, SecretKeys)
+	if errors.Is(err, ErrSecretNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", SecretKeys, err)
+	}
+
+	keys := make(map[string][]byte)
+	var lastID string
+	for _, pair := range strings.Split(encoded, ",") {
+		id, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s entry: want <key ID>:<base64 key>", SecretKeys)
+		}
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s key %q: %w", SecretKeys, id, err)
+		}
+		keys[id] = key
+		lastID = id
+	}
+
+	primary, err := provider.Secret(ctx, SecretPrimaryKey)
+	switch {
+	case errors.Is(err, ErrSecretNotFound) && len(keys) == 1:
+		primary = lastID
+	case errors.Is(err, ErrSecretNotFound):
+		return nil, fmt.Errorf("%s is required with more than one key", SecretPrimaryKey)
+	case err != nil:
+		return nil, fmt.Errorf("failed to read %s: %w", SecretPrimaryKey, err)
+	}
+
+	encodedIndexKey, err := provider.Secret(ctx, SecretIndexKey)
+	if errors.Is(err, ErrSecretNotFound) {
+		return nil, fmt.Errorf("%s is required with %s", SecretIndexKey, SecretKeys)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", SecretIndexKey, err)
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(encodedIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", SecretIndexKey, err)
+	}
+	return NewAESKeyring(keys, primary, indexKey)
+}
@@ -0,0 +1,266 @@
+// pkg/redis/redis.go
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error is an error reply of the server, such as a wrong password or an unknown command. The connection
+// stays usable after one.
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// ErrClosed is returned by commands issued after Close.
+var ErrClosed = errors.New("redis: client is closed")
+
+// Client is a minimal Redis client speaking RESP2 over a pool of connections. It covers the few commands
+// the service needs rather than the whole command set, and is safe for concurrent use.
+type Client struct {
+	addr      string
+	username  string
+	password  string
+	db        int
+	tlsConfig *tls.Config // Set for rediss:// URLs
+	timeout   time.Duration
+
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+// maxIdleConns is the number of connections kept open between commands.
+const maxIdleConns = 16
+
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Open returns a client for a redis:// or rediss:// (TLS) URL of the form
+// scheme://[[username]:password@]host[:port][/db]. Connections are opened when first needed; timeout bounds
+// the dial and each command whose context has no earlier deadline.
+func Open(rawURL string, timeout time.Duration) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	c := &Client{timeout: timeout}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		c.tlsConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	default:
+		return nil, fmt.Errorf("invalid Redis URL: unsupported scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("invalid Redis URL: no host")
+	}
+	c.addr = u.Host
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		if c.db, err = strconv.Atoi(path); err != nil || c.db < 0 {
+			return nil, fmt.Errorf("invalid Redis URL: database %q is not a number", path)
+		}
+	}
+	return c, nil
+}
+
+// Ping checks that the server is reachable and accepts the credentials.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// SetNX sets the key to the value for ttl if it does not exist, and reports whether it did not.
+func (c *Client) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	reply, err := c.Do(ctx, "SET", key, value, "NX", "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	if err != nil {
+		return false, err
+	}
+	return reply == "OK", nil
+}
+
+// Do sends a command and returns its reply: a string for simple and bulk strings, an int64 for integers,
+// a []any for arrays and nil for nil replies. An error reply is returned as an Error.
+func (c *Client) Do(ctx context.Context, args ...string) (any, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := cn.do(ctx, c.timeout, args)
+	var serverErr Error
+	if err != nil && !errors.As(err, &serverErr) {
+		// The connection may be midway through a reply; it cannot be reused
+		_ = cn.Close()
+		return nil, fmt.Errorf("redis %s: %w", args[0], err)
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close closes the idle connections; commands in flight close theirs when done.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	var err error
+	for _, cn := range c.idle {
+		err = errors.Join(err, cn.Close())
+	}
+	c.idle = nil
+	return err
+}
+
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+	return c.dial(ctx)
+}
+
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || len(c.idle) >= maxIdleConns {
+		_ = cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+// dial opens a connection and authenticates it and selects the database, if configured.
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	if c.tlsConfig != nil {
+		tlsConn := tls.Client(netConn, c.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = netConn.Close()
+			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		netConn = tlsConn
+	}
+	cn := &conn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var setup [][]string
+	if c.password != "" {
+		if c.username != "" {
+			setup = append(setup, []string{"AUTH", c.username, c.password})
+		} else {
+			setup = append(setup, []string{"AUTH", c.password})
+		}
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	for _, args := range setup {
+		if _, err := cn.do(ctx, c.timeout, args); err != nil {
+			_ = cn.Close()
+			return nil, fmt.Errorf("failed to set up Redis connection: %s: %w", args[0], err)
+		}
+	}
+	return cn, nil
+}
+
+// do writes the command as an array of bulk strings and reads its reply, within ctx's deadline or timeout,
+// whichever comes first.
+func (cn *conn) do(ctx context.Context, timeout time.Duration, args []string) (any, error) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(cn, command.String()); err != nil {
+		return nil, err
+	}
+	reply, err := readReply(cn.reader)
+	if err != nil {
+		return nil, err
+	}
+	if serverErr, ok := reply.(Error); ok {
+		return nil, serverErr
+	}
+	return reply, nil
+}
+
+// readReply reads one RESP2 reply. Error replies are returned as Error values, so that an error nested in
+// an array does not leave the rest of the array unread.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, line := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return line, nil
+	case '-':
+		return Error(line), nil
+	case ':':
+		return strconv.ParseInt(line, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err // $-1 is the nil reply
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err // *-1 is the nil array
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("malformed reply type %q", kind)
+}
@@ -0,0 +1,182 @@
+// pkg/redis/redis_test.go
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer answers the commands the client sends from an in-memory keyspace, requiring a password.
+type fakeServer struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	keys     map[string]time.Time // Key -> expiry
+	commands []string
+	conns    int
+}
+
+func newFakeServer(t *testing.T, password string) *fakeServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeServer{listener: listener, password: password, keys: map[string]time.Time{}}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			netConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+			go s.serve(netConn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) serve(netConn net.Conn) {
+	defer netConn.Close()
+	reader := bufio.NewReader(netConn)
+	authenticated := s.password == ""
+	for {
+		reply, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.Join(args, " "))
+		var answer string
+		switch {
+		case args[0] == "AUTH":
+			authenticated = args[len(args)-1] == s.password
+			answer = "+OK\r\n"
+			if !authenticated {
+				answer = "-WRONGPASS invalid username-password pair\r\n"
+			}
+		case !authenticated:
+			answer = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "PING":
+			answer = "+PONG\r\n"
+		case args[0] == "SELECT":
+			answer = "+OK\r\n"
+		case args[0] == "SET" && len(args) == 6 && args[3] == "NX" && args[4] == "PX":
+			if expiry, ok := s.keys[args[1]]; ok && time.Now().Before(expiry) {
+				answer = "$-1\r\n"
+			} else {
+				var ms int64
+				_, _ = fmt.Sscan(args[5], &ms)
+				s.keys[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+				answer = "+OK\r\n"
+			}
+		case args[0] == "MGET":
+			answer = "*2\r\n$3\r\nabc\r\n-ERR nested\r\n"
+		default:
+			answer = "-ERR unknown command '" + args[0] + "'\r\n"
+		}
+		s.mu.Unlock()
+		if _, err := netConn.Write([]byte(answer)); err != nil {
+			return
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SetNXOnlySetsMissingKeys", func(t *testing.T) {
+		server := newFakeServer(t, "")
+		client, err := Open("redis://"+server.listener.Addr().String(), time.Second)
+		require.NoError(t, err)
+		defer client.Close()
+
+		first, err := client.SetNX(ctx, "nonce:acme:n-1", "1", time.Minute)
+		require.NoError(t, err)
+		second, err := client.SetNX(ctx, "nonce:acme:n-1", "1", time.Minute)
+		require.NoError(t, err)
+
+		assert.True(t, first)
+		assert.False(t, second)
+		assert.Equal(t, "SET nonce:acme:n-1 1 NX PX 60000", server.commands[0])
+		assert.Equal(t, 1, server.conns, "the connection is reused")
+	})
+
+	t.Run("AuthenticatesAndSelectsDatabase", func(t *testing.T) {
+		server := newFakeServer(t, "pw")
+		client, err := Open("redis://:pw@"+server.listener.Addr().String()+"/2", time.Second)
+		require.NoError(t, err)
+		defer client.Close()
+
+		require.NoError(t, client.Ping(ctx))
+		assert.Equal(t, []string{"AUTH pw", "SELECT 2", "PING"}, server.commands)
+	})
+
+	t.Run("WrongPasswordFails", func(t *testing.T) {
+		server := newFakeServer(t, "pw")
+		client, err := Open("redis://default:other@"+server.listener.Addr().String(), time.Second)
+		require.NoError(t, err)
+		defer client.Close()
+
+		err = client.Ping(ctx)
+		assert.ErrorContains(t, err, "WRONGPASS")
+	})
+
+	t.Run("ErrorReplyKeepsConnection", func(t *testing.T) {
+		server := newFakeServer(t, "")
+		client, err := Open("redis://"+server.listener.Addr().String(), time.Second)
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.Do(ctx, "NOPE")
+		var serverErr Error
+		assert.ErrorAs(t, err, &serverErr)
+		reply, err := client.Do(ctx, "MGET", "a", "b")
+		require.NoError(t, err)
+		assert.Equal(t, []any{"abc", Error("ERR nested")}, reply)
+		require.NoError(t, client.Ping(ctx))
+		assert.Equal(t, 1, server.conns)
+	})
+
+	t.Run("UnreachableServerFails", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		require.NoError(t, listener.Close())
+		client, err := Open("redis://"+addr, time.Second)
+		require.NoError(t, err)
+
+		assert.Error(t, client.Ping(ctx))
+	})
+
+	t.Run("ClosedClientFails", func(t *testing.T) {
+		client, err := Open("redis://localhost", time.Second)
+		require.NoError(t, err)
+		require.NoError(t, client.Close())
+
+		assert.ErrorIs(t, client.Ping(ctx), ErrClosed)
+	})
+
+	t.Run("InvalidURLs", func(t *testing.T) {
+		for _, rawURL := range []string{"http://localhost", "redis://", "redis://localhost/db"} {
+			_, err := Open(rawURL, time.Second)
+			assert.Error(t, err, rawURL)
+		}
+	})
+}